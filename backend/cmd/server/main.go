@@ -19,11 +19,13 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/setup"
 	"github.com/Wei-Shaw/sub2api/internal/web"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 //go:embed VERSION
@@ -59,6 +61,9 @@ func main() {
 	// Parse command line flags
 	setupMode := flag.Bool("setup", false, "Run setup wizard in CLI mode")
 	showVersion := flag.Bool("version", false, "Show version information")
+	rotateSecrets := flag.Bool("rotate-secrets", false, "Re-encrypt stored secrets from totp.previous_encryption_keys to totp.encryption_key, then exit")
+	rotateDryRun := flag.Bool("rotate-dry-run", false, "With -rotate-secrets: only verify and count secrets, do not write")
+	rotateAllowUnreadable := flag.Bool("rotate-allow-unreadable", false, "With -rotate-secrets: rotate the rest even if some secrets cannot be decrypted")
 	flag.Parse()
 
 	if *showVersion {
@@ -74,6 +79,14 @@ func main() {
 		return
 	}
 
+	// Master key rotation mode
+	if *rotateSecrets {
+		if err := runSecretRotation(*rotateDryRun, *rotateAllowUnreadable); err != nil {
+			log.Fatalf("Secret rotation failed: %v", err)
+		}
+		return
+	}
+
 	// Check if setup is needed
 	if setup.NeedsSetup() {
 		// Check if auto-setup is enabled (for Docker deployment)
@@ -143,6 +156,19 @@ func runMainServer() {
 		log.Println("⚠️  WARNING: Running in SIMPLE mode - billing and quota checks are DISABLED")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, Version)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	// 最先注册、最后执行：服务关闭后再刷出剩余 span
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}()
+
 	buildInfo := handler.BuildInfo{
 		Version:   Version,
 		BuildType: BuildType,
@@ -154,6 +180,13 @@ func runMainServer() {
 	}
 	defer app.Cleanup()
 
+	// 部署记录写入审计日志，供事故快照关联"哪次发布之后出的问题"
+	logger.L().With(zap.String("component", "audit.deploy")).Info("server started",
+		zap.String("version", Version),
+		zap.String("commit", Commit),
+		zap.String("build_type", BuildType),
+	)
+
 	// 启动服务器
 	go func() {
 		if err := app.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
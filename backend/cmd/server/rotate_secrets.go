@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/repository"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// runSecretRotation 在线轮换主密钥：将旧密钥加密的存量密文重新加密到 totp.encryption_key。
+// 运行中的实例需先配置同样的新旧密钥（旧密钥放入 totp.previous_encryption_keys），
+// 轮换期间两种密文都能解密，因此无需停机；中断后可直接重跑。
+func runSecretRotation(dryRun, allowUnreadable bool) error {
+	cfg, err := config.LoadForBootstrap()
+	if err != nil {
+		return err
+	}
+	if !cfg.Totp.EncryptionKeyConfigured {
+		return errors.New("totp.encryption_key is not configured; set the new key explicitly before rotating")
+	}
+	if len(cfg.Totp.PreviousEncryptionKeys) == 0 {
+		return errors.New("totp.previous_encryption_keys is empty; add the old key(s) to rotate from")
+	}
+
+	encryptor, err := repository.NewAESEncryptor(cfg)
+	if err != nil {
+		return err
+	}
+	reencryptor, ok := encryptor.(service.SecretReencryptor)
+	if !ok {
+		return errors.New("configured encryptor does not support re-encryption")
+	}
+
+	client, sqlDB, err := repository.InitEnt(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svc := service.NewSecretRotationService(repository.NewSecretRotationRepository(sqlDB), reencryptor)
+	if !dryRun {
+		log.Println("Verifying that every stored secret can be decrypted before writing...")
+	}
+	report, err := svc.Rotate(ctx, service.SecretRotationOptions{
+		DryRun:          dryRun,
+		AllowUnreadable: allowUnreadable,
+		Progress: func(k *service.SecretRotationKindReport) {
+			log.Printf("[SecretRotation] %s: scanned=%d rotated=%d current=%d conflicts=%d unreadable=%d",
+				k.Kind, k.Scanned, k.Rotated, k.Current, k.Conflicts, k.Unreadable)
+		},
+	})
+	if report != nil {
+		for _, k := range report.Kinds {
+			log.Printf("[SecretRotation] %s done: scanned=%d rotated=%d current=%d conflicts=%d unreadable=%d",
+				k.Kind, k.Scanned, k.Rotated, k.Current, k.Conflicts, k.Unreadable)
+			if len(k.UnreadableIDs) > 0 {
+				log.Printf("[SecretRotation] %s unreadable ids (first %d): %v", k.Kind, len(k.UnreadableIDs), k.UnreadableIDs)
+			}
+		}
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrSecretRotationUnreadable) {
+			log.Println("Fix or remove the unreadable secrets, add the missing key to totp.previous_encryption_keys, or rerun with -rotate-allow-unreadable.")
+		}
+		return err
+	}
+
+	switch {
+	case dryRun:
+		log.Printf("Dry run complete: %d secret(s) still use a previous key.", report.Pending())
+	case report.Unreadable() > 0:
+		log.Printf("Rotation complete, but %d secret(s) could not be decrypted; keep totp.previous_encryption_keys until they are resolved.", report.Unreadable())
+	default:
+		log.Println("Rotation complete. Run again with -rotate-dry-run to confirm, then remove totp.previous_encryption_keys from every instance.")
+	}
+	return nil
+}
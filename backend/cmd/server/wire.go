@@ -78,13 +78,21 @@ func provideCleanup(
 	opsCleanup *service.OpsCleanupService,
 	opsScheduledReport *service.OpsScheduledReportService,
 	opsSystemLogSink *service.OpsSystemLogSink,
+	opsUpstreamRequestIDRecorder *service.OpsUpstreamRequestIDRecorder,
+	opsStreamTimingRecorder *service.OpsStreamTimingRecorder,
+	opsWatchdog *service.OpsWatchdogService,
+	opsSchemaDrift *service.OpsSchemaDriftDetector,
 	schedulerSnapshot *service.SchedulerSnapshotService,
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
+	staleAccount *service.StaleAccountService,
+	accountLease *service.AccountLeaseService,
 	proxyExpiry *service.ProxyExpiryService,
+	guestAPIKeyCleanup *service.GuestAPIKeyCleanupService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
+	configSync *service.ConfigSyncService,
 	batchImageCleanup *service.BatchImageCleanupService,
 	batchImageWorker *service.BatchImageWorkerRuntime,
 	pricing *service.PricingService,
@@ -103,6 +111,8 @@ func provideCleanup(
 	paymentOrderExpiry *service.PaymentOrderExpiryService,
 	channelMonitorRunner *service.ChannelMonitorRunner,
 	quotaFlusher *service.UserPlatformQuotaUsageFlusher,
+	usageAlert *service.UserUsageAlertService,
+	billingStatement *service.BillingStatementService,
 ) func() {
 	return func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -133,6 +143,30 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"OpsUpstreamRequestIDRecorder", func() error {
+				if opsUpstreamRequestIDRecorder != nil {
+					opsUpstreamRequestIDRecorder.Stop()
+				}
+				return nil
+			}},
+			{"OpsStreamTimingRecorder", func() error {
+				if opsStreamTimingRecorder != nil {
+					opsStreamTimingRecorder.Stop()
+				}
+				return nil
+			}},
+			{"OpsWatchdogService", func() error {
+				if opsWatchdog != nil {
+					opsWatchdog.Stop()
+				}
+				return nil
+			}},
+			{"OpsSchemaDriftDetector", func() error {
+				if opsSchemaDrift != nil {
+					opsSchemaDrift.Stop()
+				}
+				return nil
+			}},
 			{"OpsAlertEvaluatorService", func() error {
 				if opsAlertEvaluator != nil {
 					opsAlertEvaluator.Stop()
@@ -169,6 +203,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"ConfigSyncService", func() error {
+				if configSync != nil {
+					configSync.Stop()
+				}
+				return nil
+			}},
 			{"BatchImageCleanupService", func() error {
 				if batchImageCleanup != nil {
 					batchImageCleanup.Stop()
@@ -189,10 +229,22 @@ func provideCleanup(
 				accountExpiry.Stop()
 				return nil
 			}},
+			{"StaleAccountService", func() error {
+				staleAccount.Stop()
+				return nil
+			}},
+			{"AccountLeaseService", func() error {
+				accountLease.Stop()
+				return nil
+			}},
 			{"ProxyExpiryService", func() error {
 				proxyExpiry.Stop()
 				return nil
 			}},
+			{"GuestAPIKeyCleanupService", func() error {
+				guestAPIKeyCleanup.Stop()
+				return nil
+			}},
 			{"SubscriptionExpiryService", func() error {
 				subscriptionExpiry.Stop()
 				return nil
@@ -279,6 +331,18 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"UserUsageAlertService", func() error {
+				if usageAlert != nil {
+					usageAlert.Stop()
+				}
+				return nil
+			}},
+			{"BillingStatementService", func() error {
+				if billingStatement != nil {
+					billingStatement.Stop()
+				}
+				return nil
+			}},
 		}
 
 		infraSteps := []cleanupStep{
@@ -71,7 +71,8 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	schedulerCache := repository.ProvideSchedulerCache(redisClient, configConfig)
 	accountRepository := repository.NewAccountRepository(client, db, schedulerCache)
 	concurrencyService := service.ProvideConcurrencyService(concurrencyCache, accountRepository, configConfig)
-	apiKeyService := service.ProvideAPIKeyService(apiKeyRepository, userRepository, groupRepository, userSubscriptionRepository, userGroupRateRepository, apiKeyCache, configConfig, billingCacheService, concurrencyService)
+	apiKeyProjectRepository := repository.NewAPIKeyProjectRepository(db)
+	apiKeyService := service.ProvideAPIKeyService(apiKeyRepository, userRepository, groupRepository, userSubscriptionRepository, userGroupRateRepository, apiKeyCache, configConfig, billingCacheService, concurrencyService, apiKeyProjectRepository)
 	apiKeyAuthCacheInvalidator := service.ProvideAPIKeyAuthCacheInvalidator(apiKeyService)
 	promoService := service.NewPromoService(promoCodeRepository, userRepository, billingCacheService, client, apiKeyAuthCacheInvalidator)
 	subscriptionService := service.NewSubscriptionService(groupRepository, userSubscriptionRepository, billingCacheService, client, configConfig)
@@ -109,13 +110,17 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 		return nil, err
 	}
 	billingService := service.NewBillingService(configConfig, pricingService)
+	balanceReservationCache := repository.NewBalanceReservationCache(redisClient)
+	balanceLedgerRepository := repository.NewBalanceLedgerRepository(db)
+	prepaidBalanceService := service.NewPrepaidBalanceService(configConfig, balanceReservationCache, billingCacheService, billingService, userRepository, balanceLedgerRepository)
 	geminiQuotaService := service.NewGeminiQuotaService(configConfig, settingRepository)
 	tempUnschedCache := repository.NewTempUnschedCache(redisClient)
 	timeoutCounterCache := repository.NewTimeoutCounterCache(redisClient)
 	openAI403CounterCache := repository.NewOpenAI403CounterCache(redisClient)
 	geminiTokenCache := repository.NewGeminiTokenCache(redisClient)
 	compositeTokenCacheInvalidator := service.NewCompositeTokenCacheInvalidator(geminiTokenCache)
-	rateLimitService := service.ProvideRateLimitService(accountRepository, usageLogRepository, configConfig, geminiQuotaService, tempUnschedCache, timeoutCounterCache, openAI403CounterCache, settingService, compositeTokenCacheInvalidator)
+	proxyLatencyCache := repository.NewProxyLatencyCache(redisClient)
+	rateLimitService := service.ProvideRateLimitService(accountRepository, usageLogRepository, configConfig, geminiQuotaService, tempUnschedCache, timeoutCounterCache, openAI403CounterCache, settingService, compositeTokenCacheInvalidator, proxyLatencyCache)
 	identityCache := repository.NewIdentityCache(redisClient)
 	identityService := service.NewIdentityService(identityCache)
 	httpUpstream := repository.NewHTTPUpstream(configConfig)
@@ -152,7 +157,9 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	grokOAuthClient := repository.NewGrokOAuthClient()
 	grokOAuthService := service.NewGrokOAuthService(proxyRepository, grokOAuthClient)
 	grokTokenProvider := service.ProvideGrokTokenProvider(accountRepository, geminiTokenCache, grokOAuthService, oAuthRefreshAPI, tempUnschedCache)
-	openAIGatewayService := service.NewOpenAIGatewayService(accountRepository, usageLogRepository, usageBillingRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, httpUpstream, deferredService, openAITokenProvider, grokTokenProvider, modelPricingResolver, channelService, balanceNotifyService, settingService, serviceUserPlatformQuotaRepository)
+	accountUsageWindowCache := repository.NewAccountUsageWindowCache(redisClient)
+	openAIBatchRepository := repository.NewOpenAIBatchRepository(db)
+	openAIGatewayService := service.ProvideOpenAIGatewayService(accountRepository, usageLogRepository, usageBillingRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, httpUpstream, deferredService, openAITokenProvider, grokTokenProvider, modelPricingResolver, channelService, balanceNotifyService, settingService, serviceUserPlatformQuotaRepository, accountUsageWindowCache, openAIBatchRepository)
 	geminiOAuthClient := repository.NewGeminiOAuthClient(configConfig)
 	geminiCliCodeAssistClient := repository.NewGeminiCliCodeAssistClient()
 	driveClient := repository.NewGeminiDriveClient()
@@ -164,7 +171,9 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	antigravityGatewayService := service.NewAntigravityGatewayService(accountRepository, gatewayCache, schedulerSnapshotService, antigravityTokenProvider, rateLimitService, httpUpstream, settingService, internal500CounterCache)
 	geminiMessagesCompatService := service.NewGeminiMessagesCompatService(accountRepository, groupRepository, gatewayCache, schedulerSnapshotService, geminiTokenProvider, rateLimitService, httpUpstream, antigravityGatewayService, configConfig)
 	opsSystemLogSink := service.ProvideOpsSystemLogSink(opsRepository)
-	opsService := service.ProvideOpsService(opsRepository, settingRepository, configConfig, accountRepository, userRepository, concurrencyService, gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, opsSystemLogSink, settingService)
+	opsUpstreamRequestIDRecorder := service.ProvideOpsUpstreamRequestIDRecorder(opsRepository, httpUpstream, configConfig)
+	opsStreamTimingRecorder := service.ProvideOpsStreamTimingRecorder(opsRepository, configConfig, gatewayService, openAIGatewayService)
+	opsService := service.ProvideOpsService(opsRepository, settingRepository, configConfig, accountRepository, userRepository, concurrencyService, gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, opsSystemLogSink, settingService, proxyRepository, proxyLatencyCache, usageLogRepository)
 	usageHandler := handler.NewUsageHandler(usageService, apiKeyService, opsService, settingService)
 	redeemHandler := handler.NewRedeemHandler(redeemService)
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
@@ -182,7 +191,6 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	dashboardAggregationService := service.ProvideDashboardAggregationService(dashboardAggregationRepository, timingWheelService, leaderLockCache, db, configConfig)
 	dashboardHandler := admin.NewDashboardHandler(dashboardService, dashboardAggregationService)
 	proxyExitInfoProber := repository.NewProxyExitInfoProber(configConfig)
-	proxyLatencyCache := repository.NewProxyLatencyCache(redisClient)
 	adminService := service.NewAdminService(userRepository, groupRepository, accountRepository, proxyRepository, apiKeyRepository, redeemCodeRepository, userGroupRateRepository, userRPMCache, billingCacheService, proxyExitInfoProber, proxyLatencyCache, apiKeyAuthCacheInvalidator, client, settingService, subscriptionService, userSubscriptionRepository, privacyClientFactory, openAIGatewayService)
 	adminUserHandler := admin.NewUserHandler(adminService, concurrencyService, serviceUserPlatformQuotaRepository, billingCache)
 	groupCapacityService := service.NewGroupCapacityService(accountRepository, groupRepository, concurrencyService, sessionLimitCache, rpmCache)
@@ -256,40 +264,106 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	paymentHandler := admin.NewPaymentHandler(paymentService, paymentConfigService)
 	affiliateHandler := admin.NewAffiliateHandler(affiliateService, adminService)
 	complianceHandler := admin.NewComplianceHandler(settingService)
-	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, backupHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, grokOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, systemHandler, adminSubscriptionHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, tlsFingerprintProfileHandler, adminAPIKeyHandler, scheduledTestHandler, channelHandler, channelMonitorHandler, channelMonitorRequestTemplateHandler, contentModerationHandler, paymentHandler, affiliateHandler, complianceHandler)
+	configSyncRepository := repository.NewConfigSyncRepository(db)
+	configSyncService := service.ProvideConfigSyncService(configSyncRepository, apiKeyAuthCacheInvalidator, configConfig)
+	configSyncHandler := admin.NewConfigSyncHandler(configSyncService)
+	apiKeyTraceCache := repository.NewAPIKeyTraceCache(redisClient)
+	apiKeyTraceService := service.ProvideAPIKeyTraceService(apiKeyTraceCache, apiKeyRepository, settingRepository, configConfig, opsService)
+	apiKeyTraceHandler := admin.NewAPIKeyTraceHandler(apiKeyTraceService)
+	billingStatementRepository := repository.NewBillingStatementRepository(db)
+	billingStatementService, err := service.ProvideBillingStatementService(configConfig, billingStatementRepository, userRepository, leaderLockCache, db)
+	if err != nil {
+		return nil, err
+	}
+	billingStatementHandler := admin.NewBillingStatementHandler(billingStatementService)
+	capacityForecastRepository := repository.NewCapacityForecastRepository(db)
+	capacityForecastService := service.NewCapacityForecastService(capacityForecastRepository, accountRepository)
+	capacityForecastHandler := admin.NewCapacityForecastHandler(capacityForecastService)
+	cacheEfficiencyRepository := repository.NewCacheEfficiencyRepository(db)
+	cacheEfficiencyService := service.NewCacheEfficiencyService(cacheEfficiencyRepository)
+	cacheEfficiencyHandler := admin.NewCacheEfficiencyHandler(cacheEfficiencyService)
+	clientAnalyticsRepository := repository.NewClientAnalyticsRepository(db)
+	clientAnalyticsService := service.NewClientAnalyticsService(clientAnalyticsRepository)
+	clientAnalyticsHandler := admin.NewClientAnalyticsHandler(clientAnalyticsService)
+	mappingSimulationRepository := repository.NewMappingSimulationRepository(db)
+	mappingSimulationService := service.NewMappingSimulationService(mappingSimulationRepository, accountRepository, groupRepository)
+	mappingSimulationHandler := admin.NewMappingSimulationHandler(mappingSimulationService)
+	staleAccountRepository := repository.NewStaleAccountRepository(db)
+	staleAccountService := service.ProvideStaleAccountService(staleAccountRepository, accountRepository, secretEncryptor, leaderLockCache, db, configConfig)
+	staleAccountHandler := admin.NewStaleAccountHandler(staleAccountService)
+	configVersionRepository := repository.NewConfigVersionRepository(db)
+	configVersionService := service.NewConfigVersionService(configVersionRepository, groupRepository, accountRepository, adminService, channelService, errorPassthroughService, apiKeyAuthCacheInvalidator)
+	configVersionHandler := admin.NewConfigVersionHandler(configVersionService)
+	graphQLHandler := admin.NewGraphQLHandler(adminService, usageService, dashboardService, opsService, backupService)
+	debugHandler := admin.NewDebugHandler(configConfig)
+	transformFixtureRepository := repository.NewTransformFixtureRepository(db)
+	transformFixtureService := service.NewTransformFixtureService(transformFixtureRepository, accountRepository, gatewayService, openAIGatewayService)
+	transformFixtureHandler := admin.NewTransformFixtureHandler(transformFixtureService)
+	spendBudgetRepository := repository.NewSpendBudgetRepository(db)
+	spendBudgetService := service.ProvideSpendBudgetService(spendBudgetRepository, billingCacheService)
+	spendBudgetHandler := admin.NewSpendBudgetHandler(spendBudgetService)
+	modelPriceRepository := repository.NewModelPriceRepository(db)
+	modelPriceService := service.ProvideModelPriceService(modelPriceRepository, billingService)
+	modelPriceHandler := admin.NewModelPriceHandler(modelPriceService)
+	usageReportRepository := repository.NewUsageReportRepository(db)
+	usageReportService := service.NewUsageReportService(usageReportRepository)
+	usageReportHandler := admin.NewUsageReportHandler(usageReportService)
+	dataExportRepository := repository.NewDataExportRepository(db)
+	dataExportService := service.NewDataExportService(dataExportRepository)
+	dataExportHandler := admin.NewDataExportHandler(dataExportService)
+	requestClassSLORepository := repository.NewRequestClassSLORepository(db)
+	requestClassSLOService := service.NewRequestClassSLOService(requestClassSLORepository, configConfig)
+	requestClassSLOHandler := admin.NewRequestClassSLOHandler(requestClassSLOService)
+	accountLeaseRepository := repository.NewAccountLeaseRepository(db)
+	accountLeaseService := service.ProvideAccountLeaseService(accountLeaseRepository)
+	accountLeaseHandler := admin.NewAccountLeaseHandler(accountLeaseService)
+	adminServiceTokenRepository := repository.NewAdminServiceTokenRepository(db)
+	adminServiceTokenService := service.NewAdminServiceTokenService(adminServiceTokenRepository)
+	serviceTokenHandler := admin.NewServiceTokenHandler(adminServiceTokenService)
+	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, backupHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, grokOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, systemHandler, adminSubscriptionHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, tlsFingerprintProfileHandler, adminAPIKeyHandler, scheduledTestHandler, channelHandler, channelMonitorHandler, channelMonitorRequestTemplateHandler, contentModerationHandler, paymentHandler, affiliateHandler, complianceHandler, configSyncHandler, apiKeyTraceHandler, billingStatementHandler, capacityForecastHandler, cacheEfficiencyHandler, clientAnalyticsHandler, mappingSimulationHandler, staleAccountHandler, configVersionHandler, graphQLHandler, debugHandler, transformFixtureHandler, spendBudgetHandler, modelPriceHandler, usageReportHandler, dataExportHandler, requestClassSLOHandler, serviceTokenHandler, accountLeaseHandler)
 	usageRecordWorkerPool := service.NewUsageRecordWorkerPool(configConfig)
 	userMsgQueueCache := repository.NewUserMsgQueueCache(redisClient)
 	userMessageQueueService := service.ProvideUserMessageQueueService(userMsgQueueCache, rpmCache, configConfig)
-	gatewayHandler := handler.NewGatewayHandler(gatewayService, geminiMessagesCompatService, antigravityGatewayService, userService, concurrencyService, billingCacheService, usageService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, userMessageQueueService, configConfig, settingService)
-	openAIGatewayHandler := handler.NewOpenAIGatewayHandler(openAIGatewayService, concurrencyService, billingCacheService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, opsService, configConfig)
+	gatewayHandler := handler.NewGatewayHandler(gatewayService, geminiMessagesCompatService, antigravityGatewayService, userService, concurrencyService, billingCacheService, prepaidBalanceService, usageService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, userMessageQueueService, configConfig, settingService)
+	openAIGatewayHandler := handler.NewOpenAIGatewayHandler(openAIGatewayService, concurrencyService, billingCacheService, prepaidBalanceService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, opsService, configConfig)
 	handlerSettingHandler := handler.ProvideSettingHandler(settingService, buildInfo, notificationEmailService)
 	totpHandler := handler.NewTotpHandler(totpService)
 	handlerPaymentHandler := handler.NewPaymentHandler(paymentService, paymentConfigService, channelService)
 	paymentWebhookHandler := handler.NewPaymentWebhookHandler(paymentService, registry)
 	availableChannelHandler := handler.NewAvailableChannelHandler(channelService, apiKeyService, settingService)
 	batchImageHandler := handler.NewBatchImageHandler(batchImagePublicService, batchImageDownloadService, batchImageCleanupService)
+	userUsageAlertRepository := repository.NewUserUsageAlertRepository(db)
+	userUsageAlertService := service.ProvideUserUsageAlertService(userUsageAlertRepository, userRepository, apiKeyRepository, settingRepository, emailService, leaderLockCache, db)
+	userUsageAlertHandler := handler.NewUserUsageAlertHandler(userUsageAlertService)
+	handlerBillingStatementHandler := handler.NewBillingStatementHandler(billingStatementService)
+	balanceHandler := handler.NewBalanceHandler(prepaidBalanceService)
 	idempotencyCoordinator := service.ProvideIdempotencyCoordinator(idempotencyRepository, configConfig)
 	idempotencyCleanupService := service.ProvideIdempotencyCleanupService(idempotencyRepository, configConfig)
-	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, channelMonitorUserHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, handlerSettingHandler, totpHandler, handlerPaymentHandler, paymentWebhookHandler, availableChannelHandler, batchImageHandler, idempotencyCoordinator, idempotencyCleanupService)
+	apiKeyProjectService := service.NewAPIKeyProjectService(apiKeyProjectRepository, apiKeyService)
+	apiKeyProjectHandler := handler.NewAPIKeyProjectHandler(apiKeyProjectService)
+	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, channelMonitorUserHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, handlerSettingHandler, totpHandler, handlerPaymentHandler, paymentWebhookHandler, availableChannelHandler, batchImageHandler, userUsageAlertHandler, handlerBillingStatementHandler, balanceHandler, apiKeyProjectHandler, idempotencyCoordinator, idempotencyCleanupService)
 	jwtAuthMiddleware := middleware.NewJWTAuthMiddleware(authService, userService)
-	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(authService, userService, settingService)
+	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(authService, userService, settingService, adminServiceTokenService)
 	apiKeyAuthMiddleware := middleware.NewAPIKeyAuthMiddleware(apiKeyService, subscriptionService, configConfig)
-	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, apiKeyService, subscriptionService, opsService, settingService, redisClient)
+	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, apiKeyService, subscriptionService, opsService, apiKeyTraceService, settingService, redisClient)
 	httpServer := server.ProvideHTTPServer(configConfig, engine)
 	opsMetricsCollector := service.ProvideOpsMetricsCollector(opsRepository, settingRepository, accountRepository, concurrencyService, db, redisClient, configConfig)
 	opsAggregationService := service.ProvideOpsAggregationService(opsRepository, settingRepository, db, redisClient, configConfig)
 	opsAlertEvaluatorService := service.ProvideOpsAlertEvaluatorService(opsService, opsRepository, emailService, redisClient, configConfig, proxyRepository)
 	opsCleanupService := service.ProvideOpsCleanupService(opsRepository, db, redisClient, configConfig, channelMonitorService, settingRepository, opsService)
 	opsScheduledReportService := service.ProvideOpsScheduledReportService(opsService, userService, emailService, redisClient, configConfig)
+	opsWatchdogService := service.ProvideOpsWatchdogService(opsService, emailService, configConfig)
+	opsSchemaDriftDetector := service.ProvideOpsSchemaDriftDetector(opsService, httpUpstream, configConfig)
 	tokenRefreshService := service.ProvideTokenRefreshService(accountRepository, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, grokOAuthService, compositeTokenCacheInvalidator, schedulerCache, configConfig, tempUnschedCache, privacyClientFactory, proxyRepository, oAuthRefreshAPI, openAIGatewayService)
 	accountExpiryService := service.ProvideAccountExpiryService(accountRepository)
 	proxyExpiryService := service.ProvideProxyExpiryService(proxyRepository)
+	guestAPIKeyCleanupService := service.ProvideGuestAPIKeyCleanupService(apiKeyService)
 	subscriptionExpiryService := service.ProvideSubscriptionExpiryService(userSubscriptionRepository, settingRepository, notificationEmailService, leaderLockCache, db)
 	scheduledTestRunnerService := service.ProvideScheduledTestRunnerService(scheduledTestPlanRepository, scheduledTestService, accountTestService, rateLimitService, configConfig)
 	paymentOrderExpiryService := service.ProvidePaymentOrderExpiryService(paymentService, leaderLockCache, db)
 	channelMonitorRunner := service.ProvideChannelMonitorRunner(channelMonitorService, settingService)
 	userPlatformQuotaUsageFlusher := service.ProvideUserPlatformQuotaUsageFlusher(configConfig, billingCache, serviceUserPlatformQuotaRepository, timingWheelService)
-	v := provideCleanup(client, redisClient, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsSystemLogSink, schedulerSnapshotService, tokenRefreshService, accountExpiryService, proxyExpiryService, subscriptionExpiryService, usageCleanupService, idempotencyCleanupService, batchImageCleanupService, batchImageWorkerRuntime, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, grokOAuthService, openAIGatewayService, scheduledTestRunnerService, backupService, paymentOrderExpiryService, channelMonitorRunner, userPlatformQuotaUsageFlusher)
+	v := provideCleanup(client, redisClient, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsSystemLogSink, opsUpstreamRequestIDRecorder, opsStreamTimingRecorder, opsWatchdogService, opsSchemaDriftDetector, schedulerSnapshotService, tokenRefreshService, accountExpiryService, staleAccountService, accountLeaseService, proxyExpiryService, guestAPIKeyCleanupService, subscriptionExpiryService, usageCleanupService, idempotencyCleanupService, configSyncService, batchImageCleanupService, batchImageWorkerRuntime, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, grokOAuthService, openAIGatewayService, scheduledTestRunnerService, backupService, paymentOrderExpiryService, channelMonitorRunner, userPlatformQuotaUsageFlusher, userUsageAlertService, billingStatementService)
 	application := &Application{
 		Server:  httpServer,
 		Cleanup: v,
@@ -324,13 +398,21 @@ func provideCleanup(
 	opsCleanup *service.OpsCleanupService,
 	opsScheduledReport *service.OpsScheduledReportService,
 	opsSystemLogSink *service.OpsSystemLogSink,
+	opsUpstreamRequestIDRecorder *service.OpsUpstreamRequestIDRecorder,
+	opsStreamTimingRecorder *service.OpsStreamTimingRecorder,
+	opsWatchdog *service.OpsWatchdogService,
+	opsSchemaDrift *service.OpsSchemaDriftDetector,
 	schedulerSnapshot *service.SchedulerSnapshotService,
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
+	staleAccount *service.StaleAccountService,
+	accountLease *service.AccountLeaseService,
 	proxyExpiry *service.ProxyExpiryService,
+	guestAPIKeyCleanup *service.GuestAPIKeyCleanupService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
+	configSync *service.ConfigSyncService,
 	batchImageCleanup *service.BatchImageCleanupService,
 	batchImageWorker *service.BatchImageWorkerRuntime,
 	pricing *service.PricingService,
@@ -349,6 +431,8 @@ func provideCleanup(
 	paymentOrderExpiry *service.PaymentOrderExpiryService,
 	channelMonitorRunner *service.ChannelMonitorRunner,
 	quotaFlusher *service.UserPlatformQuotaUsageFlusher,
+	usageAlert *service.UserUsageAlertService,
+	billingStatement *service.BillingStatementService,
 ) func() {
 	return func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -378,6 +462,30 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"OpsUpstreamRequestIDRecorder", func() error {
+				if opsUpstreamRequestIDRecorder != nil {
+					opsUpstreamRequestIDRecorder.Stop()
+				}
+				return nil
+			}},
+			{"OpsStreamTimingRecorder", func() error {
+				if opsStreamTimingRecorder != nil {
+					opsStreamTimingRecorder.Stop()
+				}
+				return nil
+			}},
+			{"OpsWatchdogService", func() error {
+				if opsWatchdog != nil {
+					opsWatchdog.Stop()
+				}
+				return nil
+			}},
+			{"OpsSchemaDriftDetector", func() error {
+				if opsSchemaDrift != nil {
+					opsSchemaDrift.Stop()
+				}
+				return nil
+			}},
 			{"OpsAlertEvaluatorService", func() error {
 				if opsAlertEvaluator != nil {
 					opsAlertEvaluator.Stop()
@@ -414,6 +522,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"ConfigSyncService", func() error {
+				if configSync != nil {
+					configSync.Stop()
+				}
+				return nil
+			}},
 			{"BatchImageCleanupService", func() error {
 				if batchImageCleanup != nil {
 					batchImageCleanup.Stop()
@@ -434,10 +548,22 @@ func provideCleanup(
 				accountExpiry.Stop()
 				return nil
 			}},
+			{"StaleAccountService", func() error {
+				staleAccount.Stop()
+				return nil
+			}},
+			{"AccountLeaseService", func() error {
+				accountLease.Stop()
+				return nil
+			}},
 			{"ProxyExpiryService", func() error {
 				proxyExpiry.Stop()
 				return nil
 			}},
+			{"GuestAPIKeyCleanupService", func() error {
+				guestAPIKeyCleanup.Stop()
+				return nil
+			}},
 			{"SubscriptionExpiryService", func() error {
 				subscriptionExpiry.Stop()
 				return nil
@@ -524,6 +650,18 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"UserUsageAlertService", func() error {
+				if usageAlert != nil {
+					usageAlert.Stop()
+				}
+				return nil
+			}},
+			{"BillingStatementService", func() error {
+				if billingStatement != nil {
+					billingStatement.Stop()
+				}
+				return nil
+			}},
 		}
 
 		infraSteps := []cleanupStep{
@@ -46,6 +46,7 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 	emailQueueSvc := service.NewEmailQueueService(nil, 1)
 	billingCacheSvc := service.NewBillingCacheService(nil, nil, nil, nil, nil, nil, cfg, nil)
 	idempotencyCleanupSvc := service.NewIdempotencyCleanupService(nil, cfg)
+	configSyncSvc := service.NewConfigSyncService(nil, nil, cfg)
 	schedulerSnapshotSvc := service.NewSchedulerSnapshotService(nil, nil, nil, nil, cfg)
 	opsSystemLogSinkSvc := service.NewOpsSystemLogSink(nil)
 
@@ -58,13 +59,21 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		&service.OpsCleanupService{},
 		&service.OpsScheduledReportService{},
 		opsSystemLogSinkSvc,
+		service.NewOpsUpstreamRequestIDRecorder(nil),
+		service.NewOpsStreamTimingRecorder(nil, 0),
+		service.NewOpsWatchdogService(nil, nil, config.OpsWatchdogConfig{}),
+		service.NewOpsSchemaDriftDetector(config.OpsSchemaDriftConfig{}),
 		schedulerSnapshotSvc,
 		tokenRefreshSvc,
 		accountExpirySvc,
+		nil, // staleAccount
+		nil, // accountLease
 		proxyExpirySvc,
+		nil, // guestAPIKeyCleanup
 		subscriptionExpirySvc,
 		&service.UsageCleanupService{},
 		idempotencyCleanupSvc,
+		configSyncSvc,
 		&service.BatchImageCleanupService{},
 		nil, // batchImageWorker
 		pricingSvc,
@@ -83,6 +92,8 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		nil, // paymentOrderExpiry
 		nil, // channelMonitorRunner
 		nil, // quotaFlusher
+		nil, // usageAlert
+		nil, // billingStatement
 	)
 
 	require.NotPanics(t, func() {
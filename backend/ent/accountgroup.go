@@ -23,6 +23,10 @@ type AccountGroup struct {
 	GroupID int64 `json:"group_id,omitempty"`
 	// Priority holds the value of the "priority" field.
 	Priority int `json:"priority,omitempty"`
+	// Tier holds the value of the "tier" field.
+	Tier int `json:"tier,omitempty"`
+	// ConcurrencyShare holds the value of the "concurrency_share" field.
+	ConcurrencyShare int `json:"concurrency_share,omitempty"`
 	// CreatedAt holds the value of the "created_at" field.
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
@@ -69,7 +73,7 @@ func (*AccountGroup) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case accountgroup.FieldAccountID, accountgroup.FieldGroupID, accountgroup.FieldPriority:
+		case accountgroup.FieldAccountID, accountgroup.FieldGroupID, accountgroup.FieldPriority, accountgroup.FieldTier, accountgroup.FieldConcurrencyShare:
 			values[i] = new(sql.NullInt64)
 		case accountgroup.FieldCreatedAt:
 			values[i] = new(sql.NullTime)
@@ -106,6 +110,18 @@ func (_m *AccountGroup) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.Priority = int(value.Int64)
 			}
+		case accountgroup.FieldTier:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field tier", values[i])
+			} else if value.Valid {
+				_m.Tier = int(value.Int64)
+			}
+		case accountgroup.FieldConcurrencyShare:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field concurrency_share", values[i])
+			} else if value.Valid {
+				_m.ConcurrencyShare = int(value.Int64)
+			}
 		case accountgroup.FieldCreatedAt:
 			if value, ok := values[i].(*sql.NullTime); !ok {
 				return fmt.Errorf("unexpected type %T for field created_at", values[i])
@@ -166,6 +182,12 @@ func (_m *AccountGroup) String() string {
 	builder.WriteString("priority=")
 	builder.WriteString(fmt.Sprintf("%v", _m.Priority))
 	builder.WriteString(", ")
+	builder.WriteString("tier=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Tier))
+	builder.WriteString(", ")
+	builder.WriteString("concurrency_share=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ConcurrencyShare))
+	builder.WriteString(", ")
 	builder.WriteString("created_at=")
 	builder.WriteString(_m.CreatedAt.Format(time.ANSIC))
 	builder.WriteByte(')')
@@ -18,6 +18,10 @@ const (
 	FieldGroupID = "group_id"
 	// FieldPriority holds the string denoting the priority field in the database.
 	FieldPriority = "priority"
+	// FieldTier holds the string denoting the tier field in the database.
+	FieldTier = "tier"
+	// FieldConcurrencyShare holds the string denoting the concurrency_share field in the database.
+	FieldConcurrencyShare = "concurrency_share"
 	// FieldCreatedAt holds the string denoting the created_at field in the database.
 	FieldCreatedAt = "created_at"
 	// EdgeAccount holds the string denoting the account edge name in mutations.
@@ -51,6 +55,8 @@ var Columns = []string{
 	FieldAccountID,
 	FieldGroupID,
 	FieldPriority,
+	FieldTier,
+	FieldConcurrencyShare,
 	FieldCreatedAt,
 }
 
@@ -67,6 +73,10 @@ func ValidColumn(column string) bool {
 var (
 	// DefaultPriority holds the default value on creation for the "priority" field.
 	DefaultPriority int
+	// DefaultTier holds the default value on creation for the "tier" field.
+	DefaultTier int
+	// DefaultConcurrencyShare holds the default value on creation for the "concurrency_share" field.
+	DefaultConcurrencyShare int
 	// DefaultCreatedAt holds the default value on creation for the "created_at" field.
 	DefaultCreatedAt func() time.Time
 )
@@ -89,6 +99,16 @@ func ByPriority(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldPriority, opts...).ToFunc()
 }
 
+// ByTier orders the results by the tier field.
+func ByTier(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTier, opts...).ToFunc()
+}
+
+// ByConcurrencyShare orders the results by the concurrency_share field.
+func ByConcurrencyShare(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldConcurrencyShare, opts...).ToFunc()
+}
+
 // ByCreatedAt orders the results by the created_at field.
 func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
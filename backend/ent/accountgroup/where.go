@@ -25,6 +25,16 @@ func Priority(v int) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldEQ(FieldPriority, v))
 }
 
+// Tier applies equality check predicate on the "tier" field. It's identical to TierEQ.
+func Tier(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldTier, v))
+}
+
+// ConcurrencyShare applies equality check predicate on the "concurrency_share" field. It's identical to ConcurrencyShareEQ.
+func ConcurrencyShare(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldConcurrencyShare, v))
+}
+
 // CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
 func CreatedAt(v time.Time) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldEQ(FieldCreatedAt, v))
@@ -110,6 +120,86 @@ func PriorityLTE(v int) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldLTE(FieldPriority, v))
 }
 
+// TierEQ applies the EQ predicate on the "tier" field.
+func TierEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldTier, v))
+}
+
+// TierNEQ applies the NEQ predicate on the "tier" field.
+func TierNEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNEQ(FieldTier, v))
+}
+
+// TierIn applies the In predicate on the "tier" field.
+func TierIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldIn(FieldTier, vs...))
+}
+
+// TierNotIn applies the NotIn predicate on the "tier" field.
+func TierNotIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNotIn(FieldTier, vs...))
+}
+
+// TierGT applies the GT predicate on the "tier" field.
+func TierGT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGT(FieldTier, v))
+}
+
+// TierGTE applies the GTE predicate on the "tier" field.
+func TierGTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGTE(FieldTier, v))
+}
+
+// TierLT applies the LT predicate on the "tier" field.
+func TierLT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLT(FieldTier, v))
+}
+
+// TierLTE applies the LTE predicate on the "tier" field.
+func TierLTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLTE(FieldTier, v))
+}
+
+// ConcurrencyShareEQ applies the EQ predicate on the "concurrency_share" field.
+func ConcurrencyShareEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldConcurrencyShare, v))
+}
+
+// ConcurrencyShareNEQ applies the NEQ predicate on the "concurrency_share" field.
+func ConcurrencyShareNEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNEQ(FieldConcurrencyShare, v))
+}
+
+// ConcurrencyShareIn applies the In predicate on the "concurrency_share" field.
+func ConcurrencyShareIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldIn(FieldConcurrencyShare, vs...))
+}
+
+// ConcurrencyShareNotIn applies the NotIn predicate on the "concurrency_share" field.
+func ConcurrencyShareNotIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNotIn(FieldConcurrencyShare, vs...))
+}
+
+// ConcurrencyShareGT applies the GT predicate on the "concurrency_share" field.
+func ConcurrencyShareGT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGT(FieldConcurrencyShare, v))
+}
+
+// ConcurrencyShareGTE applies the GTE predicate on the "concurrency_share" field.
+func ConcurrencyShareGTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGTE(FieldConcurrencyShare, v))
+}
+
+// ConcurrencyShareLT applies the LT predicate on the "concurrency_share" field.
+func ConcurrencyShareLT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLT(FieldConcurrencyShare, v))
+}
+
+// ConcurrencyShareLTE applies the LTE predicate on the "concurrency_share" field.
+func ConcurrencyShareLTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLTE(FieldConcurrencyShare, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldEQ(FieldCreatedAt, v))
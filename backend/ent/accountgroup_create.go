@@ -50,6 +50,34 @@ func (_c *AccountGroupCreate) SetNillablePriority(v *int) *AccountGroupCreate {
 	return _c
 }
 
+// SetTier sets the "tier" field.
+func (_c *AccountGroupCreate) SetTier(v int) *AccountGroupCreate {
+	_c.mutation.SetTier(v)
+	return _c
+}
+
+// SetNillableTier sets the "tier" field if the given value is not nil.
+func (_c *AccountGroupCreate) SetNillableTier(v *int) *AccountGroupCreate {
+	if v != nil {
+		_c.SetTier(*v)
+	}
+	return _c
+}
+
+// SetConcurrencyShare sets the "concurrency_share" field.
+func (_c *AccountGroupCreate) SetConcurrencyShare(v int) *AccountGroupCreate {
+	_c.mutation.SetConcurrencyShare(v)
+	return _c
+}
+
+// SetNillableConcurrencyShare sets the "concurrency_share" field if the given value is not nil.
+func (_c *AccountGroupCreate) SetNillableConcurrencyShare(v *int) *AccountGroupCreate {
+	if v != nil {
+		_c.SetConcurrencyShare(*v)
+	}
+	return _c
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (_c *AccountGroupCreate) SetCreatedAt(v time.Time) *AccountGroupCreate {
 	_c.mutation.SetCreatedAt(v)
@@ -113,6 +141,14 @@ func (_c *AccountGroupCreate) defaults() {
 		v := accountgroup.DefaultPriority
 		_c.mutation.SetPriority(v)
 	}
+	if _, ok := _c.mutation.Tier(); !ok {
+		v := accountgroup.DefaultTier
+		_c.mutation.SetTier(v)
+	}
+	if _, ok := _c.mutation.ConcurrencyShare(); !ok {
+		v := accountgroup.DefaultConcurrencyShare
+		_c.mutation.SetConcurrencyShare(v)
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		v := accountgroup.DefaultCreatedAt()
 		_c.mutation.SetCreatedAt(v)
@@ -130,6 +166,12 @@ func (_c *AccountGroupCreate) check() error {
 	if _, ok := _c.mutation.Priority(); !ok {
 		return &ValidationError{Name: "priority", err: errors.New(`ent: missing required field "AccountGroup.priority"`)}
 	}
+	if _, ok := _c.mutation.Tier(); !ok {
+		return &ValidationError{Name: "tier", err: errors.New(`ent: missing required field "AccountGroup.tier"`)}
+	}
+	if _, ok := _c.mutation.ConcurrencyShare(); !ok {
+		return &ValidationError{Name: "concurrency_share", err: errors.New(`ent: missing required field "AccountGroup.concurrency_share"`)}
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "AccountGroup.created_at"`)}
 	}
@@ -166,6 +208,14 @@ func (_c *AccountGroupCreate) createSpec() (*AccountGroup, *sqlgraph.CreateSpec)
 		_spec.SetField(accountgroup.FieldPriority, field.TypeInt, value)
 		_node.Priority = value
 	}
+	if value, ok := _c.mutation.Tier(); ok {
+		_spec.SetField(accountgroup.FieldTier, field.TypeInt, value)
+		_node.Tier = value
+	}
+	if value, ok := _c.mutation.ConcurrencyShare(); ok {
+		_spec.SetField(accountgroup.FieldConcurrencyShare, field.TypeInt, value)
+		_node.ConcurrencyShare = value
+	}
 	if value, ok := _c.mutation.CreatedAt(); ok {
 		_spec.SetField(accountgroup.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
@@ -298,6 +348,42 @@ func (u *AccountGroupUpsert) AddPriority(v int) *AccountGroupUpsert {
 	return u
 }
 
+// SetTier sets the "tier" field.
+func (u *AccountGroupUpsert) SetTier(v int) *AccountGroupUpsert {
+	u.Set(accountgroup.FieldTier, v)
+	return u
+}
+
+// UpdateTier sets the "tier" field to the value that was provided on create.
+func (u *AccountGroupUpsert) UpdateTier() *AccountGroupUpsert {
+	u.SetExcluded(accountgroup.FieldTier)
+	return u
+}
+
+// AddTier adds v to the "tier" field.
+func (u *AccountGroupUpsert) AddTier(v int) *AccountGroupUpsert {
+	u.Add(accountgroup.FieldTier, v)
+	return u
+}
+
+// SetConcurrencyShare sets the "concurrency_share" field.
+func (u *AccountGroupUpsert) SetConcurrencyShare(v int) *AccountGroupUpsert {
+	u.Set(accountgroup.FieldConcurrencyShare, v)
+	return u
+}
+
+// UpdateConcurrencyShare sets the "concurrency_share" field to the value that was provided on create.
+func (u *AccountGroupUpsert) UpdateConcurrencyShare() *AccountGroupUpsert {
+	u.SetExcluded(accountgroup.FieldConcurrencyShare)
+	return u
+}
+
+// AddConcurrencyShare adds v to the "concurrency_share" field.
+func (u *AccountGroupUpsert) AddConcurrencyShare(v int) *AccountGroupUpsert {
+	u.Add(accountgroup.FieldConcurrencyShare, v)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -392,6 +478,48 @@ func (u *AccountGroupUpsertOne) UpdatePriority() *AccountGroupUpsertOne {
 	})
 }
 
+// SetTier sets the "tier" field.
+func (u *AccountGroupUpsertOne) SetTier(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetTier(v)
+	})
+}
+
+// AddTier adds v to the "tier" field.
+func (u *AccountGroupUpsertOne) AddTier(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddTier(v)
+	})
+}
+
+// UpdateTier sets the "tier" field to the value that was provided on create.
+func (u *AccountGroupUpsertOne) UpdateTier() *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateTier()
+	})
+}
+
+// SetConcurrencyShare sets the "concurrency_share" field.
+func (u *AccountGroupUpsertOne) SetConcurrencyShare(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetConcurrencyShare(v)
+	})
+}
+
+// AddConcurrencyShare adds v to the "concurrency_share" field.
+func (u *AccountGroupUpsertOne) AddConcurrencyShare(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddConcurrencyShare(v)
+	})
+}
+
+// UpdateConcurrencyShare sets the "concurrency_share" field to the value that was provided on create.
+func (u *AccountGroupUpsertOne) UpdateConcurrencyShare() *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateConcurrencyShare()
+	})
+}
+
 // Exec executes the query.
 func (u *AccountGroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -629,6 +757,48 @@ func (u *AccountGroupUpsertBulk) UpdatePriority() *AccountGroupUpsertBulk {
 	})
 }
 
+// SetTier sets the "tier" field.
+func (u *AccountGroupUpsertBulk) SetTier(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetTier(v)
+	})
+}
+
+// AddTier adds v to the "tier" field.
+func (u *AccountGroupUpsertBulk) AddTier(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddTier(v)
+	})
+}
+
+// UpdateTier sets the "tier" field to the value that was provided on create.
+func (u *AccountGroupUpsertBulk) UpdateTier() *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateTier()
+	})
+}
+
+// SetConcurrencyShare sets the "concurrency_share" field.
+func (u *AccountGroupUpsertBulk) SetConcurrencyShare(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetConcurrencyShare(v)
+	})
+}
+
+// AddConcurrencyShare adds v to the "concurrency_share" field.
+func (u *AccountGroupUpsertBulk) AddConcurrencyShare(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddConcurrencyShare(v)
+	})
+}
+
+// UpdateConcurrencyShare sets the "concurrency_share" field to the value that was provided on create.
+func (u *AccountGroupUpsertBulk) UpdateConcurrencyShare() *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateConcurrencyShare()
+	})
+}
+
 // Exec executes the query.
 func (u *AccountGroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -78,6 +78,48 @@ func (_u *AccountGroupUpdate) AddPriority(v int) *AccountGroupUpdate {
 	return _u
 }
 
+// SetTier sets the "tier" field.
+func (_u *AccountGroupUpdate) SetTier(v int) *AccountGroupUpdate {
+	_u.mutation.ResetTier()
+	_u.mutation.SetTier(v)
+	return _u
+}
+
+// SetNillableTier sets the "tier" field if the given value is not nil.
+func (_u *AccountGroupUpdate) SetNillableTier(v *int) *AccountGroupUpdate {
+	if v != nil {
+		_u.SetTier(*v)
+	}
+	return _u
+}
+
+// AddTier adds value to the "tier" field.
+func (_u *AccountGroupUpdate) AddTier(v int) *AccountGroupUpdate {
+	_u.mutation.AddTier(v)
+	return _u
+}
+
+// SetConcurrencyShare sets the "concurrency_share" field.
+func (_u *AccountGroupUpdate) SetConcurrencyShare(v int) *AccountGroupUpdate {
+	_u.mutation.ResetConcurrencyShare()
+	_u.mutation.SetConcurrencyShare(v)
+	return _u
+}
+
+// SetNillableConcurrencyShare sets the "concurrency_share" field if the given value is not nil.
+func (_u *AccountGroupUpdate) SetNillableConcurrencyShare(v *int) *AccountGroupUpdate {
+	if v != nil {
+		_u.SetConcurrencyShare(*v)
+	}
+	return _u
+}
+
+// AddConcurrencyShare adds value to the "concurrency_share" field.
+func (_u *AccountGroupUpdate) AddConcurrencyShare(v int) *AccountGroupUpdate {
+	_u.mutation.AddConcurrencyShare(v)
+	return _u
+}
+
 // SetAccount sets the "account" edge to the Account entity.
 func (_u *AccountGroupUpdate) SetAccount(v *Account) *AccountGroupUpdate {
 	return _u.SetAccountID(v.ID)
@@ -161,6 +203,18 @@ func (_u *AccountGroupUpdate) sqlSave(ctx context.Context) (_node int, err error
 	if value, ok := _u.mutation.AddedPriority(); ok {
 		_spec.AddField(accountgroup.FieldPriority, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.Tier(); ok {
+		_spec.SetField(accountgroup.FieldTier, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTier(); ok {
+		_spec.AddField(accountgroup.FieldTier, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ConcurrencyShare(); ok {
+		_spec.SetField(accountgroup.FieldConcurrencyShare, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedConcurrencyShare(); ok {
+		_spec.AddField(accountgroup.FieldConcurrencyShare, field.TypeInt, value)
+	}
 	if _u.mutation.AccountCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -288,6 +342,48 @@ func (_u *AccountGroupUpdateOne) AddPriority(v int) *AccountGroupUpdateOne {
 	return _u
 }
 
+// SetTier sets the "tier" field.
+func (_u *AccountGroupUpdateOne) SetTier(v int) *AccountGroupUpdateOne {
+	_u.mutation.ResetTier()
+	_u.mutation.SetTier(v)
+	return _u
+}
+
+// SetNillableTier sets the "tier" field if the given value is not nil.
+func (_u *AccountGroupUpdateOne) SetNillableTier(v *int) *AccountGroupUpdateOne {
+	if v != nil {
+		_u.SetTier(*v)
+	}
+	return _u
+}
+
+// AddTier adds value to the "tier" field.
+func (_u *AccountGroupUpdateOne) AddTier(v int) *AccountGroupUpdateOne {
+	_u.mutation.AddTier(v)
+	return _u
+}
+
+// SetConcurrencyShare sets the "concurrency_share" field.
+func (_u *AccountGroupUpdateOne) SetConcurrencyShare(v int) *AccountGroupUpdateOne {
+	_u.mutation.ResetConcurrencyShare()
+	_u.mutation.SetConcurrencyShare(v)
+	return _u
+}
+
+// SetNillableConcurrencyShare sets the "concurrency_share" field if the given value is not nil.
+func (_u *AccountGroupUpdateOne) SetNillableConcurrencyShare(v *int) *AccountGroupUpdateOne {
+	if v != nil {
+		_u.SetConcurrencyShare(*v)
+	}
+	return _u
+}
+
+// AddConcurrencyShare adds value to the "concurrency_share" field.
+func (_u *AccountGroupUpdateOne) AddConcurrencyShare(v int) *AccountGroupUpdateOne {
+	_u.mutation.AddConcurrencyShare(v)
+	return _u
+}
+
 // SetAccount sets the "account" edge to the Account entity.
 func (_u *AccountGroupUpdateOne) SetAccount(v *Account) *AccountGroupUpdateOne {
 	return _u.SetAccountID(v.ID)
@@ -403,6 +499,18 @@ func (_u *AccountGroupUpdateOne) sqlSave(ctx context.Context) (_node *AccountGro
 	if value, ok := _u.mutation.AddedPriority(); ok {
 		_spec.AddField(accountgroup.FieldPriority, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.Tier(); ok {
+		_spec.SetField(accountgroup.FieldTier, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTier(); ok {
+		_spec.AddField(accountgroup.FieldTier, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ConcurrencyShare(); ok {
+		_spec.SetField(accountgroup.FieldConcurrencyShare, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedConcurrencyShare(); ok {
+		_spec.AddField(accountgroup.FieldConcurrencyShare, field.TypeInt, value)
+	}
 	if _u.mutation.AccountCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -66,6 +66,22 @@ type APIKey struct {
 	Window1dStart *time.Time `json:"window_1d_start,omitempty"`
 	// Start time of the current 7d rate limit window
 	Window7dStart *time.Time `json:"window_7d_start,omitempty"`
+	// Requests per minute for this API key (0 = unlimited)
+	RpmLimit int `json:"rpm_limit,omitempty"`
+	// Tokens per minute for this API key, input + output + cache (0 = unlimited)
+	TpmLimit int64 `json:"tpm_limit,omitempty"`
+	// Critical keys may consume the group's reserved account concurrency
+	Critical bool `json:"critical,omitempty"`
+	// Temporary guest key, deleted by the cleanup job after expires_at
+	Guest bool `json:"guest,omitempty"`
+	// Allowed request models, supports trailing * wildcard (empty = unrestricted)
+	AllowedModels []string `json:"allowed_models,omitempty"`
+	// Total token cap for this API key (0 = unlimited)
+	TokenLimit int64 `json:"token_limit,omitempty"`
+	// Total tokens consumed (input + output + cache)
+	TokensUsed int64 `json:"tokens_used,omitempty"`
+	// API key project ID (api_key_projects), nil = not in a project
+	ProjectID *int64 `json:"project_id,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the APIKeyQuery when eager-loading is set.
 	Edges        APIKeyEdges `json:"edges"`
@@ -121,11 +137,13 @@ func (*APIKey) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist:
+		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist, apikey.FieldAllowedModels:
 			values[i] = new([]byte)
+		case apikey.FieldCritical, apikey.FieldGuest:
+			values[i] = new(sql.NullBool)
 		case apikey.FieldQuota, apikey.FieldQuotaUsed, apikey.FieldRateLimit5h, apikey.FieldRateLimit1d, apikey.FieldRateLimit7d, apikey.FieldUsage5h, apikey.FieldUsage1d, apikey.FieldUsage7d:
 			values[i] = new(sql.NullFloat64)
-		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID:
+		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID, apikey.FieldRpmLimit, apikey.FieldTpmLimit, apikey.FieldTokenLimit, apikey.FieldTokensUsed, apikey.FieldProjectID:
 			values[i] = new(sql.NullInt64)
 		case apikey.FieldKey, apikey.FieldName, apikey.FieldStatus:
 			values[i] = new(sql.NullString)
@@ -301,6 +319,57 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 				_m.Window7dStart = new(time.Time)
 				*_m.Window7dStart = value.Time
 			}
+		case apikey.FieldRpmLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field rpm_limit", values[i])
+			} else if value.Valid {
+				_m.RpmLimit = int(value.Int64)
+			}
+		case apikey.FieldTpmLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field tpm_limit", values[i])
+			} else if value.Valid {
+				_m.TpmLimit = value.Int64
+			}
+		case apikey.FieldCritical:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field critical", values[i])
+			} else if value.Valid {
+				_m.Critical = value.Bool
+			}
+		case apikey.FieldGuest:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field guest", values[i])
+			} else if value.Valid {
+				_m.Guest = value.Bool
+			}
+		case apikey.FieldAllowedModels:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_models", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedModels); err != nil {
+					return fmt.Errorf("unmarshal field allowed_models: %w", err)
+				}
+			}
+		case apikey.FieldTokenLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field token_limit", values[i])
+			} else if value.Valid {
+				_m.TokenLimit = value.Int64
+			}
+		case apikey.FieldTokensUsed:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field tokens_used", values[i])
+			} else if value.Valid {
+				_m.TokensUsed = value.Int64
+			}
+		case apikey.FieldProjectID:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field project_id", values[i])
+			} else if value.Valid {
+				_m.ProjectID = new(int64)
+				*_m.ProjectID = value.Int64
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -434,6 +503,32 @@ func (_m *APIKey) String() string {
 		builder.WriteString("window_7d_start=")
 		builder.WriteString(v.Format(time.ANSIC))
 	}
+	builder.WriteString(", ")
+	builder.WriteString("rpm_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("tpm_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("critical=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Critical))
+	builder.WriteString(", ")
+	builder.WriteString("guest=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Guest))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_models=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedModels))
+	builder.WriteString(", ")
+	builder.WriteString("token_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TokenLimit))
+	builder.WriteString(", ")
+	builder.WriteString("tokens_used=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TokensUsed))
+	builder.WriteString(", ")
+	if v := _m.ProjectID; v != nil {
+		builder.WriteString("project_id=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -61,6 +61,22 @@ const (
 	FieldWindow1dStart = "window_1d_start"
 	// FieldWindow7dStart holds the string denoting the window_7d_start field in the database.
 	FieldWindow7dStart = "window_7d_start"
+	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
+	FieldRpmLimit = "rpm_limit"
+	// FieldTpmLimit holds the string denoting the tpm_limit field in the database.
+	FieldTpmLimit = "tpm_limit"
+	// FieldCritical holds the string denoting the critical field in the database.
+	FieldCritical = "critical"
+	// FieldGuest holds the string denoting the guest field in the database.
+	FieldGuest = "guest"
+	// FieldAllowedModels holds the string denoting the allowed_models field in the database.
+	FieldAllowedModels = "allowed_models"
+	// FieldTokenLimit holds the string denoting the token_limit field in the database.
+	FieldTokenLimit = "token_limit"
+	// FieldTokensUsed holds the string denoting the tokens_used field in the database.
+	FieldTokensUsed = "tokens_used"
+	// FieldProjectID holds the string denoting the project_id field in the database.
+	FieldProjectID = "project_id"
 	// EdgeUser holds the string denoting the user edge name in mutations.
 	EdgeUser = "user"
 	// EdgeGroup holds the string denoting the group edge name in mutations.
@@ -118,6 +134,14 @@ var Columns = []string{
 	FieldWindow5hStart,
 	FieldWindow1dStart,
 	FieldWindow7dStart,
+	FieldRpmLimit,
+	FieldTpmLimit,
+	FieldCritical,
+	FieldGuest,
+	FieldAllowedModels,
+	FieldTokenLimit,
+	FieldTokensUsed,
+	FieldProjectID,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -168,6 +192,18 @@ var (
 	DefaultUsage1d float64
 	// DefaultUsage7d holds the default value on creation for the "usage_7d" field.
 	DefaultUsage7d float64
+	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
+	DefaultRpmLimit int
+	// DefaultTpmLimit holds the default value on creation for the "tpm_limit" field.
+	DefaultTpmLimit int64
+	// DefaultCritical holds the default value on creation for the "critical" field.
+	DefaultCritical bool
+	// DefaultGuest holds the default value on creation for the "guest" field.
+	DefaultGuest bool
+	// DefaultTokenLimit holds the default value on creation for the "token_limit" field.
+	DefaultTokenLimit int64
+	// DefaultTokensUsed holds the default value on creation for the "tokens_used" field.
+	DefaultTokensUsed int64
 )
 
 // OrderOption defines the ordering options for the APIKey queries.
@@ -283,6 +319,41 @@ func ByWindow7dStart(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldWindow7dStart, opts...).ToFunc()
 }
 
+// ByRpmLimit orders the results by the rpm_limit field.
+func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
+}
+
+// ByTpmLimit orders the results by the tpm_limit field.
+func ByTpmLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTpmLimit, opts...).ToFunc()
+}
+
+// ByCritical orders the results by the critical field.
+func ByCritical(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCritical, opts...).ToFunc()
+}
+
+// ByGuest orders the results by the guest field.
+func ByGuest(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldGuest, opts...).ToFunc()
+}
+
+// ByTokenLimit orders the results by the token_limit field.
+func ByTokenLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTokenLimit, opts...).ToFunc()
+}
+
+// ByTokensUsed orders the results by the tokens_used field.
+func ByTokensUsed(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTokensUsed, opts...).ToFunc()
+}
+
+// ByProjectID orders the results by the project_id field.
+func ByProjectID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldProjectID, opts...).ToFunc()
+}
+
 // ByUserField orders the results by user field.
 func ByUserField(field string, opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
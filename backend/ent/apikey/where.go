@@ -160,6 +160,41 @@ func Window7dStart(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
 }
 
+// RpmLimit applies equality check predicate on the "rpm_limit" field. It's identical to RpmLimitEQ.
+func RpmLimit(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRpmLimit, v))
+}
+
+// TpmLimit applies equality check predicate on the "tpm_limit" field. It's identical to TpmLimitEQ.
+func TpmLimit(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTpmLimit, v))
+}
+
+// Critical applies equality check predicate on the "critical" field. It's identical to CriticalEQ.
+func Critical(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldCritical, v))
+}
+
+// Guest applies equality check predicate on the "guest" field. It's identical to GuestEQ.
+func Guest(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldGuest, v))
+}
+
+// TokenLimit applies equality check predicate on the "token_limit" field. It's identical to TokenLimitEQ.
+func TokenLimit(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokenLimit, v))
+}
+
+// TokensUsed applies equality check predicate on the "tokens_used" field. It's identical to TokensUsedEQ.
+func TokensUsed(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokensUsed, v))
+}
+
+// ProjectID applies equality check predicate on the "project_id" field. It's identical to ProjectIDEQ.
+func ProjectID(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldProjectID, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldCreatedAt, v))
@@ -1125,6 +1160,246 @@ func Window7dStartNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldWindow7dStart))
 }
 
+// RpmLimitEQ applies the EQ predicate on the "rpm_limit" field.
+func RpmLimitEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRpmLimit, v))
+}
+
+// RpmLimitNEQ applies the NEQ predicate on the "rpm_limit" field.
+func RpmLimitNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldRpmLimit, v))
+}
+
+// RpmLimitIn applies the In predicate on the "rpm_limit" field.
+func RpmLimitIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldRpmLimit, vs...))
+}
+
+// RpmLimitNotIn applies the NotIn predicate on the "rpm_limit" field.
+func RpmLimitNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldRpmLimit, vs...))
+}
+
+// RpmLimitGT applies the GT predicate on the "rpm_limit" field.
+func RpmLimitGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldRpmLimit, v))
+}
+
+// RpmLimitGTE applies the GTE predicate on the "rpm_limit" field.
+func RpmLimitGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldRpmLimit, v))
+}
+
+// RpmLimitLT applies the LT predicate on the "rpm_limit" field.
+func RpmLimitLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldRpmLimit, v))
+}
+
+// RpmLimitLTE applies the LTE predicate on the "rpm_limit" field.
+func RpmLimitLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldRpmLimit, v))
+}
+
+// TpmLimitEQ applies the EQ predicate on the "tpm_limit" field.
+func TpmLimitEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTpmLimit, v))
+}
+
+// TpmLimitNEQ applies the NEQ predicate on the "tpm_limit" field.
+func TpmLimitNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldTpmLimit, v))
+}
+
+// TpmLimitIn applies the In predicate on the "tpm_limit" field.
+func TpmLimitIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldTpmLimit, vs...))
+}
+
+// TpmLimitNotIn applies the NotIn predicate on the "tpm_limit" field.
+func TpmLimitNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldTpmLimit, vs...))
+}
+
+// TpmLimitGT applies the GT predicate on the "tpm_limit" field.
+func TpmLimitGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldTpmLimit, v))
+}
+
+// TpmLimitGTE applies the GTE predicate on the "tpm_limit" field.
+func TpmLimitGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldTpmLimit, v))
+}
+
+// TpmLimitLT applies the LT predicate on the "tpm_limit" field.
+func TpmLimitLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldTpmLimit, v))
+}
+
+// TpmLimitLTE applies the LTE predicate on the "tpm_limit" field.
+func TpmLimitLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldTpmLimit, v))
+}
+
+// CriticalEQ applies the EQ predicate on the "critical" field.
+func CriticalEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldCritical, v))
+}
+
+// CriticalNEQ applies the NEQ predicate on the "critical" field.
+func CriticalNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldCritical, v))
+}
+
+// GuestEQ applies the EQ predicate on the "guest" field.
+func GuestEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldGuest, v))
+}
+
+// GuestNEQ applies the NEQ predicate on the "guest" field.
+func GuestNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldGuest, v))
+}
+
+// AllowedModelsIsNil applies the IsNil predicate on the "allowed_models" field.
+func AllowedModelsIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldAllowedModels))
+}
+
+// AllowedModelsNotNil applies the NotNil predicate on the "allowed_models" field.
+func AllowedModelsNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldAllowedModels))
+}
+
+// TokenLimitEQ applies the EQ predicate on the "token_limit" field.
+func TokenLimitEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokenLimit, v))
+}
+
+// TokenLimitNEQ applies the NEQ predicate on the "token_limit" field.
+func TokenLimitNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldTokenLimit, v))
+}
+
+// TokenLimitIn applies the In predicate on the "token_limit" field.
+func TokenLimitIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldTokenLimit, vs...))
+}
+
+// TokenLimitNotIn applies the NotIn predicate on the "token_limit" field.
+func TokenLimitNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldTokenLimit, vs...))
+}
+
+// TokenLimitGT applies the GT predicate on the "token_limit" field.
+func TokenLimitGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldTokenLimit, v))
+}
+
+// TokenLimitGTE applies the GTE predicate on the "token_limit" field.
+func TokenLimitGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldTokenLimit, v))
+}
+
+// TokenLimitLT applies the LT predicate on the "token_limit" field.
+func TokenLimitLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldTokenLimit, v))
+}
+
+// TokenLimitLTE applies the LTE predicate on the "token_limit" field.
+func TokenLimitLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldTokenLimit, v))
+}
+
+// TokensUsedEQ applies the EQ predicate on the "tokens_used" field.
+func TokensUsedEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokensUsed, v))
+}
+
+// TokensUsedNEQ applies the NEQ predicate on the "tokens_used" field.
+func TokensUsedNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldTokensUsed, v))
+}
+
+// TokensUsedIn applies the In predicate on the "tokens_used" field.
+func TokensUsedIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldTokensUsed, vs...))
+}
+
+// TokensUsedNotIn applies the NotIn predicate on the "tokens_used" field.
+func TokensUsedNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldTokensUsed, vs...))
+}
+
+// TokensUsedGT applies the GT predicate on the "tokens_used" field.
+func TokensUsedGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldTokensUsed, v))
+}
+
+// TokensUsedGTE applies the GTE predicate on the "tokens_used" field.
+func TokensUsedGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldTokensUsed, v))
+}
+
+// TokensUsedLT applies the LT predicate on the "tokens_used" field.
+func TokensUsedLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldTokensUsed, v))
+}
+
+// TokensUsedLTE applies the LTE predicate on the "tokens_used" field.
+func TokensUsedLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldTokensUsed, v))
+}
+
+// ProjectIDEQ applies the EQ predicate on the "project_id" field.
+func ProjectIDEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldProjectID, v))
+}
+
+// ProjectIDNEQ applies the NEQ predicate on the "project_id" field.
+func ProjectIDNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldProjectID, v))
+}
+
+// ProjectIDIn applies the In predicate on the "project_id" field.
+func ProjectIDIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldProjectID, vs...))
+}
+
+// ProjectIDNotIn applies the NotIn predicate on the "project_id" field.
+func ProjectIDNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldProjectID, vs...))
+}
+
+// ProjectIDGT applies the GT predicate on the "project_id" field.
+func ProjectIDGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldProjectID, v))
+}
+
+// ProjectIDGTE applies the GTE predicate on the "project_id" field.
+func ProjectIDGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldProjectID, v))
+}
+
+// ProjectIDLT applies the LT predicate on the "project_id" field.
+func ProjectIDLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldProjectID, v))
+}
+
+// ProjectIDLTE applies the LTE predicate on the "project_id" field.
+func ProjectIDLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldProjectID, v))
+}
+
+// ProjectIDIsNil applies the IsNil predicate on the "project_id" field.
+func ProjectIDIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldProjectID))
+}
+
+// ProjectIDNotNil applies the NotNil predicate on the "project_id" field.
+func ProjectIDNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldProjectID))
+}
+
 // HasUser applies the HasEdge predicate on the "user" edge.
 func HasUser() predicate.APIKey {
 	return predicate.APIKey(func(s *sql.Selector) {
@@ -307,6 +307,110 @@ func (_c *APIKeyCreate) SetNillableWindow7dStart(v *time.Time) *APIKeyCreate {
 	return _c
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_c *APIKeyCreate) SetRpmLimit(v int) *APIKeyCreate {
+	_c.mutation.SetRpmLimit(v)
+	return _c
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableRpmLimit(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetRpmLimit(*v)
+	}
+	return _c
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_c *APIKeyCreate) SetTpmLimit(v int64) *APIKeyCreate {
+	_c.mutation.SetTpmLimit(v)
+	return _c
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableTpmLimit(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetTpmLimit(*v)
+	}
+	return _c
+}
+
+// SetCritical sets the "critical" field.
+func (_c *APIKeyCreate) SetCritical(v bool) *APIKeyCreate {
+	_c.mutation.SetCritical(v)
+	return _c
+}
+
+// SetNillableCritical sets the "critical" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableCritical(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetCritical(*v)
+	}
+	return _c
+}
+
+// SetGuest sets the "guest" field.
+func (_c *APIKeyCreate) SetGuest(v bool) *APIKeyCreate {
+	_c.mutation.SetGuest(v)
+	return _c
+}
+
+// SetNillableGuest sets the "guest" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableGuest(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetGuest(*v)
+	}
+	return _c
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_c *APIKeyCreate) SetAllowedModels(v []string) *APIKeyCreate {
+	_c.mutation.SetAllowedModels(v)
+	return _c
+}
+
+// SetTokenLimit sets the "token_limit" field.
+func (_c *APIKeyCreate) SetTokenLimit(v int64) *APIKeyCreate {
+	_c.mutation.SetTokenLimit(v)
+	return _c
+}
+
+// SetNillableTokenLimit sets the "token_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableTokenLimit(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetTokenLimit(*v)
+	}
+	return _c
+}
+
+// SetTokensUsed sets the "tokens_used" field.
+func (_c *APIKeyCreate) SetTokensUsed(v int64) *APIKeyCreate {
+	_c.mutation.SetTokensUsed(v)
+	return _c
+}
+
+// SetNillableTokensUsed sets the "tokens_used" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableTokensUsed(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetTokensUsed(*v)
+	}
+	return _c
+}
+
+// SetProjectID sets the "project_id" field.
+func (_c *APIKeyCreate) SetProjectID(v int64) *APIKeyCreate {
+	_c.mutation.SetProjectID(v)
+	return _c
+}
+
+// SetNillableProjectID sets the "project_id" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableProjectID(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetProjectID(*v)
+	}
+	return _c
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_c *APIKeyCreate) SetUser(v *User) *APIKeyCreate {
 	return _c.SetUserID(v.ID)
@@ -419,6 +523,30 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUsage7d
 		_c.mutation.SetUsage7d(v)
 	}
+	if _, ok := _c.mutation.RpmLimit(); !ok {
+		v := apikey.DefaultRpmLimit
+		_c.mutation.SetRpmLimit(v)
+	}
+	if _, ok := _c.mutation.TpmLimit(); !ok {
+		v := apikey.DefaultTpmLimit
+		_c.mutation.SetTpmLimit(v)
+	}
+	if _, ok := _c.mutation.Critical(); !ok {
+		v := apikey.DefaultCritical
+		_c.mutation.SetCritical(v)
+	}
+	if _, ok := _c.mutation.Guest(); !ok {
+		v := apikey.DefaultGuest
+		_c.mutation.SetGuest(v)
+	}
+	if _, ok := _c.mutation.TokenLimit(); !ok {
+		v := apikey.DefaultTokenLimit
+		_c.mutation.SetTokenLimit(v)
+	}
+	if _, ok := _c.mutation.TokensUsed(); !ok {
+		v := apikey.DefaultTokensUsed
+		_c.mutation.SetTokensUsed(v)
+	}
 	return nil
 }
 
@@ -481,6 +609,24 @@ func (_c *APIKeyCreate) check() error {
 	if _, ok := _c.mutation.Usage7d(); !ok {
 		return &ValidationError{Name: "usage_7d", err: errors.New(`ent: missing required field "APIKey.usage_7d"`)}
 	}
+	if _, ok := _c.mutation.RpmLimit(); !ok {
+		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "APIKey.rpm_limit"`)}
+	}
+	if _, ok := _c.mutation.TpmLimit(); !ok {
+		return &ValidationError{Name: "tpm_limit", err: errors.New(`ent: missing required field "APIKey.tpm_limit"`)}
+	}
+	if _, ok := _c.mutation.Critical(); !ok {
+		return &ValidationError{Name: "critical", err: errors.New(`ent: missing required field "APIKey.critical"`)}
+	}
+	if _, ok := _c.mutation.Guest(); !ok {
+		return &ValidationError{Name: "guest", err: errors.New(`ent: missing required field "APIKey.guest"`)}
+	}
+	if _, ok := _c.mutation.TokenLimit(); !ok {
+		return &ValidationError{Name: "token_limit", err: errors.New(`ent: missing required field "APIKey.token_limit"`)}
+	}
+	if _, ok := _c.mutation.TokensUsed(); !ok {
+		return &ValidationError{Name: "tokens_used", err: errors.New(`ent: missing required field "APIKey.tokens_used"`)}
+	}
 	if len(_c.mutation.UserIDs()) == 0 {
 		return &ValidationError{Name: "user", err: errors.New(`ent: missing required edge "APIKey.user"`)}
 	}
@@ -595,6 +741,38 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldWindow7dStart, field.TypeTime, value)
 		_node.Window7dStart = &value
 	}
+	if value, ok := _c.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+		_node.RpmLimit = value
+	}
+	if value, ok := _c.mutation.TpmLimit(); ok {
+		_spec.SetField(apikey.FieldTpmLimit, field.TypeInt64, value)
+		_node.TpmLimit = value
+	}
+	if value, ok := _c.mutation.Critical(); ok {
+		_spec.SetField(apikey.FieldCritical, field.TypeBool, value)
+		_node.Critical = value
+	}
+	if value, ok := _c.mutation.Guest(); ok {
+		_spec.SetField(apikey.FieldGuest, field.TypeBool, value)
+		_node.Guest = value
+	}
+	if value, ok := _c.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+		_node.AllowedModels = value
+	}
+	if value, ok := _c.mutation.TokenLimit(); ok {
+		_spec.SetField(apikey.FieldTokenLimit, field.TypeInt64, value)
+		_node.TokenLimit = value
+	}
+	if value, ok := _c.mutation.TokensUsed(); ok {
+		_spec.SetField(apikey.FieldTokensUsed, field.TypeInt64, value)
+		_node.TokensUsed = value
+	}
+	if value, ok := _c.mutation.ProjectID(); ok {
+		_spec.SetField(apikey.FieldProjectID, field.TypeInt64, value)
+		_node.ProjectID = &value
+	}
 	if nodes := _c.mutation.UserIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -1063,6 +1241,144 @@ func (u *APIKeyUpsert) ClearWindow7dStart() *APIKeyUpsert {
 	return u
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *APIKeyUpsert) SetRpmLimit(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldRpmLimit, v)
+	return u
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateRpmLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldRpmLimit)
+	return u
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *APIKeyUpsert) AddRpmLimit(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldRpmLimit, v)
+	return u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *APIKeyUpsert) SetTpmLimit(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldTpmLimit, v)
+	return u
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTpmLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTpmLimit)
+	return u
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *APIKeyUpsert) AddTpmLimit(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldTpmLimit, v)
+	return u
+}
+
+// SetCritical sets the "critical" field.
+func (u *APIKeyUpsert) SetCritical(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldCritical, v)
+	return u
+}
+
+// UpdateCritical sets the "critical" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateCritical() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldCritical)
+	return u
+}
+
+// SetGuest sets the "guest" field.
+func (u *APIKeyUpsert) SetGuest(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldGuest, v)
+	return u
+}
+
+// UpdateGuest sets the "guest" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateGuest() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldGuest)
+	return u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsert) SetAllowedModels(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldAllowedModels, v)
+	return u
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAllowedModels() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAllowedModels)
+	return u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsert) ClearAllowedModels() *APIKeyUpsert {
+	u.SetNull(apikey.FieldAllowedModels)
+	return u
+}
+
+// SetTokenLimit sets the "token_limit" field.
+func (u *APIKeyUpsert) SetTokenLimit(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldTokenLimit, v)
+	return u
+}
+
+// UpdateTokenLimit sets the "token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTokenLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTokenLimit)
+	return u
+}
+
+// AddTokenLimit adds v to the "token_limit" field.
+func (u *APIKeyUpsert) AddTokenLimit(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldTokenLimit, v)
+	return u
+}
+
+// SetTokensUsed sets the "tokens_used" field.
+func (u *APIKeyUpsert) SetTokensUsed(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldTokensUsed, v)
+	return u
+}
+
+// UpdateTokensUsed sets the "tokens_used" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTokensUsed() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTokensUsed)
+	return u
+}
+
+// AddTokensUsed adds v to the "tokens_used" field.
+func (u *APIKeyUpsert) AddTokensUsed(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldTokensUsed, v)
+	return u
+}
+
+// SetProjectID sets the "project_id" field.
+func (u *APIKeyUpsert) SetProjectID(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldProjectID, v)
+	return u
+}
+
+// UpdateProjectID sets the "project_id" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateProjectID() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldProjectID)
+	return u
+}
+
+// AddProjectID adds v to the "project_id" field.
+func (u *APIKeyUpsert) AddProjectID(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldProjectID, v)
+	return u
+}
+
+// ClearProjectID clears the value of the "project_id" field.
+func (u *APIKeyUpsert) ClearProjectID() *APIKeyUpsert {
+	u.SetNull(apikey.FieldProjectID)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1535,6 +1851,167 @@ func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
 	})
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *APIKeyUpsertOne) SetRpmLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRpmLimit(v)
+	})
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *APIKeyUpsertOne) AddRpmLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddRpmLimit(v)
+	})
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateRpmLimit() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRpmLimit()
+	})
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *APIKeyUpsertOne) SetTpmLimit(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTpmLimit(v)
+	})
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *APIKeyUpsertOne) AddTpmLimit(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTpmLimit(v)
+	})
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTpmLimit() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTpmLimit()
+	})
+}
+
+// SetCritical sets the "critical" field.
+func (u *APIKeyUpsertOne) SetCritical(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetCritical(v)
+	})
+}
+
+// UpdateCritical sets the "critical" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateCritical() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateCritical()
+	})
+}
+
+// SetGuest sets the "guest" field.
+func (u *APIKeyUpsertOne) SetGuest(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetGuest(v)
+	})
+}
+
+// UpdateGuest sets the "guest" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateGuest() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateGuest()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertOne) SetAllowedModels(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertOne) ClearAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAllowedModels()
+	})
+}
+
+// SetTokenLimit sets the "token_limit" field.
+func (u *APIKeyUpsertOne) SetTokenLimit(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokenLimit(v)
+	})
+}
+
+// AddTokenLimit adds v to the "token_limit" field.
+func (u *APIKeyUpsertOne) AddTokenLimit(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokenLimit(v)
+	})
+}
+
+// UpdateTokenLimit sets the "token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTokenLimit() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokenLimit()
+	})
+}
+
+// SetTokensUsed sets the "tokens_used" field.
+func (u *APIKeyUpsertOne) SetTokensUsed(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokensUsed(v)
+	})
+}
+
+// AddTokensUsed adds v to the "tokens_used" field.
+func (u *APIKeyUpsertOne) AddTokensUsed(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokensUsed(v)
+	})
+}
+
+// UpdateTokensUsed sets the "tokens_used" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTokensUsed() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokensUsed()
+	})
+}
+
+// SetProjectID sets the "project_id" field.
+func (u *APIKeyUpsertOne) SetProjectID(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetProjectID(v)
+	})
+}
+
+// AddProjectID adds v to the "project_id" field.
+func (u *APIKeyUpsertOne) AddProjectID(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddProjectID(v)
+	})
+}
+
+// UpdateProjectID sets the "project_id" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateProjectID() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateProjectID()
+	})
+}
+
+// ClearProjectID clears the value of the "project_id" field.
+func (u *APIKeyUpsertOne) ClearProjectID() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearProjectID()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -2173,6 +2650,167 @@ func (u *APIKeyUpsertBulk) ClearWindow7dStart() *APIKeyUpsertBulk {
 	})
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *APIKeyUpsertBulk) SetRpmLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRpmLimit(v)
+	})
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *APIKeyUpsertBulk) AddRpmLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddRpmLimit(v)
+	})
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateRpmLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRpmLimit()
+	})
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *APIKeyUpsertBulk) SetTpmLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTpmLimit(v)
+	})
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *APIKeyUpsertBulk) AddTpmLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTpmLimit(v)
+	})
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTpmLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTpmLimit()
+	})
+}
+
+// SetCritical sets the "critical" field.
+func (u *APIKeyUpsertBulk) SetCritical(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetCritical(v)
+	})
+}
+
+// UpdateCritical sets the "critical" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateCritical() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateCritical()
+	})
+}
+
+// SetGuest sets the "guest" field.
+func (u *APIKeyUpsertBulk) SetGuest(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetGuest(v)
+	})
+}
+
+// UpdateGuest sets the "guest" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateGuest() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateGuest()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertBulk) SetAllowedModels(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertBulk) ClearAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAllowedModels()
+	})
+}
+
+// SetTokenLimit sets the "token_limit" field.
+func (u *APIKeyUpsertBulk) SetTokenLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokenLimit(v)
+	})
+}
+
+// AddTokenLimit adds v to the "token_limit" field.
+func (u *APIKeyUpsertBulk) AddTokenLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokenLimit(v)
+	})
+}
+
+// UpdateTokenLimit sets the "token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTokenLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokenLimit()
+	})
+}
+
+// SetTokensUsed sets the "tokens_used" field.
+func (u *APIKeyUpsertBulk) SetTokensUsed(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokensUsed(v)
+	})
+}
+
+// AddTokensUsed adds v to the "tokens_used" field.
+func (u *APIKeyUpsertBulk) AddTokensUsed(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokensUsed(v)
+	})
+}
+
+// UpdateTokensUsed sets the "tokens_used" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTokensUsed() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokensUsed()
+	})
+}
+
+// SetProjectID sets the "project_id" field.
+func (u *APIKeyUpsertBulk) SetProjectID(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetProjectID(v)
+	})
+}
+
+// AddProjectID adds v to the "project_id" field.
+func (u *APIKeyUpsertBulk) AddProjectID(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddProjectID(v)
+	})
+}
+
+// UpdateProjectID sets the "project_id" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateProjectID() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateProjectID()
+	})
+}
+
+// ClearProjectID clears the value of the "project_id" field.
+func (u *APIKeyUpsertBulk) ClearProjectID() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearProjectID()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
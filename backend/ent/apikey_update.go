@@ -438,6 +438,163 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *APIKeyUpdate) SetRpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableRpmLimit(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *APIKeyUpdate) AddRpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_u *APIKeyUpdate) SetTpmLimit(v int64) *APIKeyUpdate {
+	_u.mutation.ResetTpmLimit()
+	_u.mutation.SetTpmLimit(v)
+	return _u
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableTpmLimit(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetTpmLimit(*v)
+	}
+	return _u
+}
+
+// AddTpmLimit adds value to the "tpm_limit" field.
+func (_u *APIKeyUpdate) AddTpmLimit(v int64) *APIKeyUpdate {
+	_u.mutation.AddTpmLimit(v)
+	return _u
+}
+
+// SetCritical sets the "critical" field.
+func (_u *APIKeyUpdate) SetCritical(v bool) *APIKeyUpdate {
+	_u.mutation.SetCritical(v)
+	return _u
+}
+
+// SetNillableCritical sets the "critical" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableCritical(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetCritical(*v)
+	}
+	return _u
+}
+
+// SetGuest sets the "guest" field.
+func (_u *APIKeyUpdate) SetGuest(v bool) *APIKeyUpdate {
+	_u.mutation.SetGuest(v)
+	return _u
+}
+
+// SetNillableGuest sets the "guest" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableGuest(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetGuest(*v)
+	}
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdate) SetAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdate) AppendAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdate) ClearAllowedModels() *APIKeyUpdate {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
+// SetTokenLimit sets the "token_limit" field.
+func (_u *APIKeyUpdate) SetTokenLimit(v int64) *APIKeyUpdate {
+	_u.mutation.ResetTokenLimit()
+	_u.mutation.SetTokenLimit(v)
+	return _u
+}
+
+// SetNillableTokenLimit sets the "token_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableTokenLimit(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetTokenLimit(*v)
+	}
+	return _u
+}
+
+// AddTokenLimit adds value to the "token_limit" field.
+func (_u *APIKeyUpdate) AddTokenLimit(v int64) *APIKeyUpdate {
+	_u.mutation.AddTokenLimit(v)
+	return _u
+}
+
+// SetTokensUsed sets the "tokens_used" field.
+func (_u *APIKeyUpdate) SetTokensUsed(v int64) *APIKeyUpdate {
+	_u.mutation.ResetTokensUsed()
+	_u.mutation.SetTokensUsed(v)
+	return _u
+}
+
+// SetNillableTokensUsed sets the "tokens_used" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableTokensUsed(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetTokensUsed(*v)
+	}
+	return _u
+}
+
+// AddTokensUsed adds value to the "tokens_used" field.
+func (_u *APIKeyUpdate) AddTokensUsed(v int64) *APIKeyUpdate {
+	_u.mutation.AddTokensUsed(v)
+	return _u
+}
+
+// SetProjectID sets the "project_id" field.
+func (_u *APIKeyUpdate) SetProjectID(v int64) *APIKeyUpdate {
+	_u.mutation.ResetProjectID()
+	_u.mutation.SetProjectID(v)
+	return _u
+}
+
+// SetNillableProjectID sets the "project_id" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableProjectID(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetProjectID(*v)
+	}
+	return _u
+}
+
+// AddProjectID adds value to the "project_id" field.
+func (_u *APIKeyUpdate) AddProjectID(v int64) *APIKeyUpdate {
+	_u.mutation.AddProjectID(v)
+	return _u
+}
+
+// ClearProjectID clears the value of the "project_id" field.
+func (_u *APIKeyUpdate) ClearProjectID() *APIKeyUpdate {
+	_u.mutation.ClearProjectID()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -696,6 +853,56 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TpmLimit(); ok {
+		_spec.SetField(apikey.FieldTpmLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTpmLimit(); ok {
+		_spec.AddField(apikey.FieldTpmLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.Critical(); ok {
+		_spec.SetField(apikey.FieldCritical, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.Guest(); ok {
+		_spec.SetField(apikey.FieldGuest, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.TokenLimit(); ok {
+		_spec.SetField(apikey.FieldTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTokenLimit(); ok {
+		_spec.AddField(apikey.FieldTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.TokensUsed(); ok {
+		_spec.SetField(apikey.FieldTokensUsed, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTokensUsed(); ok {
+		_spec.AddField(apikey.FieldTokensUsed, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ProjectID(); ok {
+		_spec.SetField(apikey.FieldProjectID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedProjectID(); ok {
+		_spec.AddField(apikey.FieldProjectID, field.TypeInt64, value)
+	}
+	if _u.mutation.ProjectIDCleared() {
+		_spec.ClearField(apikey.FieldProjectID, field.TypeInt64)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -1225,6 +1432,163 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *APIKeyUpdateOne) SetRpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableRpmLimit(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *APIKeyUpdateOne) AddRpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_u *APIKeyUpdateOne) SetTpmLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetTpmLimit()
+	_u.mutation.SetTpmLimit(v)
+	return _u
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableTpmLimit(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetTpmLimit(*v)
+	}
+	return _u
+}
+
+// AddTpmLimit adds value to the "tpm_limit" field.
+func (_u *APIKeyUpdateOne) AddTpmLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddTpmLimit(v)
+	return _u
+}
+
+// SetCritical sets the "critical" field.
+func (_u *APIKeyUpdateOne) SetCritical(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetCritical(v)
+	return _u
+}
+
+// SetNillableCritical sets the "critical" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableCritical(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetCritical(*v)
+	}
+	return _u
+}
+
+// SetGuest sets the "guest" field.
+func (_u *APIKeyUpdateOne) SetGuest(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetGuest(v)
+	return _u
+}
+
+// SetNillableGuest sets the "guest" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableGuest(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetGuest(*v)
+	}
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdateOne) SetAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdateOne) AppendAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdateOne) ClearAllowedModels() *APIKeyUpdateOne {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
+// SetTokenLimit sets the "token_limit" field.
+func (_u *APIKeyUpdateOne) SetTokenLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetTokenLimit()
+	_u.mutation.SetTokenLimit(v)
+	return _u
+}
+
+// SetNillableTokenLimit sets the "token_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableTokenLimit(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetTokenLimit(*v)
+	}
+	return _u
+}
+
+// AddTokenLimit adds value to the "token_limit" field.
+func (_u *APIKeyUpdateOne) AddTokenLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddTokenLimit(v)
+	return _u
+}
+
+// SetTokensUsed sets the "tokens_used" field.
+func (_u *APIKeyUpdateOne) SetTokensUsed(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetTokensUsed()
+	_u.mutation.SetTokensUsed(v)
+	return _u
+}
+
+// SetNillableTokensUsed sets the "tokens_used" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableTokensUsed(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetTokensUsed(*v)
+	}
+	return _u
+}
+
+// AddTokensUsed adds value to the "tokens_used" field.
+func (_u *APIKeyUpdateOne) AddTokensUsed(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddTokensUsed(v)
+	return _u
+}
+
+// SetProjectID sets the "project_id" field.
+func (_u *APIKeyUpdateOne) SetProjectID(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetProjectID()
+	_u.mutation.SetProjectID(v)
+	return _u
+}
+
+// SetNillableProjectID sets the "project_id" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableProjectID(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetProjectID(*v)
+	}
+	return _u
+}
+
+// AddProjectID adds value to the "project_id" field.
+func (_u *APIKeyUpdateOne) AddProjectID(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddProjectID(v)
+	return _u
+}
+
+// ClearProjectID clears the value of the "project_id" field.
+func (_u *APIKeyUpdateOne) ClearProjectID() *APIKeyUpdateOne {
+	_u.mutation.ClearProjectID()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1513,6 +1877,56 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TpmLimit(); ok {
+		_spec.SetField(apikey.FieldTpmLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTpmLimit(); ok {
+		_spec.AddField(apikey.FieldTpmLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.Critical(); ok {
+		_spec.SetField(apikey.FieldCritical, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.Guest(); ok {
+		_spec.SetField(apikey.FieldGuest, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.TokenLimit(); ok {
+		_spec.SetField(apikey.FieldTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTokenLimit(); ok {
+		_spec.AddField(apikey.FieldTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.TokensUsed(); ok {
+		_spec.SetField(apikey.FieldTokensUsed, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTokensUsed(); ok {
+		_spec.AddField(apikey.FieldTokensUsed, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ProjectID(); ok {
+		_spec.SetField(apikey.FieldProjectID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedProjectID(); ok {
+		_spec.AddField(apikey.FieldProjectID, field.TypeInt64, value)
+	}
+	if _u.mutation.ProjectIDCleared() {
+		_spec.ClearField(apikey.FieldProjectID, field.TypeInt64)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -39,6 +39,8 @@ type Group struct {
 	PeakEnd string `json:"peak_end,omitempty"`
 	// 高峰时段叠加倍率，仅在 peak_rate_enabled 且处于 [peak_start, peak_end) 时乘入文本倍率
 	PeakRateMultiplier float64 `json:"peak_rate_multiplier,omitempty"`
+	// 按次固定附加费（USD），在倍率计算后的实际费用上叠加，0 表示不收取
+	RequestSurcharge float64 `json:"request_surcharge,omitempty"`
 	// IsExclusive holds the value of the "is_exclusive" field.
 	IsExclusive bool `json:"is_exclusive,omitempty"`
 	// Status holds the value of the "status" field.
@@ -113,6 +115,32 @@ type Group struct {
 	ModelsListConfig domain.GroupModelsListConfig `json:"models_list_config,omitempty"`
 	// 分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流
 	RpmLimit int `json:"rpm_limit,omitempty"`
+	// 账号并发预留比例 [0,1)，非关键 Key 无法占用预留槽位
+	ReservedConcurrencyRatio float64 `json:"reserved_concurrency_ratio,omitempty"`
+	// 过载降级模型映射：模型模式 -> 备用模型，为空表示不降级
+	OverloadFallbackModels map[string]string `json:"overload_fallback_models,omitempty"`
+	// 长上下文路由映射：模型模式 -> 大上下文模型，为空表示不改路由
+	ContextOverflowModels map[string]string `json:"context_overflow_models,omitempty"`
+	// 估算输入超出上下文窗口且无可用大上下文模型时直接拒绝（400）
+	ContextOverflowReject bool `json:"context_overflow_reject,omitempty"`
+	// 估算输入超出上下文窗口时的截断策略：drop_oldest/summarize/middle_out，空表示不截断
+	ContextTruncationStrategy string `json:"context_truncation_strategy,omitempty"`
+	// 账号选择策略：weighted_round_robin/least_recently_used/lowest_latency，空表示按优先级逐级溢出
+	AccountSelectionStrategy string `json:"account_selection_strategy,omitempty"`
+	// 图片最长边上限（像素），超出时等比缩放，0 表示不限制
+	ImageMaxEdge int `json:"image_max_edge,omitempty"`
+	// 单张图片字节数目标，超出时重新压缩，0 表示不限制
+	ImageMaxBytes int `json:"image_max_bytes,omitempty"`
+	// 输出后处理配置：替换规则与追加署名，为空表示不处理
+	OutputPostprocess domain.GroupOutputPostprocessConfig `json:"output_postprocess,omitempty"`
+	// 语言路由规则：languages 命中时改写 model 或优先 account_ids/proxy_ids，为空表示不启用
+	LanguageRouting domain.GroupLanguageRoutingConfig `json:"language_routing,omitempty"`
+	// 流式断线续传配置：enabled 开启后按 max_events/max_bytes 缓存 SSE 事件，为空表示不启用
+	StreamReplay domain.GroupStreamReplayConfig `json:"stream_replay,omitempty"`
+	// 模型能力校验：请求包含映射后模型不支持的图片/音频/工具/推理时改用备用模型或拒绝
+	CapabilityCheck domain.GroupCapabilityCheckConfig `json:"capability_check,omitempty"`
+	// 截断自动续写配置：enabled 开启后按 max_continuations 上限续写被截断的非流式响应，为空表示不启用
+	AutoContinuation domain.GroupAutoContinuationConfig `json:"auto_continuation,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the GroupQuery when eager-loading is set.
 	Edges        GroupEdges `json:"edges"`
@@ -219,15 +247,15 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig:
+		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig, group.FieldOverloadFallbackModels, group.FieldContextOverflowModels, group.FieldOutputPostprocess, group.FieldLanguageRouting, group.FieldStreamReplay, group.FieldCapabilityCheck, group.FieldAutoContinuation:
 			values[i] = new([]byte)
-		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldVideoRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet:
+		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldVideoRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldContextOverflowReject:
 			values[i] = new(sql.NullBool)
-		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p:
+		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldRequestSurcharge, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p, group.FieldReservedConcurrencyRatio:
 			values[i] = new(sql.NullFloat64)
-		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit:
+		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit, group.FieldImageMaxEdge, group.FieldImageMaxBytes:
 			values[i] = new(sql.NullInt64)
-		case group.FieldName, group.FieldDescription, group.FieldPeakStart, group.FieldPeakEnd, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel:
+		case group.FieldName, group.FieldDescription, group.FieldPeakStart, group.FieldPeakEnd, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel, group.FieldContextTruncationStrategy, group.FieldAccountSelectionStrategy:
 			values[i] = new(sql.NullString)
 		case group.FieldCreatedAt, group.FieldUpdatedAt, group.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
@@ -314,6 +342,12 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.PeakRateMultiplier = value.Float64
 			}
+		case group.FieldRequestSurcharge:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field request_surcharge", values[i])
+			} else if value.Valid {
+				_m.RequestSurcharge = value.Float64
+			}
 		case group.FieldIsExclusive:
 			if value, ok := values[i].(*sql.NullBool); !ok {
 				return fmt.Errorf("unexpected type %T for field is_exclusive", values[i])
@@ -555,6 +589,98 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.RpmLimit = int(value.Int64)
 			}
+		case group.FieldReservedConcurrencyRatio:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field reserved_concurrency_ratio", values[i])
+			} else if value.Valid {
+				_m.ReservedConcurrencyRatio = value.Float64
+			}
+		case group.FieldOverloadFallbackModels:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field overload_fallback_models", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.OverloadFallbackModels); err != nil {
+					return fmt.Errorf("unmarshal field overload_fallback_models: %w", err)
+				}
+			}
+		case group.FieldContextOverflowModels:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field context_overflow_models", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ContextOverflowModels); err != nil {
+					return fmt.Errorf("unmarshal field context_overflow_models: %w", err)
+				}
+			}
+		case group.FieldContextOverflowReject:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field context_overflow_reject", values[i])
+			} else if value.Valid {
+				_m.ContextOverflowReject = value.Bool
+			}
+		case group.FieldContextTruncationStrategy:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field context_truncation_strategy", values[i])
+			} else if value.Valid {
+				_m.ContextTruncationStrategy = value.String
+			}
+		case group.FieldAccountSelectionStrategy:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field account_selection_strategy", values[i])
+			} else if value.Valid {
+				_m.AccountSelectionStrategy = value.String
+			}
+		case group.FieldImageMaxEdge:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field image_max_edge", values[i])
+			} else if value.Valid {
+				_m.ImageMaxEdge = int(value.Int64)
+			}
+		case group.FieldImageMaxBytes:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field image_max_bytes", values[i])
+			} else if value.Valid {
+				_m.ImageMaxBytes = int(value.Int64)
+			}
+		case group.FieldOutputPostprocess:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field output_postprocess", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.OutputPostprocess); err != nil {
+					return fmt.Errorf("unmarshal field output_postprocess: %w", err)
+				}
+			}
+		case group.FieldLanguageRouting:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field language_routing", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.LanguageRouting); err != nil {
+					return fmt.Errorf("unmarshal field language_routing: %w", err)
+				}
+			}
+		case group.FieldStreamReplay:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field stream_replay", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.StreamReplay); err != nil {
+					return fmt.Errorf("unmarshal field stream_replay: %w", err)
+				}
+			}
+		case group.FieldCapabilityCheck:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field capability_check", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.CapabilityCheck); err != nil {
+					return fmt.Errorf("unmarshal field capability_check: %w", err)
+				}
+			}
+		case group.FieldAutoContinuation:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field auto_continuation", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AutoContinuation); err != nil {
+					return fmt.Errorf("unmarshal field auto_continuation: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -665,6 +791,9 @@ func (_m *Group) String() string {
 	builder.WriteString("peak_rate_multiplier=")
 	builder.WriteString(fmt.Sprintf("%v", _m.PeakRateMultiplier))
 	builder.WriteString(", ")
+	builder.WriteString("request_surcharge=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RequestSurcharge))
+	builder.WriteString(", ")
 	builder.WriteString("is_exclusive=")
 	builder.WriteString(fmt.Sprintf("%v", _m.IsExclusive))
 	builder.WriteString(", ")
@@ -797,6 +926,45 @@ func (_m *Group) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("rpm_limit=")
 	builder.WriteString(fmt.Sprintf("%v", _m.RpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("reserved_concurrency_ratio=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ReservedConcurrencyRatio))
+	builder.WriteString(", ")
+	builder.WriteString("overload_fallback_models=")
+	builder.WriteString(fmt.Sprintf("%v", _m.OverloadFallbackModels))
+	builder.WriteString(", ")
+	builder.WriteString("context_overflow_models=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ContextOverflowModels))
+	builder.WriteString(", ")
+	builder.WriteString("context_overflow_reject=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ContextOverflowReject))
+	builder.WriteString(", ")
+	builder.WriteString("context_truncation_strategy=")
+	builder.WriteString(_m.ContextTruncationStrategy)
+	builder.WriteString(", ")
+	builder.WriteString("account_selection_strategy=")
+	builder.WriteString(_m.AccountSelectionStrategy)
+	builder.WriteString(", ")
+	builder.WriteString("image_max_edge=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ImageMaxEdge))
+	builder.WriteString(", ")
+	builder.WriteString("image_max_bytes=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ImageMaxBytes))
+	builder.WriteString(", ")
+	builder.WriteString("output_postprocess=")
+	builder.WriteString(fmt.Sprintf("%v", _m.OutputPostprocess))
+	builder.WriteString(", ")
+	builder.WriteString("language_routing=")
+	builder.WriteString(fmt.Sprintf("%v", _m.LanguageRouting))
+	builder.WriteString(", ")
+	builder.WriteString("stream_replay=")
+	builder.WriteString(fmt.Sprintf("%v", _m.StreamReplay))
+	builder.WriteString(", ")
+	builder.WriteString("capability_check=")
+	builder.WriteString(fmt.Sprintf("%v", _m.CapabilityCheck))
+	builder.WriteString(", ")
+	builder.WriteString("auto_continuation=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AutoContinuation))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -36,6 +36,8 @@ const (
 	FieldPeakEnd = "peak_end"
 	// FieldPeakRateMultiplier holds the string denoting the peak_rate_multiplier field in the database.
 	FieldPeakRateMultiplier = "peak_rate_multiplier"
+	// FieldRequestSurcharge holds the string denoting the request_surcharge field in the database.
+	FieldRequestSurcharge = "request_surcharge"
 	// FieldIsExclusive holds the string denoting the is_exclusive field in the database.
 	FieldIsExclusive = "is_exclusive"
 	// FieldStatus holds the string denoting the status field in the database.
@@ -110,6 +112,32 @@ const (
 	FieldModelsListConfig = "models_list_config"
 	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
 	FieldRpmLimit = "rpm_limit"
+	// FieldReservedConcurrencyRatio holds the string denoting the reserved_concurrency_ratio field in the database.
+	FieldReservedConcurrencyRatio = "reserved_concurrency_ratio"
+	// FieldOverloadFallbackModels holds the string denoting the overload_fallback_models field in the database.
+	FieldOverloadFallbackModels = "overload_fallback_models"
+	// FieldContextOverflowModels holds the string denoting the context_overflow_models field in the database.
+	FieldContextOverflowModels = "context_overflow_models"
+	// FieldContextOverflowReject holds the string denoting the context_overflow_reject field in the database.
+	FieldContextOverflowReject = "context_overflow_reject"
+	// FieldContextTruncationStrategy holds the string denoting the context_truncation_strategy field in the database.
+	FieldContextTruncationStrategy = "context_truncation_strategy"
+	// FieldAccountSelectionStrategy holds the string denoting the account_selection_strategy field in the database.
+	FieldAccountSelectionStrategy = "account_selection_strategy"
+	// FieldImageMaxEdge holds the string denoting the image_max_edge field in the database.
+	FieldImageMaxEdge = "image_max_edge"
+	// FieldImageMaxBytes holds the string denoting the image_max_bytes field in the database.
+	FieldImageMaxBytes = "image_max_bytes"
+	// FieldOutputPostprocess holds the string denoting the output_postprocess field in the database.
+	FieldOutputPostprocess = "output_postprocess"
+	// FieldLanguageRouting holds the string denoting the language_routing field in the database.
+	FieldLanguageRouting = "language_routing"
+	// FieldStreamReplay holds the string denoting the stream_replay field in the database.
+	FieldStreamReplay = "stream_replay"
+	// FieldCapabilityCheck holds the string denoting the capability_check field in the database.
+	FieldCapabilityCheck = "capability_check"
+	// FieldAutoContinuation holds the string denoting the auto_continuation field in the database.
+	FieldAutoContinuation = "auto_continuation"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -195,6 +223,7 @@ var Columns = []string{
 	FieldPeakStart,
 	FieldPeakEnd,
 	FieldPeakRateMultiplier,
+	FieldRequestSurcharge,
 	FieldIsExclusive,
 	FieldStatus,
 	FieldPlatform,
@@ -232,6 +261,19 @@ var Columns = []string{
 	FieldMessagesDispatchModelConfig,
 	FieldModelsListConfig,
 	FieldRpmLimit,
+	FieldReservedConcurrencyRatio,
+	FieldOverloadFallbackModels,
+	FieldContextOverflowModels,
+	FieldContextOverflowReject,
+	FieldContextTruncationStrategy,
+	FieldAccountSelectionStrategy,
+	FieldImageMaxEdge,
+	FieldImageMaxBytes,
+	FieldOutputPostprocess,
+	FieldLanguageRouting,
+	FieldStreamReplay,
+	FieldCapabilityCheck,
+	FieldAutoContinuation,
 }
 
 var (
@@ -283,6 +325,8 @@ var (
 	PeakEndValidator func(string) error
 	// DefaultPeakRateMultiplier holds the default value on creation for the "peak_rate_multiplier" field.
 	DefaultPeakRateMultiplier float64
+	// DefaultRequestSurcharge holds the default value on creation for the "request_surcharge" field.
+	DefaultRequestSurcharge float64
 	// DefaultIsExclusive holds the default value on creation for the "is_exclusive" field.
 	DefaultIsExclusive bool
 	// DefaultStatus holds the default value on creation for the "status" field.
@@ -341,6 +385,32 @@ var (
 	DefaultModelsListConfig domain.GroupModelsListConfig
 	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
 	DefaultRpmLimit int
+	// DefaultReservedConcurrencyRatio holds the default value on creation for the "reserved_concurrency_ratio" field.
+	DefaultReservedConcurrencyRatio float64
+	// DefaultContextOverflowReject holds the default value on creation for the "context_overflow_reject" field.
+	DefaultContextOverflowReject bool
+	// DefaultContextTruncationStrategy holds the default value on creation for the "context_truncation_strategy" field.
+	DefaultContextTruncationStrategy string
+	// ContextTruncationStrategyValidator is a validator for the "context_truncation_strategy" field. It is called by the builders before save.
+	ContextTruncationStrategyValidator func(string) error
+	// DefaultAccountSelectionStrategy holds the default value on creation for the "account_selection_strategy" field.
+	DefaultAccountSelectionStrategy string
+	// AccountSelectionStrategyValidator is a validator for the "account_selection_strategy" field. It is called by the builders before save.
+	AccountSelectionStrategyValidator func(string) error
+	// DefaultImageMaxEdge holds the default value on creation for the "image_max_edge" field.
+	DefaultImageMaxEdge int
+	// DefaultImageMaxBytes holds the default value on creation for the "image_max_bytes" field.
+	DefaultImageMaxBytes int
+	// DefaultOutputPostprocess holds the default value on creation for the "output_postprocess" field.
+	DefaultOutputPostprocess domain.GroupOutputPostprocessConfig
+	// DefaultLanguageRouting holds the default value on creation for the "language_routing" field.
+	DefaultLanguageRouting domain.GroupLanguageRoutingConfig
+	// DefaultStreamReplay holds the default value on creation for the "stream_replay" field.
+	DefaultStreamReplay domain.GroupStreamReplayConfig
+	// DefaultCapabilityCheck holds the default value on creation for the "capability_check" field.
+	DefaultCapabilityCheck domain.GroupCapabilityCheckConfig
+	// DefaultAutoContinuation holds the default value on creation for the "auto_continuation" field.
+	DefaultAutoContinuation domain.GroupAutoContinuationConfig
 )
 
 // OrderOption defines the ordering options for the Group queries.
@@ -401,6 +471,11 @@ func ByPeakRateMultiplier(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldPeakRateMultiplier, opts...).ToFunc()
 }
 
+// ByRequestSurcharge orders the results by the request_surcharge field.
+func ByRequestSurcharge(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRequestSurcharge, opts...).ToFunc()
+}
+
 // ByIsExclusive orders the results by the is_exclusive field.
 func ByIsExclusive(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldIsExclusive, opts...).ToFunc()
@@ -566,6 +641,36 @@ func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
 }
 
+// ByReservedConcurrencyRatio orders the results by the reserved_concurrency_ratio field.
+func ByReservedConcurrencyRatio(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldReservedConcurrencyRatio, opts...).ToFunc()
+}
+
+// ByContextOverflowReject orders the results by the context_overflow_reject field.
+func ByContextOverflowReject(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldContextOverflowReject, opts...).ToFunc()
+}
+
+// ByContextTruncationStrategy orders the results by the context_truncation_strategy field.
+func ByContextTruncationStrategy(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldContextTruncationStrategy, opts...).ToFunc()
+}
+
+// ByAccountSelectionStrategy orders the results by the account_selection_strategy field.
+func ByAccountSelectionStrategy(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAccountSelectionStrategy, opts...).ToFunc()
+}
+
+// ByImageMaxEdge orders the results by the image_max_edge field.
+func ByImageMaxEdge(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldImageMaxEdge, opts...).ToFunc()
+}
+
+// ByImageMaxBytes orders the results by the image_max_bytes field.
+func ByImageMaxBytes(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldImageMaxBytes, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
@@ -105,6 +105,11 @@ func PeakRateMultiplier(v float64) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldPeakRateMultiplier, v))
 }
 
+// RequestSurcharge applies equality check predicate on the "request_surcharge" field. It's identical to RequestSurchargeEQ.
+func RequestSurcharge(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldRequestSurcharge, v))
+}
+
 // IsExclusive applies equality check predicate on the "is_exclusive" field. It's identical to IsExclusiveEQ.
 func IsExclusive(v bool) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldIsExclusive, v))
@@ -270,6 +275,36 @@ func RpmLimit(v int) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldRpmLimit, v))
 }
 
+// ReservedConcurrencyRatio applies equality check predicate on the "reserved_concurrency_ratio" field. It's identical to ReservedConcurrencyRatioEQ.
+func ReservedConcurrencyRatio(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldReservedConcurrencyRatio, v))
+}
+
+// ContextOverflowReject applies equality check predicate on the "context_overflow_reject" field. It's identical to ContextOverflowRejectEQ.
+func ContextOverflowReject(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldContextOverflowReject, v))
+}
+
+// ContextTruncationStrategy applies equality check predicate on the "context_truncation_strategy" field. It's identical to ContextTruncationStrategyEQ.
+func ContextTruncationStrategy(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldContextTruncationStrategy, v))
+}
+
+// AccountSelectionStrategy applies equality check predicate on the "account_selection_strategy" field. It's identical to AccountSelectionStrategyEQ.
+func AccountSelectionStrategy(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAccountSelectionStrategy, v))
+}
+
+// ImageMaxEdge applies equality check predicate on the "image_max_edge" field. It's identical to ImageMaxEdgeEQ.
+func ImageMaxEdge(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldImageMaxEdge, v))
+}
+
+// ImageMaxBytes applies equality check predicate on the "image_max_bytes" field. It's identical to ImageMaxBytesEQ.
+func ImageMaxBytes(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldImageMaxBytes, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldCreatedAt, v))
@@ -760,6 +795,46 @@ func PeakRateMultiplierLTE(v float64) predicate.Group {
 	return predicate.Group(sql.FieldLTE(FieldPeakRateMultiplier, v))
 }
 
+// RequestSurchargeEQ applies the EQ predicate on the "request_surcharge" field.
+func RequestSurchargeEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldRequestSurcharge, v))
+}
+
+// RequestSurchargeNEQ applies the NEQ predicate on the "request_surcharge" field.
+func RequestSurchargeNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldRequestSurcharge, v))
+}
+
+// RequestSurchargeIn applies the In predicate on the "request_surcharge" field.
+func RequestSurchargeIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldRequestSurcharge, vs...))
+}
+
+// RequestSurchargeNotIn applies the NotIn predicate on the "request_surcharge" field.
+func RequestSurchargeNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldRequestSurcharge, vs...))
+}
+
+// RequestSurchargeGT applies the GT predicate on the "request_surcharge" field.
+func RequestSurchargeGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldRequestSurcharge, v))
+}
+
+// RequestSurchargeGTE applies the GTE predicate on the "request_surcharge" field.
+func RequestSurchargeGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldRequestSurcharge, v))
+}
+
+// RequestSurchargeLT applies the LT predicate on the "request_surcharge" field.
+func RequestSurchargeLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldRequestSurcharge, v))
+}
+
+// RequestSurchargeLTE applies the LTE predicate on the "request_surcharge" field.
+func RequestSurchargeLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldRequestSurcharge, v))
+}
+
 // IsExclusiveEQ applies the EQ predicate on the "is_exclusive" field.
 func IsExclusiveEQ(v bool) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldIsExclusive, v))
@@ -1970,6 +2045,286 @@ func RpmLimitLTE(v int) predicate.Group {
 	return predicate.Group(sql.FieldLTE(FieldRpmLimit, v))
 }
 
+// ReservedConcurrencyRatioEQ applies the EQ predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldReservedConcurrencyRatio, v))
+}
+
+// ReservedConcurrencyRatioNEQ applies the NEQ predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldReservedConcurrencyRatio, v))
+}
+
+// ReservedConcurrencyRatioIn applies the In predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldReservedConcurrencyRatio, vs...))
+}
+
+// ReservedConcurrencyRatioNotIn applies the NotIn predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldReservedConcurrencyRatio, vs...))
+}
+
+// ReservedConcurrencyRatioGT applies the GT predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldReservedConcurrencyRatio, v))
+}
+
+// ReservedConcurrencyRatioGTE applies the GTE predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldReservedConcurrencyRatio, v))
+}
+
+// ReservedConcurrencyRatioLT applies the LT predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldReservedConcurrencyRatio, v))
+}
+
+// ReservedConcurrencyRatioLTE applies the LTE predicate on the "reserved_concurrency_ratio" field.
+func ReservedConcurrencyRatioLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldReservedConcurrencyRatio, v))
+}
+
+// OverloadFallbackModelsIsNil applies the IsNil predicate on the "overload_fallback_models" field.
+func OverloadFallbackModelsIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldOverloadFallbackModels))
+}
+
+// OverloadFallbackModelsNotNil applies the NotNil predicate on the "overload_fallback_models" field.
+func OverloadFallbackModelsNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldOverloadFallbackModels))
+}
+
+// ContextOverflowModelsIsNil applies the IsNil predicate on the "context_overflow_models" field.
+func ContextOverflowModelsIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldContextOverflowModels))
+}
+
+// ContextOverflowModelsNotNil applies the NotNil predicate on the "context_overflow_models" field.
+func ContextOverflowModelsNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldContextOverflowModels))
+}
+
+// ContextOverflowRejectEQ applies the EQ predicate on the "context_overflow_reject" field.
+func ContextOverflowRejectEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldContextOverflowReject, v))
+}
+
+// ContextOverflowRejectNEQ applies the NEQ predicate on the "context_overflow_reject" field.
+func ContextOverflowRejectNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldContextOverflowReject, v))
+}
+
+// ContextTruncationStrategyEQ applies the EQ predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyNEQ applies the NEQ predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyIn applies the In predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldContextTruncationStrategy, vs...))
+}
+
+// ContextTruncationStrategyNotIn applies the NotIn predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldContextTruncationStrategy, vs...))
+}
+
+// ContextTruncationStrategyGT applies the GT predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyGTE applies the GTE predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyLT applies the LT predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyLTE applies the LTE predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyContains applies the Contains predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyHasPrefix applies the HasPrefix predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyHasSuffix applies the HasSuffix predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyEqualFold applies the EqualFold predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldContextTruncationStrategy, v))
+}
+
+// ContextTruncationStrategyContainsFold applies the ContainsFold predicate on the "context_truncation_strategy" field.
+func ContextTruncationStrategyContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldContextTruncationStrategy, v))
+}
+
+// AccountSelectionStrategyEQ applies the EQ predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyNEQ applies the NEQ predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyIn applies the In predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldAccountSelectionStrategy, vs...))
+}
+
+// AccountSelectionStrategyNotIn applies the NotIn predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldAccountSelectionStrategy, vs...))
+}
+
+// AccountSelectionStrategyGT applies the GT predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyGTE applies the GTE predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyLT applies the LT predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyLTE applies the LTE predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyContains applies the Contains predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyHasPrefix applies the HasPrefix predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyHasSuffix applies the HasSuffix predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyEqualFold applies the EqualFold predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldAccountSelectionStrategy, v))
+}
+
+// AccountSelectionStrategyContainsFold applies the ContainsFold predicate on the "account_selection_strategy" field.
+func AccountSelectionStrategyContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldAccountSelectionStrategy, v))
+}
+
+// ImageMaxEdgeEQ applies the EQ predicate on the "image_max_edge" field.
+func ImageMaxEdgeEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldImageMaxEdge, v))
+}
+
+// ImageMaxEdgeNEQ applies the NEQ predicate on the "image_max_edge" field.
+func ImageMaxEdgeNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldImageMaxEdge, v))
+}
+
+// ImageMaxEdgeIn applies the In predicate on the "image_max_edge" field.
+func ImageMaxEdgeIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldImageMaxEdge, vs...))
+}
+
+// ImageMaxEdgeNotIn applies the NotIn predicate on the "image_max_edge" field.
+func ImageMaxEdgeNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldImageMaxEdge, vs...))
+}
+
+// ImageMaxEdgeGT applies the GT predicate on the "image_max_edge" field.
+func ImageMaxEdgeGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldImageMaxEdge, v))
+}
+
+// ImageMaxEdgeGTE applies the GTE predicate on the "image_max_edge" field.
+func ImageMaxEdgeGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldImageMaxEdge, v))
+}
+
+// ImageMaxEdgeLT applies the LT predicate on the "image_max_edge" field.
+func ImageMaxEdgeLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldImageMaxEdge, v))
+}
+
+// ImageMaxEdgeLTE applies the LTE predicate on the "image_max_edge" field.
+func ImageMaxEdgeLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldImageMaxEdge, v))
+}
+
+// ImageMaxBytesEQ applies the EQ predicate on the "image_max_bytes" field.
+func ImageMaxBytesEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldImageMaxBytes, v))
+}
+
+// ImageMaxBytesNEQ applies the NEQ predicate on the "image_max_bytes" field.
+func ImageMaxBytesNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldImageMaxBytes, v))
+}
+
+// ImageMaxBytesIn applies the In predicate on the "image_max_bytes" field.
+func ImageMaxBytesIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldImageMaxBytes, vs...))
+}
+
+// ImageMaxBytesNotIn applies the NotIn predicate on the "image_max_bytes" field.
+func ImageMaxBytesNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldImageMaxBytes, vs...))
+}
+
+// ImageMaxBytesGT applies the GT predicate on the "image_max_bytes" field.
+func ImageMaxBytesGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldImageMaxBytes, v))
+}
+
+// ImageMaxBytesGTE applies the GTE predicate on the "image_max_bytes" field.
+func ImageMaxBytesGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldImageMaxBytes, v))
+}
+
+// ImageMaxBytesLT applies the LT predicate on the "image_max_bytes" field.
+func ImageMaxBytesLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldImageMaxBytes, v))
+}
+
+// ImageMaxBytesLTE applies the LTE predicate on the "image_max_bytes" field.
+func ImageMaxBytesLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldImageMaxBytes, v))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.Group {
 	return predicate.Group(func(s *sql.Selector) {
@@ -161,6 +161,20 @@ func (_c *GroupCreate) SetNillablePeakRateMultiplier(v *float64) *GroupCreate {
 	return _c
 }
 
+// SetRequestSurcharge sets the "request_surcharge" field.
+func (_c *GroupCreate) SetRequestSurcharge(v float64) *GroupCreate {
+	_c.mutation.SetRequestSurcharge(v)
+	return _c
+}
+
+// SetNillableRequestSurcharge sets the "request_surcharge" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableRequestSurcharge(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetRequestSurcharge(*v)
+	}
+	return _c
+}
+
 // SetIsExclusive sets the "is_exclusive" field.
 func (_c *GroupCreate) SetIsExclusive(v bool) *GroupCreate {
 	_c.mutation.SetIsExclusive(v)
@@ -663,6 +677,172 @@ func (_c *GroupCreate) SetNillableRpmLimit(v *int) *GroupCreate {
 	return _c
 }
 
+// SetReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field.
+func (_c *GroupCreate) SetReservedConcurrencyRatio(v float64) *GroupCreate {
+	_c.mutation.SetReservedConcurrencyRatio(v)
+	return _c
+}
+
+// SetNillableReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableReservedConcurrencyRatio(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetReservedConcurrencyRatio(*v)
+	}
+	return _c
+}
+
+// SetOverloadFallbackModels sets the "overload_fallback_models" field.
+func (_c *GroupCreate) SetOverloadFallbackModels(v map[string]string) *GroupCreate {
+	_c.mutation.SetOverloadFallbackModels(v)
+	return _c
+}
+
+// SetContextOverflowModels sets the "context_overflow_models" field.
+func (_c *GroupCreate) SetContextOverflowModels(v map[string]string) *GroupCreate {
+	_c.mutation.SetContextOverflowModels(v)
+	return _c
+}
+
+// SetContextOverflowReject sets the "context_overflow_reject" field.
+func (_c *GroupCreate) SetContextOverflowReject(v bool) *GroupCreate {
+	_c.mutation.SetContextOverflowReject(v)
+	return _c
+}
+
+// SetNillableContextOverflowReject sets the "context_overflow_reject" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableContextOverflowReject(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetContextOverflowReject(*v)
+	}
+	return _c
+}
+
+// SetContextTruncationStrategy sets the "context_truncation_strategy" field.
+func (_c *GroupCreate) SetContextTruncationStrategy(v string) *GroupCreate {
+	_c.mutation.SetContextTruncationStrategy(v)
+	return _c
+}
+
+// SetNillableContextTruncationStrategy sets the "context_truncation_strategy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableContextTruncationStrategy(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetContextTruncationStrategy(*v)
+	}
+	return _c
+}
+
+// SetAccountSelectionStrategy sets the "account_selection_strategy" field.
+func (_c *GroupCreate) SetAccountSelectionStrategy(v string) *GroupCreate {
+	_c.mutation.SetAccountSelectionStrategy(v)
+	return _c
+}
+
+// SetNillableAccountSelectionStrategy sets the "account_selection_strategy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAccountSelectionStrategy(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetAccountSelectionStrategy(*v)
+	}
+	return _c
+}
+
+// SetImageMaxEdge sets the "image_max_edge" field.
+func (_c *GroupCreate) SetImageMaxEdge(v int) *GroupCreate {
+	_c.mutation.SetImageMaxEdge(v)
+	return _c
+}
+
+// SetNillableImageMaxEdge sets the "image_max_edge" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableImageMaxEdge(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetImageMaxEdge(*v)
+	}
+	return _c
+}
+
+// SetImageMaxBytes sets the "image_max_bytes" field.
+func (_c *GroupCreate) SetImageMaxBytes(v int) *GroupCreate {
+	_c.mutation.SetImageMaxBytes(v)
+	return _c
+}
+
+// SetNillableImageMaxBytes sets the "image_max_bytes" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableImageMaxBytes(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetImageMaxBytes(*v)
+	}
+	return _c
+}
+
+// SetOutputPostprocess sets the "output_postprocess" field.
+func (_c *GroupCreate) SetOutputPostprocess(v domain.GroupOutputPostprocessConfig) *GroupCreate {
+	_c.mutation.SetOutputPostprocess(v)
+	return _c
+}
+
+// SetNillableOutputPostprocess sets the "output_postprocess" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableOutputPostprocess(v *domain.GroupOutputPostprocessConfig) *GroupCreate {
+	if v != nil {
+		_c.SetOutputPostprocess(*v)
+	}
+	return _c
+}
+
+// SetLanguageRouting sets the "language_routing" field.
+func (_c *GroupCreate) SetLanguageRouting(v domain.GroupLanguageRoutingConfig) *GroupCreate {
+	_c.mutation.SetLanguageRouting(v)
+	return _c
+}
+
+// SetNillableLanguageRouting sets the "language_routing" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableLanguageRouting(v *domain.GroupLanguageRoutingConfig) *GroupCreate {
+	if v != nil {
+		_c.SetLanguageRouting(*v)
+	}
+	return _c
+}
+
+// SetStreamReplay sets the "stream_replay" field.
+func (_c *GroupCreate) SetStreamReplay(v domain.GroupStreamReplayConfig) *GroupCreate {
+	_c.mutation.SetStreamReplay(v)
+	return _c
+}
+
+// SetNillableStreamReplay sets the "stream_replay" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableStreamReplay(v *domain.GroupStreamReplayConfig) *GroupCreate {
+	if v != nil {
+		_c.SetStreamReplay(*v)
+	}
+	return _c
+}
+
+// SetCapabilityCheck sets the "capability_check" field.
+func (_c *GroupCreate) SetCapabilityCheck(v domain.GroupCapabilityCheckConfig) *GroupCreate {
+	_c.mutation.SetCapabilityCheck(v)
+	return _c
+}
+
+// SetNillableCapabilityCheck sets the "capability_check" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableCapabilityCheck(v *domain.GroupCapabilityCheckConfig) *GroupCreate {
+	if v != nil {
+		_c.SetCapabilityCheck(*v)
+	}
+	return _c
+}
+
+// SetAutoContinuation sets the "auto_continuation" field.
+func (_c *GroupCreate) SetAutoContinuation(v domain.GroupAutoContinuationConfig) *GroupCreate {
+	_c.mutation.SetAutoContinuation(v)
+	return _c
+}
+
+// SetNillableAutoContinuation sets the "auto_continuation" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAutoContinuation(v *domain.GroupAutoContinuationConfig) *GroupCreate {
+	if v != nil {
+		_c.SetAutoContinuation(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -824,6 +1004,10 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultPeakRateMultiplier
 		_c.mutation.SetPeakRateMultiplier(v)
 	}
+	if _, ok := _c.mutation.RequestSurcharge(); !ok {
+		v := group.DefaultRequestSurcharge
+		_c.mutation.SetRequestSurcharge(v)
+	}
 	if _, ok := _c.mutation.IsExclusive(); !ok {
 		v := group.DefaultIsExclusive
 		_c.mutation.SetIsExclusive(v)
@@ -924,6 +1108,50 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultRpmLimit
 		_c.mutation.SetRpmLimit(v)
 	}
+	if _, ok := _c.mutation.ReservedConcurrencyRatio(); !ok {
+		v := group.DefaultReservedConcurrencyRatio
+		_c.mutation.SetReservedConcurrencyRatio(v)
+	}
+	if _, ok := _c.mutation.ContextOverflowReject(); !ok {
+		v := group.DefaultContextOverflowReject
+		_c.mutation.SetContextOverflowReject(v)
+	}
+	if _, ok := _c.mutation.ContextTruncationStrategy(); !ok {
+		v := group.DefaultContextTruncationStrategy
+		_c.mutation.SetContextTruncationStrategy(v)
+	}
+	if _, ok := _c.mutation.AccountSelectionStrategy(); !ok {
+		v := group.DefaultAccountSelectionStrategy
+		_c.mutation.SetAccountSelectionStrategy(v)
+	}
+	if _, ok := _c.mutation.ImageMaxEdge(); !ok {
+		v := group.DefaultImageMaxEdge
+		_c.mutation.SetImageMaxEdge(v)
+	}
+	if _, ok := _c.mutation.ImageMaxBytes(); !ok {
+		v := group.DefaultImageMaxBytes
+		_c.mutation.SetImageMaxBytes(v)
+	}
+	if _, ok := _c.mutation.OutputPostprocess(); !ok {
+		v := group.DefaultOutputPostprocess
+		_c.mutation.SetOutputPostprocess(v)
+	}
+	if _, ok := _c.mutation.LanguageRouting(); !ok {
+		v := group.DefaultLanguageRouting
+		_c.mutation.SetLanguageRouting(v)
+	}
+	if _, ok := _c.mutation.StreamReplay(); !ok {
+		v := group.DefaultStreamReplay
+		_c.mutation.SetStreamReplay(v)
+	}
+	if _, ok := _c.mutation.CapabilityCheck(); !ok {
+		v := group.DefaultCapabilityCheck
+		_c.mutation.SetCapabilityCheck(v)
+	}
+	if _, ok := _c.mutation.AutoContinuation(); !ok {
+		v := group.DefaultAutoContinuation
+		_c.mutation.SetAutoContinuation(v)
+	}
 	return nil
 }
 
@@ -968,6 +1196,9 @@ func (_c *GroupCreate) check() error {
 	if _, ok := _c.mutation.PeakRateMultiplier(); !ok {
 		return &ValidationError{Name: "peak_rate_multiplier", err: errors.New(`ent: missing required field "Group.peak_rate_multiplier"`)}
 	}
+	if _, ok := _c.mutation.RequestSurcharge(); !ok {
+		return &ValidationError{Name: "request_surcharge", err: errors.New(`ent: missing required field "Group.request_surcharge"`)}
+	}
 	if _, ok := _c.mutation.IsExclusive(); !ok {
 		return &ValidationError{Name: "is_exclusive", err: errors.New(`ent: missing required field "Group.is_exclusive"`)}
 	}
@@ -1063,6 +1294,49 @@ func (_c *GroupCreate) check() error {
 	if _, ok := _c.mutation.RpmLimit(); !ok {
 		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "Group.rpm_limit"`)}
 	}
+	if _, ok := _c.mutation.ReservedConcurrencyRatio(); !ok {
+		return &ValidationError{Name: "reserved_concurrency_ratio", err: errors.New(`ent: missing required field "Group.reserved_concurrency_ratio"`)}
+	}
+	if _, ok := _c.mutation.ContextOverflowReject(); !ok {
+		return &ValidationError{Name: "context_overflow_reject", err: errors.New(`ent: missing required field "Group.context_overflow_reject"`)}
+	}
+	if _, ok := _c.mutation.ContextTruncationStrategy(); !ok {
+		return &ValidationError{Name: "context_truncation_strategy", err: errors.New(`ent: missing required field "Group.context_truncation_strategy"`)}
+	}
+	if v, ok := _c.mutation.ContextTruncationStrategy(); ok {
+		if err := group.ContextTruncationStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "context_truncation_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.context_truncation_strategy": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.AccountSelectionStrategy(); !ok {
+		return &ValidationError{Name: "account_selection_strategy", err: errors.New(`ent: missing required field "Group.account_selection_strategy"`)}
+	}
+	if v, ok := _c.mutation.AccountSelectionStrategy(); ok {
+		if err := group.AccountSelectionStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "account_selection_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.account_selection_strategy": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.ImageMaxEdge(); !ok {
+		return &ValidationError{Name: "image_max_edge", err: errors.New(`ent: missing required field "Group.image_max_edge"`)}
+	}
+	if _, ok := _c.mutation.ImageMaxBytes(); !ok {
+		return &ValidationError{Name: "image_max_bytes", err: errors.New(`ent: missing required field "Group.image_max_bytes"`)}
+	}
+	if _, ok := _c.mutation.OutputPostprocess(); !ok {
+		return &ValidationError{Name: "output_postprocess", err: errors.New(`ent: missing required field "Group.output_postprocess"`)}
+	}
+	if _, ok := _c.mutation.LanguageRouting(); !ok {
+		return &ValidationError{Name: "language_routing", err: errors.New(`ent: missing required field "Group.language_routing"`)}
+	}
+	if _, ok := _c.mutation.StreamReplay(); !ok {
+		return &ValidationError{Name: "stream_replay", err: errors.New(`ent: missing required field "Group.stream_replay"`)}
+	}
+	if _, ok := _c.mutation.CapabilityCheck(); !ok {
+		return &ValidationError{Name: "capability_check", err: errors.New(`ent: missing required field "Group.capability_check"`)}
+	}
+	if _, ok := _c.mutation.AutoContinuation(); !ok {
+		return &ValidationError{Name: "auto_continuation", err: errors.New(`ent: missing required field "Group.auto_continuation"`)}
+	}
 	return nil
 }
 
@@ -1130,6 +1404,10 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldPeakRateMultiplier, field.TypeFloat64, value)
 		_node.PeakRateMultiplier = value
 	}
+	if value, ok := _c.mutation.RequestSurcharge(); ok {
+		_spec.SetField(group.FieldRequestSurcharge, field.TypeFloat64, value)
+		_node.RequestSurcharge = value
+	}
 	if value, ok := _c.mutation.IsExclusive(); ok {
 		_spec.SetField(group.FieldIsExclusive, field.TypeBool, value)
 		_node.IsExclusive = value
@@ -1278,6 +1556,58 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldRpmLimit, field.TypeInt, value)
 		_node.RpmLimit = value
 	}
+	if value, ok := _c.mutation.ReservedConcurrencyRatio(); ok {
+		_spec.SetField(group.FieldReservedConcurrencyRatio, field.TypeFloat64, value)
+		_node.ReservedConcurrencyRatio = value
+	}
+	if value, ok := _c.mutation.OverloadFallbackModels(); ok {
+		_spec.SetField(group.FieldOverloadFallbackModels, field.TypeJSON, value)
+		_node.OverloadFallbackModels = value
+	}
+	if value, ok := _c.mutation.ContextOverflowModels(); ok {
+		_spec.SetField(group.FieldContextOverflowModels, field.TypeJSON, value)
+		_node.ContextOverflowModels = value
+	}
+	if value, ok := _c.mutation.ContextOverflowReject(); ok {
+		_spec.SetField(group.FieldContextOverflowReject, field.TypeBool, value)
+		_node.ContextOverflowReject = value
+	}
+	if value, ok := _c.mutation.ContextTruncationStrategy(); ok {
+		_spec.SetField(group.FieldContextTruncationStrategy, field.TypeString, value)
+		_node.ContextTruncationStrategy = value
+	}
+	if value, ok := _c.mutation.AccountSelectionStrategy(); ok {
+		_spec.SetField(group.FieldAccountSelectionStrategy, field.TypeString, value)
+		_node.AccountSelectionStrategy = value
+	}
+	if value, ok := _c.mutation.ImageMaxEdge(); ok {
+		_spec.SetField(group.FieldImageMaxEdge, field.TypeInt, value)
+		_node.ImageMaxEdge = value
+	}
+	if value, ok := _c.mutation.ImageMaxBytes(); ok {
+		_spec.SetField(group.FieldImageMaxBytes, field.TypeInt, value)
+		_node.ImageMaxBytes = value
+	}
+	if value, ok := _c.mutation.OutputPostprocess(); ok {
+		_spec.SetField(group.FieldOutputPostprocess, field.TypeJSON, value)
+		_node.OutputPostprocess = value
+	}
+	if value, ok := _c.mutation.LanguageRouting(); ok {
+		_spec.SetField(group.FieldLanguageRouting, field.TypeJSON, value)
+		_node.LanguageRouting = value
+	}
+	if value, ok := _c.mutation.StreamReplay(); ok {
+		_spec.SetField(group.FieldStreamReplay, field.TypeJSON, value)
+		_node.StreamReplay = value
+	}
+	if value, ok := _c.mutation.CapabilityCheck(); ok {
+		_spec.SetField(group.FieldCapabilityCheck, field.TypeJSON, value)
+		_node.CapabilityCheck = value
+	}
+	if value, ok := _c.mutation.AutoContinuation(); ok {
+		_spec.SetField(group.FieldAutoContinuation, field.TypeJSON, value)
+		_node.AutoContinuation = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1566,6 +1896,24 @@ func (u *GroupUpsert) AddPeakRateMultiplier(v float64) *GroupUpsert {
 	return u
 }
 
+// SetRequestSurcharge sets the "request_surcharge" field.
+func (u *GroupUpsert) SetRequestSurcharge(v float64) *GroupUpsert {
+	u.Set(group.FieldRequestSurcharge, v)
+	return u
+}
+
+// UpdateRequestSurcharge sets the "request_surcharge" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateRequestSurcharge() *GroupUpsert {
+	u.SetExcluded(group.FieldRequestSurcharge)
+	return u
+}
+
+// AddRequestSurcharge adds v to the "request_surcharge" field.
+func (u *GroupUpsert) AddRequestSurcharge(v float64) *GroupUpsert {
+	u.Add(group.FieldRequestSurcharge, v)
+	return u
+}
+
 // SetIsExclusive sets the "is_exclusive" field.
 func (u *GroupUpsert) SetIsExclusive(v bool) *GroupUpsert {
 	u.Set(group.FieldIsExclusive, v)
@@ -2190,6 +2538,192 @@ func (u *GroupUpsert) AddRpmLimit(v int) *GroupUpsert {
 	return u
 }
 
+// SetReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field.
+func (u *GroupUpsert) SetReservedConcurrencyRatio(v float64) *GroupUpsert {
+	u.Set(group.FieldReservedConcurrencyRatio, v)
+	return u
+}
+
+// UpdateReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateReservedConcurrencyRatio() *GroupUpsert {
+	u.SetExcluded(group.FieldReservedConcurrencyRatio)
+	return u
+}
+
+// AddReservedConcurrencyRatio adds v to the "reserved_concurrency_ratio" field.
+func (u *GroupUpsert) AddReservedConcurrencyRatio(v float64) *GroupUpsert {
+	u.Add(group.FieldReservedConcurrencyRatio, v)
+	return u
+}
+
+// SetOverloadFallbackModels sets the "overload_fallback_models" field.
+func (u *GroupUpsert) SetOverloadFallbackModels(v map[string]string) *GroupUpsert {
+	u.Set(group.FieldOverloadFallbackModels, v)
+	return u
+}
+
+// UpdateOverloadFallbackModels sets the "overload_fallback_models" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateOverloadFallbackModels() *GroupUpsert {
+	u.SetExcluded(group.FieldOverloadFallbackModels)
+	return u
+}
+
+// ClearOverloadFallbackModels clears the value of the "overload_fallback_models" field.
+func (u *GroupUpsert) ClearOverloadFallbackModels() *GroupUpsert {
+	u.SetNull(group.FieldOverloadFallbackModels)
+	return u
+}
+
+// SetContextOverflowModels sets the "context_overflow_models" field.
+func (u *GroupUpsert) SetContextOverflowModels(v map[string]string) *GroupUpsert {
+	u.Set(group.FieldContextOverflowModels, v)
+	return u
+}
+
+// UpdateContextOverflowModels sets the "context_overflow_models" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateContextOverflowModels() *GroupUpsert {
+	u.SetExcluded(group.FieldContextOverflowModels)
+	return u
+}
+
+// ClearContextOverflowModels clears the value of the "context_overflow_models" field.
+func (u *GroupUpsert) ClearContextOverflowModels() *GroupUpsert {
+	u.SetNull(group.FieldContextOverflowModels)
+	return u
+}
+
+// SetContextOverflowReject sets the "context_overflow_reject" field.
+func (u *GroupUpsert) SetContextOverflowReject(v bool) *GroupUpsert {
+	u.Set(group.FieldContextOverflowReject, v)
+	return u
+}
+
+// UpdateContextOverflowReject sets the "context_overflow_reject" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateContextOverflowReject() *GroupUpsert {
+	u.SetExcluded(group.FieldContextOverflowReject)
+	return u
+}
+
+// SetContextTruncationStrategy sets the "context_truncation_strategy" field.
+func (u *GroupUpsert) SetContextTruncationStrategy(v string) *GroupUpsert {
+	u.Set(group.FieldContextTruncationStrategy, v)
+	return u
+}
+
+// UpdateContextTruncationStrategy sets the "context_truncation_strategy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateContextTruncationStrategy() *GroupUpsert {
+	u.SetExcluded(group.FieldContextTruncationStrategy)
+	return u
+}
+
+// SetAccountSelectionStrategy sets the "account_selection_strategy" field.
+func (u *GroupUpsert) SetAccountSelectionStrategy(v string) *GroupUpsert {
+	u.Set(group.FieldAccountSelectionStrategy, v)
+	return u
+}
+
+// UpdateAccountSelectionStrategy sets the "account_selection_strategy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAccountSelectionStrategy() *GroupUpsert {
+	u.SetExcluded(group.FieldAccountSelectionStrategy)
+	return u
+}
+
+// SetImageMaxEdge sets the "image_max_edge" field.
+func (u *GroupUpsert) SetImageMaxEdge(v int) *GroupUpsert {
+	u.Set(group.FieldImageMaxEdge, v)
+	return u
+}
+
+// UpdateImageMaxEdge sets the "image_max_edge" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateImageMaxEdge() *GroupUpsert {
+	u.SetExcluded(group.FieldImageMaxEdge)
+	return u
+}
+
+// AddImageMaxEdge adds v to the "image_max_edge" field.
+func (u *GroupUpsert) AddImageMaxEdge(v int) *GroupUpsert {
+	u.Add(group.FieldImageMaxEdge, v)
+	return u
+}
+
+// SetImageMaxBytes sets the "image_max_bytes" field.
+func (u *GroupUpsert) SetImageMaxBytes(v int) *GroupUpsert {
+	u.Set(group.FieldImageMaxBytes, v)
+	return u
+}
+
+// UpdateImageMaxBytes sets the "image_max_bytes" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateImageMaxBytes() *GroupUpsert {
+	u.SetExcluded(group.FieldImageMaxBytes)
+	return u
+}
+
+// AddImageMaxBytes adds v to the "image_max_bytes" field.
+func (u *GroupUpsert) AddImageMaxBytes(v int) *GroupUpsert {
+	u.Add(group.FieldImageMaxBytes, v)
+	return u
+}
+
+// SetOutputPostprocess sets the "output_postprocess" field.
+func (u *GroupUpsert) SetOutputPostprocess(v domain.GroupOutputPostprocessConfig) *GroupUpsert {
+	u.Set(group.FieldOutputPostprocess, v)
+	return u
+}
+
+// UpdateOutputPostprocess sets the "output_postprocess" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateOutputPostprocess() *GroupUpsert {
+	u.SetExcluded(group.FieldOutputPostprocess)
+	return u
+}
+
+// SetLanguageRouting sets the "language_routing" field.
+func (u *GroupUpsert) SetLanguageRouting(v domain.GroupLanguageRoutingConfig) *GroupUpsert {
+	u.Set(group.FieldLanguageRouting, v)
+	return u
+}
+
+// UpdateLanguageRouting sets the "language_routing" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateLanguageRouting() *GroupUpsert {
+	u.SetExcluded(group.FieldLanguageRouting)
+	return u
+}
+
+// SetStreamReplay sets the "stream_replay" field.
+func (u *GroupUpsert) SetStreamReplay(v domain.GroupStreamReplayConfig) *GroupUpsert {
+	u.Set(group.FieldStreamReplay, v)
+	return u
+}
+
+// UpdateStreamReplay sets the "stream_replay" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateStreamReplay() *GroupUpsert {
+	u.SetExcluded(group.FieldStreamReplay)
+	return u
+}
+
+// SetCapabilityCheck sets the "capability_check" field.
+func (u *GroupUpsert) SetCapabilityCheck(v domain.GroupCapabilityCheckConfig) *GroupUpsert {
+	u.Set(group.FieldCapabilityCheck, v)
+	return u
+}
+
+// UpdateCapabilityCheck sets the "capability_check" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateCapabilityCheck() *GroupUpsert {
+	u.SetExcluded(group.FieldCapabilityCheck)
+	return u
+}
+
+// SetAutoContinuation sets the "auto_continuation" field.
+func (u *GroupUpsert) SetAutoContinuation(v domain.GroupAutoContinuationConfig) *GroupUpsert {
+	u.Set(group.FieldAutoContinuation, v)
+	return u
+}
+
+// UpdateAutoContinuation sets the "auto_continuation" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAutoContinuation() *GroupUpsert {
+	u.SetExcluded(group.FieldAutoContinuation)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -2389,28 +2923,49 @@ func (u *GroupUpsertOne) UpdatePeakRateMultiplier() *GroupUpsertOne {
 	})
 }
 
-// SetIsExclusive sets the "is_exclusive" field.
-func (u *GroupUpsertOne) SetIsExclusive(v bool) *GroupUpsertOne {
+// SetRequestSurcharge sets the "request_surcharge" field.
+func (u *GroupUpsertOne) SetRequestSurcharge(v float64) *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
-		s.SetIsExclusive(v)
+		s.SetRequestSurcharge(v)
 	})
 }
 
-// UpdateIsExclusive sets the "is_exclusive" field to the value that was provided on create.
-func (u *GroupUpsertOne) UpdateIsExclusive() *GroupUpsertOne {
+// AddRequestSurcharge adds v to the "request_surcharge" field.
+func (u *GroupUpsertOne) AddRequestSurcharge(v float64) *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
-		s.UpdateIsExclusive()
+		s.AddRequestSurcharge(v)
 	})
 }
 
-// SetStatus sets the "status" field.
-func (u *GroupUpsertOne) SetStatus(v string) *GroupUpsertOne {
+// UpdateRequestSurcharge sets the "request_surcharge" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateRequestSurcharge() *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
-		s.SetStatus(v)
+		s.UpdateRequestSurcharge()
 	})
 }
 
-// UpdateStatus sets the "status" field to the value that was provided on create.
+// SetIsExclusive sets the "is_exclusive" field.
+func (u *GroupUpsertOne) SetIsExclusive(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetIsExclusive(v)
+	})
+}
+
+// UpdateIsExclusive sets the "is_exclusive" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateIsExclusive() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateIsExclusive()
+	})
+}
+
+// SetStatus sets the "status" field.
+func (u *GroupUpsertOne) SetStatus(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetStatus(v)
+	})
+}
+
+// UpdateStatus sets the "status" field to the value that was provided on create.
 func (u *GroupUpsertOne) UpdateStatus() *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
 		s.UpdateStatus()
@@ -3117,6 +3672,223 @@ func (u *GroupUpsertOne) UpdateRpmLimit() *GroupUpsertOne {
 	})
 }
 
+// SetReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field.
+func (u *GroupUpsertOne) SetReservedConcurrencyRatio(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetReservedConcurrencyRatio(v)
+	})
+}
+
+// AddReservedConcurrencyRatio adds v to the "reserved_concurrency_ratio" field.
+func (u *GroupUpsertOne) AddReservedConcurrencyRatio(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddReservedConcurrencyRatio(v)
+	})
+}
+
+// UpdateReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateReservedConcurrencyRatio() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateReservedConcurrencyRatio()
+	})
+}
+
+// SetOverloadFallbackModels sets the "overload_fallback_models" field.
+func (u *GroupUpsertOne) SetOverloadFallbackModels(v map[string]string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetOverloadFallbackModels(v)
+	})
+}
+
+// UpdateOverloadFallbackModels sets the "overload_fallback_models" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateOverloadFallbackModels() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateOverloadFallbackModels()
+	})
+}
+
+// ClearOverloadFallbackModels clears the value of the "overload_fallback_models" field.
+func (u *GroupUpsertOne) ClearOverloadFallbackModels() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearOverloadFallbackModels()
+	})
+}
+
+// SetContextOverflowModels sets the "context_overflow_models" field.
+func (u *GroupUpsertOne) SetContextOverflowModels(v map[string]string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextOverflowModels(v)
+	})
+}
+
+// UpdateContextOverflowModels sets the "context_overflow_models" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateContextOverflowModels() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextOverflowModels()
+	})
+}
+
+// ClearContextOverflowModels clears the value of the "context_overflow_models" field.
+func (u *GroupUpsertOne) ClearContextOverflowModels() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearContextOverflowModels()
+	})
+}
+
+// SetContextOverflowReject sets the "context_overflow_reject" field.
+func (u *GroupUpsertOne) SetContextOverflowReject(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextOverflowReject(v)
+	})
+}
+
+// UpdateContextOverflowReject sets the "context_overflow_reject" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateContextOverflowReject() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextOverflowReject()
+	})
+}
+
+// SetContextTruncationStrategy sets the "context_truncation_strategy" field.
+func (u *GroupUpsertOne) SetContextTruncationStrategy(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextTruncationStrategy(v)
+	})
+}
+
+// UpdateContextTruncationStrategy sets the "context_truncation_strategy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateContextTruncationStrategy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextTruncationStrategy()
+	})
+}
+
+// SetAccountSelectionStrategy sets the "account_selection_strategy" field.
+func (u *GroupUpsertOne) SetAccountSelectionStrategy(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAccountSelectionStrategy(v)
+	})
+}
+
+// UpdateAccountSelectionStrategy sets the "account_selection_strategy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAccountSelectionStrategy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAccountSelectionStrategy()
+	})
+}
+
+// SetImageMaxEdge sets the "image_max_edge" field.
+func (u *GroupUpsertOne) SetImageMaxEdge(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetImageMaxEdge(v)
+	})
+}
+
+// AddImageMaxEdge adds v to the "image_max_edge" field.
+func (u *GroupUpsertOne) AddImageMaxEdge(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddImageMaxEdge(v)
+	})
+}
+
+// UpdateImageMaxEdge sets the "image_max_edge" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateImageMaxEdge() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateImageMaxEdge()
+	})
+}
+
+// SetImageMaxBytes sets the "image_max_bytes" field.
+func (u *GroupUpsertOne) SetImageMaxBytes(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetImageMaxBytes(v)
+	})
+}
+
+// AddImageMaxBytes adds v to the "image_max_bytes" field.
+func (u *GroupUpsertOne) AddImageMaxBytes(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddImageMaxBytes(v)
+	})
+}
+
+// UpdateImageMaxBytes sets the "image_max_bytes" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateImageMaxBytes() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateImageMaxBytes()
+	})
+}
+
+// SetOutputPostprocess sets the "output_postprocess" field.
+func (u *GroupUpsertOne) SetOutputPostprocess(v domain.GroupOutputPostprocessConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetOutputPostprocess(v)
+	})
+}
+
+// UpdateOutputPostprocess sets the "output_postprocess" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateOutputPostprocess() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateOutputPostprocess()
+	})
+}
+
+// SetLanguageRouting sets the "language_routing" field.
+func (u *GroupUpsertOne) SetLanguageRouting(v domain.GroupLanguageRoutingConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetLanguageRouting(v)
+	})
+}
+
+// UpdateLanguageRouting sets the "language_routing" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateLanguageRouting() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateLanguageRouting()
+	})
+}
+
+// SetStreamReplay sets the "stream_replay" field.
+func (u *GroupUpsertOne) SetStreamReplay(v domain.GroupStreamReplayConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetStreamReplay(v)
+	})
+}
+
+// UpdateStreamReplay sets the "stream_replay" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateStreamReplay() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateStreamReplay()
+	})
+}
+
+// SetCapabilityCheck sets the "capability_check" field.
+func (u *GroupUpsertOne) SetCapabilityCheck(v domain.GroupCapabilityCheckConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetCapabilityCheck(v)
+	})
+}
+
+// UpdateCapabilityCheck sets the "capability_check" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateCapabilityCheck() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateCapabilityCheck()
+	})
+}
+
+// SetAutoContinuation sets the "auto_continuation" field.
+func (u *GroupUpsertOne) SetAutoContinuation(v domain.GroupAutoContinuationConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAutoContinuation(v)
+	})
+}
+
+// UpdateAutoContinuation sets the "auto_continuation" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAutoContinuation() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAutoContinuation()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -3482,6 +4254,27 @@ func (u *GroupUpsertBulk) UpdatePeakRateMultiplier() *GroupUpsertBulk {
 	})
 }
 
+// SetRequestSurcharge sets the "request_surcharge" field.
+func (u *GroupUpsertBulk) SetRequestSurcharge(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetRequestSurcharge(v)
+	})
+}
+
+// AddRequestSurcharge adds v to the "request_surcharge" field.
+func (u *GroupUpsertBulk) AddRequestSurcharge(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddRequestSurcharge(v)
+	})
+}
+
+// UpdateRequestSurcharge sets the "request_surcharge" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateRequestSurcharge() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateRequestSurcharge()
+	})
+}
+
 // SetIsExclusive sets the "is_exclusive" field.
 func (u *GroupUpsertBulk) SetIsExclusive(v bool) *GroupUpsertBulk {
 	return u.Update(func(s *GroupUpsert) {
@@ -4210,6 +5003,223 @@ func (u *GroupUpsertBulk) UpdateRpmLimit() *GroupUpsertBulk {
 	})
 }
 
+// SetReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field.
+func (u *GroupUpsertBulk) SetReservedConcurrencyRatio(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetReservedConcurrencyRatio(v)
+	})
+}
+
+// AddReservedConcurrencyRatio adds v to the "reserved_concurrency_ratio" field.
+func (u *GroupUpsertBulk) AddReservedConcurrencyRatio(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddReservedConcurrencyRatio(v)
+	})
+}
+
+// UpdateReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateReservedConcurrencyRatio() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateReservedConcurrencyRatio()
+	})
+}
+
+// SetOverloadFallbackModels sets the "overload_fallback_models" field.
+func (u *GroupUpsertBulk) SetOverloadFallbackModels(v map[string]string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetOverloadFallbackModels(v)
+	})
+}
+
+// UpdateOverloadFallbackModels sets the "overload_fallback_models" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateOverloadFallbackModels() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateOverloadFallbackModels()
+	})
+}
+
+// ClearOverloadFallbackModels clears the value of the "overload_fallback_models" field.
+func (u *GroupUpsertBulk) ClearOverloadFallbackModels() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearOverloadFallbackModels()
+	})
+}
+
+// SetContextOverflowModels sets the "context_overflow_models" field.
+func (u *GroupUpsertBulk) SetContextOverflowModels(v map[string]string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextOverflowModels(v)
+	})
+}
+
+// UpdateContextOverflowModels sets the "context_overflow_models" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateContextOverflowModels() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextOverflowModels()
+	})
+}
+
+// ClearContextOverflowModels clears the value of the "context_overflow_models" field.
+func (u *GroupUpsertBulk) ClearContextOverflowModels() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearContextOverflowModels()
+	})
+}
+
+// SetContextOverflowReject sets the "context_overflow_reject" field.
+func (u *GroupUpsertBulk) SetContextOverflowReject(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextOverflowReject(v)
+	})
+}
+
+// UpdateContextOverflowReject sets the "context_overflow_reject" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateContextOverflowReject() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextOverflowReject()
+	})
+}
+
+// SetContextTruncationStrategy sets the "context_truncation_strategy" field.
+func (u *GroupUpsertBulk) SetContextTruncationStrategy(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextTruncationStrategy(v)
+	})
+}
+
+// UpdateContextTruncationStrategy sets the "context_truncation_strategy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateContextTruncationStrategy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextTruncationStrategy()
+	})
+}
+
+// SetAccountSelectionStrategy sets the "account_selection_strategy" field.
+func (u *GroupUpsertBulk) SetAccountSelectionStrategy(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAccountSelectionStrategy(v)
+	})
+}
+
+// UpdateAccountSelectionStrategy sets the "account_selection_strategy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAccountSelectionStrategy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAccountSelectionStrategy()
+	})
+}
+
+// SetImageMaxEdge sets the "image_max_edge" field.
+func (u *GroupUpsertBulk) SetImageMaxEdge(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetImageMaxEdge(v)
+	})
+}
+
+// AddImageMaxEdge adds v to the "image_max_edge" field.
+func (u *GroupUpsertBulk) AddImageMaxEdge(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddImageMaxEdge(v)
+	})
+}
+
+// UpdateImageMaxEdge sets the "image_max_edge" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateImageMaxEdge() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateImageMaxEdge()
+	})
+}
+
+// SetImageMaxBytes sets the "image_max_bytes" field.
+func (u *GroupUpsertBulk) SetImageMaxBytes(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetImageMaxBytes(v)
+	})
+}
+
+// AddImageMaxBytes adds v to the "image_max_bytes" field.
+func (u *GroupUpsertBulk) AddImageMaxBytes(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddImageMaxBytes(v)
+	})
+}
+
+// UpdateImageMaxBytes sets the "image_max_bytes" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateImageMaxBytes() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateImageMaxBytes()
+	})
+}
+
+// SetOutputPostprocess sets the "output_postprocess" field.
+func (u *GroupUpsertBulk) SetOutputPostprocess(v domain.GroupOutputPostprocessConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetOutputPostprocess(v)
+	})
+}
+
+// UpdateOutputPostprocess sets the "output_postprocess" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateOutputPostprocess() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateOutputPostprocess()
+	})
+}
+
+// SetLanguageRouting sets the "language_routing" field.
+func (u *GroupUpsertBulk) SetLanguageRouting(v domain.GroupLanguageRoutingConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetLanguageRouting(v)
+	})
+}
+
+// UpdateLanguageRouting sets the "language_routing" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateLanguageRouting() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateLanguageRouting()
+	})
+}
+
+// SetStreamReplay sets the "stream_replay" field.
+func (u *GroupUpsertBulk) SetStreamReplay(v domain.GroupStreamReplayConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetStreamReplay(v)
+	})
+}
+
+// UpdateStreamReplay sets the "stream_replay" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateStreamReplay() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateStreamReplay()
+	})
+}
+
+// SetCapabilityCheck sets the "capability_check" field.
+func (u *GroupUpsertBulk) SetCapabilityCheck(v domain.GroupCapabilityCheckConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetCapabilityCheck(v)
+	})
+}
+
+// UpdateCapabilityCheck sets the "capability_check" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateCapabilityCheck() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateCapabilityCheck()
+	})
+}
+
+// SetAutoContinuation sets the "auto_continuation" field.
+func (u *GroupUpsertBulk) SetAutoContinuation(v domain.GroupAutoContinuationConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAutoContinuation(v)
+	})
+}
+
+// UpdateAutoContinuation sets the "auto_continuation" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAutoContinuation() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAutoContinuation()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
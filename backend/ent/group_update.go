@@ -180,6 +180,27 @@ func (_u *GroupUpdate) AddPeakRateMultiplier(v float64) *GroupUpdate {
 	return _u
 }
 
+// SetRequestSurcharge sets the "request_surcharge" field.
+func (_u *GroupUpdate) SetRequestSurcharge(v float64) *GroupUpdate {
+	_u.mutation.ResetRequestSurcharge()
+	_u.mutation.SetRequestSurcharge(v)
+	return _u
+}
+
+// SetNillableRequestSurcharge sets the "request_surcharge" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableRequestSurcharge(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetRequestSurcharge(*v)
+	}
+	return _u
+}
+
+// AddRequestSurcharge adds value to the "request_surcharge" field.
+func (_u *GroupUpdate) AddRequestSurcharge(v float64) *GroupUpdate {
+	_u.mutation.AddRequestSurcharge(v)
+	return _u
+}
+
 // SetIsExclusive sets the "is_exclusive" field.
 func (_u *GroupUpdate) SetIsExclusive(v bool) *GroupUpdate {
 	_u.mutation.SetIsExclusive(v)
@@ -886,6 +907,205 @@ func (_u *GroupUpdate) AddRpmLimit(v int) *GroupUpdate {
 	return _u
 }
 
+// SetReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field.
+func (_u *GroupUpdate) SetReservedConcurrencyRatio(v float64) *GroupUpdate {
+	_u.mutation.ResetReservedConcurrencyRatio()
+	_u.mutation.SetReservedConcurrencyRatio(v)
+	return _u
+}
+
+// SetNillableReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableReservedConcurrencyRatio(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetReservedConcurrencyRatio(*v)
+	}
+	return _u
+}
+
+// AddReservedConcurrencyRatio adds value to the "reserved_concurrency_ratio" field.
+func (_u *GroupUpdate) AddReservedConcurrencyRatio(v float64) *GroupUpdate {
+	_u.mutation.AddReservedConcurrencyRatio(v)
+	return _u
+}
+
+// SetOverloadFallbackModels sets the "overload_fallback_models" field.
+func (_u *GroupUpdate) SetOverloadFallbackModels(v map[string]string) *GroupUpdate {
+	_u.mutation.SetOverloadFallbackModels(v)
+	return _u
+}
+
+// ClearOverloadFallbackModels clears the value of the "overload_fallback_models" field.
+func (_u *GroupUpdate) ClearOverloadFallbackModels() *GroupUpdate {
+	_u.mutation.ClearOverloadFallbackModels()
+	return _u
+}
+
+// SetContextOverflowModels sets the "context_overflow_models" field.
+func (_u *GroupUpdate) SetContextOverflowModels(v map[string]string) *GroupUpdate {
+	_u.mutation.SetContextOverflowModels(v)
+	return _u
+}
+
+// ClearContextOverflowModels clears the value of the "context_overflow_models" field.
+func (_u *GroupUpdate) ClearContextOverflowModels() *GroupUpdate {
+	_u.mutation.ClearContextOverflowModels()
+	return _u
+}
+
+// SetContextOverflowReject sets the "context_overflow_reject" field.
+func (_u *GroupUpdate) SetContextOverflowReject(v bool) *GroupUpdate {
+	_u.mutation.SetContextOverflowReject(v)
+	return _u
+}
+
+// SetNillableContextOverflowReject sets the "context_overflow_reject" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableContextOverflowReject(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetContextOverflowReject(*v)
+	}
+	return _u
+}
+
+// SetContextTruncationStrategy sets the "context_truncation_strategy" field.
+func (_u *GroupUpdate) SetContextTruncationStrategy(v string) *GroupUpdate {
+	_u.mutation.SetContextTruncationStrategy(v)
+	return _u
+}
+
+// SetNillableContextTruncationStrategy sets the "context_truncation_strategy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableContextTruncationStrategy(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetContextTruncationStrategy(*v)
+	}
+	return _u
+}
+
+// SetAccountSelectionStrategy sets the "account_selection_strategy" field.
+func (_u *GroupUpdate) SetAccountSelectionStrategy(v string) *GroupUpdate {
+	_u.mutation.SetAccountSelectionStrategy(v)
+	return _u
+}
+
+// SetNillableAccountSelectionStrategy sets the "account_selection_strategy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAccountSelectionStrategy(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetAccountSelectionStrategy(*v)
+	}
+	return _u
+}
+
+// SetImageMaxEdge sets the "image_max_edge" field.
+func (_u *GroupUpdate) SetImageMaxEdge(v int) *GroupUpdate {
+	_u.mutation.ResetImageMaxEdge()
+	_u.mutation.SetImageMaxEdge(v)
+	return _u
+}
+
+// SetNillableImageMaxEdge sets the "image_max_edge" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableImageMaxEdge(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetImageMaxEdge(*v)
+	}
+	return _u
+}
+
+// AddImageMaxEdge adds value to the "image_max_edge" field.
+func (_u *GroupUpdate) AddImageMaxEdge(v int) *GroupUpdate {
+	_u.mutation.AddImageMaxEdge(v)
+	return _u
+}
+
+// SetImageMaxBytes sets the "image_max_bytes" field.
+func (_u *GroupUpdate) SetImageMaxBytes(v int) *GroupUpdate {
+	_u.mutation.ResetImageMaxBytes()
+	_u.mutation.SetImageMaxBytes(v)
+	return _u
+}
+
+// SetNillableImageMaxBytes sets the "image_max_bytes" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableImageMaxBytes(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetImageMaxBytes(*v)
+	}
+	return _u
+}
+
+// AddImageMaxBytes adds value to the "image_max_bytes" field.
+func (_u *GroupUpdate) AddImageMaxBytes(v int) *GroupUpdate {
+	_u.mutation.AddImageMaxBytes(v)
+	return _u
+}
+
+// SetOutputPostprocess sets the "output_postprocess" field.
+func (_u *GroupUpdate) SetOutputPostprocess(v domain.GroupOutputPostprocessConfig) *GroupUpdate {
+	_u.mutation.SetOutputPostprocess(v)
+	return _u
+}
+
+// SetNillableOutputPostprocess sets the "output_postprocess" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableOutputPostprocess(v *domain.GroupOutputPostprocessConfig) *GroupUpdate {
+	if v != nil {
+		_u.SetOutputPostprocess(*v)
+	}
+	return _u
+}
+
+// SetLanguageRouting sets the "language_routing" field.
+func (_u *GroupUpdate) SetLanguageRouting(v domain.GroupLanguageRoutingConfig) *GroupUpdate {
+	_u.mutation.SetLanguageRouting(v)
+	return _u
+}
+
+// SetNillableLanguageRouting sets the "language_routing" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableLanguageRouting(v *domain.GroupLanguageRoutingConfig) *GroupUpdate {
+	if v != nil {
+		_u.SetLanguageRouting(*v)
+	}
+	return _u
+}
+
+// SetStreamReplay sets the "stream_replay" field.
+func (_u *GroupUpdate) SetStreamReplay(v domain.GroupStreamReplayConfig) *GroupUpdate {
+	_u.mutation.SetStreamReplay(v)
+	return _u
+}
+
+// SetNillableStreamReplay sets the "stream_replay" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableStreamReplay(v *domain.GroupStreamReplayConfig) *GroupUpdate {
+	if v != nil {
+		_u.SetStreamReplay(*v)
+	}
+	return _u
+}
+
+// SetCapabilityCheck sets the "capability_check" field.
+func (_u *GroupUpdate) SetCapabilityCheck(v domain.GroupCapabilityCheckConfig) *GroupUpdate {
+	_u.mutation.SetCapabilityCheck(v)
+	return _u
+}
+
+// SetNillableCapabilityCheck sets the "capability_check" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableCapabilityCheck(v *domain.GroupCapabilityCheckConfig) *GroupUpdate {
+	if v != nil {
+		_u.SetCapabilityCheck(*v)
+	}
+	return _u
+}
+
+// SetAutoContinuation sets the "auto_continuation" field.
+func (_u *GroupUpdate) SetAutoContinuation(v domain.GroupAutoContinuationConfig) *GroupUpdate {
+	_u.mutation.SetAutoContinuation(v)
+	return _u
+}
+
+// SetNillableAutoContinuation sets the "auto_continuation" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAutoContinuation(v *domain.GroupAutoContinuationConfig) *GroupUpdate {
+	if v != nil {
+		_u.SetAutoContinuation(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -1186,6 +1406,16 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ContextTruncationStrategy(); ok {
+		if err := group.ContextTruncationStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "context_truncation_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.context_truncation_strategy": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.AccountSelectionStrategy(); ok {
+		if err := group.AccountSelectionStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "account_selection_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.account_selection_strategy": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1240,6 +1470,12 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedPeakRateMultiplier(); ok {
 		_spec.AddField(group.FieldPeakRateMultiplier, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.RequestSurcharge(); ok {
+		_spec.SetField(group.FieldRequestSurcharge, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedRequestSurcharge(); ok {
+		_spec.AddField(group.FieldRequestSurcharge, field.TypeFloat64, value)
+	}
 	if value, ok := _u.mutation.IsExclusive(); ok {
 		_spec.SetField(group.FieldIsExclusive, field.TypeBool, value)
 	}
@@ -1446,6 +1682,60 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.ReservedConcurrencyRatio(); ok {
+		_spec.SetField(group.FieldReservedConcurrencyRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedReservedConcurrencyRatio(); ok {
+		_spec.AddField(group.FieldReservedConcurrencyRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.OverloadFallbackModels(); ok {
+		_spec.SetField(group.FieldOverloadFallbackModels, field.TypeJSON, value)
+	}
+	if _u.mutation.OverloadFallbackModelsCleared() {
+		_spec.ClearField(group.FieldOverloadFallbackModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ContextOverflowModels(); ok {
+		_spec.SetField(group.FieldContextOverflowModels, field.TypeJSON, value)
+	}
+	if _u.mutation.ContextOverflowModelsCleared() {
+		_spec.ClearField(group.FieldContextOverflowModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ContextOverflowReject(); ok {
+		_spec.SetField(group.FieldContextOverflowReject, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ContextTruncationStrategy(); ok {
+		_spec.SetField(group.FieldContextTruncationStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.AccountSelectionStrategy(); ok {
+		_spec.SetField(group.FieldAccountSelectionStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ImageMaxEdge(); ok {
+		_spec.SetField(group.FieldImageMaxEdge, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageMaxEdge(); ok {
+		_spec.AddField(group.FieldImageMaxEdge, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ImageMaxBytes(); ok {
+		_spec.SetField(group.FieldImageMaxBytes, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageMaxBytes(); ok {
+		_spec.AddField(group.FieldImageMaxBytes, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.OutputPostprocess(); ok {
+		_spec.SetField(group.FieldOutputPostprocess, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.LanguageRouting(); ok {
+		_spec.SetField(group.FieldLanguageRouting, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.StreamReplay(); ok {
+		_spec.SetField(group.FieldStreamReplay, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.CapabilityCheck(); ok {
+		_spec.SetField(group.FieldCapabilityCheck, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AutoContinuation(); ok {
+		_spec.SetField(group.FieldAutoContinuation, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1904,6 +2194,27 @@ func (_u *GroupUpdateOne) AddPeakRateMultiplier(v float64) *GroupUpdateOne {
 	return _u
 }
 
+// SetRequestSurcharge sets the "request_surcharge" field.
+func (_u *GroupUpdateOne) SetRequestSurcharge(v float64) *GroupUpdateOne {
+	_u.mutation.ResetRequestSurcharge()
+	_u.mutation.SetRequestSurcharge(v)
+	return _u
+}
+
+// SetNillableRequestSurcharge sets the "request_surcharge" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableRequestSurcharge(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetRequestSurcharge(*v)
+	}
+	return _u
+}
+
+// AddRequestSurcharge adds value to the "request_surcharge" field.
+func (_u *GroupUpdateOne) AddRequestSurcharge(v float64) *GroupUpdateOne {
+	_u.mutation.AddRequestSurcharge(v)
+	return _u
+}
+
 // SetIsExclusive sets the "is_exclusive" field.
 func (_u *GroupUpdateOne) SetIsExclusive(v bool) *GroupUpdateOne {
 	_u.mutation.SetIsExclusive(v)
@@ -2610,6 +2921,205 @@ func (_u *GroupUpdateOne) AddRpmLimit(v int) *GroupUpdateOne {
 	return _u
 }
 
+// SetReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field.
+func (_u *GroupUpdateOne) SetReservedConcurrencyRatio(v float64) *GroupUpdateOne {
+	_u.mutation.ResetReservedConcurrencyRatio()
+	_u.mutation.SetReservedConcurrencyRatio(v)
+	return _u
+}
+
+// SetNillableReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableReservedConcurrencyRatio(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetReservedConcurrencyRatio(*v)
+	}
+	return _u
+}
+
+// AddReservedConcurrencyRatio adds value to the "reserved_concurrency_ratio" field.
+func (_u *GroupUpdateOne) AddReservedConcurrencyRatio(v float64) *GroupUpdateOne {
+	_u.mutation.AddReservedConcurrencyRatio(v)
+	return _u
+}
+
+// SetOverloadFallbackModels sets the "overload_fallback_models" field.
+func (_u *GroupUpdateOne) SetOverloadFallbackModels(v map[string]string) *GroupUpdateOne {
+	_u.mutation.SetOverloadFallbackModels(v)
+	return _u
+}
+
+// ClearOverloadFallbackModels clears the value of the "overload_fallback_models" field.
+func (_u *GroupUpdateOne) ClearOverloadFallbackModels() *GroupUpdateOne {
+	_u.mutation.ClearOverloadFallbackModels()
+	return _u
+}
+
+// SetContextOverflowModels sets the "context_overflow_models" field.
+func (_u *GroupUpdateOne) SetContextOverflowModels(v map[string]string) *GroupUpdateOne {
+	_u.mutation.SetContextOverflowModels(v)
+	return _u
+}
+
+// ClearContextOverflowModels clears the value of the "context_overflow_models" field.
+func (_u *GroupUpdateOne) ClearContextOverflowModels() *GroupUpdateOne {
+	_u.mutation.ClearContextOverflowModels()
+	return _u
+}
+
+// SetContextOverflowReject sets the "context_overflow_reject" field.
+func (_u *GroupUpdateOne) SetContextOverflowReject(v bool) *GroupUpdateOne {
+	_u.mutation.SetContextOverflowReject(v)
+	return _u
+}
+
+// SetNillableContextOverflowReject sets the "context_overflow_reject" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableContextOverflowReject(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetContextOverflowReject(*v)
+	}
+	return _u
+}
+
+// SetContextTruncationStrategy sets the "context_truncation_strategy" field.
+func (_u *GroupUpdateOne) SetContextTruncationStrategy(v string) *GroupUpdateOne {
+	_u.mutation.SetContextTruncationStrategy(v)
+	return _u
+}
+
+// SetNillableContextTruncationStrategy sets the "context_truncation_strategy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableContextTruncationStrategy(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetContextTruncationStrategy(*v)
+	}
+	return _u
+}
+
+// SetAccountSelectionStrategy sets the "account_selection_strategy" field.
+func (_u *GroupUpdateOne) SetAccountSelectionStrategy(v string) *GroupUpdateOne {
+	_u.mutation.SetAccountSelectionStrategy(v)
+	return _u
+}
+
+// SetNillableAccountSelectionStrategy sets the "account_selection_strategy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAccountSelectionStrategy(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAccountSelectionStrategy(*v)
+	}
+	return _u
+}
+
+// SetImageMaxEdge sets the "image_max_edge" field.
+func (_u *GroupUpdateOne) SetImageMaxEdge(v int) *GroupUpdateOne {
+	_u.mutation.ResetImageMaxEdge()
+	_u.mutation.SetImageMaxEdge(v)
+	return _u
+}
+
+// SetNillableImageMaxEdge sets the "image_max_edge" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableImageMaxEdge(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetImageMaxEdge(*v)
+	}
+	return _u
+}
+
+// AddImageMaxEdge adds value to the "image_max_edge" field.
+func (_u *GroupUpdateOne) AddImageMaxEdge(v int) *GroupUpdateOne {
+	_u.mutation.AddImageMaxEdge(v)
+	return _u
+}
+
+// SetImageMaxBytes sets the "image_max_bytes" field.
+func (_u *GroupUpdateOne) SetImageMaxBytes(v int) *GroupUpdateOne {
+	_u.mutation.ResetImageMaxBytes()
+	_u.mutation.SetImageMaxBytes(v)
+	return _u
+}
+
+// SetNillableImageMaxBytes sets the "image_max_bytes" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableImageMaxBytes(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetImageMaxBytes(*v)
+	}
+	return _u
+}
+
+// AddImageMaxBytes adds value to the "image_max_bytes" field.
+func (_u *GroupUpdateOne) AddImageMaxBytes(v int) *GroupUpdateOne {
+	_u.mutation.AddImageMaxBytes(v)
+	return _u
+}
+
+// SetOutputPostprocess sets the "output_postprocess" field.
+func (_u *GroupUpdateOne) SetOutputPostprocess(v domain.GroupOutputPostprocessConfig) *GroupUpdateOne {
+	_u.mutation.SetOutputPostprocess(v)
+	return _u
+}
+
+// SetNillableOutputPostprocess sets the "output_postprocess" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableOutputPostprocess(v *domain.GroupOutputPostprocessConfig) *GroupUpdateOne {
+	if v != nil {
+		_u.SetOutputPostprocess(*v)
+	}
+	return _u
+}
+
+// SetLanguageRouting sets the "language_routing" field.
+func (_u *GroupUpdateOne) SetLanguageRouting(v domain.GroupLanguageRoutingConfig) *GroupUpdateOne {
+	_u.mutation.SetLanguageRouting(v)
+	return _u
+}
+
+// SetNillableLanguageRouting sets the "language_routing" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableLanguageRouting(v *domain.GroupLanguageRoutingConfig) *GroupUpdateOne {
+	if v != nil {
+		_u.SetLanguageRouting(*v)
+	}
+	return _u
+}
+
+// SetStreamReplay sets the "stream_replay" field.
+func (_u *GroupUpdateOne) SetStreamReplay(v domain.GroupStreamReplayConfig) *GroupUpdateOne {
+	_u.mutation.SetStreamReplay(v)
+	return _u
+}
+
+// SetNillableStreamReplay sets the "stream_replay" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableStreamReplay(v *domain.GroupStreamReplayConfig) *GroupUpdateOne {
+	if v != nil {
+		_u.SetStreamReplay(*v)
+	}
+	return _u
+}
+
+// SetCapabilityCheck sets the "capability_check" field.
+func (_u *GroupUpdateOne) SetCapabilityCheck(v domain.GroupCapabilityCheckConfig) *GroupUpdateOne {
+	_u.mutation.SetCapabilityCheck(v)
+	return _u
+}
+
+// SetNillableCapabilityCheck sets the "capability_check" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableCapabilityCheck(v *domain.GroupCapabilityCheckConfig) *GroupUpdateOne {
+	if v != nil {
+		_u.SetCapabilityCheck(*v)
+	}
+	return _u
+}
+
+// SetAutoContinuation sets the "auto_continuation" field.
+func (_u *GroupUpdateOne) SetAutoContinuation(v domain.GroupAutoContinuationConfig) *GroupUpdateOne {
+	_u.mutation.SetAutoContinuation(v)
+	return _u
+}
+
+// SetNillableAutoContinuation sets the "auto_continuation" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAutoContinuation(v *domain.GroupAutoContinuationConfig) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAutoContinuation(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2923,6 +3433,16 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ContextTruncationStrategy(); ok {
+		if err := group.ContextTruncationStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "context_truncation_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.context_truncation_strategy": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.AccountSelectionStrategy(); ok {
+		if err := group.AccountSelectionStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "account_selection_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.account_selection_strategy": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -2994,6 +3514,12 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.AddedPeakRateMultiplier(); ok {
 		_spec.AddField(group.FieldPeakRateMultiplier, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.RequestSurcharge(); ok {
+		_spec.SetField(group.FieldRequestSurcharge, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedRequestSurcharge(); ok {
+		_spec.AddField(group.FieldRequestSurcharge, field.TypeFloat64, value)
+	}
 	if value, ok := _u.mutation.IsExclusive(); ok {
 		_spec.SetField(group.FieldIsExclusive, field.TypeBool, value)
 	}
@@ -3200,6 +3726,60 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.ReservedConcurrencyRatio(); ok {
+		_spec.SetField(group.FieldReservedConcurrencyRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedReservedConcurrencyRatio(); ok {
+		_spec.AddField(group.FieldReservedConcurrencyRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.OverloadFallbackModels(); ok {
+		_spec.SetField(group.FieldOverloadFallbackModels, field.TypeJSON, value)
+	}
+	if _u.mutation.OverloadFallbackModelsCleared() {
+		_spec.ClearField(group.FieldOverloadFallbackModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ContextOverflowModels(); ok {
+		_spec.SetField(group.FieldContextOverflowModels, field.TypeJSON, value)
+	}
+	if _u.mutation.ContextOverflowModelsCleared() {
+		_spec.ClearField(group.FieldContextOverflowModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ContextOverflowReject(); ok {
+		_spec.SetField(group.FieldContextOverflowReject, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ContextTruncationStrategy(); ok {
+		_spec.SetField(group.FieldContextTruncationStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.AccountSelectionStrategy(); ok {
+		_spec.SetField(group.FieldAccountSelectionStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ImageMaxEdge(); ok {
+		_spec.SetField(group.FieldImageMaxEdge, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageMaxEdge(); ok {
+		_spec.AddField(group.FieldImageMaxEdge, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ImageMaxBytes(); ok {
+		_spec.SetField(group.FieldImageMaxBytes, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageMaxBytes(); ok {
+		_spec.AddField(group.FieldImageMaxBytes, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.OutputPostprocess(); ok {
+		_spec.SetField(group.FieldOutputPostprocess, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.LanguageRouting(); ok {
+		_spec.SetField(group.FieldLanguageRouting, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.StreamReplay(); ok {
+		_spec.SetField(group.FieldStreamReplay, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.CapabilityCheck(); ok {
+		_spec.SetField(group.FieldCapabilityCheck, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AutoContinuation(); ok {
+		_spec.SetField(group.FieldAutoContinuation, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
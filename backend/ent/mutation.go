@@ -4,7 +4,7 @@ package ent
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/json/jsontext"
 	"errors"
 	"fmt"
 	"sync"
@@ -106,51 +106,65 @@ const (
 // APIKeyMutation represents an operation that mutates the APIKey nodes in the graph.
 type APIKeyMutation struct {
 	config
-	op                 Op
-	typ                string
-	id                 *int64
-	created_at         *time.Time
-	updated_at         *time.Time
-	deleted_at         *time.Time
-	key                *string
-	name               *string
-	status             *string
-	last_used_at       *time.Time
-	ip_whitelist       *[]string
-	appendip_whitelist []string
-	ip_blacklist       *[]string
-	appendip_blacklist []string
-	quota              *float64
-	addquota           *float64
-	quota_used         *float64
-	addquota_used      *float64
-	expires_at         *time.Time
-	rate_limit_5h      *float64
-	addrate_limit_5h   *float64
-	rate_limit_1d      *float64
-	addrate_limit_1d   *float64
-	rate_limit_7d      *float64
-	addrate_limit_7d   *float64
-	usage_5h           *float64
-	addusage_5h        *float64
-	usage_1d           *float64
-	addusage_1d        *float64
-	usage_7d           *float64
-	addusage_7d        *float64
-	window_5h_start    *time.Time
-	window_1d_start    *time.Time
-	window_7d_start    *time.Time
-	clearedFields      map[string]struct{}
-	user               *int64
-	cleareduser        bool
-	group              *int64
-	clearedgroup       bool
-	usage_logs         map[int64]struct{}
-	removedusage_logs  map[int64]struct{}
-	clearedusage_logs  bool
-	done               bool
-	oldValue           func(context.Context) (*APIKey, error)
-	predicates         []predicate.APIKey
+	op                   Op
+	typ                  string
+	id                   *int64
+	created_at           *time.Time
+	updated_at           *time.Time
+	deleted_at           *time.Time
+	key                  *string
+	name                 *string
+	status               *string
+	last_used_at         *time.Time
+	ip_whitelist         *[]string
+	appendip_whitelist   []string
+	ip_blacklist         *[]string
+	appendip_blacklist   []string
+	quota                *float64
+	addquota             *float64
+	quota_used           *float64
+	addquota_used        *float64
+	expires_at           *time.Time
+	rate_limit_5h        *float64
+	addrate_limit_5h     *float64
+	rate_limit_1d        *float64
+	addrate_limit_1d     *float64
+	rate_limit_7d        *float64
+	addrate_limit_7d     *float64
+	usage_5h             *float64
+	addusage_5h          *float64
+	usage_1d             *float64
+	addusage_1d          *float64
+	usage_7d             *float64
+	addusage_7d          *float64
+	window_5h_start      *time.Time
+	window_1d_start      *time.Time
+	window_7d_start      *time.Time
+	rpm_limit            *int
+	addrpm_limit         *int
+	tpm_limit            *int64
+	addtpm_limit         *int64
+	critical             *bool
+	guest                *bool
+	allowed_models       *[]string
+	appendallowed_models []string
+	token_limit          *int64
+	addtoken_limit       *int64
+	tokens_used          *int64
+	addtokens_used       *int64
+	project_id           *int64
+	addproject_id        *int64
+	clearedFields        map[string]struct{}
+	user                 *int64
+	cleareduser          bool
+	group                *int64
+	clearedgroup         bool
+	usage_logs           map[int64]struct{}
+	removedusage_logs    map[int64]struct{}
+	clearedusage_logs    bool
+	done                 bool
+	oldValue             func(context.Context) (*APIKey, error)
+	predicates           []predicate.APIKey
 }
 
 var _ ent.Mutation = (*APIKeyMutation)(nil)
@@ -1388,6 +1402,437 @@ func (m *APIKeyMutation) ResetWindow7dStart() {
 	delete(m.clearedFields, apikey.FieldWindow7dStart)
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (m *APIKeyMutation) SetRpmLimit(i int) {
+	m.rpm_limit = &i
+	m.addrpm_limit = nil
+}
+
+// RpmLimit returns the value of the "rpm_limit" field in the mutation.
+func (m *APIKeyMutation) RpmLimit() (r int, exists bool) {
+	v := m.rpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRpmLimit returns the old "rpm_limit" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldRpmLimit(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRpmLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRpmLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRpmLimit: %w", err)
+	}
+	return oldValue.RpmLimit, nil
+}
+
+// AddRpmLimit adds i to the "rpm_limit" field.
+func (m *APIKeyMutation) AddRpmLimit(i int) {
+	if m.addrpm_limit != nil {
+		*m.addrpm_limit += i
+	} else {
+		m.addrpm_limit = &i
+	}
+}
+
+// AddedRpmLimit returns the value that was added to the "rpm_limit" field in this mutation.
+func (m *APIKeyMutation) AddedRpmLimit() (r int, exists bool) {
+	v := m.addrpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetRpmLimit resets all changes to the "rpm_limit" field.
+func (m *APIKeyMutation) ResetRpmLimit() {
+	m.rpm_limit = nil
+	m.addrpm_limit = nil
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (m *APIKeyMutation) SetTpmLimit(i int64) {
+	m.tpm_limit = &i
+	m.addtpm_limit = nil
+}
+
+// TpmLimit returns the value of the "tpm_limit" field in the mutation.
+func (m *APIKeyMutation) TpmLimit() (r int64, exists bool) {
+	v := m.tpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTpmLimit returns the old "tpm_limit" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldTpmLimit(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTpmLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTpmLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTpmLimit: %w", err)
+	}
+	return oldValue.TpmLimit, nil
+}
+
+// AddTpmLimit adds i to the "tpm_limit" field.
+func (m *APIKeyMutation) AddTpmLimit(i int64) {
+	if m.addtpm_limit != nil {
+		*m.addtpm_limit += i
+	} else {
+		m.addtpm_limit = &i
+	}
+}
+
+// AddedTpmLimit returns the value that was added to the "tpm_limit" field in this mutation.
+func (m *APIKeyMutation) AddedTpmLimit() (r int64, exists bool) {
+	v := m.addtpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTpmLimit resets all changes to the "tpm_limit" field.
+func (m *APIKeyMutation) ResetTpmLimit() {
+	m.tpm_limit = nil
+	m.addtpm_limit = nil
+}
+
+// SetCritical sets the "critical" field.
+func (m *APIKeyMutation) SetCritical(b bool) {
+	m.critical = &b
+}
+
+// Critical returns the value of the "critical" field in the mutation.
+func (m *APIKeyMutation) Critical() (r bool, exists bool) {
+	v := m.critical
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCritical returns the old "critical" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldCritical(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCritical is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCritical requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCritical: %w", err)
+	}
+	return oldValue.Critical, nil
+}
+
+// ResetCritical resets all changes to the "critical" field.
+func (m *APIKeyMutation) ResetCritical() {
+	m.critical = nil
+}
+
+// SetGuest sets the "guest" field.
+func (m *APIKeyMutation) SetGuest(b bool) {
+	m.guest = &b
+}
+
+// Guest returns the value of the "guest" field in the mutation.
+func (m *APIKeyMutation) Guest() (r bool, exists bool) {
+	v := m.guest
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldGuest returns the old "guest" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldGuest(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldGuest is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldGuest requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldGuest: %w", err)
+	}
+	return oldValue.Guest, nil
+}
+
+// ResetGuest resets all changes to the "guest" field.
+func (m *APIKeyMutation) ResetGuest() {
+	m.guest = nil
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (m *APIKeyMutation) SetAllowedModels(s []string) {
+	m.allowed_models = &s
+	m.appendallowed_models = nil
+}
+
+// AllowedModels returns the value of the "allowed_models" field in the mutation.
+func (m *APIKeyMutation) AllowedModels() (r []string, exists bool) {
+	v := m.allowed_models
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedModels returns the old "allowed_models" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAllowedModels(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedModels is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedModels requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedModels: %w", err)
+	}
+	return oldValue.AllowedModels, nil
+}
+
+// AppendAllowedModels adds s to the "allowed_models" field.
+func (m *APIKeyMutation) AppendAllowedModels(s []string) {
+	m.appendallowed_models = append(m.appendallowed_models, s...)
+}
+
+// AppendedAllowedModels returns the list of values that were appended to the "allowed_models" field in this mutation.
+func (m *APIKeyMutation) AppendedAllowedModels() ([]string, bool) {
+	if len(m.appendallowed_models) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_models, true
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (m *APIKeyMutation) ClearAllowedModels() {
+	m.allowed_models = nil
+	m.appendallowed_models = nil
+	m.clearedFields[apikey.FieldAllowedModels] = struct{}{}
+}
+
+// AllowedModelsCleared returns if the "allowed_models" field was cleared in this mutation.
+func (m *APIKeyMutation) AllowedModelsCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldAllowedModels]
+	return ok
+}
+
+// ResetAllowedModels resets all changes to the "allowed_models" field.
+func (m *APIKeyMutation) ResetAllowedModels() {
+	m.allowed_models = nil
+	m.appendallowed_models = nil
+	delete(m.clearedFields, apikey.FieldAllowedModels)
+}
+
+// SetTokenLimit sets the "token_limit" field.
+func (m *APIKeyMutation) SetTokenLimit(i int64) {
+	m.token_limit = &i
+	m.addtoken_limit = nil
+}
+
+// TokenLimit returns the value of the "token_limit" field in the mutation.
+func (m *APIKeyMutation) TokenLimit() (r int64, exists bool) {
+	v := m.token_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTokenLimit returns the old "token_limit" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldTokenLimit(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTokenLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTokenLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTokenLimit: %w", err)
+	}
+	return oldValue.TokenLimit, nil
+}
+
+// AddTokenLimit adds i to the "token_limit" field.
+func (m *APIKeyMutation) AddTokenLimit(i int64) {
+	if m.addtoken_limit != nil {
+		*m.addtoken_limit += i
+	} else {
+		m.addtoken_limit = &i
+	}
+}
+
+// AddedTokenLimit returns the value that was added to the "token_limit" field in this mutation.
+func (m *APIKeyMutation) AddedTokenLimit() (r int64, exists bool) {
+	v := m.addtoken_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTokenLimit resets all changes to the "token_limit" field.
+func (m *APIKeyMutation) ResetTokenLimit() {
+	m.token_limit = nil
+	m.addtoken_limit = nil
+}
+
+// SetTokensUsed sets the "tokens_used" field.
+func (m *APIKeyMutation) SetTokensUsed(i int64) {
+	m.tokens_used = &i
+	m.addtokens_used = nil
+}
+
+// TokensUsed returns the value of the "tokens_used" field in the mutation.
+func (m *APIKeyMutation) TokensUsed() (r int64, exists bool) {
+	v := m.tokens_used
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTokensUsed returns the old "tokens_used" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldTokensUsed(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTokensUsed is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTokensUsed requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTokensUsed: %w", err)
+	}
+	return oldValue.TokensUsed, nil
+}
+
+// AddTokensUsed adds i to the "tokens_used" field.
+func (m *APIKeyMutation) AddTokensUsed(i int64) {
+	if m.addtokens_used != nil {
+		*m.addtokens_used += i
+	} else {
+		m.addtokens_used = &i
+	}
+}
+
+// AddedTokensUsed returns the value that was added to the "tokens_used" field in this mutation.
+func (m *APIKeyMutation) AddedTokensUsed() (r int64, exists bool) {
+	v := m.addtokens_used
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTokensUsed resets all changes to the "tokens_used" field.
+func (m *APIKeyMutation) ResetTokensUsed() {
+	m.tokens_used = nil
+	m.addtokens_used = nil
+}
+
+// SetProjectID sets the "project_id" field.
+func (m *APIKeyMutation) SetProjectID(i int64) {
+	m.project_id = &i
+	m.addproject_id = nil
+}
+
+// ProjectID returns the value of the "project_id" field in the mutation.
+func (m *APIKeyMutation) ProjectID() (r int64, exists bool) {
+	v := m.project_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldProjectID returns the old "project_id" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldProjectID(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldProjectID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldProjectID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldProjectID: %w", err)
+	}
+	return oldValue.ProjectID, nil
+}
+
+// AddProjectID adds i to the "project_id" field.
+func (m *APIKeyMutation) AddProjectID(i int64) {
+	if m.addproject_id != nil {
+		*m.addproject_id += i
+	} else {
+		m.addproject_id = &i
+	}
+}
+
+// AddedProjectID returns the value that was added to the "project_id" field in this mutation.
+func (m *APIKeyMutation) AddedProjectID() (r int64, exists bool) {
+	v := m.addproject_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearProjectID clears the value of the "project_id" field.
+func (m *APIKeyMutation) ClearProjectID() {
+	m.project_id = nil
+	m.addproject_id = nil
+	m.clearedFields[apikey.FieldProjectID] = struct{}{}
+}
+
+// ProjectIDCleared returns if the "project_id" field was cleared in this mutation.
+func (m *APIKeyMutation) ProjectIDCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldProjectID]
+	return ok
+}
+
+// ResetProjectID resets all changes to the "project_id" field.
+func (m *APIKeyMutation) ResetProjectID() {
+	m.project_id = nil
+	m.addproject_id = nil
+	delete(m.clearedFields, apikey.FieldProjectID)
+}
+
 // ClearUser clears the "user" edge to the User entity.
 func (m *APIKeyMutation) ClearUser() {
 	m.cleareduser = true
@@ -1530,7 +1975,7 @@ func (m *APIKeyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *APIKeyMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 31)
 	if m.created_at != nil {
 		fields = append(fields, apikey.FieldCreatedAt)
 	}
@@ -1600,6 +2045,30 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.window_7d_start != nil {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.rpm_limit != nil {
+		fields = append(fields, apikey.FieldRpmLimit)
+	}
+	if m.tpm_limit != nil {
+		fields = append(fields, apikey.FieldTpmLimit)
+	}
+	if m.critical != nil {
+		fields = append(fields, apikey.FieldCritical)
+	}
+	if m.guest != nil {
+		fields = append(fields, apikey.FieldGuest)
+	}
+	if m.allowed_models != nil {
+		fields = append(fields, apikey.FieldAllowedModels)
+	}
+	if m.token_limit != nil {
+		fields = append(fields, apikey.FieldTokenLimit)
+	}
+	if m.tokens_used != nil {
+		fields = append(fields, apikey.FieldTokensUsed)
+	}
+	if m.project_id != nil {
+		fields = append(fields, apikey.FieldProjectID)
+	}
 	return fields
 }
 
@@ -1654,6 +2123,22 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.Window1dStart()
 	case apikey.FieldWindow7dStart:
 		return m.Window7dStart()
+	case apikey.FieldRpmLimit:
+		return m.RpmLimit()
+	case apikey.FieldTpmLimit:
+		return m.TpmLimit()
+	case apikey.FieldCritical:
+		return m.Critical()
+	case apikey.FieldGuest:
+		return m.Guest()
+	case apikey.FieldAllowedModels:
+		return m.AllowedModels()
+	case apikey.FieldTokenLimit:
+		return m.TokenLimit()
+	case apikey.FieldTokensUsed:
+		return m.TokensUsed()
+	case apikey.FieldProjectID:
+		return m.ProjectID()
 	}
 	return nil, false
 }
@@ -1709,6 +2194,22 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldWindow1dStart(ctx)
 	case apikey.FieldWindow7dStart:
 		return m.OldWindow7dStart(ctx)
+	case apikey.FieldRpmLimit:
+		return m.OldRpmLimit(ctx)
+	case apikey.FieldTpmLimit:
+		return m.OldTpmLimit(ctx)
+	case apikey.FieldCritical:
+		return m.OldCritical(ctx)
+	case apikey.FieldGuest:
+		return m.OldGuest(ctx)
+	case apikey.FieldAllowedModels:
+		return m.OldAllowedModels(ctx)
+	case apikey.FieldTokenLimit:
+		return m.OldTokenLimit(ctx)
+	case apikey.FieldTokensUsed:
+		return m.OldTokensUsed(ctx)
+	case apikey.FieldProjectID:
+		return m.OldProjectID(ctx)
 	}
 	return nil, fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1879,6 +2380,62 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWindow7dStart(v)
 		return nil
+	case apikey.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRpmLimit(v)
+		return nil
+	case apikey.FieldTpmLimit:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTpmLimit(v)
+		return nil
+	case apikey.FieldCritical:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCritical(v)
+		return nil
+	case apikey.FieldGuest:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetGuest(v)
+		return nil
+	case apikey.FieldAllowedModels:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedModels(v)
+		return nil
+	case apikey.FieldTokenLimit:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTokenLimit(v)
+		return nil
+	case apikey.FieldTokensUsed:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTokensUsed(v)
+		return nil
+	case apikey.FieldProjectID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetProjectID(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1911,6 +2468,21 @@ func (m *APIKeyMutation) AddedFields() []string {
 	if m.addusage_7d != nil {
 		fields = append(fields, apikey.FieldUsage7d)
 	}
+	if m.addrpm_limit != nil {
+		fields = append(fields, apikey.FieldRpmLimit)
+	}
+	if m.addtpm_limit != nil {
+		fields = append(fields, apikey.FieldTpmLimit)
+	}
+	if m.addtoken_limit != nil {
+		fields = append(fields, apikey.FieldTokenLimit)
+	}
+	if m.addtokens_used != nil {
+		fields = append(fields, apikey.FieldTokensUsed)
+	}
+	if m.addproject_id != nil {
+		fields = append(fields, apikey.FieldProjectID)
+	}
 	return fields
 }
 
@@ -1935,6 +2507,16 @@ func (m *APIKeyMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedUsage1d()
 	case apikey.FieldUsage7d:
 		return m.AddedUsage7d()
+	case apikey.FieldRpmLimit:
+		return m.AddedRpmLimit()
+	case apikey.FieldTpmLimit:
+		return m.AddedTpmLimit()
+	case apikey.FieldTokenLimit:
+		return m.AddedTokenLimit()
+	case apikey.FieldTokensUsed:
+		return m.AddedTokensUsed()
+	case apikey.FieldProjectID:
+		return m.AddedProjectID()
 	}
 	return nil, false
 }
@@ -2000,6 +2582,41 @@ func (m *APIKeyMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddUsage7d(v)
 		return nil
+	case apikey.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRpmLimit(v)
+		return nil
+	case apikey.FieldTpmLimit:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTpmLimit(v)
+		return nil
+	case apikey.FieldTokenLimit:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTokenLimit(v)
+		return nil
+	case apikey.FieldTokensUsed:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTokensUsed(v)
+		return nil
+	case apikey.FieldProjectID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddProjectID(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey numeric field %s", name)
 }
@@ -2035,6 +2652,12 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldWindow7dStart) {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.FieldCleared(apikey.FieldAllowedModels) {
+		fields = append(fields, apikey.FieldAllowedModels)
+	}
+	if m.FieldCleared(apikey.FieldProjectID) {
+		fields = append(fields, apikey.FieldProjectID)
+	}
 	return fields
 }
 
@@ -2076,6 +2699,12 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ClearWindow7dStart()
 		return nil
+	case apikey.FieldAllowedModels:
+		m.ClearAllowedModels()
+		return nil
+	case apikey.FieldProjectID:
+		m.ClearProjectID()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey nullable field %s", name)
 }
@@ -2153,6 +2782,30 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ResetWindow7dStart()
 		return nil
+	case apikey.FieldRpmLimit:
+		m.ResetRpmLimit()
+		return nil
+	case apikey.FieldTpmLimit:
+		m.ResetTpmLimit()
+		return nil
+	case apikey.FieldCritical:
+		m.ResetCritical()
+		return nil
+	case apikey.FieldGuest:
+		m.ResetGuest()
+		return nil
+	case apikey.FieldAllowedModels:
+		m.ResetAllowedModels()
+		return nil
+	case apikey.FieldTokenLimit:
+		m.ResetTokenLimit()
+		return nil
+	case apikey.FieldTokensUsed:
+		m.ResetTokensUsed()
+		return nil
+	case apikey.FieldProjectID:
+		m.ResetProjectID()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -5086,19 +5739,23 @@ func (m *AccountMutation) ResetEdge(name string) error {
 // AccountGroupMutation represents an operation that mutates the AccountGroup nodes in the graph.
 type AccountGroupMutation struct {
 	config
-	op             Op
-	typ            string
-	priority       *int
-	addpriority    *int
-	created_at     *time.Time
-	clearedFields  map[string]struct{}
-	account        *int64
-	clearedaccount bool
-	group          *int64
-	clearedgroup   bool
-	done           bool
-	oldValue       func(context.Context) (*AccountGroup, error)
-	predicates     []predicate.AccountGroup
+	op                   Op
+	typ                  string
+	priority             *int
+	addpriority          *int
+	tier                 *int
+	addtier              *int
+	concurrency_share    *int
+	addconcurrency_share *int
+	created_at           *time.Time
+	clearedFields        map[string]struct{}
+	account              *int64
+	clearedaccount       bool
+	group                *int64
+	clearedgroup         bool
+	done                 bool
+	oldValue             func(context.Context) (*AccountGroup, error)
+	predicates           []predicate.AccountGroup
 }
 
 var _ ent.Mutation = (*AccountGroupMutation)(nil)
@@ -5216,6 +5873,84 @@ func (m *AccountGroupMutation) ResetPriority() {
 	m.addpriority = nil
 }
 
+// SetTier sets the "tier" field.
+func (m *AccountGroupMutation) SetTier(i int) {
+	m.tier = &i
+	m.addtier = nil
+}
+
+// Tier returns the value of the "tier" field in the mutation.
+func (m *AccountGroupMutation) Tier() (r int, exists bool) {
+	v := m.tier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AddTier adds i to the "tier" field.
+func (m *AccountGroupMutation) AddTier(i int) {
+	if m.addtier != nil {
+		*m.addtier += i
+	} else {
+		m.addtier = &i
+	}
+}
+
+// AddedTier returns the value that was added to the "tier" field in this mutation.
+func (m *AccountGroupMutation) AddedTier() (r int, exists bool) {
+	v := m.addtier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTier resets all changes to the "tier" field.
+func (m *AccountGroupMutation) ResetTier() {
+	m.tier = nil
+	m.addtier = nil
+}
+
+// SetConcurrencyShare sets the "concurrency_share" field.
+func (m *AccountGroupMutation) SetConcurrencyShare(i int) {
+	m.concurrency_share = &i
+	m.addconcurrency_share = nil
+}
+
+// ConcurrencyShare returns the value of the "concurrency_share" field in the mutation.
+func (m *AccountGroupMutation) ConcurrencyShare() (r int, exists bool) {
+	v := m.concurrency_share
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AddConcurrencyShare adds i to the "concurrency_share" field.
+func (m *AccountGroupMutation) AddConcurrencyShare(i int) {
+	if m.addconcurrency_share != nil {
+		*m.addconcurrency_share += i
+	} else {
+		m.addconcurrency_share = &i
+	}
+}
+
+// AddedConcurrencyShare returns the value that was added to the "concurrency_share" field in this mutation.
+func (m *AccountGroupMutation) AddedConcurrencyShare() (r int, exists bool) {
+	v := m.addconcurrency_share
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetConcurrencyShare resets all changes to the "concurrency_share" field.
+func (m *AccountGroupMutation) ResetConcurrencyShare() {
+	m.concurrency_share = nil
+	m.addconcurrency_share = nil
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (m *AccountGroupMutation) SetCreatedAt(t time.Time) {
 	m.created_at = &t
@@ -5323,7 +6058,7 @@ func (m *AccountGroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *AccountGroupMutation) Fields() []string {
-	fields := make([]string, 0, 4)
+	fields := make([]string, 0, 6)
 	if m.account != nil {
 		fields = append(fields, accountgroup.FieldAccountID)
 	}
@@ -5333,6 +6068,12 @@ func (m *AccountGroupMutation) Fields() []string {
 	if m.priority != nil {
 		fields = append(fields, accountgroup.FieldPriority)
 	}
+	if m.tier != nil {
+		fields = append(fields, accountgroup.FieldTier)
+	}
+	if m.concurrency_share != nil {
+		fields = append(fields, accountgroup.FieldConcurrencyShare)
+	}
 	if m.created_at != nil {
 		fields = append(fields, accountgroup.FieldCreatedAt)
 	}
@@ -5350,6 +6091,10 @@ func (m *AccountGroupMutation) Field(name string) (ent.Value, bool) {
 		return m.GroupID()
 	case accountgroup.FieldPriority:
 		return m.Priority()
+	case accountgroup.FieldTier:
+		return m.Tier()
+	case accountgroup.FieldConcurrencyShare:
+		return m.ConcurrencyShare()
 	case accountgroup.FieldCreatedAt:
 		return m.CreatedAt()
 	}
@@ -5389,6 +6134,20 @@ func (m *AccountGroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetPriority(v)
 		return nil
+	case accountgroup.FieldTier:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTier(v)
+		return nil
+	case accountgroup.FieldConcurrencyShare:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConcurrencyShare(v)
+		return nil
 	case accountgroup.FieldCreatedAt:
 		v, ok := value.(time.Time)
 		if !ok {
@@ -5407,6 +6166,12 @@ func (m *AccountGroupMutation) AddedFields() []string {
 	if m.addpriority != nil {
 		fields = append(fields, accountgroup.FieldPriority)
 	}
+	if m.addtier != nil {
+		fields = append(fields, accountgroup.FieldTier)
+	}
+	if m.addconcurrency_share != nil {
+		fields = append(fields, accountgroup.FieldConcurrencyShare)
+	}
 	return fields
 }
 
@@ -5417,6 +6182,10 @@ func (m *AccountGroupMutation) AddedField(name string) (ent.Value, bool) {
 	switch name {
 	case accountgroup.FieldPriority:
 		return m.AddedPriority()
+	case accountgroup.FieldTier:
+		return m.AddedTier()
+	case accountgroup.FieldConcurrencyShare:
+		return m.AddedConcurrencyShare()
 	}
 	return nil, false
 }
@@ -5433,6 +6202,20 @@ func (m *AccountGroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddPriority(v)
 		return nil
+	case accountgroup.FieldTier:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTier(v)
+		return nil
+	case accountgroup.FieldConcurrencyShare:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddConcurrencyShare(v)
+		return nil
 	}
 	return fmt.Errorf("unknown AccountGroup numeric field %s", name)
 }
@@ -5469,6 +6252,12 @@ func (m *AccountGroupMutation) ResetField(name string) error {
 	case accountgroup.FieldPriority:
 		m.ResetPriority()
 		return nil
+	case accountgroup.FieldTier:
+		m.ResetTier()
+		return nil
+	case accountgroup.FieldConcurrencyShare:
+		m.ResetConcurrencyShare()
+		return nil
 	case accountgroup.FieldCreatedAt:
 		m.ResetCreatedAt()
 		return nil
@@ -20806,6 +21595,8 @@ type GroupMutation struct {
 	peak_end                                *string
 	peak_rate_multiplier                    *float64
 	addpeak_rate_multiplier                 *float64
+	request_surcharge                       *float64
+	addrequest_surcharge                    *float64
 	is_exclusive                            *bool
 	status                                  *string
 	platform                                *string
@@ -20862,6 +21653,22 @@ type GroupMutation struct {
 	models_list_config                      *domain.GroupModelsListConfig
 	rpm_limit                               *int
 	addrpm_limit                            *int
+	reserved_concurrency_ratio              *float64
+	addreserved_concurrency_ratio           *float64
+	overload_fallback_models                *map[string]string
+	context_overflow_models                 *map[string]string
+	context_overflow_reject                 *bool
+	context_truncation_strategy             *string
+	account_selection_strategy              *string
+	image_max_edge                          *int
+	addimage_max_edge                       *int
+	image_max_bytes                         *int
+	addimage_max_bytes                      *int
+	output_postprocess                      *domain.GroupOutputPostprocessConfig
+	language_routing                        *domain.GroupLanguageRoutingConfig
+	stream_replay                           *domain.GroupStreamReplayConfig
+	capability_check                        *domain.GroupCapabilityCheckConfig
+	auto_continuation                       *domain.GroupAutoContinuationConfig
 	clearedFields                           map[string]struct{}
 	api_keys                                map[int64]struct{}
 	removedapi_keys                         map[int64]struct{}
@@ -21410,6 +22217,62 @@ func (m *GroupMutation) ResetPeakRateMultiplier() {
 	m.addpeak_rate_multiplier = nil
 }
 
+// SetRequestSurcharge sets the "request_surcharge" field.
+func (m *GroupMutation) SetRequestSurcharge(f float64) {
+	m.request_surcharge = &f
+	m.addrequest_surcharge = nil
+}
+
+// RequestSurcharge returns the value of the "request_surcharge" field in the mutation.
+func (m *GroupMutation) RequestSurcharge() (r float64, exists bool) {
+	v := m.request_surcharge
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestSurcharge returns the old "request_surcharge" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldRequestSurcharge(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestSurcharge is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestSurcharge requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestSurcharge: %w", err)
+	}
+	return oldValue.RequestSurcharge, nil
+}
+
+// AddRequestSurcharge adds f to the "request_surcharge" field.
+func (m *GroupMutation) AddRequestSurcharge(f float64) {
+	if m.addrequest_surcharge != nil {
+		*m.addrequest_surcharge += f
+	} else {
+		m.addrequest_surcharge = &f
+	}
+}
+
+// AddedRequestSurcharge returns the value that was added to the "request_surcharge" field in this mutation.
+func (m *GroupMutation) AddedRequestSurcharge() (r float64, exists bool) {
+	v := m.addrequest_surcharge
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetRequestSurcharge resets all changes to the "request_surcharge" field.
+func (m *GroupMutation) ResetRequestSurcharge() {
+	m.request_surcharge = nil
+	m.addrequest_surcharge = nil
+}
+
 // SetIsExclusive sets the "is_exclusive" field.
 func (m *GroupMutation) SetIsExclusive(b bool) {
 	m.is_exclusive = &b
@@ -23284,6 +24147,560 @@ func (m *GroupMutation) ResetRpmLimit() {
 	m.addrpm_limit = nil
 }
 
+// SetReservedConcurrencyRatio sets the "reserved_concurrency_ratio" field.
+func (m *GroupMutation) SetReservedConcurrencyRatio(f float64) {
+	m.reserved_concurrency_ratio = &f
+	m.addreserved_concurrency_ratio = nil
+}
+
+// ReservedConcurrencyRatio returns the value of the "reserved_concurrency_ratio" field in the mutation.
+func (m *GroupMutation) ReservedConcurrencyRatio() (r float64, exists bool) {
+	v := m.reserved_concurrency_ratio
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldReservedConcurrencyRatio returns the old "reserved_concurrency_ratio" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldReservedConcurrencyRatio(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldReservedConcurrencyRatio is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldReservedConcurrencyRatio requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldReservedConcurrencyRatio: %w", err)
+	}
+	return oldValue.ReservedConcurrencyRatio, nil
+}
+
+// AddReservedConcurrencyRatio adds f to the "reserved_concurrency_ratio" field.
+func (m *GroupMutation) AddReservedConcurrencyRatio(f float64) {
+	if m.addreserved_concurrency_ratio != nil {
+		*m.addreserved_concurrency_ratio += f
+	} else {
+		m.addreserved_concurrency_ratio = &f
+	}
+}
+
+// AddedReservedConcurrencyRatio returns the value that was added to the "reserved_concurrency_ratio" field in this mutation.
+func (m *GroupMutation) AddedReservedConcurrencyRatio() (r float64, exists bool) {
+	v := m.addreserved_concurrency_ratio
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetReservedConcurrencyRatio resets all changes to the "reserved_concurrency_ratio" field.
+func (m *GroupMutation) ResetReservedConcurrencyRatio() {
+	m.reserved_concurrency_ratio = nil
+	m.addreserved_concurrency_ratio = nil
+}
+
+// SetOverloadFallbackModels sets the "overload_fallback_models" field.
+func (m *GroupMutation) SetOverloadFallbackModels(value map[string]string) {
+	m.overload_fallback_models = &value
+}
+
+// OverloadFallbackModels returns the value of the "overload_fallback_models" field in the mutation.
+func (m *GroupMutation) OverloadFallbackModels() (r map[string]string, exists bool) {
+	v := m.overload_fallback_models
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOverloadFallbackModels returns the old "overload_fallback_models" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldOverloadFallbackModels(ctx context.Context) (v map[string]string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOverloadFallbackModels is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOverloadFallbackModels requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOverloadFallbackModels: %w", err)
+	}
+	return oldValue.OverloadFallbackModels, nil
+}
+
+// ClearOverloadFallbackModels clears the value of the "overload_fallback_models" field.
+func (m *GroupMutation) ClearOverloadFallbackModels() {
+	m.overload_fallback_models = nil
+	m.clearedFields[group.FieldOverloadFallbackModels] = struct{}{}
+}
+
+// OverloadFallbackModelsCleared returns if the "overload_fallback_models" field was cleared in this mutation.
+func (m *GroupMutation) OverloadFallbackModelsCleared() bool {
+	_, ok := m.clearedFields[group.FieldOverloadFallbackModels]
+	return ok
+}
+
+// ResetOverloadFallbackModels resets all changes to the "overload_fallback_models" field.
+func (m *GroupMutation) ResetOverloadFallbackModels() {
+	m.overload_fallback_models = nil
+	delete(m.clearedFields, group.FieldOverloadFallbackModels)
+}
+
+// SetContextOverflowModels sets the "context_overflow_models" field.
+func (m *GroupMutation) SetContextOverflowModels(value map[string]string) {
+	m.context_overflow_models = &value
+}
+
+// ContextOverflowModels returns the value of the "context_overflow_models" field in the mutation.
+func (m *GroupMutation) ContextOverflowModels() (r map[string]string, exists bool) {
+	v := m.context_overflow_models
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldContextOverflowModels returns the old "context_overflow_models" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldContextOverflowModels(ctx context.Context) (v map[string]string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldContextOverflowModels is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldContextOverflowModels requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldContextOverflowModels: %w", err)
+	}
+	return oldValue.ContextOverflowModels, nil
+}
+
+// ClearContextOverflowModels clears the value of the "context_overflow_models" field.
+func (m *GroupMutation) ClearContextOverflowModels() {
+	m.context_overflow_models = nil
+	m.clearedFields[group.FieldContextOverflowModels] = struct{}{}
+}
+
+// ContextOverflowModelsCleared returns if the "context_overflow_models" field was cleared in this mutation.
+func (m *GroupMutation) ContextOverflowModelsCleared() bool {
+	_, ok := m.clearedFields[group.FieldContextOverflowModels]
+	return ok
+}
+
+// ResetContextOverflowModels resets all changes to the "context_overflow_models" field.
+func (m *GroupMutation) ResetContextOverflowModels() {
+	m.context_overflow_models = nil
+	delete(m.clearedFields, group.FieldContextOverflowModels)
+}
+
+// SetContextOverflowReject sets the "context_overflow_reject" field.
+func (m *GroupMutation) SetContextOverflowReject(b bool) {
+	m.context_overflow_reject = &b
+}
+
+// ContextOverflowReject returns the value of the "context_overflow_reject" field in the mutation.
+func (m *GroupMutation) ContextOverflowReject() (r bool, exists bool) {
+	v := m.context_overflow_reject
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldContextOverflowReject returns the old "context_overflow_reject" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldContextOverflowReject(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldContextOverflowReject is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldContextOverflowReject requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldContextOverflowReject: %w", err)
+	}
+	return oldValue.ContextOverflowReject, nil
+}
+
+// ResetContextOverflowReject resets all changes to the "context_overflow_reject" field.
+func (m *GroupMutation) ResetContextOverflowReject() {
+	m.context_overflow_reject = nil
+}
+
+// SetContextTruncationStrategy sets the "context_truncation_strategy" field.
+func (m *GroupMutation) SetContextTruncationStrategy(s string) {
+	m.context_truncation_strategy = &s
+}
+
+// ContextTruncationStrategy returns the value of the "context_truncation_strategy" field in the mutation.
+func (m *GroupMutation) ContextTruncationStrategy() (r string, exists bool) {
+	v := m.context_truncation_strategy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldContextTruncationStrategy returns the old "context_truncation_strategy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldContextTruncationStrategy(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldContextTruncationStrategy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldContextTruncationStrategy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldContextTruncationStrategy: %w", err)
+	}
+	return oldValue.ContextTruncationStrategy, nil
+}
+
+// ResetContextTruncationStrategy resets all changes to the "context_truncation_strategy" field.
+func (m *GroupMutation) ResetContextTruncationStrategy() {
+	m.context_truncation_strategy = nil
+}
+
+// SetAccountSelectionStrategy sets the "account_selection_strategy" field.
+func (m *GroupMutation) SetAccountSelectionStrategy(s string) {
+	m.account_selection_strategy = &s
+}
+
+// AccountSelectionStrategy returns the value of the "account_selection_strategy" field in the mutation.
+func (m *GroupMutation) AccountSelectionStrategy() (r string, exists bool) {
+	v := m.account_selection_strategy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAccountSelectionStrategy returns the old "account_selection_strategy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAccountSelectionStrategy(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAccountSelectionStrategy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAccountSelectionStrategy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAccountSelectionStrategy: %w", err)
+	}
+	return oldValue.AccountSelectionStrategy, nil
+}
+
+// ResetAccountSelectionStrategy resets all changes to the "account_selection_strategy" field.
+func (m *GroupMutation) ResetAccountSelectionStrategy() {
+	m.account_selection_strategy = nil
+}
+
+// SetImageMaxEdge sets the "image_max_edge" field.
+func (m *GroupMutation) SetImageMaxEdge(i int) {
+	m.image_max_edge = &i
+	m.addimage_max_edge = nil
+}
+
+// ImageMaxEdge returns the value of the "image_max_edge" field in the mutation.
+func (m *GroupMutation) ImageMaxEdge() (r int, exists bool) {
+	v := m.image_max_edge
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldImageMaxEdge returns the old "image_max_edge" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldImageMaxEdge(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldImageMaxEdge is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldImageMaxEdge requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldImageMaxEdge: %w", err)
+	}
+	return oldValue.ImageMaxEdge, nil
+}
+
+// AddImageMaxEdge adds i to the "image_max_edge" field.
+func (m *GroupMutation) AddImageMaxEdge(i int) {
+	if m.addimage_max_edge != nil {
+		*m.addimage_max_edge += i
+	} else {
+		m.addimage_max_edge = &i
+	}
+}
+
+// AddedImageMaxEdge returns the value that was added to the "image_max_edge" field in this mutation.
+func (m *GroupMutation) AddedImageMaxEdge() (r int, exists bool) {
+	v := m.addimage_max_edge
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetImageMaxEdge resets all changes to the "image_max_edge" field.
+func (m *GroupMutation) ResetImageMaxEdge() {
+	m.image_max_edge = nil
+	m.addimage_max_edge = nil
+}
+
+// SetImageMaxBytes sets the "image_max_bytes" field.
+func (m *GroupMutation) SetImageMaxBytes(i int) {
+	m.image_max_bytes = &i
+	m.addimage_max_bytes = nil
+}
+
+// ImageMaxBytes returns the value of the "image_max_bytes" field in the mutation.
+func (m *GroupMutation) ImageMaxBytes() (r int, exists bool) {
+	v := m.image_max_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldImageMaxBytes returns the old "image_max_bytes" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldImageMaxBytes(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldImageMaxBytes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldImageMaxBytes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldImageMaxBytes: %w", err)
+	}
+	return oldValue.ImageMaxBytes, nil
+}
+
+// AddImageMaxBytes adds i to the "image_max_bytes" field.
+func (m *GroupMutation) AddImageMaxBytes(i int) {
+	if m.addimage_max_bytes != nil {
+		*m.addimage_max_bytes += i
+	} else {
+		m.addimage_max_bytes = &i
+	}
+}
+
+// AddedImageMaxBytes returns the value that was added to the "image_max_bytes" field in this mutation.
+func (m *GroupMutation) AddedImageMaxBytes() (r int, exists bool) {
+	v := m.addimage_max_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetImageMaxBytes resets all changes to the "image_max_bytes" field.
+func (m *GroupMutation) ResetImageMaxBytes() {
+	m.image_max_bytes = nil
+	m.addimage_max_bytes = nil
+}
+
+// SetOutputPostprocess sets the "output_postprocess" field.
+func (m *GroupMutation) SetOutputPostprocess(dopc domain.GroupOutputPostprocessConfig) {
+	m.output_postprocess = &dopc
+}
+
+// OutputPostprocess returns the value of the "output_postprocess" field in the mutation.
+func (m *GroupMutation) OutputPostprocess() (r domain.GroupOutputPostprocessConfig, exists bool) {
+	v := m.output_postprocess
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOutputPostprocess returns the old "output_postprocess" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldOutputPostprocess(ctx context.Context) (v domain.GroupOutputPostprocessConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOutputPostprocess is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOutputPostprocess requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOutputPostprocess: %w", err)
+	}
+	return oldValue.OutputPostprocess, nil
+}
+
+// ResetOutputPostprocess resets all changes to the "output_postprocess" field.
+func (m *GroupMutation) ResetOutputPostprocess() {
+	m.output_postprocess = nil
+}
+
+// SetLanguageRouting sets the "language_routing" field.
+func (m *GroupMutation) SetLanguageRouting(dlrc domain.GroupLanguageRoutingConfig) {
+	m.language_routing = &dlrc
+}
+
+// LanguageRouting returns the value of the "language_routing" field in the mutation.
+func (m *GroupMutation) LanguageRouting() (r domain.GroupLanguageRoutingConfig, exists bool) {
+	v := m.language_routing
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldLanguageRouting returns the old "language_routing" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldLanguageRouting(ctx context.Context) (v domain.GroupLanguageRoutingConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldLanguageRouting is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldLanguageRouting requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldLanguageRouting: %w", err)
+	}
+	return oldValue.LanguageRouting, nil
+}
+
+// ResetLanguageRouting resets all changes to the "language_routing" field.
+func (m *GroupMutation) ResetLanguageRouting() {
+	m.language_routing = nil
+}
+
+// SetStreamReplay sets the "stream_replay" field.
+func (m *GroupMutation) SetStreamReplay(dsrc domain.GroupStreamReplayConfig) {
+	m.stream_replay = &dsrc
+}
+
+// StreamReplay returns the value of the "stream_replay" field in the mutation.
+func (m *GroupMutation) StreamReplay() (r domain.GroupStreamReplayConfig, exists bool) {
+	v := m.stream_replay
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStreamReplay returns the old "stream_replay" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldStreamReplay(ctx context.Context) (v domain.GroupStreamReplayConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStreamReplay is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStreamReplay requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStreamReplay: %w", err)
+	}
+	return oldValue.StreamReplay, nil
+}
+
+// ResetStreamReplay resets all changes to the "stream_replay" field.
+func (m *GroupMutation) ResetStreamReplay() {
+	m.stream_replay = nil
+}
+
+// SetCapabilityCheck sets the "capability_check" field.
+func (m *GroupMutation) SetCapabilityCheck(dccc domain.GroupCapabilityCheckConfig) {
+	m.capability_check = &dccc
+}
+
+// CapabilityCheck returns the value of the "capability_check" field in the mutation.
+func (m *GroupMutation) CapabilityCheck() (r domain.GroupCapabilityCheckConfig, exists bool) {
+	v := m.capability_check
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCapabilityCheck returns the old "capability_check" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldCapabilityCheck(ctx context.Context) (v domain.GroupCapabilityCheckConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCapabilityCheck is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCapabilityCheck requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCapabilityCheck: %w", err)
+	}
+	return oldValue.CapabilityCheck, nil
+}
+
+// ResetCapabilityCheck resets all changes to the "capability_check" field.
+func (m *GroupMutation) ResetCapabilityCheck() {
+	m.capability_check = nil
+}
+
+// SetAutoContinuation sets the "auto_continuation" field.
+func (m *GroupMutation) SetAutoContinuation(dacc domain.GroupAutoContinuationConfig) {
+	m.auto_continuation = &dacc
+}
+
+// AutoContinuation returns the value of the "auto_continuation" field in the mutation.
+func (m *GroupMutation) AutoContinuation() (r domain.GroupAutoContinuationConfig, exists bool) {
+	v := m.auto_continuation
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAutoContinuation returns the old "auto_continuation" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAutoContinuation(ctx context.Context) (v domain.GroupAutoContinuationConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAutoContinuation is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAutoContinuation requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAutoContinuation: %w", err)
+	}
+	return oldValue.AutoContinuation, nil
+}
+
+// ResetAutoContinuation resets all changes to the "auto_continuation" field.
+func (m *GroupMutation) ResetAutoContinuation() {
+	m.auto_continuation = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *GroupMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -23642,7 +25059,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 47)
+	fields := make([]string, 0, 61)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -23673,6 +25090,9 @@ func (m *GroupMutation) Fields() []string {
 	if m.peak_rate_multiplier != nil {
 		fields = append(fields, group.FieldPeakRateMultiplier)
 	}
+	if m.request_surcharge != nil {
+		fields = append(fields, group.FieldRequestSurcharge)
+	}
 	if m.is_exclusive != nil {
 		fields = append(fields, group.FieldIsExclusive)
 	}
@@ -23784,6 +25204,45 @@ func (m *GroupMutation) Fields() []string {
 	if m.rpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.reserved_concurrency_ratio != nil {
+		fields = append(fields, group.FieldReservedConcurrencyRatio)
+	}
+	if m.overload_fallback_models != nil {
+		fields = append(fields, group.FieldOverloadFallbackModels)
+	}
+	if m.context_overflow_models != nil {
+		fields = append(fields, group.FieldContextOverflowModels)
+	}
+	if m.context_overflow_reject != nil {
+		fields = append(fields, group.FieldContextOverflowReject)
+	}
+	if m.context_truncation_strategy != nil {
+		fields = append(fields, group.FieldContextTruncationStrategy)
+	}
+	if m.account_selection_strategy != nil {
+		fields = append(fields, group.FieldAccountSelectionStrategy)
+	}
+	if m.image_max_edge != nil {
+		fields = append(fields, group.FieldImageMaxEdge)
+	}
+	if m.image_max_bytes != nil {
+		fields = append(fields, group.FieldImageMaxBytes)
+	}
+	if m.output_postprocess != nil {
+		fields = append(fields, group.FieldOutputPostprocess)
+	}
+	if m.language_routing != nil {
+		fields = append(fields, group.FieldLanguageRouting)
+	}
+	if m.stream_replay != nil {
+		fields = append(fields, group.FieldStreamReplay)
+	}
+	if m.capability_check != nil {
+		fields = append(fields, group.FieldCapabilityCheck)
+	}
+	if m.auto_continuation != nil {
+		fields = append(fields, group.FieldAutoContinuation)
+	}
 	return fields
 }
 
@@ -23812,6 +25271,8 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.PeakEnd()
 	case group.FieldPeakRateMultiplier:
 		return m.PeakRateMultiplier()
+	case group.FieldRequestSurcharge:
+		return m.RequestSurcharge()
 	case group.FieldIsExclusive:
 		return m.IsExclusive()
 	case group.FieldStatus:
@@ -23886,6 +25347,32 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.ModelsListConfig()
 	case group.FieldRpmLimit:
 		return m.RpmLimit()
+	case group.FieldReservedConcurrencyRatio:
+		return m.ReservedConcurrencyRatio()
+	case group.FieldOverloadFallbackModels:
+		return m.OverloadFallbackModels()
+	case group.FieldContextOverflowModels:
+		return m.ContextOverflowModels()
+	case group.FieldContextOverflowReject:
+		return m.ContextOverflowReject()
+	case group.FieldContextTruncationStrategy:
+		return m.ContextTruncationStrategy()
+	case group.FieldAccountSelectionStrategy:
+		return m.AccountSelectionStrategy()
+	case group.FieldImageMaxEdge:
+		return m.ImageMaxEdge()
+	case group.FieldImageMaxBytes:
+		return m.ImageMaxBytes()
+	case group.FieldOutputPostprocess:
+		return m.OutputPostprocess()
+	case group.FieldLanguageRouting:
+		return m.LanguageRouting()
+	case group.FieldStreamReplay:
+		return m.StreamReplay()
+	case group.FieldCapabilityCheck:
+		return m.CapabilityCheck()
+	case group.FieldAutoContinuation:
+		return m.AutoContinuation()
 	}
 	return nil, false
 }
@@ -23915,6 +25402,8 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldPeakEnd(ctx)
 	case group.FieldPeakRateMultiplier:
 		return m.OldPeakRateMultiplier(ctx)
+	case group.FieldRequestSurcharge:
+		return m.OldRequestSurcharge(ctx)
 	case group.FieldIsExclusive:
 		return m.OldIsExclusive(ctx)
 	case group.FieldStatus:
@@ -23989,6 +25478,32 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldModelsListConfig(ctx)
 	case group.FieldRpmLimit:
 		return m.OldRpmLimit(ctx)
+	case group.FieldReservedConcurrencyRatio:
+		return m.OldReservedConcurrencyRatio(ctx)
+	case group.FieldOverloadFallbackModels:
+		return m.OldOverloadFallbackModels(ctx)
+	case group.FieldContextOverflowModels:
+		return m.OldContextOverflowModels(ctx)
+	case group.FieldContextOverflowReject:
+		return m.OldContextOverflowReject(ctx)
+	case group.FieldContextTruncationStrategy:
+		return m.OldContextTruncationStrategy(ctx)
+	case group.FieldAccountSelectionStrategy:
+		return m.OldAccountSelectionStrategy(ctx)
+	case group.FieldImageMaxEdge:
+		return m.OldImageMaxEdge(ctx)
+	case group.FieldImageMaxBytes:
+		return m.OldImageMaxBytes(ctx)
+	case group.FieldOutputPostprocess:
+		return m.OldOutputPostprocess(ctx)
+	case group.FieldLanguageRouting:
+		return m.OldLanguageRouting(ctx)
+	case group.FieldStreamReplay:
+		return m.OldStreamReplay(ctx)
+	case group.FieldCapabilityCheck:
+		return m.OldCapabilityCheck(ctx)
+	case group.FieldAutoContinuation:
+		return m.OldAutoContinuation(ctx)
 	}
 	return nil, fmt.Errorf("unknown Group field %s", name)
 }
@@ -24068,6 +25583,13 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetPeakRateMultiplier(v)
 		return nil
+	case group.FieldRequestSurcharge:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestSurcharge(v)
+		return nil
 	case group.FieldIsExclusive:
 		v, ok := value.(bool)
 		if !ok {
@@ -24327,6 +25849,97 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRpmLimit(v)
 		return nil
+	case group.FieldReservedConcurrencyRatio:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetReservedConcurrencyRatio(v)
+		return nil
+	case group.FieldOverloadFallbackModels:
+		v, ok := value.(map[string]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOverloadFallbackModels(v)
+		return nil
+	case group.FieldContextOverflowModels:
+		v, ok := value.(map[string]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContextOverflowModels(v)
+		return nil
+	case group.FieldContextOverflowReject:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContextOverflowReject(v)
+		return nil
+	case group.FieldContextTruncationStrategy:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContextTruncationStrategy(v)
+		return nil
+	case group.FieldAccountSelectionStrategy:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAccountSelectionStrategy(v)
+		return nil
+	case group.FieldImageMaxEdge:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetImageMaxEdge(v)
+		return nil
+	case group.FieldImageMaxBytes:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetImageMaxBytes(v)
+		return nil
+	case group.FieldOutputPostprocess:
+		v, ok := value.(domain.GroupOutputPostprocessConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOutputPostprocess(v)
+		return nil
+	case group.FieldLanguageRouting:
+		v, ok := value.(domain.GroupLanguageRoutingConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetLanguageRouting(v)
+		return nil
+	case group.FieldStreamReplay:
+		v, ok := value.(domain.GroupStreamReplayConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStreamReplay(v)
+		return nil
+	case group.FieldCapabilityCheck:
+		v, ok := value.(domain.GroupCapabilityCheckConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCapabilityCheck(v)
+		return nil
+	case group.FieldAutoContinuation:
+		v, ok := value.(domain.GroupAutoContinuationConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAutoContinuation(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -24341,6 +25954,9 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addpeak_rate_multiplier != nil {
 		fields = append(fields, group.FieldPeakRateMultiplier)
 	}
+	if m.addrequest_surcharge != nil {
+		fields = append(fields, group.FieldRequestSurcharge)
+	}
 	if m.adddaily_limit_usd != nil {
 		fields = append(fields, group.FieldDailyLimitUsd)
 	}
@@ -24395,6 +26011,15 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addrpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.addreserved_concurrency_ratio != nil {
+		fields = append(fields, group.FieldReservedConcurrencyRatio)
+	}
+	if m.addimage_max_edge != nil {
+		fields = append(fields, group.FieldImageMaxEdge)
+	}
+	if m.addimage_max_bytes != nil {
+		fields = append(fields, group.FieldImageMaxBytes)
+	}
 	return fields
 }
 
@@ -24407,6 +26032,8 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedRateMultiplier()
 	case group.FieldPeakRateMultiplier:
 		return m.AddedPeakRateMultiplier()
+	case group.FieldRequestSurcharge:
+		return m.AddedRequestSurcharge()
 	case group.FieldDailyLimitUsd:
 		return m.AddedDailyLimitUsd()
 	case group.FieldWeeklyLimitUsd:
@@ -24443,6 +26070,12 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedSortOrder()
 	case group.FieldRpmLimit:
 		return m.AddedRpmLimit()
+	case group.FieldReservedConcurrencyRatio:
+		return m.AddedReservedConcurrencyRatio()
+	case group.FieldImageMaxEdge:
+		return m.AddedImageMaxEdge()
+	case group.FieldImageMaxBytes:
+		return m.AddedImageMaxBytes()
 	}
 	return nil, false
 }
@@ -24466,6 +26099,13 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddPeakRateMultiplier(v)
 		return nil
+	case group.FieldRequestSurcharge:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRequestSurcharge(v)
+		return nil
 	case group.FieldDailyLimitUsd:
 		v, ok := value.(float64)
 		if !ok {
@@ -24592,6 +26232,27 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddRpmLimit(v)
 		return nil
+	case group.FieldReservedConcurrencyRatio:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddReservedConcurrencyRatio(v)
+		return nil
+	case group.FieldImageMaxEdge:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddImageMaxEdge(v)
+		return nil
+	case group.FieldImageMaxBytes:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddImageMaxBytes(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group numeric field %s", name)
 }
@@ -24642,6 +26303,12 @@ func (m *GroupMutation) ClearedFields() []string {
 	if m.FieldCleared(group.FieldModelRouting) {
 		fields = append(fields, group.FieldModelRouting)
 	}
+	if m.FieldCleared(group.FieldOverloadFallbackModels) {
+		fields = append(fields, group.FieldOverloadFallbackModels)
+	}
+	if m.FieldCleared(group.FieldContextOverflowModels) {
+		fields = append(fields, group.FieldContextOverflowModels)
+	}
 	return fields
 }
 
@@ -24698,6 +26365,12 @@ func (m *GroupMutation) ClearField(name string) error {
 	case group.FieldModelRouting:
 		m.ClearModelRouting()
 		return nil
+	case group.FieldOverloadFallbackModels:
+		m.ClearOverloadFallbackModels()
+		return nil
+	case group.FieldContextOverflowModels:
+		m.ClearContextOverflowModels()
+		return nil
 	}
 	return fmt.Errorf("unknown Group nullable field %s", name)
 }
@@ -24736,6 +26409,9 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldPeakRateMultiplier:
 		m.ResetPeakRateMultiplier()
 		return nil
+	case group.FieldRequestSurcharge:
+		m.ResetRequestSurcharge()
+		return nil
 	case group.FieldIsExclusive:
 		m.ResetIsExclusive()
 		return nil
@@ -24847,6 +26523,45 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldRpmLimit:
 		m.ResetRpmLimit()
 		return nil
+	case group.FieldReservedConcurrencyRatio:
+		m.ResetReservedConcurrencyRatio()
+		return nil
+	case group.FieldOverloadFallbackModels:
+		m.ResetOverloadFallbackModels()
+		return nil
+	case group.FieldContextOverflowModels:
+		m.ResetContextOverflowModels()
+		return nil
+	case group.FieldContextOverflowReject:
+		m.ResetContextOverflowReject()
+		return nil
+	case group.FieldContextTruncationStrategy:
+		m.ResetContextTruncationStrategy()
+		return nil
+	case group.FieldAccountSelectionStrategy:
+		m.ResetAccountSelectionStrategy()
+		return nil
+	case group.FieldImageMaxEdge:
+		m.ResetImageMaxEdge()
+		return nil
+	case group.FieldImageMaxBytes:
+		m.ResetImageMaxBytes()
+		return nil
+	case group.FieldOutputPostprocess:
+		m.ResetOutputPostprocess()
+		return nil
+	case group.FieldLanguageRouting:
+		m.ResetLanguageRouting()
+		return nil
+	case group.FieldStreamReplay:
+		m.ResetStreamReplay()
+		return nil
+	case group.FieldCapabilityCheck:
+		m.ResetCapabilityCheck()
+		return nil
+	case group.FieldAutoContinuation:
+		m.ResetAutoContinuation()
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -40579,8 +42294,8 @@ type UsageCleanupTaskMutation struct {
 	created_at      *time.Time
 	updated_at      *time.Time
 	status          *string
-	filters         *json.RawMessage
-	appendfilters   json.RawMessage
+	filters         *jsontext.Value
+	appendfilters   jsontext.Value
 	created_by      *int64
 	addcreated_by   *int64
 	deleted_rows    *int64
@@ -40804,13 +42519,13 @@ func (m *UsageCleanupTaskMutation) ResetStatus() {
 }
 
 // SetFilters sets the "filters" field.
-func (m *UsageCleanupTaskMutation) SetFilters(jm json.RawMessage) {
-	m.filters = &jm
+func (m *UsageCleanupTaskMutation) SetFilters(j jsontext.Value) {
+	m.filters = &j
 	m.appendfilters = nil
 }
 
 // Filters returns the value of the "filters" field in the mutation.
-func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
+func (m *UsageCleanupTaskMutation) Filters() (r jsontext.Value, exists bool) {
 	v := m.filters
 	if v == nil {
 		return
@@ -40821,7 +42536,7 @@ func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
 // OldFilters returns the old "filters" field's value of the UsageCleanupTask entity.
 // If the UsageCleanupTask object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMessage, err error) {
+func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v jsontext.Value, err error) {
 	if !m.op.Is(OpUpdateOne) {
 		return v, errors.New("OldFilters is only allowed on UpdateOne operations")
 	}
@@ -40835,13 +42550,13 @@ func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMe
 	return oldValue.Filters, nil
 }
 
-// AppendFilters adds jm to the "filters" field.
-func (m *UsageCleanupTaskMutation) AppendFilters(jm json.RawMessage) {
-	m.appendfilters = append(m.appendfilters, jm...)
+// AppendFilters adds j to the "filters" field.
+func (m *UsageCleanupTaskMutation) AppendFilters(j jsontext.Value) {
+	m.appendfilters = append(m.appendfilters, j...)
 }
 
 // AppendedFilters returns the list of values that were appended to the "filters" field in this mutation.
-func (m *UsageCleanupTaskMutation) AppendedFilters() (json.RawMessage, bool) {
+func (m *UsageCleanupTaskMutation) AppendedFilters() (jsontext.Value, bool) {
 	if len(m.appendfilters) == 0 {
 		return nil, false
 	}
@@ -41392,7 +43107,7 @@ func (m *UsageCleanupTaskMutation) SetField(name string, value ent.Value) error
 		m.SetStatus(v)
 		return nil
 	case usagecleanuptask.FieldFilters:
-		v, ok := value.(json.RawMessage)
+		v, ok := value.(jsontext.Value)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
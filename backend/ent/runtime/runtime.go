@@ -144,6 +144,30 @@ func init() {
 	apikeyDescUsage7d := apikeyFields[16].Descriptor()
 	// apikey.DefaultUsage7d holds the default value on creation for the usage_7d field.
 	apikey.DefaultUsage7d = apikeyDescUsage7d.Default.(float64)
+	// apikeyDescRpmLimit is the schema descriptor for rpm_limit field.
+	apikeyDescRpmLimit := apikeyFields[20].Descriptor()
+	// apikey.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
+	apikey.DefaultRpmLimit = apikeyDescRpmLimit.Default.(int)
+	// apikeyDescTpmLimit is the schema descriptor for tpm_limit field.
+	apikeyDescTpmLimit := apikeyFields[21].Descriptor()
+	// apikey.DefaultTpmLimit holds the default value on creation for the tpm_limit field.
+	apikey.DefaultTpmLimit = apikeyDescTpmLimit.Default.(int64)
+	// apikeyDescCritical is the schema descriptor for critical field.
+	apikeyDescCritical := apikeyFields[22].Descriptor()
+	// apikey.DefaultCritical holds the default value on creation for the critical field.
+	apikey.DefaultCritical = apikeyDescCritical.Default.(bool)
+	// apikeyDescGuest is the schema descriptor for guest field.
+	apikeyDescGuest := apikeyFields[23].Descriptor()
+	// apikey.DefaultGuest holds the default value on creation for the guest field.
+	apikey.DefaultGuest = apikeyDescGuest.Default.(bool)
+	// apikeyDescTokenLimit is the schema descriptor for token_limit field.
+	apikeyDescTokenLimit := apikeyFields[25].Descriptor()
+	// apikey.DefaultTokenLimit holds the default value on creation for the token_limit field.
+	apikey.DefaultTokenLimit = apikeyDescTokenLimit.Default.(int64)
+	// apikeyDescTokensUsed is the schema descriptor for tokens_used field.
+	apikeyDescTokensUsed := apikeyFields[26].Descriptor()
+	// apikey.DefaultTokensUsed holds the default value on creation for the tokens_used field.
+	apikey.DefaultTokensUsed = apikeyDescTokensUsed.Default.(int64)
 	accountMixin := schema.Account{}.Mixin()
 	accountMixinHooks1 := accountMixin[1].Hooks()
 	account.Hooks[0] = accountMixinHooks1[0]
@@ -261,8 +285,16 @@ func init() {
 	accountgroupDescPriority := accountgroupFields[2].Descriptor()
 	// accountgroup.DefaultPriority holds the default value on creation for the priority field.
 	accountgroup.DefaultPriority = accountgroupDescPriority.Default.(int)
+	// accountgroupDescTier is the schema descriptor for tier field.
+	accountgroupDescTier := accountgroupFields[3].Descriptor()
+	// accountgroup.DefaultTier holds the default value on creation for the tier field.
+	accountgroup.DefaultTier = accountgroupDescTier.Default.(int)
+	// accountgroupDescConcurrencyShare is the schema descriptor for concurrency_share field.
+	accountgroupDescConcurrencyShare := accountgroupFields[4].Descriptor()
+	// accountgroup.DefaultConcurrencyShare holds the default value on creation for the concurrency_share field.
+	accountgroup.DefaultConcurrencyShare = accountgroupDescConcurrencyShare.Default.(int)
 	// accountgroupDescCreatedAt is the schema descriptor for created_at field.
-	accountgroupDescCreatedAt := accountgroupFields[3].Descriptor()
+	accountgroupDescCreatedAt := accountgroupFields[5].Descriptor()
 	// accountgroup.DefaultCreatedAt holds the default value on creation for the created_at field.
 	accountgroup.DefaultCreatedAt = accountgroupDescCreatedAt.Default.(func() time.Time)
 	announcementFields := schema.Announcement{}.Fields()
@@ -985,114 +1017,166 @@ func init() {
 	groupDescPeakRateMultiplier := groupFields[6].Descriptor()
 	// group.DefaultPeakRateMultiplier holds the default value on creation for the peak_rate_multiplier field.
 	group.DefaultPeakRateMultiplier = groupDescPeakRateMultiplier.Default.(float64)
+	// groupDescRequestSurcharge is the schema descriptor for request_surcharge field.
+	groupDescRequestSurcharge := groupFields[7].Descriptor()
+	// group.DefaultRequestSurcharge holds the default value on creation for the request_surcharge field.
+	group.DefaultRequestSurcharge = groupDescRequestSurcharge.Default.(float64)
 	// groupDescIsExclusive is the schema descriptor for is_exclusive field.
-	groupDescIsExclusive := groupFields[7].Descriptor()
+	groupDescIsExclusive := groupFields[8].Descriptor()
 	// group.DefaultIsExclusive holds the default value on creation for the is_exclusive field.
 	group.DefaultIsExclusive = groupDescIsExclusive.Default.(bool)
 	// groupDescStatus is the schema descriptor for status field.
-	groupDescStatus := groupFields[8].Descriptor()
+	groupDescStatus := groupFields[9].Descriptor()
 	// group.DefaultStatus holds the default value on creation for the status field.
 	group.DefaultStatus = groupDescStatus.Default.(string)
 	// group.StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	group.StatusValidator = groupDescStatus.Validators[0].(func(string) error)
 	// groupDescPlatform is the schema descriptor for platform field.
-	groupDescPlatform := groupFields[9].Descriptor()
+	groupDescPlatform := groupFields[10].Descriptor()
 	// group.DefaultPlatform holds the default value on creation for the platform field.
 	group.DefaultPlatform = groupDescPlatform.Default.(string)
 	// group.PlatformValidator is a validator for the "platform" field. It is called by the builders before save.
 	group.PlatformValidator = groupDescPlatform.Validators[0].(func(string) error)
 	// groupDescSubscriptionType is the schema descriptor for subscription_type field.
-	groupDescSubscriptionType := groupFields[10].Descriptor()
+	groupDescSubscriptionType := groupFields[11].Descriptor()
 	// group.DefaultSubscriptionType holds the default value on creation for the subscription_type field.
 	group.DefaultSubscriptionType = groupDescSubscriptionType.Default.(string)
 	// group.SubscriptionTypeValidator is a validator for the "subscription_type" field. It is called by the builders before save.
 	group.SubscriptionTypeValidator = groupDescSubscriptionType.Validators[0].(func(string) error)
 	// groupDescDefaultValidityDays is the schema descriptor for default_validity_days field.
-	groupDescDefaultValidityDays := groupFields[14].Descriptor()
+	groupDescDefaultValidityDays := groupFields[15].Descriptor()
 	// group.DefaultDefaultValidityDays holds the default value on creation for the default_validity_days field.
 	group.DefaultDefaultValidityDays = groupDescDefaultValidityDays.Default.(int)
 	// groupDescAllowImageGeneration is the schema descriptor for allow_image_generation field.
-	groupDescAllowImageGeneration := groupFields[15].Descriptor()
+	groupDescAllowImageGeneration := groupFields[16].Descriptor()
 	// group.DefaultAllowImageGeneration holds the default value on creation for the allow_image_generation field.
 	group.DefaultAllowImageGeneration = groupDescAllowImageGeneration.Default.(bool)
 	// groupDescAllowBatchImageGeneration is the schema descriptor for allow_batch_image_generation field.
-	groupDescAllowBatchImageGeneration := groupFields[16].Descriptor()
+	groupDescAllowBatchImageGeneration := groupFields[17].Descriptor()
 	// group.DefaultAllowBatchImageGeneration holds the default value on creation for the allow_batch_image_generation field.
 	group.DefaultAllowBatchImageGeneration = groupDescAllowBatchImageGeneration.Default.(bool)
 	// groupDescImageRateIndependent is the schema descriptor for image_rate_independent field.
-	groupDescImageRateIndependent := groupFields[17].Descriptor()
+	groupDescImageRateIndependent := groupFields[18].Descriptor()
 	// group.DefaultImageRateIndependent holds the default value on creation for the image_rate_independent field.
 	group.DefaultImageRateIndependent = groupDescImageRateIndependent.Default.(bool)
 	// groupDescImageRateMultiplier is the schema descriptor for image_rate_multiplier field.
-	groupDescImageRateMultiplier := groupFields[18].Descriptor()
+	groupDescImageRateMultiplier := groupFields[19].Descriptor()
 	// group.DefaultImageRateMultiplier holds the default value on creation for the image_rate_multiplier field.
 	group.DefaultImageRateMultiplier = groupDescImageRateMultiplier.Default.(float64)
 	// groupDescBatchImageDiscountMultiplier is the schema descriptor for batch_image_discount_multiplier field.
-	groupDescBatchImageDiscountMultiplier := groupFields[22].Descriptor()
+	groupDescBatchImageDiscountMultiplier := groupFields[23].Descriptor()
 	// group.DefaultBatchImageDiscountMultiplier holds the default value on creation for the batch_image_discount_multiplier field.
 	group.DefaultBatchImageDiscountMultiplier = groupDescBatchImageDiscountMultiplier.Default.(float64)
 	// groupDescBatchImageHoldMultiplier is the schema descriptor for batch_image_hold_multiplier field.
-	groupDescBatchImageHoldMultiplier := groupFields[23].Descriptor()
+	groupDescBatchImageHoldMultiplier := groupFields[24].Descriptor()
 	// group.DefaultBatchImageHoldMultiplier holds the default value on creation for the batch_image_hold_multiplier field.
 	group.DefaultBatchImageHoldMultiplier = groupDescBatchImageHoldMultiplier.Default.(float64)
 	// groupDescVideoRateIndependent is the schema descriptor for video_rate_independent field.
-	groupDescVideoRateIndependent := groupFields[24].Descriptor()
+	groupDescVideoRateIndependent := groupFields[25].Descriptor()
 	// group.DefaultVideoRateIndependent holds the default value on creation for the video_rate_independent field.
 	group.DefaultVideoRateIndependent = groupDescVideoRateIndependent.Default.(bool)
 	// groupDescVideoRateMultiplier is the schema descriptor for video_rate_multiplier field.
-	groupDescVideoRateMultiplier := groupFields[25].Descriptor()
+	groupDescVideoRateMultiplier := groupFields[26].Descriptor()
 	// group.DefaultVideoRateMultiplier holds the default value on creation for the video_rate_multiplier field.
 	group.DefaultVideoRateMultiplier = groupDescVideoRateMultiplier.Default.(float64)
 	// groupDescClaudeCodeOnly is the schema descriptor for claude_code_only field.
-	groupDescClaudeCodeOnly := groupFields[29].Descriptor()
+	groupDescClaudeCodeOnly := groupFields[30].Descriptor()
 	// group.DefaultClaudeCodeOnly holds the default value on creation for the claude_code_only field.
 	group.DefaultClaudeCodeOnly = groupDescClaudeCodeOnly.Default.(bool)
 	// groupDescModelRoutingEnabled is the schema descriptor for model_routing_enabled field.
-	groupDescModelRoutingEnabled := groupFields[33].Descriptor()
+	groupDescModelRoutingEnabled := groupFields[34].Descriptor()
 	// group.DefaultModelRoutingEnabled holds the default value on creation for the model_routing_enabled field.
 	group.DefaultModelRoutingEnabled = groupDescModelRoutingEnabled.Default.(bool)
 	// groupDescMcpXMLInject is the schema descriptor for mcp_xml_inject field.
-	groupDescMcpXMLInject := groupFields[34].Descriptor()
+	groupDescMcpXMLInject := groupFields[35].Descriptor()
 	// group.DefaultMcpXMLInject holds the default value on creation for the mcp_xml_inject field.
 	group.DefaultMcpXMLInject = groupDescMcpXMLInject.Default.(bool)
 	// groupDescSupportedModelScopes is the schema descriptor for supported_model_scopes field.
-	groupDescSupportedModelScopes := groupFields[35].Descriptor()
+	groupDescSupportedModelScopes := groupFields[36].Descriptor()
 	// group.DefaultSupportedModelScopes holds the default value on creation for the supported_model_scopes field.
 	group.DefaultSupportedModelScopes = groupDescSupportedModelScopes.Default.([]string)
 	// groupDescSortOrder is the schema descriptor for sort_order field.
-	groupDescSortOrder := groupFields[36].Descriptor()
+	groupDescSortOrder := groupFields[37].Descriptor()
 	// group.DefaultSortOrder holds the default value on creation for the sort_order field.
 	group.DefaultSortOrder = groupDescSortOrder.Default.(int)
 	// groupDescAllowMessagesDispatch is the schema descriptor for allow_messages_dispatch field.
-	groupDescAllowMessagesDispatch := groupFields[37].Descriptor()
+	groupDescAllowMessagesDispatch := groupFields[38].Descriptor()
 	// group.DefaultAllowMessagesDispatch holds the default value on creation for the allow_messages_dispatch field.
 	group.DefaultAllowMessagesDispatch = groupDescAllowMessagesDispatch.Default.(bool)
 	// groupDescRequireOauthOnly is the schema descriptor for require_oauth_only field.
-	groupDescRequireOauthOnly := groupFields[38].Descriptor()
+	groupDescRequireOauthOnly := groupFields[39].Descriptor()
 	// group.DefaultRequireOauthOnly holds the default value on creation for the require_oauth_only field.
 	group.DefaultRequireOauthOnly = groupDescRequireOauthOnly.Default.(bool)
 	// groupDescRequirePrivacySet is the schema descriptor for require_privacy_set field.
-	groupDescRequirePrivacySet := groupFields[39].Descriptor()
+	groupDescRequirePrivacySet := groupFields[40].Descriptor()
 	// group.DefaultRequirePrivacySet holds the default value on creation for the require_privacy_set field.
 	group.DefaultRequirePrivacySet = groupDescRequirePrivacySet.Default.(bool)
 	// groupDescDefaultMappedModel is the schema descriptor for default_mapped_model field.
-	groupDescDefaultMappedModel := groupFields[40].Descriptor()
+	groupDescDefaultMappedModel := groupFields[41].Descriptor()
 	// group.DefaultDefaultMappedModel holds the default value on creation for the default_mapped_model field.
 	group.DefaultDefaultMappedModel = groupDescDefaultMappedModel.Default.(string)
 	// group.DefaultMappedModelValidator is a validator for the "default_mapped_model" field. It is called by the builders before save.
 	group.DefaultMappedModelValidator = groupDescDefaultMappedModel.Validators[0].(func(string) error)
 	// groupDescMessagesDispatchModelConfig is the schema descriptor for messages_dispatch_model_config field.
-	groupDescMessagesDispatchModelConfig := groupFields[41].Descriptor()
+	groupDescMessagesDispatchModelConfig := groupFields[42].Descriptor()
 	// group.DefaultMessagesDispatchModelConfig holds the default value on creation for the messages_dispatch_model_config field.
 	group.DefaultMessagesDispatchModelConfig = groupDescMessagesDispatchModelConfig.Default.(domain.OpenAIMessagesDispatchModelConfig)
 	// groupDescModelsListConfig is the schema descriptor for models_list_config field.
-	groupDescModelsListConfig := groupFields[42].Descriptor()
+	groupDescModelsListConfig := groupFields[43].Descriptor()
 	// group.DefaultModelsListConfig holds the default value on creation for the models_list_config field.
 	group.DefaultModelsListConfig = groupDescModelsListConfig.Default.(domain.GroupModelsListConfig)
 	// groupDescRpmLimit is the schema descriptor for rpm_limit field.
-	groupDescRpmLimit := groupFields[43].Descriptor()
+	groupDescRpmLimit := groupFields[44].Descriptor()
 	// group.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
 	group.DefaultRpmLimit = groupDescRpmLimit.Default.(int)
+	// groupDescReservedConcurrencyRatio is the schema descriptor for reserved_concurrency_ratio field.
+	groupDescReservedConcurrencyRatio := groupFields[45].Descriptor()
+	// group.DefaultReservedConcurrencyRatio holds the default value on creation for the reserved_concurrency_ratio field.
+	group.DefaultReservedConcurrencyRatio = groupDescReservedConcurrencyRatio.Default.(float64)
+	// groupDescContextOverflowReject is the schema descriptor for context_overflow_reject field.
+	groupDescContextOverflowReject := groupFields[48].Descriptor()
+	// group.DefaultContextOverflowReject holds the default value on creation for the context_overflow_reject field.
+	group.DefaultContextOverflowReject = groupDescContextOverflowReject.Default.(bool)
+	// groupDescContextTruncationStrategy is the schema descriptor for context_truncation_strategy field.
+	groupDescContextTruncationStrategy := groupFields[49].Descriptor()
+	// group.DefaultContextTruncationStrategy holds the default value on creation for the context_truncation_strategy field.
+	group.DefaultContextTruncationStrategy = groupDescContextTruncationStrategy.Default.(string)
+	// group.ContextTruncationStrategyValidator is a validator for the "context_truncation_strategy" field. It is called by the builders before save.
+	group.ContextTruncationStrategyValidator = groupDescContextTruncationStrategy.Validators[0].(func(string) error)
+	// groupDescAccountSelectionStrategy is the schema descriptor for account_selection_strategy field.
+	groupDescAccountSelectionStrategy := groupFields[50].Descriptor()
+	// group.DefaultAccountSelectionStrategy holds the default value on creation for the account_selection_strategy field.
+	group.DefaultAccountSelectionStrategy = groupDescAccountSelectionStrategy.Default.(string)
+	// group.AccountSelectionStrategyValidator is a validator for the "account_selection_strategy" field. It is called by the builders before save.
+	group.AccountSelectionStrategyValidator = groupDescAccountSelectionStrategy.Validators[0].(func(string) error)
+	// groupDescImageMaxEdge is the schema descriptor for image_max_edge field.
+	groupDescImageMaxEdge := groupFields[51].Descriptor()
+	// group.DefaultImageMaxEdge holds the default value on creation for the image_max_edge field.
+	group.DefaultImageMaxEdge = groupDescImageMaxEdge.Default.(int)
+	// groupDescImageMaxBytes is the schema descriptor for image_max_bytes field.
+	groupDescImageMaxBytes := groupFields[52].Descriptor()
+	// group.DefaultImageMaxBytes holds the default value on creation for the image_max_bytes field.
+	group.DefaultImageMaxBytes = groupDescImageMaxBytes.Default.(int)
+	// groupDescOutputPostprocess is the schema descriptor for output_postprocess field.
+	groupDescOutputPostprocess := groupFields[53].Descriptor()
+	// group.DefaultOutputPostprocess holds the default value on creation for the output_postprocess field.
+	group.DefaultOutputPostprocess = groupDescOutputPostprocess.Default.(domain.GroupOutputPostprocessConfig)
+	// groupDescLanguageRouting is the schema descriptor for language_routing field.
+	groupDescLanguageRouting := groupFields[54].Descriptor()
+	// group.DefaultLanguageRouting holds the default value on creation for the language_routing field.
+	group.DefaultLanguageRouting = groupDescLanguageRouting.Default.(domain.GroupLanguageRoutingConfig)
+	// groupDescStreamReplay is the schema descriptor for stream_replay field.
+	groupDescStreamReplay := groupFields[55].Descriptor()
+	// group.DefaultStreamReplay holds the default value on creation for the stream_replay field.
+	group.DefaultStreamReplay = groupDescStreamReplay.Default.(domain.GroupStreamReplayConfig)
+	// groupDescCapabilityCheck is the schema descriptor for capability_check field.
+	groupDescCapabilityCheck := groupFields[56].Descriptor()
+	// group.DefaultCapabilityCheck holds the default value on creation for the capability_check field.
+	group.DefaultCapabilityCheck = groupDescCapabilityCheck.Default.(domain.GroupCapabilityCheckConfig)
+	// groupDescAutoContinuation is the schema descriptor for auto_continuation field.
+	groupDescAutoContinuation := groupFields[57].Descriptor()
+	// group.DefaultAutoContinuation holds the default value on creation for the auto_continuation field.
+	group.DefaultAutoContinuation = groupDescAutoContinuation.Default.(domain.GroupAutoContinuationConfig)
 	idempotencyrecordMixin := schema.IdempotencyRecord{}.Mixin()
 	idempotencyrecordMixinFields0 := idempotencyrecordMixin[0].Fields()
 	_ = idempotencyrecordMixinFields0
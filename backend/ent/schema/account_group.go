@@ -13,7 +13,7 @@ import (
 )
 
 // AccountGroup holds the edge schema definition for the account_groups relationship.
-// It stores extra fields (priority, created_at) and uses a composite primary key.
+// It stores extra fields (priority, tier, concurrency_share, created_at) and uses a composite primary key.
 type AccountGroup struct {
 	ent.Schema
 }
@@ -32,6 +32,14 @@ func (AccountGroup) Fields() []ent.Field {
 		field.Int64("group_id"),
 		field.Int("priority").
 			Default(50),
+		// tier 分组内故障转移层级：0=primary，1=secondary，2=emergency。
+		field.Int("tier").
+			Default(0).
+			SchemaType(map[string]string{dialect.Postgres: "smallint"}),
+		// concurrency_share 分组可占用的账号并发百分比（1-100），0 表示不限制。
+		field.Int("concurrency_share").
+			Default(0).
+			SchemaType(map[string]string{dialect.Postgres: "smallint"}),
 		field.Time("created_at").
 			Immutable().
 			Default(time.Now).
@@ -115,6 +115,38 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+		// Throughput limits enforced by per-key token buckets (0 = unlimited)
+		field.Int("rpm_limit").
+			Default(0).
+			Comment("Requests per minute for this API key (0 = unlimited)"),
+		field.Int64("tpm_limit").
+			Default(0).
+			Comment("Tokens per minute for this API key, input + output + cache (0 = unlimited)"),
+		// Reserved capacity: critical keys may use the group's reserved account slots
+		field.Bool("critical").
+			Default(false).
+			Comment("Critical keys may consume the group's reserved account concurrency"),
+
+		// ========== Guest key fields ==========
+		// Guest keys are short-lived and removed by the cleanup job after expiry
+		field.Bool("guest").
+			Default(false).
+			Comment("Temporary guest key, deleted by the cleanup job after expires_at"),
+		field.JSON("allowed_models", []string{}).
+			Optional().
+			Comment("Allowed request models, supports trailing * wildcard (empty = unrestricted)"),
+		field.Int64("token_limit").
+			Default(0).
+			Comment("Total token cap for this API key (0 = unlimited)"),
+		field.Int64("tokens_used").
+			Default(0).
+			Comment("Total tokens consumed (input + output + cache)"),
+
+		// Project grouping: keys in the same project share the project's quota
+		field.Int64("project_id").
+			Optional().
+			Nillable().
+			Comment("API key project ID (api_key_projects), nil = not in a project"),
 	}
 }
 
@@ -144,5 +176,8 @@ func (APIKey) Indexes() []ent.Index {
 		// Index for quota queries
 		index.Fields("quota", "quota_used"),
 		index.Fields("expires_at"),
+		// Index for guest key cleanup
+		index.Fields("guest", "expires_at"),
+		index.Fields("project_id"),
 	}
 }
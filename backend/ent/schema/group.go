@@ -61,6 +61,11 @@ func (Group) Fields() []ent.Field {
 			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
 			Default(1.0).
 			Comment("高峰时段叠加倍率，仅在 peak_rate_enabled 且处于 [peak_start, peak_end) 时乘入文本倍率"),
+		// 按次附加费（added by migration 205）
+		field.Float("request_surcharge").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0).
+			Comment("按次固定附加费（USD），在倍率计算后的实际费用上叠加，0 表示不收取"),
 		field.Bool("is_exclusive").
 			Default(false),
 		field.String("status").
@@ -210,6 +215,73 @@ func (Group) Fields() []ent.Field {
 		field.Int("rpm_limit").
 			Default(0).
 			Comment("分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流"),
+
+		// 为关键 API Key 预留的账号并发比例（0 = 不预留）。
+		field.Float("reserved_concurrency_ratio").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(5,4)"}).
+			Default(0).
+			Comment("账号并发预留比例 [0,1)，非关键 Key 无法占用预留槽位"),
+
+		// 过载降级：首选账号全部饱和/冷却时改用的备用模型（请求模型模式 -> 备用模型）。
+		field.JSON("overload_fallback_models", map[string]string{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("过载降级模型映射：模型模式 -> 备用模型，为空表示不降级"),
+
+		// 长上下文路由：估算输入超出模型上下文窗口时改用的大上下文模型（请求模型模式 -> 大上下文模型）。
+		field.JSON("context_overflow_models", map[string]string{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("长上下文路由映射：模型模式 -> 大上下文模型，为空表示不改路由"),
+		field.Bool("context_overflow_reject").
+			Default(false).
+			Comment("估算输入超出上下文窗口且无可用大上下文模型时直接拒绝（400）"),
+		field.String("context_truncation_strategy").
+			MaxLen(20).
+			Default("").
+			Comment("估算输入超出上下文窗口时的截断策略：drop_oldest/summarize/middle_out，空表示不截断"),
+		field.String("account_selection_strategy").
+			MaxLen(32).
+			Default("").
+			Comment("账号选择策略：weighted_round_robin/least_recently_used/lowest_latency，空表示按优先级逐级溢出"),
+
+		// 图片预处理：转发前缩放/重新压缩超出目标的 base64 图片。
+		field.Int("image_max_edge").
+			Default(0).
+			Comment("图片最长边上限（像素），超出时等比缩放，0 表示不限制"),
+		field.Int("image_max_bytes").
+			Default(0).
+			Comment("单张图片字节数目标，超出时重新压缩，0 表示不限制"),
+
+		// 输出后处理：对最终输出文本（JSON 与流式增量）做正则替换、违禁词过滤与追加署名。
+		field.JSON("output_postprocess", domain.GroupOutputPostprocessConfig{}).
+			Default(domain.GroupOutputPostprocessConfig{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("输出后处理配置：替换规则与追加署名，为空表示不处理"),
+
+		// 语言路由：按提示词主要语言改用其他模型或优先调度指定账号/代理下的账号。
+		field.JSON("language_routing", domain.GroupLanguageRoutingConfig{}).
+			Default(domain.GroupLanguageRoutingConfig{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("语言路由规则：languages 命中时改写 model 或优先 account_ids/proxy_ids，为空表示不启用"),
+
+		// 流式断线续传：缓存进行中响应的最近 SSE 事件，客户端可携带 Last-Event-ID 重连续读。
+		field.JSON("stream_replay", domain.GroupStreamReplayConfig{}).
+			Default(domain.GroupStreamReplayConfig{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("流式断线续传配置：enabled 开启后按 max_events/max_bytes 缓存 SSE 事件，为空表示不启用"),
+
+		// capability_check: 模型能力校验配置
+		field.JSON("capability_check", domain.GroupCapabilityCheckConfig{}).
+			Default(domain.GroupCapabilityCheckConfig{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("模型能力校验：请求包含映射后模型不支持的图片/音频/工具/推理时改用备用模型或拒绝"),
+
+		// 截断自动续写：非流式响应因输出 token 上限截断时自动发起续写请求并拼接输出。
+		field.JSON("auto_continuation", domain.GroupAutoContinuationConfig{}).
+			Default(domain.GroupAutoContinuationConfig{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("截断自动续写配置：enabled 开启后按 max_continuations 上限续写被截断的非流式响应，为空表示不启用"),
 	}
 }
 
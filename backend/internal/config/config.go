@@ -2,12 +2,16 @@
 package config
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -86,6 +90,7 @@ type Config struct {
 	Dashboard               DashboardCacheConfig          `mapstructure:"dashboard_cache"`
 	DashboardAgg            DashboardAggregationConfig    `mapstructure:"dashboard_aggregation"`
 	UsageCleanup            UsageCleanupConfig            `mapstructure:"usage_cleanup"`
+	AccountStale            AccountStaleConfig            `mapstructure:"account_stale"`
 	Concurrency             ConcurrencyConfig             `mapstructure:"concurrency"`
 	TokenRefresh            TokenRefreshConfig            `mapstructure:"token_refresh"`
 	RunMode                 string                        `mapstructure:"run_mode" yaml:"run_mode"`
@@ -94,6 +99,9 @@ type Config struct {
 	Update                  UpdateConfig                  `mapstructure:"update"`
 	Idempotency             IdempotencyConfig             `mapstructure:"idempotency"`
 	BatchImage              BatchImageConfig              `mapstructure:"batch_image"`
+	ConfigSync              ConfigSyncConfig              `mapstructure:"config_sync"`
+	UpstreamCassette        UpstreamCassetteConfig        `mapstructure:"upstream_cassette"`
+	Tracing                 TracingConfig                 `mapstructure:"tracing"`
 }
 
 type LogConfig struct {
@@ -106,6 +114,7 @@ type LogConfig struct {
 	Output          LogOutputConfig   `mapstructure:"output"`
 	Rotation        LogRotationConfig `mapstructure:"rotation"`
 	Sampling        LogSamplingConfig `mapstructure:"sampling"`
+	Access          LogAccessConfig   `mapstructure:"access"`
 }
 
 type LogOutputConfig struct {
@@ -128,6 +137,31 @@ type LogSamplingConfig struct {
 	Thereafter int  `mapstructure:"thereafter"`
 }
 
+// 访问日志格式
+const (
+	AccessLogFormatCommon   = "common"
+	AccessLogFormatCombined = "combined"
+	AccessLogFormatJSON     = "json"
+)
+
+// AccessLogFields 访问日志可选附加字段（*_hash 为 HMAC-SHA256 截断值，需配置 hash_salt）
+var AccessLogFields = []string{"request_id", "latency_ms", "user_hash", "api_key_hash", "account_hash", "platform", "model"}
+
+// LogAccessConfig 独立于应用日志的 HTTP 访问日志（Common/Combined Log Format 或 JSON）
+type LogAccessConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Format: common / combined / json
+	Format string `mapstructure:"format"`
+	// FilePath 为空时写入 stdout；文件输出沿用 log.rotation 的滚动策略
+	FilePath string `mapstructure:"file_path"`
+	// Fields 追加在标准字段之后的附加字段，取值见 AccessLogFields
+	Fields []string `mapstructure:"fields"`
+	// HashSalt 计算 *_hash 字段的 HMAC 密钥，避免在访问日志中暴露可枚举的 ID
+	HashSalt string `mapstructure:"hash_salt"`
+	// SkipPaths 不记录的路径（精确匹配）
+	SkipPaths []string `mapstructure:"skip_paths"`
+}
+
 type GeminiConfig struct {
 	OAuth GeminiOAuthConfig `mapstructure:"oauth"`
 	Quota GeminiQuotaConfig `mapstructure:"quota"`
@@ -176,6 +210,94 @@ type IdempotencyConfig struct {
 	CleanupBatchSize int `mapstructure:"cleanup_batch_size"`
 }
 
+const (
+	ConfigSyncRolePrimary = "primary"
+	ConfigSyncRoleReplica = "replica"
+	// ConfigSyncRoleMirror 预发镜像：同 replica 拉取只读配置，额外允许将上游流量指向 mock 上游
+	ConfigSyncRoleMirror = "mirror"
+)
+
+// ConfigSyncConfig 跨地域部署的配置同步（主从）配置。
+// primary 通过鉴权接口导出账号/分组/Key 等配置；replica 定期从 primary 拉取并覆盖本地配置，
+// 使用日志、运维数据等仍写入各自数据库，不参与同步。
+type ConfigSyncConfig struct {
+	// Role 为空表示不启用；primary 提供快照接口；replica/mirror 从 PrimaryURL 拉取
+	Role string `mapstructure:"role"`
+	// Token 主从之间的共享令牌（Authorization: Bearer <token>）
+	Token string `mapstructure:"token"`
+	// PrimaryURL replica 拉取的 primary 根地址，例如 https://primary.example.com
+	PrimaryURL string `mapstructure:"primary_url"`
+	// IntervalSeconds replica 拉取周期（秒）
+	IntervalSeconds int `mapstructure:"interval_seconds"`
+	// RequestTimeoutSeconds 单次拉取超时（秒）
+	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	// UpstreamOverrideURL 仅 mirror 可用：所有网关上游请求改发到该地址（保留原路径与查询参数），
+	// 用于预发环境以生产配置对接 mock 上游，避免真实账号产生流量
+	UpstreamOverrideURL string `mapstructure:"upstream_override_url"`
+}
+
+const (
+	UpstreamCassetteModeRecord = "record"
+	UpstreamCassetteModeReplay = "replay"
+)
+
+// UpstreamCassetteConfig 上游交互录制/回放（VCR）：record 把脱敏后的上游请求与响应写入 Dir，
+// replay 以 Dir 中的 cassette 作为假上游回放、不发出任何上游请求，仅用于预发录制与本地/CI 回归。
+type UpstreamCassetteConfig struct {
+	// Mode "" 不启用 / "record" / "replay"
+	Mode string `mapstructure:"mode"`
+	Dir  string `mapstructure:"dir"`
+	// MaxBodyBytes 请求体或响应体超过该大小的交互不录制
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+}
+
+func (c UpstreamCassetteConfig) validate() error {
+	switch strings.ToLower(strings.TrimSpace(c.Mode)) {
+	case "":
+		return nil
+	case UpstreamCassetteModeRecord, UpstreamCassetteModeReplay:
+	default:
+		return fmt.Errorf("upstream_cassette.mode must be one of: record/replay")
+	}
+	if strings.TrimSpace(c.Dir) == "" {
+		return fmt.Errorf("upstream_cassette.dir is required when upstream_cassette.mode is set")
+	}
+	if c.MaxBodyBytes < 0 {
+		return fmt.Errorf("upstream_cassette.max_body_bytes must be non-negative")
+	}
+	return nil
+}
+
+// TracingConfig OpenTelemetry 链路追踪：为网关入口、转发与上游请求生成 span，经 OTLP/HTTP 导出到 collector。
+type TracingConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	ServiceName string `mapstructure:"service_name"`
+	// OTLPEndpoint collector 地址（host:port，如 otel-collector:4318），不含路径
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"`
+	// OTLPURLPath 为空时使用 /v1/traces
+	OTLPURLPath  string            `mapstructure:"otlp_url_path"`
+	OTLPInsecure bool              `mapstructure:"otlp_insecure"` // 使用 HTTP 而非 HTTPS
+	OTLPHeaders  map[string]string `mapstructure:"otlp_headers"`  // 导出请求附加的头（如 collector 鉴权）
+	// SampleRatio 根 span 采样比例 (0,1]；上游已带 traceparent 时沿用其采样决定
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+func (c TracingConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(c.ServiceName) == "" {
+		return fmt.Errorf("tracing.service_name is required when tracing.enabled=true")
+	}
+	if strings.TrimSpace(c.OTLPEndpoint) == "" {
+		return fmt.Errorf("tracing.otlp_endpoint is required when tracing.enabled=true")
+	}
+	if c.SampleRatio <= 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio must be within (0,1]")
+	}
+	return nil
+}
+
 type BatchImageConfig struct {
 	Enabled                           bool   `mapstructure:"enabled"`
 	MaxItemsPerJobDefault             int    `mapstructure:"max_items_per_job_default"`
@@ -595,8 +717,19 @@ type PricingConfig struct {
 	UpdateIntervalHours int `mapstructure:"update_interval_hours"`
 	// 哈希校验间隔（分钟）
 	HashCheckIntervalMinutes int `mapstructure:"hash_check_interval_minutes"`
+	// 签名文件URL（Ed25519 分离签名，base64 编码，对价格数据原文签名）
+	SignatureURL string `mapstructure:"signature_url"`
+	// 签名公钥（base64 编码的 Ed25519 公钥）；配置后远程数据必须通过签名校验才会被采用
+	PublicKey string `mapstructure:"public_key"`
+	// 更新审批模式：auto 下载后立即生效；manual 暂存为待审批更新，由管理员批准后生效
+	ApprovalMode string `mapstructure:"approval_mode"`
 }
 
+const (
+	PricingApprovalModeAuto   = "auto"
+	PricingApprovalModeManual = "manual"
+)
+
 type ServerConfig struct {
 	Host               string    `mapstructure:"host"`
 	Port               int       `mapstructure:"port"`
@@ -607,6 +740,25 @@ type ServerConfig struct {
 	TrustedProxies     []string  `mapstructure:"trusted_proxies"`       // 可信代理列表（CIDR/IP）
 	MaxRequestBodySize int64     `mapstructure:"max_request_body_size"` // 全局最大请求体限制
 	H2C                H2CConfig `mapstructure:"h2c"`                   // HTTP/2 Cleartext 配置
+
+	DebugEndpoints DebugEndpointsConfig `mapstructure:"debug_endpoints"` // 管理端 pprof 与运行时调优接口
+	Metrics        MetricsConfig        `mapstructure:"metrics"`         // Prometheus /metrics 抓取接口
+}
+
+// MetricsConfig Prometheus 指标配置（GET /metrics，不经过用户/管理员认证）
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"` // 是否启用指标记录与 /metrics 接口（默认关闭）
+	// BearerToken 非空时抓取请求需携带 Authorization: Bearer <token>；
+	// 为空时接口对所有能访问服务端口的客户端开放，应由网络层（反向代理/防火墙）限制访问。
+	BearerToken string `mapstructure:"bearer_token"`
+}
+
+// DebugEndpointsConfig 管理端调试接口配置（/api/v1/admin/debug/*，仍需管理员认证）
+type DebugEndpointsConfig struct {
+	Enabled bool `mapstructure:"enabled"` // 是否启用 pprof 与运行时调优接口（默认关闭）
+	// AllowAdminAPIKey 是否允许 Admin API Key 调用；默认只允许管理员登录会话（JWT），
+	// 避免泄漏的自动化密钥被用来抓取 profile 或修改 GC 参数。
+	AllowAdminAPIKey bool `mapstructure:"allow_admin_api_key"`
 }
 
 // H2CConfig HTTP/2 Cleartext 配置
@@ -657,10 +809,12 @@ func (c *Config) SetTrustForwardedIPForAPIKeyACL(enabled bool) {
 }
 
 type URLAllowlistConfig struct {
-	Enabled           bool     `mapstructure:"enabled"`
-	UpstreamHosts     []string `mapstructure:"upstream_hosts"`
-	PricingHosts      []string `mapstructure:"pricing_hosts"`
-	CRSHosts          []string `mapstructure:"crs_hosts"`
+	Enabled       bool     `mapstructure:"enabled"`
+	UpstreamHosts []string `mapstructure:"upstream_hosts"`
+	PricingHosts  []string `mapstructure:"pricing_hosts"`
+	CRSHosts      []string `mapstructure:"crs_hosts"`
+	// FileFetchHosts: 网关代取 input_image/input_file URL 时允许的主机（白名单启用时必须命中）
+	FileFetchHosts    []string `mapstructure:"file_fetch_hosts"`
 	AllowPrivateHosts bool     `mapstructure:"allow_private_hosts"`
 	// 关闭 URL 白名单校验时，是否允许 http URL（默认只允许 https）
 	AllowInsecureHTTP bool `mapstructure:"allow_insecure_http"`
@@ -707,6 +861,56 @@ type BillingConfig struct {
 	// UserPlatformQuotaSentinelTTLSeconds sentinel(无 limit 占位)entry 的 TTL,
 	// 显著短于 quota cache 默认 86400s 以控 Redis 内存;默认 3600=1h。
 	UserPlatformQuotaSentinelTTLSeconds int `mapstructure:"user_platform_quota_sentinel_ttl_seconds"`
+	// Statements 月度账单生成与可选的 Stripe 自动收费。
+	Statements BillingStatementConfig `mapstructure:"statements"`
+	// Prepaid 余额计费的请求级预扣（按预估费用冻结余额，请求结束后按实际用量结算）。
+	Prepaid BillingPrepaidConfig `mapstructure:"prepaid"`
+}
+
+// BillingPrepaidConfig 余额预扣配置。
+// 开启后余额模式的请求在转发前原子地冻结预估费用，可用余额 = 余额 - 在途冻结；
+// 可用余额不足以覆盖预估费用时直接拒绝，避免并发请求把余额透支成负数。
+type BillingPrepaidConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DefaultOutputTokens 请求未指定 max_tokens 时按该输出 token 数估算。
+	DefaultOutputTokens int `mapstructure:"default_output_tokens"`
+	// MinReservation / MaxReservation 单请求冻结金额的下限与上限（USD），MaxReservation=0 表示不设上限。
+	MinReservation float64 `mapstructure:"min_reservation"`
+	MaxReservation float64 `mapstructure:"max_reservation"`
+	// ReservationTTLSeconds 冻结记录的最长存活时间，进程异常退出未释放的冻结会在到期后自动失效。
+	ReservationTTLSeconds int `mapstructure:"reservation_ttl_seconds"`
+}
+
+// BillingStatementConfig 月度账单配置。
+// 账单按自然月（使用全局 timezone）汇总 usage_logs 中计价引擎落账的 actual_cost，每个有用量的用户一张。
+type BillingStatementConfig struct {
+	// Enabled 是否在每月 GenerateDay 自动生成上月账单；关闭时仍可由管理员手动生成。
+	Enabled bool `mapstructure:"enabled"`
+	// GenerateDay 每月第几天生成上月账单（1-28），给迟到的用量日志留出落库时间。
+	GenerateDay int `mapstructure:"generate_day"`
+	// MinAmount 推送到 Stripe 的最低账单金额，低于该值的账单只生成不收费。
+	MinAmount float64 `mapstructure:"min_amount"`
+	// Stripe 账单自动收费（可选）。
+	Stripe BillingStripeConfig `mapstructure:"stripe"`
+}
+
+const (
+	BillingStripeModeInvoice = "invoice"
+	BillingStripeModeMetered = "metered"
+)
+
+// BillingStripeConfig 账单推送到 Stripe 的配置（与充值用的 Stripe 支付实例相互独立）。
+type BillingStripeConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	SecretKey     string `mapstructure:"secret_key"`
+	WebhookSecret string `mapstructure:"webhook_secret"`
+	// Mode: invoice = 每张账单生成并发送一张 Stripe Invoice；metered = 向 Billing Meter 上报金额（最小货币单位），由 Stripe 订阅计费。
+	Mode     string `mapstructure:"mode"`
+	Currency string `mapstructure:"currency"`
+	// DaysUntilDue invoice 模式下 >0 时发送账单由用户自行支付；0 表示对默认支付方式自动扣款。
+	DaysUntilDue int `mapstructure:"days_until_due"`
+	// MeterEventName metered 模式下 Billing Meter 的 event_name。
+	MeterEventName string `mapstructure:"meter_event_name"`
 }
 
 type CircuitBreakerConfig struct {
@@ -721,6 +925,202 @@ type ConcurrencyConfig struct {
 	PingInterval int `mapstructure:"ping_interval"`
 }
 
+// GatewayFileFetchConfig 网关代取请求中的文件/图片 URL（转为 base64 内联）配置。
+type GatewayFileFetchConfig struct {
+	// Enabled: 是否代取 URL；关闭时保持原样转发（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxBytes: 单个文件大小上限（字节）
+	MaxBytes int64 `mapstructure:"max_bytes"`
+	// MaxFilesPerRequest: 单个请求最多代取的 URL 数
+	MaxFilesPerRequest int `mapstructure:"max_files_per_request"`
+	// TimeoutSeconds: 单次下载超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// CacheTTLSeconds: 下载结果缓存时间（秒），0 表示不缓存
+	CacheTTLSeconds int `mapstructure:"cache_ttl_seconds"`
+	// CacheMaxBytes: 进程内缓存总字节上限
+	CacheMaxBytes int64 `mapstructure:"cache_max_bytes"`
+	// AllowPrivateHosts: 是否允许代取内网/本地地址（URL 来自终端用户，默认禁止以防 SSRF）
+	AllowPrivateHosts bool `mapstructure:"allow_private_hosts"`
+}
+
+type GatewayRequestCoalescingConfig struct {
+	// Enabled: 是否合并并发中的相同请求；跟随者复用领头请求的响应，记录零费用用量（request_type=reused）（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// WaitTimeoutSeconds: 跟随者等待领头请求完成的最长时间（秒），超时后独立请求上游
+	WaitTimeoutSeconds int `mapstructure:"wait_timeout_seconds"`
+	// MaxResponseBytes: 可复用响应体的字节上限，超过后跟随者独立请求上游
+	MaxResponseBytes int64 `mapstructure:"max_response_bytes"`
+	// Streaming: 是否同时合并流式请求；领头请求的事件流实时分发给跟随者（默认关闭）
+	Streaming bool `mapstructure:"streaming"`
+}
+
+// GatewayResponseCacheConfig 确定性请求（temperature=0）的非流式响应缓存配置。
+// 仅对携带 X-Sub2API-Response-Cache 请求头的请求生效，缓存按 API Key 隔离。
+type GatewayResponseCacheConfig struct {
+	// Enabled: 是否启用响应缓存；命中的请求不调用上游，记录零费用用量（request_type=reused）（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// Backend: 缓存存储，redis 或 disk
+	Backend string `mapstructure:"backend"`
+	// EncryptionKey: 加密缓存条目的 AES-256 密钥（32 字节 hex 编码），redis 与 disk 后端都只存密文；
+	// 留空时每次启动随机生成，重启前写入的条目将无法解密并被丢弃，多实例共享 redis 时各实例也无法互相命中
+	EncryptionKey string `mapstructure:"encryption_key"`
+	// DiskDir: backend=disk 时的缓存目录
+	DiskDir string `mapstructure:"disk_dir"`
+	// DiskMaxBytes: backend=disk 时缓存目录的总字节上限，超过后按过期时间从早到晚淘汰
+	DiskMaxBytes int64 `mapstructure:"disk_max_bytes"`
+	// DiskMaxEntries: backend=disk 时缓存条目数上限，超过后按过期时间从早到晚淘汰
+	DiskMaxEntries int `mapstructure:"disk_max_entries"`
+	// TTLSeconds: 缓存有效期（秒）；请求头可指定更短的有效期
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// MaxResponseBytes: 可缓存响应体的字节上限
+	MaxResponseBytes int64 `mapstructure:"max_response_bytes"`
+}
+
+// GatewayCompactCoalescingConfig 会话压缩（/responses/compact）请求合并配置。
+type GatewayCompactCoalescingConfig struct {
+	// Enabled: 是否合并同一 API Key 对相同输入的压缩请求；跟随者复用领头请求的摘要，记录零费用用量（request_type=reused）（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSeconds: 领头请求成功后摘要的复用窗口（秒），0 表示只合并进行中的请求
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// WaitTimeoutSeconds: 跟随者等待领头请求完成的最长时间（秒），超时后独立请求上游
+	WaitTimeoutSeconds int `mapstructure:"wait_timeout_seconds"`
+	// MaxResponseBytes: 可复用摘要响应的字节上限，超过后跟随者独立请求上游
+	MaxResponseBytes int64 `mapstructure:"max_response_bytes"`
+}
+
+// GatewayCompactGuardrailsConfig 会话压缩（/responses/compact）摘要质量兜底配置。
+type GatewayCompactGuardrailsConfig struct {
+	// Enabled: 是否校验压缩摘要质量，空摘要或退化摘要时调整指令后重试（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// MinSummaryChars: 明文摘要的最少字符数，低于该值视为退化摘要；0 表示只拦截空摘要
+	MinSummaryChars int `mapstructure:"min_summary_chars"`
+	// MaxSummaryChars: 明文摘要的最多字符数，超过后要求上游精简重写；0 表示不限制
+	MaxSummaryChars int `mapstructure:"max_summary_chars"`
+	// MaxRetries: 摘要不合格时的最大重试次数；重试耗尽仍无摘要内容时返回 502，有内容则原样返回
+	MaxRetries int `mapstructure:"max_retries"`
+	// LanguageMatching: 是否要求上游使用会话所用语言撰写摘要
+	LanguageMatching bool `mapstructure:"language_matching"`
+}
+
+// GatewayCompactionTokenBindingConfig 会话压缩 encrypted_content 的租户绑定配置。
+// 开启后网关以 AES-256-GCM 包装 compact 响应中的 encrypted_content，并以分组或 API Key 作为附加认证数据（AAD），
+// 其他租户重放该 token 时解密失败并被拒绝。
+type GatewayCompactionTokenBindingConfig struct {
+	// Enabled: 是否绑定压缩 token（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// Scope: 绑定范围，group（同分组内可复用，无分组时退化为 API Key）或 api_key
+	Scope string `mapstructure:"scope"`
+	// Key: 包装用的 AES-256 密钥（32 字节 hex 编码）
+	Key string `mapstructure:"key"`
+	// AcceptLegacyTokens: 是否继续接受开启绑定前签发的未包装 token，便于平滑迁移
+	AcceptLegacyTokens bool `mapstructure:"accept_legacy_tokens"`
+}
+
+// GatewayRequestClassesConfig 请求分类（交互 / 批量）及各分类的超时、重试与延迟 SLO 配置。
+// 分类优先取客户端 X-Sub2API-Request-Class 请求头，未提供时流式请求视为交互、非流式视为批量。
+type GatewayRequestClassesConfig struct {
+	Interactive GatewayRequestClassPolicy `mapstructure:"interactive"`
+	Batch       GatewayRequestClassPolicy `mapstructure:"batch"`
+}
+
+// GatewayRequestClassPolicy 单个请求分类的策略。
+type GatewayRequestClassPolicy struct {
+	// TimeoutSeconds: 请求整体超时（秒，从开始调度上游账号起计时，含全部重试），0 表示不限制；WebSocket 会话不受限制
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// MaxAccountSwitches: 账号切换次数上限，0 表示沿用 gateway.max_account_switches(_gemini)
+	MaxAccountSwitches int `mapstructure:"max_account_switches"`
+	// SLOFirstTokenMs: 首 token 延迟 SLO 目标（毫秒），0 表示不评估
+	SLOFirstTokenMs int `mapstructure:"slo_first_token_ms"`
+	// SLODurationMs: 请求总耗时 SLO 目标（毫秒），0 表示不评估
+	SLODurationMs int `mapstructure:"slo_duration_ms"`
+}
+
+// GatewayHedgingConfig 非流式请求对冲：主请求在延迟阈值内未收到上游响应头时，
+// 向另一个账号发送相同请求，采用先返回响应头的一方并取消另一方。
+// 仅作用于 Anthropic 网关路径上的非流式 /v1/messages 请求；流式请求、OpenAI / Gemini 分组（含 OpenAI 的 /v1/messages 兼容入口）
+// 以及 Antigravity OAuth 账号不会对冲。
+type GatewayHedgingConfig struct {
+	// Enabled: 是否启用对冲（默认关闭）；对冲请求会额外消耗上游配额
+	Enabled bool `mapstructure:"enabled"`
+	// DelayPercentile: 对冲延迟取该模型近期响应头耗时的分位数（0-1，默认 0.95）
+	DelayPercentile float64 `mapstructure:"delay_percentile"`
+	// MinSamples: 样本数不足时不对冲
+	MinSamples int `mapstructure:"min_samples"`
+	// MinDelayMs / MaxDelayMs: 对冲延迟的上下限（毫秒）
+	MinDelayMs int `mapstructure:"min_delay_ms"`
+	MaxDelayMs int `mapstructure:"max_delay_ms"`
+	// BudgetPerMinute: 每个 API Key 每分钟最多发起的对冲请求数
+	BudgetPerMinute int `mapstructure:"budget_per_minute"`
+}
+
+// GatewayWarmConnectionsConfig 上游热备连接：定期向近期活跃客户端访问过的上游主机发送轻量 HEAD 请求，
+// 保持少量已完成 TLS 握手的空闲连接，HTTP/2 连接同时启用 keepalive ping。
+type GatewayWarmConnectionsConfig struct {
+	// Enabled: 是否启用热备连接（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalSeconds: 预热间隔（秒），应小于 idle_conn_timeout_seconds
+	IntervalSeconds int `mapstructure:"interval_seconds"`
+	// StandbyConnections: 每个客户端每个上游主机保持的热备连接数
+	StandbyConnections int `mapstructure:"standby_connections"`
+	// ActiveWithinSeconds: 仅预热该时间内有真实请求的客户端（预热本身不刷新活跃时间）
+	ActiveWithinSeconds int `mapstructure:"active_within_seconds"`
+}
+
+// 推理（thinking / reasoning summary）事件处理方式
+const (
+	ReasoningEventsModePassthrough = "passthrough"
+	ReasoningEventsModeFilter      = "filter"
+	ReasoningEventsModeConvert     = "convert"
+)
+
+// GatewayReasoningEventsConfig 推理事件处理：为无法识别 thinking 块或 reasoning 字段的旧版 SDK
+// 过滤推理内容，或将其转换为普通文本输出。
+type GatewayReasoningEventsConfig struct {
+	// DefaultMode: 未命中任何客户端画像时的处理方式 passthrough（默认）/filter/convert
+	DefaultMode string `mapstructure:"default_mode"`
+	// Profiles: 客户端画像，按顺序匹配 User-Agent，首个命中的画像生效
+	Profiles []GatewayReasoningEventsProfile `mapstructure:"profiles"`
+}
+
+type GatewayReasoningEventsProfile struct {
+	// Name: 画像名称，仅用于日志与排障
+	Name string `mapstructure:"name"`
+	// UserAgentPattern: 匹配客户端 User-Agent 的正则表达式
+	UserAgentPattern string `mapstructure:"user_agent_pattern"`
+	// Mode: 命中后的处理方式 passthrough/filter/convert
+	Mode string `mapstructure:"mode"`
+}
+
+// GatewayModelCapabilityOverride 模型能力矩阵覆盖项，未设置的能力沿用价格数据中的声明。
+type GatewayModelCapabilityOverride struct {
+	// Model: 模型名，支持末尾 * 通配（如 claude-3-*），精确匹配优先，其次最长通配
+	Model     string `mapstructure:"model"`
+	Vision    *bool  `mapstructure:"vision"`
+	Audio     *bool  `mapstructure:"audio"`
+	Tools     *bool  `mapstructure:"tools"`
+	Reasoning *bool  `mapstructure:"reasoning"`
+}
+
+// GatewayImageURLRewriteRule 图片结果 URL 前缀改写规则。
+type GatewayImageURLRewriteRule struct {
+	// From: 需要替换的 URL 前缀（如 https://files.upstream.example/）
+	From string `mapstructure:"from"`
+	// To: 替换后的前缀（如 https://cdn.example.com/images/）
+	To string `mapstructure:"to"`
+}
+
+// GatewayStreamFlushConfig SSE 转发的写出/flush 调优：在延迟与高并发下的 CPU/系统调用开销之间取舍。
+type GatewayStreamFlushConfig struct {
+	// Enabled: 是否启用调优（默认关闭，保持每次写出即 flush）
+	Enabled bool `mapstructure:"enabled"`
+	// FlushIntervalMs: 两次 flush 的最小间隔（毫秒），间隔内的 flush 合并，数据最多延迟该时长
+	FlushIntervalMs int `mapstructure:"flush_interval_ms"`
+	// CoalesceMinBytes: 待写出数据达到该字节数时不等间隔立即 flush；更小的增量合并到间隔结束，0 表示只按间隔合并
+	CoalesceMinBytes int `mapstructure:"coalesce_min_bytes"`
+	// WriteBufferBytes: 写缓冲上限（字节），超过后立即写出，避免大响应在内存中堆积
+	WriteBufferBytes int `mapstructure:"write_buffer_bytes"`
+}
+
 type ImageConcurrencyConfig struct {
 	// Enabled: 是否启用图片生成独立并发限制，默认关闭以保持现有行为
 	Enabled bool `mapstructure:"enabled"`
@@ -775,14 +1175,41 @@ type GatewayConfig struct {
 	// OpenAICompactModel: /responses/compact 上游使用的模型。
 	// compact 端点支持模型滞后于普通 /responses 时，可用该配置降级规避上游错误。
 	OpenAICompactModel string `mapstructure:"openai_compact_model"`
+	// OpenAISessionIDStrategy: OpenAI OAuth 上游 session_id/conversation_id 生成策略
+	// prompt_cache_key（默认，按 prompt_cache_key 派生）/ api_key（同一 API Key 共用稳定会话）/ request（每请求随机）。
+	// 可通过账号 extra.openai_session_id_strategy 单独覆盖。
+	OpenAISessionIDStrategy string `mapstructure:"openai_session_id_strategy"`
+	// StickySessionTTLSeconds: Claude 网关（/v1/messages、chat/completions、responses 兼容路径）会话 -> 账号粘连 TTL（秒）。
+	// 每次命中都会续期；过期后会话重新参与负载均衡。OpenAI 平台使用 openai_ws.sticky_session_ttl_seconds。
+	StickySessionTTLSeconds int `mapstructure:"sticky_session_ttl_seconds"`
 	// OpenAIWS: OpenAI Responses WebSocket 配置（默认开启，可按需回滚到 HTTP）
 	OpenAIWS GatewayOpenAIWSConfig `mapstructure:"openai_ws"`
 	// OpenAIScheduler: OpenAI 高级调度器粘性逃逸配置
 	OpenAIScheduler GatewayOpenAISchedulerConfig `mapstructure:"openai_scheduler"`
 	// OpenAIHTTP2: OpenAI HTTP 上游协议策略（默认启用 HTTP/2，可按代理能力回退 HTTP/1.1）
 	OpenAIHTTP2 GatewayOpenAIHTTP2Config `mapstructure:"openai_http2"`
+	// OpenAIUpstreamRetry: OpenAI HTTP 上游瞬时错误的原地重试（默认关闭；不作用于 WebSocket 入站）
+	OpenAIUpstreamRetry GatewayOpenAIUpstreamRetryConfig `mapstructure:"openai_upstream_retry"`
 	// ImageConcurrency: 图片生成独立并发限制配置（默认关闭）
 	ImageConcurrency ImageConcurrencyConfig `mapstructure:"image_concurrency"`
+	// FileFetch: 代取 input_image/input_file URL 并内联为 base64（供不接受 URL 的上游使用）
+	FileFetch GatewayFileFetchConfig `mapstructure:"file_fetch"`
+	// RequestCoalescing: 合并同一 API Key 并发中的相同非流式请求（默认关闭）
+	RequestCoalescing GatewayRequestCoalescingConfig `mapstructure:"request_coalescing"`
+	// ResponseCache: temperature=0 的非流式请求按请求哈希缓存响应（默认关闭，按请求头逐 Key 开启）
+	ResponseCache GatewayResponseCacheConfig `mapstructure:"response_cache"`
+	// CompactCoalescing: 多 agent 并发压缩同一会话时按输入合并 /responses/compact 请求（默认关闭）
+	CompactCoalescing GatewayCompactCoalescingConfig `mapstructure:"compact_coalescing"`
+	// CompactGuardrails: 会话压缩摘要的长度、语言校验与退化摘要重试（默认关闭）
+	CompactGuardrails GatewayCompactGuardrailsConfig `mapstructure:"compact_guardrails"`
+	// CompactionTokenBinding: 将压缩 encrypted_content 绑定到签发的分组或 API Key，防止跨租户重放（默认关闭）
+	CompactionTokenBinding GatewayCompactionTokenBindingConfig `mapstructure:"compaction_token_binding"`
+	// CompactResponseMetadata: 在会话压缩响应中附带 compaction_metadata（原始 token、摘要 token、压缩比、模型），不含摘要内容
+	CompactResponseMetadata bool `mapstructure:"compact_response_metadata"`
+	// Hedging: Anthropic 非流式 /v1/messages 请求长尾延迟对冲（默认关闭）
+	Hedging GatewayHedgingConfig `mapstructure:"hedging"`
+	// RequestClasses: 交互 / 批量请求分类策略
+	RequestClasses GatewayRequestClassesConfig `mapstructure:"request_classes"`
 
 	// HTTP 上游连接池配置（性能优化：支持高并发场景调优）
 	// MaxIdleConns: 所有主机的最大空闲连接总数
@@ -802,6 +1229,8 @@ type GatewayConfig struct {
 	// 超过此时间未使用的客户端会被标记为可回收
 	// 建议值：根据用户访问频率设置，一般 10-30 分钟
 	ClientIdleTTLSeconds int `mapstructure:"client_idle_ttl_seconds"`
+	// WarmConnections: 为近期活跃的上游客户端保持预建立的连接（默认关闭）
+	WarmConnections GatewayWarmConnectionsConfig `mapstructure:"warm_connections"`
 	// ConcurrencySlotTTLMinutes: 并发槽位过期时间（分钟）
 	// 应大于最长 LLM 请求时间，防止请求完成前槽位过期
 	ConcurrencySlotTTLMinutes int `mapstructure:"concurrency_slot_ttl_minutes"`
@@ -818,6 +1247,14 @@ type GatewayConfig struct {
 	ImageStreamDataIntervalTimeout int `mapstructure:"image_stream_data_interval_timeout"`
 	// ImageStreamKeepaliveInterval: 图片流式 keepalive 间隔（秒），0表示禁用
 	ImageStreamKeepaliveInterval int `mapstructure:"image_stream_keepalive_interval"`
+	// ImageResultURLRewrites: 图片结果 URL 前缀改写规则（如上游存储域名 → 自有 CDN），按顺序取第一条匹配，默认不改写
+	ImageResultURLRewrites []GatewayImageURLRewriteRule `mapstructure:"image_result_url_rewrites"`
+	// ModelCapabilities: 模型能力矩阵覆盖（图片/音频/工具/推理），供分组能力校验使用
+	ModelCapabilities []GatewayModelCapabilityOverride `mapstructure:"model_capabilities"`
+	// ReasoningEvents: 按客户端画像过滤或转换推理事件（默认透传）
+	ReasoningEvents GatewayReasoningEventsConfig `mapstructure:"reasoning_events"`
+	// StreamFlush: SSE 转发 flush 间隔与小增量合并调优（默认关闭）
+	StreamFlush GatewayStreamFlushConfig `mapstructure:"stream_flush"`
 	// MaxLineSize: 上游 SSE 单行最大字节数（0使用默认值）
 	MaxLineSize int `mapstructure:"max_line_size"`
 
@@ -826,6 +1263,14 @@ type GatewayConfig struct {
 	// 上游错误响应体记录最大字节数（超过会截断）
 	LogUpstreamErrorBodyMaxBytes int `mapstructure:"log_upstream_error_body_max_bytes"`
 
+	// 响应内容守卫：JSON 响应必须能解析、SSE 去除 BOM/垃圾前缀，违规时改写为结构化 502 并记入 ops（默认关闭）。
+	// 开启后每个 JSON 响应最多缓冲 2MB 用于校验，超过部分不校验直接透传
+	ResponseContentGuard bool `mapstructure:"response_content_guard"`
+	// OpenAI 兼容端点的严格错误体：所有网关错误（含流内错误事件）统一改写为 OpenAI error schema（默认关闭）
+	OpenAIStrictErrors bool `mapstructure:"openai_strict_errors"`
+	// Anthropic 兼容端点的严格错误体：/v1/messages 上的网关错误（含流内 error 事件）统一为 Anthropic 错误信封（默认关闭）
+	AnthropicStrictErrors bool `mapstructure:"anthropic_strict_errors"`
+
 	// API-key 账号在客户端未提供 anthropic-beta 时，是否按需自动补齐（默认关闭以保持兼容）
 	InjectBetaForAPIKey bool `mapstructure:"inject_beta_for_apikey"`
 
@@ -849,10 +1294,15 @@ type GatewayConfig struct {
 	// UsageRecord: 使用量记录异步队列配置（有界队列 + 固定 worker）
 	UsageRecord GatewayUsageRecordConfig `mapstructure:"usage_record"`
 
+	// APIKeyTrace: 按 API Key 临时开启的请求/响应全量抓取（管理员排障用）
+	APIKeyTrace GatewayAPIKeyTraceConfig `mapstructure:"api_key_trace"`
+
 	// UserGroupRateCacheTTLSeconds: 用户分组倍率热路径缓存 TTL（秒）
 	UserGroupRateCacheTTLSeconds int `mapstructure:"user_group_rate_cache_ttl_seconds"`
 	// ModelsListCacheTTLSeconds: /v1/models 模型列表短缓存 TTL（秒）
 	ModelsListCacheTTLSeconds int `mapstructure:"models_list_cache_ttl_seconds"`
+	// UpstreamMetadataCacheTTLSeconds: 上游元数据（Gemini 模型列表/详情、Codex models manifest）按账号缓存 TTL（秒），0 表示关闭
+	UpstreamMetadataCacheTTLSeconds int `mapstructure:"upstream_metadata_cache_ttl_seconds"`
 
 	// UserMessageQueue: 用户消息串行队列配置
 	// 对 role:"user" 的真实用户消息实施账号级串行化 + RPM 自适应延迟
@@ -874,6 +1324,27 @@ type GatewayOpenAIHTTP2Config struct {
 	FallbackTTLSeconds int `mapstructure:"fallback_ttl_seconds"`
 }
 
+// GatewayOpenAIUpstreamRetryConfig OpenAI HTTP 上游瞬时错误的原地重试配置。
+// 开启后 5xx、过载、带短 Retry-After 的 429 与非持久性传输错误先在当前账号上指数退避重试，
+// 重试耗尽后才返回 UpstreamFailoverError 切换账号。
+// 作用于所有 OpenAI HTTP 转发路径：/v1/responses、/v1/messages 与 /v1/chat/completions 兼容转发（含 Chat Completions 回退）、
+// 图片、embeddings、音频以及 WebSocket 的 HTTP 桥接；透传（passthrough）账号只对会触发切换账号的 429/529 与传输错误重试，
+// 其余错误仍原样透传。WebSocket 入站（openai_ws）与 WS 模式上游不做原地重试。
+type GatewayOpenAIUpstreamRetryConfig struct {
+	// Enabled: 是否在切换账号前原地重试（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxAttempts: 原地重试次数（不含首次请求）
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// BackoffInitialMS: 首次重试前的退避时长（毫秒），之后按 2 的幂增长
+	BackoffInitialMS int `mapstructure:"backoff_initial_ms"`
+	// BackoffMaxMS: 单次退避上限（毫秒）
+	BackoffMaxMS int `mapstructure:"backoff_max_ms"`
+	// JitterRatio: 退避抖动比例（0-1），避免多个请求同时重试
+	JitterRatio float64 `mapstructure:"jitter_ratio"`
+	// MaxRetryAfterSeconds: 上游 Retry-After 超过该值时不再原地等待，直接切换账号
+	MaxRetryAfterSeconds int `mapstructure:"max_retry_after_seconds"`
+}
+
 // UserMessageQueueConfig 用户消息串行队列配置
 // 用于 Anthropic OAuth/SetupToken 账号的用户消息串行化发送
 type UserMessageQueueConfig struct {
@@ -1035,6 +1506,23 @@ type GatewayOpenAISchedulerConfig struct {
 	StickyEscapeErrorRate float64 `mapstructure:"sticky_escape_error_rate"`
 }
 
+// GatewayAPIKeyTraceConfig 单个 API Key 的限时抓包配置。
+// 管理员对指定 Key 开启追踪后，该 Key 的请求体、响应体（含 SSE 流）在到期前被完整记录。
+type GatewayAPIKeyTraceConfig struct {
+	// DefaultDurationMinutes: 未指定时长时的默认追踪时长（分钟）
+	DefaultDurationMinutes int `mapstructure:"default_duration_minutes"`
+	// MaxDurationMinutes: 单次追踪允许的最长时长（分钟）
+	MaxDurationMinutes int `mapstructure:"max_duration_minutes"`
+	// MaxRecords: 每个 Key 保留的最近记录条数
+	MaxRecords int `mapstructure:"max_records"`
+	// MaxBodyBytes: 单条记录中请求体/响应体各自的最大保存字节数
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// RetentionHours: 追踪结束后记录的保留时长（小时）
+	RetentionHours int `mapstructure:"retention_hours"`
+	// CaptureUpstreamResponse: 是否同时记录各次上游调用实际返回的响应头/响应体（响应体上限同 MaxBodyBytes）
+	CaptureUpstreamResponse bool `mapstructure:"capture_upstream_response"`
+}
+
 // GatewayUsageRecordConfig 使用量记录异步队列配置
 type GatewayUsageRecordConfig struct {
 	// WorkerCount: worker 初始数量（自动扩缩容开启时作为初始并发上限）
@@ -1159,6 +1647,80 @@ type GatewaySchedulingConfig struct {
 	// 全量重建周期配置
 	// 全量重建周期（秒），0 表示禁用
 	FullRebuildIntervalSeconds int `mapstructure:"full_rebuild_interval_seconds"`
+
+	// 分组内用户公平调度配置
+	UserFairness GatewayUserFairnessConfig `mapstructure:"user_fairness"`
+
+	// 账号×模型延迟 SLO 降权配置
+	LatencySLO GatewayLatencySLOConfig `mapstructure:"latency_slo"`
+
+	// 账号滚动用量窗口（5 小时/每周）配置
+	UsageWindows GatewayUsageWindowsConfig `mapstructure:"usage_windows"`
+
+	// 流式请求的窗口费用软预占配置
+	WindowCostReservation GatewayWindowCostReservationConfig `mapstructure:"window_cost_reservation"`
+}
+
+// GatewayUserFairnessConfig 分组内用户加权公平调度配置。
+// 开启后按用户近期消耗（指数衰减的 token 量）计算其在分组内的占比，
+// 账号槽位争抢时放慢重度用户的重试节奏，让轻度用户优先拿到空闲槽位。
+type GatewayUserFairnessConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 消耗衰减半衰期（秒）
+	HalfLifeSeconds int `mapstructure:"half_life_seconds"`
+	// 重度用户等待退避的最大倍数（>=1）
+	MaxBackoffMultiplier float64 `mapstructure:"max_backoff_multiplier"`
+	// 分组内活跃用户数低于该值时不做降权
+	MinActiveUsers int `mapstructure:"min_active_users"`
+}
+
+// GatewayLatencySLOConfig 账号×模型响应耗时 SLO。
+// 某账号上某模型在统计窗口内的耗时分位数持续超过阈值时，调度降低该组合的优先级并发出告警；
+// 分位数回落到恢复阈值以下（或窗口内样本不足）后自动恢复。
+type GatewayLatencySLOConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 耗时分位数（0-1，默认 0.95）
+	Percentile float64 `mapstructure:"percentile"`
+	// 分位数超过该值（毫秒）判定为违反 SLO
+	ThresholdMs int `mapstructure:"threshold_ms"`
+	// 恢复阈值 = threshold_ms × recover_ratio（0-1，提供滞回，避免在阈值附近反复切换）
+	RecoverRatio float64 `mapstructure:"recover_ratio"`
+	// 统计窗口（分钟）
+	WindowMinutes int `mapstructure:"window_minutes"`
+	// 窗口内样本数不足时不判定
+	MinSamples int `mapstructure:"min_samples"`
+	// 流式请求使用首字耗时代替总耗时（长输出的总耗时不代表上游变慢）
+	StreamUseFirstToken bool `mapstructure:"stream_use_first_token"`
+}
+
+// GatewayUsageWindowsConfig 账号滚动用量窗口。
+// 按账号套餐（credentials.plan_type）配置 5 小时/每周的请求数上限，网关在本地（Redis）统计每个账号的滚动窗口用量，
+// 调度时避开接近上限的账号，而不是等上游返回 429 才发现。计数为估算值，所有候选都接近上限时仍按原逻辑调度。
+type GatewayUsageWindowsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 用量达到上限的该比例（0-1]即视为接近上限
+	NearLimitRatio float64 `mapstructure:"near_limit_ratio"`
+	// 按套餐配置的窗口上限，键为小写 plan_type（如 plus/pro/team）；未配置的套餐不统计
+	Plans map[string]GatewayUsageWindowLimits `mapstructure:"plans"`
+}
+
+// GatewayUsageWindowLimits 单个套餐的窗口上限（请求数），0 表示该窗口不限制。
+type GatewayUsageWindowLimits struct {
+	FiveHour int `mapstructure:"five_hour"`
+	Weekly   int `mapstructure:"weekly"`
+}
+
+// GatewayWindowCostReservationConfig 流式请求的窗口费用软预占。
+// 设置了 5h 窗口费用上限的 Anthropic OAuth/SetupToken 账号，流式请求转发前按预估费用占用窗口额度，
+// 调度时计入窗口费用；用量到达后以实际费用结算，避免并发长输出在用量落库前大幅超出窗口上限。
+type GatewayWindowCostReservationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 请求未声明 max_tokens 时按该输出 token 数估算
+	DefaultOutputTokens int `mapstructure:"default_output_tokens"`
+	// 单请求预占金额上限（USD，标准费用），0 表示不设上限
+	MaxReservation float64 `mapstructure:"max_reservation"`
+	// 预占的最长存活时间（秒），未结算/释放的预占到期后自动失效
+	ReservationTTLSeconds int `mapstructure:"reservation_ttl_seconds"`
 }
 
 func (s *ServerConfig) Address() string {
@@ -1268,6 +1830,65 @@ type OpsConfig struct {
 
 	// Pre-aggregation configuration.
 	Aggregation OpsAggregationConfig `mapstructure:"aggregation"`
+
+	// StreamTimingSampleRate samples streaming requests (0-1, 0 disables) for per-event timing capture,
+	// used to tell whether a slow stream stalled upstream or in our relay.
+	StreamTimingSampleRate float64 `mapstructure:"stream_timing_sample_rate"`
+
+	// Watchdog snapshots goroutine/heap profiles when in-flight requests, heap or goroutines cross thresholds.
+	Watchdog OpsWatchdogConfig `mapstructure:"watchdog"`
+
+	// SlowRequestLog logs gateway requests whose total duration or TTFT exceeds per-route thresholds.
+	SlowRequestLog OpsSlowRequestLogConfig `mapstructure:"slow_request_log"`
+
+	// SchemaDrift samples successful upstream responses and warns when fields / event types drift
+	// from the expected schemas.
+	SchemaDrift OpsSchemaDriftConfig `mapstructure:"schema_drift"`
+}
+
+type OpsSchemaDriftConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SampleRate is the fraction (0-1) of successful upstream responses inspected.
+	SampleRate           float64 `mapstructure:"sample_rate"`
+	CheckIntervalSeconds int     `mapstructure:"check_interval_seconds"`
+	// MinSamples is the number of samples a check window needs before expected fields / events
+	// may be reported as missing.
+	MinSamples int `mapstructure:"min_samples"`
+	// WarnCooldownMinutes is the minimum gap between two warnings for the same finding.
+	WarnCooldownMinutes int `mapstructure:"warn_cooldown_minutes"`
+}
+
+type OpsSlowRequestLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Default thresholds in milliseconds (0 disables that check).
+	TotalThresholdMs int64 `mapstructure:"total_threshold_ms"`
+	TTFTThresholdMs  int64 `mapstructure:"ttft_threshold_ms"`
+
+	// Routes overrides both thresholds for requests whose path starts with PathPrefix (longest prefix wins).
+	Routes []OpsSlowRequestRouteConfig `mapstructure:"routes"`
+}
+
+type OpsSlowRequestRouteConfig struct {
+	PathPrefix       string `mapstructure:"path_prefix"`
+	TotalThresholdMs int64  `mapstructure:"total_threshold_ms"`
+	TTFTThresholdMs  int64  `mapstructure:"ttft_threshold_ms"`
+}
+
+type OpsWatchdogConfig struct {
+	Enabled              bool `mapstructure:"enabled"`
+	CheckIntervalSeconds int  `mapstructure:"check_interval_seconds"`
+
+	// Thresholds (0 disables that check).
+	MaxInFlightRequests int64 `mapstructure:"max_in_flight_requests"`
+	MaxHeapMB           int   `mapstructure:"max_heap_mb"`
+	MaxGoroutines       int   `mapstructure:"max_goroutines"`
+
+	// CooldownMinutes is the minimum gap between two dumps (and alerts) of this instance.
+	CooldownMinutes int `mapstructure:"cooldown_minutes"`
+	// MaxDumps bounds the number of dump archives kept in DumpDir; older ones are deleted.
+	MaxDumps int    `mapstructure:"max_dumps"`
+	DumpDir  string `mapstructure:"dump_dir"`
 }
 
 type OpsCleanupConfig struct {
@@ -1312,6 +1933,9 @@ type TotpConfig struct {
 	// EncryptionKeyConfigured 标记加密密钥是否为手动配置（非自动生成）
 	// 只有手动配置了密钥才允许在管理后台启用 TOTP 功能
 	EncryptionKeyConfigured bool `mapstructure:"-"`
+	// PreviousEncryptionKeys 轮换前使用过的旧密钥（hex 编码），仅用于解密。
+	// 轮换期间保留旧密钥，运行 -rotate-secrets 将存量密文迁移到 EncryptionKey 后即可移除。
+	PreviousEncryptionKeys []string `mapstructure:"previous_encryption_keys"`
 }
 
 type TurnstileConfig struct {
@@ -1330,6 +1954,8 @@ type DefaultConfig struct {
 type RateLimitConfig struct {
 	OverloadCooldownMinutes int `mapstructure:"overload_cooldown_minutes"`  // 529过载冷却时间(分钟)
 	OAuth401CooldownMinutes int `mapstructure:"oauth_401_cooldown_minutes"` // OAuth 401临时不可调度冷却(分钟)
+	// ProxyChallengeCooldownMinutes 上游返回 Cloudflare challenge / 代理 HTML 错误页时账号的冷却时间(分钟)
+	ProxyChallengeCooldownMinutes int `mapstructure:"proxy_challenge_cooldown_minutes"`
 }
 
 // APIKeyAuthCacheConfig API Key 认证缓存配置
@@ -1410,6 +2036,20 @@ type UsageCleanupConfig struct {
 	TaskTimeoutSeconds int `mapstructure:"task_timeout_seconds"`
 }
 
+// AccountStaleConfig 闲置账号检测与归档配置。
+type AccountStaleConfig struct {
+	// Enabled: 是否周期性标记闲置账号（关闭时管理端仍可按需查询与手动归档）
+	Enabled bool `mapstructure:"enabled"`
+	// StaleDays: 超过该天数未被使用（从未使用则按创建时间计）的账号视为闲置
+	StaleDays int `mapstructure:"stale_days"`
+	// AutoArchive: 是否自动归档闲置达到 ArchiveAfterDays 的账号
+	AutoArchive bool `mapstructure:"auto_archive"`
+	// ArchiveAfterDays: 自动归档阈值（天），不得小于 StaleDays
+	ArchiveAfterDays int `mapstructure:"archive_after_days"`
+	// CheckIntervalMinutes: 后台检测间隔（分钟）
+	CheckIntervalMinutes int `mapstructure:"check_interval_minutes"`
+}
+
 func NormalizeRunMode(value string) string {
 	normalized := strings.ToLower(strings.TrimSpace(value))
 	switch normalized {
@@ -1530,6 +2170,11 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.Log.Environment = strings.TrimSpace(cfg.Log.Environment)
 	cfg.Log.StacktraceLevel = strings.ToLower(strings.TrimSpace(cfg.Log.StacktraceLevel))
 	cfg.Log.Output.FilePath = strings.TrimSpace(cfg.Log.Output.FilePath)
+	cfg.Log.Access.Format = strings.ToLower(strings.TrimSpace(cfg.Log.Access.Format))
+	cfg.Log.Access.FilePath = strings.TrimSpace(cfg.Log.Access.FilePath)
+	cfg.Log.Access.HashSalt = strings.TrimSpace(cfg.Log.Access.HashSalt)
+	cfg.Log.Access.Fields = normalizeStringSlice(cfg.Log.Access.Fields)
+	cfg.Log.Access.SkipPaths = normalizeStringSlice(cfg.Log.Access.SkipPaths)
 	cfg.Gateway.ForcedCodexInstructionsTemplateFile = strings.TrimSpace(cfg.Gateway.ForcedCodexInstructionsTemplateFile)
 	if cfg.Gateway.ForcedCodexInstructionsTemplateFile != "" {
 		content, err := os.ReadFile(cfg.Gateway.ForcedCodexInstructionsTemplateFile)
@@ -1545,6 +2190,12 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 		cfg.Gateway.OpenAIWS.StickyResponseIDTTLSeconds = cfg.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds
 	}
 
+	// 镜像模式覆盖上游时强制 OpenAI 走 HTTP，确保所有上游流量经过可改写的 HTTPUpstream。
+	cfg.ConfigSync.UpstreamOverrideURL = strings.TrimSpace(cfg.ConfigSync.UpstreamOverrideURL)
+	if cfg.ConfigSync.UpstreamOverrideURL != "" {
+		cfg.Gateway.OpenAIWS.ForceHTTP = true
+	}
+
 	// Normalize UMQ mode: 白名单校验，非法值在加载时一次性 warn 并清空
 	if m := cfg.Gateway.UserMessageQueue.Mode; m != "" && m != UMQModeSerialize && m != UMQModeThrottle {
 		slog.Warn("invalid user_message_queue mode, disabling",
@@ -1566,6 +2217,18 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	} else {
 		cfg.Totp.EncryptionKeyConfigured = true
 	}
+	previousKeys := make([]string, 0, len(cfg.Totp.PreviousEncryptionKeys))
+	for _, key := range cfg.Totp.PreviousEncryptionKeys {
+		key = strings.TrimSpace(key)
+		if key == "" || key == cfg.Totp.EncryptionKey {
+			continue
+		}
+		if decoded, err := hex.DecodeString(key); err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("totp.previous_encryption_keys entries must be 32 bytes hex encoded (64 hex chars)")
+		}
+		previousKeys = append(previousKeys, key)
+	}
+	cfg.Totp.PreviousEncryptionKeys = previousKeys
 
 	originalJWTSecret := cfg.JWT.Secret
 	if allowMissingJWTSecret && originalJWTSecret == "" {
@@ -1620,6 +2283,10 @@ func setDefaults() {
 	viper.SetDefault("server.h2c.max_read_frame_size", 1<<20)              // 1MB（够用）
 	viper.SetDefault("server.h2c.max_upload_buffer_per_connection", 2<<20) // 2MB
 	viper.SetDefault("server.h2c.max_upload_buffer_per_stream", 512<<10)   // 512KB
+	viper.SetDefault("server.debug_endpoints.enabled", false)
+	viper.SetDefault("server.debug_endpoints.allow_admin_api_key", false)
+	viper.SetDefault("server.metrics.enabled", false)
+	viper.SetDefault("server.metrics.bearer_token", "")
 
 	// Log
 	viper.SetDefault("log.level", "info")
@@ -1639,6 +2306,12 @@ func setDefaults() {
 	viper.SetDefault("log.sampling.enabled", false)
 	viper.SetDefault("log.sampling.initial", 100)
 	viper.SetDefault("log.sampling.thereafter", 100)
+	viper.SetDefault("log.access.enabled", false)
+	viper.SetDefault("log.access.format", AccessLogFormatCombined)
+	viper.SetDefault("log.access.file_path", "")
+	viper.SetDefault("log.access.fields", []string{"request_id", "latency_ms"})
+	viper.SetDefault("log.access.hash_salt", "")
+	viper.SetDefault("log.access.skip_paths", []string{"/health", "/setup/status", "/metrics"})
 
 	// CORS
 	viper.SetDefault("cors.allowed_origins", []string{})
@@ -1660,6 +2333,7 @@ func setDefaults() {
 		"raw.githubusercontent.com",
 	})
 	viper.SetDefault("security.url_allowlist.crs_hosts", []string{})
+	viper.SetDefault("security.url_allowlist.file_fetch_hosts", []string{})
 	viper.SetDefault("security.url_allowlist.allow_private_hosts", true)
 	viper.SetDefault("security.url_allowlist.allow_insecure_http", true)
 	viper.SetDefault("security.response_headers.enabled", true)
@@ -1681,6 +2355,21 @@ func setDefaults() {
 	viper.SetDefault("billing.minimum_balance_reserve", 0.000001)
 	viper.SetDefault("billing.user_platform_quota_cache_ttl_seconds", 86400)
 	viper.SetDefault("billing.user_platform_quota_sentinel_ttl_seconds", 3600)
+	viper.SetDefault("billing.statements.enabled", false)
+	viper.SetDefault("billing.statements.generate_day", 2)
+	viper.SetDefault("billing.statements.min_amount", 0.5)
+	viper.SetDefault("billing.statements.stripe.enabled", false)
+	viper.SetDefault("billing.statements.stripe.secret_key", "")
+	viper.SetDefault("billing.statements.stripe.webhook_secret", "")
+	viper.SetDefault("billing.statements.stripe.mode", BillingStripeModeInvoice)
+	viper.SetDefault("billing.statements.stripe.currency", "USD")
+	viper.SetDefault("billing.statements.stripe.days_until_due", 0)
+	viper.SetDefault("billing.statements.stripe.meter_event_name", "")
+	viper.SetDefault("billing.prepaid.enabled", false)
+	viper.SetDefault("billing.prepaid.default_output_tokens", 4096)
+	viper.SetDefault("billing.prepaid.min_reservation", 0.001)
+	viper.SetDefault("billing.prepaid.max_reservation", 5.0)
+	viper.SetDefault("billing.prepaid.reservation_ttl_seconds", 900)
 
 	// Turnstile
 	viper.SetDefault("turnstile.required", false)
@@ -1843,6 +2532,23 @@ func setDefaults() {
 	viper.SetDefault("ops.metrics_collector_cache.enabled", true)
 	// TTL should be slightly larger than collection interval (1m) to maximize cross-replica cache hits.
 	viper.SetDefault("ops.metrics_collector_cache.ttl", 65*time.Second)
+	viper.SetDefault("ops.stream_timing_sample_rate", 0.0)
+	viper.SetDefault("ops.watchdog.enabled", false)
+	viper.SetDefault("ops.watchdog.check_interval_seconds", 15)
+	viper.SetDefault("ops.watchdog.max_in_flight_requests", 0)
+	viper.SetDefault("ops.watchdog.max_heap_mb", 0)
+	viper.SetDefault("ops.watchdog.max_goroutines", 0)
+	viper.SetDefault("ops.watchdog.cooldown_minutes", 10)
+	viper.SetDefault("ops.watchdog.max_dumps", 5)
+	viper.SetDefault("ops.watchdog.dump_dir", "./data/ops_dumps")
+	viper.SetDefault("ops.slow_request_log.enabled", false)
+	viper.SetDefault("ops.slow_request_log.total_threshold_ms", 120000)
+	viper.SetDefault("ops.slow_request_log.ttft_threshold_ms", 30000)
+	viper.SetDefault("ops.schema_drift.enabled", false)
+	viper.SetDefault("ops.schema_drift.sample_rate", 0.05)
+	viper.SetDefault("ops.schema_drift.check_interval_seconds", 600)
+	viper.SetDefault("ops.schema_drift.min_samples", 20)
+	viper.SetDefault("ops.schema_drift.warn_cooldown_minutes", 1440)
 
 	// JWT
 	viper.SetDefault("jwt.secret", "")
@@ -1853,6 +2559,7 @@ func setDefaults() {
 
 	// TOTP
 	viper.SetDefault("totp.encryption_key", "")
+	viper.SetDefault("totp.previous_encryption_keys", []string{})
 
 	// Default
 	// Admin credentials are created via the setup flow (web wizard / CLI / AUTO_SETUP).
@@ -1867,6 +2574,7 @@ func setDefaults() {
 	// RateLimit
 	viper.SetDefault("rate_limit.overload_cooldown_minutes", 10)
 	viper.SetDefault("rate_limit.oauth_401_cooldown_minutes", 10)
+	viper.SetDefault("rate_limit.proxy_challenge_cooldown_minutes", 10)
 
 	// Pricing - 从 model-price-repo 同步模型定价和上下文窗口数据（固定到 commit，避免分支漂移）
 	viper.SetDefault("pricing.remote_url", "https://raw.githubusercontent.com/Wei-Shaw/model-price-repo/main/model_prices_and_context_window.json")
@@ -1875,6 +2583,9 @@ func setDefaults() {
 	viper.SetDefault("pricing.fallback_file", "./resources/model-pricing/model_prices_and_context_window.json")
 	viper.SetDefault("pricing.update_interval_hours", 24)
 	viper.SetDefault("pricing.hash_check_interval_minutes", 10)
+	viper.SetDefault("pricing.signature_url", "")
+	viper.SetDefault("pricing.public_key", "")
+	viper.SetDefault("pricing.approval_mode", PricingApprovalModeAuto)
 
 	// Timezone (default to Asia/Shanghai for Chinese users)
 	viper.SetDefault("timezone", "Asia/Shanghai")
@@ -1918,6 +2629,13 @@ func setDefaults() {
 	viper.SetDefault("usage_cleanup.worker_interval_seconds", 10)
 	viper.SetDefault("usage_cleanup.task_timeout_seconds", 1800)
 
+	// Stale account detection
+	viper.SetDefault("account_stale.enabled", false)
+	viper.SetDefault("account_stale.stale_days", 30)
+	viper.SetDefault("account_stale.auto_archive", false)
+	viper.SetDefault("account_stale.archive_after_days", 60)
+	viper.SetDefault("account_stale.check_interval_minutes", 60)
+
 	// Idempotency
 	viper.SetDefault("idempotency.observe_only", true)
 	viper.SetDefault("idempotency.default_ttl_seconds", 86400)
@@ -1928,10 +2646,34 @@ func setDefaults() {
 	viper.SetDefault("idempotency.cleanup_interval_seconds", 60)
 	viper.SetDefault("idempotency.cleanup_batch_size", 500)
 
+	// Config sync (primary/replica)
+	viper.SetDefault("config_sync.role", "")
+	viper.SetDefault("config_sync.token", "")
+	viper.SetDefault("config_sync.primary_url", "")
+	viper.SetDefault("config_sync.interval_seconds", 30)
+	viper.SetDefault("config_sync.request_timeout_seconds", 30)
+	viper.SetDefault("config_sync.upstream_override_url", "")
+
+	// Upstream cassette (record/replay)
+	viper.SetDefault("upstream_cassette.mode", "")
+	viper.SetDefault("upstream_cassette.dir", "./data/cassettes")
+	viper.SetDefault("upstream_cassette.max_body_bytes", 4<<20)
+
+	// Tracing (OpenTelemetry)
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.service_name", "sub2api")
+	viper.SetDefault("tracing.otlp_endpoint", "localhost:4318")
+	viper.SetDefault("tracing.otlp_url_path", "")
+	viper.SetDefault("tracing.otlp_insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 0.1)
+
 	// Gateway
 	viper.SetDefault("gateway.response_header_timeout", 600) // 600秒(10分钟)等待上游响应头，LLM高负载时可能排队较久
 	viper.SetDefault("gateway.openai_response_header_timeout", 0)
 	viper.SetDefault("gateway.log_upstream_error_body", true)
+	viper.SetDefault("gateway.response_content_guard", false)
+	viper.SetDefault("gateway.openai_strict_errors", false)
+	viper.SetDefault("gateway.anthropic_strict_errors", false)
 	viper.SetDefault("gateway.log_upstream_error_body_max_bytes", 2048)
 	viper.SetDefault("gateway.inject_beta_for_apikey", false)
 	viper.SetDefault("gateway.failover_on_400", false)
@@ -1941,6 +2683,7 @@ func setDefaults() {
 	viper.SetDefault("gateway.codex_image_generation_bridge_enabled", false)
 	viper.SetDefault("gateway.openai_passthrough_allow_timeout_headers", false)
 	viper.SetDefault("gateway.openai_compact_model", "gpt-5.4")
+	viper.SetDefault("gateway.openai_session_id_strategy", "prompt_cache_key")
 	// OpenAI Responses WebSocket（默认开启；可通过 force_http 紧急回滚）
 	viper.SetDefault("gateway.openai_ws.enabled", true)
 	viper.SetDefault("gateway.openai_ws.mode_router_v2_enabled", false)
@@ -1980,6 +2723,7 @@ func setDefaults() {
 	viper.SetDefault("gateway.openai_ws.payload_log_sample_rate", 0.2)
 	viper.SetDefault("gateway.openai_ws.lb_top_k", 7)
 	viper.SetDefault("gateway.openai_ws.sticky_session_ttl_seconds", 3600)
+	viper.SetDefault("gateway.sticky_session_ttl_seconds", 3600)
 	viper.SetDefault("gateway.openai_ws.session_hash_read_old_fallback", true)
 	viper.SetDefault("gateway.openai_ws.session_hash_dual_write_old", true)
 	viper.SetDefault("gateway.openai_ws.metadata_bridge_enabled", true)
@@ -2000,11 +2744,64 @@ func setDefaults() {
 	viper.SetDefault("gateway.openai_http2.fallback_error_threshold", 2)
 	viper.SetDefault("gateway.openai_http2.fallback_window_seconds", 60)
 	viper.SetDefault("gateway.openai_http2.fallback_ttl_seconds", 600)
+	viper.SetDefault("gateway.openai_upstream_retry.enabled", false)
+	viper.SetDefault("gateway.openai_upstream_retry.max_attempts", 2)
+	viper.SetDefault("gateway.openai_upstream_retry.backoff_initial_ms", 500)
+	viper.SetDefault("gateway.openai_upstream_retry.backoff_max_ms", 4000)
+	viper.SetDefault("gateway.openai_upstream_retry.jitter_ratio", 0.2)
+	viper.SetDefault("gateway.openai_upstream_retry.max_retry_after_seconds", 10)
 	viper.SetDefault("gateway.image_concurrency.enabled", false)
 	viper.SetDefault("gateway.image_concurrency.max_concurrent_requests", 0)
 	viper.SetDefault("gateway.image_concurrency.overflow_mode", ImageConcurrencyOverflowModeReject)
 	viper.SetDefault("gateway.image_concurrency.wait_timeout_seconds", 30)
 	viper.SetDefault("gateway.image_concurrency.max_waiting_requests", 100)
+	viper.SetDefault("gateway.file_fetch.enabled", false)
+	viper.SetDefault("gateway.file_fetch.max_bytes", int64(20*1024*1024))
+	viper.SetDefault("gateway.file_fetch.max_files_per_request", 8)
+	viper.SetDefault("gateway.file_fetch.timeout_seconds", 15)
+	viper.SetDefault("gateway.file_fetch.cache_ttl_seconds", 600)
+	viper.SetDefault("gateway.file_fetch.cache_max_bytes", int64(128*1024*1024))
+	viper.SetDefault("gateway.file_fetch.allow_private_hosts", false)
+	viper.SetDefault("gateway.request_coalescing.enabled", false)
+	viper.SetDefault("gateway.request_coalescing.wait_timeout_seconds", 120)
+	viper.SetDefault("gateway.request_coalescing.max_response_bytes", int64(8*1024*1024))
+	viper.SetDefault("gateway.request_coalescing.streaming", false)
+	viper.SetDefault("gateway.response_cache.enabled", false)
+	viper.SetDefault("gateway.response_cache.backend", "redis")
+	viper.SetDefault("gateway.response_cache.disk_dir", "./data/response_cache")
+	viper.SetDefault("gateway.response_cache.disk_max_bytes", int64(1024*1024*1024))
+	viper.SetDefault("gateway.response_cache.disk_max_entries", 100000)
+	viper.SetDefault("gateway.response_cache.encryption_key", "")
+	viper.SetDefault("gateway.response_cache.ttl_seconds", 3600)
+	viper.SetDefault("gateway.response_cache.max_response_bytes", int64(1024*1024))
+	viper.SetDefault("gateway.compact_coalescing.enabled", false)
+	viper.SetDefault("gateway.compact_coalescing.window_seconds", 30)
+	viper.SetDefault("gateway.compact_coalescing.wait_timeout_seconds", 300)
+	viper.SetDefault("gateway.compact_coalescing.max_response_bytes", int64(8*1024*1024))
+	viper.SetDefault("gateway.compact_response_metadata", true)
+	viper.SetDefault("gateway.compaction_token_binding.enabled", false)
+	viper.SetDefault("gateway.compaction_token_binding.scope", "group")
+	viper.SetDefault("gateway.compaction_token_binding.key", "")
+	viper.SetDefault("gateway.compaction_token_binding.accept_legacy_tokens", true)
+	viper.SetDefault("gateway.compact_guardrails.enabled", false)
+	viper.SetDefault("gateway.compact_guardrails.min_summary_chars", 50)
+	viper.SetDefault("gateway.compact_guardrails.max_summary_chars", 0)
+	viper.SetDefault("gateway.compact_guardrails.max_retries", 1)
+	viper.SetDefault("gateway.compact_guardrails.language_matching", true)
+	viper.SetDefault("gateway.request_classes.interactive.timeout_seconds", 0)
+	viper.SetDefault("gateway.request_classes.interactive.max_account_switches", 0)
+	viper.SetDefault("gateway.request_classes.interactive.slo_first_token_ms", 5000)
+	viper.SetDefault("gateway.request_classes.interactive.slo_duration_ms", 0)
+	viper.SetDefault("gateway.request_classes.batch.timeout_seconds", 0)
+	viper.SetDefault("gateway.request_classes.batch.max_account_switches", 0)
+	viper.SetDefault("gateway.request_classes.batch.slo_first_token_ms", 0)
+	viper.SetDefault("gateway.request_classes.batch.slo_duration_ms", 300000)
+	viper.SetDefault("gateway.hedging.enabled", false)
+	viper.SetDefault("gateway.hedging.delay_percentile", 0.95)
+	viper.SetDefault("gateway.hedging.min_samples", 50)
+	viper.SetDefault("gateway.hedging.min_delay_ms", 500)
+	viper.SetDefault("gateway.hedging.max_delay_ms", 30000)
+	viper.SetDefault("gateway.hedging.budget_per_minute", 5)
 	viper.SetDefault("gateway.antigravity_fallback_cooldown_minutes", 1)
 	viper.SetDefault("gateway.antigravity_extra_retries", 10)
 	viper.SetDefault("gateway.max_body_size", int64(256*1024*1024))
@@ -2019,11 +2816,20 @@ func setDefaults() {
 	viper.SetDefault("gateway.idle_conn_timeout_seconds", 90) // 空闲连接超时（秒）
 	viper.SetDefault("gateway.max_upstream_clients", 5000)
 	viper.SetDefault("gateway.client_idle_ttl_seconds", 900)
+	viper.SetDefault("gateway.warm_connections.enabled", false)
+	viper.SetDefault("gateway.warm_connections.interval_seconds", 30)
+	viper.SetDefault("gateway.warm_connections.standby_connections", 1)
+	viper.SetDefault("gateway.warm_connections.active_within_seconds", 600)
 	viper.SetDefault("gateway.concurrency_slot_ttl_minutes", 30) // 并发槽位过期时间（支持超长请求）
 	viper.SetDefault("gateway.stream_data_interval_timeout", 180)
 	viper.SetDefault("gateway.stream_keepalive_interval", 10)
 	viper.SetDefault("gateway.image_stream_data_interval_timeout", 900)
 	viper.SetDefault("gateway.image_stream_keepalive_interval", 10)
+	viper.SetDefault("gateway.reasoning_events.default_mode", ReasoningEventsModePassthrough)
+	viper.SetDefault("gateway.stream_flush.enabled", false)
+	viper.SetDefault("gateway.stream_flush.flush_interval_ms", 20)
+	viper.SetDefault("gateway.stream_flush.coalesce_min_bytes", 0)
+	viper.SetDefault("gateway.stream_flush.write_buffer_bytes", 64*1024)
 	viper.SetDefault("gateway.max_line_size", 500*1024*1024)
 	viper.SetDefault("gateway.scheduling.sticky_session_max_waiting", 3)
 	viper.SetDefault("gateway.scheduling.sticky_session_wait_timeout", 120*time.Second)
@@ -2045,6 +2851,29 @@ func setDefaults() {
 	viper.SetDefault("gateway.scheduling.outbox_lag_rebuild_failures", 3)
 	viper.SetDefault("gateway.scheduling.outbox_backlog_rebuild_rows", 10000)
 	viper.SetDefault("gateway.scheduling.full_rebuild_interval_seconds", 300)
+	viper.SetDefault("gateway.scheduling.user_fairness.enabled", false)
+	viper.SetDefault("gateway.scheduling.user_fairness.half_life_seconds", 300)
+	viper.SetDefault("gateway.scheduling.user_fairness.max_backoff_multiplier", 4.0)
+	viper.SetDefault("gateway.scheduling.user_fairness.min_active_users", 2)
+	viper.SetDefault("gateway.scheduling.latency_slo.enabled", false)
+	viper.SetDefault("gateway.scheduling.latency_slo.percentile", 0.95)
+	viper.SetDefault("gateway.scheduling.latency_slo.threshold_ms", 60000)
+	viper.SetDefault("gateway.scheduling.latency_slo.recover_ratio", 0.8)
+	viper.SetDefault("gateway.scheduling.latency_slo.window_minutes", 15)
+	viper.SetDefault("gateway.scheduling.latency_slo.min_samples", 20)
+	viper.SetDefault("gateway.scheduling.latency_slo.stream_use_first_token", true)
+	viper.SetDefault("gateway.scheduling.usage_windows.enabled", false)
+	viper.SetDefault("gateway.scheduling.usage_windows.near_limit_ratio", 0.9)
+	viper.SetDefault("gateway.scheduling.window_cost_reservation.enabled", false)
+	viper.SetDefault("gateway.scheduling.window_cost_reservation.default_output_tokens", 8192)
+	viper.SetDefault("gateway.scheduling.window_cost_reservation.max_reservation", 0)
+	viper.SetDefault("gateway.scheduling.window_cost_reservation.reservation_ttl_seconds", 900)
+	viper.SetDefault("gateway.api_key_trace.default_duration_minutes", 30)
+	viper.SetDefault("gateway.api_key_trace.max_duration_minutes", 240)
+	viper.SetDefault("gateway.api_key_trace.max_records", 200)
+	viper.SetDefault("gateway.api_key_trace.max_body_bytes", 1<<20)
+	viper.SetDefault("gateway.api_key_trace.retention_hours", 24)
+	viper.SetDefault("gateway.api_key_trace.capture_upstream_response", false)
 	viper.SetDefault("gateway.usage_record.worker_count", 128)
 	viper.SetDefault("gateway.usage_record.queue_size", 16384)
 	viper.SetDefault("gateway.usage_record.task_timeout_seconds", 5)
@@ -2064,6 +2893,7 @@ func setDefaults() {
 	viper.SetDefault("gateway.usage_record.auto_scale_cooldown_seconds", 10)
 	viper.SetDefault("gateway.user_group_rate_cache_ttl_seconds", 30)
 	viper.SetDefault("gateway.models_list_cache_ttl_seconds", 15)
+	viper.SetDefault("gateway.upstream_metadata_cache_ttl_seconds", 300)
 	// TLS指纹伪装配置（默认关闭，需要账号级别单独启用）
 	// 用户消息串行队列默认值
 	viper.SetDefault("gateway.user_message_queue.enabled", false)
@@ -2097,6 +2927,37 @@ func setDefaults() {
 
 }
 
+func validateReasoningEventsConfig(cfg GatewayReasoningEventsConfig) error {
+	if !isValidReasoningEventsMode(cfg.DefaultMode, true) {
+		return fmt.Errorf("gateway.reasoning_events.default_mode must be one of passthrough|filter|convert")
+	}
+	for i, profile := range cfg.Profiles {
+		if strings.TrimSpace(profile.Name) == "" {
+			return fmt.Errorf("gateway.reasoning_events.profiles[%d].name is required", i)
+		}
+		if strings.TrimSpace(profile.UserAgentPattern) == "" {
+			return fmt.Errorf("gateway.reasoning_events.profiles[%d].user_agent_pattern is required", i)
+		}
+		if _, err := regexp.Compile(profile.UserAgentPattern); err != nil {
+			return fmt.Errorf("gateway.reasoning_events.profiles[%d].user_agent_pattern is invalid: %w", i, err)
+		}
+		if !isValidReasoningEventsMode(profile.Mode, false) {
+			return fmt.Errorf("gateway.reasoning_events.profiles[%d].mode must be one of passthrough|filter|convert", i)
+		}
+	}
+	return nil
+}
+
+func isValidReasoningEventsMode(mode string, allowEmpty bool) bool {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case ReasoningEventsModePassthrough, ReasoningEventsModeFilter, ReasoningEventsModeConvert:
+		return true
+	case "":
+		return allowEmpty
+	}
+	return false
+}
+
 func (c *Config) Validate() error {
 	jwtSecret := strings.TrimSpace(c.JWT.Secret)
 	if jwtSecret == "" {
@@ -2155,6 +3016,21 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("log.sampling.thereafter must be non-negative")
 		}
 	}
+	if c.Log.Access.Enabled {
+		switch c.Log.Access.Format {
+		case AccessLogFormatCommon, AccessLogFormatCombined, AccessLogFormatJSON:
+		default:
+			return fmt.Errorf("log.access.format must be one of: common/combined/json")
+		}
+		for _, field := range c.Log.Access.Fields {
+			if !slices.Contains(AccessLogFields, field) {
+				return fmt.Errorf("log.access.fields contains unknown field %q (allowed: %s)", field, strings.Join(AccessLogFields, ", "))
+			}
+			if strings.HasSuffix(field, "_hash") && len(c.Log.Access.HashSalt) < 16 {
+				return fmt.Errorf("log.access.hash_salt must be at least 16 characters when %s is enabled", field)
+			}
+		}
+	}
 
 	if c.SubscriptionMaintenance.WorkerCount < 0 {
 		return fmt.Errorf("subscription_maintenance.worker_count must be non-negative")
@@ -2187,6 +3063,9 @@ func (c *Config) Validate() error {
 		}
 		warnIfInsecureURL("server.frontend_url", c.Server.FrontendURL)
 	}
+	if c.Server.Metrics.Enabled && strings.TrimSpace(c.Server.Metrics.BearerToken) == "" {
+		slog.Warn("server.metrics is enabled without bearer_token; restrict /metrics access at the network layer")
+	}
 	if c.JWT.ExpireHour <= 0 {
 		return fmt.Errorf("jwt.expire_hour must be positive")
 	}
@@ -2398,6 +3277,57 @@ func (c *Config) Validate() error {
 	if c.Billing.MinimumBalanceReserve < 0 {
 		return fmt.Errorf("billing.minimum_balance_reserve must be non-negative")
 	}
+	if c.Billing.Statements.GenerateDay < 1 || c.Billing.Statements.GenerateDay > 28 {
+		return fmt.Errorf("billing.statements.generate_day must be between 1-28")
+	}
+	switch c.Pricing.ApprovalMode {
+	case "", PricingApprovalModeAuto, PricingApprovalModeManual:
+	default:
+		return fmt.Errorf("pricing.approval_mode must be one of: %s, %s", PricingApprovalModeAuto, PricingApprovalModeManual)
+	}
+	if publicKey := strings.TrimSpace(c.Pricing.PublicKey); publicKey != "" {
+		raw, err := base64.StdEncoding.DecodeString(publicKey)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return fmt.Errorf("pricing.public_key must be a base64-encoded ed25519 public key")
+		}
+		if strings.TrimSpace(c.Pricing.SignatureURL) == "" {
+			return fmt.Errorf("pricing.signature_url is required when pricing.public_key is set")
+		}
+	}
+	if c.Billing.Statements.MinAmount < 0 {
+		return fmt.Errorf("billing.statements.min_amount must be non-negative")
+	}
+	if stripeCfg := c.Billing.Statements.Stripe; stripeCfg.Enabled {
+		if strings.TrimSpace(stripeCfg.SecretKey) == "" {
+			return fmt.Errorf("billing.statements.stripe.secret_key is required when billing.statements.stripe.enabled=true")
+		}
+		switch stripeCfg.Mode {
+		case BillingStripeModeInvoice:
+		case BillingStripeModeMetered:
+			if strings.TrimSpace(stripeCfg.MeterEventName) == "" {
+				return fmt.Errorf("billing.statements.stripe.meter_event_name is required when billing.statements.stripe.mode=metered")
+			}
+		default:
+			return fmt.Errorf("billing.statements.stripe.mode must be one of: %s, %s", BillingStripeModeInvoice, BillingStripeModeMetered)
+		}
+		if stripeCfg.DaysUntilDue < 0 {
+			return fmt.Errorf("billing.statements.stripe.days_until_due must be non-negative")
+		}
+	}
+	if prepaid := c.Billing.Prepaid; prepaid.Enabled {
+		if prepaid.DefaultOutputTokens < 0 {
+			return fmt.Errorf("billing.prepaid.default_output_tokens must be non-negative")
+		}
+		if prepaid.MinReservation < 0 || prepaid.MaxReservation < 0 {
+			return fmt.Errorf("billing.prepaid.min_reservation and max_reservation must be non-negative")
+		}
+		if prepaid.MaxReservation > 0 && prepaid.MaxReservation < prepaid.MinReservation {
+			return fmt.Errorf("billing.prepaid.max_reservation must be >= min_reservation")
+		}
+		if prepaid.ReservationTTLSeconds <= 0 {
+			return fmt.Errorf("billing.prepaid.reservation_ttl_seconds must be positive")
+		}
+	}
 	if c.Database.MaxOpenConns <= 0 {
 		return fmt.Errorf("database.max_open_conns must be positive")
 	}
@@ -2572,6 +3502,17 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("dashboard_aggregation.recompute_days must be non-negative")
 		}
 	}
+	if c.AccountStale.Enabled {
+		if c.AccountStale.StaleDays <= 0 {
+			return fmt.Errorf("account_stale.stale_days must be positive")
+		}
+		if c.AccountStale.CheckIntervalMinutes <= 0 {
+			return fmt.Errorf("account_stale.check_interval_minutes must be positive")
+		}
+		if c.AccountStale.AutoArchive && c.AccountStale.ArchiveAfterDays < c.AccountStale.StaleDays {
+			return fmt.Errorf("account_stale.archive_after_days must be >= account_stale.stale_days")
+		}
+	}
 	if c.UsageCleanup.Enabled {
 		if c.UsageCleanup.MaxRangeDays <= 0 {
 			return fmt.Errorf("usage_cleanup.max_range_days must be positive")
@@ -2620,6 +3561,15 @@ func (c *Config) Validate() error {
 	if c.Idempotency.CleanupBatchSize <= 0 {
 		return fmt.Errorf("idempotency.cleanup_batch_size must be positive")
 	}
+	if err := c.ConfigSync.validate(); err != nil {
+		return err
+	}
+	if err := c.UpstreamCassette.validate(); err != nil {
+		return err
+	}
+	if err := c.Tracing.validate(); err != nil {
+		return err
+	}
 	if c.Gateway.MaxBodySize <= 0 {
 		return fmt.Errorf("gateway.max_body_size must be positive")
 	}
@@ -2658,6 +3608,114 @@ func (c *Config) Validate() error {
 	if c.Gateway.ImageConcurrency.MaxWaitingRequests < 0 {
 		return fmt.Errorf("gateway.image_concurrency.max_waiting_requests must be non-negative")
 	}
+	if c.Gateway.FileFetch.Enabled {
+		if c.Gateway.FileFetch.MaxBytes <= 0 {
+			return fmt.Errorf("gateway.file_fetch.max_bytes must be positive")
+		}
+		if c.Gateway.FileFetch.MaxFilesPerRequest <= 0 {
+			return fmt.Errorf("gateway.file_fetch.max_files_per_request must be positive")
+		}
+		if c.Gateway.FileFetch.TimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.file_fetch.timeout_seconds must be positive")
+		}
+		if c.Gateway.FileFetch.CacheTTLSeconds < 0 || c.Gateway.FileFetch.CacheMaxBytes < 0 {
+			return fmt.Errorf("gateway.file_fetch cache settings must be non-negative")
+		}
+	}
+	if c.Gateway.RequestCoalescing.Enabled {
+		if c.Gateway.RequestCoalescing.WaitTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.request_coalescing.wait_timeout_seconds must be positive")
+		}
+		if c.Gateway.RequestCoalescing.MaxResponseBytes <= 0 {
+			return fmt.Errorf("gateway.request_coalescing.max_response_bytes must be positive")
+		}
+	}
+	if c.Gateway.ResponseCache.Enabled {
+		switch c.Gateway.ResponseCache.Backend {
+		case "redis":
+		case "disk":
+			if strings.TrimSpace(c.Gateway.ResponseCache.DiskDir) == "" {
+				return fmt.Errorf("gateway.response_cache.disk_dir is required when backend is disk")
+			}
+			if c.Gateway.ResponseCache.DiskMaxBytes <= 0 {
+				return fmt.Errorf("gateway.response_cache.disk_max_bytes must be positive")
+			}
+			if c.Gateway.ResponseCache.DiskMaxEntries <= 0 {
+				return fmt.Errorf("gateway.response_cache.disk_max_entries must be positive")
+			}
+		default:
+			return fmt.Errorf("gateway.response_cache.backend must be one of: redis, disk")
+		}
+		if key := strings.TrimSpace(c.Gateway.ResponseCache.EncryptionKey); key != "" {
+			if decoded, err := hex.DecodeString(key); err != nil || len(decoded) != 32 {
+				return fmt.Errorf("gateway.response_cache.encryption_key must be 32 bytes hex encoded (64 hex chars)")
+			}
+		}
+		if c.Gateway.ResponseCache.TTLSeconds <= 0 {
+			return fmt.Errorf("gateway.response_cache.ttl_seconds must be positive")
+		}
+		if c.Gateway.ResponseCache.MaxResponseBytes <= 0 {
+			return fmt.Errorf("gateway.response_cache.max_response_bytes must be positive")
+		}
+	}
+	if c.Gateway.CompactCoalescing.Enabled {
+		if c.Gateway.CompactCoalescing.WindowSeconds < 0 {
+			return fmt.Errorf("gateway.compact_coalescing.window_seconds must be non-negative")
+		}
+		if c.Gateway.CompactCoalescing.WaitTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.compact_coalescing.wait_timeout_seconds must be positive")
+		}
+		if c.Gateway.CompactCoalescing.MaxResponseBytes <= 0 {
+			return fmt.Errorf("gateway.compact_coalescing.max_response_bytes must be positive")
+		}
+	}
+	if c.Gateway.CompactionTokenBinding.Enabled {
+		binding := c.Gateway.CompactionTokenBinding
+		if binding.Scope != "group" && binding.Scope != "api_key" {
+			return fmt.Errorf("gateway.compaction_token_binding.scope must be one of: group, api_key")
+		}
+		if key, err := hex.DecodeString(strings.TrimSpace(binding.Key)); err != nil || len(key) != 32 {
+			return fmt.Errorf("gateway.compaction_token_binding.key must be 32 bytes hex encoded (64 hex chars)")
+		}
+	}
+	if c.Gateway.CompactGuardrails.Enabled {
+		guardrails := c.Gateway.CompactGuardrails
+		if guardrails.MinSummaryChars < 0 {
+			return fmt.Errorf("gateway.compact_guardrails.min_summary_chars must be non-negative")
+		}
+		if guardrails.MaxSummaryChars < 0 {
+			return fmt.Errorf("gateway.compact_guardrails.max_summary_chars must be non-negative")
+		}
+		if guardrails.MaxSummaryChars > 0 && guardrails.MaxSummaryChars < guardrails.MinSummaryChars {
+			return fmt.Errorf("gateway.compact_guardrails.max_summary_chars must be >= min_summary_chars")
+		}
+		if guardrails.MaxRetries < 0 || guardrails.MaxRetries > 3 {
+			return fmt.Errorf("gateway.compact_guardrails.max_retries must be between 0 and 3")
+		}
+	}
+	for name, policy := range map[string]GatewayRequestClassPolicy{
+		"interactive": c.Gateway.RequestClasses.Interactive,
+		"batch":       c.Gateway.RequestClasses.Batch,
+	} {
+		if policy.TimeoutSeconds < 0 || policy.MaxAccountSwitches < 0 || policy.SLOFirstTokenMs < 0 || policy.SLODurationMs < 0 {
+			return fmt.Errorf("gateway.request_classes.%s settings must be non-negative", name)
+		}
+	}
+	if c.Gateway.Hedging.Enabled {
+		if c.Gateway.Hedging.DelayPercentile <= 0 || c.Gateway.Hedging.DelayPercentile >= 1 {
+			return fmt.Errorf("gateway.hedging.delay_percentile must be between 0 and 1")
+		}
+		if c.Gateway.Hedging.MinSamples <= 0 {
+			return fmt.Errorf("gateway.hedging.min_samples must be positive")
+		}
+		if c.Gateway.Hedging.MinDelayMs < 0 || c.Gateway.Hedging.MaxDelayMs < c.Gateway.Hedging.MinDelayMs {
+			return fmt.Errorf("gateway.hedging.max_delay_ms must be >= min_delay_ms >= 0")
+		}
+		if c.Gateway.Hedging.BudgetPerMinute <= 0 {
+			return fmt.Errorf("gateway.hedging.budget_per_minute must be positive")
+		}
+		slog.Warn("gateway.hedging only applies to non-streaming /v1/messages requests on the Anthropic gateway path; streaming requests, OpenAI and Gemini groups, and Antigravity OAuth accounts are never hedged")
+	}
 	if c.Gateway.MaxIdleConns <= 0 {
 		return fmt.Errorf("gateway.max_idle_conns must be positive")
 	}
@@ -2679,6 +3737,21 @@ func (c *Config) Validate() error {
 	if c.Gateway.ClientIdleTTLSeconds <= 0 {
 		return fmt.Errorf("gateway.client_idle_ttl_seconds must be positive")
 	}
+	if c.Gateway.WarmConnections.Enabled {
+		if c.Gateway.WarmConnections.IntervalSeconds <= 0 {
+			return fmt.Errorf("gateway.warm_connections.interval_seconds must be positive")
+		}
+		if c.Gateway.WarmConnections.StandbyConnections <= 0 || c.Gateway.WarmConnections.StandbyConnections > 8 {
+			return fmt.Errorf("gateway.warm_connections.standby_connections must be between 1 and 8")
+		}
+		if c.Gateway.WarmConnections.ActiveWithinSeconds <= 0 {
+			return fmt.Errorf("gateway.warm_connections.active_within_seconds must be positive")
+		}
+		if c.Gateway.WarmConnections.IntervalSeconds >= c.Gateway.IdleConnTimeoutSeconds {
+			slog.Warn("gateway.warm_connections.interval_seconds should be lower than idle_conn_timeout_seconds, otherwise standby connections expire between warm-ups",
+				"interval_seconds", c.Gateway.WarmConnections.IntervalSeconds, "idle_conn_timeout_seconds", c.Gateway.IdleConnTimeoutSeconds)
+		}
+	}
 	if c.Gateway.ConcurrencySlotTTLMinutes <= 0 {
 		return fmt.Errorf("gateway.concurrency_slot_ttl_minutes must be positive")
 	}
@@ -2696,6 +3769,29 @@ func (c *Config) Validate() error {
 		(c.Gateway.StreamKeepaliveInterval < 5 || c.Gateway.StreamKeepaliveInterval > 30) {
 		return fmt.Errorf("gateway.stream_keepalive_interval must be 0 or between 5-30 seconds")
 	}
+	for i, override := range c.Gateway.ModelCapabilities {
+		model := strings.TrimSpace(override.Model)
+		if model == "" {
+			return fmt.Errorf("gateway.model_capabilities[%d].model is required", i)
+		}
+		if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+			return fmt.Errorf("gateway.model_capabilities[%d].model only supports a trailing * wildcard", i)
+		}
+	}
+	if err := validateReasoningEventsConfig(c.Gateway.ReasoningEvents); err != nil {
+		return err
+	}
+	if c.Gateway.StreamFlush.Enabled {
+		if c.Gateway.StreamFlush.FlushIntervalMs <= 0 || c.Gateway.StreamFlush.FlushIntervalMs > 1000 {
+			return fmt.Errorf("gateway.stream_flush.flush_interval_ms must be between 1-1000")
+		}
+		if c.Gateway.StreamFlush.WriteBufferBytes < 4096 || c.Gateway.StreamFlush.WriteBufferBytes > 4*1024*1024 {
+			return fmt.Errorf("gateway.stream_flush.write_buffer_bytes must be between 4096 and 4194304")
+		}
+		if c.Gateway.StreamFlush.CoalesceMinBytes < 0 || c.Gateway.StreamFlush.CoalesceMinBytes > c.Gateway.StreamFlush.WriteBufferBytes {
+			return fmt.Errorf("gateway.stream_flush.coalesce_min_bytes must be between 0 and write_buffer_bytes")
+		}
+	}
 	if c.Gateway.ImageStreamDataIntervalTimeout < 0 {
 		return fmt.Errorf("gateway.image_stream_data_interval_timeout must be non-negative")
 	}
@@ -2710,6 +3806,14 @@ func (c *Config) Validate() error {
 		(c.Gateway.ImageStreamKeepaliveInterval < 5 || c.Gateway.ImageStreamKeepaliveInterval > 60) {
 		return fmt.Errorf("gateway.image_stream_keepalive_interval must be 0 or between 5-60 seconds")
 	}
+	for i, rule := range c.Gateway.ImageResultURLRewrites {
+		if err := ValidateAbsoluteHTTPURL(rule.From); err != nil {
+			return fmt.Errorf("gateway.image_result_url_rewrites[%d].from invalid: %w", i, err)
+		}
+		if err := ValidateAbsoluteHTTPURL(rule.To); err != nil {
+			return fmt.Errorf("gateway.image_result_url_rewrites[%d].to invalid: %w", i, err)
+		}
+	}
 	// 兼容旧键 sticky_previous_response_ttl_seconds
 	if c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds <= 0 && c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds > 0 {
 		c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds = c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds
@@ -2796,6 +3900,13 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("gateway.openai_ws.ingress_mode_default must be one of off|ctx_pool|passthrough|http_bridge")
 		}
 	}
+	if strategy := strings.ToLower(strings.TrimSpace(c.Gateway.OpenAISessionIDStrategy)); strategy != "" {
+		switch strategy {
+		case "prompt_cache_key", "api_key", "request":
+		default:
+			return fmt.Errorf("gateway.openai_session_id_strategy must be one of prompt_cache_key|api_key|request")
+		}
+	}
 	if mode := strings.ToLower(strings.TrimSpace(c.Gateway.OpenAIWS.StoreDisabledConnMode)); mode != "" {
 		switch mode {
 		case "strict", "adaptive", "off":
@@ -2809,6 +3920,9 @@ func (c *Config) Validate() error {
 	if c.Gateway.OpenAIWS.LBTopK <= 0 {
 		return fmt.Errorf("gateway.openai_ws.lb_top_k must be positive")
 	}
+	if c.Gateway.StickySessionTTLSeconds <= 0 {
+		return fmt.Errorf("gateway.sticky_session_ttl_seconds must be positive")
+	}
 	if c.Gateway.OpenAIWS.StickySessionTTLSeconds <= 0 {
 		return fmt.Errorf("gateway.openai_ws.sticky_session_ttl_seconds must be positive")
 	}
@@ -2827,6 +3941,24 @@ func (c *Config) Validate() error {
 	if c.Gateway.OpenAIHTTP2.FallbackTTLSeconds < 0 {
 		return fmt.Errorf("gateway.openai_http2.fallback_ttl_seconds must be non-negative")
 	}
+	if c.Gateway.OpenAIUpstreamRetry.Enabled {
+		retry := c.Gateway.OpenAIUpstreamRetry
+		if retry.MaxAttempts < 0 || retry.MaxAttempts > 5 {
+			return fmt.Errorf("gateway.openai_upstream_retry.max_attempts must be between 0 and 5")
+		}
+		if retry.BackoffInitialMS < 0 || retry.BackoffMaxMS < 0 {
+			return fmt.Errorf("gateway.openai_upstream_retry backoff settings must be non-negative")
+		}
+		if retry.BackoffMaxMS > 0 && retry.BackoffMaxMS < retry.BackoffInitialMS {
+			return fmt.Errorf("gateway.openai_upstream_retry.backoff_max_ms must be >= backoff_initial_ms")
+		}
+		if retry.JitterRatio < 0 || retry.JitterRatio > 1 {
+			return fmt.Errorf("gateway.openai_upstream_retry.jitter_ratio must be within [0,1]")
+		}
+		if retry.MaxRetryAfterSeconds < 0 {
+			return fmt.Errorf("gateway.openai_upstream_retry.max_retry_after_seconds must be non-negative")
+		}
+	}
 	if c.Gateway.OpenAIWS.SchedulerScoreWeights.Priority < 0 ||
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.Load < 0 ||
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.Queue < 0 ||
@@ -2858,6 +3990,24 @@ func (c *Config) Validate() error {
 	if c.Gateway.MaxLineSize != 0 && c.Gateway.MaxLineSize < 1024*1024 {
 		return fmt.Errorf("gateway.max_line_size must be at least 1MB")
 	}
+	if c.Gateway.APIKeyTrace.DefaultDurationMinutes < 0 {
+		return fmt.Errorf("gateway.api_key_trace.default_duration_minutes must be non-negative")
+	}
+	if c.Gateway.APIKeyTrace.MaxDurationMinutes < 0 {
+		return fmt.Errorf("gateway.api_key_trace.max_duration_minutes must be non-negative")
+	}
+	if c.Gateway.APIKeyTrace.MaxDurationMinutes > 0 && c.Gateway.APIKeyTrace.DefaultDurationMinutes > c.Gateway.APIKeyTrace.MaxDurationMinutes {
+		return fmt.Errorf("gateway.api_key_trace.default_duration_minutes must be <= max_duration_minutes")
+	}
+	if c.Gateway.APIKeyTrace.MaxRecords < 0 {
+		return fmt.Errorf("gateway.api_key_trace.max_records must be non-negative")
+	}
+	if c.Gateway.APIKeyTrace.MaxBodyBytes < 0 {
+		return fmt.Errorf("gateway.api_key_trace.max_body_bytes must be non-negative")
+	}
+	if c.Gateway.APIKeyTrace.RetentionHours < 0 {
+		return fmt.Errorf("gateway.api_key_trace.retention_hours must be non-negative")
+	}
 	if c.Gateway.UsageRecord.WorkerCount <= 0 {
 		return fmt.Errorf("gateway.usage_record.worker_count must be positive")
 	}
@@ -2922,6 +4072,9 @@ func (c *Config) Validate() error {
 	if c.Gateway.ModelsListCacheTTLSeconds < 10 || c.Gateway.ModelsListCacheTTLSeconds > 30 {
 		return fmt.Errorf("gateway.models_list_cache_ttl_seconds must be between 10-30")
 	}
+	if c.Gateway.UpstreamMetadataCacheTTLSeconds < 0 || c.Gateway.UpstreamMetadataCacheTTLSeconds > 86400 {
+		return fmt.Errorf("gateway.upstream_metadata_cache_ttl_seconds must be between 0-86400")
+	}
 	if c.Gateway.Scheduling.StickySessionMaxWaiting <= 0 {
 		return fmt.Errorf("gateway.scheduling.sticky_session_max_waiting must be positive")
 	}
@@ -2975,6 +4128,55 @@ func (c *Config) Validate() error {
 		c.Gateway.Scheduling.OutboxLagRebuildSeconds < c.Gateway.Scheduling.OutboxLagWarnSeconds {
 		return fmt.Errorf("gateway.scheduling.outbox_lag_rebuild_seconds must be >= outbox_lag_warn_seconds")
 	}
+	if fairness := c.Gateway.Scheduling.UserFairness; fairness.Enabled {
+		if fairness.HalfLifeSeconds <= 0 {
+			return fmt.Errorf("gateway.scheduling.user_fairness.half_life_seconds must be positive")
+		}
+		if fairness.MaxBackoffMultiplier < 1 {
+			return fmt.Errorf("gateway.scheduling.user_fairness.max_backoff_multiplier must be >= 1")
+		}
+		if fairness.MinActiveUsers < 0 {
+			return fmt.Errorf("gateway.scheduling.user_fairness.min_active_users must be non-negative")
+		}
+	}
+	if slo := c.Gateway.Scheduling.LatencySLO; slo.Enabled {
+		if slo.Percentile <= 0 || slo.Percentile >= 1 {
+			return fmt.Errorf("gateway.scheduling.latency_slo.percentile must be between 0 and 1")
+		}
+		if slo.ThresholdMs <= 0 {
+			return fmt.Errorf("gateway.scheduling.latency_slo.threshold_ms must be positive")
+		}
+		if slo.RecoverRatio <= 0 || slo.RecoverRatio > 1 {
+			return fmt.Errorf("gateway.scheduling.latency_slo.recover_ratio must be in (0, 1]")
+		}
+		if slo.WindowMinutes <= 0 {
+			return fmt.Errorf("gateway.scheduling.latency_slo.window_minutes must be positive")
+		}
+		if slo.MinSamples <= 0 {
+			return fmt.Errorf("gateway.scheduling.latency_slo.min_samples must be positive")
+		}
+	}
+	if windows := c.Gateway.Scheduling.UsageWindows; windows.Enabled {
+		if windows.NearLimitRatio <= 0 || windows.NearLimitRatio > 1 {
+			return fmt.Errorf("gateway.scheduling.usage_windows.near_limit_ratio must be in (0, 1]")
+		}
+		for plan, limits := range windows.Plans {
+			if limits.FiveHour < 0 || limits.Weekly < 0 {
+				return fmt.Errorf("gateway.scheduling.usage_windows.plans.%s limits must be non-negative", plan)
+			}
+		}
+	}
+	if reservation := c.Gateway.Scheduling.WindowCostReservation; reservation.Enabled {
+		if reservation.DefaultOutputTokens <= 0 {
+			return fmt.Errorf("gateway.scheduling.window_cost_reservation.default_output_tokens must be positive")
+		}
+		if reservation.MaxReservation < 0 {
+			return fmt.Errorf("gateway.scheduling.window_cost_reservation.max_reservation must be non-negative")
+		}
+		if reservation.ReservationTTLSeconds <= 0 {
+			return fmt.Errorf("gateway.scheduling.window_cost_reservation.reservation_ttl_seconds must be positive")
+		}
+	}
 	if c.Ops.MetricsCollectorCache.TTL < 0 {
 		return fmt.Errorf("ops.metrics_collector_cache.ttl must be non-negative")
 	}
@@ -2990,6 +4192,53 @@ func (c *Config) Validate() error {
 	if c.Ops.Cleanup.Enabled && strings.TrimSpace(c.Ops.Cleanup.Schedule) == "" {
 		return fmt.Errorf("ops.cleanup.schedule is required when ops.cleanup.enabled=true")
 	}
+	if c.Ops.StreamTimingSampleRate < 0 || c.Ops.StreamTimingSampleRate > 1 {
+		return fmt.Errorf("ops.stream_timing_sample_rate must be between 0 and 1")
+	}
+	if wd := c.Ops.Watchdog; wd.Enabled {
+		if wd.CheckIntervalSeconds <= 0 {
+			return fmt.Errorf("ops.watchdog.check_interval_seconds must be positive")
+		}
+		if wd.MaxInFlightRequests < 0 || wd.MaxHeapMB < 0 || wd.MaxGoroutines < 0 {
+			return fmt.Errorf("ops.watchdog thresholds must be non-negative")
+		}
+		if wd.CooldownMinutes < 0 {
+			return fmt.Errorf("ops.watchdog.cooldown_minutes must be non-negative")
+		}
+		if wd.MaxDumps <= 0 {
+			return fmt.Errorf("ops.watchdog.max_dumps must be positive")
+		}
+		if strings.TrimSpace(wd.DumpDir) == "" {
+			return fmt.Errorf("ops.watchdog.dump_dir is required when ops.watchdog.enabled=true")
+		}
+	}
+	if srl := c.Ops.SlowRequestLog; srl.Enabled {
+		if srl.TotalThresholdMs < 0 || srl.TTFTThresholdMs < 0 {
+			return fmt.Errorf("ops.slow_request_log thresholds must be non-negative")
+		}
+		for i, route := range srl.Routes {
+			if !strings.HasPrefix(strings.TrimSpace(route.PathPrefix), "/") {
+				return fmt.Errorf("ops.slow_request_log.routes[%d].path_prefix must start with /", i)
+			}
+			if route.TotalThresholdMs < 0 || route.TTFTThresholdMs < 0 {
+				return fmt.Errorf("ops.slow_request_log.routes[%d] thresholds must be non-negative", i)
+			}
+		}
+	}
+	if sd := c.Ops.SchemaDrift; sd.Enabled {
+		if sd.SampleRate <= 0 || sd.SampleRate > 1 {
+			return fmt.Errorf("ops.schema_drift.sample_rate must be between 0 (exclusive) and 1")
+		}
+		if sd.CheckIntervalSeconds <= 0 {
+			return fmt.Errorf("ops.schema_drift.check_interval_seconds must be positive")
+		}
+		if sd.MinSamples <= 0 {
+			return fmt.Errorf("ops.schema_drift.min_samples must be positive")
+		}
+		if sd.WarnCooldownMinutes < 0 {
+			return fmt.Errorf("ops.schema_drift.warn_cooldown_minutes must be non-negative")
+		}
+	}
 	if c.Concurrency.PingInterval < 5 || c.Concurrency.PingInterval > 30 {
 		return fmt.Errorf("concurrency.ping_interval must be between 5-30 seconds")
 	}
@@ -3152,3 +4401,53 @@ func warnIfInsecureURL(field, raw string) {
 		slog.Warn("url uses http scheme; use https in production to avoid token leakage", "field", field)
 	}
 }
+
+func (c ConfigSyncConfig) validate() error {
+	role := strings.ToLower(strings.TrimSpace(c.Role))
+	if strings.TrimSpace(c.UpstreamOverrideURL) != "" {
+		if role != ConfigSyncRoleMirror {
+			return fmt.Errorf("config_sync.upstream_override_url requires config_sync.role=mirror")
+		}
+		if err := ValidateAbsoluteHTTPURL(strings.TrimSpace(c.UpstreamOverrideURL)); err != nil {
+			return fmt.Errorf("config_sync.upstream_override_url invalid: %w", err)
+		}
+	}
+	switch role {
+	case "":
+		return nil
+	case ConfigSyncRolePrimary:
+	case ConfigSyncRoleReplica, ConfigSyncRoleMirror:
+		if err := ValidateAbsoluteHTTPURL(strings.TrimSpace(c.PrimaryURL)); err != nil {
+			return fmt.Errorf("config_sync.primary_url invalid: %w", err)
+		}
+		warnIfInsecureURL("config_sync.primary_url", c.PrimaryURL)
+		if c.IntervalSeconds <= 0 {
+			return fmt.Errorf("config_sync.interval_seconds must be positive")
+		}
+		if c.RequestTimeoutSeconds <= 0 {
+			return fmt.Errorf("config_sync.request_timeout_seconds must be positive")
+		}
+	default:
+		return fmt.Errorf("config_sync.role must be one of: primary/replica/mirror")
+	}
+	if len(strings.TrimSpace(c.Token)) < 32 {
+		return fmt.Errorf("config_sync.token must be at least 32 characters")
+	}
+	return nil
+}
+
+// IsPrimary 当前实例是否作为配置同步的 primary。
+func (c ConfigSyncConfig) IsPrimary() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Role), ConfigSyncRolePrimary)
+}
+
+// IsReplica 当前实例是否从 primary 拉取配置（replica 与 mirror，本地配置只读，由 primary 覆盖）。
+func (c ConfigSyncConfig) IsReplica() bool {
+	role := strings.TrimSpace(c.Role)
+	return strings.EqualFold(role, ConfigSyncRoleReplica) || strings.EqualFold(role, ConfigSyncRoleMirror)
+}
+
+// IsMirror 当前实例是否为预发镜像（只读生产配置，使用数据写入本地库）。
+func (c ConfigSyncConfig) IsMirror() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Role), ConfigSyncRoleMirror)
+}
@@ -201,6 +201,20 @@ func TestLoadOpenAICompactModelFromEnv(t *testing.T) {
 	require.Equal(t, "gpt-5.3-codex", cfg.Gateway.OpenAICompactModel)
 }
 
+func TestLoadConfigSyncMirrorForcesOpenAIHTTP(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	t.Setenv("CONFIG_SYNC_ROLE", "mirror")
+	t.Setenv("CONFIG_SYNC_TOKEN", strings.Repeat("t", 32))
+	t.Setenv("CONFIG_SYNC_PRIMARY_URL", "https://primary.example.com")
+	t.Setenv("CONFIG_SYNC_UPSTREAM_OVERRIDE_URL", "http://127.0.0.1:18080")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.True(t, cfg.ConfigSync.IsMirror())
+	require.True(t, cfg.ConfigSync.IsReplica())
+	require.True(t, cfg.Gateway.OpenAIWS.ForceHTTP)
+}
+
 func TestLoadDefaultOpenAIHTTP2Enabled(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
@@ -1552,6 +1566,54 @@ func TestValidateConfigErrors(t *testing.T) {
 			},
 			wantErr: "gateway.scheduling.outbox_lag_rebuild_seconds",
 		},
+		{
+			name: "gateway user fairness half life",
+			mutate: func(c *Config) {
+				c.Gateway.Scheduling.UserFairness.Enabled = true
+				c.Gateway.Scheduling.UserFairness.HalfLifeSeconds = 0
+			},
+			wantErr: "gateway.scheduling.user_fairness.half_life_seconds",
+		},
+		{
+			name: "gateway user fairness max backoff multiplier",
+			mutate: func(c *Config) {
+				c.Gateway.Scheduling.UserFairness.Enabled = true
+				c.Gateway.Scheduling.UserFairness.MaxBackoffMultiplier = 0.5
+			},
+			wantErr: "gateway.scheduling.user_fairness.max_backoff_multiplier",
+		},
+		{
+			name:    "config sync role invalid",
+			mutate:  func(c *Config) { c.ConfigSync.Role = "leader" },
+			wantErr: "config_sync.role",
+		},
+		{
+			name: "config sync replica primary url",
+			mutate: func(c *Config) {
+				c.ConfigSync.Role = ConfigSyncRoleReplica
+				c.ConfigSync.Token = strings.Repeat("t", 32)
+				c.ConfigSync.PrimaryURL = "primary.example.com"
+			},
+			wantErr: "config_sync.primary_url",
+		},
+		{
+			name: "config sync token too short",
+			mutate: func(c *Config) {
+				c.ConfigSync.Role = ConfigSyncRolePrimary
+				c.ConfigSync.Token = "short"
+			},
+			wantErr: "config_sync.token",
+		},
+		{
+			name: "config sync upstream override requires mirror",
+			mutate: func(c *Config) {
+				c.ConfigSync.Role = ConfigSyncRoleReplica
+				c.ConfigSync.Token = strings.Repeat("t", 32)
+				c.ConfigSync.PrimaryURL = "https://primary.example.com"
+				c.ConfigSync.UpstreamOverrideURL = "http://127.0.0.1:18080"
+			},
+			wantErr: "config_sync.upstream_override_url",
+		},
 		{
 			name:    "log level invalid",
 			mutate:  func(c *Config) { c.Log.Level = "trace" },
@@ -1598,6 +1660,27 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Ops.Cleanup.MinuteMetricsRetentionDays = -1 },
 			wantErr: "ops.cleanup.minute_metrics_retention_days",
 		},
+		{
+			name:    "ops stream timing sample rate",
+			mutate:  func(c *Config) { c.Ops.StreamTimingSampleRate = 1.5 },
+			wantErr: "ops.stream_timing_sample_rate",
+		},
+		{
+			name: "ops watchdog max dumps",
+			mutate: func(c *Config) {
+				c.Ops.Watchdog.Enabled = true
+				c.Ops.Watchdog.MaxDumps = 0
+			},
+			wantErr: "ops.watchdog.max_dumps",
+		},
+		{
+			name: "ops slow request log route prefix",
+			mutate: func(c *Config) {
+				c.Ops.SlowRequestLog.Enabled = true
+				c.Ops.SlowRequestLog.Routes = []OpsSlowRequestRouteConfig{{PathPrefix: "v1/messages", TotalThresholdMs: 1000}}
+			},
+			wantErr: "ops.slow_request_log.routes[0].path_prefix",
+		},
 	}
 
 	for _, tt := range cases {
@@ -1983,3 +2066,133 @@ func TestLoad_DefaultGatewayImageStreamConfig(t *testing.T) {
 		t.Fatalf("image stream timeout = %d, want greater than ordinary stream timeout %d", cfg.Gateway.ImageStreamDataIntervalTimeout, cfg.Gateway.StreamDataIntervalTimeout)
 	}
 }
+
+func TestValidateConfig_ReasoningEvents(t *testing.T) {
+	cases := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr string
+	}{
+		{
+			name:    "default_mode 非法",
+			mutate:  func(c *Config) { c.Gateway.ReasoningEvents.DefaultMode = "drop" },
+			wantErr: "gateway.reasoning_events.default_mode",
+		},
+		{
+			name: "画像正则非法",
+			mutate: func(c *Config) {
+				c.Gateway.ReasoningEvents.Profiles = []GatewayReasoningEventsProfile{{Name: "x", UserAgentPattern: "(", Mode: "filter"}}
+			},
+			wantErr: "profiles[0].user_agent_pattern is invalid",
+		},
+		{
+			name: "画像 mode 必填",
+			mutate: func(c *Config) {
+				c.Gateway.ReasoningEvents.Profiles = []GatewayReasoningEventsProfile{{Name: "x", UserAgentPattern: "^sdk/"}}
+			},
+			wantErr: "profiles[0].mode",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetViperWithJWTSecret(t)
+			cfg, err := Load()
+			require.NoError(t, err)
+			require.Equal(t, ReasoningEventsModePassthrough, cfg.Gateway.ReasoningEvents.DefaultMode)
+			tc.mutate(cfg)
+			err = cfg.Validate()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidatePricingSyncConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Pricing.ApprovalMode != PricingApprovalModeAuto {
+		t.Fatalf("Pricing.ApprovalMode = %q, want %q", cfg.Pricing.ApprovalMode, PricingApprovalModeAuto)
+	}
+
+	cfg.Pricing.ApprovalMode = "review"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "pricing.approval_mode") {
+		t.Fatalf("Validate() expected approval_mode error, got: %v", err)
+	}
+
+	cfg.Pricing.ApprovalMode = PricingApprovalModeManual
+	cfg.Pricing.PublicKey = "not-a-key"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "pricing.public_key") {
+		t.Fatalf("Validate() expected public_key error, got: %v", err)
+	}
+
+	cfg.Pricing.PublicKey = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	cfg.Pricing.SignatureURL = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "pricing.signature_url") {
+		t.Fatalf("Validate() expected signature_url error, got: %v", err)
+	}
+}
+
+func TestLoadTotpPreviousEncryptionKeysFromEnv(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	current := strings.Repeat("a", 64)
+	previous := strings.Repeat("b", 64)
+	t.Setenv("TOTP_ENCRYPTION_KEY", current)
+	t.Setenv("TOTP_PREVIOUS_ENCRYPTION_KEYS", previous+", "+current)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, []string{previous}, cfg.Totp.PreviousEncryptionKeys, "current key is dropped from the decrypt-only list")
+}
+
+func TestLoadTotpPreviousEncryptionKeysRejectsInvalidKey(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	t.Setenv("TOTP_ENCRYPTION_KEY", strings.Repeat("a", 64))
+	t.Setenv("TOTP_PREVIOUS_ENCRYPTION_KEYS", "not-hex")
+
+	_, err := Load()
+	require.ErrorContains(t, err, "totp.previous_encryption_keys")
+}
+
+func TestValidateAccessLogConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Log.Access.Enabled)
+	require.Equal(t, AccessLogFormatCombined, cfg.Log.Access.Format)
+
+	cfg.Log.Access.Enabled = true
+	cfg.Log.Access.Fields = []string{"request_id", "api_key_hash"}
+	require.ErrorContains(t, cfg.Validate(), "log.access.hash_salt")
+
+	cfg.Log.Access.HashSalt = strings.Repeat("s", 16)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Log.Access.Fields = []string{"api_key_id"}
+	require.ErrorContains(t, cfg.Validate(), "unknown field")
+
+	cfg.Log.Access.Fields = nil
+	cfg.Log.Access.Format = "nginx"
+	require.ErrorContains(t, cfg.Validate(), "log.access.format")
+}
+
+func TestValidateImageResultURLRewrites(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Empty(t, cfg.Gateway.ImageResultURLRewrites)
+
+	cfg.Gateway.ImageResultURLRewrites = []GatewayImageURLRewriteRule{
+		{From: "https://files.upstream.example/", To: "https://cdn.example.com/images/"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.ImageResultURLRewrites[0].To = "/images/"
+	require.ErrorContains(t, cfg.Validate(), "gateway.image_result_url_rewrites[0].to")
+
+	cfg.Gateway.ImageResultURLRewrites[0] = GatewayImageURLRewriteRule{To: "https://cdn.example.com/"}
+	require.ErrorContains(t, cfg.Validate(), "gateway.image_result_url_rewrites[0].from")
+}
@@ -0,0 +1,12 @@
+package domain
+
+// GroupAutoContinuationConfig configures per-group automatic continuation of
+// non-streaming responses that stop because they hit the output token limit.
+// The gateway re-issues the request with the partial output as an assistant
+// prefill and stitches the pieces into one response.
+type GroupAutoContinuationConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxContinuations bounds the follow-up requests issued for a single
+	// response. Zero uses the built-in default.
+	MaxContinuations int `json:"max_continuations,omitempty"`
+}
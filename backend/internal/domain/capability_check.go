@@ -0,0 +1,12 @@
+package domain
+
+// GroupCapabilityCheckConfig configures per-group checks of request content
+// (images, audio, tools, reasoning) against the capabilities of the mapped
+// model before routing.
+type GroupCapabilityCheckConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// FallbackModels maps a requested model (exact or trailing "*" wildcard)
+	// to a model used instead when the request needs a capability the
+	// requested model lacks. Without a usable fallback the request is rejected.
+	FallbackModels map[string]string `json:"fallback_models,omitempty"`
+}
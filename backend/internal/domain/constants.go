@@ -8,6 +8,8 @@ const (
 	StatusUnused   = "unused"
 	StatusUsed     = "used"
 	StatusExpired  = "expired"
+	// StatusArchived 账号已归档：不参与调度，凭证加密保留
+	StatusArchived = "archived"
 )
 
 // Role constants
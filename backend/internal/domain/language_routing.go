@@ -0,0 +1,22 @@
+package domain
+
+// GroupLanguageRoutingConfig configures per-group routing based on the
+// detected primary language of the prompt.
+type GroupLanguageRoutingConfig struct {
+	// Rules are evaluated in order; the first rule whose languages contain the
+	// detected language wins.
+	Rules []LanguageRoutingRule `json:"rules,omitempty"`
+}
+
+// LanguageRoutingRule routes prompts in the given languages to a different
+// model and/or a preferred set of accounts.
+type LanguageRoutingRule struct {
+	// Languages are ISO 639-1 codes such as "zh", "ja" or "en".
+	Languages []string `json:"languages"`
+	// Model, when set, replaces the requested model.
+	Model string `json:"model,omitempty"`
+	// AccountIDs and ProxyIDs select the preferred accounts: an account is
+	// preferred when its ID is listed or it uses one of the listed proxies.
+	AccountIDs []int64 `json:"account_ids,omitempty"`
+	ProxyIDs   []int64 `json:"proxy_ids,omitempty"`
+}
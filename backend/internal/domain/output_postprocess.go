@@ -0,0 +1,19 @@
+package domain
+
+// GroupOutputPostprocessConfig configures per-group rewriting of the final
+// output text (JSON bodies and streaming text deltas).
+type GroupOutputPostprocessConfig struct {
+	Rules []OutputPostprocessRule `json:"rules,omitempty"`
+	// Attribution is appended as a trailing text block to completed responses
+	// (skipped when the model stops for a tool call).
+	Attribution string `json:"attribution,omitempty"`
+}
+
+// OutputPostprocessRule is a single rewrite rule.
+type OutputPostprocessRule struct {
+	// Type is "regex" (RE2 pattern, replacement supports $1 expansion) or
+	// "banned_phrase" (case-insensitive literal, replaced verbatim).
+	Type        string `json:"type"`
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement,omitempty"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+// GroupStreamReplayConfig configures per-group buffering of recent SSE events
+// so a client that drops mid-stream can reconnect with Last-Event-ID and
+// resume without re-running the generation.
+type GroupStreamReplayConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxEvents and MaxBytes bound the buffer of a single response; the oldest
+	// events are dropped first. Zero uses the built-in default.
+	MaxEvents int `json:"max_events,omitempty"`
+	MaxBytes  int `json:"max_bytes,omitempty"`
+	// RetentionSeconds is how long a finished response stays replayable.
+	RetentionSeconds int `json:"retention_seconds,omitempty"`
+}
@@ -2427,6 +2427,44 @@ func (h *AccountHandler) GetAvailableModels(c *gin.Context) {
 	response.Success(c, models)
 }
 
+// TestModelMappingRequest represents the request body for testing model mapping.
+// ModelMapping / ModelMappingRules are optional drafts that replace the saved configuration for this test only.
+type TestModelMappingRequest struct {
+	Model             string         `json:"model" binding:"required"`
+	ModelMapping      map[string]any `json:"model_mapping"`
+	ModelMappingRules []any          `json:"model_mapping_rules"`
+}
+
+// TestModelMapping shows which mapping rule a model hits on an account
+// POST /api/v1/admin/accounts/:id/model-mapping/test
+func (h *AccountHandler) TestModelMapping(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	var req TestModelMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	account, err := h.adminService.GetAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.NotFound(c, "Account not found")
+		return
+	}
+
+	account, err = account.WithModelMappingDraft(req.ModelMapping, req.ModelMappingRules)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, account.ExplainModelMapping(strings.TrimSpace(req.Model)))
+}
+
 // SyncUpstreamModels handles syncing live supported models from an account's upstream.
 // POST /api/v1/admin/accounts/:id/models/sync-upstream
 func (h *AccountHandler) SyncUpstreamModels(c *gin.Context) {
@@ -2469,6 +2507,36 @@ func (h *AccountHandler) SyncUpstreamModels(c *gin.Context) {
 	response.Success(c, gin.H{"models": models})
 }
 
+// Verify handles an on-demand credential check (token validity, tier, scopes, accessible models).
+// The result is stored in the account's extra.credential_check for the UI.
+// POST /api/v1/admin/accounts/:id/verify
+func (h *AccountHandler) Verify(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	account, err := h.adminService.GetAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.NotFound(c, "Account not found")
+		return
+	}
+
+	if h.accountTestService == nil {
+		response.InternalError(c, "Account test service is not configured")
+		return
+	}
+
+	check, err := h.accountTestService.VerifyAccountCredentials(c.Request.Context(), account)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, check)
+}
+
 // SyncUpstreamModelsPreview handles syncing live supported models using provided credentials (no account ID needed).
 // POST /api/v1/admin/accounts/models/sync-upstream-preview
 func (h *AccountHandler) SyncUpstreamModelsPreview(c *gin.Context) {
@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountLeaseHandler handles account checkout/checkin for external schedulers
+type AccountLeaseHandler struct {
+	leaseService *service.AccountLeaseService
+}
+
+// NewAccountLeaseHandler creates a new admin account lease handler
+func NewAccountLeaseHandler(leaseService *service.AccountLeaseService) *AccountLeaseHandler {
+	return &AccountLeaseHandler{leaseService: leaseService}
+}
+
+type checkoutAccountRequest struct {
+	Holder     string `json:"holder" binding:"required,max=100"`
+	Note       string `json:"note" binding:"max=255"`
+	TTLSeconds int    `json:"ttl_seconds" binding:"gte=0"`
+}
+
+type renewAccountLeaseRequest struct {
+	TTLSeconds int `json:"ttl_seconds" binding:"gte=0"`
+}
+
+// List returns all current account leases
+// GET /api/v1/admin/accounts/leases
+func (h *AccountLeaseHandler) List(c *gin.Context) {
+	leases, err := h.leaseService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, leases)
+}
+
+// Checkout leases an account exclusively; the internal scheduler skips it until checkin or expiry
+// POST /api/v1/admin/accounts/:id/lease
+func (h *AccountLeaseHandler) Checkout(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+	var req checkoutAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+
+	lease, err := h.leaseService.Checkout(c.Request.Context(), accountID, service.AccountLeaseInput{
+		Holder: req.Holder,
+		Note:   req.Note,
+		TTL:    time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, lease)
+}
+
+// Renew extends an active lease
+// POST /api/v1/admin/accounts/leases/:lease_id/renew
+func (h *AccountLeaseHandler) Renew(c *gin.Context) {
+	var req renewAccountLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+	lease, err := h.leaseService.Renew(c.Request.Context(), c.Param("lease_id"), time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, lease)
+}
+
+// Checkin releases a lease and restores the account's previous schedulable flag
+// DELETE /api/v1/admin/accounts/leases/:lease_id
+func (h *AccountLeaseHandler) Checkin(c *gin.Context) {
+	lease, err := h.leaseService.Checkin(c.Request.Context(), c.Param("lease_id"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, lease)
+}
@@ -323,6 +323,22 @@ func (s *stubAdminService) BatchSetGroupRPMOverrides(_ context.Context, _ int64,
 	return nil
 }
 
+func (s *stubAdminService) GetGroupAccountTiers(_ context.Context, _ int64) ([]service.GroupAccountTier, error) {
+	return nil, nil
+}
+
+func (s *stubAdminService) BatchSetGroupAccountTiers(_ context.Context, _ int64, _ []service.GroupAccountTier) error {
+	return nil
+}
+
+func (s *stubAdminService) GetGroupAccountConcurrencyShares(_ context.Context, _ int64) ([]service.GroupAccountConcurrencyShare, error) {
+	return nil, nil
+}
+
+func (s *stubAdminService) BatchSetGroupAccountConcurrencyShares(_ context.Context, _ int64, _ []service.GroupAccountConcurrencyShare) error {
+	return nil
+}
+
 func (s *stubAdminService) ListAccounts(ctx context.Context, page, pageSize int, platform, accountType, status, search string, groupID int64, privacyMode string, sortBy, sortOrder string) ([]service.Account, int64, error) {
 	s.lastListAccounts.platform = platform
 	s.lastListAccounts.accountType = accountType
@@ -648,6 +664,17 @@ func (s *stubAdminService) AdminUpdateAPIKeyGroupID(ctx context.Context, keyID i
 	return nil, service.ErrAPIKeyNotFound
 }
 
+func (s *stubAdminService) AdminSetAPIKeyCritical(ctx context.Context, keyID int64, critical bool) (*service.APIKey, error) {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			s.apiKeys[i].Critical = critical
+			k := s.apiKeys[i]
+			return &k, nil
+		}
+	}
+	return nil, service.ErrAPIKeyNotFound
+}
+
 func (s *stubAdminService) AdminResetAPIKeyRateLimitUsage(ctx context.Context, keyID int64) (*service.APIKey, error) {
 	for i := range s.apiKeys {
 		if s.apiKeys[i].ID == keyID {
@@ -26,6 +26,7 @@ func NewAdminAPIKeyHandler(adminService service.AdminService) *AdminAPIKeyHandle
 type AdminUpdateAPIKeyGroupRequest struct {
 	GroupID             *int64 `json:"group_id"`               // nil=不修改, 0=解绑, >0=绑定到目标分组
 	ResetRateLimitUsage *bool  `json:"reset_rate_limit_usage"` // true=重置 5h/1d/7d 限速用量
+	Critical            *bool  `json:"critical"`               // nil=不修改, true=可占用分组预留并发
 }
 
 // UpdateGroup handles updating an API key's admin-managed fields.
@@ -51,6 +52,13 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 			return
 		}
 	}
+	if req.Critical != nil {
+		resetKey, err = h.adminService.AdminSetAPIKeyCritical(c.Request.Context(), keyID, *req.Critical)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
 
 	result, err := h.adminService.AdminUpdateAPIKeyGroupID(c.Request.Context(), keyID, req.GroupID)
 	if err != nil {
@@ -0,0 +1,139 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyTraceHandler 单个 API Key 的限时请求/响应追踪。
+type APIKeyTraceHandler struct {
+	traceService *service.APIKeyTraceService
+}
+
+// NewAPIKeyTraceHandler creates a new API key trace handler
+func NewAPIKeyTraceHandler(traceService *service.APIKeyTraceService) *APIKeyTraceHandler {
+	return &APIKeyTraceHandler{traceService: traceService}
+}
+
+// StartAPIKeyTraceRequest 开启追踪请求；DurationMinutes 为 0 时使用配置的默认时长。
+type StartAPIKeyTraceRequest struct {
+	DurationMinutes int    `json:"duration_minutes" binding:"omitempty,min=1"`
+	Reason          string `json:"reason" binding:"omitempty,max=200"`
+}
+
+// Start 对 API Key 开启限时追踪。
+// POST /api/v1/admin/api-keys/:id/trace
+func (h *APIKeyTraceHandler) Start(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+	var req StartAPIKeyTraceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	var startedBy int64
+	if subject, ok := middleware2.GetAuthSubjectFromContext(c); ok {
+		startedBy = subject.UserID
+	}
+	session, err := h.traceService.StartTrace(
+		c.Request.Context(),
+		keyID,
+		time.Duration(req.DurationMinutes)*time.Minute,
+		strings.TrimSpace(req.Reason),
+		startedBy,
+	)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, session)
+}
+
+// Stop 提前结束追踪。
+// DELETE /api/v1/admin/api-keys/:id/trace
+func (h *APIKeyTraceHandler) Stop(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+	if err := h.traceService.StopTrace(c.Request.Context(), keyID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Trace stopped"})
+}
+
+// Get 查看追踪状态与最近抓取的记录。
+// GET /api/v1/admin/api-keys/:id/trace?limit=50
+func (h *APIKeyTraceHandler) Get(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	session, records, err := h.traceService.GetTrace(c.Request.Context(), keyID, limit)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"session": session,
+		"records": records,
+	})
+}
+
+// ListActive 列出所有进行中的追踪。
+// GET /api/v1/admin/api-keys/traces
+func (h *APIKeyTraceHandler) ListActive(c *gin.Context) {
+	sessions, err := h.traceService.ListActiveTraces(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, sessions)
+}
+
+// UpdateAPIKeyTraceRedactionRulesRequest 整体替换自定义脱敏规则。
+type UpdateAPIKeyTraceRedactionRulesRequest struct {
+	Rules []service.APIKeyTraceRedactionRule `json:"rules"`
+}
+
+// GetRedactionRules 查看追踪记录落库前应用的脱敏规则。
+// GET /api/v1/admin/api-keys/trace-redaction-rules
+func (h *APIKeyTraceHandler) GetRedactionRules(c *gin.Context) {
+	settings, err := h.traceService.GetRedactionSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, settings)
+}
+
+// UpdateRedactionRules 更新自定义脱敏规则。
+// PUT /api/v1/admin/api-keys/trace-redaction-rules
+func (h *APIKeyTraceHandler) UpdateRedactionRules(c *gin.Context) {
+	var req UpdateAPIKeyTraceRedactionRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	settings, err := h.traceService.UpdateRedactionRules(c.Request.Context(), req.Rules)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, settings)
+}
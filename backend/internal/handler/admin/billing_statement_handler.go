@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// BillingStatementHandler handles admin billing statement management.
+type BillingStatementHandler struct {
+	statementService *service.BillingStatementService
+}
+
+// NewBillingStatementHandler creates a new admin BillingStatementHandler.
+func NewBillingStatementHandler(statementService *service.BillingStatementService) *BillingStatementHandler {
+	return &BillingStatementHandler{statementService: statementService}
+}
+
+type generateBillingStatementsRequest struct {
+	Period string `json:"period" binding:"required"` // YYYY-MM
+}
+
+// List GET /admin/billing/statements?user_id=&period=YYYY-MM&status=
+func (h *BillingStatementHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+
+	var filter service.BillingStatementFilter
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid user ID")
+			return
+		}
+		filter.UserID = userID
+	}
+	if raw := strings.TrimSpace(c.Query("period")); raw != "" {
+		start, _, err := service.ParseBillingStatementPeriod(raw)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		filter.PeriodStart = &start
+	}
+	filter.Status = strings.TrimSpace(c.Query("status"))
+
+	items, result, err := h.statementService.List(c.Request.Context(), params, filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, items, result.Total, page, pageSize)
+}
+
+// Get GET /admin/billing/statements/:id
+func (h *BillingStatementHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+	st, err := h.statementService.Get(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, st)
+}
+
+// Generate POST /admin/billing/statements/generate
+func (h *BillingStatementHandler) Generate(c *gin.Context) {
+	var req generateBillingStatementsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	created, err := h.statementService.Generate(c.Request.Context(), req.Period)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"created": created})
+}
+
+// Push POST /admin/billing/statements/:id/push
+func (h *BillingStatementHandler) Push(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+	st, err := h.statementService.PushToStripe(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, st)
+}
+
+// Void POST /admin/billing/statements/:id/void
+func (h *BillingStatementHandler) Void(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+	st, err := h.statementService.Void(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, st)
+}
@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheEfficiencyHandler handles the admin dashboard prompt cache efficiency report.
+type CacheEfficiencyHandler struct {
+	cacheEfficiencyService *service.CacheEfficiencyService
+}
+
+// NewCacheEfficiencyHandler creates a new CacheEfficiencyHandler.
+func NewCacheEfficiencyHandler(cacheEfficiencyService *service.CacheEfficiencyService) *CacheEfficiencyHandler {
+	return &CacheEfficiencyHandler{cacheEfficiencyService: cacheEfficiencyService}
+}
+
+// GetReport handles the prompt cache hit-rate report
+// GET /api/v1/admin/dashboard/cache-efficiency?dimension=model|api_key|account&days=7&limit=50
+func (h *CacheEfficiencyHandler) GetReport(c *gin.Context) {
+	days, ok := parseOptionalPositiveInt(c, "days")
+	if !ok {
+		return
+	}
+	limit, ok := parseOptionalPositiveInt(c, "limit")
+	if !ok {
+		return
+	}
+
+	report, err := h.cacheEfficiencyService.Report(c.Request.Context(), c.Query("dimension"), days, limit)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
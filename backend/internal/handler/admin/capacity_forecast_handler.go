@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CapacityForecastHandler handles the admin dashboard capacity forecast.
+type CapacityForecastHandler struct {
+	forecastService *service.CapacityForecastService
+}
+
+// NewCapacityForecastHandler creates a new CapacityForecastHandler.
+func NewCapacityForecastHandler(forecastService *service.CapacityForecastService) *CapacityForecastHandler {
+	return &CapacityForecastHandler{forecastService: forecastService}
+}
+
+// GetForecast handles the account capacity forecast
+// GET /api/v1/admin/dashboard/capacity-forecast?lookback_days=28&horizon_days=30
+func (h *CapacityForecastHandler) GetForecast(c *gin.Context) {
+	lookbackDays, ok := parseOptionalPositiveInt(c, "lookback_days")
+	if !ok {
+		return
+	}
+	horizonDays, ok := parseOptionalPositiveInt(c, "horizon_days")
+	if !ok {
+		return
+	}
+
+	forecast, err := h.forecastService.Forecast(c.Request.Context(), lookbackDays, horizonDays)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, forecast)
+}
+
+func parseOptionalPositiveInt(c *gin.Context, key string) (int, bool) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v <= 0 {
+		response.BadRequest(c, "Invalid "+key)
+		return 0, false
+	}
+	return v, true
+}
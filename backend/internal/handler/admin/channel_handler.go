@@ -533,3 +533,54 @@ func (h *ChannelHandler) SyncPricingModels(c *gin.Context) {
 	models := h.pricingService.ListModelNamesByProvider(provider)
 	response.Success(c, gin.H{"models": models})
 }
+
+// GetPricingSyncStatus 返回远程定价数据的同步状态（含手动审批模式下的待审批更新）
+// GET /api/v1/admin/channels/pricing/sync-status
+func (h *ChannelHandler) GetPricingSyncStatus(c *gin.Context) {
+	response.Success(c, h.pricingService.GetSyncStatus())
+}
+
+// TriggerPricingSync 立即从远程拉取定价数据；手动审批模式下仅暂存为待审批更新
+// POST /api/v1/admin/channels/pricing/sync
+func (h *ChannelHandler) TriggerPricingSync(c *gin.Context) {
+	if err := h.pricingService.ForceUpdate(); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, h.pricingService.GetSyncStatus())
+}
+
+type pendingPricingActionRequest struct {
+	// Hash 管理员审阅过的待审批版本哈希，不一致时拒绝操作
+	Hash string `json:"hash" binding:"required"`
+}
+
+// ApprovePendingPricing 批准待审批的定价更新
+// POST /api/v1/admin/channels/pricing/pending/approve
+func (h *ChannelHandler) ApprovePendingPricing(c *gin.Context) {
+	var req pendingPricingActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+	if _, err := h.pricingService.ApprovePendingPricing(req.Hash); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, h.pricingService.GetSyncStatus())
+}
+
+// RejectPendingPricing 驳回待审批的定价更新
+// POST /api/v1/admin/channels/pricing/pending/reject
+func (h *ChannelHandler) RejectPendingPricing(c *gin.Context) {
+	var req pendingPricingActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+	if err := h.pricingService.RejectPendingPricing(req.Hash); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, h.pricingService.GetSyncStatus())
+}
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientAnalyticsHandler handles the admin dashboard client / origin analytics report.
+type ClientAnalyticsHandler struct {
+	clientAnalyticsService *service.ClientAnalyticsService
+}
+
+// NewClientAnalyticsHandler creates a new ClientAnalyticsHandler.
+func NewClientAnalyticsHandler(clientAnalyticsService *service.ClientAnalyticsService) *ClientAnalyticsHandler {
+	return &ClientAnalyticsHandler{clientAnalyticsService: clientAnalyticsService}
+}
+
+// GetReport handles the traffic breakdown by client profile, client version or origin network
+// GET /api/v1/admin/dashboard/client-analytics?dimension=client|client_version|origin&api_key_id=1&days=7&limit=50
+func (h *ClientAnalyticsHandler) GetReport(c *gin.Context) {
+	var apiKeyID int64
+	if raw := strings.TrimSpace(c.Query("api_key_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid api_key_id")
+			return
+		}
+		apiKeyID = id
+	}
+	days, ok := parseOptionalPositiveInt(c, "days")
+	if !ok {
+		return
+	}
+	limit, ok := parseOptionalPositiveInt(c, "limit")
+	if !ok {
+		return
+	}
+
+	report, err := h.clientAnalyticsService.Report(c.Request.Context(), c.Query("dimension"), apiKeyID, days, limit)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
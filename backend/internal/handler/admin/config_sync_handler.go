@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigSyncHandler 跨地域配置主从同步接口。
+type ConfigSyncHandler struct {
+	configSyncService *service.ConfigSyncService
+}
+
+func NewConfigSyncHandler(configSyncService *service.ConfigSyncService) *ConfigSyncHandler {
+	return &ConfigSyncHandler{configSyncService: configSyncService}
+}
+
+// Snapshot 供 replica 拉取配置快照（共享令牌鉴权，不走管理员 JWT）。
+// GET /api/v1/config-sync/snapshot
+func (h *ConfigSyncHandler) Snapshot(c *gin.Context) {
+	token := strings.TrimSpace(c.GetHeader("Authorization"))
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+		token = strings.TrimSpace(token[7:])
+	} else {
+		token = ""
+	}
+	if err := h.configSyncService.VerifyToken(token); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	snapshot, err := h.configSyncService.ExportSnapshot(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, snapshot)
+}
+
+// GetStatus 查看配置同步状态。
+// GET /api/v1/admin/config-sync/status
+func (h *ConfigSyncHandler) GetStatus(c *gin.Context) {
+	response.Success(c, h.configSyncService.Status())
+}
+
+// RunSync 立即从 primary 拉取一次配置（仅 replica）。
+// POST /api/v1/admin/config-sync/run
+func (h *ConfigSyncHandler) RunSync(c *gin.Context) {
+	if _, err := h.configSyncService.SyncOnce(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, h.configSyncService.Status())
+}
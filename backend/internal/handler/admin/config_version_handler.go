@@ -0,0 +1,205 @@
+package admin
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// configVersionCaptureLimit 创建接口响应体的捕获上限，只用于读取 data.id。
+const configVersionCaptureLimit = 64 << 10
+
+// ConfigVersionHandler 管理端配置变更历史：版本列表、差异对比与回滚。
+type ConfigVersionHandler struct {
+	configVersionService *service.ConfigVersionService
+}
+
+// NewConfigVersionHandler creates a new ConfigVersionHandler
+func NewConfigVersionHandler(configVersionService *service.ConfigVersionService) *ConfigVersionHandler {
+	return &ConfigVersionHandler{configVersionService: configVersionService}
+}
+
+// configVersionBodyCapture 缓存创建接口的响应体，以便在成功后取出新实体 ID。
+type configVersionBodyCapture struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *configVersionBodyCapture) Write(b []byte) (int, error) {
+	if w.body.Len()+len(b) <= configVersionCaptureLimit {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *configVersionBodyCapture) WriteString(s string) (int, error) {
+	if w.body.Len()+len(s) <= configVersionCaptureLimit {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Track 挂在实体的创建/更新/删除路由上：变更前补录基线，变更成功（2xx）后记录新版本。
+// 版本记录失败只记日志，不影响原接口结果。
+func (h *ConfigVersionHandler) Track(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil || h.configVersionService == nil {
+			c.Next()
+			return
+		}
+
+		operatorID := configVersionOperatorID(c)
+		entityID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+		action := service.ConfigVersionActionUpdate
+		switch {
+		case entityID <= 0:
+			action = service.ConfigVersionActionCreate
+		case c.Request.Method == http.MethodDelete:
+			action = service.ConfigVersionActionDelete
+		}
+
+		var capture *configVersionBodyCapture
+		if action == service.ConfigVersionActionCreate {
+			capture = &configVersionBodyCapture{ResponseWriter: c.Writer}
+			c.Writer = capture
+		} else if err := h.configVersionService.EnsureBaseline(c.Request.Context(), entityType, entityID, operatorID); err != nil {
+			slog.Warn("config version baseline failed", "entity_type", entityType, "entity_id", entityID, "error", err)
+		}
+
+		c.Next()
+
+		if status := c.Writer.Status(); status < 200 || status >= 300 {
+			return
+		}
+		if capture != nil {
+			entityID = gjson.GetBytes(capture.body.Bytes(), "data.id").Int()
+			if entityID <= 0 {
+				return
+			}
+		}
+		if _, err := h.configVersionService.Capture(c.Request.Context(), entityType, entityID, action, operatorID); err != nil {
+			slog.Warn("config version capture failed", "entity_type", entityType, "entity_id", entityID, "action", action, "error", err)
+		}
+	}
+}
+
+// List handles listing versions of a config entity
+// GET /api/v1/admin/config-versions/:type/:id
+func (h *ConfigVersionHandler) List(c *gin.Context) {
+	entityType, entityID, ok := parseConfigVersionEntity(c)
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+
+	items, result, err := h.configVersionService.List(c.Request.Context(), params, entityType, entityID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, items, result.Total, page, pageSize)
+}
+
+// Get handles getting a single version snapshot
+// GET /api/v1/admin/config-versions/:type/:id/versions/:version
+func (h *ConfigVersionHandler) Get(c *gin.Context) {
+	entityType, entityID, ok := parseConfigVersionEntity(c)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		response.BadRequest(c, "Invalid version")
+		return
+	}
+
+	v, err := h.configVersionService.Get(c.Request.Context(), entityType, entityID, version)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, v)
+}
+
+// Diff handles comparing two versions; to defaults to the latest version
+// GET /api/v1/admin/config-versions/:type/:id/diff?from=1&to=3
+func (h *ConfigVersionHandler) Diff(c *gin.Context) {
+	entityType, entityID, ok := parseConfigVersionEntity(c)
+	if !ok {
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from <= 0 {
+		response.BadRequest(c, "Invalid from version")
+		return
+	}
+	to := 0
+	if raw := c.Query("to"); raw != "" {
+		to, err = strconv.Atoi(raw)
+		if err != nil || to <= 0 {
+			response.BadRequest(c, "Invalid to version")
+			return
+		}
+	}
+
+	diff, err := h.configVersionService.Diff(c.Request.Context(), entityType, entityID, from, to)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, diff)
+}
+
+// Rollback handles restoring an entity to a prior version
+// POST /api/v1/admin/config-versions/:type/:id/versions/:version/rollback
+func (h *ConfigVersionHandler) Rollback(c *gin.Context) {
+	entityType, entityID, ok := parseConfigVersionEntity(c)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		response.BadRequest(c, "Invalid version")
+		return
+	}
+
+	v, err := h.configVersionService.Rollback(c.Request.Context(), entityType, entityID, version, configVersionOperatorID(c))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, v)
+}
+
+func parseConfigVersionEntity(c *gin.Context) (string, int64, bool) {
+	entityType := c.Param("type")
+	if !service.IsValidConfigEntityType(entityType) {
+		response.BadRequest(c, "Invalid entity type")
+		return "", 0, false
+	}
+	entityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || entityID <= 0 {
+		response.BadRequest(c, "Invalid entity ID")
+		return "", 0, false
+	}
+	return entityType, entityID, true
+}
+
+func configVersionOperatorID(c *gin.Context) *int64 {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		return nil
+	}
+	id := subject.UserID
+	return &id
+}
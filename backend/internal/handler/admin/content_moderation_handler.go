@@ -123,6 +123,29 @@ func (h *ContentModerationHandler) UpdateConfig(c *gin.Context) {
 	response.Success(c, cfg)
 }
 
+func (h *ContentModerationHandler) GetIntrusionConfig(c *gin.Context) {
+	cfg, err := h.service.GetIntrusionDetectionConfig(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, cfg)
+}
+
+func (h *ContentModerationHandler) UpdateIntrusionConfig(c *gin.Context) {
+	var req service.UpdateIntrusionDetectionConfigInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	cfg, err := h.service.UpdateIntrusionDetectionConfig(c.Request.Context(), req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, cfg)
+}
+
 func (h *ContentModerationHandler) TestAPIKeys(c *gin.Context) {
 	var req contentModerationAPIKeyTestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
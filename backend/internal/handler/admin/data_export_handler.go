@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DataExportHandler handles streaming CSV/JSONL exports of raw usage and error logs
+type DataExportHandler struct {
+	dataExportService *service.DataExportService
+}
+
+// NewDataExportHandler creates a new admin data export handler
+func NewDataExportHandler(dataExportService *service.DataExportService) *DataExportHandler {
+	return &DataExportHandler{dataExportService: dataExportService}
+}
+
+// ExportUsageLogs streams raw usage logs
+// GET /api/v1/admin/usage/export?format=csv|jsonl&start_date=&end_date=&timezone=&user_id=&api_key_id=&group_id=&account_id=&model=
+func (h *DataExportHandler) ExportUsageLogs(c *gin.Context) {
+	userTZ := c.Query("timezone")
+	startDate := strings.TrimSpace(c.Query("start_date"))
+	endDate := strings.TrimSpace(c.Query("end_date"))
+	if startDate == "" || endDate == "" {
+		response.BadRequest(c, "start_date and end_date are required")
+		return
+	}
+	startTime, err := timezone.ParseInUserLocation("2006-01-02", startDate, userTZ)
+	if err != nil {
+		response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+		return
+	}
+	endTime, err := timezone.ParseInUserLocation("2006-01-02", endDate, userTZ)
+	if err != nil {
+		response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+		return
+	}
+
+	filter := service.DataExportFilter{
+		StartTime: startTime,
+		// end_date is inclusive: move to next calendar day start (DST-safe)
+		EndTime: endTime.AddDate(0, 0, 1),
+		Model:   c.Query("model"),
+	}
+	if !parseDataExportIDs(c, &filter) {
+		return
+	}
+
+	h.stream(c, "usage_logs", filter, h.dataExportService.ExportUsageLogs)
+}
+
+// ExportOpsErrors streams raw ops error logs
+// GET /api/v1/admin/ops/errors/export?format=csv|jsonl&start_time=&end_time=&time_range=&platform=&phase=&error_owner=&status_code=&user_id=&api_key_id=&group_id=&account_id=&model=
+func (h *DataExportHandler) ExportOpsErrors(c *gin.Context) {
+	startTime, endTime, err := parseOpsTimeRange(c, "24h")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filter := service.DataExportFilter{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Model:     c.Query("model"),
+		Platform:  c.Query("platform"),
+		Phase:     c.Query("phase"),
+		Owner:     c.Query("error_owner"),
+	}
+	if v := strings.TrimSpace(c.Query("status_code")); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil || code <= 0 {
+			response.BadRequest(c, "Invalid status_code")
+			return
+		}
+		filter.StatusCode = code
+	}
+	if !parseDataExportIDs(c, &filter) {
+		return
+	}
+
+	h.stream(c, "ops_errors", filter, h.dataExportService.ExportOpsErrors)
+}
+
+type dataExportFunc func(ctx context.Context, w io.Writer, format string, filter service.DataExportFilter) (int64, error)
+
+// stream validates the request, then writes the export directly to the response in chunks.
+// Once the first bytes are sent the status can no longer change, so later failures are only logged
+// and the truncated body is left for the client to detect.
+func (h *DataExportHandler) stream(c *gin.Context, name string, filter service.DataExportFilter, export dataExportFunc) {
+	format, err := service.NormalizeDataExportRequest(c.Query("format"), &filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == service.DataExportFormatJSONL {
+		contentType = "application/x-ndjson; charset=utf-8"
+	}
+	filename := fmt.Sprintf("%s_%s_%s.%s", name,
+		filter.StartTime.UTC().Format("20060102T150405Z"), filter.EndTime.UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Accel-Buffering", "no")
+
+	written, err := export(c.Request.Context(), c.Writer, format, filter)
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		response.ErrorFrom(c, err)
+		return
+	}
+	logger.LegacyPrintf("handler.admin.data_export", "[DataExport] %s export aborted after %d rows: %v", name, written, err)
+}
+
+// parseDataExportIDs parses the optional user/api key/group/account id filters
+func parseDataExportIDs(c *gin.Context, filter *service.DataExportFilter) bool {
+	for _, f := range []struct {
+		key  string
+		dest *int64
+	}{
+		{"user_id", &filter.UserID},
+		{"api_key_id", &filter.APIKeyID},
+		{"group_id", &filter.GroupID},
+		{"account_id", &filter.AccountID},
+	} {
+		v := strings.TrimSpace(c.Query(f.key))
+		if v == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid "+f.key)
+			return false
+		}
+		*f.dest = id
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	debugMinGCPercent        = 10
+	debugMaxGCPercent        = 10000
+	debugMaxProcsLimit       = 1024
+	debugMinMemoryLimitBytes = 64 << 20
+)
+
+// DebugHandler 管理端 pprof 与运行时调优接口。
+// 调整只作用于处理请求的实例且不持久化，重启后恢复为 GOGC / GOMAXPROCS / GOMEMLIMIT 环境变量的值。
+type DebugHandler struct {
+	enabled          bool
+	allowAdminAPIKey bool
+
+	// mu 串行化运行时参数的读改写（读取 GC 百分比需要 set-then-restore）
+	mu sync.Mutex
+}
+
+// NewDebugHandler creates a new DebugHandler
+func NewDebugHandler(cfg *config.Config) *DebugHandler {
+	h := &DebugHandler{}
+	if cfg != nil {
+		h.enabled = cfg.Server.DebugEndpoints.Enabled
+		h.allowAdminAPIKey = cfg.Server.DebugEndpoints.AllowAdminAPIKey
+	}
+	return h
+}
+
+// RuntimeTuning 运行时参数快照。MemoryLimitBytes 为 0 表示未设置上限。
+type RuntimeTuning struct {
+	GoVersion        string `json:"go_version"`
+	NumCPU           int    `json:"num_cpu"`
+	Goroutines       int    `json:"goroutines"`
+	GCPercent        int    `json:"gc_percent"`
+	MaxProcs         int    `json:"max_procs"`
+	MemoryLimitBytes int64  `json:"memory_limit_bytes"`
+}
+
+// UpdateRuntimeTuningRequest 未提供的字段保持不变；memory_limit_bytes=0 取消内存上限。
+type UpdateRuntimeTuningRequest struct {
+	GCPercent        *int   `json:"gc_percent"`
+	MaxProcs         *int   `json:"max_procs"`
+	MemoryLimitBytes *int64 `json:"memory_limit_bytes"`
+}
+
+// authorize 校验调试接口已启用且认证方式被允许；未启用时返回 404，不暴露接口存在。
+func (h *DebugHandler) authorize(c *gin.Context) bool {
+	if !h.enabled {
+		response.Error(c, http.StatusNotFound, "Debug endpoints are disabled")
+		return false
+	}
+	if !h.allowAdminAPIKey && c.GetString("auth_method") == "admin_api_key" {
+		response.Error(c, http.StatusForbidden, "Debug endpoints require an admin session")
+		return false
+	}
+	return true
+}
+
+// Pprof serves net/http/pprof profiles.
+// GET /api/v1/admin/debug/pprof/*profile
+func (h *DebugHandler) Pprof(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	// pprof.Index 仅识别 /debug/pprof/ 前缀，这里按名称自行分发
+	switch name := strings.Trim(c.Param("profile"), "/"); name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// GetRuntime returns current runtime tuning of this instance.
+// GET /api/v1/admin/debug/runtime
+func (h *DebugHandler) GetRuntime(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	response.Success(c, readRuntimeTuning())
+}
+
+// UpdateRuntime adjusts GC percent, GOMAXPROCS and the soft memory limit of this instance.
+// PUT /api/v1/admin/debug/runtime
+func (h *DebugHandler) UpdateRuntime(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+	var req UpdateRuntimeTuningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if req.GCPercent == nil && req.MaxProcs == nil && req.MemoryLimitBytes == nil {
+		response.BadRequest(c, "gc_percent, max_procs or memory_limit_bytes is required")
+		return
+	}
+	if req.GCPercent != nil && (*req.GCPercent < debugMinGCPercent || *req.GCPercent > debugMaxGCPercent) {
+		response.BadRequest(c, "gc_percent must be between 10 and 10000")
+		return
+	}
+	if req.MaxProcs != nil && (*req.MaxProcs < 1 || *req.MaxProcs > debugMaxProcsLimit) {
+		response.BadRequest(c, "max_procs must be between 1 and 1024")
+		return
+	}
+	if req.MemoryLimitBytes != nil && *req.MemoryLimitBytes != 0 && *req.MemoryLimitBytes < debugMinMemoryLimitBytes {
+		response.BadRequest(c, "memory_limit_bytes must be 0 (unlimited) or at least 64MB")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	before := readRuntimeTuning()
+	if req.GCPercent != nil {
+		debug.SetGCPercent(*req.GCPercent)
+	}
+	if req.MaxProcs != nil {
+		runtime.GOMAXPROCS(*req.MaxProcs)
+	}
+	if req.MemoryLimitBytes != nil {
+		limit := *req.MemoryLimitBytes
+		if limit == 0 {
+			limit = math.MaxInt64
+		}
+		debug.SetMemoryLimit(limit)
+	}
+	after := readRuntimeTuning()
+
+	subject, _ := middleware.GetAuthSubjectFromContext(c)
+	logger.With(
+		zap.String("component", "audit.runtime_tuning"),
+		zap.Int64("operator_id", subject.UserID),
+		zap.String("auth_method", c.GetString("auth_method")),
+		zap.Any("before", before),
+		zap.Any("after", after),
+	).Info("runtime tuning changed")
+
+	response.Success(c, after)
+}
+
+// readRuntimeTuning 读取当前参数；调用方需持有 mu。
+func readRuntimeTuning() RuntimeTuning {
+	gcPercent := debug.SetGCPercent(100)
+	debug.SetGCPercent(gcPercent)
+	memLimit := debug.SetMemoryLimit(-1)
+	if memLimit == math.MaxInt64 {
+		memLimit = 0
+	}
+	return RuntimeTuning{
+		GoVersion:        runtime.Version(),
+		NumCPU:           runtime.NumCPU(),
+		Goroutines:       runtime.NumGoroutine(),
+		GCPercent:        gcPercent,
+		MaxProcs:         runtime.GOMAXPROCS(0),
+		MemoryLimitBytes: memLimit,
+	}
+}
@@ -0,0 +1,112 @@
+//go:build unit
+
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newDebugTestRouter(cfg *config.Config, authMethod string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewDebugHandler(cfg)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("auth_method", authMethod)
+		c.Next()
+	})
+	r.GET("/debug/pprof/*profile", h.Pprof)
+	r.GET("/debug/runtime", h.GetRuntime)
+	r.PUT("/debug/runtime", h.UpdateRuntime)
+	return r
+}
+
+func debugEnabledConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Server.DebugEndpoints.Enabled = true
+	return cfg
+}
+
+func TestDebugHandler_DisabledReturnsNotFound(t *testing.T) {
+	r := newDebugTestRouter(&config.Config{}, "jwt")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDebugHandler_AdminAPIKeyRequiresOptIn(t *testing.T) {
+	cfg := debugEnabledConfig()
+	w := httptest.NewRecorder()
+	newDebugTestRouter(cfg, "admin_api_key").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	cfg.Server.DebugEndpoints.AllowAdminAPIKey = true
+	w = httptest.NewRecorder()
+	newDebugTestRouter(cfg, "admin_api_key").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDebugHandler_PprofServesNamedProfiles(t *testing.T) {
+	r := newDebugTestRouter(debugEnabledConfig(), "jwt")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "goroutine")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, strings.HasPrefix(w.Body.String(), "goroutine profile:"))
+}
+
+func TestDebugHandler_UpdateRuntime(t *testing.T) {
+	origGC := debug.SetGCPercent(100)
+	debug.SetGCPercent(origGC)
+	origProcs := runtime.GOMAXPROCS(0)
+	origLimit := debug.SetMemoryLimit(-1)
+	t.Cleanup(func() {
+		debug.SetGCPercent(origGC)
+		runtime.GOMAXPROCS(origProcs)
+		debug.SetMemoryLimit(origLimit)
+	})
+
+	r := newDebugTestRouter(debugEnabledConfig(), "jwt")
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/debug/runtime", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusBadRequest, put(`{}`).Code)
+	require.Equal(t, http.StatusBadRequest, put(`{"gc_percent":5}`).Code)
+	require.Equal(t, http.StatusBadRequest, put(`{"memory_limit_bytes":1024}`).Code)
+
+	w := put(`{"gc_percent":250,"max_procs":1,"memory_limit_bytes":134217728}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data RuntimeTuning `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 250, resp.Data.GCPercent)
+	require.Equal(t, 1, resp.Data.MaxProcs)
+	require.Equal(t, int64(134217728), resp.Data.MemoryLimitBytes)
+
+	w = put(`{"memory_limit_bytes":0}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Zero(t, resp.Data.MemoryLimitBytes)
+	require.Equal(t, 250, resp.Data.GCPercent)
+}
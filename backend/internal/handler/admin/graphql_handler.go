@@ -0,0 +1,386 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/graphql"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// graphQLMaxBodyBytes 查询请求体上限
+	graphQLMaxBodyBytes = 256 << 10
+	// graphQLMaxPageSize 列表字段单页上限，与 REST 分页保持一致
+	graphQLMaxPageSize     = 1000
+	graphQLDefaultPageSize = 20
+)
+
+// GraphQLHandler 只读 GraphQL 报表查询：在用量、账号、错误日志与备份元数据之上组合自定义报表。
+// 字段与对应 REST 接口的 JSON 输出一致（账号凭据同样脱敏）。
+type GraphQLHandler struct {
+	adminService     service.AdminService
+	usageService     *service.UsageService
+	dashboardService *service.DashboardService
+	opsService       *service.OpsService
+	backupService    *service.BackupService
+	schema           *graphql.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQLHandler
+func NewGraphQLHandler(
+	adminService service.AdminService,
+	usageService *service.UsageService,
+	dashboardService *service.DashboardService,
+	opsService *service.OpsService,
+	backupService *service.BackupService,
+) *GraphQLHandler {
+	h := &GraphQLHandler{
+		adminService:     adminService,
+		usageService:     usageService,
+		dashboardService: dashboardService,
+		opsService:       opsService,
+		backupService:    backupService,
+	}
+	h.schema = h.buildSchema()
+	return h
+}
+
+// Query executes a read-only GraphQL query.
+// POST /api/v1/admin/graphql
+// 响应使用 GraphQL 标准结构 {data, errors}，而不是统一的 code/message 包装，便于直接对接 GraphQL 客户端。
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, graphQLMaxBodyBytes)
+	dec := json.NewDecoder(c.Request.Body)
+	dec.UseNumber()
+	var req graphql.Request
+	if err := dec.Decode(&req); err != nil || strings.TrimSpace(req.Query) == "" {
+		c.JSON(http.StatusBadRequest, &graphql.Response{Errors: []*graphql.Error{{Message: "request body must be JSON with a non-empty \"query\""}}})
+		return
+	}
+
+	resp := graphql.Execute(c.Request.Context(), h.schema, req)
+	if resp.Data == nil {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Schema returns the schema in SDL form.
+// GET /api/v1/admin/graphql/schema
+func (h *GraphQLHandler) Schema(c *gin.Context) {
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(h.schema.SDL()))
+}
+
+type graphQLUsageLogPage struct {
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Items    []dto.AdminUsageLog `json:"items"`
+}
+
+type graphQLAccountPage struct {
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	Items    []dto.Account `json:"items"`
+}
+
+func (h *GraphQLHandler) buildSchema() *graphql.Schema {
+	reg := graphql.NewRegistry()
+	usageFilterArgs := []*graphql.Argument{
+		{Name: "start_date", Type: graphql.String, Description: "YYYY-MM-DD, defaults to 7 days ago"},
+		{Name: "end_date", Type: graphql.String, Description: "YYYY-MM-DD inclusive, defaults to today"},
+		{Name: "timezone", Type: graphql.String},
+		{Name: "user_id", Type: graphql.Int},
+		{Name: "api_key_id", Type: graphql.Int},
+		{Name: "account_id", Type: graphql.Int},
+		{Name: "group_id", Type: graphql.Int},
+	}
+	modelArg := &graphql.Argument{Name: "model", Type: graphql.String}
+	pageArgs := []*graphql.Argument{
+		{Name: "page", Type: graphql.Int},
+		{Name: "page_size", Type: graphql.Int},
+	}
+	withArgs := func(groups ...[]*graphql.Argument) []*graphql.Argument {
+		var out []*graphql.Argument
+		for _, g := range groups {
+			out = append(out, g...)
+		}
+		return out
+	}
+
+	query := graphql.NewObject("Query", "Read-only admin reporting queries",
+		&graphql.Field{
+			Name:        "usage_stats",
+			Description: "Aggregated usage totals (same as GET /admin/usage/stats)",
+			Type:        reg.ObjectOf("UsageStats", usagestats.UsageStats{}),
+			Args:        withArgs(usageFilterArgs, []*graphql.Argument{modelArg}),
+			Resolve:     h.resolveUsageStats,
+		},
+		&graphql.Field{
+			Name:        "usage_by_model",
+			Description: "Per-model usage breakdown",
+			Type:        graphql.ListOf(reg.ObjectOf("ModelStat", usagestats.ModelStat{})),
+			Args:        usageFilterArgs,
+			Resolve:     h.resolveUsageByModel,
+		},
+		&graphql.Field{
+			Name:        "usage_logs",
+			Description: "Paginated usage records, newest first",
+			Type:        reg.ObjectOf("UsageLogPage", graphQLUsageLogPage{}),
+			Args:        withArgs(usageFilterArgs, []*graphql.Argument{modelArg}, pageArgs),
+			Resolve:     h.resolveUsageLogs,
+		},
+		&graphql.Field{
+			Name:        "accounts",
+			Description: "Paginated accounts (credentials redacted)",
+			Type:        reg.ObjectOf("AccountPage", graphQLAccountPage{}),
+			Args: withArgs([]*graphql.Argument{
+				{Name: "platform", Type: graphql.String},
+				{Name: "type", Type: graphql.String},
+				{Name: "status", Type: graphql.String},
+				{Name: "search", Type: graphql.String},
+				{Name: "group_id", Type: graphql.Int},
+			}, pageArgs),
+			Resolve: h.resolveAccounts,
+		},
+		&graphql.Field{
+			Name:    "account",
+			Type:    reg.TypeOf(dto.Account{}),
+			Args:    []*graphql.Argument{{Name: "id", Type: graphql.Int, Required: true}},
+			Resolve: h.resolveAccount,
+		},
+		&graphql.Field{
+			Name:        "error_logs",
+			Description: "Ops error logs; time range defaults to the last 24 hours",
+			Type:        reg.ObjectOf("ErrorLogPage", service.OpsErrorLogList{}),
+			Args: withArgs([]*graphql.Argument{
+				{Name: "start_time", Type: graphql.String, Description: "RFC3339"},
+				{Name: "end_time", Type: graphql.String, Description: "RFC3339"},
+				{Name: "platform", Type: graphql.String},
+				{Name: "group_id", Type: graphql.Int},
+				{Name: "account_id", Type: graphql.Int},
+				{Name: "status_codes", Type: graphql.ListOf(graphql.Int)},
+				{Name: "error_code", Type: graphql.String},
+				{Name: "model", Type: graphql.String},
+				{Name: "query", Type: graphql.String},
+			}, pageArgs),
+			Resolve: h.resolveErrorLogs,
+		},
+		&graphql.Field{
+			Name:        "backups",
+			Description: "Database dump (backup) records metadata",
+			Type:        graphql.ListOf(reg.ObjectOf("BackupRecord", service.BackupRecord{})),
+			Resolve:     h.resolveBackups,
+		},
+	)
+	return &graphql.Schema{Query: query, ErrorPresenter: presentGraphQLError}
+}
+
+// presentGraphQLError 复用 REST 的错误映射：业务错误返回原因码，内部错误只记日志不外泄细节。
+func presentGraphQLError(err error) *graphql.Error {
+	var gqlErr *graphql.Error
+	if errors.As(err, &gqlErr) {
+		return &graphql.Error{Message: gqlErr.Message, Extensions: gqlErr.Extensions}
+	}
+	statusCode, status := infraerrors.ToHTTP(err)
+	if statusCode >= http.StatusInternalServerError {
+		log.Printf("[ERROR] graphql resolver: %s", logredact.RedactText(err.Error()))
+	}
+	ext := map[string]any{"status": statusCode}
+	if status.Reason != "" {
+		ext["code"] = status.Reason
+	}
+	return &graphql.Error{Message: status.Message, Extensions: ext}
+}
+
+func graphQLArgError(format string, args ...any) error {
+	return infraerrors.BadRequest("GRAPHQL_INVALID_ARGUMENT", fmt.Sprintf(format, args...))
+}
+
+func graphQLPage(args graphql.Args) (int, int) {
+	page := args.Int("page", 1)
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := args.Int("page_size", graphQLDefaultPageSize)
+	if pageSize <= 0 || pageSize > graphQLMaxPageSize {
+		pageSize = graphQLDefaultPageSize
+	}
+	return page, pageSize
+}
+
+// graphQLDateRange 与仪表盘一致：按用户时区解析日期，结束日期包含当天（半开区间）。
+func graphQLDateRange(args graphql.Args) (time.Time, time.Time, error) {
+	userTZ := args.String("timezone")
+	now := timezone.NowInUserLocation(userTZ)
+	start := timezone.StartOfDayInUserLocation(now.AddDate(0, 0, -7), userTZ)
+	end := timezone.StartOfDayInUserLocation(now.AddDate(0, 0, 1), userTZ)
+	if raw := args.String("start_date"); raw != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", raw, userTZ)
+		if err != nil {
+			return time.Time{}, time.Time{}, graphQLArgError("invalid start_date, use YYYY-MM-DD")
+		}
+		start = t
+	}
+	if raw := args.String("end_date"); raw != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", raw, userTZ)
+		if err != nil {
+			return time.Time{}, time.Time{}, graphQLArgError("invalid end_date, use YYYY-MM-DD")
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, graphQLArgError("end_date must not be before start_date")
+	}
+	return start, end, nil
+}
+
+func graphQLUsageFilters(args graphql.Args) (usagestats.UsageLogFilters, error) {
+	start, end, err := graphQLDateRange(args)
+	if err != nil {
+		return usagestats.UsageLogFilters{}, err
+	}
+	return usagestats.UsageLogFilters{
+		UserID:    args.Int64("user_id"),
+		APIKeyID:  args.Int64("api_key_id"),
+		AccountID: args.Int64("account_id"),
+		GroupID:   args.Int64("group_id"),
+		Model:     args.String("model"),
+		StartTime: &start,
+		EndTime:   &end,
+	}, nil
+}
+
+func (h *GraphQLHandler) resolveUsageStats(ctx context.Context, args graphql.Args) (any, error) {
+	filters, err := graphQLUsageFilters(args)
+	if err != nil {
+		return nil, err
+	}
+	return h.usageService.GetStatsWithFilters(ctx, filters)
+}
+
+func (h *GraphQLHandler) resolveUsageByModel(ctx context.Context, args graphql.Args) (any, error) {
+	filters, err := graphQLUsageFilters(args)
+	if err != nil {
+		return nil, err
+	}
+	return h.dashboardService.GetModelStatsWithFilters(ctx, *filters.StartTime, *filters.EndTime,
+		filters.UserID, filters.APIKeyID, filters.AccountID, filters.GroupID, nil, nil, nil)
+}
+
+func (h *GraphQLHandler) resolveUsageLogs(ctx context.Context, args graphql.Args) (any, error) {
+	filters, err := graphQLUsageFilters(args)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize := graphQLPage(args)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize, SortBy: "created_at", SortOrder: "desc"}
+	records, result, err := h.usageService.ListWithFilters(ctx, params, filters)
+	if err != nil {
+		return nil, err
+	}
+	out := graphQLUsageLogPage{Page: page, PageSize: pageSize, Items: make([]dto.AdminUsageLog, 0, len(records))}
+	if result != nil {
+		out.Total = result.Total
+	}
+	for i := range records {
+		out.Items = append(out.Items, *dto.UsageLogFromServiceAdmin(&records[i]))
+	}
+	return out, nil
+}
+
+func (h *GraphQLHandler) resolveAccounts(ctx context.Context, args graphql.Args) (any, error) {
+	page, pageSize := graphQLPage(args)
+	accounts, total, err := h.adminService.ListAccounts(ctx, page, pageSize,
+		args.String("platform"), args.String("type"), args.String("status"), args.String("search"),
+		args.Int64("group_id"), "", "name", "asc")
+	if err != nil {
+		return nil, err
+	}
+	out := graphQLAccountPage{Total: total, Page: page, PageSize: pageSize, Items: make([]dto.Account, 0, len(accounts))}
+	for i := range accounts {
+		out.Items = append(out.Items, *dto.AccountFromService(&accounts[i]))
+	}
+	return out, nil
+}
+
+func (h *GraphQLHandler) resolveAccount(ctx context.Context, args graphql.Args) (any, error) {
+	account, err := h.adminService.GetAccount(ctx, args.Int64("id"))
+	if err != nil {
+		return nil, err
+	}
+	return dto.AccountFromService(account), nil
+}
+
+func (h *GraphQLHandler) resolveErrorLogs(ctx context.Context, args graphql.Args) (any, error) {
+	if h.opsService == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_SERVICE_UNAVAILABLE", "Ops service not available")
+	}
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if raw := args.String("start_time"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, graphQLArgError("invalid start_time, use RFC3339")
+		}
+		start = t
+	}
+	if raw := args.String("end_time"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, graphQLArgError("invalid end_time, use RFC3339")
+		}
+		end = t
+	}
+	if !end.After(start) {
+		return nil, graphQLArgError("end_time must be after start_time")
+	}
+
+	page, pageSize := graphQLPage(args)
+	filter := &service.OpsErrorLogFilter{
+		StartTime: &start,
+		EndTime:   &end,
+		Platform:  args.String("platform"),
+		ErrorCode: args.String("error_code"),
+		Model:     args.String("model"),
+		Query:     args.String("query"),
+		Page:      page,
+		PageSize:  pageSize,
+	}
+	if args.Has("group_id") {
+		id := args.Int64("group_id")
+		filter.GroupID = &id
+	}
+	if args.Has("account_id") {
+		id := args.Int64("account_id")
+		filter.AccountID = &id
+	}
+	for _, code := range args.IntList("status_codes") {
+		filter.StatusCodes = append(filter.StatusCodes, int(code))
+	}
+	return h.opsService.GetErrorLogs(ctx, filter)
+}
+
+func (h *GraphQLHandler) resolveBackups(ctx context.Context, _ graphql.Args) (any, error) {
+	if h.backupService == nil {
+		return nil, infraerrors.ServiceUnavailable("BACKUP_SERVICE_UNAVAILABLE", "Backup service not available")
+	}
+	return h.backupService.ListBackups(ctx)
+}
@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGraphQLRouter(adminSvc *stubAdminService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewGraphQLHandler(adminSvc, nil, nil, nil, nil)
+	router.POST("/api/v1/admin/graphql", h.Query)
+	router.GET("/api/v1/admin/graphql/schema", h.Schema)
+	return router
+}
+
+func postGraphQL(t *testing.T, router *gin.Engine, body any) (int, map[string]any) {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/graphql", bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	return rec.Code, out
+}
+
+func TestGraphQLHandler_AccountsQueryRedactsCredentials(t *testing.T) {
+	adminSvc := newStubAdminService()
+	adminSvc.accounts = []service.Account{{
+		ID:          7,
+		Name:        "claude-a",
+		Platform:    service.PlatformAnthropic,
+		Type:        service.AccountTypeOAuth,
+		Status:      service.StatusActive,
+		Credentials: map[string]any{"access_token": "secret-token"},
+	}}
+	router := setupGraphQLRouter(adminSvc)
+
+	code, out := postGraphQL(t, router, map[string]any{
+		"query":     `query Accounts($platform: String) { accounts(platform: $platform, page_size: 5) { total items { id name platform credentials } } }`,
+		"variables": map[string]any{"platform": service.PlatformAnthropic},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, out["errors"])
+	accounts := out["data"].(map[string]any)["accounts"].(map[string]any)
+	require.Equal(t, float64(1), accounts["total"])
+	item := accounts["items"].([]any)[0].(map[string]any)
+	require.Equal(t, "claude-a", item["name"])
+	require.NotContains(t, item, "status", "only selected fields are returned")
+	raw, _ := json.Marshal(item)
+	require.NotContains(t, string(raw), "secret-token")
+	require.Equal(t, service.PlatformAnthropic, adminSvc.lastListAccounts.platform)
+}
+
+func TestGraphQLHandler_RejectsInvalidQueries(t *testing.T) {
+	router := setupGraphQLRouter(newStubAdminService())
+
+	code, out := postGraphQL(t, router, map[string]any{"query": `mutation { accounts { total } }`})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Nil(t, out["data"])
+	require.Contains(t, out["errors"].([]any)[0].(map[string]any)["message"], "read-only")
+
+	code, _ = postGraphQL(t, router, map[string]any{"variables": map[string]any{}})
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, out = postGraphQL(t, router, map[string]any{"query": `{ backups { id } }`})
+	require.Equal(t, http.StatusOK, code, "resolver failures are partial results, not request failures")
+	require.Nil(t, out["data"].(map[string]any)["backups"])
+	ext := out["errors"].([]any)[0].(map[string]any)["extensions"].(map[string]any)
+	require.Equal(t, "BACKUP_SERVICE_UNAVAILABLE", ext["code"])
+}
+
+func TestGraphQLHandler_SchemaSDL(t *testing.T) {
+	router := setupGraphQLRouter(newStubAdminService())
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/graphql/schema", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	sdl := rec.Body.String()
+	require.Contains(t, sdl, "account(id: Int!): Account")
+	require.Contains(t, sdl, "type UsageStats {")
+	require.Contains(t, sdl, "error_logs(")
+	require.Contains(t, sdl, "backups: [BackupRecord]")
+}
@@ -104,6 +104,7 @@ type CreateGroupRequest struct {
 	PeakStart                       string   `json:"peak_start"`
 	PeakEnd                         string   `json:"peak_end"`
 	PeakRateMultiplier              *float64 `json:"peak_rate_multiplier"`
+	RequestSurcharge                *float64 `json:"request_surcharge"`
 	ImagePrice1K                    *float64 `json:"image_price_1k"`
 	ImagePrice2K                    *float64 `json:"image_price_2k"`
 	ImagePrice4K                    *float64 `json:"image_price_4k"`
@@ -128,6 +129,31 @@ type CreateGroupRequest struct {
 	ModelsListConfig            service.GroupModelsListConfig             `json:"models_list_config"`
 	// 分组 RPM 上限（0 = 不限制）
 	RPMLimit int `json:"rpm_limit"`
+	// 为关键 API Key 预留的账号并发比例 [0,1)
+	ReservedConcurrencyRatio float64 `json:"reserved_concurrency_ratio"`
+	// 过载降级映射：模型模式 -> 备用模型
+	OverloadFallbackModels map[string]string `json:"overload_fallback_models"`
+	// 长上下文路由映射：模型模式 -> 大上下文模型
+	ContextOverflowModels map[string]string `json:"context_overflow_models"`
+	// 超出上下文窗口且无可用大上下文模型时直接拒绝
+	ContextOverflowReject bool `json:"context_overflow_reject"`
+	// 超出上下文窗口时的截断策略：drop_oldest/summarize/middle_out
+	ContextTruncationStrategy string `json:"context_truncation_strategy"`
+	// 账号选择策略：weighted_round_robin/least_recently_used/lowest_latency，空或 priority 表示按优先级逐级溢出
+	AccountSelectionStrategy string `json:"account_selection_strategy"`
+	// 图片预处理目标：最长边像素与单张字节数（0 = 不限制）
+	ImageMaxEdge  int `json:"image_max_edge"`
+	ImageMaxBytes int `json:"image_max_bytes"`
+	// 输出后处理：替换规则与追加署名
+	OutputPostprocess service.GroupOutputPostprocessConfig `json:"output_postprocess"`
+	// 语言路由：按提示词主要语言改写模型或优先调度指定账号/代理
+	LanguageRouting service.GroupLanguageRoutingConfig `json:"language_routing"`
+	// 流式断线续传：缓存 SSE 事件供 Last-Event-ID 重连
+	StreamReplay service.GroupStreamReplayConfig `json:"stream_replay"`
+	// 模型能力校验配置
+	CapabilityCheck service.GroupCapabilityCheckConfig `json:"capability_check"`
+	// 截断自动续写配置
+	AutoContinuation service.GroupAutoContinuationConfig `json:"auto_continuation"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -157,6 +183,7 @@ type UpdateGroupRequest struct {
 	PeakStart                       *string  `json:"peak_start"`
 	PeakEnd                         *string  `json:"peak_end"`
 	PeakRateMultiplier              *float64 `json:"peak_rate_multiplier"`
+	RequestSurcharge                *float64 `json:"request_surcharge"`
 	ImagePrice1K                    *float64 `json:"image_price_1k"`
 	ImagePrice2K                    *float64 `json:"image_price_2k"`
 	ImagePrice4K                    *float64 `json:"image_price_4k"`
@@ -181,6 +208,31 @@ type UpdateGroupRequest struct {
 	ModelsListConfig            *service.GroupModelsListConfig             `json:"models_list_config"`
 	// 分组 RPM 上限（0 = 不限制）；nil 表示未提供不改动
 	RPMLimit *int `json:"rpm_limit"`
+	// 为关键 API Key 预留的账号并发比例 [0,1)；nil 表示未提供不改动
+	ReservedConcurrencyRatio *float64 `json:"reserved_concurrency_ratio"`
+	// 过载降级映射；nil 表示未提供不改动，空对象表示清除
+	OverloadFallbackModels map[string]string `json:"overload_fallback_models"`
+	// 长上下文路由映射；nil 表示未提供不改动，空对象表示清除
+	ContextOverflowModels map[string]string `json:"context_overflow_models"`
+	// 超出上下文窗口时是否直接拒绝；nil 表示未提供不改动
+	ContextOverflowReject *bool `json:"context_overflow_reject"`
+	// 截断策略；nil 表示未提供不改动，空串表示关闭
+	ContextTruncationStrategy *string `json:"context_truncation_strategy"`
+	// 账号选择策略；nil 表示未提供不改动，空串表示恢复默认（priority）
+	AccountSelectionStrategy *string `json:"account_selection_strategy"`
+	// 图片预处理目标；nil 表示未提供不改动
+	ImageMaxEdge  *int `json:"image_max_edge"`
+	ImageMaxBytes *int `json:"image_max_bytes"`
+	// 输出后处理配置；nil 表示未提供不改动
+	OutputPostprocess *service.GroupOutputPostprocessConfig `json:"output_postprocess"`
+	// 语言路由规则；nil 表示未提供不改动
+	LanguageRouting *service.GroupLanguageRoutingConfig `json:"language_routing"`
+	// 流式断线续传配置；nil 表示未提供不改动
+	StreamReplay *service.GroupStreamReplayConfig `json:"stream_replay"`
+	// 模型能力校验配置，nil 表示不修改
+	CapabilityCheck *service.GroupCapabilityCheckConfig `json:"capability_check"`
+	// 截断自动续写配置，nil 表示不修改
+	AutoContinuation *service.GroupAutoContinuationConfig `json:"auto_continuation"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -328,6 +380,7 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		PeakStart:                       req.PeakStart,
 		PeakEnd:                         req.PeakEnd,
 		PeakRateMultiplier:              req.PeakRateMultiplier,
+		RequestSurcharge:                req.RequestSurcharge,
 		ImagePrice1K:                    req.ImagePrice1K,
 		ImagePrice2K:                    req.ImagePrice2K,
 		ImagePrice4K:                    req.ImagePrice4K,
@@ -348,6 +401,19 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		ModelsListConfig:                req.ModelsListConfig,
 		RPMLimit:                        req.RPMLimit,
+		ReservedConcurrencyRatio:        req.ReservedConcurrencyRatio,
+		OverloadFallbackModels:          req.OverloadFallbackModels,
+		ContextOverflowModels:           req.ContextOverflowModels,
+		ContextOverflowReject:           req.ContextOverflowReject,
+		ContextTruncationStrategy:       req.ContextTruncationStrategy,
+		AccountSelectionStrategy:        req.AccountSelectionStrategy,
+		ImageMaxEdge:                    req.ImageMaxEdge,
+		ImageMaxBytes:                   req.ImageMaxBytes,
+		OutputPostprocess:               req.OutputPostprocess,
+		LanguageRouting:                 req.LanguageRouting,
+		StreamReplay:                    req.StreamReplay,
+		CapabilityCheck:                 req.CapabilityCheck,
+		AutoContinuation:                req.AutoContinuation,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -396,6 +462,7 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		PeakStart:                       req.PeakStart,
 		PeakEnd:                         req.PeakEnd,
 		PeakRateMultiplier:              req.PeakRateMultiplier,
+		RequestSurcharge:                req.RequestSurcharge,
 		ImagePrice1K:                    req.ImagePrice1K,
 		ImagePrice2K:                    req.ImagePrice2K,
 		ImagePrice4K:                    req.ImagePrice4K,
@@ -416,6 +483,19 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		ModelsListConfig:                req.ModelsListConfig,
 		RPMLimit:                        req.RPMLimit,
+		ReservedConcurrencyRatio:        req.ReservedConcurrencyRatio,
+		OverloadFallbackModels:          req.OverloadFallbackModels,
+		ContextOverflowModels:           req.ContextOverflowModels,
+		ContextOverflowReject:           req.ContextOverflowReject,
+		ContextTruncationStrategy:       req.ContextTruncationStrategy,
+		AccountSelectionStrategy:        req.AccountSelectionStrategy,
+		ImageMaxEdge:                    req.ImageMaxEdge,
+		ImageMaxBytes:                   req.ImageMaxBytes,
+		OutputPostprocess:               req.OutputPostprocess,
+		LanguageRouting:                 req.LanguageRouting,
+		StreamReplay:                    req.StreamReplay,
+		CapabilityCheck:                 req.CapabilityCheck,
+		AutoContinuation:                req.AutoContinuation,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -625,6 +705,98 @@ func (h *GroupHandler) ClearGroupRPMOverrides(c *gin.Context) {
 	response.Success(c, gin.H{"message": "RPM overrides cleared successfully"})
 }
 
+// GetGroupAccountTiers handles listing failover tiers (0=primary, 1=secondary, 2=emergency) of accounts in a group
+// GET /api/v1/admin/groups/:id/account-tiers
+func (h *GroupHandler) GetGroupAccountTiers(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	tiers, err := h.adminService.GetGroupAccountTiers(c.Request.Context(), groupID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, tiers)
+}
+
+// BatchSetGroupAccountTiersRequest represents batch set account failover tiers request
+type BatchSetGroupAccountTiersRequest struct {
+	Entries []service.GroupAccountTier `json:"entries" binding:"required"`
+}
+
+// BatchSetGroupAccountTiers handles batch setting failover tiers for accounts in a group
+// PUT /api/v1/admin/groups/:id/account-tiers
+func (h *GroupHandler) BatchSetGroupAccountTiers(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	var req BatchSetGroupAccountTiersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.adminService.BatchSetGroupAccountTiers(c.Request.Context(), groupID, req.Entries); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Account tiers updated successfully"})
+}
+
+// GetGroupAccountConcurrencyShares handles listing concurrency shares (percent of account concurrency, 0=unlimited) of accounts in a group
+// GET /api/v1/admin/groups/:id/account-shares
+func (h *GroupHandler) GetGroupAccountConcurrencyShares(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	shares, err := h.adminService.GetGroupAccountConcurrencyShares(c.Request.Context(), groupID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, shares)
+}
+
+// BatchSetGroupAccountConcurrencySharesRequest represents batch set account concurrency shares request
+type BatchSetGroupAccountConcurrencySharesRequest struct {
+	Entries []service.GroupAccountConcurrencyShare `json:"entries" binding:"required"`
+}
+
+// BatchSetGroupAccountConcurrencyShares handles batch setting concurrency shares for accounts in a group
+// PUT /api/v1/admin/groups/:id/account-shares
+func (h *GroupHandler) BatchSetGroupAccountConcurrencyShares(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	var req BatchSetGroupAccountConcurrencySharesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.adminService.BatchSetGroupAccountConcurrencyShares(c.Request.Context(), groupID, req.Entries); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Account concurrency shares updated successfully"})
+}
+
 // UpdateSortOrderRequest represents the request to update group sort orders
 type UpdateSortOrderRequest struct {
 	Updates []struct {
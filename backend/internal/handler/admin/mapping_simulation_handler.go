@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MappingSimulationHandler handles dry-running model mapping / routing changes against recent traffic.
+type MappingSimulationHandler struct {
+	mappingSimulationService *service.MappingSimulationService
+}
+
+// NewMappingSimulationHandler creates a new MappingSimulationHandler.
+func NewMappingSimulationHandler(mappingSimulationService *service.MappingSimulationService) *MappingSimulationHandler {
+	return &MappingSimulationHandler{mappingSimulationService: mappingSimulationService}
+}
+
+// Simulate reports which recent requests would route differently under candidate mapping/routing changes
+// POST /api/v1/admin/accounts/model-mapping/simulate
+func (h *MappingSimulationHandler) Simulate(c *gin.Context) {
+	var req service.MappingSimulationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	report, err := h.mappingSimulationService.Simulate(c.Request.Context(), req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
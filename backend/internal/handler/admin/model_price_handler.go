@@ -0,0 +1,146 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ModelPriceHandler handles admin model price table management and usage cost backfill
+type ModelPriceHandler struct {
+	modelPriceService *service.ModelPriceService
+}
+
+// NewModelPriceHandler creates a new admin model price handler
+func NewModelPriceHandler(modelPriceService *service.ModelPriceService) *ModelPriceHandler {
+	return &ModelPriceHandler{modelPriceService: modelPriceService}
+}
+
+type modelPriceRequest struct {
+	Model           string     `json:"model" binding:"required,max=100"`
+	InputPrice      float64    `json:"input_price" binding:"gte=0"`
+	OutputPrice     float64    `json:"output_price" binding:"gte=0"`
+	CacheWritePrice float64    `json:"cache_write_price" binding:"gte=0"`
+	CacheReadPrice  float64    `json:"cache_read_price" binding:"gte=0"`
+	EffectiveFrom   *time.Time `json:"effective_from"`
+	EffectiveTo     *time.Time `json:"effective_to"`
+	Note            string     `json:"note" binding:"max=255"`
+}
+
+func (r *modelPriceRequest) toModelPrice() *service.ModelPrice {
+	price := &service.ModelPrice{
+		Model:           r.Model,
+		InputPrice:      r.InputPrice,
+		OutputPrice:     r.OutputPrice,
+		CacheWritePrice: r.CacheWritePrice,
+		CacheReadPrice:  r.CacheReadPrice,
+		EffectiveTo:     r.EffectiveTo,
+		Note:            r.Note,
+	}
+	if r.EffectiveFrom != nil {
+		price.EffectiveFrom = *r.EffectiveFrom
+	}
+	return price
+}
+
+type modelPriceBackfillRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+	Model     string    `json:"model"`
+}
+
+// List returns model prices, optionally filtered by model
+// GET /api/v1/admin/model-prices?model=claude-sonnet-4-6
+func (h *ModelPriceHandler) List(c *gin.Context) {
+	prices, err := h.modelPriceService.List(c.Request.Context(), c.Query("model"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, prices)
+}
+
+// Create handles creating a model price
+// POST /api/v1/admin/model-prices
+func (h *ModelPriceHandler) Create(c *gin.Context) {
+	var req modelPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+
+	price, err := h.modelPriceService.Create(c.Request.Context(), req.toModelPrice())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, price)
+}
+
+// Update handles replacing a model price
+// PUT /api/v1/admin/model-prices/:id
+func (h *ModelPriceHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid model price ID")
+		return
+	}
+	var req modelPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+
+	price, err := h.modelPriceService.Update(c.Request.Context(), id, req.toModelPrice())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, price)
+}
+
+// Delete handles deleting a model price
+// DELETE /api/v1/admin/model-prices/:id
+func (h *ModelPriceHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid model price ID")
+		return
+	}
+	if err := h.modelPriceService.Delete(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Model price deleted successfully"})
+}
+
+// StartBackfill starts recomputing historical usage log costs from the price table
+// POST /api/v1/admin/model-prices/backfill
+func (h *ModelPriceHandler) StartBackfill(c *gin.Context) {
+	var req modelPriceBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+
+	status, err := h.modelPriceService.StartBackfill(service.ModelPriceBackfillRequest{
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Model:     req.Model,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Accepted(c, status)
+}
+
+// GetBackfillStatus returns the status of the latest usage cost backfill
+// GET /api/v1/admin/model-prices/backfill
+func (h *ModelPriceHandler) GetBackfillStatus(c *gin.Context) {
+	response.Success(c, h.modelPriceService.GetBackfillStatus())
+}
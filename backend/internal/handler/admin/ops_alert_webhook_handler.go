@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAlertWebhooks returns all ops alert webhook channels (secrets are never returned).
+// GET /api/v1/admin/ops/alert-webhooks
+func (h *OpsHandler) ListAlertWebhooks(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	hooks, err := h.opsService.ListAlertWebhooks(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, hooks)
+}
+
+// CreateAlertWebhook creates an ops alert webhook channel.
+// POST /api/v1/admin/ops/alert-webhooks
+func (h *OpsHandler) CreateAlertWebhook(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	var req service.OpsAlertWebhookInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	created, err := h.opsService.CreateAlertWebhook(c.Request.Context(), &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, created)
+}
+
+// UpdateAlertWebhook updates an ops alert webhook channel; omit "secret" to keep the stored one.
+// PUT /api/v1/admin/ops/alert-webhooks/:id
+func (h *OpsHandler) UpdateAlertWebhook(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	id, ok := parseOpsAlertWebhookID(c)
+	if !ok {
+		return
+	}
+	var req service.OpsAlertWebhookInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	updated, err := h.opsService.UpdateAlertWebhook(c.Request.Context(), id, &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// DeleteAlertWebhook deletes an ops alert webhook channel.
+// DELETE /api/v1/admin/ops/alert-webhooks/:id
+func (h *OpsHandler) DeleteAlertWebhook(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	id, ok := parseOpsAlertWebhookID(c)
+	if !ok {
+		return
+	}
+	if err := h.opsService.DeleteAlertWebhook(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"deleted": true})
+}
+
+// TestAlertWebhook sends a test message through the channel and returns the delivery record.
+// POST /api/v1/admin/ops/alert-webhooks/:id/test
+func (h *OpsHandler) TestAlertWebhook(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	id, ok := parseOpsAlertWebhookID(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.opsService.TestAlertWebhook(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, delivery)
+}
+
+// ListAlertWebhookDeliveries lists recent webhook deliveries, optionally filtered by webhook_id / event_id.
+// GET /api/v1/admin/ops/alert-webhook-deliveries
+func (h *OpsHandler) ListAlertWebhookDeliveries(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	filter := &service.OpsAlertWebhookDeliveryFilter{Limit: 50}
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		filter.Limit = n
+	}
+	for key, dst := range map[string]**int64{"webhook_id": &filter.WebhookID, "event_id": &filter.EventID} {
+		raw := strings.TrimSpace(c.Query(key))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			response.BadRequest(c, "Invalid "+key)
+			return
+		}
+		*dst = &n
+	}
+	deliveries, err := h.opsService.ListAlertWebhookDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, deliveries)
+}
+
+func parseOpsAlertWebhookID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid webhook ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -26,6 +26,7 @@ var validOpsAlertMetricTypes = []string{
 	"group_available_accounts",
 	"group_available_ratio",
 	"group_rate_limit_ratio",
+	"group_failover_tier",
 	"account_rate_limited_count",
 	"account_error_count",
 	"account_error_ratio",
@@ -33,6 +34,8 @@ var validOpsAlertMetricTypes = []string{
 	"overload_account_count",
 	"proxy_expired_count",
 	"proxy_expiring_soon_count",
+	"upstream_429_count",
+	"stream_fault_rate",
 }
 
 var validOpsAlertMetricTypeSet = func() map[string]struct{} {
@@ -95,7 +98,8 @@ func isPercentOrRateMetric(metricType string) bool {
 		"memory_usage_percent",
 		"group_available_ratio",
 		"group_rate_limit_ratio",
-		"account_error_ratio":
+		"account_error_ratio",
+		"stream_fault_rate":
 		return true
 	default:
 		return false
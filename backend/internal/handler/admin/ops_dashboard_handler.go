@@ -245,6 +245,110 @@ func (h *OpsHandler) GetDashboardOpenAITokenStats(c *gin.Context) {
 	response.Success(c, data)
 }
 
+// GetDashboardOpenAISessionStrategyStats returns prompt-cache hit stats per OpenAI OAuth session ID strategy
+// (in-process counters since startup).
+// GET /api/v1/admin/ops/dashboard/openai-session-strategy-stats
+func (h *OpsHandler) GetDashboardOpenAISessionStrategyStats(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, h.opsService.GetOpenAISessionStrategyStats())
+}
+
+// GetDashboardAutoContinuationStats returns how often non-streaming responses were truncated
+// by the output token limit and how often automatic continuation fired (in-process counters since startup).
+// GET /api/v1/admin/ops/dashboard/auto-continuation-stats
+func (h *OpsHandler) GetDashboardAutoContinuationStats(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, h.opsService.GetAutoContinuationStats())
+}
+
+// GetDashboardUpstreamConnectionStats returns upstream connection reuse and warm standby stats
+// (in-process counters since startup).
+// GET /api/v1/admin/ops/dashboard/upstream-connection-stats
+func (h *OpsHandler) GetDashboardUpstreamConnectionStats(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	stats, ok := h.opsService.GetUpstreamConnStats()
+	if !ok {
+		response.Error(c, http.StatusServiceUnavailable, "Upstream connection stats not available")
+		return
+	}
+	response.Success(c, stats)
+}
+
+// GetDashboardLatencyBreakdown returns TTFT and generation throughput grouped by model or account.
+// GET /api/v1/admin/ops/dashboard/latency-breakdown
+func (h *OpsHandler) GetDashboardLatencyBreakdown(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	timeRange := strings.TrimSpace(c.Query("time_range"))
+	if timeRange == "" {
+		timeRange = "1d"
+	}
+	dur, ok := parseOpsOpenAITokenStatsDuration(timeRange)
+	if !ok {
+		response.BadRequest(c, "invalid time_range")
+		return
+	}
+	end := time.Now().UTC()
+	filter := &service.OpsLatencyBreakdownFilter{
+		TimeRange: timeRange,
+		StartTime: end.Add(-dur),
+		EndTime:   end,
+		Platform:  strings.TrimSpace(c.Query("platform")),
+		GroupBy:   strings.TrimSpace(c.Query("group_by")),
+	}
+	if v := strings.TrimSpace(c.Query("group_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "invalid group_id")
+			return
+		}
+		filter.GroupID = &id
+	}
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			response.BadRequest(c, "invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	data, err := h.opsService.GetLatencyBreakdown(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
 func parseOpsOpenAITokenStatsFilter(c *gin.Context) (*service.OpsOpenAITokenStatsFilter, error) {
 	if c == nil {
 		return nil, fmt.Errorf("invalid request")
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExportRequestBundle downloads the debug bundle of a request as a zip archive.
+// :key matches request_id or client_request_id; the lookup window defaults to 7d.
+// GET /api/v1/admin/ops/requests/:key/bundle.zip
+func (h *OpsHandler) ExportRequestBundle(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	startTime, endTime, err := parseOpsTimeRange(c, "7d")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	bundle, err := h.opsService.BuildDebugBundle(c.Request.Context(), c.Param("key"), startTime, endTime, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	// 先完整写入内存再返回，打包失败时仍可返回 JSON 错误
+	var buf bytes.Buffer
+	if err := service.WriteDebugBundleZip(&buf, bundle); err != nil {
+		logger.L().With(zap.String("component", "handler.admin.ops")).Error("ops.debug_bundle_zip_failed", zap.String("key", bundle.Key), zap.Error(err))
+		response.InternalError(c, "Failed to build debug bundle")
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+opsDebugBundleFilename(bundle.Key)+`"`)
+	c.Header("Content-Length", strconv.Itoa(buf.Len()))
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// opsDebugBundleFilename 仅保留文件名安全字符，避免请求 ID 中的引号/路径分隔符破坏响应头。
+func opsDebugBundleFilename(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return "ops-debug-" + b.String() + ".zip"
+}
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type opsErrorCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// parseOpsErrorTriageTarget 校验服务可用、登录主体与路径中的错误 ID。
+func (h *OpsHandler) parseOpsErrorTriageTarget(c *gin.Context) (errorID int64, userID int64, ok bool) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return 0, 0, false
+	}
+	subject, exists := middleware.GetAuthSubjectFromContext(c)
+	if !exists || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid error id")
+		return 0, 0, false
+	}
+	return id, subject.UserID, true
+}
+
+// UpdateErrorTriage updates triage status and/or assignee of an error log.
+// PUT /api/v1/admin/ops/errors/:id/triage
+func (h *OpsHandler) UpdateErrorTriage(c *gin.Context) {
+	id, uid, ok := h.parseOpsErrorTriageTarget(c)
+	if !ok {
+		return
+	}
+
+	var req service.OpsErrorTriageUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	detail, err := h.opsService.UpdateErrorTriage(c.Request.Context(), id, &req, &uid)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, detail)
+}
+
+// ListErrorComments lists triage comments of an error log (oldest first).
+// GET /api/v1/admin/ops/errors/:id/comments
+func (h *OpsHandler) ListErrorComments(c *gin.Context) {
+	id, _, ok := h.parseOpsErrorTriageTarget(c)
+	if !ok {
+		return
+	}
+
+	comments, err := h.opsService.ListErrorComments(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, comments)
+}
+
+// AddErrorComment appends a triage comment to an error log.
+// POST /api/v1/admin/ops/errors/:id/comments
+func (h *OpsHandler) AddErrorComment(c *gin.Context) {
+	id, uid, ok := h.parseOpsErrorTriageTarget(c)
+	if !ok {
+		return
+	}
+
+	var req opsErrorCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	comment, err := h.opsService.AddErrorComment(c.Request.Context(), id, uid, req.Body)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, comment)
+}
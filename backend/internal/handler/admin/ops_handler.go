@@ -115,6 +115,7 @@ func (h *OpsHandler) GetErrorLogs(c *gin.Context) {
 	filter.Phase = strings.TrimSpace(c.Query("phase"))
 	filter.Owner = strings.TrimSpace(c.Query("error_owner"))
 	filter.Source = strings.TrimSpace(c.Query("error_source"))
+	filter.ErrorCode = strings.TrimSpace(c.Query("error_code"))
 	filter.Query = strings.TrimSpace(c.Query("q"))
 	filter.UserQuery = strings.TrimSpace(c.Query("user_query"))
 	// Model 过滤：admin 走精确匹配（ModelFuzzy 默认 false，保持管理端语义）。
@@ -183,6 +184,9 @@ func (h *OpsHandler) GetErrorLogs(c *gin.Context) {
 			return
 		}
 	}
+	if !applyOpsErrorTriageFilters(c, filter) {
+		return
+	}
 	if statusCodesStr := strings.TrimSpace(c.Query("status_codes")); statusCodesStr != "" {
 		parts := strings.Split(statusCodesStr, ",")
 		out := make([]int, 0, len(parts))
@@ -244,6 +248,7 @@ func (h *OpsHandler) ListRequestErrors(c *gin.Context) {
 	filter.Phase = strings.TrimSpace(c.Query("phase"))
 	filter.Owner = strings.TrimSpace(c.Query("error_owner"))
 	filter.Source = strings.TrimSpace(c.Query("error_source"))
+	filter.ErrorCode = strings.TrimSpace(c.Query("error_code"))
 	filter.Query = strings.TrimSpace(c.Query("q"))
 	filter.UserQuery = strings.TrimSpace(c.Query("user_query"))
 	// Model 过滤：admin 走精确匹配（ModelFuzzy 默认 false，保持管理端语义）。
@@ -296,6 +301,9 @@ func (h *OpsHandler) ListRequestErrors(c *gin.Context) {
 			return
 		}
 	}
+	if !applyOpsErrorTriageFilters(c, filter) {
+		return
+	}
 	if statusCodesStr := strings.TrimSpace(c.Query("status_codes")); statusCodesStr != "" {
 		parts := strings.Split(statusCodesStr, ",")
 		out := make([]int, 0, len(parts))
@@ -391,6 +399,7 @@ func (h *OpsHandler) ListRequestErrorUpstreamErrors(c *gin.Context) {
 	filter.IncludeRecoveredUpstream = true
 	filter.Owner = "provider"
 	filter.Source = strings.TrimSpace(c.Query("error_source"))
+	filter.ErrorCode = strings.TrimSpace(c.Query("error_code"))
 	filter.Query = strings.TrimSpace(c.Query("q"))
 
 	if platform := strings.TrimSpace(c.Query("platform")); platform != "" {
@@ -475,6 +484,7 @@ func (h *OpsHandler) ListUpstreamErrors(c *gin.Context) {
 	filter.IncludeRecoveredUpstream = true
 	filter.Owner = "provider"
 	filter.Source = strings.TrimSpace(c.Query("error_source"))
+	filter.ErrorCode = strings.TrimSpace(c.Query("error_code"))
 	filter.Query = strings.TrimSpace(c.Query("q"))
 
 	if platform := strings.TrimSpace(c.Query("platform")); platform != "" {
@@ -510,6 +520,9 @@ func (h *OpsHandler) ListUpstreamErrors(c *gin.Context) {
 			return
 		}
 	}
+	if !applyOpsErrorTriageFilters(c, filter) {
+		return
+	}
 	if statusCodesStr := strings.TrimSpace(c.Query("status_codes")); statusCodesStr != "" {
 		parts := strings.Split(statusCodesStr, ",")
 		out := make([]int, 0, len(parts))
@@ -653,6 +666,48 @@ func (h *OpsHandler) ListRequestDetails(c *gin.Context) {
 	response.Paginated(c, out.Items, out.Total, out.Page, out.PageSize)
 }
 
+// applyOpsErrorTriageFilters 解析处理流程过滤参数：status（逗号分隔多选）、assignee（用户 ID / me / none）。
+// 参数非法时写入 400 响应并返回 false。
+func applyOpsErrorTriageFilters(c *gin.Context, filter *service.OpsErrorLogFilter) bool {
+	if v := strings.TrimSpace(c.Query("status")); v != "" {
+		statuses := make([]string, 0, 4)
+		for _, part := range strings.Split(v, ",") {
+			status := strings.ToLower(strings.TrimSpace(part))
+			if status == "" {
+				continue
+			}
+			if !service.IsValidOpsErrorTriageStatus(status) {
+				response.BadRequest(c, "Invalid status")
+				return false
+			}
+			statuses = append(statuses, status)
+		}
+		filter.Statuses = statuses
+	}
+	if v := strings.TrimSpace(c.Query("assignee")); v != "" {
+		switch strings.ToLower(v) {
+		case "none":
+			filter.Unassigned = true
+		case "me":
+			subject, ok := middleware.GetAuthSubjectFromContext(c)
+			if !ok || subject.UserID <= 0 {
+				response.Error(c, http.StatusUnauthorized, "Unauthorized")
+				return false
+			}
+			uid := subject.UserID
+			filter.AssigneeUserID = &uid
+		default:
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id <= 0 {
+				response.BadRequest(c, "Invalid assignee")
+				return false
+			}
+			filter.AssigneeUserID = &id
+		}
+	}
+	return true
+}
+
 type opsResolveRequest struct {
 	Resolved bool `json:"resolved"`
 }
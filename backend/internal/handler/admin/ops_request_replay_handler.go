@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// OpsRequestReplayRequest 重放参数；upstream_index 为空时重放最后一次上游调用，account_id 为空时沿用原账号。
+type OpsRequestReplayRequest struct {
+	UpstreamIndex *int   `json:"upstream_index"`
+	AccountID     *int64 `json:"account_id"`
+}
+
+// ReplayRequest re-issues a captured upstream request of a traced request and stores the result.
+// :key matches request_id or client_request_id; the lookup window defaults to 7d.
+// POST /api/v1/admin/ops/requests/:key/replays
+func (h *OpsHandler) ReplayRequest(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	startTime, endTime, err := parseOpsTimeRange(c, "7d")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	var req OpsRequestReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	replay, err := h.opsService.ReplayRequestDump(c.Request.Context(), service.OpsRequestReplayInput{
+		Key:           c.Param("key"),
+		StartTime:     startTime,
+		EndTime:       endTime,
+		UpstreamIndex: req.UpstreamIndex,
+		AccountID:     req.AccountID,
+	}, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, replay)
+}
+
+// ListRequestReplays returns stored replay results of a request.
+// GET /api/v1/admin/ops/requests/:key/replays
+func (h *OpsHandler) ListRequestReplays(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	items, err := h.opsService.ListRequestReplays(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, items)
+}
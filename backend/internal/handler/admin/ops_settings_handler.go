@@ -2,6 +2,7 @@ package admin
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
@@ -271,3 +272,93 @@ func (h *OpsHandler) UpdateMetricThresholds(c *gin.Context) {
 	}
 	response.Success(c, updated)
 }
+
+// GetWeeklyDigestPreview builds the weekly usage & health digest on demand (same data as the scheduled email).
+// GET /api/v1/admin/ops/reports/weekly-digest
+func (h *OpsHandler) GetWeeklyDigestPreview(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	cfg, err := h.opsService.GetEmailNotificationConfig(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get email notification config")
+		return
+	}
+	digest, err := h.opsService.BuildWeeklyDigest(c.Request.Context(), time.Now(), service.OpsWeeklyDigestOptions{
+		QuotaThresholdPercent: cfg.Report.DigestQuotaThresholdPercent,
+		ExpiringWithinDays:    cfg.Report.DigestExpiringWithinDays,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, digest)
+}
+
+// GetReportSubscription returns the current admin's report subscription (null when using global recipients).
+// GET /api/v1/admin/ops/report-subscription
+func (h *OpsHandler) GetReportSubscription(c *gin.Context) {
+	userID, ok := h.reportSubscriptionUserID(c)
+	if !ok {
+		return
+	}
+	sub, err := h.opsService.GetReportSubscription(c.Request.Context(), userID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, sub)
+}
+
+// UpdateReportSubscription creates or replaces the current admin's report subscription.
+// PUT /api/v1/admin/ops/report-subscription
+func (h *OpsHandler) UpdateReportSubscription(c *gin.Context) {
+	userID, ok := h.reportSubscriptionUserID(c)
+	if !ok {
+		return
+	}
+	var req service.OpsReportSubscriptionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	sub, err := h.opsService.UpdateReportSubscription(c.Request.Context(), userID, &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, sub)
+}
+
+// DeleteReportSubscription removes the current admin's subscription (falls back to global recipients).
+// DELETE /api/v1/admin/ops/report-subscription
+func (h *OpsHandler) DeleteReportSubscription(c *gin.Context) {
+	userID, ok := h.reportSubscriptionUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.opsService.DeleteReportSubscription(c.Request.Context(), userID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"deleted": true})
+}
+
+func (h *OpsHandler) reportSubscriptionUserID(c *gin.Context) (int64, bool) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return 0, false
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Unauthorized(c, "User not authenticated")
+		return 0, false
+	}
+	return subject.UserID, true
+}
@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRequestStreamTimings returns sampled per-event stream timing summaries of a request.
+// :key matches request_id (upstream request id) or client_request_id.
+// GET /api/v1/admin/ops/requests/:key/stream-timings
+func (h *OpsHandler) ListRequestStreamTimings(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	items, err := h.opsService.ListStreamTimings(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, items)
+}
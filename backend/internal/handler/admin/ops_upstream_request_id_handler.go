@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ListUpstreamRequestIDs looks up upstream request id <-> client_request_id mappings.
+// ?key matches either the upstream request id (x-request-id / request-id) or client_request_id.
+// GET /api/v1/admin/ops/upstream-request-ids
+func (h *OpsHandler) ListUpstreamRequestIDs(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	items, err := h.opsService.ListUpstreamRequestIDs(c.Request.Context(), c.Query("key"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, items)
+}
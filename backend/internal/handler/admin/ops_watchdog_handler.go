@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// GetWatchdogStatus returns watchdog thresholds, current readings and stored dumps of this instance.
+// GET /api/v1/admin/ops/watchdog
+func (h *OpsHandler) GetWatchdogStatus(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	status, err := h.opsService.GetWatchdogStatus(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// CaptureWatchdogDump captures a goroutine/heap dump immediately.
+// POST /api/v1/admin/ops/watchdog/dumps
+func (h *OpsHandler) CaptureWatchdogDump(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	dump, err := h.opsService.CaptureWatchdogDump(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, dump)
+}
+
+// DownloadWatchdogDump downloads a dump archive (zip with meta.json, goroutine.txt, heap.pb.gz).
+// GET /api/v1/admin/ops/watchdog/dumps/:id/download
+func (h *OpsHandler) DownloadWatchdogDump(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	id := c.Param("id")
+	path, err := h.opsService.WatchdogDumpPath(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	// id 已通过格式校验，可直接用作文件名
+	c.FileAttachment(path, "ops-watchdog-"+id+".zip")
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestClassSLOHandler handles latency SLO reporting split by request class (interactive / batch).
+type RequestClassSLOHandler struct {
+	requestClassSLOService *service.RequestClassSLOService
+}
+
+// NewRequestClassSLOHandler creates a new RequestClassSLOHandler.
+func NewRequestClassSLOHandler(requestClassSLOService *service.RequestClassSLOService) *RequestClassSLOHandler {
+	return &RequestClassSLOHandler{requestClassSLOService: requestClassSLOService}
+}
+
+// GetReport returns first-token / duration percentiles and SLO compliance per request class
+// GET /api/v1/admin/ops/request-class-slo?time_range=24h&start_time=&end_time=&group_id=1&model=claude-sonnet-4-6
+func (h *RequestClassSLOHandler) GetReport(c *gin.Context) {
+	startTime, endTime, err := parseOpsTimeRange(c, "24h")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	filter := service.RequestClassSLOFilter{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Model:     strings.TrimSpace(c.Query("model")),
+	}
+	if v := strings.TrimSpace(c.Query("group_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid group_id")
+			return
+		}
+		filter.GroupID = id
+	}
+
+	report, err := h.requestClassSLOService.Report(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
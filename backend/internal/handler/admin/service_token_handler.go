@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceTokenHandler handles admin service token management for non-interactive admin API access
+type ServiceTokenHandler struct {
+	serviceTokenService *service.AdminServiceTokenService
+}
+
+// NewServiceTokenHandler creates a new admin service token handler
+func NewServiceTokenHandler(serviceTokenService *service.AdminServiceTokenService) *ServiceTokenHandler {
+	return &ServiceTokenHandler{serviceTokenService: serviceTokenService}
+}
+
+type createServiceTokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// List returns all service tokens without their secrets
+// GET /api/v1/admin/service-tokens
+func (h *ServiceTokenHandler) List(c *gin.Context) {
+	tokens, err := h.serviceTokenService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// Create handles creating a service token; the plaintext token is only returned here
+// POST /api/v1/admin/service-tokens
+func (h *ServiceTokenHandler) Create(c *gin.Context) {
+	var req createServiceTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	created, err := h.serviceTokenService.Create(c.Request.Context(), subject.UserID, service.AdminServiceTokenInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, created)
+}
+
+// Revoke handles revoking a service token
+// POST /api/v1/admin/service-tokens/:id/revoke
+func (h *ServiceTokenHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid service token ID")
+		return
+	}
+	token, err := h.serviceTokenService.Revoke(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, token)
+}
+
+// Delete handles deleting a service token
+// DELETE /api/v1/admin/service-tokens/:id
+func (h *ServiceTokenHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid service token ID")
+		return
+	}
+	if err := h.serviceTokenService.Delete(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Service token deleted successfully"})
+}
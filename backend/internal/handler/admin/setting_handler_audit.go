@@ -24,6 +24,7 @@ func (h *SettingHandler) auditSettingsUpdate(c *gin.Context, before *service.Sys
 	role, _ := middleware.GetUserRoleFromContext(c)
 	slog.Info("settings updated",
 		"audit", true,
+		"component", "audit.settings_change",
 		"user_id", subject.UserID,
 		"role", role,
 		"changed", changed,
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"strconv"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SpendBudgetHandler handles admin spend budget management
+type SpendBudgetHandler struct {
+	spendBudgetService *service.SpendBudgetService
+}
+
+// NewSpendBudgetHandler creates a new admin spend budget handler
+func NewSpendBudgetHandler(spendBudgetService *service.SpendBudgetService) *SpendBudgetHandler {
+	return &SpendBudgetHandler{spendBudgetService: spendBudgetService}
+}
+
+type createSpendBudgetRequest struct {
+	ScopeType string  `json:"scope_type" binding:"required,oneof=user group api_key"`
+	ScopeID   int64   `json:"scope_id" binding:"required,gt=0"`
+	Period    string  `json:"period" binding:"required,oneof=daily weekly monthly"`
+	LimitUSD  float64 `json:"limit_usd" binding:"required,gt=0"`
+	Enabled   *bool   `json:"enabled"`
+}
+
+type updateSpendBudgetRequest struct {
+	LimitUSD *float64 `json:"limit_usd" binding:"omitempty,gt=0"`
+	Enabled  *bool    `json:"enabled"`
+}
+
+// List returns spend budgets with spent and remaining amounts for the current period
+// GET /api/v1/admin/spend-budgets?scope_type=user&scope_id=1
+func (h *SpendBudgetHandler) List(c *gin.Context) {
+	scopeType := c.Query("scope_type")
+	var scopeID int64
+	if raw := c.Query("scope_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid scope_id")
+			return
+		}
+		scopeID = id
+	}
+
+	statuses, err := h.spendBudgetService.ListStatus(c.Request.Context(), scopeType, scopeID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, statuses)
+}
+
+// Create handles creating a spend budget
+// POST /api/v1/admin/spend-budgets
+func (h *SpendBudgetHandler) Create(c *gin.Context) {
+	var req createSpendBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	budget, err := h.spendBudgetService.Create(c.Request.Context(), &service.SpendBudget{
+		ScopeType: req.ScopeType,
+		ScopeID:   req.ScopeID,
+		Period:    req.Period,
+		LimitUSD:  req.LimitUSD,
+		Enabled:   enabled,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, budget)
+}
+
+// Update handles updating a spend budget's limit or enabled state
+// PUT /api/v1/admin/spend-budgets/:id
+func (h *SpendBudgetHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid budget ID")
+		return
+	}
+	var req updateSpendBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFrom(c, infraerrors.BadRequest("VALIDATION_ERROR", err.Error()))
+		return
+	}
+
+	budget, err := h.spendBudgetService.Update(c.Request.Context(), id, req.LimitUSD, req.Enabled)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, budget)
+}
+
+// Delete handles deleting a spend budget
+// DELETE /api/v1/admin/spend-budgets/:id
+func (h *SpendBudgetHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid budget ID")
+		return
+	}
+	if err := h.spendBudgetService.Delete(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Spend budget deleted successfully"})
+}
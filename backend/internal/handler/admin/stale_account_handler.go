@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaleAccountHandler handles stale account detection and archival.
+type StaleAccountHandler struct {
+	staleAccountService *service.StaleAccountService
+}
+
+// NewStaleAccountHandler creates a new StaleAccountHandler.
+func NewStaleAccountHandler(staleAccountService *service.StaleAccountService) *StaleAccountHandler {
+	return &StaleAccountHandler{staleAccountService: staleAccountService}
+}
+
+// GetReport lists accounts idle for N days and the capacity archiving them would reclaim
+// GET /api/v1/admin/accounts/stale?days=30
+func (h *StaleAccountHandler) GetReport(c *gin.Context) {
+	days, ok := parseOptionalPositiveInt(c, "days")
+	if !ok {
+		return
+	}
+
+	report, err := h.staleAccountService.Report(c.Request.Context(), days)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// ArchiveStaleAccountsRequest represents the request body for bulk archiving accounts.
+type ArchiveStaleAccountsRequest struct {
+	AccountIDs []int64 `json:"account_ids" binding:"required,min=1,max=500"`
+}
+
+// ArchiveStaleAccountsResult reports per-account outcomes of a bulk archive.
+type ArchiveStaleAccountsResult struct {
+	Archived []int64          `json:"archived"`
+	Failed   map[int64]string `json:"failed"`
+}
+
+// BulkArchive archives the given accounts, continuing past individual failures
+// POST /api/v1/admin/accounts/stale/archive
+func (h *StaleAccountHandler) BulkArchive(c *gin.Context) {
+	var req ArchiveStaleAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	result := ArchiveStaleAccountsResult{Archived: []int64{}, Failed: map[int64]string{}}
+	for _, id := range req.AccountIDs {
+		if _, err := h.staleAccountService.Archive(c.Request.Context(), id); err != nil {
+			result.Failed[id] = err.Error()
+			continue
+		}
+		result.Archived = append(result.Archived, id)
+	}
+	response.Success(c, result)
+}
+
+// Archive archives an account: excluded from routing, credentials sealed with the server key
+// POST /api/v1/admin/accounts/:id/archive
+func (h *StaleAccountHandler) Archive(c *gin.Context) {
+	h.transition(c, h.staleAccountService.Archive)
+}
+
+// Unarchive restores an archived account's credentials and previous status
+// POST /api/v1/admin/accounts/:id/unarchive
+func (h *StaleAccountHandler) Unarchive(c *gin.Context) {
+	h.transition(c, h.staleAccountService.Unarchive)
+}
+
+func (h *StaleAccountHandler) transition(c *gin.Context, fn func(ctx context.Context, id int64) (*service.Account, error)) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	account, err := fn(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AccountFromService(account))
+}
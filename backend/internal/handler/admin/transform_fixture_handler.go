@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransformFixtureHandler handles transform test fixtures and their dry runs.
+type TransformFixtureHandler struct {
+	transformFixtureService *service.TransformFixtureService
+}
+
+// NewTransformFixtureHandler creates a new TransformFixtureHandler.
+func NewTransformFixtureHandler(transformFixtureService *service.TransformFixtureService) *TransformFixtureHandler {
+	return &TransformFixtureHandler{transformFixtureService: transformFixtureService}
+}
+
+// List returns all saved fixtures with their latest run status
+// GET /api/v1/admin/transform-fixtures
+func (h *TransformFixtureHandler) List(c *gin.Context) {
+	fixtures, err := h.transformFixtureService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, fixtures)
+}
+
+// GetByID returns a fixture
+// GET /api/v1/admin/transform-fixtures/:id
+func (h *TransformFixtureHandler) GetByID(c *gin.Context) {
+	id, ok := parseTransformFixtureID(c)
+	if !ok {
+		return
+	}
+	fixture, err := h.transformFixtureService.Get(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, fixture)
+}
+
+// Create uploads a fixture
+// POST /api/v1/admin/transform-fixtures
+func (h *TransformFixtureHandler) Create(c *gin.Context) {
+	var req service.TransformFixtureInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	var createdBy int64
+	if subject, ok := middleware.GetAuthSubjectFromContext(c); ok {
+		createdBy = subject.UserID
+	}
+	fixture, err := h.transformFixtureService.Create(c.Request.Context(), req, createdBy)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, fixture)
+}
+
+// Update replaces a fixture and clears its latest run status
+// PUT /api/v1/admin/transform-fixtures/:id
+func (h *TransformFixtureHandler) Update(c *gin.Context) {
+	id, ok := parseTransformFixtureID(c)
+	if !ok {
+		return
+	}
+	var req service.TransformFixtureInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	fixture, err := h.transformFixtureService.Update(c.Request.Context(), id, req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, fixture)
+}
+
+// Delete removes a fixture
+// DELETE /api/v1/admin/transform-fixtures/:id
+func (h *TransformFixtureHandler) Delete(c *gin.Context) {
+	id, ok := parseTransformFixtureID(c)
+	if !ok {
+		return
+	}
+	if err := h.transformFixtureService.Delete(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Transform fixture deleted successfully"})
+}
+
+// Run dry-runs fixtures against the live transform pipeline; no upstream request is sent.
+// An empty body runs every saved fixture; "accounts" applies draft model mappings for pre-rollout checks.
+// POST /api/v1/admin/transform-fixtures/run
+func (h *TransformFixtureHandler) Run(c *gin.Context) {
+	var req service.TransformFixtureRunInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request: "+err.Error())
+			return
+		}
+	}
+	report, err := h.transformFixtureService.Run(c.Request.Context(), req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// RunOne dry-runs a single saved fixture
+// POST /api/v1/admin/transform-fixtures/:id/run
+func (h *TransformFixtureHandler) RunOne(c *gin.Context) {
+	id, ok := parseTransformFixtureID(c)
+	if !ok {
+		return
+	}
+	report, err := h.transformFixtureService.Run(c.Request.Context(), service.TransformFixtureRunInput{IDs: []int64{id}})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+func parseTransformFixtureID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid transform fixture ID")
+		return 0, false
+	}
+	return id, true
+}
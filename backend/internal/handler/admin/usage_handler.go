@@ -424,6 +424,28 @@ func (h *UsageHandler) SearchAPIKeys(c *gin.Context) {
 	response.Success(c, result)
 }
 
+// ThreadCost handles summing usage of all turns in a thread.
+// GET /api/v1/admin/usage/threads/:thread_id?user_id=
+// thread_id 由客户端自定义，不同用户可能重复；未指定 user_id 时汇总所有用户。
+func (h *UsageHandler) ThreadCost(c *gin.Context) {
+	var userID int64
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		userID = id
+	}
+
+	cost, err := h.usageService.GetThreadCost(c.Request.Context(), userID, c.Param("thread_id"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, cost)
+}
+
 // ListCleanupTasks handles listing usage cleanup tasks
 // GET /api/v1/admin/usage/cleanup-tasks
 func (h *UsageHandler) ListCleanupTasks(c *gin.Context) {
@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageReportHandler handles the admin multi-dimensional usage and cost report.
+type UsageReportHandler struct {
+	usageReportService *service.UsageReportService
+}
+
+// NewUsageReportHandler creates a new UsageReportHandler.
+func NewUsageReportHandler(usageReportService *service.UsageReportService) *UsageReportHandler {
+	return &UsageReportHandler{usageReportService: usageReportService}
+}
+
+// GetReport handles aggregating usage, error rate and cost by the requested dimensions and time bucket
+// GET /api/v1/admin/dashboard/usage-report?group_by=user,model&bucket=day&start_date=2026-01-01&end_date=2026-01-31&timezone=Asia/Shanghai&user_id=1&api_key_id=1&group_id=1&account_id=1&model=claude-sonnet-4-6&limit=100
+func (h *UsageReportHandler) GetReport(c *gin.Context) {
+	startTime, endTime := parseTimeRange(c)
+	query := service.UsageReportQuery{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Bucket:    c.Query("bucket"),
+		Timezone:  c.Query("timezone"),
+		Model:     c.Query("model"),
+	}
+	if raw := strings.TrimSpace(c.Query("group_by")); raw != "" {
+		query.Dimensions = strings.Split(raw, ",")
+	}
+	for _, filter := range []struct {
+		key  string
+		dest *int64
+	}{
+		{"user_id", &query.UserID},
+		{"api_key_id", &query.APIKeyID},
+		{"group_id", &query.GroupID},
+		{"account_id", &query.AccountID},
+	} {
+		raw := strings.TrimSpace(c.Query(filter.key))
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid "+filter.key)
+			return
+		}
+		*filter.dest = id
+	}
+	limit, ok := parseOptionalPositiveInt(c, "limit")
+	if !ok {
+		return
+	}
+	query.Limit = limit
+
+	report, err := h.usageReportService.Report(c.Request.Context(), query)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
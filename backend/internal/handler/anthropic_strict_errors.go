@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// anthropicStatusOverloaded Anthropic 过载专用状态码，Claude Code 据此（及 overloaded_error）退避重试。
+const anthropicStatusOverloaded = 529
+
+// anthropicStrictErrorTypes Anthropic error.type 的取值集合。
+var anthropicStrictErrorTypes = map[string]struct{}{
+	"invalid_request_error": {},
+	"authentication_error":  {},
+	"billing_error":         {},
+	"permission_error":      {},
+	"not_found_error":       {},
+	"request_too_large":     {},
+	"rate_limit_error":      {},
+	"api_error":             {},
+	"overloaded_error":      {},
+	"timeout_error":         {},
+}
+
+// anthropicStrictError 严格模式下的错误对象，仅含 type/message。
+type anthropicStrictError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// anthropicStrictErrorEnvelope Anthropic 错误信封，非流式错误体与流内 error 事件共用。
+type anthropicStrictErrorEnvelope struct {
+	Type  string               `json:"type"`
+	Error anthropicStrictError `json:"error"`
+}
+
+// anthropicStrictErrorTypeForStatus 返回 Anthropic 文档中与状态码一一对应的错误类型；无固定对应时返回空。
+func anthropicStrictErrorTypeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusPaymentRequired:
+		return "billing_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case http.StatusInternalServerError:
+		return "api_error"
+	case http.StatusServiceUnavailable, anthropicStatusOverloaded:
+		return "overloaded_error"
+	case http.StatusGatewayTimeout:
+		return "timeout_error"
+	default:
+		return ""
+	}
+}
+
+// normalizeAnthropicStrictErrorType 将内部/OpenAI 风格的错误类型映射为 Anthropic 类型。
+// 有固定对应的状态码以状态码为准（客户端的重试逻辑同时看状态码与类型，二者必须一致）；
+// 流内事件（status=0）与其他状态码沿用来源类型。
+func normalizeAnthropicStrictErrorType(errType string, status int) string {
+	if byStatus := anthropicStrictErrorTypeForStatus(status); byStatus != "" {
+		return byStatus
+	}
+	errType = strings.ToLower(strings.TrimSpace(errType))
+	if _, ok := anthropicStrictErrorTypes[errType]; ok {
+		return errType
+	}
+	switch errType {
+	case "rate_limit_exceeded", "requests", "tokens":
+		return "rate_limit_error"
+	case "insufficient_quota":
+		return "billing_error"
+	case "overloaded", "service_unavailable":
+		return "overloaded_error"
+	case "bad_request", "invalid_request", "conflict_error":
+		return "invalid_request_error"
+	case "server_error", "upstream_error", "internal_error":
+		return "api_error"
+	}
+	if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+		return "invalid_request_error"
+	}
+	return "api_error"
+}
+
+// parseAnthropicStrictError 从任意网关错误体中提取 Anthropic 错误对象，兼容的来源格式同 parseOpenAIStrictError。
+func parseAnthropicStrictError(status int, raw []byte) anthropicStrictError {
+	var top map[string]any
+	if err := json.Unmarshal(raw, &top); err != nil || top == nil {
+		return anthropicStrictError{
+			Type:    normalizeAnthropicStrictErrorType("", status),
+			Message: truncateStrictErrorMessage(status, raw),
+		}
+	}
+
+	src := top
+	message := ""
+	switch e := top["error"].(type) {
+	case map[string]any:
+		src = e
+	case string:
+		message = strings.TrimSpace(e)
+	}
+	if message == "" {
+		message = strictStringField(src, "message")
+	}
+	if message == "" {
+		message = strictStringField(top, "message")
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	if message == "" {
+		message = "Unknown error"
+	}
+
+	errType := strictStringField(src, "type")
+	if errType == "error" {
+		errType = ""
+	}
+	if errType == "" {
+		// 中间件错误体 {"code":"RATE_LIMITED",...} 仅携带原因码
+		if code, ok := src["code"].(string); ok {
+			errType = code
+		}
+	}
+	return anthropicStrictError{
+		Type:    normalizeAnthropicStrictErrorType(errType, status),
+		Message: message,
+	}
+}
+
+// anthropicStrictErrorBody 构造严格模式的非流式错误体。
+func anthropicStrictErrorBody(status int, raw []byte) []byte {
+	body, _ := json.Marshal(anthropicStrictErrorEnvelope{Type: "error", Error: parseAnthropicStrictError(status, raw)})
+	return body
+}
+
+// rewriteAnthropicStrictSSEEvent 将流内错误统一为 Anthropic 的 event: error + {"type":"error","error":{...}}，
+// 补齐缺失的 event 行（Claude Code 的 SDK 按事件名分派），其余事件原样返回。
+func rewriteAnthropicStrictSSEEvent(event []byte) []byte {
+	if !bytes.Contains(event, []byte(`"error"`)) {
+		return event
+	}
+	eventName, dataStr, ok := parseSSEEvent(string(event))
+	if !ok || (eventName != "" && eventName != "error") {
+		return event
+	}
+	data := []byte(dataStr)
+	var top map[string]any
+	if err := json.Unmarshal(data, &top); err != nil || top == nil {
+		return event
+	}
+	if eventName != "error" && top["error"] == nil && strictStringField(top, "type") != "error" {
+		return event
+	}
+
+	body, _ := json.Marshal(anthropicStrictErrorEnvelope{Type: "error", Error: parseAnthropicStrictError(0, data)})
+	return []byte(formatSSEEvent("error", string(body)))
+}
+
+// anthropicStrictErrorSchema Anthropic 协议的严格错误 schema。
+type anthropicStrictErrorSchema struct{}
+
+func (anthropicStrictErrorSchema) matches(endpoint string) bool {
+	return endpoint == EndpointMessages
+}
+
+func (anthropicStrictErrorSchema) errorBody(status int, raw []byte) []byte {
+	return anthropicStrictErrorBody(status, raw)
+}
+
+func (anthropicStrictErrorSchema) sseEvent(event []byte, _ string) []byte {
+	return rewriteAnthropicStrictSSEEvent(event)
+}
+
+// AnthropicStrictErrorMiddleware Anthropic 兼容端点（/v1/messages 及 count_tokens）的严格错误体模式，
+// 注册位置与 OpenAIStrictErrorMiddleware 相同。gateway.anthropic_strict_errors=false 时为空操作。
+func AnthropicStrictErrorMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return strictErrorMiddleware(cfg != nil && cfg.Gateway.AnthropicStrictErrors, anthropicStrictErrorSchema{})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func serveAnthropicStrictErrors(t *testing.T, enabled bool, path string, h gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.AnthropicStrictErrors = enabled
+	r := gin.New()
+	r.Use(AnthropicStrictErrorMiddleware(cfg), InboundEndpointMiddleware())
+	r.POST(path, h)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	return rec
+}
+
+// requireAnthropicErrorEnvelope 断言错误体恰好为 {"type":"error","error":{"type","message"}}，且类型符合 Anthropic schema。
+func requireAnthropicErrorEnvelope(t *testing.T, body []byte) map[string]any {
+	t.Helper()
+	var top map[string]any
+	require.NoError(t, json.Unmarshal(body, &top), string(body))
+	require.Len(t, top, 2, string(body))
+	require.Equal(t, "error", top["type"])
+	obj, ok := top["error"].(map[string]any)
+	require.True(t, ok, string(body))
+	require.Len(t, obj, 2, string(body))
+	require.IsType(t, "", obj["message"])
+	require.NotEmpty(t, obj["message"])
+	_, known := anthropicStrictErrorTypes[obj["type"].(string)]
+	require.True(t, known, "unexpected error type %q", obj["type"])
+	return obj
+}
+
+func TestAnthropicStrictErrors_JSONErrorShapesContract(t *testing.T) {
+	cases := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		status   int
+		wantType string
+	}{
+		{
+			name: "no_available_accounts_is_overloaded",
+			handler: func(c *gin.Context) {
+				(&GatewayHandler{}).errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts")
+			},
+			status:   http.StatusServiceUnavailable,
+			wantType: "overloaded_error",
+		},
+		{
+			name: "upstream_overloaded_529",
+			handler: func(c *gin.Context) {
+				c.Data(anthropicStatusOverloaded, "application/json", []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"},"request_id":"req_1"}`))
+			},
+			status:   anthropicStatusOverloaded,
+			wantType: "overloaded_error",
+		},
+		{
+			name: "concurrency_limit_is_rate_limit",
+			handler: func(c *gin.Context) {
+				middleware2.AbortWithError(c, http.StatusTooManyRequests, "CONCURRENCY_LIMIT", "Too many concurrent requests")
+			},
+			status:   http.StatusTooManyRequests,
+			wantType: "rate_limit_error",
+		},
+		{
+			name: "group_writer_type_follows_status",
+			handler: func(c *gin.Context) {
+				middleware2.AnthropicErrorWriter(c, http.StatusBadRequest, "bad")
+			},
+			status:   http.StatusBadRequest,
+			wantType: "invalid_request_error",
+		},
+		{
+			name: "openai_shaped_body",
+			handler: func(c *gin.Context) {
+				c.Data(http.StatusBadGateway, "application/json", []byte(`{"error":{"message":"Upstream request failed","type":"upstream_error","param":null,"code":null}}`))
+			},
+			status:   http.StatusBadGateway,
+			wantType: "api_error",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serveAnthropicStrictErrors(t, true, "/v1/messages", tc.handler)
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+			obj := requireAnthropicErrorEnvelope(t, rec.Body.Bytes())
+			require.Equal(t, tc.wantType, obj["type"])
+		})
+	}
+}
+
+func TestAnthropicStrictErrors_StreamErrorContract(t *testing.T) {
+	rec := serveAnthropicStrictErrors(t, true, "/v1/messages", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"m1\"}}\n\n")
+		c.Writer.Flush()
+		(&GatewayHandler{}).handleStreamingAwareError(c, http.StatusServiceUnavailable, "overloaded", "Overloaded", true)
+	})
+
+	events := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+	require.Len(t, events, 2)
+	require.Equal(t, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"m1\"}}", events[0], "non-error events pass through untouched")
+	require.True(t, strings.HasPrefix(events[1], "event: error\ndata: "), "Anthropic streams errors as named error events: %q", events[1])
+	obj := requireAnthropicErrorEnvelope(t, []byte(strings.TrimPrefix(events[1], "event: error\ndata: ")))
+	require.Equal(t, "overloaded_error", obj["type"])
+	require.Equal(t, "Overloaded", obj["message"])
+}
+
+func TestAnthropicStrictErrors_ScopeAndDisabled(t *testing.T) {
+	rec := serveAnthropicStrictErrors(t, true, "/v1/chat/completions", func(c *gin.Context) {
+		middleware2.AbortWithError(c, http.StatusUnauthorized, "INVALID_API_KEY", "Invalid API key")
+	})
+	require.JSONEq(t, `{"code":"INVALID_API_KEY","message":"Invalid API key"}`, rec.Body.String(), "OpenAI endpoints are left to their own schema")
+
+	rec = serveAnthropicStrictErrors(t, false, "/v1/messages", func(c *gin.Context) {
+		middleware2.AbortWithError(c, http.StatusUnauthorized, "INVALID_API_KEY", "Invalid API key")
+	})
+	require.JSONEq(t, `{"code":"INVALID_API_KEY","message":"Invalid API key"}`, rec.Body.String())
+
+	rec = serveAnthropicStrictErrors(t, true, "/v1/messages/count_tokens", func(c *gin.Context) {
+		middleware2.AbortWithError(c, http.StatusUnauthorized, "INVALID_API_KEY", "Invalid API key")
+	})
+	obj := requireAnthropicErrorEnvelope(t, rec.Body.Bytes())
+	require.Equal(t, "authentication_error", obj["type"])
+}
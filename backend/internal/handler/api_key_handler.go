@@ -42,6 +42,21 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h *float64 `json:"rate_limit_5h"`
 	RateLimit1d *float64 `json:"rate_limit_1d"`
 	RateLimit7d *float64 `json:"rate_limit_7d"`
+
+	// Throughput limit fields (0 = unlimited)
+	RPMLimit int   `json:"rpm_limit"`
+	TPMLimit int64 `json:"tpm_limit"`
+}
+
+// CreateGuestAPIKeyRequest represents the create guest API key request payload
+type CreateGuestAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	GroupID       *int64   `json:"group_id"`
+	TTLMinutes    int      `json:"ttl_minutes"`    // 有效期（分钟），与 ttl_hours 相加
+	TTLHours      int      `json:"ttl_hours"`      // 有效期（小时）
+	TokenLimit    int64    `json:"token_limit"`    // Token 总量上限，0=不限制
+	Quota         float64  `json:"quota"`          // 配额限制 (USD)，0=不限制
+	AllowedModels []string `json:"allowed_models"` // 允许的模型，支持尾部 * 通配
 }
 
 // UpdateAPIKeyRequest represents the update API key request payload
@@ -60,6 +75,10 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // 重置限速用量
+
+	// Throughput limit fields (nil = no change, 0 = unlimited)
+	RPMLimit *int   `json:"rpm_limit"`
+	TPMLimit *int64 `json:"tpm_limit"`
 }
 
 // List handles listing user's API keys with pagination
@@ -160,6 +179,8 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 		IPWhitelist:   req.IPWhitelist,
 		IPBlacklist:   req.IPBlacklist,
 		ExpiresInDays: req.ExpiresInDays,
+		RPMLimit:      req.RPMLimit,
+		TPMLimit:      req.TPMLimit,
 	}
 	if req.Quota != nil {
 		svcReq.Quota = *req.Quota
@@ -183,6 +204,45 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	})
 }
 
+// CreateGuest handles minting a short-lived guest API key
+// POST /api/v1/keys/guest
+func (h *APIKeyHandler) CreateGuest(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req CreateGuestAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	// 先按单位校验范围，避免相加前 Duration 溢出绕过上限
+	if req.TTLMinutes < 0 || req.TTLHours < 0 ||
+		req.TTLMinutes > int(service.GuestAPIKeyMaxTTL/time.Minute) || req.TTLHours > int(service.GuestAPIKeyMaxTTL/time.Hour) {
+		response.ErrorFrom(c, service.ErrGuestAPIKeyInvalidTTL)
+		return
+	}
+
+	svcReq := service.CreateGuestAPIKeyRequest{
+		Name:          req.Name,
+		GroupID:       req.GroupID,
+		TTL:           time.Duration(req.TTLHours)*time.Hour + time.Duration(req.TTLMinutes)*time.Minute,
+		TokenLimit:    req.TokenLimit,
+		Quota:         req.Quota,
+		AllowedModels: req.AllowedModels,
+	}
+
+	executeUserIdempotentJSON(c, "user.api_keys.create_guest", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		key, err := h.apiKeyService.CreateGuest(ctx, subject.UserID, svcReq)
+		if err != nil {
+			return nil, err
+		}
+		return dto.APIKeyFromService(key), nil
+	})
+}
+
 // Update handles updating an API key
 // PUT /api/v1/api-keys/:id
 func (h *APIKeyHandler) Update(c *gin.Context) {
@@ -213,6 +273,8 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 		RateLimit1d:         req.RateLimit1d,
 		RateLimit7d:         req.RateLimit7d,
 		ResetRateLimitUsage: req.ResetRateLimitUsage,
+		RPMLimit:            req.RPMLimit,
+		TPMLimit:            req.TPMLimit,
 	}
 	if req.Name != "" {
 		svcReq.Name = &req.Name
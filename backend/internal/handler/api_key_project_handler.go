@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyProjectHandler 处理 API Key 项目：多个 Key 共享项目额度并合并用量报表。
+type APIKeyProjectHandler struct {
+	projectService *service.APIKeyProjectService
+}
+
+// NewAPIKeyProjectHandler creates a new APIKeyProjectHandler
+func NewAPIKeyProjectHandler(projectService *service.APIKeyProjectService) *APIKeyProjectHandler {
+	return &APIKeyProjectHandler{projectService: projectService}
+}
+
+// APIKeyProjectRequest 创建/更新项目的请求体，quota 为项目合计额度（USD，0 = 不限制）。
+type APIKeyProjectRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Quota       float64 `json:"quota"`
+}
+
+func (r *APIKeyProjectRequest) toInput() service.APIKeyProjectInput {
+	return service.APIKeyProjectInput{Name: r.Name, Description: r.Description, Quota: r.Quota}
+}
+
+// APIKeyProjectKeysRequest 加入/移出项目的 Key 列表。
+type APIKeyProjectKeysRequest struct {
+	APIKeyIDs []int64 `json:"api_key_ids" binding:"required"`
+}
+
+// List handles listing the current user's api key projects
+// GET /api/v1/projects
+func (h *APIKeyProjectHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projects, err := h.projectService.List(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, projects)
+}
+
+// GetByID handles getting a single project
+// GET /api/v1/projects/:id
+func (h *APIKeyProjectHandler) GetByID(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, ok := parseAPIKeyProjectID(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.projectService.Get(c.Request.Context(), subject.UserID, projectID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, project)
+}
+
+// Create handles creating a project
+// POST /api/v1/projects
+func (h *APIKeyProjectHandler) Create(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req APIKeyProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	project, err := h.projectService.Create(c.Request.Context(), subject.UserID, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, project)
+}
+
+// Update handles updating a project
+// PUT /api/v1/projects/:id
+func (h *APIKeyProjectHandler) Update(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, ok := parseAPIKeyProjectID(c)
+	if !ok {
+		return
+	}
+
+	var req APIKeyProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	project, err := h.projectService.Update(c.Request.Context(), subject.UserID, projectID, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, project)
+}
+
+// Delete handles deleting a project; member keys are kept and detached
+// DELETE /api/v1/projects/:id
+func (h *APIKeyProjectHandler) Delete(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, ok := parseAPIKeyProjectID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.Delete(c.Request.Context(), subject.UserID, projectID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Project deleted successfully"})
+}
+
+// AddKeys handles moving keys into a project
+// POST /api/v1/projects/:id/keys
+func (h *APIKeyProjectHandler) AddKeys(c *gin.Context) {
+	h.updateKeys(c, true)
+}
+
+// RemoveKeys handles detaching keys from a project
+// DELETE /api/v1/projects/:id/keys
+func (h *APIKeyProjectHandler) RemoveKeys(c *gin.Context) {
+	h.updateKeys(c, false)
+}
+
+func (h *APIKeyProjectHandler) updateKeys(c *gin.Context, add bool) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, ok := parseAPIKeyProjectID(c)
+	if !ok {
+		return
+	}
+
+	var req APIKeyProjectKeysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	var (
+		project *service.APIKeyProject
+		err     error
+	)
+	if add {
+		project, err = h.projectService.AddKeys(c.Request.Context(), subject.UserID, projectID, req.APIKeyIDs)
+	} else {
+		project, err = h.projectService.RemoveKeys(c.Request.Context(), subject.UserID, projectID, req.APIKeyIDs)
+	}
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, project)
+}
+
+// ResetQuota handles clearing the project's used quota
+// POST /api/v1/projects/:id/reset-quota
+func (h *APIKeyProjectHandler) ResetQuota(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, ok := parseAPIKeyProjectID(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.projectService.ResetQuota(c.Request.Context(), subject.UserID, projectID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, project)
+}
+
+// Usage handles the combined usage report of a project
+// GET /api/v1/projects/:id/usage?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=
+// 日期区间为闭区间，默认最近 30 天（含今天）。
+func (h *APIKeyProjectHandler) Usage(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, ok := parseAPIKeyProjectID(c)
+	if !ok {
+		return
+	}
+
+	userTZ := c.Query("timezone")
+	now := timezone.NowInUserLocation(userTZ)
+	startTime := timezone.StartOfDayInUserLocation(now.AddDate(0, 0, -29), userTZ)
+	endTime := timezone.StartOfDayInUserLocation(now.AddDate(0, 0, 1), userTZ)
+	if startDateStr := strings.TrimSpace(c.Query("start_date")); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		startTime = t
+	}
+	if endDateStr := strings.TrimSpace(c.Query("end_date")); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		endTime = t.AddDate(0, 0, 1)
+	}
+
+	usage, err := h.projectService.GetUsage(c.Request.Context(), subject.UserID, projectID, startTime, endTime)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, usage)
+}
+
+func parseAPIKeyProjectID(c *gin.Context) (int64, bool) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || projectID <= 0 {
+		response.BadRequest(c, "Invalid project ID")
+		return 0, false
+	}
+	return projectID, true
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyTraceRedactedHeaders 追踪记录中需要脱敏的请求/响应头（小写）。
+var apiKeyTraceRedactedHeaders = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"x-api-key":           {},
+	"x-goog-api-key":      {},
+	"cookie":              {},
+	"set-cookie":          {},
+}
+
+// apiKeyTraceWriter 在追踪期间旁路复制响应体（含 SSE 流），超出上限后只计截断标记。
+type apiKeyTraceWriter struct {
+	gin.ResponseWriter
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (w *apiKeyTraceWriter) capture(b []byte) {
+	if w.truncated {
+		return
+	}
+	remaining := w.limit - w.buf.Len()
+	if len(b) > remaining {
+		_, _ = w.buf.Write(b[:remaining])
+		w.truncated = true
+		return
+	}
+	_, _ = w.buf.Write(b)
+}
+
+func (w *apiKeyTraceWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *apiKeyTraceWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// APIKeyTraceMiddleware 对处于追踪中的 API Key 抓取完整请求/响应并保存；未追踪的 Key 仅多一次本地缓存查询。
+// 需注册在 API Key 鉴权之后。
+func APIKeyTraceMiddleware(traceService *service.APIKeyTraceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if traceService == nil {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil {
+			c.Next()
+			return
+		}
+		if traceService.ActiveSession(c.Request.Context(), apiKey.ID) == nil {
+			c.Next()
+			return
+		}
+
+		maxBytes := traceService.MaxBodyBytes()
+		var requestBody []byte
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			// 读取失败（如超过 body 限制）时仍把已读部分还给下游，由下游返回原有错误。
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), apiKeyTraceErrReader{err: err}))
+			requestBody = body
+		}
+
+		var upstreamCapture *service.APIKeyTraceUpstreamCapture
+		if traceService.CaptureUpstreamResponse() {
+			upstreamCapture = service.NewAPIKeyTraceUpstreamCapture(maxBytes)
+			c.Request = c.Request.WithContext(service.WithAPIKeyTraceUpstreamCapture(c.Request.Context(), upstreamCapture))
+		}
+
+		startedAt := time.Now()
+		originalWriter := c.Writer
+		w := &apiKeyTraceWriter{ResponseWriter: originalWriter, limit: maxBytes}
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+		}()
+
+		c.Next()
+
+		record := &service.APIKeyTraceRecord{
+			APIKeyID:        apiKey.ID,
+			Method:          c.Request.Method,
+			Path:            c.Request.URL.Path,
+			StatusCode:      w.Status(),
+			DurationMs:      time.Since(startedAt).Milliseconds(),
+			RequestHeaders:  apiKeyTraceHeaders(c.Request.Header),
+			ResponseHeaders: apiKeyTraceHeaders(w.Header()),
+			ResponseBody:    w.buf.String(),
+			CreatedAt:       startedAt,
+		}
+		record.ResponseBodyTruncated = w.truncated
+		record.UpstreamResponses, record.UpstreamResponsesDropped = upstreamCapture.Responses(apiKeyTraceHeaders)
+		if len(requestBody) > maxBytes {
+			record.RequestBody = string(requestBody[:maxBytes])
+			record.RequestBodyTruncated = true
+		} else {
+			record.RequestBody = string(requestBody)
+		}
+		record.RequestID, _ = c.Request.Context().Value(ctxkey.RequestID).(string)
+		if record.RequestID == "" {
+			record.RequestID = w.Header().Get("X-Request-Id")
+		}
+		record.ClientRequestID, _ = c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+		record.Stream = strings.HasPrefix(strings.ToLower(w.Header().Get("Content-Type")), "text/event-stream")
+		if v, ok := c.Get(opsModelKey); ok {
+			record.Model, _ = v.(string)
+		}
+		if v, ok := c.Get(opsAccountIDKey); ok {
+			if id, ok := v.(int64); ok && id > 0 {
+				record.AccountID = &id
+			}
+		}
+
+		traceService.RecordTrace(record)
+	}
+}
+
+type apiKeyTraceErrReader struct {
+	err error
+}
+
+func (r apiKeyTraceErrReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+func apiKeyTraceHeaders(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if _, sensitive := apiKeyTraceRedactedHeaders[strings.ToLower(name)]; sensitive {
+			out[name] = "***"
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
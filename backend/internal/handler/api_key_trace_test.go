@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyTraceCacheRecorder struct {
+	mu       sync.Mutex
+	session  *service.APIKeyTraceSession
+	recorded chan *service.APIKeyTraceRecord
+}
+
+func (r *apiKeyTraceCacheRecorder) GetTraceSession(context.Context, int64) (*service.APIKeyTraceSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.session, nil
+}
+
+func (r *apiKeyTraceCacheRecorder) SetTraceSession(_ context.Context, session *service.APIKeyTraceSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session = session
+	return nil
+}
+
+func (r *apiKeyTraceCacheRecorder) DeleteTraceSession(context.Context, int64) error { return nil }
+
+func (r *apiKeyTraceCacheRecorder) ListTraceSessions(context.Context) ([]*service.APIKeyTraceSession, error) {
+	return nil, nil
+}
+
+func (r *apiKeyTraceCacheRecorder) AppendTraceRecord(_ context.Context, record *service.APIKeyTraceRecord, _ int, _ time.Duration) error {
+	r.recorded <- record
+	return nil
+}
+
+func (r *apiKeyTraceCacheRecorder) ListTraceRecords(context.Context, int64, int) ([]*service.APIKeyTraceRecord, error) {
+	return nil, nil
+}
+
+func newAPIKeyTraceTestRouter(traceService *service.APIKeyTraceService, keyID int64, h gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: keyID})
+		c.Next()
+	})
+	r.Use(APIKeyTraceMiddleware(traceService))
+	r.POST("/v1/messages", h)
+	return r
+}
+
+func TestAPIKeyTraceMiddleware_CapturesTracedKey(t *testing.T) {
+	cache := &apiKeyTraceCacheRecorder{recorded: make(chan *service.APIKeyTraceRecord, 1)}
+	traceService := service.NewAPIKeyTraceService(cache, nil, nil, &config.Config{})
+	_, err := traceService.StartTrace(context.Background(), 11, time.Minute, "", 1)
+	require.NoError(t, err)
+
+	r := newAPIKeyTraceTestRouter(traceService, 11, func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		require.Equal(t, `{"model":"claude","stream":true}`, string(body), "downstream must still see the body")
+		setOpsSelectedAccount(c, 99)
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: {\"type\":\"message_start\"}\n\n")
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","stream":true}`))
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	select {
+	case record := <-cache.recorded:
+		require.Equal(t, int64(11), record.APIKeyID)
+		require.Equal(t, `{"model":"claude","stream":true}`, record.RequestBody)
+		require.Equal(t, rec.Body.String(), record.ResponseBody)
+		require.True(t, record.Stream)
+		require.Equal(t, http.StatusOK, record.StatusCode)
+		require.Equal(t, "***", record.RequestHeaders["Authorization"])
+		require.NotNil(t, record.AccountID)
+		require.Equal(t, int64(99), *record.AccountID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("trace record was not stored")
+	}
+}
+
+func TestAPIKeyTraceMiddleware_SkipsUntracedKey(t *testing.T) {
+	cache := &apiKeyTraceCacheRecorder{recorded: make(chan *service.APIKeyTraceRecord, 1)}
+	traceService := service.NewAPIKeyTraceService(cache, nil, nil, &config.Config{})
+
+	r := newAPIKeyTraceTestRouter(traceService, 12, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	select {
+	case <-cache.recorded:
+		t.Fatal("untraced key must not be recorded")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAPIKeyTraceMiddleware_CapturesUpstreamResponse(t *testing.T) {
+	cache := &apiKeyTraceCacheRecorder{recorded: make(chan *service.APIKeyTraceRecord, 1)}
+	cfg := &config.Config{}
+	cfg.Gateway.APIKeyTrace.MaxBodyBytes = 16
+	cfg.Gateway.APIKeyTrace.CaptureUpstreamResponse = true
+	traceService := service.NewAPIKeyTraceService(cache, nil, nil, cfg)
+	_, err := traceService.StartTrace(context.Background(), 11, time.Minute, "", 1)
+	require.NoError(t, err)
+
+	r := newAPIKeyTraceTestRouter(traceService, 11, func(c *gin.Context) {
+		upstreamReq, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, "https://upstream.example/v1beta/models/x:generateContent?key=secret&alt=sse", strings.NewReader(`{"contents":[{"parts":[]}]}`))
+		upstreamReq.Header.Set("X-Goog-Api-Key", "secret")
+		upstreamReq.Header.Set("Content-Type", "application/json")
+		_, _ = io.ReadAll(upstreamReq.Body) // 模拟传输层已读完请求体
+		upstreamResp := &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Set-Cookie": []string{"sid=1"}, "Retry-After": []string{"3"}},
+			Body:       io.NopCloser(strings.NewReader(`{"error":"rate limited upstream"}`)),
+		}
+		service.CaptureAPIKeyTraceUpstreamResponse(upstreamReq, upstreamResp, 99)
+		_, _ = io.ReadAll(upstreamResp.Body)
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limited"})
+	})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+
+	select {
+	case record := <-cache.recorded:
+		require.Len(t, record.UpstreamResponses, 1)
+		upstream := record.UpstreamResponses[0]
+		require.Equal(t, int64(99), upstream.AccountID)
+		require.Equal(t, "https://upstream.example/v1beta/models/x:generateContent", upstream.URL, "query string must be dropped")
+		require.Equal(t, "alt=sse", upstream.Query, "credential query params must be dropped")
+		require.Equal(t, "***", upstream.RequestHeaders["X-Goog-Api-Key"])
+		require.Equal(t, "application/json", upstream.RequestHeaders["Content-Type"])
+		require.Equal(t, `{"contents":[{"p`, upstream.RequestBody)
+		require.True(t, upstream.RequestBodyTruncated)
+		require.Equal(t, http.StatusTooManyRequests, upstream.StatusCode)
+		require.Equal(t, "***", upstream.Headers["Set-Cookie"])
+		require.Equal(t, "3", upstream.Headers["Retry-After"])
+		require.Equal(t, `{"error":"rate l`, upstream.Body)
+		require.True(t, upstream.BodyTruncated)
+	case <-time.After(2 * time.Second):
+		t.Fatal("trace record was not stored")
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceHandler 处理用户余额概览（含在途冻结）与余额流水查询。
+type BalanceHandler struct {
+	prepaidBalanceService *service.PrepaidBalanceService
+}
+
+// NewBalanceHandler creates a new BalanceHandler
+func NewBalanceHandler(prepaidBalanceService *service.PrepaidBalanceService) *BalanceHandler {
+	return &BalanceHandler{prepaidBalanceService: prepaidBalanceService}
+}
+
+// GetSummary handles fetching the current user's balance, reserved and available amounts
+// GET /api/v1/user/balance
+func (h *BalanceHandler) GetSummary(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	summary, err := h.prepaidBalanceService.GetSummary(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, summary)
+}
+
+// GetHistory handles fetching the current user's daily balance history
+// GET /api/v1/user/balance/history?days=30
+func (h *BalanceHandler) GetHistory(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	days := 0
+	if raw := strings.TrimSpace(c.Query("days")); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			response.BadRequest(c, "Invalid days")
+			return
+		}
+		days = v
+	}
+
+	items, err := h.prepaidBalanceService.GetHistory(c.Request.Context(), subject.UserID, days)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, items)
+}
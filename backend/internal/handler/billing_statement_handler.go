@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BillingStatementHandler 处理用户月度账单查询与 Stripe 账单 webhook。
+type BillingStatementHandler struct {
+	statementService *service.BillingStatementService
+}
+
+// NewBillingStatementHandler creates a new BillingStatementHandler
+func NewBillingStatementHandler(statementService *service.BillingStatementService) *BillingStatementHandler {
+	return &BillingStatementHandler{statementService: statementService}
+}
+
+// 推送失败原因仅管理员可见
+func hideStatementInternals(st *service.BillingStatement) {
+	if st != nil {
+		st.StripeError = ""
+	}
+}
+
+// List handles listing the current user's billing statements
+// GET /api/v1/user/statements
+func (h *BillingStatementHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	items, result, err := h.statementService.ListForUser(c.Request.Context(), subject.UserID, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	for _, st := range items {
+		hideStatementInternals(st)
+	}
+	response.Paginated(c, items, result.Total, page, pageSize)
+}
+
+// Get handles fetching one of the current user's billing statements
+// GET /api/v1/user/statements/:id
+func (h *BillingStatementHandler) Get(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	statementID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+
+	st, err := h.statementService.GetForUser(c.Request.Context(), subject.UserID, statementID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	hideStatementInternals(st)
+	response.Success(c, st)
+}
+
+// StripeWebhook handles Stripe invoice events for billing statements
+// POST /api/v1/billing/stripe/webhook
+func (h *BillingStatementHandler) StripeWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		c.String(http.StatusBadRequest, "failed to read body")
+		return
+	}
+	if err := h.statementService.HandleStripeWebhook(c.Request.Context(), body, c.GetHeader("Stripe-Signature")); err != nil {
+		slog.Warn("[BillingStatement] stripe webhook rejected", "error", err)
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.String(http.StatusOK, "success")
+}
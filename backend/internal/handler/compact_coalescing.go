@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/tidwall/gjson"
+)
+
+// compactCoalescingKeyFields 是决定压缩结果的请求字段；prompt_cache_key、metadata 等
+// 每个 agent 各不相同但不影响摘要内容的字段不参与合并键。
+var compactCoalescingKeyFields = []string{"model", "instructions", "input", "reasoning"}
+
+// CompactCoalescingMiddleware 合并会话压缩请求（/responses/compact 及 Codex body-signal compact，默认关闭）。
+// 多 agent 场景下常有多个 agent 同时压缩同一段会话：同一 API Key 对相同输入的压缩请求，
+// 进行中的合并到领头请求，领头成功后 window 内到达的请求直接复用其摘要。
+// 合并按 API Key 隔离，摘要（及其中按 Key 绑定的压缩令牌）不会跨 Key 共享。
+// 跟随者与重放不产生上游调用，通过 usage 记录一条零费用用量（request_type=reused）；
+// 只复用 2xx 响应，领头失败时跟随者独立请求上游。
+func CompactCoalescingMiddleware(cfg *config.Config, usage ReusedResponseUsageRecorder) gin.HandlerFunc {
+	if cfg == nil || !cfg.Gateway.CompactCoalescing.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	window := time.Duration(cfg.Gateway.CompactCoalescing.WindowSeconds) * time.Second
+	waitTimeout := time.Duration(cfg.Gateway.CompactCoalescing.WaitTimeoutSeconds) * time.Second
+	maxBytes := cfg.Gateway.CompactCoalescing.MaxResponseBytes
+	group := &requestCoalescer{inflight: make(map[string]*coalescingCall)}
+	var recent *gocache.Cache
+	if window > 0 {
+		recent = gocache.New(window, time.Minute)
+	}
+
+	return func(c *gin.Context) {
+		startedAt := time.Now()
+		key, ok := compactCoalescingKey(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		if recent != nil {
+			if cached, found := recent.Get(key); found {
+				writeCoalescedResponse(c, cached.(*coalescedResponse))
+				recordReusedResponseUsage(c, usage, cached.(*coalescedResponse), startedAt)
+				c.Abort()
+				return
+			}
+		}
+		call, leader := group.join(key)
+		if leader {
+			resp := runCoalescingLeader(c, maxBytes, true)
+			if resp != nil && (resp.status < http.StatusOK || resp.status >= http.StatusMultipleChoices) {
+				resp = nil
+			}
+			if resp != nil && recent != nil {
+				recent.Set(key, resp, window)
+			}
+			group.finish(key, call, resp)
+			return
+		}
+
+		timer := time.NewTimer(waitTimeout)
+		defer timer.Stop()
+		select {
+		case <-call.done:
+		case <-c.Request.Context().Done():
+			c.Abort()
+			return
+		case <-timer.C:
+			c.Next()
+			return
+		}
+		if call.resp == nil {
+			c.Next()
+			return
+		}
+		writeCoalescedResponse(c, call.resp)
+		recordReusedResponseUsage(c, usage, call.resp, startedAt)
+		c.Abort()
+	}
+}
+
+// compactCoalescingKey 计算压缩请求的合并键：用户 + 分组 + API Key + 路径 + 是否流式 + 决定摘要内容的请求字段。
+// 合并键必须包含 API Key：compaction_token_binding.scope=api_key 时摘要内的压缩令牌按领头请求的 Key 封装，
+// 跨 Key 复用会让跟随者拿到无法在自己 Key 下解封的令牌。
+// 非压缩请求返回 ok=false。
+func compactCoalescingKey(c *gin.Context) (string, bool) {
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return "", false
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		return "", false
+	}
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		return "", false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 || !gjson.ValidBytes(body) {
+		return "", false
+	}
+	path := strings.TrimRight(c.Request.URL.Path, "/")
+	if !strings.HasSuffix(path, "/responses/compact") &&
+		!(strings.HasSuffix(path, "/responses") && service.HasCompactionTriggerInInput(body)) {
+		return "", false
+	}
+
+	groupID := int64(0)
+	if apiKey.GroupID != nil {
+		groupID = *apiKey.GroupID
+	}
+	stream := gjson.GetBytes(body, "stream").Bool() || strings.Contains(strings.ToLower(c.GetHeader("Accept")), "text/event-stream")
+	sum := sha256.New()
+	sum.Write([]byte(strconv.FormatInt(subject.UserID, 10) + ":" + strconv.FormatInt(groupID, 10) + ":" + strconv.FormatInt(apiKey.ID, 10)))
+	sum.Write([]byte{0})
+	sum.Write([]byte(path + " " + strconv.FormatBool(stream)))
+	for _, field := range compactCoalescingKeyFields {
+		sum.Write([]byte{0})
+		if value := gjson.GetBytes(body, field); value.Exists() {
+			sum.Write(canonicalCoalescingBody([]byte(value.Raw)))
+		}
+	}
+	return hex.EncodeToString(sum.Sum(nil)), true
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type reusedUsageRecorderStub struct {
+	mu      sync.Mutex
+	records []reusedUsageRecord
+}
+
+type reusedUsageRecord struct {
+	apiKeyID  int64
+	accountID int64
+	model     string
+	stream    bool
+}
+
+func (s *reusedUsageRecorderStub) RecordReusedResponseUsage(c *gin.Context, accountID int64, model string, stream bool, _ time.Duration) {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, reusedUsageRecord{apiKeyID: apiKey.ID, accountID: accountID, model: model, stream: stream})
+}
+
+func (s *reusedUsageRecorderStub) snapshot() []reusedUsageRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]reusedUsageRecord(nil), s.records...)
+}
+
+func newCompactCoalescingRouter(windowSeconds int, h gin.HandlerFunc) *gin.Engine {
+	return newCompactCoalescingRouterWithUsage(windowSeconds, nil, h)
+}
+
+func newCompactCoalescingRouterWithUsage(windowSeconds int, usage ReusedResponseUsageRecorder, h gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.CompactCoalescing = config.GatewayCompactCoalescingConfig{Enabled: true, WindowSeconds: windowSeconds, WaitTimeoutSeconds: 5, MaxResponseBytes: 1 << 20}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		userID := int64(1)
+		if v := c.GetHeader("X-Test-User"); v != "" {
+			userID, _ = strconv.ParseInt(v, 10, 64)
+		}
+		// 同一用户的不同 agent 可以使用不同 API Key
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: userID*10 + int64(len(c.GetHeader("X-Test-Key"))), UserID: userID})
+		c.Set(string(middleware2.ContextKeyUser), middleware2.AuthSubject{UserID: userID})
+		c.Next()
+	})
+	mw := CompactCoalescingMiddleware(cfg, usage)
+	r.POST("/v1/responses", mw, h)
+	r.POST("/v1/responses/*subpath", mw, h)
+	return r
+}
+
+func newCompactRequest(body string, headers ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses/compact", strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func TestCompactCoalescing_ConcurrentAgentsShareSummary(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := newCompactCoalescingRouter(0, func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		c.JSON(http.StatusOK, gin.H{"output": "summary"})
+	})
+
+	var arrived atomic.Int32
+	recs := serveConcurrently(r, 4, func(i int) *http.Request {
+		// prompt_cache_key 各 agent 不同，不影响合并
+		body := `{"model":"gpt-5","input":[{"role":"user","content":"hi"}],"prompt_cache_key":"agent-` + strconv.Itoa(i) + `"}`
+		return newCompactRequest(body)
+	}, &arrived, release)
+
+	require.Equal(t, int32(1), calls.Load())
+	coalesced := 0
+	for _, rec := range recs {
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"output":"summary"}`, rec.Body.String())
+		if rec.Header().Get(RequestCoalescedHeader) == "true" {
+			coalesced++
+		}
+	}
+	require.Equal(t, 3, coalesced)
+}
+
+func TestCompactCoalescing_WindowReusesSuccessOnly(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusInternalServerError
+	r := newCompactCoalescingRouter(60, func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(status, gin.H{"output": "summary"})
+	})
+	body := `{"model":"gpt-5","input":[{"role":"user","content":"hi"}]}`
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	require.Equal(t, http.StatusInternalServerError, serve(newCompactRequest(body)).Code)
+	require.Equal(t, http.StatusInternalServerError, serve(newCompactRequest(body)).Code)
+	require.Equal(t, int32(2), calls.Load(), "failed compactions are not reused")
+
+	status = http.StatusOK
+	require.Empty(t, serve(newCompactRequest(body)).Header().Get(RequestCoalescedHeader))
+	rec := serve(newCompactRequest(body))
+	require.Equal(t, "true", rec.Header().Get(RequestCoalescedHeader))
+	require.Equal(t, int32(3), calls.Load())
+
+	// 不同用户、不同输入、非压缩请求都不复用
+	serve(newCompactRequest(body, "X-Test-User", "2"))
+	serve(newCompactRequest(`{"model":"gpt-5","input":[{"role":"user","content":"other"}]}`))
+	plain := httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(body))
+	serve(plain)
+	require.Equal(t, int32(6), calls.Load())
+
+	// body-signal compact 走裸 /responses
+	signal := `{"model":"gpt-5","input":[{"role":"user","content":"hi"},{"type":"compaction_trigger"}]}`
+	serve(httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(signal)))
+	rec = serve(httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(signal)))
+	require.Equal(t, "true", rec.Header().Get(RequestCoalescedHeader))
+	require.Equal(t, int32(7), calls.Load())
+}
+
+func TestCompactCoalescing_IsolatedPerAPIKeyAndRecordsReusedUsage(t *testing.T) {
+	var calls atomic.Int32
+	usage := &reusedUsageRecorderStub{}
+	r := newCompactCoalescingRouterWithUsage(60, usage, func(c *gin.Context) {
+		calls.Add(1)
+		setOpsRequestContext(c, "gpt-5", false)
+		setOpsSelectedAccount(c, 7)
+		c.JSON(http.StatusOK, gin.H{"output": "summary"})
+	})
+	body := `{"model":"gpt-5","input":[{"role":"user","content":"hi"}]}`
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Empty(t, serve(newCompactRequest(body)).Header().Get(RequestCoalescedHeader))
+	// 同一用户的另一个 API Key 不复用（摘要内的压缩令牌可能按 Key 绑定）
+	require.Empty(t, serve(newCompactRequest(body, "X-Test-Key", "b")).Header().Get(RequestCoalescedHeader))
+	require.Equal(t, int32(2), calls.Load())
+	require.Empty(t, usage.snapshot())
+
+	require.Equal(t, "true", serve(newCompactRequest(body)).Header().Get(RequestCoalescedHeader))
+	require.Equal(t, "true", serve(newCompactRequest(body, "X-Test-Key", "b")).Header().Get(RequestCoalescedHeader))
+	require.Equal(t, int32(2), calls.Load())
+	require.Equal(t, []reusedUsageRecord{
+		{apiKeyID: 10, accountID: 7, model: "gpt-5"},
+		{apiKeyID: 11, accountID: 7, model: "gpt-5"},
+	}, usage.snapshot())
+}
+
+func TestCompactCoalescing_APIKeyScopedCompactionTokensNotShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	// 模拟 compaction_token_binding.scope=api_key：摘要中的压缩令牌按发起请求的 Key 封装
+	r := newCompactCoalescingRouter(0, func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"output": []gin.H{{"type": "compaction", "encrypted_content": "sealed-for-" + strconv.FormatInt(apiKey.ID, 10)}}})
+	})
+
+	var arrived atomic.Int32
+	recs := serveConcurrently(r, 4, func(i int) *http.Request {
+		return newCompactRequest(`{"model":"gpt-5","input":[{"role":"user","content":"hi"}]}`, "X-Test-Key", strings.Repeat("b", i%2))
+	}, &arrived, release)
+
+	require.Equal(t, int32(2), calls.Load(), "each API key compacts with its own leader")
+	for i, rec := range recs {
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "sealed-for-"+strconv.Itoa(10+i%2))
+	}
+}
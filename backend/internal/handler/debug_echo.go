@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DebugEchoRequestHeader 管理员 Key 携带该请求头（值为 1/true）时回显本次调度信息。
+	DebugEchoRequestHeader = "X-Sub2API-Debug"
+	// DebugEchoResponseHeader 响应头：写出响应头时已确定的账号、模型映射、重试次数与阶段耗时。
+	DebugEchoResponseHeader = "X-Sub2API-Debug"
+	// DebugEchoTrailer 响应 trailer：请求结束时的最终值（含总耗时），流式响应的首字耗时只能在这里拿到。
+	DebugEchoTrailer = "X-Sub2API-Debug-Final"
+)
+
+// debugEchoWriter 在响应头提交前注入调试头。
+type debugEchoWriter struct {
+	gin.ResponseWriter
+	c         *gin.Context
+	startedAt time.Time
+	injected  bool
+}
+
+func (w *debugEchoWriter) inject() {
+	if w.injected {
+		return
+	}
+	w.injected = true
+	if w.ResponseWriter.Written() {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	header.Set(DebugEchoResponseHeader, buildDebugEchoValue(w.c, w.ResponseWriter.Status(), 0))
+	header.Add("Trailer", DebugEchoTrailer)
+}
+
+func (w *debugEchoWriter) WriteHeaderNow() {
+	w.inject()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *debugEchoWriter) Write(b []byte) (int, error) {
+	w.inject()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *debugEchoWriter) WriteString(s string) (int, error) {
+	w.inject()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *debugEchoWriter) Flush() {
+	w.inject()
+	w.ResponseWriter.Flush()
+}
+
+// DebugEchoMiddleware 管理员 Key 发送 X-Sub2API-Debug: 1 时，在响应头/trailer 中回显所选账号、映射后的模型、
+// 重试次数与各阶段耗时，方便客户端侧直接排障而无需查库。非管理员 Key 的该请求头被忽略。
+// 需注册在 API Key 鉴权之后。
+func DebugEchoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isDebugEchoRequested(c) {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil || apiKey.User == nil || !apiKey.User.IsAdmin() {
+			c.Next()
+			return
+		}
+
+		originalWriter := c.Writer
+		w := &debugEchoWriter{ResponseWriter: originalWriter, c: c, startedAt: time.Now()}
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+		}()
+
+		c.Next()
+
+		// 仍未写出响应头（如 handler 未写 body）时补写调试头；已写出则通过 trailer 回传最终值。
+		if !originalWriter.Written() {
+			w.inject()
+		}
+		originalWriter.Header().Set(DebugEchoTrailer, buildDebugEchoValue(c, originalWriter.Status(), time.Since(w.startedAt)))
+	}
+}
+
+func isDebugEchoRequested(c *gin.Context) bool {
+	if c == nil || c.Request == nil {
+		return false
+	}
+	v := strings.TrimSpace(c.GetHeader(DebugEchoRequestHeader))
+	return v == "1" || strings.EqualFold(v, "true")
+}
+
+// buildDebugEchoValue 以 "key=value; key=value" 形式输出调试信息，缺失的字段不输出。
+// total>0 时附带请求总耗时。
+func buildDebugEchoValue(c *gin.Context, status int, total time.Duration) string {
+	parts := make([]string, 0, 12)
+	add := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			parts = append(parts, key+"="+value)
+		}
+	}
+
+	if v, ok := c.Get(opsAccountIDKey); ok {
+		if id, ok := v.(int64); ok && id > 0 {
+			add("account_id", strconv.FormatInt(id, 10))
+		}
+	}
+	if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil && apiKey.Group != nil {
+		add("platform", apiKey.Group.Platform)
+	}
+	if v, ok := c.Get(opsModelKey); ok {
+		model, _ := v.(string)
+		add("model", model)
+	}
+	if v, ok := c.Get(opsUpstreamModelKey); ok {
+		model, _ := v.(string)
+		add("mapped_model", model)
+	}
+
+	retries := 0
+	if v, ok := c.Get(service.OpsUpstreamErrorsKey); ok {
+		if events, ok := v.([]*service.OpsUpstreamErrorEvent); ok {
+			retries = len(events)
+			// 最终失败的那次上游错误不算重试
+			if status >= 400 && retries > 0 {
+				retries--
+			}
+		}
+	}
+	add("retries", strconv.Itoa(retries))
+
+	for _, stage := range []struct {
+		name string
+		key  string
+	}{
+		{"auth_ms", service.OpsAuthLatencyMsKey},
+		{"routing_ms", service.OpsRoutingLatencyMsKey},
+		{"upstream_ms", service.OpsUpstreamLatencyMsKey},
+		{"response_ms", service.OpsResponseLatencyMsKey},
+		{"ttft_ms", service.OpsTimeToFirstTokenMsKey},
+	} {
+		if ms := getContextLatencyMs(c, stage.key); ms != nil {
+			add(stage.name, strconv.FormatInt(*ms, 10))
+		}
+	}
+	if total > 0 {
+		add("total_ms", strconv.FormatInt(total.Milliseconds(), 10))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func serveDebugEcho(t *testing.T, role string, debugHeader string, h gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+			ID:    1,
+			User:  &service.User{ID: 1, Role: role},
+			Group: &service.Group{Platform: service.PlatformAnthropic},
+		})
+		c.Next()
+	})
+	r.Use(DebugEchoMiddleware())
+	r.POST("/v1/messages", h)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	if debugHeader != "" {
+		req.Header.Set(DebugEchoRequestHeader, debugHeader)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func debugEchoTestHandler(c *gin.Context) {
+	setOpsRequestContext(c, "claude-sonnet-4", false)
+	setOpsEndpointContext(c, "claude-sonnet-4-20250514", 0)
+	setOpsSelectedAccount(c, 42)
+	service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, 7)
+	c.Set(service.OpsUpstreamErrorsKey, []*service.OpsUpstreamErrorEvent{{Kind: "failover"}})
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func TestDebugEchoMiddleware_AdminKeyGetsDebugHeader(t *testing.T) {
+	rec := serveDebugEcho(t, service.RoleAdmin, "1", debugEchoTestHandler)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t,
+		"account_id=42; platform=anthropic; model=claude-sonnet-4; mapped_model=claude-sonnet-4-20250514; retries=1; routing_ms=7",
+		rec.Header().Get(DebugEchoResponseHeader),
+	)
+	require.Equal(t, DebugEchoTrailer, rec.Header().Get("Trailer"))
+	require.Contains(t, rec.Result().Trailer.Get(DebugEchoTrailer), "total_ms=")
+}
+
+func TestDebugEchoMiddleware_IgnoredForNonAdminOrWithoutHeader(t *testing.T) {
+	rec := serveDebugEcho(t, service.RoleUser, "1", debugEchoTestHandler)
+	require.Empty(t, rec.Header().Get(DebugEchoResponseHeader))
+
+	rec = serveDebugEcho(t, service.RoleAdmin, "", debugEchoTestHandler)
+	require.Empty(t, rec.Header().Get(DebugEchoResponseHeader))
+}
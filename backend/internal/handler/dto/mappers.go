@@ -105,6 +105,14 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		Window5hStart:      k.Window5hStart,
 		Window1dStart:      k.Window1dStart,
 		Window7dStart:      k.Window7dStart,
+		RPMLimit:           k.RPMLimit,
+		TPMLimit:           k.TPMLimit,
+		Critical:           k.Critical,
+		Guest:              k.Guest,
+		AllowedModels:      k.AllowedModels,
+		TokenLimit:         k.TokenLimit,
+		TokensUsed:         k.TokensUsed,
+		ProjectID:          k.ProjectID,
 		User:               UserFromServiceShallow(k.User),
 		Group:              GroupFromServiceShallow(k.Group),
 	}
@@ -157,6 +165,19 @@ func GroupFromServiceAdmin(g *service.Group) *AdminGroup {
 		ActiveAccountCount:          g.ActiveAccountCount,
 		RateLimitedAccountCount:     g.RateLimitedAccountCount,
 		SortOrder:                   g.SortOrder,
+		ReservedConcurrencyRatio:    g.ReservedConcurrencyRatio,
+		OverloadFallbackModels:      g.OverloadFallbackModels,
+		ContextOverflowModels:       g.ContextOverflowModels,
+		ContextOverflowReject:       g.ContextOverflowReject,
+		ContextTruncationStrategy:   g.ContextTruncationStrategy,
+		AccountSelectionStrategy:    g.AccountSelectionStrategy,
+		ImageMaxEdge:                g.ImageMaxEdge,
+		ImageMaxBytes:               g.ImageMaxBytes,
+		OutputPostprocess:           g.OutputPostprocess,
+		LanguageRouting:             g.LanguageRouting,
+		StreamReplay:                g.StreamReplay,
+		CapabilityCheck:             g.CapabilityCheck,
+		AutoContinuation:            g.AutoContinuation,
 	}
 	if len(g.AccountGroups) > 0 {
 		out.AccountGroups = make([]AccountGroup, 0, len(g.AccountGroups))
@@ -193,6 +214,7 @@ func groupFromServiceBase(g *service.Group) Group {
 		PeakStart:                       g.PeakStart,
 		PeakEnd:                         g.PeakEnd,
 		PeakRateMultiplier:              g.PeakRateMultiplier,
+		RequestSurcharge:                g.RequestSurcharge,
 		ImagePrice1K:                    g.ImagePrice1K,
 		ImagePrice2K:                    g.ImagePrice2K,
 		ImagePrice4K:                    g.ImagePrice4K,
@@ -420,9 +442,12 @@ func AccountGroupFromService(ag *service.AccountGroup) *AccountGroup {
 		AccountID: ag.AccountID,
 		GroupID:   ag.GroupID,
 		Priority:  ag.Priority,
+		Tier:      ag.Tier,
 		CreatedAt: ag.CreatedAt,
 		Account:   AccountFromServiceShallow(ag.Account),
 		Group:     GroupFromServiceShallow(ag.Group),
+
+		ConcurrencyShare: ag.ConcurrencyShare,
 	}
 }
 
@@ -607,6 +632,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		ServiceTier:           l.ServiceTier,
 		ReasoningEffort:       l.ReasoningEffort,
 		InboundEndpoint:       l.InboundEndpoint,
+		ThreadID:              l.ThreadID,
 		GroupID:               l.GroupID,
 		SubscriptionID:        l.SubscriptionID,
 		InputTokens:           l.InputTokens,
@@ -615,6 +641,9 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		CacheReadTokens:       l.CacheReadTokens,
 		CacheCreation5mTokens: l.CacheCreation5mTokens,
 		CacheCreation1hTokens: l.CacheCreation1hTokens,
+		ReasoningTokens:       l.ReasoningTokens,
+		ToolCallTokens:        l.ToolCallTokens,
+		VisibleOutputTokens:   l.VisibleOutputTokens(),
 		InputCost:             l.InputCost,
 		OutputCost:            l.OutputCost,
 		CacheCreationCost:     l.CacheCreationCost,
@@ -628,6 +657,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		OpenAIWSMode:          openAIWSMode,
 		DurationMs:            l.DurationMs,
 		FirstTokenMs:          l.FirstTokenMs,
+		OutputTokensPerSec:    l.OutputTokensPerSecond(),
 		ImageCount:            l.ImageCount,
 		ImageSize:             l.ImageSize,
 		ImageInputSize:        l.ImageInputSize,
@@ -637,6 +667,8 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		ImageSizeSource:       l.ImageSizeSource,
 		ImageSizeBreakdown:    l.ImageSizeBreakdown,
 		MediaType:             l.MediaType,
+		AudioDurationSeconds:  l.AudioDurationSeconds,
+		AudioCharacters:       l.AudioCharacters,
 		UserAgent:             l.UserAgent,
 		IPAddress:             l.IPAddress,
 		CacheTTLOverridden:    l.CacheTTLOverridden,
@@ -82,6 +82,22 @@ type APIKey struct {
 	Reset1dAt     *time.Time `json:"reset_1d_at,omitempty"`
 	Reset7dAt     *time.Time `json:"reset_7d_at,omitempty"`
 
+	// Throughput limits (0 = unlimited)
+	RPMLimit int   `json:"rpm_limit"`
+	TPMLimit int64 `json:"tpm_limit"`
+
+	// Critical keys may consume the group's reserved account concurrency
+	Critical bool `json:"critical"`
+
+	// Guest key scope (omitted for regular keys)
+	Guest         bool     `json:"guest,omitempty"`
+	AllowedModels []string `json:"allowed_models,omitempty"`
+	TokenLimit    int64    `json:"token_limit,omitempty"`
+	TokensUsed    int64    `json:"tokens_used,omitempty"`
+
+	// Project grouping (omitted when the key is not in a project)
+	ProjectID *int64 `json:"project_id,omitempty"`
+
 	User  *User  `json:"user,omitempty"`
 	Group *Group `json:"group,omitempty"`
 }
@@ -114,6 +130,7 @@ type Group struct {
 	PeakStart          string   `json:"peak_start"`
 	PeakEnd            string   `json:"peak_end"`
 	PeakRateMultiplier float64  `json:"peak_rate_multiplier"`
+	RequestSurcharge   float64  `json:"request_surcharge"` // 按次固定附加费（USD）
 	ImagePrice1K       *float64 `json:"image_price_1k"`
 	ImagePrice2K       *float64 `json:"image_price_2k"`
 	ImagePrice4K       *float64 `json:"image_price_4k"`
@@ -167,6 +184,39 @@ type AdminGroup struct {
 
 	// 分组排序
 	SortOrder int `json:"sort_order"`
+
+	// 为关键 API Key 预留的账号并发比例（0 = 不预留）
+	ReservedConcurrencyRatio float64 `json:"reserved_concurrency_ratio"`
+
+	// 过载降级映射：首选账号饱和/冷却时改用的备用模型
+	OverloadFallbackModels map[string]string `json:"overload_fallback_models"`
+
+	// 长上下文路由：估算输入超出上下文窗口时改用的大上下文模型，及无可用模型时是否拒绝
+	ContextOverflowModels map[string]string `json:"context_overflow_models"`
+	ContextOverflowReject bool              `json:"context_overflow_reject"`
+	// 超出上下文窗口且无法改路由时的截断策略
+	ContextTruncationStrategy string `json:"context_truncation_strategy"`
+	// 负载感知调度的账号选择策略（空表示 priority）
+	AccountSelectionStrategy string `json:"account_selection_strategy"`
+
+	// 图片预处理目标（0 = 不限制）
+	ImageMaxEdge  int `json:"image_max_edge"`
+	ImageMaxBytes int `json:"image_max_bytes"`
+
+	// 输出后处理配置
+	OutputPostprocess domain.GroupOutputPostprocessConfig `json:"output_postprocess"`
+
+	// 语言路由规则
+	LanguageRouting domain.GroupLanguageRoutingConfig `json:"language_routing"`
+
+	// 流式断线续传配置
+	StreamReplay domain.GroupStreamReplayConfig `json:"stream_replay"`
+
+	// 模型能力校验配置
+	CapabilityCheck domain.GroupCapabilityCheckConfig `json:"capability_check"`
+
+	// 截断自动续写配置
+	AutoContinuation domain.GroupAutoContinuationConfig `json:"auto_continuation"`
 }
 
 type Account struct {
@@ -292,7 +342,10 @@ type AccountGroup struct {
 	AccountID int64     `json:"account_id"`
 	GroupID   int64     `json:"group_id"`
 	Priority  int       `json:"priority"`
+	Tier      int       `json:"tier"`
 	CreatedAt time.Time `json:"created_at"`
+	// ConcurrencyShare 分组可占用的账号并发百分比，0 表示不限制
+	ConcurrencyShare int `json:"concurrency_share"`
 
 	Account *Account `json:"account,omitempty"`
 	Group   *Group   `json:"group,omitempty"`
@@ -468,6 +521,8 @@ type UsageLog struct {
 	InboundEndpoint *string `json:"inbound_endpoint,omitempty"`
 	// UpstreamEndpoint is the normalized upstream endpoint path, e.g. /v1/responses.
 	UpstreamEndpoint *string `json:"upstream_endpoint,omitempty"`
+	// ThreadID is the client-supplied conversation/thread identifier.
+	ThreadID *string `json:"thread_id,omitempty"`
 
 	GroupID        *int64 `json:"group_id"`
 	SubscriptionID *int64 `json:"subscription_id"`
@@ -480,6 +535,11 @@ type UsageLog struct {
 	CacheCreation5mTokens int `json:"cache_creation_5m_tokens"`
 	CacheCreation1hTokens int `json:"cache_creation_1h_tokens"`
 
+	// 输出 token 拆分：推理与工具调用（估算）均包含在 output_tokens 中
+	ReasoningTokens     int `json:"reasoning_tokens"`
+	ToolCallTokens      int `json:"tool_call_tokens"`
+	VisibleOutputTokens int `json:"visible_output_tokens"`
+
 	InputCost         float64 `json:"input_cost"`
 	OutputCost        float64 `json:"output_cost"`
 	CacheCreationCost float64 `json:"cache_creation_cost"`
@@ -494,6 +554,8 @@ type UsageLog struct {
 	OpenAIWSMode bool   `json:"openai_ws_mode"`
 	DurationMs   *int   `json:"duration_ms"`
 	FirstTokenMs *int   `json:"first_token_ms"`
+	// OutputTokensPerSec 生成阶段出字速度（tokens/s，不含首字等待）
+	OutputTokensPerSec *float64 `json:"output_tokens_per_sec,omitempty"`
 
 	// 图片生成字段
 	ImageCount         int            `json:"image_count"`
@@ -506,6 +568,10 @@ type UsageLog struct {
 	ImageSizeBreakdown map[string]int `json:"image_size_breakdown"`
 	MediaType          *string        `json:"media_type"`
 
+	// 音频字段：转写按秒、语音合成按字符计费
+	AudioDurationSeconds *float64 `json:"audio_duration_seconds,omitempty"`
+	AudioCharacters      *int     `json:"audio_characters,omitempty"`
+
 	// User-Agent
 	UserAgent *string `json:"user_agent"`
 	// IPAddress is visible to the owner of the usage record.
@@ -514,7 +580,7 @@ type UsageLog struct {
 	// Cache TTL Override 标记
 	CacheTTLOverridden bool `json:"cache_ttl_overridden"`
 
-	// BillingMode 计费模式：token/image
+	// BillingMode 计费模式：token/image/video/audio
 	BillingMode *string `json:"billing_mode,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
@@ -18,6 +18,10 @@ const (
 	EndpointMessages          = "/v1/messages"
 	EndpointChatCompletions   = "/v1/chat/completions"
 	EndpointEmbeddings        = "/v1/embeddings"
+	EndpointAudioTranscribe   = "/v1/audio/transcriptions"
+	EndpointAudioSpeech       = "/v1/audio/speech"
+	EndpointBatches           = "/v1/batches"
+	EndpointFiles             = "/v1/files"
 	EndpointResponses         = "/v1/responses"
 	EndpointResponsesCompact  = "/v1/responses/compact"
 	EndpointImagesGenerations = "/v1/images/generations"
@@ -77,6 +81,14 @@ func NormalizeInboundEndpoint(path string) string {
 		return EndpointEmbeddings
 	case strings.Contains(path, EndpointChatCompletions):
 		return EndpointChatCompletions
+	case strings.Contains(path, EndpointAudioTranscribe) || strings.Contains(path, "/audio/transcriptions"):
+		return EndpointAudioTranscribe
+	case strings.Contains(path, EndpointAudioSpeech) || strings.Contains(path, "/audio/speech"):
+		return EndpointAudioSpeech
+	case strings.Contains(path, EndpointBatches) || isBareOrSubpathOf(strings.TrimRight(path, "/"), "/batches"):
+		return EndpointBatches
+	case strings.Contains(path, EndpointFiles) || isBareOrSubpathOf(strings.TrimRight(path, "/"), "/files"):
+		return EndpointFiles
 	case strings.Contains(path, EndpointMessages):
 		return EndpointMessages
 	case strings.Contains(path, EndpointImagesGenerations) || strings.Contains(path, "/images/generations"):
@@ -167,7 +179,7 @@ func DeriveUpstreamEndpoint(inbound, rawRequestPath, platform string) string {
 
 	switch platform {
 	case service.PlatformOpenAI, service.PlatformGrok:
-		if inbound == EndpointEmbeddings || inbound == EndpointImagesGenerations || inbound == EndpointImagesEdits || inbound == EndpointVideosGenerations || inbound == EndpointVideos {
+		if inbound == EndpointEmbeddings || inbound == EndpointAudioTranscribe || inbound == EndpointAudioSpeech || inbound == EndpointBatches || inbound == EndpointFiles || inbound == EndpointImagesGenerations || inbound == EndpointImagesEdits || inbound == EndpointVideosGenerations || inbound == EndpointVideos {
 			return inbound
 		}
 		// OpenAI forwards everything to the Responses API.
@@ -25,6 +25,11 @@ func TestNormalizeInboundEndpoint(t *testing.T) {
 		{"/v1/messages", EndpointMessages},
 		{"/v1/chat/completions", EndpointChatCompletions},
 		{"/v1/embeddings", EndpointEmbeddings},
+		{"/v1/audio/transcriptions", EndpointAudioTranscribe},
+		{"/v1/audio/speech", EndpointAudioSpeech},
+		{"/v1/batches/batch_abc/cancel", EndpointBatches},
+		{"/batches", EndpointBatches},
+		{"/v1/files/file-abc/content", EndpointFiles},
 		{"/v1/responses", EndpointResponses},
 		{"/v1/responses/compact", EndpointResponsesCompact},
 		{"/v1/responses/compact/detail", EndpointResponsesCompact},
@@ -119,6 +124,10 @@ func TestDeriveUpstreamEndpoint(t *testing.T) {
 		{"openai from messages", EndpointMessages, "/v1/messages", service.PlatformOpenAI, EndpointResponses},
 		{"openai from completions", EndpointChatCompletions, "/v1/chat/completions", service.PlatformOpenAI, EndpointResponses},
 		{"openai embeddings", EndpointEmbeddings, "/v1/embeddings", service.PlatformOpenAI, EndpointEmbeddings},
+		{"openai audio transcriptions", EndpointAudioTranscribe, "/v1/audio/transcriptions", service.PlatformOpenAI, EndpointAudioTranscribe},
+		{"openai audio speech", EndpointAudioSpeech, "/audio/speech", service.PlatformOpenAI, EndpointAudioSpeech},
+		{"openai batches", EndpointBatches, "/v1/batches/batch_abc", service.PlatformOpenAI, EndpointBatches},
+		{"openai files", EndpointFiles, "/v1/files", service.PlatformOpenAI, EndpointFiles},
 		{"openai image generations", EndpointImagesGenerations, "/v1/images/generations", service.PlatformOpenAI, EndpointImagesGenerations},
 		{"openai image edits", EndpointImagesEdits, "/openai/v1/images/edits", service.PlatformOpenAI, EndpointImagesEdits},
 		{"grok video generations", EndpointVideosGenerations, "/v1/videos/generations", service.PlatformGrok, EndpointVideosGenerations},
@@ -43,6 +43,7 @@ type GatewayHandler struct {
 	antigravityGatewayService *service.AntigravityGatewayService
 	userService               *service.UserService
 	billingCacheService       *service.BillingCacheService
+	prepaidBalanceService     *service.PrepaidBalanceService
 	usageService              *service.UsageService
 	apiKeyService             *service.APIKeyService
 	usageRecordWorkerPool     *service.UsageRecordWorkerPool
@@ -54,6 +55,7 @@ type GatewayHandler struct {
 	maxAccountSwitchesGemini  int
 	cfg                       *config.Config
 	settingService            *service.SettingService
+	hedger                    *requestHedger
 }
 
 // NewGatewayHandler creates a new GatewayHandler
@@ -64,6 +66,7 @@ func NewGatewayHandler(
 	userService *service.UserService,
 	concurrencyService *service.ConcurrencyService,
 	billingCacheService *service.BillingCacheService,
+	prepaidBalanceService *service.PrepaidBalanceService,
 	usageService *service.UsageService,
 	apiKeyService *service.APIKeyService,
 	usageRecordWorkerPool *service.UsageRecordWorkerPool,
@@ -98,6 +101,7 @@ func NewGatewayHandler(
 		antigravityGatewayService: antigravityGatewayService,
 		userService:               userService,
 		billingCacheService:       billingCacheService,
+		prepaidBalanceService:     prepaidBalanceService,
 		usageService:              usageService,
 		apiKeyService:             apiKeyService,
 		usageRecordWorkerPool:     usageRecordWorkerPool,
@@ -109,6 +113,7 @@ func NewGatewayHandler(
 		maxAccountSwitchesGemini:  maxAccountSwitchesGemini,
 		cfg:                       cfg,
 		settingService:            settingService,
+		hedger:                    newRequestHedger(cfg),
 	}
 }
 
@@ -196,6 +201,104 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// 图片预处理：按分组目标缩放/重新压缩超大的 base64 图片
+	if apiKey.Group.ImagePreprocessEnabled() {
+		if out, result := service.PreprocessAnthropicImages(body, apiKey.Group.ImageMaxEdge, apiKey.Group.ImageMaxBytes); result != nil {
+			reqLog.Info("gateway.images_downscaled",
+				zap.Int("images", result.Images),
+				zap.Int("bytes_before", result.BytesBefore),
+				zap.Int("bytes_after", result.BytesAfter),
+			)
+			body = out
+			if err := parsedReq.ReplaceBody(body); err != nil {
+				h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+				return
+			}
+			c.Header(service.ImagePreprocessHeader, result.HeaderValue())
+		}
+	}
+
+	// 语言路由：按提示词主要语言改写模型，并记录语言供调度优先选择规则指定的账号
+	if lang, rule := service.ResolveLanguageRoute(apiKey.Group, body); rule != nil {
+		c.Request = c.Request.WithContext(service.WithPromptLanguage(c.Request.Context(), lang))
+		if rule.Model != "" && rule.Model != reqModel {
+			reqLog.Info("gateway.language_routed", zap.String("language", lang), zap.String("to_model", rule.Model))
+			c.Header(service.LanguageRoutedHeader, reqModel)
+			body = service.ReplaceModelInBody(body, rule.Model)
+			if err := parsedReq.ReplaceBody(body); err != nil {
+				h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+				return
+			}
+			reqModel = rule.Model
+			parsedReq.Model = reqModel
+			channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+			setOpsRequestContext(c, reqModel, reqStream)
+		}
+	}
+
+	// 长上下文处理：估算输入超出模型上下文窗口时改用大上下文模型、按策略截断历史，或提前拒绝
+	overflow, err := h.gatewayService.ResolveContextOverflow(c.Request.Context(), apiKey.Group, reqModel, body)
+	if err != nil {
+		reqLog.Info("gateway.context_overflow_rejected", zap.Error(err))
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", pkgerrors.Message(err))
+		return
+	}
+	if overflow != nil && overflow.RoutedModel != "" {
+		reqLog.Info("gateway.context_overflow_routed",
+			zap.String("to_model", overflow.RoutedModel),
+			zap.Int("estimated_tokens", overflow.EstimatedTokens),
+			zap.Int("context_window", overflow.ContextWindow),
+		)
+		c.Header(service.ContextOverflowRoutedHeader, reqModel)
+		body = service.ReplaceModelInBody(body, overflow.RoutedModel)
+		if err := parsedReq.ReplaceBody(body); err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+			return
+		}
+		reqModel = overflow.RoutedModel
+		parsedReq.Model = reqModel
+		channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+		setOpsRequestContext(c, reqModel, reqStream)
+	} else if overflow != nil && overflow.Truncation != nil {
+		reqLog.Info("gateway.context_overflow_truncated",
+			zap.String("strategy", overflow.Truncation.Strategy),
+			zap.Int("removed_messages", overflow.Truncation.RemovedMessages),
+			zap.Int("estimated_tokens", overflow.EstimatedTokens),
+			zap.Int("truncated_tokens", overflow.Truncation.EstimatedTokens),
+			zap.Int("context_window", overflow.ContextWindow),
+		)
+		c.Header(service.ContextTruncationHeader, overflow.Truncation.HeaderValue())
+		body = overflow.Body
+		if err := parsedReq.ReplaceBody(body); err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+			return
+		}
+	}
+
+	capability, err := h.gatewayService.ResolveCapabilityCheck(c.Request.Context(), apiKey.Group, reqModel, service.DetectAnthropicCapabilityRequirements(body))
+	if err != nil {
+		reqLog.Info("gateway.capability_check_rejected", zap.Error(err))
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", pkgerrors.Message(err))
+		return
+	}
+	if capability != nil && capability.RoutedModel != "" {
+		reqLog.Info("gateway.capability_check_routed",
+			zap.String("to_model", capability.RoutedModel),
+			zap.String("capability", string(capability.Missing.Capability)),
+			zap.String("location", capability.Missing.Location),
+		)
+		c.Header(service.CapabilityRoutedHeader, reqModel)
+		body = service.ReplaceModelInBody(body, capability.RoutedModel)
+		if err := parsedReq.ReplaceBody(body); err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+			return
+		}
+		reqModel = capability.RoutedModel
+		parsedReq.Model = reqModel
+		channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+		setOpsRequestContext(c, reqModel, reqStream)
+	}
+
 	if decision := h.checkContentModeration(c, reqLog, apiKey, subject, service.ContentModerationProtocolAnthropicMessages, reqModel, body); decision != nil && decision.Blocked {
 		h.errorResponse(c, contentModerationStatus(decision), contentModerationErrorCode(decision), decision.Message)
 		return
@@ -236,14 +339,26 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// 余额预扣：按预估费用冻结余额；产生用量时随用量记录任务移交、扣费后释放，否则请求结束时释放
+	balanceReservation, err := h.prepaidBalanceService.Reserve(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("gateway.prepaid_reserve_failed", zap.Error(err))
+		status, code, message, _ := billingErrorDetails(err)
+		h.handleStreamingAwareError(c, status, code, message, streamStarted)
+		return
+	}
+	defer balanceReservation.Release()
+	c.Request = c.Request.WithContext(service.WithBalanceReservation(c.Request.Context(), balanceReservation))
+
 	// 设置请求所属分组 ID（用于渠道级功能判断，如 WebSearch 模拟）
 	parsedReq.GroupID = apiKey.GroupID
 
 	// 计算粘性会话hash
 	parsedReq.SessionContext = &service.SessionContext{
-		ClientIP:  ip.GetClientIP(c),
-		UserAgent: c.GetHeader("User-Agent"),
-		APIKeyID:  apiKey.ID,
+		ClientIP:       ip.GetClientIP(c),
+		UserAgent:      c.GetHeader("User-Agent"),
+		APIKeyID:       apiKey.ID,
+		ConversationID: service.ConversationIDFromHeaders(c.Request.Header),
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
@@ -289,7 +404,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	hasBoundSession := sessionKey != "" && sessionBoundAccountID > 0
 
 	if platform == service.PlatformGemini {
-		fs := NewFailoverState(h.maxAccountSwitchesGemini, hasBoundSession)
+		fs := NewFailoverState(applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitchesGemini), hasBoundSession)
 
 		// 单账号分组提前设置 SingleAccountRetry 标记，让 Service 层首次 503 就不设模型限流标记。
 		// 避免单账号分组收到 503 (MODEL_CAPACITY_EXHAUSTED) 时设 29s 限流，导致后续请求连续快速失败。
@@ -339,6 +454,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			account := selection.Account
 			setOpsSelectedAccount(c, account.ID, account.Platform)
+			setOpsSelectedProxy(c, account)
 
 			// 检查请求拦截（预热请求、SUGGESTION MODE等）
 			if account.IsInterceptWarmupEnabled() {
@@ -378,6 +494,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						zap.Int64("account_id", account.ID),
 						zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
 					)
+					setClientRetryAfter(c, waitQueueFullRetryAfterSeconds)
 					h.handleStreamingAwareError(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later", streamStarted)
 					return
 				}
@@ -440,6 +557,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			if accountReleaseFunc != nil {
 				accountReleaseFunc()
 			}
+			if err == nil && result != nil && result.FirstTokenMs != nil {
+				service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, int64(*result.FirstTokenMs))
+			}
 			if err != nil {
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
@@ -497,6 +617,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 
 			// 捕获请求信息（用于异步记录，避免在 goroutine 中访问 gin.Context）
 			userAgent := c.GetHeader("User-Agent")
+			threadID := c.GetHeader(service.UsageThreadIDHeader)
 			clientIP := ip.GetClientIP(c)
 			requestPayloadHash := service.HashUsageRequestPayload(body)
 			inboundEndpoint := GetInboundEndpoint(c)
@@ -531,6 +652,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					InboundEndpoint:    inboundEndpoint,
 					UpstreamEndpoint:   upstreamEndpoint,
 					UserAgent:          userAgent,
+					ThreadID:           threadID,
 					IPAddress:          clientIP,
 					RequestPayloadHash: requestPayloadHash,
 					ForceCacheBilling:  forceCacheBilling,
@@ -559,6 +681,21 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	}
 	fallbackUsed := false
 
+	// 过载降级：首选账号全部饱和/冷却时按分组策略改用备用模型重新调度
+	downgradedFrom := ""
+	tryOverloadDowngrade := func() bool {
+		model, newBody, ok := applyOverloadDowngrade(c, currentAPIKey.Group, &downgradedFrom, reqModel, body, streamStarted)
+		if !ok {
+			return false
+		}
+		reqLog.Info("gateway.overload_downgrade", zap.String("from_model", downgradedFrom), zap.String("to_model", model))
+		reqModel, body = model, newBody
+		parsedReq.Model = model
+		channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), currentAPIKey.GroupID, model)
+		setOpsRequestContext(c, model, reqStream)
+		return true
+	}
+
 	// 单账号分组提前设置 SingleAccountRetry 标记，让 Service 层首次 503 就不设模型限流标记。
 	// 避免单账号分组收到 503 (MODEL_CAPACITY_EXHAUSTED) 时设 29s 限流，导致后续请求连续快速失败。
 	if h.gatewayService.IsSingleAntigravityAccountGroup(c.Request.Context(), currentAPIKey.GroupID) {
@@ -567,7 +704,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	}
 
 	for {
-		fs := NewFailoverState(h.maxAccountSwitches, hasBoundSession)
+		fs := NewFailoverState(applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitches), hasBoundSession)
 		retryWithFallback := false
 
 		for {
@@ -599,6 +736,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						zap.Bool("model_not_found", cls.ModelNotFound),
 						zap.Error(err),
 					)
+					if !cls.ModelNotFound && tryOverloadDowngrade() {
+						continue
+					}
 					message := cls.Message
 					if !cls.ModelNotFound {
 						message = "No available accounts: " + err.Error()
@@ -625,6 +765,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			account := selection.Account
 			setOpsSelectedAccount(c, account.ID, account.Platform)
+			setOpsSelectedProxy(c, account)
 
 			// [DEBUG-STICKY] 打印账号选择结果
 			reqLog.Info("sticky.account_selected",
@@ -662,6 +803,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						zap.String("model", reqModel),
 						zap.String("platform", platform),
 					)
+					if tryOverloadDowngrade() {
+						continue
+					}
 					h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "api_error", "No available accounts", streamStarted)
 					return
 				}
@@ -674,6 +818,10 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						zap.Int64("account_id", account.ID),
 						zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
 					)
+					if tryOverloadDowngrade() {
+						continue
+					}
+					setClientRetryAfter(c, waitQueueFullRetryAfterSeconds)
 					h.handleStreamingAwareError(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later", streamStarted)
 					return
 				}
@@ -698,6 +846,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				if err != nil {
 					reqLog.Warn("gateway.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 					releaseWait()
+					if isAccountSlotWaitTimeout(err) && tryOverloadDowngrade() {
+						continue
+					}
 					h.handleConcurrencyError(c, err, "account", streamStarted)
 					return
 				}
@@ -774,6 +925,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					return
 				}
 			}
+			// 对冲请求可能落到其他账号，需基于 Bedrock 兼容处理前的 body 单独处理
+			var hedgeBase *service.ParsedRequest
+			if !reqStream && h.hedger != nil {
+				hedgeBase, _ = attemptParsedReq.CloneForBody(attemptParsedReq.Body.Bytes())
+			}
 			// Bedrock CC 兼容：清理 body 专有字段 + 过滤 anthropic-beta header，适用于所有转发路径
 			if err := attemptParsedReq.ReplaceBody(h.gatewayService.ApplyBedrockCCCompat(c, attemptParsedReq.Body.Bytes(), attemptParsedReq.Model, account, apiKey.GroupID)); err != nil {
 				h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
@@ -781,6 +937,12 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			attemptBody := attemptParsedReq.Body.Bytes()
 
+			// 流式请求按预估费用预占账号窗口费用，防止并发长输出在用量落库前大幅超出窗口上限
+			var windowCostReservation *service.WindowCostReservation
+			if reqStream {
+				windowCostReservation = h.gatewayService.ReserveWindowCost(c.Request.Context(), account, attemptParsedReq.Model, attemptBody)
+			}
+
 			// 转发请求 - 根据账号平台分流
 			c.Set("parsed_request", attemptParsedReq)
 			var result *service.ForwardResult
@@ -792,6 +954,19 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			writerSizeBeforeForward := c.Writer.Size()
 			if account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
 				result, err = h.antigravityGatewayService.Forward(requestCtx, c, account, attemptBody, hasBoundSession)
+			} else if hedgeBase != nil {
+				var servedBy *service.Account
+				result, servedBy, err = h.forwardWithHedge(requestCtx, c, currentAPIKey, account, attemptParsedReq, hedgeBase, fs.FailedAccountIDs, subject.UserID, reqLog)
+				if servedBy != account {
+					// 对冲请求胜出：主账号槽位照常释放，后续 RPM/用量/failover 按实际账号处理
+					if accountReleaseFunc != nil {
+						accountReleaseFunc()
+					}
+					accountReleaseFunc = nil
+					account = servedBy
+					windowCostReservation.Release()
+					windowCostReservation = nil
+				}
 			} else {
 				result, err = h.gatewayService.Forward(requestCtx, c, account, attemptParsedReq)
 			}
@@ -806,7 +981,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			if accountReleaseFunc != nil {
 				accountReleaseFunc()
 			}
+			if err == nil && result != nil && result.FirstTokenMs != nil {
+				service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, int64(*result.FirstTokenMs))
+			}
 			if err != nil {
+				windowCostReservation.Release()
 				// Beta policy block: return 400 immediately, no failover
 				var betaBlockedErr *service.BetaBlockedError
 				if errors.As(err, &betaBlockedErr) {
@@ -928,6 +1107,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 
 			// 捕获请求信息（用于异步记录，避免在 goroutine 中访问 gin.Context）
 			userAgent := c.GetHeader("User-Agent")
+			threadID := c.GetHeader(service.UsageThreadIDHeader)
 			clientIP := ip.GetClientIP(c)
 			// Forward 内部可能继续改写 body，usage 去重指纹必须使用最终上游接受的当前 body。
 			requestPayloadHash := service.HashUsageRequestPayload(attemptParsedReq.Body.Bytes())
@@ -952,20 +1132,22 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			quotaPlatform := service.QuotaPlatform(c.Request.Context(), currentAPIKey)
 			h.submitUsageRecordTask(c.Request.Context(), func(ctx context.Context) {
 				if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
-					Result:             result,
-					QuotaPlatform:      quotaPlatform,
-					APIKey:             currentAPIKey,
-					User:               currentAPIKey.User,
-					Account:            account,
-					Subscription:       currentSubscription,
-					InboundEndpoint:    inboundEndpoint,
-					UpstreamEndpoint:   upstreamEndpoint,
-					UserAgent:          userAgent,
-					IPAddress:          clientIP,
-					RequestPayloadHash: requestPayloadHash,
-					ForceCacheBilling:  forceCacheBilling,
-					APIKeyService:      h.apiKeyService,
-					ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
+					Result:                result,
+					QuotaPlatform:         quotaPlatform,
+					APIKey:                currentAPIKey,
+					User:                  currentAPIKey.User,
+					Account:               account,
+					Subscription:          currentSubscription,
+					InboundEndpoint:       inboundEndpoint,
+					UpstreamEndpoint:      upstreamEndpoint,
+					UserAgent:             userAgent,
+					ThreadID:              threadID,
+					IPAddress:             clientIP,
+					RequestPayloadHash:    requestPayloadHash,
+					ForceCacheBilling:     forceCacheBilling,
+					APIKeyService:         h.apiKeyService,
+					ChannelUsageFields:    channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
+					WindowCostReservation: windowCostReservation,
 				}); err != nil {
 					logger.L().With(
 						zap.String("component", "handler.gateway.messages"),
@@ -1296,6 +1478,31 @@ func (h *GatewayHandler) parseUsageDateRange(c *gin.Context) (time.Time, time.Ti
 	return startTime, endTime
 }
 
+// ThreadUsage 返回当前 API Key 所属用户某个会话（X-Sub2API-Thread-ID）的累计用量与成本。
+// GET /v1/usage/threads/:thread_id
+func (h *GatewayHandler) ThreadUsage(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	if h.usageService == nil {
+		h.errorResponse(c, http.StatusServiceUnavailable, "api_error", "Usage service unavailable")
+		return
+	}
+
+	cost, err := h.usageService.GetThreadCost(c.Request.Context(), subject.UserID, c.Param("thread_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrUsageThreadIDInvalid) {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "thread_id must be 1-128 printable characters")
+			return
+		}
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "Failed to query thread usage")
+		return
+	}
+	c.JSON(http.StatusOK, cost)
+}
+
 // buildUsageData 构建 today/total 用量摘要
 func (h *GatewayHandler) buildUsageData(ctx context.Context, apiKeyID int64) gin.H {
 	if h.usageService == nil {
@@ -1556,6 +1763,7 @@ func (h *GatewayHandler) calculateSubscriptionRemaining(group *service.Group, su
 // handleConcurrencyError handles concurrency-related acquire errors.
 func (h *GatewayHandler) handleConcurrencyError(c *gin.Context, err error, slotType string, streamStarted bool) {
 	status, errType, message := concurrencyErrorResponse(err, slotType)
+	setClientRetryAfter(c, concurrencyRetryAfter(err))
 	h.handleStreamingAwareError(c, status, errType, message, streamStarted)
 }
 
@@ -1567,6 +1775,7 @@ func (h *GatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *se
 		h.handleStreamingAwareError(c, http.StatusBadGateway, "upstream_error", service.OpenAISilentRefusalClientMessage(), streamStarted)
 		return
 	}
+	setUpstreamRetryAfter(c, failoverErr)
 
 	// 先检查透传规则
 	if h.errorPassthroughService != nil && len(responseBody) > 0 {
@@ -1645,7 +1854,7 @@ func (h *GatewayHandler) handleStreamingAwareError(c *gin.Context, status int, e
 		flusher, ok := c.Writer.(http.Flusher)
 		if ok {
 			// SSE 错误事件固定 schema，使用 Quote 直拼可避免额外 Marshal 分配。
-			errorEvent := `data: {"type":"error","error":{"type":` + strconv.Quote(errType) + `,"message":` + strconv.Quote(message) + `}}` + "\n\n"
+			errorEvent := `data: {"type":"error","error":{"type":` + strconv.Quote(errType) + `,"message":` + strconv.Quote(message) + sseErrorRetryAfterField(c) + `}}` + "\n\n"
 			if _, err := fmt.Fprint(c.Writer, errorEvent); err != nil {
 				_ = c.Error(err)
 			}
@@ -1836,9 +2045,10 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 
 	// 计算粘性会话 hash
 	parsedReq.SessionContext = &service.SessionContext{
-		ClientIP:  ip.GetClientIP(c),
-		UserAgent: c.GetHeader("User-Agent"),
-		APIKeyID:  apiKey.ID,
+		ClientIP:       ip.GetClientIP(c),
+		UserAgent:      c.GetHeader("User-Agent"),
+		APIKeyID:       apiKey.ID,
+		ConversationID: service.ConversationIDFromHeaders(c.Request.Header),
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
@@ -1854,6 +2064,7 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 		return
 	}
 	setOpsSelectedAccount(c, account.ID, account.Platform)
+	setOpsSelectedProxy(c, account)
 
 	// 转发请求（不记录使用量）
 	if err := h.gatewayService.ForwardCountTokens(c.Request.Context(), c, account, parsedReq); err != nil {
@@ -2134,7 +2345,8 @@ func billingErrorDetails(err error) (status int, code, message string, retryAfte
 	}
 	if errors.Is(err, service.ErrUserPlatformDailyQuotaExhausted) ||
 		errors.Is(err, service.ErrUserPlatformWeeklyQuotaExhausted) ||
-		errors.Is(err, service.ErrUserPlatformMonthlyQuotaExhausted) {
+		errors.Is(err, service.ErrUserPlatformMonthlyQuotaExhausted) ||
+		errors.Is(err, service.ErrSpendBudgetExhausted) {
 		// 与 RPM 超限一致映射 429 + Retry-After，让 SDK 自动退避（而非 403 直接失败）。
 		// 错误码用 rate_limit_exceeded 与 OpenAI 兼容客户端一致；细分类型由 ErrCode + window_resets_at metadata 区分。
 		msg := pkgerrors.Message(err)
@@ -2179,9 +2391,11 @@ func (h *GatewayHandler) submitUsageRecordTask(parent context.Context, task serv
 	if task == nil {
 		return
 	}
-	task = wrapUsageRecordTaskContext(parent, task)
+	task, abandon := wrapUsageRecordTaskContext(parent, task)
 	if h.usageRecordWorkerPool != nil {
-		h.usageRecordWorkerPool.Submit(task)
+		if h.usageRecordWorkerPool.Submit(task) == service.UsageRecordSubmitModeDropped {
+			abandon()
+		}
 		return
 	}
 	// 回退路径：worker 池未注入时同步执行，避免退回到无界 goroutine 模式。
@@ -2198,6 +2412,30 @@ func (h *GatewayHandler) submitUsageRecordTask(parent context.Context, task serv
 	task(ctx)
 }
 
+// RecordReusedResponseUsage 为直接复用已有响应、未请求上游的请求提交零费用用量记录（请求合并跟随者、压缩重放、缓存命中）。
+func (h *GatewayHandler) RecordReusedResponseUsage(c *gin.Context, accountID int64, model string, stream bool, duration time.Duration) {
+	if h == nil || h.gatewayService == nil || accountID <= 0 {
+		return
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		return
+	}
+	input := &service.ReusedResponseUsageInput{
+		APIKey:          apiKey,
+		AccountID:       accountID,
+		Model:           model,
+		Stream:          stream,
+		InboundEndpoint: GetInboundEndpoint(c),
+		UserAgent:       c.GetHeader("User-Agent"),
+		IPAddress:       ip.GetClientIP(c),
+		DurationMs:      int(duration.Milliseconds()),
+	}
+	h.submitUsageRecordTask(c.Request.Context(), func(ctx context.Context) {
+		h.gatewayService.RecordReusedResponseUsage(ctx, input)
+	})
+}
+
 // getUserMsgQueueMode 获取当前请求的 UMQ 模式
 // 返回 "serialize" | "throttle" | ""
 func (h *GatewayHandler) getUserMsgQueueMode(account *service.Account, parsed *service.ParsedRequest) string {
@@ -41,6 +41,17 @@ func TestBillingErrorDetails_APIKeyRateLimitStillMaps(t *testing.T) {
 	}
 }
 
+func TestBillingErrorDetails_SpendBudgetExhaustedRetriesAfterPeriodReset(t *testing.T) {
+	err := service.ErrSpendBudgetExhausted.WithMetadata(map[string]string{
+		"window_resets_at": time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339),
+	})
+	status, code, msg, retryAfter := billingErrorDetails(err)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Equal(t, "rate_limit_exceeded", code)
+	require.NotEmpty(t, msg)
+	require.Greater(t, retryAfter, 7000)
+}
+
 func TestBillingErrorDetails_BillingServiceUnavailableMapsTo503(t *testing.T) {
 	status, code, _, retryAfter := billingErrorDetails(service.ErrBillingServiceUnavailable)
 	require.Equal(t, http.StatusServiceUnavailable, status)
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	pkgerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -131,6 +132,17 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	// 余额预扣：按预估费用冻结余额；产生用量时随用量记录任务移交、扣费后释放，否则请求结束时释放
+	balanceReservation, err := h.prepaidBalanceService.Reserve(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("gateway.cc.prepaid_reserve_failed", zap.Error(err))
+		status, code, message, _ := billingErrorDetails(err)
+		h.chatCompletionsErrorResponse(c, status, code, message)
+		return
+	}
+	defer balanceReservation.Release()
+	c.Request = c.Request.WithContext(service.WithBalanceReservation(c.Request.Context(), balanceReservation))
+
 	// Parse request for session hash
 	bodyRef := service.NewRequestBodyRef(body)
 	parsedReq, _ := service.ParseGatewayRequest(bodyRef, "chat_completions")
@@ -138,9 +150,10 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		parsedReq = &service.ParsedRequest{Model: reqModel, Stream: reqStream, Body: bodyRef}
 	}
 	parsedReq.SessionContext = &service.SessionContext{
-		ClientIP:  ip.GetClientIP(c),
-		UserAgent: c.GetHeader("User-Agent"),
-		APIKeyID:  apiKey.ID,
+		ClientIP:       ip.GetClientIP(c),
+		UserAgent:      c.GetHeader("User-Agent"),
+		APIKeyID:       apiKey.ID,
+		ConversationID: service.ConversationIDFromHeaders(c.Request.Header),
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 	groupPlatform := ""
@@ -153,9 +166,9 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 	}
 
 	// 3. Account selection + failover loop
-	fs := NewFailoverState(h.maxAccountSwitches, false)
+	fs := NewFailoverState(applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitches), false)
 	if groupPlatform == service.PlatformGemini {
-		fs = NewFailoverState(h.maxAccountSwitchesGemini, false)
+		fs = NewFailoverState(applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitchesGemini), false)
 	}
 
 	for {
@@ -190,6 +203,7 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		}
 		account := selection.Account
 		setOpsSelectedAccount(c, account.ID, account.Platform)
+		setOpsSelectedProxy(c, account)
 
 		// 4. Acquire account concurrency slot
 		accountReleaseFunc := selection.ReleaseFunc
@@ -229,6 +243,16 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		if channelMapping.Mapped {
 			forwardBody = h.gatewayService.ReplaceModelInBody(body, channelMapping.MappedModel)
 		}
+		// Anthropic/Gemini 上游不接受远程图片 URL：经账号代理代取并内联为 base64
+		forwardBody, err = h.gatewayService.InlineChatCompletionsImageURLs(c.Request.Context(), account, forwardBody)
+		if err != nil {
+			if accountReleaseFunc != nil {
+				accountReleaseFunc()
+			}
+			reqLog.Info("gateway.cc.file_url_fetch_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+			h.chatCompletionsErrorResponse(c, http.StatusBadRequest, "invalid_request_error", pkgerrors.Message(err))
+			return
+		}
 		var result *service.ForwardResult
 		if account.Platform == service.PlatformGemini {
 			if h.geminiCompatService == nil {
@@ -281,6 +305,7 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 
 		// 6. Record usage
 		userAgent := c.GetHeader("User-Agent")
+		threadID := c.GetHeader(service.UsageThreadIDHeader)
 		clientIP := ip.GetClientIP(c)
 		requestPayloadHash := service.HashUsageRequestPayload(body)
 		inboundEndpoint := GetInboundEndpoint(c)
@@ -298,6 +323,7 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 				InboundEndpoint:    inboundEndpoint,
 				UpstreamEndpoint:   upstreamEndpoint,
 				UserAgent:          userAgent,
+				ThreadID:           threadID,
 				IPAddress:          clientIP,
 				RequestPayloadHash: requestPayloadHash,
 				APIKeyService:      h.apiKeyService,
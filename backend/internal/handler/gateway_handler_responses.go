@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	pkgerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -140,6 +141,17 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 
+	// 余额预扣：按预估费用冻结余额；产生用量时随用量记录任务移交、扣费后释放，否则请求结束时释放
+	balanceReservation, err := h.prepaidBalanceService.Reserve(requestCtx, apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("gateway.responses.prepaid_reserve_failed", zap.Error(err))
+		status, code, message, _ := billingErrorDetails(err)
+		h.responsesErrorResponse(c, status, code, message)
+		return
+	}
+	defer balanceReservation.Release()
+	c.Request = c.Request.WithContext(service.WithBalanceReservation(c.Request.Context(), balanceReservation))
+
 	// Parse request for session hash
 	bodyRef := service.NewRequestBodyRef(body)
 	parsedReq, _ := service.ParseGatewayRequest(bodyRef, "responses")
@@ -147,14 +159,15 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		parsedReq = &service.ParsedRequest{Model: reqModel, Stream: reqStream, Body: bodyRef}
 	}
 	parsedReq.SessionContext = &service.SessionContext{
-		ClientIP:  ip.GetClientIP(c),
-		UserAgent: c.GetHeader("User-Agent"),
-		APIKeyID:  apiKey.ID,
+		ClientIP:       ip.GetClientIP(c),
+		UserAgent:      c.GetHeader("User-Agent"),
+		APIKeyID:       apiKey.ID,
+		ConversationID: service.ConversationIDFromHeaders(c.Request.Header),
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
 	// 3. Account selection + failover loop
-	fs := NewFailoverState(h.maxAccountSwitches, false)
+	fs := NewFailoverState(applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitches), false)
 
 	for {
 		selection, err := h.gatewayService.SelectAccountWithLoadAwareness(requestCtx, apiKey.GroupID, sessionHash, reqModel, fs.FailedAccountIDs, "", int64(0))
@@ -188,6 +201,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		}
 		account := selection.Account
 		setOpsSelectedAccount(c, account.ID, account.Platform)
+		setOpsSelectedProxy(c, account)
 
 		// 4. Acquire account concurrency slot
 		accountReleaseFunc := selection.ReleaseFunc
@@ -219,6 +233,16 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		if channelMapping.Mapped {
 			forwardBody = h.gatewayService.ReplaceModelInBody(body, channelMapping.MappedModel)
 		}
+		// Anthropic 上游不接受图片/文件 URL：经账号代理代取并内联为 base64
+		forwardBody, err = h.gatewayService.InlineResponsesFileURLs(requestCtx, account, forwardBody)
+		if err != nil {
+			if accountReleaseFunc != nil {
+				accountReleaseFunc()
+			}
+			reqLog.Info("gateway.responses.file_url_fetch_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+			h.responsesErrorResponse(c, http.StatusBadRequest, "invalid_request_error", pkgerrors.Message(err))
+			return
+		}
 		result, err := h.gatewayService.ForwardAsResponses(requestCtx, c, account, forwardBody, parsedReq)
 
 		if accountReleaseFunc != nil {
@@ -260,6 +284,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 		// 6. Record usage
 		userAgent := c.GetHeader("User-Agent")
+		threadID := c.GetHeader(service.UsageThreadIDHeader)
 		clientIP := ip.GetClientIP(c)
 		requestPayloadHash := service.HashUsageRequestPayload(body)
 		inboundEndpoint := GetInboundEndpoint(c)
@@ -277,6 +302,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 				InboundEndpoint:    inboundEndpoint,
 				UpstreamEndpoint:   upstreamEndpoint,
 				UserAgent:          userAgent,
+				ThreadID:           threadID,
 				IPAddress:          clientIP,
 				RequestPayloadHash: requestPayloadHash,
 				APIKeyService:      h.apiKeyService,
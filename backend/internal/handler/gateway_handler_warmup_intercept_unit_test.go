@@ -94,6 +94,18 @@ func (f *fakeGroupRepo) GetAccountIDsByGroupIDs(context.Context, []int64) ([]int
 	return nil, nil
 }
 func (f *fakeGroupRepo) BindAccountsToGroup(context.Context, int64, []int64) error { return nil }
+func (f *fakeGroupRepo) ListAccountTiers(context.Context, int64) ([]service.GroupAccountTier, error) {
+	return nil, nil
+}
+func (f *fakeGroupRepo) SetAccountTiers(context.Context, int64, []service.GroupAccountTier) error {
+	return nil
+}
+func (f *fakeGroupRepo) ListAccountConcurrencyShares(context.Context, int64) ([]service.GroupAccountConcurrencyShare, error) {
+	return nil, nil
+}
+func (f *fakeGroupRepo) SetAccountConcurrencyShares(context.Context, int64, []service.GroupAccountConcurrencyShare) error {
+	return nil
+}
 func (f *fakeGroupRepo) UpdateSortOrders(context.Context, []service.GroupSortOrderUpdate) error {
 	return nil
 }
@@ -368,8 +368,15 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 		pingCh = pingTicker.C
 	}
 
+	// 账号槽位争抢时，分组内近期消耗超出公平份额的用户放慢重试节奏，
+	// 让轻度用户优先拿到释放出来的槽位。
+	fairnessMultiplier := 1.0
+	if slotType == "account" {
+		fairnessMultiplier = h.userFairnessMultiplier(c)
+	}
+
 	backoff := initialBackoff
-	timer := time.NewTimer(backoff)
+	timer := time.NewTimer(scaleBackoff(backoff, fairnessMultiplier))
 	defer timer.Stop()
 
 	for {
@@ -408,11 +415,39 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 				return result.ReleaseFunc, nil
 			}
 			backoff = nextBackoff(backoff)
-			timer.Reset(backoff)
+			timer.Reset(scaleBackoff(backoff, fairnessMultiplier))
 		}
 	}
 }
 
+// userFairnessMultiplier 根据当前 API Key 所属分组与用户，返回公平调度的退避倍数。
+func (h *ConcurrencyHelper) userFairnessMultiplier(c *gin.Context) float64 {
+	if h == nil || h.concurrencyService == nil || c == nil {
+		return 1
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		return 1
+	}
+	var groupID int64
+	if apiKey.GroupID != nil {
+		groupID = *apiKey.GroupID
+	}
+	return h.concurrencyService.UserFairnessBackoffMultiplier(groupID, apiKey.UserID)
+}
+
+// scaleBackoff 按公平调度倍数放大退避时间，上限同步放大。
+func scaleBackoff(backoff time.Duration, multiplier float64) time.Duration {
+	if multiplier <= 1 {
+		return backoff
+	}
+	scaled := time.Duration(float64(backoff) * multiplier)
+	if limit := time.Duration(float64(maxBackoff) * multiplier); scaled > limit {
+		return limit
+	}
+	return scaled
+}
+
 // AcquireAccountSlotWithWaitTimeout acquires an account slot with a custom timeout (keeps SSE ping).
 func (h *ConcurrencyHelper) AcquireAccountSlotWithWaitTimeout(c *gin.Context, accountID int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool) (func(), error) {
 	return h.waitForSlotWithPingTimeout(c, "account", accountID, maxConcurrency, timeout, isStream, streamStarted, true)
@@ -104,3 +104,12 @@ func BenchmarkNextBackoff(b *testing.B) {
 		}
 	}
 }
+
+func TestScaleBackoff_UserFairness(t *testing.T) {
+	require.Equal(t, 200*time.Millisecond, scaleBackoff(200*time.Millisecond, 1))
+	require.Equal(t, 200*time.Millisecond, scaleBackoff(200*time.Millisecond, 0.5))
+	require.Equal(t, 600*time.Millisecond, scaleBackoff(200*time.Millisecond, 3))
+	// 上限随倍数同步放大
+	require.Equal(t, 2*maxBackoff, scaleBackoff(maxBackoff, 2))
+	require.Equal(t, 3*maxBackoff, scaleBackoff(10*time.Second, 3))
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// recordGatewayMetrics 在网关请求结束后记录 Prometheus 指标：平台/模型/最终账号/状态码、总耗时，
+// 以及响应已固化为 200 后的流内失败。平台口径与 ops 错误日志一致（优先分组平台）。
+func recordGatewayMetrics(c *gin.Context, duration time.Duration) {
+	if c == nil || c.Request == nil || !metrics.Enabled() {
+		return
+	}
+	platform := resolveOpsPlatform(getOpsAPIKey(c), guessPlatformFromPath(c.Request.URL.Path))
+
+	var model string
+	if v, ok := c.Get(opsModelKey); ok {
+		model, _ = v.(string)
+	}
+	var accountID int64
+	if v, ok := c.Get(opsAccountIDKey); ok {
+		accountID, _ = v.(int64)
+	}
+	metrics.ObserveGatewayRequest(platform, model, accountID, c.Writer.Status(), duration)
+
+	if streamErr, ok := service.GetOpsStreamError(c); ok {
+		metrics.IncStreamFault(platform, streamErr.ErrType)
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// applyOverloadDowngrade 在首选账号全部饱和/冷却时按分组策略把请求改写为备用模型。
+// 每个请求最多降级一次（downgradedFrom 非空即已降级）；返回新的模型与请求体。
+// 流式响应头尚未发出时写入 X-Sub2API-Downgraded-From 标记原始模型。
+func applyOverloadDowngrade(c *gin.Context, group *service.Group, downgradedFrom *string, reqModel string, body []byte, streamStarted bool) (string, []byte, bool) {
+	if *downgradedFrom != "" {
+		return reqModel, body, false
+	}
+	fallback := group.ResolveOverloadFallbackModel(reqModel)
+	if fallback == "" {
+		return reqModel, body, false
+	}
+	*downgradedFrom = reqModel
+	if !streamStarted {
+		c.Header(service.OverloadDowngradeHeader, reqModel)
+	}
+	return fallback, service.ReplaceModelInBody(body, fallback), true
+}
+
+// isAccountSlotWaitTimeout 判断账号槽位等待是否因超时失败（客户端断开不触发降级）。
+func isAccountSlotWaitTimeout(err error) bool {
+	var concurrencyErr *ConcurrencyError
+	return errors.As(err, &concurrencyErr) && concurrencyErr.IsTimeout
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOverloadDowngrade_RewritesOnceAndFlagsHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	group := &service.Group{OverloadFallbackModels: map[string]string{
+		"claude-opus-*":   "claude-sonnet-4-5",
+		"claude-sonnet-*": "claude-haiku-4-5",
+	}}
+	downgradedFrom := ""
+
+	model, body, ok := applyOverloadDowngrade(c, group, &downgradedFrom, "claude-opus-4-1", []byte(`{"model":"claude-opus-4-1","max_tokens":8}`), false)
+	require.True(t, ok)
+	require.Equal(t, "claude-sonnet-4-5", model)
+	require.JSONEq(t, `{"model":"claude-sonnet-4-5","max_tokens":8}`, string(body))
+	require.Equal(t, "claude-opus-4-1", downgradedFrom)
+	require.Equal(t, "claude-opus-4-1", rec.Header().Get(service.OverloadDowngradeHeader))
+
+	// 已降级过的请求不再级联降级
+	model, _, ok = applyOverloadDowngrade(c, group, &downgradedFrom, model, body, false)
+	require.False(t, ok)
+	require.Equal(t, "claude-sonnet-4-5", model)
+}
+
+func TestApplyOverloadDowngrade_NoPolicyOrStreamStarted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	downgradedFrom := ""
+
+	_, _, ok := applyOverloadDowngrade(c, nil, &downgradedFrom, "claude-opus-4-1", nil, false)
+	require.False(t, ok)
+	_, _, ok = applyOverloadDowngrade(c, &service.Group{}, &downgradedFrom, "claude-opus-4-1", nil, false)
+	require.False(t, ok)
+	require.Empty(t, downgradedFrom)
+
+	group := &service.Group{OverloadFallbackModels: map[string]string{"claude-opus-4-1": "claude-sonnet-4-5"}}
+	_, _, ok = applyOverloadDowngrade(c, group, &downgradedFrom, "claude-opus-4-1", []byte(`{"model":"claude-opus-4-1"}`), true)
+	require.True(t, ok)
+	require.Empty(t, rec.Header().Get(service.OverloadDowngradeHeader), "headers already flushed for streams")
+}
+
+func TestIsAccountSlotWaitTimeout(t *testing.T) {
+	require.True(t, isAccountSlotWaitTimeout(&ConcurrencyError{SlotType: "account", IsTimeout: true}))
+	require.True(t, isAccountSlotWaitTimeout(fmt.Errorf("wrap: %w", &ConcurrencyError{SlotType: "account", IsTimeout: true})))
+	require.False(t, isAccountSlotWaitTimeout(&ConcurrencyError{SlotType: "account"}))
+	require.False(t, isAccountSlotWaitTimeout(context.Canceled))
+}
@@ -213,6 +213,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 	userReleaseFunc, err := geminiConcurrency.AcquireUserSlotWithWait(c, authSubject.UserID, authSubject.Concurrency, stream, &streamStarted)
 	if err != nil {
 		reqLog.Warn("gemini.user_slot_acquire_failed", zap.Error(err))
+		setClientRetryAfter(c, concurrencyRetryAfter(err))
 		googleError(c, http.StatusTooManyRequests, err.Error())
 		return
 	}
@@ -233,6 +234,17 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		return
 	}
 
+	// 余额预扣：按预估费用冻结余额；产生用量时随用量记录任务移交、扣费后释放，否则请求结束时释放
+	balanceReservation, err := h.prepaidBalanceService.Reserve(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("gemini.prepaid_reserve_failed", zap.Error(err))
+		status, _, message, _ := billingErrorDetails(err)
+		googleError(c, status, message)
+		return
+	}
+	defer balanceReservation.Release()
+	c.Request = c.Request.WithContext(service.WithBalanceReservation(c.Request.Context(), balanceReservation))
+
 	// 3) select account (sticky session based on request body)
 	// 优先使用 Gemini CLI 的会话标识（privileged-user-id + tmp 目录哈希）
 	sessionHash := extractGeminiCLISessionHash(c, body)
@@ -337,7 +349,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 	hasBoundSession := sessionKey != "" && sessionBoundAccountID > 0
 	cleanedForUnknownBinding := false
 
-	fs := NewFailoverState(h.maxAccountSwitchesGemini, hasBoundSession)
+	fs := NewFailoverState(applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitchesGemini), hasBoundSession)
 
 	// 单账号分组提前设置 SingleAccountRetry 标记，让 Service 层首次 503 就不设模型限流标记。
 	// 避免单账号分组收到 503 (MODEL_CAPACITY_EXHAUSTED) 时设 29s 限流，导致后续请求连续快速失败。
@@ -376,6 +388,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		}
 		account := selection.Account
 		setOpsSelectedAccount(c, account.ID, account.Platform)
+		setOpsSelectedProxy(c, account)
 
 		// 检测账号切换：如果粘性会话绑定的账号与当前选择的账号不同，清除 thoughtSignature
 		// 注意：Gemini 原生 API 的 thoughtSignature 与具体上游账号强相关；跨账号透传会导致 400。
@@ -418,6 +431,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 					zap.Int64("account_id", account.ID),
 					zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
 				)
+				setClientRetryAfter(c, waitQueueFullRetryAfterSeconds)
 				googleError(c, http.StatusTooManyRequests, "Too many pending requests, please retry later")
 				return
 			}
@@ -440,6 +454,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 			)
 			if err != nil {
 				reqLog.Warn("gemini.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+				setClientRetryAfter(c, concurrencyRetryAfter(err))
 				googleError(c, http.StatusTooManyRequests, err.Error())
 				return
 			}
@@ -500,6 +515,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 
 		// 捕获请求信息（用于异步记录，避免在 goroutine 中访问 gin.Context）
 		userAgent := c.GetHeader("User-Agent")
+		threadID := c.GetHeader(service.UsageThreadIDHeader)
 		clientIP := ip.GetClientIP(c)
 
 		// 保存 Gemini 内容摘要会话（用于 Fallback 匹配）
@@ -535,6 +551,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 				InboundEndpoint:       inboundEndpoint,
 				UpstreamEndpoint:      upstreamEndpoint,
 				UserAgent:             userAgent,
+				ThreadID:              threadID,
 				IPAddress:             clientIP,
 				RequestPayloadHash:    requestPayloadHash,
 				LongContextThreshold:  200000, // Gemini 200K 阈值
@@ -588,6 +605,7 @@ func (h *GatewayHandler) handleGeminiFailoverExhausted(c *gin.Context, failoverE
 
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
+	setUpstreamRetryAfter(c, failoverErr)
 
 	// 先检查透传规则
 	if h.errorPassthroughService != nil && len(responseBody) > 0 {
@@ -38,6 +38,25 @@ type AdminHandlers struct {
 	Payment                *admin.PaymentHandler
 	Affiliate              *admin.AffiliateHandler
 	Compliance             *admin.ComplianceHandler
+	ConfigSync             *admin.ConfigSyncHandler
+	APIKeyTrace            *admin.APIKeyTraceHandler
+	BillingStatement       *admin.BillingStatementHandler
+	CapacityForecast       *admin.CapacityForecastHandler
+	CacheEfficiency        *admin.CacheEfficiencyHandler
+	ClientAnalytics        *admin.ClientAnalyticsHandler
+	MappingSimulation      *admin.MappingSimulationHandler
+	StaleAccount           *admin.StaleAccountHandler
+	ConfigVersion          *admin.ConfigVersionHandler
+	GraphQL                *admin.GraphQLHandler
+	Debug                  *admin.DebugHandler
+	TransformFixture       *admin.TransformFixtureHandler
+	SpendBudget            *admin.SpendBudgetHandler
+	ModelPrice             *admin.ModelPriceHandler
+	UsageReport            *admin.UsageReportHandler
+	DataExport             *admin.DataExportHandler
+	RequestClassSLO        *admin.RequestClassSLOHandler
+	ServiceToken           *admin.ServiceTokenHandler
+	AccountLease           *admin.AccountLeaseHandler
 }
 
 // Handlers contains all HTTP handlers
@@ -59,6 +78,10 @@ type Handlers struct {
 	PaymentWebhook   *PaymentWebhookHandler
 	AvailableChannel *AvailableChannelHandler
 	BatchImage       *BatchImageHandler
+	UsageAlert       *UserUsageAlertHandler
+	BillingStatement *BillingStatementHandler
+	Balance          *BalanceHandler
+	Project          *APIKeyProjectHandler
 }
 
 // BuildInfo contains build-time information
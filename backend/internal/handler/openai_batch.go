@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// openAIBatchContext 是 Batch API 各接口共用的鉴权上下文。
+type openAIBatchContext struct {
+	apiKey  *service.APIKey
+	subject middleware2.AuthSubject
+	reqLog  *zap.Logger
+}
+
+func (h *OpenAIGatewayHandler) prepareOpenAIBatch(c *gin.Context, component string) (*openAIBatchContext, bool) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return nil, false
+	}
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return nil, false
+	}
+	reqLog := requestLogger(
+		c,
+		component,
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return nil, false
+	}
+	if !h.gatewayService.OpenAIBatchEnabled() {
+		h.errorResponse(c, http.StatusNotFound, "not_found_error", "Batch API is not enabled")
+		return nil, false
+	}
+	return &openAIBatchContext{apiKey: apiKey, subject: subject, reqLog: reqLog}, true
+}
+
+// loadOpenAIBatchObject 查询当前 API Key 创建的对象及其绑定账号，失败时已写入响应。
+func (h *OpenAIGatewayHandler) loadOpenAIBatchObject(c *gin.Context, bc *openAIBatchContext, objectType, objectID string) (*service.OpenAIBatchObject, *service.Account, bool) {
+	obj, account, err := h.gatewayService.GetOpenAIBatchObject(c.Request.Context(), bc.apiKey.ID, objectType, objectID)
+	if err != nil {
+		if errors.Is(err, service.ErrOpenAIBatchObjectNotFound) {
+			h.errorResponse(c, http.StatusNotFound, "invalid_request_error", "No such "+objectType+": "+objectID)
+			return nil, nil, false
+		}
+		bc.reqLog.Warn("openai_batch.load_object_failed", zap.String("object_id", objectID), zap.Error(err))
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "Failed to load "+objectType)
+		return nil, nil, false
+	}
+	setOpsSelectedAccount(c, account.ID, account.Platform)
+	setOpsSelectedProxy(c, account)
+	return obj, account, true
+}
+
+// checkOpenAIBatchBilling 创建类请求前检查计费资格，失败时已写入响应。
+func (h *OpenAIGatewayHandler) checkOpenAIBatchBilling(c *gin.Context, bc *openAIBatchContext) bool {
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), bc.apiKey.User, bc.apiKey, bc.apiKey.Group, subscription, service.QuotaPlatform(c.Request.Context(), bc.apiKey)); err != nil {
+		bc.reqLog.Info("openai_batch.billing_check_failed", zap.Error(err))
+		status, code, message, retryAfter := billingErrorDetails(err)
+		if retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+		}
+		h.errorResponse(c, status, code, message)
+		return false
+	}
+	return true
+}
+
+// forwardPinnedOpenAIBatchRequest 把请求转发到对象绑定的账号；上游对象只存在于该账号，不做 failover。
+func (h *OpenAIGatewayHandler) forwardPinnedOpenAIBatchRequest(c *gin.Context, bc *openAIBatchContext, account *service.Account, method, endpoint string, body []byte, contentType string, rewrite service.OpenAIBatchResponseRewriter) ([]byte, bool) {
+	writerSizeBeforeForward := c.Writer.Size()
+	respBody, err := h.gatewayService.ForwardOpenAIBatchRequest(c.Request.Context(), c, account, method, endpoint, body, contentType, rewrite)
+	if err != nil {
+		var failoverErr *service.UpstreamFailoverError
+		if errors.As(err, &failoverErr) {
+			h.handleFailoverExhausted(c, failoverErr, c.Writer.Size() != writerSizeBeforeForward)
+		} else if c.Writer.Size() == writerSizeBeforeForward {
+			h.errorResponse(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
+		}
+		bc.reqLog.Warn("openai_batch.forward_failed",
+			zap.Int64("account_id", account.ID),
+			zap.String("endpoint", endpoint),
+			zap.Error(err),
+		)
+		return nil, false
+	}
+	return respBody, true
+}
+
+// BatchCreate creates a batch from a previously uploaded input file, on the account holding that file.
+// POST /v1/batches
+func (h *OpenAIGatewayHandler) BatchCreate(c *gin.Context) {
+	bc, ok := h.prepareOpenAIBatch(c, "handler.openai_gateway.batch_create")
+	if !ok {
+		return
+	}
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+	if !gjson.ValidBytes(body) {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+	inputFileID := strings.TrimSpace(gjson.GetBytes(body, "input_file_id").String())
+	if inputFileID == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "input_file_id is required")
+		return
+	}
+	file, account, ok := h.loadOpenAIBatchObject(c, bc, service.OpenAIBatchObjectFile, inputFileID)
+	if !ok {
+		return
+	}
+	setOpsRequestContext(c, file.Model, false)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeSync))
+	if !h.checkOpenAIBatchBilling(c, bc) {
+		return
+	}
+
+	// 客户端传的是 PublicID，转发前换回该账号下的上游文件 ID。
+	body, err = sjson.SetBytes(body, "input_file_id", file.ObjectID)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+	_, _ = h.forwardPinnedOpenAIBatchRequest(c, bc, account, http.MethodPost, service.OpenAIBatchEndpoint("", ""), body, "application/json", func(respBody []byte) ([]byte, error) {
+		state := service.ParseOpenAIBatchState(respBody)
+		if !service.ValidOpenAIBatchObjectID(state.ID) {
+			bc.reqLog.Warn("openai_batch.batch_id_missing", zap.Int64("account_id", account.ID))
+		} else if err := h.gatewayService.RegisterOpenAIBatchObject(c.Request.Context(), &service.OpenAIBatchObject{
+			ObjectType: service.OpenAIBatchObjectBatch,
+			ObjectID:   state.ID,
+			UserID:     bc.subject.UserID,
+			APIKeyID:   bc.apiKey.ID,
+			AccountID:  account.ID,
+			GroupID:    bc.apiKey.GroupID,
+			Model:      file.Model,
+			Status:     state.Status,
+		}); err != nil {
+			bc.reqLog.Error("openai_batch.register_batch_failed",
+				zap.Int64("account_id", account.ID),
+				zap.String("batch_id", state.ID),
+				zap.Error(err),
+			)
+		}
+		return h.gatewayService.RewriteOpenAIBatchFileIDs(c.Request.Context(), bc.apiKey.ID, respBody)
+	})
+}
+
+// BatchRetrieve polls a batch on the account that created it. The first poll that observes a
+// terminal status bills the batch's reported usage once, at Batch API pricing.
+// GET /v1/batches/:batch_id
+func (h *OpenAIGatewayHandler) BatchRetrieve(c *gin.Context) {
+	h.handlePinnedOpenAIBatch(c, "handler.openai_gateway.batch_retrieve", http.MethodGet, "")
+}
+
+// BatchCancel cancels a batch on the account that created it.
+// POST /v1/batches/:batch_id/cancel
+func (h *OpenAIGatewayHandler) BatchCancel(c *gin.Context) {
+	h.handlePinnedOpenAIBatch(c, "handler.openai_gateway.batch_cancel", http.MethodPost, "cancel")
+}
+
+func (h *OpenAIGatewayHandler) handlePinnedOpenAIBatch(c *gin.Context, component, method, action string) {
+	bc, ok := h.prepareOpenAIBatch(c, component)
+	if !ok {
+		return
+	}
+	batchID := strings.TrimSpace(c.Param("batch_id"))
+	batch, account, ok := h.loadOpenAIBatchObject(c, bc, service.OpenAIBatchObjectBatch, batchID)
+	if !ok {
+		return
+	}
+	setOpsRequestContext(c, batch.Model, false)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeSync))
+
+	// 先同步状态（登记结果/错误文件），再把响应中的文件 ID 换成 PublicID。
+	var (
+		state  service.OpenAIBatchState
+		settle bool
+	)
+	_, _ = h.forwardPinnedOpenAIBatchRequest(c, bc, account, method, service.OpenAIBatchEndpoint(batchID, action), nil, "", func(respBody []byte) ([]byte, error) {
+		state = service.ParseOpenAIBatchState(respBody)
+		var err error
+		settle, err = h.gatewayService.SyncOpenAIBatchState(c.Request.Context(), batch, state)
+		if err != nil {
+			bc.reqLog.Warn("openai_batch.sync_state_failed", zap.String("batch_id", batchID), zap.Error(err))
+			return nil, err
+		}
+		return h.gatewayService.RewriteOpenAIBatchFileIDs(c.Request.Context(), bc.apiKey.ID, respBody)
+	})
+	// 结算权一旦抢到就必须记账，即使后续改写响应失败。
+	if settle {
+		h.settleOpenAIBatchUsage(c, bc, batch, account, state)
+	}
+}
+
+// settleOpenAIBatchUsage 记录批次用量；记录失败时撤销结算抢占，由下一次轮询重试。
+func (h *OpenAIGatewayHandler) settleOpenAIBatchUsage(c *gin.Context, bc *openAIBatchContext, batch *service.OpenAIBatchObject, account *service.Account, state service.OpenAIBatchState) {
+	apiKey := bc.apiKey
+	result := service.BuildOpenAIBatchUsageResult(batch, state)
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+	userAgent := c.GetHeader("User-Agent")
+	clientIP := ip.GetClientIP(c)
+	inboundEndpoint := GetInboundEndpoint(c)
+	upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
+	quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+
+	h.submitMandatoryUsageRecordTask(c.Request.Context(), func(ctx context.Context) {
+		if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+			Result:           result,
+			APIKey:           apiKey,
+			User:             apiKey.User,
+			Account:          account,
+			Subscription:     subscription,
+			InboundEndpoint:  inboundEndpoint,
+			UpstreamEndpoint: upstreamEndpoint,
+			UserAgent:        userAgent,
+			IPAddress:        clientIP,
+			APIKeyService:    h.apiKeyService,
+			QuotaPlatform:    quotaPlatform,
+			ChannelUsageFields: service.ChannelUsageFields{
+				OriginalModel:      batch.Model,
+				ChannelMappedModel: batch.Model,
+			},
+		}); err != nil {
+			logger.L().With(
+				zap.String("component", "handler.openai_gateway.batch_retrieve"),
+				zap.Int64("user_id", bc.subject.UserID),
+				zap.Int64("api_key_id", apiKey.ID),
+				zap.String("batch_id", batch.ObjectID),
+				zap.Int64("account_id", account.ID),
+			).Error("openai_batch.record_usage_failed", zap.Error(err))
+			if releaseErr := h.gatewayService.ReleaseOpenAIBatchSettlement(ctx, batch.ID); releaseErr != nil {
+				logger.L().Error("openai_batch.release_settlement_failed", zap.String("batch_id", batch.ObjectID), zap.Error(releaseErr))
+			}
+		}
+	})
+}
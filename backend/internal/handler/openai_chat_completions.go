@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	pkgerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/openai_compat"
@@ -96,6 +97,25 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 	// 解析渠道级模型映射
 	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
 
+	capability, err := h.gatewayService.ResolveCapabilityCheck(c.Request.Context(), apiKey.Group, reqModel, service.DetectChatCompletionsCapabilityRequirements(body))
+	if err != nil {
+		reqLog.Info("openai_chat_completions.capability_check_rejected", zap.Error(err))
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", pkgerrors.Message(err))
+		return
+	}
+	if capability != nil && capability.RoutedModel != "" {
+		reqLog.Info("openai_chat_completions.capability_check_routed",
+			zap.String("to_model", capability.RoutedModel),
+			zap.String("capability", string(capability.Missing.Capability)),
+			zap.String("location", capability.Missing.Location),
+		)
+		c.Header(service.CapabilityRoutedHeader, reqModel)
+		body = h.gatewayService.ReplaceModelInBody(body, capability.RoutedModel)
+		reqModel = capability.RoutedModel
+		channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+		setOpsRequestContext(c, reqModel, reqStream)
+	}
+
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
 	}
@@ -124,10 +144,21 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	// 余额预扣：按预估费用冻结余额；产生用量时随用量记录任务移交、扣费后释放，否则请求结束时释放
+	balanceReservation, err := h.prepaidBalanceService.Reserve(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("openai_chat_completions.prepaid_reserve_failed", zap.Error(err))
+		status, code, message, _ := billingErrorDetails(err)
+		h.handleStreamingAwareError(c, status, code, message, streamStarted)
+		return
+	}
+	defer balanceReservation.Release()
+	c.Request = c.Request.WithContext(service.WithBalanceReservation(c.Request.Context(), balanceReservation))
+
 	sessionHash := h.gatewayService.GenerateSessionHash(c, body)
 	promptCacheKey := h.gatewayService.ExtractSessionID(c, body)
 
-	maxAccountSwitches := h.maxAccountSwitches
+	maxAccountSwitches := applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitches)
 	switchCount := 0
 	failedAccountIDs := make(map[int64]struct{})
 	sameAccountRetryCount := make(map[int64]int)
@@ -182,6 +213,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		reqLog.Debug("openai_chat_completions.account_selected", zap.Int64("account_id", account.ID), zap.String("account_name", account.Name))
 		_ = scheduleDecision
 		setOpsSelectedAccount(c, account.ID, account.Platform)
+		setOpsSelectedProxy(c, account)
 
 		accountReleaseFunc, acquired := h.acquireResponsesAccountSlot(c, apiKey.GroupID, sessionHash, selection, reqStream, &streamStarted, reqLog)
 		if !acquired {
@@ -296,6 +328,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		}
 
 		userAgent := c.GetHeader("User-Agent")
+		threadID := c.GetHeader(service.UsageThreadIDHeader)
 		clientIP := ip.GetClientIP(c)
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := resolveOpenAIUpstreamEndpoint(c, account)
@@ -312,6 +345,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 				InboundEndpoint:    inboundEndpoint,
 				UpstreamEndpoint:   upstreamEndpoint,
 				UserAgent:          userAgent,
+				ThreadID:           threadID,
 				IPAddress:          clientIP,
 				APIKeyService:      h.apiKeyService,
 				QuotaPlatform:      quotaPlatform,
@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FileUpload uploads a file to an account that supports the Files/Batch API.
+// The file is pinned to that account and the client receives a gateway-issued file ID;
+// later requests referencing that ID are resolved back to the account and upstream file ID.
+// Batch input files (purpose=batch) must use a single model, which routes and bills the batch.
+// POST /v1/files
+func (h *OpenAIGatewayHandler) FileUpload(c *gin.Context) {
+	streamStarted := false
+	requestStart := time.Now()
+	bc, ok := h.prepareOpenAIBatch(c, "handler.openai_gateway.file_upload")
+	if !ok {
+		return
+	}
+	apiKey, reqLog := bc.apiKey, bc.reqLog
+
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			h.errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+	contentType := c.GetHeader("Content-Type")
+	upload, err := service.ParseOpenAIBatchFileUpload(body, contentType)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Invalid file upload: "+err.Error())
+		return
+	}
+	if upload.Purpose == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "purpose is required")
+		return
+	}
+	if !upload.HasFile {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "file is required")
+		return
+	}
+
+	reqModel := upload.Model
+	reqLog = reqLog.With(zap.String("purpose", upload.Purpose), zap.String("model", reqModel), zap.Int("lines", upload.Lines))
+	setOpsRequestContext(c, reqModel, false)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeSync))
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, bc.subject.UserID, bc.subject.Concurrency, false, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+	if !h.checkOpenAIBatchBilling(c, bc) {
+		return
+	}
+
+	failedAccountIDs := make(map[int64]struct{})
+	var lastFailoverErr *service.UpstreamFailoverError
+	switchCount := 0
+	maxAccountSwitches := applyRequestClassPolicy(c, h.cfg, h.maxAccountSwitches)
+	if maxAccountSwitches <= 0 {
+		maxAccountSwitches = 3
+	}
+	routingStart := time.Now()
+
+	for {
+		selection, _, err := h.gatewayService.SelectAccountWithSchedulerForCapability(
+			c.Request.Context(),
+			apiKey.GroupID,
+			"",
+			"",
+			reqModel,
+			failedAccountIDs,
+			service.OpenAIUpstreamTransportHTTPSSE,
+			service.OpenAIEndpointCapabilityBatch,
+			false,
+			false,
+		)
+		if err != nil || selection == nil || selection.Account == nil {
+			reqLog.Warn("openai_files.account_select_failed",
+				zap.Error(err),
+				zap.Int("excluded_account_count", len(failedAccountIDs)),
+			)
+			if len(failedAccountIDs) == 0 {
+				cls := classifyNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel, service.PlatformOpenAI)
+				if !cls.ModelNotFound {
+					markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
+				}
+				h.errorResponse(c, cls.Status, cls.ErrType, cls.Message)
+				return
+			}
+			if lastFailoverErr != nil {
+				h.handleFailoverExhausted(c, lastFailoverErr, false)
+			} else {
+				h.errorResponse(c, http.StatusBadGateway, "api_error", "Upstream request failed")
+			}
+			return
+		}
+		account := selection.Account
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+		setOpsSelectedProxy(c, account)
+
+		accountReleaseFunc, accountAcquired := h.acquireResponsesAccountSlot(c, apiKey.GroupID, "", selection, false, &streamStarted, reqLog)
+		if !accountAcquired {
+			return
+		}
+		service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, time.Since(routingStart).Milliseconds())
+
+		// 登记绑定并把响应中的上游文件 ID 换成 PublicID；登记失败时不能把上游 ID 返回给客户端。
+		var (
+			file        *service.OpenAIBatchObject
+			registerErr error
+		)
+		rewrite := func(respBody []byte) ([]byte, error) {
+			info := service.ParseOpenAIFileInfo(respBody)
+			if !service.ValidOpenAIBatchObjectID(info.ID) {
+				registerErr = errors.New("upstream file id missing")
+				return nil, registerErr
+			}
+			purpose := info.Purpose
+			if purpose == "" {
+				purpose = upload.Purpose
+			}
+			filename := info.Filename
+			if filename == "" {
+				filename = upload.Filename
+			}
+			file = &service.OpenAIBatchObject{
+				ObjectType: service.OpenAIBatchObjectFile,
+				ObjectID:   info.ID,
+				UserID:     bc.subject.UserID,
+				APIKeyID:   apiKey.ID,
+				AccountID:  account.ID,
+				GroupID:    apiKey.GroupID,
+				Model:      reqModel,
+				Status:     info.Status,
+				Purpose:    purpose,
+				Filename:   filename,
+				Bytes:      info.Bytes,
+			}
+			if registerErr = h.gatewayService.RegisterOpenAIBatchObject(c.Request.Context(), file); registerErr != nil {
+				return nil, registerErr
+			}
+			return service.RewriteOpenAIFileID(respBody, file.PublicID)
+		}
+
+		writerSizeBeforeForward := c.Writer.Size()
+		_, err = func() ([]byte, error) {
+			defer func() {
+				if accountReleaseFunc != nil {
+					accountReleaseFunc()
+				}
+			}()
+			return h.gatewayService.ForwardOpenAIBatchRequest(c.Request.Context(), c, account, http.MethodPost, service.OpenAIBatchFilesEndpoint(), body, contentType, rewrite)
+		}()
+		if registerErr != nil {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, nil)
+			reqLog.Error("openai_files.register_file_failed", zap.Int64("account_id", account.ID), zap.Error(registerErr))
+			return
+		}
+		if err != nil {
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				if c.Writer.Size() != writerSizeBeforeForward {
+					h.handleFailoverExhausted(c, failoverErr, true)
+					return
+				}
+				h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+				h.gatewayService.RecordOpenAIAccountSwitch()
+				failedAccountIDs[account.ID] = struct{}{}
+				lastFailoverErr = failoverErr
+				if switchCount >= maxAccountSwitches {
+					h.handleFailoverExhausted(c, failoverErr, false)
+					return
+				}
+				switchCount++
+				reqLog.Warn("openai_files.upstream_failover_switching",
+					zap.Int64("account_id", account.ID),
+					zap.Int("upstream_status", failoverErr.StatusCode),
+					zap.Int("switch_count", switchCount),
+					zap.Int("max_switches", maxAccountSwitches),
+				)
+				continue
+			}
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+			if c.Writer.Size() == writerSizeBeforeForward {
+				h.errorResponse(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
+			}
+			reqLog.Warn("openai_files.forward_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+			return
+		}
+
+		h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, nil)
+		reqLog.Debug("openai_files.file_uploaded",
+			zap.Int64("account_id", account.ID),
+			zap.String("file_id", file.PublicID),
+			zap.Int("switch_count", switchCount),
+		)
+		return
+	}
+}
+
+// FileContent downloads a file's content from the account it lives on.
+// GET /v1/files/:file_id/content
+func (h *OpenAIGatewayHandler) FileContent(c *gin.Context) {
+	bc, ok := h.prepareOpenAIBatch(c, "handler.openai_gateway.file_content")
+	if !ok {
+		return
+	}
+	fileID := strings.TrimSpace(c.Param("file_id"))
+	file, account, ok := h.loadOpenAIBatchObject(c, bc, service.OpenAIBatchObjectFile, fileID)
+	if !ok {
+		return
+	}
+	writerSizeBeforeForward := c.Writer.Size()
+	if err := h.gatewayService.ForwardOpenAIBatchFileContent(c.Request.Context(), c, account, file.ObjectID); err != nil {
+		var failoverErr *service.UpstreamFailoverError
+		if errors.As(err, &failoverErr) {
+			h.handleFailoverExhausted(c, failoverErr, c.Writer.Size() != writerSizeBeforeForward)
+		} else if c.Writer.Size() == writerSizeBeforeForward {
+			h.errorResponse(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
+		}
+		bc.reqLog.Warn("openai_files.file_content_failed",
+			zap.Int64("account_id", account.ID),
+			zap.String("file_id", fileID),
+			zap.Error(err),
+		)
+	}
+}
+
+// FileList lists the files uploaded with the current API key, from the gateway's own records.
+// Upstream listings are never exposed: on shared accounts they would include other users' files.
+// GET /v1/files
+func (h *OpenAIGatewayHandler) FileList(c *gin.Context) {
+	bc, ok := h.prepareOpenAIBatch(c, "handler.openai_gateway.file_list")
+	if !ok {
+		return
+	}
+	filter := service.OpenAIFileListFilter{
+		Purpose: strings.TrimSpace(c.Query("purpose")),
+		Limit:   service.OpenAIFileListDefaultLimit,
+	}
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > service.OpenAIFileListMaxLimit {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "limit must be between 1 and "+strconv.Itoa(service.OpenAIFileListMaxLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+	switch order := strings.TrimSpace(c.Query("order")); order {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "order must be asc or desc")
+		return
+	}
+	if after := strings.TrimSpace(c.Query("after")); after != "" {
+		if !service.ValidOpenAIBatchObjectID(after) {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Invalid after cursor")
+			return
+		}
+		filter.After = after
+	}
+
+	files, hasMore, err := h.gatewayService.ListOpenAIFiles(c.Request.Context(), bc.apiKey.ID, filter)
+	if err != nil {
+		bc.reqLog.Warn("openai_files.list_failed", zap.Error(err))
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "Failed to list files")
+		return
+	}
+	body, err := service.BuildOpenAIFileListResponse(files, hasMore)
+	if err != nil {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "Failed to list files")
+		return
+	}
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+// FileRetrieve returns a file's metadata from the account it lives on.
+// GET /v1/files/:file_id
+func (h *OpenAIGatewayHandler) FileRetrieve(c *gin.Context) {
+	bc, ok := h.prepareOpenAIBatch(c, "handler.openai_gateway.file_retrieve")
+	if !ok {
+		return
+	}
+	file, account, ok := h.loadOpenAIBatchObject(c, bc, service.OpenAIBatchObjectFile, strings.TrimSpace(c.Param("file_id")))
+	if !ok {
+		return
+	}
+	_, _ = h.forwardPinnedOpenAIBatchRequest(c, bc, account, http.MethodGet, service.OpenAIFileEndpoint(file.ObjectID), nil, "", func(respBody []byte) ([]byte, error) {
+		return service.RewriteOpenAIFileID(respBody, file.PublicID)
+	})
+}
+
+// FileDelete deletes a file on the account it lives on, then drops the gateway's file ID mapping.
+// DELETE /v1/files/:file_id
+func (h *OpenAIGatewayHandler) FileDelete(c *gin.Context) {
+	bc, ok := h.prepareOpenAIBatch(c, "handler.openai_gateway.file_delete")
+	if !ok {
+		return
+	}
+	file, account, ok := h.loadOpenAIBatchObject(c, bc, service.OpenAIBatchObjectFile, strings.TrimSpace(c.Param("file_id")))
+	if !ok {
+		return
+	}
+	_, ok = h.forwardPinnedOpenAIBatchRequest(c, bc, account, http.MethodDelete, service.OpenAIFileEndpoint(file.ObjectID), nil, "", func(respBody []byte) ([]byte, error) {
+		return service.RewriteOpenAIFileID(respBody, file.PublicID)
+	})
+	if !ok {
+		return
+	}
+	if err := h.gatewayService.DeleteOpenAIFile(c.Request.Context(), file); err != nil {
+		bc.reqLog.Warn("openai_files.delete_mapping_failed", zap.String("file_id", file.PublicID), zap.Error(err))
+	}
+}
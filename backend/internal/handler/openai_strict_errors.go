@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// openAIStrictErrorTypes OpenAI error.type 的取值集合（"requests"/"tokens" 为限流错误的细分类型）。
+var openAIStrictErrorTypes = map[string]struct{}{
+	"invalid_request_error": {},
+	"authentication_error":  {},
+	"permission_error":      {},
+	"not_found_error":       {},
+	"conflict_error":        {},
+	"rate_limit_error":      {},
+	"insufficient_quota":    {},
+	"server_error":          {},
+	"requests":              {},
+	"tokens":                {},
+}
+
+// openAIStrictError 严格模式下的错误对象，字段顺序与 OpenAI 一致；param/code 缺省时输出 null。
+type openAIStrictError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
+
+// openAIStrictResponsesErrorEvent Responses API 流内 error 事件（type=error，字段平铺）。
+type openAIStrictResponsesErrorEvent struct {
+	Type           string  `json:"type"`
+	Code           *string `json:"code"`
+	Message        string  `json:"message"`
+	Param          *string `json:"param"`
+	SequenceNumber *int64  `json:"sequence_number,omitempty"`
+}
+
+// isOpenAIStrictErrorEndpoint 判断入站端点是否使用 OpenAI 协议。
+func isOpenAIStrictErrorEndpoint(endpoint string) bool {
+	switch endpoint {
+	case EndpointChatCompletions, EndpointEmbeddings, EndpointResponses, EndpointResponsesCompact,
+		EndpointImagesGenerations, EndpointImagesEdits, EndpointVideosGenerations, EndpointVideos:
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeOpenAIStrictErrorType 将内部/Anthropic 风格的错误类型映射为 OpenAI 类型。
+func normalizeOpenAIStrictErrorType(errType string, status int) string {
+	errType = strings.ToLower(strings.TrimSpace(errType))
+	if _, ok := openAIStrictErrorTypes[errType]; ok {
+		return errType
+	}
+	switch errType {
+	case "api_error", "upstream_error", "overloaded_error", "internal_error", "timeout_error":
+		return "server_error"
+	case "bad_request", "invalid_request":
+		return "invalid_request_error"
+	}
+	switch {
+	case status == http.StatusUnauthorized:
+		return "authentication_error"
+	case status == http.StatusPaymentRequired:
+		return "insufficient_quota"
+	case status == http.StatusForbidden:
+		return "permission_error"
+	case status == http.StatusNotFound:
+		return "not_found_error"
+	case status == http.StatusConflict:
+		return "conflict_error"
+	case status == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case status >= http.StatusBadRequest && status < http.StatusInternalServerError:
+		return "invalid_request_error"
+	default:
+		return "server_error"
+	}
+}
+
+// normalizeOpenAIStrictErrorCode 错误码统一为字符串；内部 SCREAMING_CASE 原因码转为 OpenAI 的小写风格，数字码丢弃。
+func normalizeOpenAIStrictErrorCode(v any) *string {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if strings.ToUpper(s) == s {
+		s = strings.ToLower(s)
+	}
+	return &s
+}
+
+func strictStringField(m map[string]any, key string) string {
+	if m == nil {
+		return ""
+	}
+	s, _ := m[key].(string)
+	return strings.TrimSpace(s)
+}
+
+// parseOpenAIStrictError 从任意网关错误体中提取 OpenAI 错误对象，兼容：
+//   - OpenAI：{"error":{"message","type","param","code"}}
+//   - Anthropic：{"type":"error","error":{"type","message"}}
+//   - 中间件：{"code":"INVALID_API_KEY","message"} 与 infraerrors：{"code":401,"message","reason"}
+//   - Responses 流内事件：{"type":"error","code","message","param"}
+//   - 非 JSON 文本：整体作为 message
+func parseOpenAIStrictError(status int, raw []byte) openAIStrictError {
+	var top map[string]any
+	if err := json.Unmarshal(raw, &top); err != nil || top == nil {
+		return openAIStrictError{Message: truncateStrictErrorMessage(status, raw), Type: normalizeOpenAIStrictErrorType("", status)}
+	}
+
+	src := top
+	message := ""
+	switch e := top["error"].(type) {
+	case map[string]any:
+		src = e
+	case string:
+		message = strings.TrimSpace(e)
+	}
+	if message == "" {
+		message = strictStringField(src, "message")
+	}
+	if message == "" {
+		message = strictStringField(top, "message")
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	if message == "" {
+		message = "Unknown error"
+	}
+
+	errType := strictStringField(src, "type")
+	if errType == "error" {
+		// Anthropic / Responses 事件的外层 type=error 不是错误分类
+		errType = ""
+	}
+	code := normalizeOpenAIStrictErrorCode(src["code"])
+	if code == nil {
+		code = normalizeOpenAIStrictErrorCode(src["reason"])
+	}
+	var param *string
+	if p := strictStringField(src, "param"); p != "" {
+		param = &p
+	}
+	return openAIStrictError{
+		Message: message,
+		Type:    normalizeOpenAIStrictErrorType(errType, status),
+		Param:   param,
+		Code:    code,
+	}
+}
+
+// openAIStrictErrorBody 构造严格模式的非流式错误体。
+func openAIStrictErrorBody(status int, raw []byte) []byte {
+	body, _ := json.Marshal(gin.H{"error": parseOpenAIStrictError(status, raw)})
+	return body
+}
+
+// rewriteOpenAIStrictSSEEvent 改写单个 SSE 事件（含结尾空行）中的错误；非错误事件原样返回。
+//   - Chat Completions 等：输出 OpenAI 的 data-only 错误帧 data: {"error":{...}}
+//   - Responses：输出 event: error + {"type":"error","code","message","param"}；response.failed 等终止事件保持不变
+func rewriteOpenAIStrictSSEEvent(event []byte, responses bool) []byte {
+	if !bytes.Contains(event, []byte(`"error"`)) {
+		return event
+	}
+	eventName, dataStr, ok := parseSSEEvent(string(event))
+	if !ok || strings.HasPrefix(eventName, "response.") {
+		return event
+	}
+	data := []byte(dataStr)
+	var top map[string]any
+	if err := json.Unmarshal(data, &top); err != nil || top == nil {
+		return event
+	}
+	isError := eventName == "error" || top["error"] != nil || strictStringField(top, "type") == "error"
+	if !isError {
+		return event
+	}
+
+	strict := parseOpenAIStrictError(0, data)
+	var out []byte
+	if responses {
+		payload := openAIStrictResponsesErrorEvent{
+			Type:    "error",
+			Code:    strict.Code,
+			Message: strict.Message,
+			Param:   strict.Param,
+		}
+		if seq, ok := top["sequence_number"].(float64); ok {
+			n := int64(seq)
+			payload.SequenceNumber = &n
+		}
+		body, _ := json.Marshal(payload)
+		out = append(out, "event: error\ndata: "...)
+		out = append(out, body...)
+	} else {
+		body, _ := json.Marshal(gin.H{"error": strict})
+		out = append(out, "data: "...)
+		out = append(out, body...)
+	}
+	return append(out, sseEventSeparator...)
+}
+
+// openAIStrictErrorSchema OpenAI 协议的严格错误 schema。
+type openAIStrictErrorSchema struct{}
+
+func (openAIStrictErrorSchema) matches(endpoint string) bool {
+	return isOpenAIStrictErrorEndpoint(endpoint)
+}
+
+func (openAIStrictErrorSchema) errorBody(status int, raw []byte) []byte {
+	return openAIStrictErrorBody(status, raw)
+}
+
+func (openAIStrictErrorSchema) sseEvent(event []byte, endpoint string) []byte {
+	return rewriteOpenAIStrictSSEEvent(event, endpoint == EndpointResponses || endpoint == EndpointResponsesCompact)
+}
+
+// OpenAIStrictErrorMiddleware OpenAI 兼容端点的严格错误体模式，需注册在 OpsErrorLoggerMiddleware 之后、
+// ResponseContentGuardMiddleware 之前，使守卫改写出的错误同样被规整、ops 采集到客户端实际收到的错误体。
+// gateway.openai_strict_errors=false 时为空操作。
+func OpenAIStrictErrorMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return strictErrorMiddleware(cfg != nil && cfg.Gateway.OpenAIStrictErrors, openAIStrictErrorSchema{})
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func serveOpenAIStrictErrors(t *testing.T, enabled bool, path string, h gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAIStrictErrors = enabled
+	cfg.Gateway.ResponseContentGuard = true
+	r := gin.New()
+	r.Use(OpenAIStrictErrorMiddleware(cfg), ResponseContentGuardMiddleware(cfg), InboundEndpointMiddleware())
+	r.POST(path, h)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	return rec
+}
+
+// requireOpenAIErrorObject 断言错误对象恰好包含 message/type/param/code，且类型符合 OpenAI schema。
+func requireOpenAIErrorObject(t *testing.T, obj map[string]any) {
+	t.Helper()
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	require.Equal(t, []string{"code", "message", "param", "type"}, keys)
+	require.IsType(t, "", obj["message"])
+	require.NotEmpty(t, obj["message"])
+	require.IsType(t, "", obj["type"])
+	_, known := openAIStrictErrorTypes[obj["type"].(string)]
+	require.True(t, known, "unexpected error type %q", obj["type"])
+	for _, key := range []string{"param", "code"} {
+		if obj[key] != nil {
+			require.IsType(t, "", obj[key], key)
+		}
+	}
+}
+
+func requireOpenAIErrorBody(t *testing.T, body []byte) map[string]any {
+	t.Helper()
+	var top map[string]any
+	require.NoError(t, json.Unmarshal(body, &top), string(body))
+	require.Len(t, top, 1, string(body))
+	obj, ok := top["error"].(map[string]any)
+	require.True(t, ok, string(body))
+	requireOpenAIErrorObject(t, obj)
+	return obj
+}
+
+func TestOpenAIStrictErrors_JSONErrorShapesContract(t *testing.T) {
+	cases := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		status   int
+		wantType string
+		wantCode any
+	}{
+		{
+			name: "gateway_error_response",
+			handler: func(c *gin.Context) {
+				(&OpenAIGatewayHandler{}).errorResponse(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
+			},
+			status:   http.StatusBadGateway,
+			wantType: "server_error",
+		},
+		{
+			name: "api_key_middleware",
+			handler: func(c *gin.Context) {
+				middleware2.AbortWithError(c, http.StatusUnauthorized, "INVALID_API_KEY", "Invalid API key")
+			},
+			status:   http.StatusUnauthorized,
+			wantType: "authentication_error",
+			wantCode: "invalid_api_key",
+		},
+		{
+			name: "anthropic_envelope",
+			handler: func(c *gin.Context) {
+				middleware2.AnthropicErrorWriter(c, http.StatusForbidden, "API key is not assigned to any group")
+			},
+			status:   http.StatusForbidden,
+			wantType: "permission_error",
+		},
+		{
+			name: "upstream_openai_body_keeps_fields",
+			handler: func(c *gin.Context) {
+				c.Data(http.StatusBadRequest, "application/json", []byte(`{"error":{"message":"bad temperature","type":"invalid_request_error","param":"temperature","code":"invalid_value","extra":1}}`))
+			},
+			status:   http.StatusBadRequest,
+			wantType: "invalid_request_error",
+			wantCode: "invalid_value",
+		},
+		{
+			name: "invalid_upstream_json_rewritten_by_guard",
+			handler: func(c *gin.Context) {
+				c.Data(http.StatusTooManyRequests, "application/json", []byte(`{"error":`))
+			},
+			status:   http.StatusTooManyRequests,
+			wantType: "server_error",
+			wantCode: service.UpstreamErrorCodeInvalidResponse,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serveOpenAIStrictErrors(t, true, "/v1/chat/completions", tc.handler)
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+			obj := requireOpenAIErrorBody(t, rec.Body.Bytes())
+			require.Equal(t, tc.wantType, obj["type"])
+			require.Equal(t, tc.wantCode, obj["code"])
+		})
+	}
+}
+
+func TestOpenAIStrictErrors_PreservesParam(t *testing.T) {
+	rec := serveOpenAIStrictErrors(t, true, "/v1/embeddings", func(c *gin.Context) {
+		c.Data(http.StatusBadRequest, "application/json", []byte(`{"error":{"message":"bad input","type":"invalid_request_error","param":"input","code":null}}`))
+	})
+	obj := requireOpenAIErrorBody(t, rec.Body.Bytes())
+	require.Equal(t, "input", obj["param"])
+	require.Nil(t, obj["code"])
+}
+
+func TestOpenAIStrictErrors_ChatCompletionsStreamErrorContract(t *testing.T) {
+	rec := serveOpenAIStrictErrors(t, true, "/v1/chat/completions", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: {\"id\":\"c1\",\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n")
+		c.Writer.Flush()
+		(&OpenAIGatewayHandler{}).handleStreamingAwareError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed", true)
+	})
+
+	events := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+	require.Len(t, events, 2)
+	require.Equal(t, "data: {\"id\":\"c1\",\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}", events[0], "non-error events pass through untouched")
+	require.True(t, strings.HasPrefix(events[1], "data: "), "OpenAI streams errors as data-only frames: %q", events[1])
+	obj := requireOpenAIErrorBody(t, []byte(strings.TrimPrefix(events[1], "data: ")))
+	require.Equal(t, "server_error", obj["type"])
+	require.Equal(t, "Upstream request failed", obj["message"])
+}
+
+func TestOpenAIStrictErrors_ResponsesStreamErrorContract(t *testing.T) {
+	rec := serveOpenAIStrictErrors(t, true, "/v1/responses", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("event: error\ndata: {\"error\":{\"type\":\"rate_limit_error\",\"message\":\"slow down\"},\"sequence_number\":3}\n\n")
+		_, _ = c.Writer.WriteString("event: response.failed\ndata: {\"type\":\"response.failed\",\"response\":{\"error\":{\"code\":\"server_error\",\"message\":\"x\"}}}")
+	})
+
+	events := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+	require.Len(t, events, 2)
+	require.True(t, strings.HasPrefix(events[0], "event: error\ndata: "))
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[0], "event: error\ndata: ")), &payload))
+	require.Equal(t, map[string]any{
+		"type":            "error",
+		"code":            nil,
+		"message":         "slow down",
+		"param":           nil,
+		"sequence_number": float64(3),
+	}, payload)
+	require.Equal(t, "event: response.failed\ndata: {\"type\":\"response.failed\",\"response\":{\"error\":{\"code\":\"server_error\",\"message\":\"x\"}}}", events[1], "terminal events are kept as-is")
+}
+
+func TestOpenAIStrictErrors_ScopeAndDisabled(t *testing.T) {
+	anthropic := func(c *gin.Context) {
+		middleware2.AnthropicErrorWriter(c, http.StatusBadRequest, "bad")
+	}
+	rec := serveOpenAIStrictErrors(t, true, "/v1/messages", anthropic)
+	require.JSONEq(t, `{"type":"error","error":{"type":"permission_error","message":"bad"}}`, rec.Body.String(), "Anthropic endpoints keep their own schema")
+
+	rec = serveOpenAIStrictErrors(t, false, "/v1/chat/completions", func(c *gin.Context) {
+		middleware2.AbortWithError(c, http.StatusUnauthorized, "INVALID_API_KEY", "Invalid API key")
+	})
+	require.JSONEq(t, `{"code":"INVALID_API_KEY","message":"Invalid API key"}`, rec.Body.String())
+
+	rec = serveOpenAIStrictErrors(t, true, "/v1/chat/completions", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": "c1", "error": nil})
+	})
+	require.JSONEq(t, `{"id":"c1","error":null}`, rec.Body.String(), "successful responses are never rewritten")
+}
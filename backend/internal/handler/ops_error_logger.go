@@ -399,6 +399,9 @@ func setOpsRequestContext(c *gin.Context, model string, stream bool) {
 		ctx := context.WithValue(c.Request.Context(), ctxkey.Model, model)
 		c.Request = c.Request.WithContext(ctx)
 	}
+	if model != "" {
+		setRequestClass(c, stream)
+	}
 }
 
 // setOpsEndpointContext stores upstream model and request type for ops error logging.
@@ -610,6 +613,10 @@ func (w *opsCaptureWriter) WriteString(s string) (int, error) {
 // - Streaming errors after the response has started (SSE) may still need explicit logging.
 func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 在途请求计数供资源看门狗判断请求堆积
+		defer ops.TrackInFlightRequest()()
+		start := time.Now()
+
 		originalWriter := c.Writer
 		w := acquireOpsCaptureWriter(originalWriter)
 		defer func() {
@@ -621,11 +628,15 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			releaseOpsCaptureWriter(w)
 		}()
 		c.Writer = w
-		c.Next()
+		if recovered := runOpsRecoverable(c); recovered != nil {
+			handleOpsRecoveredPanic(c, recovered)
+		}
+		recordGatewayMetrics(c, time.Since(start))
 
 		if ops == nil {
 			return
 		}
+		logOpsSlowRequest(c, ops, time.Since(start).Milliseconds())
 		if !ops.IsMonitoringEnabled(c.Request.Context()) {
 			return
 		}
@@ -634,6 +645,12 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			return
 		}
 
+		// panic（本中间件恢复或 handler 自行恢复并标记）单独落库，附带脱敏调用栈。
+		if p, ok := service.GetOpsPanic(c); ok {
+			logOpsPanic(c, ops, p)
+			return
+		}
+
 		status := c.Writer.Status()
 		if status < 400 {
 			// Even when the client request succeeds, we still want to persist upstream error attempts
@@ -850,6 +867,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 				CreatedAt: time.Now(),
 			}
 			applyOpsLatencyFieldsFromContext(c, entry)
+			applyOpsErrorCodeFromContext(c, entry)
 
 			if apiKey != nil {
 				entry.APIKeyID = &apiKey.ID
@@ -987,6 +1005,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			CreatedAt: time.Now(),
 		}
 		applyOpsLatencyFieldsFromContext(c, entry)
+		applyOpsErrorCodeFromContext(c, entry)
 
 		// Capture upstream error context set by gateway services (if present).
 		// This does NOT affect the client response; it enriches Ops troubleshooting data.
@@ -1194,6 +1213,7 @@ func logOpsStreamError(c *gin.Context, ops *service.OpsService, wireStatus int)
 		CreatedAt: time.Now(),
 	}
 	applyOpsLatencyFieldsFromContext(c, entry)
+	applyOpsErrorCodeFromContext(c, entry)
 
 	if apiKey != nil {
 		entry.APIKeyID = &apiKey.ID
@@ -1216,6 +1236,77 @@ func logOpsStreamError(c *gin.Context, ops *service.OpsService, wireStatus int)
 	enqueueOpsErrorLog(ops, entry)
 }
 
+// logOpsPanic 记录一次请求内恢复的 panic。流已开始时 wire 状态码可能仍为 200，
+// 分级统一按 500 处理；调用栈写入 error_body 供错误详情查看。
+func logOpsPanic(c *gin.Context, ops *service.OpsService, p service.OpsPanic) {
+	apiKey := getOpsAPIKey(c)
+	clientRequestID, _ := c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+
+	model, _ := c.Get(opsModelKey)
+	var modelName string
+	if s, ok := model.(string); ok {
+		modelName = s
+	}
+	streamV, _ := c.Get(opsStreamKey)
+	stream, _ := streamV.(bool)
+	accountIDV, _ := c.Get(opsAccountIDKey)
+	var accountID *int64
+	if v, ok := accountIDV.(int64); ok && v > 0 {
+		accountID = &v
+	}
+
+	platform := resolveOpsPlatform(apiKey, guessPlatformFromPath(c.Request.URL.Path))
+	requestID := c.Writer.Header().Get("X-Request-Id")
+
+	entry := &service.OpsInsertErrorLogInput{
+		RequestID:       requestID,
+		ClientRequestID: clientRequestID,
+
+		AccountID:        accountID,
+		Platform:         platform,
+		Model:            modelName,
+		RequestPath:      c.Request.URL.Path,
+		Stream:           stream,
+		InboundEndpoint:  GetInboundEndpoint(c),
+		UpstreamEndpoint: GetUpstreamEndpoint(c, platform),
+		RequestedModel:   modelName,
+		UserAgent:        c.GetHeader("User-Agent"),
+
+		ErrorPhase:    "internal",
+		ErrorType:     "api_error",
+		Severity:      classifyOpsSeverity("api_error", http.StatusInternalServerError),
+		StatusCode:    c.Writer.Status(),
+		IsCountTokens: isCountTokensRequest(c),
+
+		ErrorMessage: truncateString("panic: "+p.Message, 2048),
+		ErrorBody:    p.Stack,
+		ErrorSource:  "gateway",
+		ErrorOwner:   "platform",
+
+		CreatedAt: time.Now(),
+	}
+	applyOpsLatencyFieldsFromContext(c, entry)
+
+	if apiKey != nil {
+		entry.APIKeyID = &apiKey.ID
+		entry.APIKeyPrefix = keyPrefix(apiKey.Key, 8)
+		if apiKey.User != nil {
+			entry.UserID = &apiKey.User.ID
+		}
+		if apiKey.GroupID != nil {
+			entry.GroupID = apiKey.GroupID
+		}
+		if apiKey.Group != nil && apiKey.Group.Platform != "" {
+			entry.Platform = apiKey.Group.Platform
+		}
+	}
+	if clientIP := strings.TrimSpace(ip.GetClientIP(c)); clientIP != "" {
+		entry.ClientIP = &clientIP
+	}
+
+	enqueueOpsErrorLog(ops, entry)
+}
+
 // isCountTokensRequest checks if the request is a count_tokens request
 func isCountTokensRequest(c *gin.Context) bool {
 	if c == nil || c.Request == nil || c.Request.URL == nil {
@@ -1224,6 +1315,17 @@ func isCountTokensRequest(c *gin.Context) bool {
 	return strings.Contains(c.Request.URL.Path, "/count_tokens")
 }
 
+func applyOpsErrorCodeFromContext(c *gin.Context, entry *service.OpsInsertErrorLogInput) {
+	if c == nil || entry == nil {
+		return
+	}
+	if v, ok := c.Get(service.OpsErrorCodeKey); ok {
+		if code, ok := v.(string); ok && strings.TrimSpace(code) != "" {
+			entry.ErrorCode = strings.TrimSpace(code)
+		}
+	}
+}
+
 func applyOpsLatencyFieldsFromContext(c *gin.Context, entry *service.OpsInsertErrorLogInput) {
 	if c == nil || entry == nil {
 		return
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const opsPanicClientMessage = "Internal server error"
+
+// opsRecoveredPanic 网关处理链中恢复的 panic 及其现场调用栈。
+type opsRecoveredPanic struct {
+	value any
+	stack []byte
+}
+
+// runOpsRecoverable 执行后续处理链并就地恢复 panic，使单个请求的 panic
+// 只影响该请求：同一进程内的其它流不受影响，且 ops 中间件仍能完成采集。
+func runOpsRecoverable(c *gin.Context) (recovered *opsRecoveredPanic) {
+	defer func() {
+		if r := recover(); r != nil {
+			recovered = &opsRecoveredPanic{value: r, stack: debug.Stack()}
+		}
+	}()
+	c.Next()
+	return nil
+}
+
+// isOpsAbortPanic 识别不应按服务端错误处理的 panic：http.ErrAbortHandler 用于主动中止响应，
+// 客户端断开（broken pipe / connection reset）交由外层 Recovery 按既有方式处理。
+func isOpsAbortPanic(v any) bool {
+	err, ok := v.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(err, http.ErrAbortHandler) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// handleOpsRecoveredPanic 记录 panic，并在响应尚未开始时按入站协议返回结构化 500。
+// 已开始的流只能中断，不再追加错误体。
+func handleOpsRecoveredPanic(c *gin.Context, p *opsRecoveredPanic) {
+	if isOpsAbortPanic(p.value) {
+		panic(p.value)
+	}
+	service.MarkOpsPanic(c, p.value, p.stack)
+
+	started := c.Writer.Written() || service.IsResponseCommitted(c)
+	requestLogger(c, "handler.ops_panic_recovery").Error(
+		"gateway.panic_recovered",
+		zap.Bool("response_started", started),
+		zap.Any("panic", p.value),
+		zap.ByteString("stack", p.stack),
+	)
+	if !started {
+		writeOpsPanicResponse(c)
+	}
+	c.Abort()
+}
+
+// writeOpsPanicResponse 按入站端点选择 Anthropic / Gemini / OpenAI 错误格式。
+func writeOpsPanicResponse(c *gin.Context) {
+	endpoint := GetInboundEndpoint(c)
+	switch {
+	case strings.HasPrefix(endpoint, EndpointMessages):
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"type":  "error",
+			"error": gin.H{"type": "api_error", "message": opsPanicClientMessage},
+		})
+	case strings.HasPrefix(endpoint, EndpointGeminiModels):
+		middleware2.GoogleErrorWriter(c, http.StatusInternalServerError, opsPanicClientMessage)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"type": "server_error", "message": opsPanicClientMessage},
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// newOpsPanicTestRouter 返回的 marked 记录最近一次请求上标记的 panic。
+func newOpsPanicTestRouter(handler gin.HandlerFunc) (*gin.Engine, *service.OpsPanic) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	marked := &service.OpsPanic{}
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		if p, ok := service.GetOpsPanic(c); ok {
+			*marked = p
+		}
+	})
+	r.Use(OpsErrorLoggerMiddleware(nil))
+	r.POST("/v1/messages", handler)
+	r.POST("/v1/chat/completions", handler)
+	return r, marked
+}
+
+func TestOpsErrorLoggerMiddleware_RecoversPanicWithProtocolError(t *testing.T) {
+	r, marked := newOpsPanicTestRouter(func(c *gin.Context) {
+		panic("boom sk-ant-REDACTED")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{"type":"error","error":{"type":"api_error","message":"Internal server error"}}`, w.Body.String())
+	require.Contains(t, marked.Message, "boom")
+	require.NotContains(t, marked.Message, "sk-ant-REDACTED")
+	require.Contains(t, marked.Stack, "ops_panic_recovery_test.go")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{"error":{"type":"server_error","message":"Internal server error"}}`, w.Body.String())
+}
+
+func TestOpsErrorLoggerMiddleware_PanicAfterStreamStartKeepsResponse(t *testing.T) {
+	r, marked := newOpsPanicTestRouter(func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: {}\n\n")
+		panic("mid-stream")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "data: {}\n\n", w.Body.String())
+	require.Equal(t, "mid-stream", marked.Message)
+}
+
+func TestOpsErrorLoggerMiddleware_RepanicsAbortHandler(t *testing.T) {
+	r, _ := newOpsPanicTestRouter(func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	})
+}
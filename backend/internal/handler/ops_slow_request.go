@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	opsProxyIDKey   = "ops_proxy_id"
+	opsProxyNameKey = "ops_proxy_name"
+)
+
+// setOpsSelectedProxy 记录所选账号使用的代理，供慢请求日志定位代理维度的系统性变慢。
+func setOpsSelectedProxy(c *gin.Context, account *service.Account) {
+	if c == nil || account == nil {
+		return
+	}
+	if account.Proxy != nil {
+		c.Set(opsProxyIDKey, account.Proxy.ID)
+		c.Set(opsProxyNameKey, account.Proxy.Name)
+		return
+	}
+	if account.ProxyID != nil {
+		c.Set(opsProxyIDKey, *account.ProxyID)
+	}
+}
+
+// logOpsSlowRequest 在请求总耗时或首字耗时超过所在路由阈值时输出 warn 日志；
+// warn 级别会被 ops 系统日志索引，无需开启全链路追踪即可按账号 / 模型 / 代理聚合排查。
+func logOpsSlowRequest(c *gin.Context, ops *service.OpsService, totalMs int64) {
+	if c == nil || c.Request == nil || c.Request.URL == nil {
+		return
+	}
+	thresholds, ok := ops.SlowRequestThresholds(c.Request.URL.Path)
+	if !ok {
+		return
+	}
+
+	var exceeded []string
+	if thresholds.TotalMs > 0 && totalMs > thresholds.TotalMs {
+		exceeded = append(exceeded, "total")
+	}
+	ttftMs := getContextLatencyMs(c, service.OpsTimeToFirstTokenMsKey)
+	if thresholds.TTFTMs > 0 && ttftMs != nil && *ttftMs > thresholds.TTFTMs {
+		exceeded = append(exceeded, "ttft")
+	}
+	if len(exceeded) == 0 {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("exceeded", strings.Join(exceeded, ",")),
+		zap.String("method", c.Request.Method),
+		zap.String("path", c.Request.URL.Path),
+		zap.String("route_prefix", thresholds.RoutePrefix),
+		zap.Int("status_code", c.Writer.Status()),
+		zap.Int64("total_ms", totalMs),
+		zap.Int64("total_threshold_ms", thresholds.TotalMs),
+		zap.Int64p("ttft_ms", ttftMs),
+		zap.Int64("ttft_threshold_ms", thresholds.TTFTMs),
+		zap.Int64p("upstream_latency_ms", getContextLatencyMs(c, service.OpsUpstreamLatencyMsKey)),
+	}
+	if v, ok := c.Get(opsStreamKey); ok {
+		if stream, ok := v.(bool); ok {
+			fields = append(fields, zap.Bool("stream", stream))
+		}
+	}
+	if model := strings.TrimSpace(c.GetString(opsModelKey)); model != "" {
+		fields = append(fields, zap.String("model", model))
+	}
+	if accountID := c.GetInt64(opsAccountIDKey); accountID > 0 {
+		fields = append(fields, zap.Int64("account_id", accountID))
+	}
+	apiKey := getOpsAPIKey(c)
+	if apiKey != nil {
+		fields = append(fields, zap.Int64("api_key_id", apiKey.ID))
+	}
+	platform, _ := c.Request.Context().Value(ctxkey.Platform).(string)
+	if platform == "" {
+		platform = resolveOpsPlatform(apiKey, guessPlatformFromPath(c.Request.URL.Path))
+	}
+	if platform != "" {
+		fields = append(fields, zap.String("platform", platform))
+	}
+	if proxyID := c.GetInt64(opsProxyIDKey); proxyID > 0 {
+		fields = append(fields, zap.Int64("proxy_id", proxyID))
+		if name := c.GetString(opsProxyNameKey); name != "" {
+			fields = append(fields, zap.String("proxy_name", name))
+		}
+	}
+
+	requestLogger(c, "gateway.slow_request").Warn("gateway.slow_request", fields...)
+}
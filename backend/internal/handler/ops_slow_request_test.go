@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newSlowRequestTestOpsService() *service.OpsService {
+	cfg := &config.Config{}
+	cfg.Ops.Enabled = true
+	cfg.Ops.SlowRequestLog = config.OpsSlowRequestLogConfig{
+		Enabled:          true,
+		TotalThresholdMs: 1000,
+		TTFTThresholdMs:  500,
+		Routes: []config.OpsSlowRequestRouteConfig{
+			{PathPrefix: "/v1/images", TotalThresholdMs: 0, TTFTThresholdMs: 0},
+		},
+	}
+	return service.NewOpsService(nil, nil, cfg, nil, nil, nil, nil, nil, nil, nil, nil)
+}
+
+func newSlowRequestTestContext(path string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, path, nil)
+	c.Status(http.StatusOK)
+	return c
+}
+
+func TestLogOpsSlowRequest_TTFTExceededIncludesContext(t *testing.T) {
+	logSink, restore := captureHandlerStructuredLog(t)
+	defer restore()
+
+	c := newSlowRequestTestContext("/v1/messages")
+	setOpsRequestContext(c, "claude-sonnet-4-5", true)
+	setOpsSelectedAccount(c, 42, service.PlatformAnthropic)
+	proxyID := int64(7)
+	setOpsSelectedProxy(c, &service.Account{ProxyID: &proxyID})
+	service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, 800)
+
+	logOpsSlowRequest(c, newSlowRequestTestOpsService(), 900)
+
+	require.True(t, logSink.ContainsMessageAtLevel("gateway.slow_request", "warn"))
+	require.True(t, logSink.ContainsFieldValue("exceeded", "ttft"))
+	require.False(t, logSink.ContainsFieldValue("exceeded", "total"))
+	require.True(t, logSink.ContainsFieldValue("model", "claude-sonnet-4-5"))
+	require.True(t, logSink.ContainsFieldValue("account_id", "42"))
+	require.True(t, logSink.ContainsFieldValue("platform", service.PlatformAnthropic))
+	require.True(t, logSink.ContainsFieldValue("proxy_id", "7"))
+}
+
+func TestLogOpsSlowRequest_BelowThresholdOrDisabledRoute(t *testing.T) {
+	logSink, restore := captureHandlerStructuredLog(t)
+	defer restore()
+	ops := newSlowRequestTestOpsService()
+
+	logOpsSlowRequest(newSlowRequestTestContext("/v1/messages"), ops, 999)
+	logOpsSlowRequest(newSlowRequestTestContext("/v1/images/generations"), ops, 60000)
+
+	require.False(t, logSink.ContainsMessageAtLevel("gateway.slow_request", "warn"))
+}
@@ -0,0 +1,831 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// outputPostprocessMaxJSONBytes JSON 响应缓冲上限；超过后放弃后处理直接透传。
+const outputPostprocessMaxJSONBytes = 32 << 20
+
+type outputPostprocessFormat int
+
+const (
+	outputFormatAnthropic outputPostprocessFormat = iota + 1
+	outputFormatChatCompletions
+	outputFormatResponses
+	outputFormatGemini
+)
+
+// responsesAttributionItemID 署名作为独立 message 输出项追加到 Responses 响应末尾时使用的 item id。
+const responsesAttributionItemID = "msg_output_attribution"
+
+type outputPostprocessMode int
+
+const (
+	outputPostprocessUndecided outputPostprocessMode = iota
+	outputPostprocessPassthrough
+	outputPostprocessJSON
+	outputPostprocessSSE
+)
+
+// outputPostprocessWriter 按分组规则改写最终输出文本：
+//   - 非流式 JSON（2xx）先缓冲，结束时改写 text/content 字段并追加署名；
+//   - SSE 按事件边界解析，文本增量经 OutputPostprocessStream 处理后立即写出，
+//     仅保留少量尾部字符用于跨增量匹配，不影响逐字渲染；
+//   - 错误响应与其他内容类型原样透传。
+type outputPostprocessWriter struct {
+	gin.ResponseWriter
+	p        *service.OutputPostprocessor
+	format   outputPostprocessFormat
+	mode     outputPostprocessMode
+	buf      bytes.Buffer
+	accepted int
+
+	// Anthropic SSE：按 content block index 跟踪文本块
+	blocks     map[int64]*service.OutputPostprocessStream
+	maxIndex   int64
+	attributed bool
+
+	// Chat Completions SSE：按 choice index 跟踪，lastChunk 用于补发剩余文本
+	choices   map[int64]*service.OutputPostprocessStream
+	lastChunk string
+
+	// Responses SSE：按 item_id + content_index 跟踪 output_text；插入事件后顺延后续 sequence_number
+	parts          map[string]*responsesTextPart
+	finalTexts     map[string]string
+	maxOutputIndex int64
+	seqOffset      int64
+
+	// Gemini SSE：按 candidate index 跟踪
+	candidates map[int64]*geminiCandidateState
+}
+
+type responsesTextPart struct {
+	stream  *service.OutputPostprocessStream
+	emitted strings.Builder
+}
+
+type geminiCandidateState struct {
+	stream       *service.OutputPostprocessStream
+	functionCall bool
+}
+
+func newOutputPostprocessWriter(w gin.ResponseWriter, p *service.OutputPostprocessor, format outputPostprocessFormat) *outputPostprocessWriter {
+	return &outputPostprocessWriter{
+		ResponseWriter: w,
+		p:              p,
+		format:         format,
+		blocks:         make(map[int64]*service.OutputPostprocessStream),
+		maxIndex:       -1,
+		choices:        make(map[int64]*service.OutputPostprocessStream),
+		parts:          make(map[string]*responsesTextPart),
+		finalTexts:     make(map[string]string),
+		maxOutputIndex: -1,
+		candidates:     make(map[int64]*geminiCandidateState),
+	}
+}
+
+func (w *outputPostprocessWriter) decide() {
+	if w.mode != outputPostprocessUndecided {
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(w.ResponseWriter.Header().Get("Content-Type")))
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		w.mode = outputPostprocessSSE
+	case strings.HasPrefix(contentType, "application/json") && w.ResponseWriter.Status() < http.StatusBadRequest:
+		w.mode = outputPostprocessJSON
+	default:
+		w.mode = outputPostprocessPassthrough
+	}
+}
+
+func (w *outputPostprocessWriter) Write(b []byte) (int, error) {
+	w.decide()
+	w.accepted += len(b)
+	switch w.mode {
+	case outputPostprocessJSON:
+		if w.buf.Len()+len(b) > outputPostprocessMaxJSONBytes {
+			if err := w.writeBuffered(); err != nil {
+				return 0, err
+			}
+			return w.ResponseWriter.Write(b)
+		}
+		return w.buf.Write(b)
+	case outputPostprocessSSE:
+		w.buf.Write(b)
+		if err := w.drainSSE(false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	default:
+		return w.ResponseWriter.Write(b)
+	}
+}
+
+func (w *outputPostprocessWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *outputPostprocessWriter) WriteHeaderNow() {
+	w.decide()
+	if w.mode == outputPostprocessJSON {
+		// 改写后长度会变化，JSON 响应延迟到 finish 时再提交
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Written / Size 以已接收字节计算，保证 handler 依据写出量判断能否 failover 时不受缓冲影响。
+func (w *outputPostprocessWriter) Written() bool {
+	return w.accepted > 0 || w.ResponseWriter.Written()
+}
+
+func (w *outputPostprocessWriter) Size() int {
+	if w.accepted > 0 {
+		return w.accepted
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *outputPostprocessWriter) Flush() {
+	w.decide()
+	if w.mode == outputPostprocessJSON {
+		if len(bytes.TrimSpace(w.buf.Bytes())) > 0 {
+			// 已有正文的 JSON 被主动 flush 时无法事后改写，退化为透传
+			_ = w.writeBuffered()
+		} else if w.buf.Len() > 0 {
+			// 非流式 keepalive 的前导空白可以提前写出（JSON 允许前导空白）
+			_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+			w.buf.Reset()
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *outputPostprocessWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mode = outputPostprocessPassthrough
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *outputPostprocessWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.CloseNotify()
+}
+
+// writeBuffered 原样写出已缓冲的字节并切换为透传模式。
+func (w *outputPostprocessWriter) writeBuffered() error {
+	w.mode = outputPostprocessPassthrough
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	data := w.buf.Bytes()
+	w.buf = bytes.Buffer{}
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
+
+// finish 在 handler 返回后写出改写后的 JSON，或处理 SSE 末尾未以空行结束的事件。
+func (w *outputPostprocessWriter) finish() {
+	switch w.mode {
+	case outputPostprocessJSON:
+		data := w.buf.Bytes()
+		w.buf = bytes.Buffer{}
+		w.mode = outputPostprocessPassthrough
+		if len(bytes.TrimSpace(data)) == 0 {
+			w.ResponseWriter.WriteHeaderNow()
+			if len(data) > 0 {
+				_, _ = w.ResponseWriter.Write(data)
+			}
+			return
+		}
+		out := w.rewriteJSON(data)
+		w.ResponseWriter.Header().Del("Content-Length")
+		_, _ = w.ResponseWriter.Write(out)
+	case outputPostprocessSSE:
+		_ = w.drainSSE(true)
+		if w.format == outputFormatGemini {
+			// Gemini 流没有结束标记，上游未发送 finishReason 时补发保留中的文本
+			if rest := w.flushGeminiCandidates(); rest != "" {
+				_, _ = w.ResponseWriter.Write([]byte(rest))
+			}
+		}
+	}
+}
+
+// drainSSE 处理缓冲区中完整的 SSE 事件；final=true 时把剩余字节视为最后一个事件。
+func (w *outputPostprocessWriter) drainSSE(final bool) error {
+	for {
+		data := w.buf.Bytes()
+		end := bytes.Index(data, []byte("\n\n"))
+		if end < 0 {
+			if !final || len(data) == 0 {
+				return nil
+			}
+			end = len(data)
+		} else {
+			end += 2
+		}
+		event := string(data[:end])
+		w.buf.Next(end)
+		if out := w.rewriteSSEEvent(event); out != "" {
+			if _, err := w.ResponseWriter.Write([]byte(out)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// rewriteSSEEvent 改写单个 SSE 事件，返回需要写出的内容（可能包含补发的事件，空串表示丢弃）。
+func (w *outputPostprocessWriter) rewriteSSEEvent(event string) string {
+	eventName, payload, ok := parseSSEEvent(event)
+	if !ok {
+		return event
+	}
+	switch w.format {
+	case outputFormatAnthropic:
+		return w.rewriteAnthropicEvent(event, eventName, payload)
+	case outputFormatChatCompletions:
+		return w.rewriteChatCompletionsEvent(event, payload)
+	case outputFormatResponses:
+		return w.rewriteResponsesEvent(event, eventName, payload)
+	case outputFormatGemini:
+		return w.rewriteGeminiEvent(event, payload)
+	}
+	return event
+}
+
+func (w *outputPostprocessWriter) rewriteAnthropicEvent(event, eventName, payload string) string {
+	if !gjson.Valid(payload) {
+		return event
+	}
+	parsed := gjson.Parse(payload)
+	index := parsed.Get("index").Int()
+	switch parsed.Get("type").String() {
+	case "content_block_start":
+		w.maxIndex = max(w.maxIndex, index)
+		if parsed.Get("content_block.type").String() == "text" {
+			w.blocks[index] = w.p.NewStream()
+		}
+	case "content_block_delta":
+		stream := w.blocks[index]
+		if stream == nil || parsed.Get("delta.type").String() != "text_delta" {
+			return event
+		}
+		text := stream.Push(parsed.Get("delta.text").String())
+		if text == "" {
+			return ""
+		}
+		updated, err := sjson.Set(payload, "delta.text", text)
+		if err != nil {
+			return event
+		}
+		return formatSSEEvent(eventName, updated)
+	case "content_block_stop":
+		stream := w.blocks[index]
+		if stream == nil {
+			return event
+		}
+		delete(w.blocks, index)
+		if rest := stream.Flush(); rest != "" {
+			return anthropicTextDeltaEvent(index, rest) + event
+		}
+	case "message_delta":
+		stopReason := parsed.Get("delta.stop_reason").String()
+		attribution := w.p.Attribution()
+		if attribution == "" || w.attributed || stopReason == "" || stopReason == "tool_use" {
+			return event
+		}
+		w.attributed = true
+		index := w.maxIndex + 1
+		w.maxIndex = index
+		start, _ := json.Marshal(gin.H{"type": "content_block_start", "index": index, "content_block": gin.H{"type": "text", "text": ""}})
+		stop, _ := json.Marshal(gin.H{"type": "content_block_stop", "index": index})
+		return formatSSEEvent("content_block_start", string(start)) +
+			anthropicTextDeltaEvent(index, attribution) +
+			formatSSEEvent("content_block_stop", string(stop)) +
+			event
+	}
+	return event
+}
+
+func (w *outputPostprocessWriter) rewriteChatCompletionsEvent(event, payload string) string {
+	if strings.TrimSpace(payload) == "[DONE]" {
+		return w.flushChatChoices() + event
+	}
+	if !gjson.Valid(payload) {
+		return event
+	}
+	choices := gjson.Get(payload, "choices")
+	if !choices.IsArray() || len(choices.Array()) == 0 {
+		return event
+	}
+	w.lastChunk = payload
+	updated := payload
+	changed := false
+	choices.ForEach(func(i, choice gjson.Result) bool {
+		index := choice.Get("index").Int()
+		stream := w.choices[index]
+		if stream == nil {
+			stream = w.p.NewStream()
+			w.choices[index] = stream
+		}
+		content := choice.Get("delta.content")
+		text := ""
+		if content.Type == gjson.String {
+			text = stream.Push(content.String())
+		}
+		finishReason := choice.Get("finish_reason").String()
+		if finishReason != "" {
+			text += stream.Flush()
+			delete(w.choices, index)
+			if attribution := w.p.Attribution(); attribution != "" && !isChatToolFinishReason(finishReason) {
+				text += "\n\n" + attribution
+			}
+		}
+		if content.Type != gjson.String && text == "" {
+			return true
+		}
+		if next, err := sjson.Set(updated, "choices."+i.String()+".delta.content", text); err == nil {
+			updated = next
+			changed = true
+		}
+		return true
+	})
+	if !changed {
+		return event
+	}
+	return "data: " + updated + "\n\n"
+}
+
+// flushChatChoices 流结束时补发仍在保留中的文本（上游未发送 finish_reason 的情况）。
+func (w *outputPostprocessWriter) flushChatChoices() string {
+	if len(w.choices) == 0 || w.lastChunk == "" {
+		return ""
+	}
+	var out strings.Builder
+	for index, stream := range w.choices {
+		rest := stream.Flush()
+		if rest == "" {
+			continue
+		}
+		chunk := gin.H{
+			"id":      gjson.Get(w.lastChunk, "id").String(),
+			"object":  "chat.completion.chunk",
+			"created": gjson.Get(w.lastChunk, "created").Int(),
+			"model":   gjson.Get(w.lastChunk, "model").String(),
+			"choices": []gin.H{{"index": index, "delta": gin.H{"content": rest}}},
+		}
+		if data, err := json.Marshal(chunk); err == nil {
+			out.WriteString("data: " + string(data) + "\n\n")
+		}
+	}
+	w.choices = make(map[int64]*service.OutputPostprocessStream)
+	return out.String()
+}
+
+// rewriteResponsesEvent 改写 Responses 流：output_text 增量按 item/content 逐段处理，
+// *.done 与 response.completed 中的完整文本同步替换；署名作为新的 message 输出项在 response.completed 前补发。
+func (w *outputPostprocessWriter) rewriteResponsesEvent(event, eventName, payload string) string {
+	if !gjson.Valid(payload) {
+		return event
+	}
+	parsed := gjson.Parse(payload)
+	updated := payload
+	prefix := ""
+	switch parsed.Get("type").String() {
+	case "response.output_item.added":
+		w.maxOutputIndex = max(w.maxOutputIndex, parsed.Get("output_index").Int())
+	case "response.output_text.delta":
+		key := responsesPartKey(parsed.Get("item_id").String(), parsed.Get("content_index").Int())
+		part := w.parts[key]
+		if part == nil {
+			part = &responsesTextPart{stream: w.p.NewStream()}
+			w.parts[key] = part
+		}
+		text := part.stream.Push(parsed.Get("delta").String())
+		part.emitted.WriteString(text)
+		// 保留区内的增量也照常发出（delta 为空），保证 sequence_number 连续
+		updated, _ = sjson.Set(updated, "delta", text)
+	case "response.output_text.done":
+		itemID, contentIndex := parsed.Get("item_id").String(), parsed.Get("content_index").Int()
+		key := responsesPartKey(itemID, contentIndex)
+		final := w.p.Apply(parsed.Get("text").String())
+		if part := w.parts[key]; part != nil {
+			delete(w.parts, key)
+			if rest := part.stream.Flush(); rest != "" {
+				part.emitted.WriteString(rest)
+				prefix = w.responsesInsertedEvent(parsed, gin.H{
+					"type":          "response.output_text.delta",
+					"item_id":       itemID,
+					"output_index":  parsed.Get("output_index").Int(),
+					"content_index": contentIndex,
+					"delta":         rest,
+				})
+			}
+			final = part.emitted.String()
+		}
+		w.finalTexts[key] = final
+		updated, _ = sjson.Set(updated, "text", final)
+	case "response.content_part.done":
+		if parsed.Get("part.type").String() == "output_text" {
+			key := responsesPartKey(parsed.Get("item_id").String(), parsed.Get("content_index").Int())
+			updated, _ = sjson.Set(updated, "part.text", w.responsesFinalText(key, parsed.Get("part.text").String()))
+		}
+	case "response.output_item.done":
+		item := parsed.Get("item")
+		if item.Get("type").String() == "message" {
+			item.Get("content").ForEach(func(i, content gjson.Result) bool {
+				if content.Get("type").String() == "output_text" {
+					key := responsesPartKey(item.Get("id").String(), i.Int())
+					updated, _ = sjson.Set(updated, "item.content."+i.String()+".text", w.responsesFinalText(key, content.Get("text").String()))
+				}
+				return true
+			})
+		}
+	case "response.completed", "response.incomplete", "response.failed":
+		updated = string(w.rewriteResponsesOutput([]byte(updated), "response.output"))
+		output := gjson.Get(updated, "response.output")
+		if parsed.Get("type").String() == "response.completed" && w.p.Attribution() != "" && !responsesOutputHasToolCall(output) {
+			index := max(w.maxOutputIndex, int64(len(output.Array()))-1) + 1
+			w.maxOutputIndex = index
+			prefix = w.responsesAttributionEvents(parsed, index)
+			updated, _ = sjson.Set(updated, "response.output.-1", responsesAttributionItem(w.p.Attribution()))
+		}
+	}
+	updated = w.responsesRenumber(updated)
+	if prefix == "" && updated == payload {
+		return event
+	}
+	return prefix + formatSSEEvent(eventName, updated)
+}
+
+func responsesPartKey(itemID string, contentIndex int64) string {
+	return itemID + "/" + strconv.FormatInt(contentIndex, 10)
+}
+
+// responsesFinalText 返回流中已输出的完整文本；未经过增量处理的部分直接整段改写。
+func (w *outputPostprocessWriter) responsesFinalText(key, original string) string {
+	if final, ok := w.finalTexts[key]; ok {
+		return final
+	}
+	return w.p.Apply(original)
+}
+
+// responsesRenumber 按已插入的事件数顺延 sequence_number。
+func (w *outputPostprocessWriter) responsesRenumber(payload string) string {
+	if w.seqOffset == 0 {
+		return payload
+	}
+	seq := gjson.Get(payload, "sequence_number")
+	if !seq.Exists() {
+		return payload
+	}
+	updated, err := sjson.Set(payload, "sequence_number", seq.Int()+w.seqOffset)
+	if err != nil {
+		return payload
+	}
+	return updated
+}
+
+// responsesInsertedEvent 构造一个插入在当前事件之前的事件，占用当前事件的 sequence_number 并顺延后续事件。
+func (w *outputPostprocessWriter) responsesInsertedEvent(current gjson.Result, data gin.H) string {
+	if seq := current.Get("sequence_number"); seq.Exists() {
+		data["sequence_number"] = seq.Int() + w.seqOffset
+		w.seqOffset++
+	}
+	payload, _ := json.Marshal(data)
+	return formatSSEEvent(data["type"].(string), string(payload))
+}
+
+// responsesAttributionEvents 构造署名输出项的完整事件序列（added → delta → done）。
+func (w *outputPostprocessWriter) responsesAttributionEvents(current gjson.Result, index int64) string {
+	attribution := w.p.Attribution()
+	part := gin.H{"type": "output_text", "text": "", "annotations": []any{}}
+	donePart := gin.H{"type": "output_text", "text": attribution, "annotations": []any{}}
+	base := gin.H{"item_id": responsesAttributionItemID, "output_index": index, "content_index": 0}
+	with := func(fields gin.H) gin.H {
+		for k, v := range base {
+			fields[k] = v
+		}
+		return fields
+	}
+	added := responsesAttributionItem("")
+	added["status"] = "in_progress"
+	added["content"] = []any{}
+	return w.responsesInsertedEvent(current, gin.H{"type": "response.output_item.added", "output_index": index, "item": added}) +
+		w.responsesInsertedEvent(current, with(gin.H{"type": "response.content_part.added", "part": part})) +
+		w.responsesInsertedEvent(current, with(gin.H{"type": "response.output_text.delta", "delta": attribution})) +
+		w.responsesInsertedEvent(current, with(gin.H{"type": "response.output_text.done", "text": attribution})) +
+		w.responsesInsertedEvent(current, with(gin.H{"type": "response.content_part.done", "part": donePart})) +
+		w.responsesInsertedEvent(current, gin.H{"type": "response.output_item.done", "output_index": index, "item": responsesAttributionItem(attribution)})
+}
+
+func responsesAttributionItem(text string) gin.H {
+	return gin.H{
+		"id":      responsesAttributionItemID,
+		"type":    "message",
+		"status":  "completed",
+		"role":    "assistant",
+		"content": []gin.H{{"type": "output_text", "text": text, "annotations": []any{}}},
+	}
+}
+
+// responsesOutputHasToolCall 判断输出中是否有需要客户端执行的工具调用（此时不追加署名）。
+func responsesOutputHasToolCall(output gjson.Result) bool {
+	found := false
+	output.ForEach(func(_, item gjson.Result) bool {
+		switch item.Get("type").String() {
+		case "function_call", "custom_tool_call", "local_shell_call", "shell_call", "apply_patch_call", "computer_call":
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// rewriteResponsesOutput 改写 Responses 输出数组中 message 项的 output_text。
+func (w *outputPostprocessWriter) rewriteResponsesOutput(data []byte, path string) []byte {
+	out := data
+	gjson.GetBytes(data, path).ForEach(func(i, item gjson.Result) bool {
+		if item.Get("type").String() != "message" {
+			return true
+		}
+		item.Get("content").ForEach(func(j, content gjson.Result) bool {
+			if content.Get("type").String() == "output_text" {
+				if next, err := sjson.SetBytes(out, path+"."+i.String()+".content."+j.String()+".text", w.p.Apply(content.Get("text").String())); err == nil {
+					out = next
+				}
+			}
+			return true
+		})
+		return true
+	})
+	return out
+}
+
+// rewriteGeminiEvent 改写 Gemini 流式分片：按 candidate 逐段处理文本 part（跳过 thought），
+// 收到 finishReason 时补发剩余文本并在未发生函数调用时追加署名。
+func (w *outputPostprocessWriter) rewriteGeminiEvent(event, payload string) string {
+	if !gjson.Valid(payload) {
+		return event
+	}
+	candidates := gjson.Get(payload, "candidates")
+	if !candidates.IsArray() || len(candidates.Array()) == 0 {
+		return event
+	}
+	w.lastChunk = payload
+	updated := payload
+	candidates.ForEach(func(i, candidate gjson.Result) bool {
+		index := candidate.Get("index").Int()
+		state := w.candidates[index]
+		if state == nil {
+			state = &geminiCandidateState{stream: w.p.NewStream()}
+			w.candidates[index] = state
+		}
+		partsPath := "candidates." + i.String() + ".content.parts"
+		lastText := int64(-1)
+		candidate.Get("content.parts").ForEach(func(j, part gjson.Result) bool {
+			if part.Get("functionCall").Exists() {
+				state.functionCall = true
+			}
+			text := part.Get("text")
+			if text.Type != gjson.String || part.Get("thought").Bool() {
+				return true
+			}
+			lastText = j.Int()
+			updated, _ = sjson.Set(updated, partsPath+"."+j.String()+".text", state.stream.Push(text.String()))
+			return true
+		})
+		if candidate.Get("finishReason").String() == "" {
+			return true
+		}
+		delete(w.candidates, index)
+		if rest := state.stream.Flush(); rest != "" {
+			if lastText >= 0 {
+				textPath := partsPath + "." + strconv.FormatInt(lastText, 10) + ".text"
+				updated, _ = sjson.Set(updated, textPath, gjson.Get(updated, textPath).String()+rest)
+			} else {
+				updated, _ = sjson.Set(updated, partsPath+".-1", gin.H{"text": rest})
+			}
+		}
+		if attribution := w.p.Attribution(); attribution != "" && !state.functionCall {
+			updated, _ = sjson.Set(updated, partsPath+".-1", gin.H{"text": "\n\n" + attribution})
+		}
+		return true
+	})
+	if updated == payload {
+		return event
+	}
+	return "data: " + updated + "\n\n"
+}
+
+// flushGeminiCandidates 流结束时补发仍在保留中的文本（上游未发送 finishReason 的情况）。
+func (w *outputPostprocessWriter) flushGeminiCandidates() string {
+	if len(w.candidates) == 0 || w.lastChunk == "" {
+		return ""
+	}
+	var out strings.Builder
+	for index, state := range w.candidates {
+		rest := state.stream.Flush()
+		if rest == "" {
+			continue
+		}
+		chunk := gin.H{
+			"candidates":   []gin.H{{"index": index, "content": gin.H{"role": "model", "parts": []gin.H{{"text": rest}}}}},
+			"modelVersion": gjson.Get(w.lastChunk, "modelVersion").String(),
+		}
+		if data, err := json.Marshal(chunk); err == nil {
+			out.WriteString("data: " + string(data) + "\n\n")
+		}
+	}
+	w.candidates = make(map[int64]*geminiCandidateState)
+	return out.String()
+}
+
+// rewriteGeminiResponse 改写一个 Gemini 非流式响应对象（prefix 为其在 JSON 中的路径前缀）。
+func (w *outputPostprocessWriter) rewriteGeminiResponse(data []byte, prefix string) []byte {
+	out := data
+	attribution := w.p.Attribution()
+	gjson.GetBytes(data, prefix+"candidates").ForEach(func(i, candidate gjson.Result) bool {
+		partsPath := prefix + "candidates." + i.String() + ".content.parts"
+		functionCall := false
+		candidate.Get("content.parts").ForEach(func(j, part gjson.Result) bool {
+			if part.Get("functionCall").Exists() {
+				functionCall = true
+			}
+			text := part.Get("text")
+			if text.Type != gjson.String || part.Get("thought").Bool() {
+				return true
+			}
+			if next, err := sjson.SetBytes(out, partsPath+"."+j.String()+".text", w.p.Apply(text.String())); err == nil {
+				out = next
+			}
+			return true
+		})
+		if attribution != "" && !functionCall && candidate.Get("finishReason").String() != "" {
+			if next, err := sjson.SetBytes(out, partsPath+".-1", gin.H{"text": "\n\n" + attribution}); err == nil {
+				out = next
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// rewriteJSON 改写非流式响应的文本并追加署名；无法识别的响应原样返回。
+func (w *outputPostprocessWriter) rewriteJSON(data []byte) []byte {
+	if !gjson.ValidBytes(data) {
+		return data
+	}
+	out := data
+	attribution := w.p.Attribution()
+	switch w.format {
+	case outputFormatAnthropic:
+		if gjson.GetBytes(out, "type").String() != "message" {
+			return data
+		}
+		gjson.GetBytes(data, "content").ForEach(func(i, block gjson.Result) bool {
+			if block.Get("type").String() == "text" {
+				if next, err := sjson.SetBytes(out, "content."+i.String()+".text", w.p.Apply(block.Get("text").String())); err == nil {
+					out = next
+				}
+			}
+			return true
+		})
+		if stopReason := gjson.GetBytes(out, "stop_reason").String(); attribution != "" && stopReason != "tool_use" {
+			if next, err := sjson.SetBytes(out, "content.-1", gin.H{"type": "text", "text": attribution}); err == nil {
+				out = next
+			}
+		}
+	case outputFormatChatCompletions:
+		gjson.GetBytes(data, "choices").ForEach(func(i, choice gjson.Result) bool {
+			content := choice.Get("message.content")
+			if content.Type != gjson.String {
+				return true
+			}
+			text := w.p.Apply(content.String())
+			if attribution != "" && !isChatToolFinishReason(choice.Get("finish_reason").String()) {
+				text += "\n\n" + attribution
+			}
+			if next, err := sjson.SetBytes(out, "choices."+i.String()+".message.content", text); err == nil {
+				out = next
+			}
+			return true
+		})
+	case outputFormatResponses:
+		if gjson.GetBytes(out, "object").String() != "response" {
+			return data
+		}
+		out = w.rewriteResponsesOutput(out, "output")
+		if attribution != "" && gjson.GetBytes(out, "status").String() == "completed" && !responsesOutputHasToolCall(gjson.GetBytes(out, "output")) {
+			if next, err := sjson.SetBytes(out, "output.-1", responsesAttributionItem(attribution)); err == nil {
+				out = next
+			}
+		}
+	case outputFormatGemini:
+		// streamGenerateContent 未指定 alt=sse 时返回分片数组
+		if parsed := gjson.ParseBytes(out); parsed.IsArray() {
+			parsed.ForEach(func(i, _ gjson.Result) bool {
+				out = w.rewriteGeminiResponse(out, i.String()+".")
+				return true
+			})
+		} else {
+			out = w.rewriteGeminiResponse(out, "")
+		}
+	}
+	return out
+}
+
+func isChatToolFinishReason(reason string) bool {
+	return reason == "tool_calls" || reason == "function_call"
+}
+
+// parseSSEEvent 提取事件名与 data 内容（多行 data 以换行拼接）；无 data 行（如注释 keepalive）时 ok=false。
+func parseSSEEvent(event string) (name, data string, ok bool) {
+	var dataLines []string
+	for _, line := range strings.Split(strings.TrimRight(event, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if len(dataLines) == 0 {
+		return "", "", false
+	}
+	return name, strings.Join(dataLines, "\n"), true
+}
+
+func formatSSEEvent(name, data string) string {
+	if name == "" {
+		return "data: " + data + "\n\n"
+	}
+	return "event: " + name + "\ndata: " + data + "\n\n"
+}
+
+func anthropicTextDeltaEvent(index int64, text string) string {
+	data, _ := json.Marshal(gin.H{"type": "content_block_delta", "index": index, "delta": gin.H{"type": "text_delta", "text": text}})
+	return formatSSEEvent("content_block_delta", string(data))
+}
+
+// outputPostprocessFormatForPath 按入口路径选择输出格式；会话压缩（/responses/compact）等非生成接口返回 0，不做改写。
+func outputPostprocessFormatForPath(path string) outputPostprocessFormat {
+	switch {
+	case strings.HasSuffix(path, "/messages"):
+		return outputFormatAnthropic
+	case strings.HasSuffix(path, "/chat/completions"):
+		return outputFormatChatCompletions
+	case strings.HasSuffix(path, "/responses"):
+		return outputFormatResponses
+	case strings.HasSuffix(path, ":generateContent") || strings.HasSuffix(path, ":streamGenerateContent"):
+		return outputFormatGemini
+	}
+	return 0
+}
+
+// OutputPostprocessMiddleware 按 API Key 所属分组的 output_postprocess 配置改写最终输出文本，覆盖 Messages、
+// Chat Completions、Responses（含 Codex 入口）与 Gemini generateContent。需注册在 API Key 认证之后、
+// 位于 ResponseContentGuardMiddleware 之内。分组未配置时为空操作。
+func OutputPostprocessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := outputPostprocessFormatForPath(c.Request.URL.Path)
+		if format == 0 || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil {
+			c.Next()
+			return
+		}
+		p := service.OutputPostprocessorForGroup(apiKey.Group)
+		if p == nil {
+			c.Next()
+			return
+		}
+		originalWriter := c.Writer
+		w := newOutputPostprocessWriter(originalWriter, p, format)
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+		}()
+		c.Next()
+		w.finish()
+	}
+}
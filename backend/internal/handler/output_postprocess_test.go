@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func serveOutputPostprocess(t *testing.T, path string, cfg service.GroupOutputPostprocessConfig, h gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveOutputPostprocessRoute(t, path, path, cfg, h)
+}
+
+func serveOutputPostprocessRoute(t *testing.T, route, path string, cfg service.GroupOutputPostprocessConfig, h gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 1, Group: &service.Group{ID: 1, OutputPostprocess: cfg}})
+		c.Next()
+	})
+	r.Use(OutputPostprocessMiddleware())
+	r.POST(route, h)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	return rec
+}
+
+var testOutputPostprocessConfig = service.GroupOutputPostprocessConfig{
+	Rules:       []service.OutputPostprocessRule{{Type: service.OutputPostprocessRuleBannedPhrase, Pattern: "secret sauce", Replacement: "[redacted]"}},
+	Attribution: "— via gateway",
+}
+
+// collectAnthropicText 拼接 SSE 中全部 text_delta 文本，并返回出现过的事件类型序列。
+func collectAnthropicText(t *testing.T, body string) (string, []string) {
+	t.Helper()
+	var text strings.Builder
+	var types []string
+	for _, event := range strings.Split(body, "\n\n") {
+		_, data, ok := parseSSEEvent(event)
+		if !ok {
+			continue
+		}
+		types = append(types, gjson.Get(data, "type").String())
+		if gjson.Get(data, "delta.type").String() == "text_delta" {
+			text.WriteString(gjson.Get(data, "delta.text").String())
+		}
+	}
+	return text.String(), types
+}
+
+func TestOutputPostprocess_AnthropicStream(t *testing.T) {
+	rec := serveOutputPostprocess(t, "/v1/messages", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		events := []string{
+			`event: message_start` + "\n" + `data: {"type":"message_start","message":{"id":"msg_1"}}`,
+			`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"The secr"}}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"et Sauce is here and there is plenty more text"}}`,
+			`event: content_block_stop` + "\n" + `data: {"type":"content_block_stop","index":0}`,
+			`event: message_delta` + "\n" + `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`,
+			`event: message_stop` + "\n" + `data: {"type":"message_stop"}`,
+		}
+		for _, ev := range events {
+			// 拆成两半写入，模拟事件跨 Write 调用
+			half := len(ev) / 2
+			_, _ = c.Writer.WriteString(ev[:half])
+			_, _ = c.Writer.WriteString(ev[half:] + "\n\n")
+			c.Writer.Flush()
+		}
+	})
+
+	text, types := collectAnthropicText(t, rec.Body.String())
+	require.Equal(t, "The [redacted] is here and there is plenty more text— via gateway", text)
+	require.NotContains(t, rec.Body.String(), "secr")
+	// 首个增量全部落在保留区内被暂缓；块结束前补发剩余文本，署名作为新文本块追加
+	require.Equal(t, []string{
+		"message_start", "content_block_start", "content_block_delta", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_stop", "message_delta", "message_stop",
+	}, types)
+	require.Contains(t, rec.Body.String(), `"index":1`)
+}
+
+func TestOutputPostprocess_AnthropicStreamSkipsAttributionOnToolUse(t *testing.T) {
+	rec := serveOutputPostprocess(t, "/v1/messages", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.WriteString("event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"}}\n\n")
+	})
+	require.NotContains(t, rec.Body.String(), "via gateway")
+}
+
+func TestOutputPostprocess_AnthropicJSON(t *testing.T) {
+	rec := serveOutputPostprocess(t, "/v1/messages", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"type":"message","content":[{"type":"thinking","thinking":"secret sauce"},{"type":"text","text":"my secret sauce"}],"stop_reason":"end_turn"}`))
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Equal(t, "secret sauce", gjson.Get(body, "content.0.thinking").String(), "thinking blocks are left untouched")
+	require.Equal(t, "my [redacted]", gjson.Get(body, "content.1.text").String())
+	require.Equal(t, "— via gateway", gjson.Get(body, "content.2.text").String())
+}
+
+func TestOutputPostprocess_ErrorResponsePassesThrough(t *testing.T) {
+	raw := `{"type":"error","error":{"type":"invalid_request_error","message":"secret sauce"}}`
+	rec := serveOutputPostprocess(t, "/v1/messages", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Data(http.StatusBadRequest, "application/json", []byte(raw))
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, raw, rec.Body.String())
+}
+
+func TestOutputPostprocess_ChatCompletionsStream(t *testing.T) {
+	rec := serveOutputPostprocess(t, "/v1/chat/completions", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":"Our secret"}}]}`,
+			`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"content":" sauce!"}}]}`,
+			`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		} {
+			_, _ = c.Writer.WriteString("data: " + chunk + "\n\n")
+		}
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+	})
+
+	var text strings.Builder
+	for _, event := range strings.Split(rec.Body.String(), "\n\n") {
+		if _, data, ok := parseSSEEvent(event); ok && data != "[DONE]" {
+			text.WriteString(gjson.Get(data, "choices.0.delta.content").String())
+		}
+	}
+	require.Equal(t, "Our [redacted]!\n\n— via gateway", text.String())
+	require.True(t, strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n"))
+}
+
+func TestOutputPostprocess_ChatCompletionsJSON(t *testing.T) {
+	rec := serveOutputPostprocess(t, "/v1/chat/completions", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"choices": []gin.H{
+			{"index": 0, "message": gin.H{"role": "assistant", "content": "secret sauce"}, "finish_reason": "stop"},
+			{"index": 1, "message": gin.H{"role": "assistant", "content": nil, "tool_calls": []gin.H{}}, "finish_reason": "tool_calls"},
+		}})
+	})
+	require.Equal(t, "[redacted]\n\n— via gateway", gjson.Get(rec.Body.String(), "choices.0.message.content").String())
+	require.Equal(t, gjson.Null, gjson.Get(rec.Body.String(), "choices.1.message.content").Type)
+}
+
+func TestOutputPostprocess_ResponsesStream(t *testing.T) {
+	for _, path := range []string{"/v1/responses", "/responses", "/backend-api/codex/responses"} {
+		rec := serveOutputPostprocess(t, path, testOutputPostprocessConfig, func(c *gin.Context) {
+			c.Header("Content-Type", "text/event-stream")
+			for _, ev := range []string{
+				`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","output":[]}}`,
+				`{"type":"response.output_item.added","sequence_number":1,"output_index":0,"item":{"id":"msg_1","type":"message","content":[]}}`,
+				`{"type":"response.output_text.delta","sequence_number":2,"item_id":"msg_1","output_index":0,"content_index":0,"delta":"Our secret"}`,
+				`{"type":"response.output_text.delta","sequence_number":3,"item_id":"msg_1","output_index":0,"content_index":0,"delta":" sauce!"}`,
+				`{"type":"response.output_text.done","sequence_number":4,"item_id":"msg_1","output_index":0,"content_index":0,"text":"Our secret sauce!"}`,
+				`{"type":"response.content_part.done","sequence_number":5,"item_id":"msg_1","output_index":0,"content_index":0,"part":{"type":"output_text","text":"Our secret sauce!"}}`,
+				`{"type":"response.output_item.done","sequence_number":6,"output_index":0,"item":{"id":"msg_1","type":"message","content":[{"type":"output_text","text":"Our secret sauce!"}]}}`,
+				`{"type":"response.completed","sequence_number":7,"response":{"id":"resp_1","status":"completed","output":[{"id":"msg_1","type":"message","content":[{"type":"output_text","text":"Our secret sauce!"}]}]}}`,
+			} {
+				_, _ = c.Writer.WriteString("event: " + gjson.Get(ev, "type").String() + "\ndata: " + ev + "\n\n")
+			}
+		})
+
+		var deltas strings.Builder
+		var seqs []int64
+		var completed string
+		for _, event := range strings.Split(rec.Body.String(), "\n\n") {
+			name, data, ok := parseSSEEvent(event)
+			if !ok {
+				continue
+			}
+			require.Equal(t, gjson.Get(data, "type").String(), name)
+			seqs = append(seqs, gjson.Get(data, "sequence_number").Int())
+			switch name {
+			case "response.output_text.delta":
+				deltas.WriteString(gjson.Get(data, "delta").String())
+			case "response.output_text.done":
+				if gjson.Get(data, "item_id").String() == "msg_1" {
+					require.Equal(t, "Our [redacted]!", gjson.Get(data, "text").String())
+				}
+			case "response.completed":
+				completed = data
+			}
+		}
+		require.NotContains(t, rec.Body.String(), "secret", path)
+		require.Equal(t, "Our [redacted]!— via gateway", deltas.String(), path)
+		for i, seq := range seqs {
+			require.Equal(t, int64(i), seq, "sequence numbers stay contiguous after inserted events")
+		}
+		require.Equal(t, "Our [redacted]!", gjson.Get(completed, "response.output.0.content.0.text").String())
+		require.Equal(t, "— via gateway", gjson.Get(completed, "response.output.1.content.0.text").String())
+	}
+}
+
+func TestOutputPostprocess_ResponsesJSON(t *testing.T) {
+	rec := serveOutputPostprocess(t, "/v1/responses", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"object":"response","status":"completed","output":[{"type":"reasoning","summary":[]},{"type":"message","content":[{"type":"output_text","text":"my secret sauce"}]}]}`))
+	})
+	body := rec.Body.String()
+	require.Equal(t, "my [redacted]", gjson.Get(body, "output.1.content.0.text").String())
+	require.Equal(t, "— via gateway", gjson.Get(body, "output.2.content.0.text").String())
+
+	// 工具调用不追加署名
+	rec = serveOutputPostprocess(t, "/v1/responses", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"object":"response","status":"completed","output":[{"type":"function_call","name":"f","arguments":"{}"}]}`))
+	})
+	require.NotContains(t, rec.Body.String(), "via gateway")
+}
+
+func TestOutputPostprocess_GeminiStream(t *testing.T) {
+	rec := serveOutputPostprocessRoute(t, "/v1beta/models/*modelAction", "/v1beta/models/gemini-2.5-pro:streamGenerateContent", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"candidates":[{"index":0,"content":{"role":"model","parts":[{"text":"thinking about secret sauce","thought":true}]}}]}`,
+			`{"candidates":[{"index":0,"content":{"role":"model","parts":[{"text":"Our secret"}]}}]}`,
+			`{"candidates":[{"index":0,"content":{"role":"model","parts":[{"text":" sauce!"}]},"finishReason":"STOP"}]}`,
+		} {
+			_, _ = c.Writer.WriteString("data: " + chunk + "\n\n")
+		}
+	})
+
+	var text strings.Builder
+	for _, event := range strings.Split(rec.Body.String(), "\n\n") {
+		if _, data, ok := parseSSEEvent(event); ok {
+			gjson.Get(data, "candidates.0.content.parts").ForEach(func(_, part gjson.Result) bool {
+				if !part.Get("thought").Bool() {
+					text.WriteString(part.Get("text").String())
+				}
+				return true
+			})
+		}
+	}
+	require.Equal(t, "Our [redacted]!\n\n— via gateway", text.String())
+	require.Contains(t, rec.Body.String(), "thinking about secret sauce", "thought parts are left untouched")
+}
+
+func TestOutputPostprocess_GeminiJSON(t *testing.T) {
+	rec := serveOutputPostprocessRoute(t, "/v1beta/models/*modelAction", "/v1beta/models/gemini-2.5-pro:generateContent", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"candidates":[{"content":{"parts":[{"text":"my secret sauce"}]},"finishReason":"STOP"},{"content":{"parts":[{"functionCall":{"name":"f"}}]},"finishReason":"STOP"}]}`))
+	})
+	body := rec.Body.String()
+	require.Equal(t, "my [redacted]", gjson.Get(body, "candidates.0.content.parts.0.text").String())
+	require.Equal(t, "\n\n— via gateway", gjson.Get(body, "candidates.0.content.parts.1.text").String())
+	require.Len(t, gjson.Get(body, "candidates.1.content.parts").Array(), 1)
+}
+
+func TestOutputPostprocess_CompactIsNoop(t *testing.T) {
+	raw := `{"object":"response.compaction","output":[{"type":"message","content":[{"type":"output_text","text":"secret sauce"}]}]}`
+	rec := serveOutputPostprocess(t, "/v1/responses/compact", testOutputPostprocessConfig, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(raw))
+	})
+	require.Equal(t, raw, rec.Body.String())
+}
+
+func TestOutputPostprocess_NoConfigIsNoop(t *testing.T) {
+	raw := `{"type":"message","content":[{"type":"text","text":"secret sauce"}],"stop_reason":"end_turn"}`
+	rec := serveOutputPostprocess(t, "/v1/messages", service.GroupOutputPostprocessConfig{}, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(raw))
+	})
+	require.Equal(t, raw, rec.Body.String())
+}
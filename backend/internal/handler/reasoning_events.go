@@ -0,0 +1,575 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ReasoningEventsProfileHeader 命中客户端画像时返回的响应头，值为画像名称。
+const ReasoningEventsProfileHeader = "X-Sub2API-Reasoning-Profile"
+
+// convert 模式下包裹推理文本的标记
+const (
+	reasoningConvertOpen  = "<think>\n"
+	reasoningConvertClose = "\n</think>\n\n"
+)
+
+// reasoningEventsMaxJSONBytes 非流式 JSON 缓冲上限；超过后放弃处理直接透传。
+const reasoningEventsMaxJSONBytes = 32 << 20
+
+type reasoningEventsFormat int
+
+const (
+	reasoningFormatAnthropic reasoningEventsFormat = iota + 1
+	reasoningFormatChatCompletions
+	reasoningFormatResponses
+)
+
+type reasoningEventsProfile struct {
+	name    string
+	pattern *regexp.Regexp
+	mode    string
+}
+
+// reasoningEventsResolver 按 User-Agent 选择客户端画像。
+type reasoningEventsResolver struct {
+	defaultMode string
+	profiles    []reasoningEventsProfile
+}
+
+func newReasoningEventsResolver(cfg config.GatewayReasoningEventsConfig) *reasoningEventsResolver {
+	r := &reasoningEventsResolver{defaultMode: normalizeReasoningEventsMode(cfg.DefaultMode)}
+	for _, p := range cfg.Profiles {
+		pattern, err := regexp.Compile(p.UserAgentPattern)
+		if err != nil {
+			// 配置校验已拦截非法正则，这里仅防御
+			continue
+		}
+		r.profiles = append(r.profiles, reasoningEventsProfile{name: p.Name, pattern: pattern, mode: normalizeReasoningEventsMode(p.Mode)})
+	}
+	if r.defaultMode == config.ReasoningEventsModePassthrough && len(r.profiles) == 0 {
+		return nil
+	}
+	return r
+}
+
+func normalizeReasoningEventsMode(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case config.ReasoningEventsModeFilter, config.ReasoningEventsModeConvert:
+		return mode
+	}
+	return config.ReasoningEventsModePassthrough
+}
+
+// resolve 返回命中的画像名称（未命中为空）与处理方式。
+func (r *reasoningEventsResolver) resolve(userAgent string) (string, string) {
+	for _, p := range r.profiles {
+		if p.pattern.MatchString(userAgent) {
+			return p.name, p.mode
+		}
+	}
+	return "", r.defaultMode
+}
+
+type reasoningEventsWriterMode int
+
+const (
+	reasoningWriterUndecided reasoningEventsWriterMode = iota
+	reasoningWriterPassthrough
+	reasoningWriterJSON
+	reasoningWriterSSE
+)
+
+// reasoningEventsWriter 过滤或转换响应中的推理内容：
+//   - Anthropic：thinking / redacted_thinking 内容块，过滤后重新编号后续块的 index；
+//   - Chat Completions：delta/message 中的 reasoning_content / reasoning 字段；
+//   - Responses：reasoning summary / reasoning text 事件（convert 等同 filter）。
+type reasoningEventsWriter struct {
+	gin.ResponseWriter
+	format   reasoningEventsFormat
+	convert  bool
+	mode     reasoningEventsWriterMode
+	buf      bytes.Buffer
+	accepted int
+
+	// Anthropic：上游 index -> 输出 index；dropped 记录被过滤的块
+	indexMap  map[int64]int64
+	dropped   map[int64]bool
+	converted map[int64]bool
+	nextIndex int64
+
+	// Chat Completions convert：各 choice 是否处于未闭合的 <think> 中
+	thinking map[int64]bool
+}
+
+func newReasoningEventsWriter(w gin.ResponseWriter, format reasoningEventsFormat, mode string) *reasoningEventsWriter {
+	return &reasoningEventsWriter{
+		ResponseWriter: w,
+		format:         format,
+		convert:        mode == config.ReasoningEventsModeConvert,
+		indexMap:       make(map[int64]int64),
+		dropped:        make(map[int64]bool),
+		converted:      make(map[int64]bool),
+		thinking:       make(map[int64]bool),
+	}
+}
+
+func (w *reasoningEventsWriter) decide() {
+	if w.mode != reasoningWriterUndecided {
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(w.ResponseWriter.Header().Get("Content-Type")))
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		w.mode = reasoningWriterSSE
+	case strings.HasPrefix(contentType, "application/json") && w.ResponseWriter.Status() < http.StatusBadRequest:
+		w.mode = reasoningWriterJSON
+	default:
+		w.mode = reasoningWriterPassthrough
+	}
+}
+
+func (w *reasoningEventsWriter) Write(b []byte) (int, error) {
+	w.decide()
+	w.accepted += len(b)
+	switch w.mode {
+	case reasoningWriterJSON:
+		if w.buf.Len()+len(b) > reasoningEventsMaxJSONBytes {
+			if err := w.writeBuffered(); err != nil {
+				return 0, err
+			}
+			return w.ResponseWriter.Write(b)
+		}
+		return w.buf.Write(b)
+	case reasoningWriterSSE:
+		w.buf.Write(b)
+		if err := w.drainSSE(false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	default:
+		return w.ResponseWriter.Write(b)
+	}
+}
+
+func (w *reasoningEventsWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *reasoningEventsWriter) WriteHeaderNow() {
+	w.decide()
+	if w.mode == reasoningWriterJSON {
+		// 处理后长度会变化，JSON 响应延迟到 finish 时再提交
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *reasoningEventsWriter) Written() bool {
+	return w.accepted > 0 || w.ResponseWriter.Written()
+}
+
+func (w *reasoningEventsWriter) Size() int {
+	if w.accepted > 0 {
+		return w.accepted
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *reasoningEventsWriter) Flush() {
+	w.decide()
+	if w.mode == reasoningWriterJSON {
+		if len(bytes.TrimSpace(w.buf.Bytes())) > 0 {
+			_ = w.writeBuffered()
+		} else if w.buf.Len() > 0 {
+			_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+			w.buf.Reset()
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *reasoningEventsWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mode = reasoningWriterPassthrough
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *reasoningEventsWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.CloseNotify()
+}
+
+func (w *reasoningEventsWriter) writeBuffered() error {
+	w.mode = reasoningWriterPassthrough
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	data := w.buf.Bytes()
+	w.buf = bytes.Buffer{}
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
+
+func (w *reasoningEventsWriter) finish() {
+	switch w.mode {
+	case reasoningWriterJSON:
+		data := w.buf.Bytes()
+		w.buf = bytes.Buffer{}
+		w.mode = reasoningWriterPassthrough
+		if len(bytes.TrimSpace(data)) == 0 {
+			w.ResponseWriter.WriteHeaderNow()
+			if len(data) > 0 {
+				_, _ = w.ResponseWriter.Write(data)
+			}
+			return
+		}
+		out := w.rewriteJSON(data)
+		w.ResponseWriter.Header().Del("Content-Length")
+		_, _ = w.ResponseWriter.Write(out)
+	case reasoningWriterSSE:
+		_ = w.drainSSE(true)
+	}
+}
+
+func (w *reasoningEventsWriter) drainSSE(final bool) error {
+	for {
+		data := w.buf.Bytes()
+		end := bytes.Index(data, []byte("\n\n"))
+		if end < 0 {
+			if !final || len(data) == 0 {
+				return nil
+			}
+			end = len(data)
+		} else {
+			end += 2
+		}
+		event := string(data[:end])
+		w.buf.Next(end)
+		if out := w.rewriteSSEEvent(event); out != "" {
+			if _, err := w.ResponseWriter.Write([]byte(out)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// rewriteSSEEvent 处理单个 SSE 事件，返回需要写出的内容（空串表示丢弃）。
+func (w *reasoningEventsWriter) rewriteSSEEvent(event string) string {
+	eventName, payload, ok := parseSSEEvent(event)
+	if !ok || !gjson.Valid(payload) {
+		return event
+	}
+	switch w.format {
+	case reasoningFormatAnthropic:
+		return w.rewriteAnthropicEvent(event, eventName, payload)
+	case reasoningFormatChatCompletions:
+		return w.rewriteChatCompletionsEvent(event, payload)
+	case reasoningFormatResponses:
+		return w.rewriteResponsesEvent(event, eventName, payload)
+	}
+	return event
+}
+
+func (w *reasoningEventsWriter) rewriteResponsesEvent(event, eventName, payload string) string {
+	eventType := gjson.Get(payload, "type").String()
+	if isResponsesReasoningEvent(eventType) {
+		return ""
+	}
+	var updated []byte
+	switch eventType {
+	case "response.output_item.added", "response.output_item.done":
+		updated = clearReasoningSummary([]byte(payload), "item")
+	case "response.completed", "response.incomplete", "response.failed":
+		updated = clearResponsesOutputSummaries([]byte(payload), "response.output")
+	default:
+		return event
+	}
+	if string(updated) == payload {
+		return event
+	}
+	return formatSSEEvent(eventName, string(updated))
+}
+
+// clearReasoningSummary 清空 reasoning 条目的 summary，保留 encrypted_content 以便客户端回传多轮上下文。
+func clearReasoningSummary(data []byte, path string) []byte {
+	item := gjson.GetBytes(data, path)
+	if item.Get("type").String() != "reasoning" || len(item.Get("summary").Array()) == 0 {
+		return data
+	}
+	if next, err := sjson.SetBytes(data, path+".summary", []any{}); err == nil {
+		return next
+	}
+	return data
+}
+
+func clearResponsesOutputSummaries(data []byte, path string) []byte {
+	out := data
+	gjson.GetBytes(data, path).ForEach(func(i, _ gjson.Result) bool {
+		out = clearReasoningSummary(out, path+"."+i.String())
+		return true
+	})
+	return out
+}
+
+func isAnthropicThinkingBlock(blockType string) bool {
+	return blockType == "thinking" || blockType == "redacted_thinking"
+}
+
+func (w *reasoningEventsWriter) rewriteAnthropicEvent(event, eventName, payload string) string {
+	parsed := gjson.Parse(payload)
+	index := parsed.Get("index")
+	switch parsed.Get("type").String() {
+	case "content_block_start":
+		blockType := parsed.Get("content_block.type").String()
+		if isAnthropicThinkingBlock(blockType) && (!w.convert || blockType == "redacted_thinking") {
+			w.dropped[index.Int()] = true
+			return ""
+		}
+		outIndex := w.nextIndex
+		w.nextIndex++
+		w.indexMap[index.Int()] = outIndex
+		if blockType != "thinking" {
+			return w.withAnthropicIndex(event, eventName, payload, index.Int(), outIndex)
+		}
+		w.converted[index.Int()] = true
+		start, _ := json.Marshal(gin.H{"type": "content_block_start", "index": outIndex, "content_block": gin.H{"type": "text", "text": ""}})
+		return formatSSEEvent("content_block_start", string(start)) + anthropicTextDeltaEvent(outIndex, reasoningConvertOpen)
+	case "content_block_delta":
+		if w.dropped[index.Int()] {
+			return ""
+		}
+		outIndex, ok := w.indexMap[index.Int()]
+		if !ok {
+			return event
+		}
+		if w.converted[index.Int()] {
+			if parsed.Get("delta.type").String() != "thinking_delta" {
+				// signature_delta 等仅对 thinking 块有意义
+				return ""
+			}
+			return anthropicTextDeltaEvent(outIndex, parsed.Get("delta.thinking").String())
+		}
+		return w.withAnthropicIndex(event, eventName, payload, index.Int(), outIndex)
+	case "content_block_stop":
+		if w.dropped[index.Int()] {
+			delete(w.dropped, index.Int())
+			return ""
+		}
+		outIndex, ok := w.indexMap[index.Int()]
+		if !ok {
+			return event
+		}
+		if w.converted[index.Int()] {
+			delete(w.converted, index.Int())
+			return anthropicTextDeltaEvent(outIndex, strings.TrimSuffix(reasoningConvertClose, "\n\n")) +
+				w.withAnthropicIndex(event, eventName, payload, index.Int(), outIndex)
+		}
+		return w.withAnthropicIndex(event, eventName, payload, index.Int(), outIndex)
+	}
+	return event
+}
+
+func (w *reasoningEventsWriter) withAnthropicIndex(event, eventName, payload string, index, outIndex int64) string {
+	if index == outIndex {
+		return event
+	}
+	updated, err := sjson.Set(payload, "index", outIndex)
+	if err != nil {
+		return event
+	}
+	return formatSSEEvent(eventName, updated)
+}
+
+// chatReasoningText 读取 Chat Completions 推理字段（reasoning_content 优先，兼容 reasoning）。
+func chatReasoningText(obj gjson.Result) (string, []string) {
+	var fields []string
+	text := ""
+	for _, field := range []string{"reasoning_content", "reasoning"} {
+		if v := obj.Get(field); v.Exists() {
+			fields = append(fields, field)
+			if text == "" && v.Type == gjson.String {
+				text = v.String()
+			}
+		}
+	}
+	return text, fields
+}
+
+func (w *reasoningEventsWriter) rewriteChatCompletionsEvent(event, payload string) string {
+	choices := gjson.Get(payload, "choices")
+	if !choices.IsArray() {
+		return event
+	}
+	updated := payload
+	changed := false
+	empty := true
+	choices.ForEach(func(i, choice gjson.Result) bool {
+		prefix := "choices." + i.String() + ".delta"
+		index := choice.Get("index").Int()
+		delta := choice.Get("delta")
+		reasoning, fields := chatReasoningText(delta)
+		content := delta.Get("content").String()
+		for _, field := range fields {
+			if next, err := sjson.Delete(updated, prefix+"."+field); err == nil {
+				updated = next
+				changed = true
+			}
+		}
+		if w.convert {
+			text := ""
+			if reasoning != "" {
+				if !w.thinking[index] {
+					text = reasoningConvertOpen
+					w.thinking[index] = true
+				}
+				text += reasoning
+			}
+			closing := content != "" || delta.Get("tool_calls").Exists() || choice.Get("finish_reason").String() != ""
+			if w.thinking[index] && closing {
+				text += reasoningConvertClose
+				delete(w.thinking, index)
+			}
+			if text != "" {
+				if next, err := sjson.Set(updated, prefix+".content", text+content); err == nil {
+					updated = next
+					changed = true
+				}
+			}
+		}
+		remaining := gjson.Get(updated, prefix)
+		if choice.Get("finish_reason").String() != "" || (remaining.IsObject() && len(remaining.Map()) > 0) {
+			empty = false
+		}
+		return true
+	})
+	if !changed {
+		return event
+	}
+	if empty && len(choices.Array()) > 0 && !gjson.Get(updated, "usage").IsObject() {
+		// 只含推理内容的增量在过滤后为空，整块丢弃
+		return ""
+	}
+	return "data: " + updated + "\n\n"
+}
+
+// isResponsesReasoningEvent Responses 流中的推理 summary / 推理文本事件。
+func isResponsesReasoningEvent(eventType string) bool {
+	return strings.HasPrefix(eventType, "response.reasoning_summary_") || strings.HasPrefix(eventType, "response.reasoning_text.")
+}
+
+// rewriteJSON 处理非流式响应中的推理内容；无法识别的响应原样返回。
+func (w *reasoningEventsWriter) rewriteJSON(data []byte) []byte {
+	if !gjson.ValidBytes(data) {
+		return data
+	}
+	out := data
+	switch w.format {
+	case reasoningFormatAnthropic:
+		if gjson.GetBytes(data, "type").String() != "message" {
+			return data
+		}
+		var content []json.RawMessage
+		changed := false
+		gjson.GetBytes(data, "content").ForEach(func(_, block gjson.Result) bool {
+			blockType := block.Get("type").String()
+			switch {
+			case !isAnthropicThinkingBlock(blockType):
+				content = append(content, json.RawMessage(block.Raw))
+			case w.convert && blockType == "thinking":
+				text, _ := json.Marshal(gin.H{"type": "text", "text": reasoningConvertOpen + block.Get("thinking").String() + strings.TrimSuffix(reasoningConvertClose, "\n\n")})
+				content = append(content, text)
+				changed = true
+			default:
+				changed = true
+			}
+			return true
+		})
+		if !changed {
+			return data
+		}
+		if content == nil {
+			content = []json.RawMessage{}
+		}
+		if next, err := sjson.SetBytes(out, "content", content); err == nil {
+			out = next
+		}
+	case reasoningFormatChatCompletions:
+		gjson.GetBytes(data, "choices").ForEach(func(i, choice gjson.Result) bool {
+			prefix := "choices." + i.String() + ".message"
+			reasoning, fields := chatReasoningText(choice.Get("message"))
+			for _, field := range fields {
+				if next, err := sjson.DeleteBytes(out, prefix+"."+field); err == nil {
+					out = next
+				}
+			}
+			if w.convert && reasoning != "" {
+				text := reasoningConvertOpen + reasoning + reasoningConvertClose + choice.Get("message.content").String()
+				if next, err := sjson.SetBytes(out, prefix+".content", text); err == nil {
+					out = next
+				}
+			}
+			return true
+		})
+	case reasoningFormatResponses:
+		out = clearResponsesOutputSummaries(out, "output")
+	}
+	return out
+}
+
+func reasoningEventsFormatForPath(path string) reasoningEventsFormat {
+	switch {
+	case strings.HasSuffix(path, "/messages"):
+		return reasoningFormatAnthropic
+	case strings.HasSuffix(path, "/chat/completions"):
+		return reasoningFormatChatCompletions
+	case strings.HasSuffix(path, "/responses"):
+		return reasoningFormatResponses
+	}
+	return 0
+}
+
+// ReasoningEventsMiddleware 按 gateway.reasoning_events 的客户端画像过滤或转换推理内容，
+// 需注册在输出后处理之外，使后处理仍按上游原始的内容块编号工作。全部透传时为空操作。
+func ReasoningEventsMiddleware(cfg *config.Config) gin.HandlerFunc {
+	var resolver *reasoningEventsResolver
+	if cfg != nil {
+		resolver = newReasoningEventsResolver(cfg.Gateway.ReasoningEvents)
+	}
+	if resolver == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		format := reasoningEventsFormatForPath(c.Request.URL.Path)
+		if format == 0 || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		profile, mode := resolver.resolve(c.GetHeader("User-Agent"))
+		if profile != "" {
+			c.Header(ReasoningEventsProfileHeader, profile)
+		}
+		if mode == config.ReasoningEventsModePassthrough {
+			c.Next()
+			return
+		}
+		originalWriter := c.Writer
+		w := newReasoningEventsWriter(originalWriter, format, mode)
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+		}()
+		c.Next()
+		w.finish()
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func serveReasoningEvents(t *testing.T, eventsCfg config.GatewayReasoningEventsConfig, path, userAgent string, h gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.ReasoningEvents = eventsCfg
+	r := gin.New()
+	r.POST(path, ReasoningEventsMiddleware(cfg), h)
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+	req.Header.Set("User-Agent", userAgent)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func sseHandler(body string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, body)
+	}
+}
+
+const anthropicThinkingStream = "event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\n" +
+	"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"hmm\"}}\n\n" +
+	"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"signature_delta\",\"signature\":\"sig\"}}\n\n" +
+	"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+	"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+	"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n" +
+	"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":1}\n\n"
+
+func TestReasoningEvents_ProfileSelection(t *testing.T) {
+	require.Nil(t, newReasoningEventsResolver(config.GatewayReasoningEventsConfig{}))
+
+	resolver := newReasoningEventsResolver(config.GatewayReasoningEventsConfig{
+		DefaultMode: "filter",
+		Profiles: []config.GatewayReasoningEventsProfile{
+			{Name: "claude-cli", UserAgentPattern: `^claude-cli/`, Mode: "passthrough"},
+			{Name: "legacy", UserAgentPattern: `^OpenAI/Python 0\.`, Mode: "convert"},
+		},
+	})
+	name, mode := resolver.resolve("claude-cli/2.1.0")
+	require.Equal(t, "claude-cli", name)
+	require.Equal(t, config.ReasoningEventsModePassthrough, mode)
+	name, mode = resolver.resolve("OpenAI/Python 0.28.1")
+	require.Equal(t, "legacy", name)
+	require.Equal(t, config.ReasoningEventsModeConvert, mode)
+	name, mode = resolver.resolve("curl/8")
+	require.Empty(t, name)
+	require.Equal(t, config.ReasoningEventsModeFilter, mode)
+}
+
+func TestReasoningEvents_AnthropicStreamFilterRenumbers(t *testing.T) {
+	rec := serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "filter"}, "/v1/messages", "sdk", sseHandler(anthropicThinkingStream))
+	body := rec.Body.String()
+	require.NotContains(t, body, "thinking")
+	require.NotContains(t, body, "signature")
+	require.Contains(t, body, `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`)
+	require.Contains(t, body, `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hi"}}`)
+	require.Contains(t, body, `{"type":"content_block_stop","index":0}`)
+}
+
+func TestReasoningEvents_AnthropicStreamConvert(t *testing.T) {
+	rec := serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "convert"}, "/v1/messages", "sdk", sseHandler(anthropicThinkingStream))
+	var text strings.Builder
+	for _, event := range strings.Split(rec.Body.String(), "\n\n") {
+		_, payload, ok := parseSSEEvent(event)
+		if !ok {
+			continue
+		}
+		parsed := gjson.Parse(payload)
+		require.NotEqual(t, "thinking", parsed.Get("content_block.type").String())
+		if parsed.Get("delta.type").String() == "text_delta" {
+			text.WriteString(parsed.Get("delta.text").String())
+		}
+	}
+	require.Equal(t, "<think>\nhmm\n</think>hi", text.String())
+	require.NotContains(t, rec.Body.String(), "signature")
+}
+
+func TestReasoningEvents_AnthropicJSON(t *testing.T) {
+	h := func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"type":"message","content":[{"type":"thinking","thinking":"hmm","signature":"s"},{"type":"redacted_thinking","data":"x"},{"type":"text","text":"hi"}]}`))
+	}
+	rec := serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "filter"}, "/v1/messages", "sdk", h)
+	require.JSONEq(t, `{"type":"message","content":[{"type":"text","text":"hi"}]}`, rec.Body.String())
+
+	rec = serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "convert"}, "/v1/messages", "sdk", h)
+	require.JSONEq(t, `{"type":"message","content":[{"type":"text","text":"<think>\nhmm\n</think>"},{"type":"text","text":"hi"}]}`, rec.Body.String())
+}
+
+func TestReasoningEvents_ChatCompletionsStream(t *testing.T) {
+	stream := "data: {\"choices\":[{\"index\":0,\"delta\":{\"reasoning_content\":\"a\"}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{\"reasoning_content\":\"b\"}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n" +
+		"data: [DONE]\n\n"
+
+	rec := serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "filter"}, "/v1/chat/completions", "sdk", sseHandler(stream))
+	require.Equal(t, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n"+
+		"data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n"+
+		"data: [DONE]\n\n", rec.Body.String())
+
+	rec = serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "convert"}, "/v1/chat/completions", "sdk", sseHandler(stream))
+	var content strings.Builder
+	for _, event := range strings.Split(rec.Body.String(), "\n\n") {
+		_, payload, ok := parseSSEEvent(event)
+		if !ok || payload == "[DONE]" {
+			continue
+		}
+		require.False(t, gjson.Get(payload, "choices.0.delta.reasoning_content").Exists())
+		content.WriteString(gjson.Get(payload, "choices.0.delta.content").String())
+	}
+	require.Equal(t, "<think>\nab\n</think>\n\nhi", content.String())
+}
+
+func TestReasoningEvents_ChatCompletionsJSON(t *testing.T) {
+	h := func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"hi","reasoning_content":"hmm"}}]}`))
+	}
+	rec := serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "convert"}, "/v1/chat/completions", "sdk", h)
+	require.JSONEq(t, `{"choices":[{"index":0,"message":{"role":"assistant","content":"<think>\nhmm\n</think>\n\nhi"}}]}`, rec.Body.String())
+}
+
+func TestReasoningEvents_ResponsesFilter(t *testing.T) {
+	stream := "event: response.reasoning_summary_text.delta\ndata: {\"type\":\"response.reasoning_summary_text.delta\",\"delta\":\"x\"}\n\n" +
+		"event: response.output_item.done\ndata: {\"type\":\"response.output_item.done\",\"item\":{\"type\":\"reasoning\",\"summary\":[{\"type\":\"summary_text\",\"text\":\"x\"}],\"encrypted_content\":\"enc\"}}\n\n" +
+		"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n"
+	rec := serveReasoningEvents(t, config.GatewayReasoningEventsConfig{DefaultMode: "convert"}, "/v1/responses", "sdk", sseHandler(stream))
+	body := rec.Body.String()
+	require.NotContains(t, body, "reasoning_summary_text")
+	require.Contains(t, body, `"summary":[]`)
+	require.Contains(t, body, `"encrypted_content":"enc"`)
+	require.Contains(t, body, "response.output_text.delta")
+}
+
+func TestReasoningEvents_PassthroughProfileSetsHeader(t *testing.T) {
+	cfg := config.GatewayReasoningEventsConfig{
+		DefaultMode: "filter",
+		Profiles:    []config.GatewayReasoningEventsProfile{{Name: "modern", UserAgentPattern: "^modern/", Mode: "passthrough"}},
+	}
+	rec := serveReasoningEvents(t, cfg, "/v1/messages", "modern/1.0", sseHandler(anthropicThinkingStream))
+	require.Equal(t, "modern", rec.Header().Get(ReasoningEventsProfileHeader))
+	require.Equal(t, anthropicThinkingStream, rec.Body.String())
+}
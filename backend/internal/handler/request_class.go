@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestClassKey         = "request_class"
+	requestClassDeadlineKey = "request_class_deadline_applied"
+)
+
+// setRequestClass 根据客户端声明或流式标记确定请求分类，写入 gin 与请求 context。
+// 由 setOpsRequestContext 在解析出模型后调用；尚未解析请求体（model 为空）时不分类。
+func setRequestClass(c *gin.Context, stream bool) {
+	class := service.ResolveRequestClass(c.GetHeader(service.RequestClassHeader), stream)
+	c.Set(requestClassKey, class)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(service.WithRequestClass(c.Request.Context(), class))
+	}
+}
+
+// requestClassFromGin 返回当前请求分类；未分类时返回空。
+func requestClassFromGin(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	class, _ := c.Get(requestClassKey)
+	s, _ := class.(string)
+	return s
+}
+
+func requestClassPolicy(cfg *config.Config, class string) config.GatewayRequestClassPolicy {
+	if cfg == nil {
+		return config.GatewayRequestClassPolicy{}
+	}
+	switch class {
+	case service.RequestClassInteractive:
+		return cfg.Gateway.RequestClasses.Interactive
+	case service.RequestClassBatch:
+		return cfg.Gateway.RequestClasses.Batch
+	default:
+		return config.GatewayRequestClassPolicy{}
+	}
+}
+
+// applyRequestClassPolicy 在进入账号调度循环前应用请求分类策略：
+// 为请求 context 设置分类整体超时（每个请求只设置一次，WebSocket 会话除外），
+// 并返回分类的账号切换上限，未配置时返回 fallback。
+func applyRequestClassPolicy(c *gin.Context, cfg *config.Config, fallback int) int {
+	policy := requestClassPolicy(cfg, requestClassFromGin(c))
+	if policy.TimeoutSeconds > 0 && c.Request != nil && !c.IsWebsocket() {
+		if _, applied := c.Get(requestClassDeadlineKey); !applied {
+			c.Set(requestClassDeadlineKey, true)
+			parent := c.Request.Context()
+			ctx, cancel := context.WithTimeout(parent, time.Duration(policy.TimeoutSeconds)*time.Second)
+			// 请求结束时 net/http 取消父 context，随之释放定时器
+			context.AfterFunc(parent, cancel)
+			c.Request = c.Request.WithContext(ctx)
+		}
+	}
+	if policy.MaxAccountSwitches > 0 {
+		return policy.MaxAccountSwitches
+	}
+	return fallback
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestClassTestContext(header string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	if header != "" {
+		c.Request.Header.Set(service.RequestClassHeader, header)
+	}
+	return c
+}
+
+func TestSetRequestClass_HeaderOverridesStreamInference(t *testing.T) {
+	c := newRequestClassTestContext("")
+	setRequestClass(c, true)
+	require.Equal(t, service.RequestClassInteractive, requestClassFromGin(c))
+	require.Equal(t, service.RequestClassInteractive, service.RequestClassFromContext(c.Request.Context()))
+
+	c = newRequestClassTestContext("batch")
+	setRequestClass(c, true)
+	require.Equal(t, service.RequestClassBatch, requestClassFromGin(c))
+}
+
+func TestApplyRequestClassPolicy(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.RequestClasses.Batch = config.GatewayRequestClassPolicy{TimeoutSeconds: 600, MaxAccountSwitches: 2}
+
+	t.Run("batch uses class timeout and switch limit", func(t *testing.T) {
+		c := newRequestClassTestContext("batch")
+		setRequestClass(c, false)
+
+		require.Equal(t, 2, applyRequestClassPolicy(c, cfg, 10))
+		deadline, ok := c.Request.Context().Deadline()
+		require.True(t, ok)
+
+		// 同一请求再次进入调度循环时不叠加超时
+		require.Equal(t, 2, applyRequestClassPolicy(c, cfg, 10))
+		again, _ := c.Request.Context().Deadline()
+		require.Equal(t, deadline, again)
+	})
+
+	t.Run("unconfigured class keeps fallback", func(t *testing.T) {
+		c := newRequestClassTestContext("")
+		setRequestClass(c, true)
+
+		require.Equal(t, 10, applyRequestClassPolicy(c, cfg, 10))
+		_, ok := c.Request.Context().Deadline()
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// RequestCoalescedHeader 标记响应复用自同一 Key 并发中的相同请求（未单独请求上游）。
+const RequestCoalescedHeader = "X-Sub2API-Coalesced"
+
+// coalescedResponse 领头请求的完整响应快照。
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	// accountID/model 为产生该响应的账号与模型，用于给复用方记录用量
+	accountID int64
+	model     string
+}
+
+// ReusedResponseUsageRecorder 为直接复用已有响应（合并跟随者、压缩重放、缓存命中）的请求记录零费用用量。
+type ReusedResponseUsageRecorder interface {
+	RecordReusedResponseUsage(c *gin.Context, accountID int64, model string, stream bool, duration time.Duration)
+}
+
+// recordReusedResponseUsage 在复用响应写出后为当前请求记录用量；recorder 为 nil 时不记录。
+func recordReusedResponseUsage(c *gin.Context, recorder ReusedResponseUsageRecorder, resp *coalescedResponse, startedAt time.Time) {
+	if recorder == nil || resp == nil {
+		return
+	}
+	stream := strings.HasPrefix(strings.ToLower(resp.header.Get("Content-Type")), "text/event-stream")
+	recorder.RecordReusedResponseUsage(c, resp.accountID, resp.model, stream, time.Since(startedAt))
+}
+
+// coalescingLeaderSource 读取领头请求选中的账号与模型（由 handler 写入 ops 上下文）。
+func coalescingLeaderSource(c *gin.Context) (accountID int64, model string) {
+	if v, ok := c.Get(opsAccountIDKey); ok {
+		accountID, _ = v.(int64)
+	}
+	if v, ok := c.Get(opsModelKey); ok {
+		model, _ = v.(string)
+	}
+	return accountID, model
+}
+
+type coalescingCall struct {
+	done chan struct{}
+	resp *coalescedResponse // nil 表示结果不可复用，跟随者需自行请求
+}
+
+// requestCoalescer 按请求键合并并发中的相同请求：第一个请求（领头）正常执行，
+// 其余请求等待领头完成后直接复用其响应。与 singleflight 不同，跟随者可以因客户端断开或等待超时单独退出。
+type requestCoalescer struct {
+	mu       sync.Mutex
+	inflight map[string]*coalescingCall
+}
+
+// join 返回请求键对应的进行中调用；leader=true 表示调用方成为领头，需在完成后调用 finish。
+func (g *requestCoalescer) join(key string) (call *coalescingCall, leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if call, ok := g.inflight[key]; ok {
+		return call, false
+	}
+	call = &coalescingCall{done: make(chan struct{})}
+	g.inflight[key] = call
+	return call, true
+}
+
+func (g *requestCoalescer) finish(key string, call *coalescingCall, resp *coalescedResponse) {
+	g.mu.Lock()
+	delete(g.inflight, key)
+	g.mu.Unlock()
+	call.resp = resp
+	close(call.done)
+}
+
+// RequestCoalescingMiddleware 合并同一 API Key 并发发送的完全相同的请求（默认关闭）：
+// 只有领头请求调用上游，并把响应同时分发给等待中的跟随者，避免客户端重试风暴消耗上游配额。
+// 跟随者不产生上游调用，因此不计费，但通过 usage 记录一条零费用用量（request_type=reused）；
+// 超出缓冲上限的响应、领头客户端中途断开等情况下跟随者退回为独立请求。流式请求仅在开启 streaming 时合并。
+func RequestCoalescingMiddleware(cfg *config.Config, usage ReusedResponseUsageRecorder) gin.HandlerFunc {
+	if cfg == nil || !cfg.Gateway.RequestCoalescing.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	waitTimeout := time.Duration(cfg.Gateway.RequestCoalescing.WaitTimeoutSeconds) * time.Second
+	maxBytes := cfg.Gateway.RequestCoalescing.MaxResponseBytes
+	streaming := cfg.Gateway.RequestCoalescing.Streaming
+	group := &requestCoalescer{inflight: make(map[string]*coalescingCall)}
+	streamGroup := &streamCoalescer{inflight: make(map[string]*streamCoalescingCall)}
+
+	return func(c *gin.Context) {
+		startedAt := time.Now()
+		key, stream, ok := requestCoalescingKey(c)
+		if !ok || (stream && !streaming) {
+			c.Next()
+			return
+		}
+		if stream {
+			call, leader := streamGroup.join(key)
+			if leader {
+				streamGroup.finish(key, call, runStreamCoalescingLeader(c, call, maxBytes))
+				return
+			}
+			if resp := followStreamCoalescingCall(c, call, waitTimeout); resp != nil {
+				recordReusedResponseUsage(c, usage, resp, startedAt)
+			}
+			return
+		}
+		call, leader := group.join(key)
+		if leader {
+			group.finish(key, call, runCoalescingLeader(c, maxBytes, false))
+			return
+		}
+
+		timer := time.NewTimer(waitTimeout)
+		defer timer.Stop()
+		select {
+		case <-call.done:
+		case <-c.Request.Context().Done():
+			c.Abort()
+			return
+		case <-timer.C:
+			c.Next()
+			return
+		}
+		if call.resp == nil {
+			c.Next()
+			return
+		}
+		writeCoalescedResponse(c, call.resp)
+		recordReusedResponseUsage(c, usage, call.resp, startedAt)
+		c.Abort()
+	}
+}
+
+// requestCoalescingKey 计算请求键：API Key + 方法 + 路径与查询参数 + 是否流式 + 规范化后的请求体哈希。
+// 非 POST 或未鉴权的请求返回 ok=false。
+func requestCoalescingKey(c *gin.Context) (key string, stream bool, ok bool) {
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return "", false, false
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		return "", false, false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return "", false, false
+	}
+	stream = isStreamingCoalescingRequest(c, body)
+
+	sum := sha256.New()
+	sum.Write([]byte(strconv.FormatInt(apiKey.ID, 10)))
+	sum.Write([]byte{0})
+	sum.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery + " " + strconv.FormatBool(stream)))
+	sum.Write([]byte{0})
+	sum.Write(canonicalCoalescingBody(body))
+	return hex.EncodeToString(sum.Sum(nil)), stream, true
+}
+
+func isStreamingCoalescingRequest(c *gin.Context, body []byte) bool {
+	if gjson.GetBytes(body, "stream").Bool() {
+		return true
+	}
+	if strings.Contains(c.Request.URL.Path, "streamGenerateContent") || c.Query("alt") == "sse" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(c.GetHeader("Accept")), "text/event-stream")
+}
+
+// canonicalCoalescingBody 重新序列化 JSON（键排序、去除空白），使字段顺序不同的相同请求得到同一键；
+// 非 JSON 请求体按原始字节参与哈希。
+func canonicalCoalescingBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return body
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// runCoalescingLeader 执行领头请求并返回可复用的响应快照；不可复用时返回 nil。
+// allowEventStream 为 true 时也复用 SSE 响应（调用方需保证领头结束时流已完整）。
+func runCoalescingLeader(c *gin.Context, maxBytes int64, allowEventStream bool) *coalescedResponse {
+	originalWriter := c.Writer
+	w := &coalescingCaptureWriter{ResponseWriter: originalWriter, max: maxBytes}
+	c.Writer = w
+	defer func() {
+		if c.Writer == w {
+			c.Writer = originalWriter
+		}
+	}()
+	c.Next()
+
+	if w.overflow || w.hijacked || !w.ResponseWriter.Written() || c.Request.Context().Err() != nil {
+		return nil
+	}
+	header := w.ResponseWriter.Header().Clone()
+	if !allowEventStream && strings.HasPrefix(strings.ToLower(header.Get("Content-Type")), "text/event-stream") {
+		return nil
+	}
+	accountID, model := coalescingLeaderSource(c)
+	return &coalescedResponse{status: w.ResponseWriter.Status(), header: header, body: w.buf.Bytes(), accountID: accountID, model: model}
+}
+
+func writeCoalescedResponse(c *gin.Context, resp *coalescedResponse) {
+	dst := c.Writer.Header()
+	for k, values := range resp.header {
+		if k == "Content-Length" || dst.Get(k) != "" {
+			continue
+		}
+		dst[k] = append([]string(nil), values...)
+	}
+	dst.Set(RequestCoalescedHeader, "true")
+	c.Writer.WriteHeader(resp.status)
+	if len(resp.body) > 0 {
+		_, _ = c.Writer.Write(resp.body)
+	}
+}
+
+// coalescingCaptureWriter 透传写出的同时缓存响应体，超过上限后停止缓存并标记不可复用。
+type coalescingCaptureWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	max      int64
+	overflow bool
+	hijacked bool
+}
+
+func (w *coalescingCaptureWriter) capture(b []byte) {
+	if w.overflow {
+		return
+	}
+	if int64(w.buf.Len()+len(b)) > w.max {
+		w.overflow = true
+		w.buf = bytes.Buffer{}
+		return
+	}
+	w.buf.Write(b)
+}
+
+func (w *coalescingCaptureWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.capture(b[:n])
+	return n, err
+}
+
+func (w *coalescingCaptureWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *coalescingCaptureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.ResponseWriter.Hijack()
+}
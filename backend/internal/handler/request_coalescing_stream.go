@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamCoalescingCall 是进行中的流式领头请求：领头写出的字节实时追加到 buf，
+// 跟随者按各自的偏移量读取并转发，因此中途加入的跟随者也能拿到完整的事件流。
+type streamCoalescingCall struct {
+	mu       sync.Mutex
+	status   int
+	header   http.Header
+	buf      []byte
+	started  chan struct{} // 领头首次写出响应体后关闭
+	changed  chan struct{} // 每次追加或结束时关闭并替换，用于唤醒跟随者
+	finished bool
+	// broken 表示跟随者无法获得完整响应：超过缓冲上限、连接被劫持或领头异常结束
+	broken bool
+	// followers 为正在转发的跟随者数量，leaderGone 表示领头客户端已断开。
+	// 上游请求运行在与领头客户端解耦的 context 上，只有领头断开且没有跟随者在接收时才取消。
+	followers  int
+	leaderGone bool
+	cancel     context.CancelFunc
+	// accountID/model 为领头请求选中的账号与模型，首次写出时记录，用于给跟随者记录用量
+	accountID int64
+	model     string
+}
+
+type streamCoalescer struct {
+	mu       sync.Mutex
+	inflight map[string]*streamCoalescingCall
+}
+
+func (g *streamCoalescer) join(key string) (call *streamCoalescingCall, leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if call, ok := g.inflight[key]; ok {
+		return call, false
+	}
+	call = &streamCoalescingCall{started: make(chan struct{}), changed: make(chan struct{})}
+	g.inflight[key] = call
+	return call, true
+}
+
+func (g *streamCoalescer) finish(key string, call *streamCoalescingCall, broken bool) {
+	g.mu.Lock()
+	delete(g.inflight, key)
+	g.mu.Unlock()
+
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	call.finished = true
+	call.broken = call.broken || broken
+	if call.status == 0 {
+		close(call.started)
+	}
+	close(call.changed)
+}
+
+func (call *streamCoalescingCall) setSource(accountID int64, model string) {
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	call.accountID, call.model = accountID, model
+}
+
+// append 把领头写出的字节追加到共享缓冲；返回是否仍有跟随者在接收。
+func (call *streamCoalescingCall) append(status int, header http.Header, b []byte, max int64) bool {
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	if call.status == 0 {
+		call.status = status
+		call.header = header.Clone()
+		close(call.started)
+	}
+	if call.broken {
+		return false
+	}
+	if int64(len(call.buf)+len(b)) > max {
+		call.broken = true
+		call.buf = nil
+	} else {
+		call.buf = append(call.buf, b...)
+	}
+	close(call.changed)
+	call.changed = make(chan struct{})
+	return !call.broken && call.followers > 0
+}
+
+// attach 登记一个开始转发的跟随者；共享已中断时返回 false。
+func (call *streamCoalescingCall) attach() bool {
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	if call.broken {
+		return false
+	}
+	call.followers++
+	return true
+}
+
+func (call *streamCoalescingCall) detach() {
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	call.followers--
+	if call.followers == 0 && call.leaderGone {
+		call.abortLocked()
+	}
+}
+
+// leaderDisconnected 标记领头客户端已断开；没有跟随者在接收时取消上游请求。
+func (call *streamCoalescingCall) leaderDisconnected() {
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	call.leaderGone = true
+	if call.followers == 0 {
+		call.abortLocked()
+	}
+}
+
+func (call *streamCoalescingCall) abortLocked() {
+	if call.cancel != nil {
+		call.cancel()
+	}
+	if call.broken {
+		return
+	}
+	call.broken = true
+	if !call.finished {
+		close(call.changed)
+		call.changed = make(chan struct{})
+	}
+}
+
+// runStreamCoalescingLeader 执行流式领头请求，写出的同时分发给跟随者；返回 true 表示结果不完整。
+// 上游请求使用与领头客户端解耦的 context：领头客户端断开后，只要还有跟随者在接收就继续读完上游，
+// 避免一个客户端断开切断所有跟随者。
+func runStreamCoalescingLeader(c *gin.Context, call *streamCoalescingCall, maxBytes int64) bool {
+	clientCtx := c.Request.Context()
+	ctx, cancel := context.WithCancel(context.WithoutCancel(clientCtx))
+	defer cancel()
+	call.mu.Lock()
+	call.cancel = cancel
+	call.mu.Unlock()
+	stop := context.AfterFunc(clientCtx, call.leaderDisconnected)
+	defer stop()
+	c.Request = c.Request.WithContext(ctx)
+
+	originalWriter := c.Writer
+	w := &streamTeeWriter{ResponseWriter: originalWriter, call: call, max: maxBytes, leader: c}
+	c.Writer = w
+	defer func() {
+		if c.Writer == w {
+			c.Writer = originalWriter
+		}
+	}()
+	c.Next()
+	return w.hijacked || !w.ResponseWriter.Written() || ctx.Err() != nil
+}
+
+// followStreamCoalescingCall 等待领头开始输出后转发其事件流。领头在首个字节前失败、
+// 等待超时或共享已中断时，跟随者退回为独立请求；已开始转发后共享中断（超过缓冲上限、上游异常结束），
+// 跟随者按入口协议写入终止错误事件后结束，避免客户端把截断的 200 流当作完整响应。
+// 返回跟随者已复用的响应来源（用于记录用量），退回独立请求或未转发时返回 nil。
+func followStreamCoalescingCall(c *gin.Context, call *streamCoalescingCall, waitTimeout time.Duration) *coalescedResponse {
+	timer := time.NewTimer(waitTimeout)
+	defer timer.Stop()
+	select {
+	case <-call.started:
+	case <-c.Request.Context().Done():
+		c.Abort()
+		return nil
+	case <-timer.C:
+		c.Next()
+		return nil
+	}
+
+	call.mu.Lock()
+	status := call.status
+	header := call.header
+	reused := &coalescedResponse{status: status, header: header, accountID: call.accountID, model: call.model}
+	call.mu.Unlock()
+	if status == 0 || !call.attach() {
+		c.Next()
+		return nil
+	}
+	defer call.detach()
+	c.Abort()
+
+	dst := c.Writer.Header()
+	for k, values := range header {
+		if k == "Content-Length" || dst.Get(k) != "" {
+			continue
+		}
+		dst[k] = append([]string(nil), values...)
+	}
+	dst.Set(RequestCoalescedHeader, "true")
+	c.Writer.WriteHeader(status)
+
+	offset := 0
+	for {
+		call.mu.Lock()
+		chunk, changed, finished, broken := call.buf[min(offset, len(call.buf)):], call.changed, call.finished, call.broken
+		call.mu.Unlock()
+		// 超过缓冲上限时 buf 已清空，chunk 为空
+		if len(chunk) > 0 {
+			if _, err := c.Writer.Write(chunk); err != nil {
+				return reused
+			}
+			c.Writer.Flush()
+			offset += len(chunk)
+		}
+		if broken {
+			writeRouteStreamError(c, http.StatusBadGateway, "upstream_error", "Shared upstream stream was interrupted before completion")
+			return reused
+		}
+		if finished {
+			return reused
+		}
+		select {
+		case <-changed:
+		case <-c.Request.Context().Done():
+			return reused
+		}
+	}
+}
+
+// streamTeeWriter 透传写出的同时把字节追加到领头调用的共享缓冲。
+// 领头客户端写失败后，只要还有跟随者在接收，就对 handler 隐藏写错误，使其照常读完上游并分发。
+type streamTeeWriter struct {
+	gin.ResponseWriter
+	call      *streamCoalescingCall
+	max       int64
+	hijacked  bool
+	clientErr error
+	leader    *gin.Context
+	sourced   bool
+}
+
+func (w *streamTeeWriter) Write(b []byte) (int, error) {
+	if !w.sourced {
+		w.sourced = true
+		w.call.setSource(coalescingLeaderSource(w.leader))
+	}
+	if w.clientErr == nil {
+		n, err := w.ResponseWriter.Write(b)
+		if err == nil {
+			w.call.append(w.ResponseWriter.Status(), w.ResponseWriter.Header(), b, w.max)
+			return n, nil
+		}
+		w.clientErr = err
+		w.call.leaderDisconnected()
+	}
+	if !w.call.append(w.ResponseWriter.Status(), w.ResponseWriter.Header(), b, w.max) {
+		return 0, w.clientErr
+	}
+	return len(b), nil
+}
+
+func (w *streamTeeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *streamTeeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.ResponseWriter.Hijack()
+}
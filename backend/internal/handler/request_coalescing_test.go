@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestCoalescingRouter(enabled bool, h gin.HandlerFunc) *gin.Engine {
+	return newRequestCoalescingRouterWithConfig(config.GatewayRequestCoalescingConfig{Enabled: enabled, WaitTimeoutSeconds: 5, MaxResponseBytes: 1 << 20}, h)
+}
+
+func newRequestCoalescingRouterWithConfig(coalescing config.GatewayRequestCoalescingConfig, h gin.HandlerFunc) *gin.Engine {
+	return newRequestCoalescingRouterWithUsage(coalescing, nil, h)
+}
+
+func newRequestCoalescingRouterWithUsage(coalescing config.GatewayRequestCoalescingConfig, usage ReusedResponseUsageRecorder, h gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.RequestCoalescing = coalescing
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		id := int64(1)
+		if c.GetHeader("X-Test-Key") == "2" {
+			id = 2
+		}
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: id})
+		c.Next()
+	})
+	r.POST("/v1/messages", RequestCoalescingMiddleware(cfg, usage), h)
+	return r
+}
+
+// serveConcurrently 并发发送 n 个请求；release 关闭前 handler 阻塞，保证请求在同一时间窗口内到达。
+func serveConcurrently(r *gin.Engine, n int, build func(i int) *http.Request, arrived *atomic.Int32, release chan struct{}) []*httptest.ResponseRecorder {
+	recs := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		recs[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			arrived.Add(1)
+			r.ServeHTTP(recs[i], build(i))
+		}(i)
+	}
+	for arrived.Load() < int32(n) {
+		time.Sleep(time.Millisecond)
+	}
+	// 给跟随者留出加入等待的时间
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return recs
+}
+
+func TestRequestCoalescing_ConcurrentIdenticalRequests(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := newRequestCoalescingRouter(true, func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		c.Header("X-Upstream", "1")
+		c.JSON(http.StatusOK, gin.H{"id": "msg_1"})
+	})
+
+	var arrived atomic.Int32
+	recs := serveConcurrently(r, 5, func(i int) *http.Request {
+		// 字段顺序不同也视为相同请求
+		body := `{"model":"claude","max_tokens":10}`
+		if i%2 == 1 {
+			body = `{"max_tokens":10, "model":"claude"}`
+		}
+		return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	}, &arrived, release)
+
+	require.Equal(t, int32(1), calls.Load())
+	coalesced := 0
+	for _, rec := range recs {
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"id":"msg_1"}`, rec.Body.String())
+		require.Equal(t, "1", rec.Header().Get("X-Upstream"))
+		if rec.Header().Get(RequestCoalescedHeader) == "true" {
+			coalesced++
+		}
+	}
+	require.Equal(t, 4, coalesced)
+}
+
+func TestRequestCoalescing_NotCoalesced(t *testing.T) {
+	cases := []struct {
+		name    string
+		enabled bool
+		build   func(i int) *http.Request
+	}{
+		{"disabled", false, func(i int) *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude"}`))
+		}},
+		{"stream", true, func(i int) *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","stream":true}`))
+		}},
+		{"different_keys", true, func(i int) *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude"}`))
+			if i == 1 {
+				req.Header.Set("X-Test-Key", "2")
+			}
+			return req
+		}},
+		{"different_bodies", true, func(i int) *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","n":`+string(rune('0'+i))+`}`))
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			release := make(chan struct{})
+			r := newRequestCoalescingRouter(tc.enabled, func(c *gin.Context) {
+				calls.Add(1)
+				<-release
+				c.JSON(http.StatusOK, gin.H{"ok": true})
+			})
+			var arrived atomic.Int32
+			recs := serveConcurrently(r, 2, tc.build, &arrived, release)
+			require.Equal(t, int32(2), calls.Load())
+			for _, rec := range recs {
+				require.Empty(t, rec.Header().Get(RequestCoalescedHeader))
+			}
+		})
+	}
+}
+
+func TestRequestCoalescing_StreamingResponseNotShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := newRequestCoalescingRouter(true, func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "data: {}\n\n")
+	})
+	var arrived atomic.Int32
+	recs := serveConcurrently(r, 2, func(int) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude"}`))
+	}, &arrived, release)
+	require.Equal(t, int32(2), calls.Load())
+	for _, rec := range recs {
+		require.Equal(t, "data: {}\n\n", rec.Body.String())
+	}
+}
+
+func TestRequestCoalescing_StreamingTee(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := newRequestCoalescingRouterWithConfig(config.GatewayRequestCoalescingConfig{Enabled: true, WaitTimeoutSeconds: 5, MaxResponseBytes: 1 << 20, Streaming: true}, func(c *gin.Context) {
+		calls.Add(1)
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+		<-release
+		_, _ = c.Writer.WriteString("data: 2\n\n")
+	})
+	var arrived atomic.Int32
+	recs := serveConcurrently(r, 3, func(int) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","stream":true}`))
+	}, &arrived, release)
+
+	require.Equal(t, int32(1), calls.Load())
+	coalesced := 0
+	for _, rec := range recs {
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+		require.Equal(t, "data: 1\n\ndata: 2\n\n", rec.Body.String())
+		if rec.Header().Get(RequestCoalescedHeader) == "true" {
+			coalesced++
+		}
+	}
+	require.Equal(t, 2, coalesced)
+}
+
+func TestRequestCoalescing_StreamingOverflowStopsSharing(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := newRequestCoalescingRouterWithConfig(config.GatewayRequestCoalescingConfig{Enabled: true, WaitTimeoutSeconds: 5, MaxResponseBytes: 8, Streaming: true}, func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "data: too long for the buffer\n\n")
+	})
+	var arrived atomic.Int32
+	recs := serveConcurrently(r, 2, func(int) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude"}`))
+		req.Header.Set("Accept", "text/event-stream")
+		return req
+	}, &arrived, release)
+
+	// 首个字节前已超出上限，跟随者退回为独立请求
+	require.Equal(t, int32(2), calls.Load())
+	for _, rec := range recs {
+		require.Equal(t, "data: too long for the buffer\n\n", rec.Body.String())
+		require.Empty(t, rec.Header().Get(RequestCoalescedHeader))
+	}
+}
+
+func TestRequestCoalescing_StreamingOverflowMidStreamEndsFollowerWithError(t *testing.T) {
+	release := make(chan struct{})
+	r := newRequestCoalescingRouterWithConfig(config.GatewayRequestCoalescingConfig{Enabled: true, WaitTimeoutSeconds: 5, MaxResponseBytes: 16, Streaming: true}, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+		<-release
+		_, _ = c.Writer.WriteString("data: too long for the buffer\n\n")
+	})
+	var arrived atomic.Int32
+	recs := serveConcurrently(r, 2, func(int) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","stream":true}`))
+	}, &arrived, release)
+
+	var follower *httptest.ResponseRecorder
+	for _, rec := range recs {
+		if rec.Header().Get(RequestCoalescedHeader) == "true" {
+			follower = rec
+		} else {
+			require.Equal(t, "data: 1\n\ndata: too long for the buffer\n\n", rec.Body.String())
+		}
+	}
+	require.NotNil(t, follower)
+	body := follower.Body.String()
+	require.True(t, strings.HasPrefix(body, "data: 1\n\nevent: error\ndata: "), body)
+	require.Contains(t, body, `"type":"error"`)
+	require.Contains(t, body, `"type":"upstream_error"`)
+}
+
+func TestRequestCoalescing_StreamingLeaderDisconnectKeepsFollowers(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	upstreamCtxErr := make(chan error, 1)
+	r := newRequestCoalescingRouterWithConfig(config.GatewayRequestCoalescingConfig{Enabled: true, WaitTimeoutSeconds: 5, MaxResponseBytes: 1 << 20, Streaming: true}, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+		close(entered)
+		<-release
+		upstreamCtxErr <- c.Request.Context().Err()
+		_, _ = c.Writer.WriteString("data: 2\n\n")
+	})
+	newReq := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","stream":true}`))
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		r.ServeHTTP(httptest.NewRecorder(), newReq().WithContext(leaderCtx))
+	}()
+	<-entered
+
+	follower := httptest.NewRecorder()
+	followerDone := make(chan struct{})
+	go func() {
+		defer close(followerDone)
+		r.ServeHTTP(follower, newReq())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancelLeader()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	<-leaderDone
+	<-followerDone
+
+	require.NoError(t, <-upstreamCtxErr, "leader client disconnect must not cancel the shared upstream request")
+	require.Equal(t, "true", follower.Header().Get(RequestCoalescedHeader))
+	require.Equal(t, "data: 1\n\ndata: 2\n\n", follower.Body.String())
+}
+
+func TestRequestCoalescing_FollowersRecordReusedUsage(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		release := make(chan struct{})
+		usage := &reusedUsageRecorderStub{}
+		r := newRequestCoalescingRouterWithUsage(config.GatewayRequestCoalescingConfig{Enabled: true, WaitTimeoutSeconds: 5, MaxResponseBytes: 1 << 20, Streaming: true}, usage, func(c *gin.Context) {
+			setOpsRequestContext(c, "claude", stream)
+			setOpsSelectedAccount(c, 7)
+			if stream {
+				c.Header("Content-Type", "text/event-stream")
+				c.Status(http.StatusOK)
+				_, _ = c.Writer.WriteString("data: 1\n\n")
+				c.Writer.Flush()
+				<-release
+				_, _ = c.Writer.WriteString("data: 2\n\n")
+				return
+			}
+			<-release
+			c.JSON(http.StatusOK, gin.H{"id": "msg_1"})
+		})
+		var arrived atomic.Int32
+		serveConcurrently(r, 3, func(int) *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","stream":`+strconv.FormatBool(stream)+`}`))
+		}, &arrived, release)
+
+		want := reusedUsageRecord{apiKeyID: 1, accountID: 7, model: "claude", stream: stream}
+		require.Equal(t, []reusedUsageRecord{want, want}, usage.snapshot(), "stream=%v", stream)
+	}
+}
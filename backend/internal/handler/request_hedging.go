@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	hedgeLatencySamplesPerModel = 256
+	hedgeMaxTrackedModels       = 256
+)
+
+// requestHedger 维护对冲所需的响应头耗时样本与每个 API Key 的对冲预算（进程内）。
+type requestHedger struct {
+	cfg config.GatewayHedgingConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	samples map[string]*hedgeLatencyRing
+	budgets map[int64]*hedgeBudget
+}
+
+type hedgeLatencyRing struct {
+	values []time.Duration
+	next   int
+}
+
+type hedgeBudget struct {
+	window time.Time
+	used   int
+}
+
+// newRequestHedger 未启用对冲时返回 nil。
+func newRequestHedger(cfg *config.Config) *requestHedger {
+	if cfg == nil || !cfg.Gateway.Hedging.Enabled {
+		return nil
+	}
+	return &requestHedger{
+		cfg:     cfg.Gateway.Hedging,
+		now:     time.Now,
+		samples: make(map[string]*hedgeLatencyRing),
+		budgets: make(map[int64]*hedgeBudget),
+	}
+}
+
+// observe 记录一次上游响应头耗时。
+func (h *requestHedger) observe(model string, d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ring := h.samples[model]
+	if ring == nil {
+		if len(h.samples) >= hedgeMaxTrackedModels {
+			return
+		}
+		ring = &hedgeLatencyRing{values: make([]time.Duration, 0, hedgeLatencySamplesPerModel)}
+		h.samples[model] = ring
+	}
+	if len(ring.values) < hedgeLatencySamplesPerModel {
+		ring.values = append(ring.values, d)
+		return
+	}
+	ring.values[ring.next] = d
+	ring.next = (ring.next + 1) % hedgeLatencySamplesPerModel
+}
+
+// delay 返回该模型的对冲延迟；样本不足时 ok=false。
+func (h *requestHedger) delay(model string) (time.Duration, bool) {
+	h.mu.Lock()
+	ring := h.samples[model]
+	if ring == nil || len(ring.values) < h.cfg.MinSamples {
+		h.mu.Unlock()
+		return 0, false
+	}
+	values := append([]time.Duration(nil), ring.values...)
+	h.mu.Unlock()
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	idx := int(float64(len(values)-1) * h.cfg.DelayPercentile)
+	d := values[idx]
+	d = max(d, time.Duration(h.cfg.MinDelayMs)*time.Millisecond)
+	d = min(d, time.Duration(h.cfg.MaxDelayMs)*time.Millisecond)
+	return d, true
+}
+
+// allow 消耗一次 API Key 的对冲预算（按自然分钟窗口计数）。
+func (h *requestHedger) allow(apiKeyID int64) bool {
+	window := h.now().Truncate(time.Minute)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	budget := h.budgets[apiKeyID]
+	if budget == nil || !budget.window.Equal(window) {
+		if budget == nil && len(h.budgets) > 0 {
+			// 顺带清理过期窗口，避免长期运行后 map 只增不减
+			for id, b := range h.budgets {
+				if !b.window.Equal(window) {
+					delete(h.budgets, id)
+				}
+			}
+		}
+		budget = &hedgeBudget{window: window}
+		h.budgets[apiKeyID] = budget
+	}
+	if budget.used >= h.cfg.BudgetPerMinute {
+		return false
+	}
+	budget.used++
+	return true
+}
+
+// hedgeAttempt 一次在影子 context 上执行的 Forward；响应先写入缓冲，胜出后再复制到真实响应。
+type hedgeAttempt struct {
+	account  *service.Account
+	shadow   *gin.Context
+	writer   *hedgeResponseWriter
+	cancel   context.CancelFunc
+	start    time.Time
+	accepted chan struct{}
+	done     chan struct{}
+	result   *service.ForwardResult
+	err      error
+}
+
+func newHedgeAttempt(ctx context.Context, c *gin.Context, account *service.Account, parsed *service.ParsedRequest) *hedgeAttempt {
+	attemptCtx, cancel := context.WithCancel(ctx)
+	shadow := c.Copy()
+	shadow.Request = c.Request.Clone(attemptCtx)
+	writer := newHedgeResponseWriter()
+	shadow.Writer = writer
+	a := &hedgeAttempt{
+		account:  account,
+		shadow:   shadow,
+		writer:   writer,
+		cancel:   cancel,
+		accepted: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	var once sync.Once
+	onAccepted := parsed.OnUpstreamAccepted
+	parsed.OnUpstreamAccepted = func() {
+		if onAccepted != nil {
+			onAccepted()
+		}
+		once.Do(func() { close(a.accepted) })
+	}
+	return a
+}
+
+func (a *hedgeAttempt) run(gatewayService *service.GatewayService, parsed *service.ParsedRequest, release func()) {
+	a.start = time.Now()
+	go func() {
+		defer close(a.done)
+		defer a.cancel()
+		if release != nil {
+			defer release()
+		}
+		a.result, a.err = gatewayService.Forward(a.shadow.Request.Context(), a.shadow, a.account, parsed)
+	}()
+}
+
+// commit 把胜出尝试的 context 键与缓冲响应写回真实请求。
+func (a *hedgeAttempt) commit(c *gin.Context) {
+	for k, v := range a.shadow.Keys {
+		c.Set(k, v)
+	}
+	setOpsSelectedAccount(c, a.account.ID, a.account.Platform)
+	setOpsSelectedProxy(c, a.account)
+	if !a.writer.Written() {
+		return
+	}
+	dst := c.Writer.Header()
+	for k, values := range a.writer.Header() {
+		dst[k] = values
+	}
+	c.Writer.WriteHeader(a.writer.Status())
+	if a.writer.body.Len() > 0 {
+		_, _ = c.Writer.Write(a.writer.body.Bytes())
+	}
+}
+
+// forwardWithHedge 在影子 context 上执行主请求；主请求在分位数延迟内未收到上游响应头时，
+// 向另一个空闲账号发送同一请求，采用先收到响应头的一方并取消另一方。
+// 返回实际产生结果的账号；未触发对冲时即为主账号。
+func (h *GatewayHandler) forwardWithHedge(
+	ctx context.Context,
+	c *gin.Context,
+	apiKey *service.APIKey,
+	primary *service.Account,
+	primaryReq *service.ParsedRequest,
+	hedgeBase *service.ParsedRequest,
+	excludedIDs map[int64]struct{},
+	userID int64,
+	reqLog *zap.Logger,
+) (*service.ForwardResult, *service.Account, error) {
+	model := primaryReq.Model
+	delay, ok := h.hedger.delay(model)
+	if !ok {
+		// 样本不足：直接在真实 context 上转发，仅采集响应头耗时
+		start := time.Now()
+		onAccepted := primaryReq.OnUpstreamAccepted
+		primaryReq.OnUpstreamAccepted = func() {
+			h.hedger.observe(model, time.Since(start))
+			if onAccepted != nil {
+				onAccepted()
+			}
+		}
+		result, err := h.gatewayService.Forward(ctx, c, primary, primaryReq)
+		return result, primary, err
+	}
+
+	first := newHedgeAttempt(ctx, c, primary, primaryReq)
+	first.run(h.gatewayService, primaryReq, nil)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-first.accepted:
+		h.hedger.observe(model, time.Since(first.start))
+		return commitHedgeAttempt(c, first)
+	case <-first.done:
+		return commitHedgeAttempt(c, first)
+	case <-timer.C:
+	}
+
+	second := h.startHedgeAttempt(ctx, c, apiKey, primary.ID, hedgeBase, excludedIDs, userID)
+	if second == nil {
+		return commitHedgeAttempt(c, first)
+	}
+	reqLog.Info("gateway.hedge_started",
+		zap.Int64("primary_account_id", primary.ID),
+		zap.Int64("hedge_account_id", second.account.ID),
+		zap.Duration("delay", delay),
+	)
+
+	// 先拿到成功响应头的一方胜出；一方失败结束时等待另一方
+	winner, loser := first, second
+	primaryFailed := false
+	select {
+	case <-first.accepted:
+		h.hedger.observe(model, time.Since(first.start))
+	case <-second.accepted:
+		winner, loser = second, first
+	case <-first.done:
+		if first.err != nil {
+			winner, loser = second, first
+			primaryFailed = true
+		}
+	case <-second.done:
+		if second.err == nil {
+			winner, loser = second, first
+		}
+	}
+	loser.cancel()
+	<-winner.done
+	<-loser.done
+	// 两者都失败时沿用主请求的结果，保持既有 failover 语义
+	if primaryFailed && winner.err != nil {
+		winner = first
+	}
+	reqLog.Info("gateway.hedge_finished",
+		zap.Int64("winner_account_id", winner.account.ID),
+		zap.Bool("hedge_won", winner == second),
+	)
+	return commitHedgeAttempt(c, winner)
+}
+
+func commitHedgeAttempt(c *gin.Context, a *hedgeAttempt) (*service.ForwardResult, *service.Account, error) {
+	<-a.done
+	a.commit(c)
+	return a.result, a.account, a.err
+}
+
+// startHedgeAttempt 选择并占用另一个账号的并发槽位（不排队），启动对冲请求；无可用账号或预算耗尽时返回 nil。
+func (h *GatewayHandler) startHedgeAttempt(
+	ctx context.Context,
+	c *gin.Context,
+	apiKey *service.APIKey,
+	primaryID int64,
+	hedgeBase *service.ParsedRequest,
+	excludedIDs map[int64]struct{},
+	userID int64,
+) *hedgeAttempt {
+	if ctx.Err() != nil || !h.hedger.allow(apiKey.ID) {
+		return nil
+	}
+	excluded := make(map[int64]struct{}, len(excludedIDs)+1)
+	for id := range excludedIDs {
+		excluded[id] = struct{}{}
+	}
+	excluded[primaryID] = struct{}{}
+	selection, err := h.gatewayService.SelectAccountWithLoadAwareness(ctx, apiKey.GroupID, "", hedgeBase.Model, excluded, hedgeBase.MetadataUserID, userID)
+	if err != nil || selection == nil || selection.Account == nil {
+		return nil
+	}
+	if !selection.Acquired {
+		return nil
+	}
+	account := selection.Account
+	if account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
+		if selection.ReleaseFunc != nil {
+			selection.ReleaseFunc()
+		}
+		return nil
+	}
+	parsed, err := hedgeBase.CloneForBody(hedgeBase.Body.Bytes())
+	if err != nil {
+		if selection.ReleaseFunc != nil {
+			selection.ReleaseFunc()
+		}
+		return nil
+	}
+	a := newHedgeAttempt(ctx, c, account, parsed)
+	if err := parsed.ReplaceBody(h.gatewayService.ApplyBedrockCCCompat(a.shadow, parsed.Body.Bytes(), parsed.Model, account, apiKey.GroupID)); err != nil {
+		a.cancel()
+		if selection.ReleaseFunc != nil {
+			selection.ReleaseFunc()
+		}
+		return nil
+	}
+	a.run(h.gatewayService, parsed, selection.ReleaseFunc)
+	return a
+}
+
+// hedgeResponseWriter 影子 context 使用的内存响应。
+type hedgeResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func newHedgeResponseWriter() *hedgeResponseWriter {
+	return &hedgeResponseWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (w *hedgeResponseWriter) Header() http.Header { return w.header }
+
+func (w *hedgeResponseWriter) WriteHeader(code int) {
+	if !w.wrote && code > 0 {
+		w.status = code
+	}
+}
+
+func (w *hedgeResponseWriter) WriteHeaderNow() { w.wrote = true }
+
+func (w *hedgeResponseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.body.Write(b)
+}
+
+func (w *hedgeResponseWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *hedgeResponseWriter) Status() int   { return w.status }
+func (w *hedgeResponseWriter) Size() int     { return w.body.Len() }
+func (w *hedgeResponseWriter) Written() bool { return w.wrote }
+func (w *hedgeResponseWriter) Flush()        {}
+
+func (w *hedgeResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hedge response writer does not support hijack")
+}
+
+func (w *hedgeResponseWriter) CloseNotify() <-chan bool { return make(chan bool) }
+
+func (w *hedgeResponseWriter) Pusher() http.Pusher { return nil }
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRequestHedger(t *testing.T) *requestHedger {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Gateway.Hedging = config.GatewayHedgingConfig{
+		Enabled:         true,
+		DelayPercentile: 0.9,
+		MinSamples:      10,
+		MinDelayMs:      100,
+		MaxDelayMs:      5000,
+		BudgetPerMinute: 2,
+	}
+	h := newRequestHedger(cfg)
+	require.NotNil(t, h)
+	return h
+}
+
+func TestRequestHedger_Disabled(t *testing.T) {
+	require.Nil(t, newRequestHedger(nil))
+	require.Nil(t, newRequestHedger(&config.Config{}))
+}
+
+func TestRequestHedger_DelayPercentile(t *testing.T) {
+	h := newTestRequestHedger(t)
+
+	for i := 1; i <= 9; i++ {
+		h.observe("claude", time.Duration(i)*100*time.Millisecond)
+	}
+	_, ok := h.delay("claude")
+	require.False(t, ok, "not enough samples")
+
+	h.observe("claude", time.Second)
+	d, ok := h.delay("claude")
+	require.True(t, ok)
+	require.Equal(t, 900*time.Millisecond, d)
+
+	// 上下限
+	for i := 0; i < 10; i++ {
+		h.observe("fast", time.Millisecond)
+		h.observe("slow", time.Minute)
+	}
+	d, _ = h.delay("fast")
+	require.Equal(t, 100*time.Millisecond, d)
+	d, _ = h.delay("slow")
+	require.Equal(t, 5*time.Second, d)
+
+	// 环形缓冲只保留最近样本
+	for i := 0; i < hedgeLatencySamplesPerModel; i++ {
+		h.observe("claude", 200*time.Millisecond)
+	}
+	d, _ = h.delay("claude")
+	require.Equal(t, 200*time.Millisecond, d)
+}
+
+func TestRequestHedger_BudgetPerKey(t *testing.T) {
+	h := newTestRequestHedger(t)
+	now := time.Date(2026, 10, 14, 12, 0, 10, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	require.True(t, h.allow(1))
+	require.True(t, h.allow(1))
+	require.False(t, h.allow(1))
+	require.True(t, h.allow(2), "budgets are per API key")
+
+	now = now.Add(time.Minute)
+	require.True(t, h.allow(1), "budget resets every minute")
+}
+
+func TestHedgeAttempt_CommitCopiesBufferedResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Set("existing", 1)
+
+	a := newHedgeAttempt(c.Request.Context(), c, &service.Account{ID: 9, Platform: service.PlatformAnthropic}, &service.ParsedRequest{})
+	a.shadow.Set("from_attempt", "yes")
+	a.shadow.Header("X-Upstream", "1")
+	a.shadow.JSON(http.StatusCreated, gin.H{"id": "msg_1"})
+	require.Empty(t, rec.Body.String(), "shadow writes must not reach the client")
+	close(a.done)
+
+	_, account, err := commitHedgeAttempt(c, a)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), account.ID)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.JSONEq(t, `{"id":"msg_1"}`, rec.Body.String())
+	require.Equal(t, "1", rec.Header().Get("X-Upstream"))
+	require.Equal(t, "yes", c.GetString("from_attempt"))
+	require.Equal(t, 1, c.GetInt("existing"))
+}
@@ -0,0 +1,427 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// ResponseCacheHeader 既是客户端开启响应缓存的请求头（true 或以秒为单位的有效期），
+// 也是响应中标记缓存结果（hit / miss）的响应头。
+const ResponseCacheHeader = "X-Sub2API-Response-Cache"
+
+const responseCacheRedisKeyPrefix = "response_cache:"
+
+// responseCacheTemperaturePaths 是各协议中 temperature 的位置（OpenAI/Anthropic 顶层，Gemini generationConfig）。
+var responseCacheTemperaturePaths = []string{"temperature", "generationConfig.temperature", "generation_config.temperature"}
+
+// responseCacheStore 是响应缓存的存储后端。
+type responseCacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// cachedResponse 是落入缓存的响应快照。AccountID/Model 为产生该响应的账号与模型，用于命中时记录用量。
+type cachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body"`
+	AccountID   int64  `json:"account_id,omitempty"`
+	Model       string `json:"model,omitempty"`
+}
+
+// ResponseCacheMiddleware 缓存确定性请求（temperature 为 0）的非流式响应（默认关闭）。
+// 仅对携带 ResponseCacheHeader 请求头的请求生效，缓存键为 API Key + 方法 + 路径 + 规范化请求体哈希；
+// 命中时直接返回缓存响应，不调用上游，因此不计费，但通过 usage 记录一条零费用用量（request_type=reused）。
+// 只缓存 2xx 的非 SSE 响应。
+func ResponseCacheMiddleware(cfg *config.Config, rdb *redis.Client, usage ReusedResponseUsageRecorder) gin.HandlerFunc {
+	if cfg == nil || !cfg.Gateway.ResponseCache.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	sealer, err := newResponseCacheSealer(cfg.Gateway.ResponseCache.EncryptionKey)
+	if err != nil {
+		logger.L().Error("response_cache.cipher_init_failed", zap.Error(err))
+		return func(c *gin.Context) { c.Next() }
+	}
+	var store responseCacheStore
+	switch cfg.Gateway.ResponseCache.Backend {
+	case "disk":
+		store = newDiskResponseCacheStore(cfg.Gateway.ResponseCache, sealer)
+	default:
+		if rdb == nil {
+			return func(c *gin.Context) { c.Next() }
+		}
+		store = &redisResponseCacheStore{rdb: rdb, sealer: sealer}
+	}
+	return newResponseCacheMiddleware(store, time.Duration(cfg.Gateway.ResponseCache.TTLSeconds)*time.Second, cfg.Gateway.ResponseCache.MaxResponseBytes, usage)
+}
+
+func newResponseCacheMiddleware(store responseCacheStore, maxTTL time.Duration, maxBytes int64, usage ReusedResponseUsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startedAt := time.Now()
+		key, ttl, ok := responseCacheKey(c, maxTTL)
+		if !ok {
+			c.Next()
+			return
+		}
+		raw, found, err := store.Get(c.Request.Context(), key)
+		if err != nil {
+			logger.L().Warn("response_cache.get_failed", zap.Error(err))
+		}
+		var entry cachedResponse
+		if found && json.Unmarshal(raw, &entry) == nil && entry.Status != 0 {
+			if entry.ContentType != "" {
+				c.Writer.Header().Set("Content-Type", entry.ContentType)
+			}
+			c.Writer.Header().Set(ResponseCacheHeader, "hit")
+			c.Writer.WriteHeader(entry.Status)
+			_, _ = c.Writer.Write(entry.Body)
+			recordReusedResponseUsage(c, usage, &coalescedResponse{status: entry.Status, header: c.Writer.Header(), accountID: entry.AccountID, model: entry.Model}, startedAt)
+			c.Abort()
+			return
+		}
+
+		c.Writer.Header().Set(ResponseCacheHeader, "miss")
+		resp := runCoalescingLeader(c, maxBytes, false)
+		if resp == nil || resp.status < http.StatusOK || resp.status >= http.StatusMultipleChoices {
+			return
+		}
+		raw, err = json.Marshal(cachedResponse{
+			Status:      resp.status,
+			ContentType: resp.header.Get("Content-Type"),
+			Body:        resp.body,
+			AccountID:   resp.accountID,
+			Model:       resp.model,
+		})
+		if err == nil {
+			err = store.Set(context.WithoutCancel(c.Request.Context()), key, raw, ttl)
+		}
+		if err != nil {
+			logger.L().Warn("response_cache.set_failed", zap.Error(err))
+		}
+	}
+}
+
+// responseCacheKey 计算缓存键与有效期。未开启缓存、非 POST、未鉴权、流式或 temperature 不为 0 的请求返回 ok=false。
+func responseCacheKey(c *gin.Context, maxTTL time.Duration) (string, time.Duration, bool) {
+	ttl, ok := parseResponseCacheHeader(c.GetHeader(ResponseCacheHeader), maxTTL)
+	if !ok || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return "", 0, false
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		return "", 0, false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 || !gjson.ValidBytes(body) || isStreamingCoalescingRequest(c, body) || !isDeterministicRequest(body) {
+		return "", 0, false
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(strconv.FormatInt(apiKey.ID, 10)))
+	sum.Write([]byte{0})
+	sum.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery))
+	sum.Write([]byte{0})
+	sum.Write(canonicalCoalescingBody(body))
+	return hex.EncodeToString(sum.Sum(nil)), ttl, true
+}
+
+// parseResponseCacheHeader 解析开启缓存的请求头：true/1/on 使用配置的有效期，正整数表示有效期秒数（不超过配置值）。
+func parseResponseCacheHeader(value string, maxTTL time.Duration) (time.Duration, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "":
+		return 0, false
+	case "true", "1", "on", "yes":
+		return maxTTL, true
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0, false
+	}
+	return min(time.Duration(seconds)*time.Second, maxTTL), true
+}
+
+// isDeterministicRequest 判断请求是否显式指定 temperature 为 0；未指定 temperature 时上游按默认值采样，不视为确定性请求。
+func isDeterministicRequest(body []byte) bool {
+	for _, path := range responseCacheTemperaturePaths {
+		if value := gjson.GetBytes(body, path); value.Exists() {
+			return value.Type == gjson.Number && value.Float() == 0
+		}
+	}
+	return false
+}
+
+// responseCacheSealer 以 AES-256-GCM 加解密缓存条目，redis 与 disk 后端共用。
+// 缓存键作为附加数据，条目不能被挪到其他键下；密文格式为 nonce || ciphertext。
+type responseCacheSealer struct {
+	aead cipher.AEAD
+}
+
+// newResponseCacheSealer 由 hex 编码的密钥创建加解密器；密钥为空时随机生成，仅在本进程内有效。
+func newResponseCacheSealer(hexKey string) (*responseCacheSealer, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate encryption key: %w", err)
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return &responseCacheSealer{aead: aead}, nil
+}
+
+func (s *responseCacheSealer) seal(key string, value []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, value, []byte(key)), nil
+}
+
+// open 解密条目；密钥已更换（如未配置固定密钥时重启）或条目损坏时返回 false。
+func (s *responseCacheSealer) open(key string, sealed []byte) ([]byte, bool) {
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, false
+	}
+	raw, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, false
+	}
+	return raw, true
+}
+
+// redisResponseCacheStore 把加密后的条目写入 response_cache:<键>，过期交给 Redis TTL。
+type redisResponseCacheStore struct {
+	rdb    *redis.Client
+	sealer *responseCacheSealer
+}
+
+func (s *redisResponseCacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	sealed, err := s.rdb.Get(ctx, responseCacheRedisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	raw, ok := s.sealer.open(key, sealed)
+	if !ok {
+		// 无法解密（其他密钥写入或历史明文条目）按未命中处理，随后的写入会覆盖它
+		return nil, false, nil
+	}
+	return raw, true, nil
+}
+
+func (s *redisResponseCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	sealed, err := s.sealer.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, responseCacheRedisKeyPrefix+key, sealed, ttl).Err()
+}
+
+// diskResponseCacheStore 把缓存条目按键哈希写入 dir/<前两位>/<键>。
+// 条目经 responseCacheSealer 加密落盘，文件 mtime 记为过期时间：
+// 过期判断与清扫只需 stat，不读取文件内容。总大小或条目数超过上限时按过期时间从早到晚淘汰。
+type diskResponseCacheStore struct {
+	dir        string
+	sealer     *responseCacheSealer
+	maxBytes   int64
+	maxEntries int64
+	lastSweep  atomic.Int64
+	sweeping   atomic.Bool
+	// bytes/entries 为磁盘占用的估计值：清扫时按实际重算，写入时累加（覆盖写会高估，只会让清扫提前）
+	bytes   atomic.Int64
+	entries atomic.Int64
+}
+
+const diskResponseCacheSweepInterval = 10 * time.Minute
+
+// diskResponseCacheStaleTemp 是残留临时文件（写入中途进程退出）的清理阈值。
+const diskResponseCacheStaleTemp = time.Hour
+
+func newDiskResponseCacheStore(cfg config.GatewayResponseCacheConfig, sealer *responseCacheSealer) *diskResponseCacheStore {
+	s := &diskResponseCacheStore{dir: cfg.DiskDir, sealer: sealer, maxBytes: cfg.DiskMaxBytes, maxEntries: int64(cfg.DiskMaxEntries)}
+	s.lastSweep.Store(time.Now().Unix())
+	// 启动时清扫一次，得到已有条目的实际占用
+	s.sweeping.Store(true)
+	go func() {
+		defer s.sweeping.Store(false)
+		s.sweep(time.Now())
+	}()
+	return s
+}
+
+func (s *diskResponseCacheStore) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+func (s *diskResponseCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	path := s.path(key)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !info.ModTime().After(time.Now()) {
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
+	sealed, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	raw, ok := s.sealer.open(key, sealed)
+	if !ok {
+		// 密钥已更换（如未配置固定密钥时重启）或文件损坏，按未命中处理
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
+	return raw, true, nil
+}
+
+func (s *diskResponseCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	sealed, err := s.sealer.seal(key, value)
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免并发读到写了一半的条目
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(sealed); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	expiresAt := time.Now().Add(ttl)
+	if err := os.Chtimes(tmp.Name(), expiresAt, expiresAt); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	s.bytes.Add(int64(len(sealed)))
+	s.entries.Add(1)
+	s.maybeSweep()
+	return nil
+}
+
+// maybeSweep 到达清扫间隔或估计占用超过上限时在后台清扫，同一时间只运行一次清扫。
+func (s *diskResponseCacheStore) maybeSweep() {
+	now := time.Now()
+	overLimit := s.bytes.Load() > s.maxBytes || s.entries.Load() > s.maxEntries
+	if !overLimit && now.Unix()-s.lastSweep.Load() < int64(diskResponseCacheSweepInterval/time.Second) {
+		return
+	}
+	if !s.sweeping.CompareAndSwap(false, true) {
+		return
+	}
+	s.lastSweep.Store(now.Unix())
+	go func() {
+		defer s.sweeping.Store(false)
+		s.sweep(now)
+	}()
+}
+
+// sweep 删除过期条目与残留临时文件；剩余条目超过上限时按过期时间从早到晚淘汰，并重算占用。
+func (s *diskResponseCacheStore) sweep(now time.Time) {
+	type entry struct {
+		path      string
+		size      int64
+		expiresAt time.Time
+	}
+	var live []entry
+	var total int64
+	_ = filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if strings.Contains(d.Name(), ".tmp") {
+			if now.Sub(info.ModTime()) > diskResponseCacheStaleTemp {
+				_ = os.Remove(path)
+			}
+			return nil
+		}
+		if !info.ModTime().After(now) {
+			_ = os.Remove(path)
+			return nil
+		}
+		live = append(live, entry{path: path, size: info.Size(), expiresAt: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+
+	if total > s.maxBytes || int64(len(live)) > s.maxEntries {
+		sort.Slice(live, func(i, j int) bool { return live[i].expiresAt.Before(live[j].expiresAt) })
+		evicted := 0
+		for evicted < len(live) && (total > s.maxBytes || int64(len(live)-evicted) > s.maxEntries) {
+			if err := os.Remove(live[evicted].path); err == nil || errors.Is(err, os.ErrNotExist) {
+				total -= live[evicted].size
+			}
+			evicted++
+		}
+		live = live[evicted:]
+	}
+	s.bytes.Store(total)
+	s.entries.Store(int64(len(live)))
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newResponseCacheRouter(t *testing.T, backend string, calls *atomic.Int32) (*gin.Engine, *miniredis.Miniredis) {
+	return newResponseCacheRouterWithUsage(t, backend, calls, nil)
+}
+
+func newResponseCacheRouterWithUsage(t *testing.T, backend string, calls *atomic.Int32, usage ReusedResponseUsageRecorder) (*gin.Engine, *miniredis.Miniredis) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.ResponseCache = config.GatewayResponseCacheConfig{Enabled: true, Backend: backend, DiskDir: t.TempDir(), DiskMaxBytes: 1 << 20, DiskMaxEntries: 100, TTLSeconds: 60, MaxResponseBytes: 1 << 20}
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		keyID := int64(1)
+		if v := c.GetHeader("X-Test-Key"); v != "" {
+			keyID, _ = strconv.ParseInt(v, 10, 64)
+		}
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: keyID})
+		c.Next()
+	})
+	r.POST("/v1/chat/completions", ResponseCacheMiddleware(cfg, rdb, usage), func(c *gin.Context) {
+		n := calls.Add(1)
+		setOpsRequestContext(c, "gpt-5", false)
+		setOpsSelectedAccount(c, 7)
+		status := http.StatusOK
+		if c.GetHeader("X-Test-Fail") != "" {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"n": n})
+	})
+	return r, mr
+}
+
+func serveResponseCache(r *gin.Engine, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponseCache_CachesDeterministicOptInRequests(t *testing.T) {
+	for _, backend := range []string{"redis", "disk"} {
+		t.Run(backend, func(t *testing.T) {
+			var calls atomic.Int32
+			r, _ := newResponseCacheRouter(t, backend, &calls)
+			body := `{"model":"gpt-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`
+
+			rec := serveResponseCache(r, body, ResponseCacheHeader, "true")
+			require.Equal(t, "miss", rec.Header().Get(ResponseCacheHeader))
+			require.JSONEq(t, `{"n":1}`, rec.Body.String())
+
+			// 字段顺序不同的相同请求命中缓存
+			rec = serveResponseCache(r, `{"messages":[{"role":"user","content":"hi"}],"temperature":0,"model":"gpt-5"}`, ResponseCacheHeader, "true")
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, "hit", rec.Header().Get(ResponseCacheHeader))
+			require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+			require.JSONEq(t, `{"n":1}`, rec.Body.String())
+			require.Equal(t, int32(1), calls.Load())
+
+			// 未开启、其他 API Key、非确定性、流式请求都不走缓存
+			serveResponseCache(r, body)
+			serveResponseCache(r, body, ResponseCacheHeader, "true", "X-Test-Key", "2")
+			serveResponseCache(r, `{"model":"gpt-5","temperature":0.7,"messages":[]}`, ResponseCacheHeader, "true")
+			serveResponseCache(r, `{"model":"gpt-5","temperature":0.7,"messages":[]}`, ResponseCacheHeader, "true")
+			serveResponseCache(r, `{"model":"gpt-5","messages":[]}`, ResponseCacheHeader, "true")
+			serveResponseCache(r, `{"model":"gpt-5","messages":[]}`, ResponseCacheHeader, "true")
+			serveResponseCache(r, `{"model":"gpt-5","temperature":0,"stream":true}`, ResponseCacheHeader, "true")
+			rec = serveResponseCache(r, `{"model":"gpt-5","temperature":0,"stream":true}`, ResponseCacheHeader, "true")
+			require.Empty(t, rec.Header().Get(ResponseCacheHeader))
+			require.Equal(t, int32(9), calls.Load())
+		})
+	}
+}
+
+func TestResponseCache_SkipsFailedResponsesAndExpires(t *testing.T) {
+	var calls atomic.Int32
+	r, mr := newResponseCacheRouter(t, "redis", &calls)
+	body := `{"contents":[{"parts":[{"text":"hi"}]}],"generationConfig":{"temperature":0}}`
+
+	serveResponseCache(r, body, ResponseCacheHeader, "true", "X-Test-Fail", "1")
+	require.Equal(t, "miss", serveResponseCache(r, body, ResponseCacheHeader, "10").Header().Get(ResponseCacheHeader))
+	require.Equal(t, "hit", serveResponseCache(r, body, ResponseCacheHeader, "true").Header().Get(ResponseCacheHeader))
+	require.Equal(t, int32(2), calls.Load())
+
+	// 请求头指定的有效期生效
+	mr.FastForward(11 * time.Second)
+	require.Equal(t, "miss", serveResponseCache(r, body, ResponseCacheHeader, "true").Header().Get(ResponseCacheHeader))
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestResponseCache_HitRecordsReusedUsage(t *testing.T) {
+	var calls atomic.Int32
+	usage := &reusedUsageRecorderStub{}
+	r, _ := newResponseCacheRouterWithUsage(t, "disk", &calls, usage)
+	body := `{"model":"gpt-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`
+
+	require.Equal(t, "miss", serveResponseCache(r, body, ResponseCacheHeader, "true").Header().Get(ResponseCacheHeader))
+	require.Empty(t, usage.snapshot())
+	require.Equal(t, "hit", serveResponseCache(r, body, ResponseCacheHeader, "true").Header().Get(ResponseCacheHeader))
+	require.Equal(t, []reusedUsageRecord{{apiKeyID: 1, accountID: 7, model: "gpt-5"}}, usage.snapshot())
+}
+
+func newTestDiskResponseCacheStore(t *testing.T, maxBytes int64, maxEntries int) *diskResponseCacheStore {
+	sealer, err := newResponseCacheSealer("")
+	require.NoError(t, err)
+	store := newDiskResponseCacheStore(config.GatewayResponseCacheConfig{DiskDir: t.TempDir(), DiskMaxBytes: maxBytes, DiskMaxEntries: maxEntries}, sealer)
+	require.Eventually(t, func() bool { return !store.sweeping.Load() }, time.Second, time.Millisecond)
+	return store
+}
+
+func diskResponseCacheKey(i int) string {
+	return fmt.Sprintf("%064x", i)
+}
+
+func TestDiskResponseCache_EncryptsEntriesAndExpiresByMtime(t *testing.T) {
+	store := newTestDiskResponseCacheStore(t, 1<<20, 100)
+	ctx := context.Background()
+	key := diskResponseCacheKey(1)
+	secret := []byte(`{"answer":"top secret response body"}`)
+
+	require.NoError(t, store.Set(ctx, key, secret, time.Minute))
+	raw, err := os.ReadFile(store.path(key))
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "top secret")
+	got, ok, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, secret, got)
+
+	// 条目不能被挪到其他键下复用
+	other := diskResponseCacheKey(2)
+	require.NoError(t, os.MkdirAll(filepath.Dir(store.path(other)), 0o700))
+	require.NoError(t, os.WriteFile(store.path(other), raw, 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(store.path(other), future, future))
+	_, ok, err = store.Get(ctx, other)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// mtime 即过期时间
+	past := time.Now().Add(-time.Second)
+	require.NoError(t, os.Chtimes(store.path(key), past, past))
+	_, ok, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, err = os.Stat(store.path(key))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRedisResponseCache_EncryptsEntries(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	hexKey := strings.Repeat("ab", 32)
+	newStore := func(hexKey string) *redisResponseCacheStore {
+		sealer, err := newResponseCacheSealer(hexKey)
+		require.NoError(t, err)
+		return &redisResponseCacheStore{rdb: rdb, sealer: sealer}
+	}
+	store := newStore(hexKey)
+	ctx := context.Background()
+	key := diskResponseCacheKey(1)
+	secret := []byte(`{"answer":"top secret response body"}`)
+
+	require.NoError(t, store.Set(ctx, key, secret, time.Minute))
+	raw, err := mr.Get(responseCacheRedisKeyPrefix + key)
+	require.NoError(t, err)
+	require.NotContains(t, raw, "top secret")
+
+	// 配置相同密钥的实例可以互相命中
+	got, ok, err := newStore(hexKey).Get(ctx, key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, secret, got)
+
+	// 其他密钥写入的条目与被挪到其他键下的条目都按未命中处理
+	_, ok, err = newStore(strings.Repeat("cd", 32)).Get(ctx, key)
+	require.NoError(t, err)
+	require.False(t, ok)
+	other := diskResponseCacheKey(2)
+	require.NoError(t, mr.Set(responseCacheRedisKeyPrefix+other, raw))
+	_, ok, err = store.Get(ctx, other)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestDiskResponseCache_SweepEvictsSoonestExpiringOverLimits(t *testing.T) {
+	ctx := context.Background()
+	value := make([]byte, 100)
+
+	store := newTestDiskResponseCacheStore(t, 1<<20, 3)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, store.Set(ctx, diskResponseCacheKey(i), value, time.Duration(i)*time.Minute))
+		require.Eventually(t, func() bool { return !store.sweeping.Load() }, time.Second, time.Millisecond)
+	}
+	store.sweep(time.Now())
+	require.Equal(t, int64(3), store.entries.Load())
+	for i := 1; i <= 5; i++ {
+		_, ok, err := store.Get(ctx, diskResponseCacheKey(i))
+		require.NoError(t, err)
+		require.Equal(t, i > 2, ok, i)
+	}
+
+	// 按总大小淘汰：每个加密条目略大于 100 字节，上限只容得下两个
+	store = newTestDiskResponseCacheStore(t, 300, 100)
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, store.Set(ctx, diskResponseCacheKey(i), value, time.Duration(i)*time.Minute))
+		require.Eventually(t, func() bool { return !store.sweeping.Load() }, time.Second, time.Millisecond)
+	}
+	store.sweep(time.Now())
+	require.Equal(t, int64(2), store.entries.Load())
+	require.LessOrEqual(t, store.bytes.Load(), int64(300))
+	_, ok, _ := store.Get(ctx, diskResponseCacheKey(4))
+	require.True(t, ok)
+
+	// 过期条目清扫时只按 mtime 删除，无需读取内容
+	past := time.Now().Add(-time.Second)
+	require.NoError(t, os.Chtimes(store.path(diskResponseCacheKey(4)), past, past))
+	store.sweep(time.Now())
+	require.Equal(t, int64(1), store.entries.Load())
+}
+
+func TestParseResponseCacheHeader(t *testing.T) {
+	for value, want := range map[string]time.Duration{"true": time.Hour, "ON": time.Hour, "30": 30 * time.Second, "7200": time.Hour} {
+		ttl, ok := parseResponseCacheHeader(value, time.Hour)
+		require.True(t, ok, value)
+		require.Equal(t, want, ttl, value)
+	}
+	for _, value := range []string{"", "false", "0", "-5", "abc"} {
+		_, ok := parseResponseCacheHeader(value, time.Hour)
+		require.False(t, ok, value)
+	}
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// responseGuardMaxJSONBytes JSON 响应缓冲上限；超过后放弃校验直接透传，避免大图片/大 base64 响应按并发数放大内存占用。
+	responseGuardMaxJSONBytes = 2 << 20
+	// responseGuardMaxSSEPrefixBytes SSE 前缀探测上限；在此范围内找不到合法 SSE 行时原样透传。
+	responseGuardMaxSSEPrefixBytes = 4 << 10
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// sseFieldPrefixes 合法 SSE 行的开头（":" 为注释/keepalive）。
+var sseFieldPrefixes = [][]byte{
+	[]byte("data:"),
+	[]byte("event:"),
+	[]byte("id:"),
+	[]byte("retry:"),
+	[]byte(":"),
+}
+
+type responseGuardMode int
+
+const (
+	responseGuardUndecided responseGuardMode = iota
+	responseGuardPassthrough
+	responseGuardJSON
+	responseGuardSSE
+)
+
+// responseContentGuardWriter 是网关响应的最后一道关卡：
+//   - Content-Type 为 JSON 的非流式响应先缓冲，结束时校验可解析后再写出；
+//   - SSE 流在首个合法行之前的 BOM / 垃圾前缀被丢弃；
+//   - 其余内容类型（图片、视频、WebSocket 升级等）原样透传。
+type responseContentGuardWriter struct {
+	gin.ResponseWriter
+	mode     responseGuardMode
+	buf      bytes.Buffer
+	buffered bool
+	stripped int
+}
+
+func (w *responseContentGuardWriter) decide() {
+	if w.mode != responseGuardUndecided {
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(w.ResponseWriter.Header().Get("Content-Type")))
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		w.mode = responseGuardSSE
+	case strings.HasPrefix(contentType, "application/json") || strings.Contains(contentType, "+json"):
+		w.mode = responseGuardJSON
+	default:
+		w.mode = responseGuardPassthrough
+	}
+}
+
+func (w *responseContentGuardWriter) Write(b []byte) (int, error) {
+	w.decide()
+	switch w.mode {
+	case responseGuardJSON:
+		if w.buf.Len()+len(b) > responseGuardMaxJSONBytes {
+			if err := w.flushBuffered(); err != nil {
+				return 0, err
+			}
+			return w.ResponseWriter.Write(b)
+		}
+		w.buffered = true
+		return w.buf.Write(b)
+	case responseGuardSSE:
+		return w.writeSSE(b)
+	default:
+		return w.ResponseWriter.Write(b)
+	}
+}
+
+func (w *responseContentGuardWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *responseContentGuardWriter) WriteHeaderNow() {
+	w.decide()
+	if w.mode == responseGuardJSON {
+		// JSON 响应在校验前不提交状态码，以便违规时仍能改写为 502。
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *responseContentGuardWriter) Written() bool {
+	return w.buffered || w.ResponseWriter.Written()
+}
+
+func (w *responseContentGuardWriter) Size() int {
+	if w.buffered {
+		return w.buf.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *responseContentGuardWriter) Flush() {
+	w.decide()
+	switch w.mode {
+	case responseGuardJSON:
+		// 主动 flush 的 JSON 响应（如非流式 keepalive）无法事后改写，退化为透传。
+		_ = w.flushBuffered()
+	case responseGuardSSE:
+		if w.buf.Len() > 0 {
+			// 仍在探测前缀，暂不 flush 未确定的字节。
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *responseContentGuardWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mode = responseGuardPassthrough
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *responseContentGuardWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.CloseNotify()
+}
+
+// flushBuffered 写出已缓冲的字节并切换为透传模式。
+func (w *responseContentGuardWriter) flushBuffered() error {
+	w.mode = responseGuardPassthrough
+	if !w.buffered {
+		return nil
+	}
+	w.buffered = false
+	data := w.buf.Bytes()
+	w.buf = bytes.Buffer{}
+	if len(data) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
+
+// writeSSE 丢弃首个合法 SSE 行之前的 BOM / 垃圾前缀；找到合法行后切换为透传。
+func (w *responseContentGuardWriter) writeSSE(b []byte) (int, error) {
+	n := len(b)
+	w.buf.Write(b)
+	data := w.buf.Bytes()
+
+	start, decided := findSSEStart(data)
+	if !decided {
+		if len(data) < responseGuardMaxSSEPrefixBytes {
+			return n, nil
+		}
+		start = 0
+	}
+
+	w.stripped = start
+	w.mode = responseGuardPassthrough
+	out := append([]byte(nil), data[start:]...)
+	w.buf = bytes.Buffer{}
+	if len(out) == 0 {
+		return n, nil
+	}
+	if _, err := w.ResponseWriter.Write(out); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// findSSEStart 返回首个合法 SSE 行的起始偏移。decided=false 表示数据不足以判断（需要继续缓冲）。
+func findSSEStart(data []byte) (int, bool) {
+	lineStart := 0
+	for lineStart < len(data) {
+		line := data[lineStart:]
+		if lineStart == 0 {
+			line = bytes.TrimPrefix(line, utf8BOM)
+		}
+		offset := lineStart + (len(data[lineStart:]) - len(line))
+		if hasSSEFieldPrefix(line) {
+			return offset, true
+		}
+		nl := bytes.IndexByte(line, '\n')
+		if nl < 0 {
+			return 0, false
+		}
+		lineStart = offset + nl + 1
+	}
+	return 0, false
+}
+
+func hasSSEFieldPrefix(line []byte) bool {
+	for _, prefix := range sseFieldPrefixes {
+		if bytes.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// finish 在 handler 返回后校验缓冲的 JSON 响应，合法则写出，违规则改写为结构化错误。
+func (w *responseContentGuardWriter) finish(c *gin.Context) {
+	if w.mode != responseGuardJSON || !w.buffered {
+		if w.mode == responseGuardSSE && w.buf.Len() > 0 {
+			// 流结束仍未找到合法 SSE 行：原样写出，交由客户端处理。
+			data := w.buf.Bytes()
+			w.buf = bytes.Buffer{}
+			w.mode = responseGuardPassthrough
+			_, _ = w.ResponseWriter.Write(data)
+		}
+		if w.stripped > 0 {
+			logger.L().With(zap.String("component", "handler.response_guard")).Warn("response_guard.sse_prefix_stripped",
+				zap.String("path", c.Request.URL.Path),
+				zap.Int("stripped_bytes", w.stripped),
+			)
+		}
+		return
+	}
+
+	data := bytes.TrimPrefix(w.buf.Bytes(), utf8BOM)
+	if len(bytes.TrimSpace(data)) == 0 || json.Valid(data) {
+		w.buf = bytes.Buffer{}
+		w.buffered = false
+		w.mode = responseGuardPassthrough
+		if len(data) == 0 {
+			w.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		_, _ = w.ResponseWriter.Write(data)
+		return
+	}
+
+	originalStatus := w.ResponseWriter.Status()
+	status := http.StatusBadGateway
+	if originalStatus >= http.StatusBadRequest {
+		// 上游错误本身的状态码（如 429）对客户端重试语义更有价值，予以保留。
+		status = originalStatus
+	}
+	message := fmt.Sprintf("Upstream returned an invalid JSON response (HTTP %d)", originalStatus)
+
+	service.SetOpsUpstreamError(c, originalStatus, message, truncateString(string(data), 2048))
+	service.SetOpsErrorCode(c, service.UpstreamErrorCodeInvalidResponse)
+	logger.L().With(zap.String("component", "handler.response_guard")).Warn("response_guard.invalid_json",
+		zap.String("path", c.Request.URL.Path),
+		zap.Int("original_status", originalStatus),
+		zap.Int("body_bytes", len(data)),
+	)
+
+	w.buf = bytes.Buffer{}
+	w.buffered = false
+	w.mode = responseGuardPassthrough
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Length")
+	header.Del("Content-Encoding")
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(status)
+	_, _ = w.ResponseWriter.Write(responseGuardErrorBody(c, status, message))
+}
+
+// responseGuardErrorBody 按入口协议构造错误体：Gemini 原生接口用 Google 格式，/v1/messages 用 Anthropic 信封，
+// 其余（Chat Completions、Responses 等）用 OpenAI error schema。
+func responseGuardErrorBody(c *gin.Context, status int, message string) []byte {
+	var payload any
+	switch routeErrorFormatOf(c) {
+	case routeErrorFormatGoogle:
+		payload = gin.H{"error": gin.H{
+			"code":    status,
+			"message": message,
+			"status":  googleapi.HTTPStatusToGoogleStatus(status),
+		}}
+	case routeErrorFormatAnthropic:
+		payload = gin.H{"type": "error", "error": gin.H{
+			"type":    "upstream_error",
+			"code":    service.UpstreamErrorCodeInvalidResponse,
+			"message": message,
+		}}
+	default:
+		payload = gin.H{"error": gin.H{
+			"message": message,
+			"type":    "upstream_error",
+			"param":   nil,
+			"code":    service.UpstreamErrorCodeInvalidResponse,
+		}}
+	}
+	body, _ := json.Marshal(payload)
+	return body
+}
+
+// ResponseContentGuardMiddleware 网关响应内容守卫，需注册在 OpsErrorLoggerMiddleware 之后，
+// 以便改写后的错误响应仍由 ops 采集。默认关闭，仅 gateway.response_content_guard=true 时生效。
+func ResponseContentGuardMiddleware(cfg *config.Config) gin.HandlerFunc {
+	enabled := cfg != nil && cfg.Gateway.ResponseContentGuard
+	return func(c *gin.Context) {
+		if !enabled || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		originalWriter := c.Writer
+		w := &responseContentGuardWriter{ResponseWriter: originalWriter}
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+		}()
+		c.Next()
+		w.finish(c)
+	}
+}
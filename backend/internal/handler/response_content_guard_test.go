@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func serveResponseGuard(t *testing.T, enabled bool, path string, h gin.HandlerFunc) (*httptest.ResponseRecorder, *gin.Context) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.ResponseContentGuard = enabled
+	r := gin.New()
+	var captured *gin.Context
+	r.Use(func(c *gin.Context) {
+		captured = c
+		c.Next()
+	})
+	r.Use(ResponseContentGuardMiddleware(cfg))
+	r.POST(path, h)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	return rec, captured
+}
+
+func TestResponseContentGuard_ValidJSONPassesThrough(t *testing.T) {
+	rec, c := serveResponseGuard(t, true, "/v1/messages", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": "msg_1"})
+	})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"id":"msg_1"}`, rec.Body.String())
+	_, ok := c.Get(service.OpsErrorCodeKey)
+	require.False(t, ok)
+}
+
+func TestResponseContentGuard_InvalidJSONBecomes502(t *testing.T) {
+	rec, c := serveResponseGuard(t, true, "/v1/messages", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"id":"msg_1",`))
+	})
+
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.Bytes()
+	require.True(t, gjson.ValidBytes(body))
+	require.Equal(t, "upstream_error", gjson.GetBytes(body, "error.type").String())
+	require.Equal(t, service.UpstreamErrorCodeInvalidResponse, gjson.GetBytes(body, "error.code").String())
+
+	code, _ := c.Get(service.OpsErrorCodeKey)
+	require.Equal(t, service.UpstreamErrorCodeInvalidResponse, code)
+	status, _ := c.Get(service.OpsUpstreamStatusCodeKey)
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestResponseContentGuard_InvalidJSONUsesRouteErrorFormat(t *testing.T) {
+	rec, _ := serveResponseGuard(t, true, "/v1/chat/completions", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"id":"chatcmpl_1",`))
+	})
+
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	body := rec.Body.Bytes()
+	require.False(t, gjson.GetBytes(body, "type").Exists(), "OpenAI routes must not get the Anthropic envelope")
+	require.Equal(t, "upstream_error", gjson.GetBytes(body, "error.type").String())
+	require.Equal(t, service.UpstreamErrorCodeInvalidResponse, gjson.GetBytes(body, "error.code").String())
+	require.True(t, gjson.GetBytes(body, "error.param").Exists())
+
+	rec, _ = serveResponseGuard(t, true, "/v1/messages", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"id":"msg_1",`))
+	})
+	require.Equal(t, "error", gjson.Get(rec.Body.String(), "type").String())
+}
+
+func TestResponseContentGuard_InvalidJSONKeepsUpstreamErrorStatus(t *testing.T) {
+	rec, _ := serveResponseGuard(t, true, "/v1beta/models/gemini:generateContent", func(c *gin.Context) {
+		c.Data(http.StatusTooManyRequests, "application/json", []byte("Too Many Requests"))
+	})
+
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, int64(http.StatusTooManyRequests), gjson.Get(rec.Body.String(), "error.code").Int())
+	require.Equal(t, "RESOURCE_EXHAUSTED", gjson.Get(rec.Body.String(), "error.status").String())
+}
+
+func TestResponseContentGuard_StripsJSONBOM(t *testing.T) {
+	rec, _ := serveResponseGuard(t, true, "/v1/chat/completions", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", append([]byte{0xEF, 0xBB, 0xBF}, `{"ok":true}`...))
+	})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"ok":true}`, rec.Body.String())
+}
+
+func TestResponseContentGuard_StripsSSEGarbagePrefix(t *testing.T) {
+	rec, _ := serveResponseGuard(t, true, "/v1/messages", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.Write([]byte{0xEF, 0xBB, 0xBF})
+		_, _ = c.Writer.Write([]byte("garbage\n"))
+		c.Writer.Flush()
+		_, _ = c.Writer.Write([]byte("event: message_start\ndata: {}\n\n"))
+		_, _ = c.Writer.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	require.Equal(t, "event: message_start\ndata: {}\n\ndata: [DONE]\n\n", rec.Body.String())
+}
+
+func TestResponseContentGuard_SSEStripsLeadingBOM(t *testing.T) {
+	rec, _ := serveResponseGuard(t, true, "/v1/messages", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.Write(append([]byte{0xEF, 0xBB, 0xBF}, "data: {}\n\n"...))
+	})
+
+	require.Equal(t, "data: {}\n\n", rec.Body.String())
+}
+
+func TestResponseContentGuard_NonJSONPassesThrough(t *testing.T) {
+	rec, _ := serveResponseGuard(t, true, "/v1/images/generations", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte("\x89PNG not json"))
+	})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "\x89PNG not json", rec.Body.String())
+}
+
+func TestResponseContentGuard_Disabled(t *testing.T) {
+	rec, _ := serveResponseGuard(t, false, "/v1/messages", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`not json`))
+	})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "not json", rec.Body.String())
+}
+
+func TestResponseContentGuard_OffByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ResponseContentGuardMiddleware(nil))
+	r.POST("/v1/messages", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`not json`))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "not json", rec.Body.String())
+}
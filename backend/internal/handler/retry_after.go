@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const ctxKeyClientRetryAfter = "_gateway_client_retry_after"
+
+const (
+	// concurrencyRetryAfterSeconds 并发槽位已满时的退避提示：槽位通常在一次请求内释放。
+	concurrencyRetryAfterSeconds = 1
+	// waitQueueFullRetryAfterSeconds 等待队列已满时的退避提示：队列需先排空，给出稍长的间隔。
+	waitQueueFullRetryAfterSeconds = 3
+)
+
+// setClientRetryAfter 记录本次错误的退避秒数并写入 Retry-After 响应头；
+// 响应头已提交（流已开始）时仅记录，由流内错误事件携带。
+func setClientRetryAfter(c *gin.Context, seconds int) {
+	if c == nil || seconds <= 0 {
+		return
+	}
+	c.Set(ctxKeyClientRetryAfter, seconds)
+	if c.Writer != nil && !c.Writer.Written() {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+}
+
+// clientRetryAfter 返回 setClientRetryAfter 记录的退避秒数，未设置时为 0。
+func clientRetryAfter(c *gin.Context) int {
+	if c == nil {
+		return 0
+	}
+	v, ok := c.Get(ctxKeyClientRetryAfter)
+	if !ok {
+		return 0
+	}
+	seconds, _ := v.(int)
+	return seconds
+}
+
+// sseErrorRetryAfterField 返回追加到流内 error 对象末尾的 retry_after 字段（含前导逗号），未设置时为空。
+func sseErrorRetryAfterField(c *gin.Context) string {
+	seconds := clientRetryAfter(c)
+	if seconds <= 0 {
+		return ""
+	}
+	return `,"retry_after":` + strconv.Itoa(seconds)
+}
+
+// concurrencyRetryAfter 本地并发限制对应的退避秒数；非限流错误返回 0。
+func concurrencyRetryAfter(err error) int {
+	var waitQueueFullErr *WaitQueueFullError
+	if errors.As(err, &waitQueueFullErr) {
+		return waitQueueFullRetryAfterSeconds
+	}
+	var concurrencyErr *ConcurrencyError
+	if errors.As(err, &concurrencyErr) {
+		return concurrencyRetryAfterSeconds
+	}
+	return 0
+}
+
+// setUpstreamRetryAfter 故障转移耗尽且最后一次为限流/过载时，把上游给出的等待时间透传给客户端。
+func setUpstreamRetryAfter(c *gin.Context, failoverErr *service.UpstreamFailoverError) {
+	if failoverErr == nil {
+		return
+	}
+	switch failoverErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, 529:
+	default:
+		return
+	}
+	setClientRetryAfter(c, service.ClientRetryAfterSeconds(failoverErr.ResponseHeaders, time.Now()))
+}
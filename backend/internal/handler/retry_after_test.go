@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyErrorSetsRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	(&GatewayHandler{}).handleConcurrencyError(c, &WaitQueueFullError{SlotType: "user"}, "user", false)
+
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "3", rec.Header().Get("Retry-After"))
+}
+
+func TestFailoverExhaustedPropagatesUpstreamRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	headers := http.Header{}
+	headers.Set("Retry-After", "12")
+	(&OpenAIGatewayHandler{}).handleFailoverExhausted(c, &service.UpstreamFailoverError{
+		StatusCode:      http.StatusTooManyRequests,
+		ResponseHeaders: headers,
+	}, false)
+
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "12", rec.Header().Get("Retry-After"))
+}
+
+func TestStreamingErrorEventCarriesRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Header("Content-Type", "text/event-stream")
+	c.Status(http.StatusOK)
+	_, _ = c.Writer.WriteString("event: ping\ndata: {\"type\":\"ping\"}\n\n")
+
+	(&GatewayHandler{}).handleConcurrencyError(c, &ConcurrencyError{SlotType: "account", IsTimeout: true}, "account", true)
+
+	require.Empty(t, rec.Header().Get("Retry-After"), "headers are already committed once the stream started")
+	events := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+	require.Len(t, events, 2)
+	var payload struct {
+		Error struct {
+			Type       string `json:"type"`
+			RetryAfter int    `json:"retry_after"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[1], "data: ")), &payload))
+	require.Equal(t, "rate_limit_error", payload.Error.Type)
+	require.Equal(t, concurrencyRetryAfterSeconds, payload.Error.RetryAfter)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
+	"github.com/gin-gonic/gin"
+)
+
+// routeErrorFormat 是网关入口协议对应的错误格式。
+// 供不经过具体 handler、却需要自行写出错误的中间件使用（请求合并、响应守卫等）。
+type routeErrorFormat int
+
+const (
+	routeErrorFormatOpenAI routeErrorFormat = iota
+	routeErrorFormatAnthropic
+	routeErrorFormatGoogle
+)
+
+// routeErrorFormatOf 按入口端点选择错误格式：/v1/messages 为 Anthropic，Gemini 原生接口为 Google，
+// 其余（Chat Completions、Responses、Images 等）为 OpenAI 兼容格式。
+func routeErrorFormatOf(c *gin.Context) routeErrorFormat {
+	if c != nil && c.Request != nil && c.Request.URL != nil && strings.Contains(c.Request.URL.Path, "/v1beta/") {
+		return routeErrorFormatGoogle
+	}
+	switch GetInboundEndpoint(c) {
+	case EndpointMessages:
+		return routeErrorFormatAnthropic
+	case EndpointGeminiModels:
+		return routeErrorFormatGoogle
+	default:
+		return routeErrorFormatOpenAI
+	}
+}
+
+// writeRouteStreamError 在已开始输出的 SSE 流上按入口协议写入终止错误事件：
+// Responses 路由写 response.failed，Anthropic 写 event: error，OpenAI / Gemini 写带 error 对象的 data 帧。
+func writeRouteStreamError(c *gin.Context, status int, errType, message string) {
+	if inboundIsResponses(c) && writeResponsesFailedSSE(c, errType, message) {
+		return
+	}
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		return
+	}
+	var frame string
+	switch routeErrorFormatOf(c) {
+	case routeErrorFormatAnthropic:
+		payload, _ := json.Marshal(gin.H{"type": "error", "error": gin.H{"type": errType, "message": message}})
+		frame = fmt.Sprintf("event: error\ndata: %s\n\n", payload)
+	case routeErrorFormatGoogle:
+		payload, _ := json.Marshal(gin.H{"error": gin.H{"code": status, "message": message, "status": googleapi.HTTPStatusToGoogleStatus(status)}})
+		frame = fmt.Sprintf("data: %s\n\n", payload)
+	default:
+		payload, _ := json.Marshal(gin.H{"error": gin.H{"type": errType, "message": message}})
+		frame = fmt.Sprintf("event: error\ndata: %s\n\n", payload)
+	}
+	if _, err := fmt.Fprint(c.Writer, frame); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	flusher.Flush()
+}
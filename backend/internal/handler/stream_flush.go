@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+type streamFlushMode int
+
+const (
+	streamFlushUndecided streamFlushMode = iota
+	streamFlushPassthrough
+	streamFlushSSE
+)
+
+// streamFlushWriter 合并 SSE 响应的 flush：
+//   - 距上次 flush 已满 interval，或待写出数据达到 minBytes 时立即写出并 flush；
+//   - 否则暂存，由定时器在 interval 结束时统一写出，保证数据最多延迟 interval；
+//   - 待写出数据超过 bufferBytes 时立即写出（不 flush），限制内存占用。
+//
+// 定时器与 handler 并发写出，所有下游操作都在 mu 内完成；写错误延迟到下一次 Write 返回，
+// 保证上层仍能按写错误判断客户端断开。
+type streamFlushWriter struct {
+	gin.ResponseWriter
+	interval    time.Duration
+	minBytes    int
+	bufferBytes int
+
+	mu        sync.Mutex
+	mode      streamFlushMode
+	pending   []byte
+	accepted  int
+	lastFlush time.Time
+	timer     *time.Timer
+	err       error
+	closed    bool
+	now       func() time.Time
+}
+
+func newStreamFlushWriter(w gin.ResponseWriter, cfg config.GatewayStreamFlushConfig) *streamFlushWriter {
+	return &streamFlushWriter{
+		ResponseWriter: w,
+		interval:       time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		minBytes:       cfg.CoalesceMinBytes,
+		bufferBytes:    cfg.WriteBufferBytes,
+		now:            time.Now,
+	}
+}
+
+func (w *streamFlushWriter) decideLocked() {
+	if w.mode != streamFlushUndecided {
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(w.ResponseWriter.Header().Get("Content-Type")))
+	if strings.HasPrefix(contentType, "text/event-stream") && w.ResponseWriter.Status() == http.StatusOK {
+		w.mode = streamFlushSSE
+		return
+	}
+	w.mode = streamFlushPassthrough
+}
+
+func (w *streamFlushWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.decideLocked()
+	if w.mode != streamFlushSSE || w.closed {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.accepted += len(b)
+	w.pending = append(w.pending, b...)
+	if len(w.pending) >= w.bufferBytes {
+		w.writePendingLocked()
+	}
+	return len(b), nil
+}
+
+func (w *streamFlushWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *streamFlushWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.decideLocked()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *streamFlushWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.accepted > 0 || w.ResponseWriter.Written()
+}
+
+func (w *streamFlushWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.accepted > 0 {
+		return w.accepted
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *streamFlushWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.decideLocked()
+	if w.mode != streamFlushSSE || w.closed {
+		w.ResponseWriter.Flush()
+		return
+	}
+	now := w.now()
+	if now.Sub(w.lastFlush) >= w.interval || (w.minBytes > 0 && len(w.pending) >= w.minBytes) {
+		w.flushLocked(now)
+		return
+	}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.interval-now.Sub(w.lastFlush), w.onTimer)
+	}
+}
+
+func (w *streamFlushWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.mode = streamFlushPassthrough
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *streamFlushWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.CloseNotify()
+}
+
+func (w *streamFlushWriter) onTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timer = nil
+	if w.closed {
+		return
+	}
+	w.flushLocked(w.now())
+}
+
+func (w *streamFlushWriter) writePendingLocked() {
+	if len(w.pending) == 0 || w.err != nil {
+		w.pending = w.pending[:0]
+		return
+	}
+	if _, err := w.ResponseWriter.Write(w.pending); err != nil {
+		w.err = err
+	}
+	w.pending = w.pending[:0]
+}
+
+func (w *streamFlushWriter) flushLocked(now time.Time) {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.writePendingLocked()
+	if w.err == nil {
+		w.ResponseWriter.Flush()
+	}
+	w.lastFlush = now
+}
+
+// finish 在 handler 返回后写出剩余数据并停止定时器。
+func (w *streamFlushWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.mode != streamFlushSSE || w.closed {
+		return
+	}
+	w.closed = true
+	if w.timer != nil || len(w.pending) > 0 {
+		w.flushLocked(w.now())
+	}
+}
+
+// StreamFlushMiddleware 按 gateway.stream_flush 合并 SSE 响应的 flush 与小增量写出，
+// 未启用时直接透传，保持每次写出即 flush 的默认行为。
+func StreamFlushMiddleware(cfg *config.Config) gin.HandlerFunc {
+	if cfg == nil || !cfg.Gateway.StreamFlush.Enabled || cfg.Gateway.StreamFlush.FlushIntervalMs <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	flushCfg := cfg.Gateway.StreamFlush
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		originalWriter := c.Writer
+		w := newStreamFlushWriter(originalWriter, flushCfg)
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+			w.finish()
+		}()
+		c.Next()
+	}
+}
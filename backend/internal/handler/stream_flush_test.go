@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// flushCountingRecorder 记录下游实际 flush 次数及每次 flush 时已写出的内容。
+type flushCountingRecorder struct {
+	*httptest.ResponseRecorder
+	mu      sync.Mutex
+	flushes int
+}
+
+func (r *flushCountingRecorder) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ResponseRecorder.Write(b)
+}
+
+func (r *flushCountingRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushes++
+	r.ResponseRecorder.Flush()
+}
+
+func (r *flushCountingRecorder) snapshot() (int, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flushes, r.Body.String()
+}
+
+func serveStreamFlush(t *testing.T, flushCfg config.GatewayStreamFlushConfig, h gin.HandlerFunc) *flushCountingRecorder {
+	t.Helper()
+	rec := &flushCountingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	serveStreamFlushTo(rec, flushCfg, h)
+	return rec
+}
+
+func serveStreamFlushTo(rec *flushCountingRecorder, flushCfg config.GatewayStreamFlushConfig, h gin.HandlerFunc) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.StreamFlush = flushCfg
+	r := gin.New()
+	r.POST("/v1/messages", StreamFlushMiddleware(cfg), h)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+}
+
+func writeDeltas(n int, contentType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", contentType)
+		c.Status(http.StatusOK)
+		for i := 0; i < n; i++ {
+			_, _ = c.Writer.WriteString("data: x\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+func TestStreamFlush_CoalescesFlushesWithinInterval(t *testing.T) {
+	rec := serveStreamFlush(t, config.GatewayStreamFlushConfig{Enabled: true, FlushIntervalMs: 1000, WriteBufferBytes: 64 << 10}, writeDeltas(10, "text/event-stream"))
+	flushes, body := rec.snapshot()
+	// 首个事件立即 flush，其余在结束时合并写出
+	require.Equal(t, 2, flushes)
+	require.Equal(t, strings.Repeat("data: x\n\n", 10), body)
+}
+
+func TestStreamFlush_LargeDeltasBypassInterval(t *testing.T) {
+	rec := serveStreamFlush(t, config.GatewayStreamFlushConfig{Enabled: true, FlushIntervalMs: 1000, CoalesceMinBytes: 5, WriteBufferBytes: 64 << 10}, writeDeltas(10, "text/event-stream"))
+	flushes, _ := rec.snapshot()
+	require.Equal(t, 10, flushes)
+}
+
+func TestStreamFlush_PassthroughWhenDisabledOrNotSSE(t *testing.T) {
+	rec := serveStreamFlush(t, config.GatewayStreamFlushConfig{}, writeDeltas(5, "text/event-stream"))
+	flushes, _ := rec.snapshot()
+	require.Equal(t, 5, flushes)
+
+	rec = serveStreamFlush(t, config.GatewayStreamFlushConfig{Enabled: true, FlushIntervalMs: 1000, WriteBufferBytes: 64 << 10}, writeDeltas(5, "application/json"))
+	flushes, _ = rec.snapshot()
+	require.Equal(t, 5, flushes)
+}
+
+func TestStreamFlush_TimerBoundsLatency(t *testing.T) {
+	rec := &flushCountingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	serveStreamFlushTo(rec, config.GatewayStreamFlushConfig{Enabled: true, FlushIntervalMs: 20, WriteBufferBytes: 64 << 10}, func(c *gin.Context) {
+		writeDeltas(2, "text/event-stream")(c)
+		// handler 不再写出时，暂存的增量由定时器在间隔结束后 flush
+		require.Eventually(t, func() bool {
+			flushes, body := rec.snapshot()
+			return flushes == 2 && body == "data: x\n\ndata: x\n\n"
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestStreamFlush_WriteBufferCap(t *testing.T) {
+	rec := serveStreamFlush(t, config.GatewayStreamFlushConfig{Enabled: true, FlushIntervalMs: 1000, WriteBufferBytes: 4096}, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("data: " + strings.Repeat("a", 5000) + "\n\n")
+		require.Equal(t, 5008, c.Writer.Size())
+	})
+	_, body := rec.snapshot()
+	require.Len(t, body, 5008)
+}
@@ -0,0 +1,433 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// StreamIDHeader 可续传的流式响应返回的流 ID，客户端重连时以 Last-Event-ID（"<流ID>:<序号>"）携带。
+	StreamIDHeader = "X-Sub2API-Stream-ID"
+	// StreamReplayHeader 重连请求的续传结果：hit 表示从缓冲区续读，miss 表示缓冲已失效、重新生成。
+	StreamReplayHeader = "X-Sub2API-Stream-Replay"
+
+	// streamReplayMaxStreams 进程内同时缓存的流上限，超过后新流不再可续传。
+	streamReplayMaxStreams = 1000
+	// streamReplayMaxTotalBytes 所有流缓冲的总字节上限，超过后各流优先丢弃自身最旧的事件。
+	streamReplayMaxTotalBytes = 512 << 20
+)
+
+type streamReplayEvent struct {
+	seq  int64
+	data string
+}
+
+// replayStream 单个进行中（或刚结束）响应的事件缓冲。
+type replayStream struct {
+	id        string
+	apiKeyID  int64
+	maxEvents int
+	maxBytes  int
+	retention time.Duration
+
+	mu        sync.Mutex
+	events    []streamReplayEvent
+	bytes     int
+	nextSeq   int64
+	done      bool
+	expiresAt time.Time
+	// notify 在追加事件或结束时关闭并替换，用于唤醒续读中的重连请求
+	notify chan struct{}
+}
+
+// replayFrom 返回 seq 之后的事件；ok=false 表示其中部分事件已被淘汰，无法完整续传。
+func (s *replayStream) replayFrom(seq int64) (events []streamReplayEvent, done bool, notify <-chan struct{}, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	first := s.nextSeq
+	if len(s.events) > 0 {
+		first = s.events[0].seq
+	}
+	if seq+1 < first || seq >= s.nextSeq {
+		return nil, false, nil, false
+	}
+	for _, ev := range s.events {
+		if ev.seq > seq {
+			events = append(events, ev)
+		}
+	}
+	return events, s.done, s.notify, true
+}
+
+type streamReplayRegistry struct {
+	mu         sync.Mutex
+	streams    map[string]*replayStream
+	totalBytes atomic.Int64
+	now        func() time.Time
+}
+
+func newStreamReplayRegistry() *streamReplayRegistry {
+	return &streamReplayRegistry{streams: make(map[string]*replayStream), now: time.Now}
+}
+
+var defaultStreamReplayRegistry = newStreamReplayRegistry()
+
+// register 创建新的流缓冲；顺带清理已过期的流，容量已满时返回 nil。
+func (r *streamReplayRegistry) register(apiKeyID int64, cfg service.GroupStreamReplayConfig) *replayStream {
+	maxEvents, maxBytes, retention := service.StreamReplayLimits(cfg)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	for id, s := range r.streams {
+		if r.expiredLocked(s, now) {
+			r.removeLocked(id, s)
+		}
+	}
+	if len(r.streams) >= streamReplayMaxStreams {
+		return nil
+	}
+	s := &replayStream{
+		id:        uuid.NewString(),
+		apiKeyID:  apiKeyID,
+		maxEvents: maxEvents,
+		maxBytes:  maxBytes,
+		retention: retention,
+		nextSeq:   1,
+		notify:    make(chan struct{}),
+	}
+	r.streams[s.id] = s
+	return s
+}
+
+func (r *streamReplayRegistry) lookup(id string, apiKeyID int64) *replayStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.streams[id]
+	if s == nil {
+		return nil
+	}
+	if r.expiredLocked(s, r.now()) {
+		r.removeLocked(id, s)
+		return nil
+	}
+	if s.apiKeyID != apiKeyID {
+		return nil
+	}
+	return s
+}
+
+func (r *streamReplayRegistry) expiredLocked(s *replayStream, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done && now.After(s.expiresAt)
+}
+
+func (r *streamReplayRegistry) removeLocked(id string, s *replayStream) {
+	delete(r.streams, id)
+	s.mu.Lock()
+	r.totalBytes.Add(-int64(s.bytes))
+	s.mu.Unlock()
+}
+
+// append 追加一个事件并返回其序号，超出单流或全局上限时丢弃最旧的事件。
+func (r *streamReplayRegistry) append(s *replayStream, data string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq := s.nextSeq
+	s.nextSeq++
+	s.events = append(s.events, streamReplayEvent{seq: seq, data: data})
+	s.bytes += len(data)
+	r.totalBytes.Add(int64(len(data)))
+	for len(s.events) > 1 && (len(s.events) > s.maxEvents || s.bytes > s.maxBytes || r.totalBytes.Load() > streamReplayMaxTotalBytes) {
+		dropped := len(s.events[0].data)
+		s.events[0] = streamReplayEvent{}
+		s.events = s.events[1:]
+		s.bytes -= dropped
+		r.totalBytes.Add(-int64(dropped))
+	}
+	close(s.notify)
+	s.notify = make(chan struct{})
+	return seq
+}
+
+func (r *streamReplayRegistry) finish(s *replayStream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.expiresAt = r.now().Add(s.retention)
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+type streamReplayMode int
+
+const (
+	streamReplayUndecided streamReplayMode = iota
+	streamReplayPassthrough
+	streamReplaySSE
+)
+
+// streamReplayWriter 为 SSE 响应的每个事件追加 "id: <流ID>:<序号>" 并写入续传缓冲。
+// 客户端断开后吞掉写错误，让上游生成继续完成并留在缓冲区中供重连续读。
+type streamReplayWriter struct {
+	gin.ResponseWriter
+	registry   *streamReplayRegistry
+	apiKeyID   int64
+	cfg        service.GroupStreamReplayConfig
+	mode       streamReplayMode
+	stream     *replayStream
+	buf        bytes.Buffer
+	accepted   int
+	clientGone bool
+}
+
+func (w *streamReplayWriter) decide() {
+	if w.mode != streamReplayUndecided {
+		return
+	}
+	w.mode = streamReplayPassthrough
+	contentType := strings.ToLower(strings.TrimSpace(w.ResponseWriter.Header().Get("Content-Type")))
+	if !strings.HasPrefix(contentType, "text/event-stream") || w.ResponseWriter.Status() != http.StatusOK {
+		return
+	}
+	if w.stream = w.registry.register(w.apiKeyID, w.cfg); w.stream == nil {
+		return
+	}
+	w.mode = streamReplaySSE
+	w.ResponseWriter.Header().Set(StreamIDHeader, w.stream.id)
+}
+
+func (w *streamReplayWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if w.mode != streamReplaySSE {
+		return w.ResponseWriter.Write(b)
+	}
+	w.accepted += len(b)
+	w.buf.Write(b)
+	w.drain(false)
+	return len(b), nil
+}
+
+func (w *streamReplayWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *streamReplayWriter) WriteHeaderNow() {
+	w.decide()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *streamReplayWriter) Written() bool {
+	return w.accepted > 0 || w.ResponseWriter.Written()
+}
+
+func (w *streamReplayWriter) Size() int {
+	if w.accepted > 0 {
+		return w.accepted
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *streamReplayWriter) Flush() {
+	w.decide()
+	if w.clientGone {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *streamReplayWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mode = streamReplayPassthrough
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *streamReplayWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.CloseNotify()
+}
+
+// drain 切分缓冲区中完整的 SSE 事件；final=true 时把剩余字节视为最后一个事件。
+func (w *streamReplayWriter) drain(final bool) {
+	for {
+		body, n := splitSSEEvent(w.buf.Bytes())
+		if n == 0 {
+			if !final || w.buf.Len() == 0 {
+				return
+			}
+			rest := w.buf.String()
+			w.buf.Reset()
+			if strings.TrimSpace(rest) == "" {
+				w.writeDownstream(rest)
+				return
+			}
+			body = strings.TrimRight(rest, "\r\n")
+		} else {
+			w.buf.Next(n)
+		}
+		if !sseEventHasFields(body) {
+			// 纯注释（keepalive）不进入缓冲，也不占用序号
+			w.writeDownstream(body + "\n\n")
+			continue
+		}
+		seq := w.registry.append(w.stream, body)
+		w.writeDownstream(formatReplayEvent(w.stream.id, seq, body))
+	}
+}
+
+func (w *streamReplayWriter) writeDownstream(s string) {
+	if w.clientGone || s == "" {
+		return
+	}
+	if _, err := w.ResponseWriter.Write([]byte(s)); err != nil {
+		w.clientGone = true
+	}
+}
+
+func (w *streamReplayWriter) finish() {
+	if w.mode != streamReplaySSE {
+		return
+	}
+	w.drain(true)
+	w.registry.finish(w.stream)
+}
+
+// splitSSEEvent 返回首个完整事件（不含结尾空行）及其占用的字节数，未找到事件边界时 n=0。
+func splitSSEEvent(data []byte) (string, int) {
+	lf := bytes.Index(data, []byte("\n\n"))
+	crlf := bytes.Index(data, []byte("\n\r\n"))
+	switch {
+	case lf >= 0 && (crlf < 0 || lf < crlf):
+		return string(data[:lf]), lf + 2
+	case crlf >= 0:
+		return strings.TrimSuffix(string(data[:crlf]), "\r"), crlf + 3
+	default:
+		return "", 0
+	}
+}
+
+// sseEventHasFields 事件中是否包含非注释字段。
+func sseEventHasFields(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, ":") {
+			return true
+		}
+	}
+	return false
+}
+
+// formatReplayEvent 在事件末尾追加 id 字段：同一事件内后出现的 id 生效，覆盖上游自带的 id。
+func formatReplayEvent(streamID string, seq int64, body string) string {
+	return body + "\nid: " + streamID + ":" + strconv.FormatInt(seq, 10) + "\n\n"
+}
+
+// parseReplayEventID 解析 Last-Event-ID（"<流ID>:<序号>"）。
+func parseReplayEventID(raw string) (string, int64, bool) {
+	raw = strings.TrimSpace(raw)
+	idx := strings.LastIndex(raw, ":")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseInt(raw[idx+1:], 10, 64)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return raw[:idx], seq, true
+}
+
+// serveStreamReplay 从缓冲区续读 seq 之后的事件，并跟随仍在进行的生成直到结束或客户端再次断开。
+func serveStreamReplay(c *gin.Context, s *replayStream, seq int64) bool {
+	events, done, notify, ok := s.replayFrom(seq)
+	if !ok {
+		return false
+	}
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	header.Set(StreamIDHeader, s.id)
+	header.Set(StreamReplayHeader, "hit")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	for {
+		for _, ev := range events {
+			if _, err := c.Writer.WriteString(formatReplayEvent(s.id, ev.seq, ev.data)); err != nil {
+				return true
+			}
+			seq = ev.seq
+		}
+		c.Writer.Flush()
+		if done {
+			return true
+		}
+		select {
+		case <-notify:
+		case <-c.Request.Context().Done():
+			return true
+		}
+		if events, done, notify, ok = s.replayFrom(seq); !ok {
+			// 续读过慢被淘汰，无法保证事件连续，直接结束
+			return true
+		}
+	}
+}
+
+// StreamReplayMiddleware 为启用流式续传的分组缓存进行中 SSE 响应的最近事件：
+// 客户端断线后携带 Last-Event-ID 重发同一请求即可从断点续读，无需重新生成；
+// 缓冲已失效时按普通请求处理并返回 X-Sub2API-Stream-Replay: miss。
+func StreamReplayMiddleware() gin.HandlerFunc {
+	return streamReplayMiddleware(defaultStreamReplayRegistry)
+}
+
+func streamReplayMiddleware(registry *streamReplayRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil || apiKey.Group == nil || !apiKey.Group.StreamReplay.Enabled {
+			c.Next()
+			return
+		}
+		if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+			if streamID, seq, ok := parseReplayEventID(lastEventID); ok {
+				if s := registry.lookup(streamID, apiKey.ID); s != nil && serveStreamReplay(c, s, seq) {
+					c.Abort()
+					return
+				}
+			}
+			c.Header(StreamReplayHeader, "miss")
+		}
+
+		originalWriter := c.Writer
+		w := &streamReplayWriter{
+			ResponseWriter: originalWriter,
+			registry:       registry,
+			apiKeyID:       apiKey.ID,
+			cfg:            apiKey.Group.StreamReplay,
+		}
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+			w.finish()
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamReplayRouter(registry *streamReplayRegistry, cfg service.GroupStreamReplayConfig, h gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		id := int64(1)
+		if c.GetHeader("X-Test-Key") == "2" {
+			id = 2
+		}
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: id, Group: &service.Group{StreamReplay: cfg}})
+		c.Next()
+	})
+	r.POST("/v1/messages", streamReplayMiddleware(registry), h)
+	return r
+}
+
+func writeTestSSE(c *gin.Context, events ...string) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Status(http.StatusOK)
+	for _, ev := range events {
+		_, _ = c.Writer.WriteString(ev)
+		c.Writer.Flush()
+	}
+}
+
+// failingRecorder 模拟客户端在写出 n 次后断开。
+type failingRecorder struct {
+	*httptest.ResponseRecorder
+	writesLeft int
+}
+
+func (r *failingRecorder) Write(b []byte) (int, error) {
+	if r.writesLeft <= 0 {
+		return 0, errors.New("broken pipe")
+	}
+	r.writesLeft--
+	return r.ResponseRecorder.Write(b)
+}
+
+func TestStreamReplay_InjectsEventIDsAndResumes(t *testing.T) {
+	registry := newStreamReplayRegistry()
+	var calls int
+	r := newStreamReplayRouter(registry, service.GroupStreamReplayConfig{Enabled: true}, func(c *gin.Context) {
+		calls++
+		writeTestSSE(c, "event: a\ndata: 1\n\n", ": ping\n\n", "event: b\ndata: 2\n\nevent: c\n", "data: 3\n\n")
+	})
+
+	// 客户端只收到第一个事件就断开，生成仍继续写入缓冲
+	rec := &failingRecorder{ResponseRecorder: httptest.NewRecorder(), writesLeft: 1}
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+	streamID := rec.Header().Get(StreamIDHeader)
+	require.NotEmpty(t, streamID)
+	require.Equal(t, "event: a\ndata: 1\nid: "+streamID+":1\n\n", rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`))
+	req.Header.Set("Last-Event-ID", streamID+":1")
+	replay := httptest.NewRecorder()
+	r.ServeHTTP(replay, req)
+	require.Equal(t, 1, calls, "reconnect must not re-run the generation")
+	require.Equal(t, "hit", replay.Header().Get(StreamReplayHeader))
+	require.Equal(t, "event: b\ndata: 2\nid: "+streamID+":2\n\nevent: c\ndata: 3\nid: "+streamID+":3\n\n", replay.Body.String())
+}
+
+func TestStreamReplay_FollowsInFlightStream(t *testing.T) {
+	registry := newStreamReplayRegistry()
+	release := make(chan struct{})
+	started := make(chan string, 1)
+	r := newStreamReplayRouter(registry, service.GroupStreamReplayConfig{Enabled: true}, func(c *gin.Context) {
+		writeTestSSE(c, "data: 1\n\n")
+		started <- c.Writer.Header().Get(StreamIDHeader)
+		<-release
+		writeTestSSE(c, "data: 2\n\n")
+	})
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+	streamID := <-started
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`))
+	req.Header.Set("Last-Event-ID", streamID+":0")
+	replay := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(replay, req)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("replay did not finish with the original stream")
+	}
+	require.Equal(t, "data: 1\nid: "+streamID+":1\n\ndata: 2\nid: "+streamID+":2\n\n", replay.Body.String())
+}
+
+func TestStreamReplay_Miss(t *testing.T) {
+	registry := newStreamReplayRegistry()
+	var calls int
+	r := newStreamReplayRouter(registry, service.GroupStreamReplayConfig{Enabled: true, MaxEvents: 1, RetentionSeconds: 1}, func(c *gin.Context) {
+		calls++
+		writeTestSSE(c, "data: 1\n\n", "data: 2\n\n")
+	})
+	first := httptest.NewRecorder()
+	r.ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+	streamID := first.Header().Get(StreamIDHeader)
+
+	cases := map[string]func(req *http.Request){
+		"other_api_key":   func(req *http.Request) { req.Header.Set("X-Test-Key", "2") },
+		"evicted_event":   func(req *http.Request) { req.Header.Set("Last-Event-ID", streamID+":0") },
+		"malformed_id":    func(req *http.Request) { req.Header.Set("Last-Event-ID", "garbage") },
+		"unknown_stream":  func(req *http.Request) { req.Header.Set("Last-Event-ID", "nope:1") },
+		"future_sequence": func(req *http.Request) { req.Header.Set("Last-Event-ID", streamID+":9") },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`))
+			req.Header.Set("Last-Event-ID", streamID+":1")
+			mutate(req)
+			rec := httptest.NewRecorder()
+			before := calls
+			r.ServeHTTP(rec, req)
+			require.Equal(t, before+1, calls)
+			require.Equal(t, "miss", rec.Header().Get(StreamReplayHeader))
+		})
+	}
+
+	// 结束后超过保留时长即失效
+	registry.now = func() time.Time { return time.Now().Add(time.Minute) }
+	require.Nil(t, registry.lookup(streamID, 1))
+}
+
+func TestStreamReplay_DisabledOrNonSSEPassthrough(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg service.GroupStreamReplayConfig
+		h   gin.HandlerFunc
+	}{
+		"disabled": {service.GroupStreamReplayConfig{}, func(c *gin.Context) { writeTestSSE(c, "data: 1\n\n") }},
+		"json":     {service.GroupStreamReplayConfig{Enabled: true}, func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }},
+		"error": {service.GroupStreamReplayConfig{Enabled: true}, func(c *gin.Context) {
+			c.Header("Content-Type", "text/event-stream")
+			c.String(http.StatusBadGateway, "data: 1\n\n")
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			registry := newStreamReplayRegistry()
+			rec := httptest.NewRecorder()
+			newStreamReplayRouter(registry, tc.cfg, tc.h).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+			require.Empty(t, rec.Header().Get(StreamIDHeader))
+			require.NotContains(t, rec.Body.String(), "id: ")
+			require.Empty(t, registry.streams)
+		})
+	}
+}
+
+func TestSplitSSEEvent(t *testing.T) {
+	body, n := splitSSEEvent([]byte("data: 1\r\n\r\ndata: 2"))
+	require.Equal(t, "data: 1", body)
+	require.Equal(t, 11, n)
+	_, n = splitSSEEvent([]byte("data: partial"))
+	require.Zero(t, n)
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// strictErrorMaxJSONBytes 错误体缓冲上限；超过后视为非错误负载直接透传。
+	strictErrorMaxJSONBytes = 1 << 20
+	// strictErrorMaxSSEEventBytes 单个 SSE 事件缓冲上限；超过后原样透传，避免大增量占用内存。
+	strictErrorMaxSSEEventBytes = 1 << 20
+	// strictErrorMaxRawMessageBytes 非 JSON 错误体作为 message 时的截断长度。
+	strictErrorMaxRawMessageBytes = 1024
+)
+
+var sseEventSeparator = []byte("\n\n")
+
+// strictErrorSchema 描述一种客户端协议的错误 schema，由 strictErrorWriter 在对应端点上套用。
+type strictErrorSchema interface {
+	// matches 判断入站端点是否使用该协议。
+	matches(endpoint string) bool
+	// errorBody 将任意网关错误体改写为该协议的非流式错误体。
+	errorBody(status int, raw []byte) []byte
+	// sseEvent 改写单个 SSE 事件（含结尾空行）中的错误；非错误事件原样返回。
+	sseEvent(event []byte, endpoint string) []byte
+}
+
+type strictErrorMode int
+
+const (
+	strictErrorUndecided strictErrorMode = iota
+	strictErrorPassthrough
+	strictErrorJSON
+	strictErrorSSE
+)
+
+// strictErrorWriter 在匹配 schema 的端点上统一错误形状：
+//   - 状态码 >= 400 的 JSON 响应先缓冲，结束时改写为 schema 规定的错误体；
+//   - SSE 流按事件切分，错误事件改写后写出，其余事件原样透传；
+//   - 其他端点与其他内容类型原样透传。
+type strictErrorWriter struct {
+	gin.ResponseWriter
+	c        *gin.Context
+	schema   strictErrorSchema
+	mode     strictErrorMode
+	endpoint string
+	buf      bytes.Buffer
+	buffered bool
+}
+
+// decide 在首次写出时判定模式：此时入站端点已由 InboundEndpointMiddleware 标注，状态码与 Content-Type 也已确定。
+func (w *strictErrorWriter) decide() {
+	if w.mode != strictErrorUndecided {
+		return
+	}
+	w.endpoint = GetInboundEndpoint(w.c)
+	if !w.schema.matches(w.endpoint) {
+		w.mode = strictErrorPassthrough
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(w.ResponseWriter.Header().Get("Content-Type")))
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		w.mode = strictErrorSSE
+	case w.ResponseWriter.Status() >= http.StatusBadRequest &&
+		(strings.HasPrefix(contentType, "application/json") || strings.Contains(contentType, "+json")):
+		w.mode = strictErrorJSON
+	default:
+		w.mode = strictErrorPassthrough
+	}
+}
+
+func (w *strictErrorWriter) Write(b []byte) (int, error) {
+	w.decide()
+	switch w.mode {
+	case strictErrorJSON:
+		if w.buf.Len()+len(b) > strictErrorMaxJSONBytes {
+			if err := w.flushBuffered(); err != nil {
+				return 0, err
+			}
+			return w.ResponseWriter.Write(b)
+		}
+		w.buffered = true
+		return w.buf.Write(b)
+	case strictErrorSSE:
+		return w.writeSSE(b)
+	default:
+		return w.ResponseWriter.Write(b)
+	}
+}
+
+func (w *strictErrorWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *strictErrorWriter) WriteHeaderNow() {
+	w.decide()
+	if w.mode == strictErrorJSON {
+		// 错误体改写后长度会变化，改写前不提交响应头。
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *strictErrorWriter) Written() bool {
+	return w.buffered || w.ResponseWriter.Written()
+}
+
+func (w *strictErrorWriter) Size() int {
+	if w.buffered {
+		return w.buf.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *strictErrorWriter) Flush() {
+	w.decide()
+	if w.mode == strictErrorJSON {
+		// 主动 flush 的 JSON 响应无法事后改写，退化为透传。
+		_ = w.flushBuffered()
+	}
+	// SSE 未完整的事件留在缓冲中，待分隔符到达后再写出。
+	w.ResponseWriter.Flush()
+}
+
+func (w *strictErrorWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mode = strictErrorPassthrough
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *strictErrorWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.CloseNotify()
+}
+
+// flushBuffered 原样写出已缓冲的字节并切换为透传模式。
+func (w *strictErrorWriter) flushBuffered() error {
+	w.mode = strictErrorPassthrough
+	if !w.buffered {
+		return nil
+	}
+	w.buffered = false
+	data := w.buf.Bytes()
+	w.buf = bytes.Buffer{}
+	if len(data) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
+
+// writeSSE 缓冲到完整事件（以空行结尾）后逐个改写写出。
+func (w *strictErrorWriter) writeSSE(b []byte) (int, error) {
+	n := len(b)
+	w.buf.Write(b)
+	data := w.buf.Bytes()
+
+	var out []byte
+	for {
+		idx := bytes.Index(data, sseEventSeparator)
+		if idx < 0 {
+			break
+		}
+		end := idx + len(sseEventSeparator)
+		out = append(out, w.schema.sseEvent(data[:end], w.endpoint)...)
+		data = data[end:]
+	}
+	if len(data) > strictErrorMaxSSEEventBytes {
+		out = append(out, data...)
+		data = nil
+	}
+	rest := append([]byte(nil), data...)
+	w.buf = bytes.Buffer{}
+	w.buf.Write(rest)
+
+	if len(out) == 0 {
+		return n, nil
+	}
+	if _, err := w.ResponseWriter.Write(out); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// finish 在 handler 返回后写出改写后的错误体，或流末尾未以空行结束的残留事件。
+func (w *strictErrorWriter) finish() {
+	switch {
+	case w.mode == strictErrorSSE && w.buf.Len() > 0:
+		data := append([]byte(nil), w.buf.Bytes()...)
+		w.buf = bytes.Buffer{}
+		w.mode = strictErrorPassthrough
+		_, _ = w.ResponseWriter.Write(w.schema.sseEvent(data, w.endpoint))
+	case w.mode == strictErrorJSON:
+		status := w.ResponseWriter.Status()
+		data := w.buf.Bytes()
+		w.buf = bytes.Buffer{}
+		w.buffered = false
+		w.mode = strictErrorPassthrough
+		header := w.ResponseWriter.Header()
+		header.Del("Content-Length")
+		header.Set("Content-Type", "application/json; charset=utf-8")
+		w.ResponseWriter.WriteHeader(status)
+		_, _ = w.ResponseWriter.Write(w.schema.errorBody(status, data))
+	}
+}
+
+// strictErrorMiddleware 以 schema 包装响应写出；enabled=false 时为空操作。
+func strictErrorMiddleware(enabled bool, schema strictErrorSchema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		originalWriter := c.Writer
+		w := &strictErrorWriter{ResponseWriter: originalWriter, c: c, schema: schema}
+		c.Writer = w
+		defer func() {
+			if c.Writer == w {
+				c.Writer = originalWriter
+			}
+		}()
+		c.Next()
+		w.finish()
+	}
+}
+
+// truncateStrictErrorMessage 非 JSON 错误体整体作为 message，超长截断，空体回落到状态文本。
+func truncateStrictErrorMessage(status int, raw []byte) string {
+	message := strings.TrimSpace(string(raw))
+	if len(message) > strictErrorMaxRawMessageBytes {
+		message = message[:strictErrorMaxRawMessageBytes]
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return message
+}
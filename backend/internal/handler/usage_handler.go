@@ -660,6 +660,23 @@ func (h *UsageHandler) DashboardAPIKeysUsage(c *gin.Context) {
 	response.Success(c, gin.H{"stats": stats})
 }
 
+// ThreadCost handles summing usage of all turns in one of the current user's threads.
+// GET /api/v1/usage/threads/:thread_id
+func (h *UsageHandler) ThreadCost(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	cost, err := h.usageService.GetThreadCost(c.Request.Context(), subject.UserID, c.Param("thread_id"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, cost)
+}
+
 // GetMyAPIKeyDailyUsage handles getting daily usage details for the current user's API key.
 // GET /api/v1/user/api-keys/:id/usage/daily?days=30
 func (h *UsageHandler) GetMyAPIKeyDailyUsage(c *gin.Context) {
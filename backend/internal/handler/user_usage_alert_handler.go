@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserUsageAlertHandler 处理用户自助用量告警规则（webhook / 邮件），与管理员 ops 告警独立。
+type UserUsageAlertHandler struct {
+	usageAlertService *service.UserUsageAlertService
+}
+
+// NewUserUsageAlertHandler creates a new UserUsageAlertHandler
+func NewUserUsageAlertHandler(usageAlertService *service.UserUsageAlertService) *UserUsageAlertHandler {
+	return &UserUsageAlertHandler{usageAlertService: usageAlertService}
+}
+
+// UserUsageAlertRequest 创建/更新用量告警规则的请求体。
+// api_key_id 为空或 0 表示统计当前用户全部 Key；enabled 省略时默认启用。
+type UserUsageAlertRequest struct {
+	APIKeyID      *int64  `json:"api_key_id"`
+	Metric        string  `json:"metric" binding:"required"`
+	WindowMinutes int     `json:"window_minutes" binding:"required"`
+	Threshold     float64 `json:"threshold" binding:"required"`
+	WebhookURL    string  `json:"webhook_url"`
+	NotifyEmail   bool    `json:"notify_email"`
+	Enabled       *bool   `json:"enabled"`
+}
+
+func (r *UserUsageAlertRequest) toInput() service.UserUsageAlertInput {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return service.UserUsageAlertInput{
+		APIKeyID:      r.APIKeyID,
+		Metric:        r.Metric,
+		WindowMinutes: r.WindowMinutes,
+		Threshold:     r.Threshold,
+		WebhookURL:    r.WebhookURL,
+		NotifyEmail:   r.NotifyEmail,
+		Enabled:       enabled,
+	}
+}
+
+// List handles listing the current user's usage alerts
+// GET /api/v1/user/usage-alerts
+func (h *UserUsageAlertHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	alerts, err := h.usageAlertService.List(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, alerts)
+}
+
+// Create handles creating a usage alert
+// POST /api/v1/user/usage-alerts
+func (h *UserUsageAlertHandler) Create(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req UserUsageAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	alert, err := h.usageAlertService.Create(c.Request.Context(), subject.UserID, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, alert)
+}
+
+// Update handles updating a usage alert
+// PUT /api/v1/user/usage-alerts/:id
+func (h *UserUsageAlertHandler) Update(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	alertID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid alert ID")
+		return
+	}
+
+	var req UserUsageAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	alert, err := h.usageAlertService.Update(c.Request.Context(), subject.UserID, alertID, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, alert)
+}
+
+// Delete handles deleting a usage alert
+// DELETE /api/v1/user/usage-alerts/:id
+func (h *UserUsageAlertHandler) Delete(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	alertID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid alert ID")
+		return
+	}
+
+	if err := h.usageAlertService.Delete(c.Request.Context(), subject.UserID, alertID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Usage alert deleted successfully"})
+}
+
+// Test handles sending a test notification for a usage alert
+// POST /api/v1/user/usage-alerts/:id/test
+func (h *UserUsageAlertHandler) Test(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	alertID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid alert ID")
+		return
+	}
+
+	if err := h.usageAlertService.SendTest(c.Request.Context(), subject.UserID, alertID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Test notification sent"})
+}
@@ -41,6 +41,25 @@ func ProvideAdminHandlers(
 	paymentHandler *admin.PaymentHandler,
 	affiliateHandler *admin.AffiliateHandler,
 	complianceHandler *admin.ComplianceHandler,
+	configSyncHandler *admin.ConfigSyncHandler,
+	apiKeyTraceHandler *admin.APIKeyTraceHandler,
+	billingStatementHandler *admin.BillingStatementHandler,
+	capacityForecastHandler *admin.CapacityForecastHandler,
+	cacheEfficiencyHandler *admin.CacheEfficiencyHandler,
+	clientAnalyticsHandler *admin.ClientAnalyticsHandler,
+	mappingSimulationHandler *admin.MappingSimulationHandler,
+	staleAccountHandler *admin.StaleAccountHandler,
+	configVersionHandler *admin.ConfigVersionHandler,
+	graphQLHandler *admin.GraphQLHandler,
+	debugHandler *admin.DebugHandler,
+	transformFixtureHandler *admin.TransformFixtureHandler,
+	spendBudgetHandler *admin.SpendBudgetHandler,
+	modelPriceHandler *admin.ModelPriceHandler,
+	usageReportHandler *admin.UsageReportHandler,
+	dataExportHandler *admin.DataExportHandler,
+	requestClassSLOHandler *admin.RequestClassSLOHandler,
+	serviceTokenHandler *admin.ServiceTokenHandler,
+	accountLeaseHandler *admin.AccountLeaseHandler,
 ) *AdminHandlers {
 	return &AdminHandlers{
 		Dashboard:              dashboardHandler,
@@ -75,6 +94,25 @@ func ProvideAdminHandlers(
 		Payment:                paymentHandler,
 		Affiliate:              affiliateHandler,
 		Compliance:             complianceHandler,
+		ConfigSync:             configSyncHandler,
+		APIKeyTrace:            apiKeyTraceHandler,
+		BillingStatement:       billingStatementHandler,
+		CapacityForecast:       capacityForecastHandler,
+		CacheEfficiency:        cacheEfficiencyHandler,
+		ClientAnalytics:        clientAnalyticsHandler,
+		MappingSimulation:      mappingSimulationHandler,
+		StaleAccount:           staleAccountHandler,
+		ConfigVersion:          configVersionHandler,
+		GraphQL:                graphQLHandler,
+		Debug:                  debugHandler,
+		TransformFixture:       transformFixtureHandler,
+		SpendBudget:            spendBudgetHandler,
+		ModelPrice:             modelPriceHandler,
+		UsageReport:            usageReportHandler,
+		DataExport:             dataExportHandler,
+		RequestClassSLO:        requestClassSLOHandler,
+		ServiceToken:           serviceTokenHandler,
+		AccountLease:           accountLeaseHandler,
 	}
 }
 
@@ -116,6 +154,10 @@ func ProvideHandlers(
 	paymentWebhookHandler *PaymentWebhookHandler,
 	availableChannelHandler *AvailableChannelHandler,
 	batchImageHandler *BatchImageHandler,
+	usageAlertHandler *UserUsageAlertHandler,
+	billingStatementHandler *BillingStatementHandler,
+	balanceHandler *BalanceHandler,
+	projectHandler *APIKeyProjectHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
@@ -137,6 +179,10 @@ func ProvideHandlers(
 		PaymentWebhook:   paymentWebhookHandler,
 		AvailableChannel: availableChannelHandler,
 		BatchImage:       batchImageHandler,
+		UsageAlert:       usageAlertHandler,
+		BillingStatement: billingStatementHandler,
+		Balance:          balanceHandler,
+		Project:          projectHandler,
 	}
 }
 
@@ -159,6 +205,10 @@ var ProviderSet = wire.NewSet(
 	NewPaymentWebhookHandler,
 	NewAvailableChannelHandler,
 	NewBatchImageHandler,
+	NewUserUsageAlertHandler,
+	NewBillingStatementHandler,
+	NewBalanceHandler,
+	NewAPIKeyProjectHandler,
 
 	// Admin handlers
 	admin.NewDashboardHandler,
@@ -193,6 +243,25 @@ var ProviderSet = wire.NewSet(
 	admin.NewPaymentHandler,
 	admin.NewAffiliateHandler,
 	admin.NewComplianceHandler,
+	admin.NewConfigSyncHandler,
+	admin.NewAPIKeyTraceHandler,
+	admin.NewBillingStatementHandler,
+	admin.NewCapacityForecastHandler,
+	admin.NewCacheEfficiencyHandler,
+	admin.NewClientAnalyticsHandler,
+	admin.NewMappingSimulationHandler,
+	admin.NewStaleAccountHandler,
+	admin.NewConfigVersionHandler,
+	admin.NewGraphQLHandler,
+	admin.NewDebugHandler,
+	admin.NewTransformFixtureHandler,
+	admin.NewSpendBudgetHandler,
+	admin.NewModelPriceHandler,
+	admin.NewUsageReportHandler,
+	admin.NewDataExportHandler,
+	admin.NewRequestClassSLOHandler,
+	admin.NewServiceTokenHandler,
+	admin.NewAccountLeaseHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
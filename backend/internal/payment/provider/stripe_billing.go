@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	stripe "github.com/stripe/stripe-go/v85"
+	"github.com/stripe/stripe-go/v85/webhook"
+)
+
+// Stripe invoice webhook event types handled for billing statements.
+const (
+	StripeEventInvoicePaid   = "invoice.paid"
+	StripeEventInvoiceVoided = "invoice.voided"
+)
+
+// stripeBillingStatementMetadataKey links Stripe objects back to the local statement.
+const stripeBillingStatementMetadataKey = "statementId"
+
+// StripeCustomerRequest describes the customer to create for a user.
+type StripeCustomerRequest struct {
+	UserID int64
+	Email  string
+	Name   string
+}
+
+// StripeInvoiceRequest describes a one-line invoice for a billing statement.
+type StripeInvoiceRequest struct {
+	StatementID  int64
+	CustomerID   string
+	Amount       string // major units, e.g. "12.34"
+	Description  string
+	DaysUntilDue int // >0 = send_invoice, 0 = charge_automatically
+}
+
+// StripeInvoiceResult is the finalized invoice.
+type StripeInvoiceResult struct {
+	InvoiceID string
+	HostedURL string
+	AmountDue int64
+	Currency  string
+}
+
+// StripeMeterEventRequest reports a statement amount to a Billing Meter.
+type StripeMeterEventRequest struct {
+	StatementID int64
+	CustomerID  string
+	EventName   string
+	Amount      string // major units; reported as minor units
+	Timestamp   int64  // unix seconds, 0 = now
+}
+
+// StripeInvoiceEvent is a verified invoice webhook event.
+type StripeInvoiceEvent struct {
+	Type        string
+	InvoiceID   string
+	StatementID int64
+}
+
+// StripeBilling pushes billing statements to Stripe (customers, invoices, meter events).
+// It is independent from the Stripe payment provider used for top-up orders.
+type StripeBilling struct {
+	sc            *stripe.Client
+	currency      string
+	webhookSecret string
+}
+
+// NewStripeBilling creates a Stripe billing client.
+func NewStripeBilling(secretKey, webhookSecret, currency string) (*StripeBilling, error) {
+	if strings.TrimSpace(secretKey) == "" {
+		return nil, fmt.Errorf("stripe billing: secret key is required")
+	}
+	normalized, err := payment.NormalizePaymentCurrency(currency)
+	if err != nil {
+		return nil, fmt.Errorf("stripe billing currency: %w", err)
+	}
+	return &StripeBilling{
+		sc:            stripe.NewClient(secretKey),
+		currency:      normalized,
+		webhookSecret: webhookSecret,
+	}, nil
+}
+
+// Currency returns the normalized (upper-case) billing currency.
+func (s *StripeBilling) Currency() string { return s.currency }
+
+// CreateCustomer creates a Stripe customer for a user.
+func (s *StripeBilling) CreateCustomer(ctx context.Context, req StripeCustomerRequest) (string, error) {
+	params := &stripe.CustomerCreateParams{
+		Metadata: map[string]string{"userId": strconv.FormatInt(req.UserID, 10)},
+	}
+	if email := strings.TrimSpace(req.Email); email != "" {
+		params.Email = stripe.String(email)
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		params.Name = stripe.String(name)
+	}
+	params.SetIdempotencyKey(fmt.Sprintf("cus-user-%d", req.UserID))
+	params.Context = ctx
+
+	cus, err := s.sc.V1Customers.Create(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("stripe create customer: %w", err)
+	}
+	return cus.ID, nil
+}
+
+// CreateInvoice creates, fills and finalizes an invoice for a statement.
+// All calls use statement-scoped idempotency keys so retries never double-bill.
+func (s *StripeBilling) CreateInvoice(ctx context.Context, req StripeInvoiceRequest) (*StripeInvoiceResult, error) {
+	amount, err := payment.AmountToMinorUnit(req.Amount, s.currency)
+	if err != nil {
+		return nil, fmt.Errorf("stripe create invoice: %w", err)
+	}
+	metadata := map[string]string{stripeBillingStatementMetadataKey: strconv.FormatInt(req.StatementID, 10)}
+	currency := strings.ToLower(s.currency)
+
+	invParams := &stripe.InvoiceCreateParams{
+		Customer:    stripe.String(req.CustomerID),
+		Currency:    stripe.String(currency),
+		Description: stripe.String(req.Description),
+		AutoAdvance: stripe.Bool(false),
+		Metadata:    metadata,
+	}
+	if req.DaysUntilDue > 0 {
+		invParams.CollectionMethod = stripe.String(string(stripe.InvoiceCollectionMethodSendInvoice))
+		invParams.DaysUntilDue = stripe.Int64(int64(req.DaysUntilDue))
+	} else {
+		invParams.CollectionMethod = stripe.String(string(stripe.InvoiceCollectionMethodChargeAutomatically))
+	}
+	invParams.SetIdempotencyKey(fmt.Sprintf("stmt-inv-%d", req.StatementID))
+	invParams.Context = ctx
+	inv, err := s.sc.V1Invoices.Create(ctx, invParams)
+	if err != nil {
+		return nil, fmt.Errorf("stripe create invoice: %w", err)
+	}
+
+	itemParams := &stripe.InvoiceItemCreateParams{
+		Customer:    stripe.String(req.CustomerID),
+		Invoice:     stripe.String(inv.ID),
+		Amount:      stripe.Int64(amount),
+		Currency:    stripe.String(currency),
+		Description: stripe.String(req.Description),
+		Metadata:    metadata,
+	}
+	itemParams.SetIdempotencyKey(fmt.Sprintf("stmt-item-%d", req.StatementID))
+	itemParams.Context = ctx
+	if _, err := s.sc.V1InvoiceItems.Create(ctx, itemParams); err != nil {
+		return nil, fmt.Errorf("stripe create invoice item: %w", err)
+	}
+
+	finParams := &stripe.InvoiceFinalizeInvoiceParams{AutoAdvance: stripe.Bool(true)}
+	finParams.Context = ctx
+	inv, err = s.sc.V1Invoices.FinalizeInvoice(ctx, inv.ID, finParams)
+	if err != nil {
+		return nil, fmt.Errorf("stripe finalize invoice: %w", err)
+	}
+	if req.DaysUntilDue > 0 {
+		sendParams := &stripe.InvoiceSendInvoiceParams{}
+		sendParams.Context = ctx
+		if sent, err := s.sc.V1Invoices.SendInvoice(ctx, inv.ID, sendParams); err == nil {
+			inv = sent
+		}
+		// 发送失败不影响账单已定稿，Stripe 侧仍可手动发送
+	}
+
+	return &StripeInvoiceResult{
+		InvoiceID: inv.ID,
+		HostedURL: inv.HostedInvoiceURL,
+		AmountDue: inv.AmountDue,
+		Currency:  s.currency,
+	}, nil
+}
+
+// ReportMeterEvent reports a statement amount (in minor units) to a Billing Meter.
+// The statement id is used as the event identifier so Stripe de-duplicates retries.
+func (s *StripeBilling) ReportMeterEvent(ctx context.Context, req StripeMeterEventRequest) error {
+	amount, err := payment.AmountToMinorUnit(req.Amount, s.currency)
+	if err != nil {
+		return fmt.Errorf("stripe meter event: %w", err)
+	}
+	params := &stripe.BillingMeterEventCreateParams{
+		EventName:  stripe.String(req.EventName),
+		Identifier: stripe.String(fmt.Sprintf("stmt-%d", req.StatementID)),
+		Payload: map[string]string{
+			"stripe_customer_id": req.CustomerID,
+			"value":              strconv.FormatInt(amount, 10),
+		},
+	}
+	if req.Timestamp > 0 {
+		params.Timestamp = stripe.Int64(req.Timestamp)
+	}
+	params.Context = ctx
+	if _, err := s.sc.V1BillingMeterEvents.Create(ctx, params); err != nil {
+		return fmt.Errorf("stripe meter event: %w", err)
+	}
+	return nil
+}
+
+// ParseInvoiceEvent verifies a webhook payload and extracts invoice events.
+// Returns (nil, nil) for event types that are not relevant to statements.
+func (s *StripeBilling) ParseInvoiceEvent(payload []byte, signature string) (*StripeInvoiceEvent, error) {
+	if s.webhookSecret == "" {
+		return nil, fmt.Errorf("stripe billing webhook secret not configured")
+	}
+	if signature == "" {
+		return nil, fmt.Errorf("stripe notification missing stripe-signature header")
+	}
+	event, err := webhook.ConstructEvent(payload, signature, s.webhookSecret)
+	if err != nil {
+		return nil, fmt.Errorf("stripe verify notification: %w", err)
+	}
+	switch string(event.Type) {
+	case StripeEventInvoicePaid, StripeEventInvoiceVoided:
+	default:
+		return nil, nil
+	}
+
+	var inv stripe.Invoice
+	if err := json.Unmarshal(event.Data.Raw, &inv); err != nil {
+		return nil, fmt.Errorf("stripe parse invoice: %w", err)
+	}
+	out := &StripeInvoiceEvent{Type: string(event.Type), InvoiceID: inv.ID}
+	if raw := inv.Metadata[stripeBillingStatementMetadataKey]; raw != "" {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			out.StatementID = id
+		}
+	}
+	return out, nil
+}
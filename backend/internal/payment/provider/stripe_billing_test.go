@@ -0,0 +1,58 @@
+//go:build unit
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	stripe "github.com/stripe/stripe-go/v85"
+	"github.com/stripe/stripe-go/v85/webhook"
+)
+
+func signedStripeBillingEvent(t *testing.T, secret, eventType, invoiceJSON string) ([]byte, string) {
+	t.Helper()
+	payload := []byte(fmt.Sprintf(`{"id":"evt_1","object":"event","api_version":%q,"type":%q,"data":{"object":%s}}`,
+		stripe.APIVersion, eventType, invoiceJSON))
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: secret})
+	return payload, signed.Header
+}
+
+func TestNewStripeBillingValidatesConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStripeBilling("", "whsec", "USD")
+	require.Error(t, err)
+
+	_, err = NewStripeBilling("sk_test", "whsec", "US$")
+	require.Error(t, err)
+
+	sb, err := NewStripeBilling("sk_test", "whsec", "usd")
+	require.NoError(t, err)
+	require.Equal(t, "USD", sb.Currency())
+}
+
+func TestStripeBillingParseInvoiceEvent(t *testing.T) {
+	t.Parallel()
+
+	sb, err := NewStripeBilling("sk_test", "whsec_test", "USD")
+	require.NoError(t, err)
+
+	payload, sig := signedStripeBillingEvent(t, "whsec_test", StripeEventInvoicePaid,
+		`{"id":"in_123","object":"invoice","metadata":{"statementId":"42"}}`)
+	event, err := sb.ParseInvoiceEvent(payload, sig)
+	require.NoError(t, err)
+	require.Equal(t, &StripeInvoiceEvent{Type: StripeEventInvoicePaid, InvoiceID: "in_123", StatementID: 42}, event)
+
+	// 与账单无关的事件被忽略
+	payload, sig = signedStripeBillingEvent(t, "whsec_test", "customer.created", `{"id":"cus_1","object":"customer"}`)
+	event, err = sb.ParseInvoiceEvent(payload, sig)
+	require.NoError(t, err)
+	require.Nil(t, event)
+
+	// 签名错误
+	payload, sig = signedStripeBillingEvent(t, "whsec_other", StripeEventInvoicePaid, `{"id":"in_123","object":"invoice"}`)
+	_, err = sb.ParseInvoiceEvent(payload, sig)
+	require.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponsesToAnthropicRequest_InputFile(t *testing.T) {
+	convert := func(t *testing.T, part string) []AnthropicContentBlock {
+		t.Helper()
+		req := &ResponsesRequest{
+			Model: "claude-sonnet-4-20250514",
+			Input: json.RawMessage(`[{"role":"user","content":[{"type":"input_text","text":"read this"},` + part + `]}]`),
+		}
+		result, err := ResponsesToAnthropicRequest(req)
+		require.NoError(t, err)
+		require.Len(t, result.Messages, 1)
+		var blocks []AnthropicContentBlock
+		require.NoError(t, json.Unmarshal(result.Messages[0].Content, &blocks))
+		return blocks
+	}
+
+	t.Run("pdf_becomes_base64_document", func(t *testing.T) {
+		blocks := convert(t, `{"type":"input_file","filename":"a.pdf","file_data":"data:application/pdf;base64,JVBERi0="}`)
+		require.Len(t, blocks, 2)
+		assert.Equal(t, "document", blocks[1].Type)
+		assert.Equal(t, "a.pdf", blocks[1].Title)
+		require.NotNil(t, blocks[1].Source)
+		assert.Equal(t, "base64", blocks[1].Source.Type)
+		assert.Equal(t, "application/pdf", blocks[1].Source.MediaType)
+		assert.Equal(t, "JVBERi0=", blocks[1].Source.Data)
+	})
+
+	t.Run("text_becomes_text_document", func(t *testing.T) {
+		blocks := convert(t, `{"type":"input_file","file_data":"data:text/markdown;base64,IyBoaQ=="}`)
+		require.Len(t, blocks, 2)
+		require.NotNil(t, blocks[1].Source)
+		assert.Equal(t, "text", blocks[1].Source.Type)
+		assert.Equal(t, "text/plain", blocks[1].Source.MediaType)
+		assert.Equal(t, "# hi", blocks[1].Source.Data)
+	})
+
+	t.Run("unsupported_or_url_dropped", func(t *testing.T) {
+		blocks := convert(t, `{"type":"input_file","file_data":"data:application/zip;base64,UEsDBA=="},{"type":"input_file","file_url":"https://example.com/a.pdf"}`)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, "text", blocks[0].Type)
+	})
+}
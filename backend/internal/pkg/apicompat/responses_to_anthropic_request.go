@@ -1,6 +1,7 @@
 package apicompat
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -394,6 +395,10 @@ func convertResponsesUserToAnthropicContent(raw json.RawMessage) (json.RawMessag
 					Source: src,
 				})
 			}
+		case "input_file":
+			if doc := dataURIToAnthropicDocument(p.FileData, p.Filename); doc != nil {
+				blocks = append(blocks, *doc)
+			}
 		}
 	}
 
@@ -481,6 +486,30 @@ func dataURIToAnthropicImageSource(dataURI string) *AnthropicImageSource {
 	}
 }
 
+// dataURIToAnthropicDocument converts an inline input_file (data URI) into an
+// Anthropic document block. Only PDF and text/* files are supported upstream;
+// other media types return nil and are dropped.
+func dataURIToAnthropicDocument(dataURI, filename string) *AnthropicContentBlock {
+	src := dataURIToAnthropicImageSource(dataURI)
+	if src == nil {
+		return nil
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(src.MediaType))
+	switch {
+	case mediaType == "application/pdf":
+		src.MediaType = mediaType
+	case strings.HasPrefix(mediaType, "text/"):
+		decoded, err := base64.StdEncoding.DecodeString(src.Data)
+		if err != nil {
+			return nil
+		}
+		src = &AnthropicImageSource{Type: "text", MediaType: "text/plain", Data: string(decoded)}
+	default:
+		return nil
+	}
+	return &AnthropicContentBlock{Type: "document", Source: src, Title: filename}
+}
+
 // mergeConsecutiveMessages merges consecutive messages with the same role
 // because Anthropic requires alternating user/assistant turns.
 func mergeConsecutiveMessages(messages []AnthropicMessage) []AnthropicMessage {
@@ -61,8 +61,9 @@ type AnthropicContentBlock struct {
 	// type=thinking
 	Thinking string `json:"thinking,omitempty"`
 
-	// type=image
+	// type=image / type=document
 	Source *AnthropicImageSource `json:"source,omitempty"`
+	Title  string                `json:"title,omitempty"` // type=document
 
 	// type=tool_use
 	ID    string          `json:"id,omitempty"`
@@ -97,9 +98,9 @@ func (b AnthropicContentBlock) MarshalJSON() ([]byte, error) {
 	}
 }
 
-// AnthropicImageSource describes the source data for an image content block.
+// AnthropicImageSource describes the source data for an image or document content block.
 type AnthropicImageSource struct {
-	Type      string `json:"type"` // "base64"
+	Type      string `json:"type"` // "base64" | "text" (document only)
 	MediaType string `json:"media_type"`
 	Data      string `json:"data"`
 }
@@ -242,9 +243,13 @@ type ResponsesInputItem struct {
 
 // ResponsesContentPart is a typed content part in a Responses message.
 type ResponsesContentPart struct {
-	Type     string `json:"type"` // "input_text" | "output_text" | "input_image"
+	Type     string `json:"type"` // "input_text" | "output_text" | "input_image" | "input_file"
 	Text     string `json:"text,omitempty"`
 	ImageURL string `json:"image_url,omitempty"` // data URI for input_image
+
+	// type=input_file
+	FileData string `json:"file_data,omitempty"` // data URI
+	Filename string `json:"filename,omitempty"`
 }
 
 // ResponsesTool describes a tool in the Responses API.
@@ -0,0 +1,260 @@
+// Package cassette 提供 VCR 式的上游交互录制与回放：预发环境把脱敏后的上游请求/响应录成 cassette 文件，
+// CI 与本地运行时以 cassette 作为假上游回放，无需真实凭证即可回归测试请求转换与流处理。
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
+)
+
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+
+	// BodyEncodingBase64 响应体不是合法 UTF-8（如图片）时以 base64 保存。
+	BodyEncodingBase64 = "base64"
+
+	redactedValue = "***"
+)
+
+// sensitiveHeaders 录制时替换为 *** 的请求/响应头（小写）；名称含 auth/token/secret/cookie/api-key 的头同样处理。
+var sensitiveHeaders = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"x-api-key":           {},
+	"x-goog-api-key":      {},
+	"cookie":              {},
+	"set-cookie":          {},
+	"chatgpt-account-id":  {},
+	"openai-organization": {},
+	"openai-project":      {},
+}
+
+var sensitiveHeaderFragments = []string{"auth", "token", "secret", "cookie", "api-key", "apikey"}
+
+// droppedResponseHeaders 录制的是解压后的完整响应体，长度与编码相关的头不再成立。
+var droppedResponseHeaders = map[string]struct{}{
+	"content-length":    {},
+	"content-encoding":  {},
+	"transfer-encoding": {},
+	"connection":        {},
+}
+
+var sensitiveQueryParams = map[string]struct{}{
+	"key":           {},
+	"api_key":       {},
+	"access_token":  {},
+	"refresh_token": {},
+	"client_secret": {},
+}
+
+// Cassette 一组上游交互；录制时每次交互单独成文件，测试可按需把多个交互合并到一个文件。
+type Cassette struct {
+	Name         string         `json:"name,omitempty"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction 一次脱敏后的上游请求与响应。
+type Interaction struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+}
+
+type Request struct {
+	Method  string            `json:"method"`
+	Host    string            `json:"host,omitempty"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode   int               `json:"status_code"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         string            `json:"body,omitempty"`
+	BodyEncoding string            `json:"body_encoding,omitempty"`
+}
+
+// BodyBytes 返回解码后的响应体。
+func (r *Response) BodyBytes() ([]byte, error) {
+	if r.BodyEncoding == BodyEncodingBase64 {
+		return base64.StdEncoding.DecodeString(r.Body)
+	}
+	return []byte(r.Body), nil
+}
+
+// Load 读取单个 cassette 文件。
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// LoadDir 按文件名顺序读取目录下所有 *.json cassette 并合并交互。
+func LoadDir(dir string) (*Cassette, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	merged := &Cassette{Name: filepath.Base(dir), Interactions: []*Interaction{}}
+	for _, path := range paths {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		merged.Interactions = append(merged.Interactions, c.Interactions...)
+	}
+	return merged, nil
+}
+
+// Save 以缩进 JSON 写入 cassette，先写临时文件再 rename。
+func Save(path string, c *Cassette) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cassette-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// NewInteraction 由上游请求与完整响应体构建脱敏后的交互。
+func NewInteraction(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, recordedAt time.Time) *Interaction {
+	in := &Interaction{RecordedAt: recordedAt.UTC(), Request: NewRequest(req, reqBody)}
+	in.Response.StatusCode = resp.StatusCode
+	in.Response.Headers = sanitizeHeaders(resp.Header, droppedResponseHeaders)
+	switch {
+	case !utf8.Valid(respBody):
+		in.Response.Body = base64.StdEncoding.EncodeToString(respBody)
+		in.Response.BodyEncoding = BodyEncodingBase64
+	case strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/event-stream"):
+		in.Response.Body = sanitizeSSE(string(respBody))
+	default:
+		in.Response.Body = SanitizeBody(respBody)
+	}
+	return in
+}
+
+// NewRequest 构建脱敏后的上游请求记录（凭证头替换为 ***，query 中的 key 删除，JSON 体中的凭证字段脱敏）。
+func NewRequest(req *http.Request, body []byte) Request {
+	out := Request{Method: req.Method}
+	if req.URL != nil {
+		out.Host = req.URL.Host
+		out.Path = req.URL.Path
+		out.Query = sanitizeQuery(req.URL.RawQuery)
+	}
+	out.Headers = sanitizeHeaders(req.Header, nil)
+	out.Body = SanitizeBody(body)
+	return out
+}
+
+// SanitizeBody 对 JSON 请求/响应体中的凭证字段脱敏（JSON 会被重新序列化）；非 JSON 按文本规则脱敏。
+// 回放匹配时对实际请求体做同样处理，保证两侧可比。
+func SanitizeBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if json.Valid(body) {
+		return logredact.RedactJSON(body)
+	}
+	return logredact.RedactText(string(body))
+}
+
+// sanitizeSSE 逐行处理 SSE：只对 data 行中的 JSON 脱敏，保留事件边界与其它行原样。
+func sanitizeSSE(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		payload, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		trimmed := strings.TrimSpace(strings.TrimRight(payload, "\r"))
+		if !strings.HasPrefix(trimmed, "{") || !json.Valid([]byte(trimmed)) {
+			continue
+		}
+		suffix := ""
+		if strings.HasSuffix(line, "\r") {
+			suffix = "\r"
+		}
+		lines[i] = "data: " + logredact.RedactJSON([]byte(trimmed)) + suffix
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sanitizeHeaders(h http.Header, drop map[string]struct{}) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		lower := strings.ToLower(name)
+		if _, ok := drop[lower]; ok {
+			continue
+		}
+		if isSensitiveHeader(lower) {
+			out[http.CanonicalHeaderKey(name)] = redactedValue
+			continue
+		}
+		out[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+func isSensitiveHeader(lower string) bool {
+	if _, ok := sensitiveHeaders[lower]; ok {
+		return true
+	}
+	for _, fragment := range sensitiveHeaderFragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	for name := range values {
+		if _, ok := sensitiveQueryParams[strings.ToLower(name)]; ok {
+			values.Del(name)
+		}
+	}
+	return values.Encode()
+}
@@ -0,0 +1,134 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newUpstreamRequest(t *testing.T, method, rawURL, body string) *http.Request {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = bytes.NewReader([]byte(body))
+	}
+	req, err := http.NewRequest(method, rawURL, reader)
+	require.NoError(t, err)
+	return req
+}
+
+func TestNewInteraction_Sanitizes(t *testing.T) {
+	req := newUpstreamRequest(t, http.MethodPost, "https://generativelanguage.googleapis.com/v1beta/models/x:generateContent?key=AIzaSecret&alt=sse", "")
+	req.Header.Set("Authorization", "Bearer sk-live")
+	req.Header.Set("X-Custom-Token", "t")
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":     []string{"text/event-stream"},
+			"Content-Encoding": []string{"gzip"},
+			"Set-Cookie":       []string{"sid=1"},
+		},
+	}
+	respBody := "event: token\ndata: {\"access_token\":\"at-secret\",\"text\":\"hi\"}\r\n\r\ndata: [DONE]\n\n"
+
+	in := NewInteraction(req, []byte(`{"refresh_token":"rt-secret","model":"m"}`), resp, []byte(respBody), time.Unix(0, 0))
+
+	require.Equal(t, "/v1beta/models/x:generateContent", in.Request.Path)
+	require.Equal(t, "alt=sse", in.Request.Query)
+	require.Equal(t, "***", in.Request.Headers["Authorization"])
+	require.Equal(t, "***", in.Request.Headers["X-Custom-Token"])
+	require.Equal(t, "2023-06-01", in.Request.Headers["Anthropic-Version"])
+	require.NotContains(t, in.Request.Body, "rt-secret")
+	require.Contains(t, in.Request.Body, `"model":"m"`)
+
+	require.Equal(t, "***", in.Response.Headers["Set-Cookie"])
+	require.NotContains(t, in.Response.Headers, "Content-Encoding")
+	require.NotContains(t, in.Response.Body, "at-secret")
+	require.Contains(t, in.Response.Body, "event: token\n")
+	require.Contains(t, in.Response.Body, `"text":"hi"}`+"\r\n\r\ndata: [DONE]\n\n")
+}
+
+func TestNewInteraction_BinaryBodyRoundTrips(t *testing.T) {
+	req := newUpstreamRequest(t, http.MethodGet, "https://files.example/img.png", "")
+	png := []byte{0x89, 'P', 'N', 'G', 0xff, 0x00}
+	in := NewInteraction(req, nil, &http.Response{StatusCode: 200, Header: http.Header{}}, png, time.Now())
+
+	require.Equal(t, BodyEncodingBase64, in.Response.BodyEncoding)
+	decoded, err := in.Response.BodyBytes()
+	require.NoError(t, err)
+	require.Equal(t, png, decoded)
+}
+
+func TestRecorder_WritesOnlyCompleteResponses(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(dir, 0)
+	require.NoError(t, err)
+
+	complete := newUpstreamRequest(t, http.MethodPost, "https://api.anthropic.com/v1/messages", `{"a":1}`)
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"id":"msg"}`))}
+	rec.Record(complete, resp)
+	out, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"msg"}`, string(out), "body passes through unchanged")
+
+	partial := newUpstreamRequest(t, http.MethodPost, "https://api.anthropic.com/v1/messages", `{"a":2}`)
+	resp = &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"id":"msg2"}`))}
+	rec.Record(partial, resp)
+	_, _ = resp.Body.Read(make([]byte, 4))
+	_ = resp.Body.Close()
+
+	c, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 1)
+	require.Equal(t, `{"a":1}`, c.Interactions[0].Request.Body)
+	require.Equal(t, `{"id":"msg"}`, c.Interactions[0].Response.Body)
+}
+
+func TestPlayer_MatchesByPathThenBodyThenOrder(t *testing.T) {
+	c := &Cassette{Interactions: []*Interaction{
+		{Request: Request{Method: http.MethodPost, Path: "/v1/messages", Body: `{"n":1}`}, Response: Response{StatusCode: 200, Body: "first"}},
+		{Request: Request{Method: http.MethodPost, Path: "/v1/messages", Body: `{"n":2}`}, Response: Response{StatusCode: 529, Body: "second", Headers: map[string]string{"Retry-After": "1"}}},
+	}}
+	path := filepath.Join(t.TempDir(), "c.json")
+	require.NoError(t, Save(path, c))
+	p, err := LoadPlayer(path)
+	require.NoError(t, err)
+
+	read := func(req *http.Request) (*http.Response, string) {
+		resp, err := p.Respond(req)
+		require.NoError(t, err)
+		b, _ := io.ReadAll(resp.Body)
+		return resp, string(b)
+	}
+
+	// 请求体相同的交互优先，与录制顺序无关；host 不参与匹配
+	resp, body := read(newUpstreamRequest(t, http.MethodPost, "http://relay.local/v1/messages", `{"n":2}`))
+	require.Equal(t, 529, resp.StatusCode)
+	require.Equal(t, "second", body)
+	require.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	_, body = read(newUpstreamRequest(t, http.MethodPost, "http://relay.local/v1/messages", `{"n":3}`))
+	require.Equal(t, "first", body, "falls back to the first unused interaction")
+	require.Zero(t, p.Unused())
+
+	_, body = read(newUpstreamRequest(t, http.MethodPost, "http://relay.local/v1/messages", `{"n":1}`))
+	require.Equal(t, "second", body, "repeats the last candidate once all are used")
+
+	_, err = p.Respond(newUpstreamRequest(t, http.MethodGet, "http://relay.local/v1/models", ""))
+	require.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+func TestLoadPlayer_MissingPath(t *testing.T) {
+	_, err := LoadPlayer(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+	require.True(t, os.IsNotExist(errors.Unwrap(err)))
+}
@@ -0,0 +1,150 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+)
+
+// ErrNoInteraction 没有与请求方法和路径匹配的录制交互。
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches request")
+
+// Player 以 cassette 作为假上游回放响应，不发出任何网络请求。
+//
+// 匹配规则：方法与路径相同（忽略 host，便于预发录制的 cassette 在其它 base_url 下回放）；
+// 多个候选时优先选择脱敏后请求体相同且未用过的交互，其次是第一个未用过的，全部用过后重复最后一个。
+// Player 同时实现 http.RoundTripper 与 service.HTTPUpstream 的方法集，可直接注入网关服务做回归测试。
+type Player struct {
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+func NewPlayer(c *Cassette) *Player {
+	p := &Player{}
+	if c != nil {
+		p.interactions = c.Interactions
+	}
+	p.used = make([]bool, len(p.interactions))
+	return p
+}
+
+// LoadPlayer 读取 cassette 文件或目录（目录下所有 *.json）并创建 Player。
+func LoadPlayer(path string) (*Player, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("open cassette %s: %w", path, err)
+	}
+	var c *Cassette
+	if info.IsDir() {
+		c, err = LoadDir(path)
+	} else {
+		c, err = Load(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayer(c), nil
+}
+
+// Respond 返回与请求匹配的录制响应。
+func (p *Player) Respond(req *http.Request) (*http.Response, error) {
+	if req == nil || req.URL == nil {
+		return nil, ErrNoInteraction
+	}
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cassette: read request body: %w", err)
+		}
+		body = data
+	}
+
+	in := p.match(req.Method, req.URL.Path, SanitizeBody(body))
+	if in == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.Path)
+	}
+	respBody, err := in.Response.BodyBytes()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: decode response body: %w", err)
+	}
+	header := make(http.Header, len(in.Response.Headers))
+	for name, value := range in.Response.Headers {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+func (p *Player) match(method, path, body string) *Interaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	firstUnused, last := -1, -1
+	for i, in := range p.interactions {
+		if in == nil || in.Request.Method != method || in.Request.Path != path {
+			continue
+		}
+		last = i
+		if p.used[i] {
+			continue
+		}
+		if in.Request.Body == body {
+			p.used[i] = true
+			return in
+		}
+		if firstUnused < 0 {
+			firstUnused = i
+		}
+	}
+	switch {
+	case firstUnused >= 0:
+		p.used[firstUnused] = true
+		return p.interactions[firstUnused]
+	case last >= 0:
+		return p.interactions[last]
+	default:
+		return nil
+	}
+}
+
+// Unused 返回尚未被回放的交互数，测试可据此断言请求序列完整。
+func (p *Player) Unused() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, used := range p.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	return p.Respond(req)
+}
+
+func (p *Player) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	return p.Respond(req)
+}
+
+func (p *Player) DoWithTLS(req *http.Request, _ string, _ int64, _ int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return p.Respond(req)
+}
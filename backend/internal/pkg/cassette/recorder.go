@@ -0,0 +1,122 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxBodyBytes 请求体或响应体超过该大小的交互不录制。
+const DefaultMaxBodyBytes = 4 << 20
+
+var fileNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Recorder 把上游交互写入目录，每次交互一个 cassette 文件。
+// 只有响应体被完整读到 EOF 的交互才会写入，避免中途断开的流产生残缺 cassette。
+type Recorder struct {
+	dir          string
+	maxBodyBytes int
+	seq          atomic.Int64
+	now          func() time.Time
+}
+
+func NewRecorder(dir string, maxBodyBytes int) (*Recorder, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("cassette dir is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create cassette dir: %w", err)
+	}
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &Recorder{dir: dir, maxBodyBytes: maxBodyBytes, now: time.Now}, nil
+}
+
+// Record 通过 GetBody 重新读取已发送的请求体，并包装 resp.Body 旁路复制响应（不改变读出的内容）。
+func (r *Recorder) Record(req *http.Request, resp *http.Response) {
+	if r == nil || req == nil || resp == nil || resp.Body == nil {
+		return
+	}
+	reqBody, ok := r.requestBody(req)
+	if !ok {
+		return
+	}
+	resp.Body = &recordingBody{ReadCloser: resp.Body, recorder: r, req: req, reqBody: reqBody, resp: resp}
+}
+
+func (r *Recorder) requestBody(req *http.Request) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(body, int64(r.maxBodyBytes)+1))
+	if err != nil || len(data) > r.maxBodyBytes {
+		return nil, false
+	}
+	return data, true
+}
+
+func (r *Recorder) save(in *Interaction) {
+	seq := r.seq.Add(1)
+	slug := strings.Trim(fileNameUnsafe.ReplaceAllString(in.Request.Path, "_"), "_")
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	name := fmt.Sprintf("%s-%04d-%s-%s.json", in.RecordedAt.Format("20060102T150405.000Z"), seq%10000, strings.ToLower(in.Request.Method), slug)
+	c := &Cassette{Name: strings.TrimSuffix(name, ".json"), Interactions: []*Interaction{in}}
+	if err := Save(filepath.Join(r.dir, name), c); err != nil {
+		slog.Warn("cassette_record_failed", "path", in.Request.Path, "error", err)
+	}
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	recorder *Recorder
+	req      *http.Request
+	reqBody  []byte
+	resp     *http.Response
+
+	buf      bytes.Buffer
+	overflow bool
+	done     bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.done {
+		return n, err
+	}
+	if n > 0 && !b.overflow {
+		if b.buf.Len()+n > b.recorder.maxBodyBytes {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			_, _ = b.buf.Write(p[:n])
+		}
+	}
+	if err != nil {
+		b.done = true
+		if err == io.EOF && !b.overflow {
+			b.recorder.save(NewInteraction(b.req, b.reqBody, b.resp, b.buf.Bytes(), b.recorder.now()))
+		}
+		b.buf = bytes.Buffer{}
+	}
+	return n, err
+}
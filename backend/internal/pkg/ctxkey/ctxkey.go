@@ -45,6 +45,9 @@ const (
 	// 供 service 层执行用户级策略，不能使用客户端请求体中的 user 标识替代。
 	UserID Key = "ctx_user_id"
 
+	// CapacityReservation 分组账号并发预留策略（service.CapacityReservation），由 API Key 认证中间件设置。
+	CapacityReservation Key = "ctx_capacity_reservation"
+
 	// IsMaxTokensOneHaikuRequest 标识当前请求是否为 max_tokens=1 + haiku 模型的探测请求
 	// 用于 ClaudeCodeOnly 验证绕过（绕过 system prompt 检查，但仍需验证 User-Agent）
 	IsMaxTokensOneHaikuRequest Key = "ctx_is_max_tokens_one_haiku"
@@ -63,4 +66,11 @@ const (
 
 	// ClaudeCodeVersion stores the extracted Claude Code version from User-Agent (e.g. "2.1.22")
 	ClaudeCodeVersion Key = "ctx_claude_code_version"
+
+	// PromptLanguage 提示词的主要语言（ISO 639-1，如 "zh"），仅在命中分组语言路由规则时写入，
+	// 供调度层优先选择规则指定的账号。
+	PromptLanguage Key = "ctx_prompt_language"
+
+	// RequestClass 请求分类（interactive / batch），供调度层筛选专属账号池并写入用量日志。
+	RequestClass Key = "ctx_request_class"
 )
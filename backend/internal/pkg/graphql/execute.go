@@ -0,0 +1,627 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Request 标准 GraphQL HTTP 请求体。
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Location 查询文档中的位置（行列均从 1 开始）。
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error GraphQL 响应错误。
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Response 标准 GraphQL 响应体。解析或校验失败时 Data 为空。
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Execute 解析、校验并执行查询。只接受 query 操作。
+func Execute(ctx context.Context, schema *Schema, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return errorResponse(err)
+	}
+	op, gqlErr := selectOperation(doc, req.OperationName)
+	if gqlErr != nil {
+		return &Response{Errors: []*Error{gqlErr}}
+	}
+	if op.kind != "query" {
+		return &Response{Errors: []*Error{{Message: fmt.Sprintf("%s operations are not supported; this endpoint is read-only", op.kind), Locations: []Location{op.pos}}}}
+	}
+
+	vars, errs := coerceVariables(op, req.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	ex := &executor{schema: schema, doc: doc, vars: vars}
+	maxDepth := schema.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	ex.validateSelections(op.selections, schema.Query, 1, maxDepth, nil)
+	if len(ex.errors) > 0 {
+		return &Response{Errors: ex.errors}
+	}
+
+	data := ex.executeRoot(ctx, op.selections)
+	return &Response{Data: data, Errors: ex.errors}
+}
+
+func errorResponse(err error) *Response {
+	if gqlErr, ok := err.(*Error); ok {
+		return &Response{Errors: []*Error{gqlErr}}
+	}
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *document, name string) (*operation, *Error) {
+	if name == "" {
+		if len(doc.operations) != 1 {
+			return nil, &Error{Message: "operationName is required when the document contains multiple operations"}
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+type executor struct {
+	schema *Schema
+	doc    *document
+	vars   map[string]any
+	errors []*Error
+}
+
+func (ex *executor) addError(e *Error) { ex.errors = append(ex.errors, e) }
+
+// ---- 校验 ----
+
+func (ex *executor) validateSelections(sels []selection, obj *Object, depth, maxDepth int, visiting map[string]bool) {
+	if depth > maxDepth {
+		ex.addError(&Error{Message: fmt.Sprintf("query exceeds maximum depth of %d", maxDepth), Locations: []Location{sels[0].position()}})
+		return
+	}
+	for _, sel := range sels {
+		switch s := sel.(type) {
+		case *fieldNode:
+			ex.validateDirectives(s.directives)
+			if s.name == "__typename" {
+				if len(s.selections) > 0 {
+					ex.addError(&Error{Message: `field "__typename" must not have a selection`, Locations: []Location{s.pos}})
+				}
+				continue
+			}
+			def := obj.Field(s.name)
+			if def == nil {
+				ex.addError(&Error{Message: fmt.Sprintf("cannot query field %q on type %q", s.name, obj.Name), Locations: []Location{s.pos}})
+				continue
+			}
+			ex.validateArgs(s, def)
+			named := namedType(def.Type)
+			if child, isObj := named.(*Object); isObj {
+				if len(s.selections) == 0 {
+					ex.addError(&Error{Message: fmt.Sprintf("field %q of type %q must have a selection of subfields", s.name, def.Type), Locations: []Location{s.pos}})
+					continue
+				}
+				ex.validateSelections(s.selections, child, depth+1, maxDepth, visiting)
+			} else if len(s.selections) > 0 {
+				ex.addError(&Error{Message: fmt.Sprintf("field %q must not have a selection since type %q has no subfields", s.name, def.Type), Locations: []Location{s.pos}})
+			}
+		case *fragmentSpread:
+			ex.validateDirectives(s.directives)
+			frag, ok := ex.doc.fragments[s.name]
+			if !ok {
+				ex.addError(&Error{Message: fmt.Sprintf("unknown fragment %q", s.name), Locations: []Location{s.pos}})
+				continue
+			}
+			if visiting[s.name] {
+				ex.addError(&Error{Message: fmt.Sprintf("cannot spread fragment %q within itself", s.name), Locations: []Location{s.pos}})
+				continue
+			}
+			if frag.typeCondition != obj.Name {
+				ex.addError(&Error{Message: fmt.Sprintf("fragment %q on %q cannot be spread on type %q", s.name, frag.typeCondition, obj.Name), Locations: []Location{s.pos}})
+				continue
+			}
+			next := make(map[string]bool, len(visiting)+1)
+			for k := range visiting {
+				next[k] = true
+			}
+			next[s.name] = true
+			ex.validateSelections(frag.selections, obj, depth, maxDepth, next)
+		case *inlineFragment:
+			ex.validateDirectives(s.directives)
+			if s.typeCondition != "" && s.typeCondition != obj.Name {
+				ex.addError(&Error{Message: fmt.Sprintf("inline fragment on %q cannot be used on type %q", s.typeCondition, obj.Name), Locations: []Location{s.pos}})
+				continue
+			}
+			ex.validateSelections(s.selections, obj, depth, maxDepth, visiting)
+		}
+	}
+}
+
+func (ex *executor) validateDirectives(dirs []*directive) {
+	for _, d := range dirs {
+		if d.name != "include" && d.name != "skip" {
+			ex.addError(&Error{Message: fmt.Sprintf("unknown directive @%s", d.name), Locations: []Location{d.pos}})
+			continue
+		}
+		if len(d.args) != 1 || d.args[0].name != "if" {
+			ex.addError(&Error{Message: fmt.Sprintf("directive @%s requires exactly one argument \"if\"", d.name), Locations: []Location{d.pos}})
+			continue
+		}
+		if _, err := coerceInput(ex.resolveValue(d.args[0].value), Boolean); err != nil {
+			ex.addError(&Error{Message: fmt.Sprintf("directive @%s argument \"if\": %v", d.name, err), Locations: []Location{d.pos}})
+		}
+	}
+}
+
+func (ex *executor) validateArgs(f *fieldNode, def *Field) {
+	provided := make(map[string]bool, len(f.args))
+	for _, a := range f.args {
+		argDef := def.arg(a.name)
+		if argDef == nil {
+			ex.addError(&Error{Message: fmt.Sprintf("unknown argument %q on field %q", a.name, def.Name), Locations: []Location{a.pos}})
+			continue
+		}
+		provided[a.name] = true
+		v := ex.resolveValue(a.value)
+		if v == nil && argDef.Required {
+			ex.addError(&Error{Message: fmt.Sprintf("argument %q of field %q is required", a.name, def.Name), Locations: []Location{a.pos}})
+			continue
+		}
+		if _, err := coerceInput(v, argDef.Type); err != nil {
+			ex.addError(&Error{Message: fmt.Sprintf("argument %q of field %q: %v", a.name, def.Name, err), Locations: []Location{a.pos}})
+		}
+	}
+	for _, argDef := range def.Args {
+		if argDef.Required && !provided[argDef.Name] {
+			ex.addError(&Error{Message: fmt.Sprintf("argument %q of field %q is required", argDef.Name, def.Name), Locations: []Location{f.pos}})
+		}
+	}
+}
+
+// ---- 执行 ----
+
+func (ex *executor) executeRoot(ctx context.Context, sels []selection) *orderedMap {
+	out := &orderedMap{}
+	for _, group := range ex.collectFields(sels) {
+		f := group[0]
+		key := f.responseKey()
+		if f.name == "__typename" {
+			out.set(key, ex.schema.Query.Name)
+			continue
+		}
+		def := ex.schema.Query.Field(f.name)
+		args, _ := ex.fieldArgs(f, def)
+		if def.Resolve == nil {
+			out.set(key, nil)
+			continue
+		}
+		val, err := def.Resolve(ctx, args)
+		if err != nil {
+			ex.addResolverError(err, f, []any{key})
+			out.set(key, nil)
+			continue
+		}
+		normalized, err := normalize(val)
+		if err != nil {
+			ex.addResolverError(err, f, []any{key})
+			out.set(key, nil)
+			continue
+		}
+		out.set(key, ex.complete(def.Type, normalized, mergeSelections(group), []any{key}))
+	}
+	return out
+}
+
+func (ex *executor) addResolverError(err error, f *fieldNode, path []any) {
+	var e *Error
+	if ex.schema.ErrorPresenter != nil {
+		e = ex.schema.ErrorPresenter(err)
+	}
+	if e == nil {
+		e = &Error{Message: err.Error()}
+	}
+	e.Locations = []Location{f.pos}
+	e.Path = path
+	ex.addError(e)
+}
+
+func (ex *executor) complete(t Type, val any, sels []selection, path []any) any {
+	if val == nil {
+		return nil
+	}
+	switch tt := t.(type) {
+	case *List:
+		items, ok := val.([]any)
+		if !ok {
+			return nil
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = ex.complete(tt.OfType, item, sels, appendPath(path, i))
+		}
+		return out
+	case *Object:
+		src, ok := val.(map[string]any)
+		if !ok {
+			return nil
+		}
+		out := &orderedMap{}
+		for _, group := range ex.collectFields(sels) {
+			f := group[0]
+			key := f.responseKey()
+			if f.name == "__typename" {
+				out.set(key, tt.Name)
+				continue
+			}
+			def := tt.Field(f.name)
+			out.set(key, ex.complete(def.Type, src[f.name], mergeSelections(group), appendPath(path, key)))
+		}
+		return out
+	default:
+		return val
+	}
+}
+
+func appendPath(path []any, elem any) []any {
+	out := make([]any, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, elem)
+}
+
+// collectFields 展开片段、处理 @skip/@include，并按响应键合并同名字段。
+func (ex *executor) collectFields(sels []selection) [][]*fieldNode {
+	var order []string
+	groups := map[string][]*fieldNode{}
+	var walk func(sels []selection)
+	walk = func(sels []selection) {
+		for _, sel := range sels {
+			switch s := sel.(type) {
+			case *fieldNode:
+				if !ex.shouldInclude(s.directives) {
+					continue
+				}
+				key := s.responseKey()
+				if _, ok := groups[key]; !ok {
+					order = append(order, key)
+				}
+				groups[key] = append(groups[key], s)
+			case *fragmentSpread:
+				if !ex.shouldInclude(s.directives) {
+					continue
+				}
+				if frag := ex.doc.fragments[s.name]; frag != nil {
+					walk(frag.selections)
+				}
+			case *inlineFragment:
+				if !ex.shouldInclude(s.directives) {
+					continue
+				}
+				walk(s.selections)
+			}
+		}
+	}
+	walk(sels)
+	out := make([][]*fieldNode, 0, len(order))
+	for _, key := range order {
+		out = append(out, groups[key])
+	}
+	return out
+}
+
+func mergeSelections(group []*fieldNode) []selection {
+	if len(group) == 1 {
+		return group[0].selections
+	}
+	var out []selection
+	for _, f := range group {
+		out = append(out, f.selections...)
+	}
+	return out
+}
+
+func (ex *executor) shouldInclude(dirs []*directive) bool {
+	for _, d := range dirs {
+		if len(d.args) == 0 {
+			continue
+		}
+		v, _ := ex.resolveValue(d.args[0].value).(bool)
+		if d.name == "skip" && v {
+			return false
+		}
+		if d.name == "include" && !v {
+			return false
+		}
+	}
+	return true
+}
+
+func (ex *executor) fieldArgs(f *fieldNode, def *Field) (Args, error) {
+	args := Args{}
+	for _, a := range f.args {
+		argDef := def.arg(a.name)
+		if argDef == nil {
+			continue
+		}
+		v, err := coerceInput(ex.resolveValue(a.value), argDef.Type)
+		if err != nil {
+			return nil, err
+		}
+		args[a.name] = v
+	}
+	return args, nil
+}
+
+// resolveValue 将 AST 值转换为 Go 值（变量替换为请求中的值）。
+func (ex *executor) resolveValue(v *value) any {
+	if v == nil {
+		return nil
+	}
+	switch v.kind {
+	case valVariable:
+		return ex.vars[v.raw]
+	case valInt:
+		return json.Number(v.raw)
+	case valFloat:
+		return json.Number(v.raw)
+	case valString, valEnum:
+		return v.raw
+	case valBoolean:
+		return v.raw == "true"
+	case valList:
+		out := make([]any, len(v.list))
+		for i, item := range v.list {
+			out[i] = ex.resolveValue(item)
+		}
+		return out
+	case valObject:
+		out := make(map[string]any, len(v.fields))
+		for _, f := range v.fields {
+			out[f.name] = ex.resolveValue(f.value)
+		}
+		return out
+	}
+	return nil
+}
+
+func coerceVariables(op *operation, provided map[string]any) (map[string]any, []*Error) {
+	vars := make(map[string]any, len(op.vars))
+	var errs []*Error
+	for _, def := range op.vars {
+		v, ok := provided[def.name]
+		if !ok && def.defaultVal != nil {
+			v = (&executor{}).resolveValue(def.defaultVal)
+			ok = true
+		}
+		if def.typ.nonNull && (!ok || v == nil) {
+			errs = append(errs, &Error{Message: fmt.Sprintf("variable \"$%s\" of required type %q was not provided", def.name, def.typ)})
+			continue
+		}
+		if t := inputType(def.typ); t != nil && v != nil {
+			if _, err := coerceInput(v, t); err != nil {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable \"$%s\": %v", def.name, err)})
+				continue
+			}
+		}
+		vars[def.name] = v
+	}
+	return vars, errs
+}
+
+func inputType(t *typeRef) Type {
+	if t.elem != nil {
+		if inner := inputType(t.elem); inner != nil {
+			return ListOf(inner)
+		}
+		return nil
+	}
+	switch t.name {
+	case "Int":
+		return Int
+	case "Float":
+		return Float
+	case "String", "ID":
+		return String
+	case "Boolean":
+		return Boolean
+	}
+	return nil
+}
+
+// coerceInput 按参数类型转换输入值；单个值传给列表类型时按规范包装成单元素列表。
+func coerceInput(v any, t Type) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch tt := t.(type) {
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			item, err := coerceInput(v, tt.OfType)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			c, err := coerceInput(item, tt.OfType)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = c
+		}
+		return out, nil
+	case *Scalar:
+		switch tt {
+		case Int:
+			return coerceInt(v)
+		case Float:
+			return coerceFloat(v)
+		case String:
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("expected String, got %s", describeValue(v))
+		case Boolean:
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("expected Boolean, got %s", describeValue(v))
+		default:
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("type %s cannot be used as input", t)
+}
+
+func coerceInt(v any) (any, error) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected Int, got %s", n)
+		}
+		return i, nil
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > 1<<53 {
+			return nil, fmt.Errorf("expected Int, got %v", n)
+		}
+		return int64(n), nil
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	}
+	return nil, fmt.Errorf("expected Int, got %s", describeValue(v))
+}
+
+func coerceFloat(v any) (any, error) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("expected Float, got %s", n)
+		}
+		return f, nil
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	}
+	return nil, fmt.Errorf("expected Float, got %s", describeValue(v))
+}
+
+func describeValue(v any) string {
+	switch x := v.(type) {
+	case string:
+		return strconv.Quote(x)
+	case bool:
+		return "Boolean"
+	case []any:
+		return "List"
+	case map[string]any:
+		return "Object"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func namedType(t Type) Type {
+	for {
+		l, ok := t.(*List)
+		if !ok {
+			return t
+		}
+		t = l.OfType
+	}
+}
+
+// normalize 将 resolver 返回值转为 JSON 通用形态（map / []any / json.Number / ...），
+// 数字保留原始精度。
+func normalize(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// orderedMap 按选择集顺序序列化对象字段。
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, v any) {
+	if m.values == nil {
+		m.values = map[string]any{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,177 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testOwner struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+type testBase struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type testItem struct {
+	testBase
+	Secret    string         `json:"-"`
+	Cost      float64        `json:"cost"`
+	Tags      []string       `json:"tags"`
+	Owner     *testOwner     `json:"owner,omitempty"`
+	Extra     map[string]any `json:"extra"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type testPage struct {
+	Total int64      `json:"total"`
+	Items []testItem `json:"items"`
+}
+
+func testSchema(t *testing.T, calls *[]Args) *Schema {
+	t.Helper()
+	reg := NewRegistry()
+	items := []testItem{
+		{testBase: testBase{ID: 1, Name: "a"}, Secret: "s1", Cost: 1.5, Tags: []string{"x"}, Owner: &testOwner{ID: 9, Email: "o@example.com"}, Extra: map[string]any{"k": "v"}},
+		{testBase: testBase{ID: 9007199254740993, Name: "b"}, Cost: 2},
+	}
+	return &Schema{
+		Query: NewObject("Query", "root",
+			&Field{
+				Name: "items",
+				Type: reg.ObjectOf("ItemPage", testPage{}),
+				Args: []*Argument{{Name: "ids", Type: ListOf(Int)}, {Name: "page", Type: Int}, {Name: "name", Type: String}},
+				Resolve: func(ctx context.Context, args Args) (any, error) {
+					*calls = append(*calls, args)
+					return testPage{Total: int64(len(items)), Items: items}, nil
+				},
+			},
+			&Field{
+				Name: "item",
+				Type: reg.TypeOf(testItem{}),
+				Args: []*Argument{{Name: "id", Type: Int, Required: true}},
+				Resolve: func(ctx context.Context, args Args) (any, error) {
+					if args.Int64("id") != 1 {
+						return nil, errors.New("item not found")
+					}
+					return &items[0], nil
+				},
+			},
+		),
+	}
+}
+
+func execJSON(t *testing.T, schema *Schema, req Request) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(Execute(context.Background(), schema, req))
+	require.NoError(t, err)
+	var out map[string]any
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&out))
+	return out
+}
+
+func TestExecute_SelectionsAliasesFragmentsAndVariables(t *testing.T) {
+	var calls []Args
+	schema := testSchema(t, &calls)
+
+	query := `
+		query Report($ids: [Int], $withOwner: Boolean = false) {
+			__typename
+			page: items(ids: $ids, page: 2, name: "aé") {
+				total
+				items { ...itemFields owner @include(if: $withOwner) { email } }
+			}
+		}
+		fragment itemFields on testItem { id name cost tags extra }
+	`
+	out := execJSON(t, schema, Request{Query: query, Variables: map[string]any{"ids": []any{float64(1), float64(2)}}})
+	require.Nil(t, out["errors"])
+	data := out["data"].(map[string]any)
+	require.Equal(t, "Query", data["__typename"])
+	page := data["page"].(map[string]any)
+	require.Equal(t, json.Number("2"), page["total"])
+	list := page["items"].([]any)
+	first := list[0].(map[string]any)
+	require.Equal(t, "a", first["name"])
+	require.Equal(t, map[string]any{"k": "v"}, first["extra"])
+	require.NotContains(t, first, "owner", "@include(if: false) drops the field")
+	require.NotContains(t, first, "Secret")
+	require.Equal(t, json.Number("9007199254740993"), list[1].(map[string]any)["id"], "int64 precision is preserved")
+
+	require.Len(t, calls, 1)
+	require.Equal(t, []int64{1, 2}, calls[0].IntList("ids"))
+	require.Equal(t, 2, calls[0].Int("page", 1))
+	require.Equal(t, "aé", calls[0].String("name"))
+
+	raw, err := json.Marshal(Execute(context.Background(), schema, Request{Query: `{ items { total items { name id } } }`}))
+	require.NoError(t, err)
+	require.Contains(t, string(raw), `{"name":"a","id":1}`, "fields follow selection order")
+}
+
+func TestExecute_ValidationErrors(t *testing.T) {
+	var calls []Args
+	schema := testSchema(t, &calls)
+
+	cases := map[string]string{
+		`{ items { missing } }`:                              `cannot query field "missing"`,
+		`{ items }`:                                          "must have a selection of subfields",
+		`{ items { total { x } } }`:                          "must not have a selection",
+		`{ item { id } }`:                                    `argument "id" of field "item" is required`,
+		`{ items(page: "two") { total } }`:                   `argument "page"`,
+		`{ items(sort: 1) { total } }`:                       `unknown argument "sort"`,
+		`mutation { items { total } }`:                       "read-only",
+		`{ items { ...f } } fragment f on ItemPage { ...f }`: "within itself",
+		`{ items { total } `:                                 "Syntax Error",
+	}
+	for query, want := range cases {
+		out := execJSON(t, schema, Request{Query: query})
+		require.Nil(t, out["data"], query)
+		errs := out["errors"].([]any)
+		require.NotEmpty(t, errs, query)
+		require.Contains(t, errs[0].(map[string]any)["message"], want, query)
+	}
+	require.Empty(t, calls, "invalid documents never reach resolvers")
+
+	schema.MaxDepth = 2
+	out := execJSON(t, schema, Request{Query: `{ items { items { owner { id } } } }`})
+	require.Contains(t, out["errors"].([]any)[0].(map[string]any)["message"], "maximum depth")
+}
+
+func TestExecute_ResolverErrorNullsFieldWithPath(t *testing.T) {
+	var calls []Args
+	schema := testSchema(t, &calls)
+	schema.ErrorPresenter = func(err error) *Error {
+		return &Error{Message: "presented: " + err.Error(), Extensions: map[string]any{"code": "NOT_FOUND"}}
+	}
+
+	out := execJSON(t, schema, Request{Query: `{ ok: item(id: 1) { id owner { id } } bad: item(id: 2) { id } }`})
+	data := out["data"].(map[string]any)
+	require.Equal(t, json.Number("9"), data["ok"].(map[string]any)["owner"].(map[string]any)["id"])
+	require.Nil(t, data["bad"])
+	errs := out["errors"].([]any)
+	require.Len(t, errs, 1)
+	e := errs[0].(map[string]any)
+	require.Equal(t, "presented: item not found", e["message"])
+	require.Equal(t, []any{"bad"}, e["path"])
+	require.Equal(t, map[string]any{"code": "NOT_FOUND"}, e["extensions"])
+}
+
+func TestSchemaSDL(t *testing.T) {
+	var calls []Args
+	sdl := testSchema(t, &calls).SDL()
+	require.Contains(t, sdl, "items(ids: [Int], page: Int, name: String): ItemPage")
+	require.Contains(t, sdl, "item(id: Int!): testItem")
+	require.Contains(t, sdl, "type testItem {\n  id: Int\n  name: String\n  cost: Float\n  tags: [String]\n  owner: testOwner\n  extra: JSON\n  created_at: Time\n}")
+	require.Contains(t, sdl, "scalar JSON")
+	require.NotContains(t, sdl, "Secret")
+}
@@ -0,0 +1,739 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 只实现查询所需的 GraphQL 语法子集：operation / fragment / 变量 / 别名 / 参数 / @include / @skip。
+// 不支持 schema 定义语言（SDL）解析与订阅。
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   Location
+}
+
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+func (l *lexer) loc() Location { return Location{Line: l.line, Column: l.col} }
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.off < len(l.src); i++ {
+		if l.src[l.off] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.off++
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.off < len(l.src) {
+		switch c := l.src[l.off]; {
+		case c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.advance(1)
+		case c == '#':
+			for l.off < len(l.src) && l.src[l.off] != '\n' {
+				l.advance(1)
+			}
+		case strings.HasPrefix(l.src[l.off:], "\uFEFF"):
+			l.off += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	pos := l.loc()
+	if l.off >= len(l.src) {
+		return token{kind: tokEOF, pos: pos}, nil
+	}
+	c := l.src[l.off]
+	switch {
+	case strings.HasPrefix(l.src[l.off:], "..."):
+		l.advance(3)
+		return token{kind: tokPunct, value: "...", pos: pos}, nil
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		l.advance(1)
+		return token{kind: tokPunct, value: string(c), pos: pos}, nil
+	case c == '_' || isLetter(c):
+		start := l.off
+		for l.off < len(l.src) && (l.src[l.off] == '_' || isLetter(l.src[l.off]) || isDigit(l.src[l.off])) {
+			l.advance(1)
+		}
+		return token{kind: tokName, value: l.src[start:l.off], pos: pos}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.off:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+	return token{}, syntaxError(pos, "unexpected character %q", c)
+}
+
+func (l *lexer) number(pos Location) (token, error) {
+	start := l.off
+	kind := tokInt
+	if l.src[l.off] == '-' {
+		l.advance(1)
+	}
+	digits := func() int {
+		n := 0
+		for l.off < len(l.src) && isDigit(l.src[l.off]) {
+			l.advance(1)
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, syntaxError(pos, "invalid number")
+	}
+	if l.off < len(l.src) && l.src[l.off] == '.' {
+		kind = tokFloat
+		l.advance(1)
+		if digits() == 0 {
+			return token{}, syntaxError(pos, "invalid number")
+		}
+	}
+	if l.off < len(l.src) && (l.src[l.off] == 'e' || l.src[l.off] == 'E') {
+		kind = tokFloat
+		l.advance(1)
+		if l.off < len(l.src) && (l.src[l.off] == '+' || l.src[l.off] == '-') {
+			l.advance(1)
+		}
+		if digits() == 0 {
+			return token{}, syntaxError(pos, "invalid number")
+		}
+	}
+	return token{kind: kind, value: l.src[start:l.off], pos: pos}, nil
+}
+
+func (l *lexer) string(pos Location) (token, error) {
+	l.advance(1)
+	var b strings.Builder
+	for l.off < len(l.src) {
+		c := l.src[l.off]
+		switch {
+		case c == '"':
+			l.advance(1)
+			return token{kind: tokString, value: b.String(), pos: pos}, nil
+		case c == '\n' || c == '\r':
+			return token{}, syntaxError(pos, "unterminated string")
+		case c == '\\':
+			if l.off+1 >= len(l.src) {
+				return token{}, syntaxError(pos, "unterminated string")
+			}
+			esc := l.src[l.off+1]
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.off+6 > len(l.src) {
+					return token{}, syntaxError(pos, "invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(l.src[l.off+2:l.off+6], 16, 32)
+				if err != nil {
+					return token{}, syntaxError(pos, "invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				l.advance(4)
+			default:
+				return token{}, syntaxError(pos, "invalid escape sequence \\%c", esc)
+			}
+			l.advance(2)
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.off:])
+			b.WriteRune(r)
+			l.off += size
+			l.col++
+		}
+	}
+	return token{}, syntaxError(pos, "unterminated string")
+}
+
+func (l *lexer) blockString(pos Location) (token, error) {
+	l.advance(3)
+	end := strings.Index(l.src[l.off:], `"""`)
+	if end < 0 {
+		return token{}, syntaxError(pos, "unterminated block string")
+	}
+	raw := l.src[l.off : l.off+end]
+	l.advance(end + 3)
+	return token{kind: tokString, value: strings.TrimSpace(strings.ReplaceAll(raw, `\"""`, `"""`)), pos: pos}, nil
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// ---- AST ----
+
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string // query / mutation / subscription
+	name       string
+	vars       []*varDef
+	selections []selection
+	pos        Location
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	selections    []selection
+	pos           Location
+}
+
+type varDef struct {
+	name       string
+	typ        *typeRef
+	defaultVal *value
+}
+
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type selection interface{ position() Location }
+
+type fieldNode struct {
+	alias      string
+	name       string
+	args       []*argNode
+	directives []*directive
+	selections []selection
+	pos        Location
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	pos        Location
+}
+
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	pos           Location
+}
+
+func (f *fieldNode) position() Location      { return f.pos }
+func (f *fragmentSpread) position() Location { return f.pos }
+func (f *inlineFragment) position() Location { return f.pos }
+
+func (f *fieldNode) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type argNode struct {
+	name  string
+	value *value
+	pos   Location
+}
+
+type directive struct {
+	name string
+	args []*argNode
+	pos  Location
+}
+
+type valueKind int
+
+const (
+	valVariable valueKind = iota
+	valInt
+	valFloat
+	valString
+	valBoolean
+	valNull
+	valEnum
+	valList
+	valObject
+)
+
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []*value
+	fields []*argNode
+	pos    Location
+}
+
+// ---- parser ----
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func parse(src string) (*document, error) {
+	p := &parser{lex: &lexer{src: src, line: 1, col: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	doc := &document{fragments: map[string]*fragment{}}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.peekPunct("{"), p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peekName("fragment"):
+			frag, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.fragments[frag.name]; dup {
+				return nil, syntaxError(frag.pos, "duplicate fragment %q", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, syntaxError(Location{Line: 1, Column: 1}, "document contains no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peekPunct(v string) bool { return p.tok.kind == tokPunct && p.tok.value == v }
+func (p *parser) peekName(v string) bool  { return p.tok.kind == tokName && p.tok.value == v }
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokEOF {
+		return syntaxError(p.tok.pos, "unexpected end of document")
+	}
+	return syntaxError(p.tok.pos, "unexpected %q", p.tok.value)
+}
+
+func (p *parser) expectPunct(v string) error {
+	if !p.peekPunct(v) {
+		return syntaxError(p.tok.pos, "expected %q, found %s", v, p.describe())
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokName {
+		return "", syntaxError(p.tok.pos, "expected name, found %s", p.describe())
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) describe() string {
+	if p.tok.kind == tokEOF {
+		return "<EOF>"
+	}
+	return strconv.Quote(p.tok.value)
+}
+
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{kind: "query", pos: p.tok.pos}
+	if p.tok.kind == tokName {
+		op.kind = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokName {
+			op.name = p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if p.peekPunct("(") {
+			vars, err := p.parseVarDefs()
+			if err != nil {
+				return nil, err
+			}
+			op.vars = vars
+		}
+		if _, err := p.parseDirectives(); err != nil {
+			return nil, err
+		}
+	}
+	sels, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = sels
+	return op, nil
+}
+
+func (p *parser) parseFragment() (*fragment, error) {
+	frag := &fragment{pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, syntaxError(frag.pos, "fragment cannot be named \"on\"")
+	}
+	frag.name = name
+	if !p.peekName("on") {
+		return nil, syntaxError(p.tok.pos, "expected \"on\", found %s", p.describe())
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if frag.typeCondition, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if frag.selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return frag, nil
+}
+
+func (p *parser) parseVarDefs() ([]*varDef, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var defs []*varDef
+	for !p.peekPunct(")") {
+		if err := p.expectPunct("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		def := &varDef{name: name, typ: typ}
+		if p.peekPunct("=") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if def.defaultVal, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs, p.advance()
+}
+
+func (p *parser) parseType() (*typeRef, error) {
+	var t *typeRef
+	if p.peekPunct("[") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return nil, err
+		}
+		t = &typeRef{elem: elem}
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		t = &typeRef{name: name}
+	}
+	if p.peekPunct("!") {
+		t.nonNull = true
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var sels []selection
+	for !p.peekPunct("}") {
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, syntaxError(p.tok.pos, "selection set cannot be empty")
+	}
+	return sels, p.advance()
+}
+
+func (p *parser) parseSelection() (selection, error) {
+	pos := p.tok.pos
+	if p.peekPunct("...") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokName && p.tok.value != "on" {
+			name := p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			dirs, err := p.parseDirectives()
+			if err != nil {
+				return nil, err
+			}
+			return &fragmentSpread{name: name, directives: dirs, pos: pos}, nil
+		}
+		inline := &inlineFragment{pos: pos}
+		if p.peekName("on") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			inline.typeCondition = name
+		}
+		var err error
+		if inline.directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		if inline.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+		return inline, nil
+	}
+
+	f := &fieldNode{pos: pos}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	f.name = name
+	if p.peekPunct(":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		f.alias = name
+		if f.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekPunct("(") {
+		if f.args, err = p.parseArgs(false); err != nil {
+			return nil, err
+		}
+	}
+	if f.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peekPunct("{") {
+		if f.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) parseArgs(constOnly bool) ([]*argNode, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var args []*argNode
+	seen := map[string]struct{}{}
+	for !p.peekPunct(")") {
+		pos := p.tok.pos
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[name]; dup {
+			return nil, syntaxError(pos, "duplicate argument %q", name)
+		}
+		seen[name] = struct{}{}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.parseValue(constOnly)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &argNode{name: name, value: v, pos: pos})
+	}
+	if len(args) == 0 {
+		return nil, syntaxError(p.tok.pos, "argument list cannot be empty")
+	}
+	return args, p.advance()
+}
+
+func (p *parser) parseDirectives() ([]*directive, error) {
+	var dirs []*directive
+	for p.peekPunct("@") {
+		pos := p.tok.pos
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		d := &directive{name: name, pos: pos}
+		if p.peekPunct("(") {
+			if d.args, err = p.parseArgs(false); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+func (p *parser) parseValue(constOnly bool) (*value, error) {
+	tok := p.tok
+	v := &value{pos: tok.pos, raw: tok.value}
+	switch {
+	case tok.kind == tokPunct && tok.value == "$":
+		if constOnly {
+			return nil, syntaxError(tok.pos, "variables are not allowed here")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		v.kind, v.raw = valVariable, name
+		return v, nil
+	case tok.kind == tokPunct && tok.value == "[":
+		v.kind = valList
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for !p.peekPunct("]") {
+			item, err := p.parseValue(constOnly)
+			if err != nil {
+				return nil, err
+			}
+			v.list = append(v.list, item)
+		}
+		return v, p.advance()
+	case tok.kind == tokPunct && tok.value == "{":
+		v.kind = valObject
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for !p.peekPunct("}") {
+			pos := p.tok.pos
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return nil, err
+			}
+			item, err := p.parseValue(constOnly)
+			if err != nil {
+				return nil, err
+			}
+			v.fields = append(v.fields, &argNode{name: name, value: item, pos: pos})
+		}
+		return v, p.advance()
+	case tok.kind == tokInt:
+		v.kind = valInt
+	case tok.kind == tokFloat:
+		v.kind = valFloat
+	case tok.kind == tokString:
+		v.kind = valString
+	case tok.kind == tokName:
+		switch tok.value {
+		case "true", "false":
+			v.kind = valBoolean
+		case "null":
+			v.kind = valNull
+		default:
+			v.kind = valEnum
+		}
+	default:
+		return nil, p.unexpected()
+	}
+	return v, p.advance()
+}
+
+func syntaxError(pos Location, format string, args ...any) *Error {
+	return &Error{Message: "Syntax Error: " + fmt.Sprintf(format, args...), Locations: []Location{pos}}
+}
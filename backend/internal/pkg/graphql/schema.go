@@ -0,0 +1,329 @@
+// Package graphql 提供只读 GraphQL 查询的最小实现：解析查询文档、按 schema 校验并执行。
+//
+// 对象类型一般由 Go 结构体（按 json tag）反射生成，字段值来自根字段 resolver 返回值的
+// JSON 形态，因此 GraphQL 输出与对应 REST 接口的字段名、脱敏规则保持一致。
+package graphql
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Type 是 GraphQL 输出/输入类型：*Scalar、*Object 或 *List。
+type Type interface {
+	String() string
+}
+
+// Scalar 叶子类型。
+type Scalar struct {
+	Name        string
+	Description string
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// 内置标量。JSON 原样输出任意值，Time 为 RFC3339 字符串。
+var (
+	Int     = &Scalar{Name: "Int"}
+	Float   = &Scalar{Name: "Float"}
+	String  = &Scalar{Name: "String"}
+	Boolean = &Scalar{Name: "Boolean"}
+	JSON    = &Scalar{Name: "JSON", Description: "Arbitrary JSON value"}
+	Time    = &Scalar{Name: "Time", Description: "RFC3339 timestamp"}
+)
+
+// List 列表类型。
+type List struct {
+	OfType Type
+}
+
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// ListOf 返回元素类型为 t 的列表类型。
+func ListOf(t Type) *List { return &List{OfType: t} }
+
+// ResolveFunc 解析根字段。返回值会按 JSON 序列化后供子字段选择。
+type ResolveFunc func(ctx context.Context, args Args) (any, error)
+
+// Argument 字段参数定义。
+type Argument struct {
+	Name        string
+	Type        Type
+	Required    bool
+	Description string
+}
+
+// Field 对象字段定义。非根字段 Resolve 为空，值取自父对象同名 JSON 键。
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	Resolve     ResolveFunc
+}
+
+func (f *Field) arg(name string) *Argument {
+	for _, a := range f.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// Object 对象类型。
+type Object struct {
+	Name        string
+	Description string
+	fields      []*Field
+	byName      map[string]*Field
+}
+
+// NewObject 创建对象类型，字段按传入顺序输出到 SDL。
+func NewObject(name, description string, fields ...*Field) *Object {
+	o := &Object{Name: name, Description: description, byName: make(map[string]*Field, len(fields))}
+	for _, f := range fields {
+		o.AddField(f)
+	}
+	return o
+}
+
+func (o *Object) String() string { return o.Name }
+
+// AddField 追加字段；同名字段会被替换。
+func (o *Object) AddField(f *Field) {
+	if _, ok := o.byName[f.Name]; ok {
+		for i, existing := range o.fields {
+			if existing.Name == f.Name {
+				o.fields[i] = f
+			}
+		}
+	} else {
+		o.fields = append(o.fields, f)
+	}
+	o.byName[f.Name] = f
+}
+
+// Fields 按定义顺序返回字段。
+func (o *Object) Fields() []*Field { return o.fields }
+
+// Field 按名称返回字段。
+func (o *Object) Field(name string) *Field { return o.byName[name] }
+
+// Schema 只读 schema：只有 Query 根类型。
+type Schema struct {
+	Query *Object
+	// MaxDepth 选择集最大嵌套层数，<=0 使用 DefaultMaxDepth。
+	MaxDepth int
+	// ErrorPresenter 将 resolver 错误转为响应错误；为空时直接使用 err.Error()。
+	ErrorPresenter func(err error) *Error
+}
+
+// DefaultMaxDepth 默认最大查询深度。
+const DefaultMaxDepth = 10
+
+// Registry 由 Go 类型反射生成对象类型，同一 Go 类型只生成一次。
+type Registry struct {
+	byType map[reflect.Type]Type
+	names  map[string]reflect.Type
+}
+
+// NewRegistry 创建类型注册表。
+func NewRegistry() *Registry {
+	return &Registry{byType: map[reflect.Type]Type{}, names: map[string]reflect.Type{}}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ObjectOf 以 sample 的结构体类型生成对象类型并命名为 name（为空时使用 Go 类型名）。
+func (r *Registry) ObjectOf(name string, sample any) *Object {
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("graphql: ObjectOf requires a struct, got %s", t))
+	}
+	if existing, ok := r.byType[t]; ok {
+		return existing.(*Object)
+	}
+	return r.buildObject(t, name)
+}
+
+// TypeOf 返回 Go 类型对应的 GraphQL 类型。
+func (r *Registry) TypeOf(sample any) Type {
+	return r.typeFor(reflect.TypeOf(sample))
+}
+
+func (r *Registry) typeFor(t reflect.Type) Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if existing, ok := r.byType[t]; ok {
+		return existing
+	}
+	switch {
+	case t == timeType:
+		return Time
+	case t == rawMessageType:
+		return JSON
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return JSON
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return String
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Int
+	case reflect.Float32, reflect.Float64:
+		return Float
+	case reflect.String:
+		return String
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return String
+		}
+		return ListOf(r.typeFor(t.Elem()))
+	case reflect.Struct:
+		return r.buildObject(t, "")
+	default:
+		// map / interface 等无法静态描述的结构
+		return JSON
+	}
+}
+
+func (r *Registry) buildObject(t reflect.Type, name string) *Object {
+	if name == "" {
+		name = r.typeName(t)
+	}
+	if other, taken := r.names[name]; taken && other != t {
+		panic(fmt.Sprintf("graphql: type name %q already used by %s", name, other))
+	}
+	obj := NewObject(name, "")
+	// 先注册再填充字段，支持自引用类型。
+	r.byType[t] = obj
+	r.names[name] = t
+	r.addStructFields(obj, t)
+	return obj
+}
+
+func (r *Registry) typeName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		name = "Object"
+	}
+	if other, taken := r.names[name]; !taken || other == t {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg != "" {
+		pkg = strings.ToUpper(pkg[:1]) + pkg[1:]
+	}
+	candidate := pkg + name
+	for i := 2; ; i++ {
+		if other, taken := r.names[candidate]; !taken || other == t {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%s%d", pkg, name, i)
+	}
+}
+
+func (r *Registry) addStructFields(obj *Object, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.addStructFields(obj, ft)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		obj.AddField(&Field{Name: name, Type: r.typeFor(sf.Type)})
+	}
+}
+
+// Args 已按参数类型转换后的参数值：Int 为 int64，Float 为 float64，列表为 []any。
+type Args map[string]any
+
+// Has 判断参数是否提供（显式 null 视为未提供）。
+func (a Args) Has(name string) bool {
+	v, ok := a[name]
+	return ok && v != nil
+}
+
+// String 返回字符串参数，缺省为空串。
+func (a Args) String(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+// Int64 返回整数参数，缺省为 0。
+func (a Args) Int64(name string) int64 {
+	n, _ := a[name].(int64)
+	return n
+}
+
+// Int 返回整数参数，缺省为 def。
+func (a Args) Int(name string, def int) int {
+	if n, ok := a[name].(int64); ok {
+		return int(n)
+	}
+	return def
+}
+
+// Float 返回浮点参数，缺省为 0。
+func (a Args) Float(name string) float64 {
+	f, _ := a[name].(float64)
+	return f
+}
+
+// Bool 返回布尔参数指针，未提供时为 nil。
+func (a Args) Bool(name string) *bool {
+	b, ok := a[name].(bool)
+	if !ok {
+		return nil
+	}
+	return &b
+}
+
+// IntList 返回整数列表参数。
+func (a Args) IntList(name string) []int64 {
+	items, _ := a[name].([]any)
+	out := make([]int64, 0, len(items))
+	for _, item := range items {
+		if n, ok := item.(int64); ok {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"sort"
+	"strings"
+)
+
+// SDL 以 GraphQL schema 定义语言输出 schema，供调用方生成客户端或查阅字段。
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	seen := map[string]bool{}
+	scalars := map[string]*Scalar{}
+	queue := []*Object{s.Query}
+	seen[s.Query.Name] = true
+
+	track := func(t Type) {
+		switch nt := namedType(t).(type) {
+		case *Object:
+			if !seen[nt.Name] {
+				seen[nt.Name] = true
+				queue = append(queue, nt)
+			}
+		case *Scalar:
+			if nt != Int && nt != Float && nt != String && nt != Boolean {
+				scalars[nt.Name] = nt
+			}
+		}
+	}
+
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+		b.WriteByte('\n')
+		writeDescription(&b, "", obj.Description)
+		b.WriteString("type " + obj.Name + " {\n")
+		for _, f := range obj.Fields() {
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				parts := make([]string, 0, len(f.Args))
+				for _, a := range f.Args {
+					track(a.Type)
+					part := a.Name + ": " + a.Type.String()
+					if a.Required {
+						part += "!"
+					}
+					parts = append(parts, part)
+				}
+				b.WriteString("(" + strings.Join(parts, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+			track(f.Type)
+		}
+		b.WriteString("}\n")
+	}
+
+	names := make([]string, 0, len(scalars))
+	for name := range scalars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteByte('\n')
+		writeDescription(&b, "", scalars[name].Description)
+		b.WriteString("scalar " + name + "\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, desc string) {
+	if desc == "" {
+		return
+	}
+	b.WriteString(indent + `"""` + strings.ReplaceAll(desc, `"""`, `\"""`) + `"""` + "\n")
+}
@@ -0,0 +1,228 @@
+// Package metrics 提供网关的 Prometheus 指标（请求量、上游耗时、failover、流故障与 token 用量）。
+//
+// 指标注册在独立的 Registry 上，仅在 SetEnabled(true) 后才记录；未启用时各 Observe/Inc 函数为空操作。
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "sub2api"
+
+// maxModelLabels 模型名来自客户端请求，超过上限后新出现的模型统一记为 other，防止标签基数失控。
+const maxModelLabels = 200
+
+const otherLabel = "other"
+
+var (
+	enabled atomic.Bool
+
+	registry = prometheus.NewRegistry()
+
+	gatewayRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_requests_total",
+		Help:      "Gateway requests by platform, model and response status class.",
+	}, []string{"platform", "model", "status"})
+
+	gatewayAccountRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_account_requests_total",
+		Help:      "Gateway requests by the finally selected upstream account.",
+	}, []string{"platform", "account_id", "status"})
+
+	gatewayRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gateway_request_duration_seconds",
+		Help:      "End-to-end gateway request duration (including streaming).",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"platform", "status"})
+
+	upstreamRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Upstream request latency until response headers are received.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"platform", "status"})
+
+	upstreamErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_errors_total",
+		Help:      "Upstream error events (retries, failovers, request errors) by kind.",
+	}, []string{"platform", "kind"})
+
+	failovers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_failovers_total",
+		Help:      "Account failovers triggered by upstream errors, by upstream status code.",
+	}, []string{"platform", "upstream_status"})
+
+	streamFaults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_stream_faults_total",
+		Help:      "Streams that failed after the response was committed (in-band SSE error frames).",
+	}, []string{"platform", "kind"})
+
+	tokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_tokens_total",
+		Help:      "Billed tokens by platform, model and token type.",
+	}, []string{"platform", "model", "type"})
+
+	models = &modelLabels{seen: make(map[string]struct{})}
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		gatewayRequests,
+		gatewayAccountRequests,
+		gatewayRequestDuration,
+		upstreamRequestDuration,
+		upstreamErrors,
+		failovers,
+		streamFaults,
+		tokens,
+	)
+}
+
+// SetEnabled 开关指标记录，由启动流程按 server.metrics.enabled 设置。
+func SetEnabled(v bool) {
+	enabled.Store(v)
+}
+
+// Enabled 返回指标记录是否开启。
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Handler 返回 Prometheus 文本格式的抓取处理器。
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveGatewayRequest 记录一次网关请求的结果；accountID <= 0 表示未选中账号（如鉴权失败）。
+func ObserveGatewayRequest(platform, model string, accountID int64, status int, duration time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	platform = platformLabel(platform)
+	statusClass := statusClassLabel(status)
+	gatewayRequests.WithLabelValues(platform, models.label(model), statusClass).Inc()
+	gatewayRequestDuration.WithLabelValues(platform, statusClass).Observe(duration.Seconds())
+	if accountID > 0 {
+		gatewayAccountRequests.WithLabelValues(platform, strconv.FormatInt(accountID, 10), statusClass).Inc()
+	}
+}
+
+// ObserveUpstreamRequest 记录上游请求耗时；status 为 0 表示未拿到响应（网络错误、超时等）。
+func ObserveUpstreamRequest(platform string, status int, duration time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	upstreamRequestDuration.WithLabelValues(platformLabel(platform), statusClassLabel(status)).Observe(duration.Seconds())
+}
+
+// IncUpstreamError 记录一次上游错误事件；kind 含 failover 时同时计入 failover 次数。
+func IncUpstreamError(platform, kind string, upstreamStatus int) {
+	if !enabled.Load() {
+		return
+	}
+	platform = platformLabel(platform)
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		kind = "unknown"
+	}
+	upstreamErrors.WithLabelValues(platform, kind).Inc()
+	if strings.Contains(kind, "failover") {
+		failovers.WithLabelValues(platform, strconv.Itoa(upstreamStatus)).Inc()
+	}
+}
+
+// IncStreamFault 记录一次响应已提交后的流内失败，kind 为对客错误类型（如 rate_limit_error）。
+func IncStreamFault(platform, kind string) {
+	if !enabled.Load() {
+		return
+	}
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		kind = "unknown"
+	}
+	streamFaults.WithLabelValues(platformLabel(platform), kind).Inc()
+}
+
+// AddTokens 累加一次请求的计费 token 数。
+func AddTokens(platform, model string, input, output, cacheCreation, cacheRead int) {
+	if !enabled.Load() {
+		return
+	}
+	platform = platformLabel(platform)
+	model = models.label(model)
+	for _, t := range []struct {
+		name  string
+		value int
+	}{
+		{"input", input},
+		{"output", output},
+		{"cache_creation", cacheCreation},
+		{"cache_read", cacheRead},
+	} {
+		if t.value > 0 {
+			tokens.WithLabelValues(platform, model, t.name).Add(float64(t.value))
+		}
+	}
+}
+
+func platformLabel(platform string) string {
+	platform = strings.TrimSpace(platform)
+	if platform == "" {
+		return "unknown"
+	}
+	return platform
+}
+
+func statusClassLabel(status int) string {
+	if status < 100 || status > 599 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// modelLabels 记录已出现的模型名，超过 maxModelLabels 后新模型记为 other。
+type modelLabels struct {
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+func (m *modelLabels) label(model string) string {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return "unknown"
+	}
+	m.mu.RLock()
+	_, ok := m.seen[model]
+	m.mu.RUnlock()
+	if ok {
+		return model
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.seen[model]; ok {
+		return model
+	}
+	if len(m.seen) >= maxModelLabels {
+		return otherLabel
+	}
+	m.seen[model] = struct{}{}
+	return model
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func enableForTest(t *testing.T) {
+	t.Helper()
+	SetEnabled(true)
+	t.Cleanup(func() { SetEnabled(false) })
+}
+
+func TestObserve_DisabledIsNoop(t *testing.T) {
+	SetEnabled(false)
+	ObserveGatewayRequest("anthropic", "disabled-model", 1, 200, time.Second)
+
+	require.NotContains(t, scrape(t), `model="disabled-model"`)
+}
+
+func TestObserve_RecordsGatewayMetrics(t *testing.T) {
+	enableForTest(t)
+
+	ObserveGatewayRequest("anthropic", "claude-test", 42, 529, 1500*time.Millisecond)
+	ObserveGatewayRequest("", "", 0, 401, time.Millisecond)
+	ObserveUpstreamRequest("anthropic", 0, 2*time.Second)
+	IncUpstreamError("anthropic", "failover", 529)
+	IncUpstreamError("anthropic", "retry", 500)
+	IncStreamFault("openai", "rate_limit_error")
+	AddTokens("anthropic", "claude-test", 100, 20, 0, 300)
+
+	out := scrape(t)
+	require.Contains(t, out, `sub2api_gateway_requests_total{model="claude-test",platform="anthropic",status="5xx"} 1`)
+	require.Contains(t, out, `sub2api_gateway_requests_total{model="unknown",platform="unknown",status="4xx"} 1`)
+	require.Contains(t, out, `sub2api_gateway_account_requests_total{account_id="42",platform="anthropic",status="5xx"} 1`)
+	require.NotContains(t, out, `account_id="0"`, "requests without a selected account are not attributed")
+	require.Contains(t, out, `sub2api_upstream_request_duration_seconds_count{platform="anthropic",status="error"} 1`)
+	require.Contains(t, out, `sub2api_gateway_failovers_total{platform="anthropic",upstream_status="529"} 1`)
+	require.Contains(t, out, `sub2api_upstream_errors_total{kind="retry",platform="anthropic"} 1`)
+	require.NotContains(t, out, `upstream_status="500"`, "non-failover errors are not failovers")
+	require.Contains(t, out, `sub2api_gateway_stream_faults_total{kind="rate_limit_error",platform="openai"} 1`)
+	require.Contains(t, out, `sub2api_gateway_tokens_total{model="claude-test",platform="anthropic",type="cache_read"} 300`)
+	require.NotContains(t, out, `type="cache_creation"`, "zero counts are skipped")
+}
+
+func TestModelLabels_CapsCardinality(t *testing.T) {
+	m := &modelLabels{seen: make(map[string]struct{})}
+	for i := 0; i < maxModelLabels; i++ {
+		require.Equal(t, fmt.Sprintf("m-%d", i), m.label(fmt.Sprintf("m-%d", i)))
+	}
+	require.Equal(t, otherLabel, m.label("one-too-many"))
+	require.Equal(t, "m-0", m.label("m-0"), "known models keep their label")
+	require.Equal(t, "unknown", m.label(" "))
+}
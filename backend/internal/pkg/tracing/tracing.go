@@ -0,0 +1,93 @@
+// Package tracing 提供 OpenTelemetry 链路追踪：网关入口、转发与上游请求的 span 经 OTLP/HTTP 导出，
+// 用于在基础设施之间按 trace 关联慢请求。
+//
+// 未调用 Setup（或 tracing.enabled=false）时全局 TracerProvider 为 noop，Tracer() 产生的 span 不记录也不导出。
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/Wei-Shaw/sub2api"
+
+// 跨 span 关联用的属性键，与日志字段同名，便于从日志跳转到 trace。
+const (
+	AttrClientRequestID   = attribute.Key("client_request_id")
+	AttrAccountID         = attribute.Key("account_id")
+	AttrUpstreamRequestID = attribute.Key("upstream_request_id")
+	AttrPlatform          = attribute.Key("platform")
+	AttrModel             = attribute.Key("model")
+)
+
+// Setup 按配置初始化全局 TracerProvider 与 W3C trace context 传播器，返回进程退出时调用的 shutdown。
+// 未启用时不做任何事，返回的 shutdown 为空操作。
+func Setup(ctx context.Context, cfg config.TracingConfig, version string) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(strings.TrimSpace(cfg.OTLPEndpoint))}
+	if path := strings.TrimSpace(cfg.OTLPURLPath); path != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(path))
+	}
+	if cfg.OTLPInsecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.OTLPHeaders) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.OTLPHeaders))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := newProvider(cfg, version, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// newProvider 构建带服务资源与采样器的 TracerProvider；入站请求已带采样决定时沿用父 span 的决定。
+func newProvider(cfg config.TracingConfig, version string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	attrs := []attribute.KeyValue{semconv.ServiceName(strings.TrimSpace(cfg.ServiceName))}
+	if version = strings.TrimSpace(version); version != "" {
+		attrs = append(attrs, semconv.ServiceVersion(version))
+	}
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// Tracer 返回全局 provider 下的 tracer；Setup 之前创建的 tracer 在 Setup 之后同样生效。
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Propagator 返回全局 trace context 传播器。
+func Propagator() propagation.TextMapPropagator {
+	return otel.GetTextMapPropagator()
+}
+
+// EndSpan 按 err 设置 span 状态后结束 span；context.Canceled（客户端断开）不视为错误。
+func EndSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
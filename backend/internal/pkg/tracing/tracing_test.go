@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupDisabledIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Enabled: false}, "1.0.0")
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, span := Tracer().Start(context.Background(), "noop")
+	require.False(t, span.IsRecording())
+	span.End()
+}
+
+func TestNewProviderSamplingAndResource(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	// 采样比例为 0 附近时根 span 不采样，但已采样的父 span 决定优先
+	provider := newProvider(config.TracingConfig{ServiceName: "sub2api-test", SampleRatio: 1e-12}, "1.2.3", sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	tracer := provider.Tracer(instrumentationName)
+
+	_, root := tracer.Start(context.Background(), "root")
+	require.False(t, root.IsRecording())
+	root.End()
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	_, child := tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), parent), "child")
+	require.True(t, child.IsRecording())
+	child.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, trace.TraceID{1}, spans[0].SpanContext().TraceID())
+	resAttrs := spans[0].Resource().Set()
+	name, _ := resAttrs.Value(attribute.Key("service.name"))
+	version, _ := resAttrs.Value(attribute.Key("service.version"))
+	require.Equal(t, "sub2api-test", name.AsString())
+	require.Equal(t, "1.2.3", version.AsString())
+}
+
+func TestEndSpanStatus(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer(instrumentationName)
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	EndSpan(ok, nil)
+	_, canceled := tracer.Start(context.Background(), "canceled")
+	EndSpan(canceled, context.Canceled)
+	_, failed := tracer.Start(context.Background(), "failed")
+	EndSpan(failed, errors.New("upstream 502"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, codes.Unset, spans[1].Status().Code, "client disconnects are not errors")
+	require.Equal(t, codes.Error, spans[2].Status().Code)
+	require.Equal(t, "upstream 502", spans[2].Status().Description)
+}
@@ -104,6 +104,9 @@ type ModelStat struct {
 	Cost                float64 `json:"cost"`         // 标准计费
 	ActualCost          float64 `json:"actual_cost"`  // 实际扣除
 	AccountCost         float64 `json:"account_cost"` // 账号成本
+	// 输出 token 中的推理与工具调用部分（工具调用为估算值）
+	ReasoningTokens int64 `json:"reasoning_tokens"`
+	ToolCallTokens  int64 `json:"tool_call_tokens"`
 }
 
 // EndpointStat represents usage statistics for a single request endpoint.
@@ -296,6 +299,8 @@ type UsageStats struct {
 	TotalActualCost          float64        `json:"total_actual_cost"`
 	TotalAccountCost         *float64       `json:"total_account_cost,omitempty"`
 	AverageDurationMs        float64        `json:"average_duration_ms"`
+	TotalReasoningTokens     int64          `json:"total_reasoning_tokens"`
+	TotalToolCallTokens      int64          `json:"total_tool_call_tokens"`
 	Endpoints                []EndpointStat `json:"endpoints,omitempty"`
 	UpstreamEndpoints        []EndpointStat `json:"upstream_endpoints,omitempty"`
 	EndpointPaths            []EndpointStat `json:"endpoint_paths,omitempty"`
@@ -324,6 +329,21 @@ type BatchAPIKeyUsageStats struct {
 	TotalActualCost float64 `json:"total_actual_cost"`
 }
 
+// ThreadCost 单个会话/线程（客户端传入的 thread ID）所有轮次的用量与成本汇总
+type ThreadCost struct {
+	ThreadID            string     `json:"thread_id"`
+	Requests            int64      `json:"requests"`
+	InputTokens         int64      `json:"input_tokens"`
+	OutputTokens        int64      `json:"output_tokens"`
+	CacheCreationTokens int64      `json:"cache_creation_tokens"`
+	CacheReadTokens     int64      `json:"cache_read_tokens"`
+	TotalTokens         int64      `json:"total_tokens"`
+	Cost                float64    `json:"cost"`        // 标准计费
+	ActualCost          float64    `json:"actual_cost"` // 实际扣除
+	FirstRequestAt      *time.Time `json:"first_request_at"`
+	LastRequestAt       *time.Time `json:"last_request_at"`
+}
+
 // AccountUsageHistory represents daily usage history for an account
 type AccountUsageHistory struct {
 	Date       string  `json:"date"`
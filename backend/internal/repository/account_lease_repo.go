@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type accountLeaseRepository struct {
+	db *sql.DB
+}
+
+func NewAccountLeaseRepository(db *sql.DB) service.AccountLeaseRepository {
+	return &accountLeaseRepository{db: db}
+}
+
+const accountLeaseColumns = `account_id, lease_id, holder, note, prev_schedulable, expires_at, created_at, renewed_at`
+
+// Checkout 先锁账号行再写租约，与释放路径保持相同的加锁顺序。
+// 接管已过期但尚未清理的租约时保留原 prev_schedulable：此时账号仍处于上一次借出造成的停调度状态。
+func (r *accountLeaseRepository) Checkout(ctx context.Context, lease *service.AccountLease) (*service.AccountLease, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var schedulable bool
+	err = tx.QueryRowContext(ctx, `SELECT schedulable FROM accounts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, lease.AccountID).Scan(&schedulable)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO account_leases (account_id, lease_id, holder, note, prev_schedulable, expires_at, created_at, renewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (account_id) DO UPDATE
+		SET lease_id = EXCLUDED.lease_id,
+			holder = EXCLUDED.holder,
+			note = EXCLUDED.note,
+			expires_at = EXCLUDED.expires_at,
+			created_at = NOW(),
+			renewed_at = NOW()
+		WHERE account_leases.expires_at <= NOW()
+		RETURNING `+accountLeaseColumns,
+		lease.AccountID, lease.LeaseID, lease.Holder, lease.Note, schedulable, lease.ExpiresAt)
+	created, err := scanAccountLease(row)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountLeaseNotFound) {
+			return nil, service.ErrAccountLeaseHeld
+		}
+		return nil, err
+	}
+
+	if schedulable {
+		if _, err := tx.ExecContext(ctx, `UPDATE accounts SET schedulable = FALSE, updated_at = NOW() WHERE id = $1`, lease.AccountID); err != nil {
+			return nil, err
+		}
+		if err := enqueueSchedulerOutbox(ctx, tx, service.SchedulerOutboxEventAccountChanged, &lease.AccountID, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *accountLeaseRepository) Renew(ctx context.Context, leaseID string, expiresAt time.Time) (*service.AccountLease, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE account_leases
+		SET expires_at = $2, renewed_at = NOW()
+		WHERE lease_id = $1 AND expires_at > NOW()
+		RETURNING `+accountLeaseColumns, leaseID, expiresAt)
+	return scanAccountLease(row)
+}
+
+func (r *accountLeaseRepository) Release(ctx context.Context, leaseID string) (*service.AccountLease, error) {
+	var accountID int64
+	err := r.db.QueryRowContext(ctx, `SELECT account_id FROM account_leases WHERE lease_id = $1`, leaseID).Scan(&accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrAccountLeaseNotFound
+		}
+		return nil, err
+	}
+	return r.release(ctx, accountID, `lease_id = $2`, leaseID)
+}
+
+// ReleaseExpired 逐个账号在独立事务中释放，单个失败不影响其余租约。
+func (r *accountLeaseRepository) ReleaseExpired(ctx context.Context, now time.Time) ([]*service.AccountLease, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT account_id FROM account_leases WHERE expires_at <= $1 ORDER BY account_id`, now)
+	if err != nil {
+		return nil, err
+	}
+	var accountIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		accountIDs = append(accountIDs, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		released []*service.AccountLease
+		firstErr error
+	)
+	for _, accountID := range accountIDs {
+		lease, err := r.release(ctx, accountID, `expires_at <= $2`, now)
+		if err != nil {
+			// 并发实例已释放或租约已被续期时静默跳过
+			if !errors.Is(err, service.ErrAccountLeaseNotFound) && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		released = append(released, lease)
+	}
+	return released, firstErr
+}
+
+func (r *accountLeaseRepository) List(ctx context.Context) ([]*service.AccountLease, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+accountLeaseColumns+` FROM account_leases ORDER BY expires_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var leases []*service.AccountLease
+	for rows.Next() {
+		lease, err := scanAccountLease(rows)
+		if err != nil {
+			return nil, err
+		}
+		leases = append(leases, lease)
+	}
+	return leases, rows.Err()
+}
+
+// release 锁定账号行后按条件删除租约，并在借出前可调度时恢复调度。
+func (r *accountLeaseRepository) release(ctx context.Context, accountID int64, cond string, arg any) (*service.AccountLease, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM accounts WHERE id = $1 FOR UPDATE`, accountID); err != nil {
+		return nil, err
+	}
+	row := tx.QueryRowContext(ctx, `DELETE FROM account_leases WHERE account_id = $1 AND `+cond+` RETURNING `+accountLeaseColumns, accountID, arg)
+	lease, err := scanAccountLease(row)
+	if err != nil {
+		return nil, err
+	}
+	if lease.PrevSchedulable {
+		if _, err := tx.ExecContext(ctx, `UPDATE accounts SET schedulable = TRUE, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, accountID); err != nil {
+			return nil, err
+		}
+		if err := enqueueSchedulerOutbox(ctx, tx, service.SchedulerOutboxEventAccountChanged, &accountID, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+func scanAccountLease(row scannable) (*service.AccountLease, error) {
+	l := &service.AccountLease{}
+	if err := row.Scan(&l.AccountID, &l.LeaseID, &l.Holder, &l.Note, &l.PrevSchedulable, &l.ExpiresAt, &l.CreatedAt, &l.RenewedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrAccountLeaseNotFound
+		}
+		return nil, err
+	}
+	return l, nil
+}
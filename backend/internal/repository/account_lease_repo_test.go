@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+var accountLeaseTestColumns = []string{"account_id", "lease_id", "holder", "note", "prev_schedulable", "expires_at", "created_at", "renewed_at"}
+
+func TestAccountLeaseRepositoryCheckout_PausesSchedulingInSameTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAccountLeaseRepository(db)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	expires := now.Add(15 * time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT schedulable FROM accounts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE")).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"schedulable"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE account_leases.expires_at <= NOW()")).
+		WithArgs(int64(7), "lease-1", "batch-runner", "", true, expires).
+		WillReturnRows(sqlmock.NewRows(accountLeaseTestColumns).AddRow(int64(7), "lease-1", "batch-runner", "", true, expires, now, now))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET schedulable = FALSE")).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO scheduler_outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	lease, err := repo.Checkout(context.Background(), &service.AccountLease{AccountID: 7, LeaseID: "lease-1", Holder: "batch-runner", ExpiresAt: expires})
+
+	require.NoError(t, err)
+	require.Equal(t, "lease-1", lease.LeaseID)
+	require.True(t, lease.PrevSchedulable)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountLeaseRepositoryCheckout_RejectsActiveLease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAccountLeaseRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT schedulable FROM accounts")).
+		WillReturnRows(sqlmock.NewRows([]string{"schedulable"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO account_leases")).
+		WillReturnRows(sqlmock.NewRows(accountLeaseTestColumns))
+	mock.ExpectRollback()
+
+	_, err = repo.Checkout(context.Background(), &service.AccountLease{AccountID: 7, LeaseID: "lease-2", Holder: "other", ExpiresAt: time.Now().Add(time.Minute)})
+
+	require.ErrorIs(t, err, service.ErrAccountLeaseHeld)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountLeaseRepositoryRelease_RestoresPreviousSchedulable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAccountLeaseRepository(db)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account_id FROM account_leases WHERE lease_id = $1")).
+		WithArgs("lease-1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow(int64(7)))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT 1 FROM accounts WHERE id = $1 FOR UPDATE")).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM account_leases WHERE account_id = $1 AND lease_id = $2")).
+		WithArgs(int64(7), "lease-1").
+		WillReturnRows(sqlmock.NewRows(accountLeaseTestColumns).AddRow(int64(7), "lease-1", "batch-runner", "", true, now, now, now))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET schedulable = TRUE")).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO scheduler_outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	lease, err := repo.Release(context.Background(), "lease-1")
+
+	require.NoError(t, err)
+	require.Equal(t, int64(7), lease.AccountID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
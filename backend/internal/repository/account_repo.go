@@ -927,8 +927,9 @@ func (r *accountRepository) syncSchedulerAccountSnapshots(ctx context.Context, a
 }
 
 func (r *accountRepository) ClearError(ctx context.Context, id int64) error {
+	// 归档账号的凭证已封存，只能经恢复归档重新启用
 	_, err := r.client.Account.Update().
-		Where(dbaccount.IDEQ(id)).
+		Where(dbaccount.IDEQ(id), dbaccount.StatusNEQ(service.StatusArchived)).
 		SetStatus(service.StatusActive).
 		SetErrorMessage("").
 		Save(ctx)
@@ -993,10 +994,17 @@ func (r *accountRepository) GetGroups(ctx context.Context, accountID int64) ([]s
 }
 
 func (r *accountRepository) BindGroups(ctx context.Context, accountID int64, groupIDs []int64) error {
-	existingGroupIDs, err := r.loadAccountGroupIDs(ctx, accountID)
+	existing, err := r.client.AccountGroup.Query().Where(dbaccountgroup.AccountIDEQ(accountID)).All(ctx)
 	if err != nil {
 		return err
 	}
+	existingGroupIDs := make([]int64, 0, len(existing))
+	// 重建绑定时保留仍在的分组上已配置的故障转移层级与并发份额
+	existingByGroup := make(map[int64]*dbent.AccountGroup, len(existing))
+	for _, entry := range existing {
+		existingGroupIDs = append(existingGroupIDs, entry.GroupID)
+		existingByGroup[entry.GroupID] = entry
+	}
 	// 使用事务保证删除旧绑定与创建新绑定的原子性
 	tx, err := r.client.Tx(ctx)
 	if err != nil && !errors.Is(err, dbent.ErrTxStarted) {
@@ -1025,11 +1033,14 @@ func (r *accountRepository) BindGroups(ctx context.Context, accountID int64, gro
 
 	builders := make([]*dbent.AccountGroupCreate, 0, len(groupIDs))
 	for i, groupID := range groupIDs {
-		builders = append(builders, txClient.AccountGroup.Create().
+		builder := txClient.AccountGroup.Create().
 			SetAccountID(accountID).
 			SetGroupID(groupID).
-			SetPriority(i+1),
-		)
+			SetPriority(i + 1)
+		if prev := existingByGroup[groupID]; prev != nil {
+			builder.SetTier(prev.Tier).SetConcurrencyShare(prev.ConcurrencyShare)
+		}
+		builders = append(builders, builder)
 	}
 
 	if _, err := txClient.AccountGroup.CreateBulk(builders...).Save(ctx); err != nil {
@@ -1048,6 +1059,26 @@ func (r *accountRepository) BindGroups(ctx context.Context, accountID int64, gro
 	return nil
 }
 
+// ListAccountGroupConcurrencyShares 列出全部配置了并发份额的账号分组绑定
+func (r *accountRepository) ListAccountGroupConcurrencyShares(ctx context.Context) ([]service.AccountGroup, error) {
+	entries, err := r.client.AccountGroup.Query().
+		Where(dbaccountgroup.ConcurrencyShareGT(0)).
+		Select(dbaccountgroup.FieldAccountID, dbaccountgroup.FieldGroupID, dbaccountgroup.FieldConcurrencyShare).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]service.AccountGroup, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, service.AccountGroup{
+			AccountID:        entry.AccountID,
+			GroupID:          entry.GroupID,
+			ConcurrencyShare: entry.ConcurrencyShare,
+		})
+	}
+	return out, nil
+}
+
 func (r *accountRepository) ListSchedulable(ctx context.Context) ([]service.Account, error) {
 	now := time.Now()
 	accounts, err := r.client.Account.Query().
@@ -1936,8 +1967,11 @@ func (r *accountRepository) loadAccountGroups(ctx context.Context, accountIDs []
 				AccountID: ag.AccountID,
 				GroupID:   ag.GroupID,
 				Priority:  ag.Priority,
+				Tier:      ag.Tier,
 				CreatedAt: ag.CreatedAt,
 				Group:     groupSvc,
+
+				ConcurrencyShare: ag.ConcurrencyShare,
 			}
 			accountGroupsByAccount[ag.AccountID] = append(accountGroupsByAccount[ag.AccountID], agSvc)
 			groupIDsByAccount[ag.AccountID] = append(groupIDsByAccount[ag.AccountID], ag.GroupID)
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// 账号用量窗口缓存常量定义
+//
+// 设计说明：
+// 每个账号一个 Redis Hash，按窗口分桶计数：
+// - Key: usage_window:account:{accountID}
+// - Field: {windowName}:{bucketStartUnix}
+// - Value: 该桶内的请求数
+//
+// 递增、过期桶清理与窗口求和在同一个 Lua 脚本中完成，多实例并发写入时计数保持一致；
+// 单 key 操作，兼容 Redis Cluster。时间取 Redis 服务端 TIME，避免多实例时钟偏差。
+const (
+	// 用量窗口键前缀
+	// 格式: usage_window:account:{accountID}
+	usageWindowKeyPrefix = "usage_window:account:"
+)
+
+// usageWindowScript 递增（可选）并统计各窗口计数
+// KEYS[1] = usage_window:account:{accountID}
+// ARGV[1] = 递增量（0 表示只读）
+// ARGV[2..] = 每个窗口三个参数：name, windowSeconds, bucketSeconds
+// 返回: 各窗口当前计数（与传入窗口顺序一致）
+var usageWindowScript = redis.NewScript(`
+	-- Redis 3.2-4.x compat: opt into effects replication so redis.call('TIME')
+	-- replicates correctly. No-op on Redis 5.0+ (effects replication is default).
+	redis.replicate_commands()
+	local key = KEYS[1]
+	local incr = tonumber(ARGV[1])
+	local now = tonumber(redis.call('TIME')[1])
+
+	local names = {}
+	local windows = {}
+	local maxWindow = 0
+	local n = (#ARGV - 1) / 3
+	for i = 1, n do
+		local name = ARGV[(i - 1) * 3 + 2]
+		local window = tonumber(ARGV[(i - 1) * 3 + 3])
+		local bucket = tonumber(ARGV[(i - 1) * 3 + 4])
+		names[i] = name
+		windows[name] = window
+		if window > maxWindow then
+			maxWindow = window
+		end
+		if incr > 0 then
+			redis.call('HINCRBY', key, name .. ':' .. (now - now % bucket), incr)
+		end
+	end
+
+	local sums = {}
+	local fields = redis.call('HGETALL', key)
+	for j = 1, #fields, 2 do
+		local field = fields[j]
+		local sep = string.find(field, ':', 1, true)
+		local name = sep and string.sub(field, 1, sep - 1) or ''
+		local start = sep and tonumber(string.sub(field, sep + 1)) or nil
+		local window = windows[name]
+		if window and start then
+			if start <= now - window then
+				redis.call('HDEL', key, field)
+			else
+				sums[name] = (sums[name] or 0) + tonumber(fields[j + 1])
+			end
+		end
+	end
+
+	if incr > 0 and maxWindow > 0 then
+		redis.call('EXPIRE', key, maxWindow)
+	end
+
+	local result = {}
+	for i = 1, n do
+		result[i] = sums[names[i]] or 0
+	end
+	return result
+`)
+
+type accountUsageWindowCache struct {
+	rdb *redis.Client
+}
+
+// NewAccountUsageWindowCache 创建账号用量窗口缓存
+func NewAccountUsageWindowCache(rdb *redis.Client) service.AccountUsageWindowCache {
+	// 预加载 Lua 脚本到 Redis，避免 Pipeline 中出现 NOSCRIPT 错误
+	if err := usageWindowScript.Load(context.Background(), rdb).Err(); err != nil {
+		log.Printf("[AccountUsageWindowCache] Failed to preload Lua script: %v", err)
+	}
+	return &accountUsageWindowCache{rdb: rdb}
+}
+
+// usageWindowKey 生成用量窗口的 Redis 键
+func usageWindowKey(accountID int64) string {
+	return fmt.Sprintf("%s%d", usageWindowKeyPrefix, accountID)
+}
+
+func usageWindowScriptArgs(incr int, windows []service.AccountUsageWindowSpec) []any {
+	args := make([]any, 0, 1+len(windows)*3)
+	args = append(args, incr)
+	for _, w := range windows {
+		args = append(args, w.Name, int64(w.Window/time.Second), int64(w.Bucket/time.Second))
+	}
+	return args
+}
+
+func (c *accountUsageWindowCache) IncrementUsageWindows(ctx context.Context, accountID int64, windows []service.AccountUsageWindowSpec) ([]int64, error) {
+	counts, err := usageWindowScript.Run(ctx, c.rdb, []string{usageWindowKey(accountID)}, usageWindowScriptArgs(1, windows)...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("usage window increment: %w", err)
+	}
+	return counts, nil
+}
+
+func (c *accountUsageWindowCache) GetUsageWindowsBatch(ctx context.Context, accountIDs []int64, windows []service.AccountUsageWindowSpec) (map[int64][]int64, error) {
+	result := make(map[int64][]int64, len(accountIDs))
+	if len(accountIDs) == 0 {
+		return result, nil
+	}
+
+	args := usageWindowScriptArgs(0, windows)
+	pipe := c.rdb.Pipeline()
+	cmds := make(map[int64]*redis.Cmd, len(accountIDs))
+	for _, id := range accountIDs {
+		cmds[id] = usageWindowScript.Run(ctx, pipe, []string{usageWindowKey(id)}, args...)
+	}
+
+	// 执行 pipeline，即使部分失败也尝试获取成功的结果
+	_, _ = pipe.Exec(ctx)
+
+	var firstErr error
+	for id, cmd := range cmds {
+		counts, err := cmd.Int64Slice()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result[id] = counts
+	}
+	if len(result) == 0 && firstErr != nil {
+		return result, fmt.Errorf("usage window batch get: %w", firstErr)
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+var usageWindowTestSpecs = []service.AccountUsageWindowSpec{
+	{Name: "5h", Window: 5 * time.Hour, Bucket: 5 * time.Minute},
+	{Name: "7d", Window: 7 * 24 * time.Hour, Bucket: time.Hour},
+}
+
+func newAccountUsageWindowCacheTest(t *testing.T) (*accountUsageWindowCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = rdb.Close()
+	})
+	return &accountUsageWindowCache{rdb: rdb}, mr
+}
+
+func TestAccountUsageWindowCache_IncrementAndRollOff(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newAccountUsageWindowCacheTest(t)
+	start := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	mr.SetTime(start)
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.IncrementUsageWindows(ctx, 7, usageWindowTestSpecs)
+		require.NoError(t, err)
+	}
+
+	// 6 小时后：5h 窗口内的计数已过期，周窗口仍保留
+	mr.SetTime(start.Add(6 * time.Hour))
+	counts, err := cache.IncrementUsageWindows(ctx, 7, usageWindowTestSpecs)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 4}, counts)
+
+	// 8 天后：两个窗口都只剩新计数
+	mr.SetTime(start.Add(8 * 24 * time.Hour))
+	counts, err = cache.IncrementUsageWindows(ctx, 7, usageWindowTestSpecs)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 1}, counts)
+	fields, err := mr.HKeys(usageWindowKey(7))
+	require.NoError(t, err)
+	require.Len(t, fields, 2, "expired buckets should be pruned")
+}
+
+func TestAccountUsageWindowCache_GetBatch(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newAccountUsageWindowCacheTest(t)
+	mr.SetTime(time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC))
+
+	_, err := cache.IncrementUsageWindows(ctx, 1, usageWindowTestSpecs)
+	require.NoError(t, err)
+	_, err = cache.IncrementUsageWindows(ctx, 1, usageWindowTestSpecs)
+	require.NoError(t, err)
+
+	counts, err := cache.GetUsageWindowsBatch(ctx, []int64{1, 2}, usageWindowTestSpecs)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 2}, counts[1])
+	require.Equal(t, []int64{0, 0}, counts[2])
+	require.False(t, mr.Exists(usageWindowKey(2)), "read-only lookups must not create keys")
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type adminServiceTokenRepository struct {
+	db *sql.DB
+}
+
+func NewAdminServiceTokenRepository(db *sql.DB) service.AdminServiceTokenRepository {
+	return &adminServiceTokenRepository{db: db}
+}
+
+// adminServiceTokenColumns 不包含 token_hash，摘要只用于查询条件。
+const adminServiceTokenColumns = `id, name, token_prefix, scopes, expires_at, last_used_at, last_used_ip,
+	created_by, revoked_at, created_at, updated_at`
+
+func (r *adminServiceTokenRepository) Create(ctx context.Context, token *service.AdminServiceToken, tokenHash string) (*service.AdminServiceToken, error) {
+	scopes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO admin_service_tokens (name, token_hash, token_prefix, scopes, expires_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6, NOW(), NOW())
+		RETURNING `+adminServiceTokenColumns,
+		token.Name, tokenHash, token.TokenPrefix, string(scopes), token.ExpiresAt, token.CreatedBy)
+	return scanAdminServiceToken(row)
+}
+
+func (r *adminServiceTokenRepository) List(ctx context.Context) ([]*service.AdminServiceToken, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+adminServiceTokenColumns+` FROM admin_service_tokens ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tokens []*service.AdminServiceToken
+	for rows.Next() {
+		token, err := scanAdminServiceToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *adminServiceTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*service.AdminServiceToken, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+adminServiceTokenColumns+` FROM admin_service_tokens WHERE token_hash = $1`, tokenHash)
+	return scanAdminServiceToken(row)
+}
+
+func (r *adminServiceTokenRepository) Revoke(ctx context.Context, id int64) (*service.AdminServiceToken, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE admin_service_tokens
+		SET revoked_at = COALESCE(revoked_at, NOW()), updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+adminServiceTokenColumns, id)
+	return scanAdminServiceToken(row)
+}
+
+func (r *adminServiceTokenRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM admin_service_tokens WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return service.ErrAdminServiceTokenNotFound
+	}
+	return nil
+}
+
+// UpdateLastUsed 不更新 updated_at，updated_at 只反映管理操作。
+func (r *adminServiceTokenRepository) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time, ip string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE admin_service_tokens SET last_used_at = $2, last_used_ip = $3 WHERE id = $1`, id, usedAt, ip)
+	return err
+}
+
+func scanAdminServiceToken(row scannable) (*service.AdminServiceToken, error) {
+	t := &service.AdminServiceToken{}
+	var (
+		scopes     []byte
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+		revokedAt  sql.NullTime
+	)
+	if err := row.Scan(
+		&t.ID, &t.Name, &t.TokenPrefix, &scopes, &expiresAt, &lastUsedAt, &t.LastUsedIP,
+		&t.CreatedBy, &revokedAt, &t.CreatedAt, &t.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrAdminServiceTokenNotFound
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &t.Scopes); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		t.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		t.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return t, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminServiceTokenRepositoryGetByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAdminServiceTokenRepository(db)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	expires := now.Add(30 * 24 * time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM admin_service_tokens WHERE token_hash = $1")).
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "name", "token_prefix", "scopes", "expires_at", "last_used_at", "last_used_ip",
+			"created_by", "revoked_at", "created_at", "updated_at",
+		}).AddRow(int64(3), "grafana", "sat_1234abcd", []byte(`["accounts:read","usage:read"]`), expires, nil, "", int64(1), nil, now, now))
+
+	token, err := repo.GetByHash(context.Background(), "abc")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"accounts:read", "usage:read"}, token.Scopes)
+	require.Equal(t, expires, *token.ExpiresAt)
+	require.Nil(t, token.LastUsedAt)
+	require.Nil(t, token.RevokedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminServiceTokenRepositoryDeleteMissing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAdminServiceTokenRepository(db)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM admin_service_tokens WHERE id = $1")).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Delete(context.Background(), 5)
+
+	require.ErrorIs(t, err, service.ErrAdminServiceTokenNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -13,23 +13,41 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/service"
 )
 
-// AESEncryptor implements SecretEncryptor using AES-256-GCM
+// AESEncryptor implements SecretEncryptor using AES-256-GCM.
+// New secrets are always sealed with the current key; previous keys are only
+// used for decryption while a key rotation is in progress.
 type AESEncryptor struct {
-	key []byte
+	key          []byte
+	previousKeys [][]byte
 }
 
 // NewAESEncryptor creates a new AES encryptor
 func NewAESEncryptor(cfg *config.Config) (service.SecretEncryptor, error) {
-	key, err := hex.DecodeString(cfg.Totp.EncryptionKey)
+	key, err := decodeAESKey(cfg.Totp.EncryptionKey)
 	if err != nil {
 		return nil, fmt.Errorf("invalid totp encryption key: %w", err)
 	}
 
-	if len(key) != 32 {
-		return nil, fmt.Errorf("totp encryption key must be 32 bytes (64 hex chars), got %d bytes", len(key))
+	e := &AESEncryptor{key: key}
+	for i, raw := range cfg.Totp.PreviousEncryptionKeys {
+		previous, err := decodeAESKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid totp previous encryption key #%d: %w", i+1, err)
+		}
+		e.previousKeys = append(e.previousKeys, previous)
 	}
+	return e, nil
+}
 
-	return &AESEncryptor{key: key}, nil
+func decodeAESKey(raw string) ([]byte, error) {
+	key, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes (64 hex chars), got %d bytes", len(key))
+	}
+	return key, nil
 }
 
 // Encrypt encrypts plaintext using AES-256-GCM
@@ -59,15 +77,53 @@ func (e *AESEncryptor) Encrypt(plaintext string) (string, error) {
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// Decrypt decrypts ciphertext using AES-256-GCM
+// Decrypt decrypts ciphertext with the current key, falling back to previous keys
 func (e *AESEncryptor) Decrypt(ciphertext string) (string, error) {
+	plaintext, err := decryptAESGCM(e.key, ciphertext)
+	if err == nil {
+		return plaintext, nil
+	}
+	for _, key := range e.previousKeys {
+		if plaintext, prevErr := decryptAESGCM(key, ciphertext); prevErr == nil {
+			return plaintext, nil
+		}
+	}
+	return "", err
+}
+
+// Reencrypt re-seals ciphertext produced by a previous key with the current key.
+// Ciphertext already sealed with the current key is returned unchanged with rotated=false.
+// The new ciphertext is decrypted again and compared before being returned.
+func (e *AESEncryptor) Reencrypt(ciphertext string) (string, bool, error) {
+	if _, err := decryptAESGCM(e.key, ciphertext); err == nil {
+		return ciphertext, false, nil
+	}
+	for _, key := range e.previousKeys {
+		plaintext, err := decryptAESGCM(key, ciphertext)
+		if err != nil {
+			continue
+		}
+		sealed, err := e.Encrypt(plaintext)
+		if err != nil {
+			return "", false, err
+		}
+		if check, err := decryptAESGCM(e.key, sealed); err != nil || check != plaintext {
+			return "", false, fmt.Errorf("verify re-encrypted secret failed")
+		}
+		return sealed, true, nil
+	}
+	return "", false, fmt.Errorf("no configured key can decrypt the secret")
+}
+
+// decryptAESGCM decrypts ciphertext using AES-256-GCM
+func decryptAESGCM(key []byte, ciphertext string) (string, error) {
 	// Decode from base64
 	data, err := base64.StdEncoding.DecodeString(ciphertext)
 	if err != nil {
 		return "", fmt.Errorf("decode base64: %w", err)
 	}
 
-	block, err := aes.NewCipher(e.key)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", fmt.Errorf("create cipher: %w", err)
 	}
@@ -217,3 +217,52 @@ func TestAESEncryptor_CrossInstance_DifferentKey_CannotDecrypt(t *testing.T) {
 	_, err = enc2.Decrypt(ct)
 	require.Error(t, err, "不同密钥的实例不应能解密对方的密文")
 }
+
+// ── 密钥轮换 ─────────────────────────────────────────────────────────────────
+
+func TestAESEncryptor_PreviousKeys_DecryptAndReencrypt(t *testing.T) {
+	oldKey, newKey := aesHexKey(32, 0xAA), aesHexKey(32, 0xBB)
+	oldEnc, err := NewAESEncryptor(aesTestCfg(oldKey))
+	require.NoError(t, err)
+	legacy, err := oldEnc.Encrypt("rotate me")
+	require.NoError(t, err)
+
+	cfg := aesTestCfg(newKey)
+	cfg.Totp.PreviousEncryptionKeys = []string{oldKey}
+	enc, err := NewAESEncryptor(cfg)
+	require.NoError(t, err)
+	keyring := enc.(*AESEncryptor)
+
+	got, err := keyring.Decrypt(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "rotate me", got, "轮换期间旧密文仍可解密")
+
+	rotated, changed, err := keyring.Reencrypt(legacy)
+	require.NoError(t, err)
+	require.True(t, changed)
+	_, err = oldEnc.Decrypt(rotated)
+	require.Error(t, err, "重新加密后的密文只能由新密钥解密")
+
+	again, changed, err := keyring.Reencrypt(rotated)
+	require.NoError(t, err)
+	assert.False(t, changed, "已使用当前密钥的密文不应重复加密")
+	assert.Equal(t, rotated, again)
+}
+
+func TestAESEncryptor_Reencrypt_UnknownKey(t *testing.T) {
+	other, err := NewAESEncryptor(aesTestCfg(aesHexKey(32, 0xCC)))
+	require.NoError(t, err)
+	ct, err := other.Encrypt("orphan")
+	require.NoError(t, err)
+
+	_, _, err = aesEncryptor(t).Reencrypt(ct)
+	require.Error(t, err)
+}
+
+func TestNewAESEncryptor_InvalidPreviousKey(t *testing.T) {
+	cfg := aesTestCfg(aesHexKey(32, 0x01))
+	cfg.Totp.PreviousEncryptionKeys = []string{aesHexKey(16, 0x02)}
+	_, err := NewAESEncryptor(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "previous encryption key #1")
+}
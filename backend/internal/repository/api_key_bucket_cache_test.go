@@ -0,0 +1,54 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyCache_TakeAPIKeyTokens(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := &apiKeyCache{rdb: rdb}
+	start := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	mr.SetTime(start)
+
+	for i := 0; i < 2; i++ {
+		res, err := cache.TakeAPIKeyTokens(ctx, 5, service.APIKeyBucketRequests, 2, 1, 1)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, int64(1-i), res.Remaining)
+	}
+
+	res, err := cache.TakeAPIKeyTokens(ctx, 5, service.APIKeyBucketRequests, 2, 1, 1)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 30*time.Second, res.RetryAfter)
+	require.Equal(t, time.Minute, res.ResetAfter)
+	require.True(t, mr.Exists(apiKeyBucketKey(5, service.APIKeyBucketRequests)))
+
+	// 30 秒补充一个令牌
+	mr.SetTime(start.Add(30 * time.Second))
+	res, err = cache.TakeAPIKeyTokens(ctx, 5, service.APIKeyBucketRequests, 2, 1, 1)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	// TPM 事后记账允许透支
+	res, err = cache.TakeAPIKeyTokens(ctx, 5, service.APIKeyBucketTokens, 600, 900, 0)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, int64(0), res.Remaining)
+	res, err = cache.TakeAPIKeyTokens(ctx, 5, service.APIKeyBucketTokens, 600, 0, 1)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 30100*time.Millisecond, res.RetryAfter)
+}
@@ -16,6 +16,7 @@ const (
 	apiKeyRateLimitKeyPrefix   = "apikey:ratelimit:"
 	apiKeyRateLimitDuration    = 24 * time.Hour
 	apiKeyAuthCachePrefix      = "apikey:auth:"
+	apiKeyBucketKeyPrefix      = "apikey:bucket:"
 	authCacheInvalidateChannel = "auth:cache:invalidate"
 )
 
@@ -28,6 +29,53 @@ func apiKeyAuthCacheKey(key string) string {
 	return fmt.Sprintf("%s%s", apiKeyAuthCachePrefix, key)
 }
 
+// apiKeyBucketKey generates the Redis key for an API key RPM/TPM token bucket.
+func apiKeyBucketKey(apiKeyID int64, bucket string) string {
+	return fmt.Sprintf("%s%d:%s", apiKeyBucketKeyPrefix, apiKeyID, bucket)
+}
+
+// apiKeyTokenBucketScript 原子地补充并扣除 API Key 令牌桶，与 service.takeAPIKeyBucket 的计算一致。
+// KEYS[1] = apikey:bucket:{apiKeyID}:{rpm|tpm}
+// ARGV[1] = limit（每分钟容量）, ARGV[2] = cost, ARGV[3] = need（<= 0 表示无条件扣除）
+// 返回: {allowed, remaining, retry_after_ms, reset_after_ms}
+var apiKeyTokenBucketScript = redis.NewScript(`
+	-- Redis 3.2-4.x compat: opt into effects replication so redis.call('TIME')
+	-- replicates correctly. No-op on Redis 5.0+ (effects replication is default).
+	redis.replicate_commands()
+	local key = KEYS[1]
+	local limit = tonumber(ARGV[1])
+	local cost = tonumber(ARGV[2])
+	local need = tonumber(ARGV[3])
+	local t = redis.call('TIME')
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local rate = limit / 60000
+
+	local state = redis.call('HMGET', key, 'tokens', 'ts')
+	local tokens = tonumber(state[1])
+	local ts = tonumber(state[2])
+	if tokens == nil or ts == nil then
+		tokens = limit
+		ts = now
+	end
+	if now > ts then
+		tokens = tokens + (now - ts) * rate
+	end
+	tokens = math.min(tokens, limit)
+
+	local allowed = 0
+	local retry = 0
+	if need <= 0 or tokens >= need then
+		allowed = 1
+		tokens = tokens - cost
+	else
+		retry = math.ceil((need - tokens) / rate)
+	end
+	local reset = math.ceil((limit - tokens) / rate)
+	redis.call('HMSET', key, 'tokens', tostring(tokens), 'ts', now)
+	redis.call('PEXPIRE', key, reset + 1000)
+	return {allowed, math.floor(math.max(tokens, 0)), retry, reset}
+`)
+
 type apiKeyCache struct {
 	rdb *redis.Client
 }
@@ -135,3 +183,20 @@ func (c *apiKeyCache) SubscribeAuthCacheInvalidation(ctx context.Context, handle
 
 	return nil
 }
+
+// TakeAPIKeyTokens implements service.APIKeyTokenBucketCache with a Redis-backed token bucket shared by all instances.
+func (c *apiKeyCache) TakeAPIKeyTokens(ctx context.Context, apiKeyID int64, bucket string, limit, cost, need int64) (service.APIKeyBucketResult, error) {
+	vals, err := apiKeyTokenBucketScript.Run(ctx, c.rdb, []string{apiKeyBucketKey(apiKeyID, bucket)}, limit, cost, need).Int64Slice()
+	if err != nil {
+		return service.APIKeyBucketResult{}, err
+	}
+	if len(vals) != 4 {
+		return service.APIKeyBucketResult{}, fmt.Errorf("unexpected api key bucket result length %d", len(vals))
+	}
+	return service.APIKeyBucketResult{
+		Allowed:    vals[0] == 1,
+		Remaining:  vals[1],
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+		ResetAfter: time.Duration(vals[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type apiKeyProjectRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyProjectRepository(db *sql.DB) service.APIKeyProjectRepository {
+	return &apiKeyProjectRepository{db: db}
+}
+
+// apiKeyProjectColumns 以别名 p 引用 api_key_projects，key_count 只统计未删除的成员 Key。
+const apiKeyProjectColumns = `p.id, p.user_id, p.name, p.description, p.quota, p.quota_used,
+	(SELECT COUNT(*) FROM api_keys k WHERE k.project_id = p.id AND k.deleted_at IS NULL),
+	p.created_at, p.updated_at`
+
+func (r *apiKeyProjectRepository) Create(ctx context.Context, project *service.APIKeyProject) (*service.APIKeyProject, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO api_key_projects AS p (user_id, name, description, quota, quota_used, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, NOW(), NOW())
+		RETURNING `+apiKeyProjectColumns,
+		project.UserID, project.Name, project.Description, project.Quota)
+	created, err := scanAPIKeyProject(row)
+	return created, translatePersistenceError(err, nil, service.ErrAPIKeyProjectExists)
+}
+
+func (r *apiKeyProjectRepository) GetByID(ctx context.Context, id int64) (*service.APIKeyProject, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiKeyProjectColumns+` FROM api_key_projects p WHERE p.id = $1`, id)
+	return scanAPIKeyProject(row)
+}
+
+func (r *apiKeyProjectRepository) ListByUserID(ctx context.Context, userID int64) ([]*service.APIKeyProject, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyProjectColumns+`
+		FROM api_key_projects p WHERE p.user_id = $1
+		ORDER BY p.id ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanAPIKeyProjects(rows)
+}
+
+func (r *apiKeyProjectRepository) CountByUserID(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_key_projects WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *apiKeyProjectRepository) Update(ctx context.Context, project *service.APIKeyProject) (*service.APIKeyProject, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE api_key_projects AS p
+		SET name = $2, description = $3, quota = $4, updated_at = NOW()
+		WHERE p.id = $1
+		RETURNING `+apiKeyProjectColumns,
+		project.ID, project.Name, project.Description, project.Quota)
+	updated, err := scanAPIKeyProject(row)
+	return updated, translatePersistenceError(err, nil, service.ErrAPIKeyProjectExists)
+}
+
+func (r *apiKeyProjectRepository) ResetQuotaUsed(ctx context.Context, id int64) (*service.APIKeyProject, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE api_key_projects AS p
+		SET quota_used = 0, updated_at = NOW()
+		WHERE p.id = $1
+		RETURNING `+apiKeyProjectColumns, id)
+	return scanAPIKeyProject(row)
+}
+
+// Delete 删除项目，成员 Key 的 project_id 由外键 ON DELETE SET NULL 置空。
+func (r *apiKeyProjectRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM api_key_projects WHERE id = $1`, id)
+	return err
+}
+
+func (r *apiKeyProjectRepository) ListKeys(ctx context.Context, projectID int64) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT key FROM api_keys WHERE project_id = $1 AND deleted_at IS NULL`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanAPIKeyProjectKeys(rows)
+}
+
+// AddKeys 只有当全部 ID 都是该用户未删除的 Key 时才更新，避免部分成功。
+func (r *apiKeyProjectRepository) AddKeys(ctx context.Context, userID, projectID int64, apiKeyIDs []int64) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH owned AS (
+			SELECT id FROM api_keys
+			WHERE user_id = $1 AND id = ANY($3) AND deleted_at IS NULL
+		)
+		UPDATE api_keys
+		SET project_id = $2, updated_at = NOW()
+		WHERE id IN (SELECT id FROM owned)
+		  AND (SELECT COUNT(*) FROM owned) = $4
+		RETURNING key
+	`, userID, projectID, pq.Array(apiKeyIDs), len(apiKeyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	keys, err := scanAPIKeyProjectKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) != len(apiKeyIDs) {
+		return nil, service.ErrAPIKeyProjectKeyNotOwned
+	}
+	return keys, nil
+}
+
+func (r *apiKeyProjectRepository) RemoveKeys(ctx context.Context, projectID int64, apiKeyIDs []int64) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE api_keys
+		SET project_id = NULL, updated_at = NOW()
+		WHERE project_id = $1 AND id = ANY($2) AND deleted_at IS NULL
+		RETURNING key
+	`, projectID, pq.Array(apiKeyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanAPIKeyProjectKeys(rows)
+}
+
+// SumUsageByKey 以当前成员 Key 为驱动表 LEFT JOIN usage_logs，没有用量的成员也会返回零值行。
+func (r *apiKeyProjectRepository) SumUsageByKey(ctx context.Context, projectID int64, start, end time.Time) ([]*service.APIKeyProjectKeyUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			k.id,
+			k.name,
+			COUNT(ul.id),
+			COALESCE(SUM(ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens), 0),
+			COALESCE(SUM(ul.total_cost), 0),
+			COALESCE(SUM(ul.actual_cost), 0)
+		FROM api_keys k
+		LEFT JOIN usage_logs ul
+			ON ul.api_key_id = k.id AND ul.created_at >= $2 AND ul.created_at < $3
+		WHERE k.project_id = $1 AND k.deleted_at IS NULL
+		GROUP BY k.id, k.name
+		ORDER BY k.id ASC
+	`, projectID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []*service.APIKeyProjectKeyUsage
+	for rows.Next() {
+		u := &service.APIKeyProjectKeyUsage{}
+		if err := rows.Scan(&u.APIKeyID, &u.Name, &u.Requests, &u.Tokens, &u.TotalCost, &u.ActualCost); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func scanAPIKeyProject(row scannable) (*service.APIKeyProject, error) {
+	p := &service.APIKeyProject{}
+	if err := row.Scan(
+		&p.ID, &p.UserID, &p.Name, &p.Description, &p.Quota, &p.QuotaUsed, &p.KeyCount, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrAPIKeyProjectNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func scanAPIKeyProjects(rows *sql.Rows) ([]*service.APIKeyProject, error) {
+	var projects []*service.APIKeyProject
+	for rows.Next() {
+		p, err := scanAPIKeyProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+func scanAPIKeyProjectKeys(rows *sql.Rows) ([]string, error) {
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
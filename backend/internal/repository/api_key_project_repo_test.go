@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyProjectRepositoryAddKeys_RejectsKeysNotOwned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAPIKeyProjectRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta("AND (SELECT COUNT(*) FROM owned) = $4")).
+		WithArgs(int64(7), int64(3), pq.Array([]int64{11, 12}), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+	_, err = repo.AddKeys(context.Background(), 7, 3, []int64{11, 12})
+
+	require.ErrorIs(t, err, service.ErrAPIKeyProjectKeyNotOwned)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyProjectRepositorySumUsageByKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAPIKeyProjectRepository(db)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE k.project_id = $1 AND k.deleted_at IS NULL")).
+		WithArgs(int64(3), start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "requests", "tokens", "total_cost", "actual_cost"}).
+			AddRow(int64(11), "web", int64(4), int64(1200), 0.5, 0.6).
+			AddRow(int64(12), "worker", int64(0), int64(0), 0.0, 0.0))
+
+	usage, err := repo.SumUsageByKey(context.Background(), 3, start, end)
+
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	require.Equal(t, "web", usage[0].Name)
+	require.Equal(t, int64(1200), usage[0].Tokens)
+	require.Equal(t, 0.6, usage[0].ActualCost)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -53,8 +53,18 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 		SetNillableExpiresAt(key.ExpiresAt).
 		SetRateLimit5h(key.RateLimit5h).
 		SetRateLimit1d(key.RateLimit1d).
-		SetRateLimit7d(key.RateLimit7d)
+		SetRateLimit7d(key.RateLimit7d).
+		SetRpmLimit(key.RPMLimit).
+		SetTpmLimit(key.TPMLimit).
+		SetCritical(key.Critical).
+		SetGuest(key.Guest).
+		SetTokenLimit(key.TokenLimit).
+		SetTokensUsed(key.TokensUsed).
+		SetNillableProjectID(key.ProjectID)
 
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	}
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
 	}
@@ -142,6 +152,14 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldRateLimit5h,
 			apikey.FieldRateLimit1d,
 			apikey.FieldRateLimit7d,
+			apikey.FieldRpmLimit,
+			apikey.FieldTpmLimit,
+			apikey.FieldCritical,
+			apikey.FieldGuest,
+			apikey.FieldAllowedModels,
+			apikey.FieldTokenLimit,
+			apikey.FieldTokensUsed,
+			apikey.FieldProjectID,
 		).
 		WithUser(func(q *dbent.UserQuery) {
 			q.Select(
@@ -206,6 +224,20 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldPeakStart,
 				group.FieldPeakEnd,
 				group.FieldPeakRateMultiplier,
+				group.FieldRequestSurcharge,
+				group.FieldReservedConcurrencyRatio,
+				group.FieldOverloadFallbackModels,
+				group.FieldContextOverflowModels,
+				group.FieldContextOverflowReject,
+				group.FieldContextTruncationStrategy,
+				group.FieldAccountSelectionStrategy,
+				group.FieldImageMaxEdge,
+				group.FieldImageMaxBytes,
+				group.FieldOutputPostprocess,
+				group.FieldLanguageRouting,
+				group.FieldStreamReplay,
+				group.FieldCapabilityCheck,
+				group.FieldAutoContinuation,
 			)
 		}).
 		Only(ctx)
@@ -238,6 +270,10 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 		SetUsage5h(key.Usage5h).
 		SetUsage1d(key.Usage1d).
 		SetUsage7d(key.Usage7d).
+		SetRpmLimit(key.RPMLimit).
+		SetTpmLimit(key.TPMLimit).
+		SetCritical(key.Critical).
+		SetTokenLimit(key.TokenLimit).
 		SetUpdatedAt(now)
 	if key.GroupID != nil {
 		builder.SetGroupID(*key.GroupID)
@@ -269,6 +305,13 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 		builder.ClearWindow7dStart()
 	}
 
+	// 模型白名单
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	} else {
+		builder.ClearAllowedModels()
+	}
+
 	// IP 限制字段
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
@@ -395,6 +438,48 @@ func (r *apiKeyRepository) deleteWithAudit(ctx context.Context, exec *dbent.Clie
 	return nil
 }
 
+// DeleteExpiredGuestKeys 批量软删除已过期的访客 Key，与 DeleteWithAudit 一样先写删除审计再覆盖 tombstone key。
+// SKIP LOCKED 让多实例并发清理时互不阻塞；返回被删除 Key 的原始值用于失效认证缓存。
+func (r *apiKeyRepository) DeleteExpiredGuestKeys(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	rows, err := r.sql.QueryContext(ctx, `
+		WITH expired AS (
+			SELECT id, key, user_id, name
+			FROM api_keys
+			WHERE guest = true AND expires_at IS NOT NULL AND expires_at <= $1 AND deleted_at IS NULL
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), audited AS (
+			INSERT INTO deleted_api_key_audits (key, api_key_id, user_id, key_name, deleted_at)
+			SELECT key, id, user_id, name, NOW()
+			FROM expired
+		)
+		UPDATE api_keys a
+		SET key = '__deleted__' || a.id || '__' || (EXTRACT(EPOCH FROM clock_timestamp()) * 1000000000)::bigint,
+			deleted_at = NOW(),
+			updated_at = NOW()
+		FROM expired e
+		WHERE a.id = e.id
+		RETURNING e.key`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
 func (r *apiKeyRepository) apiKeyListByUserIDQuery(userID int64, filters service.APIKeyListFilters) *dbent.APIKeyQuery {
 	q := r.activeQuery().Where(apikey.UserIDEQ(userID))
 
@@ -857,6 +942,14 @@ func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 		Window5hStart: m.Window5hStart,
 		Window1dStart: m.Window1dStart,
 		Window7dStart: m.Window7dStart,
+		RPMLimit:      m.RpmLimit,
+		TPMLimit:      m.TpmLimit,
+		Critical:      m.Critical,
+		Guest:         m.Guest,
+		AllowedModels: m.AllowedModels,
+		TokenLimit:    m.TokenLimit,
+		TokensUsed:    m.TokensUsed,
+		ProjectID:     m.ProjectID,
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -963,6 +1056,20 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		PeakStart:                       g.PeakStart,
 		PeakEnd:                         g.PeakEnd,
 		PeakRateMultiplier:              g.PeakRateMultiplier,
+		RequestSurcharge:                g.RequestSurcharge,
+		ReservedConcurrencyRatio:        g.ReservedConcurrencyRatio,
+		OverloadFallbackModels:          g.OverloadFallbackModels,
+		ContextOverflowModels:           g.ContextOverflowModels,
+		ContextOverflowReject:           g.ContextOverflowReject,
+		ContextTruncationStrategy:       g.ContextTruncationStrategy,
+		AccountSelectionStrategy:        g.AccountSelectionStrategy,
+		ImageMaxEdge:                    g.ImageMaxEdge,
+		ImageMaxBytes:                   g.ImageMaxBytes,
+		OutputPostprocess:               g.OutputPostprocess,
+		LanguageRouting:                 g.LanguageRouting,
+		StreamReplay:                    g.StreamReplay,
+		CapabilityCheck:                 g.CapabilityCheck,
+		AutoContinuation:                g.AutoContinuation,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepositoryDeleteExpiredGuestKeys_AuditsAndReturnsOriginalKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := newAPIKeyRepositoryWithSQL(nil, db)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`(?s)WHERE guest = true AND expires_at IS NOT NULL AND expires_at <= \$1 AND deleted_at IS NULL.*FOR UPDATE SKIP LOCKED.*INSERT INTO deleted_api_key_audits.*UPDATE api_keys a.*RETURNING e\.key`).
+		WithArgs(now, 200).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("sk-guest-1").AddRow("sk-guest-2"))
+
+	keys, err := repo.DeleteExpiredGuestKeys(context.Background(), now, 200)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"sk-guest-1", "sk-guest-2"}, keys)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
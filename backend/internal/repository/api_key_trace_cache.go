@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	apiKeyTraceSessionKeyPrefix = "apikey:trace:session:"
+	apiKeyTraceRecordsKeyPrefix = "apikey:trace:records:"
+	// apiKeyTraceActiveKey 进行中会话索引（ZSET，score 为到期时间 unix 秒）
+	apiKeyTraceActiveKey = "apikey:trace:active"
+)
+
+func apiKeyTraceSessionKey(apiKeyID int64) string {
+	return fmt.Sprintf("%s%d", apiKeyTraceSessionKeyPrefix, apiKeyID)
+}
+
+func apiKeyTraceRecordsKey(apiKeyID int64) string {
+	return fmt.Sprintf("%s%d", apiKeyTraceRecordsKeyPrefix, apiKeyID)
+}
+
+type apiKeyTraceCache struct {
+	rdb *redis.Client
+}
+
+func NewAPIKeyTraceCache(rdb *redis.Client) service.APIKeyTraceCache {
+	return &apiKeyTraceCache{rdb: rdb}
+}
+
+func (c *apiKeyTraceCache) GetTraceSession(ctx context.Context, apiKeyID int64) (*service.APIKeyTraceSession, error) {
+	val, err := c.rdb.Get(ctx, apiKeyTraceSessionKey(apiKeyID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session service.APIKeyTraceSession
+	if err := json.Unmarshal(val, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (c *apiKeyTraceCache) SetTraceSession(ctx context.Context, session *service.APIKeyTraceSession) error {
+	if session == nil {
+		return nil
+	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return c.DeleteTraceSession(ctx, session.APIKeyID)
+	}
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, apiKeyTraceSessionKey(session.APIKeyID), payload, ttl)
+	pipe.ZAdd(ctx, apiKeyTraceActiveKey, redis.Z{
+		Score:  float64(session.ExpiresAt.Unix()),
+		Member: strconv.FormatInt(session.APIKeyID, 10),
+	})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *apiKeyTraceCache) DeleteTraceSession(ctx context.Context, apiKeyID int64) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.Del(ctx, apiKeyTraceSessionKey(apiKeyID))
+	pipe.ZRem(ctx, apiKeyTraceActiveKey, strconv.FormatInt(apiKeyID, 10))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *apiKeyTraceCache) ListTraceSessions(ctx context.Context) ([]*service.APIKeyTraceSession, error) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := c.rdb.ZRemRangeByScore(ctx, apiKeyTraceActiveKey, "-inf", "("+now).Err(); err != nil {
+		return nil, err
+	}
+	members, err := c.rdb.ZRange(ctx, apiKeyTraceActiveKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return []*service.APIKeyTraceSession{}, nil
+	}
+
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		keys = append(keys, apiKeyTraceSessionKeyPrefix+member)
+	}
+	values, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*service.APIKeyTraceSession, 0, len(values))
+	for _, raw := range values {
+		payload, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		var session service.APIKeyTraceSession
+		if err := json.Unmarshal([]byte(payload), &session); err != nil {
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+	return sessions, nil
+}
+
+func (c *apiKeyTraceCache) AppendTraceRecord(ctx context.Context, record *service.APIKeyTraceRecord, maxRecords int, ttl time.Duration) error {
+	if record == nil {
+		return nil
+	}
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	key := apiKeyTraceRecordsKey(record.APIKeyID)
+	pipe := c.rdb.TxPipeline()
+	pipe.LPush(ctx, key, payload)
+	if maxRecords > 0 {
+		pipe.LTrim(ctx, key, 0, int64(maxRecords-1))
+	}
+	if ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *apiKeyTraceCache) ListTraceRecords(ctx context.Context, apiKeyID int64, limit int) ([]*service.APIKeyTraceRecord, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit - 1)
+	}
+	values, err := c.rdb.LRange(ctx, apiKeyTraceRecordsKey(apiKeyID), 0, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*service.APIKeyTraceRecord, 0, len(values))
+	for _, raw := range values {
+		var record service.APIKeyTraceRecord
+		if err := json.Unmarshal([]byte(raw), &record); err != nil {
+			continue
+		}
+		records = append(records, &record)
+	}
+	return records, nil
+}
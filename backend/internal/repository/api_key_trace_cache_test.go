@@ -0,0 +1,80 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newAPIKeyTraceCacheTest(t *testing.T) (*apiKeyTraceCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = rdb.Close()
+	})
+	return &apiKeyTraceCache{rdb: rdb}, mr
+}
+
+func TestAPIKeyTraceCache_SessionLifecycle(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newAPIKeyTraceCacheTest(t)
+
+	session := &service.APIKeyTraceSession{APIKeyID: 9, Reason: "ticket", StartedAt: time.Now(), ExpiresAt: time.Now().Add(30 * time.Minute)}
+	require.NoError(t, cache.SetTraceSession(ctx, session))
+	require.InDelta(t, (30 * time.Minute).Seconds(), mr.TTL(apiKeyTraceSessionKey(9)).Seconds(), 2)
+
+	got, err := cache.GetTraceSession(ctx, 9)
+	require.NoError(t, err)
+	require.Equal(t, "ticket", got.Reason)
+
+	sessions, err := cache.ListTraceSessions(ctx)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	require.NoError(t, cache.DeleteTraceSession(ctx, 9))
+	got, err = cache.GetTraceSession(ctx, 9)
+	require.NoError(t, err)
+	require.Nil(t, got)
+	sessions, err = cache.ListTraceSessions(ctx)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestAPIKeyTraceCache_ListPrunesExpiredIndex(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newAPIKeyTraceCacheTest(t)
+
+	require.NoError(t, cache.rdb.ZAdd(ctx, apiKeyTraceActiveKey, redis.Z{
+		Score:  float64(time.Now().Add(-time.Minute).Unix()),
+		Member: "5",
+	}).Err())
+
+	sessions, err := cache.ListTraceSessions(ctx)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+	require.Zero(t, cache.rdb.ZCard(ctx, apiKeyTraceActiveKey).Val())
+}
+
+func TestAPIKeyTraceCache_RecordsAreCappedNewestFirst(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newAPIKeyTraceCacheTest(t)
+
+	for _, id := range []string{"r1", "r2", "r3"} {
+		require.NoError(t, cache.AppendTraceRecord(ctx, &service.APIKeyTraceRecord{APIKeyID: 3, RequestID: id}, 2, time.Hour))
+	}
+
+	records, err := cache.ListTraceRecords(ctx, 3, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "r3", records[0].RequestID)
+	require.Equal(t, "r2", records[1].RequestID)
+	require.Greater(t, mr.TTL(apiKeyTraceRecordsKey(3)), time.Duration(0))
+}
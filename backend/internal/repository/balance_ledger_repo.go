@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type balanceLedgerRepository struct {
+	db *sql.DB
+}
+
+func NewBalanceLedgerRepository(db *sql.DB) service.BalanceLedgerRepository {
+	return &balanceLedgerRepository{db: db}
+}
+
+// ListDailyFlows 按天汇总入账（已使用的余额类兑换码，含充值订单与管理员调整）与余额计费扣费。
+// 两侧分别先按天聚合再合并，usage_logs 侧走 (user_id, created_at) 索引。
+func (r *balanceLedgerRepository) ListDailyFlows(ctx context.Context, userID int64, since time.Time, tz string) ([]service.BalanceDailyFlow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT date, COALESCE(SUM(credits), 0), COALESCE(SUM(debits), 0)
+		FROM (
+			SELECT TO_CHAR(used_at AT TIME ZONE $3, 'YYYY-MM-DD') AS date, SUM(value) AS credits, 0 AS debits
+			FROM redeem_codes
+			WHERE used_by = $1 AND used_at >= $2 AND type IN ($4, $5)
+			GROUP BY 1
+			UNION ALL
+			SELECT TO_CHAR(created_at AT TIME ZONE $3, 'YYYY-MM-DD') AS date, 0 AS credits, SUM(actual_cost) AS debits
+			FROM usage_logs
+			WHERE user_id = $1 AND created_at >= $2 AND billing_type = $6
+			GROUP BY 1
+		) flows
+		GROUP BY date
+		ORDER BY date DESC
+	`, userID, since, tz, service.RedeemTypeBalance, service.AdjustmentTypeAdminBalance, service.BillingTypeBalance)
+	if err != nil {
+		return nil, fmt.Errorf("list balance daily flows: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	flows := make([]service.BalanceDailyFlow, 0)
+	for rows.Next() {
+		var f service.BalanceDailyFlow
+		if err := rows.Scan(&f.Date, &f.Credits, &f.Debits); err != nil {
+			return nil, err
+		}
+		flows = append(flows, f)
+	}
+	return flows, rows.Err()
+}
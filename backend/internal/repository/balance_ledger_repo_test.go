@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceLedgerRepositoryListDailyFlows_MergesCreditsAndDebits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewBalanceLedgerRepository(db)
+	since := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM redeem_codes")).
+		WithArgs(int64(7), since, "Asia/Shanghai", service.RedeemTypeBalance, service.AdjustmentTypeAdminBalance, service.BillingTypeBalance).
+		WillReturnRows(sqlmock.NewRows([]string{"date", "credits", "debits"}).
+			AddRow("2026-10-14", 0.0, 1.5).
+			AddRow("2026-10-01", 20.0, 0.25))
+
+	flows, err := repo.ListDailyFlows(context.Background(), 7, since, "Asia/Shanghai")
+
+	require.NoError(t, err)
+	require.Equal(t, []service.BalanceDailyFlow{
+		{Date: "2026-10-14", Debits: 1.5},
+		{Date: "2026-10-01", Credits: 20, Debits: 0.25},
+	}, flows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
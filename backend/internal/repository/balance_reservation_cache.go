@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	balanceHoldKeyPrefix       = "billing:hold:"
+	balanceHoldExpiryKeyPrefix = "billing:hold_exp:"
+)
+
+// balanceHoldKey HASH: reservationID -> 冻结金额
+func balanceHoldKey(userID int64) string {
+	return fmt.Sprintf("%s%d", balanceHoldKeyPrefix, userID)
+}
+
+// balanceHoldExpiryKey ZSET: reservationID -> 过期时间（unix 毫秒），用于清理未释放的冻结
+func balanceHoldExpiryKey(userID int64) string {
+	return fmt.Sprintf("%s%d", balanceHoldExpiryKeyPrefix, userID)
+}
+
+var (
+	// reserveBalanceScript 原子地清理过期冻结、计算可用余额并写入新冻结。
+	// KEYS: [1]=余额缓存 [2]=冻结 HASH [3]=过期 ZSET
+	// ARGV: [1]=reservationID [2]=amount [3]=余额缓存缺失时的余额 [4]=floor [5]=now_ms [6]=expire_at_ms [7]=key_ttl_seconds
+	reserveBalanceScript = redis.NewScript(`
+		local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[5])
+		for _, id in ipairs(expired) do
+			redis.call('HDEL', KEYS[2], id)
+		end
+		if #expired > 0 then
+			redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', ARGV[5])
+		end
+
+		local balance = tonumber(redis.call('GET', KEYS[1]) or ARGV[3])
+		local reserved = 0
+		for _, v in ipairs(redis.call('HVALS', KEYS[2])) do
+			reserved = reserved + tonumber(v)
+		end
+		local available = balance - reserved
+		local amount = tonumber(ARGV[2])
+		if available - amount < tonumber(ARGV[4]) then
+			return {0, tostring(available)}
+		end
+
+		redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+		redis.call('ZADD', KEYS[3], ARGV[6], ARGV[1])
+		redis.call('EXPIRE', KEYS[2], ARGV[7])
+		redis.call('EXPIRE', KEYS[3], ARGV[7])
+		return {1, tostring(available - amount)}
+	`)
+
+	releaseBalanceScript = redis.NewScript(`
+		redis.call('HDEL', KEYS[1], ARGV[1])
+		redis.call('ZREM', KEYS[2], ARGV[1])
+		return 1
+	`)
+
+	// reservedBalanceScript 汇总未过期的冻结。ARGV: [1]=now_ms
+	reservedBalanceScript = redis.NewScript(`
+		local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '(' .. ARGV[1], '+inf')
+		local reserved = 0
+		for _, id in ipairs(ids) do
+			local v = redis.call('HGET', KEYS[1], id)
+			if v then
+				reserved = reserved + tonumber(v)
+			end
+		end
+		return tostring(reserved)
+	`)
+)
+
+type balanceReservationCache struct {
+	rdb *redis.Client
+}
+
+func NewBalanceReservationCache(rdb *redis.Client) service.BalanceReservationCache {
+	return &balanceReservationCache{rdb: rdb}
+}
+
+func (c *balanceReservationCache) Reserve(ctx context.Context, userID int64, reservationID string, amount, fallbackBalance, floor float64, ttl time.Duration) (bool, float64, error) {
+	now := time.Now()
+	// 冻结 key 的 TTL 比单条冻结多留一分钟，确保 ZSET 能覆盖到所有未过期的冻结
+	keyTTL := int((ttl + time.Minute).Seconds())
+	res, err := reserveBalanceScript.Run(ctx, c.rdb,
+		[]string{billingBalanceKey(userID), balanceHoldKey(userID), balanceHoldExpiryKey(userID)},
+		reservationID, amount, fallbackBalance, floor, now.UnixMilli(), now.Add(ttl).UnixMilli(), keyTTL,
+	).Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected reserve balance result: %v", res)
+	}
+	ok, _ := res[0].(int64)
+	availableRaw, _ := res[1].(string)
+	available, err := strconv.ParseFloat(availableRaw, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("parse available balance: %w", err)
+	}
+	return ok == 1, available, nil
+}
+
+func (c *balanceReservationCache) Release(ctx context.Context, userID int64, reservationID string) error {
+	return releaseBalanceScript.Run(ctx, c.rdb, []string{balanceHoldKey(userID), balanceHoldExpiryKey(userID)}, reservationID).Err()
+}
+
+func (c *balanceReservationCache) Reserved(ctx context.Context, userID int64) (float64, error) {
+	raw, err := reservedBalanceScript.Run(ctx, c.rdb,
+		[]string{balanceHoldKey(userID), balanceHoldExpiryKey(userID)}, time.Now().UnixMilli(),
+	).Text()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(raw, 64)
+}
@@ -0,0 +1,73 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newBalanceReservationCacheTest(t *testing.T) (*balanceReservationCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = rdb.Close()
+	})
+	return &balanceReservationCache{rdb: rdb}, mr
+}
+
+func TestBalanceReservationCache_ReserveRespectsInFlightHolds(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newBalanceReservationCacheTest(t)
+	mr.Set(billingBalanceKey(7), "1")
+
+	ok, available, err := cache.Reserve(ctx, 7, "a", 0.6, 0, 0, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.InDelta(t, 0.4, available, 1e-9)
+
+	// 第二个并发请求无法透支
+	ok, available, err = cache.Reserve(ctx, 7, "b", 0.6, 0, 0, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.InDelta(t, 0.4, available, 1e-9)
+
+	reserved, err := cache.Reserved(ctx, 7)
+	require.NoError(t, err)
+	require.InDelta(t, 0.6, reserved, 1e-9)
+
+	require.NoError(t, cache.Release(ctx, 7, "a"))
+	ok, _, err = cache.Reserve(ctx, 7, "b", 0.6, 0, 0, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestBalanceReservationCache_FallbackBalanceAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newBalanceReservationCacheTest(t)
+
+	// 余额缓存缺失时使用调用方传入的余额；floor 生效
+	ok, _, err := cache.Reserve(ctx, 8, "a", 0.5, 1, 0.6, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, _, err = cache.Reserve(ctx, 8, "short", 0.9, 1, 0, time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ok)
+	time.Sleep(5 * time.Millisecond)
+
+	// 过期的冻结在下一次 Reserve 时被清理
+	reserved, err := cache.Reserved(ctx, 8)
+	require.NoError(t, err)
+	require.Zero(t, reserved)
+	ok, available, err := cache.Reserve(ctx, 8, "b", 0.9, 1, 0, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.InDelta(t, 0.1, available, 1e-9)
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type billingStatementRepository struct {
+	db *sql.DB
+}
+
+func NewBillingStatementRepository(db *sql.DB) service.BillingStatementRepository {
+	return &billingStatementRepository{db: db}
+}
+
+const billingStatementColumns = `id, user_id, period_start, period_end, total_requests, total_tokens, total_cost, actual_cost,
+	line_items, status, stripe_invoice_id, stripe_invoice_url, stripe_error, created_at, updated_at`
+
+// GenerateForPeriod 先按 (user, model) 聚合再按用户汇总，明细按实际扣费降序写入 line_items。
+// 走 usage_logs 的 created_at 分区/索引；唯一索引冲突时跳过，保证幂等。
+func (r *billingStatementRepository) GenerateForPeriod(ctx context.Context, start, end time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		WITH per_model AS (
+			SELECT
+				user_id,
+				model,
+				COUNT(*)                              AS requests,
+				COALESCE(SUM(input_tokens), 0)          AS input_tokens,
+				COALESCE(SUM(output_tokens), 0)         AS output_tokens,
+				COALESCE(SUM(cache_creation_tokens), 0) AS cache_creation_tokens,
+				COALESCE(SUM(cache_read_tokens), 0)     AS cache_read_tokens,
+				COALESCE(SUM(total_cost), 0)            AS total_cost,
+				COALESCE(SUM(actual_cost), 0)           AS actual_cost
+			FROM usage_logs
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY user_id, model
+		)
+		INSERT INTO billing_statements (
+			user_id, period_start, period_end, total_requests, total_tokens, total_cost, actual_cost, line_items
+		)
+		SELECT
+			user_id, $1, $2,
+			SUM(requests),
+			SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens),
+			SUM(total_cost),
+			SUM(actual_cost),
+			jsonb_agg(jsonb_build_object(
+				'model', model,
+				'requests', requests,
+				'input_tokens', input_tokens,
+				'output_tokens', output_tokens,
+				'cache_creation_tokens', cache_creation_tokens,
+				'cache_read_tokens', cache_read_tokens,
+				'total_cost', total_cost,
+				'actual_cost', actual_cost
+			) ORDER BY actual_cost DESC, model ASC)
+		FROM per_model
+		GROUP BY user_id
+		ON CONFLICT (user_id, period_start) DO NOTHING
+	`, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("generate billing statements: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func (r *billingStatementRepository) GetByID(ctx context.Context, id int64) (*service.BillingStatement, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+billingStatementColumns+` FROM billing_statements WHERE id = $1`, id)
+	return scanBillingStatement(row)
+}
+
+func (r *billingStatementRepository) List(ctx context.Context, params pagination.PaginationParams, filter service.BillingStatementFilter) ([]*service.BillingStatement, *pagination.PaginationResult, error) {
+	where := []string{"1=1"}
+	args := []any{}
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		where = append(where, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.PeriodStart != nil {
+		args = append(args, *filter.PeriodStart)
+		where = append(where, fmt.Sprintf("period_start = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where = append(where, fmt.Sprintf("status = $%d", len(args)))
+	}
+	whereSQL := "WHERE " + strings.Join(where, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM billing_statements "+whereSQL, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count billing statements: %w", err)
+	}
+
+	queryArgs := append([]any{}, args...)
+	queryArgs = append(queryArgs, params.Limit(), params.Offset())
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+billingStatementColumns+`
+		FROM billing_statements `+whereSQL+`
+		ORDER BY period_start DESC, id DESC
+		LIMIT $`+fmt.Sprint(len(queryArgs)-1)+` OFFSET $`+fmt.Sprint(len(queryArgs)),
+		queryArgs...,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list billing statements: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	items, err := scanBillingStatements(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, paginationResultFromTotal(total, params), nil
+}
+
+func (r *billingStatementRepository) ListPendingPush(ctx context.Context, minAmount float64, limit int) ([]*service.BillingStatement, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+billingStatementColumns+`
+		FROM billing_statements
+		WHERE status = $1 AND actual_cost >= $2
+		ORDER BY updated_at ASC, id ASC
+		LIMIT $3
+	`, service.BillingStatementStatusIssued, minAmount, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanBillingStatements(rows)
+}
+
+func (r *billingStatementRepository) UpdateStripeResult(ctx context.Context, id int64, status, invoiceID, invoiceURL, stripeErr string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE billing_statements
+		SET status = $2, stripe_invoice_id = $3, stripe_invoice_url = $4, stripe_error = $5, updated_at = NOW()
+		WHERE id = $1
+	`, id, status, invoiceID, invoiceURL, stripeErr)
+	return err
+}
+
+func (r *billingStatementRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE billing_statements SET status = $2, updated_at = NOW() WHERE id = $1`, id, status)
+	return err
+}
+
+func (r *billingStatementRepository) GetStripeCustomerID(ctx context.Context, userID int64) (string, error) {
+	var customerID string
+	err := r.db.QueryRowContext(ctx, `SELECT customer_id FROM billing_stripe_customers WHERE user_id = $1`, userID).Scan(&customerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return customerID, err
+}
+
+func (r *billingStatementRepository) SaveStripeCustomerID(ctx context.Context, userID int64, customerID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO billing_stripe_customers (user_id, customer_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET customer_id = EXCLUDED.customer_id
+	`, userID, customerID)
+	return err
+}
+
+func scanBillingStatement(row scannable) (*service.BillingStatement, error) {
+	st := &service.BillingStatement{}
+	var lineItemsRaw []byte
+	if err := row.Scan(
+		&st.ID, &st.UserID, &st.PeriodStart, &st.PeriodEnd, &st.TotalRequests, &st.TotalTokens, &st.TotalCost, &st.ActualCost,
+		&lineItemsRaw, &st.Status, &st.StripeInvoiceID, &st.StripeInvoiceURL, &st.StripeError, &st.CreatedAt, &st.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrBillingStatementNotFound
+		}
+		return nil, err
+	}
+	st.LineItems = []service.BillingStatementLineItem{}
+	if len(lineItemsRaw) > 0 {
+		if err := json.Unmarshal(lineItemsRaw, &st.LineItems); err != nil {
+			return nil, fmt.Errorf("decode billing statement line items: %w", err)
+		}
+	}
+	return st, nil
+}
+
+func scanBillingStatements(rows *sql.Rows) ([]*service.BillingStatement, error) {
+	statements := make([]*service.BillingStatement, 0)
+	for rows.Next() {
+		st, err := scanBillingStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, st)
+	}
+	return statements, rows.Err()
+}
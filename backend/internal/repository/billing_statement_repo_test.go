@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+var billingStatementTestColumns = []string{
+	"id", "user_id", "period_start", "period_end", "total_requests", "total_tokens", "total_cost", "actual_cost",
+	"line_items", "status", "stripe_invoice_id", "stripe_invoice_url", "stripe_error", "created_at", "updated_at",
+}
+
+func TestBillingStatementRepositoryGenerateForPeriod_IsIdempotent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewBillingStatementRepository(db)
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (user_id, period_start) DO NOTHING")).
+		WithArgs(start, end).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	created, err := repo.GenerateForPeriod(context.Background(), start, end)
+
+	require.NoError(t, err)
+	require.Equal(t, 3, created)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBillingStatementRepositoryList_FiltersAndDecodesLineItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewBillingStatementRepository(db)
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM billing_statements WHERE 1=1 AND user_id = $1 AND status = $2")).
+		WithArgs(int64(7), service.BillingStatementStatusIssued).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("LIMIT $3 OFFSET $4")).
+		WithArgs(int64(7), service.BillingStatementStatusIssued, 20, 0).
+		WillReturnRows(sqlmock.NewRows(billingStatementTestColumns).AddRow(
+			1, 7, start, start.AddDate(0, 1, 0), 10, 5000, 2.5, 2.0,
+			[]byte(`[{"model":"claude-sonnet-4","requests":10,"actual_cost":2.0}]`),
+			service.BillingStatementStatusIssued, "", "", "", now, now,
+		))
+
+	items, page, err := repo.List(context.Background(), pagination.PaginationParams{Page: 1, PageSize: 20},
+		service.BillingStatementFilter{UserID: 7, Status: service.BillingStatementStatusIssued})
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), page.Total)
+	require.Len(t, items, 1)
+	require.Equal(t, []service.BillingStatementLineItem{{Model: "claude-sonnet-4", Requests: 10, ActualCost: 2.0}}, items[0].LineItems)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBillingStatementRepositoryGetStripeCustomerID_MissingReturnsEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewBillingStatementRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM billing_stripe_customers WHERE user_id = $1")).
+		WithArgs(int64(7)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM billing_statements WHERE id = $1")).
+		WithArgs(int64(9)).
+		WillReturnError(sql.ErrNoRows)
+
+	customerID, err := repo.GetStripeCustomerID(context.Background(), 7)
+	require.NoError(t, err)
+	require.Empty(t, customerID)
+
+	_, err = repo.GetByID(context.Background(), 9)
+	require.ErrorIs(t, err, service.ErrBillingStatementNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
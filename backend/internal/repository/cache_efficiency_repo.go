@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type cacheEfficiencyRepository struct {
+	db *sql.DB
+}
+
+// NewCacheEfficiencyRepository 创建缓存命中率报表仓储（直接聚合 usage_logs）。
+func NewCacheEfficiencyRepository(db *sql.DB) service.CacheEfficiencyRepository {
+	return &cacheEfficiencyRepository{db: db}
+}
+
+// cacheEfficiencyDimensionSQL 维度白名单：ID 表达式、名称表达式、JOIN 与 GROUP BY。
+var cacheEfficiencyDimensionSQL = map[string]struct {
+	id, name, join, groupBy string
+}{
+	service.CacheEfficiencyDimensionModel:   {"0::bigint", "ul.model", "", "ul.model"},
+	service.CacheEfficiencyDimensionAPIKey:  {"ul.api_key_id", "COALESCE(MAX(k.name), '')", "LEFT JOIN api_keys k ON k.id = ul.api_key_id", "ul.api_key_id"},
+	service.CacheEfficiencyDimensionAccount: {"ul.account_id", "COALESCE(MAX(a.name), '')", "LEFT JOIN accounts a ON a.id = ul.account_id", "ul.account_id"},
+}
+
+func (r *cacheEfficiencyRepository) AggregateCacheUsage(ctx context.Context, dimension string, start, end time.Time, limit int) ([]service.CacheEfficiencyRow, error) {
+	dim, ok := cacheEfficiencyDimensionSQL[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unsupported cache efficiency dimension: %s", dimension)
+	}
+	query := `
+		SELECT
+			` + dim.id + ` AS id,
+			` + dim.name + ` AS name,
+			COUNT(*) AS requests,
+			COALESCE(SUM(ul.input_tokens), 0) AS input_tokens,
+			COALESCE(SUM(ul.cache_creation_tokens), 0) AS cache_creation_tokens,
+			COALESCE(SUM(ul.cache_read_tokens), 0) AS cache_read_tokens
+		FROM usage_logs ul
+		` + dim.join + `
+		WHERE ul.created_at >= $1 AND ul.created_at < $2
+		GROUP BY ` + dim.groupBy + `
+		ORDER BY SUM(ul.input_tokens + ul.cache_creation_tokens + ul.cache_read_tokens) DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, start.UTC(), end.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate cache usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.CacheEfficiencyRow, 0)
+	for rows.Next() {
+		var row service.CacheEfficiencyRow
+		if err := rows.Scan(&row.ID, &row.Name, &row.Requests, &row.InputTokens, &row.CacheCreationTokens, &row.CacheReadTokens); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEfficiencyRepositoryAggregateCacheUsage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewCacheEfficiencyRepository(db)
+	end := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+	mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN accounts a ON a.id = ul.account_id")).
+		WithArgs(start, end, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "requests", "input_tokens", "cache_creation_tokens", "cache_read_tokens"}).
+			AddRow(int64(3), "acc-3", int64(40), int64(1000), int64(200), int64(800)))
+
+	rows, err := repo.AggregateCacheUsage(context.Background(), service.CacheEfficiencyDimensionAccount, start, end, 10)
+
+	require.NoError(t, err)
+	require.Equal(t, []service.CacheEfficiencyRow{{ID: 3, Name: "acc-3", Requests: 40, InputTokens: 1000, CacheCreationTokens: 200, CacheReadTokens: 800}}, rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = repo.AggregateCacheUsage(context.Background(), "user; DROP TABLE", start, end, 10)
+	require.Error(t, err)
+}
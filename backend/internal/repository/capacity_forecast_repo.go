@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type capacityForecastRepository struct {
+	db *sql.DB
+}
+
+// NewCapacityForecastRepository 创建容量预测仓储（读取 usage_dashboard_daily 预聚合表）。
+func NewCapacityForecastRepository(db *sql.DB) service.CapacityForecastRepository {
+	return &capacityForecastRepository{db: db}
+}
+
+func (r *capacityForecastRepository) ListDailyUsage(ctx context.Context, start, end time.Time) ([]service.CapacityUsageDay, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			bucket_date,
+			input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens AS tokens,
+			account_cost
+		FROM usage_dashboard_daily
+		WHERE bucket_date >= $1::date AND bucket_date < $2::date
+		ORDER BY bucket_date ASC
+	`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list capacity daily usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	days := make([]service.CapacityUsageDay, 0)
+	for rows.Next() {
+		var d service.CapacityUsageDay
+		if err := rows.Scan(&d.Date, &d.Tokens, &d.AccountCost); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
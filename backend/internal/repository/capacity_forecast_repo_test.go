@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapacityForecastRepositoryListDailyUsage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewCapacityForecastRepository(db)
+	start := time.Date(2026, 9, 16, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 29)
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM usage_dashboard_daily")).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket_date", "tokens", "account_cost"}).AddRow(day, int64(1200), 3.5))
+
+	days, err := repo.ListDailyUsage(context.Background(), start, end)
+
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	require.Equal(t, day, days[0].Date)
+	require.Equal(t, int64(1200), days[0].Tokens)
+	require.Equal(t, 3.5, days[0].AccountCost)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type clientAnalyticsRepository struct {
+	db *sql.DB
+}
+
+// NewClientAnalyticsRepository 创建客户端来源分析仓储（直接聚合 usage_logs）。
+func NewClientAnalyticsRepository(db *sql.DB) service.ClientAnalyticsRepository {
+	return &clientAnalyticsRepository{db: db}
+}
+
+func (r *clientAnalyticsRepository) AggregateClientUsage(ctx context.Context, start, end time.Time, apiKeyID int64, limit int) ([]service.ClientUsageGroup, error) {
+	args := []any{start.UTC(), end.UTC()}
+	keyFilter := ""
+	if apiKeyID > 0 {
+		args = append(args, apiKeyID)
+		keyFilter = fmt.Sprintf(" AND ul.api_key_id = $%d", len(args))
+	}
+	args = append(args, limit)
+	query := `
+		SELECT
+			ul.api_key_id,
+			COALESCE(MAX(k.name), '') AS api_key_name,
+			COALESCE(ul.user_agent, '') AS user_agent,
+			COALESCE(ul.ip_address, '') AS ip_address,
+			COUNT(*) AS requests,
+			COALESCE(SUM(ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens), 0) AS total_tokens,
+			COALESCE(SUM(ul.actual_cost), 0) AS actual_cost,
+			MAX(ul.created_at) AS last_used_at
+		FROM usage_logs ul
+		LEFT JOIN api_keys k ON k.id = ul.api_key_id
+		WHERE ul.created_at >= $1 AND ul.created_at < $2` + keyFilter + `
+		GROUP BY ul.api_key_id, ul.user_agent, ul.ip_address
+		ORDER BY COUNT(*) DESC
+		LIMIT $` + fmt.Sprint(len(args)) + `
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate client usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.ClientUsageGroup, 0)
+	for rows.Next() {
+		var g service.ClientUsageGroup
+		if err := rows.Scan(&g.APIKeyID, &g.APIKeyName, &g.UserAgent, &g.IPAddress, &g.Requests, &g.TotalTokens, &g.ActualCost, &g.LastUsedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAnalyticsRepositoryAggregateClientUsage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewClientAnalyticsRepository(db)
+	end := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+	columns := []string{"api_key_id", "api_key_name", "user_agent", "ip_address", "requests", "total_tokens", "actual_cost", "last_used_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("AND ul.api_key_id = $3")).
+		WithArgs(start, end, int64(5), 100).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(5), "team-a", "curl/8.7.1", "203.0.113.7", int64(12), int64(3400), 0.25, end))
+	groups, err := repo.AggregateClientUsage(context.Background(), start, end, 5, 100)
+	require.NoError(t, err)
+	require.Equal(t, []service.ClientUsageGroup{{
+		APIKeyID: 5, APIKeyName: "team-a", UserAgent: "curl/8.7.1", IPAddress: "203.0.113.7",
+		Requests: 12, TotalTokens: 3400, ActualCost: 0.25, LastUsedAt: end,
+	}}, groups)
+
+	mock.ExpectQuery(regexp.QuoteMeta("LIMIT $3")).
+		WithArgs(start, end, 100).
+		WillReturnRows(sqlmock.NewRows(columns))
+	groups, err = repo.AggregateClientUsage(context.Background(), start, end, 0, 100)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
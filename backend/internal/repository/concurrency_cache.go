@@ -30,6 +30,8 @@ const (
 	userSlotKeyPrefix = "concurrency:user:"
 	// 格式: concurrency:api_key:{apiKeyID}
 	apiKeySlotKeyPrefix = "concurrency:api_key:"
+	// 格式: concurrency:account_group:{accountID}:{groupID}
+	accountGroupSlotKeyPrefix = "concurrency:account_group:"
 	// 等待队列计数器格式: concurrency:wait:{userID}
 	waitQueueKeyPrefix = "concurrency:wait:"
 	// 账号级等待队列计数器格式: wait:account:{accountID}
@@ -283,6 +285,10 @@ func apiKeySlotKey(apiKeyID int64) string {
 	return fmt.Sprintf("%s%d", apiKeySlotKeyPrefix, apiKeyID)
 }
 
+func accountGroupSlotKey(accountID, groupID int64) string {
+	return fmt.Sprintf("%s%d:%d", accountGroupSlotKeyPrefix, accountID, groupID)
+}
+
 func waitQueueKey(userID int64) string {
 	return fmt.Sprintf("%s%d", waitQueueKeyPrefix, userID)
 }
@@ -623,6 +629,22 @@ func (c *concurrencyCache) ReleaseAPIKeySlot(ctx context.Context, apiKeyID int64
 	return c.rdb.ZRem(ctx, key, requestID).Err()
 }
 
+// AcquireAccountGroupSlot 获取账号在某分组份额内的槽位。
+// 份额槽位不进入活跃索引，进程异常退出遗留的槽位依赖 TTL 自然过期。
+func (c *concurrencyCache) AcquireAccountGroupSlot(ctx context.Context, accountID, groupID int64, maxConcurrency int, requestID string) (bool, error) {
+	key := accountGroupSlotKey(accountID, groupID)
+	result, _, err := runScriptInt64Pair(ctx, c.rdb, acquireScript, []string{key}, maxConcurrency, c.slotTTLSeconds, requestID)
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+func (c *concurrencyCache) ReleaseAccountGroupSlot(ctx context.Context, accountID, groupID int64, requestID string) error {
+	key := accountGroupSlotKey(accountID, groupID)
+	return c.rdb.ZRem(ctx, key, requestID).Err()
+}
+
 func (c *concurrencyCache) GetAPIKeyConcurrencyBatch(ctx context.Context, apiKeyIDs []int64) (map[int64]int, error) {
 	if len(apiKeyIDs) == 0 {
 		return map[int64]int{}, nil
@@ -0,0 +1,512 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// configSyncTable 描述参与主从同步的一张配置表。
+type configSyncTable struct {
+	name string
+	// keyColumns 行标识列；单列 id 的表按 id upsert 并删除缺失行（有 deleted_at 列时软删除），其余（关联表）整表替换
+	keyColumns []string
+	// localColumns 运行期本地状态列：新行按快照写入，已存在行保留本地值且不参与变更比较
+	localColumns []string
+}
+
+func (t configSyncTable) upsertByID() bool {
+	return len(t.keyColumns) == 1 && t.keyColumns[0] == "id"
+}
+
+// configSyncTables 按外键依赖顺序排列：被同步表引用的配置表必须一并同步且排在前面，否则 replica 的 upsert 违反外键。
+// 余额与用量计数属于各实例本地账本，调度运行态（限流/过载/会话窗口）由各实例独立维护。
+// 用户告警规则、运维 webhook、管理端服务令牌等按实例维护，不参与同步。
+var configSyncTables = []configSyncTable{
+	{name: "proxies", keyColumns: []string{"id"}},
+	{name: "groups", keyColumns: []string{"id"}},
+	{name: "users", keyColumns: []string{"id"}, localColumns: []string{
+		"balance", "frozen_balance", "total_recharged", "last_login_at", "last_active_at",
+	}},
+	{name: "user_allowed_groups", keyColumns: []string{"user_id", "group_id"}},
+	{name: "accounts", keyColumns: []string{"id"}, localColumns: []string{
+		"last_used_at", "rate_limited_at", "rate_limit_reset_at", "overload_until",
+		"temp_unschedulable_until", "temp_unschedulable_reason",
+		"session_window_start", "session_window_end", "session_window_status",
+	}},
+	{name: "account_groups", keyColumns: []string{"account_id", "group_id"}},
+	{name: "api_key_projects", keyColumns: []string{"id"}, localColumns: []string{"quota_used"}},
+	{name: "api_keys", keyColumns: []string{"id"}, localColumns: []string{
+		"last_used_at", "quota_used", "tokens_used", "usage_5h", "usage_1d", "usage_7d",
+		"window_5h_start", "window_1d_start", "window_7d_start",
+	}},
+	{name: "spend_budgets", keyColumns: []string{"id"}},
+	{name: "model_prices", keyColumns: []string{"id"}},
+}
+
+const configSyncAdvisoryLockKey = "config_sync_apply"
+
+type configSyncRepository struct {
+	db *sql.DB
+}
+
+func NewConfigSyncRepository(db *sql.DB) service.ConfigSyncRepository {
+	return &configSyncRepository{db: db}
+}
+
+func (r *configSyncRepository) ExportSnapshot(ctx context.Context) (*service.ConfigSyncSnapshot, error) {
+	// REPEATABLE READ 保证各表导出来自同一时间点，避免外键关联不一致。
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("begin config export: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL TIME ZONE 'UTC'"); err != nil {
+		return nil, fmt.Errorf("set export time zone: %w", err)
+	}
+	schemaVersion, err := configSyncSchemaVersion(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := exportConfigSyncTables(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit config export: %w", err)
+	}
+	return &service.ConfigSyncSnapshot{
+		Version:       service.ConfigSyncFormatVersion,
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Tables:        tables,
+	}, nil
+}
+
+func (r *configSyncRepository) ApplySnapshot(ctx context.Context, snapshot *service.ConfigSyncSnapshot) (*service.ConfigSyncApplyResult, error) {
+	if snapshot == nil {
+		return nil, fmt.Errorf("config snapshot is nil")
+	}
+	for _, table := range configSyncTables {
+		if _, ok := snapshot.Tables[table.name]; !ok {
+			return nil, fmt.Errorf("config snapshot missing table %s", table.name)
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin config apply: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL TIME ZONE 'UTC'"); err != nil {
+		return nil, fmt.Errorf("set apply time zone: %w", err)
+	}
+	// 多个 replica 进程共享同一数据库时串行应用。
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", configSyncAdvisoryLockKey); err != nil {
+		return nil, fmt.Errorf("acquire config sync lock: %w", err)
+	}
+	schemaVersion, err := configSyncSchemaVersion(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if schemaVersion != snapshot.SchemaVersion {
+		return nil, service.ErrConfigSyncSchemaMismatch.WithMetadata(map[string]string{
+			"primary_schema": snapshot.SchemaVersion,
+			"replica_schema": schemaVersion,
+		})
+	}
+	local, err := exportConfigSyncTables(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &service.ConfigSyncApplyResult{RowCounts: make(map[string]int, len(configSyncTables))}
+	changedAPIKeys := make(map[string]struct{})
+	changedUserIDs := make(map[int64]struct{})
+	changedGroupIDs := make(map[int64]struct{})
+	changedProjectIDs := make(map[int64]struct{})
+	var changedAccountIDs []int64
+	fullRebuild := false
+
+	for _, table := range configSyncTables {
+		diff, err := diffConfigSyncTable(table, local[table.name], snapshot.Tables[table.name])
+		if err != nil {
+			return nil, err
+		}
+		result.RowCounts[table.name] = diff.total
+		if !diff.changed() {
+			continue
+		}
+		result.ChangedTables = append(result.ChangedTables, table.name)
+		if err := applyConfigSyncTable(ctx, tx, table, diff, snapshot.Tables[table.name]); err != nil {
+			return nil, err
+		}
+
+		switch table.name {
+		case "groups":
+			fullRebuild = true
+			for _, id := range diff.changedIDs() {
+				changedGroupIDs[id] = struct{}{}
+			}
+		case "account_groups":
+			fullRebuild = true
+		case "accounts":
+			changedAccountIDs = diff.changedIDs()
+		case "users":
+			for _, id := range diff.changedIDs() {
+				changedUserIDs[id] = struct{}{}
+			}
+		case "user_allowed_groups":
+			for _, row := range diff.changedRows() {
+				if id, ok := configSyncRowInt64(row, "user_id"); ok {
+					changedUserIDs[id] = struct{}{}
+				}
+			}
+		case "api_key_projects":
+			for _, id := range diff.changedIDs() {
+				changedProjectIDs[id] = struct{}{}
+			}
+		case "api_keys":
+			for _, row := range diff.changedRows() {
+				if key, ok := configSyncRowString(row, "key"); ok && key != "" {
+					changedAPIKeys[key] = struct{}{}
+				}
+			}
+		}
+	}
+
+	switch {
+	case fullRebuild:
+		if err := enqueueSchedulerOutbox(ctx, tx, service.SchedulerOutboxEventFullRebuild, nil, nil, nil); err != nil {
+			return nil, fmt.Errorf("enqueue scheduler rebuild: %w", err)
+		}
+		result.SchedulerChanged = true
+	case len(changedAccountIDs) > 0:
+		payload := map[string]any{"account_ids": changedAccountIDs}
+		if err := enqueueSchedulerOutbox(ctx, tx, service.SchedulerOutboxEventAccountBulkChanged, nil, nil, payload); err != nil {
+			return nil, fmt.Errorf("enqueue scheduler account change: %w", err)
+		}
+		result.SchedulerChanged = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit config apply: %w", err)
+	}
+
+	result.ChangedAPIKeys = sortedStringKeys(changedAPIKeys)
+	result.ChangedUserIDs = sortedInt64Keys(changedUserIDs)
+	result.ChangedGroupIDs = sortedInt64Keys(changedGroupIDs)
+	result.ChangedProjectIDs = sortedInt64Keys(changedProjectIDs)
+	return result, nil
+}
+
+func configSyncSchemaVersion(ctx context.Context, tx *sql.Tx) (string, error) {
+	var version string
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(filename), '') FROM schema_migrations").Scan(&version); err != nil {
+		return "", fmt.Errorf("query schema version: %w", err)
+	}
+	return version, nil
+}
+
+func exportConfigSyncTables(ctx context.Context, tx *sql.Tx) (map[string]json.RawMessage, error) {
+	tables := make(map[string]json.RawMessage, len(configSyncTables))
+	for _, table := range configSyncTables {
+		orderBy := make([]string, 0, len(table.keyColumns))
+		for _, col := range table.keyColumns {
+			orderBy = append(orderBy, "t."+pq.QuoteIdentifier(col))
+		}
+		query := fmt.Sprintf(
+			"SELECT COALESCE(json_agg(row_to_json(t) ORDER BY %s), '[]'::json) FROM %s t",
+			strings.Join(orderBy, ", "), pq.QuoteIdentifier(table.name),
+		)
+		var raw []byte
+		if err := tx.QueryRowContext(ctx, query).Scan(&raw); err != nil {
+			return nil, fmt.Errorf("export %s: %w", table.name, err)
+		}
+		tables[table.name] = json.RawMessage(raw)
+	}
+	return tables, nil
+}
+
+type configSyncRow map[string]json.RawMessage
+
+// configSyncTableDiff 本地与快照之间的行级差异。
+type configSyncTableDiff struct {
+	total int
+	// upserts 新增或内容变化的快照行
+	upserts []configSyncRow
+	// removed 快照中不存在的本地行（按 id 表仅统计未软删除的行）
+	removed []configSyncRow
+	// replaced 关联表内容变化的旧行，用于定位受影响的实体
+	replaced []configSyncRow
+}
+
+func (d configSyncTableDiff) changed() bool {
+	return len(d.upserts) > 0 || len(d.removed) > 0
+}
+
+// changedRows 返回变更前后涉及的所有行（旧行在前）。
+func (d configSyncTableDiff) changedRows() []configSyncRow {
+	rows := make([]configSyncRow, 0, len(d.replaced)+len(d.removed)+len(d.upserts))
+	rows = append(rows, d.replaced...)
+	rows = append(rows, d.removed...)
+	return append(rows, d.upserts...)
+}
+
+func (d configSyncTableDiff) changedIDs() []int64 {
+	seen := make(map[int64]struct{})
+	for _, row := range d.changedRows() {
+		if id, ok := configSyncRowInt64(row, "id"); ok {
+			seen[id] = struct{}{}
+		}
+	}
+	return sortedInt64Keys(seen)
+}
+
+func diffConfigSyncTable(table configSyncTable, localRaw, remoteRaw json.RawMessage) (configSyncTableDiff, error) {
+	var localRows, remoteRows []configSyncRow
+	if err := json.Unmarshal(localRaw, &localRows); err != nil {
+		return configSyncTableDiff{}, fmt.Errorf("decode local %s: %w", table.name, err)
+	}
+	if err := json.Unmarshal(remoteRaw, &remoteRows); err != nil {
+		return configSyncTableDiff{}, fmt.Errorf("decode snapshot %s: %w", table.name, err)
+	}
+
+	diff := configSyncTableDiff{total: len(remoteRows)}
+	localByKey := make(map[string]configSyncRow, len(localRows))
+	localSig := make(map[string]string, len(localRows))
+	for _, row := range localRows {
+		key := configSyncRowKey(table, row)
+		sig, err := configSyncRowSignature(table, row)
+		if err != nil {
+			return configSyncTableDiff{}, err
+		}
+		localByKey[key] = row
+		localSig[key] = sig
+	}
+
+	remoteKeys := make(map[string]struct{}, len(remoteRows))
+	for _, row := range remoteRows {
+		key := configSyncRowKey(table, row)
+		if _, dup := remoteKeys[key]; dup {
+			return configSyncTableDiff{}, fmt.Errorf("config snapshot %s has duplicate row %s", table.name, key)
+		}
+		remoteKeys[key] = struct{}{}
+		sig, err := configSyncRowSignature(table, row)
+		if err != nil {
+			return configSyncTableDiff{}, err
+		}
+		if prev, ok := localSig[key]; ok && prev == sig {
+			continue
+		}
+		diff.upserts = append(diff.upserts, row)
+		if old, ok := localByKey[key]; ok {
+			diff.replaced = append(diff.replaced, old)
+		}
+	}
+	for _, row := range localRows {
+		if _, ok := remoteKeys[configSyncRowKey(table, row)]; ok {
+			continue
+		}
+		if table.upsertByID() && !configSyncRowIsNull(row, "deleted_at") {
+			continue
+		}
+		diff.removed = append(diff.removed, row)
+	}
+	return diff, nil
+}
+
+func applyConfigSyncTable(ctx context.Context, tx *sql.Tx, table configSyncTable, diff configSyncTableDiff, snapshotRows json.RawMessage) error {
+	columns, err := configSyncTableColumns(ctx, tx, table.name)
+	if err != nil {
+		return err
+	}
+	quotedTable := pq.QuoteIdentifier(table.name)
+	quotedCols := make([]string, 0, len(columns))
+	for _, col := range columns {
+		quotedCols = append(quotedCols, pq.QuoteIdentifier(col))
+	}
+	colList := strings.Join(quotedCols, ", ")
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM json_populate_recordset(NULL::%s, $1::json)",
+		quotedTable, colList, colList, quotedTable,
+	)
+
+	if !table.upsertByID() {
+		// 关联表无独立主键且体量小，整表替换即可。
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+quotedTable); err != nil {
+			return fmt.Errorf("clear %s: %w", table.name, err)
+		}
+		if _, err := tx.ExecContext(ctx, insertQuery, []byte(snapshotRows)); err != nil {
+			return fmt.Errorf("insert %s: %w", table.name, err)
+		}
+		return nil
+	}
+
+	// 先删除缺失行，再 upsert，避免唯一约束被待删除的旧行占用。
+	if len(diff.removed) > 0 {
+		ids := make([]int64, 0, len(diff.removed))
+		for _, row := range diff.removed {
+			if id, ok := configSyncRowInt64(row, "id"); ok {
+				ids = append(ids, id)
+			}
+		}
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = NOW() WHERE id = ANY($1) AND deleted_at IS NULL", quotedTable)
+		if !slices.Contains(columns, "deleted_at") {
+			query = fmt.Sprintf("DELETE FROM %s WHERE id = ANY($1)", quotedTable)
+		}
+		if _, err := tx.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+			return fmt.Errorf("delete %s: %w", table.name, err)
+		}
+	}
+	if len(diff.upserts) > 0 {
+		local := make(map[string]struct{}, len(table.localColumns))
+		for _, col := range table.localColumns {
+			local[col] = struct{}{}
+		}
+		sets := make([]string, 0, len(columns))
+		for _, col := range columns {
+			if col == "id" {
+				continue
+			}
+			if _, ok := local[col]; ok {
+				continue
+			}
+			quoted := pq.QuoteIdentifier(col)
+			sets = append(sets, quoted+" = EXCLUDED."+quoted)
+		}
+		payload, err := json.Marshal(diff.upserts)
+		if err != nil {
+			return fmt.Errorf("encode %s rows: %w", table.name, err)
+		}
+		query := insertQuery + " ON CONFLICT (id) DO UPDATE SET " + strings.Join(sets, ", ")
+		if _, err := tx.ExecContext(ctx, query, payload); err != nil {
+			return fmt.Errorf("upsert %s: %w", table.name, err)
+		}
+	}
+	// 同步后对齐自增序列，防止本地后续插入（如运维脚本）与 primary 的 id 冲突。
+	seqQuery := fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence($1, 'id'), GREATEST((SELECT COALESCE(MAX(id), 0) FROM %s), 1))",
+		quotedTable,
+	)
+	if _, err := tx.ExecContext(ctx, seqQuery, table.name); err != nil {
+		return fmt.Errorf("align %s sequence: %w", table.name, err)
+	}
+	return nil
+}
+
+func configSyncTableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+			AND table_name = $1
+			AND is_generated = 'NEVER'
+		ORDER BY ordinal_position
+	`, table)
+	if err != nil {
+		return nil, fmt.Errorf("list %s columns: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s has no columns", table)
+	}
+	return columns, nil
+}
+
+func configSyncRowKey(table configSyncTable, row configSyncRow) string {
+	parts := make([]string, 0, len(table.keyColumns))
+	for _, col := range table.keyColumns {
+		parts = append(parts, string(row[col]))
+	}
+	return strings.Join(parts, ":")
+}
+
+// configSyncRowSignature 去除本地状态列后的规范化行内容（encoding/json 对 map 键排序）。
+func configSyncRowSignature(table configSyncTable, row configSyncRow) (string, error) {
+	filtered := make(configSyncRow, len(row))
+	for k, v := range row {
+		filtered[k] = v
+	}
+	for _, col := range table.localColumns {
+		delete(filtered, col)
+	}
+	encoded, err := json.Marshal(filtered)
+	if err != nil {
+		return "", fmt.Errorf("encode %s row: %w", table.name, err)
+	}
+	return string(encoded), nil
+}
+
+func configSyncRowIsNull(row configSyncRow, col string) bool {
+	raw, ok := row[col]
+	return !ok || string(raw) == "null"
+}
+
+func configSyncRowInt64(row configSyncRow, col string) (int64, bool) {
+	raw, ok := row[col]
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	return id, err == nil
+}
+
+func configSyncRowString(row configSyncRow, col string) (string, bool) {
+	raw, ok := row[col]
+	if !ok {
+		return "", false
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+func sortedInt64Keys(set map[int64]struct{}) []int64 {
+	if len(set) == 0 {
+		return nil
+	}
+	out := make([]int64, 0, len(set))
+	for id := range set {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func sortedStringKeys(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(set))
+	for key := range set {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
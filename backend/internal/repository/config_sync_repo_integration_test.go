@@ -0,0 +1,90 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSyncRepository_ApplySnapshotWithProjectKey(t *testing.T) {
+	ctx := context.Background()
+	client := testEntClient(t)
+	suffix := time.Now().UnixNano()
+
+	user, err := client.User.Create().
+		SetEmail(fmt.Sprintf("config-sync-%d@test.com", suffix)).
+		SetPasswordHash("test-password-hash").
+		SetStatus(service.StatusActive).
+		SetRole(service.RoleUser).
+		Save(ctx)
+	require.NoError(t, err)
+	key, err := client.APIKey.Create().
+		SetUserID(user.ID).
+		SetKey(fmt.Sprintf("sk-config-sync-%d", suffix)).
+		SetName("synced").
+		SetStatus(service.StatusActive).
+		Save(ctx)
+	require.NoError(t, err)
+	projectID := suffix % 1_000_000_000
+	t.Cleanup(func() {
+		_, _ = integrationDB.ExecContext(ctx, "DELETE FROM api_keys WHERE id = $1", key.ID)
+		_, _ = integrationDB.ExecContext(ctx, "DELETE FROM api_key_projects WHERE id = $1", projectID)
+		_, _ = integrationDB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+	})
+
+	repo := NewConfigSyncRepository(integrationDB)
+	snapshot, err := repo.ExportSnapshot(ctx)
+	require.NoError(t, err)
+
+	// primary 上该 Key 已归入一个 replica 尚不存在的项目
+	withProject := func(quotaUsed float64) *service.ConfigSyncSnapshot {
+		var projects, keys []map[string]any
+		require.NoError(t, json.Unmarshal(snapshot.Tables["api_key_projects"], &projects))
+		require.NoError(t, json.Unmarshal(snapshot.Tables["api_keys"], &keys))
+		projects = append(projects, map[string]any{
+			"id": projectID, "user_id": user.ID, "name": "synced-project", "description": "",
+			"quota": 10, "quota_used": quotaUsed,
+			"created_at": "2026-01-01T00:00:00+00:00", "updated_at": "2026-01-01T00:00:00+00:00",
+		})
+		for _, row := range keys {
+			if row["key"] == key.Key {
+				row["project_id"] = projectID
+			}
+		}
+		patched := *snapshot
+		patched.Tables = make(map[string]json.RawMessage, len(snapshot.Tables))
+		for name, raw := range snapshot.Tables {
+			patched.Tables[name] = raw
+		}
+		var err error
+		patched.Tables["api_key_projects"], err = json.Marshal(projects)
+		require.NoError(t, err)
+		patched.Tables["api_keys"], err = json.Marshal(keys)
+		require.NoError(t, err)
+		return &patched
+	}
+
+	result, err := repo.ApplySnapshot(ctx, withProject(3))
+	require.NoError(t, err)
+	require.Contains(t, result.ChangedTables, "api_key_projects")
+	require.Contains(t, result.ChangedAPIKeys, key.Key)
+	require.Equal(t, []int64{projectID}, result.ChangedProjectIDs)
+
+	var gotProjectID int64
+	require.NoError(t, integrationDB.QueryRowContext(ctx, "SELECT project_id FROM api_keys WHERE id = $1", key.ID).Scan(&gotProjectID))
+	require.Equal(t, projectID, gotProjectID)
+
+	// quota_used 是本地账本：已存在的项目不被快照覆盖
+	_, err = repo.ApplySnapshot(ctx, withProject(9))
+	require.NoError(t, err)
+	var quotaUsed float64
+	require.NoError(t, integrationDB.QueryRowContext(ctx, "SELECT quota_used FROM api_key_projects WHERE id = $1", projectID).Scan(&quotaUsed))
+	require.Equal(t, 3.0, quotaUsed)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func configSyncTableByName(t *testing.T, name string) configSyncTable {
+	t.Helper()
+	for _, table := range configSyncTables {
+		if table.name == name {
+			return table
+		}
+	}
+	t.Fatalf("config sync table %s not found", name)
+	return configSyncTable{}
+}
+
+func TestDiffConfigSyncTable_IgnoresLocalColumns(t *testing.T) {
+	table := configSyncTableByName(t, "api_keys")
+	local := json.RawMessage(`[
+		{"id":1,"key":"sk-a","name":"a","quota_used":5,"last_used_at":"2026-01-01T00:00:00+00:00","deleted_at":null},
+		{"id":2,"key":"sk-b","name":"b","quota_used":0,"last_used_at":null,"deleted_at":null},
+		{"id":3,"key":"sk-c","name":"c","quota_used":0,"last_used_at":null,"deleted_at":null},
+		{"id":4,"key":"sk-d","name":"d","quota_used":0,"last_used_at":null,"deleted_at":"2026-01-01T00:00:00+00:00"}
+	]`)
+	remote := json.RawMessage(`[
+		{"id":1,"key":"sk-a","name":"a","quota_used":0,"last_used_at":null,"deleted_at":null},
+		{"id":2,"key":"sk-b","name":"renamed","quota_used":0,"last_used_at":null,"deleted_at":null},
+		{"id":5,"key":"sk-e","name":"e","quota_used":0,"last_used_at":null,"deleted_at":null}
+	]`)
+
+	diff, err := diffConfigSyncTable(table, local, remote)
+	require.NoError(t, err)
+	require.True(t, diff.changed())
+	require.Equal(t, 3, diff.total)
+	// id=1 仅本地用量列不同，不视为变更；id=4 已软删除，不重复删除
+	require.Len(t, diff.upserts, 2)
+	require.Len(t, diff.removed, 1)
+	require.Equal(t, []int64{2, 3, 5}, diff.changedIDs())
+
+	keys := make([]string, 0)
+	for _, row := range diff.changedRows() {
+		key, ok := configSyncRowString(row, "key")
+		require.True(t, ok)
+		keys = append(keys, key)
+	}
+	require.ElementsMatch(t, []string{"sk-b", "sk-b", "sk-c", "sk-e"}, keys)
+}
+
+func TestDiffConfigSyncTable_JoinTableByCompositeKey(t *testing.T) {
+	table := configSyncTableByName(t, "account_groups")
+	local := json.RawMessage(`[{"account_id":1,"group_id":1,"priority":1},{"account_id":1,"group_id":2,"priority":1}]`)
+
+	diff, err := diffConfigSyncTable(table, local, local)
+	require.NoError(t, err)
+	require.False(t, diff.changed())
+
+	remote := json.RawMessage(`[{"account_id":1,"group_id":1,"priority":5}]`)
+	diff, err = diffConfigSyncTable(table, local, remote)
+	require.NoError(t, err)
+	require.True(t, diff.changed())
+	require.Len(t, diff.upserts, 1)
+	require.Len(t, diff.removed, 1)
+}
+
+func TestDiffConfigSyncTable_RejectsDuplicateRows(t *testing.T) {
+	table := configSyncTableByName(t, "groups")
+	_, err := diffConfigSyncTable(table, json.RawMessage(`[]`), json.RawMessage(`[{"id":1},{"id":1}]`))
+	require.Error(t, err)
+}
+
+func TestConfigSyncTables_ProjectsPrecedeAPIKeys(t *testing.T) {
+	index := make(map[string]int, len(configSyncTables))
+	for i, table := range configSyncTables {
+		index[table.name] = i
+	}
+	// api_keys.project_id 引用 api_key_projects，api_key_projects.user_id 引用 users
+	require.Less(t, index["users"], index["api_key_projects"])
+	require.Less(t, index["api_key_projects"], index["api_keys"])
+	require.Contains(t, index, "spend_budgets")
+	require.Contains(t, index, "model_prices")
+	require.Contains(t, configSyncTableByName(t, "api_keys").localColumns, "tokens_used")
+}
+
+func TestDiffConfigSyncTable_ProjectQuotaUsedIsLocalAndMissingRowsRemoved(t *testing.T) {
+	table := configSyncTableByName(t, "api_key_projects")
+	local := json.RawMessage(`[
+		{"id":1,"user_id":7,"name":"web","quota":10,"quota_used":4},
+		{"id":2,"user_id":7,"name":"old","quota":0,"quota_used":0}
+	]`)
+	remote := json.RawMessage(`[
+		{"id":1,"user_id":7,"name":"web","quota":10,"quota_used":0},
+		{"id":3,"user_id":7,"name":"batch","quota":5,"quota_used":0}
+	]`)
+
+	diff, err := diffConfigSyncTable(table, local, remote)
+	require.NoError(t, err)
+	require.Len(t, diff.upserts, 1)
+	require.JSONEq(t, `3`, string(diff.upserts[0]["id"]))
+	// 无 deleted_at 列的表缺失行直接删除
+	require.Len(t, diff.removed, 1)
+	require.JSONEq(t, `2`, string(diff.removed[0]["id"]))
+	require.Equal(t, []int64{2, 3}, diff.changedIDs())
+}
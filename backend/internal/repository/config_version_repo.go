@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// configVersionAppendAttempts 并发写同一实体时版本号可能撞唯一索引，重试次数上限。
+const configVersionAppendAttempts = 3
+
+type configVersionRepository struct {
+	db *sql.DB
+}
+
+func NewConfigVersionRepository(db *sql.DB) service.ConfigVersionRepository {
+	return &configVersionRepository{db: db}
+}
+
+const configVersionColumns = `id, entity_type, entity_id, version, action, snapshot, snapshot_hash, operator_id, restored_from, created_at`
+
+// Append 在同一条 INSERT ... SELECT 中计算 MAX(version)+1；并发追加撞 (entity_type, entity_id, version) 唯一索引时重试。
+func (r *configVersionRepository) Append(ctx context.Context, v *service.ConfigVersion) error {
+	var err error
+	for attempt := 0; attempt < configVersionAppendAttempts; attempt++ {
+		err = r.db.QueryRowContext(ctx, `
+			INSERT INTO config_versions (entity_type, entity_id, version, action, snapshot, snapshot_hash, operator_id, restored_from)
+			SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, $6, $7
+			FROM config_versions
+			WHERE entity_type = $1 AND entity_id = $2
+			RETURNING id, version, created_at
+		`, v.EntityType, v.EntityID, v.Action, string(v.Snapshot), v.SnapshotHash, v.OperatorID, v.RestoredFrom,
+		).Scan(&v.ID, &v.Version, &v.CreatedAt)
+		if err == nil || !isUniqueViolation(err) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("insert config version: %w", err)
+	}
+	return nil
+}
+
+func (r *configVersionRepository) Latest(ctx context.Context, entityType string, entityID int64) (*service.ConfigVersion, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+configVersionColumns+`
+		FROM config_versions
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY version DESC
+		LIMIT 1
+	`, entityType, entityID)
+	v, err := scanConfigVersion(row)
+	if errors.Is(err, service.ErrConfigVersionNotFound) {
+		return nil, nil
+	}
+	return v, err
+}
+
+func (r *configVersionRepository) GetByVersion(ctx context.Context, entityType string, entityID int64, version int) (*service.ConfigVersion, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+configVersionColumns+`
+		FROM config_versions
+		WHERE entity_type = $1 AND entity_id = $2 AND version = $3
+	`, entityType, entityID, version)
+	return scanConfigVersion(row)
+}
+
+func (r *configVersionRepository) List(ctx context.Context, params pagination.PaginationParams, entityType string, entityID int64) ([]*service.ConfigVersion, *pagination.PaginationResult, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM config_versions WHERE entity_type = $1 AND entity_id = $2`,
+		entityType, entityID,
+	).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count config versions: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+configVersionColumns+`
+		FROM config_versions
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY version DESC
+		LIMIT $3 OFFSET $4
+	`, entityType, entityID, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, nil, fmt.Errorf("list config versions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.ConfigVersion, 0)
+	for rows.Next() {
+		v, err := scanConfigVersion(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return items, paginationResultFromTotal(total, params), nil
+}
+
+func scanConfigVersion(row scannable) (*service.ConfigVersion, error) {
+	v := &service.ConfigVersion{}
+	var snapshot []byte
+	var operatorID sql.NullInt64
+	var restoredFrom sql.NullInt32
+	if err := row.Scan(
+		&v.ID, &v.EntityType, &v.EntityID, &v.Version, &v.Action, &snapshot, &v.SnapshotHash,
+		&operatorID, &restoredFrom, &v.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrConfigVersionNotFound
+		}
+		return nil, err
+	}
+	v.Snapshot = snapshot
+	if operatorID.Valid {
+		id := operatorID.Int64
+		v.OperatorID = &id
+	}
+	if restoredFrom.Valid {
+		version := int(restoredFrom.Int32)
+		v.RestoredFrom = &version
+	}
+	return v, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigVersionRepositoryAppend_RetriesOnVersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewConfigVersionRepository(db)
+	operatorID := int64(1)
+	now := time.Now()
+	insert := regexp.QuoteMeta("SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, $6, $7")
+	mock.ExpectQuery(insert).
+		WithArgs(service.ConfigEntityGroup, int64(3), service.ConfigVersionActionUpdate, `{"Name":"g"}`, "h", &operatorID, nil).
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectQuery(insert).
+		WithArgs(service.ConfigEntityGroup, int64(3), service.ConfigVersionActionUpdate, `{"Name":"g"}`, "h", &operatorID, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "created_at"}).AddRow(11, 4, now))
+
+	v := &service.ConfigVersion{
+		EntityType:   service.ConfigEntityGroup,
+		EntityID:     3,
+		Action:       service.ConfigVersionActionUpdate,
+		Snapshot:     []byte(`{"Name":"g"}`),
+		SnapshotHash: "h",
+		OperatorID:   &operatorID,
+	}
+	require.NoError(t, repo.Append(context.Background(), v))
+	require.Equal(t, int64(11), v.ID)
+	require.Equal(t, 4, v.Version)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfigVersionRepositoryLatest_NoRowsReturnsNil(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewConfigVersionRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY version DESC")).
+		WithArgs(service.ConfigEntityAccount, int64(5)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("AND version = $3")).
+		WithArgs(service.ConfigEntityAccount, int64(5), 2).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "entity_type", "entity_id", "version", "action", "snapshot", "snapshot_hash", "operator_id", "restored_from", "created_at",
+		}).AddRow(8, service.ConfigEntityAccount, 5, 2, service.ConfigVersionActionRollback, []byte(`{"name":"a"}`), "h", nil, 1, time.Now()))
+
+	latest, err := repo.Latest(context.Background(), service.ConfigEntityAccount, 5)
+	require.NoError(t, err)
+	require.Nil(t, latest)
+
+	v, err := repo.GetByVersion(context.Background(), service.ConfigEntityAccount, 5, 2)
+	require.NoError(t, err)
+	require.Nil(t, v.OperatorID)
+	require.NotNil(t, v.RestoredFrom)
+	require.Equal(t, 1, *v.RestoredFrom)
+	require.JSONEq(t, `{"name":"a"}`, string(v.Snapshot))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
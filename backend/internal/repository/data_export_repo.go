@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type dataExportRepository struct {
+	db *sql.DB
+}
+
+// NewDataExportRepository 创建原始数据导出仓储（按 id 游标分批读取 usage_logs / ops_error_logs）。
+func NewDataExportRepository(db *sql.DB) service.DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+// buildDataExportWhere 组装公共过滤条件（两张表列名一致），args 从 $1 = afterID 开始。
+func buildDataExportWhere(filter *service.DataExportFilter, afterID int64, extra func(add func(clause string, arg any))) (string, []any) {
+	args := []any{afterID, filter.StartTime, filter.EndTime}
+	clauses := []string{"id > $1", "created_at >= $2", "created_at < $3"}
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+	for _, f := range []struct {
+		column string
+		value  int64
+	}{
+		{"user_id", filter.UserID},
+		{"api_key_id", filter.APIKeyID},
+		{"group_id", filter.GroupID},
+		{"account_id", filter.AccountID},
+	} {
+		if f.value > 0 {
+			add(f.column+" = $%d", f.value)
+		}
+	}
+	if filter.Model != "" {
+		add("model = $%d", filter.Model)
+	}
+	if extra != nil {
+		extra(add)
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func (r *dataExportRepository) ListUsageLogs(ctx context.Context, filter *service.DataExportFilter, afterID int64, limit int) ([]*service.UsageLogExportRow, error) {
+	where, args := buildDataExportWhere(filter, afterID, nil)
+	args = append(args, limit)
+	q := `
+		SELECT
+			id, created_at, COALESCE(request_id, ''), user_id, api_key_id, account_id, group_id,
+			COALESCE(model, ''), COALESCE(requested_model, ''), COALESCE(upstream_model, ''),
+			COALESCE(request_type, 0), stream,
+			input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens,
+			input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost,
+			rate_multiplier, COALESCE(billing_type, 0), COALESCE(billing_mode, ''),
+			duration_ms, first_token_ms, COALESCE(ip_address, ''), COALESCE(user_agent, '')
+		FROM usage_logs
+		` + where + `
+		ORDER BY id ASC
+		LIMIT $` + fmt.Sprint(len(args))
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list usage logs for export: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.UsageLogExportRow, 0, limit)
+	for rows.Next() {
+		var (
+			row          service.UsageLogExportRow
+			groupID      sql.NullInt64
+			durationMs   sql.NullInt64
+			firstTokenMs sql.NullInt64
+		)
+		if err := rows.Scan(
+			&row.ID, &row.CreatedAt, &row.RequestID, &row.UserID, &row.APIKeyID, &row.AccountID, &groupID,
+			&row.Model, &row.RequestedModel, &row.UpstreamModel,
+			&row.RequestType, &row.Stream,
+			&row.InputTokens, &row.OutputTokens, &row.CacheCreationTokens, &row.CacheReadTokens,
+			&row.InputCost, &row.OutputCost, &row.CacheCreationCost, &row.CacheReadCost, &row.TotalCost, &row.ActualCost,
+			&row.RateMultiplier, &row.BillingType, &row.BillingMode,
+			&durationMs, &firstTokenMs, &row.IPAddress, &row.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		row.CreatedAt = row.CreatedAt.UTC()
+		row.GroupID = dataExportNullInt64Ptr(groupID)
+		row.DurationMs = dataExportNullInt64Ptr(durationMs)
+		row.FirstTokenMs = dataExportNullInt64Ptr(firstTokenMs)
+		out = append(out, &row)
+	}
+	return out, rows.Err()
+}
+
+func (r *dataExportRepository) ListOpsErrors(ctx context.Context, filter *service.DataExportFilter, afterID int64, limit int) ([]*service.OpsErrorExportRow, error) {
+	where, args := buildDataExportWhere(filter, afterID, func(add func(clause string, arg any)) {
+		if filter.Platform != "" {
+			add("platform = $%d", filter.Platform)
+		}
+		if filter.Phase != "" {
+			add("error_phase = $%d", filter.Phase)
+		}
+		if filter.Owner != "" {
+			add("error_owner = $%d", filter.Owner)
+		}
+		if filter.StatusCode > 0 {
+			add("status_code = $%d", filter.StatusCode)
+		}
+	})
+	args = append(args, limit)
+	q := `
+		SELECT
+			id, created_at, COALESCE(request_id, ''), COALESCE(client_request_id, ''),
+			user_id, api_key_id, account_id, group_id,
+			COALESCE(platform, ''), COALESCE(model, ''), COALESCE(request_path, ''), stream,
+			COALESCE(error_phase, ''), COALESCE(error_type, ''), COALESCE(error_code, ''), COALESCE(severity, ''),
+			status_code, upstream_status_code, COALESCE(error_owner, ''), COALESCE(error_source, ''),
+			is_business_limited, is_count_tokens, COALESCE(error_message, ''), duration_ms,
+			CASE WHEN client_ip IS NULL THEN '' ELSE host(client_ip) END
+		FROM ops_error_logs
+		` + where + `
+		ORDER BY id ASC
+		LIMIT $` + fmt.Sprint(len(args))
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list ops errors for export: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsErrorExportRow, 0, limit)
+	for rows.Next() {
+		var (
+			row                service.OpsErrorExportRow
+			userID             sql.NullInt64
+			apiKeyID           sql.NullInt64
+			accountID          sql.NullInt64
+			groupID            sql.NullInt64
+			statusCode         sql.NullInt64
+			upstreamStatusCode sql.NullInt64
+			durationMs         sql.NullInt64
+		)
+		if err := rows.Scan(
+			&row.ID, &row.CreatedAt, &row.RequestID, &row.ClientRequestID,
+			&userID, &apiKeyID, &accountID, &groupID,
+			&row.Platform, &row.Model, &row.RequestPath, &row.Stream,
+			&row.ErrorPhase, &row.ErrorType, &row.ErrorCode, &row.Severity,
+			&statusCode, &upstreamStatusCode, &row.ErrorOwner, &row.ErrorSource,
+			&row.IsBusinessLimited, &row.IsCountTokens, &row.ErrorMessage, &durationMs,
+			&row.ClientIP,
+		); err != nil {
+			return nil, err
+		}
+		row.CreatedAt = row.CreatedAt.UTC()
+		row.UserID = dataExportNullInt64Ptr(userID)
+		row.APIKeyID = dataExportNullInt64Ptr(apiKeyID)
+		row.AccountID = dataExportNullInt64Ptr(accountID)
+		row.GroupID = dataExportNullInt64Ptr(groupID)
+		row.StatusCode = dataExportNullInt64Ptr(statusCode)
+		row.UpstreamStatusCode = dataExportNullInt64Ptr(upstreamStatusCode)
+		row.DurationMs = dataExportNullInt64Ptr(durationMs)
+		out = append(out, &row)
+	}
+	return out, rows.Err()
+}
+
+func dataExportNullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
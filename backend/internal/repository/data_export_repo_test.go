@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExportRepositoryListOpsErrors_AppliesCursorAndFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewDataExportRepository(db)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	filter := &service.DataExportFilter{StartTime: start, EndTime: end, AccountID: 5, Platform: "openai", StatusCode: 429}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ops_error_logs\n\t\tWHERE id > $1 AND created_at >= $2 AND created_at < $3 AND account_id = $4 AND platform = $5 AND status_code = $6\n\t\tORDER BY id ASC\n\t\tLIMIT $7")).
+		WithArgs(int64(100), start, end, int64(5), "openai", 429, 2).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "created_at", "request_id", "client_request_id", "user_id", "api_key_id", "account_id", "group_id",
+			"platform", "model", "request_path", "stream", "error_phase", "error_type", "error_code", "severity",
+			"status_code", "upstream_status_code", "error_owner", "error_source", "is_business_limited", "is_count_tokens",
+			"error_message", "duration_ms", "client_ip",
+		}).AddRow(
+			int64(101), start.Add(time.Hour), "req-1", "", nil, nil, int64(5), nil,
+			"openai", "gpt-5", "/v1/responses", true, "upstream", "rate_limit_error", "rate_limited", "P2",
+			int64(429), int64(429), "provider", "upstream_http", false, false,
+			"rate limited", int64(120), "",
+		))
+
+	rows, err := repo.ListOpsErrors(context.Background(), filter, 100, 2)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, int64(101), rows[0].ID)
+	require.Nil(t, rows[0].UserID)
+	require.Equal(t, int64(5), *rows[0].AccountID)
+	require.Equal(t, int64(429), *rows[0].StatusCode)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataExportRepositoryListUsageLogs_ScansRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewDataExportRepository(db)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	filter := &service.DataExportFilter{StartTime: start, EndTime: end, Model: "claude-sonnet-4-6"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id > $1 AND created_at >= $2 AND created_at < $3 AND model = $4")).
+		WithArgs(int64(0), start, end, "claude-sonnet-4-6", 1000).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "created_at", "request_id", "user_id", "api_key_id", "account_id", "group_id",
+			"model", "requested_model", "upstream_model", "request_type", "stream",
+			"input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens",
+			"input_cost", "output_cost", "cache_creation_cost", "cache_read_cost", "total_cost", "actual_cost",
+			"rate_multiplier", "billing_type", "billing_mode", "duration_ms", "first_token_ms", "ip_address", "user_agent",
+		}).AddRow(
+			int64(1), start, "req-1", int64(2), int64(3), int64(4), int64(6),
+			"claude-sonnet-4-6", "claude-sonnet-4-6", "", int64(2), true,
+			int64(100), int64(50), int64(0), int64(10),
+			0.1, 0.2, 0.0, 0.01, 0.31, 0.62,
+			2.0, int64(0), "token", nil, int64(300), "127.0.0.1", "curl/8",
+		))
+
+	rows, err := repo.ListUsageLogs(context.Background(), filter, 0, 1000)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, int64(6), *rows[0].GroupID)
+	require.Nil(t, rows[0].DurationMs)
+	require.Equal(t, int64(300), *rows[0].FirstTokenMs)
+	require.InDelta(t, 0.62, rows[0].ActualCost, 1e-9)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -76,15 +76,32 @@ func (r *groupRepository) Create(ctx context.Context, groupIn *service.Group) er
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
 		SetModelsListConfig(groupIn.ModelsListConfig).
 		SetRpmLimit(groupIn.RPMLimit).
+		SetReservedConcurrencyRatio(groupIn.ReservedConcurrencyRatio).
 		SetPeakRateEnabled(groupIn.PeakRateEnabled).
 		SetPeakStart(groupIn.PeakStart).
 		SetPeakEnd(groupIn.PeakEnd).
-		SetPeakRateMultiplier(groupIn.PeakRateMultiplier)
+		SetPeakRateMultiplier(groupIn.PeakRateMultiplier).
+		SetRequestSurcharge(groupIn.RequestSurcharge)
 
 	// 设置模型路由配置
 	if groupIn.ModelRouting != nil {
 		builder = builder.SetModelRouting(groupIn.ModelRouting)
 	}
+	if len(groupIn.OverloadFallbackModels) > 0 {
+		builder = builder.SetOverloadFallbackModels(groupIn.OverloadFallbackModels)
+	}
+	if len(groupIn.ContextOverflowModels) > 0 {
+		builder = builder.SetContextOverflowModels(groupIn.ContextOverflowModels)
+	}
+	builder = builder.SetContextOverflowReject(groupIn.ContextOverflowReject)
+	builder = builder.SetContextTruncationStrategy(groupIn.ContextTruncationStrategy)
+	builder = builder.SetAccountSelectionStrategy(groupIn.AccountSelectionStrategy)
+	builder = builder.SetImageMaxEdge(groupIn.ImageMaxEdge).SetImageMaxBytes(groupIn.ImageMaxBytes)
+	builder = builder.SetOutputPostprocess(groupIn.OutputPostprocess)
+	builder = builder.SetLanguageRouting(groupIn.LanguageRouting)
+	builder = builder.SetStreamReplay(groupIn.StreamReplay)
+	builder = builder.SetCapabilityCheck(groupIn.CapabilityCheck)
+	builder = builder.SetAutoContinuation(groupIn.AutoContinuation)
 
 	// 设置支持的模型系列（始终设置，空数组表示不限制）
 	builder = builder.SetSupportedModelScopes(groupIn.SupportedModelScopes)
@@ -164,10 +181,12 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
 		SetModelsListConfig(groupIn.ModelsListConfig).
 		SetRpmLimit(groupIn.RPMLimit).
+		SetReservedConcurrencyRatio(groupIn.ReservedConcurrencyRatio).
 		SetPeakRateEnabled(groupIn.PeakRateEnabled).
 		SetPeakStart(groupIn.PeakStart).
 		SetPeakEnd(groupIn.PeakEnd).
-		SetPeakRateMultiplier(groupIn.PeakRateMultiplier)
+		SetPeakRateMultiplier(groupIn.PeakRateMultiplier).
+		SetRequestSurcharge(groupIn.RequestSurcharge)
 
 	// 显式处理可空字段：nil 需要 clear，非 nil 需要 set。
 	if groupIn.DailyLimitUSD != nil {
@@ -236,6 +255,29 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		builder = builder.ClearModelRouting()
 	}
 
+	// 过载降级映射：空时清除
+	if len(groupIn.OverloadFallbackModels) > 0 {
+		builder = builder.SetOverloadFallbackModels(groupIn.OverloadFallbackModels)
+	} else {
+		builder = builder.ClearOverloadFallbackModels()
+	}
+
+	// 长上下文路由映射：空时清除
+	if len(groupIn.ContextOverflowModels) > 0 {
+		builder = builder.SetContextOverflowModels(groupIn.ContextOverflowModels)
+	} else {
+		builder = builder.ClearContextOverflowModels()
+	}
+	builder = builder.SetContextOverflowReject(groupIn.ContextOverflowReject)
+	builder = builder.SetContextTruncationStrategy(groupIn.ContextTruncationStrategy)
+	builder = builder.SetAccountSelectionStrategy(groupIn.AccountSelectionStrategy)
+	builder = builder.SetImageMaxEdge(groupIn.ImageMaxEdge).SetImageMaxBytes(groupIn.ImageMaxBytes)
+	builder = builder.SetOutputPostprocess(groupIn.OutputPostprocess)
+	builder = builder.SetLanguageRouting(groupIn.LanguageRouting)
+	builder = builder.SetStreamReplay(groupIn.StreamReplay)
+	builder = builder.SetCapabilityCheck(groupIn.CapabilityCheck)
+	builder = builder.SetAutoContinuation(groupIn.AutoContinuation)
+
 	// 处理 SupportedModelScopes（始终设置，空数组表示不限制）
 	builder = builder.SetSupportedModelScopes(groupIn.SupportedModelScopes)
 
@@ -877,6 +919,109 @@ func (r *groupRepository) BindAccountsToGroup(ctx context.Context, groupID int64
 	return nil
 }
 
+// ListAccountTiers 列出分组内全部账号的故障转移层级
+func (r *groupRepository) ListAccountTiers(ctx context.Context, groupID int64) ([]service.GroupAccountTier, error) {
+	rows, err := r.sql.QueryContext(ctx,
+		"SELECT account_id, tier FROM account_groups WHERE group_id = $1 ORDER BY tier, priority, account_id",
+		groupID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	tiers := []service.GroupAccountTier{}
+	for rows.Next() {
+		var item service.GroupAccountTier
+		if err := rows.Scan(&item.AccountID, &item.Tier); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+// SetAccountTiers 批量更新分组内账号的故障转移层级（仅更新已存在的绑定）
+func (r *groupRepository) SetAccountTiers(ctx context.Context, groupID int64, tiers []service.GroupAccountTier) error {
+	if len(tiers) == 0 {
+		return nil
+	}
+	accountIDs := make([]int64, 0, len(tiers))
+	values := make([]int64, 0, len(tiers))
+	for _, item := range tiers {
+		accountIDs = append(accountIDs, item.AccountID)
+		values = append(values, int64(item.Tier))
+	}
+	_, err := r.sql.ExecContext(ctx,
+		`UPDATE account_groups AS ag
+		 SET tier = v.tier
+		 FROM (SELECT unnest($2::bigint[]) AS account_id, unnest($3::smallint[]) AS tier) AS v
+		 WHERE ag.group_id = $1 AND ag.account_id = v.account_id`,
+		groupID,
+		pq.Array(accountIDs),
+		pq.Array(values),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := enqueueSchedulerOutbox(ctx, r.sql, service.SchedulerOutboxEventGroupChanged, nil, &groupID, nil); err != nil {
+		logger.LegacyPrintf("repository.group", "[SchedulerOutbox] enqueue set account tiers failed: group=%d err=%v", groupID, err)
+	}
+	return nil
+}
+
+// ListAccountConcurrencyShares 列出分组内全部账号的并发份额
+func (r *groupRepository) ListAccountConcurrencyShares(ctx context.Context, groupID int64) ([]service.GroupAccountConcurrencyShare, error) {
+	rows, err := r.sql.QueryContext(ctx,
+		"SELECT account_id, concurrency_share FROM account_groups WHERE group_id = $1 ORDER BY priority, account_id",
+		groupID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	shares := []service.GroupAccountConcurrencyShare{}
+	for rows.Next() {
+		var item service.GroupAccountConcurrencyShare
+		if err := rows.Scan(&item.AccountID, &item.ConcurrencyShare); err != nil {
+			return nil, err
+		}
+		shares = append(shares, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// SetAccountConcurrencyShares 批量更新分组内账号的并发份额（仅更新已存在的绑定）
+func (r *groupRepository) SetAccountConcurrencyShares(ctx context.Context, groupID int64, shares []service.GroupAccountConcurrencyShare) error {
+	if len(shares) == 0 {
+		return nil
+	}
+	accountIDs := make([]int64, 0, len(shares))
+	values := make([]int64, 0, len(shares))
+	for _, item := range shares {
+		accountIDs = append(accountIDs, item.AccountID)
+		values = append(values, int64(item.ConcurrencyShare))
+	}
+	_, err := r.sql.ExecContext(ctx,
+		`UPDATE account_groups AS ag
+		 SET concurrency_share = v.concurrency_share
+		 FROM (SELECT unnest($2::bigint[]) AS account_id, unnest($3::smallint[]) AS concurrency_share) AS v
+		 WHERE ag.group_id = $1 AND ag.account_id = v.account_id`,
+		groupID,
+		pq.Array(accountIDs),
+		pq.Array(values),
+	)
+	return err
+}
+
 // UpdateSortOrders 批量更新分组排序
 func (r *groupRepository) UpdateSortOrders(ctx context.Context, updates []service.GroupSortOrderUpdate) error {
 	if len(updates) == 0 {
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupRepositoryListAccountTiers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account_id, tier FROM account_groups WHERE group_id = $1")).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "tier"}).AddRow(11, 0).AddRow(12, 2))
+
+	tiers, err := newGroupRepositoryWithSQL(nil, db).ListAccountTiers(context.Background(), 5)
+	require.NoError(t, err)
+	require.Equal(t, []service.GroupAccountTier{{AccountID: 11, Tier: 0}, {AccountID: 12, Tier: 2}}, tiers)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepositorySetAccountTiers_UpdatesAndEnqueuesGroupRebuild(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE account_groups AS ag")).
+		WithArgs(int64(5), pq.Array([]int64{11, 12}), pq.Array([]int64{1, 2})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO scheduler_outbox")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = newGroupRepositoryWithSQL(nil, db).SetAccountTiers(context.Background(), 5, []service.GroupAccountTier{
+		{AccountID: 11, Tier: service.AccountGroupTierSecondary},
+		{AccountID: 12, Tier: service.AccountGroupTierEmergency},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepositoryAccountConcurrencyShares(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account_id, concurrency_share FROM account_groups WHERE group_id = $1")).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "concurrency_share"}).AddRow(11, 70).AddRow(12, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SET concurrency_share = v.concurrency_share")).
+		WithArgs(int64(5), pq.Array([]int64{11}), pq.Array([]int64{30})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := newGroupRepositoryWithSQL(nil, db)
+	shares, err := repo.ListAccountConcurrencyShares(context.Background(), 5)
+	require.NoError(t, err)
+	require.Equal(t, []service.GroupAccountConcurrencyShare{{AccountID: 11, ConcurrencyShare: 70}, {AccountID: 12, ConcurrencyShare: 0}}, shares)
+
+	require.NoError(t, repo.SetAccountConcurrencyShares(context.Background(), 5, []service.GroupAccountConcurrencyShare{{AccountID: 11, ConcurrencyShare: 30}}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -22,6 +22,9 @@ import (
 	"github.com/klauspost/compress/zstd"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/cassette"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyurl"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyutil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
@@ -95,6 +98,7 @@ type upstreamClientEntry struct {
 	protocolMode string       // 协议模式（default/openai_h1/openai_h2/openai_h1_fallback）
 	lastUsed     int64        // 最后使用时间戳（纳秒），用于 LRU 淘汰
 	inFlight     int64        // 当前进行中的请求数，>0 时不可淘汰
+	warm         warmTargets  // 近期访问过的上游主机，供热备连接预热
 }
 
 type openAIHTTP2FallbackState struct {
@@ -127,6 +131,26 @@ type httpUpstreamService struct {
 	clients map[string]*upstreamClientEntry // 客户端缓存池，key 由隔离策略决定
 	// OpenAI 走 HTTP/HTTPS 代理时的 H2->H1 回退状态（key=标准化 proxyKey）
 	openAIHTTP2Fallbacks sync.Map
+	// 镜像模式的上游覆盖地址（nil 表示不覆盖）
+	upstreamOverride *url.URL
+	// 连接复用与热备统计
+	connStats  upstreamConnStats
+	statsSince time.Time
+	// 可选的请求/响应观察者（启动后注入，存放 httpUpstreamObserverRef）
+	observer atomic.Value
+	// 可选的响应检查器（启动后注入，存放 httpUpstreamInspectorRef）
+	inspector atomic.Value
+	// 上游交互录制/回放（upstream_cassette），至多一个非 nil
+	cassetteRecorder *cassette.Recorder
+	cassettePlayer   *cassette.Player
+}
+
+type httpUpstreamObserverRef struct {
+	observer service.HTTPUpstreamObserver
+}
+
+type httpUpstreamInspectorRef struct {
+	inspector service.HTTPUpstreamResponseInspector
 }
 
 // NewHTTPUpstream 创建通用 HTTP 上游服务
@@ -138,9 +162,52 @@ type httpUpstreamService struct {
 // 返回:
 //   - service.HTTPUpstream 接口实现
 func NewHTTPUpstream(cfg *config.Config) service.HTTPUpstream {
-	return &httpUpstreamService{
-		cfg:     cfg,
-		clients: make(map[string]*upstreamClientEntry),
+	s := &httpUpstreamService{
+		cfg:              cfg,
+		clients:          make(map[string]*upstreamClientEntry),
+		upstreamOverride: parseUpstreamOverride(cfg),
+		statsSince:       time.Now(),
+	}
+	s.cassetteRecorder, s.cassettePlayer = parseUpstreamCassette(cfg)
+	if s.warmEnabled() {
+		go s.runConnectionWarmer()
+	}
+	return s
+}
+
+// observeUpstreamMetrics 记录上游请求到拿到响应头的耗时；平台取自网关选号时写入 ctx 的 ctxkey.Platform。
+func observeUpstreamMetrics(req *http.Request, resp *http.Response, startedAt time.Time) {
+	if req == nil || !metrics.Enabled() {
+		return
+	}
+	platform, _ := req.Context().Value(ctxkey.Platform).(string)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	metrics.ObserveUpstreamRequest(platform, status, time.Since(startedAt))
+}
+
+// SetObserver 注入上游请求观察者（如上游请求 ID 关联记录），传 nil 取消。
+func (s *httpUpstreamService) SetObserver(observer service.HTTPUpstreamObserver) {
+	s.observer.Store(httpUpstreamObserverRef{observer: observer})
+}
+
+func (s *httpUpstreamService) loadObserver() service.HTTPUpstreamObserver {
+	ref, _ := s.observer.Load().(httpUpstreamObserverRef)
+	return ref.observer
+}
+
+// SetResponseInspector 注入上游响应检查器（如格式漂移采样），传 nil 取消。
+func (s *httpUpstreamService) SetResponseInspector(inspector service.HTTPUpstreamResponseInspector) {
+	s.inspector.Store(httpUpstreamInspectorRef{inspector: inspector})
+}
+
+// inspectResponse 在响应解压后调用检查器，使其看到与业务层一致的响应体。
+func (s *httpUpstreamService) inspectResponse(req *http.Request, resp *http.Response) {
+	ref, _ := s.inspector.Load().(httpUpstreamInspectorRef)
+	if ref.inspector != nil {
+		ref.inspector.InspectUpstreamResponse(req, resp)
 	}
 }
 
@@ -161,6 +228,20 @@ func NewHTTPUpstream(cfg *config.Config) service.HTTPUpstream {
 //   - 调用方必须关闭 resp.Body，否则会导致 inFlight 计数泄漏
 //   - inFlight > 0 的客户端不会被淘汰，确保活跃请求不被中断
 func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	// 转换测试夹具干跑：只截获最终上游请求，不发出
+	if intercepted, err := service.InterceptUpstreamDryRun(req); intercepted {
+		return nil, err
+	}
+	// 回放模式直接返回录制的响应，不发出真实上游请求
+	if s.cassettePlayer != nil {
+		return s.cassettePlayer.Respond(req)
+	}
+	// 观察者需看到改写前的原始上游地址
+	observer := s.loadObserver()
+	if observer != nil && req != nil {
+		observer.BeforeUpstreamRequest(req)
+	}
+	proxyURL = s.applyUpstreamOverride(req, proxyURL)
 	if err := s.validateRequestHost(req); err != nil {
 		return nil, err
 	}
@@ -176,7 +257,11 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 	}
 
 	// 执行请求
-	resp, err := entry.client.Do(req)
+	startedAt := time.Now()
+	spanReq, span := startUpstreamSpan(req, accountID)
+	resp, err := entry.client.Do(s.traceUpstreamRequest(spanReq, entry))
+	endUpstreamSpan(span, resp, err)
+	observeUpstreamMetrics(req, resp, startedAt)
 	if err != nil {
 		s.recordOpenAIHTTP2Failure(profile, entry.protocolMode, entry.proxyKey, err)
 		// 请求失败，立即减少计数
@@ -185,9 +270,16 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 		return nil, err
 	}
 	s.recordOpenAIHTTP2Success(profile, entry.protocolMode, entry.proxyKey)
+	if observer != nil {
+		observer.AfterUpstreamResponse(req, resp, accountID)
+	}
 
 	// 如果上游返回了压缩内容，解压后再交给业务层
 	decompressResponseBody(resp)
+	// 追踪中的请求旁路复制解压后的上游响应
+	service.CaptureAPIKeyTraceUpstreamResponse(req, resp, accountID)
+	s.inspectResponse(req, resp)
+	s.recordCassette(req, resp)
 
 	// 包装响应体，在关闭时自动减少计数并更新时间戳
 	// 这确保了流式响应（如 SSE）在完全读取前不会被淘汰
@@ -207,6 +299,17 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 	if profile == nil {
 		return s.Do(req, proxyURL, accountID, accountConcurrency)
 	}
+	if intercepted, err := service.InterceptUpstreamDryRun(req); intercepted {
+		return nil, err
+	}
+	if s.cassettePlayer != nil {
+		return s.cassettePlayer.Respond(req)
+	}
+	observer := s.loadObserver()
+	if observer != nil && req != nil {
+		observer.BeforeUpstreamRequest(req)
+	}
+	proxyURL = s.applyUpstreamOverride(req, proxyURL)
 	upstreamProfile := service.HTTPUpstreamProfileDefault
 	if req != nil {
 		upstreamProfile = service.HTTPUpstreamProfileFromContext(req.Context())
@@ -232,15 +335,25 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 		return nil, err
 	}
 
-	resp, err := entry.client.Do(req)
+	startedAt := time.Now()
+	spanReq, span := startUpstreamSpan(req, accountID)
+	resp, err := entry.client.Do(s.traceUpstreamRequest(spanReq, entry))
+	endUpstreamSpan(span, resp, err)
+	observeUpstreamMetrics(req, resp, startedAt)
 	if err != nil {
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
 		slog.Debug("tls_fingerprint_request_failed", "account_id", accountID, "error", err)
 		return nil, err
 	}
+	if observer != nil {
+		observer.AfterUpstreamResponse(req, resp, accountID)
+	}
 
 	decompressResponseBody(resp)
+	service.CaptureAPIKeyTraceUpstreamResponse(req, resp, accountID)
+	s.inspectResponse(req, resp)
+	s.recordCassette(req, resp)
 
 	resp.Body = wrapTrackedBody(resp.Body, func() {
 		atomic.AddInt64(&entry.inFlight, -1)
@@ -323,6 +436,7 @@ func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID i
 		s.mu.Unlock()
 		return nil, fmt.Errorf("build TLS fingerprint transport: %w", err)
 	}
+	s.applyWarmTransportSettings(transport)
 
 	client := &http.Client{Transport: transport}
 	if s.shouldValidateResolvedIP() {
@@ -476,6 +590,7 @@ func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, a
 		s.mu.Unlock()
 		return nil, fmt.Errorf("build transport: %w", err)
 	}
+	s.applyWarmTransportSettings(transport)
 	client := &http.Client{Transport: transport}
 	if s.shouldValidateResolvedIP() {
 		client.CheckRedirect = s.redirectChecker
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/cassette"
+)
+
+// parseUpstreamCassette 按 upstream_cassette 配置创建录制器或回放器（二者至多一个非 nil）。
+// 回放模式下 cassette 加载失败时使用空回放器：宁可让请求失败，也不把流量发往真实上游。
+func parseUpstreamCassette(cfg *config.Config) (*cassette.Recorder, *cassette.Player) {
+	if cfg == nil {
+		return nil, nil
+	}
+	c := cfg.UpstreamCassette
+	dir := strings.TrimSpace(c.Dir)
+	switch strings.ToLower(strings.TrimSpace(c.Mode)) {
+	case config.UpstreamCassetteModeRecord:
+		recorder, err := cassette.NewRecorder(dir, c.MaxBodyBytes)
+		if err != nil {
+			slog.Error("upstream cassette recording disabled", "dir", dir, "error", err)
+			return nil, nil
+		}
+		slog.Warn("upstream cassette recording enabled", "dir", dir)
+		return recorder, nil
+	case config.UpstreamCassetteModeReplay:
+		player, err := cassette.LoadPlayer(dir)
+		if err != nil {
+			slog.Error("upstream cassette load failed, all upstream requests will fail", "dir", dir, "error", err)
+			return nil, cassette.NewPlayer(nil)
+		}
+		slog.Warn("upstream cassette replay enabled, no real upstream requests will be sent", "dir", dir)
+		return nil, player
+	default:
+		return nil, nil
+	}
+}
+
+// recordCassette 录制模式下旁路复制解压后的上游响应。
+func (s *httpUpstreamService) recordCassette(req *http.Request, resp *http.Response) {
+	if s.cassetteRecorder != nil {
+		s.cassetteRecorder.Record(req, resp)
+	}
+}
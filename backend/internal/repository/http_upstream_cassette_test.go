@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/cassette"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPUpstreamCassetteRecordThenReplay(t *testing.T) {
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Set-Cookie", "sid=secret")
+		_, _ = w.Write([]byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer mock.Close()
+
+	dir := t.TempDir()
+	recordUp := NewHTTPUpstream(&config.Config{UpstreamCassette: config.UpstreamCassetteConfig{Mode: config.UpstreamCassetteModeRecord, Dir: dir}})
+	body := []byte(`{"model":"claude","stream":true}`)
+	req, err := http.NewRequest(http.MethodPost, mock.URL+"/v1/messages", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Api-Key", "sk-live")
+	resp, err := recordUp.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	recorded, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	raw, err := os.ReadFile(dir + "/" + entries[0].Name())
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "sk-live")
+	require.NotContains(t, string(raw), "sid=secret")
+
+	// 回放时目标地址不可达也能拿到录制的响应
+	replayUp := NewHTTPUpstream(&config.Config{UpstreamCassette: config.UpstreamCassetteConfig{Mode: config.UpstreamCassetteModeReplay, Dir: dir}})
+	req, err = http.NewRequest(http.MethodPost, "http://127.0.0.1:1/v1/messages", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp, err = replayUp.Do(req, "http://127.0.0.1:1", 1, 1)
+	require.NoError(t, err)
+	replayed, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	require.Equal(t, string(recorded), string(replayed))
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+}
+
+func TestHTTPUpstreamCassetteReplayNeverFallsBackToNetwork(t *testing.T) {
+	hit := false
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+	defer mock.Close()
+
+	up := NewHTTPUpstream(&config.Config{UpstreamCassette: config.UpstreamCassetteConfig{Mode: config.UpstreamCassetteModeReplay, Dir: t.TempDir() + "/missing"}})
+	req, err := http.NewRequest(http.MethodGet, mock.URL+"/v1/models", nil)
+	require.NoError(t, err)
+	_, err = up.Do(req, "", 1, 1)
+	require.ErrorIs(t, err, cassette.ErrNoInteraction)
+	require.False(t, hit)
+}
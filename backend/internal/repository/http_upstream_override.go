@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// UpstreamOriginalHostHeader 镜像模式改写上游地址时携带的原始目标 Host，供 mock 上游区分平台。
+const UpstreamOriginalHostHeader = "X-Sub2API-Original-Host"
+
+func parseUpstreamOverride(cfg *config.Config) *url.URL {
+	if cfg == nil || !cfg.ConfigSync.IsMirror() {
+		return nil
+	}
+	raw := strings.TrimSpace(cfg.ConfigSync.UpstreamOverrideURL)
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		slog.Warn("config_sync.upstream_override_url ignored", "url", raw, "error", err)
+		return nil
+	}
+	slog.Info("upstream override enabled for mirror mode", "target", parsed.Scheme+"://"+parsed.Host)
+	return parsed
+}
+
+// applyUpstreamOverride 镜像模式下将请求改写到覆盖地址：保留原路径与查询参数（拼接在覆盖地址路径之后），
+// 并改为直连，避免经由账号代理访问 mock 上游。返回实际使用的代理地址。
+func (s *httpUpstreamService) applyUpstreamOverride(req *http.Request, proxyURL string) string {
+	target := s.upstreamOverride
+	if target == nil || req == nil || req.URL == nil {
+		return proxyURL
+	}
+	originalHost := req.URL.Host
+	rewritten := *req.URL
+	rewritten.Scheme = target.Scheme
+	rewritten.Host = target.Host
+	rewritten.User = target.User
+	if prefix := strings.TrimRight(target.Path, "/"); prefix != "" {
+		rewritten.Path = prefix + "/" + strings.TrimLeft(req.URL.Path, "/")
+		rewritten.RawPath = ""
+	}
+	req.URL = &rewritten
+	req.Host = ""
+	if originalHost != "" {
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		req.Header.Set(UpstreamOriginalHostHeader, originalHost)
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPUpstreamMirrorOverrideRedirectsToMockUpstream(t *testing.T) {
+	var gotPath, gotQuery, gotOriginalHost string
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		gotOriginalHost = r.Header.Get(UpstreamOriginalHostHeader)
+		_, _ = w.Write([]byte("mocked"))
+	}))
+	defer mock.Close()
+
+	cfg := &config.Config{ConfigSync: config.ConfigSyncConfig{
+		Role:                config.ConfigSyncRoleMirror,
+		UpstreamOverrideURL: mock.URL + "/mock/",
+	}}
+	up := NewHTTPUpstream(cfg)
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages?beta=true", nil)
+	require.NoError(t, err)
+	// 账号代理在镜像模式下被忽略，不可达的代理不应影响请求
+	resp, err := up.Do(req, "http://127.0.0.1:1", 1, 1)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	require.Equal(t, "mocked", string(body))
+	require.Equal(t, "/mock/v1/messages", gotPath)
+	require.Equal(t, "beta=true", gotQuery)
+	require.Equal(t, "api.anthropic.com", gotOriginalHost)
+}
+
+func TestHTTPUpstreamOverrideRequiresMirrorRole(t *testing.T) {
+	cfg := &config.Config{ConfigSync: config.ConfigSyncConfig{
+		Role:                config.ConfigSyncRoleReplica,
+		UpstreamOverrideURL: "http://127.0.0.1:9",
+	}}
+	require.Nil(t, parseUpstreamOverride(cfg))
+	require.Nil(t, parseUpstreamOverride(nil))
+}
@@ -250,6 +250,48 @@ func (s *HTTPUpstreamSuite) TestDo_WithoutProxy_GoesDirect() {
 	require.Equal(s.T(), "direct", string(b), "unexpected body")
 }
 
+type recordingUpstreamObserver struct {
+	before    int
+	accountID int64
+	requestID string
+}
+
+func (o *recordingUpstreamObserver) BeforeUpstreamRequest(req *http.Request) {
+	o.before++
+	req.Header.Set("X-Observed", "1")
+}
+
+func (o *recordingUpstreamObserver) AfterUpstreamResponse(req *http.Request, resp *http.Response, accountID int64) {
+	o.accountID = accountID
+	o.requestID = resp.Header.Get("x-request-id")
+}
+
+// TestDo_ObserverSeesRequestAndResponse 验证观察者可改写请求头并在响应头到达时收到回调
+func (s *HTTPUpstreamSuite) TestDo_ObserverSeesRequestAndResponse() {
+	upstream := newLocalTestServer(s.T(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_up_1")
+		_, _ = io.WriteString(w, r.Header.Get("X-Observed"))
+	}))
+	s.T().Cleanup(upstream.Close)
+
+	up := NewHTTPUpstream(s.cfg)
+	observer := &recordingUpstreamObserver{}
+	setter, ok := up.(service.HTTPUpstreamObserverSetter)
+	require.True(s.T(), ok, "expected HTTPUpstreamObserverSetter")
+	setter.SetObserver(observer)
+
+	req, err := http.NewRequest(http.MethodGet, upstream.URL+"/o", nil)
+	require.NoError(s.T(), err, "NewRequest")
+	resp, err := up.Do(req, "", 7, 1)
+	require.NoError(s.T(), err, "Do")
+	defer func() { _ = resp.Body.Close() }()
+	b, _ := io.ReadAll(resp.Body)
+	require.Equal(s.T(), "1", string(b), "observer header should reach upstream")
+	require.Equal(s.T(), 1, observer.before)
+	require.Equal(s.T(), int64(7), observer.accountID)
+	require.Equal(s.T(), "req_up_1", observer.requestID)
+}
+
 // TestDo_WithHTTPProxy_UsesProxy 测试 HTTP 代理功能
 // 验证请求通过代理服务器转发，使用绝对 URI 格式
 func (s *HTTPUpstreamSuite) TestDo_WithHTTPProxy_UsesProxy() {
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startUpstreamSpan 为一次上游请求开启 client span，返回携带 span 的请求副本，仅用于发出请求；
+// 不向上游注入 traceparent，避免把内部 trace 透传给第三方 AI 服务。
+func startUpstreamSpan(req *http.Request, accountID int64) (*http.Request, trace.Span) {
+	if req == nil || req.URL == nil {
+		return req, trace.SpanFromContext(context.Background())
+	}
+	ctx, span := tracing.Tracer().Start(req.Context(), "upstream.request",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+			semconv.URLPath(req.URL.Path),
+		),
+	)
+	if !span.IsRecording() {
+		return req, span
+	}
+	if accountID > 0 {
+		span.SetAttributes(tracing.AttrAccountID.Int64(accountID))
+	}
+	if id, _ := req.Context().Value(ctxkey.ClientRequestID).(string); strings.TrimSpace(id) != "" {
+		span.SetAttributes(tracing.AttrClientRequestID.String(strings.TrimSpace(id)))
+	}
+	if platform, _ := req.Context().Value(ctxkey.Platform).(string); platform != "" {
+		span.SetAttributes(tracing.AttrPlatform.String(platform))
+	}
+	return req.WithContext(ctx), span
+}
+
+// endUpstreamSpan 在拿到响应头（或请求失败）时结束 span，记录状态码与上游请求 ID。
+func endUpstreamSpan(span trace.Span, resp *http.Response, err error) {
+	if resp != nil && span.IsRecording() {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		if id := upstreamRequestIDFromHeader(resp.Header); id != "" {
+			span.SetAttributes(tracing.AttrUpstreamRequestID.String(id))
+		}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+	}
+	tracing.EndSpan(span, err)
+}
+
+func upstreamRequestIDFromHeader(h http.Header) string {
+	for _, name := range []string{"x-request-id", "request-id"} {
+		if id := strings.TrimSpace(h.Get(name)); id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestHTTPUpstreamDoRecordsClientSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	var gotTraceparent string
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+		w.Header().Set("Request-Id", "req_upstream_1")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer mock.Close()
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "gateway.forward")
+	ctx = context.WithValue(ctx, ctxkey.ClientRequestID, "client-req-1")
+	ctx = context.WithValue(ctx, ctxkey.Platform, "anthropic")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, mock.URL+"/v1/messages", nil)
+	require.NoError(t, err)
+
+	up := NewHTTPUpstream(&config.Config{})
+	resp, err := up.Do(req, "", 7, 1)
+	require.NoError(t, err)
+	_, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	parent.End()
+
+	require.Empty(t, gotTraceparent, "trace context must not leak to upstream providers")
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	require.Equal(t, "upstream.request", span.Name())
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	require.Equal(t, int64(7), attrs[tracing.AttrAccountID].AsInt64())
+	require.Equal(t, "client-req-1", attrs[tracing.AttrClientRequestID].AsString())
+	require.Equal(t, "anthropic", attrs[tracing.AttrPlatform].AsString())
+	require.Equal(t, "req_upstream_1", attrs[tracing.AttrUpstreamRequestID].AsString())
+	require.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const (
+	// warmRequestTimeout 单次预热请求超时，慢代理下也不应拖住预热轮次
+	warmRequestTimeout = 15 * time.Second
+	// warmMaxTargetsPerClient 每个客户端记录的上游主机上限
+	warmMaxTargetsPerClient = 4
+	// warmHTTP2PingTimeout HTTP/2 keepalive ping 未收到响应时关闭连接的超时
+	warmHTTP2PingTimeout = 15 * time.Second
+)
+
+// upstreamConnStats 上游连接复用计数（原子操作）。
+type upstreamConnStats struct {
+	requests             atomic.Int64
+	reusedConns          atomic.Int64
+	newConns             atomic.Int64
+	connSetupNanos       atomic.Int64
+	warmRounds           atomic.Int64
+	warmRequests         atomic.Int64
+	warmFailures         atomic.Int64
+	warmConnsEstablished atomic.Int64
+}
+
+// warmTargets 客户端近期访问过的上游主机（scheme://host）。
+type warmTargets struct {
+	mu      sync.Mutex
+	targets []string
+}
+
+func (t *warmTargets) remember(u *url.URL) {
+	if u == nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	target := u.Scheme + "://" + u.Host
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, existing := range t.targets {
+		if existing == target {
+			return
+		}
+	}
+	if len(t.targets) >= warmMaxTargetsPerClient {
+		t.targets = t.targets[1:]
+	}
+	t.targets = append(t.targets, target)
+}
+
+func (t *warmTargets) snapshot() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.targets...)
+}
+
+// warmEnabled 是否启用热备连接。
+func (s *httpUpstreamService) warmEnabled() bool {
+	return s.cfg != nil && s.cfg.Gateway.WarmConnections.Enabled
+}
+
+// applyWarmTransportSettings 启用热备时为 HTTP/2 连接开启空闲 keepalive ping，
+// 避免代理/NAT 静默断开长时间空闲的连接。
+func (s *httpUpstreamService) applyWarmTransportSettings(transport *http.Transport) {
+	if !s.warmEnabled() || transport == nil {
+		return
+	}
+	transport.HTTP2 = &http.HTTP2Config{
+		SendPingTimeout: time.Duration(s.cfg.Gateway.WarmConnections.IntervalSeconds) * time.Second,
+		PingTimeout:     warmHTTP2PingTimeout,
+	}
+}
+
+// traceUpstreamRequest 记录目标主机并挂载连接复用统计。
+func (s *httpUpstreamService) traceUpstreamRequest(req *http.Request, entry *upstreamClientEntry) *http.Request {
+	if req == nil || req.URL == nil {
+		return req
+	}
+	entry.warm.remember(req.URL)
+	s.connStats.requests.Add(1)
+	var getConnAt time.Time
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) { getConnAt = time.Now() },
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				s.connStats.reusedConns.Add(1)
+				return
+			}
+			s.connStats.newConns.Add(1)
+			if !getConnAt.IsZero() {
+				s.connStats.connSetupNanos.Add(int64(time.Since(getConnAt)))
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// ConnStats 返回上游连接复用统计快照。
+func (s *httpUpstreamService) ConnStats() service.HTTPUpstreamConnStats {
+	stats := service.HTTPUpstreamConnStats{
+		Since:                s.statsSince,
+		WarmEnabled:          s.warmEnabled(),
+		Requests:             s.connStats.requests.Load(),
+		ReusedConns:          s.connStats.reusedConns.Load(),
+		NewConns:             s.connStats.newConns.Load(),
+		WarmRounds:           s.connStats.warmRounds.Load(),
+		WarmRequests:         s.connStats.warmRequests.Load(),
+		WarmFailures:         s.connStats.warmFailures.Load(),
+		WarmConnsEstablished: s.connStats.warmConnsEstablished.Load(),
+	}
+	if total := stats.ReusedConns + stats.NewConns; total > 0 {
+		stats.ReuseRate = float64(stats.ReusedConns) / float64(total)
+	}
+	if stats.NewConns > 0 {
+		stats.AvgConnSetupMs = float64(s.connStats.connSetupNanos.Load()) / float64(stats.NewConns) / float64(time.Millisecond)
+	}
+	s.mu.RLock()
+	stats.CachedClients = len(s.clients)
+	s.mu.RUnlock()
+	return stats
+}
+
+// runConnectionWarmer 按间隔预热近期活跃的客户端，随进程生命周期运行。
+func (s *httpUpstreamService) runConnectionWarmer() {
+	ticker := time.NewTicker(time.Duration(s.cfg.Gateway.WarmConnections.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.warmConnections(context.Background(), time.Now())
+	}
+}
+
+// warmConnections 对 activeWithin 内有真实请求的客户端，向其访问过的每个上游主机并发发送
+// standby_connections 个 HEAD 请求：空闲连接被复用并刷新空闲计时，不足时新建连接留在连接池中。
+// 预热不更新 lastUsed，确保长期无真实流量的客户端仍会按空闲 TTL 被回收。
+func (s *httpUpstreamService) warmConnections(ctx context.Context, now time.Time) {
+	activeWithin := time.Duration(s.cfg.Gateway.WarmConnections.ActiveWithinSeconds) * time.Second
+	standby := max(s.cfg.Gateway.WarmConnections.StandbyConnections, 1)
+
+	type warmJob struct {
+		entry   *upstreamClientEntry
+		targets []string
+	}
+	var jobs []warmJob
+	s.mu.RLock()
+	for _, entry := range s.clients {
+		if now.Sub(time.Unix(0, atomic.LoadInt64(&entry.lastUsed))) > activeWithin {
+			continue
+		}
+		if targets := entry.warm.snapshot(); len(targets) > 0 {
+			// 预热期间占用 inFlight，防止客户端被淘汰关闭
+			atomic.AddInt64(&entry.inFlight, 1)
+			jobs = append(jobs, warmJob{entry: entry, targets: targets})
+		}
+	}
+	s.mu.RUnlock()
+	if len(jobs) == 0 {
+		return
+	}
+	s.connStats.warmRounds.Add(1)
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job warmJob) {
+			defer wg.Done()
+			defer atomic.AddInt64(&job.entry.inFlight, -1)
+			var inner sync.WaitGroup
+			for _, target := range job.targets {
+				for i := 0; i < standby; i++ {
+					inner.Add(1)
+					go func(target string) {
+						defer inner.Done()
+						s.warmOnce(ctx, job.entry, target)
+					}(target)
+				}
+			}
+			inner.Wait()
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (s *httpUpstreamService) warmOnce(ctx context.Context, entry *upstreamClientEntry, target string) {
+	s.connStats.warmRequests.Add(1)
+	reqCtx, cancel := context.WithTimeout(ctx, warmRequestTimeout)
+	defer cancel()
+	reqCtx = httptrace.WithClientTrace(reqCtx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if !info.Reused {
+				s.connStats.warmConnsEstablished.Add(1)
+			}
+		},
+	})
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodHead, target+"/", nil)
+	if err != nil {
+		s.connStats.warmFailures.Add(1)
+		return
+	}
+	resp, err := entry.client.Do(req)
+	if err != nil {
+		s.connStats.warmFailures.Add(1)
+		slog.Debug("upstream_warm_request_failed", "target", target, "error", err)
+		return
+	}
+	// 读完并关闭响应体，连接才会回到空闲池
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
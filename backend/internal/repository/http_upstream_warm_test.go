@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newWarmTestUpstream(t *testing.T) *httpUpstreamService {
+	t.Helper()
+	cfg := &config.Config{
+		Security: config.SecurityConfig{URLAllowlist: config.URLAllowlistConfig{AllowPrivateHosts: true}},
+	}
+	cfg.Gateway.WarmConnections = config.GatewayWarmConnectionsConfig{
+		Enabled:             true,
+		IntervalSeconds:     3600, // 测试中手动触发预热
+		StandbyConnections:  1,
+		ActiveWithinSeconds: 600,
+	}
+	svc, ok := NewHTTPUpstream(cfg).(*httpUpstreamService)
+	require.True(t, ok)
+	return svc
+}
+
+func doWarmTestRequest(t *testing.T, svc *httpUpstreamService, url string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url+"/v1/messages", nil)
+	require.NoError(t, err)
+	resp, err := svc.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestHTTPUpstreamWarmConnections(t *testing.T) {
+	var heads, posts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+		} else {
+			posts.Add(1)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	svc := newWarmTestUpstream(t)
+	doWarmTestRequest(t, svc, server.URL)
+
+	stats := svc.ConnStats()
+	require.True(t, stats.WarmEnabled)
+	require.Equal(t, int64(1), stats.Requests)
+	require.Equal(t, int64(1), stats.NewConns)
+	require.Equal(t, 1, stats.CachedClients)
+
+	// 预热复用已有空闲连接，且不刷新客户端的活跃时间
+	var entry *upstreamClientEntry
+	for _, e := range svc.clients {
+		entry = e
+	}
+	lastUsed := atomic.LoadInt64(&entry.lastUsed)
+	svc.warmConnections(context.Background(), time.Now())
+	require.Equal(t, int32(1), heads.Load())
+	require.Equal(t, lastUsed, atomic.LoadInt64(&entry.lastUsed))
+	require.Equal(t, int64(0), atomic.LoadInt64(&entry.inFlight))
+
+	doWarmTestRequest(t, svc, server.URL)
+	stats = svc.ConnStats()
+	require.Equal(t, int64(1), stats.WarmRounds)
+	require.Equal(t, int64(1), stats.WarmRequests)
+	require.Equal(t, int64(0), stats.WarmFailures)
+	require.Equal(t, int64(1), stats.ReusedConns)
+	require.InDelta(t, 0.5, stats.ReuseRate, 1e-9)
+
+	// 超出活跃窗口的客户端不再预热
+	svc.warmConnections(context.Background(), time.Now().Add(time.Hour))
+	require.Equal(t, int32(1), heads.Load())
+	require.Equal(t, int32(2), posts.Load())
+}
+
+func TestHTTPUpstreamWarmTransportSettings(t *testing.T) {
+	svc := newWarmTestUpstream(t)
+	entry := mustGetOrCreateClient(t, svc, "", 1, 1)
+	transport, ok := entry.client.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.NotNil(t, transport.HTTP2)
+	require.Equal(t, time.Hour, transport.HTTP2.SendPingTimeout)
+
+	disabled, ok := NewHTTPUpstream(&config.Config{}).(*httpUpstreamService)
+	require.True(t, ok)
+	transport, ok = mustGetOrCreateClient(t, disabled, "", 1, 1).client.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.Nil(t, transport.HTTP2)
+}
+
+func TestWarmTargetsRemember(t *testing.T) {
+	var targets warmTargets
+	for _, raw := range []string{"https://a.example/v1", "https://a.example/v2", "http://b.example", "ftp://c.example", "https://c.example", "https://d.example", "https://e.example"} {
+		req, err := http.NewRequest(http.MethodGet, raw, nil)
+		require.NoError(t, err)
+		targets.remember(req.URL)
+	}
+	require.Equal(t, []string{"http://b.example", "https://c.example", "https://d.example", "https://e.example"}, targets.snapshot())
+}
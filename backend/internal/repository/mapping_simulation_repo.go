@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type mappingSimulationRepository struct {
+	db *sql.DB
+}
+
+// NewMappingSimulationRepository 创建映射/路由变更模拟所需的流量聚合仓储（直接聚合 usage_logs）。
+func NewMappingSimulationRepository(db *sql.DB) service.MappingSimulationRepository {
+	return &mappingSimulationRepository{db: db}
+}
+
+func (r *mappingSimulationRepository) AggregateRoutedTraffic(ctx context.Context, start, end time.Time, accountIDs, groupIDs []int64, limit int) ([]service.MappingSimulationTraffic, error) {
+	requestedExpr := resolveModelDimensionExpressionWithAlias(usagestats.ModelSourceRequested, "ul")
+	upstreamExpr := resolveModelDimensionExpressionWithAlias(usagestats.ModelSourceUpstream, "ul")
+	query := `
+		SELECT
+			ul.account_id,
+			ul.group_id,
+			` + requestedExpr + ` AS requested_model,
+			` + upstreamExpr + ` AS upstream_model,
+			COUNT(*) AS requests,
+			MAX(ul.created_at) AS last_seen_at
+		FROM usage_logs ul
+		WHERE ul.created_at >= $1 AND ul.created_at < $2
+			AND (ul.account_id = ANY($3) OR ul.group_id = ANY($4))
+		GROUP BY 1, 2, 3, 4
+		ORDER BY COUNT(*) DESC
+		LIMIT $5
+	`
+	rows, err := r.db.QueryContext(ctx, query, start.UTC(), end.UTC(), pq.Array(accountIDs), pq.Array(groupIDs), limit)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate routed traffic: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.MappingSimulationTraffic, 0)
+	for rows.Next() {
+		var (
+			row     service.MappingSimulationTraffic
+			groupID sql.NullInt64
+		)
+		if err := rows.Scan(&row.AccountID, &groupID, &row.RequestedModel, &row.UpstreamModel, &row.Requests, &row.LastSeenAt); err != nil {
+			return nil, err
+		}
+		if groupID.Valid {
+			v := groupID.Int64
+			row.GroupID = &v
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappingSimulationRepositoryAggregateRoutedTraffic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewMappingSimulationRepository(db)
+	end := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+	lastSeen := end.Add(-time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("(ul.account_id = ANY($3) OR ul.group_id = ANY($4))")).
+		WithArgs(start, end, pq.Array([]int64{3}), pq.Array([]int64{7}), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "group_id", "requested_model", "upstream_model", "requests", "last_seen_at"}).
+			AddRow(int64(3), int64(7), "gpt-5-mini", "gpt-5", int64(12), lastSeen).
+			AddRow(int64(3), nil, "gpt-5.1", "gpt-5.1", int64(2), lastSeen))
+
+	rows, err := repo.AggregateRoutedTraffic(context.Background(), start, end, []int64{3}, []int64{7}, 100)
+
+	require.NoError(t, err)
+	groupID := int64(7)
+	require.Equal(t, []service.MappingSimulationTraffic{
+		{AccountID: 3, GroupID: &groupID, RequestedModel: "gpt-5-mini", UpstreamModel: "gpt-5", Requests: 12, LastSeenAt: lastSeen},
+		{AccountID: 3, RequestedModel: "gpt-5.1", UpstreamModel: "gpt-5.1", Requests: 2, LastSeenAt: lastSeen},
+	}, rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type modelPriceRepository struct {
+	db *sql.DB
+}
+
+func NewModelPriceRepository(db *sql.DB) service.ModelPriceRepository {
+	return &modelPriceRepository{db: db}
+}
+
+const modelPriceColumns = `id, model, input_price, output_price, cache_write_price, cache_read_price, effective_from, effective_to, note, created_at, updated_at`
+
+func (r *modelPriceRepository) Create(ctx context.Context, price *service.ModelPrice) (*service.ModelPrice, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO model_prices (model, input_price, output_price, cache_write_price, cache_read_price, effective_from, effective_to, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING `+modelPriceColumns,
+		price.Model, price.InputPrice, price.OutputPrice, price.CacheWritePrice, price.CacheReadPrice,
+		price.EffectiveFrom, price.EffectiveTo, price.Note)
+	created, err := scanModelPrice(row)
+	return created, translatePersistenceError(err, nil, service.ErrModelPriceExists)
+}
+
+func (r *modelPriceRepository) GetByID(ctx context.Context, id int64) (*service.ModelPrice, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+modelPriceColumns+` FROM model_prices WHERE id = $1`, id)
+	return scanModelPrice(row)
+}
+
+func (r *modelPriceRepository) Update(ctx context.Context, price *service.ModelPrice) (*service.ModelPrice, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE model_prices
+		SET model = $2, input_price = $3, output_price = $4, cache_write_price = $5, cache_read_price = $6,
+			effective_from = $7, effective_to = $8, note = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+modelPriceColumns,
+		price.ID, price.Model, price.InputPrice, price.OutputPrice, price.CacheWritePrice, price.CacheReadPrice,
+		price.EffectiveFrom, price.EffectiveTo, price.Note)
+	updated, err := scanModelPrice(row)
+	return updated, translatePersistenceError(err, nil, service.ErrModelPriceExists)
+}
+
+func (r *modelPriceRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM model_prices WHERE id = $1`, id)
+	return err
+}
+
+func (r *modelPriceRepository) List(ctx context.Context, model string) ([]*service.ModelPrice, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+modelPriceColumns+`
+		FROM model_prices
+		WHERE ($1 = '' OR model = $1)
+		ORDER BY model ASC, effective_from DESC
+	`, model)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	prices := []*service.ModelPrice{}
+	for rows.Next() {
+		p, err := scanModelPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+	return prices, rows.Err()
+}
+
+func (r *modelPriceRepository) ListUsageForBackfill(ctx context.Context, start, end time.Time, model string, afterID int64, limit int) ([]service.UsageCostRow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, LOWER(model), created_at, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, rate_multiplier
+		FROM usage_logs
+		WHERE created_at >= $1 AND created_at < $2
+			AND ($3 = '' OR LOWER(model) = $3)
+			AND (billing_mode IS NULL OR billing_mode = 'token')
+			AND id > $4
+		ORDER BY id ASC
+		LIMIT $5
+	`, start, end, model, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []service.UsageCostRow{}
+	for rows.Next() {
+		var row service.UsageCostRow
+		if err := rows.Scan(
+			&row.ID, &row.Model, &row.CreatedAt, &row.InputTokens, &row.OutputTokens,
+			&row.CacheCreationTokens, &row.CacheReadTokens, &row.RateMultiplier,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *modelPriceRepository) UpdateUsageCosts(ctx context.Context, updates []service.UsageCostUpdate) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE usage_logs
+		SET input_cost = $2, output_cost = $3, cache_creation_cost = $4, cache_read_cost = $5,
+			total_cost = $6, actual_cost = $7
+		WHERE id = $1
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, u := range updates {
+		if _, err = stmt.ExecContext(ctx, u.ID, u.InputCost, u.OutputCost, u.CacheCreationCost, u.CacheReadCost, u.TotalCost, u.ActualCost); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func scanModelPrice(row scannable) (*service.ModelPrice, error) {
+	p := &service.ModelPrice{}
+	var effectiveTo sql.NullTime
+	if err := row.Scan(
+		&p.ID, &p.Model, &p.InputPrice, &p.OutputPrice, &p.CacheWritePrice, &p.CacheReadPrice,
+		&p.EffectiveFrom, &effectiveTo, &p.Note, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrModelPriceNotFound
+		}
+		return nil, err
+	}
+	if effectiveTo.Valid {
+		p.EffectiveTo = &effectiveTo.Time
+	}
+	return p, nil
+}
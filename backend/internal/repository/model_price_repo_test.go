@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelPriceRepositoryList_ScansOptionalEffectiveTo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewModelPriceRepository(db)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "model", "input_price", "output_price", "cache_write_price", "cache_read_price",
+		"effective_from", "effective_to", "note", "created_at", "updated_at"}
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE ($1 = '' OR model = $1)")).
+		WithArgs("claude-sonnet-4-6").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(2), "claude-sonnet-4-6", 3e-6, 15e-6, 3.75e-6, 0.3e-6, to, nil, "", from, from).
+			AddRow(int64(1), "claude-sonnet-4-6", 2e-6, 10e-6, 2.5e-6, 0.2e-6, from, to, "launch", from, from))
+
+	prices, err := repo.List(context.Background(), "claude-sonnet-4-6")
+
+	require.NoError(t, err)
+	require.Len(t, prices, 2)
+	require.Nil(t, prices[0].EffectiveTo)
+	require.NotNil(t, prices[1].EffectiveTo)
+	require.True(t, prices[1].EffectiveTo.Equal(to))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModelPriceRepositoryUpdateUsageCosts_UsesSingleTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewModelPriceRepository(db)
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usage_logs"))
+	prep.ExpectExec().WithArgs(int64(1), 1.0, 2.0, 0.0, 0.0, 3.0, 3.0).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs(int64(2), 0.5, 0.5, 0.0, 0.0, 1.0, 2.0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.UpdateUsageCosts(context.Background(), []service.UsageCostUpdate{
+		{ID: 1, InputCost: 1, OutputCost: 2, TotalCost: 3, ActualCost: 3},
+		{ID: 2, InputCost: 0.5, OutputCost: 0.5, TotalCost: 1, ActualCost: 2},
+	})
+
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type openAIBatchRepository struct {
+	db *sql.DB
+}
+
+func NewOpenAIBatchRepository(db *sql.DB) service.OpenAIBatchRepository {
+	return &openAIBatchRepository{db: db}
+}
+
+const openAIBatchObjectColumns = `id, object_type, object_id, public_id, user_id, api_key_id, account_id, group_id, model, status, purpose, filename, bytes, settled_at, created_at, updated_at`
+
+// Create 冲突时做一次空更新以便 RETURNING 拿到首次登记的 id / public_id。
+func (r *openAIBatchRepository) Create(ctx context.Context, obj *service.OpenAIBatchObject) error {
+	var publicID sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO openai_batch_objects (object_type, object_id, public_id, user_id, api_key_id, account_id, group_id, model, status, purpose, filename, bytes, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+		ON CONFLICT (object_type, object_id) DO UPDATE SET object_type = EXCLUDED.object_type
+		RETURNING id, public_id`,
+		obj.ObjectType, obj.ObjectID, obj.PublicID, obj.UserID, obj.APIKeyID, obj.AccountID, obj.GroupID, obj.Model, obj.Status,
+		obj.Purpose, obj.Filename, obj.Bytes).Scan(&obj.ID, &publicID)
+	if err != nil {
+		return err
+	}
+	obj.PublicID = publicID.String
+	return nil
+}
+
+func (r *openAIBatchRepository) Get(ctx context.Context, objectType, objectID string) (*service.OpenAIBatchObject, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+openAIBatchObjectColumns+` FROM openai_batch_objects WHERE object_type = $1 AND object_id = $2`, objectType, objectID)
+	return scanOpenAIBatchObject(row)
+}
+
+func (r *openAIBatchRepository) GetByPublicID(ctx context.Context, objectType, publicID string) (*service.OpenAIBatchObject, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+openAIBatchObjectColumns+` FROM openai_batch_objects WHERE object_type = $1 AND public_id = $2`, objectType, publicID)
+	return scanOpenAIBatchObject(row)
+}
+
+func (r *openAIBatchRepository) ListFiles(ctx context.Context, apiKeyID int64, filter service.OpenAIFileListFilter) ([]*service.OpenAIBatchObject, error) {
+	conditions := []string{"object_type = $1", "api_key_id = $2", "public_id IS NOT NULL"}
+	args := []any{service.OpenAIBatchObjectFile, apiKeyID}
+	if filter.Purpose != "" {
+		args = append(args, filter.Purpose)
+		conditions = append(conditions, fmt.Sprintf("purpose = $%d", len(args)))
+	}
+	order, cmp := "DESC", "<"
+	if filter.Ascending {
+		order, cmp = "ASC", ">"
+	}
+	if filter.After != "" {
+		args = append(args, filter.After)
+		conditions = append(conditions, fmt.Sprintf("id %s (SELECT id FROM openai_batch_objects WHERE object_type = $1 AND public_id = $%d)", cmp, len(args)))
+	}
+	args = append(args, filter.Limit)
+	query := `SELECT ` + openAIBatchObjectColumns + ` FROM openai_batch_objects WHERE ` + strings.Join(conditions, " AND ") +
+		fmt.Sprintf(" ORDER BY id %s LIMIT $%d", order, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var files []*service.OpenAIBatchObject
+	for rows.Next() {
+		file, err := scanOpenAIBatchObject(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+func scanOpenAIBatchObject(row interface{ Scan(dest ...any) error }) (*service.OpenAIBatchObject, error) {
+	obj := &service.OpenAIBatchObject{}
+	var (
+		publicID  sql.NullString
+		groupID   sql.NullInt64
+		settledAt sql.NullTime
+	)
+	err := row.Scan(&obj.ID, &obj.ObjectType, &obj.ObjectID, &publicID, &obj.UserID, &obj.APIKeyID, &obj.AccountID, &groupID,
+		&obj.Model, &obj.Status, &obj.Purpose, &obj.Filename, &obj.Bytes, &settledAt, &obj.CreatedAt, &obj.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrOpenAIBatchObjectNotFound
+		}
+		return nil, err
+	}
+	obj.PublicID = publicID.String
+	if groupID.Valid {
+		obj.GroupID = &groupID.Int64
+	}
+	if settledAt.Valid {
+		obj.SettledAt = &settledAt.Time
+	}
+	return obj, nil
+}
+
+func (r *openAIBatchRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE openai_batch_objects SET status = $2, updated_at = NOW() WHERE id = $1`, id, status)
+	return err
+}
+
+func (r *openAIBatchRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM openai_batch_objects WHERE id = $1`, id)
+	return err
+}
+
+func (r *openAIBatchRepository) ClaimSettlement(ctx context.Context, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE openai_batch_objects SET settled_at = NOW(), updated_at = NOW() WHERE id = $1 AND settled_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *openAIBatchRepository) ReleaseSettlement(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE openai_batch_objects SET settled_at = NULL, updated_at = NOW() WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+var openAIBatchObjectTestColumns = []string{"id", "object_type", "object_id", "public_id", "user_id", "api_key_id", "account_id", "group_id", "model", "status", "purpose", "filename", "bytes", "settled_at", "created_at", "updated_at"}
+
+func TestOpenAIBatchRepositoryCreate_KeepsFirstBinding(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewOpenAIBatchRepository(db)
+	groupID := int64(3)
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (object_type, object_id) DO UPDATE SET object_type = EXCLUDED.object_type")).
+		WithArgs(service.OpenAIBatchObjectFile, "file-1", "file-new", int64(1), int64(5), int64(7), &groupID, "gpt-4o-mini", "processed", "batch", "in.jsonl", int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "public_id"}).AddRow(int64(9), "file-first"))
+
+	obj := &service.OpenAIBatchObject{
+		ObjectType: service.OpenAIBatchObjectFile,
+		ObjectID:   "file-1",
+		PublicID:   "file-new",
+		UserID:     1,
+		APIKeyID:   5,
+		AccountID:  7,
+		GroupID:    &groupID,
+		Model:      "gpt-4o-mini",
+		Status:     "processed",
+		Purpose:    "batch",
+		Filename:   "in.jsonl",
+		Bytes:      42,
+	}
+	require.NoError(t, repo.Create(context.Background(), obj))
+	require.Equal(t, int64(9), obj.ID)
+	require.Equal(t, "file-first", obj.PublicID, "existing binding wins")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenAIBatchRepositoryGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewOpenAIBatchRepository(db)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM openai_batch_objects WHERE object_type = $1 AND object_id = $2")).
+		WithArgs(service.OpenAIBatchObjectBatch, "batch_1").
+		WillReturnRows(sqlmock.NewRows(openAIBatchObjectTestColumns).
+			AddRow(int64(9), "batch", "batch_1", nil, int64(1), int64(5), int64(7), nil, "gpt-4o-mini", "completed", "", "", int64(0), now, now, now))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM openai_batch_objects WHERE object_type = $1 AND object_id = $2")).
+		WithArgs(service.OpenAIBatchObjectBatch, "batch_missing").
+		WillReturnRows(sqlmock.NewRows(openAIBatchObjectTestColumns))
+
+	obj, err := repo.Get(context.Background(), service.OpenAIBatchObjectBatch, "batch_1")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), obj.AccountID)
+	require.Nil(t, obj.GroupID)
+	require.NotNil(t, obj.SettledAt)
+	require.Empty(t, obj.PublicID)
+
+	_, err = repo.Get(context.Background(), service.OpenAIBatchObjectBatch, "batch_missing")
+	require.ErrorIs(t, err, service.ErrOpenAIBatchObjectNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenAIBatchRepositoryClaimSettlement_OnlyOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewOpenAIBatchRepository(db)
+	claim := regexp.QuoteMeta("SET settled_at = NOW(), updated_at = NOW() WHERE id = $1 AND settled_at IS NULL")
+	mock.ExpectExec(claim).WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(claim).WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := repo.ClaimSettlement(context.Background(), 9)
+	require.NoError(t, err)
+	require.True(t, claimed)
+	claimed, err = repo.ClaimSettlement(context.Background(), 9)
+	require.NoError(t, err)
+	require.False(t, claimed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenAIBatchRepositoryListFiles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewOpenAIBatchRepository(db)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE object_type = $1 AND api_key_id = $2 AND public_id IS NOT NULL AND purpose = $3 AND id > (SELECT id FROM openai_batch_objects WHERE object_type = $1 AND public_id = $4) ORDER BY id ASC LIMIT $5")).
+		WithArgs(service.OpenAIBatchObjectFile, int64(5), "batch", "file-after", 3).
+		WillReturnRows(sqlmock.NewRows(openAIBatchObjectTestColumns).
+			AddRow(int64(10), "file", "file-up", "file-pub", int64(1), int64(5), int64(7), nil, "", "processed", "batch", "in.jsonl", int64(42), nil, now, now))
+
+	files, err := repo.ListFiles(context.Background(), 5, service.OpenAIFileListFilter{Purpose: "batch", After: "file-after", Limit: 3, Ascending: true})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "file-pub", files[0].PublicID)
+	require.Equal(t, int64(42), files[0].Bytes)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
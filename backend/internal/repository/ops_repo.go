@@ -59,9 +59,10 @@ INSERT INTO ops_error_logs (
   attempted_key_prefix,
   deleted_key_owner_user_id,
   deleted_key_name,
-  api_key_prefix
+  api_key_prefix,
+  error_code
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42
 )`
 
 func NewOpsRepository(db *sql.DB) service.OpsRepository {
@@ -174,6 +175,7 @@ func opsInsertErrorLogArgs(input *service.OpsInsertErrorLogInput) []any {
 		opsNullInt64(input.DeletedKeyOwnerUserID),
 		opsNullString(input.DeletedKeyName),
 		opsNullString(input.APIKeyPrefix),
+		opsNullString(input.ErrorCode),
 	}
 }
 
@@ -208,6 +210,12 @@ func opsErrorLogsOrderBy(filter *service.OpsErrorLogFilter) string {
 	return fmt.Sprintf("%s %s, e.id %s", column, dir, dir)
 }
 
+// opsErrorTriageStatusExpr 处理状态：未显式设置（迁移前的行）时按 resolved 推导。
+const opsErrorTriageStatusExpr = "COALESCE(e.triage_status, CASE WHEN COALESCE(e.resolved, false) THEN 'resolved' ELSE 'open' END)"
+
+// opsErrorCommentCountExpr 错误日志的评论数（仅对返回的分页行求值）。
+const opsErrorCommentCountExpr = "(SELECT COUNT(*) FROM ops_error_comments c WHERE c.error_id = e.id)"
+
 func (r *opsRepository) ListErrorLogs(ctx context.Context, filter *service.OpsErrorLogFilter) (*service.OpsErrorLogList, error) {
 	if r == nil || r.db == nil {
 		return nil, fmt.Errorf("nil ops repository")
@@ -277,13 +285,19 @@ SELECT
   ak.deleted_at,
   COALESCE(e.deleted_key_name, ''),
   e.deleted_key_owner_user_id,
-  COALESCE(du.email, '')
+  COALESCE(du.email, ''),
+  COALESCE(e.error_code, ''),
+  ` + opsErrorTriageStatusExpr + `,
+  e.assignee_user_id,
+  COALESCE(ua.email, ''),
+  ` + opsErrorCommentCountExpr + `
 FROM ops_error_logs e
 LEFT JOIN accounts a ON e.account_id = a.id
 LEFT JOIN groups g ON e.group_id = g.id
 LEFT JOIN users u ON e.user_id = u.id
 LEFT JOIN users u2 ON e.resolved_by_user_id = u2.id
 LEFT JOIN users du ON e.deleted_key_owner_user_id = du.id
+LEFT JOIN users ua ON e.assignee_user_id = ua.id
 LEFT JOIN api_keys ak ON ak.id = e.api_key_id
 ` + where + `
 ORDER BY ` + opsErrorLogsOrderBy(filter) + `
@@ -316,6 +330,7 @@ LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
 		var deletedKeyName string
 		var deletedKeyOwnerID sql.NullInt64
 		var deletedKeyOwnerEmail string
+		var assigneeID sql.NullInt64
 		if err := rows.Scan(
 			&item.ID,
 			&item.CreatedAt,
@@ -355,9 +370,18 @@ LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
 			&deletedKeyName,
 			&deletedKeyOwnerID,
 			&deletedKeyOwnerEmail,
+			&item.ErrorCode,
+			&item.Status,
+			&assigneeID,
+			&item.AssigneeEmail,
+			&item.CommentCount,
 		); err != nil {
 			return nil, err
 		}
+		if assigneeID.Valid {
+			v := assigneeID.Int64
+			item.AssigneeUserID = &v
+		}
 		if resolvedAt.Valid {
 			t := resolvedAt.Time
 			item.ResolvedAt = &t
@@ -483,12 +507,18 @@ SELECT
   COALESCE(e.deleted_key_name, ''),
   COALESCE(e.api_key_prefix, ''),
   COALESCE(ak.name, ''),
-  ak.deleted_at
+  ak.deleted_at,
+  COALESCE(e.error_code, ''),
+  ` + opsErrorTriageStatusExpr + `,
+  e.assignee_user_id,
+  COALESCE(ua.email, ''),
+  ` + opsErrorCommentCountExpr + `
 FROM ops_error_logs e
 LEFT JOIN users u ON e.user_id = u.id
 LEFT JOIN accounts a ON e.account_id = a.id
 LEFT JOIN groups g ON e.group_id = g.id
 LEFT JOIN users du ON e.deleted_key_owner_user_id = du.id
+LEFT JOIN users ua ON e.assignee_user_id = ua.id
 LEFT JOIN api_keys ak ON ak.id = e.api_key_id
 WHERE e.id = $1
 LIMIT 1`
@@ -512,6 +542,7 @@ LIMIT 1`
 	var deletedKeyOwnerUserID sql.NullInt64
 	var detailAPIKeyName string
 	var detailAPIKeyDeletedAt sql.NullTime
+	var assigneeID sql.NullInt64
 
 	err := r.db.QueryRowContext(ctx, q, id).Scan(
 		&out.ID,
@@ -564,10 +595,19 @@ LIMIT 1`
 		&out.APIKeyPrefix,
 		&detailAPIKeyName,
 		&detailAPIKeyDeletedAt,
+		&out.ErrorCode,
+		&out.Status,
+		&assigneeID,
+		&out.AssigneeEmail,
+		&out.CommentCount,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if assigneeID.Valid {
+		v := assigneeID.Int64
+		out.AssigneeUserID = &v
+	}
 
 	out.StatusCode = int(statusCode.Int64)
 	if resolvedAt.Valid {
@@ -681,7 +721,8 @@ UPDATE ops_error_logs
 SET
   resolved = $2,
   resolved_at = $3,
-  resolved_by_user_id = $4
+  resolved_by_user_id = $4,
+  triage_status = CASE WHEN $2 THEN 'resolved' ELSE 'open' END
 WHERE id = $1`
 
 	at := sql.NullTime{}
@@ -703,6 +744,109 @@ WHERE id = $1`
 	return err
 }
 
+func (r *opsRepository) UpdateErrorTriage(ctx context.Context, errorID int64, status string, assigneeUserID *int64, actorUserID *int64) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	if errorID <= 0 {
+		return fmt.Errorf("invalid error id")
+	}
+
+	// 已关闭的行保持原 resolved_at / resolved_by（仅改负责人或在 resolved/ignored 间切换时不覆盖）；
+	// 重新打开时清空。SET 中引用的列均为更新前的值。
+	q := `
+UPDATE ops_error_logs
+SET
+  triage_status = $2,
+  assignee_user_id = $3,
+  resolved_at = CASE
+    WHEN NOT $4 THEN NULL
+    WHEN COALESCE(resolved, false) THEN COALESCE(resolved_at, NOW())
+    ELSE NOW()
+  END,
+  resolved_by_user_id = CASE
+    WHEN NOT $4 THEN NULL
+    WHEN COALESCE(resolved, false) THEN resolved_by_user_id
+    ELSE $5
+  END,
+  resolved = $4
+WHERE id = $1`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		q,
+		errorID,
+		status,
+		nullInt64(assigneeUserID),
+		service.IsOpsErrorTriageStatusClosed(status),
+		nullInt64(actorUserID),
+	)
+	return err
+}
+
+func (r *opsRepository) InsertErrorComment(ctx context.Context, comment *service.OpsErrorComment) (*service.OpsErrorComment, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if comment == nil || comment.ErrorID <= 0 {
+		return nil, fmt.Errorf("invalid comment")
+	}
+
+	out := *comment
+	if err := r.db.QueryRowContext(ctx, `
+INSERT INTO ops_error_comments (error_id, user_id, body)
+VALUES ($1, $2, $3)
+RETURNING id, created_at`,
+		comment.ErrorID,
+		nullInt64(comment.UserID),
+		comment.Body,
+	).Scan(&out.ID, &out.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *opsRepository) ListErrorComments(ctx context.Context, errorID int64) ([]*service.OpsErrorComment, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT
+  c.id,
+  c.error_id,
+  c.user_id,
+  COALESCE(u.email, ''),
+  c.body,
+  c.created_at
+FROM ops_error_comments c
+LEFT JOIN users u ON c.user_id = u.id
+WHERE c.error_id = $1
+ORDER BY c.created_at ASC, c.id ASC`, errorID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsErrorComment, 0)
+	for rows.Next() {
+		var item service.OpsErrorComment
+		var userID sql.NullInt64
+		if err := rows.Scan(&item.ID, &item.ErrorID, &userID, &item.UserEmail, &item.Body, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		if userID.Valid {
+			v := userID.Int64
+			item.UserID = &v
+		}
+		out = append(out, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *opsRepository) BatchInsertSystemLogs(ctx context.Context, inputs []*service.OpsInsertSystemLogInput) (int64, error) {
 	if r == nil || r.db == nil {
 		return 0, fmt.Errorf("nil ops repository")
@@ -1018,11 +1162,27 @@ func buildOpsErrorLogsWhere(filter *service.OpsErrorLogFilter) (string, []any) {
 			args = append(args, source)
 			clauses = append(clauses, "LOWER(COALESCE(e.error_source,'')) = $"+itoa(len(args)))
 		}
+		if code := strings.TrimSpace(strings.ToLower(filter.ErrorCode)); code != "" {
+			args = append(args, code)
+			clauses = append(clauses, "e.error_code = $"+itoa(len(args)))
+		}
 	}
 	if resolvedFilter != nil {
 		args = append(args, *resolvedFilter)
 		clauses = append(clauses, "COALESCE(e.resolved,false) = $"+itoa(len(args)))
 	}
+	if filter != nil {
+		if len(filter.Statuses) > 0 {
+			args = append(args, pq.Array(filter.Statuses))
+			clauses = append(clauses, opsErrorTriageStatusExpr+" = ANY($"+itoa(len(args))+")")
+		}
+		if filter.AssigneeUserID != nil && *filter.AssigneeUserID > 0 {
+			args = append(args, *filter.AssigneeUserID)
+			clauses = append(clauses, "e.assignee_user_id = $"+itoa(len(args)))
+		} else if filter.Unassigned {
+			clauses = append(clauses, "e.assignee_user_id IS NULL")
+		}
+	}
 
 	// View filter: errors vs excluded vs all.
 	// Excluded = business-limited errors (quota/concurrency/billing).
@@ -1140,6 +1300,11 @@ func buildOpsSystemLogsWhere(filter *service.OpsSystemLogFilter) (string, []any,
 			clauses = append(clauses, "COALESCE(l.component,'') = $"+itoa(len(args)))
 			hasConstraint = true
 		}
+		if v := strings.TrimSpace(filter.ComponentPrefix); v != "" {
+			args = append(args, escapeLikePattern(v)+"%")
+			clauses = append(clauses, "COALESCE(l.component,'') LIKE $"+itoa(len(args)))
+			hasConstraint = true
+		}
 		if v := strings.TrimSpace(filter.RequestID); v != "" {
 			args = append(args, v)
 			clauses = append(clauses, "COALESCE(l.request_id,'') = $"+itoa(len(args)))
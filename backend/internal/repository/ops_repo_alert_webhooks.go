@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const opsAlertWebhookColumns = `id, name, type, url, secret, chat_id, enabled, rule_ids, min_severity, notify_resolved, created_at, updated_at`
+
+func (r *opsRepository) ListAlertWebhooks(ctx context.Context) ([]*service.OpsAlertWebhook, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+opsAlertWebhookColumns+` FROM ops_alert_webhooks ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsAlertWebhook{}
+	for rows.Next() {
+		hook, err := scanOpsAlertWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) GetAlertWebhookByID(ctx context.Context, id int64) (*service.OpsAlertWebhook, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT `+opsAlertWebhookColumns+` FROM ops_alert_webhooks WHERE id = $1`, id)
+	return scanOpsAlertWebhook(row)
+}
+
+func (r *opsRepository) CreateAlertWebhook(ctx context.Context, input *service.OpsAlertWebhook) (*service.OpsAlertWebhook, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if input == nil {
+		return nil, fmt.Errorf("nil input")
+	}
+	ruleIDs, err := opsAlertWebhookRuleIDsJSON(input.RuleIDs)
+	if err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRowContext(ctx, `
+INSERT INTO ops_alert_webhooks (
+  name, type, url, secret, chat_id, enabled, rule_ids, min_severity, notify_resolved, created_at, updated_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+RETURNING `+opsAlertWebhookColumns,
+		strings.TrimSpace(input.Name),
+		input.Type,
+		input.URL,
+		input.Secret,
+		input.ChatID,
+		input.Enabled,
+		ruleIDs,
+		input.MinSeverity,
+		input.NotifyResolved,
+	)
+	return scanOpsAlertWebhook(row)
+}
+
+func (r *opsRepository) UpdateAlertWebhook(ctx context.Context, input *service.OpsAlertWebhook) (*service.OpsAlertWebhook, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if input == nil {
+		return nil, fmt.Errorf("nil input")
+	}
+	if input.ID <= 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+	ruleIDs, err := opsAlertWebhookRuleIDsJSON(input.RuleIDs)
+	if err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRowContext(ctx, `
+UPDATE ops_alert_webhooks
+SET name = $2, type = $3, url = $4, secret = $5, chat_id = $6, enabled = $7,
+    rule_ids = $8, min_severity = $9, notify_resolved = $10, updated_at = NOW()
+WHERE id = $1
+RETURNING `+opsAlertWebhookColumns,
+		input.ID,
+		strings.TrimSpace(input.Name),
+		input.Type,
+		input.URL,
+		input.Secret,
+		input.ChatID,
+		input.Enabled,
+		ruleIDs,
+		input.MinSeverity,
+		input.NotifyResolved,
+	)
+	return scanOpsAlertWebhook(row)
+}
+
+func (r *opsRepository) DeleteAlertWebhook(ctx context.Context, id int64) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM ops_alert_webhooks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *opsRepository) InsertAlertWebhookDelivery(ctx context.Context, delivery *service.OpsAlertWebhookDelivery) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil ops repository")
+	}
+	if delivery == nil {
+		return 0, fmt.Errorf("nil delivery")
+	}
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+INSERT INTO ops_alert_webhook_deliveries (
+  webhook_id, rule_id, event_id, event_status, success, status_code, error, duration_ms, created_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+RETURNING id, created_at`,
+		delivery.WebhookID,
+		opsNullInt64(delivery.RuleID),
+		opsNullInt64(delivery.EventID),
+		delivery.EventStatus,
+		delivery.Success,
+		opsNullInt(delivery.StatusCode),
+		delivery.Error,
+		delivery.DurationMs,
+	).Scan(&id, &delivery.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	delivery.ID = id
+	return id, nil
+}
+
+func (r *opsRepository) ListAlertWebhookDeliveries(ctx context.Context, filter *service.OpsAlertWebhookDeliveryFilter) ([]*service.OpsAlertWebhookDelivery, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	limit := 50
+	clauses := []string{"1=1"}
+	args := []any{}
+	if filter != nil {
+		if filter.Limit > 0 && filter.Limit <= 500 {
+			limit = filter.Limit
+		}
+		if filter.WebhookID != nil && *filter.WebhookID > 0 {
+			args = append(args, *filter.WebhookID)
+			clauses = append(clauses, fmt.Sprintf("webhook_id = $%d", len(args)))
+		}
+		if filter.EventID != nil && *filter.EventID > 0 {
+			args = append(args, *filter.EventID)
+			clauses = append(clauses, fmt.Sprintf("event_id = $%d", len(args)))
+		}
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, webhook_id, rule_id, event_id, event_status, success, status_code, error, duration_ms, created_at
+FROM ops_alert_webhook_deliveries
+WHERE `+strings.Join(clauses, " AND ")+`
+ORDER BY created_at DESC, id DESC
+LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsAlertWebhookDelivery{}
+	for rows.Next() {
+		item := &service.OpsAlertWebhookDelivery{}
+		var (
+			ruleID     sql.NullInt64
+			eventID    sql.NullInt64
+			statusCode sql.NullInt64
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.WebhookID,
+			&ruleID,
+			&eventID,
+			&item.EventStatus,
+			&item.Success,
+			&statusCode,
+			&item.Error,
+			&item.DurationMs,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if ruleID.Valid {
+			v := ruleID.Int64
+			item.RuleID = &v
+		}
+		if eventID.Valid {
+			v := eventID.Int64
+			item.EventID = &v
+		}
+		if statusCode.Valid {
+			v := int(statusCode.Int64)
+			item.StatusCode = &v
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAlertWindowCounts 上游 429 口径与看板 upstream_429 一致；流内失败为 HTTP 200 上落库的流式错误，
+// 分母取窗口内流式成功请求数 + 流内失败数。
+func (r *opsRepository) GetAlertWindowCounts(ctx context.Context, filter *service.OpsAlertWindowFilter) (*service.OpsAlertWindowCounts, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil || filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, fmt.Errorf("start_time/end_time required")
+	}
+	start := filter.StartTime.UTC()
+	end := filter.EndTime.UTC()
+	if end.Sub(start) > 24*time.Hour {
+		return nil, fmt.Errorf("window too large")
+	}
+
+	dashFilter := &service.OpsDashboardFilter{Platform: filter.Platform, GroupID: filter.GroupID}
+
+	errorWhere, errorArgs, next := buildErrorWhere(dashFilter, start, end, 1)
+	if filter.AccountID != nil && *filter.AccountID > 0 {
+		errorArgs = append(errorArgs, *filter.AccountID)
+		errorWhere += fmt.Sprintf(" AND account_id = $%d", next)
+	}
+	out := &service.OpsAlertWindowCounts{}
+	if err := r.db.QueryRowContext(ctx, `
+SELECT
+  COALESCE(COUNT(*) FILTER (WHERE error_owner = 'provider' AND NOT is_business_limited AND COALESCE(upstream_status_code, status_code, 0) = 429), 0),
+  COALESCE(COUNT(*) FILTER (WHERE stream AND COALESCE(status_code, 0) < 400), 0)
+FROM ops_error_logs
+`+errorWhere, errorArgs...).Scan(&out.Upstream429Count, &out.StreamFaultCount); err != nil {
+		return nil, err
+	}
+
+	usageJoin, usageWhere, usageArgs, next := buildUsageWhere(dashFilter, start, end, 1)
+	if filter.AccountID != nil && *filter.AccountID > 0 {
+		usageArgs = append(usageArgs, *filter.AccountID)
+		usageWhere += fmt.Sprintf(" AND ul.account_id = $%d", next)
+	}
+	var streamSuccess int64
+	if err := r.db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM usage_logs ul
+`+usageJoin+`
+`+usageWhere+` AND ul.stream = TRUE`, usageArgs...).Scan(&streamSuccess); err != nil {
+		return nil, err
+	}
+	out.StreamRequestCount = streamSuccess + out.StreamFaultCount
+	return out, nil
+}
+
+func opsAlertWebhookRuleIDsJSON(ids []int64) (string, error) {
+	if len(ids) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func scanOpsAlertWebhook(row scannable) (*service.OpsAlertWebhook, error) {
+	hook := &service.OpsAlertWebhook{}
+	var ruleIDs []byte
+	if err := row.Scan(
+		&hook.ID,
+		&hook.Name,
+		&hook.Type,
+		&hook.URL,
+		&hook.Secret,
+		&hook.ChatID,
+		&hook.Enabled,
+		&ruleIDs,
+		&hook.MinSeverity,
+		&hook.NotifyResolved,
+		&hook.CreatedAt,
+		&hook.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	hook.SecretConfigured = hook.Secret != ""
+	hook.RuleIDs = []int64{}
+	if len(ruleIDs) > 0 {
+		_ = json.Unmarshal(ruleIDs, &hook.RuleIDs)
+	}
+	return hook, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsRepositoryGetAlertWindowCounts_AccountScope(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	end := time.Now().UTC()
+	start := end.Add(-5 * time.Minute)
+	accountID := int64(12)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ops_error_logs")).
+		WithArgs(start, end, "openai", accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"upstream_429", "stream_faults"}).AddRow(31, 3))
+	mock.ExpectQuery(regexp.QuoteMeta("ul.account_id = $4 AND ul.stream = TRUE")).
+		WithArgs(start, end, "openai", accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(57))
+
+	repo := &opsRepository{db: db}
+	counts, err := repo.GetAlertWindowCounts(context.Background(), &service.OpsAlertWindowFilter{
+		StartTime: start, EndTime: end, Platform: "openai", AccountID: &accountID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &service.OpsAlertWindowCounts{Upstream429Count: 31, StreamFaultCount: 3, StreamRequestCount: 60}, counts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpsRepositoryListAlertWebhooks_MarksSecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ops_alert_webhooks ORDER BY id ASC")).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "name", "type", "url", "secret", "chat_id", "enabled", "rule_ids", "min_severity", "notify_resolved", "created_at", "updated_at",
+		}).
+			AddRow(1, "tg", service.OpsAlertWebhookTypeTelegram, "https://api.telegram.org", "123:abc", "-100", true, []byte(`[3,5]`), "warning", true, now, now).
+			AddRow(2, "slack", service.OpsAlertWebhookTypeSlack, "https://hooks.slack.com/x", "", "", false, []byte(`[]`), "", false, now, now))
+
+	hooks, err := (&opsRepository{db: db}).ListAlertWebhooks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	require.True(t, hooks[0].SecretConfigured)
+	require.Equal(t, []int64{3, 5}, hooks[0].RuleIDs)
+	require.False(t, hooks[1].SecretConfigured)
+	require.Equal(t, []int64{}, hooks[1].RuleIDs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -46,3 +46,31 @@ func TestBuildOpsErrorLogsWhere_UserQueryUsesExistsSubquery(t *testing.T) {
 		t.Fatalf("where should include EXISTS user email condition: %s", where)
 	}
 }
+
+func TestBuildOpsErrorLogsWhere_TriageFilters(t *testing.T) {
+	assignee := int64(5)
+	filter := &service.OpsErrorLogFilter{
+		Statuses:       []string{"open", "investigating"},
+		AssigneeUserID: &assignee,
+		Unassigned:     true,
+	}
+
+	where, args := buildOpsErrorLogsWhere(filter)
+	if len(args) != 2 {
+		t.Fatalf("args len = %d, want 2", len(args))
+	}
+	if !strings.Contains(where, opsErrorTriageStatusExpr+" = ANY($") {
+		t.Fatalf("where should match derived triage status: %s", where)
+	}
+	if !strings.Contains(where, "e.assignee_user_id = $") {
+		t.Fatalf("where should include assignee condition: %s", where)
+	}
+	if strings.Contains(where, "e.assignee_user_id IS NULL") {
+		t.Fatalf("explicit assignee should take precedence over unassigned: %s", where)
+	}
+
+	where, _ = buildOpsErrorLogsWhere(&service.OpsErrorLogFilter{Unassigned: true})
+	if !strings.Contains(where, "e.assignee_user_id IS NULL") {
+		t.Fatalf("where should include unassigned condition: %s", where)
+	}
+}
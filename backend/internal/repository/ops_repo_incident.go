@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// GetErrorFingerprints 在 [baseline_start, end) 上一次扫描，同时统计事故窗口 [start, end) 与基线窗口的错误数。
+func (r *opsRepository) GetErrorFingerprints(ctx context.Context, filter *service.OpsErrorFingerprintFilter) ([]*service.OpsErrorFingerprint, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("nil filter")
+	}
+	if filter.BaselineStartTime.IsZero() || filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, fmt.Errorf("baseline_start_time/start_time/end_time required")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	where, args, next := buildErrorWhere(&service.OpsDashboardFilter{
+		Platform: filter.Platform,
+		GroupID:  filter.GroupID,
+	}, filter.BaselineStartTime.UTC(), filter.EndTime.UTC(), 1)
+	args = append(args, filter.StartTime.UTC())
+	startArg := fmt.Sprintf("$%d", next)
+	args = append(args, limit)
+	limitArg := fmt.Sprintf("$%d", next+1)
+
+	q := `
+SELECT
+  COALESCE(error_phase, '') AS phase,
+  COALESCE(error_type, '') AS type,
+  COALESCE(error_code, '') AS error_code,
+  COALESCE(upstream_status_code, status_code, 0) AS status_code,
+  COALESCE(platform, '') AS platform,
+  COUNT(*) FILTER (WHERE created_at >= ` + startArg + `) AS cnt,
+  COUNT(*) FILTER (WHERE created_at < ` + startArg + `) AS baseline_cnt,
+  MIN(created_at) FILTER (WHERE created_at >= ` + startArg + `) AS first_seen,
+  MAX(created_at) FILTER (WHERE created_at >= ` + startArg + `) AS last_seen,
+  (ARRAY_AGG(COALESCE(error_message, '') ORDER BY created_at DESC) FILTER (WHERE created_at >= ` + startArg + `))[1] AS sample_message
+FROM ops_error_logs
+` + where + `
+  AND COALESCE(status_code, 0) >= 400
+GROUP BY 1, 2, 3, 4, 5
+HAVING COUNT(*) FILTER (WHERE created_at >= ` + startArg + `) > 0
+ORDER BY cnt DESC
+LIMIT ` + limitArg
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsErrorFingerprint, 0, limit)
+	for rows.Next() {
+		var item service.OpsErrorFingerprint
+		var sample sql.NullString
+		if err := rows.Scan(
+			&item.Phase,
+			&item.Type,
+			&item.ErrorCode,
+			&item.StatusCode,
+			&item.Platform,
+			&item.Count,
+			&item.BaselineCount,
+			&item.FirstSeen,
+			&item.LastSeen,
+			&sample,
+		); err != nil {
+			return nil, err
+		}
+		item.SampleMessage = sample.String
+		item.FirstSeen = item.FirstSeen.UTC()
+		item.LastSeen = item.LastSeen.UTC()
+		out = append(out, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
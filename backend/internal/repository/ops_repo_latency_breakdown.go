@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// GetLatencyBreakdown 按模型或账号聚合 TTFT 与生成阶段吞吐。
+//
+// 生成阶段吞吐按单请求计算 output_tokens / (duration_ms - first_token_ms) 后取平均，
+// 仅统计记录了 first_token_ms 的请求；端到端吞吐沿用 output_tokens / duration_ms 口径。
+func (r *opsRepository) GetLatencyBreakdown(ctx context.Context, filter *service.OpsLatencyBreakdownFilter) (*service.OpsLatencyBreakdownResponse, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("nil filter")
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, fmt.Errorf("start_time/end_time required")
+	}
+	if filter.StartTime.After(filter.EndTime) {
+		return nil, fmt.Errorf("start_time must be <= end_time")
+	}
+
+	dashboardFilter := &service.OpsDashboardFilter{
+		StartTime: filter.StartTime.UTC(),
+		EndTime:   filter.EndTime.UTC(),
+		Platform:  strings.TrimSpace(strings.ToLower(filter.Platform)),
+		GroupID:   filter.GroupID,
+	}
+	join, where, args, next := buildUsageWhere(dashboardFilter, dashboardFilter.StartTime, dashboardFilter.EndTime, 1)
+
+	groupBy := filter.GroupBy
+	keySelect := "ul.model AS model, NULL::bigint AS account_id, ''::text AS account_name"
+	keyGroup := "ul.model"
+	if groupBy == service.OpsLatencyBreakdownGroupByAccount {
+		join += " LEFT JOIN accounts lba ON lba.id = ul.account_id"
+		keySelect = "''::text AS model, ul.account_id AS account_id, COALESCE(MAX(lba.name), '') AS account_name"
+		keyGroup = "ul.account_id"
+	} else {
+		groupBy = service.OpsLatencyBreakdownGroupByModel
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	args = append(args, limit)
+
+	query := `
+SELECT
+  ` + keySelect + `,
+  COUNT(*)::bigint AS request_count,
+  COUNT(*) FILTER (WHERE ul.stream)::bigint AS stream_request_count,
+  COALESCE(SUM(ul.output_tokens), 0)::bigint AS total_output_tokens,
+  AVG(ul.duration_ms)::float8 AS avg_duration_ms,
+  AVG(ul.first_token_ms)::float8 AS avg_ttft_ms,
+  percentile_cont(0.50) WITHIN GROUP (ORDER BY ul.first_token_ms) FILTER (WHERE ul.first_token_ms IS NOT NULL) AS p50_ttft_ms,
+  percentile_cont(0.95) WITHIN GROUP (ORDER BY ul.first_token_ms) FILTER (WHERE ul.first_token_ms IS NOT NULL) AS p95_ttft_ms,
+  AVG(
+    CASE
+      WHEN ul.first_token_ms IS NOT NULL AND ul.duration_ms >= ul.first_token_ms
+      THEN (ul.duration_ms - ul.first_token_ms)
+    END
+  )::float8 AS avg_generation_ms,
+  AVG(
+    CASE
+      WHEN ul.first_token_ms IS NOT NULL AND ul.duration_ms > ul.first_token_ms AND ul.output_tokens > 0
+      THEN ul.output_tokens * 1000.0 / (ul.duration_ms - ul.first_token_ms)
+    END
+  )::float8 AS avg_output_tokens_per_sec,
+  AVG(
+    CASE
+      WHEN ul.duration_ms > 0 AND ul.output_tokens > 0
+      THEN ul.output_tokens * 1000.0 / ul.duration_ms
+    END
+  )::float8 AS avg_end_to_end_tokens_per_sec,
+  AVG(
+    CASE
+      WHEN ul.first_token_ms IS NOT NULL AND ul.duration_ms > 0
+      THEN LEAST(ul.first_token_ms::float8 / ul.duration_ms, 1.0)
+    END
+  )::float8 AS ttft_share
+FROM usage_logs ul
+` + join + `
+` + where + `
+GROUP BY ` + keyGroup + `
+ORDER BY request_count DESC
+LIMIT $` + fmt.Sprint(next)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.OpsLatencyBreakdownItem, 0, 32)
+	for rows.Next() {
+		item := &service.OpsLatencyBreakdownItem{}
+		var (
+			accountID                                      sql.NullInt64
+			avgDuration, avgTTFT, p50TTFT, p95TTFT, avgGen sql.NullFloat64
+			avgOutputTPS, avgEndToEndTPS, ttftShare        sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&item.Model,
+			&accountID,
+			&item.AccountName,
+			&item.RequestCount,
+			&item.StreamRequestCount,
+			&item.TotalOutputTokens,
+			&avgDuration,
+			&avgTTFT,
+			&p50TTFT,
+			&p95TTFT,
+			&avgGen,
+			&avgOutputTPS,
+			&avgEndToEndTPS,
+			&ttftShare,
+		); err != nil {
+			return nil, err
+		}
+		if accountID.Valid {
+			v := accountID.Int64
+			item.AccountID = &v
+		}
+		item.AvgDurationMs = nullFloat64Ptr(avgDuration)
+		item.AvgTTFTMs = nullFloat64Ptr(avgTTFT)
+		item.P50TTFTMs = nullFloat64Ptr(p50TTFT)
+		item.P95TTFTMs = nullFloat64Ptr(p95TTFT)
+		item.AvgGenerationMs = nullFloat64Ptr(avgGen)
+		item.AvgOutputTokensPerSec = nullFloat64Ptr(avgOutputTPS)
+		item.AvgEndToEndTokensPerSec = nullFloat64Ptr(avgEndToEndTPS)
+		item.TTFTShare = nullFloat64Ptr(ttftShare)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &service.OpsLatencyBreakdownResponse{
+		TimeRange: strings.TrimSpace(filter.TimeRange),
+		StartTime: dashboardFilter.StartTime,
+		EndTime:   dashboardFilter.EndTime,
+		Platform:  dashboardFilter.Platform,
+		GroupID:   dashboardFilter.GroupID,
+		GroupBy:   groupBy,
+		Items:     items,
+	}, nil
+}
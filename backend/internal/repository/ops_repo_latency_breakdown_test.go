@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+var latencyBreakdownColumns = []string{
+	"model",
+	"account_id",
+	"account_name",
+	"request_count",
+	"stream_request_count",
+	"total_output_tokens",
+	"avg_duration_ms",
+	"avg_ttft_ms",
+	"p50_ttft_ms",
+	"p95_ttft_ms",
+	"avg_generation_ms",
+	"avg_output_tokens_per_sec",
+	"avg_end_to_end_tokens_per_sec",
+	"ttft_share",
+}
+
+func TestOpsRepositoryGetLatencyBreakdown_ByModel(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &opsRepository{db: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	rows := sqlmock.NewRows(latencyBreakdownColumns).
+		AddRow("gpt-5", nil, "", int64(10), int64(8), int64(5000), 4200.0, 1200.0, 900.0, 3000.0, 3000.0, 80.5, 55.1, 0.3).
+		AddRow("gpt-5-mini", nil, "", int64(4), int64(0), int64(400), 800.0, nil, nil, nil, nil, nil, 120.0, nil)
+
+	mock.ExpectQuery(`GROUP BY ul\.model\s+ORDER BY request_count DESC\s+LIMIT \$3`).
+		WithArgs(start, end, 50).
+		WillReturnRows(rows)
+
+	resp, err := repo.GetLatencyBreakdown(context.Background(), &service.OpsLatencyBreakdownFilter{
+		TimeRange: "1h",
+		StartTime: start,
+		EndTime:   end,
+		GroupBy:   service.OpsLatencyBreakdownGroupByModel,
+	})
+	require.NoError(t, err)
+	require.Equal(t, service.OpsLatencyBreakdownGroupByModel, resp.GroupBy)
+	require.Len(t, resp.Items, 2)
+	require.Equal(t, "gpt-5", resp.Items[0].Model)
+	require.Nil(t, resp.Items[0].AccountID)
+	require.InDelta(t, 80.5, *resp.Items[0].AvgOutputTokensPerSec, 1e-9)
+	require.InDelta(t, 3000.0, *resp.Items[0].P95TTFTMs, 1e-9)
+	require.Nil(t, resp.Items[1].AvgTTFTMs)
+	require.Nil(t, resp.Items[1].AvgOutputTokensPerSec)
+	require.InDelta(t, 120.0, *resp.Items[1].AvgEndToEndTokensPerSec, 1e-9)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpsRepositoryGetLatencyBreakdown_ByAccountWithFilters(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &opsRepository{db: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	groupID := int64(3)
+
+	rows := sqlmock.NewRows(latencyBreakdownColumns).
+		AddRow("", int64(42), "acc-42", int64(7), int64(7), int64(700), 2000.0, 500.0, 400.0, 900.0, 1500.0, 66.0, 50.0, 0.25)
+
+	mock.ExpectQuery(`LEFT JOIN accounts lba ON lba\.id = ul\.account_id[\s\S]+GROUP BY ul\.account_id\s+ORDER BY request_count DESC\s+LIMIT \$5`).
+		WithArgs(start, end, groupID, "openai", 10).
+		WillReturnRows(rows)
+
+	resp, err := repo.GetLatencyBreakdown(context.Background(), &service.OpsLatencyBreakdownFilter{
+		StartTime: start,
+		EndTime:   end,
+		Platform:  " OpenAI ",
+		GroupID:   &groupID,
+		GroupBy:   service.OpsLatencyBreakdownGroupByAccount,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, service.OpsLatencyBreakdownGroupByAccount, resp.GroupBy)
+	require.Len(t, resp.Items, 1)
+	require.NotNil(t, resp.Items[0].AccountID)
+	require.Equal(t, int64(42), *resp.Items[0].AccountID)
+	require.Equal(t, "acc-42", resp.Items[0].AccountName)
+	require.InDelta(t, 0.25, *resp.Items[0].TTFTShare, 1e-9)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
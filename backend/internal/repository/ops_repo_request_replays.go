@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+func (r *opsRepository) InsertRequestReplay(ctx context.Context, replay *service.OpsRequestReplay) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil ops repository")
+	}
+	if replay == nil {
+		return 0, fmt.Errorf("nil replay")
+	}
+	headers := "{}"
+	if len(replay.ResponseHeaders) > 0 {
+		if b, err := json.Marshal(replay.ResponseHeaders); err == nil {
+			headers = string(b)
+		}
+	}
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+INSERT INTO ops_request_replays (
+  created_at, created_by, request_id, api_key_id, upstream_index, source_account_id, account_id,
+  method, url, status_code, duration_ms, response_headers, response_body, response_body_truncated, error_message
+) VALUES (NOW(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING id, created_at`,
+		opsNullInt64(&replay.CreatedBy),
+		replay.RequestID,
+		replay.APIKeyID,
+		replay.UpstreamIndex,
+		opsNullInt64(replay.SourceAccountID),
+		replay.AccountID,
+		replay.Method,
+		replay.URL,
+		replay.StatusCode,
+		replay.DurationMs,
+		headers,
+		replay.ResponseBody,
+		replay.ResponseBodyTruncated,
+		replay.ErrorMessage,
+	).Scan(&id, &replay.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *opsRepository) ListRequestReplays(ctx context.Context, requestID string, limit int) ([]*service.OpsRequestReplay, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	requestID = strings.TrimSpace(requestID)
+	if requestID == "" {
+		return []*service.OpsRequestReplay{}, nil
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT
+  id, created_at, COALESCE(created_by, 0), request_id, api_key_id, upstream_index,
+  source_account_id, account_id, method, url, status_code, duration_ms,
+  response_headers, response_body, response_body_truncated, error_message
+FROM ops_request_replays
+WHERE request_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2`, requestID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsRequestReplay, 0)
+	for rows.Next() {
+		item := &service.OpsRequestReplay{}
+		var (
+			sourceAccountID sql.NullInt64
+			headers         []byte
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.CreatedAt,
+			&item.CreatedBy,
+			&item.RequestID,
+			&item.APIKeyID,
+			&item.UpstreamIndex,
+			&sourceAccountID,
+			&item.AccountID,
+			&item.Method,
+			&item.URL,
+			&item.StatusCode,
+			&item.DurationMs,
+			&headers,
+			&item.ResponseBody,
+			&item.ResponseBodyTruncated,
+			&item.ErrorMessage,
+		); err != nil {
+			return nil, err
+		}
+		if sourceAccountID.Valid {
+			v := sourceAccountID.Int64
+			item.SourceAccountID = &v
+		}
+		if len(headers) > 0 {
+			_ = json.Unmarshal(headers, &item.ResponseHeaders)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+func (r *opsRepository) BatchInsertStreamTimings(ctx context.Context, inputs []*service.OpsStreamTiming) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil ops repository")
+	}
+	if len(inputs) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
+		"ops_stream_timings",
+		"created_at",
+		"request_id",
+		"client_request_id",
+		"account_id",
+		"platform",
+		"model",
+		"outcome",
+		"event_count",
+		"duration_ms",
+		"first_event_ms",
+		"max_upstream_gap_ms",
+		"max_relay_lag_ms",
+		"avg_relay_lag_ms",
+		"upstream_gap_histogram",
+		"stall_source",
+	))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var inserted int64
+	for _, input := range inputs {
+		if input == nil {
+			continue
+		}
+		createdAt := input.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now().UTC()
+		}
+		outcome := strings.TrimSpace(input.Outcome)
+		if outcome == "" {
+			outcome = "completed"
+		}
+		stallSource := strings.TrimSpace(input.StallSource)
+		if stallSource == "" {
+			stallSource = service.OpsStreamStallSourceNone
+		}
+		histogram := "{}"
+		if len(input.UpstreamGapHistogram) > 0 {
+			if b, err := json.Marshal(input.UpstreamGapHistogram); err == nil {
+				histogram = string(b)
+			}
+		}
+		var firstEventMs any = sql.NullInt64{}
+		if input.FirstEventMs != nil {
+			firstEventMs = *input.FirstEventMs
+		}
+		if _, err := stmt.ExecContext(
+			ctx,
+			createdAt.UTC(),
+			opsNullString(input.RequestID),
+			opsNullString(input.ClientRequestID),
+			opsNullInt64(input.AccountID),
+			opsNullString(input.Platform),
+			opsNullString(input.Model),
+			outcome,
+			input.EventCount,
+			input.DurationMs,
+			firstEventMs,
+			input.MaxUpstreamGapMs,
+			input.MaxRelayLagMs,
+			input.AvgRelayLagMs,
+			histogram,
+			stallSource,
+		); err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+			return inserted, err
+		}
+		inserted++
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		_ = tx.Rollback()
+		return inserted, err
+	}
+	if err := stmt.Close(); err != nil {
+		_ = tx.Rollback()
+		return inserted, err
+	}
+	if err := tx.Commit(); err != nil {
+		return inserted, err
+	}
+	return inserted, nil
+}
+
+func (r *opsRepository) ListStreamTimings(ctx context.Context, keys []string, limit int) ([]*service.OpsStreamTiming, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	cleaned := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k = strings.TrimSpace(k); k != "" {
+			cleaned = append(cleaned, k)
+		}
+	}
+	if len(cleaned) == 0 {
+		return []*service.OpsStreamTiming{}, nil
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT
+  id, created_at,
+  COALESCE(request_id, ''), COALESCE(client_request_id, ''),
+  account_id, COALESCE(platform, ''), COALESCE(model, ''), outcome,
+  event_count, duration_ms, first_event_ms,
+  max_upstream_gap_ms, max_relay_lag_ms, avg_relay_lag_ms,
+  upstream_gap_histogram, stall_source
+FROM ops_stream_timings
+WHERE request_id = ANY($1) OR client_request_id = ANY($1)
+ORDER BY created_at DESC, id DESC
+LIMIT $2`, pq.Array(cleaned), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsStreamTiming, 0)
+	for rows.Next() {
+		item := &service.OpsStreamTiming{}
+		var (
+			accountID    sql.NullInt64
+			firstEventMs sql.NullInt64
+			histogram    []byte
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.CreatedAt,
+			&item.RequestID,
+			&item.ClientRequestID,
+			&accountID,
+			&item.Platform,
+			&item.Model,
+			&item.Outcome,
+			&item.EventCount,
+			&item.DurationMs,
+			&firstEventMs,
+			&item.MaxUpstreamGapMs,
+			&item.MaxRelayLagMs,
+			&item.AvgRelayLagMs,
+			&histogram,
+			&item.StallSource,
+		); err != nil {
+			return nil, err
+		}
+		if accountID.Valid {
+			v := accountID.Int64
+			item.AccountID = &v
+		}
+		if firstEventMs.Valid {
+			v := firstEventMs.Int64
+			item.FirstEventMs = &v
+		}
+		item.UpstreamGapHistogram = map[string]int{}
+		if len(histogram) > 0 {
+			_ = json.Unmarshal(histogram, &item.UpstreamGapHistogram)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -599,8 +599,50 @@ LIMIT 20`
 		return nil, err
 	}
 
+	codes, err := r.getErrorCodeDistribution(ctx, where, args)
+	if err != nil {
+		return nil, err
+	}
+
 	return &service.OpsErrorDistributionResponse{
-		Total: total,
-		Items: items,
+		Total:      total,
+		Items:      items,
+		ErrorCodes: codes,
 	}, nil
 }
+
+// getErrorCodeDistribution 按归一化上游错误代码聚合，复用状态码分布的过滤条件。
+func (r *opsRepository) getErrorCodeDistribution(ctx context.Context, where string, args []any) ([]*service.OpsErrorCodeDistributionItem, error) {
+	q := `
+SELECT
+  error_code,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE NOT is_business_limited) AS sla,
+  COUNT(*) FILTER (WHERE is_business_limited) AS business_limited
+FROM ops_error_logs
+` + where + `
+  AND COALESCE(status_code, 0) >= 400
+  AND error_code IS NOT NULL
+GROUP BY 1
+ORDER BY total DESC
+LIMIT 20`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.OpsErrorCodeDistributionItem, 0, 16)
+	for rows.Next() {
+		item := &service.OpsErrorCodeDistributionItem{}
+		if err := rows.Scan(&item.ErrorCode, &item.Total, &item.SLA, &item.BusinessLimited); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+func (r *opsRepository) BatchInsertUpstreamRequestIDs(ctx context.Context, inputs []*service.OpsUpstreamRequestID) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil ops repository")
+	}
+	if len(inputs) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
+		"ops_upstream_request_ids",
+		"created_at",
+		"client_request_id",
+		"upstream_request_id",
+		"account_id",
+		"upstream_host",
+		"status_code",
+	))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var inserted int64
+	for _, input := range inputs {
+		if input == nil {
+			continue
+		}
+		clientRequestID := strings.TrimSpace(input.ClientRequestID)
+		upstreamRequestID := strings.TrimSpace(input.UpstreamRequestID)
+		if clientRequestID == "" || upstreamRequestID == "" {
+			continue
+		}
+		createdAt := input.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now().UTC()
+		}
+		if _, err := stmt.ExecContext(
+			ctx,
+			createdAt.UTC(),
+			clientRequestID,
+			upstreamRequestID,
+			opsNullInt64(input.AccountID),
+			opsNullString(input.UpstreamHost),
+			opsNullInt(input.StatusCode),
+		); err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+			return inserted, err
+		}
+		inserted++
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		_ = tx.Rollback()
+		return inserted, err
+	}
+	if err := stmt.Close(); err != nil {
+		_ = tx.Rollback()
+		return inserted, err
+	}
+	if err := tx.Commit(); err != nil {
+		return inserted, err
+	}
+	return inserted, nil
+}
+
+func (r *opsRepository) ListUpstreamRequestIDs(ctx context.Context, key string, limit int) ([]*service.OpsUpstreamRequestID, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return []*service.OpsUpstreamRequestID{}, nil
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, created_at, client_request_id, upstream_request_id, account_id, COALESCE(upstream_host, ''), COALESCE(status_code, 0)
+FROM ops_upstream_request_ids
+WHERE upstream_request_id = $1 OR client_request_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2`, key, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsUpstreamRequestID, 0)
+	for rows.Next() {
+		item := &service.OpsUpstreamRequestID{}
+		var accountID sql.NullInt64
+		if err := rows.Scan(
+			&item.ID,
+			&item.CreatedAt,
+			&item.ClientRequestID,
+			&item.UpstreamRequestID,
+			&accountID,
+			&item.UpstreamHost,
+			&item.StatusCode,
+		); err != nil {
+			return nil, err
+		}
+		if accountID.Valid {
+			v := accountID.Int64
+			item.AccountID = &v
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
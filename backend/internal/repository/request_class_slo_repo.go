@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type requestClassSLORepository struct {
+	db *sql.DB
+}
+
+// NewRequestClassSLORepository 创建分类 SLO 报表仓储（聚合 usage_logs 延迟）。
+func NewRequestClassSLORepository(db *sql.DB) service.RequestClassSLORepository {
+	return &requestClassSLORepository{db: db}
+}
+
+func (r *requestClassSLORepository) AggregateLatency(ctx context.Context, filter *service.RequestClassSLOFilter, targets map[string]service.RequestClassSLOTarget) ([]*service.RequestClassSLOStats, error) {
+	interactive := targets[service.RequestClassInteractive]
+	batch := targets[service.RequestClassBatch]
+	args := []any{
+		filter.StartTime, filter.EndTime,
+		service.RequestClassInteractive, service.RequestClassBatch,
+		interactive.FirstTokenMs, interactive.DurationMs, batch.FirstTokenMs, batch.DurationMs,
+	}
+	clauses := []string{"created_at >= $1", "created_at < $2"}
+	if filter.GroupID > 0 {
+		args = append(args, filter.GroupID)
+		clauses = append(clauses, fmt.Sprintf("group_id = $%d", len(args)))
+	}
+	if model := strings.TrimSpace(filter.Model); model != "" {
+		args = append(args, model)
+		clauses = append(clauses, fmt.Sprintf("model = $%d", len(args)))
+	}
+
+	// 历史数据没有 request_class，按流式标记归类，与未声明分类时的推断规则一致
+	q := `
+		WITH classified AS (
+			SELECT
+				COALESCE(request_class, CASE WHEN stream THEN $3 ELSE $4 END) AS class,
+				first_token_ms,
+				duration_ms
+			FROM usage_logs
+			WHERE ` + strings.Join(clauses, " AND ") + `
+		)
+		SELECT
+			class,
+			COUNT(*),
+			COUNT(first_token_ms),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY first_token_ms) FILTER (WHERE first_token_ms IS NOT NULL),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY first_token_ms) FILTER (WHERE first_token_ms IS NOT NULL),
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY first_token_ms) FILTER (WHERE first_token_ms IS NOT NULL),
+			COUNT(*) FILTER (WHERE first_token_ms <= CASE WHEN class = $3 THEN $5 ELSE $7 END),
+			COUNT(duration_ms),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE duration_ms IS NOT NULL),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE duration_ms IS NOT NULL),
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE duration_ms IS NOT NULL),
+			COUNT(*) FILTER (WHERE duration_ms <= CASE WHEN class = $3 THEN $6 ELSE $8 END)
+		FROM classified
+		GROUP BY class
+	`
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate request class latency: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.RequestClassSLOStats, 0, 2)
+	for rows.Next() {
+		var (
+			stats                     service.RequestClassSLOStats
+			ttftP50, ttftP95, ttftP99 sql.NullFloat64
+			durP50, durP95, durP99    sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&stats.Class, &stats.Requests,
+			&stats.FirstToken.Samples, &ttftP50, &ttftP95, &ttftP99, &stats.FirstToken.WithinTarget,
+			&stats.Duration.Samples, &durP50, &durP95, &durP99, &stats.Duration.WithinTarget,
+		); err != nil {
+			return nil, err
+		}
+		stats.FirstToken.P50, stats.FirstToken.P95, stats.FirstToken.P99 = nullFloat64Ptr(ttftP50), nullFloat64Ptr(ttftP95), nullFloat64Ptr(ttftP99)
+		stats.Duration.P50, stats.Duration.P95, stats.Duration.P99 = nullFloat64Ptr(durP50), nullFloat64Ptr(durP95), nullFloat64Ptr(durP99)
+		out = append(out, &stats)
+	}
+	return out, rows.Err()
+}
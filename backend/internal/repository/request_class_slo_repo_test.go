@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestClassSLORepositoryAggregateLatency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestClassSLORepository(db)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	filter := &service.RequestClassSLOFilter{StartTime: start, EndTime: end, GroupID: 3, Model: "gpt-5"}
+	targets := map[string]service.RequestClassSLOTarget{
+		service.RequestClassInteractive: {FirstTokenMs: 5000},
+		service.RequestClassBatch:       {DurationMs: 300000},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE created_at >= $1 AND created_at < $2 AND group_id = $9 AND model = $10")).
+		WithArgs(start, end, "interactive", "batch", 5000, 0, 0, 300000, int64(3), "gpt-5").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"class", "requests",
+			"ttft_samples", "ttft_p50", "ttft_p95", "ttft_p99", "ttft_within",
+			"duration_samples", "duration_p50", "duration_p95", "duration_p99", "duration_within",
+		}).
+			AddRow("interactive", int64(4), int64(4), 800.0, 2400.0, 3000.0, int64(4), int64(4), 5000.0, 9000.0, 9800.0, int64(0)).
+			AddRow("batch", int64(2), int64(0), nil, nil, nil, int64(0), int64(2), 60000.0, 90000.0, 95000.0, int64(2)))
+
+	rows, err := repo.AggregateLatency(context.Background(), filter, targets)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, "interactive", rows[0].Class)
+	require.Equal(t, int64(4), rows[0].FirstToken.WithinTarget)
+	require.NotNil(t, rows[0].FirstToken.P95)
+	require.InDelta(t, 2400.0, *rows[0].FirstToken.P95, 1e-9)
+	require.Nil(t, rows[1].FirstToken.P50)
+	require.Equal(t, int64(2), rows[1].Duration.WithinTarget)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
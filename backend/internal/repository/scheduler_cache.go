@@ -472,7 +472,10 @@ func filterSchedulerAccountGroups(accountGroups []service.AccountGroup) []servic
 			AccountID: ag.AccountID,
 			GroupID:   ag.GroupID,
 			Priority:  ag.Priority,
+			Tier:      ag.Tier,
 			CreatedAt: ag.CreatedAt,
+
+			ConcurrencyShare: ag.ConcurrencyShare,
 		})
 	}
 	if len(filtered) == 0 {
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type secretRotationRepository struct {
+	db *sql.DB
+}
+
+// NewSecretRotationRepository 创建主密钥轮换仓储（按类别读取与比较并交换存量密文）。
+func NewSecretRotationRepository(db *sql.DB) service.SecretRotationRepository {
+	return &secretRotationRepository{db: db}
+}
+
+// secretRotationQueries 各类别的分批读取（$1 = afterID，$2 = limit）与比较并交换（$1 = id，$2 = 旧密文，$3 = 新密文）语句。
+var secretRotationQueries = map[string]struct {
+	list    string
+	replace string
+}{
+	service.SecretKindUserTOTP: {
+		list: `SELECT id, totp_secret_encrypted FROM users
+			WHERE id > $1 AND totp_secret_encrypted IS NOT NULL AND totp_secret_encrypted <> ''
+			ORDER BY id ASC LIMIT $2`,
+		replace: `UPDATE users SET totp_secret_encrypted = $3
+			WHERE id = $1 AND totp_secret_encrypted = $2`,
+	},
+	service.SecretKindChannelMonitorAPIKey: {
+		list: `SELECT id, api_key_encrypted FROM channel_monitors
+			WHERE id > $1 AND api_key_encrypted <> ''
+			ORDER BY id ASC LIMIT $2`,
+		replace: `UPDATE channel_monitors SET api_key_encrypted = $3
+			WHERE id = $1 AND api_key_encrypted = $2`,
+	},
+	// 归档账号的 credentials 只保留 archived_credentials 一个密文字段
+	service.SecretKindArchivedCredentials: {
+		list: `SELECT id, credentials->>'archived_credentials' FROM accounts
+			WHERE id > $1 AND COALESCE(credentials->>'archived_credentials', '') <> ''
+			ORDER BY id ASC LIMIT $2`,
+		replace: `UPDATE accounts SET credentials = jsonb_set(credentials, '{archived_credentials}', to_jsonb($3::text))
+			WHERE id = $1 AND credentials->>'archived_credentials' = $2`,
+	},
+	// 备份 S3 配置以 JSON 存于 settings（key = backup_s3_config）
+	service.SecretKindBackupS3SecretKey: {
+		list: `SELECT id, value::jsonb->>'secret_access_key' FROM settings
+			WHERE key = 'backup_s3_config' AND id > $1 AND COALESCE(value::jsonb->>'secret_access_key', '') <> ''
+			ORDER BY id ASC LIMIT $2`,
+		replace: `UPDATE settings SET value = jsonb_set(value::jsonb, '{secret_access_key}', to_jsonb($3::text))::text, updated_at = NOW()
+			WHERE id = $1 AND key = 'backup_s3_config' AND value::jsonb->>'secret_access_key' = $2`,
+	},
+}
+
+func (r *secretRotationRepository) ListEncryptedSecrets(ctx context.Context, kind string, afterID int64, limit int) ([]service.EncryptedSecret, error) {
+	q, ok := secretRotationQueries[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported secret kind: %s", kind)
+	}
+	rows, err := r.db.QueryContext(ctx, q.list, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list %s secrets: %w", kind, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.EncryptedSecret, 0, limit)
+	for rows.Next() {
+		var secret service.EncryptedSecret
+		if err := rows.Scan(&secret.ID, &secret.Ciphertext); err != nil {
+			return nil, err
+		}
+		out = append(out, secret)
+	}
+	return out, rows.Err()
+}
+
+func (r *secretRotationRepository) ReplaceEncryptedSecret(ctx context.Context, kind string, id int64, oldCiphertext, newCiphertext string) (bool, error) {
+	q, ok := secretRotationQueries[kind]
+	if !ok {
+		return false, fmt.Errorf("unsupported secret kind: %s", kind)
+	}
+	res, err := r.db.ExecContext(ctx, q.replace, id, oldCiphertext, newCiphertext)
+	if err != nil {
+		return false, fmt.Errorf("replace %s secret %d: %w", kind, id, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
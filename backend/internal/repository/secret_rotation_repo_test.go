@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRotationRepositoryListEncryptedSecrets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewSecretRotationRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, credentials->>'archived_credentials' FROM accounts")).
+		WithArgs(int64(10), 200).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ciphertext"}).AddRow(int64(11), "sealed"))
+
+	secrets, err := repo.ListEncryptedSecrets(context.Background(), service.SecretKindArchivedCredentials, 10, 200)
+	require.NoError(t, err)
+	require.Equal(t, []service.EncryptedSecret{{ID: 11, Ciphertext: "sealed"}}, secrets)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSecretRotationRepositoryReplaceEncryptedSecret_CompareAndSwap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewSecretRotationRepository(db)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET totp_secret_encrypted = $3\n\t\t\tWHERE id = $1 AND totp_secret_encrypted = $2")).
+		WithArgs(int64(5), "old", "new").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET totp_secret_encrypted = $3")).
+		WithArgs(int64(6), "old", "new").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	replaced, err := repo.ReplaceEncryptedSecret(context.Background(), service.SecretKindUserTOTP, 5, "old", "new")
+	require.NoError(t, err)
+	require.True(t, replaced)
+	replaced, err = repo.ReplaceEncryptedSecret(context.Background(), service.SecretKindUserTOTP, 6, "old", "new")
+	require.NoError(t, err)
+	require.False(t, replaced, "concurrently modified secret is left untouched")
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = repo.ListEncryptedSecrets(context.Background(), "unknown", 0, 10)
+	require.Error(t, err)
+}
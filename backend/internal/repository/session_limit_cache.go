@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -31,9 +32,50 @@ const (
 
 	// 窗口费用缓存 TTL（30秒）
 	windowCostCacheTTL = 30 * time.Second
+
+	// 窗口费用预占键前缀
+	// 格式: window_cost_hold:account:{accountID}（HASH: reservationID -> 金额）
+	windowCostHoldKeyPrefix = "window_cost_hold:account:"
+	// 格式: window_cost_hold_exp:account:{accountID}（ZSET: reservationID -> 过期时间 unix 毫秒）
+	windowCostHoldExpiryKeyPrefix = "window_cost_hold_exp:account:"
 )
 
 var (
+	// reserveWindowCostScript 清理过期预占并写入新预占
+	// KEYS[1] = window_cost_hold:account:{accountID}
+	// KEYS[2] = window_cost_hold_exp:account:{accountID}
+	// ARGV[1] = reservationID, ARGV[2] = amount, ARGV[3] = now_ms, ARGV[4] = expire_at_ms, ARGV[5] = key_ttl_seconds
+	reserveWindowCostScript = redis.NewScript(`
+		local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[3])
+		for _, id in ipairs(expired) do
+			redis.call('HDEL', KEYS[1], id)
+		end
+		if #expired > 0 then
+			redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ARGV[3])
+		end
+		redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+		redis.call('ZADD', KEYS[2], ARGV[4], ARGV[1])
+		redis.call('EXPIRE', KEYS[1], ARGV[5])
+		redis.call('EXPIRE', KEYS[2], ARGV[5])
+		return 1
+	`)
+
+	// settleWindowCostScript 用实际费用替换预占并更新过期时间；key TTL 只延长不缩短（可能还有其他在途预占）
+	// KEYS 同 reserveWindowCostScript
+	// ARGV[1] = reservationID, ARGV[2] = actualCost, ARGV[3] = expire_at_ms, ARGV[4] = key_ttl_seconds
+	settleWindowCostScript = redis.NewScript(`
+		redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+		redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+		local keyTTL = tonumber(ARGV[4])
+		for _, key in ipairs(KEYS) do
+			local ttl = redis.call('TTL', key)
+			if ttl < keyTTL then
+				redis.call('EXPIRE', key, keyTTL)
+			end
+		end
+		return 1
+	`)
+
 	// registerSessionScript 注册会话活动
 	// 使用 Redis TIME 命令获取服务器时间，避免多实例时钟不同步
 	// KEYS[1] = session_limit:account:{accountID}
@@ -174,6 +216,9 @@ func NewSessionLimitCache(rdb *redis.Client, defaultIdleTimeoutMinutes int) serv
 		refreshSessionScript,
 		getActiveSessionCountScript,
 		isSessionActiveScript,
+		reserveWindowCostScript,
+		settleWindowCostScript,
+		reservedBalanceScript,
 	}
 	for _, script := range scripts {
 		if err := script.Load(ctx, rdb).Err(); err != nil {
@@ -192,6 +237,16 @@ func sessionLimitKey(accountID int64) string {
 	return fmt.Sprintf("%s%d", sessionLimitKeyPrefix, accountID)
 }
 
+// windowCostHoldKey 生成窗口费用预占 HASH 的 Redis 键
+func windowCostHoldKey(accountID int64) string {
+	return fmt.Sprintf("%s%d", windowCostHoldKeyPrefix, accountID)
+}
+
+// windowCostHoldExpiryKey 生成窗口费用预占过期 ZSET 的 Redis 键
+func windowCostHoldExpiryKey(accountID int64) string {
+	return fmt.Sprintf("%s%d", windowCostHoldExpiryKeyPrefix, accountID)
+}
+
 // windowCostKey 生成窗口费用缓存的 Redis 键
 func windowCostKey(accountID int64) string {
 	return fmt.Sprintf("%s%d", windowCostKeyPrefix, accountID)
@@ -354,3 +409,66 @@ func (c *sessionLimitCache) GetWindowCostBatch(ctx context.Context, accountIDs [
 
 	return results, nil
 }
+
+// ========== 5h窗口费用软预占实现 ==========
+
+// ReserveWindowCost 写入一条预占
+func (c *sessionLimitCache) ReserveWindowCost(ctx context.Context, accountID int64, reservationID string, amount float64, ttl time.Duration) error {
+	now := time.Now()
+	// 预占 key 的 TTL 比单条预占多留一分钟，确保 ZSET 能覆盖到所有未过期的预占
+	keyTTL := int((ttl + time.Minute).Seconds())
+	return reserveWindowCostScript.Run(ctx, c.rdb,
+		[]string{windowCostHoldKey(accountID), windowCostHoldExpiryKey(accountID)},
+		reservationID, amount, now.UnixMilli(), now.Add(ttl).UnixMilli(), keyTTL,
+	).Err()
+}
+
+// SettleWindowCost 用实际费用替换预占金额，保留到窗口费用缓存过期（届时数据库聚合已包含该笔费用）
+// 预占已过期或被清理时同样写入，避免实际费用在缓存刷新前漏计
+func (c *sessionLimitCache) SettleWindowCost(ctx context.Context, accountID int64, reservationID string, actualCost float64) error {
+	expireAt := time.Now().Add(windowCostCacheTTL).UnixMilli()
+	keyTTL := int((windowCostCacheTTL + time.Minute).Seconds())
+	return settleWindowCostScript.Run(ctx, c.rdb,
+		[]string{windowCostHoldKey(accountID), windowCostHoldExpiryKey(accountID)},
+		reservationID, actualCost, expireAt, keyTTL,
+	).Err()
+}
+
+// ReleaseWindowCost 删除一条预占
+func (c *sessionLimitCache) ReleaseWindowCost(ctx context.Context, accountID int64, reservationID string) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.HDel(ctx, windowCostHoldKey(accountID), reservationID)
+	pipe.ZRem(ctx, windowCostHoldExpiryKey(accountID), reservationID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetReservedWindowCostBatch 批量获取未过期的预占总额（与余额冻结相同的 HASH + ZSET 结构，复用其汇总脚本）
+func (c *sessionLimitCache) GetReservedWindowCostBatch(ctx context.Context, accountIDs []int64) (map[int64]float64, error) {
+	results := make(map[int64]float64, len(accountIDs))
+	if len(accountIDs) == 0 {
+		return results, nil
+	}
+
+	nowMs := time.Now().UnixMilli()
+	pipe := c.rdb.Pipeline()
+	cmds := make(map[int64]*redis.Cmd, len(accountIDs))
+	for _, accountID := range accountIDs {
+		cmds[accountID] = reservedBalanceScript.Run(ctx, pipe,
+			[]string{windowCostHoldKey(accountID), windowCostHoldExpiryKey(accountID)}, nowMs)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for accountID, cmd := range cmds {
+		raw, err := cmd.Text()
+		if err != nil {
+			continue
+		}
+		if amount, err := strconv.ParseFloat(raw, 64); err == nil && amount > 0 {
+			results[accountID] = amount
+		}
+	}
+	return results, nil
+}
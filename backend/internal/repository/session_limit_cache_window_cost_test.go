@@ -0,0 +1,64 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newWindowCostHoldCacheTest(t *testing.T) (*sessionLimitCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = rdb.Close()
+	})
+	// 通过构造函数创建以预加载 Lua 脚本（批量读取走 Pipeline）
+	return NewSessionLimitCache(rdb, 5).(*sessionLimitCache), mr
+}
+
+func TestSessionLimitCache_WindowCostReservation(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newWindowCostHoldCacheTest(t)
+
+	require.NoError(t, cache.ReserveWindowCost(ctx, 1, "r1", 2.5, 15*time.Minute))
+	require.NoError(t, cache.ReserveWindowCost(ctx, 1, "r2", 1.5, 15*time.Minute))
+	require.NoError(t, cache.ReserveWindowCost(ctx, 2, "r3", 4, 15*time.Minute))
+
+	reserved, err := cache.GetReservedWindowCostBatch(ctx, []int64{1, 2, 3})
+	require.NoError(t, err)
+	require.InDelta(t, 4.0, reserved[1], 1e-9)
+	require.InDelta(t, 4.0, reserved[2], 1e-9)
+	_, ok := reserved[3]
+	require.False(t, ok, "accounts without holds should be omitted")
+
+	// 结算后以实际费用计入，TTL 不短于窗口费用缓存
+	require.NoError(t, cache.SettleWindowCost(ctx, 1, "r1", 0.3))
+	require.NoError(t, cache.ReleaseWindowCost(ctx, 1, "r2"))
+	reserved, err = cache.GetReservedWindowCostBatch(ctx, []int64{1})
+	require.NoError(t, err)
+	require.InDelta(t, 0.3, reserved[1], 1e-9)
+	require.GreaterOrEqual(t, mr.TTL(windowCostHoldKey(1)), 15*time.Minute, "settle must not shorten key TTL")
+}
+
+func TestSessionLimitCache_WindowCostReservationExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newWindowCostHoldCacheTest(t)
+
+	// 已过期的预占不计入，并在下一次预占时被清理
+	require.NoError(t, cache.ReserveWindowCost(ctx, 1, "stale", 3, -time.Second))
+	reserved, err := cache.GetReservedWindowCostBatch(ctx, []int64{1})
+	require.NoError(t, err)
+	require.Zero(t, reserved[1])
+
+	require.NoError(t, cache.ReserveWindowCost(ctx, 1, "fresh", 1, time.Minute))
+	fields, err := mr.HKeys(windowCostHoldKey(1))
+	require.NoError(t, err)
+	require.Equal(t, []string{"fresh"}, fields)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type spendBudgetRepository struct {
+	db *sql.DB
+}
+
+func NewSpendBudgetRepository(db *sql.DB) service.SpendBudgetRepository {
+	return &spendBudgetRepository{db: db}
+}
+
+const spendBudgetColumns = `id, scope_type, scope_id, period, limit_usd, enabled, created_at, updated_at`
+
+// spendBudgetUsageColumn 预算对象类型到 usage_logs 过滤列的映射（白名单，禁止拼接外部输入）。
+var spendBudgetUsageColumn = map[string]string{
+	service.SpendBudgetScopeUser:   "user_id",
+	service.SpendBudgetScopeGroup:  "group_id",
+	service.SpendBudgetScopeAPIKey: "api_key_id",
+}
+
+func (r *spendBudgetRepository) Create(ctx context.Context, budget *service.SpendBudget) (*service.SpendBudget, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO spend_budgets (scope_type, scope_id, period, limit_usd, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING `+spendBudgetColumns,
+		budget.ScopeType, budget.ScopeID, budget.Period, budget.LimitUSD, budget.Enabled)
+	created, err := scanSpendBudget(row)
+	return created, translatePersistenceError(err, nil, service.ErrSpendBudgetExists)
+}
+
+func (r *spendBudgetRepository) GetByID(ctx context.Context, id int64) (*service.SpendBudget, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+spendBudgetColumns+` FROM spend_budgets WHERE id = $1`, id)
+	return scanSpendBudget(row)
+}
+
+func (r *spendBudgetRepository) Update(ctx context.Context, budget *service.SpendBudget) (*service.SpendBudget, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE spend_budgets
+		SET limit_usd = $2, enabled = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+spendBudgetColumns,
+		budget.ID, budget.LimitUSD, budget.Enabled)
+	return scanSpendBudget(row)
+}
+
+func (r *spendBudgetRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM spend_budgets WHERE id = $1`, id)
+	return err
+}
+
+func (r *spendBudgetRepository) List(ctx context.Context, scopeType string, scopeID int64) ([]*service.SpendBudget, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+spendBudgetColumns+`
+		FROM spend_budgets
+		WHERE ($1 = '' OR scope_type = $1) AND ($2 <= 0 OR scope_id = $2)
+		ORDER BY scope_type ASC, scope_id ASC, id ASC
+	`, scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanSpendBudgets(rows)
+}
+
+func (r *spendBudgetRepository) ListEnabled(ctx context.Context) ([]*service.SpendBudget, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+spendBudgetColumns+` FROM spend_budgets WHERE enabled = TRUE`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanSpendBudgets(rows)
+}
+
+func (r *spendBudgetRepository) SumActualCost(ctx context.Context, scopeType string, scopeID int64, since time.Time) (float64, error) {
+	column, ok := spendBudgetUsageColumn[scopeType]
+	if !ok {
+		return 0, fmt.Errorf("unsupported spend budget scope: %s", scopeType)
+	}
+	var spent float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(actual_cost), 0)
+		FROM usage_logs
+		WHERE `+column+` = $1 AND created_at >= $2
+	`, scopeID, since).Scan(&spent)
+	return spent, err
+}
+
+func scanSpendBudget(row scannable) (*service.SpendBudget, error) {
+	b := &service.SpendBudget{}
+	if err := row.Scan(
+		&b.ID, &b.ScopeType, &b.ScopeID, &b.Period, &b.LimitUSD, &b.Enabled, &b.CreatedAt, &b.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrSpendBudgetNotFound
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+func scanSpendBudgets(rows *sql.Rows) ([]*service.SpendBudget, error) {
+	budgets := []*service.SpendBudget{}
+	for rows.Next() {
+		b, err := scanSpendBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendBudgetRepositorySumActualCost_FiltersByScopeColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewSpendBudgetRepository(db)
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE group_id = $1 AND created_at >= $2")).
+		WithArgs(int64(4), since).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(12.5))
+
+	spent, err := repo.SumActualCost(context.Background(), service.SpendBudgetScopeGroup, 4, since)
+
+	require.NoError(t, err)
+	require.Equal(t, 12.5, spent)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = repo.SumActualCost(context.Background(), "account", 4, since)
+	require.Error(t, err)
+}
+
+func TestSpendBudgetRepositoryGetByID_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewSpendBudgetRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM spend_budgets WHERE id = $1")).
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.GetByID(context.Background(), 9)
+
+	require.ErrorIs(t, err, service.ErrSpendBudgetNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
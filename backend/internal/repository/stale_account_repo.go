@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type staleAccountRepository struct {
+	db *sql.DB
+}
+
+// NewStaleAccountRepository 创建闲置账号检测仓储（直接查询 accounts）。
+func NewStaleAccountRepository(db *sql.DB) service.StaleAccountRepository {
+	return &staleAccountRepository{db: db}
+}
+
+// staleAccountLastActiveExpr 账号最后活跃时间：最近使用时间（从未使用取创建时间），
+// 恢复归档时写入的 unarchived_at 也视为一次活跃，避免刚恢复的账号立刻被再次归档。
+const staleAccountLastActiveExpr = `GREATEST(COALESCE(last_used_at, created_at), COALESCE((extra->>'` + service.AccountExtraUnarchivedAt + `')::timestamptz, created_at))`
+
+func (r *staleAccountRepository) ListStaleAccounts(ctx context.Context, cutoff time.Time) ([]service.StaleAccount, error) {
+	query := `
+		SELECT
+			id, name, platform, type, status, concurrency, last_used_at, created_at,
+			` + staleAccountLastActiveExpr + ` AS last_active_at,
+			extra->>'` + service.AccountExtraStaleFlaggedAt + `' AS flagged_at
+		FROM accounts
+		WHERE deleted_at IS NULL
+			AND status <> $1
+			AND ` + staleAccountLastActiveExpr + ` < $2
+		ORDER BY last_active_at ASC, id ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, service.StatusArchived, cutoff.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list stale accounts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.StaleAccount, 0)
+	for rows.Next() {
+		var (
+			acc        service.StaleAccount
+			lastUsedAt sql.NullTime
+			flaggedAt  sql.NullString
+		)
+		if err := rows.Scan(&acc.ID, &acc.Name, &acc.Platform, &acc.Type, &acc.Status, &acc.Concurrency,
+			&lastUsedAt, &acc.CreatedAt, &acc.LastActiveAt, &flaggedAt); err != nil {
+			return nil, err
+		}
+		if lastUsedAt.Valid {
+			t := lastUsedAt.Time
+			acc.LastUsedAt = &t
+		}
+		if flaggedAt.Valid {
+			if t, err := time.Parse(time.RFC3339, flaggedAt.String); err == nil {
+				acc.FlaggedAt = &t
+			}
+		}
+		out = append(out, acc)
+	}
+	return out, rows.Err()
+}
+
+func (r *staleAccountRepository) SummarizeArchived(ctx context.Context) (service.StaleAccountCapacity, error) {
+	var capacity service.StaleAccountCapacity
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(concurrency), 0)
+		FROM accounts
+		WHERE deleted_at IS NULL AND status = $1
+	`, service.StatusArchived).Scan(&capacity.Accounts, &capacity.Concurrency)
+	if err != nil {
+		return service.StaleAccountCapacity{}, fmt.Errorf("summarize archived accounts: %w", err)
+	}
+	return capacity, nil
+}
+
+func (r *staleAccountRepository) SyncStaleFlags(ctx context.Context, cutoff, flaggedAt time.Time) (flagged, cleared int64, err error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE accounts
+		SET extra = COALESCE(extra, '{}'::jsonb) || jsonb_build_object('`+service.AccountExtraStaleFlaggedAt+`', $2::text)
+		WHERE deleted_at IS NULL
+			AND status <> $3
+			AND NOT (COALESCE(extra, '{}'::jsonb) ? '`+service.AccountExtraStaleFlaggedAt+`')
+			AND `+staleAccountLastActiveExpr+` < $1
+	`, cutoff.UTC(), flaggedAt.UTC().Format(time.RFC3339), service.StatusArchived)
+	if err != nil {
+		return 0, 0, fmt.Errorf("flag stale accounts: %w", err)
+	}
+	if flagged, err = result.RowsAffected(); err != nil {
+		return 0, 0, err
+	}
+
+	result, err = r.db.ExecContext(ctx, `
+		UPDATE accounts
+		SET extra = extra - '`+service.AccountExtraStaleFlaggedAt+`'
+		WHERE deleted_at IS NULL
+			AND extra ? '`+service.AccountExtraStaleFlaggedAt+`'
+			AND `+staleAccountLastActiveExpr+` >= $1
+	`, cutoff.UTC())
+	if err != nil {
+		return flagged, 0, fmt.Errorf("clear stale account flags: %w", err)
+	}
+	cleared, err = result.RowsAffected()
+	return flagged, cleared, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleAccountRepositoryListStaleAccounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewStaleAccountRepository(db)
+	cutoff := time.Date(2026, 9, 14, 0, 0, 0, 0, time.UTC)
+	created := cutoff.AddDate(0, -3, 0)
+	lastUsed := cutoff.AddDate(0, -1, 0)
+	mock.ExpectQuery(regexp.QuoteMeta("COALESCE((extra->>'unarchived_at')::timestamptz, created_at))")).
+		WithArgs(service.StatusArchived, cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "platform", "type", "status", "concurrency", "last_used_at", "created_at", "last_active_at", "flagged_at"}).
+			AddRow(int64(1), "acc-1", "openai", "oauth", "active", 3, lastUsed, created, lastUsed, "2026-09-01T00:00:00Z").
+			AddRow(int64(2), "acc-2", "anthropic", "apikey", "error", 5, nil, created, created, nil))
+
+	rows, err := repo.ListStaleAccounts(context.Background(), cutoff)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, &lastUsed, rows[0].LastUsedAt)
+	require.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), *rows[0].FlaggedAt)
+	require.Nil(t, rows[1].LastUsedAt)
+	require.Nil(t, rows[1].FlaggedAt)
+	require.Equal(t, created, rows[1].LastActiveAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStaleAccountRepositorySyncStaleFlags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewStaleAccountRepository(db)
+	cutoff := time.Date(2026, 9, 14, 0, 0, 0, 0, time.UTC)
+	now := cutoff.AddDate(0, 0, 30)
+	mock.ExpectExec(regexp.QuoteMeta("|| jsonb_build_object('stale_flagged_at', $2::text)")).
+		WithArgs(cutoff, "2026-10-14T00:00:00Z", service.StatusArchived).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(regexp.QuoteMeta("SET extra = extra - 'stale_flagged_at'")).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	flagged, cleared, err := repo.SyncStaleFlags(context.Background(), cutoff, now)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(4), flagged)
+	require.Equal(t, int64(1), cleared)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
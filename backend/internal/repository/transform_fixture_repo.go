@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type transformFixtureRepository struct {
+	db *sql.DB
+}
+
+func NewTransformFixtureRepository(db *sql.DB) service.TransformFixtureRepository {
+	return &transformFixtureRepository{db: db}
+}
+
+const transformFixtureColumns = `id, name, description, endpoint, account_id, group_id, request, expect,
+	last_run_at, last_passed, created_by, created_at, updated_at`
+
+func (r *transformFixtureRepository) Create(ctx context.Context, fixture *service.TransformFixture) (*service.TransformFixture, error) {
+	request, expect, err := marshalTransformFixtureSpec(fixture)
+	if err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO transform_fixtures (name, description, endpoint, account_id, group_id, request, expect, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING `+transformFixtureColumns,
+		fixture.Name, fixture.Description, fixture.Endpoint, fixture.AccountID, fixture.GroupID, request, expect, fixture.CreatedBy)
+	created, err := scanTransformFixture(row)
+	return created, translatePersistenceError(err, service.ErrTransformFixtureNotFound, service.ErrTransformFixtureExists)
+}
+
+func (r *transformFixtureRepository) GetByID(ctx context.Context, id int64) (*service.TransformFixture, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+transformFixtureColumns+` FROM transform_fixtures WHERE id = $1`, id)
+	fixture, err := scanTransformFixture(row)
+	return fixture, translatePersistenceError(err, service.ErrTransformFixtureNotFound, nil)
+}
+
+func (r *transformFixtureRepository) List(ctx context.Context) ([]*service.TransformFixture, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+transformFixtureColumns+` FROM transform_fixtures ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	fixtures := []*service.TransformFixture{}
+	for rows.Next() {
+		fixture, err := scanTransformFixture(rows)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, rows.Err()
+}
+
+// Update 修改夹具内容并清空最近运行结果（旧结果不再对应新内容）。
+func (r *transformFixtureRepository) Update(ctx context.Context, fixture *service.TransformFixture) (*service.TransformFixture, error) {
+	request, expect, err := marshalTransformFixtureSpec(fixture)
+	if err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE transform_fixtures
+		SET name = $2, description = $3, endpoint = $4, account_id = $5, group_id = $6, request = $7, expect = $8,
+			last_run_at = NULL, last_passed = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+transformFixtureColumns,
+		fixture.ID, fixture.Name, fixture.Description, fixture.Endpoint, fixture.AccountID, fixture.GroupID, request, expect)
+	updated, err := scanTransformFixture(row)
+	return updated, translatePersistenceError(err, service.ErrTransformFixtureNotFound, service.ErrTransformFixtureExists)
+}
+
+func (r *transformFixtureRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM transform_fixtures WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return service.ErrTransformFixtureNotFound
+	}
+	return nil
+}
+
+func (r *transformFixtureRepository) RecordRun(ctx context.Context, id int64, passed bool, ranAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE transform_fixtures SET last_run_at = $2, last_passed = $3 WHERE id = $1`, id, ranAt, passed)
+	return err
+}
+
+func marshalTransformFixtureSpec(fixture *service.TransformFixture) ([]byte, []byte, error) {
+	request, err := json.Marshal(fixture.Request)
+	if err != nil {
+		return nil, nil, err
+	}
+	expect, err := json.Marshal(fixture.Expect)
+	if err != nil {
+		return nil, nil, err
+	}
+	return request, expect, nil
+}
+
+func scanTransformFixture(row scannable) (*service.TransformFixture, error) {
+	f := &service.TransformFixture{}
+	var (
+		groupID    sql.NullInt64
+		createdBy  sql.NullInt64
+		lastRunAt  sql.NullTime
+		lastPassed sql.NullBool
+		request    []byte
+		expect     []byte
+	)
+	if err := row.Scan(
+		&f.ID, &f.Name, &f.Description, &f.Endpoint, &f.AccountID, &groupID, &request, &expect,
+		&lastRunAt, &lastPassed, &createdBy, &f.CreatedAt, &f.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if groupID.Valid {
+		f.GroupID = &groupID.Int64
+	}
+	if createdBy.Valid {
+		f.CreatedBy = &createdBy.Int64
+	}
+	if lastRunAt.Valid {
+		f.LastRunAt = &lastRunAt.Time
+	}
+	if lastPassed.Valid {
+		f.LastPassed = &lastPassed.Bool
+	}
+	if err := json.Unmarshal(request, &f.Request); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(expect, &f.Expect); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
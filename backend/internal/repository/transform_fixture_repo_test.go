@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+var transformFixtureTestColumns = []string{
+	"id", "name", "description", "endpoint", "account_id", "group_id", "request", "expect",
+	"last_run_at", "last_passed", "created_by", "created_at", "updated_at",
+}
+
+func TestTransformFixtureRepositoryGetByID_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM transform_fixtures WHERE id = $1")).
+		WithArgs(int64(9)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = NewTransformFixtureRepository(db).GetByID(context.Background(), 9)
+	require.ErrorIs(t, err, service.ErrTransformFixtureNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransformFixtureRepositoryList_ScansSpecAndNullableColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM transform_fixtures ORDER BY id ASC")).
+		WillReturnRows(sqlmock.NewRows(transformFixtureTestColumns).
+			AddRow(1, "a", "", service.TransformFixtureEndpointMessages, 3, nil,
+				[]byte(`{"body":{"model":"m"}}`), []byte(`{"path":"/v1/messages"}`), nil, nil, nil, now, now).
+			AddRow(2, "b", "d", service.TransformFixtureEndpointResponses, 4, 7,
+				[]byte(`{"headers":{"x":"1"},"body":{}}`), []byte(`{"absent_headers":["authorization"]}`), now, false, 5, now, now))
+
+	fixtures, err := NewTransformFixtureRepository(db).List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, fixtures, 2)
+
+	require.Nil(t, fixtures[0].GroupID)
+	require.Nil(t, fixtures[0].LastPassed)
+	require.Nil(t, fixtures[0].CreatedBy)
+	require.JSONEq(t, `{"model":"m"}`, string(fixtures[0].Request.Body))
+	require.Equal(t, "/v1/messages", fixtures[0].Expect.Path)
+
+	require.Equal(t, int64(7), *fixtures[1].GroupID)
+	require.False(t, *fixtures[1].LastPassed)
+	require.NotNil(t, fixtures[1].LastRunAt)
+	require.Equal(t, "1", fixtures[1].Request.Headers["x"])
+	require.Equal(t, []string{"authorization"}, fixtures[1].Expect.AbsentHeaders)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransformFixtureRepositoryDelete_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM transform_fixtures WHERE id = $1")).
+		WithArgs(int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = NewTransformFixtureRepository(db).Delete(context.Background(), 4)
+	require.ErrorIs(t, err, service.ErrTransformFixtureNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
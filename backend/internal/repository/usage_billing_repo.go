@@ -195,6 +195,22 @@ func (r *usageBillingRepository) applyUsageBillingEffects(ctx context.Context, t
 		result.APIKeyQuotaExhausted = exhausted
 	}
 
+	if cmd.APIKeyTokens > 0 {
+		exhausted, err := incrementUsageBillingAPIKeyTokens(ctx, tx, cmd.APIKeyID, cmd.APIKeyTokens)
+		if err != nil {
+			return err
+		}
+		result.APIKeyTokenLimitExhausted = exhausted
+	}
+
+	if cmd.APIKeyProjectID > 0 && cmd.APIKeyProjectCost > 0 {
+		exhausted, err := incrementUsageBillingAPIKeyProjectQuota(ctx, tx, cmd.APIKeyProjectID, cmd.APIKeyProjectCost)
+		if err != nil {
+			return err
+		}
+		result.APIKeyProjectQuotaExhausted = exhausted
+	}
+
 	if cmd.APIKeyRateLimitCost > 0 {
 		if err := incrementUsageBillingAPIKeyRateLimit(ctx, tx, cmd.APIKeyID, cmd.APIKeyRateLimitCost); err != nil {
 			return err
@@ -439,6 +455,46 @@ func incrementUsageBillingAPIKeyQuota(ctx context.Context, tx *sql.Tx, apiKeyID
 	return exhausted, nil
 }
 
+// incrementUsageBillingAPIKeyTokens 累加 Key 的 Token 用量，返回本次是否首次达到 token_limit。
+// 不改 status：鉴权按 tokens_used >= token_limit 实时拦截，调整上限后即可恢复。
+func incrementUsageBillingAPIKeyTokens(ctx context.Context, tx *sql.Tx, apiKeyID int64, tokens int64) (bool, error) {
+	var exhausted bool
+	err := tx.QueryRowContext(ctx, `
+		UPDATE api_keys
+		SET tokens_used = tokens_used + $1,
+			updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING token_limit > 0 AND tokens_used >= token_limit AND tokens_used - $1 < token_limit
+	`, tokens, apiKeyID).Scan(&exhausted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, service.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	return exhausted, nil
+}
+
+// incrementUsageBillingAPIKeyProjectQuota 累加项目合计用量，返回本次是否首次达到项目额度。
+// 项目可能刚被删除（Key 的 project_id 已置空但快照未刷新），此时忽略而不是让整笔扣费失败。
+func incrementUsageBillingAPIKeyProjectQuota(ctx context.Context, tx *sql.Tx, projectID int64, amount float64) (bool, error) {
+	var exhausted bool
+	err := tx.QueryRowContext(ctx, `
+		UPDATE api_key_projects
+		SET quota_used = quota_used + $1,
+			updated_at = NOW()
+		WHERE id = $2
+		RETURNING quota > 0 AND quota_used >= quota AND quota_used - $1 < quota
+	`, amount, projectID).Scan(&exhausted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return exhausted, nil
+}
+
 func incrementUsageBillingAPIKeyRateLimit(ctx context.Context, tx *sql.Tx, apiKeyID int64, cost float64) error {
 	res, err := tx.ExecContext(ctx, `
 		UPDATE api_keys SET
@@ -259,3 +259,58 @@ func TestReleaseUsageBillingBatchImageBalance_SkipsWhenHoldNeverReserved(t *test
 	require.NoError(t, tx.Commit())
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestIncrementUsageBillingAPIKeyTokens_ReportsFirstCrossing(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(`(?s)UPDATE api_keys\s+SET tokens_used = tokens_used \+ \$1,.*WHERE id = \$2 AND deleted_at IS NULL\s+RETURNING token_limit > 0 AND tokens_used >= token_limit AND tokens_used - \$1 < token_limit`).
+		WithArgs(int64(1500), int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"exhausted"}).AddRow(true))
+	mock.ExpectQuery(`(?s)UPDATE api_keys\s+SET tokens_used`).
+		WithArgs(int64(10), int64(10)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectCommit()
+
+	exhausted, err := incrementUsageBillingAPIKeyTokens(ctx, tx, 9, 1500)
+	require.NoError(t, err)
+	require.True(t, exhausted)
+
+	_, err = incrementUsageBillingAPIKeyTokens(ctx, tx, 10, 10)
+	require.ErrorIs(t, err, service.ErrAPIKeyNotFound)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIncrementUsageBillingAPIKeyProjectQuota_IgnoresDeletedProject(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(`(?s)UPDATE api_key_projects\s+SET quota_used = quota_used \+ \$1,.*WHERE id = \$2\s+RETURNING quota > 0 AND quota_used >= quota AND quota_used - \$1 < quota`).
+		WithArgs(2.5, int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"exhausted"}).AddRow(true))
+	mock.ExpectQuery(`(?s)UPDATE api_key_projects\s+SET quota_used`).
+		WithArgs(1.0, int64(4)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectCommit()
+
+	exhausted, err := incrementUsageBillingAPIKeyProjectQuota(ctx, tx, 3, 2.5)
+	require.NoError(t, err)
+	require.True(t, exhausted)
+
+	exhausted, err = incrementUsageBillingAPIKeyProjectQuota(ctx, tx, 4, 1.0)
+	require.NoError(t, err)
+	require.False(t, exhausted)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -76,6 +76,12 @@ var usageLogInsertArgTypes = [...]string{
 	"text",        // billing_tier
 	"text",        // billing_mode
 	"numeric",     // account_stats_cost
+	"integer",     // reasoning_tokens
+	"integer",     // tool_call_tokens
+	"text",        // thread_id
+	"text",        // request_class
+	"numeric",     // audio_duration_seconds
+	"integer",     // audio_characters
 	"timestamptz", // created_at
 }
 
@@ -268,6 +274,12 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			reasoning_tokens,
+			tool_call_tokens,
+			thread_id,
+			request_class,
+			audio_duration_seconds,
+			audio_characters,
 			created_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
@@ -275,7 +287,7 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			$10, $11, $12, $13,
 			$14, $15, $16, $17,
 			$18, $19, $20, $21, $22, $23,
-			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53
+			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 		RETURNING id, created_at
@@ -719,10 +731,16 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			reasoning_tokens,
+			tool_call_tokens,
+			thread_id,
+			request_class,
+			audio_duration_seconds,
+			audio_characters,
 			created_at
 		) AS (VALUES `)
 
-	args := make([]any, 0, len(keys)*53)
+	args := make([]any, 0, len(keys)*59)
 	argPos := 1
 	for idx, key := range keys {
 		if idx > 0 {
@@ -803,6 +821,12 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				billing_tier,
 				billing_mode,
 				account_stats_cost,
+				reasoning_tokens,
+				tool_call_tokens,
+				thread_id,
+				request_class,
+				audio_duration_seconds,
+				audio_characters,
 				created_at
 			)
 			SELECT
@@ -858,6 +882,12 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				billing_tier,
 				billing_mode,
 				account_stats_cost,
+				reasoning_tokens,
+				tool_call_tokens,
+				thread_id,
+				request_class,
+				audio_duration_seconds,
+				audio_characters,
 				created_at
 			FROM input
 			ON CONFLICT (request_id, api_key_id) DO NOTHING
@@ -953,10 +983,16 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			reasoning_tokens,
+			tool_call_tokens,
+			thread_id,
+			request_class,
+			audio_duration_seconds,
+			audio_characters,
 			created_at
 		) AS (VALUES `)
 
-	args := make([]any, 0, len(preparedList)*53)
+	args := make([]any, 0, len(preparedList)*59)
 	argPos := 1
 	for idx, prepared := range preparedList {
 		if idx > 0 {
@@ -1034,6 +1070,12 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			reasoning_tokens,
+			tool_call_tokens,
+			thread_id,
+			request_class,
+			audio_duration_seconds,
+			audio_characters,
 			created_at
 		)
 		SELECT
@@ -1089,6 +1131,12 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			reasoning_tokens,
+			tool_call_tokens,
+			thread_id,
+			request_class,
+			audio_duration_seconds,
+			audio_characters,
 			created_at
 		FROM input
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
@@ -1152,6 +1200,12 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			reasoning_tokens,
+			tool_call_tokens,
+			thread_id,
+			request_class,
+			audio_duration_seconds,
+			audio_characters,
 			created_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
@@ -1159,7 +1213,7 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			$10, $11, $12, $13,
 			$14, $15, $16, $17,
 			$18, $19, $20, $21, $22, $23,
-			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53
+			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 	`, prepared.args...)
@@ -1200,6 +1254,9 @@ func prepareUsageLogInsert(log *service.UsageLog) usageLogInsertPrepared {
 	modelMappingChain := nullString(log.ModelMappingChain)
 	billingTier := nullString(log.BillingTier)
 	billingMode := nullString(log.BillingMode)
+	threadID := nullString(log.ThreadID)
+	requestClass := nullString(log.RequestClass)
+	audioCharacters := nullInt(log.AudioCharacters)
 	requestedModel := strings.TrimSpace(log.RequestedModel)
 	if requestedModel == "" {
 		requestedModel = strings.TrimSpace(log.Model)
@@ -1269,6 +1326,12 @@ func prepareUsageLogInsert(log *service.UsageLog) usageLogInsertPrepared {
 			billingTier,
 			billingMode,
 			log.AccountStatsCost, // account_stats_cost
+			log.ReasoningTokens,
+			log.ToolCallTokens,
+			threadID,
+			requestClass,
+			log.AudioDurationSeconds, // audio_duration_seconds
+			audioCharacters,
 			createdAt,
 		},
 	}
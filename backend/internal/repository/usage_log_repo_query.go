@@ -19,7 +19,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/service"
 )
 
-const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, image_output_tokens, image_output_cost, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, image_input_size, image_output_size, image_size_source, image_size_breakdown, video_count, video_resolution, video_duration_seconds, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, cache_ttl_overridden, channel_id, model_mapping_chain, billing_tier, billing_mode, account_stats_cost, created_at"
+const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, image_output_tokens, image_output_cost, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, image_input_size, image_output_size, image_size_source, image_size_breakdown, video_count, video_resolution, video_duration_seconds, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, cache_ttl_overridden, channel_id, model_mapping_chain, billing_tier, billing_mode, account_stats_cost, reasoning_tokens, tool_call_tokens, thread_id, request_class, audio_duration_seconds, audio_characters, created_at"
 
 func (r *usageLogRepository) GetByID(ctx context.Context, id int64) (log *service.UsageLog, err error) {
 	query := "SELECT " + usageLogSelectColumns + " FROM usage_logs WHERE id = $1"
@@ -478,6 +478,12 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		billingTier           sql.NullString
 		billingMode           sql.NullString
 		accountStatsCost      sql.NullFloat64
+		reasoningTokens       int
+		toolCallTokens        int
+		threadID              sql.NullString
+		requestClass          sql.NullString
+		audioDurationSeconds  sql.NullFloat64
+		audioCharacters       sql.NullInt64
 		createdAt             time.Time
 	)
 
@@ -535,6 +541,12 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		&billingTier,
 		&billingMode,
 		&accountStatsCost,
+		&reasoningTokens,
+		&toolCallTokens,
+		&threadID,
+		&requestClass,
+		&audioDurationSeconds,
+		&audioCharacters,
 		&createdAt,
 	); err != nil {
 		return nil, err
@@ -555,6 +567,8 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		CacheCreation1hTokens: cacheCreation1h,
 		ImageOutputTokens:     imageOutputTokens,
 		ImageOutputCost:       imageOutputCost,
+		ReasoningTokens:       reasoningTokens,
+		ToolCallTokens:        toolCallTokens,
 		InputCost:             inputCost,
 		OutputCost:            outputCost,
 		CacheCreationCost:     cacheCreationCost,
@@ -633,6 +647,17 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 	if upstreamEndpoint.Valid {
 		log.UpstreamEndpoint = &upstreamEndpoint.String
 	}
+	if threadID.Valid {
+		log.ThreadID = &threadID.String
+	}
+	if requestClass.Valid {
+		log.RequestClass = &requestClass.String
+	}
+	log.AudioDurationSeconds = nullFloat64Ptr(audioDurationSeconds)
+	if audioCharacters.Valid {
+		value := int(audioCharacters.Int64)
+		log.AudioCharacters = &value
+	}
 	if upstreamModel.Valid {
 		log.UpstreamModel = &upstreamModel.String
 	}
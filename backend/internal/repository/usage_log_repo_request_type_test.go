@@ -93,6 +93,12 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 			sqlmock.AnyArg(), // billing_tier
 			sqlmock.AnyArg(), // billing_mode
 			sqlmock.AnyArg(), // account_stats_cost
+			log.ReasoningTokens,
+			log.ToolCallTokens,
+			sqlmock.AnyArg(), // thread_id
+			sqlmock.AnyArg(), // request_class
+			sqlmock.AnyArg(), // audio_duration_seconds
+			sqlmock.AnyArg(), // audio_characters
 			createdAt,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(99), createdAt))
@@ -179,6 +185,12 @@ func TestUsageLogRepositoryCreate_PersistsServiceTier(t *testing.T) {
 			sqlmock.AnyArg(), // billing_tier
 			sqlmock.AnyArg(), // billing_mode
 			sqlmock.AnyArg(), // account_stats_cost
+			log.ReasoningTokens,
+			log.ToolCallTokens,
+			sqlmock.AnyArg(), // thread_id
+			sqlmock.AnyArg(), // request_class
+			sqlmock.AnyArg(), // audio_duration_seconds
+			sqlmock.AnyArg(), // audio_characters
 			createdAt,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(100), createdAt))
@@ -437,7 +449,7 @@ func TestUsageLogRepositoryGetModelStatsWithFiltersRequestTypePriority(t *testin
 
 	mock.ExpectQuery("AND \\(request_type = \\$3 OR \\(request_type = 0 AND openai_ws_mode = TRUE\\)\\)").
 		WithArgs(start, end, requestType).
-		WillReturnRows(sqlmock.NewRows([]string{"model", "requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost", "actual_cost", "account_cost"}))
+		WillReturnRows(sqlmock.NewRows([]string{"model", "requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost", "actual_cost", "account_cost", "reasoning_tokens", "tool_call_tokens"}))
 
 	stats, err := repo.GetModelStatsWithFilters(context.Background(), start, end, 0, 0, 0, 0, &requestType, &stream, nil)
 	require.NoError(t, err)
@@ -458,7 +470,8 @@ func TestUsageLogRepositoryGetUserModelStatsUsesRequestedModel(t *testing.T) {
 			"model", "requests", "input_tokens", "output_tokens",
 			"cache_creation_tokens", "cache_read_tokens", "total_tokens",
 			"cost", "actual_cost", "account_cost",
-		}).AddRow("gpt-5.5", int64(2), int64(10), int64(20), int64(0), int64(0), int64(30), 0.1, 0.08, 0.07))
+			"reasoning_tokens", "tool_call_tokens",
+		}).AddRow("gpt-5.5", int64(2), int64(10), int64(20), int64(0), int64(0), int64(30), 0.1, 0.08, 0.07, int64(0), int64(0)))
 
 	stats, err := repo.GetUserModelStats(context.Background(), 7, start, end)
 	require.NoError(t, err)
@@ -489,7 +502,9 @@ func TestUsageLogRepositoryGetStatsWithFiltersRequestedModelSource(t *testing.T)
 			"total_actual_cost",
 			"total_account_cost",
 			"avg_duration_ms",
-		}).AddRow(int64(1), int64(2), int64(3), int64(4), int64(1), int64(3), 1.2, 1.0, 1.2, 20.0))
+			"total_reasoning_tokens",
+			"total_tool_call_tokens",
+		}).AddRow(int64(1), int64(2), int64(3), int64(4), int64(1), int64(3), 1.2, 1.0, 1.2, 20.0, int64(0), int64(0)))
 	mock.ExpectQuery("SELECT COALESCE\\(NULLIF\\(TRIM\\(inbound_endpoint\\), ''\\), 'unknown'\\) AS endpoint").
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "gpt-5").
 		WillReturnRows(sqlmock.NewRows([]string{"endpoint", "requests", "total_tokens", "cost", "actual_cost"}))
@@ -530,7 +545,9 @@ func TestUsageLogRepositoryGetStatsWithFiltersRequestTypePriority(t *testing.T)
 			"total_actual_cost",
 			"total_account_cost",
 			"avg_duration_ms",
-		}).AddRow(int64(1), int64(2), int64(3), int64(4), int64(1), int64(3), 1.2, 1.0, 1.2, 20.0))
+			"total_reasoning_tokens",
+			"total_tool_call_tokens",
+		}).AddRow(int64(1), int64(2), int64(3), int64(4), int64(1), int64(3), 1.2, 1.0, 1.2, 20.0, int64(0), int64(0)))
 	mock.ExpectQuery("SELECT COALESCE\\(NULLIF\\(TRIM\\(inbound_endpoint\\), ''\\), 'unknown'\\) AS endpoint").
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), requestType).
 		WillReturnRows(sqlmock.NewRows([]string{"endpoint", "requests", "total_tokens", "cost", "actual_cost"}))
@@ -563,9 +580,10 @@ func TestUsageLogRepositoryGetModelStatsAccountCostColumn(t *testing.T) {
 			"model", "requests", "input_tokens", "output_tokens",
 			"cache_creation_tokens", "cache_read_tokens", "total_tokens",
 			"cost", "actual_cost", "account_cost",
+			"reasoning_tokens", "tool_call_tokens",
 		}).
-			AddRow("claude-opus-4-6", int64(10), int64(100), int64(200), int64(5), int64(3), int64(308), 2.5, 2.0, 1.8).
-			AddRow("claude-sonnet-4-6", int64(5), int64(50), int64(100), int64(0), int64(0), int64(150), 1.0, 0.8, 0.7))
+			AddRow("claude-opus-4-6", int64(10), int64(100), int64(200), int64(5), int64(3), int64(308), 2.5, 2.0, 1.8, int64(0), int64(0)).
+			AddRow("claude-sonnet-4-6", int64(5), int64(50), int64(100), int64(0), int64(0), int64(150), 1.0, 0.8, 0.7, int64(0), int64(0)))
 
 	results, err := repo.GetModelStatsWithFilters(context.Background(), start, end, 0, 0, 0, 0, nil, nil, nil)
 	require.NoError(t, err)
@@ -593,7 +611,8 @@ func TestUsageLogRepositoryGetModelStatsWithUsageFiltersAppliesRequestedModelFil
 			"model", "requests", "input_tokens", "output_tokens",
 			"cache_creation_tokens", "cache_read_tokens", "total_tokens",
 			"cost", "actual_cost", "account_cost",
-		}).AddRow("gpt-5", int64(1), int64(10), int64(20), int64(0), int64(0), int64(30), 0.1, 0.08, 0.07))
+			"reasoning_tokens", "tool_call_tokens",
+		}).AddRow("gpt-5", int64(1), int64(10), int64(20), int64(0), int64(0), int64(30), 0.1, 0.08, 0.07, int64(0), int64(0)))
 
 	results, err := repo.GetModelStatsWithUsageFiltersBySource(context.Background(), start, end, filters, usagestats.ModelSourceRequested)
 	require.NoError(t, err)
@@ -666,7 +685,8 @@ func TestUsageLogRepositoryGetStatsWithFiltersAlwaysReturnsAccountCost(t *testin
 			"total_cache_tokens", "total_cache_creation_tokens", "total_cache_read_tokens",
 			"total_cost", "total_actual_cost",
 			"total_account_cost", "avg_duration_ms",
-		}).AddRow(int64(50), int64(1000), int64(2000), int64(100), int64(60), int64(40), 15.0, 12.5, 11.0, 100.0))
+			"total_reasoning_tokens", "total_tool_call_tokens",
+		}).AddRow(int64(50), int64(1000), int64(2000), int64(100), int64(60), int64(40), 15.0, 12.5, 11.0, 100.0, int64(300), int64(120)))
 	mock.ExpectQuery("SELECT COALESCE\\(NULLIF\\(TRIM\\(inbound_endpoint\\)").
 		WillReturnRows(sqlmock.NewRows([]string{"endpoint", "requests", "total_tokens", "cost", "actual_cost"}))
 	mock.ExpectQuery("SELECT COALESCE\\(NULLIF\\(TRIM\\(upstream_endpoint\\)").
@@ -678,6 +698,8 @@ func TestUsageLogRepositoryGetStatsWithFiltersAlwaysReturnsAccountCost(t *testin
 	require.NoError(t, err)
 	require.NotNil(t, stats.TotalAccountCost, "TotalAccountCost must always be returned, even without AccountID filter")
 	require.Equal(t, 11.0, *stats.TotalAccountCost)
+	require.Equal(t, int64(300), stats.TotalReasoningTokens)
+	require.Equal(t, int64(120), stats.TotalToolCallTokens)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -818,6 +840,12 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			sql.NullFloat64{},
+			0,                 // reasoning_tokens
+			0,                 // tool_call_tokens
+			sql.NullString{},  // thread_id
+			sql.NullString{},  // request_class
+			sql.NullFloat64{}, // audio_duration_seconds
+			sql.NullInt64{},   // audio_characters
 			now,
 		}})
 		require.NoError(t, err)
@@ -889,6 +917,12 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},  // billing_tier
 			sql.NullString{},  // billing_mode
 			sql.NullFloat64{}, // account_stats_cost
+			0,                 // reasoning_tokens
+			0,                 // tool_call_tokens
+			sql.NullString{},  // thread_id
+			sql.NullString{},  // request_class
+			sql.NullFloat64{}, // audio_duration_seconds
+			sql.NullInt64{},   // audio_characters
 			now,
 		}})
 		require.NoError(t, err)
@@ -944,6 +978,12 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},  // billing_tier
 			sql.NullString{},  // billing_mode
 			sql.NullFloat64{}, // account_stats_cost
+			0,                 // reasoning_tokens
+			0,                 // tool_call_tokens
+			sql.NullString{},  // thread_id
+			sql.NullString{},  // request_class
+			sql.NullFloat64{}, // audio_duration_seconds
+			sql.NullInt64{},   // audio_characters
 			now,
 		}})
 		require.NoError(t, err)
@@ -999,6 +1039,12 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},  // billing_tier
 			sql.NullString{},  // billing_mode
 			sql.NullFloat64{}, // account_stats_cost
+			0,                 // reasoning_tokens
+			0,                 // tool_call_tokens
+			sql.NullString{},  // thread_id
+			sql.NullString{},  // request_class
+			sql.NullFloat64{}, // audio_duration_seconds
+			sql.NullInt64{},   // audio_characters
 			now,
 		}})
 		require.NoError(t, err)
@@ -1007,3 +1053,46 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 	})
 
 }
+
+func TestUsageLogRepositoryGetThreadCostScopesToUser(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+
+	first := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	last := first.Add(10 * time.Minute)
+	mock.ExpectQuery("FROM usage_logs\\s+WHERE thread_id = \\$1 AND user_id = \\$2").
+		WithArgs("conv-42", int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens",
+			"cost", "actual_cost", "first_request_at", "last_request_at",
+		}).AddRow(int64(3), int64(100), int64(50), int64(10), int64(40), 0.9, 0.45, first, last))
+
+	cost, err := repo.GetThreadCost(context.Background(), 7, "conv-42")
+	require.NoError(t, err)
+	require.Equal(t, "conv-42", cost.ThreadID)
+	require.Equal(t, int64(3), cost.Requests)
+	require.Equal(t, int64(200), cost.TotalTokens)
+	require.Equal(t, 0.45, cost.ActualCost)
+	require.Equal(t, first, *cost.FirstRequestAt)
+	require.Equal(t, last, *cost.LastRequestAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryGetThreadCostEmptyThread(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+
+	mock.ExpectQuery("FROM usage_logs\\s+WHERE thread_id = \\$1\\s*$").
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens",
+			"cost", "actual_cost", "first_request_at", "last_request_at",
+		}).AddRow(int64(0), int64(0), int64(0), int64(0), int64(0), 0.0, 0.0, nil, nil))
+
+	cost, err := repo.GetThreadCost(context.Background(), 0, "unknown")
+	require.NoError(t, err)
+	require.Zero(t, cost.Requests)
+	require.Nil(t, cost.FirstRequestAt)
+	require.Nil(t, cost.LastRequestAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
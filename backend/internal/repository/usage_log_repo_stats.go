@@ -703,7 +703,9 @@ func (r *usageLogRepository) GetStatsWithFilters(ctx context.Context, filters Us
 			COALESCE(SUM(total_cost), 0) as total_cost,
 			COALESCE(SUM(actual_cost), 0) as total_actual_cost,
 			COALESCE(SUM(COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1)), 0) as total_account_cost,
-			COALESCE(AVG(duration_ms), 0) as avg_duration_ms
+			COALESCE(AVG(duration_ms), 0) as avg_duration_ms,
+			COALESCE(SUM(reasoning_tokens), 0) as total_reasoning_tokens,
+			COALESCE(SUM(tool_call_tokens), 0) as total_tool_call_tokens
 		FROM usage_logs
 		%s
 	`, buildWhere(conditions))
@@ -736,6 +738,8 @@ func (r *usageLogRepository) GetStatsWithFilters(ctx context.Context, filters Us
 			&stats.TotalActualCost,
 			&totalAccountCost,
 			&stats.AverageDurationMs,
+			&stats.TotalReasoningTokens,
+			&stats.TotalToolCallTokens,
 		)
 	}
 	// endpoint 明细:best-effort(失败 log + 返空),不致命。
@@ -1143,3 +1147,51 @@ func (r *usageLogRepository) GetAccountUsageStats(ctx context.Context, accountID
 	}
 	return resp, nil
 }
+
+// GetThreadCost 汇总某个 thread_id 的全部请求。userID 为 0 时不按用户过滤（管理员查询）。
+func (r *usageLogRepository) GetThreadCost(ctx context.Context, userID int64, threadID string) (*usagestats.ThreadCost, error) {
+	conditions := []string{"thread_id = $1"}
+	args := []any{threadID}
+	if userID > 0 {
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)+1))
+		args = append(args, userID)
+	}
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*) as requests,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(cache_creation_tokens), 0) as cache_creation_tokens,
+			COALESCE(SUM(cache_read_tokens), 0) as cache_read_tokens,
+			COALESCE(SUM(total_cost), 0) as cost,
+			COALESCE(SUM(actual_cost), 0) as actual_cost,
+			MIN(created_at) as first_request_at,
+			MAX(created_at) as last_request_at
+		FROM usage_logs
+		%s
+	`, buildWhere(conditions))
+
+	result := &usagestats.ThreadCost{ThreadID: threadID}
+	var firstAt, lastAt sql.NullTime
+	if err := scanSingleRow(ctx, r.sql, query, args,
+		&result.Requests,
+		&result.InputTokens,
+		&result.OutputTokens,
+		&result.CacheCreationTokens,
+		&result.CacheReadTokens,
+		&result.Cost,
+		&result.ActualCost,
+		&firstAt,
+		&lastAt,
+	); err != nil {
+		return nil, err
+	}
+	result.TotalTokens = result.InputTokens + result.OutputTokens + result.CacheCreationTokens + result.CacheReadTokens
+	if firstAt.Valid {
+		result.FirstRequestAt = &firstAt.Time
+	}
+	if lastAt.Valid {
+		result.LastRequestAt = &lastAt.Time
+	}
+	return result, nil
+}
@@ -455,7 +455,9 @@ func (r *usageLogRepository) getModelStatsWithFiltersBySource(ctx context.Contex
 			COALESCE(SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens), 0) as total_tokens,
 			COALESCE(SUM(total_cost), 0) as cost,
 			%s,
-			%s
+			%s,
+			COALESCE(SUM(reasoning_tokens), 0) as reasoning_tokens,
+			COALESCE(SUM(tool_call_tokens), 0) as tool_call_tokens
 		FROM usage_logs
 		WHERE created_at >= $1 AND created_at < $2
 	`, modelExpr, actualCostExpr, accountCostExpr)
@@ -800,6 +802,8 @@ func scanModelStatsRows(rows *sql.Rows) ([]ModelStat, error) {
 			&row.Cost,
 			&row.ActualCost,
 			&row.AccountCost,
+			&row.ReasoningTokens,
+			&row.ToolCallTokens,
 		); err != nil {
 			return nil, err
 		}
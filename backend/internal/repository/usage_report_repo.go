@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type usageReportRepository struct {
+	db *sql.DB
+}
+
+// NewUsageReportRepository 创建用量报表仓储（直接聚合 usage_logs 与 ops_error_logs）。
+func NewUsageReportRepository(db *sql.DB) service.UsageReportRepository {
+	return &usageReportRepository{db: db}
+}
+
+// usageReportDimensionColumns 维度到分组列的映射（白名单，固定顺序；两张表列名一致）。
+var usageReportDimensionColumns = []struct {
+	dimension string
+	expr      string
+}{
+	{service.UsageReportDimensionUser, "COALESCE(user_id, 0)"},
+	{service.UsageReportDimensionAPIKey, "COALESCE(api_key_id, 0)"},
+	{service.UsageReportDimensionGroup, "COALESCE(group_id, 0)"},
+	{service.UsageReportDimensionAccount, "COALESCE(account_id, 0)"},
+	{service.UsageReportDimensionModel, "COALESCE(model, '')"},
+}
+
+// usageReportBucketFormats 时间桶截断单位与输出格式。
+var usageReportBucketFormats = map[string][2]string{
+	service.UsageReportBucketHour:  {"hour", "YYYY-MM-DD HH24:00"},
+	service.UsageReportBucketDay:   {"day", "YYYY-MM-DD"},
+	service.UsageReportBucketMonth: {"month", "YYYY-MM"},
+}
+
+// usageReportNameQueries 维度对象展示名查询（白名单）。
+var usageReportNameQueries = map[string]string{
+	service.UsageReportDimensionUser:    `SELECT id, email FROM users WHERE id = ANY($1)`,
+	service.UsageReportDimensionAPIKey:  `SELECT id, name FROM api_keys WHERE id = ANY($1)`,
+	service.UsageReportDimensionGroup:   `SELECT id, name FROM groups WHERE id = ANY($1)`,
+	service.UsageReportDimensionAccount: `SELECT id, name FROM accounts WHERE id = ANY($1)`,
+}
+
+// buildUsageReportSQL 组装分组列与过滤条件，返回 SELECT 分组列、WHERE 子句、GROUP BY 子句与参数。
+func buildUsageReportSQL(query *service.UsageReportQuery) (selectCols string, where string, groupBy string, args []any) {
+	args = []any{query.StartTime, query.EndTime}
+	cols := make([]string, 0, 6)
+	if format, ok := usageReportBucketFormats[query.Bucket]; ok {
+		tz := strings.TrimSpace(query.Timezone)
+		if tz == "" {
+			tz = resolveUsageStatsTimezone()
+		}
+		args = append(args, tz)
+		cols = append(cols, fmt.Sprintf("TO_CHAR(date_trunc('%s', created_at AT TIME ZONE $%d), '%s')", format[0], len(args), format[1]))
+	}
+	for _, col := range usageReportDimensionColumns {
+		if query.HasDimension(col.dimension) {
+			cols = append(cols, col.expr)
+		}
+	}
+
+	clauses := []string{"created_at >= $1", "created_at < $2"}
+	for _, filter := range []struct {
+		column string
+		value  int64
+	}{
+		{"user_id", query.UserID},
+		{"api_key_id", query.APIKeyID},
+		{"group_id", query.GroupID},
+		{"account_id", query.AccountID},
+	} {
+		if filter.value > 0 {
+			args = append(args, filter.value)
+			clauses = append(clauses, fmt.Sprintf("%s = $%d", filter.column, len(args)))
+		}
+	}
+	if query.Model != "" {
+		args = append(args, query.Model)
+		clauses = append(clauses, fmt.Sprintf("model = $%d", len(args)))
+	}
+
+	if len(cols) > 0 {
+		selectCols = strings.Join(cols, ", ") + ","
+		positions := make([]string, len(cols))
+		for i := range cols {
+			positions[i] = fmt.Sprint(i + 1)
+		}
+		groupBy = "GROUP BY " + strings.Join(positions, ", ")
+	}
+	return selectCols, "WHERE " + strings.Join(clauses, " AND "), groupBy, args
+}
+
+func (r *usageReportRepository) AggregateUsage(ctx context.Context, query *service.UsageReportQuery, limit int) ([]*service.UsageReportRow, error) {
+	selectCols, where, groupBy, args := buildUsageReportSQL(query)
+	args = append(args, limit)
+	q := `
+		SELECT ` + selectCols + `
+			COUNT(*),
+			COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(cache_creation_tokens), 0),
+			COALESCE(SUM(cache_read_tokens), 0),
+			COALESCE(SUM(total_cost), 0),
+			COALESCE(SUM(actual_cost), 0)
+		FROM usage_logs
+		` + where + `
+		` + groupBy + `
+		LIMIT $` + fmt.Sprint(len(args))
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate usage report: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.UsageReportRow, 0)
+	for rows.Next() {
+		row := &service.UsageReportRow{}
+		m := &row.UsageReportMetrics
+		dest := append(usageReportKeyDest(query, row),
+			&m.Requests, &m.InputTokens, &m.OutputTokens, &m.CacheCreationTokens, &m.CacheReadTokens, &m.TotalCost, &m.ActualCost)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		m.TotalTokens = m.InputTokens + m.OutputTokens + m.CacheCreationTokens + m.CacheReadTokens
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *usageReportRepository) AggregateErrors(ctx context.Context, query *service.UsageReportQuery, limit int) ([]*service.UsageReportRow, error) {
+	selectCols, where, groupBy, args := buildUsageReportSQL(query)
+	args = append(args, limit)
+	q := `
+		SELECT ` + selectCols + `
+			COUNT(*)
+		FROM ops_error_logs
+		` + where + `
+			AND is_count_tokens = FALSE
+			AND COALESCE(status_code, 0) >= 400
+			AND NOT COALESCE(is_business_limited, FALSE)
+		` + groupBy + `
+		LIMIT $` + fmt.Sprint(len(args))
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate usage report errors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.UsageReportRow, 0)
+	for rows.Next() {
+		row := &service.UsageReportRow{}
+		dest := append(usageReportKeyDest(query, row), &row.Errors)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// usageReportKeyDest 按 buildUsageReportSQL 的列顺序返回分组列的扫描目标
+func usageReportKeyDest(query *service.UsageReportQuery, row *service.UsageReportRow) []any {
+	dest := make([]any, 0, 8)
+	if _, ok := usageReportBucketFormats[query.Bucket]; ok {
+		dest = append(dest, &row.Bucket)
+	}
+	for _, col := range usageReportDimensionColumns {
+		if !query.HasDimension(col.dimension) {
+			continue
+		}
+		switch col.dimension {
+		case service.UsageReportDimensionUser:
+			row.UserID = new(int64)
+			dest = append(dest, row.UserID)
+		case service.UsageReportDimensionAPIKey:
+			row.APIKeyID = new(int64)
+			dest = append(dest, row.APIKeyID)
+		case service.UsageReportDimensionGroup:
+			row.GroupID = new(int64)
+			dest = append(dest, row.GroupID)
+		case service.UsageReportDimensionAccount:
+			row.AccountID = new(int64)
+			dest = append(dest, row.AccountID)
+		case service.UsageReportDimensionModel:
+			row.Model = new(string)
+			dest = append(dest, row.Model)
+		}
+	}
+	return dest
+}
+
+func (r *usageReportRepository) LookupNames(ctx context.Context, dimension string, ids []int64) (map[int64]string, error) {
+	q, ok := usageReportNameQueries[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unsupported usage report dimension: %s", dimension)
+	}
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	names := make(map[int64]string, len(ids))
+	for rows.Next() {
+		var (
+			id   int64
+			name sql.NullString
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name.String
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageReportRepositoryAggregateUsage_GroupsByBucketAndDimensions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewUsageReportRepository(db)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	query := &service.UsageReportQuery{
+		StartTime:  start,
+		EndTime:    end,
+		Dimensions: []string{service.UsageReportDimensionModel, service.UsageReportDimensionAPIKey},
+		Bucket:     service.UsageReportBucketDay,
+		Timezone:   "Asia/Shanghai",
+		GroupID:    3,
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT TO_CHAR(date_trunc('day', created_at AT TIME ZONE $3), 'YYYY-MM-DD'), COALESCE(api_key_id, 0), COALESCE(model, ''),")).
+		WithArgs(start, end, "Asia/Shanghai", int64(3), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "api_key_id", "model", "count", "in", "out", "cc", "cr", "total", "actual"}).
+			AddRow("2026-10-01", int64(7), "claude-sonnet-4-6", int64(4), int64(100), int64(50), int64(10), int64(40), 0.5, 0.75))
+
+	rows, err := repo.AggregateUsage(context.Background(), query, 10)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "2026-10-01", rows[0].Bucket)
+	require.Equal(t, int64(7), *rows[0].APIKeyID)
+	require.Equal(t, "claude-sonnet-4-6", *rows[0].Model)
+	require.Nil(t, rows[0].UserID)
+	require.Equal(t, int64(200), rows[0].TotalTokens)
+	require.InDelta(t, 0.75, rows[0].ActualCost, 1e-12)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageReportRepositoryAggregateErrors_ExcludesBusinessLimited(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewUsageReportRepository(db)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := &service.UsageReportQuery{StartTime: start, EndTime: start.AddDate(0, 0, 1), Dimensions: []string{service.UsageReportDimensionUser}}
+	mock.ExpectQuery(regexp.QuoteMeta("AND NOT COALESCE(is_business_limited, FALSE)")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "count"}).AddRow(int64(0), int64(2)))
+
+	rows, err := repo.AggregateErrors(context.Background(), query, 10)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, int64(0), *rows[0].UserID)
+	require.Equal(t, int64(2), rows[0].Errors)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
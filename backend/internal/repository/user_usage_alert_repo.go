@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type userUsageAlertRepository struct {
+	db *sql.DB
+}
+
+func NewUserUsageAlertRepository(db *sql.DB) service.UserUsageAlertRepository {
+	return &userUsageAlertRepository{db: db}
+}
+
+const userUsageAlertColumns = `id, user_id, api_key_id, metric, window_minutes, threshold, webhook_url, notify_email, enabled, last_triggered_at, created_at, updated_at`
+
+func (r *userUsageAlertRepository) Create(ctx context.Context, alert *service.UserUsageAlert) (*service.UserUsageAlert, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO user_usage_alerts (user_id, api_key_id, metric, window_minutes, threshold, webhook_url, notify_email, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING `+userUsageAlertColumns,
+		alert.UserID, alert.APIKeyID, alert.Metric, alert.WindowMinutes, alert.Threshold, alert.WebhookURL, alert.NotifyEmail, alert.Enabled)
+	return scanUserUsageAlert(row)
+}
+
+func (r *userUsageAlertRepository) GetByID(ctx context.Context, id int64) (*service.UserUsageAlert, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userUsageAlertColumns+` FROM user_usage_alerts WHERE id = $1`, id)
+	return scanUserUsageAlert(row)
+}
+
+func (r *userUsageAlertRepository) ListByUserID(ctx context.Context, userID int64) ([]*service.UserUsageAlert, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+userUsageAlertColumns+`
+		FROM user_usage_alerts WHERE user_id = $1
+		ORDER BY id ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanUserUsageAlerts(rows)
+}
+
+func (r *userUsageAlertRepository) CountByUserID(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_usage_alerts WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *userUsageAlertRepository) ListEnabled(ctx context.Context) ([]*service.UserUsageAlert, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+userUsageAlertColumns+`
+		FROM user_usage_alerts WHERE enabled = TRUE
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanUserUsageAlerts(rows)
+}
+
+func (r *userUsageAlertRepository) Update(ctx context.Context, alert *service.UserUsageAlert) (*service.UserUsageAlert, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE user_usage_alerts
+		SET api_key_id = $2, metric = $3, window_minutes = $4, threshold = $5, webhook_url = $6,
+		    notify_email = $7, enabled = $8, last_triggered_at = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+userUsageAlertColumns,
+		alert.ID, alert.APIKeyID, alert.Metric, alert.WindowMinutes, alert.Threshold, alert.WebhookURL,
+		alert.NotifyEmail, alert.Enabled, alert.LastTriggeredAt)
+	return scanUserUsageAlert(row)
+}
+
+func (r *userUsageAlertRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_usage_alerts WHERE id = $1`, id)
+	return err
+}
+
+func (r *userUsageAlertRepository) MarkTriggered(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_usage_alerts SET last_triggered_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+// SumUsage 走 idx_usage_logs_user_created（或 api_key_id 索引）聚合窗口内用量。
+func (r *userUsageAlertRepository) SumUsage(ctx context.Context, userID int64, apiKeyID *int64, since time.Time) (*service.UserUsageAlertUsage, error) {
+	out := &service.UserUsageAlertUsage{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(actual_cost), 0),
+			COUNT(*),
+			COALESCE(SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens), 0)
+		FROM usage_logs
+		WHERE user_id = $1
+		  AND ($2::bigint IS NULL OR api_key_id = $2)
+		  AND created_at >= $3
+	`, userID, apiKeyID, since).Scan(&out.Cost, &out.Requests, &out.Tokens)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func scanUserUsageAlert(row scannable) (*service.UserUsageAlert, error) {
+	a := &service.UserUsageAlert{}
+	var apiKeyID sql.NullInt64
+	var lastTriggeredAt sql.NullTime
+	if err := row.Scan(
+		&a.ID, &a.UserID, &apiKeyID, &a.Metric, &a.WindowMinutes, &a.Threshold, &a.WebhookURL,
+		&a.NotifyEmail, &a.Enabled, &lastTriggeredAt, &a.CreatedAt, &a.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrUserUsageAlertNotFound
+		}
+		return nil, err
+	}
+	if apiKeyID.Valid {
+		v := apiKeyID.Int64
+		a.APIKeyID = &v
+	}
+	if lastTriggeredAt.Valid {
+		v := lastTriggeredAt.Time
+		a.LastTriggeredAt = &v
+	}
+	return a, nil
+}
+
+func scanUserUsageAlerts(rows *sql.Rows) ([]*service.UserUsageAlert, error) {
+	var alerts []*service.UserUsageAlert
+	for rows.Next() {
+		a, err := scanUserUsageAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserUsageAlertRepositorySumUsage_AllKeysPassesNullKeyFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewUserUsageAlertRepository(db)
+	since := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("AND ($2::bigint IS NULL OR api_key_id = $2)")).
+		WithArgs(int64(7), nil, since).
+		WillReturnRows(sqlmock.NewRows([]string{"cost", "requests", "tokens"}).AddRow(1.25, 3, 4200))
+
+	usage, err := repo.SumUsage(context.Background(), 7, nil, since)
+
+	require.NoError(t, err)
+	require.Equal(t, 1.25, usage.Cost)
+	require.Equal(t, int64(3), usage.Requests)
+	require.Equal(t, int64(4200), usage.Tokens)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserUsageAlertRepositoryGetByID_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewUserUsageAlertRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM user_usage_alerts WHERE id = $1")).
+		WithArgs(int64(9)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetByID(context.Background(), 9)
+
+	require.ErrorIs(t, err, service.ErrUserUsageAlertNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
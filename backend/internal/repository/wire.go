@@ -70,6 +70,25 @@ var ProviderSet = wire.NewSet(
 	NewAccountRepository,
 	NewScheduledTestPlanRepository,   // 定时测试计划仓储
 	NewScheduledTestResultRepository, // 定时测试结果仓储
+	NewUserUsageAlertRepository,      // 用户用量告警规则仓储
+	NewAPIKeyProjectRepository,       // API Key 项目仓储
+	NewSpendBudgetRepository,         // 消费预算仓储
+	NewModelPriceRepository,          // 模型价格表仓储
+	NewUsageReportRepository,         // 多维度用量报表仓储
+	NewDataExportRepository,          // 原始数据流式导出仓储
+	NewRequestClassSLORepository,     // 请求分类延迟 SLO 报表仓储
+	NewAdminServiceTokenRepository,   // 管理端服务令牌仓储
+	NewBillingStatementRepository,    // 月度账单仓储
+	NewConfigVersionRepository,       // 管理端配置版本历史
+	NewBalanceLedgerRepository,       // 余额流水（只读聚合）
+	NewCapacityForecastRepository,    // 容量预测（读取每日预聚合）
+	NewCacheEfficiencyRepository,     // 缓存命中率报表（聚合 usage_logs）
+	NewClientAnalyticsRepository,     // 客户端来源分析（聚合 usage_logs）
+	NewMappingSimulationRepository,   // 映射/路由变更模拟（聚合 usage_logs）
+	NewStaleAccountRepository,        // 闲置账号检测
+	NewAccountLeaseRepository,        // 账号租约（外部调度借出）
+	NewOpenAIBatchRepository,         // OpenAI Batch API 对象与账号绑定
+	NewTransformFixtureRepository,    // 转换测试夹具
 	NewProxyRepository,
 	NewRedeemCodeRepository,
 	NewPromoCodeRepository,
@@ -100,6 +119,7 @@ var ProviderSet = wire.NewSet(
 	// Cache implementations
 	NewGatewayCache,
 	NewBillingCache,
+	NewBalanceReservationCache,
 	NewAPIKeyCache,
 	NewTempUnschedCache,
 	NewTimeoutCounterCache,
@@ -108,6 +128,7 @@ var ProviderSet = wire.NewSet(
 	ProvideConcurrencyCache,
 	ProvideSessionLimitCache,
 	NewRPMCache,
+	NewAccountUsageWindowCache,
 	NewUserRPMCache,
 	NewUserMsgQueueCache,
 	NewDashboardCache,
@@ -121,6 +142,8 @@ var ProviderSet = wire.NewSet(
 	NewLeaderLockCache,
 	ProvideSchedulerCache,
 	NewSchedulerOutboxRepository,
+	NewConfigSyncRepository,
+	NewAPIKeyTraceCache,
 	NewProxyLatencyCache,
 	NewTotpCache,
 	NewRefreshTokenCache,
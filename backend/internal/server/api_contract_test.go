@@ -247,6 +247,9 @@ func TestAPIContracts(t *testing.T) {
 					"window_5h_start": null,
 					"window_1d_start": null,
 					"window_7d_start": null,
+					"rpm_limit": 0,
+					"tpm_limit": 0,
+					"critical": false,
 					"expires_at": null,
 					"created_at": "2025-01-02T03:04:05Z",
 					"updated_at": "2025-01-02T03:04:05Z"
@@ -298,6 +301,9 @@ func TestAPIContracts(t *testing.T) {
 							"window_5h_start": null,
 							"window_1d_start": null,
 							"window_7d_start": null,
+							"rpm_limit": 0,
+							"tpm_limit": 0,
+							"critical": false,
 							"expires_at": null,
 							"created_at": "2025-01-02T03:04:05Z",
 							"updated_at": "2025-01-02T03:04:05Z"
@@ -354,6 +360,7 @@ func TestAPIContracts(t *testing.T) {
 						"peak_start": "",
 						"peak_end": "",
 						"peak_rate_multiplier": 1,
+						"request_surcharge": 0,
 						"is_exclusive": false,
 						"status": "active",
 						"subscription_type": "standard",
@@ -529,7 +536,9 @@ func TestAPIContracts(t *testing.T) {
 					"total_tokens": 53,
 					"total_cost": 0.75,
 					"total_actual_cost": 0.75,
-					"average_duration_ms": 200
+					"average_duration_ms": 200,
+					"total_reasoning_tokens": 0,
+					"total_tool_call_tokens": 0
 				}
 			}`,
 		},
@@ -582,10 +591,14 @@ func TestAPIContracts(t *testing.T) {
 								"subscription_id": null,
 							"input_tokens": 10,
 							"output_tokens": 20,
+							"output_tokens_per_sec": 400,
 							"cache_creation_tokens": 1,
 							"cache_read_tokens": 2,
 							"cache_creation_5m_tokens": 0,
 							"cache_creation_1h_tokens": 0,
+							"reasoning_tokens": 0,
+							"tool_call_tokens": 0,
+							"visible_output_tokens": 20,
 							"input_cost": 0,
 							"output_cost": 0,
 							"cache_creation_cost": 0,
@@ -1703,6 +1716,22 @@ func (stubGroupRepo) BindAccountsToGroup(ctx context.Context, groupID int64, acc
 	return errors.New("not implemented")
 }
 
+func (stubGroupRepo) ListAccountTiers(context.Context, int64) ([]service.GroupAccountTier, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (stubGroupRepo) SetAccountTiers(context.Context, int64, []service.GroupAccountTier) error {
+	return errors.New("not implemented")
+}
+
+func (stubGroupRepo) ListAccountConcurrencyShares(context.Context, int64) ([]service.GroupAccountConcurrencyShare, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (stubGroupRepo) SetAccountConcurrencyShares(context.Context, int64, []service.GroupAccountConcurrencyShare) error {
+	return errors.New("not implemented")
+}
+
 func (stubGroupRepo) GetAccountIDsByGroupIDs(ctx context.Context, groupIDs []int64) ([]int64, error) {
 	return nil, errors.New("not implemented")
 }
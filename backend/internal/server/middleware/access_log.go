@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLog 输出独立于应用日志的 HTTP 访问日志（Common/Combined Log Format 或 JSON），
+// 供现有访问日志工具与 WAF 分析采集。file_path 为空时写入 stdout，否则按 log.rotation 滚动。
+func AccessLog(cfg config.LogAccessConfig, rotation config.LogRotationConfig) gin.HandlerFunc {
+	var w io.Writer = os.Stdout
+	if cfg.FilePath != "" {
+		w = &lumberjack.Logger{
+			Filename:   cfg.FilePath,
+			MaxSize:    rotation.MaxSizeMB,
+			MaxBackups: rotation.MaxBackups,
+			MaxAge:     rotation.MaxAgeDays,
+			Compress:   rotation.Compress,
+			LocalTime:  rotation.LocalTime,
+		}
+	}
+	return newAccessLogHandler(cfg, w)
+}
+
+func newAccessLogHandler(cfg config.LogAccessConfig, w io.Writer) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = struct{}{}
+	}
+	var mu sync.Mutex
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		if _, ok := skip[path]; ok {
+			return
+		}
+		entry := newAccessLogEntry(c, cfg, path, start)
+		var line []byte
+		if cfg.Format == config.AccessLogFormatJSON {
+			line = entry.json()
+		} else {
+			line = entry.clf(cfg.Format == config.AccessLogFormatCombined)
+		}
+		mu.Lock()
+		_, _ = w.Write(line)
+		mu.Unlock()
+	}
+}
+
+type accessLogExtra struct {
+	key   string
+	value string
+}
+
+type accessLogEntry struct {
+	time      time.Time
+	remote    string
+	method    string
+	path      string
+	protocol  string
+	status    int
+	bytes     int
+	referer   string
+	userAgent string
+	extras    []accessLogExtra
+}
+
+// newAccessLogEntry 收集一条访问日志。路径不含查询串，避免 ?key= 等凭证写入日志。
+func newAccessLogEntry(c *gin.Context, cfg config.LogAccessConfig, path string, start time.Time) *accessLogEntry {
+	e := &accessLogEntry{
+		time:      start,
+		remote:    ip.GetClientIP(c),
+		method:    c.Request.Method,
+		path:      path,
+		protocol:  c.Request.Proto,
+		status:    c.Writer.Status(),
+		bytes:     c.Writer.Size(),
+		referer:   c.Request.Referer(),
+		userAgent: c.Request.UserAgent(),
+	}
+	ctx := c.Request.Context()
+	for _, field := range cfg.Fields {
+		var value string
+		switch field {
+		case "request_id":
+			value, _ = ctx.Value(ctxkey.RequestID).(string)
+		case "latency_ms":
+			value = strconv.FormatInt(time.Since(start).Milliseconds(), 10)
+		case "user_hash":
+			if subject, ok := GetAuthSubjectFromContext(c); ok && subject.UserID > 0 {
+				value = accessLogHash(cfg.HashSalt, "user", subject.UserID)
+			}
+		case "api_key_hash":
+			if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey != nil {
+				value = accessLogHash(cfg.HashSalt, "api_key", apiKey.ID)
+			}
+		case "account_hash":
+			if accountID, ok := ctx.Value(ctxkey.AccountID).(int64); ok && accountID > 0 {
+				value = accessLogHash(cfg.HashSalt, "account", accountID)
+			}
+		case "platform":
+			value, _ = ctx.Value(ctxkey.Platform).(string)
+		case "model":
+			value, _ = ctx.Value(ctxkey.Model).(string)
+		}
+		e.extras = append(e.extras, accessLogExtra{key: field, value: value})
+	}
+	return e
+}
+
+// accessLogHash 返回 HMAC-SHA256(salt, kind:id) 的前 16 位 hex，同一 ID 在日志间可关联但不可枚举还原。
+func accessLogHash(salt, kind string, id int64) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(kind + ":" + strconv.FormatInt(id, 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// clf 按 Common/Combined Log Format 输出，附加字段以 key=value 追加在行尾（空值记为 -）。
+func (e *accessLogEntry) clf(combined bool) []byte {
+	var b strings.Builder
+	b.WriteString(accessLogDash(e.remote))
+	b.WriteString(" - - [")
+	b.WriteString(e.time.Format(accessLogTimeLayout))
+	b.WriteString("] \"")
+	b.WriteString(accessLogEscape(e.method + " " + e.path + " " + e.protocol))
+	b.WriteString("\" ")
+	b.WriteString(strconv.Itoa(e.status))
+	b.WriteByte(' ')
+	if e.bytes > 0 {
+		b.WriteString(strconv.Itoa(e.bytes))
+	} else {
+		b.WriteByte('-')
+	}
+	if combined {
+		b.WriteString(" \"")
+		b.WriteString(accessLogEscape(accessLogDash(e.referer)))
+		b.WriteString("\" \"")
+		b.WriteString(accessLogEscape(accessLogDash(e.userAgent)))
+		b.WriteByte('"')
+	}
+	for _, extra := range e.extras {
+		b.WriteByte(' ')
+		b.WriteString(extra.key)
+		b.WriteByte('=')
+		b.WriteString(accessLogDash(strings.ReplaceAll(accessLogEscape(extra.value), " ", "_")))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// json 输出与 Combined 格式等价的 JSON 行，附加字段为空时省略。
+func (e *accessLogEntry) json() []byte {
+	record := map[string]any{
+		"time":        e.time.Format(time.RFC3339Nano),
+		"remote_addr": e.remote,
+		"method":      e.method,
+		"path":        e.path,
+		"protocol":    e.protocol,
+		"status":      e.status,
+		"bytes":       max(e.bytes, 0),
+		"referer":     e.referer,
+		"user_agent":  e.userAgent,
+	}
+	for _, extra := range e.extras {
+		if extra.value == "" {
+			continue
+		}
+		if extra.key == "latency_ms" {
+			if n, err := strconv.ParseInt(extra.value, 10, 64); err == nil {
+				record[extra.key] = n
+				continue
+			}
+		}
+		record[extra.key] = extra.value
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil
+	}
+	return append(line, '\n')
+}
+
+func accessLogDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// accessLogEscape 转义引号、反斜杠与控制字符，防止伪造日志行。
+func accessLogEscape(v string) string {
+	if strings.IndexFunc(v, func(r rune) bool { return r < 0x20 || r == 0x7f || r == '"' || r == '\\' }) < 0 {
+		return v
+	}
+	quoted := strconv.Quote(v)
+	return quoted[1 : len(quoted)-1]
+}
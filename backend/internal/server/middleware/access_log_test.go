@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessLogTestRouter(cfg config.LogAccessConfig, buf *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), ctxkey.RequestID, "req-1")
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 42})
+		c.Next()
+	})
+	r.Use(newAccessLogHandler(cfg, buf))
+	r.POST("/v1/messages", func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), ctxkey.AccountID, int64(7))
+		ctx = context.WithValue(ctx, ctxkey.Model, "claude\nfake line")
+		c.Request = c.Request.WithContext(ctx)
+		c.String(http.StatusOK, "hello")
+	})
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestAccessLog_CombinedFormatWithHashedIDs(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := config.LogAccessConfig{
+		Format:    config.AccessLogFormatCombined,
+		Fields:    []string{"request_id", "api_key_hash", "account_hash", "model"},
+		HashSalt:  "0123456789abcdef",
+		SkipPaths: []string{"/health"},
+	}
+	r := newAccessLogTestRouter(cfg, &buf)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages?key=secret", nil)
+	req.Header.Set("User-Agent", `curl/8 "x"`)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	line := buf.String()
+	require.Equal(t, 1, strings.Count(line, "\n"), "skipped paths and escaped values must not add lines")
+	require.Regexp(t, regexp.MustCompile(`^\S+ - - \[[^\]]+\] "POST /v1/messages HTTP/1.1" 200 5 "-" "curl/8 \\"x\\"" `), line)
+	require.NotContains(t, line, "secret", "query string is never logged")
+	require.Contains(t, line, "request_id=req-1")
+	require.Contains(t, line, "api_key_hash="+accessLogHash(cfg.HashSalt, "api_key", 42))
+	require.Contains(t, line, "account_hash="+accessLogHash(cfg.HashSalt, "account", 7))
+	require.NotContains(t, line, "account_hash=7")
+	require.Contains(t, line, `model=claude\nfake_line`)
+}
+
+func TestAccessLog_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := config.LogAccessConfig{Format: config.AccessLogFormatJSON, Fields: []string{"request_id", "latency_ms", "platform"}}
+	r := newAccessLogTestRouter(cfg, &buf)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "POST", record["method"])
+	require.Equal(t, "/v1/messages", record["path"])
+	require.EqualValues(t, 200, record["status"])
+	require.EqualValues(t, 5, record["bytes"])
+	require.Equal(t, "req-1", record["request_id"])
+	require.Contains(t, record, "latency_ms")
+	require.NotContains(t, record, "platform", "empty extras are omitted")
+}
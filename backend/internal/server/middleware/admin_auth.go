@@ -6,6 +6,7 @@ import (
 	"errors"
 	"strings"
 
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -16,18 +17,21 @@ func NewAdminAuthMiddleware(
 	authService *service.AuthService,
 	userService *service.UserService,
 	settingService *service.SettingService,
+	serviceTokenService *service.AdminServiceTokenService,
 ) AdminAuthMiddleware {
-	return AdminAuthMiddleware(adminAuth(authService, userService, settingService))
+	return AdminAuthMiddleware(adminAuth(authService, userService, settingService, serviceTokenService))
 }
 
 // adminAuth 管理员认证中间件实现
-// 支持两种认证方式（通过不同的 header 区分）：
+// 支持三种认证方式（通过不同的 header 区分）：
 // 1. Admin API Key: x-api-key: <admin-api-key>
-// 2. JWT Token: Authorization: Bearer <jwt-token> (需要管理员角色)
+// 2. 服务令牌: Authorization: Bearer sat_<token> (按 scope 限制可访问的资源)
+// 3. JWT Token: Authorization: Bearer <jwt-token> (需要管理员角色)
 func adminAuth(
 	authService *service.AuthService,
 	userService *service.UserService,
 	settingService *service.SettingService,
+	serviceTokenService *service.AdminServiceTokenService,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// WebSocket upgrade requests cannot set Authorization headers in browsers.
@@ -64,6 +68,13 @@ func adminAuth(
 					AbortWithError(c, 401, "UNAUTHORIZED", "Authorization required")
 					return
 				}
+				if serviceTokenService != nil && strings.HasPrefix(token, service.AdminServiceTokenPrefix) {
+					if !validateAdminServiceToken(c, token, serviceTokenService, userService) {
+						return
+					}
+					c.Next()
+					return
+				}
 				if !validateJWTForAdmin(c, token, authService, userService) {
 					return
 				}
@@ -150,6 +161,112 @@ func validateAdminAPIKey(
 	return true
 }
 
+// validateAdminServiceToken 验证服务令牌及其对本次请求的 scope，并以令牌创建者身份继续请求。
+// GET/HEAD/OPTIONS 与 adminReadOnlyPostRoutes 中的 POST 视为读操作，其余视为写操作；资源取 /admin/ 之后的一级路径。
+func validateAdminServiceToken(
+	c *gin.Context,
+	token string,
+	serviceTokenService *service.AdminServiceTokenService,
+	userService *service.UserService,
+) bool {
+	st, err := serviceTokenService.Authenticate(c.Request.Context(), token, c.ClientIP())
+	if err != nil {
+		if infraerrors.IsUnauthorized(err) {
+			AbortWithError(c, 401, infraerrors.Reason(err), infraerrors.Message(err))
+			return false
+		}
+		AbortWithError(c, 500, "INTERNAL_ERROR", "Internal server error")
+		return false
+	}
+
+	resource := adminServiceTokenResource(c.Request.URL.Path)
+	write := !isAdminServiceTokenReadRequest(c.Request.Method, c.Request.URL.Path)
+	if !st.Allows(resource, write) {
+		action := service.AdminServiceTokenScopeRead
+		if write {
+			action = service.AdminServiceTokenScopeWrite
+		}
+		AbortWithError(c, 403, "INSUFFICIENT_SCOPE", "Service token lacks scope "+resource+":"+action)
+		return false
+	}
+
+	// 创建者失去管理员身份或被停用后，其创建的令牌一并失效
+	user, err := userService.GetByID(c.Request.Context(), st.CreatedBy)
+	if err != nil || !user.IsActive() || !user.IsAdmin() {
+		AbortWithError(c, 401, "INVALID_SERVICE_TOKEN", "Invalid service token")
+		return false
+	}
+
+	c.Set(string(ContextKeyUser), AuthSubject{
+		UserID:      user.ID,
+		Concurrency: user.Concurrency,
+	})
+	c.Set(string(ContextKeyUserRole), user.Role)
+	c.Set("auth_method", "service_token")
+	c.Set("service_token_id", st.ID)
+	return true
+}
+
+// adminServiceTokenResource 返回管理 API 路径中 /admin/ 之后的一级路径，非管理路径返回空串。
+func adminServiceTokenResource(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/v1/admin/")
+	if !ok {
+		return ""
+	}
+	resource, _, _ := strings.Cut(rest, "/")
+	return resource
+}
+
+// adminReadOnlyPostRoutes 只用 POST 承载请求体、不修改任何状态的管理 API，服务令牌按读操作校验 scope。
+// 路径为 /admin/ 之后的部分，"*" 匹配单个路径段。
+var adminReadOnlyPostRoutes = []string{
+	"graphql",                         // 管理端 GraphQL 只支持 query
+	"accounts/model-mapping/simulate", // 映射变更模拟
+	"accounts/*/model-mapping/test",   // 草稿映射命中测试，不落库
+	"transform-fixtures/run",          // 转换夹具 dry-run，不请求上游
+	"transform-fixtures/*/run",
+}
+
+func isAdminServiceTokenReadRequest(method, path string) bool {
+	if isAdminReadMethod(method) {
+		return true
+	}
+	if method != "POST" {
+		return false
+	}
+	rest, ok := strings.CutPrefix(path, "/api/v1/admin/")
+	if !ok {
+		return false
+	}
+	segments := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	for _, route := range adminReadOnlyPostRoutes {
+		if matchAdminRoutePattern(strings.Split(route, "/"), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAdminRoutePattern(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, part := range pattern {
+		if part != "*" && part != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isAdminReadMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
 // validateJWTForAdmin 验证 JWT 并检查管理员权限
 func validateJWTForAdmin(
 	c *gin.Context,
@@ -6,6 +6,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -43,7 +44,7 @@ func TestAdminAuthJWTValidatesTokenVersion(t *testing.T) {
 	userService := service.NewUserService(userRepo, nil, nil, nil)
 
 	router := gin.New()
-	router.Use(gin.HandlerFunc(NewAdminAuthMiddleware(authService, userService, nil)))
+	router.Use(gin.HandlerFunc(NewAdminAuthMiddleware(authService, userService, nil, nil)))
 	router.GET("/t", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	})
@@ -123,6 +124,155 @@ func TestAdminAuthJWTValidatesTokenVersion(t *testing.T) {
 	})
 }
 
+func TestAdminAuthServiceTokenEnforcesScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admin := &service.User{ID: 1, Role: service.RoleAdmin, Status: service.StatusActive, Concurrency: 1}
+	userRepo := &stubUserRepo{
+		getByID: func(ctx context.Context, id int64) (*service.User, error) {
+			clone := *admin
+			return &clone, nil
+		},
+	}
+	userService := service.NewUserService(userRepo, nil, nil, nil)
+	tokenRepo := &stubAdminServiceTokenRepo{}
+	tokenService := service.NewAdminServiceTokenService(tokenRepo)
+	created, err := tokenService.Create(context.Background(), admin.ID, service.AdminServiceTokenInput{
+		Name:   "dashboard",
+		Scopes: []string{"accounts:read", "usage:read"},
+	})
+	require.NoError(t, err)
+	tokenRepo.token = created.AdminServiceToken
+
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAdminAuthMiddleware(nil, userService, nil, tokenService)))
+	router.Any("/api/v1/admin/*path", func(c *gin.Context) {
+		subject, _ := GetAuthSubjectFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": subject.UserID, "auth_method": c.GetString("auth_method")})
+	})
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/api/v1/admin/accounts/12", created.Token)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"auth_method":"service_token"`)
+
+	w = serve(http.MethodPost, "/api/v1/admin/accounts", created.Token)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "accounts:write")
+
+	w = serve(http.MethodGet, "/api/v1/admin/groups", created.Token)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(http.MethodGet, "/api/v1/admin/service-tokens", created.Token)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	// 只读的 POST 路由（模拟、dry-run）按读操作校验
+	w = serve(http.MethodPost, "/api/v1/admin/accounts/model-mapping/simulate", created.Token)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(http.MethodPost, "/api/v1/admin/accounts/12/model-mapping/test", created.Token)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(http.MethodPost, "/api/v1/admin/accounts/12/test", created.Token)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(http.MethodGet, "/api/v1/admin/usage", service.AdminServiceTokenPrefix+"bogus")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), "INVALID_SERVICE_TOKEN")
+
+	admin.Role = service.RoleUser
+	w = serve(http.MethodGet, "/api/v1/admin/usage", created.Token)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAuthServiceTokenGraphQLReadScopeCanQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admin := &service.User{ID: 1, Role: service.RoleAdmin, Status: service.StatusActive, Concurrency: 1}
+	userRepo := &stubUserRepo{
+		getByID: func(ctx context.Context, id int64) (*service.User, error) {
+			clone := *admin
+			return &clone, nil
+		},
+	}
+	userService := service.NewUserService(userRepo, nil, nil, nil)
+	tokenRepo := &stubAdminServiceTokenRepo{}
+	tokenService := service.NewAdminServiceTokenService(tokenRepo)
+	created, err := tokenService.Create(context.Background(), admin.ID, service.AdminServiceTokenInput{
+		Name:   "graphql-reader",
+		Scopes: []string{"graphql:read"},
+	})
+	require.NoError(t, err)
+	tokenRepo.token = created.AdminServiceToken
+
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAdminAuthMiddleware(nil, userService, nil, tokenService)))
+	router.POST("/api/v1/admin/graphql", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{}})
+	})
+	router.POST("/api/v1/admin/graphql/persist", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"query":"{ accounts { total } }"}`))
+		req.Header.Set("Authorization", "Bearer "+created.Token)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/api/v1/admin/graphql")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 只放行列出的路由本身，同一资源下的其他 POST 仍需写权限
+	w = serve("/api/v1/admin/graphql/persist")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "graphql:write")
+}
+
+type stubAdminServiceTokenRepo struct {
+	token *service.AdminServiceToken
+	hash  string
+}
+
+func (s *stubAdminServiceTokenRepo) Create(ctx context.Context, token *service.AdminServiceToken, tokenHash string) (*service.AdminServiceToken, error) {
+	clone := *token
+	clone.ID = 1
+	s.hash = tokenHash
+	return &clone, nil
+}
+
+func (s *stubAdminServiceTokenRepo) List(ctx context.Context) ([]*service.AdminServiceToken, error) {
+	panic("unexpected List call")
+}
+
+func (s *stubAdminServiceTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*service.AdminServiceToken, error) {
+	if s.token == nil || tokenHash != s.hash {
+		return nil, service.ErrAdminServiceTokenNotFound
+	}
+	clone := *s.token
+	return &clone, nil
+}
+
+func (s *stubAdminServiceTokenRepo) Revoke(ctx context.Context, id int64) (*service.AdminServiceToken, error) {
+	panic("unexpected Revoke call")
+}
+
+func (s *stubAdminServiceTokenRepo) Delete(ctx context.Context, id int64) error {
+	panic("unexpected Delete call")
+}
+
+func (s *stubAdminServiceTokenRepo) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time, ip string) error {
+	return nil
+}
+
 type stubUserRepo struct {
 	getByID func(ctx context.Context, id int64) (*service.User, error)
 }
@@ -127,7 +127,20 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		if abortIfAPIKeyGroupNotAllowed(c, apiKey) {
 			return
 		}
+		// 模型白名单（访客 Key）：不属于计费检查，简易模式下同样生效
+		if violation := checkAPIKeyModelScope(c, apiKey); violation != nil {
+			service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonAPIKeyModelNotAllowed)
+			AbortWithError(c, violation.status, violation.code, violation.message)
+			return
+		}
+		// RPM/TPM 令牌桶限流：保护上游容量，简易模式下同样生效
+		if violation := checkAPIKeyThroughput(c, apiKeyService, apiKey); violation != nil {
+			service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonAPIKeyRateLimited)
+			abortWithRateLimitError(c, violation)
+			return
+		}
 		ctx := context.WithValue(c.Request.Context(), ctxkey.UserID, user.ID)
+		ctx = service.WithCapacityReservation(ctx, apiKey)
 		c.Request = c.Request.WithContext(ctx)
 
 		// ── 4. SimpleMode → early return ─────────────────────────────
@@ -192,6 +205,14 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 				AbortWithError(c, 429, "API_KEY_QUOTA_EXHAUSTED", "API key 额度已用完")
 				return
 			}
+			if apiKey.IsTokenLimitExhausted() {
+				AbortWithError(c, 429, "API_KEY_TOKEN_LIMIT_EXHAUSTED", "API key Token 额度已用完")
+				return
+			}
+			if apiKey.IsProjectQuotaExhausted() {
+				AbortWithError(c, 429, "API_KEY_PROJECT_QUOTA_EXHAUSTED", "API key 所属项目额度已用完")
+				return
+			}
 
 			// 订阅模式：验证订阅限额
 			if subscription != nil {
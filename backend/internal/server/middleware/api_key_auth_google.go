@@ -94,6 +94,19 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			return
 		}
 
+		if violation := checkAPIKeyModelScope(c, apiKey); violation != nil {
+			service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonAPIKeyModelNotAllowed)
+			abortWithGoogleError(c, violation.status, violation.message)
+			return
+		}
+		if violation := checkAPIKeyThroughput(c, apiKeyService, apiKey); violation != nil {
+			service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonAPIKeyRateLimited)
+			abortWithGoogleError(c, 429, violation.message)
+			return
+		}
+
+		c.Request = c.Request.WithContext(service.WithCapacityReservation(c.Request.Context(), apiKey))
+
 		// 简易模式：跳过余额和订阅检查
 		if cfg.RunMode == config.RunModeSimple {
 			c.Set(string(ContextKeyAPIKey), apiKey)
@@ -127,6 +140,14 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 429, "API key 额度已用完")
 			return
 		}
+		if apiKey.IsTokenLimitExhausted() {
+			abortWithGoogleError(c, 429, "API key Token 额度已用完")
+			return
+		}
+		if apiKey.IsProjectQuotaExhausted() {
+			abortWithGoogleError(c, 429, "API key 所属项目额度已用完")
+			return
+		}
 
 		isSubscriptionType := apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
 		if isSubscriptionType && subscriptionService != nil {
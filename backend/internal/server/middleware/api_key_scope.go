@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// apiKeyScopeViolation 访客 Key 作用域（模型白名单）检查失败的结果，由各鉴权中间件按自身协议格式输出。
+type apiKeyScopeViolation struct {
+	status  int
+	code    string
+	message string
+}
+
+// checkAPIKeyModelScope 对设置了 AllowedModels 的 Key 校验本次请求的模型。
+// 模型取自路径（Gemini /models/{model}:action）或请求体的 model 字段；读取后恢复请求体供 handler 使用。
+// 无请求体的查询类请求放行；带请求体却无法识别模型的请求、以及 WebSocket 升级请求一律拒绝。
+func checkAPIKeyModelScope(c *gin.Context, apiKey *service.APIKey) *apiKeyScopeViolation {
+	if apiKey == nil || !apiKey.HasModelRestriction() {
+		return nil
+	}
+	model, hasModel, err := apiKeyScopeRequestedModel(c.Request)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return &apiKeyScopeViolation{status: http.StatusRequestEntityTooLarge, code: "REQUEST_TOO_LARGE", message: fmt.Sprintf("Request body too large, limit is %d bytes", maxErr.Limit)}
+		}
+		return &apiKeyScopeViolation{status: http.StatusBadRequest, code: "INVALID_REQUEST", message: "Failed to read request body"}
+	}
+	if !hasModel {
+		return nil
+	}
+	if model == "" {
+		return &apiKeyScopeViolation{status: http.StatusForbidden, code: "API_KEY_MODEL_NOT_ALLOWED", message: "This API key only allows specific models; the request model could not be determined"}
+	}
+	if !apiKey.IsModelAllowed(model) {
+		return &apiKeyScopeViolation{status: http.StatusForbidden, code: "API_KEY_MODEL_NOT_ALLOWED", message: fmt.Sprintf("Model %s is not allowed for this API key", model)}
+	}
+	return nil
+}
+
+// apiKeyScopeRequestedModel 返回请求的模型；hasModel=false 表示请求不针对具体模型（如 GET 列表）。
+func apiKeyScopeRequestedModel(req *http.Request) (model string, hasModel bool, err error) {
+	if model := modelFromModelsPath(req.URL.Path); model != "" {
+		return model, true, nil
+	}
+	if strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		// WebSocket 的模型在后续消息中，无法在鉴权阶段校验
+		return "", true, nil
+	}
+	if req.Body == nil || req.Body == http.NoBody || req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return "", false, nil
+	}
+
+	body, err := httputil.ReadRequestBodyWithPrealloc(req)
+	if err != nil {
+		return "", false, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return "", false, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		return strings.TrimSpace(multipartModelField(body, params["boundary"])), true, nil
+	}
+	return strings.TrimSpace(gjson.GetBytes(body, "model").String()), true, nil
+}
+
+// modelFromModelsPath 解析 .../models/{model}[:action] 形式的路径。
+func modelFromModelsPath(path string) string {
+	idx := strings.LastIndex(path, "/models/")
+	if idx < 0 {
+		return ""
+	}
+	model := path[idx+len("/models/"):]
+	if i := strings.IndexByte(model, ':'); i >= 0 {
+		model = model[:i]
+	}
+	return strings.Trim(model, "/")
+}
+
+func multipartModelField(body []byte, boundary string) string {
+	if boundary == "" {
+		return ""
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return ""
+		}
+		if part.FormName() == "model" && part.FileName() == "" {
+			value, _ := io.ReadAll(io.LimitReader(part, 256))
+			return string(value)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+//go:build unit
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newGuestScopeTestRouter(t *testing.T, apiKey *service.APIKey) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			if key != apiKey.Key {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+	cfg := &config.Config{RunMode: config.RunModeStandard}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	}
+	router.GET("/v1/models", echo)
+	router.POST("/v1/messages", echo)
+	router.POST("/v1/images/edits", echo)
+	router.POST("/v1beta/models/*modelAction", echo)
+	return router
+}
+
+func guestScopeTestKey() *service.APIKey {
+	return &service.APIKey{
+		ID:            200,
+		UserID:        9,
+		Key:           "guest-scope-key",
+		Status:        service.StatusActive,
+		Guest:         true,
+		AllowedModels: []string{"claude-sonnet-*"},
+		User:          &service.User{ID: 9, Role: service.RoleUser, Status: service.StatusActive, Balance: 10, Concurrency: 1},
+	}
+}
+
+func serveGuestScope(router *gin.Engine, method, path, contentType string, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("x-api-key", "guest-scope-key")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyAuthEnforcesGuestModelScope(t *testing.T) {
+	router := newGuestScopeTestRouter(t, guestScopeTestKey())
+
+	allowed := []byte(`{"model":"claude-sonnet-4-5","max_tokens":16}`)
+	w := serveGuestScope(router, http.MethodPost, "/v1/messages", "application/json", allowed)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, string(allowed), w.Body.String(), "request body must be restored for the handler")
+
+	w = serveGuestScope(router, http.MethodPost, "/v1/messages", "application/json", []byte(`{"model":"claude-opus-4-1"}`))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	requireAPIKeyAuthError(t, w, "API_KEY_MODEL_NOT_ALLOWED", "Model claude-opus-4-1 is not allowed for this API key")
+
+	w = serveGuestScope(router, http.MethodPost, "/v1/messages", "application/json", []byte(`{"messages":[]}`))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveGuestScope(router, http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", "application/json", []byte(`{}`))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveGuestScope(router, http.MethodGet, "/v1/models", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	require.NoError(t, mw.WriteField("model", "gpt-image-1"))
+	require.NoError(t, mw.Close())
+	w = serveGuestScope(router, http.MethodPost, "/v1/images/edits", mw.FormDataContentType(), form.Bytes())
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.True(t, strings.Contains(w.Body.String(), "gpt-image-1"))
+}
+
+func TestAPIKeyAuthRejectsExhaustedTokenLimit(t *testing.T) {
+	apiKey := guestScopeTestKey()
+	apiKey.AllowedModels = nil
+	apiKey.TokenLimit = 1000
+	apiKey.TokensUsed = 1000
+	router := newGuestScopeTestRouter(t, apiKey)
+
+	w := serveGuestScope(router, http.MethodPost, "/v1/messages", "application/json", []byte(`{"model":"claude-sonnet-4-5"}`))
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	requireAPIKeyAuthError(t, w, "API_KEY_TOKEN_LIMIT_EXHAUSTED", "API key Token 额度已用完")
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyThroughputViolation Key 的 RPM/TPM 限制超限结果，由各鉴权中间件按自身协议格式输出。
+type apiKeyThroughputViolation struct {
+	limitType string // requests / tokens，与 OpenAI rate limit 错误的 type 一致
+	message   string
+}
+
+// checkAPIKeyThroughput 校验 Key 的 RPM/TPM 限制，并按 OpenAI 约定写入 x-ratelimit-* 响应头；超限时同时写入 Retry-After。
+// /v1/usage 只查询用量，不消耗请求额度。
+func checkAPIKeyThroughput(c *gin.Context, apiKeyService *service.APIKeyService, apiKey *service.APIKey) *apiKeyThroughputViolation {
+	if apiKeyService == nil || apiKey == nil || !apiKey.HasThroughputLimits() || c.Request.URL.Path == "/v1/usage" {
+		return nil
+	}
+	status, err := apiKeyService.CheckThroughputLimits(c.Request.Context(), apiKey)
+	if status != nil {
+		writeAPIKeyRateLimitHeaders(c, status)
+	}
+	if err == nil {
+		return nil
+	}
+	retrySeconds := int(math.Ceil(status.RetryAfter.Seconds()))
+	if retrySeconds < 1 {
+		retrySeconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retrySeconds))
+	if errors.Is(err, service.ErrAPIKeyTPMExceeded) {
+		return &apiKeyThroughputViolation{
+			limitType: "tokens",
+			message:   fmt.Sprintf("Rate limit reached for tokens per minute (TPM) on this API key: Limit %d. Please try again in %ds.", status.TokenLimit, retrySeconds),
+		}
+	}
+	return &apiKeyThroughputViolation{
+		limitType: "requests",
+		message:   fmt.Sprintf("Rate limit reached for requests per minute (RPM) on this API key: Limit %d. Please try again in %ds.", status.RequestLimit, retrySeconds),
+	}
+}
+
+func writeAPIKeyRateLimitHeaders(c *gin.Context, status *service.APIKeyThroughputStatus) {
+	if status.RequestLimit > 0 {
+		c.Header("x-ratelimit-limit-requests", strconv.FormatInt(status.RequestLimit, 10))
+		c.Header("x-ratelimit-remaining-requests", strconv.FormatInt(status.RequestRemaining, 10))
+		c.Header("x-ratelimit-reset-requests", formatRateLimitReset(status.RequestReset))
+	}
+	if status.TokenLimit > 0 {
+		c.Header("x-ratelimit-limit-tokens", strconv.FormatInt(status.TokenLimit, 10))
+		c.Header("x-ratelimit-remaining-tokens", strconv.FormatInt(status.TokenRemaining, 10))
+		c.Header("x-ratelimit-reset-tokens", formatRateLimitReset(status.TokenReset))
+	}
+}
+
+// formatRateLimitReset 按 OpenAI 的 x-ratelimit-reset-* 格式输出时长（如 "1s"、"6m0s"、"120ms"）。
+func formatRateLimitReset(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// abortWithRateLimitError 按入口协议输出 429 错误体并中断请求：
+// Anthropic /v1/messages 使用 rate_limit_error 信封，其余使用 OpenAI 兼容格式。
+func abortWithRateLimitError(c *gin.Context, violation *apiKeyThroughputViolation) {
+	if isAnthropicMessagesPath(c.Request.URL.Path) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"type":  "error",
+			"error": gin.H{"type": "rate_limit_error", "message": violation.message},
+		})
+		c.Abort()
+		return
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": gin.H{
+			"message": violation.message,
+			"type":    violation.limitType,
+			"param":   nil,
+			"code":    "rate_limit_exceeded",
+		},
+	})
+	c.Abort()
+}
+
+// isAnthropicMessagesPath 判断是否为 Anthropic Messages 入口（含 /v1/messages/count_tokens 及平台前缀路由）。
+func isAnthropicMessagesPath(path string) bool {
+	return strings.HasSuffix(path, "/v1/messages") || strings.Contains(path, "/v1/messages/")
+}
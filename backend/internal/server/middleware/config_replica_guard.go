@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// configReplicaReadOnlyPrefixes 由 primary 同步的配置所对应的写接口前缀。
+var configReplicaReadOnlyPrefixes = []string{
+	"/api/v1/admin/users",
+	"/api/v1/admin/groups",
+	"/api/v1/admin/accounts",
+	"/api/v1/admin/proxies",
+	"/api/v1/admin/api-keys",
+	"/api/v1/admin/openai",
+	"/api/v1/admin/gemini",
+	"/api/v1/admin/antigravity",
+	"/api/v1/admin/grok",
+	"/api/v1/keys",
+	"/api/v1/user",
+	"/api/v1/auth/register",
+	"/api/v1/auth/oauth",
+}
+
+// configReplicaAllowedSuffixes 只读或仅影响本地运行态的接口，在 replica 上仍然放行。
+var configReplicaAllowedSuffixes = []string{
+	"/test",
+	"/today-stats/batch",
+	"/check-mixed-channel",
+	"/sync/crs/preview",
+	"/models/sync-upstream-preview",
+	"/clear-rate-limit",
+	"/temp-unschedulable",
+}
+
+// ConfigReplicaReadOnlyGuard 在配置同步 replica 上拒绝修改账号/分组/用户/Key/代理的请求，
+// 避免本地修改被下一次同步覆盖或与 primary 的 id 冲突。未启用 replica 时为空操作。
+func ConfigReplicaReadOnlyGuard(cfg config.ConfigSyncConfig) gin.HandlerFunc {
+	replica := cfg.IsReplica()
+	return func(c *gin.Context) {
+		if !replica || !configReplicaBlocksRequest(c.Request.Method, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		response.ErrorFrom(c, service.ErrConfigReadOnly)
+		c.Abort()
+	}
+}
+
+func configReplicaBlocksRequest(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	path = strings.TrimRight(strings.ToLower(path), "/")
+	for _, suffix := range configReplicaAllowedSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return false
+		}
+	}
+	for _, prefix := range configReplicaReadOnlyPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
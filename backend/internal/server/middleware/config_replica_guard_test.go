@@ -0,0 +1,52 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigReplicaReadOnlyGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(role string) *gin.Engine {
+		r := gin.New()
+		r.Use(ConfigReplicaReadOnlyGuard(config.ConfigSyncConfig{Role: role}))
+		r.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		role   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "replica blocks account update", role: config.ConfigSyncRoleReplica, method: http.MethodPut, path: "/api/v1/admin/accounts/1", want: http.StatusForbidden},
+		{name: "replica blocks user key create", role: config.ConfigSyncRoleReplica, method: http.MethodPost, path: "/api/v1/keys", want: http.StatusForbidden},
+		{name: "replica blocks registration", role: config.ConfigSyncRoleReplica, method: http.MethodPost, path: "/api/v1/auth/register", want: http.StatusForbidden},
+		{name: "replica allows reads", role: config.ConfigSyncRoleReplica, method: http.MethodGet, path: "/api/v1/admin/accounts", want: http.StatusOK},
+		{name: "replica allows account test", role: config.ConfigSyncRoleReplica, method: http.MethodPost, path: "/api/v1/admin/accounts/1/test", want: http.StatusOK},
+		{name: "replica allows login", role: config.ConfigSyncRoleReplica, method: http.MethodPost, path: "/api/v1/auth/login", want: http.StatusOK},
+		{name: "replica allows unrelated admin writes", role: config.ConfigSyncRoleReplica, method: http.MethodPost, path: "/api/v1/admin/config-sync/run", want: http.StatusOK},
+		{name: "prefix match respects path boundary", role: config.ConfigSyncRoleReplica, method: http.MethodPost, path: "/api/v1/admin/usersx", want: http.StatusOK},
+		{name: "mirror blocks group delete", role: config.ConfigSyncRoleMirror, method: http.MethodDelete, path: "/api/v1/admin/groups/1", want: http.StatusForbidden},
+		{name: "primary allows writes", role: config.ConfigSyncRolePrimary, method: http.MethodPut, path: "/api/v1/admin/accounts/1", want: http.StatusOK},
+		{name: "disabled allows writes", role: "", method: http.MethodDelete, path: "/api/v1/admin/groups/1", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			newRouter(tt.role).ServeHTTP(w, req)
+			require.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing 为每个请求开启 server span（沿用入站 traceparent），后续转发与上游请求的 span 挂在其下。
+// client_request_id、账号与平台在请求处理完成后从 ctx 读取，因为它们由更内层的中间件与 handler 写入。
+func Tracing() gin.HandlerFunc {
+	tracer := tracing.Tracer()
+	return func(c *gin.Context) {
+		if c.Request == nil {
+			c.Next()
+			return
+		}
+		path := c.Request.URL.Path
+		if path == "/health" || path == "/setup/status" || path == "/metrics" {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		spanName := c.Request.Method
+		if route != "" {
+			spanName += " " + route
+		}
+		ctx := tracing.Propagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(path),
+			),
+		)
+		defer span.End()
+		if route != "" {
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		reqCtx := c.Request.Context()
+		if id, _ := reqCtx.Value(ctxkey.ClientRequestID).(string); strings.TrimSpace(id) != "" {
+			span.SetAttributes(tracing.AttrClientRequestID.String(strings.TrimSpace(id)))
+		}
+		if accountID, ok := reqCtx.Value(ctxkey.AccountID).(int64); ok && accountID > 0 {
+			span.SetAttributes(tracing.AttrAccountID.Int64(accountID))
+		}
+		if platform, _ := reqCtx.Value(ctxkey.Platform).(string); platform != "" {
+			span.SetAttributes(tracing.AttrPlatform.String(platform))
+		}
+		if model, _ := reqCtx.Value(ctxkey.Model).(string); model != "" {
+			span.SetAttributes(tracing.AttrModel.String(model))
+		}
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracingRecordsServerSpanWithRequestContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Tracing(), ClientRequestID())
+	router.POST("/v1/messages", func(c *gin.Context) {
+		require.True(t, trace.SpanFromContext(c.Request.Context()).IsRecording())
+		ctx := context.WithValue(c.Request.Context(), ctxkey.AccountID, int64(42))
+		ctx = context.WithValue(ctx, ctxkey.Platform, "anthropic")
+		c.Request = c.Request.WithContext(ctx)
+		c.Status(http.StatusBadGateway)
+	})
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1, "probe paths are not traced")
+	span := spans[0]
+	require.Equal(t, "POST /v1/messages", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID().String(), "inbound traceparent is continued")
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	require.Equal(t, w.Header().Get(clientRequestIDHeader), attrs[tracing.AttrClientRequestID].AsString())
+	require.Equal(t, int64(42), attrs[tracing.AttrAccountID].AsInt64())
+	require.Equal(t, "anthropic", attrs[tracing.AttrPlatform].AsString())
+	require.Equal(t, int64(http.StatusBadGateway), attrs["http.response.status_code"].AsInt64())
+	require.Equal(t, "Error", span.Status().Code.String())
+}
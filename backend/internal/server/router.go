@@ -29,6 +29,7 @@ func SetupRouter(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	apiKeyTraceService *service.APIKeyTraceService,
 	settingService *service.SettingService,
 	cfg *config.Config,
 	redisClient *redis.Client,
@@ -52,7 +53,13 @@ func SetupRouter(
 
 	// 应用中间件
 	r.Use(middleware2.RequestLogger())
+	if cfg.Tracing.Enabled {
+		r.Use(middleware2.Tracing())
+	}
 	r.Use(middleware2.Logger())
+	if cfg.Log.Access.Enabled {
+		r.Use(middleware2.AccessLog(cfg.Log.Access, cfg.Log.Rotation))
+	}
 	r.Use(middleware2.CORS(cfg.CORS))
 	r.Use(middleware2.SecurityHeaders(cfg.Security.CSP, func() []string {
 		if p := cachedFrameOrigins.Load(); p != nil {
@@ -81,7 +88,7 @@ func SetupRouter(
 	}
 
 	// 注册路由
-	registerRoutes(r, handlers, jwtAuth, adminAuth, apiKeyAuth, apiKeyService, subscriptionService, opsService, settingService, cfg, redisClient)
+	registerRoutes(r, handlers, jwtAuth, adminAuth, apiKeyAuth, apiKeyService, subscriptionService, opsService, apiKeyTraceService, settingService, cfg, redisClient)
 
 	return r
 }
@@ -96,21 +103,27 @@ func registerRoutes(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	apiKeyTraceService *service.APIKeyTraceService,
 	settingService *service.SettingService,
 	cfg *config.Config,
 	redisClient *redis.Client,
 ) {
 	// 通用路由（健康检查、状态等）
 	routes.RegisterCommonRoutes(r)
+	routes.RegisterMetricsRoutes(r, cfg.Server.Metrics)
 
 	// API v1
 	v1 := r.Group("/api/v1")
+	// 配置同步 replica 上拒绝修改由 primary 管理的配置
+	v1.Use(middleware2.ConfigReplicaReadOnlyGuard(cfg.ConfigSync))
 
 	// 注册各模块路由
 	routes.RegisterAuthRoutes(v1, h, jwtAuth, redisClient, settingService)
 	routes.RegisterUserRoutes(v1, h, jwtAuth, settingService)
 	routes.RegisterAdminRoutes(v1, h, adminAuth, settingService)
-	routes.RegisterGatewayRoutes(r, h, apiKeyAuth, apiKeyService, subscriptionService, opsService, settingService, cfg)
+	routes.RegisterGatewayRoutes(r, h, apiKeyAuth, apiKeyService, subscriptionService, opsService, apiKeyTraceService, settingService, cfg, redisClient)
+	routes.RegisterConfigSyncRoutes(v1, h)
+	routes.RegisterBillingRoutes(v1, h)
 	routes.RegisterPaymentRoutes(v1, h.Payment, h.PaymentWebhook, h.Admin.Payment, jwtAuth, adminAuth, settingService)
 
 	handler.RegisterPageRoutes(v1, cfg.Pricing.DataDir, gin.HandlerFunc(jwtAuth), gin.HandlerFunc(adminAuth), settingService)
@@ -68,6 +68,9 @@ func RegisterAdminRoutes(
 		// 数据库备份恢复
 		registerBackupRoutes(admin, h)
 
+		// 跨地域配置同步
+		registerConfigSyncAdminRoutes(admin, h)
+
 		// 运维监控（Ops）
 		registerOpsRoutes(admin, h)
 
@@ -86,6 +89,18 @@ func RegisterAdminRoutes(
 		// 错误透传规则管理
 		registerErrorPassthroughRoutes(admin, h)
 
+		// 配置变更历史与回滚
+		registerConfigVersionRoutes(admin, h)
+
+		// 只读 GraphQL 报表查询
+		registerGraphQLRoutes(admin, h)
+
+		// pprof 与运行时调优（需配置开启）
+		registerDebugRoutes(admin, h)
+
+		// 转换测试夹具（干跑，不发出上游请求）
+		registerTransformFixtureRoutes(admin, h)
+
 		// TLS 指纹模板管理
 		registerTLSFingerprintProfileRoutes(admin, h)
 
@@ -95,6 +110,18 @@ func RegisterAdminRoutes(
 		// 定时测试计划
 		registerScheduledTestRoutes(admin, h)
 
+		// 月度账单
+		registerBillingStatementRoutes(admin, h)
+
+		// 消费预算
+		registerSpendBudgetRoutes(admin, h)
+
+		// 模型价格表
+		registerModelPriceRoutes(admin, h)
+
+		// 服务令牌（脚本/看板访问管理 API）
+		registerServiceTokenRoutes(admin, h)
+
 		// 渠道管理
 		registerChannelRoutes(admin, h)
 
@@ -122,6 +149,8 @@ func registerContentModerationRoutes(admin *gin.RouterGroup, h *handler.Handlers
 	{
 		risk.GET("/config", h.Admin.ContentModeration.GetConfig)
 		risk.PUT("/config", h.Admin.ContentModeration.UpdateConfig)
+		risk.GET("/intrusion/config", h.Admin.ContentModeration.GetIntrusionConfig)
+		risk.PUT("/intrusion/config", h.Admin.ContentModeration.UpdateIntrusionConfig)
 		risk.POST("/api-keys/test", h.Admin.ContentModeration.TestAPIKeys)
 		risk.GET("/status", h.Admin.ContentModeration.GetStatus)
 		risk.GET("/logs", h.Admin.ContentModeration.ListLogs)
@@ -135,6 +164,12 @@ func registerAdminAPIKeyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	apiKeys := admin.Group("/api-keys")
 	{
 		apiKeys.PUT("/:id", h.Admin.APIKey.UpdateGroup)
+		apiKeys.GET("/traces", h.Admin.APIKeyTrace.ListActive)
+		apiKeys.GET("/trace-redaction-rules", h.Admin.APIKeyTrace.GetRedactionRules)
+		apiKeys.PUT("/trace-redaction-rules", h.Admin.APIKeyTrace.UpdateRedactionRules)
+		apiKeys.GET("/:id/trace", h.Admin.APIKeyTrace.Get)
+		apiKeys.POST("/:id/trace", h.Admin.APIKeyTrace.Start)
+		apiKeys.DELETE("/:id/trace", h.Admin.APIKeyTrace.Stop)
 	}
 }
 
@@ -156,10 +191,20 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/alert-events/:id", h.Admin.Ops.GetAlertEvent)
 		ops.PUT("/alert-events/:id/status", h.Admin.Ops.UpdateAlertEventStatus)
 		ops.POST("/alert-silences", h.Admin.Ops.CreateAlertSilence)
+		ops.GET("/alert-webhooks", h.Admin.Ops.ListAlertWebhooks)
+		ops.POST("/alert-webhooks", h.Admin.Ops.CreateAlertWebhook)
+		ops.PUT("/alert-webhooks/:id", h.Admin.Ops.UpdateAlertWebhook)
+		ops.DELETE("/alert-webhooks/:id", h.Admin.Ops.DeleteAlertWebhook)
+		ops.POST("/alert-webhooks/:id/test", h.Admin.Ops.TestAlertWebhook)
+		ops.GET("/alert-webhook-deliveries", h.Admin.Ops.ListAlertWebhookDeliveries)
 
 		// Email notification config (DB-backed)
 		ops.GET("/email-notification/config", h.Admin.Ops.GetEmailNotificationConfig)
 		ops.PUT("/email-notification/config", h.Admin.Ops.UpdateEmailNotificationConfig)
+		ops.GET("/reports/weekly-digest", h.Admin.Ops.GetWeeklyDigestPreview)
+		ops.GET("/report-subscription", h.Admin.Ops.GetReportSubscription)
+		ops.PUT("/report-subscription", h.Admin.Ops.UpdateReportSubscription)
+		ops.DELETE("/report-subscription", h.Admin.Ops.DeleteReportSubscription)
 
 		// Runtime settings (DB-backed)
 		runtime := ops.Group("/runtime")
@@ -190,8 +235,14 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 
 		// Error logs (legacy)
 		ops.GET("/errors", h.Admin.Ops.GetErrorLogs)
+		ops.GET("/errors/export", h.Admin.DataExport.ExportOpsErrors)
+		ops.GET("/request-class-slo", h.Admin.RequestClassSLO.GetReport)
 		ops.GET("/errors/:id", h.Admin.Ops.GetErrorLogByID)
 		ops.PUT("/errors/:id/resolve", h.Admin.Ops.UpdateErrorResolution)
+		// Triage workflow (status / assignee / comments)
+		ops.PUT("/errors/:id/triage", h.Admin.Ops.UpdateErrorTriage)
+		ops.GET("/errors/:id/comments", h.Admin.Ops.ListErrorComments)
+		ops.POST("/errors/:id/comments", h.Admin.Ops.AddErrorComment)
 
 		// Request errors (client-visible failures)
 		ops.GET("/request-errors", h.Admin.Ops.ListRequestErrors)
@@ -206,6 +257,22 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 
 		// Request drilldown (success + error)
 		ops.GET("/requests", h.Admin.Ops.ListRequestDetails)
+		ops.GET("/requests/:key/bundle.zip", h.Admin.Ops.ExportRequestBundle)
+		ops.GET("/requests/:key/stream-timings", h.Admin.Ops.ListRequestStreamTimings)
+		ops.GET("/requests/:key/replays", h.Admin.Ops.ListRequestReplays)
+		ops.POST("/requests/:key/replays", h.Admin.Ops.ReplayRequest)
+		ops.GET("/upstream-request-ids", h.Admin.Ops.ListUpstreamRequestIDs)
+
+		// Resource watchdog dumps (goroutine/heap profiles, local to the serving instance)
+		ops.GET("/watchdog", h.Admin.Ops.GetWatchdogStatus)
+		ops.POST("/watchdog/dumps", h.Admin.Ops.CaptureWatchdogDump)
+		ops.GET("/watchdog/dumps/:id/download", h.Admin.Ops.DownloadWatchdogDump)
+
+		// Upstream schema drift findings (local to the serving instance)
+		ops.GET("/schema-drift", h.Admin.Ops.GetSchemaDriftStatus)
+
+		// Incident snapshot ("what broke at time T")
+		ops.GET("/incidents/snapshot", h.Admin.Ops.GetIncidentSnapshot)
 
 		// Indexed system logs
 		ops.GET("/system-logs", h.Admin.Ops.ListSystemLogs)
@@ -220,6 +287,10 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/dashboard/error-trend", h.Admin.Ops.GetDashboardErrorTrend)
 		ops.GET("/dashboard/error-distribution", h.Admin.Ops.GetDashboardErrorDistribution)
 		ops.GET("/dashboard/openai-token-stats", h.Admin.Ops.GetDashboardOpenAITokenStats)
+		ops.GET("/dashboard/openai-session-strategy-stats", h.Admin.Ops.GetDashboardOpenAISessionStrategyStats)
+		ops.GET("/dashboard/auto-continuation-stats", h.Admin.Ops.GetDashboardAutoContinuationStats)
+		ops.GET("/dashboard/upstream-connection-stats", h.Admin.Ops.GetDashboardUpstreamConnectionStats)
+		ops.GET("/dashboard/latency-breakdown", h.Admin.Ops.GetDashboardLatencyBreakdown)
 	}
 }
 
@@ -238,6 +309,10 @@ func registerDashboardRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		dashboard.POST("/users-usage", h.Admin.Dashboard.GetBatchUsersUsage)
 		dashboard.POST("/api-keys-usage", h.Admin.Dashboard.GetBatchAPIKeysUsage)
 		dashboard.GET("/user-breakdown", h.Admin.Dashboard.GetUserBreakdown)
+		dashboard.GET("/capacity-forecast", h.Admin.CapacityForecast.GetForecast)
+		dashboard.GET("/cache-efficiency", h.Admin.CacheEfficiency.GetReport)
+		dashboard.GET("/client-analytics", h.Admin.ClientAnalytics.GetReport)
+		dashboard.GET("/usage-report", h.Admin.UsageReport.GetReport)
 		dashboard.POST("/aggregation/backfill", h.Admin.Dashboard.BackfillAggregation)
 	}
 }
@@ -278,15 +353,19 @@ func registerGroupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		groups.PUT("/sort-order", h.Admin.Group.UpdateSortOrder)
 		groups.GET("/:id/models-list-candidates", h.Admin.Group.GetModelsListCandidates)
 		groups.GET("/:id", h.Admin.Group.GetByID)
-		groups.POST("", h.Admin.Group.Create)
-		groups.PUT("/:id", h.Admin.Group.Update)
-		groups.DELETE("/:id", h.Admin.Group.Delete)
+		groups.POST("", h.Admin.ConfigVersion.Track(service.ConfigEntityGroup), h.Admin.Group.Create)
+		groups.PUT("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityGroup), h.Admin.Group.Update)
+		groups.DELETE("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityGroup), h.Admin.Group.Delete)
 		groups.GET("/:id/stats", h.Admin.Group.GetStats)
 		groups.GET("/:id/rate-multipliers", h.Admin.Group.GetGroupRateMultipliers)
 		groups.PUT("/:id/rate-multipliers", h.Admin.Group.BatchSetGroupRateMultipliers)
 		groups.DELETE("/:id/rate-multipliers", h.Admin.Group.ClearGroupRateMultipliers)
 		groups.PUT("/:id/rpm-overrides", h.Admin.Group.BatchSetGroupRPMOverrides)
 		groups.DELETE("/:id/rpm-overrides", h.Admin.Group.ClearGroupRPMOverrides)
+		groups.GET("/:id/account-tiers", h.Admin.Group.GetGroupAccountTiers)
+		groups.PUT("/:id/account-tiers", h.Admin.Group.BatchSetGroupAccountTiers)
+		groups.GET("/:id/account-shares", h.Admin.Group.GetGroupAccountConcurrencyShares)
+		groups.PUT("/:id/account-shares", h.Admin.Group.BatchSetGroupAccountConcurrencyShares)
 		groups.GET("/:id/api-keys", h.Admin.Group.GetGroupAPIKeys)
 	}
 }
@@ -296,13 +375,13 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	{
 		accounts.GET("", h.Admin.Account.List)
 		accounts.GET("/:id", h.Admin.Account.GetByID)
-		accounts.POST("", h.Admin.Account.Create)
+		accounts.POST("", h.Admin.ConfigVersion.Track(service.ConfigEntityAccount), h.Admin.Account.Create)
 		accounts.POST("/check-mixed-channel", h.Admin.Account.CheckMixedChannel)
 		accounts.POST("/import/codex-session", h.Admin.Account.ImportCodexSession)
 		accounts.POST("/sync/crs", h.Admin.Account.SyncFromCRS)
 		accounts.POST("/sync/crs/preview", h.Admin.Account.PreviewFromCRS)
-		accounts.PUT("/:id", h.Admin.Account.Update)
-		accounts.DELETE("/:id", h.Admin.Account.Delete)
+		accounts.PUT("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityAccount), h.Admin.Account.Update)
+		accounts.DELETE("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityAccount), h.Admin.Account.Delete)
 		accounts.POST("/:id/test", h.Admin.Account.Test)
 		accounts.POST("/:id/recover-state", h.Admin.Account.RecoverState)
 		accounts.POST("/:id/refresh", h.Admin.Account.Refresh)
@@ -311,6 +390,15 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.POST("/:id/refresh-tier", h.Admin.Account.RefreshTier)
 		accounts.GET("/:id/stats", h.Admin.Account.GetStats)
 		accounts.POST("/:id/clear-error", h.Admin.Account.ClearError)
+		accounts.POST("/:id/verify", h.Admin.Account.Verify)
+		accounts.POST("/:id/archive", h.Admin.StaleAccount.Archive)
+		accounts.POST("/:id/unarchive", h.Admin.StaleAccount.Unarchive)
+		accounts.GET("/stale", h.Admin.StaleAccount.GetReport)
+		accounts.POST("/stale/archive", h.Admin.StaleAccount.BulkArchive)
+		accounts.GET("/leases", h.Admin.AccountLease.List)
+		accounts.POST("/:id/lease", h.Admin.AccountLease.Checkout)
+		accounts.POST("/leases/:lease_id/renew", h.Admin.AccountLease.Renew)
+		accounts.DELETE("/leases/:lease_id", h.Admin.AccountLease.Checkin)
 		accounts.POST("/:id/revert-proxy-fallback", h.Admin.Account.RevertProxyFallback)
 		accounts.GET("/:id/usage", h.Admin.Account.GetUsage)
 		accounts.GET("/:id/today-stats", h.Admin.Account.GetTodayStats)
@@ -322,6 +410,8 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.POST("/:id/schedulable", h.Admin.Account.SetSchedulable)
 		accounts.POST("/models/sync-upstream-preview", h.Admin.Account.SyncUpstreamModelsPreview)
 		accounts.GET("/:id/models", h.Admin.Account.GetAvailableModels)
+		accounts.POST("/:id/model-mapping/test", h.Admin.Account.TestModelMapping)
+		accounts.POST("/model-mapping/simulate", h.Admin.MappingSimulation.Simulate)
 		accounts.POST("/:id/models/sync-upstream", h.Admin.Account.SyncUpstreamModels)
 		accounts.POST("/batch", h.Admin.Account.BatchCreate)
 		accounts.GET("/data", h.Admin.Account.ExportData)
@@ -540,6 +630,14 @@ func registerBackupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	}
 }
 
+func registerConfigSyncAdminRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	configSync := admin.Group("/config-sync")
+	{
+		configSync.GET("/status", h.Admin.ConfigSync.GetStatus)
+		configSync.POST("/run", h.Admin.ConfigSync.RunSync)
+	}
+}
+
 func registerSystemRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	system := admin.Group("/system")
 	{
@@ -579,6 +677,8 @@ func registerUsageRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	{
 		usage.GET("", h.Admin.Usage.List)
 		usage.GET("/stats", h.Admin.Usage.Stats)
+		usage.GET("/export", h.Admin.DataExport.ExportUsageLogs)
+		usage.GET("/threads/:thread_id", h.Admin.Usage.ThreadCost)
 		usage.GET("/search-users", h.Admin.Usage.SearchUsers)
 		usage.GET("/search-api-keys", h.Admin.Usage.SearchAPIKeys)
 		usage.GET("/cleanup-tasks", h.Admin.Usage.ListCleanupTasks)
@@ -611,14 +711,25 @@ func registerScheduledTestRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	admin.GET("/accounts/:id/scheduled-test-plans", h.Admin.ScheduledTest.ListByAccount)
 }
 
+func registerBillingStatementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	statements := admin.Group("/billing/statements")
+	{
+		statements.GET("", h.Admin.BillingStatement.List)
+		statements.POST("/generate", h.Admin.BillingStatement.Generate)
+		statements.GET("/:id", h.Admin.BillingStatement.Get)
+		statements.POST("/:id/push", h.Admin.BillingStatement.Push)
+		statements.POST("/:id/void", h.Admin.BillingStatement.Void)
+	}
+}
+
 func registerErrorPassthroughRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	rules := admin.Group("/error-passthrough-rules")
 	{
 		rules.GET("", h.Admin.ErrorPassthrough.List)
 		rules.GET("/:id", h.Admin.ErrorPassthrough.GetByID)
-		rules.POST("", h.Admin.ErrorPassthrough.Create)
-		rules.PUT("/:id", h.Admin.ErrorPassthrough.Update)
-		rules.DELETE("/:id", h.Admin.ErrorPassthrough.Delete)
+		rules.POST("", h.Admin.ConfigVersion.Track(service.ConfigEntityErrorPassthroughRule), h.Admin.ErrorPassthrough.Create)
+		rules.PUT("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityErrorPassthroughRule), h.Admin.ErrorPassthrough.Update)
+		rules.DELETE("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityErrorPassthroughRule), h.Admin.ErrorPassthrough.Delete)
 	}
 }
 
@@ -633,16 +744,93 @@ func registerTLSFingerprintProfileRoutes(admin *gin.RouterGroup, h *handler.Hand
 	}
 }
 
+func registerSpendBudgetRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	budgets := admin.Group("/spend-budgets")
+	{
+		budgets.GET("", h.Admin.SpendBudget.List)
+		budgets.POST("", h.Admin.SpendBudget.Create)
+		budgets.PUT("/:id", h.Admin.SpendBudget.Update)
+		budgets.DELETE("/:id", h.Admin.SpendBudget.Delete)
+	}
+}
+
+func registerModelPriceRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	prices := admin.Group("/model-prices")
+	{
+		prices.GET("", h.Admin.ModelPrice.List)
+		prices.POST("", h.Admin.ModelPrice.Create)
+		prices.GET("/backfill", h.Admin.ModelPrice.GetBackfillStatus)
+		prices.POST("/backfill", h.Admin.ModelPrice.StartBackfill)
+		prices.PUT("/:id", h.Admin.ModelPrice.Update)
+		prices.DELETE("/:id", h.Admin.ModelPrice.Delete)
+	}
+}
+
+func registerServiceTokenRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	tokens := admin.Group("/service-tokens")
+	{
+		tokens.GET("", h.Admin.ServiceToken.List)
+		tokens.POST("", h.Admin.ServiceToken.Create)
+		tokens.POST("/:id/revoke", h.Admin.ServiceToken.Revoke)
+		tokens.DELETE("/:id", h.Admin.ServiceToken.Delete)
+	}
+}
+
 func registerChannelRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	channels := admin.Group("/channels")
 	{
 		channels.GET("", h.Admin.Channel.List)
 		channels.GET("/model-pricing", h.Admin.Channel.GetModelDefaultPricing)
 		channels.GET("/pricing/sync-models", h.Admin.Channel.SyncPricingModels)
+		channels.GET("/pricing/sync-status", h.Admin.Channel.GetPricingSyncStatus)
+		channels.POST("/pricing/sync", h.Admin.Channel.TriggerPricingSync)
+		channels.POST("/pricing/pending/approve", h.Admin.Channel.ApprovePendingPricing)
+		channels.POST("/pricing/pending/reject", h.Admin.Channel.RejectPendingPricing)
 		channels.GET("/:id", h.Admin.Channel.GetByID)
-		channels.POST("", h.Admin.Channel.Create)
-		channels.PUT("/:id", h.Admin.Channel.Update)
-		channels.DELETE("/:id", h.Admin.Channel.Delete)
+		channels.POST("", h.Admin.ConfigVersion.Track(service.ConfigEntityChannel), h.Admin.Channel.Create)
+		channels.PUT("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityChannel), h.Admin.Channel.Update)
+		channels.DELETE("/:id", h.Admin.ConfigVersion.Track(service.ConfigEntityChannel), h.Admin.Channel.Delete)
+	}
+}
+
+func registerConfigVersionRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	versions := admin.Group("/config-versions/:type/:id")
+	{
+		versions.GET("", h.Admin.ConfigVersion.List)
+		versions.GET("/diff", h.Admin.ConfigVersion.Diff)
+		versions.GET("/versions/:version", h.Admin.ConfigVersion.Get)
+		versions.POST("/versions/:version/rollback", h.Admin.ConfigVersion.Rollback)
+	}
+}
+
+func registerGraphQLRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	gql := admin.Group("/graphql")
+	{
+		gql.POST("", h.Admin.GraphQL.Query)
+		gql.GET("/schema", h.Admin.GraphQL.Schema)
+	}
+}
+
+func registerDebugRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	debug := admin.Group("/debug")
+	{
+		debug.GET("/pprof/*profile", h.Admin.Debug.Pprof)
+		debug.POST("/pprof/*profile", h.Admin.Debug.Pprof)
+		debug.GET("/runtime", h.Admin.Debug.GetRuntime)
+		debug.PUT("/runtime", h.Admin.Debug.UpdateRuntime)
+	}
+}
+
+func registerTransformFixtureRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	fixtures := admin.Group("/transform-fixtures")
+	{
+		fixtures.GET("", h.Admin.TransformFixture.List)
+		fixtures.POST("/run", h.Admin.TransformFixture.Run)
+		fixtures.GET("/:id", h.Admin.TransformFixture.GetByID)
+		fixtures.POST("", h.Admin.TransformFixture.Create)
+		fixtures.PUT("/:id", h.Admin.TransformFixture.Update)
+		fixtures.DELETE("/:id", h.Admin.TransformFixture.Delete)
+		fixtures.POST("/:id/run", h.Admin.TransformFixture.RunOne)
 	}
 }
 
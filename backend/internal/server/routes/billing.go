@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterBillingRoutes 注册账单相关的公开回调（Stripe 账单 webhook，签名鉴权）
+func RegisterBillingRoutes(v1 *gin.RouterGroup, h *handler.Handlers) {
+	v1.POST("/billing/stripe/webhook", h.BillingStatement.StripeWebhook)
+}
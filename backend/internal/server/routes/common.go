@@ -1,7 +1,12 @@
 package routes
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,3 +35,24 @@ func RegisterCommonRoutes(r *gin.Engine) {
 		})
 	})
 }
+
+// RegisterMetricsRoutes 按配置注册 Prometheus 抓取接口，并开启指标记录；未启用时不注册路由。
+func RegisterMetricsRoutes(r *gin.Engine, cfg config.MetricsConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	metrics.SetEnabled(true)
+
+	handler := metrics.Handler()
+	token := strings.TrimSpace(cfg.BearerToken)
+	r.GET("/metrics", func(c *gin.Context) {
+		if token != "" {
+			got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(token)) != 1 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	})
+}
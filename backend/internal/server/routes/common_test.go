@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func serveMetrics(router *gin.Engine, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRegisterMetricsRoutes_DisabledNotRegistered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterMetricsRoutes(router, config.MetricsConfig{})
+
+	require.Equal(t, http.StatusNotFound, serveMetrics(router, "").Code)
+	require.False(t, metrics.Enabled())
+}
+
+func TestRegisterMetricsRoutes_BearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+	router := gin.New()
+	RegisterMetricsRoutes(router, config.MetricsConfig{Enabled: true, BearerToken: "scrape-secret"})
+
+	require.True(t, metrics.Enabled())
+	require.Equal(t, http.StatusUnauthorized, serveMetrics(router, "").Code)
+	require.Equal(t, http.StatusUnauthorized, serveMetrics(router, "Bearer wrong").Code)
+	require.Equal(t, http.StatusUnauthorized, serveMetrics(router, "scrape-secret").Code)
+
+	w := serveMetrics(router, "Bearer scrape-secret")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "go_goroutines")
+}
+
+func TestRegisterMetricsRoutes_NoToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+	router := gin.New()
+	RegisterMetricsRoutes(router, config.MetricsConfig{Enabled: true})
+
+	require.Equal(t, http.StatusOK, serveMetrics(router, "").Code)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterConfigSyncRoutes 注册配置同步快照接口（primary 对 replica 暴露，共享令牌鉴权）
+func RegisterConfigSyncRoutes(v1 *gin.RouterGroup, h *handler.Handlers) {
+	v1.GET("/config-sync/snapshot", h.Admin.ConfigSync.Snapshot)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
 // RegisterGatewayRoutes 注册 API 网关路由（Claude/OpenAI/Gemini 兼容）
@@ -19,13 +20,32 @@ func RegisterGatewayRoutes(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	apiKeyTraceService *service.APIKeyTraceService,
 	settingService *service.SettingService,
 	cfg *config.Config,
+	redisClient *redis.Client,
 ) {
 	bodyLimit := middleware.RequestBodyLimit(cfg.Gateway.MaxBodySize)
 	clientRequestID := middleware.ClientRequestID()
 	opsErrorLogger := handler.OpsErrorLoggerMiddleware(opsService)
 	endpointNorm := handler.InboundEndpointMiddleware()
+	responseGuard := handler.ResponseContentGuardMiddleware(cfg)
+	openAIStrictErrors := handler.OpenAIStrictErrorMiddleware(cfg)
+	anthropicStrictErrors := handler.AnthropicStrictErrorMiddleware(cfg)
+	apiKeyTrace := handler.APIKeyTraceMiddleware(apiKeyTraceService)
+	debugEcho := handler.DebugEchoMiddleware()
+	// flush 合并位于最外层，直接面向客户端连接
+	streamFlush := handler.StreamFlushMiddleware(cfg)
+	// 续传缓冲位于输出后处理之外，缓存并重放的是客户端最终收到的事件
+	streamReplay := handler.StreamReplayMiddleware()
+	reasoningEvents := handler.ReasoningEventsMiddleware(cfg)
+	outputPostprocess := handler.OutputPostprocessMiddleware()
+	// 仅挂在生成类端点上，批量任务/查询类端点不合并
+	coalesce := handler.RequestCoalescingMiddleware(cfg, h.Gateway)
+	// 确定性请求的响应缓存位于合并之前：命中时直接返回，未命中的相同请求再进入合并
+	responseCache := handler.ResponseCacheMiddleware(cfg, redisClient, h.Gateway)
+	// 会话压缩请求按输入合并（含裸 /responses 上的 body-signal compact）
+	compactCoalesce := handler.CompactCoalescingMiddleware(cfg, h.Gateway)
 
 	// 未分组 Key 拦截中间件（按协议格式区分错误响应）
 	requireGroupAnthropic := middleware.RequireGroupAssignment(settingService, middleware.AnthropicErrorWriter)
@@ -58,6 +78,46 @@ func RegisterGatewayRoutes(
 			})
 		}
 	}
+	audioHandler := func(handle gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if getGroupPlatform(c) != service.PlatformOpenAI {
+				service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"type":    "not_found_error",
+						"message": "Audio API is not supported for this platform",
+					},
+				})
+				return
+			}
+			handle(c)
+		}
+	}
+	audioTranscriptionsHandler := audioHandler(h.OpenAIGateway.AudioTranscriptions)
+	audioSpeechHandler := audioHandler(h.OpenAIGateway.AudioSpeech)
+	batchHandler := func(handle gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if getGroupPlatform(c) != service.PlatformOpenAI {
+				service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"type":    "not_found_error",
+						"message": "Files and Batch API are not supported for this platform",
+					},
+				})
+				return
+			}
+			handle(c)
+		}
+	}
+	fileUploadHandler := batchHandler(h.OpenAIGateway.FileUpload)
+	fileListHandler := batchHandler(h.OpenAIGateway.FileList)
+	fileRetrieveHandler := batchHandler(h.OpenAIGateway.FileRetrieve)
+	fileDeleteHandler := batchHandler(h.OpenAIGateway.FileDelete)
+	fileContentHandler := batchHandler(h.OpenAIGateway.FileContent)
+	batchCreateHandler := batchHandler(h.OpenAIGateway.BatchCreate)
+	batchRetrieveHandler := batchHandler(h.OpenAIGateway.BatchRetrieve)
+	batchCancelHandler := batchHandler(h.OpenAIGateway.BatchCancel)
 	videoGenerationHandler := func(c *gin.Context) {
 		if getGroupPlatform(c) == service.PlatformGrok {
 			h.OpenAIGateway.GrokVideoGeneration(c)
@@ -88,13 +148,13 @@ func RegisterGatewayRoutes(
 	gateway := r.Group("/v1")
 	gateway.Use(bodyLimit)
 	gateway.Use(clientRequestID)
-	gateway.Use(opsErrorLogger)
+	gateway.Use(opsErrorLogger, openAIStrictErrors, anthropicStrictErrors, responseGuard)
 	gateway.Use(endpointNorm)
 	gateway.Use(gin.HandlerFunc(apiKeyAuth))
-	gateway.Use(requireGroupAnthropic)
+	gateway.Use(requireGroupAnthropic, apiKeyTrace, debugEcho, streamFlush, streamReplay, reasoningEvents, outputPostprocess)
 	{
 		// /v1/messages: auto-route based on group platform
-		gateway.POST("/messages", func(c *gin.Context) {
+		gateway.POST("/messages", responseCache, coalesce, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.Messages(c)
 				return
@@ -132,15 +192,16 @@ func RegisterGatewayRoutes(
 			h.Gateway.Models(c)
 		})
 		gateway.GET("/usage", h.Gateway.Usage)
+		gateway.GET("/usage/threads/:thread_id", h.Gateway.ThreadUsage)
 		// OpenAI Responses API: auto-route based on group platform
-		gateway.POST("/responses", func(c *gin.Context) {
+		gateway.POST("/responses", responseCache, coalesce, compactCoalesce, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.Responses(c)
 				return
 			}
 			h.Gateway.Responses(c)
 		})
-		gateway.POST("/responses/*subpath", func(c *gin.Context) {
+		gateway.POST("/responses/*subpath", compactCoalesce, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.Responses(c)
 				return
@@ -151,7 +212,7 @@ func RegisterGatewayRoutes(
 			h.OpenAIGateway.ResponsesWebSocket(c)
 		})
 		// OpenAI Chat Completions API: auto-route based on group platform
-		gateway.POST("/chat/completions", func(c *gin.Context) {
+		gateway.POST("/chat/completions", responseCache, coalesce, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.ChatCompletions(c)
 				return
@@ -171,6 +232,16 @@ func RegisterGatewayRoutes(
 			}
 			h.OpenAIGateway.Embeddings(c)
 		})
+		gateway.POST("/audio/transcriptions", audioTranscriptionsHandler)
+		gateway.POST("/audio/speech", audioSpeechHandler)
+		gateway.POST("/files", fileUploadHandler)
+		gateway.GET("/files", fileListHandler)
+		gateway.GET("/files/:file_id", fileRetrieveHandler)
+		gateway.DELETE("/files/:file_id", fileDeleteHandler)
+		gateway.GET("/files/:file_id/content", fileContentHandler)
+		gateway.POST("/batches", batchCreateHandler)
+		gateway.GET("/batches/:batch_id", batchRetrieveHandler)
+		gateway.POST("/batches/:batch_id/cancel", batchCancelHandler)
 		gateway.POST("/images/generations", imagesHandler)
 		gateway.POST("/images/edits", imagesHandler)
 		gateway.POST("/images/batches", h.BatchImage.Submit)
@@ -191,15 +262,15 @@ func RegisterGatewayRoutes(
 	gemini := r.Group("/v1beta")
 	gemini.Use(bodyLimit)
 	gemini.Use(clientRequestID)
-	gemini.Use(opsErrorLogger)
+	gemini.Use(opsErrorLogger, responseGuard)
 	gemini.Use(endpointNorm)
 	gemini.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
-	gemini.Use(requireGroupGoogle)
+	gemini.Use(requireGroupGoogle, apiKeyTrace, debugEcho, streamFlush, streamReplay, outputPostprocess)
 	{
 		gemini.GET("/models", h.Gateway.GeminiV1BetaListModels)
 		gemini.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
 		// Gin treats ":" as a param marker, but Gemini uses "{model}:{action}" in the same segment.
-		gemini.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		gemini.POST("/models/*modelAction", responseCache, coalesce, h.Gateway.GeminiV1BetaModels)
 	}
 
 	// OpenAI Responses API（不带v1前缀的别名）— auto-route based on group platform
@@ -210,30 +281,30 @@ func RegisterGatewayRoutes(
 		}
 		h.Gateway.Responses(c)
 	}
-	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, streamFlush, streamReplay, reasoningEvents, outputPostprocess, responseCache, coalesce, compactCoalesce, responsesHandler)
+	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, outputPostprocess, compactCoalesce, responsesHandler)
+	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, func(c *gin.Context) {
 		h.OpenAIGateway.ResponsesWebSocket(c)
 	})
 	codexDirect := r.Group("/backend-api/codex")
-	codexDirect.Use(bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic)
+	codexDirect.Use(bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho)
 	{
-		codexDirect.POST("/responses", responsesHandler)
-		codexDirect.POST("/responses/*subpath", responsesHandler)
+		codexDirect.POST("/responses", streamFlush, streamReplay, reasoningEvents, outputPostprocess, compactCoalesce, responsesHandler)
+		codexDirect.POST("/responses/*subpath", outputPostprocess, compactCoalesce, responsesHandler)
 		codexDirect.GET("/responses", func(c *gin.Context) {
 			h.OpenAIGateway.ResponsesWebSocket(c)
 		})
 		codexDirect.GET("/models", h.OpenAIGateway.CodexModels)
 	}
 	// OpenAI Chat Completions API（不带v1前缀的别名）— auto-route based on group platform
-	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, streamFlush, streamReplay, reasoningEvents, outputPostprocess, responseCache, coalesce, func(c *gin.Context) {
 		if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 			h.OpenAIGateway.ChatCompletions(c)
 			return
 		}
 		h.Gateway.ChatCompletions(c)
 	})
-	r.POST("/embeddings", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	r.POST("/embeddings", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, func(c *gin.Context) {
 		if getGroupPlatform(c) != service.PlatformOpenAI {
 			service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
 			c.JSON(http.StatusNotFound, gin.H{
@@ -246,25 +317,35 @@ func RegisterGatewayRoutes(
 		}
 		h.OpenAIGateway.Embeddings(c)
 	})
-	r.POST("/images/generations", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, imagesHandler)
-	r.POST("/images/edits", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, imagesHandler)
-	r.POST("/videos/generations", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, videoGenerationHandler)
-	r.GET("/videos/:request_id", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, videoStatusHandler)
+	r.POST("/audio/transcriptions", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, audioTranscriptionsHandler)
+	r.POST("/audio/speech", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, audioSpeechHandler)
+	r.POST("/files", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, fileUploadHandler)
+	r.GET("/files", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, fileListHandler)
+	r.GET("/files/:file_id", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, fileRetrieveHandler)
+	r.DELETE("/files/:file_id", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, fileDeleteHandler)
+	r.GET("/files/:file_id/content", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, fileContentHandler)
+	r.POST("/batches", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, batchCreateHandler)
+	r.GET("/batches/:batch_id", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, batchRetrieveHandler)
+	r.POST("/batches/:batch_id/cancel", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, batchCancelHandler)
+	r.POST("/images/generations", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, imagesHandler)
+	r.POST("/images/edits", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, imagesHandler)
+	r.POST("/videos/generations", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, videoGenerationHandler)
+	r.GET("/videos/:request_id", bodyLimit, clientRequestID, opsErrorLogger, openAIStrictErrors, responseGuard, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, videoStatusHandler)
 
 	// Antigravity 模型列表
-	r.GET("/antigravity/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.Gateway.AntigravityModels)
+	r.GET("/antigravity/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, apiKeyTrace, debugEcho, h.Gateway.AntigravityModels)
 
 	// Antigravity 专用路由（仅使用 antigravity 账户，不混合调度）
 	antigravityV1 := r.Group("/antigravity/v1")
 	antigravityV1.Use(bodyLimit)
 	antigravityV1.Use(clientRequestID)
-	antigravityV1.Use(opsErrorLogger)
+	antigravityV1.Use(opsErrorLogger, anthropicStrictErrors, responseGuard)
 	antigravityV1.Use(endpointNorm)
 	antigravityV1.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1.Use(gin.HandlerFunc(apiKeyAuth))
-	antigravityV1.Use(requireGroupAnthropic)
+	antigravityV1.Use(requireGroupAnthropic, apiKeyTrace, debugEcho, streamFlush, streamReplay, reasoningEvents, outputPostprocess)
 	{
-		antigravityV1.POST("/messages", h.Gateway.Messages)
+		antigravityV1.POST("/messages", responseCache, coalesce, h.Gateway.Messages)
 		antigravityV1.POST("/messages/count_tokens", h.Gateway.CountTokens)
 		antigravityV1.GET("/models", h.Gateway.AntigravityModels)
 		antigravityV1.GET("/usage", h.Gateway.Usage)
@@ -273,15 +354,15 @@ func RegisterGatewayRoutes(
 	antigravityV1Beta := r.Group("/antigravity/v1beta")
 	antigravityV1Beta.Use(bodyLimit)
 	antigravityV1Beta.Use(clientRequestID)
-	antigravityV1Beta.Use(opsErrorLogger)
+	antigravityV1Beta.Use(opsErrorLogger, responseGuard)
 	antigravityV1Beta.Use(endpointNorm)
 	antigravityV1Beta.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1Beta.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
-	antigravityV1Beta.Use(requireGroupGoogle)
+	antigravityV1Beta.Use(requireGroupGoogle, apiKeyTrace, debugEcho, streamFlush, streamReplay, outputPostprocess)
 	{
 		antigravityV1Beta.GET("/models", h.Gateway.GeminiV1BetaListModels)
 		antigravityV1Beta.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
-		antigravityV1Beta.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		antigravityV1Beta.POST("/models/*modelAction", responseCache, coalesce, h.Gateway.GeminiV1BetaModels)
 	}
 
 }
@@ -41,7 +41,9 @@ func newGatewayRoutesTestRouter(platform ...string) *gin.Engine {
 		nil,
 		nil,
 		nil,
+		nil,
 		&config.Config{},
+		nil,
 	)
 
 	return router
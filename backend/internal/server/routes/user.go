@@ -43,6 +43,30 @@ func RegisterUserRoutes(
 				notifyEmail.DELETE("", h.User.RemoveNotifyEmail)
 			}
 
+			// 用量告警（用户自助 webhook / 邮件通知）
+			usageAlerts := user.Group("/usage-alerts")
+			{
+				usageAlerts.GET("", h.UsageAlert.List)
+				usageAlerts.POST("", h.UsageAlert.Create)
+				usageAlerts.PUT("/:id", h.UsageAlert.Update)
+				usageAlerts.DELETE("/:id", h.UsageAlert.Delete)
+				usageAlerts.POST("/:id/test", h.UsageAlert.Test)
+			}
+
+			// 月度账单
+			statements := user.Group("/statements")
+			{
+				statements.GET("", h.BillingStatement.List)
+				statements.GET("/:id", h.BillingStatement.Get)
+			}
+
+			// 余额概览与流水
+			balance := user.Group("/balance")
+			{
+				balance.GET("", h.Balance.GetSummary)
+				balance.GET("/history", h.Balance.GetHistory)
+			}
+
 			// TOTP 双因素认证
 			totp := user.Group("/totp")
 			{
@@ -61,10 +85,25 @@ func RegisterUserRoutes(
 			keys.GET("", h.APIKey.List)
 			keys.GET("/:id", h.APIKey.GetByID)
 			keys.POST("", h.APIKey.Create)
+			keys.POST("/guest", h.APIKey.CreateGuest)
 			keys.PUT("/:id", h.APIKey.Update)
 			keys.DELETE("/:id", h.APIKey.Delete)
 		}
 
+		// API Key 项目（多个 Key 共享额度、合并用量）
+		projects := authenticated.Group("/projects")
+		{
+			projects.GET("", h.Project.List)
+			projects.GET("/:id", h.Project.GetByID)
+			projects.POST("", h.Project.Create)
+			projects.PUT("/:id", h.Project.Update)
+			projects.DELETE("/:id", h.Project.Delete)
+			projects.POST("/:id/keys", h.Project.AddKeys)
+			projects.DELETE("/:id/keys", h.Project.RemoveKeys)
+			projects.POST("/:id/reset-quota", h.Project.ResetQuota)
+			projects.GET("/:id/usage", h.Project.Usage)
+		}
+
 		// 用户可用分组（非管理员接口）
 		groups := authenticated.Group("/groups")
 		{
@@ -86,6 +125,7 @@ func RegisterUserRoutes(
 			usage.GET("/errors/:id", h.Usage.GetErrorDetail)
 			usage.GET("/:id", h.Usage.GetByID)
 			usage.GET("/stats", h.Usage.Stats)
+			usage.GET("/threads/:thread_id", h.Usage.ThreadCost)
 			// User dashboard endpoints
 			usage.GET("/dashboard/stats", h.Usage.DashboardStats)
 			usage.GET("/dashboard/trend", h.Usage.DashboardTrend)
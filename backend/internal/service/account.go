@@ -85,6 +85,8 @@ type OpenAIEndpointCapability string
 const (
 	OpenAIEndpointCapabilityChatCompletions OpenAIEndpointCapability = "chat_completions"
 	OpenAIEndpointCapabilityEmbeddings      OpenAIEndpointCapability = "embeddings"
+	OpenAIEndpointCapabilityAudio           OpenAIEndpointCapability = "audio"
+	OpenAIEndpointCapabilityBatch           OpenAIEndpointCapability = "batch"
 )
 
 const openAIEndpointCapabilitiesCredentialKey = "openai_capabilities"
@@ -728,29 +730,23 @@ func mappingSupportsRequestedModel(mapping map[string]string, requestedModel str
 	if requestedModel == "" {
 		return false
 	}
-	if _, exists := mapping[requestedModel]; exists {
-		return true
-	}
-	for pattern := range mapping {
-		if matchWildcard(pattern, requestedModel) {
-			return true
-		}
-	}
-	return false
+	_, ok := matchModelMappingMap(mapping, requestedModel)
+	return ok
 }
 
 func resolveRequestedModelInMapping(mapping map[string]string, requestedModel string) (mappedModel string, matched bool) {
 	if requestedModel == "" {
 		return "", false
 	}
-	if mappedModel, exists := mapping[requestedModel]; exists {
-		return mappedModel, true
+	hit, ok := matchModelMappingMap(mapping, requestedModel)
+	if !ok {
+		return requestedModel, false
 	}
-	return matchWildcardMappingResult(mapping, requestedModel)
+	return hit.target, true
 }
 
-// IsModelSupported 检查模型是否在 model_mapping 中（支持通配符）
-// 如果未配置 mapping，返回 true（允许所有模型）。
+// IsModelSupported 检查模型是否命中 model_mapping_rules 或 model_mapping（支持通配符/正则）
+// 如果未配置 mapping，返回 true（允许所有模型）；仅配置有序规则时，未命中的模型按未配置处理。
 //
 // 例外：OpenAI OAuth 账号（Codex 上游）的空映射会排除明确属于其他厂商
 // 家族的模型（deepseek-*/glm-* 等）——转发阶段 normalizeOpenAIModelForUpstream
@@ -758,6 +754,9 @@ func resolveRequestedModelInMapping(mapping map[string]string, requestedModel st
 // 请求卡死在该账号上、无法 failover 到真正支持该模型的 API Key 账号（#3662）。
 // 未知/自定义别名仍保持允许（兼容渠道级映射），见 isOpenAIOAuthServableModel。
 func (a *Account) IsModelSupported(requestedModel string) bool {
+	if _, _, ok := a.resolveModelMappingHit(requestedModel); ok {
+		return true
+	}
 	mapping := a.GetModelMapping()
 	if len(mapping) == 0 {
 		if a.IsOpenAIOAuth() && !a.IsOpenAIPassthroughEnabled() {
@@ -765,14 +764,10 @@ func (a *Account) IsModelSupported(requestedModel string) bool {
 		}
 		return true // 无映射 = 允许所有
 	}
-	if mappingSupportsRequestedModel(mapping, requestedModel) {
-		return true
-	}
-	normalized := normalizeRequestedModelForLookup(a.Platform, requestedModel)
-	return normalized != requestedModel && mappingSupportsRequestedModel(mapping, normalized)
+	return false
 }
 
-// GetMappedModel 获取映射后的模型名（支持通配符，最长优先匹配）
+// GetMappedModel 获取映射后的模型名（有序规则首个命中优先；model_mapping 精确优先、模式最长优先）
 // 如果未配置 mapping，返回原始模型名
 func (a *Account) GetMappedModel(requestedModel string) string {
 	mappedModel, _ := a.ResolveMappedModel(requestedModel)
@@ -782,20 +777,11 @@ func (a *Account) GetMappedModel(requestedModel string) string {
 // ResolveMappedModel 获取映射后的模型名，并返回是否命中了账号级映射。
 // matched=true 表示命中了精确映射或通配符映射，即使映射结果与原模型名相同。
 func (a *Account) ResolveMappedModel(requestedModel string) (mappedModel string, matched bool) {
-	mapping := a.GetModelMapping()
-	if len(mapping) == 0 {
+	hit, _, ok := a.resolveModelMappingHit(requestedModel)
+	if !ok {
 		return requestedModel, false
 	}
-	if mappedModel, matched := resolveRequestedModelInMapping(mapping, requestedModel); matched {
-		return mappedModel, true
-	}
-	normalized := normalizeRequestedModelForLookup(a.Platform, requestedModel)
-	if normalized != requestedModel {
-		if mappedModel, matched := resolveRequestedModelInMapping(mapping, normalized); matched {
-			return mappedModel, true
-		}
-	}
-	return requestedModel, false
+	return hit.target, true
 }
 
 // GetOpenAICompactMode returns the compact routing mode for an OpenAI account.
@@ -934,39 +920,19 @@ func matchAntigravityWildcard(pattern, str string) bool {
 	return pattern == str
 }
 
-// matchWildcard 通用通配符匹配（仅支持末尾 *）
-// 复用 Antigravity 的通配符逻辑，供其他平台使用
+// matchWildcard 通用模式匹配（* 可出现在任意位置，re: 前缀为正则），供其他平台使用
 func matchWildcard(pattern, str string) bool {
-	return matchAntigravityWildcard(pattern, str)
+	_, ok := matchModelMappingPattern(pattern, "", str)
+	return ok
 }
 
+// matchWildcardMappingResult 精确优先，其次模式（通配符/正则）最长优先；target 展开捕获组。
 func matchWildcardMappingResult(mapping map[string]string, requestedModel string) (string, bool) {
-	// 收集所有匹配的 pattern，按长度降序排序（最长优先）
-	type patternMatch struct {
-		pattern string
-		target  string
-	}
-	var matches []patternMatch
-
-	for pattern, target := range mapping {
-		if matchWildcard(pattern, requestedModel) {
-			matches = append(matches, patternMatch{pattern, target})
-		}
-	}
-
-	if len(matches) == 0 {
+	hit, ok := matchModelMappingMap(mapping, requestedModel)
+	if !ok {
 		return requestedModel, false // 无匹配，返回原始模型名
 	}
-
-	// 按 pattern 长度降序排序
-	sort.Slice(matches, func(i, j int) bool {
-		if len(matches[i].pattern) != len(matches[j].pattern) {
-			return len(matches[i].pattern) > len(matches[j].pattern)
-		}
-		return matches[i].pattern < matches[j].pattern
-	})
-
-	return matches[0].target, true
+	return hit.target, true
 }
 
 func (a *Account) IsCustomErrorCodesEnabled() bool {
@@ -1360,7 +1326,7 @@ func (a *Account) SupportsOpenAIEndpointCapability(capability OpenAIEndpointCapa
 	}
 	switch capability {
 	case OpenAIEndpointCapabilityChatCompletions:
-	case OpenAIEndpointCapabilityEmbeddings:
+	case OpenAIEndpointCapabilityEmbeddings, OpenAIEndpointCapabilityAudio, OpenAIEndpointCapabilityBatch:
 		if a.Type != AccountTypeAPIKey {
 			return false
 		}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/openai"
+)
+
+// AccountExtraCredentialCheck 最近一次凭证校验结果在 accounts.extra 中的键。
+const AccountExtraCredentialCheck = "credential_check"
+
+// 凭证校验状态。
+const (
+	CredentialCheckValid      = "valid"      // 上游接受了凭证
+	CredentialCheckInvalid    = "invalid"    // 上游拒绝（401/403）或凭证缺失
+	CredentialCheckExpired    = "expired"    // 访问令牌已过期且无法刷新
+	CredentialCheckUnverified = "unverified" // 该账号类型无法在线探测，或上游暂时不可用
+)
+
+// 订阅等级的来源。
+const (
+	CredentialTierSourceIDToken     = "id_token"
+	CredentialTierSourceCredentials = "credentials"
+)
+
+// AccountCredentialCheck 账号凭证校验结果：令牌有效性、订阅等级、授权范围与可访问模型。
+// 以 JSON 保存在 accounts.extra.credential_check，供管理端展示。
+type AccountCredentialCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message,omitempty"`
+	// UpstreamStatus 探测请求的上游状态码，未发出请求时为 0
+	UpstreamStatus int        `json:"upstream_status,omitempty"`
+	TokenExpiresAt *time.Time `json:"token_expires_at,omitempty"`
+	Refreshable    bool       `json:"refreshable"`
+
+	Tier       string   `json:"tier,omitempty"`
+	TierSource string   `json:"tier_source,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+
+	Models      []string `json:"models,omitempty"`
+	ModelsError string   `json:"models_error,omitempty"`
+}
+
+// VerifyAccountCredentials 按需校验账号凭证并把结果写回账号 extra。
+// 有效性以上游模型列表探测为准（同时得到可访问模型）；无法探测的账号类型按令牌过期时间给出 unverified/expired。
+func (s *AccountTestService) VerifyAccountCredentials(ctx context.Context, account *Account) (*AccountCredentialCheck, error) {
+	check := inspectAccountCredentials(account, time.Now())
+	if check.Status == "" {
+		models, err := s.FetchUpstreamSupportedModels(ctx, account)
+		applyCredentialProbeResult(check, models, err)
+	}
+
+	if s.accountRepo != nil {
+		if err := s.accountRepo.UpdateExtra(ctx, account.ID, map[string]any{AccountExtraCredentialCheck: check}); err != nil {
+			return nil, err
+		}
+	}
+	return check, nil
+}
+
+// inspectAccountCredentials 离线检查凭证：提取等级/范围/过期时间；凭证缺失或已过期且不可刷新时直接给出结论（Status 非空）。
+func inspectAccountCredentials(account *Account, now time.Time) *AccountCredentialCheck {
+	check := &AccountCredentialCheck{CheckedAt: now.UTC()}
+	check.Tier, check.TierSource = detectCredentialTier(account)
+	check.Scopes = detectCredentialScopes(account)
+
+	if !account.IsOAuth() {
+		return check
+	}
+	check.Refreshable = strings.TrimSpace(account.GetCredential("refresh_token")) != ""
+	check.TokenExpiresAt = account.GetCredentialAsTime("expires_at")
+	if strings.TrimSpace(account.GetCredential("access_token")) == "" && !check.Refreshable {
+		check.Status = CredentialCheckInvalid
+		check.Message = "No access token or refresh token is stored"
+		return check
+	}
+	if check.TokenExpiresAt != nil && !check.TokenExpiresAt.After(now) && !check.Refreshable {
+		check.Status = CredentialCheckExpired
+		check.Message = "Access token has expired and no refresh token is stored"
+	}
+	return check
+}
+
+// applyCredentialProbeResult 根据模型列表探测结果判定有效性。
+func applyCredentialProbeResult(check *AccountCredentialCheck, models []string, err error) {
+	if err == nil {
+		check.Status = CredentialCheckValid
+		check.Models = models
+		return
+	}
+	var syncErr *UpstreamModelSyncError
+	if !errors.As(err, &syncErr) {
+		check.Status = CredentialCheckUnverified
+		check.ModelsError = err.Error()
+		return
+	}
+	check.ModelsError = syncErr.SafeMessage()
+	check.UpstreamStatus = syncErr.StatusCode
+	switch {
+	case syncErr.StatusCode == http.StatusUnauthorized || syncErr.StatusCode == http.StatusForbidden:
+		check.Status = CredentialCheckInvalid
+		check.Message = "Upstream rejected the credentials"
+	case syncErr.Kind == UpstreamModelSyncErrorConfiguration && syncErr.StatusCode == 0 && strings.HasPrefix(syncErr.Message, "No "):
+		// 构造请求时发现凭证缺失（如 "No OpenAI API key is available"）
+		check.Status = CredentialCheckInvalid
+		check.Message = syncErr.Message
+	case syncErr.StatusCode > 0 && syncErr.StatusCode < http.StatusInternalServerError && syncErr.StatusCode != http.StatusTooManyRequests:
+		// 上游已完成鉴权但拒绝了模型列表请求（如 404），凭证本身可用
+		check.Status = CredentialCheckValid
+	default:
+		check.Status = CredentialCheckUnverified
+		if syncErr.Kind == UpstreamModelSyncErrorUnsupported {
+			check.Message = "Live verification is not supported for this account type; validity is based on token expiry only"
+		}
+	}
+}
+
+// detectCredentialTier 提取订阅等级（Plus/Pro/Team 等）；无法识别时返回空。
+func detectCredentialTier(account *Account) (tier, source string) {
+	if account.IsOpenAIOAuth() {
+		if idToken := strings.TrimSpace(account.GetCredential("id_token")); idToken != "" {
+			if claims, err := openai.DecodeIDToken(idToken); err == nil && claims.OpenAIAuth != nil && claims.OpenAIAuth.ChatGPTPlanType != "" {
+				return claims.OpenAIAuth.ChatGPTPlanType, CredentialTierSourceIDToken
+			}
+		}
+	}
+	for _, key := range []string{"plan_type", "tier_id"} {
+		if v := strings.TrimSpace(account.GetCredential(key)); v != "" {
+			return v, CredentialTierSourceCredentials
+		}
+	}
+	return "", ""
+}
+
+// detectCredentialScopes 提取授权范围：优先取 OAuth 令牌响应保存的 scope，其次解析 access_token JWT 的 scp/scope 声明。
+func detectCredentialScopes(account *Account) []string {
+	if scope := strings.TrimSpace(account.GetCredential("scope")); scope != "" {
+		return strings.Fields(scope)
+	}
+	if !account.IsOAuth() {
+		return nil
+	}
+	return parseJWTScopes(account.GetCredential("access_token"))
+}
+
+func parseJWTScopes(token string) []string {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	var claims struct {
+		Scp   json.RawMessage `json:"scp"`
+		Scope string          `json:"scope"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+	if len(claims.Scp) > 0 {
+		var list []string
+		if err := json.Unmarshal(claims.Scp, &list); err == nil {
+			return list
+		}
+		var joined string
+		if err := json.Unmarshal(claims.Scp, &joined); err == nil {
+			return strings.Fields(joined)
+		}
+	}
+	return strings.Fields(claims.Scope)
+}
@@ -0,0 +1,162 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type credentialCheckRepoStub struct {
+	mockAccountRepoForGemini
+	updatedID    int64
+	updatedExtra map[string]any
+}
+
+func (r *credentialCheckRepoStub) UpdateExtra(_ context.Context, id int64, updates map[string]any) error {
+	r.updatedID = id
+	r.updatedExtra = updates
+	return nil
+}
+
+func credentialCheckTestJWT(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestVerifyAccountCredentials_ValidAPIKeyListsModels(t *testing.T) {
+	repo := &credentialCheckRepoStub{}
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"data":[{"id":"gpt-5"},{"id":"o3"}]}`)),
+	}}
+	svc := &AccountTestService{accountRepo: repo, httpUpstream: upstream, cfg: upstreamModelSyncTestConfig()}
+
+	check, err := svc.VerifyAccountCredentials(context.Background(), &Account{
+		ID:       3,
+		Platform: PlatformOpenAI,
+		Type:     AccountTypeAPIKey,
+		Credentials: map[string]any{
+			"api_key":  "openai-key",
+			"base_url": "https://openai.example.com/v1",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, CredentialCheckValid, check.Status)
+	require.Equal(t, []string{"gpt-5", "o3"}, check.Models)
+	require.Equal(t, int64(3), repo.updatedID)
+	require.Same(t, check, repo.updatedExtra[AccountExtraCredentialCheck])
+}
+
+func TestVerifyAccountCredentials_UpstreamRejects(t *testing.T) {
+	repo := &credentialCheckRepoStub{}
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Body:       io.NopCloser(strings.NewReader(`{"error":"invalid key"}`)),
+	}}
+	svc := &AccountTestService{accountRepo: repo, httpUpstream: upstream, cfg: upstreamModelSyncTestConfig()}
+
+	check, err := svc.VerifyAccountCredentials(context.Background(), &Account{
+		ID:          4,
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Credentials: map[string]any{"api_key": "bad", "base_url": "https://openai.example.com/v1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, CredentialCheckInvalid, check.Status)
+	require.Equal(t, http.StatusUnauthorized, check.UpstreamStatus)
+	require.Empty(t, check.Models)
+}
+
+func TestVerifyAccountCredentials_ExpiredOAuthWithoutRefreshToken(t *testing.T) {
+	repo := &credentialCheckRepoStub{}
+	upstream := &httpUpstreamRecorder{}
+	svc := &AccountTestService{accountRepo: repo, httpUpstream: upstream, cfg: upstreamModelSyncTestConfig()}
+
+	check, err := svc.VerifyAccountCredentials(context.Background(), &Account{
+		ID:       5,
+		Platform: PlatformAnthropic,
+		Type:     AccountTypeOAuth,
+		Credentials: map[string]any{
+			"access_token": "expired-token",
+			"expires_at":   time.Now().Add(-time.Hour).Unix(),
+			"scope":        "user:inference user:profile",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, CredentialCheckExpired, check.Status)
+	require.False(t, check.Refreshable)
+	require.NotNil(t, check.TokenExpiresAt)
+	require.Equal(t, []string{"user:inference", "user:profile"}, check.Scopes)
+	require.Nil(t, upstream.lastReq, "expired credentials should not be probed")
+	require.NotNil(t, repo.updatedExtra[AccountExtraCredentialCheck])
+}
+
+func TestInspectAccountCredentials_OpenAIOAuthTierAndScopes(t *testing.T) {
+	idToken := credentialCheckTestJWT(t, map[string]any{
+		"https://api.openai.com/auth": map[string]any{"chatgpt_plan_type": "pro"},
+	})
+	accessToken := credentialCheckTestJWT(t, map[string]any{"scp": []string{"openid", "offline_access"}})
+
+	check := inspectAccountCredentials(&Account{
+		Platform: PlatformOpenAI,
+		Type:     AccountTypeOAuth,
+		Credentials: map[string]any{
+			"access_token":  accessToken,
+			"refresh_token": "rt",
+			"id_token":      idToken,
+			"plan_type":     "plus",
+			"expires_at":    time.Now().Add(-time.Minute).Unix(),
+		},
+	}, time.Now())
+
+	require.Empty(t, check.Status, "expired but refreshable tokens still need a live probe")
+	require.True(t, check.Refreshable)
+	require.Equal(t, "pro", check.Tier)
+	require.Equal(t, CredentialTierSourceIDToken, check.TierSource)
+	require.Equal(t, []string{"openid", "offline_access"}, check.Scopes)
+}
+
+func TestInspectAccountCredentials_TierFromCredentials(t *testing.T) {
+	check := inspectAccountCredentials(&Account{
+		Platform:    PlatformGemini,
+		Type:        AccountTypeOAuth,
+		Credentials: map[string]any{"access_token": "tok", "tier_id": "standard-tier"},
+	}, time.Now())
+	require.Equal(t, "standard-tier", check.Tier)
+	require.Equal(t, CredentialTierSourceCredentials, check.TierSource)
+}
+
+func TestApplyCredentialProbeResult(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unsupported", newUpstreamModelSyncUnsupportedError("not supported", nil), CredentialCheckUnverified},
+		{"missing key", newUpstreamModelSyncConfigError("No OpenAI API key is available", nil), CredentialCheckInvalid},
+		{"forbidden", &UpstreamModelSyncError{Kind: UpstreamModelSyncErrorUpstream, Message: "x", StatusCode: http.StatusForbidden}, CredentialCheckInvalid},
+		{"not found", &UpstreamModelSyncError{Kind: UpstreamModelSyncErrorUpstream, Message: "x", StatusCode: http.StatusNotFound}, CredentialCheckValid},
+		{"rate limited", &UpstreamModelSyncError{Kind: UpstreamModelSyncErrorUpstream, Message: "x", StatusCode: http.StatusTooManyRequests}, CredentialCheckUnverified},
+		{"network", newUpstreamModelSyncUpstreamError("Failed to request upstream model list", io.ErrUnexpectedEOF), CredentialCheckUnverified},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := &AccountCredentialCheck{}
+			applyCredentialProbeResult(check, nil, tc.err)
+			require.Equal(t, tc.want, check.Status)
+			require.NotEmpty(t, check.ModelsError)
+		})
+	}
+}
@@ -2,12 +2,53 @@ package service
 
 import "time"
 
+// 分组内故障转移层级：数值越小层级越高，调度只在更高层级账号全部不可用时启用更低层级。
+const (
+	AccountGroupTierPrimary   = 0
+	AccountGroupTierSecondary = 1
+	AccountGroupTierEmergency = 2
+)
+
 type AccountGroup struct {
 	AccountID int64
 	GroupID   int64
 	Priority  int
-	CreatedAt time.Time
+	Tier      int
+	// ConcurrencyShare 分组可占用的账号并发百分比（1-100），0 表示不限制
+	ConcurrencyShare int
+	CreatedAt        time.Time
 
 	Account *Account
 	Group   *Group
 }
+
+// GroupAccountTier 分组内单个账号的故障转移层级。
+type GroupAccountTier struct {
+	AccountID int64 `json:"account_id"`
+	Tier      int   `json:"tier"`
+}
+
+// GroupAccountConcurrencyShare 分组内单个账号的并发份额。
+type GroupAccountConcurrencyShare struct {
+	AccountID        int64 `json:"account_id"`
+	ConcurrencyShare int   `json:"concurrency_share"`
+}
+
+// IsValidAccountGroupTier 判断层级取值是否合法。
+func IsValidAccountGroupTier(tier int) bool {
+	return tier >= AccountGroupTierPrimary && tier <= AccountGroupTierEmergency
+}
+
+// AccountGroupTierName 返回层级的可读名称（用于日志与告警）。
+func AccountGroupTierName(tier int) string {
+	switch tier {
+	case AccountGroupTierPrimary:
+		return "primary"
+	case AccountGroupTierSecondary:
+		return "secondary"
+	case AccountGroupTierEmergency:
+		return "emergency"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// accountGroupShareRefreshInterval 并发份额配置的本地缓存刷新间隔；管理端修改后最多延迟该时长生效。
+const accountGroupShareRefreshInterval = 15 * time.Second
+
+// AccountGroupShareReader 读取全部配置了并发份额（ConcurrencyShare > 0）的账号分组绑定。
+type AccountGroupShareReader interface {
+	ListAccountGroupConcurrencyShares(ctx context.Context) ([]AccountGroup, error)
+}
+
+// AccountGroupShareCache 按 (账号, 分组) 单独计数的份额槽位；未实现时份额不生效。
+type AccountGroupShareCache interface {
+	AcquireAccountGroupSlot(ctx context.Context, accountID, groupID int64, maxConcurrency int, requestID string) (bool, error)
+	ReleaseAccountGroupSlot(ctx context.Context, accountID, groupID int64, requestID string) error
+}
+
+type accountGroupShareKey struct {
+	accountID int64
+	groupID   int64
+}
+
+type accountGroupShareSnapshot struct {
+	shares    map[accountGroupShareKey]int
+	expiresAt time.Time
+}
+
+// AccountGroupShareSlots 返回分组按份额可占用的账号槽位数。
+// 按 ceil 取整且至少为 1：并发较小的账号也不会因份额过小而对分组完全不可用。
+func AccountGroupShareSlots(maxConcurrency, share int) int {
+	if maxConcurrency <= 0 || share <= 0 || share >= 100 {
+		return 0
+	}
+	slots := int(math.Ceil(float64(maxConcurrency) * float64(share) / 100))
+	if slots < 1 {
+		slots = 1
+	}
+	return slots
+}
+
+func validateAccountGroupConcurrencyShare(share int) error {
+	if share < 0 || share > 100 {
+		return infraerrors.BadRequest("INVALID_CONCURRENCY_SHARE", "concurrency_share must be in [0, 100]")
+	}
+	return nil
+}
+
+// SetAccountGroupShareReader 设置并发份额配置来源；nil 表示关闭份额限制。
+func (s *ConcurrencyService) SetAccountGroupShareReader(reader AccountGroupShareReader) {
+	if s == nil {
+		return
+	}
+	s.shareReader = reader
+	s.shareSnapshot.Store(nil)
+}
+
+// accountGroupShare 返回当前请求所在分组对该账号的并发份额；未配置或无分组上下文时返回 0。
+func (s *ConcurrencyService) accountGroupShare(ctx context.Context, accountID int64) (int64, int) {
+	if s == nil || s.shareReader == nil || ctx == nil {
+		return 0, 0
+	}
+	group, ok := ctx.Value(ctxkey.Group).(*Group)
+	if !ok || group == nil || group.ID <= 0 {
+		return 0, 0
+	}
+	shares := s.loadAccountGroupShares(ctx)
+	return group.ID, shares[accountGroupShareKey{accountID: accountID, groupID: group.ID}]
+}
+
+// loadAccountGroupShares 读取份额配置快照，过期后同步刷新。
+func (s *ConcurrencyService) loadAccountGroupShares(ctx context.Context) map[accountGroupShareKey]int {
+	now := time.Now()
+	snapshot := s.shareSnapshot.Load()
+	if snapshot != nil && now.Before(snapshot.expiresAt) {
+		return snapshot.shares
+	}
+	value, _, _ := s.shareLoadGroup.Do("shares", func() (any, error) {
+		if current := s.shareSnapshot.Load(); current != nil && time.Now().Before(current.expiresAt) {
+			return current.shares, nil
+		}
+		entries, err := s.shareReader.ListAccountGroupConcurrencyShares(context.WithoutCancel(ctx))
+		if err != nil {
+			logger.LegacyPrintf("service.concurrency", "Warning: failed to load account group concurrency shares: %v", err)
+			// 沿用旧快照（无则不限制）并推迟下次刷新，避免数据库异常时每个请求都重试
+			var stale map[accountGroupShareKey]int
+			if snapshot != nil {
+				stale = snapshot.shares
+			}
+			s.shareSnapshot.Store(&accountGroupShareSnapshot{shares: stale, expiresAt: time.Now().Add(accountGroupShareRefreshInterval)})
+			return stale, nil
+		}
+		shares := make(map[accountGroupShareKey]int, len(entries))
+		for _, entry := range entries {
+			if entry.ConcurrencyShare > 0 {
+				shares[accountGroupShareKey{accountID: entry.AccountID, groupID: entry.GroupID}] = entry.ConcurrencyShare
+			}
+		}
+		s.shareSnapshot.Store(&accountGroupShareSnapshot{shares: shares, expiresAt: time.Now().Add(accountGroupShareRefreshInterval)})
+		return shares, nil
+	})
+	shares, _ := value.(map[accountGroupShareKey]int)
+	return shares
+}
+
+// acquireAccountGroupShareSlot 在账号配置了分组份额时占用份额槽位。
+// 返回 acquired=false 表示份额已满；Redis 异常时放行（fail-open），仅受账号整体并发约束。
+func (s *ConcurrencyService) acquireAccountGroupShareSlot(ctx context.Context, accountID int64, maxConcurrency int, requestID string) (release func(), acquired bool) {
+	groupID, share := s.accountGroupShare(ctx, accountID)
+	limit := AccountGroupShareSlots(maxConcurrency, share)
+	if limit <= 0 {
+		return func() {}, true
+	}
+	cache, ok := s.cache.(AccountGroupShareCache)
+	if !ok {
+		return func() {}, true
+	}
+	acquired, err := cache.AcquireAccountGroupSlot(ctx, accountID, groupID, limit, requestID)
+	if err != nil {
+		logger.LegacyPrintf("service.concurrency", "Warning: failed to acquire group share slot for account %d group %d (req=%s): %v", accountID, groupID, requestID, err)
+		return func() {}, true
+	}
+	if !acquired {
+		return nil, false
+	}
+	return func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := cache.ReleaseAccountGroupSlot(bgCtx, accountID, groupID, requestID); err != nil {
+			logger.LegacyPrintf("service.concurrency", "Warning: failed to release group share slot for account %d group %d (req=%s): %v", accountID, groupID, requestID, err)
+		}
+	}, true
+}
@@ -0,0 +1,135 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+type accountGroupShareReaderStub struct {
+	entries []AccountGroup
+	err     error
+	calls   int
+}
+
+func (r *accountGroupShareReaderStub) ListAccountGroupConcurrencyShares(context.Context) ([]AccountGroup, error) {
+	r.calls++
+	return r.entries, r.err
+}
+
+// shareConcurrencyCacheStub 在通用并发缓存桩上按 (账号, 分组) 计数份额槽位。
+type shareConcurrencyCacheStub struct {
+	stubConcurrencyCacheForTest
+	shareSlots map[accountGroupShareKey]map[string]struct{}
+	shareMax   []int
+}
+
+func (c *shareConcurrencyCacheStub) AcquireAccountGroupSlot(_ context.Context, accountID, groupID int64, maxConcurrency int, requestID string) (bool, error) {
+	c.shareMax = append(c.shareMax, maxConcurrency)
+	key := accountGroupShareKey{accountID: accountID, groupID: groupID}
+	if c.shareSlots == nil {
+		c.shareSlots = make(map[accountGroupShareKey]map[string]struct{})
+	}
+	if c.shareSlots[key] == nil {
+		c.shareSlots[key] = make(map[string]struct{})
+	}
+	if len(c.shareSlots[key]) >= maxConcurrency {
+		return false, nil
+	}
+	c.shareSlots[key][requestID] = struct{}{}
+	return true, nil
+}
+
+func (c *shareConcurrencyCacheStub) ReleaseAccountGroupSlot(_ context.Context, accountID, groupID int64, requestID string) error {
+	delete(c.shareSlots[accountGroupShareKey{accountID: accountID, groupID: groupID}], requestID)
+	return nil
+}
+
+func groupContext(groupID int64) context.Context {
+	return context.WithValue(context.Background(), ctxkey.Group, &Group{ID: groupID})
+}
+
+func TestAccountGroupShareSlots(t *testing.T) {
+	require.Equal(t, 7, AccountGroupShareSlots(10, 70))
+	require.Equal(t, 3, AccountGroupShareSlots(10, 30))
+	require.Equal(t, 1, AccountGroupShareSlots(1, 30), "small accounts keep at least one slot")
+	require.Equal(t, 0, AccountGroupShareSlots(10, 0))
+	require.Equal(t, 0, AccountGroupShareSlots(10, 100))
+	require.Equal(t, 0, AccountGroupShareSlots(0, 50))
+}
+
+func TestAcquireAccountSlot_EnforcesGroupConcurrencyShare(t *testing.T) {
+	cache := &shareConcurrencyCacheStub{stubConcurrencyCacheForTest: stubConcurrencyCacheForTest{acquireResult: true}}
+	reader := &accountGroupShareReaderStub{entries: []AccountGroup{
+		{AccountID: 1, GroupID: 10, ConcurrencyShare: 70},
+		{AccountID: 1, GroupID: 20, ConcurrencyShare: 30},
+	}}
+	svc := NewConcurrencyService(cache)
+	svc.SetAccountGroupShareReader(reader)
+
+	var releases []func()
+	for i := 0; i < 3; i++ {
+		result, err := svc.AcquireAccountSlot(groupContext(20), 1, 10)
+		require.NoError(t, err)
+		require.True(t, result.Acquired)
+		releases = append(releases, result.ReleaseFunc)
+	}
+	result, err := svc.AcquireAccountSlot(groupContext(20), 1, 10)
+	require.NoError(t, err)
+	require.False(t, result.Acquired, "group B is capped at 30% of 10 slots")
+	require.Len(t, cache.acquiredAccountMax, 3, "account slot is not touched once the share is full")
+
+	result, err = svc.AcquireAccountSlot(groupContext(10), 1, 10)
+	require.NoError(t, err)
+	require.True(t, result.Acquired, "group A keeps its own share")
+
+	releases[0]()
+	result, err = svc.AcquireAccountSlot(groupContext(20), 1, 10)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+
+	// 未配置份额的分组与无分组上下文不受限制
+	result, err = svc.AcquireAccountSlot(groupContext(30), 1, 10)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+	result, err = svc.AcquireAccountSlot(context.Background(), 1, 10)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+
+	require.Equal(t, []int{3, 3, 3, 3, 7, 3}, cache.shareMax)
+	require.Equal(t, 1, reader.calls, "share config is cached between acquisitions")
+}
+
+func TestAcquireAccountSlot_ReleasesShareWhenAccountFull(t *testing.T) {
+	cache := &shareConcurrencyCacheStub{stubConcurrencyCacheForTest: stubConcurrencyCacheForTest{acquireResult: false}}
+	svc := NewConcurrencyService(cache)
+	svc.SetAccountGroupShareReader(&accountGroupShareReaderStub{entries: []AccountGroup{{AccountID: 1, GroupID: 10, ConcurrencyShare: 50}}})
+
+	result, err := svc.AcquireAccountSlot(groupContext(10), 1, 4)
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+	require.Empty(t, cache.shareSlots[accountGroupShareKey{accountID: 1, groupID: 10}])
+}
+
+func TestAcquireAccountSlot_ShareReaderErrorFailsOpen(t *testing.T) {
+	cache := &shareConcurrencyCacheStub{stubConcurrencyCacheForTest: stubConcurrencyCacheForTest{acquireResult: true}}
+	svc := NewConcurrencyService(cache)
+	svc.SetAccountGroupShareReader(&accountGroupShareReaderStub{err: errors.New("db down")})
+
+	result, err := svc.AcquireAccountSlot(groupContext(10), 1, 4)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+	require.Empty(t, cache.shareMax)
+}
+
+func TestValidateAccountGroupConcurrencyShare(t *testing.T) {
+	require.NoError(t, validateAccountGroupConcurrencyShare(0))
+	require.NoError(t, validateAccountGroupConcurrencyShare(100))
+	require.Error(t, validateAccountGroupConcurrencyShare(-1))
+	require.Error(t, validateAccountGroupConcurrencyShare(101))
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	accountLeaseDefaultTTL    = 15 * time.Minute
+	accountLeaseMinTTL        = 10 * time.Second
+	accountLeaseMaxTTL        = 24 * time.Hour
+	accountLeaseMaxHolderLen  = 100
+	accountLeaseMaxNoteLen    = 255
+	accountLeaseSweepInterval = 15 * time.Second
+)
+
+var (
+	ErrAccountLeaseHeld          = infraerrors.Conflict("ACCOUNT_LEASED", "account is already leased")
+	ErrAccountLeaseNotFound      = infraerrors.NotFound("ACCOUNT_LEASE_NOT_FOUND", "lease not found or already expired")
+	ErrAccountLeaseInvalidHolder = infraerrors.BadRequest("ACCOUNT_LEASE_INVALID_HOLDER", fmt.Sprintf("holder must be 1-%d characters", accountLeaseMaxHolderLen))
+	ErrAccountLeaseInvalidNote   = infraerrors.BadRequest("ACCOUNT_LEASE_INVALID_NOTE", fmt.Sprintf("note must be at most %d characters", accountLeaseMaxNoteLen))
+	ErrAccountLeaseInvalidTTL    = infraerrors.BadRequest("ACCOUNT_LEASE_INVALID_TTL", fmt.Sprintf("ttl_seconds must be between %d and %d", int(accountLeaseMinTTL.Seconds()), int(accountLeaseMaxTTL.Seconds())))
+)
+
+// AccountLease 外部系统对账号的独占租约。租约期间账号 schedulable 为 false，内部调度器不会选中；
+// 归还或过期后恢复借出前的调度开关。租约期间手动修改调度开关会在释放时被覆盖。
+type AccountLease struct {
+	AccountID       int64     `json:"account_id"`
+	LeaseID         string    `json:"lease_id"`
+	Holder          string    `json:"holder"`
+	Note            string    `json:"note"`
+	PrevSchedulable bool      `json:"prev_schedulable"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	RenewedAt       time.Time `json:"renewed_at"`
+}
+
+// AccountLeaseInput 借出账号的入参，TTL 为 0 时使用默认时长。
+type AccountLeaseInput struct {
+	Holder string
+	Note   string
+	TTL    time.Duration
+}
+
+// AccountLeaseRepository 租约存储。借出/释放与账号调度开关的变更在同一事务内完成，并写入调度器 outbox。
+type AccountLeaseRepository interface {
+	// Checkout 创建租约并停止账号调度；账号已有未过期租约时返回 ErrAccountLeaseHeld，账号不存在时返回 ErrAccountNotFound。
+	Checkout(ctx context.Context, lease *AccountLease) (*AccountLease, error)
+	// Renew 延长未过期租约的到期时间。
+	Renew(ctx context.Context, leaseID string, expiresAt time.Time) (*AccountLease, error)
+	// Release 删除租约并恢复借出前的调度开关（已过期但尚未清理的租约同样可以归还）。
+	Release(ctx context.Context, leaseID string) (*AccountLease, error)
+	// ReleaseExpired 释放 now 之前到期的全部租约，返回被释放的租约。
+	ReleaseExpired(ctx context.Context, now time.Time) ([]*AccountLease, error)
+	List(ctx context.Context) ([]*AccountLease, error)
+}
+
+// AccountLeaseService 管理账号租约，并周期性释放过期租约。
+type AccountLeaseService struct {
+	repo     AccountLeaseRepository
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewAccountLeaseService 创建账号租约服务。
+func NewAccountLeaseService(repo AccountLeaseRepository, interval time.Duration) *AccountLeaseService {
+	return &AccountLeaseService{
+		repo:     repo,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// List 返回当前全部租约（含已过期但尚未清理的）。
+func (s *AccountLeaseService) List(ctx context.Context) ([]*AccountLease, error) {
+	leases, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if leases == nil {
+		leases = []*AccountLease{}
+	}
+	return leases, nil
+}
+
+// Checkout 借出账号。
+func (s *AccountLeaseService) Checkout(ctx context.Context, accountID int64, in AccountLeaseInput) (*AccountLease, error) {
+	holder := strings.TrimSpace(in.Holder)
+	if holder == "" || len([]rune(holder)) > accountLeaseMaxHolderLen {
+		return nil, ErrAccountLeaseInvalidHolder
+	}
+	note := strings.TrimSpace(in.Note)
+	if len([]rune(note)) > accountLeaseMaxNoteLen {
+		return nil, ErrAccountLeaseInvalidNote
+	}
+	ttl, err := normalizeAccountLeaseTTL(in.TTL)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Checkout(ctx, &AccountLease{
+		AccountID: accountID,
+		LeaseID:   uuid.NewString(),
+		Holder:    holder,
+		Note:      note,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+// Renew 续期租约，新的到期时间从当前时刻起算。
+func (s *AccountLeaseService) Renew(ctx context.Context, leaseID string, ttl time.Duration) (*AccountLease, error) {
+	ttl, err := normalizeAccountLeaseTTL(ttl)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Renew(ctx, leaseID, time.Now().Add(ttl))
+}
+
+// Checkin 归还账号。
+func (s *AccountLeaseService) Checkin(ctx context.Context, leaseID string) (*AccountLease, error) {
+	return s.repo.Release(ctx, leaseID)
+}
+
+func normalizeAccountLeaseTTL(ttl time.Duration) (time.Duration, error) {
+	if ttl == 0 {
+		return accountLeaseDefaultTTL, nil
+	}
+	if ttl < accountLeaseMinTTL || ttl > accountLeaseMaxTTL {
+		return 0, ErrAccountLeaseInvalidTTL
+	}
+	return ttl, nil
+}
+
+func (s *AccountLeaseService) Start() {
+	if s == nil || s.repo == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *AccountLeaseService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *AccountLeaseService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	released, err := s.repo.ReleaseExpired(ctx, time.Now())
+	for _, lease := range released {
+		log.Printf("[AccountLease] Released expired lease: account=%d holder=%s lease=%s", lease.AccountID, lease.Holder, lease.LeaseID)
+	}
+	if err != nil {
+		log.Printf("[AccountLease] Release expired leases failed: %v", err)
+	}
+}
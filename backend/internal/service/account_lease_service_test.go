@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type accountLeaseRepoStub struct {
+	checkedOut *AccountLease
+	renewedTo  time.Time
+	expired    []*AccountLease
+}
+
+func (r *accountLeaseRepoStub) Checkout(_ context.Context, lease *AccountLease) (*AccountLease, error) {
+	r.checkedOut = lease
+	return lease, nil
+}
+
+func (r *accountLeaseRepoStub) Renew(_ context.Context, leaseID string, expiresAt time.Time) (*AccountLease, error) {
+	r.renewedTo = expiresAt
+	return &AccountLease{LeaseID: leaseID, ExpiresAt: expiresAt}, nil
+}
+
+func (r *accountLeaseRepoStub) Release(_ context.Context, leaseID string) (*AccountLease, error) {
+	return &AccountLease{LeaseID: leaseID}, nil
+}
+
+func (r *accountLeaseRepoStub) ReleaseExpired(context.Context, time.Time) ([]*AccountLease, error) {
+	released := r.expired
+	r.expired = nil
+	return released, nil
+}
+
+func (r *accountLeaseRepoStub) List(context.Context) ([]*AccountLease, error) {
+	return nil, nil
+}
+
+func TestAccountLeaseServiceCheckout(t *testing.T) {
+	repo := &accountLeaseRepoStub{}
+	svc := NewAccountLeaseService(repo, 0)
+	ctx := context.Background()
+
+	before := time.Now()
+	lease, err := svc.Checkout(ctx, 7, AccountLeaseInput{Holder: " batch-runner ", Note: "nightly eval"})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), lease.AccountID)
+	require.Equal(t, "batch-runner", lease.Holder)
+	require.NotEmpty(t, lease.LeaseID)
+	require.WithinDuration(t, before.Add(accountLeaseDefaultTTL), lease.ExpiresAt, time.Second)
+
+	_, err = svc.Checkout(ctx, 7, AccountLeaseInput{Holder: ""})
+	require.ErrorIs(t, err, ErrAccountLeaseInvalidHolder)
+	_, err = svc.Checkout(ctx, 7, AccountLeaseInput{Holder: "x", TTL: time.Second})
+	require.ErrorIs(t, err, ErrAccountLeaseInvalidTTL)
+	_, err = svc.Checkout(ctx, 7, AccountLeaseInput{Holder: "x", TTL: 48 * time.Hour})
+	require.ErrorIs(t, err, ErrAccountLeaseInvalidTTL)
+}
+
+func TestAccountLeaseServiceRenewAndList(t *testing.T) {
+	repo := &accountLeaseRepoStub{}
+	svc := NewAccountLeaseService(repo, 0)
+	ctx := context.Background()
+
+	before := time.Now()
+	_, err := svc.Renew(ctx, "lease-1", time.Hour)
+	require.NoError(t, err)
+	require.WithinDuration(t, before.Add(time.Hour), repo.renewedTo, time.Second)
+
+	leases, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, leases)
+	require.Empty(t, leases)
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 模型映射规则：model_mapping（对象，key 为模型或模式）之外，账号可配置有序的
+// model_mapping_rules：[{"pattern": "...", "target": "..."}]，按顺序首个命中生效，优先于 model_mapping。
+//
+// 模式语法（两处通用）：
+//   - 无 * 且无 re: 前缀：精确匹配；
+//   - 含 *：通配符，* 可出现在任意位置，每个 * 为一个捕获组（$1、$2…）；
+//   - re: 前缀：正则，整体锚定匹配，支持编号与命名捕获组（$1、${name}）。
+//
+// target 中的 $1 / ${1} / ${name} 会以捕获内容展开，例如 "re:^gpt-5(\.\d+)?-mini$" → "gpt-5$1"。
+const (
+	credKeyModelMappingRules = "model_mapping_rules"
+
+	modelMappingRegexPrefix = "re:"
+	maxModelMappingRules    = 200
+)
+
+const (
+	ModelMappingKindExact    = "exact"
+	ModelMappingKindWildcard = "wildcard"
+	ModelMappingKindRegex    = "regex"
+
+	ModelMappingSourceRules   = "rules"
+	ModelMappingSourceMapping = "mapping"
+)
+
+// ModelMappingRule 有序映射规则。
+type ModelMappingRule struct {
+	Pattern string `json:"pattern"`
+	Target  string `json:"target"`
+}
+
+// ModelMappingResolution 描述某个模型在账号映射配置中的命中情况，供管理端测试接口展示。
+type ModelMappingResolution struct {
+	RequestedModel string `json:"requested_model"`
+	LookupModel    string `json:"lookup_model"`
+	MappedModel    string `json:"mapped_model"`
+	Matched        bool   `json:"matched"`
+	Supported      bool   `json:"supported"`
+	Source         string `json:"source,omitempty"`
+	RuleIndex      *int   `json:"rule_index,omitempty"`
+	Pattern        string `json:"pattern,omitempty"`
+	Kind           string `json:"kind,omitempty"`
+}
+
+// modelMappingPattern 编译后的模式；exact 模式 re 为 nil。
+type modelMappingPattern struct {
+	kind string
+	re   *regexp.Regexp
+	err  error
+}
+
+var modelMappingPatternCache sync.Map // pattern -> *modelMappingPattern
+
+func compileModelMappingPattern(pattern string) *modelMappingPattern {
+	if cached, ok := modelMappingPatternCache.Load(pattern); ok {
+		return cached.(*modelMappingPattern)
+	}
+	compiled := &modelMappingPattern{kind: ModelMappingKindExact}
+	switch {
+	case strings.HasPrefix(pattern, modelMappingRegexPrefix):
+		compiled.kind = ModelMappingKindRegex
+		compiled.re, compiled.err = regexp.Compile(`^(?:` + strings.TrimPrefix(pattern, modelMappingRegexPrefix) + `)$`)
+	case strings.Contains(pattern, "*"):
+		compiled.kind = ModelMappingKindWildcard
+		parts := strings.Split(pattern, "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		compiled.re, compiled.err = regexp.Compile(`^` + strings.Join(parts, `(.*)`) + `$`)
+	}
+	actual, _ := modelMappingPatternCache.LoadOrStore(pattern, compiled)
+	return actual.(*modelMappingPattern)
+}
+
+// matchModelMappingPattern 判断模型是否命中模式，命中时返回展开捕获组后的 target。
+func matchModelMappingPattern(pattern, target, model string) (string, bool) {
+	compiled := compileModelMappingPattern(pattern)
+	if compiled.re == nil {
+		// 非法正则视为不命中（保存时已校验，这里兜底历史数据）
+		return target, compiled.err == nil && pattern == model
+	}
+	submatches := compiled.re.FindStringSubmatchIndex(model)
+	if submatches == nil {
+		return "", false
+	}
+	if !strings.Contains(target, "$") {
+		return target, true
+	}
+	return string(compiled.re.ExpandString(nil, target, model, submatches)), true
+}
+
+// modelMappingHit 单次命中详情。
+type modelMappingHit struct {
+	source  string
+	index   int
+	pattern string
+	target  string
+}
+
+// matchModelMappingRules 按顺序返回首个命中的规则。
+func matchModelMappingRules(rules []ModelMappingRule, model string) (modelMappingHit, bool) {
+	if model == "" {
+		return modelMappingHit{}, false
+	}
+	for i, rule := range rules {
+		if target, ok := matchModelMappingPattern(rule.Pattern, rule.Target, model); ok {
+			return modelMappingHit{source: ModelMappingSourceRules, index: i, pattern: rule.Pattern, target: target}, true
+		}
+	}
+	return modelMappingHit{}, false
+}
+
+// matchModelMappingMap 在 model_mapping 中查找：精确优先，其次模式按长度降序（最长优先）、同长按字典序。
+func matchModelMappingMap(mapping map[string]string, model string) (modelMappingHit, bool) {
+	if model == "" {
+		return modelMappingHit{}, false
+	}
+	if target, exists := mapping[model]; exists {
+		return modelMappingHit{source: ModelMappingSourceMapping, pattern: model, target: target}, true
+	}
+	var patterns []string
+	for pattern := range mapping {
+		if compileModelMappingPattern(pattern).kind != ModelMappingKindExact {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if target, ok := matchModelMappingPattern(pattern, mapping[pattern], model); ok {
+			return modelMappingHit{source: ModelMappingSourceMapping, pattern: pattern, target: target}, true
+		}
+	}
+	return modelMappingHit{}, false
+}
+
+// GetModelMappingRules 返回账号的有序映射规则（model_mapping_rules），未配置时为 nil。
+func (a *Account) GetModelMappingRules() []ModelMappingRule {
+	if a == nil || a.Credentials == nil {
+		return nil
+	}
+	return parseModelMappingRules(a.Credentials[credKeyModelMappingRules])
+}
+
+func parseModelMappingRules(raw any) []ModelMappingRule {
+	items, ok := raw.([]any)
+	if !ok || len(items) == 0 {
+		return nil
+	}
+	rules := make([]ModelMappingRule, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		pattern, _ := m["pattern"].(string)
+		target, _ := m["target"].(string)
+		if pattern == "" || target == "" {
+			continue
+		}
+		rules = append(rules, ModelMappingRule{Pattern: pattern, Target: target})
+	}
+	return rules
+}
+
+// resolveModelMappingHit 依次以原始模型名、平台规范化后的模型名查找：有序规则优先于 model_mapping。
+func (a *Account) resolveModelMappingHit(requestedModel string) (hit modelMappingHit, lookupModel string, ok bool) {
+	rules := a.GetModelMappingRules()
+	mapping := a.GetModelMapping()
+	if len(rules) == 0 && len(mapping) == 0 {
+		return modelMappingHit{}, requestedModel, false
+	}
+	candidates := []string{requestedModel}
+	if normalized := normalizeRequestedModelForLookup(a.Platform, requestedModel); normalized != requestedModel {
+		candidates = append(candidates, normalized)
+	}
+	for _, model := range candidates {
+		if hit, ok := matchModelMappingRules(rules, model); ok {
+			return hit, model, true
+		}
+		if hit, ok := matchModelMappingMap(mapping, model); ok {
+			return hit, model, true
+		}
+	}
+	return modelMappingHit{}, requestedModel, false
+}
+
+// ExplainModelMapping 返回模型在账号映射配置中命中的规则与映射结果。
+func (a *Account) ExplainModelMapping(requestedModel string) ModelMappingResolution {
+	res := ModelMappingResolution{
+		RequestedModel: requestedModel,
+		LookupModel:    requestedModel,
+		MappedModel:    requestedModel,
+		Supported:      a.IsModelSupported(requestedModel),
+	}
+	hit, lookupModel, ok := a.resolveModelMappingHit(requestedModel)
+	if !ok {
+		return res
+	}
+	res.LookupModel = lookupModel
+	res.MappedModel = hit.target
+	res.Matched = true
+	res.Source = hit.source
+	res.Pattern = hit.pattern
+	res.Kind = compileModelMappingPattern(hit.pattern).kind
+	if hit.source == ModelMappingSourceRules {
+		index := hit.index
+		res.RuleIndex = &index
+	}
+	return res
+}
+
+// WithModelMappingDraft 返回以草稿映射配置替换已保存配置的账号副本，用于测试与模拟，不落库。
+// modelMapping / rules 为 nil 时沿用已保存的对应字段。
+func (a *Account) WithModelMappingDraft(modelMapping map[string]any, rules []any) (*Account, error) {
+	if modelMapping == nil && rules == nil {
+		return a, nil
+	}
+	credentials := make(map[string]any, len(a.Credentials)+2)
+	for k, v := range a.Credentials {
+		credentials[k] = v
+	}
+	if modelMapping != nil {
+		credentials["model_mapping"] = modelMapping
+	}
+	if rules != nil {
+		credentials[credKeyModelMappingRules] = rules
+	}
+	if err := NormalizeModelMappingCredentials(credentials); err != nil {
+		return nil, err
+	}
+	return &Account{
+		ID:          a.ID,
+		Name:        a.Name,
+		Platform:    a.Platform,
+		Type:        a.Type,
+		Credentials: credentials,
+		Extra:       a.Extra,
+	}, nil
+}
+
+func invalidModelMappingRules(format string, args ...any) error {
+	return infraerrors.Newf(http.StatusBadRequest, "INVALID_MODEL_MAPPING_RULES", format, args...)
+}
+
+// NormalizeModelMappingCredentials 校验并原地规范化 credentials 中的模型映射模式。
+// 供账号创建/更新/批量更新的保存路径调用：model_mapping_rules 去除首尾空白、丢弃空条目，
+// 两处的正则模式必须可编译；未携带相关字段时为 no-op。
+func NormalizeModelMappingCredentials(credentials map[string]any) error {
+	if credentials == nil {
+		return nil
+	}
+	if mapping, ok := credentials["model_mapping"].(map[string]any); ok {
+		for pattern := range mapping {
+			if err := compileModelMappingPattern(pattern).err; err != nil {
+				return invalidModelMappingRules("model_mapping pattern %q is not a valid regex: %v", pattern, err)
+			}
+		}
+	}
+	raw, ok := credentials[credKeyModelMappingRules]
+	if !ok || raw == nil {
+		return nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return invalidModelMappingRules("model_mapping_rules must be an array of {pattern, target}")
+	}
+	if len(items) > maxModelMappingRules {
+		return invalidModelMappingRules("model_mapping_rules supports at most %d rules", maxModelMappingRules)
+	}
+	normalized := make([]any, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return invalidModelMappingRules("model_mapping_rules[%d] must be an object", i)
+		}
+		pattern, pOK := m["pattern"].(string)
+		target, tOK := m["target"].(string)
+		if (m["pattern"] != nil && !pOK) || (m["target"] != nil && !tOK) {
+			return invalidModelMappingRules("model_mapping_rules[%d] pattern and target must be strings", i)
+		}
+		pattern = strings.TrimSpace(pattern)
+		target = strings.TrimSpace(target)
+		if pattern == "" && target == "" {
+			continue
+		}
+		if pattern == "" || target == "" {
+			return invalidModelMappingRules("model_mapping_rules[%d] requires both pattern and target", i)
+		}
+		if err := compileModelMappingPattern(pattern).err; err != nil {
+			return invalidModelMappingRules("model_mapping_rules[%d] pattern %q is not a valid regex: %v", i, pattern, err)
+		}
+		normalized = append(normalized, map[string]any{"pattern": pattern, "target": target})
+	}
+	if len(normalized) == 0 {
+		delete(credentials, credKeyModelMappingRules)
+		return nil
+	}
+	credentials[credKeyModelMappingRules] = normalized
+	return nil
+}
@@ -0,0 +1,128 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchModelMappingPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		target  string
+		model   string
+		want    string
+		matched bool
+	}{
+		{"exact", "gpt-5", "gpt-5.1", "gpt-5", "gpt-5.1", true},
+		{"trailing wildcard", "gpt-5*", "gpt-5.1", "gpt-5-mini", "gpt-5.1", true},
+		{"middle wildcard", "claude-*-4-5", "claude-sonnet-4-5", "claude-opus-4-5", "claude-sonnet-4-5", true},
+		{"middle wildcard mismatch", "claude-*-4-5", "x", "claude-opus-4-6", "", false},
+		{"wildcard capture", "claude-3-5-*", "claude-$1-4-5", "claude-3-5-sonnet", "claude-sonnet-4-5", true},
+		{"wildcard is literal otherwise", "gpt-4.1*", "x", "gpt-451", "", false},
+		{"regex capture", `re:gpt-5(\.\d+)?-codex`, "gpt-5${1}", "gpt-5.2-codex", "gpt-5.2", true},
+		{"regex named capture", `re:(?P<family>sonnet|opus)-latest`, "claude-${family}-4-5", "opus-latest", "claude-opus-4-5", true},
+		{"regex is anchored", "re:gpt-5", "x", "gpt-5-mini", "", false},
+		{"invalid regex never matches", "re:gpt-(", "x", "gpt-(", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchModelMappingPattern(tt.pattern, tt.target, tt.model)
+			require.Equal(t, tt.matched, ok)
+			if ok {
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAccountModelMappingRulesOrdering(t *testing.T) {
+	account := &Account{
+		Platform: PlatformAnthropic,
+		Type:     AccountTypeAPIKey,
+		Credentials: map[string]any{
+			"model_mapping": map[string]any{
+				"claude-sonnet-4-5": "claude-sonnet-4-5-20250929",
+				"claude-*":          "claude-default",
+			},
+			"model_mapping_rules": []any{
+				map[string]any{"pattern": "claude-3-5-*", "target": "claude-sonnet-4-5"},
+				map[string]any{"pattern": "claude-3-*", "target": "claude-haiku-4-5"},
+			},
+		},
+	}
+
+	mapped, matched := account.ResolveMappedModel("claude-3-5-sonnet-20241022")
+	require.True(t, matched)
+	require.Equal(t, "claude-sonnet-4-5", mapped, "first matching rule wins even though a later one also matches")
+
+	mapped, matched = account.ResolveMappedModel("claude-3-opus")
+	require.True(t, matched)
+	require.Equal(t, "claude-haiku-4-5", mapped)
+
+	mapped, matched = account.ResolveMappedModel("claude-opus-4-1")
+	require.True(t, matched)
+	require.Equal(t, "claude-default", mapped, "unmatched rules fall through to model_mapping")
+
+	require.False(t, account.IsModelSupported("gpt-5"))
+
+	res := account.ExplainModelMapping("claude-3-opus")
+	require.True(t, res.Matched)
+	require.True(t, res.Supported)
+	require.Equal(t, ModelMappingSourceRules, res.Source)
+	require.Equal(t, "claude-3-*", res.Pattern)
+	require.Equal(t, ModelMappingKindWildcard, res.Kind)
+	require.NotNil(t, res.RuleIndex)
+	require.Equal(t, 1, *res.RuleIndex)
+
+	res = account.ExplainModelMapping("claude-sonnet-4-5")
+	require.Equal(t, ModelMappingSourceMapping, res.Source)
+	require.Equal(t, ModelMappingKindExact, res.Kind)
+	require.Nil(t, res.RuleIndex)
+}
+
+func TestAccountModelMappingRulesOnlyRewrite(t *testing.T) {
+	account := &Account{
+		Platform: PlatformAnthropic,
+		Type:     AccountTypeAPIKey,
+		Credentials: map[string]any{
+			"model_mapping_rules": []any{
+				map[string]any{"pattern": "gpt-5*", "target": "gpt-5.1"},
+			},
+		},
+	}
+	require.Equal(t, "gpt-5.1", account.GetMappedModel("gpt-5-mini"))
+	require.True(t, account.IsModelSupported("claude-opus-4-1"), "rules alone do not restrict models")
+	require.Equal(t, "claude-opus-4-1", account.GetMappedModel("claude-opus-4-1"))
+}
+
+func TestNormalizeModelMappingCredentials(t *testing.T) {
+	creds := map[string]any{
+		"model_mapping_rules": []any{
+			map[string]any{"pattern": "  gpt-5* ", "target": " gpt-5.1 "},
+			map[string]any{"pattern": "", "target": ""},
+		},
+	}
+	require.NoError(t, NormalizeModelMappingCredentials(creds))
+	require.Equal(t, []any{map[string]any{"pattern": "gpt-5*", "target": "gpt-5.1"}}, creds["model_mapping_rules"])
+
+	creds = map[string]any{"model_mapping_rules": []any{map[string]any{"pattern": "", "target": ""}}}
+	require.NoError(t, NormalizeModelMappingCredentials(creds))
+	require.NotContains(t, creds, "model_mapping_rules")
+
+	require.Error(t, NormalizeModelMappingCredentials(map[string]any{
+		"model_mapping_rules": []any{map[string]any{"pattern": "re:gpt-(", "target": "x"}},
+	}))
+	require.Error(t, NormalizeModelMappingCredentials(map[string]any{
+		"model_mapping_rules": []any{map[string]any{"pattern": "gpt-5*"}},
+	}))
+	require.Error(t, NormalizeModelMappingCredentials(map[string]any{
+		"model_mapping_rules": map[string]any{"gpt-5*": "gpt-5.1"},
+	}))
+	require.Error(t, NormalizeModelMappingCredentials(map[string]any{
+		"model_mapping": map[string]any{"re:[": "x"},
+	}))
+}
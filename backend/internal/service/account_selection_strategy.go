@@ -0,0 +1,172 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 负载感知调度的账号选择策略（分组 account_selection_strategy，空表示 priority）。
+// 除 priority 外的策略都把候选视为一个平级池、忽略账号优先级；故障转移层级与延迟 SLO 降权仍先于策略生效。
+const (
+	// AccountSelectionPriority 按优先级逐级溢出：只有更高优先级账号全部满载时才使用下一级，同级内按负载率 → LRU。
+	// 例如把廉价的 API Key 账号设为更高优先级，仅在其满载时溢出到 OAuth 账号。
+	AccountSelectionPriority = "priority"
+	// AccountSelectionWeightedRoundRobin 按账号负载因子（未设置时为并发数）做平滑加权轮询。
+	AccountSelectionWeightedRoundRobin = "weighted_round_robin"
+	// AccountSelectionLeastRecentlyUsed 选择最久未被使用的账号。
+	AccountSelectionLeastRecentlyUsed = "least_recently_used"
+	// AccountSelectionLowestLatency 选择近期响应延迟（流式取首 token 耗时）最低的账号；无样本的账号优先试用。
+	AccountSelectionLowestLatency = "lowest_latency"
+)
+
+const (
+	// accountLatencyEWMAAlpha 账号延迟指数滑动平均的新样本权重。
+	accountLatencyEWMAAlpha = 0.2
+	// accountLatencyMaxTracked 延迟跟踪的账号数上限，超出时不再记录新账号。
+	accountLatencyMaxTracked = 10000
+)
+
+// normalizeAccountSelectionStrategy 校验账号选择策略；priority 与空串等价，统一存为空串。
+func normalizeAccountSelectionStrategy(strategy string) (string, error) {
+	strategy = strings.TrimSpace(strategy)
+	switch strategy {
+	case "", AccountSelectionPriority:
+		return "", nil
+	case AccountSelectionWeightedRoundRobin, AccountSelectionLeastRecentlyUsed, AccountSelectionLowestLatency:
+		return strategy, nil
+	}
+	return "", infraerrors.BadRequest("INVALID_ACCOUNT_SELECTION_STRATEGY", "account selection strategy must be one of priority, weighted_round_robin, least_recently_used, lowest_latency").
+		WithMetadata(map[string]string{"strategy": strategy})
+}
+
+// weightedRoundRobinSelector 平滑加权轮询（nginx 算法）的分组内状态；零值可用，状态仅在本实例内存中维护。
+type weightedRoundRobinSelector struct {
+	mu      sync.Mutex
+	current map[int64]map[int64]int // groupID -> accountID -> current weight
+}
+
+// pick 从候选中按权重选出一个账号；只保留本次候选的状态，离开候选集的账号在重新加入时从零开始。
+func (w *weightedRoundRobinSelector) pick(groupID int64, accounts []accountWithLoad) *accountWithLoad {
+	if len(accounts) == 0 {
+		return nil
+	}
+	if len(accounts) == 1 {
+		return &accounts[0]
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.current == nil {
+		w.current = make(map[int64]map[int64]int)
+	}
+	prev := w.current[groupID]
+	next := make(map[int64]int, len(accounts))
+	total := 0
+	best := -1
+	for i := range accounts {
+		weight := accounts[i].account.EffectiveLoadFactor()
+		if weight <= 0 {
+			weight = 1
+		}
+		id := accounts[i].account.ID
+		next[id] = prev[id] + weight
+		total += weight
+		if best < 0 || next[id] > next[accounts[best].account.ID] {
+			best = i
+		}
+	}
+	next[accounts[best].account.ID] -= total
+	w.current[groupID] = next
+	return &accounts[best]
+}
+
+// accountLatencyTracker 记录各账号近期响应延迟的指数滑动平均；零值可用。
+type accountLatencyTracker struct {
+	mu   sync.RWMutex
+	ewma map[int64]float64 // accountID -> latency ms
+}
+
+// observe 记录一次转发结果：有首 token 耗时时取首 token，否则取总耗时。
+func (t *accountLatencyTracker) observe(accountID int64, duration time.Duration, firstTokenMs *int) {
+	latency := float64(duration.Milliseconds())
+	if firstTokenMs != nil && *firstTokenMs > 0 {
+		latency = float64(*firstTokenMs)
+	}
+	if accountID <= 0 || latency <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ewma == nil {
+		t.ewma = make(map[int64]float64)
+	}
+	prev, ok := t.ewma[accountID]
+	switch {
+	case ok:
+		t.ewma[accountID] = prev + accountLatencyEWMAAlpha*(latency-prev)
+	case len(t.ewma) < accountLatencyMaxTracked:
+		t.ewma[accountID] = latency
+	}
+}
+
+// latency 返回账号的延迟估计；无样本时 ok=false。
+func (t *accountLatencyTracker) latency(accountID int64) (float64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	v, ok := t.ewma[accountID]
+	return v, ok
+}
+
+// filterByLowestLatency 过滤出延迟最低的账号集合；存在无样本账号时优先返回它们，以便积累延迟数据。
+func (t *accountLatencyTracker) filterByLowestLatency(accounts []accountWithLoad) []accountWithLoad {
+	if len(accounts) <= 1 {
+		return accounts
+	}
+	unsampled := make([]accountWithLoad, 0, len(accounts))
+	var best []accountWithLoad
+	bestLatency := 0.0
+	for _, acc := range accounts {
+		v, ok := t.latency(acc.account.ID)
+		if !ok {
+			unsampled = append(unsampled, acc)
+			continue
+		}
+		switch {
+		case best == nil || v < bestLatency:
+			best, bestLatency = []accountWithLoad{acc}, v
+		case v == bestLatency:
+			best = append(best, acc)
+		}
+	}
+	if len(unsampled) > 0 {
+		return unsampled
+	}
+	return best
+}
+
+// selectByStrategy 按分组策略从负载未满的候选中选出下一个尝试获取槽位的账号。
+func (s *GatewayService) selectByStrategy(strategy string, groupID *int64, accounts []accountWithLoad, preferSoonestReset, preferOAuth bool) *accountWithLoad {
+	switch strategy {
+	case AccountSelectionWeightedRoundRobin:
+		return s.selectionRoundRobin.pick(derefGroupID(groupID), accounts)
+	case AccountSelectionLeastRecentlyUsed:
+		return selectByLRU(accounts, preferOAuth)
+	case AccountSelectionLowestLatency:
+		candidates := s.accountLatency.filterByLowestLatency(accounts)
+		candidates = filterByMinLoadRate(candidates)
+		return selectByLRU(candidates, preferOAuth)
+	default:
+		// 1. 取优先级最小的集合
+		candidates := filterByMinPriority(accounts)
+		// 2. （可选）use-it-or-lose-it：优先选用会话窗口最早重置的账号
+		if preferSoonestReset {
+			candidates = filterBySoonestReset(candidates)
+		}
+		// 3. 取负载率最低的集合
+		candidates = filterByMinLoadRate(candidates)
+		// 4. LRU 选择最久未用的账号
+		return selectByLRU(candidates, preferOAuth)
+	}
+}
@@ -0,0 +1,97 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func strategyCandidate(id int64, priority, loadRate, loadFactor int, lastUsed *time.Time) accountWithLoad {
+	return accountWithLoad{
+		account:  &Account{ID: id, Priority: priority, Concurrency: loadFactor, LastUsedAt: lastUsed},
+		loadInfo: &AccountLoadInfo{AccountID: id, LoadRate: loadRate},
+	}
+}
+
+func TestNormalizeAccountSelectionStrategy(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                     "",
+		" priority ":           "",
+		"weighted_round_robin": AccountSelectionWeightedRoundRobin,
+		"least_recently_used":  AccountSelectionLeastRecentlyUsed,
+		"lowest_latency":       AccountSelectionLowestLatency,
+	} {
+		got, err := normalizeAccountSelectionStrategy(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, got, in)
+	}
+	_, err := normalizeAccountSelectionStrategy("random")
+	require.Error(t, err)
+}
+
+func TestWeightedRoundRobinSelector_DistributesByLoadFactor(t *testing.T) {
+	var selector weightedRoundRobinSelector
+	accounts := []accountWithLoad{
+		strategyCandidate(1, 1, 0, 3, nil),
+		strategyCandidate(2, 1, 0, 1, nil),
+	}
+	counts := map[int64]int{}
+	var order []int64
+	for i := 0; i < 8; i++ {
+		picked := selector.pick(7, accounts)
+		counts[picked.account.ID]++
+		order = append(order, picked.account.ID)
+	}
+	require.Equal(t, map[int64]int{1: 6, 2: 2}, counts)
+	require.Equal(t, []int64{1, 1, 2, 1, 1, 1, 2, 1}, order, "smooth WRR interleaves the lighter account")
+
+	// 不同分组互不影响
+	require.Equal(t, int64(1), selector.pick(8, accounts).account.ID)
+}
+
+func TestAccountLatencyTracker_FilterByLowestLatency(t *testing.T) {
+	var tracker accountLatencyTracker
+	firstToken := 300
+	tracker.observe(1, 5*time.Second, &firstToken)
+	tracker.observe(2, 900*time.Millisecond, nil)
+	tracker.observe(2, 1900*time.Millisecond, nil)
+
+	latency, ok := tracker.latency(2)
+	require.True(t, ok)
+	require.InDelta(t, 1100, latency, 0.001, "EWMA with alpha 0.2")
+
+	accounts := []accountWithLoad{strategyCandidate(1, 1, 0, 1, nil), strategyCandidate(2, 1, 0, 1, nil)}
+	got := tracker.filterByLowestLatency(accounts)
+	require.Len(t, got, 1)
+	require.Equal(t, int64(1), got[0].account.ID, "first-token latency is preferred over total duration")
+
+	accounts = append(accounts, strategyCandidate(3, 1, 0, 1, nil))
+	got = tracker.filterByLowestLatency(accounts)
+	require.Len(t, got, 1)
+	require.Equal(t, int64(3), got[0].account.ID, "unsampled accounts are explored first")
+}
+
+func TestGatewayService_SelectByStrategy(t *testing.T) {
+	svc := &GatewayService{}
+	old := time.Now().Add(-time.Hour)
+	recent := time.Now()
+	accounts := []accountWithLoad{
+		strategyCandidate(1, 1, 80, 1, &recent), // 高优先级、负载较高、刚用过
+		strategyCandidate(2, 5, 10, 1, &old),    // 低优先级、空闲、最久未用
+	}
+
+	require.Equal(t, int64(1), svc.selectByStrategy("", nil, accounts, false, false).account.ID, "priority overflows only when the top tier is saturated")
+	require.Equal(t, int64(2), svc.selectByStrategy(AccountSelectionLeastRecentlyUsed, nil, accounts, false, false).account.ID)
+
+	svc.accountLatency.observe(1, 2*time.Second, nil)
+	svc.accountLatency.observe(2, 500*time.Millisecond, nil)
+	require.Equal(t, int64(2), svc.selectByStrategy(AccountSelectionLowestLatency, nil, accounts, false, false).account.ID)
+
+	groupID := int64(3)
+	first := svc.selectByStrategy(AccountSelectionWeightedRoundRobin, &groupID, accounts, false, false).account.ID
+	second := svc.selectByStrategy(AccountSelectionWeightedRoundRobin, &groupID, accounts, false, false).account.ID
+	require.NotEqual(t, first, second, "equal weights alternate")
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AccountUsageWindowSpec 一个滚动用量窗口：窗口长度与计数桶粒度。
+type AccountUsageWindowSpec struct {
+	Name   string
+	Window time.Duration
+	Bucket time.Duration
+}
+
+// accountUsageWindowSpecs 跟踪的窗口，顺序与 AccountUsageWindowCache 返回的计数一一对应。
+var accountUsageWindowSpecs = []AccountUsageWindowSpec{
+	{Name: "5h", Window: 5 * time.Hour, Bucket: 5 * time.Minute},
+	{Name: "7d", Window: 7 * 24 * time.Hour, Bucket: time.Hour},
+}
+
+// accountUsageWindowSnapshotTTL 实例内计数快照的有效期，避免每次调度都回源 Redis。
+const accountUsageWindowSnapshotTTL = 5 * time.Second
+
+// AccountUsageWindowCache 账号滚动用量窗口计数（Redis，跨实例共享）。
+// 计数按 windows 的顺序返回，均为当前窗口内的请求总数。
+type AccountUsageWindowCache interface {
+	// IncrementUsageWindows 原子递增各窗口计数并返回递增后的计数
+	IncrementUsageWindows(ctx context.Context, accountID int64, windows []AccountUsageWindowSpec) ([]int64, error)
+	// GetUsageWindowsBatch 批量读取多个账号的窗口计数（使用 Pipeline）
+	GetUsageWindowsBatch(ctx context.Context, accountIDs []int64, windows []AccountUsageWindowSpec) (map[int64][]int64, error)
+}
+
+// AccountUsageWindowTracker 按套餐上限跟踪账号 5 小时/每周的请求数，供调度避开接近上限的账号。
+// 未启用时为 nil，所有方法对 nil 安全。
+type AccountUsageWindowTracker struct {
+	cache          AccountUsageWindowCache
+	nearLimitRatio float64
+	plans          map[string]config.GatewayUsageWindowLimits
+	now            func() time.Time
+
+	mu        sync.Mutex
+	snapshots map[int64]accountUsageWindowSnapshot
+}
+
+type accountUsageWindowSnapshot struct {
+	counts    []int64
+	fetchedAt time.Time
+}
+
+// NewAccountUsageWindowTracker 创建用量窗口跟踪器。
+func NewAccountUsageWindowTracker(cache AccountUsageWindowCache, cfg config.GatewayUsageWindowsConfig) *AccountUsageWindowTracker {
+	plans := make(map[string]config.GatewayUsageWindowLimits, len(cfg.Plans))
+	for plan, limits := range cfg.Plans {
+		if limits.FiveHour <= 0 && limits.Weekly <= 0 {
+			continue
+		}
+		plans[strings.ToLower(strings.TrimSpace(plan))] = limits
+	}
+	return &AccountUsageWindowTracker{
+		cache:          cache,
+		nearLimitRatio: cfg.NearLimitRatio,
+		plans:          plans,
+		now:            time.Now,
+		snapshots:      make(map[int64]accountUsageWindowSnapshot),
+	}
+}
+
+// newAccountUsageWindowTrackerFromConfig 未启用或未配置任何套餐时返回 nil。
+func newAccountUsageWindowTrackerFromConfig(cfg *config.Config, cache AccountUsageWindowCache) *AccountUsageWindowTracker {
+	if cfg == nil || cache == nil || !cfg.Gateway.Scheduling.UsageWindows.Enabled {
+		return nil
+	}
+	tracker := NewAccountUsageWindowTracker(cache, cfg.Gateway.Scheduling.UsageWindows)
+	if len(tracker.plans) == 0 {
+		return nil
+	}
+	return tracker
+}
+
+// limitsFor 返回账号套餐的窗口上限（与 accountUsageWindowSpecs 顺序一致）；套餐未配置时 ok=false。
+func (t *AccountUsageWindowTracker) limitsFor(account *Account) ([]int, bool) {
+	if t == nil || account == nil {
+		return nil, false
+	}
+	plan := strings.ToLower(strings.TrimSpace(account.GetCredential("plan_type")))
+	if plan == "" {
+		return nil, false
+	}
+	limits, ok := t.plans[plan]
+	if !ok {
+		return nil, false
+	}
+	return []int{limits.FiveHour, limits.Weekly}, true
+}
+
+// Record 记录账号的一次成功请求。失败仅记日志，不影响请求。
+func (t *AccountUsageWindowTracker) Record(ctx context.Context, account *Account) {
+	if _, ok := t.limitsFor(account); !ok {
+		return
+	}
+	counts, err := t.cache.IncrementUsageWindows(ctx, account.ID, accountUsageWindowSpecs)
+	if err != nil {
+		logger.L().With(
+			zap.String("component", "service.usage_window"),
+			zap.Int64("account_id", account.ID),
+		).Warn("usage_window.increment_failed", zap.Error(err))
+		return
+	}
+	t.storeSnapshot(account.ID, counts)
+}
+
+// IsNearLimit 判断单个账号是否接近任一窗口上限（读取失败时失败开放）。
+func (t *AccountUsageWindowTracker) IsNearLimit(ctx context.Context, account *Account) bool {
+	if account == nil {
+		return false
+	}
+	_, near := t.NearLimitAccounts(ctx, []*Account{account})[account.ID]
+	return near
+}
+
+// NearLimitAccounts 返回接近窗口上限的账号 ID 集合。
+// 实例内快照未过期的账号直接使用快照，其余批量回源；回源失败时这些账号视为未接近上限。
+func (t *AccountUsageWindowTracker) NearLimitAccounts(ctx context.Context, accounts []*Account) map[int64]struct{} {
+	if t == nil || len(accounts) == 0 {
+		return nil
+	}
+	limitsByID := make(map[int64][]int, len(accounts))
+	for _, account := range accounts {
+		if limits, ok := t.limitsFor(account); ok {
+			limitsByID[account.ID] = limits
+		}
+	}
+	if len(limitsByID) == 0 {
+		return nil
+	}
+
+	now := t.now()
+	counts := make(map[int64][]int64, len(limitsByID))
+	missing := make([]int64, 0, len(limitsByID))
+	t.mu.Lock()
+	for id := range limitsByID {
+		if snap, ok := t.snapshots[id]; ok && now.Sub(snap.fetchedAt) < accountUsageWindowSnapshotTTL {
+			counts[id] = snap.counts
+			continue
+		}
+		missing = append(missing, id)
+	}
+	t.mu.Unlock()
+
+	if len(missing) > 0 {
+		fetched, err := t.cache.GetUsageWindowsBatch(ctx, missing, accountUsageWindowSpecs)
+		if err != nil {
+			logger.L().With(zap.String("component", "service.usage_window")).Warn("usage_window.batch_get_failed", zap.Error(err))
+		}
+		for id, c := range fetched {
+			counts[id] = c
+			t.storeSnapshot(id, c)
+		}
+	}
+
+	near := make(map[int64]struct{})
+	for id, limits := range limitsByID {
+		if t.exceedsNearLimit(counts[id], limits) {
+			near[id] = struct{}{}
+		}
+	}
+	return near
+}
+
+func (t *AccountUsageWindowTracker) exceedsNearLimit(counts []int64, limits []int) bool {
+	for i, limit := range limits {
+		if limit <= 0 || i >= len(counts) {
+			continue
+		}
+		threshold := int64(math.Ceil(float64(limit) * t.nearLimitRatio))
+		if counts[i] >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *AccountUsageWindowTracker) storeSnapshot(accountID int64, counts []int64) {
+	t.mu.Lock()
+	t.snapshots[accountID] = accountUsageWindowSnapshot{counts: counts, fetchedAt: t.now()}
+	t.mu.Unlock()
+}
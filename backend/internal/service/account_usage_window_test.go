@@ -0,0 +1,114 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type accountUsageWindowCacheStub struct {
+	counts     map[int64][]int64
+	batchCalls int
+}
+
+func (s *accountUsageWindowCacheStub) IncrementUsageWindows(_ context.Context, accountID int64, windows []AccountUsageWindowSpec) ([]int64, error) {
+	c := s.counts[accountID]
+	if c == nil {
+		c = make([]int64, len(windows))
+	}
+	for i := range c {
+		c[i]++
+	}
+	s.counts[accountID] = c
+	return append([]int64(nil), c...), nil
+}
+
+func (s *accountUsageWindowCacheStub) GetUsageWindowsBatch(_ context.Context, accountIDs []int64, windows []AccountUsageWindowSpec) (map[int64][]int64, error) {
+	s.batchCalls++
+	out := make(map[int64][]int64, len(accountIDs))
+	for _, id := range accountIDs {
+		if c, ok := s.counts[id]; ok {
+			out[id] = append([]int64(nil), c...)
+		} else {
+			out[id] = make([]int64, len(windows))
+		}
+	}
+	return out, nil
+}
+
+func newUsageWindowTestTracker(cache AccountUsageWindowCache) *AccountUsageWindowTracker {
+	return NewAccountUsageWindowTracker(cache, config.GatewayUsageWindowsConfig{
+		Enabled:        true,
+		NearLimitRatio: 0.8,
+		Plans: map[string]config.GatewayUsageWindowLimits{
+			"Plus": {FiveHour: 10, Weekly: 100},
+			"pro":  {Weekly: 5},
+			"free": {},
+		},
+	})
+}
+
+func usageWindowTestAccount(id int64, plan string) *Account {
+	return &Account{ID: id, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": plan}}
+}
+
+func TestAccountUsageWindowTracker_NearLimitAccounts(t *testing.T) {
+	cache := &accountUsageWindowCacheStub{counts: map[int64][]int64{
+		1: {8, 20}, // 5h 达到 80%
+		2: {7, 20},
+		3: {0, 4}, // pro 周窗口达到 80%
+		4: {100, 100},
+	}}
+	tracker := newUsageWindowTestTracker(cache)
+
+	near := tracker.NearLimitAccounts(context.Background(), []*Account{
+		usageWindowTestAccount(1, "plus"),
+		usageWindowTestAccount(2, "PLUS"),
+		usageWindowTestAccount(3, "pro"),
+		usageWindowTestAccount(4, "free"), // 上限全为 0 的套餐不统计
+		{ID: 5, Platform: PlatformOpenAI, Type: AccountTypeAPIKey},
+	})
+	require.Equal(t, map[int64]struct{}{1: {}, 3: {}}, near)
+}
+
+func TestAccountUsageWindowTracker_RecordRefreshesSnapshot(t *testing.T) {
+	cache := &accountUsageWindowCacheStub{counts: map[int64][]int64{1: {6, 0}}}
+	tracker := newUsageWindowTestTracker(cache)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	account := usageWindowTestAccount(1, "plus")
+
+	require.False(t, tracker.IsNearLimit(context.Background(), account))
+	require.Equal(t, 1, cache.batchCalls)
+
+	tracker.Record(context.Background(), account)
+	tracker.Record(context.Background(), account)
+	require.True(t, tracker.IsNearLimit(context.Background(), account), "local snapshot should reflect recorded usage")
+	require.Equal(t, 1, cache.batchCalls, "fresh snapshot should not hit the cache")
+
+	now = now.Add(accountUsageWindowSnapshotTTL)
+	require.True(t, tracker.IsNearLimit(context.Background(), account))
+	require.Equal(t, 2, cache.batchCalls)
+}
+
+func TestAccountUsageWindowTracker_NilSafe(t *testing.T) {
+	var tracker *AccountUsageWindowTracker
+	tracker.Record(context.Background(), usageWindowTestAccount(1, "plus"))
+	require.False(t, tracker.IsNearLimit(context.Background(), usageWindowTestAccount(1, "plus")))
+
+	require.Nil(t, newAccountUsageWindowTrackerFromConfig(&config.Config{}, &accountUsageWindowCacheStub{}))
+}
+
+func TestExcludeOpenAIUsageWindowNearLimit(t *testing.T) {
+	a, b := usageWindowTestAccount(1, "plus"), usageWindowTestAccount(2, "plus")
+
+	require.Equal(t, []*Account{b}, excludeOpenAIUsageWindowNearLimit([]*Account{a, b}, map[int64]struct{}{1: {}}))
+	// 全部接近上限时保留原列表
+	require.Equal(t, []*Account{a, b}, excludeOpenAIUsageWindowNearLimit([]*Account{a, b}, map[int64]struct{}{1: {}, 2: {}}))
+	require.Equal(t, []*Account{a, b}, excludeOpenAIUsageWindowNearLimit([]*Account{a, b}, nil))
+}
@@ -133,6 +133,48 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 	if input.RateMultiplier <= 0 {
 		return nil, errors.New("rate_multiplier must be > 0")
 	}
+	if err := validateReservedConcurrencyRatio(input.ReservedConcurrencyRatio); err != nil {
+		return nil, err
+	}
+	overloadFallbackModels, err := normalizeOverloadFallbackModels(input.OverloadFallbackModels)
+	if err != nil {
+		return nil, err
+	}
+	contextOverflowModels, err := normalizeContextOverflowModels(input.ContextOverflowModels)
+	if err != nil {
+		return nil, err
+	}
+	contextTruncationStrategy, err := normalizeContextTruncationStrategy(input.ContextTruncationStrategy)
+	if err != nil {
+		return nil, err
+	}
+	accountSelectionStrategy, err := normalizeAccountSelectionStrategy(input.AccountSelectionStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateImagePreprocessTargets(input.ImageMaxEdge, input.ImageMaxBytes); err != nil {
+		return nil, err
+	}
+	outputPostprocess, err := normalizeGroupOutputPostprocessConfig(input.OutputPostprocess)
+	if err != nil {
+		return nil, err
+	}
+	languageRouting, err := normalizeGroupLanguageRoutingConfig(input.LanguageRouting)
+	if err != nil {
+		return nil, err
+	}
+	streamReplay, err := normalizeGroupStreamReplayConfig(input.StreamReplay)
+	if err != nil {
+		return nil, err
+	}
+	capabilityCheck, err := normalizeGroupCapabilityCheckConfig(input.CapabilityCheck)
+	if err != nil {
+		return nil, err
+	}
+	autoContinuation, err := normalizeGroupAutoContinuationConfig(input.AutoContinuation)
+	if err != nil {
+		return nil, err
+	}
 
 	platform := input.Platform
 	if platform == "" {
@@ -190,6 +232,14 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		videoRateMultiplier = *input.VideoRateMultiplier
 	}
 
+	requestSurcharge := 0.0
+	if input.RequestSurcharge != nil {
+		if *input.RequestSurcharge < 0 {
+			return nil, errors.New("request_surcharge must be >= 0")
+		}
+		requestSurcharge = *input.RequestSurcharge
+	}
+
 	peakRateMultiplier := 1.0
 	if input.PeakRateMultiplier != nil {
 		peakRateMultiplier = *input.PeakRateMultiplier
@@ -281,6 +331,7 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		PeakStart:                       peakStart,
 		PeakEnd:                         peakEnd,
 		PeakRateMultiplier:              peakRateMultiplier,
+		RequestSurcharge:                requestSurcharge,
 		ImagePrice1K:                    imagePrice1K,
 		ImagePrice2K:                    imagePrice2K,
 		ImagePrice4K:                    imagePrice4K,
@@ -300,6 +351,19 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		MessagesDispatchModelConfig:     normalizeOpenAIMessagesDispatchModelConfig(input.MessagesDispatchModelConfig),
 		ModelsListConfig:                normalizeGroupModelsListConfig(input.ModelsListConfig),
 		RPMLimit:                        input.RPMLimit,
+		ReservedConcurrencyRatio:        input.ReservedConcurrencyRatio,
+		OverloadFallbackModels:          overloadFallbackModels,
+		ContextOverflowModels:           contextOverflowModels,
+		ContextOverflowReject:           input.ContextOverflowReject,
+		ContextTruncationStrategy:       contextTruncationStrategy,
+		AccountSelectionStrategy:        accountSelectionStrategy,
+		ImageMaxEdge:                    input.ImageMaxEdge,
+		ImageMaxBytes:                   input.ImageMaxBytes,
+		OutputPostprocess:               outputPostprocess,
+		LanguageRouting:                 languageRouting,
+		StreamReplay:                    streamReplay,
+		CapabilityCheck:                 capabilityCheck,
+		AutoContinuation:                autoContinuation,
 	}
 	sanitizeGroupMessagesDispatchFields(group)
 	if err := s.groupRepo.Create(ctx, group); err != nil {
@@ -506,6 +570,12 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 		}
 		group.VideoRateMultiplier = *input.VideoRateMultiplier
 	}
+	if input.RequestSurcharge != nil {
+		if *input.RequestSurcharge < 0 {
+			return nil, errors.New("request_surcharge must be >= 0")
+		}
+		group.RequestSurcharge = *input.RequestSurcharge
+	}
 	if input.PeakRateEnabled != nil {
 		group.PeakRateEnabled = *input.PeakRateEnabled
 	}
@@ -613,6 +683,91 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.RPMLimit != nil {
 		group.RPMLimit = *input.RPMLimit
 	}
+	if input.ReservedConcurrencyRatio != nil {
+		if err := validateReservedConcurrencyRatio(*input.ReservedConcurrencyRatio); err != nil {
+			return nil, err
+		}
+		group.ReservedConcurrencyRatio = *input.ReservedConcurrencyRatio
+	}
+	if input.OverloadFallbackModels != nil {
+		overloadFallbackModels, err := normalizeOverloadFallbackModels(input.OverloadFallbackModels)
+		if err != nil {
+			return nil, err
+		}
+		group.OverloadFallbackModels = overloadFallbackModels
+	}
+	if input.ContextOverflowModels != nil {
+		contextOverflowModels, err := normalizeContextOverflowModels(input.ContextOverflowModels)
+		if err != nil {
+			return nil, err
+		}
+		group.ContextOverflowModels = contextOverflowModels
+	}
+	if input.ContextOverflowReject != nil {
+		group.ContextOverflowReject = *input.ContextOverflowReject
+	}
+	if input.ContextTruncationStrategy != nil {
+		strategy, err := normalizeContextTruncationStrategy(*input.ContextTruncationStrategy)
+		if err != nil {
+			return nil, err
+		}
+		group.ContextTruncationStrategy = strategy
+	}
+	if input.AccountSelectionStrategy != nil {
+		strategy, err := normalizeAccountSelectionStrategy(*input.AccountSelectionStrategy)
+		if err != nil {
+			return nil, err
+		}
+		group.AccountSelectionStrategy = strategy
+	}
+	if input.ImageMaxEdge != nil || input.ImageMaxBytes != nil {
+		maxEdge, maxBytes := group.ImageMaxEdge, group.ImageMaxBytes
+		if input.ImageMaxEdge != nil {
+			maxEdge = *input.ImageMaxEdge
+		}
+		if input.ImageMaxBytes != nil {
+			maxBytes = *input.ImageMaxBytes
+		}
+		if err := validateImagePreprocessTargets(maxEdge, maxBytes); err != nil {
+			return nil, err
+		}
+		group.ImageMaxEdge, group.ImageMaxBytes = maxEdge, maxBytes
+	}
+	if input.OutputPostprocess != nil {
+		outputPostprocess, err := normalizeGroupOutputPostprocessConfig(*input.OutputPostprocess)
+		if err != nil {
+			return nil, err
+		}
+		group.OutputPostprocess = outputPostprocess
+	}
+	if input.LanguageRouting != nil {
+		languageRouting, err := normalizeGroupLanguageRoutingConfig(*input.LanguageRouting)
+		if err != nil {
+			return nil, err
+		}
+		group.LanguageRouting = languageRouting
+	}
+	if input.StreamReplay != nil {
+		streamReplay, err := normalizeGroupStreamReplayConfig(*input.StreamReplay)
+		if err != nil {
+			return nil, err
+		}
+		group.StreamReplay = streamReplay
+	}
+	if input.CapabilityCheck != nil {
+		capabilityCheck, err := normalizeGroupCapabilityCheckConfig(*input.CapabilityCheck)
+		if err != nil {
+			return nil, err
+		}
+		group.CapabilityCheck = capabilityCheck
+	}
+	if input.AutoContinuation != nil {
+		autoContinuation, err := normalizeGroupAutoContinuationConfig(*input.AutoContinuation)
+		if err != nil {
+			return nil, err
+		}
+		group.AutoContinuation = autoContinuation
+	}
 	sanitizeGroupMessagesDispatchFields(group)
 
 	if err := s.groupRepo.Update(ctx, group); err != nil {
@@ -799,6 +954,65 @@ func (s *adminServiceImpl) BatchSetGroupRPMOverrides(ctx context.Context, groupI
 	return nil
 }
 
+// GetGroupAccountTiers 返回分组内各账号的故障转移层级
+func (s *adminServiceImpl) GetGroupAccountTiers(ctx context.Context, groupID int64) ([]GroupAccountTier, error) {
+	if _, err := s.groupRepo.GetByIDLite(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.ListAccountTiers(ctx, groupID)
+}
+
+// BatchSetGroupAccountTiers 批量设置分组内账号的故障转移层级，账号必须已绑定到该分组
+func (s *adminServiceImpl) BatchSetGroupAccountTiers(ctx context.Context, groupID int64, entries []GroupAccountTier) error {
+	current, err := s.GetGroupAccountTiers(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	bound := make(map[int64]struct{}, len(current))
+	for _, item := range current {
+		bound[item.AccountID] = struct{}{}
+	}
+	for _, e := range entries {
+		if !IsValidAccountGroupTier(e.Tier) {
+			return infraerrors.BadRequest("INVALID_ACCOUNT_TIER", fmt.Sprintf("tier must be 0 (primary), 1 (secondary) or 2 (emergency) (account_id=%d)", e.AccountID))
+		}
+		if _, ok := bound[e.AccountID]; !ok {
+			return infraerrors.BadRequest("ACCOUNT_NOT_IN_GROUP", fmt.Sprintf("account %d is not bound to group %d", e.AccountID, groupID))
+		}
+	}
+	return s.groupRepo.SetAccountTiers(ctx, groupID, entries)
+}
+
+// GetGroupAccountConcurrencyShares 返回分组内各账号的并发份额
+func (s *adminServiceImpl) GetGroupAccountConcurrencyShares(ctx context.Context, groupID int64) ([]GroupAccountConcurrencyShare, error) {
+	if _, err := s.groupRepo.GetByIDLite(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.ListAccountConcurrencyShares(ctx, groupID)
+}
+
+// BatchSetGroupAccountConcurrencyShares 批量设置分组内账号的并发份额，账号必须已绑定到该分组。
+// 份额是各分组独立的上限，不要求同一账号在各分组的份额之和为 100。
+func (s *adminServiceImpl) BatchSetGroupAccountConcurrencyShares(ctx context.Context, groupID int64, entries []GroupAccountConcurrencyShare) error {
+	current, err := s.GetGroupAccountConcurrencyShares(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	bound := make(map[int64]struct{}, len(current))
+	for _, item := range current {
+		bound[item.AccountID] = struct{}{}
+	}
+	for _, e := range entries {
+		if err := validateAccountGroupConcurrencyShare(e.ConcurrencyShare); err != nil {
+			return err
+		}
+		if _, ok := bound[e.AccountID]; !ok {
+			return infraerrors.BadRequest("ACCOUNT_NOT_IN_GROUP", fmt.Sprintf("account %d is not bound to group %d", e.AccountID, groupID))
+		}
+	}
+	return s.groupRepo.SetAccountConcurrencyShares(ctx, groupID, entries)
+}
+
 func (s *adminServiceImpl) UpdateGroupSortOrders(ctx context.Context, updates []GroupSortOrderUpdate) error {
 	return s.groupRepo.UpdateSortOrders(ctx, updates)
 }
@@ -932,6 +1146,26 @@ func (s *adminServiceImpl) AdminResetAPIKeyRateLimitUsage(ctx context.Context, k
 	return apiKey, nil
 }
 
+// AdminSetAPIKeyCritical 设置 API Key 是否为关键 Key（可占用分组预留的账号并发）。
+func (s *adminServiceImpl) AdminSetAPIKeyCritical(ctx context.Context, keyID int64, critical bool) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.Critical == critical {
+		return apiKey, nil
+	}
+	apiKey.Critical = critical
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key critical flag: %w", err)
+	}
+	// 关键标记随认证快照缓存，必须失效
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
 // ReplaceUserGroup 替换用户的专属分组
 func (s *adminServiceImpl) ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error) {
 	if oldGroupID == newGroupID {
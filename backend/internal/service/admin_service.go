@@ -47,11 +47,16 @@ type AdminService interface {
 	BatchSetGroupRateMultipliers(ctx context.Context, groupID int64, entries []GroupRateMultiplierInput) error
 	ClearGroupRPMOverrides(ctx context.Context, groupID int64) error
 	BatchSetGroupRPMOverrides(ctx context.Context, groupID int64, entries []GroupRPMOverrideInput) error
+	GetGroupAccountTiers(ctx context.Context, groupID int64) ([]GroupAccountTier, error)
+	BatchSetGroupAccountTiers(ctx context.Context, groupID int64, entries []GroupAccountTier) error
+	GetGroupAccountConcurrencyShares(ctx context.Context, groupID int64) ([]GroupAccountConcurrencyShare, error)
+	BatchSetGroupAccountConcurrencyShares(ctx context.Context, groupID int64, entries []GroupAccountConcurrencyShare) error
 	UpdateGroupSortOrders(ctx context.Context, updates []GroupSortOrderUpdate) error
 
 	// API Key management (admin)
 	AdminUpdateAPIKeyGroupID(ctx context.Context, keyID int64, groupID *int64) (*AdminUpdateAPIKeyGroupIDResult, error)
 	AdminResetAPIKeyRateLimitUsage(ctx context.Context, keyID int64) (*APIKey, error)
+	AdminSetAPIKeyCritical(ctx context.Context, keyID int64, critical bool) (*APIKey, error)
 
 	// ReplaceUserGroup 替换用户的专属分组：授予新分组权限、迁移 Key、移除旧分组权限
 	ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error)
@@ -214,6 +219,7 @@ type CreateGroupInput struct {
 	PeakStart          string
 	PeakEnd            string
 	PeakRateMultiplier *float64
+	RequestSurcharge   *float64 // 按次固定附加费（USD）
 	ImagePrice1K       *float64
 	ImagePrice2K       *float64
 	ImagePrice4K       *float64
@@ -239,6 +245,31 @@ type CreateGroupInput struct {
 	ModelsListConfig            GroupModelsListConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制）
 	RPMLimit int
+	// ReservedConcurrencyRatio 为关键 API Key 预留的账号并发比例 [0,1)
+	ReservedConcurrencyRatio float64
+	// OverloadFallbackModels 过载降级映射（模型模式 -> 备用模型）
+	OverloadFallbackModels map[string]string
+	// ContextOverflowModels 长上下文路由映射（模型模式 -> 大上下文模型）
+	ContextOverflowModels map[string]string
+	// ContextOverflowReject 超出上下文窗口且无可用大上下文模型时直接拒绝
+	ContextOverflowReject bool
+	// ContextTruncationStrategy 超出上下文窗口时的截断策略（drop_oldest/summarize/middle_out）
+	ContextTruncationStrategy string
+	// AccountSelectionStrategy 负载感知调度的账号选择策略（空表示 priority）
+	AccountSelectionStrategy string
+	// ImageMaxEdge / ImageMaxBytes 图片预处理目标（0 = 不限制）
+	ImageMaxEdge  int
+	ImageMaxBytes int
+	// OutputPostprocess 输出后处理配置（空表示不处理）
+	OutputPostprocess GroupOutputPostprocessConfig
+	// LanguageRouting 语言路由规则（空表示不启用）
+	LanguageRouting GroupLanguageRoutingConfig
+	// StreamReplay 流式断线续传配置（空表示不启用）
+	StreamReplay GroupStreamReplayConfig
+	// CapabilityCheck 模型能力校验配置（空表示不启用）
+	CapabilityCheck GroupCapabilityCheckConfig
+	// AutoContinuation 截断自动续写配置（空表示不启用）
+	AutoContinuation GroupAutoContinuationConfig
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -268,6 +299,7 @@ type UpdateGroupInput struct {
 	PeakStart          *string
 	PeakEnd            *string
 	PeakRateMultiplier *float64
+	RequestSurcharge   *float64 // 按次固定附加费（USD），nil 表示不修改
 	ImagePrice1K       *float64
 	ImagePrice2K       *float64
 	ImagePrice4K       *float64
@@ -293,6 +325,31 @@ type UpdateGroupInput struct {
 	ModelsListConfig            *GroupModelsListConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制），nil 表示未提供不改动。
 	RPMLimit *int
+	// ReservedConcurrencyRatio 为关键 API Key 预留的账号并发比例 [0,1)，nil 表示不修改
+	ReservedConcurrencyRatio *float64
+	// OverloadFallbackModels 过载降级映射，nil 表示不修改，空 map 表示清除
+	OverloadFallbackModels map[string]string
+	// ContextOverflowModels 长上下文路由映射，nil 表示不修改，空 map 表示清除
+	ContextOverflowModels map[string]string
+	// ContextOverflowReject 超出上下文窗口时是否直接拒绝，nil 表示不修改
+	ContextOverflowReject *bool
+	// ContextTruncationStrategy 超出上下文窗口时的截断策略，nil 表示不修改，空串表示关闭
+	ContextTruncationStrategy *string
+	// AccountSelectionStrategy 账号选择策略，nil 表示不修改，空串表示恢复默认（priority）
+	AccountSelectionStrategy *string
+	// ImageMaxEdge / ImageMaxBytes 图片预处理目标，nil 表示不修改，0 表示不限制
+	ImageMaxEdge  *int
+	ImageMaxBytes *int
+	// OutputPostprocess 输出后处理配置，nil 表示不修改
+	OutputPostprocess *GroupOutputPostprocessConfig
+	// LanguageRouting 语言路由规则，nil 表示不修改
+	LanguageRouting *GroupLanguageRoutingConfig
+	// StreamReplay 流式断线续传配置，nil 表示不修改
+	StreamReplay *GroupStreamReplayConfig
+	// CapabilityCheck 模型能力校验配置，nil 表示不修改
+	CapabilityCheck *GroupCapabilityCheckConfig
+	// AutoContinuation 截断自动续写配置，nil 表示不修改
+	AutoContinuation *GroupAutoContinuationConfig
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
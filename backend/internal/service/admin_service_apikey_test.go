@@ -251,6 +251,18 @@ func (s *groupRepoStubForGroupUpdate) GetAccountIDsByGroupIDs(context.Context, [
 func (s *groupRepoStubForGroupUpdate) BindAccountsToGroup(context.Context, int64, []int64) error {
 	panic("unexpected")
 }
+func (s *groupRepoStubForGroupUpdate) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	panic("unexpected")
+}
+func (s *groupRepoStubForGroupUpdate) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	panic("unexpected")
+}
+func (s *groupRepoStubForGroupUpdate) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	panic("unexpected")
+}
+func (s *groupRepoStubForGroupUpdate) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	panic("unexpected")
+}
 func (s *groupRepoStubForGroupUpdate) UpdateSortOrders(context.Context, []GroupSortOrderUpdate) error {
 	panic("unexpected")
 }
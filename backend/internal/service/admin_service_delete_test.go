@@ -240,6 +240,22 @@ func (s *groupRepoStub) BindAccountsToGroup(ctx context.Context, groupID int64,
 	panic("unexpected BindAccountsToGroup call")
 }
 
+func (s *groupRepoStub) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	panic("unexpected ListAccountTiers call")
+}
+
+func (s *groupRepoStub) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	panic("unexpected SetAccountTiers call")
+}
+
+func (s *groupRepoStub) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	panic("unexpected ListAccountConcurrencyShares call")
+}
+
+func (s *groupRepoStub) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	panic("unexpected SetAccountConcurrencyShares call")
+}
+
 func (s *groupRepoStub) GetAccountIDsByGroupIDs(ctx context.Context, groupIDs []int64) ([]int64, error) {
 	panic("unexpected GetAccountIDsByGroupIDs call")
 }
@@ -117,6 +117,22 @@ func (s *groupRepoStubForAdmin) BindAccountsToGroup(_ context.Context, _ int64,
 	panic("unexpected BindAccountsToGroup call")
 }
 
+func (s *groupRepoStubForAdmin) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	panic("unexpected ListAccountTiers call")
+}
+
+func (s *groupRepoStubForAdmin) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	panic("unexpected SetAccountTiers call")
+}
+
+func (s *groupRepoStubForAdmin) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	panic("unexpected ListAccountConcurrencyShares call")
+}
+
+func (s *groupRepoStubForAdmin) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	panic("unexpected SetAccountConcurrencyShares call")
+}
+
 func (s *groupRepoStubForAdmin) GetAccountIDsByGroupIDs(_ context.Context, _ []int64) ([]int64, error) {
 	panic("unexpected GetAccountIDsByGroupIDs call")
 }
@@ -946,6 +962,22 @@ func (s *groupRepoStubForFallbackCycle) BindAccountsToGroup(_ context.Context, _
 	panic("unexpected BindAccountsToGroup call")
 }
 
+func (s *groupRepoStubForFallbackCycle) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	panic("unexpected ListAccountTiers call")
+}
+
+func (s *groupRepoStubForFallbackCycle) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	panic("unexpected SetAccountTiers call")
+}
+
+func (s *groupRepoStubForFallbackCycle) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	panic("unexpected ListAccountConcurrencyShares call")
+}
+
+func (s *groupRepoStubForFallbackCycle) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	panic("unexpected SetAccountConcurrencyShares call")
+}
+
 func (s *groupRepoStubForFallbackCycle) GetAccountIDsByGroupIDs(_ context.Context, _ []int64) ([]int64, error) {
 	panic("unexpected GetAccountIDsByGroupIDs call")
 }
@@ -1025,6 +1057,22 @@ func (s *groupRepoStubForInvalidRequestFallback) BindAccountsToGroup(_ context.C
 	panic("unexpected BindAccountsToGroup call")
 }
 
+func (s *groupRepoStubForInvalidRequestFallback) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	panic("unexpected ListAccountTiers call")
+}
+
+func (s *groupRepoStubForInvalidRequestFallback) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	panic("unexpected SetAccountTiers call")
+}
+
+func (s *groupRepoStubForInvalidRequestFallback) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	panic("unexpected ListAccountConcurrencyShares call")
+}
+
+func (s *groupRepoStubForInvalidRequestFallback) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	panic("unexpected SetAccountConcurrencyShares call")
+}
+
 func (s *groupRepoStubForInvalidRequestFallback) UpdateSortOrders(_ context.Context, _ []GroupSortOrderUpdate) error {
 	return nil
 }
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	// AdminServiceTokenPrefix 服务令牌明文前缀，鉴权中间件据此区分服务令牌与登录 JWT。
+	AdminServiceTokenPrefix = "sat_"
+
+	AdminServiceTokenScopeRead  = "read"
+	AdminServiceTokenScopeWrite = "write"
+
+	adminServiceTokenMaxNameLen      = 100
+	adminServiceTokenMaxScopes       = 50
+	adminServiceTokenDisplayLen      = 12
+	adminServiceTokenLastUsedMinGap  = 30 * time.Second
+	adminServiceTokenLastUsedBackoff = 5 * time.Second
+)
+
+// adminServiceTokenResources 可授权给服务令牌的管理 API 资源，对应 /api/v1/admin/ 下的一级路径。
+// 合规确认与服务令牌自身的管理接口不在其中：服务令牌无法创建或撤销令牌。
+var adminServiceTokenResources = map[string]struct{}{
+	"accounts": {}, "affiliates": {}, "announcements": {}, "antigravity": {}, "api-keys": {},
+	"backups": {}, "billing": {}, "channel-monitor-templates": {}, "channel-monitors": {}, "channels": {},
+	"config-sync": {}, "config-versions": {}, "dashboard": {}, "data-management": {}, "debug": {},
+	"error-passthrough-rules": {}, "gemini": {}, "graphql": {}, "grok": {}, "groups": {},
+	"model-prices": {}, "openai": {}, "ops": {}, "payment": {}, "promo-codes": {}, "proxies": {},
+	"redeem-codes": {}, "risk-control": {}, "scheduled-test-plans": {}, "settings": {},
+	"spend-budgets": {}, "subscriptions": {}, "system": {}, "tls-fingerprint-profiles": {},
+	"transform-fixtures": {}, "usage": {}, "user-attributes": {}, "users": {},
+}
+
+var (
+	ErrAdminServiceTokenNotFound     = infraerrors.NotFound("ADMIN_SERVICE_TOKEN_NOT_FOUND", "service token not found")
+	ErrAdminServiceTokenInvalidName  = infraerrors.BadRequest("ADMIN_SERVICE_TOKEN_INVALID_NAME", fmt.Sprintf("name must be 1-%d characters", adminServiceTokenMaxNameLen))
+	ErrAdminServiceTokenNoScopes     = infraerrors.BadRequest("ADMIN_SERVICE_TOKEN_NO_SCOPES", "at least one scope is required")
+	ErrAdminServiceTokenInvalidScope = infraerrors.BadRequest("ADMIN_SERVICE_TOKEN_INVALID_SCOPE", "invalid scope")
+	ErrAdminServiceTokenInvalidTTL   = infraerrors.BadRequest("ADMIN_SERVICE_TOKEN_INVALID_EXPIRY", "expires_at must be in the future")
+	ErrAdminServiceTokenInvalid      = infraerrors.Unauthorized("INVALID_SERVICE_TOKEN", "Invalid service token")
+	ErrAdminServiceTokenExpired      = infraerrors.Unauthorized("SERVICE_TOKEN_EXPIRED", "Service token has expired")
+	ErrAdminServiceTokenRevoked      = infraerrors.Unauthorized("SERVICE_TOKEN_REVOKED", "Service token has been revoked")
+)
+
+// AdminServiceToken 管理 API 服务令牌：按 scope 授权、可设置过期时间，并记录最近一次使用。
+// 请求以创建者（CreatedBy）的管理员身份执行，明文令牌仅在创建时返回。
+type AdminServiceToken struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	CreatedBy   int64      `json:"created_by"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsExpired 令牌是否已过期。
+func (t *AdminServiceToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// Allows 判断令牌是否允许对 resource 执行读（write=false）或写操作。
+// scope 形如 "<resource>:<read|write>"，resource 可为 "*"；write 隐含 read。
+func (t *AdminServiceToken) Allows(resource string, write bool) bool {
+	if resource == "" {
+		return false
+	}
+	if _, ok := adminServiceTokenResources[resource]; !ok {
+		return false
+	}
+	for _, scope := range t.Scopes {
+		res, action, ok := strings.Cut(scope, ":")
+		if !ok || (res != "*" && res != resource) {
+			continue
+		}
+		if action == AdminServiceTokenScopeWrite || (!write && action == AdminServiceTokenScopeRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminServiceTokenInput 创建服务令牌的入参。
+type AdminServiceTokenInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// AdminServiceTokenCreated 创建结果，Token 为明文，只返回这一次。
+type AdminServiceTokenCreated struct {
+	*AdminServiceToken
+	Token string `json:"token"`
+}
+
+// AdminServiceTokenRepository 服务令牌存储。
+type AdminServiceTokenRepository interface {
+	Create(ctx context.Context, token *AdminServiceToken, tokenHash string) (*AdminServiceToken, error)
+	List(ctx context.Context) ([]*AdminServiceToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*AdminServiceToken, error)
+	// Revoke 设置 revoked_at，已撤销的令牌保持原撤销时间。
+	Revoke(ctx context.Context, id int64) (*AdminServiceToken, error)
+	Delete(ctx context.Context, id int64) error
+	UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time, ip string) error
+}
+
+// AdminServiceTokenService 管理服务令牌并在管理端鉴权时校验令牌。
+type AdminServiceTokenService struct {
+	repo AdminServiceTokenRepository
+
+	// lastUsedNext 记录每个令牌下次允许写入 last_used 的时间，避免脚本高频调用造成写放大。
+	lastUsedNext sync.Map
+}
+
+// NewAdminServiceTokenService 创建服务令牌服务。
+func NewAdminServiceTokenService(repo AdminServiceTokenRepository) *AdminServiceTokenService {
+	return &AdminServiceTokenService{repo: repo}
+}
+
+// List 返回全部服务令牌（不含明文）。
+func (s *AdminServiceTokenService) List(ctx context.Context) ([]*AdminServiceToken, error) {
+	tokens, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		tokens = []*AdminServiceToken{}
+	}
+	return tokens, nil
+}
+
+// Create 生成新令牌，返回值中的明文令牌之后无法再次获取。
+func (s *AdminServiceTokenService) Create(ctx context.Context, createdBy int64, in AdminServiceTokenInput) (*AdminServiceTokenCreated, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len([]rune(name)) > adminServiceTokenMaxNameLen {
+		return nil, ErrAdminServiceTokenInvalidName
+	}
+	scopes, err := NormalizeAdminServiceTokenScopes(in.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, ErrAdminServiceTokenInvalidTTL
+	}
+
+	plain, err := generateAdminServiceToken()
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, &AdminServiceToken{
+		Name:        name,
+		TokenPrefix: plain[:adminServiceTokenDisplayLen],
+		Scopes:      scopes,
+		ExpiresAt:   in.ExpiresAt,
+		CreatedBy:   createdBy,
+	}, hashAdminServiceToken(plain))
+	if err != nil {
+		return nil, err
+	}
+	return &AdminServiceTokenCreated{AdminServiceToken: created, Token: plain}, nil
+}
+
+// Revoke 撤销令牌，撤销后立即无法鉴权，记录保留以便审计。
+func (s *AdminServiceTokenService) Revoke(ctx context.Context, id int64) (*AdminServiceToken, error) {
+	return s.repo.Revoke(ctx, id)
+}
+
+// Delete 删除令牌记录。
+func (s *AdminServiceTokenService) Delete(ctx context.Context, id int64) error {
+	s.lastUsedNext.Delete(id)
+	return s.repo.Delete(ctx, id)
+}
+
+// Authenticate 校验明文令牌，成功时按防抖间隔记录使用时间与来源 IP（尽力而为，失败不影响鉴权）。
+func (s *AdminServiceTokenService) Authenticate(ctx context.Context, plain, clientIP string) (*AdminServiceToken, error) {
+	if !strings.HasPrefix(plain, AdminServiceTokenPrefix) {
+		return nil, ErrAdminServiceTokenInvalid
+	}
+	token, err := s.repo.GetByHash(ctx, hashAdminServiceToken(plain))
+	if err != nil {
+		if infraerrors.IsNotFound(err) {
+			return nil, ErrAdminServiceTokenInvalid
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if token.RevokedAt != nil {
+		return nil, ErrAdminServiceTokenRevoked
+	}
+	if token.IsExpired(now) {
+		return nil, ErrAdminServiceTokenExpired
+	}
+
+	if v, ok := s.lastUsedNext.Load(token.ID); !ok || !now.Before(v.(time.Time)) {
+		if err := s.repo.UpdateLastUsed(ctx, token.ID, now, clientIP); err != nil {
+			s.lastUsedNext.Store(token.ID, now.Add(adminServiceTokenLastUsedBackoff))
+		} else {
+			s.lastUsedNext.Store(token.ID, now.Add(adminServiceTokenLastUsedMinGap))
+			token.LastUsedAt = &now
+			token.LastUsedIP = clientIP
+		}
+	}
+	return token, nil
+}
+
+// NormalizeAdminServiceTokenScopes 校验并规范化 scope 列表：小写、去重、排序。
+func NormalizeAdminServiceTokenScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, raw := range scopes {
+		scope := strings.ToLower(strings.TrimSpace(raw))
+		if scope == "" {
+			continue
+		}
+		resource, action, ok := strings.Cut(scope, ":")
+		if !ok || (action != AdminServiceTokenScopeRead && action != AdminServiceTokenScopeWrite) {
+			return nil, ErrAdminServiceTokenInvalidScope.WithMetadata(map[string]string{"scope": raw})
+		}
+		if _, known := adminServiceTokenResources[resource]; resource != "*" && !known {
+			return nil, ErrAdminServiceTokenInvalidScope.WithMetadata(map[string]string{"scope": raw})
+		}
+		if _, dup := seen[scope]; dup {
+			continue
+		}
+		seen[scope] = struct{}{}
+		out = append(out, scope)
+	}
+	if len(out) == 0 {
+		return nil, ErrAdminServiceTokenNoScopes
+	}
+	if len(out) > adminServiceTokenMaxScopes {
+		return nil, ErrAdminServiceTokenInvalidScope
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func generateAdminServiceToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate service token: %w", err)
+	}
+	return AdminServiceTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashAdminServiceToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
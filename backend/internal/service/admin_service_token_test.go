@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type adminServiceTokenRepoStub struct {
+	byHash      map[string]*AdminServiceToken
+	created     *AdminServiceToken
+	createdHash string
+	touches     int
+}
+
+func (r *adminServiceTokenRepoStub) Create(_ context.Context, token *AdminServiceToken, tokenHash string) (*AdminServiceToken, error) {
+	clone := *token
+	clone.ID = 1
+	r.created, r.createdHash = &clone, tokenHash
+	return &clone, nil
+}
+
+func (r *adminServiceTokenRepoStub) List(context.Context) ([]*AdminServiceToken, error) {
+	return nil, nil
+}
+
+func (r *adminServiceTokenRepoStub) GetByHash(_ context.Context, tokenHash string) (*AdminServiceToken, error) {
+	if token, ok := r.byHash[tokenHash]; ok {
+		clone := *token
+		return &clone, nil
+	}
+	return nil, ErrAdminServiceTokenNotFound
+}
+
+func (r *adminServiceTokenRepoStub) Revoke(context.Context, int64) (*AdminServiceToken, error) {
+	return nil, ErrAdminServiceTokenNotFound
+}
+
+func (r *adminServiceTokenRepoStub) Delete(context.Context, int64) error {
+	return nil
+}
+
+func (r *adminServiceTokenRepoStub) UpdateLastUsed(context.Context, int64, time.Time, string) error {
+	r.touches++
+	return nil
+}
+
+func TestNormalizeAdminServiceTokenScopes(t *testing.T) {
+	scopes, err := NormalizeAdminServiceTokenScopes([]string{" Usage:Read ", "accounts:read", "usage:read", ""})
+	require.NoError(t, err)
+	require.Equal(t, []string{"accounts:read", "usage:read"}, scopes)
+
+	_, err = NormalizeAdminServiceTokenScopes(nil)
+	require.ErrorIs(t, err, ErrAdminServiceTokenNoScopes)
+	for _, bad := range []string{"accounts", "accounts:admin", "service-tokens:write", "compliance:read", "nope:read"} {
+		_, err = NormalizeAdminServiceTokenScopes([]string{bad})
+		require.ErrorIs(t, err, ErrAdminServiceTokenInvalidScope, bad)
+	}
+}
+
+func TestAdminServiceTokenAllows(t *testing.T) {
+	token := &AdminServiceToken{Scopes: []string{"accounts:read", "groups:write"}}
+	require.True(t, token.Allows("accounts", false))
+	require.False(t, token.Allows("accounts", true))
+	require.True(t, token.Allows("groups", false))
+	require.True(t, token.Allows("groups", true))
+	require.False(t, token.Allows("usage", false))
+
+	readAll := &AdminServiceToken{Scopes: []string{"*:read"}}
+	require.True(t, readAll.Allows("usage", false))
+	require.False(t, readAll.Allows("usage", true))
+	require.False(t, readAll.Allows("service-tokens", false))
+	require.False(t, readAll.Allows("", false))
+}
+
+func TestAdminServiceTokenCreateAndAuthenticate(t *testing.T) {
+	repo := &adminServiceTokenRepoStub{}
+	svc := NewAdminServiceTokenService(repo)
+	ctx := context.Background()
+
+	created, err := svc.Create(ctx, 9, AdminServiceTokenInput{Name: " dashboard ", Scopes: []string{"usage:read"}})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(created.Token, AdminServiceTokenPrefix))
+	require.Equal(t, "dashboard", created.Name)
+	require.Equal(t, created.Token[:adminServiceTokenDisplayLen], created.TokenPrefix)
+	require.Equal(t, hashAdminServiceToken(created.Token), repo.createdHash)
+	require.NotContains(t, repo.createdHash, created.Token)
+
+	repo.byHash = map[string]*AdminServiceToken{repo.createdHash: repo.created}
+	token, err := svc.Authenticate(ctx, created.Token, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, int64(9), token.CreatedBy)
+	require.Equal(t, "10.0.0.1", token.LastUsedIP)
+	_, err = svc.Authenticate(ctx, created.Token, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, 1, repo.touches, "last_used should be debounced")
+
+	_, err = svc.Authenticate(ctx, AdminServiceTokenPrefix+"unknown", "")
+	require.ErrorIs(t, err, ErrAdminServiceTokenInvalid)
+
+	past := time.Now().Add(-time.Minute)
+	repo.created.ExpiresAt = &past
+	_, err = svc.Authenticate(ctx, created.Token, "")
+	require.ErrorIs(t, err, ErrAdminServiceTokenExpired)
+
+	repo.created.ExpiresAt = nil
+	repo.created.RevokedAt = &past
+	_, err = svc.Authenticate(ctx, created.Token, "")
+	require.ErrorIs(t, err, ErrAdminServiceTokenRevoked)
+
+	_, err = svc.Create(ctx, 9, AdminServiceTokenInput{Name: "old", Scopes: []string{"usage:read"}, ExpiresAt: &past})
+	require.ErrorIs(t, err, ErrAdminServiceTokenInvalidTTL)
+}
@@ -144,7 +144,7 @@ func (s *AntigravityGatewayService) readUpstreamErrorBody(resp *http.Response) [
 		return nil
 	}
 	body, _ := io.ReadAll(io.LimitReader(resp.Body, s.upstreamErrorBodyReadLimit()))
-	return body
+	return replaceUpstreamHTMLErrorBody(resp, body)
 }
 
 func NewAntigravityGatewayService(
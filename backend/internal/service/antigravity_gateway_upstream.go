@@ -18,7 +18,10 @@ import (
 )
 
 // ForwardUpstream 使用 base_url + /v1/messages + 双 header 认证透传上游 Claude 请求
-func (s *AntigravityGatewayService) ForwardUpstream(ctx context.Context, c *gin.Context, account *Account, body []byte) (*ForwardResult, error) {
+func (s *AntigravityGatewayService) ForwardUpstream(ctx context.Context, c *gin.Context, account *Account, body []byte) (forwardResult *ForwardResult, retErr error) {
+	ctx, span := startGatewayForwardSpan(ctx, "antigravity.forward_upstream", account)
+	defer func() { endGatewayForwardSpan(span, forwardResult.upstreamRequestID(), retErr) }()
+
 	startTime := time.Now()
 	sessionID := getSessionID(c)
 	prefix := logPrefix(sessionID, account.Name)
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"strings"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
@@ -62,6 +63,23 @@ type APIKey struct {
 	Window5hStart *time.Time // Start of current 5h window
 	Window1dStart *time.Time // Start of current 1d window
 	Window7dStart *time.Time // Start of current 7d window
+
+	// Throughput limits enforced by per-key token buckets (0 = unlimited)
+	RPMLimit int   // Requests per minute
+	TPMLimit int64 // Tokens per minute (input + output + cache)
+
+	// Critical keys may consume the group's reserved account concurrency
+	Critical bool
+
+	// Guest key fields
+	Guest         bool     // Temporary guest key, deleted by the cleanup job after expiry
+	AllowedModels []string // Allowed request models, supports trailing * wildcard (empty = unrestricted)
+	TokenLimit    int64    // Total token cap (0 = unlimited)
+	TokensUsed    int64    // Total tokens consumed
+
+	// Project grouping: member keys share the project's quota
+	ProjectID *int64
+	Project   *APIKeyProject // Only loaded on the auth path
 }
 
 func (k *APIKey) IsActive() bool {
@@ -73,6 +91,11 @@ func (k *APIKey) HasRateLimits() bool {
 	return k.RateLimit5h > 0 || k.RateLimit1d > 0 || k.RateLimit7d > 0
 }
 
+// HasThroughputLimits returns true if an RPM or TPM limit is configured
+func (k *APIKey) HasThroughputLimits() bool {
+	return k.RPMLimit > 0 || k.TPMLimit > 0
+}
+
 // IsExpired checks if the API key has expired
 func (k *APIKey) IsExpired() bool {
 	if k.ExpiresAt == nil {
@@ -89,6 +112,52 @@ func (k *APIKey) IsQuotaExhausted() bool {
 	return k.QuotaUsed >= k.Quota
 }
 
+// IsTokenLimitExhausted checks if the API key token cap is reached
+func (k *APIKey) IsTokenLimitExhausted() bool {
+	if k.TokenLimit <= 0 {
+		return false // unlimited
+	}
+	return k.TokensUsed >= k.TokenLimit
+}
+
+// IsProjectQuotaExhausted checks if the shared quota of the key's project is exhausted
+func (k *APIKey) IsProjectQuotaExhausted() bool {
+	if k.Project == nil {
+		return false
+	}
+	return k.Project.IsQuotaExhausted()
+}
+
+// HasModelRestriction returns true if the key may only request AllowedModels
+func (k *APIKey) HasModelRestriction() bool {
+	return len(k.AllowedModels) > 0
+}
+
+// IsModelAllowed checks the requested model against AllowedModels (case-insensitive,
+// a trailing "*" matches by prefix). Keys without restriction allow any model.
+func (k *APIKey) IsModelAllowed(model string) bool {
+	if !k.HasModelRestriction() {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return false
+	}
+	for _, pattern := range k.AllowedModels {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(model, prefix) {
+				return true
+			}
+			continue
+		}
+		if pattern == model {
+			return true
+		}
+	}
+	return false
+}
+
 // GetQuotaRemaining returns remaining quota (-1 for unlimited)
 func (k *APIKey) GetQuotaRemaining() float64 {
 	if k.Quota <= 0 {
@@ -26,6 +26,30 @@ type APIKeyAuthSnapshot struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Throughput limits (token bucket state lives in Redis / local memory)
+	RPMLimit int   `json:"rpm_limit,omitempty"`
+	TPMLimit int64 `json:"tpm_limit,omitempty"`
+
+	// Critical keys may consume the group's reserved account concurrency
+	Critical bool `json:"critical,omitempty"`
+
+	// Guest key scope: model allowlist and token cap
+	Guest         bool     `json:"guest,omitempty"`
+	AllowedModels []string `json:"allowed_models,omitempty"`
+	TokenLimit    int64    `json:"token_limit,omitempty"`
+	TokensUsed    int64    `json:"tokens_used,omitempty"`
+
+	// Project grouping: shared project quota (loaded when the snapshot is built)
+	ProjectID *int64                     `json:"project_id,omitempty"`
+	Project   *APIKeyAuthProjectSnapshot `json:"project,omitempty"`
+}
+
+// APIKeyAuthProjectSnapshot Key 所属项目的额度快照
+type APIKeyAuthProjectSnapshot struct {
+	ID        int64   `json:"id"`
+	Quota     float64 `json:"quota"`
+	QuotaUsed float64 `json:"quota_used"`
 }
 
 // APIKeyAuthUserSnapshot 用户快照
@@ -107,6 +131,42 @@ type APIKeyAuthGroupSnapshot struct {
 	PeakStart          string  `json:"peak_start"`
 	PeakEnd            string  `json:"peak_end"`
 	PeakRateMultiplier float64 `json:"peak_rate_multiplier"`
+
+	// RequestSurcharge 分组按次附加费；扣费路径读取 apiKey.Group，必须随快照缓存。
+	RequestSurcharge float64 `json:"request_surcharge"`
+
+	// ReservedConcurrencyRatio 账号并发中为关键 Key 预留的比例；调度热路径需要，必须随快照缓存。
+	ReservedConcurrencyRatio float64 `json:"reserved_concurrency_ratio,omitempty"`
+
+	// OverloadFallbackModels 过载降级映射；调度失败时在热路径读取，必须随快照缓存。
+	OverloadFallbackModels map[string]string `json:"overload_fallback_models,omitempty"`
+
+	// 长上下文路由配置；转发前在热路径读取，必须随快照缓存。
+	ContextOverflowModels     map[string]string `json:"context_overflow_models,omitempty"`
+	ContextOverflowReject     bool              `json:"context_overflow_reject,omitempty"`
+	ContextTruncationStrategy string            `json:"context_truncation_strategy,omitempty"`
+
+	// 账号选择策略；调度时在热路径读取，必须随快照缓存。
+	AccountSelectionStrategy string `json:"account_selection_strategy,omitempty"`
+
+	// 图片预处理目标；转发前在热路径读取，必须随快照缓存。
+	ImageMaxEdge  int `json:"image_max_edge,omitempty"`
+	ImageMaxBytes int `json:"image_max_bytes,omitempty"`
+
+	// 输出后处理配置；响应写出时在热路径读取，必须随快照缓存。
+	OutputPostprocess GroupOutputPostprocessConfig `json:"output_postprocess,omitempty"`
+
+	// 语言路由规则；调度前在热路径读取，必须随快照缓存。
+	LanguageRouting GroupLanguageRoutingConfig `json:"language_routing,omitempty"`
+
+	// 流式断线续传配置；响应写出时在热路径读取，必须随快照缓存。
+	StreamReplay GroupStreamReplayConfig `json:"stream_replay,omitempty"`
+
+	// 模型能力校验配置
+	CapabilityCheck GroupCapabilityCheckConfig `json:"capability_check,omitempty"`
+
+	// 截断自动续写配置；非流式响应写出前在热路径读取，必须随快照缓存。
+	AutoContinuation GroupAutoContinuationConfig `json:"auto_continuation,omitempty"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
@@ -14,7 +14,7 @@ import (
 	"github.com/dgraph-io/ristretto"
 )
 
-const apiKeyAuthSnapshotVersion = 14 // v14: include group video pricing fields
+const apiKeyAuthSnapshotVersion = 28 // v28: include group request surcharge
 
 type apiKeyAuthCacheConfig struct {
 	l1Size        int
@@ -206,20 +206,28 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 		return nil
 	}
 	snapshot := &APIKeyAuthSnapshot{
-		Version:     apiKeyAuthSnapshotVersion,
-		APIKeyID:    apiKey.ID,
-		UserID:      apiKey.UserID,
-		GroupID:     apiKey.GroupID,
-		Name:        apiKey.Name,
-		Status:      apiKey.Status,
-		IPWhitelist: apiKey.IPWhitelist,
-		IPBlacklist: apiKey.IPBlacklist,
-		Quota:       apiKey.Quota,
-		QuotaUsed:   apiKey.QuotaUsed,
-		ExpiresAt:   apiKey.ExpiresAt,
-		RateLimit5h: apiKey.RateLimit5h,
-		RateLimit1d: apiKey.RateLimit1d,
-		RateLimit7d: apiKey.RateLimit7d,
+		Version:       apiKeyAuthSnapshotVersion,
+		APIKeyID:      apiKey.ID,
+		UserID:        apiKey.UserID,
+		GroupID:       apiKey.GroupID,
+		Name:          apiKey.Name,
+		Status:        apiKey.Status,
+		IPWhitelist:   apiKey.IPWhitelist,
+		IPBlacklist:   apiKey.IPBlacklist,
+		Quota:         apiKey.Quota,
+		QuotaUsed:     apiKey.QuotaUsed,
+		ExpiresAt:     apiKey.ExpiresAt,
+		RateLimit5h:   apiKey.RateLimit5h,
+		RateLimit1d:   apiKey.RateLimit1d,
+		RateLimit7d:   apiKey.RateLimit7d,
+		RPMLimit:      apiKey.RPMLimit,
+		TPMLimit:      apiKey.TPMLimit,
+		Critical:      apiKey.Critical,
+		Guest:         apiKey.Guest,
+		AllowedModels: apiKey.AllowedModels,
+		TokenLimit:    apiKey.TokenLimit,
+		TokensUsed:    apiKey.TokensUsed,
+		ProjectID:     apiKey.ProjectID,
 		User: APIKeyAuthUserSnapshot{
 			ID:                         apiKey.User.ID,
 			Status:                     apiKey.User.Status,
@@ -246,6 +254,13 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 		}
 		// 查询失败或无 override 时留 nil，checkRPM 会回退到 DB 查询
 	}
+	// 项目合计额度同样在构建快照时查询；超额时由扣费链路失效全部成员 Key 的缓存
+	if apiKey.ProjectID != nil && s.projectRepo != nil {
+		project, err := s.projectRepo.GetByID(ctx, *apiKey.ProjectID)
+		if err == nil && project != nil {
+			snapshot.Project = &APIKeyAuthProjectSnapshot{ID: project.ID, Quota: project.Quota, QuotaUsed: project.QuotaUsed}
+		}
+	}
 	if apiKey.Group != nil {
 		snapshot.Group = &APIKeyAuthGroupSnapshot{
 			ID:                              apiKey.Group.ID,
@@ -286,6 +301,20 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			PeakStart:                       apiKey.Group.PeakStart,
 			PeakEnd:                         apiKey.Group.PeakEnd,
 			PeakRateMultiplier:              apiKey.Group.PeakRateMultiplier,
+			RequestSurcharge:                apiKey.Group.RequestSurcharge,
+			ReservedConcurrencyRatio:        apiKey.Group.ReservedConcurrencyRatio,
+			OverloadFallbackModels:          apiKey.Group.OverloadFallbackModels,
+			ContextOverflowModels:           apiKey.Group.ContextOverflowModels,
+			ContextOverflowReject:           apiKey.Group.ContextOverflowReject,
+			ContextTruncationStrategy:       apiKey.Group.ContextTruncationStrategy,
+			AccountSelectionStrategy:        apiKey.Group.AccountSelectionStrategy,
+			ImageMaxEdge:                    apiKey.Group.ImageMaxEdge,
+			ImageMaxBytes:                   apiKey.Group.ImageMaxBytes,
+			OutputPostprocess:               apiKey.Group.OutputPostprocess,
+			LanguageRouting:                 apiKey.Group.LanguageRouting,
+			StreamReplay:                    apiKey.Group.StreamReplay,
+			CapabilityCheck:                 apiKey.Group.CapabilityCheck,
+			AutoContinuation:                apiKey.Group.AutoContinuation,
 		}
 	}
 	return snapshot
@@ -296,20 +325,28 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 		return nil
 	}
 	apiKey := &APIKey{
-		ID:          snapshot.APIKeyID,
-		UserID:      snapshot.UserID,
-		GroupID:     snapshot.GroupID,
-		Key:         key,
-		Name:        snapshot.Name,
-		Status:      snapshot.Status,
-		IPWhitelist: snapshot.IPWhitelist,
-		IPBlacklist: snapshot.IPBlacklist,
-		Quota:       snapshot.Quota,
-		QuotaUsed:   snapshot.QuotaUsed,
-		ExpiresAt:   snapshot.ExpiresAt,
-		RateLimit5h: snapshot.RateLimit5h,
-		RateLimit1d: snapshot.RateLimit1d,
-		RateLimit7d: snapshot.RateLimit7d,
+		ID:            snapshot.APIKeyID,
+		UserID:        snapshot.UserID,
+		GroupID:       snapshot.GroupID,
+		Key:           key,
+		Name:          snapshot.Name,
+		Status:        snapshot.Status,
+		IPWhitelist:   snapshot.IPWhitelist,
+		IPBlacklist:   snapshot.IPBlacklist,
+		Quota:         snapshot.Quota,
+		QuotaUsed:     snapshot.QuotaUsed,
+		ExpiresAt:     snapshot.ExpiresAt,
+		RateLimit5h:   snapshot.RateLimit5h,
+		RateLimit1d:   snapshot.RateLimit1d,
+		RateLimit7d:   snapshot.RateLimit7d,
+		RPMLimit:      snapshot.RPMLimit,
+		TPMLimit:      snapshot.TPMLimit,
+		Critical:      snapshot.Critical,
+		Guest:         snapshot.Guest,
+		AllowedModels: snapshot.AllowedModels,
+		TokenLimit:    snapshot.TokenLimit,
+		TokensUsed:    snapshot.TokensUsed,
+		ProjectID:     snapshot.ProjectID,
 		User: &User{
 			ID:                         snapshot.User.ID,
 			Status:                     snapshot.User.Status,
@@ -328,6 +365,14 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			UserGroupRPMOverride:       snapshot.User.UserGroupRPMOverride,
 		},
 	}
+	if snapshot.Project != nil {
+		apiKey.Project = &APIKeyProject{
+			ID:        snapshot.Project.ID,
+			UserID:    snapshot.UserID,
+			Quota:     snapshot.Project.Quota,
+			QuotaUsed: snapshot.Project.QuotaUsed,
+		}
+	}
 	if snapshot.Group != nil {
 		apiKey.Group = &Group{
 			ID:                              snapshot.Group.ID,
@@ -369,6 +414,20 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			PeakStart:                       snapshot.Group.PeakStart,
 			PeakEnd:                         snapshot.Group.PeakEnd,
 			PeakRateMultiplier:              snapshot.Group.PeakRateMultiplier,
+			RequestSurcharge:                snapshot.Group.RequestSurcharge,
+			ReservedConcurrencyRatio:        snapshot.Group.ReservedConcurrencyRatio,
+			OverloadFallbackModels:          snapshot.Group.OverloadFallbackModels,
+			ContextOverflowModels:           snapshot.Group.ContextOverflowModels,
+			ContextOverflowReject:           snapshot.Group.ContextOverflowReject,
+			ContextTruncationStrategy:       snapshot.Group.ContextTruncationStrategy,
+			AccountSelectionStrategy:        snapshot.Group.AccountSelectionStrategy,
+			ImageMaxEdge:                    snapshot.Group.ImageMaxEdge,
+			ImageMaxBytes:                   snapshot.Group.ImageMaxBytes,
+			OutputPostprocess:               snapshot.Group.OutputPostprocess,
+			LanguageRouting:                 snapshot.Group.LanguageRouting,
+			StreamReplay:                    snapshot.Group.StreamReplay,
+			CapabilityCheck:                 snapshot.Group.CapabilityCheck,
+			AutoContinuation:                snapshot.Group.AutoContinuation,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
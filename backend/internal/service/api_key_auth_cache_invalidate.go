@@ -35,6 +35,18 @@ func (s *APIKeyService) InvalidateAuthCacheByGroupID(ctx context.Context, groupI
 	s.deleteAuthCacheByKeys(ctx, keys)
 }
 
+// InvalidateAuthCacheByProjectID 清除项目成员 Key 的认证缓存
+func (s *APIKeyService) InvalidateAuthCacheByProjectID(ctx context.Context, projectID int64) {
+	if projectID <= 0 || s.projectRepo == nil {
+		return
+	}
+	keys, err := s.projectRepo.ListKeys(ctx, projectID)
+	if err != nil {
+		return
+	}
+	s.deleteAuthCacheByKeys(ctx, keys)
+}
+
 func (s *APIKeyService) deleteAuthCacheByKeys(ctx context.Context, keys []string) {
 	if len(keys) == 0 {
 		return
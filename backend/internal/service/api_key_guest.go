@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	// GuestAPIKeyMaxTTL 访客 Key 最长有效期，避免演示 Key 被当作长期 Key 使用。
+	GuestAPIKeyMaxTTL = 72 * time.Hour
+
+	guestAPIKeyMaxAllowedModels = 50
+	guestAPIKeyMaxModelLen      = 100
+	guestAPIKeyCleanupBatchSize = 200
+)
+
+var (
+	ErrGuestAPIKeyInvalidTTL    = infraerrors.BadRequest("GUEST_API_KEY_INVALID_TTL", "guest api key ttl must be between 1 minute and 72 hours")
+	ErrGuestAPIKeyInvalidModels = infraerrors.BadRequest("GUEST_API_KEY_INVALID_MODELS", "allowed_models must contain at most 50 non-empty model names")
+	ErrGuestAPIKeyInvalidLimit  = infraerrors.BadRequest("GUEST_API_KEY_INVALID_LIMIT", "token_limit and quota must not be negative")
+)
+
+// CreateGuestAPIKeyRequest 创建临时访客 Key 请求
+type CreateGuestAPIKeyRequest struct {
+	Name          string
+	GroupID       *int64
+	TTL           time.Duration // 有效期，(0, GuestAPIKeyMaxTTL]
+	TokenLimit    int64         // Token 总量上限（0 = 不限制）
+	Quota         float64       // 额度上限 USD（0 = 不限制）
+	AllowedModels []string      // 允许的模型，支持尾部 * 通配（空 = 不限制）
+}
+
+// guestAPIKeyExpirySweeper 可选仓储能力：批量软删除已过期的访客 Key，返回被删除 Key 的原始值。
+type guestAPIKeyExpirySweeper interface {
+	DeleteExpiredGuestKeys(ctx context.Context, now time.Time, limit int) ([]string, error)
+}
+
+// CreateGuest 创建短期访客 Key：到期后不可用并由 GuestAPIKeyCleanupService 删除。
+// 分组权限、额度等沿用普通 Key 的校验。
+func (s *APIKeyService) CreateGuest(ctx context.Context, userID int64, req CreateGuestAPIKeyRequest) (*APIKey, error) {
+	if req.TTL < time.Minute || req.TTL > GuestAPIKeyMaxTTL {
+		return nil, ErrGuestAPIKeyInvalidTTL
+	}
+	if req.TokenLimit < 0 || req.Quota < 0 {
+		return nil, ErrGuestAPIKeyInvalidLimit
+	}
+	models, err := normalizeGuestAllowedModels(req.AllowedModels)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "guest-" + time.Now().Format("20060102-1504")
+	}
+
+	expiresAt := time.Now().Add(req.TTL)
+	return s.create(ctx, userID, CreateAPIKeyRequest{
+		Name:    name,
+		GroupID: req.GroupID,
+		Quota:   req.Quota,
+	}, func(apiKey *APIKey) {
+		apiKey.Guest = true
+		apiKey.ExpiresAt = &expiresAt
+		apiKey.TokenLimit = req.TokenLimit
+		apiKey.AllowedModels = models
+	})
+}
+
+func normalizeGuestAllowedModels(models []string) ([]string, error) {
+	if len(models) > guestAPIKeyMaxAllowedModels {
+		return nil, ErrGuestAPIKeyInvalidModels
+	}
+	out := make([]string, 0, len(models))
+	seen := make(map[string]struct{}, len(models))
+	for _, model := range models {
+		model = strings.TrimSpace(model)
+		if model == "" || len(model) > guestAPIKeyMaxModelLen {
+			return nil, ErrGuestAPIKeyInvalidModels
+		}
+		key := strings.ToLower(model)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, model)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// DeleteExpiredGuestKeys 分批删除已过期的访客 Key 并失效其认证缓存，返回删除数量。
+func (s *APIKeyService) DeleteExpiredGuestKeys(ctx context.Context, now time.Time) (int, error) {
+	sweeper, ok := s.apiKeyRepo.(guestAPIKeyExpirySweeper)
+	if !ok {
+		return 0, nil
+	}
+	total := 0
+	for {
+		keys, err := sweeper.DeleteExpiredGuestKeys(ctx, now, guestAPIKeyCleanupBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("delete expired guest api keys: %w", err)
+		}
+		for _, key := range keys {
+			s.InvalidateAuthCacheByKey(ctx, key)
+		}
+		total += len(keys)
+		if len(keys) < guestAPIKeyCleanupBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// GuestAPIKeyCleanupService 周期删除已过期的临时访客 Key。
+type GuestAPIKeyCleanupService struct {
+	apiKeyService *APIKeyService
+	interval      time.Duration
+	stopCh        chan struct{}
+	stopOnce      sync.Once
+	wg            sync.WaitGroup
+}
+
+func NewGuestAPIKeyCleanupService(apiKeyService *APIKeyService, interval time.Duration) *GuestAPIKeyCleanupService {
+	return &GuestAPIKeyCleanupService{apiKeyService: apiKeyService, interval: interval, stopCh: make(chan struct{})}
+}
+
+func (s *GuestAPIKeyCleanupService) Start() {
+	if s == nil || s.apiKeyService == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *GuestAPIKeyCleanupService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	s.wg.Wait()
+}
+
+func (s *GuestAPIKeyCleanupService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	deleted, err := s.apiKeyService.DeleteExpiredGuestKeys(ctx, time.Now())
+	if err != nil {
+		log.Printf("[GuestAPIKeyCleanup] delete expired guest keys failed: %v", err)
+	}
+	if deleted > 0 {
+		log.Printf("[GuestAPIKeyCleanup] deleted %d expired guest keys", deleted)
+	}
+}
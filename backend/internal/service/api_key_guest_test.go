@@ -0,0 +1,114 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type guestAPIKeyRepoStub struct {
+	quotaBaseAPIKeyRepoStub
+	created    []*APIKey
+	sweepCalls int
+	sweepPages [][]string
+}
+
+func (s *guestAPIKeyRepoStub) Create(_ context.Context, key *APIKey) error {
+	key.ID = int64(len(s.created) + 1)
+	s.created = append(s.created, key)
+	return nil
+}
+
+func (s *guestAPIKeyRepoStub) DeleteExpiredGuestKeys(_ context.Context, _ time.Time, _ int) ([]string, error) {
+	s.sweepCalls++
+	if len(s.sweepPages) == 0 {
+		return nil, nil
+	}
+	page := s.sweepPages[0]
+	s.sweepPages = s.sweepPages[1:]
+	return page, nil
+}
+
+func TestAPIKeyService_CreateGuest(t *testing.T) {
+	repo := &guestAPIKeyRepoStub{}
+	svc := &APIKeyService{
+		apiKeyRepo: repo,
+		userRepo:   &userRepoStub{user: &User{ID: 7}},
+		cfg:        &config.Config{},
+	}
+
+	key, err := svc.CreateGuest(context.Background(), 7, CreateGuestAPIKeyRequest{
+		TTL:           90 * time.Minute,
+		TokenLimit:    50000,
+		AllowedModels: []string{" claude-sonnet-* ", "gpt-4o", "GPT-4O"},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, repo.created, 1)
+	require.True(t, key.Guest)
+	require.Equal(t, int64(50000), key.TokenLimit)
+	require.Equal(t, []string{"claude-sonnet-*", "gpt-4o"}, key.AllowedModels)
+	require.NotEmpty(t, key.Name)
+	require.NotNil(t, key.ExpiresAt)
+	require.WithinDuration(t, time.Now().Add(90*time.Minute), *key.ExpiresAt, 5*time.Second)
+
+	_, err = svc.CreateGuest(context.Background(), 7, CreateGuestAPIKeyRequest{TTL: GuestAPIKeyMaxTTL + time.Minute})
+	require.ErrorIs(t, err, ErrGuestAPIKeyInvalidTTL)
+	_, err = svc.CreateGuest(context.Background(), 7, CreateGuestAPIKeyRequest{TTL: time.Hour, AllowedModels: []string{" "}})
+	require.ErrorIs(t, err, ErrGuestAPIKeyInvalidModels)
+	_, err = svc.CreateGuest(context.Background(), 7, CreateGuestAPIKeyRequest{TTL: time.Hour, TokenLimit: -1})
+	require.ErrorIs(t, err, ErrGuestAPIKeyInvalidLimit)
+	require.Len(t, repo.created, 1)
+}
+
+func TestAPIKeyService_DeleteExpiredGuestKeys_PagesAndInvalidatesAuthCache(t *testing.T) {
+	fullPage := make([]string, guestAPIKeyCleanupBatchSize)
+	for i := range fullPage {
+		fullPage[i] = "sk-guest-full"
+	}
+	repo := &guestAPIKeyRepoStub{sweepPages: [][]string{fullPage, {"sk-guest-last"}}}
+	cache := &quotaStateCacheStub{}
+	svc := &APIKeyService{apiKeyRepo: repo, cache: cache}
+
+	deleted, err := svc.DeleteExpiredGuestKeys(context.Background(), time.Now())
+
+	require.NoError(t, err)
+	require.Equal(t, guestAPIKeyCleanupBatchSize+1, deleted)
+	require.Equal(t, 2, repo.sweepCalls)
+	require.Contains(t, cache.deleteAuthKeys, svc.authCacheKey("sk-guest-last"))
+}
+
+func TestAPIKey_ModelScopeAndTokenLimit(t *testing.T) {
+	key := &APIKey{AllowedModels: []string{"claude-sonnet-*", "gpt-4o"}}
+	require.True(t, key.IsModelAllowed("claude-sonnet-4-5"))
+	require.True(t, key.IsModelAllowed("GPT-4o"))
+	require.False(t, key.IsModelAllowed("gpt-4o-mini"))
+	require.False(t, key.IsModelAllowed(""))
+	require.True(t, (&APIKey{}).IsModelAllowed("anything"))
+
+	require.False(t, (&APIKey{TokensUsed: 100}).IsTokenLimitExhausted(), "0 = unlimited")
+	require.False(t, (&APIKey{TokenLimit: 100, TokensUsed: 99}).IsTokenLimitExhausted())
+	require.True(t, (&APIKey{TokenLimit: 100, TokensUsed: 100}).IsTokenLimitExhausted())
+}
+
+func TestBuildUsageBillingCommand_CountsTokensOnlyForTokenLimitedKeys(t *testing.T) {
+	usageLog := &UsageLog{InputTokens: 100, OutputTokens: 50, CacheCreationTokens: 20, CacheReadTokens: 5}
+	p := &postUsageBillingParams{
+		Cost:    &CostBreakdown{TotalCost: 1, ActualCost: 1},
+		User:    &User{ID: 1},
+		APIKey:  &APIKey{ID: 2, TokenLimit: 1000},
+		Account: &Account{ID: 3},
+	}
+
+	cmd := buildUsageBillingCommand("req-1", usageLog, p)
+	require.Equal(t, int64(175), cmd.APIKeyTokens)
+
+	p.APIKey = &APIKey{ID: 2}
+	cmd = buildUsageBillingCommand("req-2", usageLog, p)
+	require.Zero(t, cmd.APIKeyTokens)
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	apiKeyProjectMaxPerUser        = 50
+	apiKeyProjectMaxNameLen        = 100
+	apiKeyProjectMaxDescriptionLen = 500
+	apiKeyProjectMaxUsageRange     = 366 * 24 * time.Hour
+)
+
+var (
+	ErrAPIKeyProjectNotFound           = infraerrors.NotFound("API_KEY_PROJECT_NOT_FOUND", "api key project not found")
+	ErrAPIKeyProjectExists             = infraerrors.Conflict("API_KEY_PROJECT_EXISTS", "api key project with this name already exists")
+	ErrAPIKeyProjectInvalidName        = infraerrors.BadRequest("API_KEY_PROJECT_INVALID_NAME", fmt.Sprintf("name must be 1-%d characters", apiKeyProjectMaxNameLen))
+	ErrAPIKeyProjectInvalidQuota       = infraerrors.BadRequest("API_KEY_PROJECT_INVALID_QUOTA", "quota must not be negative")
+	ErrAPIKeyProjectInvalidRange       = infraerrors.BadRequest("API_KEY_PROJECT_INVALID_RANGE", "end must be after start and the range must not exceed 366 days")
+	ErrAPIKeyProjectTooMany            = infraerrors.Conflict("API_KEY_PROJECT_LIMIT_REACHED", fmt.Sprintf("at most %d api key projects per user", apiKeyProjectMaxPerUser))
+	ErrAPIKeyProjectNoKeys             = infraerrors.BadRequest("API_KEY_PROJECT_NO_KEYS", "api_key_ids must not be empty")
+	ErrAPIKeyProjectKeyNotOwned        = infraerrors.NotFound("API_KEY_PROJECT_KEY_NOT_FOUND", "one or more api keys not found")
+	ErrAPIKeyProjectInvalidDescription = infraerrors.BadRequest("API_KEY_PROJECT_INVALID_DESCRIPTION", fmt.Sprintf("description must be at most %d characters", apiKeyProjectMaxDescriptionLen))
+)
+
+// APIKeyProject 把同一用户的多个 Key 归为一个项目：成员 Key 的扣费同时累加到项目，
+// 共享项目额度（Quota = 0 表示只汇总不限制），用量报表按项目合并。
+type APIKeyProject struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Quota       float64   `json:"quota"`
+	QuotaUsed   float64   `json:"quota_used"`
+	KeyCount    int       `json:"key_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsQuotaExhausted 项目合计额度是否已用完。
+func (p *APIKeyProject) IsQuotaExhausted() bool {
+	if p == nil || p.Quota <= 0 {
+		return false
+	}
+	return p.QuotaUsed >= p.Quota
+}
+
+// APIKeyProjectInput 创建/更新项目的入参。
+type APIKeyProjectInput struct {
+	Name        string
+	Description string
+	Quota       float64
+}
+
+// APIKeyProjectKeyUsage 项目内单个 Key 在统计区间内的用量。
+type APIKeyProjectKeyUsage struct {
+	APIKeyID   int64   `json:"api_key_id"`
+	Name       string  `json:"name"`
+	Requests   int64   `json:"requests"`
+	Tokens     int64   `json:"tokens"`
+	TotalCost  float64 `json:"total_cost"`
+	ActualCost float64 `json:"actual_cost"`
+}
+
+// APIKeyProjectUsage 项目合并用量报表：合计 + 按 Key 拆分（仅统计当前成员 Key）。
+type APIKeyProjectUsage struct {
+	ProjectID  int64                    `json:"project_id"`
+	StartTime  time.Time                `json:"start_time"`
+	EndTime    time.Time                `json:"end_time"`
+	Requests   int64                    `json:"requests"`
+	Tokens     int64                    `json:"tokens"`
+	TotalCost  float64                  `json:"total_cost"`
+	ActualCost float64                  `json:"actual_cost"`
+	Quota      float64                  `json:"quota"`
+	QuotaUsed  float64                  `json:"quota_used"`
+	Keys       []*APIKeyProjectKeyUsage `json:"keys"`
+}
+
+// APIKeyProjectRepository 项目存储、成员关系维护与用量聚合。
+type APIKeyProjectRepository interface {
+	Create(ctx context.Context, project *APIKeyProject) (*APIKeyProject, error)
+	GetByID(ctx context.Context, id int64) (*APIKeyProject, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*APIKeyProject, error)
+	CountByUserID(ctx context.Context, userID int64) (int, error)
+	Update(ctx context.Context, project *APIKeyProject) (*APIKeyProject, error)
+	ResetQuotaUsed(ctx context.Context, id int64) (*APIKeyProject, error)
+	Delete(ctx context.Context, id int64) error
+	// ListKeys 返回项目成员 Key 的原始值（用于失效认证缓存）。
+	ListKeys(ctx context.Context, projectID int64) ([]string, error)
+	// AddKeys 把用户自己的指定 Key 移入项目，全部 ID 都属于该用户时才生效，否则返回 ErrAPIKeyProjectKeyNotOwned。
+	// 返回被变更 Key 的原始值。
+	AddKeys(ctx context.Context, userID, projectID int64, apiKeyIDs []int64) ([]string, error)
+	// RemoveKeys 把项目内的指定 Key 移出项目，不在项目内的 ID 忽略。
+	RemoveKeys(ctx context.Context, projectID int64, apiKeyIDs []int64) ([]string, error)
+	// SumUsageByKey 按成员 Key 汇总 [start, end) 内的用量。
+	SumUsageByKey(ctx context.Context, projectID int64, start, end time.Time) ([]*APIKeyProjectKeyUsage, error)
+}
+
+// APIKeyProjectService 管理用户的 Key 项目、成员关系与合并用量报表。
+type APIKeyProjectService struct {
+	repo          APIKeyProjectRepository
+	apiKeyService *APIKeyService
+}
+
+// NewAPIKeyProjectService 创建 Key 项目服务。
+func NewAPIKeyProjectService(repo APIKeyProjectRepository, apiKeyService *APIKeyService) *APIKeyProjectService {
+	return &APIKeyProjectService{repo: repo, apiKeyService: apiKeyService}
+}
+
+// List 返回用户的全部项目。
+func (s *APIKeyProjectService) List(ctx context.Context, userID int64) ([]*APIKeyProject, error) {
+	projects, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if projects == nil {
+		projects = []*APIKeyProject{}
+	}
+	return projects, nil
+}
+
+// Get 返回用户自己的项目。
+func (s *APIKeyProjectService) Get(ctx context.Context, userID, id int64) (*APIKeyProject, error) {
+	return s.getOwned(ctx, userID, id)
+}
+
+// Create 为用户新建项目。
+func (s *APIKeyProjectService) Create(ctx context.Context, userID int64, in APIKeyProjectInput) (*APIKeyProject, error) {
+	project := &APIKeyProject{UserID: userID}
+	if err := applyAPIKeyProjectInput(project, in); err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= apiKeyProjectMaxPerUser {
+		return nil, ErrAPIKeyProjectTooMany
+	}
+	return s.repo.Create(ctx, project)
+}
+
+// Update 更新项目名称、描述与额度。额度变化会影响成员 Key 的鉴权结果，因此失效成员 Key 的认证缓存。
+func (s *APIKeyProjectService) Update(ctx context.Context, userID, id int64, in APIKeyProjectInput) (*APIKeyProject, error) {
+	project, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	quotaChanged := project.Quota != in.Quota
+	if err := applyAPIKeyProjectInput(project, in); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	if quotaChanged {
+		s.invalidateProjectKeys(ctx, id)
+	}
+	return updated, nil
+}
+
+// ResetQuota 清零项目已用额度，使因项目额度用完而被拒绝的成员 Key 恢复可用。
+func (s *APIKeyProjectService) ResetQuota(ctx context.Context, userID, id int64) (*APIKeyProject, error) {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	project, err := s.repo.ResetQuotaUsed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateProjectKeys(ctx, id)
+	return project, nil
+}
+
+// Delete 删除项目；成员 Key 保留，仅解除归属（project_id 置空）。
+func (s *APIKeyProjectService) Delete(ctx context.Context, userID, id int64) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	// 先取成员，删除后 project_id 已被置空无法再查
+	keys, err := s.repo.ListKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateKeys(ctx, keys)
+	return nil
+}
+
+// AddKeys 把用户自己的 Key 加入项目（已在其他项目中的 Key 会被移过来）。
+func (s *APIKeyProjectService) AddKeys(ctx context.Context, userID, id int64, apiKeyIDs []int64) (*APIKeyProject, error) {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	ids := normalizeAPIKeyProjectKeyIDs(apiKeyIDs)
+	if len(ids) == 0 {
+		return nil, ErrAPIKeyProjectNoKeys
+	}
+	keys, err := s.repo.AddKeys(ctx, userID, id, ids)
+	if err != nil {
+		return nil, err
+	}
+	// 从其他项目移入的 Key 也需刷新快照中的项目额度
+	s.invalidateKeys(ctx, keys)
+	return s.repo.GetByID(ctx, id)
+}
+
+// RemoveKeys 把 Key 移出项目。
+func (s *APIKeyProjectService) RemoveKeys(ctx context.Context, userID, id int64, apiKeyIDs []int64) (*APIKeyProject, error) {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	ids := normalizeAPIKeyProjectKeyIDs(apiKeyIDs)
+	if len(ids) == 0 {
+		return nil, ErrAPIKeyProjectNoKeys
+	}
+	keys, err := s.repo.RemoveKeys(ctx, id, ids)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateKeys(ctx, keys)
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetUsage 返回项目在 [start, end) 内的合并用量及按 Key 拆分明细。
+func (s *APIKeyProjectService) GetUsage(ctx context.Context, userID, id int64, start, end time.Time) (*APIKeyProjectUsage, error) {
+	project, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !end.After(start) || end.Sub(start) > apiKeyProjectMaxUsageRange {
+		return nil, ErrAPIKeyProjectInvalidRange
+	}
+	keys, err := s.repo.SumUsageByKey(ctx, id, start, end)
+	if err != nil {
+		return nil, err
+	}
+	usage := &APIKeyProjectUsage{
+		ProjectID: id,
+		StartTime: start,
+		EndTime:   end,
+		Quota:     project.Quota,
+		QuotaUsed: project.QuotaUsed,
+		Keys:      keys,
+	}
+	if usage.Keys == nil {
+		usage.Keys = []*APIKeyProjectKeyUsage{}
+	}
+	for _, k := range usage.Keys {
+		usage.Requests += k.Requests
+		usage.Tokens += k.Tokens
+		usage.TotalCost += k.TotalCost
+		usage.ActualCost += k.ActualCost
+	}
+	return usage, nil
+}
+
+func normalizeAPIKeyProjectKeyIDs(ids []int64) []int64 {
+	out := make([]int64, 0, len(ids))
+	seen := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+func (s *APIKeyProjectService) getOwned(ctx context.Context, userID, id int64) (*APIKeyProject, error) {
+	project, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// 不区分“不存在”与“不属于自己”，避免探测他人项目 ID
+	if project == nil || project.UserID != userID {
+		return nil, ErrAPIKeyProjectNotFound
+	}
+	return project, nil
+}
+
+func (s *APIKeyProjectService) invalidateProjectKeys(ctx context.Context, projectID int64) {
+	if s.apiKeyService != nil {
+		s.apiKeyService.InvalidateAuthCacheByProjectID(ctx, projectID)
+	}
+}
+
+func (s *APIKeyProjectService) invalidateKeys(ctx context.Context, keys []string) {
+	if s.apiKeyService != nil {
+		s.apiKeyService.deleteAuthCacheByKeys(ctx, keys)
+	}
+}
+
+func applyAPIKeyProjectInput(project *APIKeyProject, in APIKeyProjectInput) error {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len([]rune(name)) > apiKeyProjectMaxNameLen {
+		return ErrAPIKeyProjectInvalidName
+	}
+	description := strings.TrimSpace(in.Description)
+	if len([]rune(description)) > apiKeyProjectMaxDescriptionLen {
+		return ErrAPIKeyProjectInvalidDescription
+	}
+	if in.Quota < 0 {
+		return ErrAPIKeyProjectInvalidQuota
+	}
+	project.Name = name
+	project.Description = description
+	project.Quota = in.Quota
+	return nil
+}
@@ -0,0 +1,235 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyProjectRepoStub struct {
+	projects map[int64]*APIKeyProject
+	members  map[int64][]string // projectID -> keys
+	owned    map[int64]string   // apiKeyID -> key, keys of the calling user
+	usage    []*APIKeyProjectKeyUsage
+	deleted  []int64
+}
+
+func newAPIKeyProjectRepoStub() *apiKeyProjectRepoStub {
+	return &apiKeyProjectRepoStub{projects: map[int64]*APIKeyProject{}, members: map[int64][]string{}, owned: map[int64]string{}}
+}
+
+func (s *apiKeyProjectRepoStub) Create(_ context.Context, p *APIKeyProject) (*APIKeyProject, error) {
+	cp := *p
+	cp.ID = int64(len(s.projects) + 1)
+	s.projects[cp.ID] = &cp
+	return &cp, nil
+}
+
+func (s *apiKeyProjectRepoStub) GetByID(_ context.Context, id int64) (*APIKeyProject, error) {
+	p, ok := s.projects[id]
+	if !ok {
+		return nil, ErrAPIKeyProjectNotFound
+	}
+	cp := *p
+	cp.KeyCount = len(s.members[id])
+	return &cp, nil
+}
+
+func (s *apiKeyProjectRepoStub) ListByUserID(_ context.Context, userID int64) ([]*APIKeyProject, error) {
+	var out []*APIKeyProject
+	for _, p := range s.projects {
+		if p.UserID == userID {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (s *apiKeyProjectRepoStub) CountByUserID(ctx context.Context, userID int64) (int, error) {
+	list, _ := s.ListByUserID(ctx, userID)
+	return len(list), nil
+}
+
+func (s *apiKeyProjectRepoStub) Update(_ context.Context, p *APIKeyProject) (*APIKeyProject, error) {
+	cp := *p
+	s.projects[p.ID] = &cp
+	return &cp, nil
+}
+
+func (s *apiKeyProjectRepoStub) ResetQuotaUsed(_ context.Context, id int64) (*APIKeyProject, error) {
+	s.projects[id].QuotaUsed = 0
+	cp := *s.projects[id]
+	return &cp, nil
+}
+
+func (s *apiKeyProjectRepoStub) Delete(_ context.Context, id int64) error {
+	delete(s.projects, id)
+	delete(s.members, id)
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *apiKeyProjectRepoStub) ListKeys(_ context.Context, projectID int64) ([]string, error) {
+	return s.members[projectID], nil
+}
+
+func (s *apiKeyProjectRepoStub) AddKeys(_ context.Context, _ int64, projectID int64, ids []int64) ([]string, error) {
+	var keys []string
+	for _, id := range ids {
+		key, ok := s.owned[id]
+		if !ok {
+			return nil, ErrAPIKeyProjectKeyNotOwned
+		}
+		keys = append(keys, key)
+	}
+	s.members[projectID] = append(s.members[projectID], keys...)
+	return keys, nil
+}
+
+func (s *apiKeyProjectRepoStub) RemoveKeys(_ context.Context, projectID int64, ids []int64) ([]string, error) {
+	var removed []string
+	for _, id := range ids {
+		key := s.owned[id]
+		remaining := s.members[projectID][:0]
+		for _, m := range s.members[projectID] {
+			if m == key {
+				removed = append(removed, m)
+				continue
+			}
+			remaining = append(remaining, m)
+		}
+		s.members[projectID] = remaining
+	}
+	return removed, nil
+}
+
+func (s *apiKeyProjectRepoStub) SumUsageByKey(context.Context, int64, time.Time, time.Time) ([]*APIKeyProjectKeyUsage, error) {
+	return s.usage, nil
+}
+
+func newAPIKeyProjectServiceForTest(repo *apiKeyProjectRepoStub) (*APIKeyProjectService, *APIKeyService, *quotaStateCacheStub) {
+	cache := &quotaStateCacheStub{}
+	apiKeySvc := &APIKeyService{cache: cache}
+	apiKeySvc.SetProjectRepository(repo)
+	return NewAPIKeyProjectService(repo, apiKeySvc), apiKeySvc, cache
+}
+
+func TestAPIKeyProjectService_CreateValidatesAndScopesToOwner(t *testing.T) {
+	repo := newAPIKeyProjectRepoStub()
+	svc, _, _ := newAPIKeyProjectServiceForTest(repo)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, 7, APIKeyProjectInput{Name: "  "})
+	require.ErrorIs(t, err, ErrAPIKeyProjectInvalidName)
+	_, err = svc.Create(ctx, 7, APIKeyProjectInput{Name: "svc", Quota: -1})
+	require.ErrorIs(t, err, ErrAPIKeyProjectInvalidQuota)
+
+	project, err := svc.Create(ctx, 7, APIKeyProjectInput{Name: " backend ", Quota: 50})
+	require.NoError(t, err)
+	require.Equal(t, "backend", project.Name)
+	require.Equal(t, int64(7), project.UserID)
+
+	_, err = svc.Get(ctx, 8, project.ID)
+	require.ErrorIs(t, err, ErrAPIKeyProjectNotFound, "other users must not see the project")
+	require.ErrorIs(t, svc.Delete(ctx, 8, project.ID), ErrAPIKeyProjectNotFound)
+	require.Empty(t, repo.deleted)
+}
+
+func TestAPIKeyProjectService_MembershipChangesInvalidateAuthCache(t *testing.T) {
+	repo := newAPIKeyProjectRepoStub()
+	repo.owned[11] = "sk-web"
+	repo.owned[12] = "sk-worker"
+	svc, apiKeySvc, cache := newAPIKeyProjectServiceForTest(repo)
+	ctx := context.Background()
+	project, err := svc.Create(ctx, 7, APIKeyProjectInput{Name: "backend", Quota: 50})
+	require.NoError(t, err)
+
+	_, err = svc.AddKeys(ctx, 7, project.ID, []int64{0, -1})
+	require.ErrorIs(t, err, ErrAPIKeyProjectNoKeys)
+	_, err = svc.AddKeys(ctx, 7, project.ID, []int64{11, 99})
+	require.ErrorIs(t, err, ErrAPIKeyProjectKeyNotOwned)
+
+	updated, err := svc.AddKeys(ctx, 7, project.ID, []int64{11, 12, 11})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.KeyCount)
+	require.Contains(t, cache.deleteAuthKeys, apiKeySvc.authCacheKey("sk-web"))
+	require.Contains(t, cache.deleteAuthKeys, apiKeySvc.authCacheKey("sk-worker"))
+
+	cache.deleteAuthKeys = nil
+	_, err = svc.Update(ctx, 7, project.ID, APIKeyProjectInput{Name: "backend", Quota: 80})
+	require.NoError(t, err)
+	require.Len(t, cache.deleteAuthKeys, 2, "quota change refreshes all member snapshots")
+
+	cache.deleteAuthKeys = nil
+	updated, err = svc.RemoveKeys(ctx, 7, project.ID, []int64{12})
+	require.NoError(t, err)
+	require.Equal(t, 1, updated.KeyCount)
+	require.Equal(t, []string{apiKeySvc.authCacheKey("sk-worker")}, cache.deleteAuthKeys)
+
+	cache.deleteAuthKeys = nil
+	require.NoError(t, svc.Delete(ctx, 7, project.ID))
+	require.Equal(t, []string{apiKeySvc.authCacheKey("sk-web")}, cache.deleteAuthKeys)
+}
+
+func TestAPIKeyProjectService_GetUsageRollsUpKeys(t *testing.T) {
+	repo := newAPIKeyProjectRepoStub()
+	repo.usage = []*APIKeyProjectKeyUsage{
+		{APIKeyID: 11, Name: "web", Requests: 3, Tokens: 900, TotalCost: 0.3, ActualCost: 0.45},
+		{APIKeyID: 12, Name: "worker", Requests: 1, Tokens: 100, TotalCost: 0.1, ActualCost: 0.15},
+	}
+	svc, _, _ := newAPIKeyProjectServiceForTest(repo)
+	ctx := context.Background()
+	project, err := svc.Create(ctx, 7, APIKeyProjectInput{Name: "backend", Quota: 50})
+	require.NoError(t, err)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err = svc.GetUsage(ctx, 7, project.ID, start, start)
+	require.ErrorIs(t, err, ErrAPIKeyProjectInvalidRange)
+
+	usage, err := svc.GetUsage(ctx, 7, project.ID, start, start.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	require.Equal(t, int64(4), usage.Requests)
+	require.Equal(t, int64(1000), usage.Tokens)
+	require.InDelta(t, 0.6, usage.ActualCost, 1e-9)
+	require.Equal(t, 50.0, usage.Quota)
+	require.Len(t, usage.Keys, 2)
+}
+
+func TestAPIKeyProject_SnapshotCarriesProjectQuota(t *testing.T) {
+	repo := newAPIKeyProjectRepoStub()
+	repo.projects[3] = &APIKeyProject{ID: 3, UserID: 7, Quota: 10, QuotaUsed: 10}
+	svc := &APIKeyService{}
+	svc.SetProjectRepository(repo)
+	projectID := int64(3)
+
+	snapshot := svc.snapshotFromAPIKey(context.Background(), &APIKey{ID: 1, UserID: 7, ProjectID: &projectID, User: &User{ID: 7}})
+	require.NotNil(t, snapshot.Project)
+
+	key := svc.snapshotToAPIKey("sk-web", snapshot)
+	require.Equal(t, &projectID, key.ProjectID)
+	require.True(t, key.IsProjectQuotaExhausted())
+	require.False(t, (&APIKey{Project: &APIKeyProject{QuotaUsed: 10}}).IsProjectQuotaExhausted(), "0 = unlimited")
+}
+
+func TestBuildUsageBillingCommand_ChargesProjectForMemberKeys(t *testing.T) {
+	projectID := int64(3)
+	p := &postUsageBillingParams{
+		Cost:    &CostBreakdown{TotalCost: 1, ActualCost: 1.5},
+		User:    &User{ID: 1},
+		APIKey:  &APIKey{ID: 2, ProjectID: &projectID},
+		Account: &Account{ID: 3},
+	}
+
+	cmd := buildUsageBillingCommand("req-1", nil, p)
+	require.Equal(t, projectID, cmd.APIKeyProjectID)
+	require.Equal(t, 1.5, cmd.APIKeyProjectCost)
+
+	p.APIKey = &APIKey{ID: 2}
+	cmd = buildUsageBillingCommand("req-2", nil, p)
+	require.Zero(t, cmd.APIKeyProjectID)
+	require.Zero(t, cmd.APIKeyProjectCost)
+}
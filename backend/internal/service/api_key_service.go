@@ -38,6 +38,9 @@ var (
 	ErrAPIKeyRateLimit5hExceeded = infraerrors.TooManyRequests("API_KEY_RATE_5H_EXCEEDED", "api key 5小时限额已用完")
 	ErrAPIKeyRateLimit1dExceeded = infraerrors.TooManyRequests("API_KEY_RATE_1D_EXCEEDED", "api key 日限额已用完")
 	ErrAPIKeyRateLimit7dExceeded = infraerrors.TooManyRequests("API_KEY_RATE_7D_EXCEEDED", "api key 7天限额已用完")
+
+	// Throughput limit errors
+	ErrAPIKeyInvalidThroughputLimit = infraerrors.BadRequest("INVALID_API_KEY_THROUGHPUT_LIMIT", "rpm_limit and tpm_limit must be >= 0")
 )
 
 const (
@@ -172,6 +175,10 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Throughput limit fields (0 = unlimited)
+	RPMLimit int   `json:"rpm_limit"`
+	TPMLimit int64 `json:"tpm_limit"`
 }
 
 // UpdateAPIKeyRequest 更新API Key请求
@@ -193,6 +200,10 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // Reset all usage counters to 0
+
+	// Throughput limit fields (nil = no change, 0 = unlimited)
+	RPMLimit *int   `json:"rpm_limit"`
+	TPMLimit *int64 `json:"tpm_limit"`
 }
 
 // APIKeyService API Key服务
@@ -210,12 +221,14 @@ type APIKeyService struct {
 	cache                 APIKeyCache
 	rateLimitCacheInvalid RateLimitCacheInvalidator // optional: invalidate Redis rate limit cache
 	concurrencyService    *ConcurrencyService
+	projectRepo           APIKeyProjectRepository // optional: load project quota into auth snapshot
 	cfg                   *config.Config
 	authCacheL1           *ristretto.Cache
 	authCfg               apiKeyAuthCacheConfig
 	authGroup             singleflight.Group
 	lastUsedTouchL1       sync.Map // keyID -> nextAllowedAt(time.Time)
 	lastUsedTouchSF       singleflight.Group
+	localBuckets          apiKeyLocalTokenBuckets // RPM/TPM 令牌桶的本地回退（无 Redis 或 Redis 异常时使用）
 }
 
 // NewAPIKeyService 创建API Key服务实例
@@ -251,6 +264,11 @@ func (s *APIKeyService) SetConcurrencyService(concurrencyService *ConcurrencySer
 	s.concurrencyService = concurrencyService
 }
 
+// SetProjectRepository 注入 Key 项目仓储，用于在认证快照中带上项目合计额度。
+func (s *APIKeyService) SetProjectRepository(projectRepo APIKeyProjectRepository) {
+	s.projectRepo = projectRepo
+}
+
 func (s *APIKeyService) compileAPIKeyIPRules(apiKey *APIKey) {
 	if apiKey == nil {
 		return
@@ -341,6 +359,11 @@ func (s *APIKeyService) canUserBindGroup(ctx context.Context, user *User, group
 
 // Create 创建API Key
 func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIKeyRequest) (*APIKey, error) {
+	return s.create(ctx, userID, req, nil)
+}
+
+// create 校验并持久化新 Key；customize 在落库前调整记录（如访客 Key 的过期时间与作用域）。
+func (s *APIKeyService) create(ctx context.Context, userID int64, req CreateAPIKeyRequest, customize func(*APIKey)) (*APIKey, error) {
 	// 验证用户存在
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -360,6 +383,9 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 			return nil, fmt.Errorf("%w: %v", ErrInvalidIPPattern, invalid)
 		}
 	}
+	if req.RPMLimit < 0 || req.TPMLimit < 0 {
+		return nil, ErrAPIKeyInvalidThroughputLimit
+	}
 
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
@@ -423,6 +449,8 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		RateLimit5h: req.RateLimit5h,
 		RateLimit1d: req.RateLimit1d,
 		RateLimit7d: req.RateLimit7d,
+		RPMLimit:    req.RPMLimit,
+		TPMLimit:    req.TPMLimit,
 	}
 
 	// Set expiration time if specified
@@ -430,6 +458,9 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
 		apiKey.ExpiresAt = &expiresAt
 	}
+	if customize != nil {
+		customize(apiKey)
+	}
 
 	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
 		return nil, fmt.Errorf("create api key: %w", err)
@@ -732,6 +763,18 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	if req.RateLimit7d != nil {
 		apiKey.RateLimit7d = *req.RateLimit7d
 	}
+	if req.RPMLimit != nil {
+		if *req.RPMLimit < 0 {
+			return nil, ErrAPIKeyInvalidThroughputLimit
+		}
+		apiKey.RPMLimit = *req.RPMLimit
+	}
+	if req.TPMLimit != nil {
+		if *req.TPMLimit < 0 {
+			return nil, ErrAPIKeyInvalidThroughputLimit
+		}
+		apiKey.TPMLimit = *req.TPMLimit
+	}
 	resetRateLimit := req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage
 	if resetRateLimit {
 		apiKey.Usage5h = 0
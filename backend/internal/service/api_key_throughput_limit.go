@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// API Key 吞吐限制的令牌桶类型。
+const (
+	APIKeyBucketRequests = "rpm" // 每分钟请求数
+	APIKeyBucketTokens   = "tpm" // 每分钟 token 数
+)
+
+// apiKeyLocalBucketMaxTracked 本地令牌桶的条目上限，超出时清空重建（相当于放宽一次限制）。
+const apiKeyLocalBucketMaxTracked = 100000
+
+var (
+	ErrAPIKeyRPMExceeded = infraerrors.TooManyRequests("API_KEY_RPM_EXCEEDED", "api key requests-per-minute limit exceeded")
+	ErrAPIKeyTPMExceeded = infraerrors.TooManyRequests("API_KEY_TPM_EXCEEDED", "api key tokens-per-minute limit exceeded")
+)
+
+// APIKeyBucketResult 一次令牌桶操作的结果。
+type APIKeyBucketResult struct {
+	Allowed    bool
+	Remaining  int64         // 操作后的剩余令牌（不小于 0）
+	RetryAfter time.Duration // 未放行时，令牌补足到 need 所需时长
+	ResetAfter time.Duration // 令牌桶回满所需时长
+}
+
+// APIKeyTokenBucketCache 跨实例共享的 API Key 令牌桶；APIKeyCache 未实现该接口时仅使用本地令牌桶。
+type APIKeyTokenBucketCache interface {
+	// TakeAPIKeyTokens 先按 limit/分钟 的速率补充令牌（容量为 limit），剩余令牌 >= need 时扣除 cost 并放行。
+	// need <= 0 表示无条件扣除（事后记账），cost 可使余额为负，后续请求需等待补足。
+	TakeAPIKeyTokens(ctx context.Context, apiKeyID int64, bucket string, limit, cost, need int64) (APIKeyBucketResult, error)
+}
+
+// APIKeyThroughputStatus 本次请求的 RPM/TPM 限制状态，用于输出 x-ratelimit-* 响应头；limit 为 0 表示未配置。
+type APIKeyThroughputStatus struct {
+	RequestLimit     int64
+	RequestRemaining int64
+	RequestReset     time.Duration
+	TokenLimit       int64
+	TokenRemaining   int64
+	TokenReset       time.Duration
+	// RetryAfter 超限时建议的重试等待时长
+	RetryAfter time.Duration
+}
+
+// CheckThroughputLimits 校验 API Key 的 RPM/TPM 限制，放行时消耗一个请求令牌。
+// 先检查 TPM（只要求余额为正，不扣除），再扣除 RPM，避免被 TPM 拒绝的请求占用请求额度。
+// 未配置限制时返回 nil, nil；超限时返回状态与 ErrAPIKeyRPMExceeded / ErrAPIKeyTPMExceeded。
+func (s *APIKeyService) CheckThroughputLimits(ctx context.Context, apiKey *APIKey) (*APIKeyThroughputStatus, error) {
+	if s == nil || apiKey == nil || !apiKey.HasThroughputLimits() {
+		return nil, nil
+	}
+	status := &APIKeyThroughputStatus{RequestLimit: int64(apiKey.RPMLimit), TokenLimit: apiKey.TPMLimit}
+	if apiKey.TPMLimit > 0 {
+		res := s.takeAPIKeyTokens(ctx, apiKey.ID, APIKeyBucketTokens, apiKey.TPMLimit, 0, 1)
+		status.TokenRemaining, status.TokenReset = res.Remaining, res.ResetAfter
+		if !res.Allowed {
+			status.RetryAfter = res.RetryAfter
+			return status, ErrAPIKeyTPMExceeded
+		}
+	}
+	if apiKey.RPMLimit > 0 {
+		res := s.takeAPIKeyTokens(ctx, apiKey.ID, APIKeyBucketRequests, int64(apiKey.RPMLimit), 1, 1)
+		status.RequestRemaining, status.RequestReset = res.Remaining, res.ResetAfter
+		if !res.Allowed {
+			status.RetryAfter = res.RetryAfter
+			return status, ErrAPIKeyRPMExceeded
+		}
+	}
+	return status, nil
+}
+
+// RecordThroughputTokens 在请求完成后按实际用量扣除 TPM 令牌；请求开始时无法预知 token 数，故事后记账。
+func (s *APIKeyService) RecordThroughputTokens(ctx context.Context, apiKey *APIKey, tokens int64) {
+	if s == nil || apiKey == nil || apiKey.TPMLimit <= 0 || tokens <= 0 {
+		return
+	}
+	s.takeAPIKeyTokens(ctx, apiKey.ID, APIKeyBucketTokens, apiKey.TPMLimit, tokens, 0)
+}
+
+// takeAPIKeyTokens 优先使用 Redis 令牌桶；未配置或 Redis 异常时回退到本实例内存令牌桶。
+func (s *APIKeyService) takeAPIKeyTokens(ctx context.Context, apiKeyID int64, bucket string, limit, cost, need int64) APIKeyBucketResult {
+	if cache, ok := s.cache.(APIKeyTokenBucketCache); ok {
+		res, err := cache.TakeAPIKeyTokens(ctx, apiKeyID, bucket, limit, cost, need)
+		if err == nil {
+			return res
+		}
+		logger.LegacyPrintf("service.api_key", "Warning: api key %d %s bucket fallback to local: %v", apiKeyID, bucket, err)
+	}
+	return s.localBuckets.take(apiKeyID, bucket, limit, cost, need, time.Now())
+}
+
+type apiKeyBucketKey struct {
+	apiKeyID int64
+	bucket   string
+}
+
+type apiKeyBucketState struct {
+	tokens  float64
+	updated time.Time
+}
+
+// apiKeyLocalTokenBuckets 本实例内存中的 API Key 令牌桶；零值可用。多实例部署时各实例独立计数。
+type apiKeyLocalTokenBuckets struct {
+	mu      sync.Mutex
+	buckets map[apiKeyBucketKey]*apiKeyBucketState
+}
+
+func (b *apiKeyLocalTokenBuckets) take(apiKeyID int64, bucket string, limit, cost, need int64, now time.Time) APIKeyBucketResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buckets == nil || len(b.buckets) >= apiKeyLocalBucketMaxTracked {
+		b.buckets = make(map[apiKeyBucketKey]*apiKeyBucketState)
+	}
+	key := apiKeyBucketKey{apiKeyID: apiKeyID, bucket: bucket}
+	state := b.buckets[key]
+	if state == nil {
+		state = &apiKeyBucketState{tokens: float64(limit), updated: now}
+		b.buckets[key] = state
+	}
+	elapsedMs := float64(now.Sub(state.updated).Milliseconds())
+	state.updated = now
+	res, tokens := takeAPIKeyBucket(state.tokens, elapsedMs, limit, cost, need)
+	state.tokens = tokens
+	return res
+}
+
+// takeAPIKeyBucket 令牌桶计算（与 Redis Lua 脚本一致）：按 limit/60000 每毫秒补充，容量为 limit。
+func takeAPIKeyBucket(tokens, elapsedMs float64, limit, cost, need int64) (APIKeyBucketResult, float64) {
+	capacity := float64(limit)
+	ratePerMs := capacity / float64(time.Minute.Milliseconds())
+	if elapsedMs > 0 {
+		tokens = math.Min(capacity, tokens+elapsedMs*ratePerMs)
+	}
+	tokens = math.Min(tokens, capacity)
+
+	res := APIKeyBucketResult{Allowed: need <= 0 || tokens >= float64(need)}
+	if res.Allowed {
+		tokens -= float64(cost)
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((float64(need)-tokens)/ratePerMs)) * time.Millisecond
+	}
+	res.Remaining = int64(math.Floor(math.Max(tokens, 0)))
+	res.ResetAfter = time.Duration(math.Ceil((capacity-tokens)/ratePerMs)) * time.Millisecond
+	return res, tokens
+}
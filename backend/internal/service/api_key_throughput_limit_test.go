@@ -0,0 +1,85 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeAPIKeyBucket_RefillAndDebt(t *testing.T) {
+	// 满桶放行并扣除
+	res, tokens := takeAPIKeyBucket(60, 0, 60, 1, 1)
+	require.True(t, res.Allowed)
+	require.Equal(t, int64(59), res.Remaining)
+	require.Equal(t, time.Second, res.ResetAfter)
+
+	// 事后记账允许透支，透支后要求 need=1 的检查被拒绝
+	res, tokens = takeAPIKeyBucket(tokens, 0, 60, 100, 0)
+	require.True(t, res.Allowed)
+	require.Equal(t, int64(0), res.Remaining)
+	require.InDelta(t, -41, tokens, 1e-9)
+
+	res, tokens = takeAPIKeyBucket(tokens, 0, 60, 0, 1)
+	require.False(t, res.Allowed)
+	require.Equal(t, 42*time.Second, res.RetryAfter)
+
+	// 按 limit/分钟 补充，且不超过容量
+	res, _ = takeAPIKeyBucket(tokens, float64((42 * time.Second).Milliseconds()), 60, 0, 1)
+	require.True(t, res.Allowed)
+	res, _ = takeAPIKeyBucket(tokens, float64((time.Hour).Milliseconds()), 60, 0, 1)
+	require.Equal(t, int64(60), res.Remaining)
+}
+
+func TestAPIKeyService_CheckThroughputLimits_RPM(t *testing.T) {
+	svc := &APIKeyService{}
+	apiKey := &APIKey{ID: 7, RPMLimit: 2}
+
+	for i := 0; i < 2; i++ {
+		status, err := svc.CheckThroughputLimits(context.Background(), apiKey)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), status.RequestLimit)
+		require.Equal(t, int64(1-i), status.RequestRemaining)
+	}
+
+	status, err := svc.CheckThroughputLimits(context.Background(), apiKey)
+	require.ErrorIs(t, err, ErrAPIKeyRPMExceeded)
+	require.Equal(t, 30*time.Second, status.RetryAfter)
+
+	// 其他 Key 不受影响
+	_, err = svc.CheckThroughputLimits(context.Background(), &APIKey{ID: 8, RPMLimit: 2})
+	require.NoError(t, err)
+}
+
+func TestAPIKeyService_CheckThroughputLimits_TPMDebitedAfterUsage(t *testing.T) {
+	svc := &APIKeyService{}
+	apiKey := &APIKey{ID: 9, RPMLimit: 100, TPMLimit: 1000}
+
+	status, err := svc.CheckThroughputLimits(context.Background(), apiKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), status.TokenRemaining)
+
+	svc.RecordThroughputTokens(context.Background(), apiKey, 1500)
+
+	status, err = svc.CheckThroughputLimits(context.Background(), apiKey)
+	require.ErrorIs(t, err, ErrAPIKeyTPMExceeded)
+	require.Equal(t, int64(0), status.TokenRemaining)
+	require.Greater(t, status.RetryAfter, 29*time.Second)
+
+	// 被 TPM 拒绝的请求不消耗 RPM 令牌
+	svc.localBuckets.mu.Lock()
+	_, tracked := svc.localBuckets.buckets[apiKeyBucketKey{apiKeyID: 9, bucket: APIKeyBucketRequests}]
+	rpmTokens := svc.localBuckets.buckets[apiKeyBucketKey{apiKeyID: 9, bucket: APIKeyBucketRequests}].tokens
+	svc.localBuckets.mu.Unlock()
+	require.True(t, tracked)
+	require.InDelta(t, 99, rpmTokens, 0.1)
+}
+
+func TestAPIKeyService_CheckThroughputLimits_NoLimits(t *testing.T) {
+	status, err := (&APIKeyService{}).CheckThroughputLimits(context.Background(), &APIKey{ID: 1})
+	require.NoError(t, err)
+	require.Nil(t, status)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyTraceMaxRedactionRules      = 50
+	apiKeyTraceMaxRedactionPatternLen = 512
+	apiKeyTraceRedactedPlaceholder    = "[REDACTED]"
+)
+
+func invalidAPIKeyTraceRedactionRule(format string, a ...any) error {
+	return infraerrors.BadRequest("API_KEY_TRACE_INVALID_REDACTION_RULE", fmt.Sprintf(format, a...))
+}
+
+// APIKeyTraceRedactionRule 管理员自定义的正则脱敏规则，作用于追踪记录的请求/响应头值与请求/响应体。
+type APIKeyTraceRedactionRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// Replacement 支持 $1 等分组引用；为空时替换为 [REDACTED]
+	Replacement string `json:"replacement,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// APIKeyTraceRedactionSettings 脱敏规则视图：内置规则始终生效、不可关闭，Rules 为可编辑的自定义规则。
+type APIKeyTraceRedactionSettings struct {
+	BuiltinRules []string                   `json:"builtin_rules"`
+	Rules        []APIKeyTraceRedactionRule `json:"rules"`
+}
+
+type apiKeyTraceRedaction struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+}
+
+// apiKeyTraceBuiltinRedactions 内置文本规则；JSON 体另按敏感字段名（api_key / authorization / *_token 等）整体脱敏。
+var apiKeyTraceBuiltinRedactions = []apiKeyTraceRedaction{
+	{name: "authorization_bearer", re: regexp.MustCompile(`(?i)\b(Bearer\s+)[A-Za-z0-9._~+/=-]{8,}`), replacement: "${1}" + apiKeyTraceRedactedPlaceholder},
+	{name: "api_key", re: regexp.MustCompile(`\b(?:sk-ant-[a-z0-9]+-|sk-proj-|sk-|AIza|GOCSPX-|xai-)[A-Za-z0-9_-]{16,}`), replacement: apiKeyTraceRedactedPlaceholder},
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\b`), replacement: apiKeyTraceRedactedPlaceholder},
+	{name: "email", re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`), replacement: "[REDACTED_EMAIL]"},
+}
+
+// apiKeyTraceRedactor 内置规则 + 已编译的自定义规则，按顺序应用。
+type apiKeyTraceRedactor struct {
+	rules []apiKeyTraceRedaction
+}
+
+type apiKeyTraceRedactorCache struct {
+	mu        sync.Mutex
+	redactor  *apiKeyTraceRedactor
+	fetchedAt time.Time
+}
+
+func builtinAPIKeyTraceRedactionNames() []string {
+	names := make([]string, 0, len(apiKeyTraceBuiltinRedactions)+1)
+	names = append(names, "sensitive_json_fields")
+	for _, r := range apiKeyTraceBuiltinRedactions {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+func compileAPIKeyTraceRedactor(custom []APIKeyTraceRedactionRule) (*apiKeyTraceRedactor, error) {
+	rules := make([]apiKeyTraceRedaction, 0, len(apiKeyTraceBuiltinRedactions)+len(custom))
+	rules = append(rules, apiKeyTraceBuiltinRedactions...)
+	for i, rule := range custom {
+		if !rule.Enabled {
+			continue
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d] %q: %w", i, rule.Name, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = apiKeyTraceRedactedPlaceholder
+		}
+		rules = append(rules, apiKeyTraceRedaction{name: rule.Name, re: re, replacement: replacement})
+	}
+	return &apiKeyTraceRedactor{rules: rules}, nil
+}
+
+func (r *apiKeyTraceRedactor) text(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.re.ReplaceAllString(s, rule.replacement)
+	}
+	return s
+}
+
+// body JSON 体先按敏感字段名脱敏再套文本规则；非 JSON（如 SSE 流）只套文本规则。
+func (r *apiKeyTraceRedactor) body(s string) string {
+	if s == "" {
+		return s
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(s), &decoded); err == nil {
+		if encoded, err := json.Marshal(redactSensitiveJSON(decoded)); err == nil {
+			s = string(encoded)
+		}
+	}
+	return r.text(s)
+}
+
+func (r *apiKeyTraceRedactor) headers(h map[string]string) map[string]string {
+	if len(h) == 0 {
+		return h
+	}
+	out := make(map[string]string, len(h))
+	for name, value := range h {
+		out[name] = r.text(value)
+	}
+	return out
+}
+
+// record 原地脱敏追踪记录的头部与请求/响应体（含上游请求与响应）。
+func (r *apiKeyTraceRedactor) record(record *APIKeyTraceRecord) {
+	record.RequestHeaders = r.headers(record.RequestHeaders)
+	record.RequestBody = r.body(record.RequestBody)
+	record.ResponseHeaders = r.headers(record.ResponseHeaders)
+	record.ResponseBody = r.body(record.ResponseBody)
+	for _, upstream := range record.UpstreamResponses {
+		if upstream == nil {
+			continue
+		}
+		upstream.RequestHeaders = r.headers(upstream.RequestHeaders)
+		upstream.RequestBody = r.body(upstream.RequestBody)
+		upstream.Headers = r.headers(upstream.Headers)
+		upstream.Body = r.body(upstream.Body)
+	}
+}
+
+// redactor 返回当前生效的脱敏器，自定义规则在本地缓存 apiKeyTraceLocalCacheTTL。
+// 读取失败时沿用上次成功加载的规则（首次失败则仅用内置规则），不因配置读取故障丢弃记录。
+func (s *APIKeyTraceService) redactor(ctx context.Context) *apiKeyTraceRedactor {
+	s.redaction.mu.Lock()
+	defer s.redaction.mu.Unlock()
+	now := s.now()
+	if s.redaction.redactor != nil && now.Sub(s.redaction.fetchedAt) < apiKeyTraceLocalCacheTTL {
+		return s.redaction.redactor
+	}
+
+	custom, err := s.loadRedactionRules(ctx)
+	var redactor *apiKeyTraceRedactor
+	if err == nil {
+		redactor, err = compileAPIKeyTraceRedactor(custom)
+	}
+	if err != nil {
+		logger.L().With(zap.String("component", "service.api_key_trace")).Warn("api_key_trace.redaction_rules_load_failed", zap.Error(err))
+		if s.redaction.redactor == nil {
+			s.redaction.redactor, _ = compileAPIKeyTraceRedactor(nil)
+		}
+		s.redaction.fetchedAt = now
+		return s.redaction.redactor
+	}
+	s.redaction.redactor = redactor
+	s.redaction.fetchedAt = now
+	return redactor
+}
+
+func (s *APIKeyTraceService) loadRedactionRules(ctx context.Context) ([]APIKeyTraceRedactionRule, error) {
+	if s.settingRepo == nil {
+		return nil, nil
+	}
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyAPIKeyTraceRedactionRules)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var rules []APIKeyTraceRedactionRule
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetRedactionSettings 返回内置规则名与自定义脱敏规则。
+func (s *APIKeyTraceService) GetRedactionSettings(ctx context.Context) (*APIKeyTraceRedactionSettings, error) {
+	rules, err := s.loadRedactionRules(ctx)
+	if err != nil {
+		return nil, infraerrors.InternalServer("API_KEY_TRACE_REDACTION_LOAD_FAILED", "failed to load redaction rules").WithCause(err)
+	}
+	if rules == nil {
+		rules = []APIKeyTraceRedactionRule{}
+	}
+	return &APIKeyTraceRedactionSettings{BuiltinRules: builtinAPIKeyTraceRedactionNames(), Rules: rules}, nil
+}
+
+// UpdateRedactionRules 校验并整体替换自定义脱敏规则，本实例立即生效，其他实例在本地缓存过期后生效。
+func (s *APIKeyTraceService) UpdateRedactionRules(ctx context.Context, rules []APIKeyTraceRedactionRule) (*APIKeyTraceRedactionSettings, error) {
+	if s.settingRepo == nil {
+		return nil, ErrAPIKeyTraceUnavailable
+	}
+	if len(rules) > apiKeyTraceMaxRedactionRules {
+		return nil, invalidAPIKeyTraceRedactionRule("at most %d rules", apiKeyTraceMaxRedactionRules)
+	}
+	normalized := make([]APIKeyTraceRedactionRule, 0, len(rules))
+	for i, rule := range rules {
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return nil, invalidAPIKeyTraceRedactionRule("rules[%d].name is required", i)
+		}
+		if rule.Pattern == "" || len(rule.Pattern) > apiKeyTraceMaxRedactionPatternLen {
+			return nil, invalidAPIKeyTraceRedactionRule("rules[%d].pattern must be 1-%d characters", i, apiKeyTraceMaxRedactionPatternLen)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return nil, invalidAPIKeyTraceRedactionRule("rules[%d].pattern: %v", i, err)
+		}
+		normalized = append(normalized, rule)
+	}
+	redactor, err := compileAPIKeyTraceRedactor(normalized)
+	if err != nil {
+		return nil, invalidAPIKeyTraceRedactionRule("%v", err)
+	}
+
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyAPIKeyTraceRedactionRules, string(raw)); err != nil {
+		return nil, infraerrors.InternalServer("API_KEY_TRACE_REDACTION_SAVE_FAILED", "failed to save redaction rules").WithCause(err)
+	}
+
+	s.redaction.mu.Lock()
+	s.redaction.redactor = redactor
+	s.redaction.fetchedAt = s.now()
+	s.redaction.mu.Unlock()
+
+	return &APIKeyTraceRedactionSettings{BuiltinRules: builtinAPIKeyTraceRedactionNames(), Rules: normalized}, nil
+}
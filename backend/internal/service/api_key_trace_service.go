@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAPIKeyTraceDurationMinutes    = 30
+	defaultAPIKeyTraceMaxDurationMinutes = 240
+	defaultAPIKeyTraceMaxRecords         = 200
+	defaultAPIKeyTraceMaxBodyBytes       = 1 << 20
+	defaultAPIKeyTraceRetentionHours     = 24
+
+	// apiKeyTraceLocalCacheTTL 热路径本地缓存时长：其他实例开启/关闭追踪后最多延迟这么久生效。
+	apiKeyTraceLocalCacheTTL = 5 * time.Second
+	apiKeyTraceWriteTimeout  = 3 * time.Second
+)
+
+var (
+	ErrAPIKeyTraceInvalidDuration = infraerrors.BadRequest("API_KEY_TRACE_INVALID_DURATION", "trace duration is out of range")
+	ErrAPIKeyTraceNotFound        = infraerrors.NotFound("API_KEY_TRACE_NOT_FOUND", "api key is not being traced")
+	ErrAPIKeyTraceUnavailable     = infraerrors.ServiceUnavailable("API_KEY_TRACE_UNAVAILABLE", "api key trace storage is not available")
+)
+
+// APIKeyTraceSession 单个 API Key 的限时追踪会话，到期后自动失效。
+type APIKeyTraceSession struct {
+	APIKeyID  int64     `json:"api_key_id"`
+	Reason    string    `json:"reason,omitempty"`
+	StartedBy int64     `json:"started_by,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Active 会话是否仍在有效期内。
+func (s *APIKeyTraceSession) Active(now time.Time) bool {
+	return s != nil && now.Before(s.ExpiresAt)
+}
+
+// APIKeyTraceRecord 追踪期间抓取到的一次完整请求/响应。
+type APIKeyTraceRecord struct {
+	RequestID             string            `json:"request_id,omitempty"`
+	ClientRequestID       string            `json:"client_request_id,omitempty"`
+	APIKeyID              int64             `json:"api_key_id"`
+	AccountID             *int64            `json:"account_id,omitempty"`
+	Model                 string            `json:"model,omitempty"`
+	Method                string            `json:"method"`
+	Path                  string            `json:"path"`
+	Stream                bool              `json:"stream"`
+	StatusCode            int               `json:"status_code"`
+	DurationMs            int64             `json:"duration_ms"`
+	RequestHeaders        map[string]string `json:"request_headers,omitempty"`
+	RequestBody           string            `json:"request_body,omitempty"`
+	RequestBodyTruncated  bool              `json:"request_body_truncated,omitempty"`
+	ResponseHeaders       map[string]string `json:"response_headers,omitempty"`
+	ResponseBody          string            `json:"response_body,omitempty"`
+	ResponseBodyTruncated bool              `json:"response_body_truncated,omitempty"`
+	// UpstreamResponses 开启 capture_upstream_response 时各次上游调用实际返回的响应
+	UpstreamResponses        []*APIKeyTraceUpstreamResponse `json:"upstream_responses,omitempty"`
+	UpstreamResponsesDropped int                            `json:"upstream_responses_dropped,omitempty"`
+	CreatedAt                time.Time                      `json:"created_at"`
+}
+
+// APIKeyTraceCache 追踪会话与记录的存储（Redis），会话按到期时间自动过期。
+type APIKeyTraceCache interface {
+	// GetTraceSession 不存在时返回 (nil, nil)。
+	GetTraceSession(ctx context.Context, apiKeyID int64) (*APIKeyTraceSession, error)
+	SetTraceSession(ctx context.Context, session *APIKeyTraceSession) error
+	DeleteTraceSession(ctx context.Context, apiKeyID int64) error
+	// ListTraceSessions 返回所有未到期的会话。
+	ListTraceSessions(ctx context.Context) ([]*APIKeyTraceSession, error)
+	// AppendTraceRecord 追加记录并只保留最近 maxRecords 条，记录整体在 ttl 后过期。
+	AppendTraceRecord(ctx context.Context, record *APIKeyTraceRecord, maxRecords int, ttl time.Duration) error
+	// ListTraceRecords 按时间倒序返回最近 limit 条记录。
+	ListTraceRecords(ctx context.Context, apiKeyID int64, limit int) ([]*APIKeyTraceRecord, error)
+}
+
+type apiKeyTraceLocalEntry struct {
+	session   *APIKeyTraceSession
+	fetchedAt time.Time
+}
+
+// APIKeyTraceService 管理员对单个 API Key 开启限时全量抓包，替代排查个别用户问题时粗暴的全局开关。
+type APIKeyTraceService struct {
+	cache       APIKeyTraceCache
+	apiKeyRepo  APIKeyRepository
+	settingRepo SettingRepository
+	cfg         config.GatewayAPIKeyTraceConfig
+
+	local     sync.Map // map[int64]apiKeyTraceLocalEntry
+	redaction apiKeyTraceRedactorCache
+	now       func() time.Time
+}
+
+// NewAPIKeyTraceService creates an APIKeyTraceService.
+func NewAPIKeyTraceService(cache APIKeyTraceCache, apiKeyRepo APIKeyRepository, settingRepo SettingRepository, cfg *config.Config) *APIKeyTraceService {
+	svc := &APIKeyTraceService{
+		cache:       cache,
+		apiKeyRepo:  apiKeyRepo,
+		settingRepo: settingRepo,
+		now:         time.Now,
+	}
+	if cfg != nil {
+		svc.cfg = cfg.Gateway.APIKeyTrace
+	}
+	return svc
+}
+
+func (s *APIKeyTraceService) defaultDuration() time.Duration {
+	if s.cfg.DefaultDurationMinutes > 0 {
+		return time.Duration(s.cfg.DefaultDurationMinutes) * time.Minute
+	}
+	return defaultAPIKeyTraceDurationMinutes * time.Minute
+}
+
+func (s *APIKeyTraceService) maxDuration() time.Duration {
+	if s.cfg.MaxDurationMinutes > 0 {
+		return time.Duration(s.cfg.MaxDurationMinutes) * time.Minute
+	}
+	return defaultAPIKeyTraceMaxDurationMinutes * time.Minute
+}
+
+func (s *APIKeyTraceService) maxRecords() int {
+	if s.cfg.MaxRecords > 0 {
+		return s.cfg.MaxRecords
+	}
+	return defaultAPIKeyTraceMaxRecords
+}
+
+// MaxBodyBytes 单条记录请求体/响应体各自的保存上限。
+func (s *APIKeyTraceService) MaxBodyBytes() int {
+	if s == nil || s.cfg.MaxBodyBytes <= 0 {
+		return defaultAPIKeyTraceMaxBodyBytes
+	}
+	return s.cfg.MaxBodyBytes
+}
+
+// CaptureUpstreamResponse 是否在追踪记录中附带上游原始响应。
+func (s *APIKeyTraceService) CaptureUpstreamResponse() bool {
+	return s != nil && s.cfg.CaptureUpstreamResponse
+}
+
+func (s *APIKeyTraceService) retention() time.Duration {
+	if s.cfg.RetentionHours > 0 {
+		return time.Duration(s.cfg.RetentionHours) * time.Hour
+	}
+	return defaultAPIKeyTraceRetentionHours * time.Hour
+}
+
+// StartTrace 对指定 API Key 开启追踪；duration<=0 时使用默认时长。重复开启会以新的时长覆盖。
+func (s *APIKeyTraceService) StartTrace(ctx context.Context, apiKeyID int64, duration time.Duration, reason string, startedBy int64) (*APIKeyTraceSession, error) {
+	if s == nil || s.cache == nil {
+		return nil, ErrAPIKeyTraceUnavailable
+	}
+	if duration <= 0 {
+		duration = s.defaultDuration()
+	}
+	if duration > s.maxDuration() {
+		return nil, ErrAPIKeyTraceInvalidDuration
+	}
+	if s.apiKeyRepo != nil {
+		if _, err := s.apiKeyRepo.GetByID(ctx, apiKeyID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now()
+	session := &APIKeyTraceSession{
+		APIKeyID:  apiKeyID,
+		Reason:    reason,
+		StartedBy: startedBy,
+		StartedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+	if err := s.cache.SetTraceSession(ctx, session); err != nil {
+		return nil, err
+	}
+	s.local.Store(apiKeyID, apiKeyTraceLocalEntry{session: session, fetchedAt: now})
+
+	logger.L().With(zap.String("component", "audit.api_key_trace")).Info("api_key_trace.started",
+		zap.Int64("api_key_id", apiKeyID),
+		zap.Int64("started_by", startedBy),
+		zap.Time("expires_at", session.ExpiresAt),
+		zap.String("reason", reason),
+	)
+	return session, nil
+}
+
+// StopTrace 提前结束追踪，已抓取的记录保留至过期。
+func (s *APIKeyTraceService) StopTrace(ctx context.Context, apiKeyID int64) error {
+	if s == nil || s.cache == nil {
+		return ErrAPIKeyTraceUnavailable
+	}
+	session, err := s.cache.GetTraceSession(ctx, apiKeyID)
+	if err != nil {
+		return err
+	}
+	if !session.Active(s.now()) {
+		return ErrAPIKeyTraceNotFound
+	}
+	if err := s.cache.DeleteTraceSession(ctx, apiKeyID); err != nil {
+		return err
+	}
+	s.local.Delete(apiKeyID)
+
+	logger.L().With(zap.String("component", "audit.api_key_trace")).Info("api_key_trace.stopped",
+		zap.Int64("api_key_id", apiKeyID),
+	)
+	return nil
+}
+
+// GetTrace 返回当前会话（未追踪时为 nil）与最近的记录。
+func (s *APIKeyTraceService) GetTrace(ctx context.Context, apiKeyID int64, limit int) (*APIKeyTraceSession, []*APIKeyTraceRecord, error) {
+	if s == nil || s.cache == nil {
+		return nil, nil, ErrAPIKeyTraceUnavailable
+	}
+	session, err := s.cache.GetTraceSession(ctx, apiKeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !session.Active(s.now()) {
+		session = nil
+	}
+	if limit <= 0 || limit > s.maxRecords() {
+		limit = s.maxRecords()
+	}
+	records, err := s.cache.ListTraceRecords(ctx, apiKeyID, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, records, nil
+}
+
+// ListActiveTraces 返回所有进行中的追踪会话。
+func (s *APIKeyTraceService) ListActiveTraces(ctx context.Context) ([]*APIKeyTraceSession, error) {
+	if s == nil || s.cache == nil {
+		return nil, ErrAPIKeyTraceUnavailable
+	}
+	sessions, err := s.cache.ListTraceSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	out := make([]*APIKeyTraceSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Active(now) {
+			out = append(out, session)
+		}
+	}
+	return out, nil
+}
+
+// ActiveSession 网关热路径：判断 API Key 是否处于追踪中。
+// 结果在本地缓存 apiKeyTraceLocalCacheTTL，Redis 故障时视为未追踪，不影响正常转发。
+func (s *APIKeyTraceService) ActiveSession(ctx context.Context, apiKeyID int64) *APIKeyTraceSession {
+	if s == nil || s.cache == nil || apiKeyID <= 0 {
+		return nil
+	}
+	now := s.now()
+	if v, ok := s.local.Load(apiKeyID); ok {
+		entry := v.(apiKeyTraceLocalEntry)
+		if now.Sub(entry.fetchedAt) < apiKeyTraceLocalCacheTTL {
+			if entry.session.Active(now) {
+				return entry.session
+			}
+			return nil
+		}
+	}
+
+	session, err := s.cache.GetTraceSession(ctx, apiKeyID)
+	if err != nil {
+		logger.L().With(zap.String("component", "service.api_key_trace")).Debug("api_key_trace.lookup_failed",
+			zap.Int64("api_key_id", apiKeyID),
+			zap.Error(err),
+		)
+		session = nil
+	}
+	s.local.Store(apiKeyID, apiKeyTraceLocalEntry{session: session, fetchedAt: now})
+	if session.Active(now) {
+		return session
+	}
+	return nil
+}
+
+// RecordTrace 异步脱敏并保存一条追踪记录，不阻塞请求返回；调用方之后不得再使用 record。
+func (s *APIKeyTraceService) RecordTrace(record *APIKeyTraceRecord) {
+	if s == nil || s.cache == nil || record == nil {
+		return
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = s.now()
+	}
+	ttl := s.maxDuration() + s.retention()
+	maxRecords := s.maxRecords()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), apiKeyTraceWriteTimeout)
+		defer cancel()
+		// 落库前统一脱敏：内置规则 + 管理员自定义正则
+		s.redactor(ctx).record(record)
+		if err := s.cache.AppendTraceRecord(ctx, record, maxRecords, ttl); err != nil {
+			logger.L().With(zap.String("component", "service.api_key_trace")).Warn("api_key_trace.record_failed",
+				zap.Int64("api_key_id", record.APIKeyID),
+				zap.String("request_id", record.RequestID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
@@ -0,0 +1,173 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyTraceCacheFake struct {
+	mu       sync.Mutex
+	sessions map[int64]*APIKeyTraceSession
+	records  map[int64][]*APIKeyTraceRecord
+	gets     int
+	getErr   error
+}
+
+func newAPIKeyTraceCacheFake() *apiKeyTraceCacheFake {
+	return &apiKeyTraceCacheFake{
+		sessions: make(map[int64]*APIKeyTraceSession),
+		records:  make(map[int64][]*APIKeyTraceRecord),
+	}
+}
+
+func (f *apiKeyTraceCacheFake) GetTraceSession(_ context.Context, apiKeyID int64) (*APIKeyTraceSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	return f.sessions[apiKeyID], nil
+}
+
+func (f *apiKeyTraceCacheFake) SetTraceSession(_ context.Context, session *APIKeyTraceSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[session.APIKeyID] = session
+	return nil
+}
+
+func (f *apiKeyTraceCacheFake) DeleteTraceSession(_ context.Context, apiKeyID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, apiKeyID)
+	return nil
+}
+
+func (f *apiKeyTraceCacheFake) ListTraceSessions(context.Context) ([]*APIKeyTraceSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]*APIKeyTraceSession, 0, len(f.sessions))
+	for _, s := range f.sessions {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (f *apiKeyTraceCacheFake) AppendTraceRecord(_ context.Context, record *APIKeyTraceRecord, maxRecords int, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := append([]*APIKeyTraceRecord{record}, f.records[record.APIKeyID]...)
+	if len(list) > maxRecords {
+		list = list[:maxRecords]
+	}
+	f.records[record.APIKeyID] = list
+	return nil
+}
+
+func (f *apiKeyTraceCacheFake) ListTraceRecords(_ context.Context, apiKeyID int64, limit int) ([]*APIKeyTraceRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := f.records[apiKeyID]
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func TestAPIKeyTraceService_StartUsesDefaultAndRejectsTooLong(t *testing.T) {
+	cache := newAPIKeyTraceCacheFake()
+	cfg := &config.Config{}
+	cfg.Gateway.APIKeyTrace.DefaultDurationMinutes = 30
+	cfg.Gateway.APIKeyTrace.MaxDurationMinutes = 60
+	svc := NewAPIKeyTraceService(cache, nil, nil, cfg)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	session, err := svc.StartTrace(context.Background(), 7, 0, "ticket-1", 1)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(30*time.Minute), session.ExpiresAt)
+	require.Equal(t, "ticket-1", cache.sessions[7].Reason)
+
+	_, err = svc.StartTrace(context.Background(), 7, 2*time.Hour, "", 1)
+	require.ErrorIs(t, err, ErrAPIKeyTraceInvalidDuration)
+}
+
+func TestAPIKeyTraceService_StartRejectsUnknownKey(t *testing.T) {
+	repo := &apiKeyRepoStubForGroupUpdate{getErr: ErrAPIKeyNotFound}
+	svc := NewAPIKeyTraceService(newAPIKeyTraceCacheFake(), repo, nil, &config.Config{})
+
+	_, err := svc.StartTrace(context.Background(), 404, time.Minute, "", 1)
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyTraceService_ActiveSessionExpiresAutomatically(t *testing.T) {
+	cache := newAPIKeyTraceCacheFake()
+	svc := NewAPIKeyTraceService(cache, nil, nil, &config.Config{})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	_, err := svc.StartTrace(context.Background(), 5, 10*time.Minute, "", 1)
+	require.NoError(t, err)
+	require.NotNil(t, svc.ActiveSession(context.Background(), 5))
+
+	now = now.Add(11 * time.Minute)
+	require.Nil(t, svc.ActiveSession(context.Background(), 5))
+	require.Nil(t, svc.ActiveSession(context.Background(), 6))
+}
+
+func TestAPIKeyTraceService_ActiveSessionUsesLocalCache(t *testing.T) {
+	cache := newAPIKeyTraceCacheFake()
+	svc := NewAPIKeyTraceService(cache, nil, nil, &config.Config{})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	require.Nil(t, svc.ActiveSession(context.Background(), 8))
+	require.Nil(t, svc.ActiveSession(context.Background(), 8))
+	require.Equal(t, 1, cache.gets)
+
+	// 其他实例开启的追踪在本地缓存过期后生效
+	cache.sessions[8] = &APIKeyTraceSession{APIKeyID: 8, StartedAt: now, ExpiresAt: now.Add(time.Hour)}
+	now = now.Add(apiKeyTraceLocalCacheTTL)
+	require.NotNil(t, svc.ActiveSession(context.Background(), 8))
+	require.Equal(t, 2, cache.gets)
+}
+
+func TestAPIKeyTraceService_ActiveSessionIgnoresCacheErrors(t *testing.T) {
+	cache := newAPIKeyTraceCacheFake()
+	cache.getErr = errors.New("redis down")
+	svc := NewAPIKeyTraceService(cache, nil, nil, &config.Config{})
+
+	require.Nil(t, svc.ActiveSession(context.Background(), 1))
+}
+
+func TestAPIKeyTraceService_StopAndGetTrace(t *testing.T) {
+	cache := newAPIKeyTraceCacheFake()
+	svc := NewAPIKeyTraceService(cache, nil, nil, &config.Config{})
+
+	require.ErrorIs(t, svc.StopTrace(context.Background(), 3), ErrAPIKeyTraceNotFound)
+
+	_, err := svc.StartTrace(context.Background(), 3, time.Minute, "", 1)
+	require.NoError(t, err)
+	require.NoError(t, cache.AppendTraceRecord(context.Background(), &APIKeyTraceRecord{APIKeyID: 3, RequestID: "r1"}, 10, time.Hour))
+
+	session, records, err := svc.GetTrace(context.Background(), 3, 0)
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	require.Len(t, records, 1)
+
+	require.NoError(t, svc.StopTrace(context.Background(), 3))
+	require.Nil(t, svc.ActiveSession(context.Background(), 3))
+	session, records, err = svc.GetTrace(context.Background(), 3, 0)
+	require.NoError(t, err)
+	require.Nil(t, session)
+	require.Len(t, records, 1, "records survive until retention expiry")
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// apiKeyTraceMaxUpstreamResponses 单条追踪记录保留的上游响应数（含重试 / 切换账号），超出部分只计数。
+const apiKeyTraceMaxUpstreamResponses = 8
+
+type apiKeyTraceUpstreamCaptureKey struct{}
+
+// apiKeyTraceSensitiveQueryParams 上游 URL 中携带凭证的 query 参数，抓取时丢弃。
+var apiKeyTraceSensitiveQueryParams = map[string]struct{}{
+	"key":           {},
+	"api_key":       {},
+	"access_token":  {},
+	"refresh_token": {},
+	"client_secret": {},
+}
+
+// APIKeyTraceUpstreamResponse 追踪期间一次上游调用实际发出的请求与返回的响应（解压后），供排查与重放。
+type APIKeyTraceUpstreamResponse struct {
+	AccountID int64  `json:"account_id,omitempty"`
+	Method    string `json:"method"`
+	URL       string `json:"url"`
+	// Query 去掉凭证参数后的 query string（不含 ?）。
+	Query                string            `json:"query,omitempty"`
+	RequestHeaders       map[string]string `json:"request_headers,omitempty"`
+	RequestBody          string            `json:"request_body,omitempty"`
+	RequestBodyTruncated bool              `json:"request_body_truncated,omitempty"`
+
+	StatusCode    int               `json:"status_code"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Body          string            `json:"body,omitempty"`
+	BodyTruncated bool              `json:"body_truncated,omitempty"`
+}
+
+// APIKeyTraceUpstreamCapture 收集同一入站请求内各次上游响应；响应体只复制业务层实际读取的部分。
+// 可能被流式读取 goroutine 并发写入，读取结果前需等待入站请求处理完毕。
+type APIKeyTraceUpstreamCapture struct {
+	limit int
+
+	mu      sync.Mutex
+	entries []*apiKeyTraceUpstreamEntry
+	dropped int
+}
+
+type apiKeyTraceUpstreamEntry struct {
+	resp      APIKeyTraceUpstreamResponse
+	reqHeader http.Header
+	header    http.Header
+	buf       bytes.Buffer
+	truncated bool
+}
+
+// NewAPIKeyTraceUpstreamCapture 创建上游响应抓取器，limit 为单个响应体的保存上限。
+func NewAPIKeyTraceUpstreamCapture(limit int) *APIKeyTraceUpstreamCapture {
+	if limit <= 0 {
+		limit = defaultAPIKeyTraceMaxBodyBytes
+	}
+	return &APIKeyTraceUpstreamCapture{limit: limit}
+}
+
+// WithAPIKeyTraceUpstreamCapture 将抓取器挂到请求 context，HTTPUpstream 据此旁路复制上游响应。
+func WithAPIKeyTraceUpstreamCapture(ctx context.Context, capture *APIKeyTraceUpstreamCapture) context.Context {
+	if ctx == nil || capture == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyTraceUpstreamCaptureKey{}, capture)
+}
+
+// CaptureAPIKeyTraceUpstreamResponse 若请求处于追踪中，记录上游请求（请求体经 GetBody 重新读取）与响应头，
+// 并包装 resp.Body 以复制读取到的内容。由 HTTPUpstream 实现在响应解压后调用；未追踪的请求只多一次 context 查询。
+func CaptureAPIKeyTraceUpstreamResponse(req *http.Request, resp *http.Response, accountID int64) {
+	if req == nil || resp == nil {
+		return
+	}
+	capture, _ := req.Context().Value(apiKeyTraceUpstreamCaptureKey{}).(*APIKeyTraceUpstreamCapture)
+	if capture == nil {
+		return
+	}
+	entry := &apiKeyTraceUpstreamEntry{
+		resp: APIKeyTraceUpstreamResponse{
+			AccountID:  accountID,
+			Method:     req.Method,
+			StatusCode: resp.StatusCode,
+		},
+		reqHeader: req.Header.Clone(),
+		header:    resp.Header.Clone(),
+	}
+	if req.URL != nil {
+		// URL 只保留 scheme://host/path：query 中可能带 key（如 Gemini ?key=），去掉凭证参数后单独保存
+		entry.resp.URL = req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+		entry.resp.Query = apiKeyTraceSafeQuery(req.URL.RawQuery)
+	}
+	entry.resp.RequestBody, entry.resp.RequestBodyTruncated = apiKeyTraceUpstreamRequestBody(req, capture.limit)
+
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	if len(capture.entries) >= apiKeyTraceMaxUpstreamResponses {
+		capture.dropped++
+		return
+	}
+	capture.entries = append(capture.entries, entry)
+	if resp.Body != nil {
+		resp.Body = &apiKeyTraceUpstreamBody{ReadCloser: resp.Body, capture: capture, entry: entry}
+	}
+}
+
+// Responses 返回已抓取的上游响应（按调用顺序）及因数量上限被丢弃的个数。
+// headerFn 用于在导出前转换（脱敏）响应头。
+func (c *APIKeyTraceUpstreamCapture) Responses(headerFn func(http.Header) map[string]string) ([]*APIKeyTraceUpstreamResponse, int) {
+	if c == nil {
+		return nil, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]*APIKeyTraceUpstreamResponse, 0, len(c.entries))
+	for _, entry := range c.entries {
+		resp := entry.resp
+		if headerFn != nil {
+			resp.RequestHeaders = headerFn(entry.reqHeader)
+			resp.Headers = headerFn(entry.header)
+		}
+		resp.Body = entry.buf.String()
+		resp.BodyTruncated = entry.truncated
+		out = append(out, &resp)
+	}
+	return out, c.dropped
+}
+
+// apiKeyTraceUpstreamRequestBody 通过 GetBody 重新读取已发送的请求体（最多 limit 字节），不影响原请求。
+func apiKeyTraceUpstreamRequestBody(req *http.Request, limit int) (string, bool) {
+	if req.GetBody == nil || req.Body == nil || req.Body == http.NoBody {
+		return "", false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer func() { _ = body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if err != nil {
+		return "", false
+	}
+	if len(data) > limit {
+		return string(data[:limit]), true
+	}
+	return string(data), false
+}
+
+func apiKeyTraceSafeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	for name := range values {
+		if _, sensitive := apiKeyTraceSensitiveQueryParams[strings.ToLower(name)]; sensitive {
+			values.Del(name)
+		}
+	}
+	return values.Encode()
+}
+
+type apiKeyTraceUpstreamBody struct {
+	io.ReadCloser
+	capture *APIKeyTraceUpstreamCapture
+	entry   *apiKeyTraceUpstreamEntry
+}
+
+func (b *apiKeyTraceUpstreamBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.capture.mu.Lock()
+		if !b.entry.truncated {
+			chunk := p[:n]
+			if remaining := b.capture.limit - b.entry.buf.Len(); len(chunk) > remaining {
+				chunk = chunk[:remaining]
+				b.entry.truncated = true
+			}
+			_, _ = b.entry.buf.Write(chunk)
+		}
+		b.capture.mu.Unlock()
+	}
+	return n, err
+}
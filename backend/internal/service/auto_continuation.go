@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// defaultAutoContinuationMax 单个响应默认最多发起的续写请求数。
+	defaultAutoContinuationMax = 2
+	maxAutoContinuationMax     = 5
+)
+
+// normalizeGroupAutoContinuationConfig 校验截断自动续写配置：max_continuations 不能为负且不超过上限，0 表示使用默认值。
+func normalizeGroupAutoContinuationConfig(cfg GroupAutoContinuationConfig) (GroupAutoContinuationConfig, error) {
+	if cfg.MaxContinuations < 0 || cfg.MaxContinuations > maxAutoContinuationMax {
+		return GroupAutoContinuationConfig{}, infraerrors.BadRequest("INVALID_AUTO_CONTINUATION",
+			"auto continuation max_continuations must be between 0 and "+strconv.Itoa(maxAutoContinuationMax))
+	}
+	return cfg, nil
+}
+
+// AutoContinuationLimit 返回生效的续写次数上限，未启用时返回 0。
+func AutoContinuationLimit(cfg GroupAutoContinuationConfig) int {
+	if !cfg.Enabled {
+		return 0
+	}
+	if cfg.MaxContinuations <= 0 {
+		return defaultAutoContinuationMax
+	}
+	return cfg.MaxContinuations
+}
+
+// anthropicResponseTruncated 判断 Messages 响应是否因输出 token 上限被截断。
+func anthropicResponseTruncated(body []byte) bool {
+	return gjson.GetBytes(body, "stop_reason").String() == "max_tokens"
+}
+
+// openAIResponseTruncated 判断 Responses（status=incomplete 且原因为 max_output_tokens）
+// 或 Chat Completions（finish_reason=length）响应是否因输出 token 上限被截断。
+func openAIResponseTruncated(body []byte) bool {
+	if gjson.GetBytes(body, "status").String() == "incomplete" {
+		return gjson.GetBytes(body, "incomplete_details.reason").String() == "max_output_tokens"
+	}
+	truncated := false
+	gjson.GetBytes(body, "choices").ForEach(func(_, choice gjson.Result) bool {
+		truncated = choice.Get("finish_reason").String() == "length"
+		return !truncated
+	})
+	return truncated
+}
+
+// 截断检测统计的平台维度。
+var autoContinuationPlatforms = [...]string{PlatformAnthropic, PlatformOpenAI}
+
+type autoContinuationCounters struct {
+	truncated [len(autoContinuationPlatforms)]atomic.Int64
+	continued atomic.Int64
+	requests  atomic.Int64
+	completed atomic.Int64
+	exhausted atomic.Int64
+	failed    atomic.Int64
+	skipped   atomic.Int64
+}
+
+var (
+	autoContinuationStats   autoContinuationCounters
+	autoContinuationStatsAt = time.Now()
+)
+
+// recordTruncatedResponse 累计检测到的被截断非流式响应（进程内统计，重启后清零）。
+func recordTruncatedResponse(platform string) {
+	for i, p := range autoContinuationPlatforms {
+		if p == platform {
+			autoContinuationStats.truncated[i].Add(1)
+			return
+		}
+	}
+}
+
+// AutoContinuationStatsSnapshot 截断检测与自动续写统计（进程内、自启动以来）。
+type AutoContinuationStatsSnapshot struct {
+	Since time.Time `json:"since"`
+	// TruncatedResponses 各平台检测到的被截断非流式响应数（无论分组是否开启续写）
+	TruncatedResponses map[string]int64 `json:"truncated_responses"`
+	// ContinuedResponses 触发了续写的响应数；ContinuationRequests 为发出的续写请求总数
+	ContinuedResponses   int64 `json:"continued_responses"`
+	ContinuationRequests int64 `json:"continuation_requests"`
+	// Completed 续写后正常结束；Exhausted 达到续写上限仍被截断；Failed 续写请求失败（返回已拼接的内容）
+	Completed int64 `json:"completed"`
+	Exhausted int64 `json:"exhausted"`
+	Failed    int64 `json:"failed"`
+	// Skipped 分组已开启但请求不适合续写（含 tool_use/thinking 输出、客户端预填充等）
+	Skipped int64 `json:"skipped"`
+}
+
+// GetAutoContinuationStats 返回截断检测与自动续写统计。
+func (s *OpsService) GetAutoContinuationStats() AutoContinuationStatsSnapshot {
+	snapshot := AutoContinuationStatsSnapshot{
+		Since:                autoContinuationStatsAt,
+		TruncatedResponses:   make(map[string]int64, len(autoContinuationPlatforms)),
+		ContinuedResponses:   autoContinuationStats.continued.Load(),
+		ContinuationRequests: autoContinuationStats.requests.Load(),
+		Completed:            autoContinuationStats.completed.Load(),
+		Exhausted:            autoContinuationStats.exhausted.Load(),
+		Failed:               autoContinuationStats.failed.Load(),
+		Skipped:              autoContinuationStats.skipped.Load(),
+	}
+	for i, p := range autoContinuationPlatforms {
+		snapshot.TruncatedResponses[p] = autoContinuationStats.truncated[i].Load()
+	}
+	return snapshot
+}
+
+// anthropicContinuation 非流式 Messages 响应被截断时的续写器：以已生成的文本作为 assistant 预填充重新请求，
+// 并把续写输出拼接到原响应。只有 Messages API 支持从预填充处继续生成，OpenAI 路径仅做截断检测。
+type anthropicContinuation struct {
+	max     int
+	reqBody []byte
+	// send 用同一账号发送续写请求（非流式）
+	send     func(ctx context.Context, body []byte) (*http.Response, error)
+	maxBytes int64
+}
+
+// newAnthropicContinuation 在分组开启续写时返回续写器，否则返回 nil；请求是否适合续写在截断发生后判断。
+func (s *GatewayService) newAnthropicContinuation(group *Group, reqBody []byte, send func(ctx context.Context, body []byte) (*http.Response, error)) *anthropicContinuation {
+	if group == nil {
+		return nil
+	}
+	limit := AutoContinuationLimit(group.AutoContinuation)
+	if limit <= 0 {
+		return nil
+	}
+	return &anthropicContinuation{max: limit, reqBody: reqBody, send: send, maxBytes: resolveUpstreamResponseReadLimit(s.cfg)}
+}
+
+// run 对被截断的响应循环续写，返回拼接后的响应体，并把续写请求的用量累加到 usage（每次续写都会重新计费输入）。
+// 续写失败时保留已拼接的内容，不影响原响应的返回。
+func (a *anthropicContinuation) run(ctx context.Context, body []byte, usage *ClaudeUsage) []byte {
+	if a == nil || !anthropicResponseTruncated(body) {
+		return body
+	}
+	for i := 0; i < a.max; i++ {
+		contBody, prefill, ok := buildAnthropicContinuationRequest(a.reqBody, body)
+		if !ok {
+			if i == 0 {
+				autoContinuationStats.skipped.Add(1)
+			} else {
+				autoContinuationStats.exhausted.Add(1)
+			}
+			return body
+		}
+		if i == 0 {
+			autoContinuationStats.continued.Add(1)
+		}
+		autoContinuationStats.requests.Add(1)
+
+		contResp, err := a.fetch(ctx, contBody)
+		if err != nil {
+			autoContinuationStats.failed.Add(1)
+			logger.LegacyPrintf("service.gateway", "[AutoContinuation] continuation %d failed: %v", i+1, err)
+			return body
+		}
+		stitched, err := stitchAnthropicContinuation(body, prefill, contResp, usage)
+		if err != nil {
+			autoContinuationStats.failed.Add(1)
+			logger.LegacyPrintf("service.gateway", "[AutoContinuation] stitch continuation %d failed: %v", i+1, err)
+			return body
+		}
+		body = stitched
+		if !anthropicResponseTruncated(body) {
+			autoContinuationStats.completed.Add(1)
+			return body
+		}
+	}
+	autoContinuationStats.exhausted.Add(1)
+	return body
+}
+
+func (a *anthropicContinuation) fetch(ctx context.Context, body []byte) ([]byte, error) {
+	resp, err := a.send(ctx, body)
+	if err != nil {
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, a.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upstream status %d: %s", resp.StatusCode, truncateString(extractUpstreamErrorMessage(respBody), 200))
+	}
+	return respBody, nil
+}
+
+// buildAnthropicContinuationRequest 在原请求的 messages 末尾追加 assistant 预填充（已生成的文本块）。
+// 仅当输出全部为文本块、且原请求末尾不是客户端自己的 assistant 预填充时可续写。
+// 上游拒绝以空白结尾的预填充，因此最后一个文本块去除尾部空白，返回值 prefill 为去除后的文本。
+func buildAnthropicContinuationRequest(reqBody, respBody []byte) (contBody []byte, prefill string, ok bool) {
+	messages := gjson.GetBytes(reqBody, "messages")
+	if !messages.IsArray() {
+		return nil, "", false
+	}
+	if msgs := messages.Array(); len(msgs) > 0 && msgs[len(msgs)-1].Get("role").String() == "assistant" {
+		return nil, "", false
+	}
+	content := gjson.GetBytes(respBody, "content").Array()
+	if len(content) == 0 {
+		return nil, "", false
+	}
+	blocks := make([]map[string]any, 0, len(content))
+	for i, block := range content {
+		if block.Get("type").String() != "text" {
+			return nil, "", false
+		}
+		text := block.Get("text").String()
+		if i == len(content)-1 {
+			text = strings.TrimRight(text, " \t\r\n")
+			prefill = text
+		}
+		if text == "" {
+			continue
+		}
+		blocks = append(blocks, map[string]any{"type": "text", "text": text})
+	}
+	if prefill == "" {
+		return nil, "", false
+	}
+	contBody, err := sjson.SetBytes(reqBody, "messages.-1", map[string]any{"role": "assistant", "content": blocks})
+	if err != nil {
+		return nil, "", false
+	}
+	return contBody, prefill, true
+}
+
+// stitchAnthropicContinuation 把续写响应拼接到原响应：续写的首个文本块接在最后一个文本块（去除尾部空白后）之后，
+// 其余内容块依次追加；stop_reason 取续写响应，usage 累加。
+func stitchAnthropicContinuation(body []byte, prefill string, contBody []byte, usage *ClaudeUsage) ([]byte, error) {
+	var cont struct {
+		Content      []json.RawMessage `json:"content"`
+		StopReason   string            `json:"stop_reason"`
+		StopSequence *string           `json:"stop_sequence"`
+		Usage        ClaudeUsage       `json:"usage"`
+	}
+	if err := json.Unmarshal(contBody, &cont); err != nil {
+		return nil, fmt.Errorf("parse continuation: %w", err)
+	}
+	last := len(gjson.GetBytes(body, "content").Array()) - 1
+	out, err := sjson.SetBytes(body, fmt.Sprintf("content.%d.text", last), prefill)
+	if err != nil {
+		return nil, err
+	}
+	for i, raw := range cont.Content {
+		block := gjson.ParseBytes(raw)
+		if i == 0 && block.Get("type").String() == "text" {
+			out, err = sjson.SetBytes(out, fmt.Sprintf("content.%d.text", last), prefill+block.Get("text").String())
+		} else {
+			out, err = sjson.SetRawBytes(out, "content.-1", raw)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if out, err = sjson.SetBytes(out, "stop_reason", cont.StopReason); err != nil {
+		return nil, err
+	}
+	if out, err = sjson.SetBytes(out, "stop_sequence", cont.StopSequence); err != nil {
+		return nil, err
+	}
+
+	cont.Usage.CacheCreation5mTokens = int(gjson.GetBytes(contBody, "usage.cache_creation.ephemeral_5m_input_tokens").Int())
+	cont.Usage.CacheCreation1hTokens = int(gjson.GetBytes(contBody, "usage.cache_creation.ephemeral_1h_input_tokens").Int())
+	usage.InputTokens += cont.Usage.InputTokens
+	usage.OutputTokens += cont.Usage.OutputTokens
+	usage.CacheCreationInputTokens += cont.Usage.CacheCreationInputTokens
+	usage.CacheReadInputTokens += cont.Usage.CacheReadInputTokens
+	usage.CacheCreation5mTokens += cont.Usage.CacheCreation5mTokens
+	usage.CacheCreation1hTokens += cont.Usage.CacheCreation1hTokens
+	for path, value := range map[string]int{
+		"usage.input_tokens":                usage.InputTokens,
+		"usage.output_tokens":               usage.OutputTokens,
+		"usage.cache_creation_input_tokens": usage.CacheCreationInputTokens,
+		"usage.cache_read_input_tokens":     usage.CacheReadInputTokens,
+	} {
+		if out, err = sjson.SetBytes(out, path, value); err != nil {
+			return nil, err
+		}
+	}
+	if gjson.GetBytes(out, "usage.cache_creation").Exists() {
+		if out, err = sjson.SetBytes(out, "usage.cache_creation.ephemeral_5m_input_tokens", usage.CacheCreation5mTokens); err != nil {
+			return nil, err
+		}
+		if out, err = sjson.SetBytes(out, "usage.cache_creation.ephemeral_1h_input_tokens", usage.CacheCreation1hTokens); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,128 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+func TestNormalizeGroupAutoContinuationConfig(t *testing.T) {
+	cfg, err := normalizeGroupAutoContinuationConfig(GroupAutoContinuationConfig{Enabled: true})
+	require.NoError(t, err)
+	require.Equal(t, defaultAutoContinuationMax, AutoContinuationLimit(cfg))
+	require.Zero(t, AutoContinuationLimit(GroupAutoContinuationConfig{MaxContinuations: 3}))
+
+	_, err = normalizeGroupAutoContinuationConfig(GroupAutoContinuationConfig{Enabled: true, MaxContinuations: maxAutoContinuationMax + 1})
+	require.Error(t, err)
+}
+
+func TestOpenAIResponseTruncated(t *testing.T) {
+	require.True(t, openAIResponseTruncated([]byte(`{"status":"incomplete","incomplete_details":{"reason":"max_output_tokens"}}`)))
+	require.False(t, openAIResponseTruncated([]byte(`{"status":"incomplete","incomplete_details":{"reason":"content_filter"}}`)))
+	require.True(t, openAIResponseTruncated([]byte(`{"choices":[{"finish_reason":"stop"},{"finish_reason":"length"}]}`)))
+	require.False(t, openAIResponseTruncated([]byte(`{"status":"completed","choices":[{"finish_reason":"stop"}]}`)))
+}
+
+func TestBuildAnthropicContinuationRequest(t *testing.T) {
+	req := []byte(`{"model":"claude-sonnet-4-6","max_tokens":16,"messages":[{"role":"user","content":"count"}]}`)
+
+	contBody, prefill, ok := buildAnthropicContinuationRequest(req, []byte(`{"content":[{"type":"text","text":"one two \n"}],"stop_reason":"max_tokens"}`))
+	require.True(t, ok)
+	require.Equal(t, "one two", prefill)
+	last := gjson.GetBytes(contBody, "messages.1")
+	require.Equal(t, "assistant", last.Get("role").String())
+	require.Equal(t, "one two", last.Get("content.0.text").String())
+	require.Equal(t, int64(16), gjson.GetBytes(contBody, "max_tokens").Int())
+
+	// tool_use / thinking 输出无法以预填充续写
+	_, _, ok = buildAnthropicContinuationRequest(req, []byte(`{"content":[{"type":"thinking","thinking":"..."},{"type":"text","text":"a"}]}`))
+	require.False(t, ok)
+	// 客户端自己预填充了 assistant 消息
+	_, _, ok = buildAnthropicContinuationRequest(
+		[]byte(`{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"{"}]}`),
+		[]byte(`{"content":[{"type":"text","text":"\"a\":"}]}`))
+	require.False(t, ok)
+}
+
+func TestHandleNonStreamingResponse_AutoContinuationStitchesOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	svc := &GatewayService{cfg: &config.Config{}, rateLimitService: &RateLimitService{}}
+	group := &Group{ID: 1, AutoContinuation: GroupAutoContinuationConfig{Enabled: true}}
+	reqBody := []byte(`{"model":"claude-sonnet-4-6","max_tokens":4,"messages":[{"role":"user","content":"count"}]}`)
+
+	var sent [][]byte
+	continuations := []string{
+		`{"content":[{"type":"text","text":" three four"}],"stop_reason":"max_tokens","stop_sequence":null,"usage":{"input_tokens":12,"output_tokens":4}}`,
+		`{"content":[{"type":"text","text":" five."}],"stop_reason":"end_turn","stop_sequence":null,"usage":{"input_tokens":14,"output_tokens":2,"cache_read_input_tokens":8}}`,
+	}
+	continuation := svc.newAnthropicContinuation(group, reqBody, func(_ context.Context, body []byte) (*http.Response, error) {
+		sent = append(sent, body)
+		return jsonResponse(continuations[len(sent)-1]), nil
+	})
+	before := autoContinuationStats.completed.Load()
+
+	resp := jsonResponse(`{"id":"msg_1","type":"message","content":[{"type":"text","text":"one two "}],"stop_reason":"max_tokens","stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":4}}`)
+	usage, err := svc.handleNonStreamingResponseWithContinuation(context.Background(), resp, c, &Account{ID: 1}, "claude-sonnet-4-6", "claude-sonnet-4-6", continuation)
+
+	require.NoError(t, err)
+	require.Len(t, sent, 2)
+	require.Equal(t, "one two", gjson.GetBytes(sent[0], "messages.1.content.0.text").String())
+	require.Equal(t, "one two three four", gjson.GetBytes(sent[1], "messages.1.content.0.text").String())
+
+	out := rec.Body.Bytes()
+	require.Equal(t, "one two three four five.", gjson.GetBytes(out, "content.0.text").String())
+	require.Equal(t, int64(1), gjson.GetBytes(out, "content.#").Int())
+	require.Equal(t, "end_turn", gjson.GetBytes(out, "stop_reason").String())
+	require.Equal(t, 36, usage.InputTokens)
+	require.Equal(t, 10, usage.OutputTokens)
+	require.Equal(t, 8, usage.CacheReadInputTokens)
+	require.Equal(t, int64(36), gjson.GetBytes(out, "usage.input_tokens").Int())
+	require.Equal(t, before+1, autoContinuationStats.completed.Load())
+}
+
+func TestHandleNonStreamingResponse_AutoContinuationFailureKeepsOriginal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	svc := &GatewayService{cfg: &config.Config{}, rateLimitService: &RateLimitService{}}
+	group := &Group{ID: 1, AutoContinuation: GroupAutoContinuationConfig{Enabled: true, MaxContinuations: 1}}
+	reqBody := []byte(`{"model":"claude-sonnet-4-6","messages":[{"role":"user","content":"count"}]}`)
+	continuation := svc.newAnthropicContinuation(group, reqBody, func(context.Context, []byte) (*http.Response, error) {
+		return nil, errors.New("dial tcp: timeout")
+	})
+	before := autoContinuationStats.failed.Load()
+
+	resp := jsonResponse(`{"content":[{"type":"text","text":"one two"}],"stop_reason":"max_tokens","usage":{"input_tokens":10,"output_tokens":4}}`)
+	usage, err := svc.handleNonStreamingResponseWithContinuation(context.Background(), resp, c, &Account{ID: 1}, "claude-sonnet-4-6", "claude-sonnet-4-6", continuation)
+
+	require.NoError(t, err)
+	require.Equal(t, 10, usage.InputTokens)
+	require.Equal(t, "one two", gjson.GetBytes(rec.Body.Bytes(), "content.0.text").String())
+	require.Equal(t, "max_tokens", gjson.GetBytes(rec.Body.Bytes(), "stop_reason").String())
+	require.Equal(t, before+1, autoContinuationStats.failed.Load())
+}
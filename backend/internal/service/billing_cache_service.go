@@ -113,6 +113,7 @@ type BillingCacheService struct {
 	cfg                   *config.Config
 	circuitBreaker        *billingCircuitBreaker
 	userPlatformQuotaRepo UserPlatformQuotaRepository
+	spendBudgetService    *SpendBudgetService
 
 	cacheWriteChan     chan cacheWriteTask
 	cacheWriteWg       sync.WaitGroup
@@ -154,6 +155,11 @@ func NewBillingCacheService(
 	return svc
 }
 
+// SetSpendBudgetService 注入消费预算服务，启用用户/分组/API Key 周期预算的请求前拦截。
+func (s *BillingCacheService) SetSpendBudgetService(spendBudgetService *SpendBudgetService) {
+	s.spendBudgetService = spendBudgetService
+}
+
 // Stop 关闭缓存写入工作池
 func (s *BillingCacheService) Stop() {
 	s.cacheWriteStopOnce.Do(func() {
@@ -768,6 +774,21 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 		}
 	}
 
+	// 消费预算（用户 / 分组 / API Key 的日、周、月上限）对两种计费模式都生效
+	if s.spendBudgetService != nil && user != nil {
+		var apiKeyID int64
+		var groupID *int64
+		if apiKey != nil {
+			apiKeyID = apiKey.ID
+		}
+		if group != nil {
+			groupID = &group.ID
+		}
+		if err := s.spendBudgetService.CheckEligibility(ctx, user.ID, groupID, apiKeyID); err != nil {
+			return err
+		}
+	}
+
 	// RPM 限流：级联回落（Override → Group → User），放在最后以避免为注定失败的请求增加计数。
 	if err := s.checkRPM(ctx, user, group); err != nil {
 		return err
@@ -132,7 +132,8 @@ func serviceTierCostMultiplier(serviceTier string) float64 {
 	switch normalizeBillingServiceTier(serviceTier) {
 	case "priority":
 		return 2.0
-	case "flex":
+	case "flex", "batch":
+		// Batch API 与 flex 同为半价
 		return 0.5
 	default:
 		return 1.0
@@ -173,6 +174,9 @@ type BillingService struct {
 	pricingService *PricingService
 	fallbackPrices map[string]*ModelPricing // 硬编码回退价格
 
+	// modelPriceService 管理员维护的模型价格表，命中时优先于 LiteLLM 与回退价格（可选）
+	modelPriceService *ModelPriceService
+
 	// fallbackWarnSeen 记录已打过 fallback 警告日志的(已小写化)模型名,
 	// 让 "[Billing] Using fallback pricing" 每个模型每进程最多打一条,
 	// 避免热路径上每请求刷屏(issue #3394)。零值即可用,无需在构造函数初始化。
@@ -193,6 +197,23 @@ func NewBillingService(cfg *config.Config, pricingService *PricingService) *Bill
 	return s
 }
 
+// SetModelPriceService 注入模型价格表（可选）
+func (s *BillingService) SetModelPriceService(svc *ModelPriceService) {
+	s.modelPriceService = svc
+}
+
+// modelPriceOverride 返回价格表中当前生效的定价，未配置时返回 nil
+func (s *BillingService) modelPriceOverride(model string) *ModelPricing {
+	if s.modelPriceService == nil {
+		return nil
+	}
+	pricing := s.modelPriceService.PricingAt(context.Background(), model, time.Now())
+	if pricing == nil {
+		return nil
+	}
+	return s.applyModelSpecificPricingPolicy(strings.ToLower(model), pricing)
+}
+
 // initFallbackPricing 初始化硬编码回退价格（当动态价格不可用时使用）
 // 价格单位：USD per token（与LiteLLM格式一致）
 func (s *BillingService) initFallbackPricing() {
@@ -759,6 +780,11 @@ func (s *BillingService) GetModelPricing(model string) (*ModelPricing, error) {
 	// 标准化模型名称（转小写）
 	model = strings.ToLower(model)
 
+	// 0. 管理员价格表优先
+	if pricing := s.modelPriceOverride(model); pricing != nil {
+		return pricing, nil
+	}
+
 	// 1. 优先从动态价格服务获取
 	if s.pricingService != nil {
 		litellmPricing := s.pricingService.GetModelPricing(model)
@@ -863,7 +889,7 @@ type CostInput struct {
 	RequestCount   int    // 按次计费时使用
 	SizeTier       string // 按次/图片模式的层级标签（"1K","2K","4K","HD" 等）
 	RateMultiplier float64
-	ServiceTier    string                // "priority","flex","" 等
+	ServiceTier    string                // "priority","flex","batch","" 等
 	Resolver       *ModelPricingResolver // 定价解析器
 	Resolved       *ResolvedPricing      // 可选：预解析的定价结果（避免重复 Resolve 调用）
 }
@@ -1320,6 +1346,10 @@ const (
 	defaultGrokImagineVideo15Price480P  = 0.08
 	defaultGrokImagineVideo15Price720P  = 0.14
 	defaultGrokImagineVideo15Price1080P = 0.25
+
+	// 音频默认价取 OpenAI 官方价：whisper-1 $0.006/分钟，tts-1 $15/百万字符。
+	defaultAudioTranscriptionPricePerSecond = 0.0001
+	defaultAudioSpeechPricePerCharacter     = 0.000015
 )
 
 // CalculateImageCost 计算图片生成费用
@@ -1382,6 +1412,47 @@ func (s *BillingService) CalculateVideoCost(model string, resolution string, vid
 	}
 }
 
+// CalculateAudioCost 计算音频费用：转写按输入音频秒数计费，语音合成按输入字符数计费。
+// model: 请求的模型名称（用于获取 LiteLLM 默认价格）
+// durationSeconds: 转写音频时长（秒），语音合成传 0
+// characters: 语音合成输入字符数，转写传 0
+// rateMultiplier: 费率倍数
+func (s *BillingService) CalculateAudioCost(model string, durationSeconds float64, characters int, rateMultiplier float64) *CostBreakdown {
+	if durationSeconds <= 0 && characters <= 0 {
+		return &CostBreakdown{BillingMode: string(BillingModeAudio)}
+	}
+	var pricing *LiteLLMModelPricing
+	if s.pricingService != nil {
+		pricing = s.pricingService.GetModelPricing(model)
+	}
+
+	totalCost := 0.0
+	if durationSeconds > 0 {
+		perSecond := defaultAudioTranscriptionPricePerSecond
+		if pricing != nil && pricing.InputCostPerSecond > 0 {
+			perSecond = pricing.InputCostPerSecond
+		}
+		totalCost += perSecond * durationSeconds
+	}
+	if characters > 0 {
+		perCharacter := defaultAudioSpeechPricePerCharacter
+		if pricing != nil && pricing.InputCostPerCharacter > 0 {
+			perCharacter = pricing.InputCostPerCharacter
+		}
+		totalCost += perCharacter * float64(characters)
+	}
+
+	if rateMultiplier < 0 {
+		rateMultiplier = 0
+	}
+	return &CostBreakdown{
+		InputCost:   totalCost,
+		TotalCost:   totalCost,
+		ActualCost:  totalCost * rateMultiplier,
+		BillingMode: string(BillingModeAudio),
+	}
+}
+
 // getImageUnitPrice 获取图片单价
 func (s *BillingService) getImageUnitPrice(model string, imageSize string, groupConfig *ImagePriceConfig) float64 {
 	// 优先使用分组配置的价格
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/payment/provider"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/google/uuid"
+)
+
+// 账单状态
+const (
+	BillingStatementStatusIssued   = "issued"   // 已生成，尚未推送 Stripe
+	BillingStatementStatusInvoiced = "invoiced" // 已生成 Stripe Invoice，等待付款
+	BillingStatementStatusReported = "reported" // 已上报 Stripe Billing Meter（metered 模式）
+	BillingStatementStatusPaid     = "paid"
+	BillingStatementStatusVoid     = "void"
+)
+
+// BillingStatementPeriodLayout 账单周期（自然月）的字符串格式。
+const BillingStatementPeriodLayout = "2006-01"
+
+const (
+	billingStatementTickInterval = time.Hour
+	billingStatementRunTimeout   = 10 * time.Minute
+	billingStatementPushBatch    = 50
+
+	billingStatementLeaderLockKey = "billing:statement:leader"
+	billingStatementLeaderLockTTL = 15 * time.Minute
+)
+
+var (
+	ErrBillingStatementNotFound      = infraerrors.NotFound("BILLING_STATEMENT_NOT_FOUND", "billing statement not found")
+	ErrBillingStatementInvalidPeriod = infraerrors.BadRequest("BILLING_STATEMENT_INVALID_PERIOD", "period must be a past month in YYYY-MM format")
+	ErrBillingStatementNotPushable   = infraerrors.Conflict("BILLING_STATEMENT_NOT_PUSHABLE", "only issued statements can be pushed or voided")
+	ErrBillingStripeDisabled         = infraerrors.BadRequest("BILLING_STRIPE_DISABLED", "stripe billing is not enabled")
+	ErrBillingStripePushFailed       = infraerrors.ServiceUnavailable("BILLING_STRIPE_PUSH_FAILED", "failed to push statement to stripe")
+)
+
+// BillingStatementLineItem 账单中按模型拆分的明细。
+type BillingStatementLineItem struct {
+	Model               string  `json:"model"`
+	Requests            int64   `json:"requests"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalCost           float64 `json:"total_cost"`
+	ActualCost          float64 `json:"actual_cost"`
+}
+
+// BillingStatement 用户月度账单。金额来自计价引擎在请求时落账的 total_cost / actual_cost。
+type BillingStatement struct {
+	ID               int64                      `json:"id"`
+	UserID           int64                      `json:"user_id"`
+	PeriodStart      time.Time                  `json:"period_start"`
+	PeriodEnd        time.Time                  `json:"period_end"`
+	TotalRequests    int64                      `json:"total_requests"`
+	TotalTokens      int64                      `json:"total_tokens"`
+	TotalCost        float64                    `json:"total_cost"`
+	ActualCost       float64                    `json:"actual_cost"`
+	LineItems        []BillingStatementLineItem `json:"line_items"`
+	Status           string                     `json:"status"`
+	StripeInvoiceID  string                     `json:"stripe_invoice_id,omitempty"`
+	StripeInvoiceURL string                     `json:"stripe_invoice_url,omitempty"`
+	StripeError      string                     `json:"stripe_error,omitempty"`
+	CreatedAt        time.Time                  `json:"created_at"`
+	UpdatedAt        time.Time                  `json:"updated_at"`
+}
+
+// BillingStatementFilter 账单列表筛选条件，零值表示不过滤。
+type BillingStatementFilter struct {
+	UserID      int64
+	PeriodStart *time.Time
+	Status      string
+}
+
+// BillingStatementRepository 账单存储。
+type BillingStatementRepository interface {
+	// GenerateForPeriod 汇总 [start, end) 的 usage_logs 为每个有用量的用户生成账单；已存在的账单保持不变。
+	// 返回新生成的账单数。
+	GenerateForPeriod(ctx context.Context, start, end time.Time) (int, error)
+	GetByID(ctx context.Context, id int64) (*BillingStatement, error)
+	List(ctx context.Context, params pagination.PaginationParams, filter BillingStatementFilter) ([]*BillingStatement, *pagination.PaginationResult, error)
+	// ListPendingPush 返回 issued 且 actual_cost >= minAmount 的账单，最久未更新的优先。
+	ListPendingPush(ctx context.Context, minAmount float64, limit int) ([]*BillingStatement, error)
+	UpdateStripeResult(ctx context.Context, id int64, status, invoiceID, invoiceURL, stripeErr string) error
+	UpdateStatus(ctx context.Context, id int64, status string) error
+	GetStripeCustomerID(ctx context.Context, userID int64) (string, error)
+	SaveStripeCustomerID(ctx context.Context, userID int64, customerID string) error
+}
+
+// BillingStripeClient 账单推送 Stripe 所需的最小接口，由 provider.StripeBilling 实现。
+type BillingStripeClient interface {
+	Currency() string
+	CreateCustomer(ctx context.Context, req provider.StripeCustomerRequest) (string, error)
+	CreateInvoice(ctx context.Context, req provider.StripeInvoiceRequest) (*provider.StripeInvoiceResult, error)
+	ReportMeterEvent(ctx context.Context, req provider.StripeMeterEventRequest) error
+	ParseInvoiceEvent(payload []byte, signature string) (*provider.StripeInvoiceEvent, error)
+}
+
+// BillingStatementService 生成月度账单并（可选）推送到 Stripe 自动收费。
+type BillingStatementService struct {
+	repo     BillingStatementRepository
+	userRepo UserRepository
+	stripe   BillingStripeClient
+	cfg      config.BillingStatementConfig
+	now      func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+}
+
+// NewBillingStatementService 创建账单服务。stripe 为 nil 时仅生成账单、不收费。
+func NewBillingStatementService(repo BillingStatementRepository, userRepo UserRepository, stripe BillingStripeClient, cfg *config.Config) *BillingStatementService {
+	s := &BillingStatementService{
+		repo:       repo,
+		userRepo:   userRepo,
+		stripe:     stripe,
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+		instanceID: uuid.NewString(),
+	}
+	if cfg != nil {
+		s.cfg = cfg.Billing.Statements
+	}
+	return s
+}
+
+// SetLeaderLock 注入多实例选主所用的锁缓存与 DB；均为 nil 时不做门控（单实例/测试）。
+func (s *BillingStatementService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// StripeEnabled 是否启用了 Stripe 自动收费。
+func (s *BillingStatementService) StripeEnabled() bool {
+	return s != nil && s.stripe != nil && s.cfg.Stripe.Enabled
+}
+
+// ParseBillingStatementPeriod 解析 YYYY-MM 为全局时区下该月的 [start, end)。
+func ParseBillingStatementPeriod(period string) (time.Time, time.Time, error) {
+	t, err := timezone.ParseInLocation(BillingStatementPeriodLayout, strings.TrimSpace(period))
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrBillingStatementInvalidPeriod
+	}
+	start := timezone.StartOfMonth(t)
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// Generate 生成指定月份的账单（幂等），只允许已结束的月份。
+func (s *BillingStatementService) Generate(ctx context.Context, period string) (int, error) {
+	start, end, err := ParseBillingStatementPeriod(period)
+	if err != nil {
+		return 0, err
+	}
+	if end.After(s.now()) {
+		return 0, ErrBillingStatementInvalidPeriod
+	}
+	created, err := s.repo.GenerateForPeriod(ctx, start, end)
+	if err != nil {
+		return 0, err
+	}
+	if created > 0 {
+		slog.Info("[BillingStatement] statements generated", "period", start.Format(BillingStatementPeriodLayout), "count", created)
+	}
+	return created, nil
+}
+
+// List 管理员查询账单列表。
+func (s *BillingStatementService) List(ctx context.Context, params pagination.PaginationParams, filter BillingStatementFilter) ([]*BillingStatement, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, params, filter)
+}
+
+// Get 管理员查询单张账单。
+func (s *BillingStatementService) Get(ctx context.Context, id int64) (*BillingStatement, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListForUser 用户查询自己的账单。
+func (s *BillingStatementService) ListForUser(ctx context.Context, userID int64, params pagination.PaginationParams) ([]*BillingStatement, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, params, BillingStatementFilter{UserID: userID})
+}
+
+// GetForUser 用户查询自己的单张账单；不属于该用户时按不存在处理。
+func (s *BillingStatementService) GetForUser(ctx context.Context, userID, id int64) (*BillingStatement, error) {
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.UserID != userID {
+		return nil, ErrBillingStatementNotFound
+	}
+	return st, nil
+}
+
+// Void 作废尚未推送的账单。已推送到 Stripe 的账单需在 Stripe 侧作废，由 webhook 回写状态。
+func (s *BillingStatementService) Void(ctx context.Context, id int64) (*BillingStatement, error) {
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != BillingStatementStatusIssued {
+		return nil, ErrBillingStatementNotPushable
+	}
+	if err := s.repo.UpdateStatus(ctx, id, BillingStatementStatusVoid); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// PushToStripe 手动推送（或重试）单张账单到 Stripe。
+func (s *BillingStatementService) PushToStripe(ctx context.Context, id int64) (*BillingStatement, error) {
+	if !s.StripeEnabled() {
+		return nil, ErrBillingStripeDisabled
+	}
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != BillingStatementStatusIssued {
+		return nil, ErrBillingStatementNotPushable
+	}
+	if err := s.push(ctx, st); err != nil {
+		return nil, ErrBillingStripePushFailed.WithCause(err).WithMetadata(map[string]string{"detail": err.Error()})
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// push 推送一张账单；失败时记录 stripe_error 并保持 issued，等待下一轮重试。
+// Stripe 侧所有写操作都带账单维度的幂等键，重试不会重复收费。
+func (s *BillingStatementService) push(ctx context.Context, st *BillingStatement) error {
+	err := s.doPush(ctx, st)
+	if err != nil {
+		if updateErr := s.repo.UpdateStripeResult(ctx, st.ID, BillingStatementStatusIssued, "", "", truncateString(err.Error(), 500)); updateErr != nil {
+			slog.Warn("[BillingStatement] record stripe error failed", "statement_id", st.ID, "error", updateErr)
+		}
+	}
+	return err
+}
+
+func (s *BillingStatementService) doPush(ctx context.Context, st *BillingStatement) error {
+	customerID, err := s.ensureStripeCustomer(ctx, st.UserID)
+	if err != nil {
+		return err
+	}
+	amount := payment.FormatAmountForCurrency(st.ActualCost, s.stripe.Currency())
+	period := st.PeriodStart.In(timezone.Location()).Format(BillingStatementPeriodLayout)
+
+	if s.cfg.Stripe.Mode == config.BillingStripeModeMetered {
+		if err := s.stripe.ReportMeterEvent(ctx, provider.StripeMeterEventRequest{
+			StatementID: st.ID,
+			CustomerID:  customerID,
+			EventName:   s.cfg.Stripe.MeterEventName,
+			Amount:      amount,
+		}); err != nil {
+			return err
+		}
+		return s.repo.UpdateStripeResult(ctx, st.ID, BillingStatementStatusReported, "", "", "")
+	}
+
+	res, err := s.stripe.CreateInvoice(ctx, provider.StripeInvoiceRequest{
+		StatementID:  st.ID,
+		CustomerID:   customerID,
+		Amount:       amount,
+		Description:  fmt.Sprintf("API usage %s (statement #%d)", period, st.ID),
+		DaysUntilDue: s.cfg.Stripe.DaysUntilDue,
+	})
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateStripeResult(ctx, st.ID, BillingStatementStatusInvoiced, res.InvoiceID, res.HostedURL, "")
+}
+
+func (s *BillingStatementService) ensureStripeCustomer(ctx context.Context, userID int64) (string, error) {
+	customerID, err := s.repo.GetStripeCustomerID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if customerID != "" {
+		return customerID, nil
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	customerID, err = s.stripe.CreateCustomer(ctx, provider.StripeCustomerRequest{
+		UserID: user.ID,
+		Email:  user.Email,
+		Name:   user.Username,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.SaveStripeCustomerID(ctx, userID, customerID); err != nil {
+		return "", err
+	}
+	return customerID, nil
+}
+
+// HandleStripeWebhook 处理 Stripe invoice 事件，回写账单付款/作废状态。
+func (s *BillingStatementService) HandleStripeWebhook(ctx context.Context, payload []byte, signature string) error {
+	if !s.StripeEnabled() {
+		return ErrBillingStripeDisabled
+	}
+	event, err := s.stripe.ParseInvoiceEvent(payload, signature)
+	if err != nil {
+		return infraerrors.BadRequest("BILLING_STRIPE_WEBHOOK_INVALID", "invalid stripe webhook").WithCause(err)
+	}
+	if event == nil || event.StatementID <= 0 {
+		return nil
+	}
+	st, err := s.repo.GetByID(ctx, event.StatementID)
+	if err != nil {
+		if infraerrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	// 只接受与本地记录一致的 invoice，防止伪造 metadata 串改其他账单
+	if st.StripeInvoiceID == "" || st.StripeInvoiceID != event.InvoiceID {
+		return nil
+	}
+	var status string
+	switch event.Type {
+	case provider.StripeEventInvoicePaid:
+		status = BillingStatementStatusPaid
+	case provider.StripeEventInvoiceVoided:
+		status = BillingStatementStatusVoid
+	default:
+		return nil
+	}
+	if st.Status == status {
+		return nil
+	}
+	return s.repo.UpdateStatus(ctx, st.ID, status)
+}
+
+// Start 启动后台任务（每小时）：到达 generate_day 后生成上月账单，并推送待收费账单。
+func (s *BillingStatementService) Start() {
+	if s == nil || s.repo == nil || (!s.cfg.Enabled && !s.StripeEnabled()) {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(billingStatementTickInterval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台任务。
+func (s *BillingStatementService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *BillingStatementService) runOnce() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, billingStatementLeaderLockKey, s.instanceID, billingStatementLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), billingStatementRunTimeout)
+	defer cancel()
+
+	if s.cfg.Enabled {
+		if _, err := s.generateDue(ctx); err != nil {
+			slog.Warn("[BillingStatement] generate failed", "error", err)
+		}
+	}
+	if s.StripeEnabled() {
+		pushed, failed := s.pushPending(ctx)
+		if pushed > 0 || failed > 0 {
+			slog.Info("[BillingStatement] stripe push finished", "pushed", pushed, "failed", failed)
+		}
+	}
+}
+
+// generateDue 每月 generate_day 起生成上月账单；GenerateForPeriod 幂等，重复执行无副作用。
+func (s *BillingStatementService) generateDue(ctx context.Context) (int, error) {
+	now := s.now().In(timezone.Location())
+	if now.Day() < s.cfg.GenerateDay {
+		return 0, nil
+	}
+	end := timezone.StartOfMonth(now)
+	return s.Generate(ctx, end.AddDate(0, -1, 0).Format(BillingStatementPeriodLayout))
+}
+
+func (s *BillingStatementService) pushPending(ctx context.Context) (pushed, failed int) {
+	// 不足最小货币单位的金额无法收费
+	minAmount := s.cfg.MinAmount
+	if minAmount < 0.01 {
+		minAmount = 0.01
+	}
+	statements, err := s.repo.ListPendingPush(ctx, minAmount, billingStatementPushBatch)
+	if err != nil {
+		slog.Warn("[BillingStatement] list pending push failed", "error", err)
+		return 0, 0
+	}
+	for _, st := range statements {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.push(ctx, st); err != nil {
+			failed++
+			slog.Warn("[BillingStatement] stripe push failed", "statement_id", st.ID, "user_id", st.UserID, "error", err)
+			continue
+		}
+		pushed++
+	}
+	return pushed, failed
+}
@@ -0,0 +1,263 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/payment/provider"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+type billingStatementRepoFake struct {
+	statements  map[int64]*BillingStatement
+	customers   map[int64]string
+	generated   [][2]time.Time
+	pendingMins []float64
+}
+
+func newBillingStatementRepoFake(statements ...*BillingStatement) *billingStatementRepoFake {
+	f := &billingStatementRepoFake{statements: map[int64]*BillingStatement{}, customers: map[int64]string{}}
+	for _, st := range statements {
+		f.statements[st.ID] = st
+	}
+	return f
+}
+
+func (f *billingStatementRepoFake) GenerateForPeriod(_ context.Context, start, end time.Time) (int, error) {
+	f.generated = append(f.generated, [2]time.Time{start, end})
+	return 1, nil
+}
+
+func (f *billingStatementRepoFake) GetByID(_ context.Context, id int64) (*BillingStatement, error) {
+	st, ok := f.statements[id]
+	if !ok {
+		return nil, ErrBillingStatementNotFound
+	}
+	clone := *st
+	return &clone, nil
+}
+
+func (f *billingStatementRepoFake) List(_ context.Context, params pagination.PaginationParams, filter BillingStatementFilter) ([]*BillingStatement, *pagination.PaginationResult, error) {
+	var out []*BillingStatement
+	for _, st := range f.statements {
+		if filter.UserID == 0 || st.UserID == filter.UserID {
+			clone := *st
+			out = append(out, &clone)
+		}
+	}
+	return out, &pagination.PaginationResult{Total: int64(len(out)), Page: params.Page, PageSize: params.PageSize}, nil
+}
+
+func (f *billingStatementRepoFake) ListPendingPush(_ context.Context, minAmount float64, _ int) ([]*BillingStatement, error) {
+	f.pendingMins = append(f.pendingMins, minAmount)
+	var out []*BillingStatement
+	for _, st := range f.statements {
+		if st.Status == BillingStatementStatusIssued && st.ActualCost >= minAmount {
+			clone := *st
+			out = append(out, &clone)
+		}
+	}
+	return out, nil
+}
+
+func (f *billingStatementRepoFake) UpdateStripeResult(_ context.Context, id int64, status, invoiceID, invoiceURL, stripeErr string) error {
+	st := f.statements[id]
+	st.Status, st.StripeInvoiceID, st.StripeInvoiceURL, st.StripeError = status, invoiceID, invoiceURL, stripeErr
+	return nil
+}
+
+func (f *billingStatementRepoFake) UpdateStatus(_ context.Context, id int64, status string) error {
+	f.statements[id].Status = status
+	return nil
+}
+
+func (f *billingStatementRepoFake) GetStripeCustomerID(_ context.Context, userID int64) (string, error) {
+	return f.customers[userID], nil
+}
+
+func (f *billingStatementRepoFake) SaveStripeCustomerID(_ context.Context, userID int64, customerID string) error {
+	f.customers[userID] = customerID
+	return nil
+}
+
+type billingStripeClientFake struct {
+	customers  []provider.StripeCustomerRequest
+	invoices   []provider.StripeInvoiceRequest
+	meters     []provider.StripeMeterEventRequest
+	invoiceErr error
+	event      *provider.StripeInvoiceEvent
+}
+
+func (f *billingStripeClientFake) Currency() string { return "USD" }
+
+func (f *billingStripeClientFake) CreateCustomer(_ context.Context, req provider.StripeCustomerRequest) (string, error) {
+	f.customers = append(f.customers, req)
+	return "cus_1", nil
+}
+
+func (f *billingStripeClientFake) CreateInvoice(_ context.Context, req provider.StripeInvoiceRequest) (*provider.StripeInvoiceResult, error) {
+	f.invoices = append(f.invoices, req)
+	if f.invoiceErr != nil {
+		return nil, f.invoiceErr
+	}
+	return &provider.StripeInvoiceResult{InvoiceID: "in_1", HostedURL: "https://invoice.stripe.com/i/1"}, nil
+}
+
+func (f *billingStripeClientFake) ReportMeterEvent(_ context.Context, req provider.StripeMeterEventRequest) error {
+	f.meters = append(f.meters, req)
+	return nil
+}
+
+func (f *billingStripeClientFake) ParseInvoiceEvent([]byte, string) (*provider.StripeInvoiceEvent, error) {
+	return f.event, nil
+}
+
+func newBillingStatementServiceForTest(repo *billingStatementRepoFake, stripe BillingStripeClient, mode string) *BillingStatementService {
+	cfg := &config.Config{}
+	cfg.Billing.Statements = config.BillingStatementConfig{
+		Enabled:     true,
+		GenerateDay: 2,
+		MinAmount:   0.5,
+		Stripe: config.BillingStripeConfig{
+			Enabled:        stripe != nil,
+			Mode:           mode,
+			MeterEventName: "sub2api_usage",
+		},
+	}
+	return NewBillingStatementService(repo, &userRepoStub{user: &User{ID: 7, Email: "u@example.com", Username: "u"}}, stripe, cfg)
+}
+
+func issuedStatement(id int64, cost float64) *BillingStatement {
+	return &BillingStatement{
+		ID: id, UserID: 7, Status: BillingStatementStatusIssued, ActualCost: cost,
+		PeriodStart: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBillingStatementService_GenerateRejectsOpenPeriod(t *testing.T) {
+	repo := newBillingStatementRepoFake()
+	svc := newBillingStatementServiceForTest(repo, nil, "")
+	svc.now = func() time.Time { return time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	_, err := svc.Generate(ctx, "2026-10")
+	require.ErrorIs(t, err, ErrBillingStatementInvalidPeriod)
+	_, err = svc.Generate(ctx, "2026/09")
+	require.ErrorIs(t, err, ErrBillingStatementInvalidPeriod)
+
+	created, err := svc.Generate(ctx, "2026-09")
+	require.NoError(t, err)
+	require.Equal(t, 1, created)
+	require.Len(t, repo.generated, 1)
+	require.Equal(t, repo.generated[0][0].AddDate(0, 1, 0), repo.generated[0][1])
+}
+
+func TestBillingStatementService_GenerateDueWaitsForGenerateDay(t *testing.T) {
+	repo := newBillingStatementRepoFake()
+	svc := newBillingStatementServiceForTest(repo, nil, "")
+	ctx := context.Background()
+
+	svc.now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC) }
+	_, err := svc.generateDue(ctx)
+	require.NoError(t, err)
+	require.Empty(t, repo.generated)
+
+	svc.now = func() time.Time { return time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC) }
+	_, err = svc.generateDue(ctx)
+	require.NoError(t, err)
+	require.Len(t, repo.generated, 1)
+	require.Equal(t, 9, int(repo.generated[0][0].Month()))
+}
+
+func TestBillingStatementService_PushInvoiceCreatesCustomerOnce(t *testing.T) {
+	repo := newBillingStatementRepoFake(issuedStatement(1, 12.345), issuedStatement(2, 0.2))
+	stripe := &billingStripeClientFake{}
+	svc := newBillingStatementServiceForTest(repo, stripe, config.BillingStripeModeInvoice)
+
+	pushed, failed := svc.pushPending(context.Background())
+	require.Equal(t, 1, pushed)
+	require.Equal(t, 0, failed)
+	require.Len(t, stripe.customers, 1)
+	require.Equal(t, "cus_1", repo.customers[7])
+	require.Len(t, stripe.invoices, 1)
+	require.Equal(t, "12.35", stripe.invoices[0].Amount)
+	require.Equal(t, BillingStatementStatusInvoiced, repo.statements[1].Status)
+	require.Equal(t, "in_1", repo.statements[1].StripeInvoiceID)
+	require.Equal(t, BillingStatementStatusIssued, repo.statements[2].Status, "below min_amount stays issued")
+
+	_, err := svc.PushToStripe(context.Background(), 1)
+	require.ErrorIs(t, err, ErrBillingStatementNotPushable)
+}
+
+func TestBillingStatementService_PushFailureRecordsError(t *testing.T) {
+	repo := newBillingStatementRepoFake(issuedStatement(1, 5))
+	stripe := &billingStripeClientFake{invoiceErr: errors.New("card_declined")}
+	svc := newBillingStatementServiceForTest(repo, stripe, config.BillingStripeModeInvoice)
+
+	_, err := svc.PushToStripe(context.Background(), 1)
+	require.ErrorIs(t, err, ErrBillingStripePushFailed)
+	require.Equal(t, BillingStatementStatusIssued, repo.statements[1].Status)
+	require.Equal(t, "card_declined", repo.statements[1].StripeError)
+}
+
+func TestBillingStatementService_PushMeteredReportsEvent(t *testing.T) {
+	repo := newBillingStatementRepoFake(issuedStatement(1, 3))
+	stripe := &billingStripeClientFake{}
+	svc := newBillingStatementServiceForTest(repo, stripe, config.BillingStripeModeMetered)
+
+	_, err := svc.PushToStripe(context.Background(), 1)
+	require.NoError(t, err)
+	require.Empty(t, stripe.invoices)
+	require.Equal(t, []provider.StripeMeterEventRequest{{StatementID: 1, CustomerID: "cus_1", EventName: "sub2api_usage", Amount: "3.00"}}, stripe.meters)
+	require.Equal(t, BillingStatementStatusReported, repo.statements[1].Status)
+}
+
+func TestBillingStatementService_PushWithoutStripe(t *testing.T) {
+	svc := newBillingStatementServiceForTest(newBillingStatementRepoFake(issuedStatement(1, 3)), nil, "")
+	_, err := svc.PushToStripe(context.Background(), 1)
+	require.ErrorIs(t, err, ErrBillingStripeDisabled)
+}
+
+func TestBillingStatementService_WebhookMarksPaidOnlyForMatchingInvoice(t *testing.T) {
+	st := issuedStatement(1, 5)
+	st.Status = BillingStatementStatusInvoiced
+	st.StripeInvoiceID = "in_1"
+	repo := newBillingStatementRepoFake(st)
+	stripe := &billingStripeClientFake{}
+	svc := newBillingStatementServiceForTest(repo, stripe, config.BillingStripeModeInvoice)
+	ctx := context.Background()
+
+	stripe.event = &provider.StripeInvoiceEvent{Type: provider.StripeEventInvoicePaid, InvoiceID: "in_other", StatementID: 1}
+	require.NoError(t, svc.HandleStripeWebhook(ctx, nil, "sig"))
+	require.Equal(t, BillingStatementStatusInvoiced, repo.statements[1].Status)
+
+	stripe.event = &provider.StripeInvoiceEvent{Type: provider.StripeEventInvoicePaid, InvoiceID: "in_1", StatementID: 1}
+	require.NoError(t, svc.HandleStripeWebhook(ctx, nil, "sig"))
+	require.Equal(t, BillingStatementStatusPaid, repo.statements[1].Status)
+
+	// 未知账单直接确认，避免 Stripe 无限重试
+	stripe.event = &provider.StripeInvoiceEvent{Type: provider.StripeEventInvoicePaid, InvoiceID: "in_9", StatementID: 9}
+	require.NoError(t, svc.HandleStripeWebhook(ctx, nil, "sig"))
+}
+
+func TestBillingStatementService_GetForUserAndVoid(t *testing.T) {
+	repo := newBillingStatementRepoFake(issuedStatement(1, 5))
+	svc := newBillingStatementServiceForTest(repo, nil, "")
+	ctx := context.Background()
+
+	_, err := svc.GetForUser(ctx, 8, 1)
+	require.ErrorIs(t, err, ErrBillingStatementNotFound)
+
+	voided, err := svc.Void(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, BillingStatementStatusVoid, voided.Status)
+
+	_, err = svc.Void(ctx, 1)
+	require.ErrorIs(t, err, ErrBillingStatementNotPushable)
+}
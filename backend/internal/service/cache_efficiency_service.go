@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 缓存效率报表的聚合维度。
+const (
+	CacheEfficiencyDimensionModel   = "model"
+	CacheEfficiencyDimensionAPIKey  = "api_key"
+	CacheEfficiencyDimensionAccount = "account"
+)
+
+const (
+	cacheEfficiencyDefaultDays  = 7
+	cacheEfficiencyMaxDays      = 90
+	cacheEfficiencyDefaultLimit = 50
+	cacheEfficiencyMaxLimit     = 200
+
+	// 只对有足够样本的行给出建议，避免零星请求触发误报。
+	cacheEfficiencyMinRequests     = 20
+	cacheEfficiencyMinPromptTokens = 100_000
+)
+
+// 路由建议代码（前端按代码做本地化）。
+const (
+	CacheSuggestionWritesNotReused = "cache_writes_not_reused"
+	CacheSuggestionNoCacheUsage    = "no_cache_usage"
+	CacheSuggestionBelowAverage    = "below_average"
+)
+
+// CacheEfficiencyRow 单个维度值在统计区间内的用量汇总。
+// usage_logs 中 input_tokens 不含缓存部分，提示词总量 = input + cache_creation + cache_read。
+type CacheEfficiencyRow struct {
+	ID                  int64  `json:"id,omitempty"` // api_key / account 维度的 ID；model 维度为 0
+	Name                string `json:"name"`
+	Requests            int64  `json:"requests"`
+	InputTokens         int64  `json:"input_tokens"`
+	CacheCreationTokens int64  `json:"cache_creation_tokens"`
+	CacheReadTokens     int64  `json:"cache_read_tokens"`
+
+	PromptTokens   int64   `json:"prompt_tokens"`
+	HitRate        float64 `json:"hit_rate"`         // cache_read / prompt
+	CacheWriteRate float64 `json:"cache_write_rate"` // cache_creation / prompt
+}
+
+// CacheEfficiencyRepository 读取缓存效率报表所需的用量聚合。
+type CacheEfficiencyRepository interface {
+	// AggregateCacheUsage 按维度聚合 [start, end) 区间的用量，按提示词总量降序返回前 limit 行。
+	AggregateCacheUsage(ctx context.Context, dimension string, start, end time.Time, limit int) ([]CacheEfficiencyRow, error)
+}
+
+// CacheSuggestion 针对低命中率行的路由建议。
+type CacheSuggestion struct {
+	Code    string `json:"code"`
+	ID      int64  `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// CacheEfficiencyReport 缓存效率报表。
+type CacheEfficiencyReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Dimension   string    `json:"dimension"`
+	Days        int       `json:"days"`
+
+	// 汇总口径为返回的各行之和
+	Requests        int64   `json:"requests"`
+	PromptTokens    int64   `json:"prompt_tokens"`
+	CacheReadTokens int64   `json:"cache_read_tokens"`
+	HitRate         float64 `json:"hit_rate"`
+
+	Rows        []CacheEfficiencyRow `json:"rows"`
+	Suggestions []CacheSuggestion    `json:"suggestions"`
+}
+
+// CacheEfficiencyService 汇总 prompt cache 命中率并给出路由建议。
+type CacheEfficiencyService struct {
+	repo CacheEfficiencyRepository
+	now  func() time.Time
+}
+
+// NewCacheEfficiencyService creates a new CacheEfficiencyService.
+func NewCacheEfficiencyService(repo CacheEfficiencyRepository) *CacheEfficiencyService {
+	return &CacheEfficiencyService{repo: repo, now: time.Now}
+}
+
+// Report 生成最近 days 天按 dimension 聚合的缓存效率报表；参数为 0 时使用默认值。
+func (s *CacheEfficiencyService) Report(ctx context.Context, dimension string, days, limit int) (*CacheEfficiencyReport, error) {
+	dimension = strings.TrimSpace(dimension)
+	if dimension == "" {
+		dimension = CacheEfficiencyDimensionModel
+	}
+	switch dimension {
+	case CacheEfficiencyDimensionModel, CacheEfficiencyDimensionAPIKey, CacheEfficiencyDimensionAccount:
+	default:
+		return nil, infraerrors.BadRequest("INVALID_CACHE_EFFICIENCY_DIMENSION", "dimension must be one of model, api_key, account")
+	}
+	days = clampCapacityDays(days, cacheEfficiencyDefaultDays, 1, cacheEfficiencyMaxDays)
+	if limit <= 0 {
+		limit = cacheEfficiencyDefaultLimit
+	}
+	limit = min(limit, cacheEfficiencyMaxLimit)
+
+	end := s.now().UTC()
+	rows, err := s.repo.AggregateCacheUsage(ctx, dimension, end.AddDate(0, 0, -days), end, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CacheEfficiencyReport{
+		GeneratedAt: end,
+		Dimension:   dimension,
+		Days:        days,
+		Rows:        make([]CacheEfficiencyRow, 0, len(rows)),
+		Suggestions: []CacheSuggestion{},
+	}
+	for _, row := range rows {
+		row.PromptTokens = row.InputTokens + row.CacheCreationTokens + row.CacheReadTokens
+		if row.PromptTokens > 0 {
+			row.HitRate = float64(row.CacheReadTokens) / float64(row.PromptTokens)
+			row.CacheWriteRate = float64(row.CacheCreationTokens) / float64(row.PromptTokens)
+		}
+		report.Requests += row.Requests
+		report.PromptTokens += row.PromptTokens
+		report.CacheReadTokens += row.CacheReadTokens
+		report.Rows = append(report.Rows, row)
+	}
+	if report.PromptTokens > 0 {
+		report.HitRate = float64(report.CacheReadTokens) / float64(report.PromptTokens)
+	}
+	for _, row := range report.Rows {
+		if suggestion, ok := cacheSuggestionFor(dimension, row, report.HitRate); ok {
+			report.Suggestions = append(report.Suggestions, suggestion)
+		}
+	}
+	return report, nil
+}
+
+// cacheSuggestionFor 按命中率与缓存写入占比给出单行建议：
+//   - 缓存写入多但很少被读取：同一会话的请求大概率落到了不同账号，建议开启/延长粘性会话；
+//   - 几乎没有缓存读写：客户端未发送 cache_control 断点或 prompt_cache_key；
+//   - （仅账号维度）命中率远低于整体水平：账号可能被多方共享或粘性会话频繁逃逸。
+func cacheSuggestionFor(dimension string, row CacheEfficiencyRow, overallHitRate float64) (CacheSuggestion, bool) {
+	if row.Requests < cacheEfficiencyMinRequests || row.PromptTokens < cacheEfficiencyMinPromptTokens {
+		return CacheSuggestion{}, false
+	}
+	suggestion := CacheSuggestion{ID: row.ID, Name: row.Name}
+	switch {
+	case row.HitRate < 0.3 && row.CacheWriteRate >= 0.2:
+		suggestion.Code = CacheSuggestionWritesNotReused
+		suggestion.Message = "Prompt cache is written but rarely read: requests of the same conversation are likely routed to different accounts. " +
+			"Enable or lengthen sticky sessions (gateway.sticky_session_ttl_seconds) and avoid spreading this " + cacheDimensionLabel(dimension) + " across many accounts."
+	case row.HitRate < 0.05 && row.CacheWriteRate < 0.05:
+		suggestion.Code = CacheSuggestionNoCacheUsage
+		suggestion.Message = "Almost no prompt caching: the client may not send cache_control breakpoints or prompt_cache_key. " +
+			"For OpenAI OAuth accounts, keep gateway.openai_session_id_strategy at prompt_cache_key or api_key rather than request."
+	case dimension == CacheEfficiencyDimensionAccount && overallHitRate >= 0.2 && row.HitRate < overallHitRate/2:
+		suggestion.Code = CacheSuggestionBelowAverage
+		suggestion.Message = "Hit rate is far below the overall average: check whether this account is shared outside the gateway " +
+			"or whether sticky sessions escape too often (gateway.openai_scheduler.sticky_escape_*)."
+	default:
+		return CacheSuggestion{}, false
+	}
+	return suggestion, true
+}
+
+func cacheDimensionLabel(dimension string) string {
+	switch dimension {
+	case CacheEfficiencyDimensionAPIKey:
+		return "API key"
+	case CacheEfficiencyDimensionAccount:
+		return "account's traffic"
+	}
+	return "model"
+}
@@ -0,0 +1,74 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheEfficiencyRepoStub struct {
+	rows      []CacheEfficiencyRow
+	dimension string
+	start     time.Time
+	end       time.Time
+	limit     int
+}
+
+func (r *cacheEfficiencyRepoStub) AggregateCacheUsage(_ context.Context, dimension string, start, end time.Time, limit int) ([]CacheEfficiencyRow, error) {
+	r.dimension, r.start, r.end, r.limit = dimension, start, end, limit
+	return r.rows, nil
+}
+
+func TestCacheEfficiencyService_Report(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	repo := &cacheEfficiencyRepoStub{rows: []CacheEfficiencyRow{
+		// 良好：80% 命中
+		{ID: 1, Name: "good", Requests: 100, InputTokens: 100_000, CacheCreationTokens: 100_000, CacheReadTokens: 800_000},
+		// 写入多读取少
+		{ID: 2, Name: "churn", Requests: 100, InputTokens: 100_000, CacheCreationTokens: 400_000, CacheReadTokens: 50_000},
+		// 完全没用缓存
+		{ID: 3, Name: "nocache", Requests: 100, InputTokens: 500_000},
+		// 低于整体水平
+		{ID: 4, Name: "shared", Requests: 100, InputTokens: 800_000, CacheCreationTokens: 100_000, CacheReadTokens: 100_000},
+		// 样本不足不给建议
+		{ID: 5, Name: "tiny", Requests: 3, InputTokens: 1_000},
+	}}
+	svc := NewCacheEfficiencyService(repo)
+	svc.now = func() time.Time { return now }
+
+	report, err := svc.Report(context.Background(), CacheEfficiencyDimensionAccount, 0, 500)
+	require.NoError(t, err)
+	require.Equal(t, CacheEfficiencyDimensionAccount, repo.dimension)
+	require.Equal(t, now.AddDate(0, 0, -cacheEfficiencyDefaultDays), repo.start)
+	require.Equal(t, cacheEfficiencyMaxLimit, repo.limit)
+
+	require.Len(t, report.Rows, 5)
+	require.InDelta(t, 0.8, report.Rows[0].HitRate, 1e-9)
+	require.Equal(t, int64(1_000_000), report.Rows[0].PromptTokens)
+	require.InDelta(t, float64(950_000)/float64(3_051_000), report.HitRate, 1e-9)
+
+	codes := map[string]int64{}
+	for _, s := range report.Suggestions {
+		codes[s.Code] = s.ID
+	}
+	require.Equal(t, map[string]int64{
+		CacheSuggestionWritesNotReused: 2,
+		CacheSuggestionNoCacheUsage:    3,
+		CacheSuggestionBelowAverage:    4,
+	}, codes)
+
+	// 非账号维度不给出“低于整体水平”建议
+	report, err = svc.Report(context.Background(), CacheEfficiencyDimensionModel, 7, 10)
+	require.NoError(t, err)
+	for _, s := range report.Suggestions {
+		require.NotEqual(t, CacheSuggestionBelowAverage, s.Code)
+	}
+
+	_, err = svc.Report(context.Background(), "user", 7, 10)
+	require.Equal(t, "INVALID_CACHE_EFFICIENCY_DIMENSION", infraerrors.Reason(err))
+}
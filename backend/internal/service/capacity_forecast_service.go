@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	capacityForecastDefaultLookbackDays = 28
+	capacityForecastMinLookbackDays     = 7
+	capacityForecastMaxLookbackDays     = 90
+	capacityForecastDefaultHorizonDays  = 30
+	capacityForecastMaxHorizonDays      = 180
+
+	capacityForecastDateLayout = "2006-01-02"
+)
+
+// CapacityUsageDay 某一天（UTC）的全站用量，来源于 usage_dashboard_daily 预聚合表。
+type CapacityUsageDay struct {
+	Date        time.Time
+	Tokens      int64
+	AccountCost float64
+}
+
+// CapacityForecastRepository 读取容量预测所需的每日用量汇总。
+type CapacityForecastRepository interface {
+	// ListDailyUsage 返回 [start, end) 区间内的每日用量，按日期升序；无用量的日期可缺失。
+	ListDailyUsage(ctx context.Context, start, end time.Time) ([]CapacityUsageDay, error)
+}
+
+// CapacityForecastDay 历史或预测的单日用量。
+type CapacityForecastDay struct {
+	Date        string  `json:"date"`
+	Tokens      int64   `json:"tokens"`
+	AccountCost float64 `json:"account_cost"`
+}
+
+// CapacityPlatformCaps 单个平台的账号额度汇总。
+type CapacityPlatformCaps struct {
+	Platform          string  `json:"platform"`
+	Accounts          int     `json:"accounts"`
+	CappedAccounts    int     `json:"capped_accounts"`
+	DailyCostCap      float64 `json:"daily_cost_cap"`
+	RemainingQuota    float64 `json:"remaining_quota"`
+	UnlimitedAccounts int     `json:"unlimited_accounts"` // 未配置总额度的账号数
+}
+
+// CapacityForecast 账号容量预测结果。
+//
+// 容量口径：活跃账号配置的日额度（未配置日额度时取周额度 / 7）之和，以及总额度剩余之和；
+// 费用均为账号成本（account_cost，与账号配额扣减口径一致）。token 上限按回溯期平均单 token 成本折算。
+// 未配置任何额度的账号（如 OAuth 订阅号）无法计入容量，数量见 UncappedAccounts，预测结果偏保守。
+type CapacityForecast struct {
+	GeneratedAt  time.Time `json:"generated_at"`
+	LookbackDays int       `json:"lookback_days"`
+	HorizonDays  int       `json:"horizon_days"`
+
+	History    []CapacityForecastDay `json:"history"`
+	Projection []CapacityForecastDay `json:"projection"`
+
+	// 线性趋势：每日增长量
+	DailyTokenGrowth float64 `json:"daily_token_growth"`
+	DailyCostGrowth  float64 `json:"daily_cost_growth"`
+
+	ActiveAccounts   int                    `json:"active_accounts"`
+	UncappedAccounts int                    `json:"uncapped_accounts"`
+	DailyCostCap     float64                `json:"daily_cost_cap"`
+	DailyTokenCap    int64                  `json:"daily_token_cap"`
+	RemainingQuota   float64                `json:"remaining_quota"`
+	Platforms        []CapacityPlatformCaps `json:"platforms"`
+
+	// 预测日用量首次超过日额度上限的日期
+	DailyCapExceededOn *string `json:"daily_cap_exceeded_on,omitempty"`
+	// 预测累计用量耗尽总额度剩余的日期
+	QuotaExhaustedOn *string `json:"quota_exhausted_on,omitempty"`
+	// 距最早耗尽日期的天数（今天为 0）
+	DaysUntilExhausted *int `json:"days_until_exhausted,omitempty"`
+}
+
+// CapacityForecastService 基于历史用量趋势与账号额度配置预测容量耗尽时间。
+type CapacityForecastService struct {
+	repo        CapacityForecastRepository
+	accountRepo AccountRepository
+	now         func() time.Time
+}
+
+// NewCapacityForecastService 创建容量预测服务。
+func NewCapacityForecastService(repo CapacityForecastRepository, accountRepo AccountRepository) *CapacityForecastService {
+	return &CapacityForecastService{repo: repo, accountRepo: accountRepo, now: time.Now}
+}
+
+// Forecast 以最近 lookbackDays 个完整日（UTC）的用量拟合线性趋势，预测未来 horizonDays 天的用量并与账号额度对比。
+// 参数为 0 时使用默认值，超出范围时截断。
+func (s *CapacityForecastService) Forecast(ctx context.Context, lookbackDays, horizonDays int) (*CapacityForecast, error) {
+	lookbackDays = clampCapacityDays(lookbackDays, capacityForecastDefaultLookbackDays, capacityForecastMinLookbackDays, capacityForecastMaxLookbackDays)
+	horizonDays = clampCapacityDays(horizonDays, capacityForecastDefaultHorizonDays, 1, capacityForecastMaxHorizonDays)
+
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := today.AddDate(0, 0, -lookbackDays)
+
+	rows, err := s.repo.ListDailyUsage(ctx, start, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]CapacityUsageDay, len(rows))
+	for _, r := range rows {
+		byDate[r.Date.UTC().Format(capacityForecastDateLayout)] = r
+	}
+
+	out := &CapacityForecast{
+		GeneratedAt:  now,
+		LookbackDays: lookbackDays,
+		HorizonDays:  horizonDays,
+		History:      make([]CapacityForecastDay, 0, lookbackDays),
+		Projection:   make([]CapacityForecastDay, 0, horizonDays),
+	}
+
+	// 今天尚未结束，不参与拟合
+	tokens := make([]float64, 0, lookbackDays)
+	costs := make([]float64, 0, lookbackDays)
+	var sumTokens, sumCost float64
+	for day := start; day.Before(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format(capacityForecastDateLayout)
+		r := byDate[date]
+		out.History = append(out.History, CapacityForecastDay{Date: date, Tokens: r.Tokens, AccountCost: r.AccountCost})
+		tokens = append(tokens, float64(r.Tokens))
+		costs = append(costs, r.AccountCost)
+		sumTokens += float64(r.Tokens)
+		sumCost += r.AccountCost
+	}
+	tokenIntercept, tokenSlope := linearFit(tokens)
+	costIntercept, costSlope := linearFit(costs)
+	out.DailyTokenGrowth = tokenSlope
+	out.DailyCostGrowth = costSlope
+
+	if err := s.fillCapacity(ctx, out); err != nil {
+		return nil, err
+	}
+	if sumTokens > 0 && sumCost > 0 && out.DailyCostCap > 0 {
+		out.DailyTokenCap = int64(out.DailyCostCap / (sumCost / sumTokens))
+	}
+
+	todaySoFar := byDate[today.Format(capacityForecastDateLayout)].AccountCost
+	remaining := out.RemainingQuota
+	exhaustedAt := -1
+	for i := 0; i < horizonDays; i++ {
+		x := float64(lookbackDays + i)
+		day := CapacityForecastDay{
+			Date:        today.AddDate(0, 0, i).Format(capacityForecastDateLayout),
+			Tokens:      int64(math.Round(math.Max(0, tokenIntercept+tokenSlope*x))),
+			AccountCost: math.Max(0, costIntercept+costSlope*x),
+		}
+		out.Projection = append(out.Projection, day)
+
+		if out.DailyCostCap > 0 && out.DailyCapExceededOn == nil && day.AccountCost > out.DailyCostCap {
+			date := day.Date
+			out.DailyCapExceededOn = &date
+			if exhaustedAt < 0 {
+				exhaustedAt = i
+			}
+		}
+		if out.RemainingQuota > 0 && out.QuotaExhaustedOn == nil {
+			spend := day.AccountCost
+			if i == 0 {
+				// 总额度剩余已扣除今天的已用部分
+				spend = math.Max(0, spend-todaySoFar)
+			}
+			remaining -= spend
+			if remaining <= 0 {
+				date := day.Date
+				out.QuotaExhaustedOn = &date
+				if exhaustedAt < 0 || i < exhaustedAt {
+					exhaustedAt = i
+				}
+			}
+		}
+	}
+	if exhaustedAt >= 0 {
+		out.DaysUntilExhausted = &exhaustedAt
+	}
+	return out, nil
+}
+
+func (s *CapacityForecastService) fillCapacity(ctx context.Context, out *CapacityForecast) error {
+	accounts, err := s.accountRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	platforms := make(map[string]*CapacityPlatformCaps)
+	for i := range accounts {
+		a := &accounts[i]
+		if a.IsShadow() {
+			// 影子账号与父账号共享上游额度
+			continue
+		}
+		p := platforms[a.Platform]
+		if p == nil {
+			p = &CapacityPlatformCaps{Platform: a.Platform}
+			platforms[a.Platform] = p
+		}
+		p.Accounts++
+		out.ActiveAccounts++
+
+		dailyCap := a.GetQuotaDailyLimit()
+		if dailyCap <= 0 && a.GetQuotaWeeklyLimit() > 0 {
+			dailyCap = a.GetQuotaWeeklyLimit() / 7
+		}
+		if dailyCap > 0 {
+			p.CappedAccounts++
+			p.DailyCostCap += dailyCap
+		}
+		if limit := a.GetQuotaLimit(); limit > 0 {
+			p.RemainingQuota += math.Max(0, limit-a.GetQuotaUsed())
+		} else {
+			p.UnlimitedAccounts++
+		}
+		if !a.HasAnyQuotaLimit() {
+			out.UncappedAccounts++
+		}
+	}
+
+	out.Platforms = make([]CapacityPlatformCaps, 0, len(platforms))
+	for _, p := range platforms {
+		out.DailyCostCap += p.DailyCostCap
+		out.RemainingQuota += p.RemainingQuota
+		out.Platforms = append(out.Platforms, *p)
+	}
+	sort.Slice(out.Platforms, func(i, j int) bool { return out.Platforms[i].Platform < out.Platforms[j].Platform })
+	return nil
+}
+
+func clampCapacityDays(v, def, minV, maxV int) int {
+	if v <= 0 {
+		return def
+	}
+	if v < minV {
+		return minV
+	}
+	if v > maxV {
+		return maxV
+	}
+	return v
+}
+
+// linearFit 对 y[i]（x = i）做最小二乘线性拟合，返回截距与斜率。
+func linearFit(y []float64) (intercept, slope float64) {
+	n := float64(len(y))
+	if n == 0 {
+		return 0, 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range y {
+		x := float64(i)
+		sumX += x
+		sumY += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return sumY / n, 0
+	}
+	slope = (n*sumXY - sumX*sumY) / denom
+	intercept = (sumY - slope*sumX) / n
+	return intercept, slope
+}
@@ -0,0 +1,118 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type capacityForecastRepoStub struct {
+	days       []CapacityUsageDay
+	start, end time.Time
+}
+
+func (s *capacityForecastRepoStub) ListDailyUsage(_ context.Context, start, end time.Time) ([]CapacityUsageDay, error) {
+	s.start, s.end = start, end
+	return s.days, nil
+}
+
+type capacityAccountRepoStub struct {
+	*accountRepoStub
+	accounts []Account
+}
+
+func (s *capacityAccountRepoStub) ListActive(context.Context) ([]Account, error) {
+	return s.accounts, nil
+}
+
+func newCapacityForecastServiceForTest(days []CapacityUsageDay, accounts []Account) (*CapacityForecastService, *capacityForecastRepoStub) {
+	repo := &capacityForecastRepoStub{days: days}
+	svc := NewCapacityForecastService(repo, &capacityAccountRepoStub{accountRepoStub: &accountRepoStub{}, accounts: accounts})
+	svc.now = func() time.Time { return time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC) }
+	return svc, repo
+}
+
+// 回溯期内每天账号成本线性增长 1 美元：10/6 为 1，10/13 为 8。
+func linearCapacityDays() []CapacityUsageDay {
+	out := make([]CapacityUsageDay, 0, 8)
+	for i := 0; i < 8; i++ {
+		out = append(out, CapacityUsageDay{
+			Date:        time.Date(2026, 10, 6+i, 0, 0, 0, 0, time.UTC),
+			Tokens:      int64(i+1) * 1000,
+			AccountCost: float64(i + 1),
+		})
+	}
+	return out
+}
+
+func TestCapacityForecastService_ProjectsDailyCapExceeded(t *testing.T) {
+	accounts := []Account{
+		{ID: 1, Platform: PlatformAnthropic, Extra: map[string]any{"quota_daily_limit": 6.0}},
+		{ID: 2, Platform: PlatformOpenAI, Extra: map[string]any{"quota_weekly_limit": 42.0}},
+		{ID: 3, Platform: PlatformAnthropic},
+	}
+	svc, repo := newCapacityForecastServiceForTest(linearCapacityDays(), accounts)
+
+	out, err := svc.Forecast(context.Background(), 8, 10)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC), repo.start)
+	require.Len(t, out.History, 8)
+	require.Len(t, out.Projection, 10)
+	require.InDelta(t, 1.0, out.DailyCostGrowth, 1e-9)
+	require.InDelta(t, 1000.0, out.DailyTokenGrowth, 1e-6)
+	require.Equal(t, "2026-10-14", out.Projection[0].Date)
+	require.InDelta(t, 9.0, out.Projection[0].AccountCost, 1e-9)
+
+	// 日额度 6 + 42/7 = 12，预测值 10/17 为 12，10/18 为 13 首次超出
+	require.InDelta(t, 12.0, out.DailyCostCap, 1e-9)
+	require.Equal(t, int64(12000), out.DailyTokenCap)
+	require.Equal(t, 3, out.ActiveAccounts)
+	require.Equal(t, 1, out.UncappedAccounts)
+	require.NotNil(t, out.DailyCapExceededOn)
+	require.Equal(t, "2026-10-18", *out.DailyCapExceededOn)
+	require.Nil(t, out.QuotaExhaustedOn)
+	require.Equal(t, 4, *out.DaysUntilExhausted)
+	require.Len(t, out.Platforms, 2)
+	require.Equal(t, PlatformAnthropic, out.Platforms[0].Platform)
+	require.Equal(t, 2, out.Platforms[0].Accounts)
+	require.Equal(t, 1, out.Platforms[0].CappedAccounts)
+}
+
+func TestCapacityForecastService_ProjectsTotalQuotaExhaustion(t *testing.T) {
+	days := linearCapacityDays()
+	// 今天已用 4 美元
+	days = append(days, CapacityUsageDay{Date: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), Tokens: 4000, AccountCost: 4})
+	accounts := []Account{
+		{ID: 1, Platform: PlatformAnthropic, Extra: map[string]any{"quota_limit": 100.0, "quota_used": 80.0}},
+		{ID: 2, Platform: PlatformAnthropic, Extra: map[string]any{"quota_limit": 10.0, "quota_used": 10.0}},
+		{ID: 3, Platform: PlatformAnthropic, ParentAccountID: ptrInt64(1)},
+	}
+	svc, _ := newCapacityForecastServiceForTest(days, accounts)
+
+	out, err := svc.Forecast(context.Background(), 8, 10)
+	require.NoError(t, err)
+	require.Len(t, out.History, 8, "today is excluded from the fit")
+	require.Equal(t, 2, out.ActiveAccounts, "shadow accounts share the parent quota")
+	require.InDelta(t, 20.0, out.RemainingQuota, 1e-9)
+	// 剩余 20：今天剩余 9-4=5，10/15 为 10（累计 15），10/16 为 11（累计 26）
+	require.Equal(t, "2026-10-16", *out.QuotaExhaustedOn)
+	require.Nil(t, out.DailyCapExceededOn)
+	require.Equal(t, 2, *out.DaysUntilExhausted)
+}
+
+func TestCapacityForecastService_ClampsWindowAndHandlesNoData(t *testing.T) {
+	svc, _ := newCapacityForecastServiceForTest(nil, nil)
+
+	out, err := svc.Forecast(context.Background(), 1, 1000)
+	require.NoError(t, err)
+	require.Equal(t, capacityForecastMinLookbackDays, out.LookbackDays)
+	require.Equal(t, capacityForecastMaxHorizonDays, out.HorizonDays)
+	require.Zero(t, out.DailyCostGrowth)
+	require.Zero(t, out.DailyTokenCap)
+	require.Nil(t, out.DaysUntilExhausted)
+	require.Empty(t, out.Platforms)
+}
@@ -65,6 +65,18 @@ func (s *stubGroupRepoForAvailable) GetAccountIDsByGroupIDs(ctx context.Context,
 func (s *stubGroupRepoForAvailable) BindAccountsToGroup(ctx context.Context, groupID int64, accountIDs []int64) error {
 	return nil
 }
+func (s *stubGroupRepoForAvailable) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	return nil, nil
+}
+func (s *stubGroupRepoForAvailable) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	return nil
+}
+func (s *stubGroupRepoForAvailable) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	return nil, nil
+}
+func (s *stubGroupRepoForAvailable) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	return nil
+}
 func (s *stubGroupRepoForAvailable) UpdateSortOrders(ctx context.Context, updates []GroupSortOrderUpdate) error {
 	return nil
 }
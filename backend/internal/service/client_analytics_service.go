@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 客户端来源分析的聚合维度。
+const (
+	ClientAnalyticsDimensionClient        = "client"         // 客户端类型（Claude Code / Codex / SDK ...）
+	ClientAnalyticsDimensionClientVersion = "client_version" // 客户端类型 + 版本
+	ClientAnalyticsDimensionOrigin        = "origin"         // 来源网段（IPv4 /24、IPv6 /48）
+)
+
+const (
+	clientAnalyticsDefaultDays  = 7
+	clientAnalyticsMaxDays      = 90
+	clientAnalyticsDefaultLimit = 50
+	clientAnalyticsMaxLimit     = 200
+	// clientAnalyticsMaxGroups 单次读取的 (API Key, UA, IP) 原始分组上限，超出时报表标记为截断。
+	clientAnalyticsMaxGroups = 20000
+	// clientAnalyticsTopKeys 每行列出的请求量最高的 API Key 数。
+	clientAnalyticsTopKeys = 3
+
+	// ClientProfileUnknown 未携带 User-Agent 或无法识别产品名的请求。
+	ClientProfileUnknown = "unknown"
+)
+
+// ClientUsageGroup 统计区间内按 (API Key, User-Agent, IP) 聚合的原始用量。
+type ClientUsageGroup struct {
+	APIKeyID    int64
+	APIKeyName  string
+	UserAgent   string
+	IPAddress   string
+	Requests    int64
+	TotalTokens int64
+	ActualCost  float64
+	LastUsedAt  time.Time
+}
+
+// ClientAnalyticsRepository 读取客户端来源分析所需的用量聚合。
+type ClientAnalyticsRepository interface {
+	// AggregateClientUsage 聚合 [start, end) 区间的用量，按请求数降序返回前 limit 组；apiKeyID > 0 时只统计该 Key。
+	AggregateClientUsage(ctx context.Context, start, end time.Time, apiKeyID int64, limit int) ([]ClientUsageGroup, error)
+}
+
+// ClientAnalyticsKeyShare 某个维度值下单个 API Key 的请求量。
+type ClientAnalyticsKeyShare struct {
+	APIKeyID int64  `json:"api_key_id"`
+	Name     string `json:"name"`
+	Requests int64  `json:"requests"`
+}
+
+// ClientAnalyticsRow 单个维度值在统计区间内的用量汇总。
+type ClientAnalyticsRow struct {
+	Key     string `json:"key"`
+	Client  string `json:"client,omitempty"`
+	Version string `json:"version,omitempty"`
+	Origin  string `json:"origin,omitempty"`
+
+	Requests    int64   `json:"requests"`
+	Share       float64 `json:"share"` // 占报表总请求数的比例
+	TotalTokens int64   `json:"total_tokens"`
+	ActualCost  float64 `json:"actual_cost"`
+	APIKeys     int     `json:"api_keys"` // 涉及的 API Key 数
+	Origins     int     `json:"origins"`  // 涉及的来源网段数
+
+	TopAPIKeys      []ClientAnalyticsKeyShare `json:"top_api_keys"`
+	SampleUserAgent string                    `json:"sample_user_agent,omitempty"`
+	LastUsedAt      time.Time                 `json:"last_used_at"`
+}
+
+// ClientAnalyticsReport 客户端来源分析报表。
+type ClientAnalyticsReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Dimension   string    `json:"dimension"`
+	Days        int       `json:"days"`
+	APIKeyID    int64     `json:"api_key_id,omitempty"`
+
+	Requests int64 `json:"requests"`
+	// Truncated 原始分组超过上限，长尾流量未计入
+	Truncated bool                 `json:"truncated"`
+	Rows      []ClientAnalyticsRow `json:"rows"`
+}
+
+// ClientAnalyticsService 按客户端类型、版本与来源网段汇总流量，用于判断哪些工具/团队在驱动请求，
+// 从而决定兼容层（请求改写、伪装 header 等）的优先级。
+type ClientAnalyticsService struct {
+	repo ClientAnalyticsRepository
+	now  func() time.Time
+}
+
+// NewClientAnalyticsService creates a new ClientAnalyticsService.
+func NewClientAnalyticsService(repo ClientAnalyticsRepository) *ClientAnalyticsService {
+	return &ClientAnalyticsService{repo: repo, now: time.Now}
+}
+
+// Report 生成最近 days 天按 dimension 聚合的客户端来源报表；参数为 0 时使用默认值。
+func (s *ClientAnalyticsService) Report(ctx context.Context, dimension string, apiKeyID int64, days, limit int) (*ClientAnalyticsReport, error) {
+	dimension = strings.TrimSpace(dimension)
+	if dimension == "" {
+		dimension = ClientAnalyticsDimensionClient
+	}
+	switch dimension {
+	case ClientAnalyticsDimensionClient, ClientAnalyticsDimensionClientVersion, ClientAnalyticsDimensionOrigin:
+	default:
+		return nil, infraerrors.BadRequest("INVALID_CLIENT_ANALYTICS_DIMENSION", "dimension must be one of client, client_version, origin")
+	}
+	days = clampCapacityDays(days, clientAnalyticsDefaultDays, 1, clientAnalyticsMaxDays)
+	if limit <= 0 {
+		limit = clientAnalyticsDefaultLimit
+	}
+	limit = min(limit, clientAnalyticsMaxLimit)
+
+	end := s.now().UTC()
+	groups, err := s.repo.AggregateClientUsage(ctx, end.AddDate(0, 0, -days), end, apiKeyID, clientAnalyticsMaxGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	type accumulator struct {
+		row     ClientAnalyticsRow
+		keys    map[int64]*ClientAnalyticsKeyShare
+		origins map[string]struct{}
+		sample  int64 // SampleUserAgent 对应分组的请求数
+	}
+	byKey := make(map[string]*accumulator)
+	report := &ClientAnalyticsReport{
+		GeneratedAt: end,
+		Dimension:   dimension,
+		Days:        days,
+		APIKeyID:    apiKeyID,
+		Truncated:   len(groups) >= clientAnalyticsMaxGroups,
+		Rows:        []ClientAnalyticsRow{},
+	}
+	for _, g := range groups {
+		client, version := DetectClientProfile(g.UserAgent)
+		origin := ClientOriginNetwork(g.IPAddress)
+		row := ClientAnalyticsRow{}
+		switch dimension {
+		case ClientAnalyticsDimensionClient:
+			row.Key, row.Client = client, client
+		case ClientAnalyticsDimensionClientVersion:
+			row.Key, row.Client, row.Version = client+"/"+version, client, version
+			if version == "" {
+				row.Key = client
+			}
+		case ClientAnalyticsDimensionOrigin:
+			row.Key, row.Origin = origin, origin
+		}
+
+		acc := byKey[row.Key]
+		if acc == nil {
+			acc = &accumulator{row: row, keys: make(map[int64]*ClientAnalyticsKeyShare), origins: make(map[string]struct{})}
+			byKey[row.Key] = acc
+		}
+		acc.row.Requests += g.Requests
+		acc.row.TotalTokens += g.TotalTokens
+		acc.row.ActualCost += g.ActualCost
+		if g.LastUsedAt.After(acc.row.LastUsedAt) {
+			acc.row.LastUsedAt = g.LastUsedAt
+		}
+		if g.UserAgent != "" && g.Requests > acc.sample {
+			acc.row.SampleUserAgent, acc.sample = g.UserAgent, g.Requests
+		}
+		acc.origins[origin] = struct{}{}
+		share := acc.keys[g.APIKeyID]
+		if share == nil {
+			share = &ClientAnalyticsKeyShare{APIKeyID: g.APIKeyID, Name: g.APIKeyName}
+			acc.keys[g.APIKeyID] = share
+		}
+		share.Requests += g.Requests
+		report.Requests += g.Requests
+	}
+
+	for _, acc := range byKey {
+		row := acc.row
+		row.APIKeys = len(acc.keys)
+		row.Origins = len(acc.origins)
+		if report.Requests > 0 {
+			row.Share = float64(row.Requests) / float64(report.Requests)
+		}
+		row.TopAPIKeys = topClientAnalyticsKeys(acc.keys)
+		report.Rows = append(report.Rows, row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Requests != report.Rows[j].Requests {
+			return report.Rows[i].Requests > report.Rows[j].Requests
+		}
+		return report.Rows[i].Key < report.Rows[j].Key
+	})
+	if len(report.Rows) > limit {
+		report.Rows = report.Rows[:limit]
+	}
+	return report, nil
+}
+
+func topClientAnalyticsKeys(keys map[int64]*ClientAnalyticsKeyShare) []ClientAnalyticsKeyShare {
+	out := make([]ClientAnalyticsKeyShare, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, *k)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].APIKeyID < out[j].APIKeyID
+	})
+	if len(out) > clientAnalyticsTopKeys {
+		out = out[:clientAnalyticsTopKeys]
+	}
+	return out
+}
+
+var (
+	// clientSDKUserAgentPattern 官方 SDK 的 "Anthropic/Python 0.39.0"、"OpenAI/JS 4.67.0" 形式。
+	clientSDKUserAgentPattern = regexp.MustCompile(`(?i)\b(anthropic|openai)/([a-z]+)\s+v?(\d[\w.-]*)`)
+	// clientProductUserAgentPattern 通用 Product/Version 令牌。
+	clientProductUserAgentPattern = regexp.MustCompile(`([A-Za-z][A-Za-z0-9._-]*)/v?(\d[\w.-]*)`)
+)
+
+// clientProfileProducts 已知客户端的 UA 产品名（小写）到客户端类型的映射，按出现顺序匹配。
+var clientProfileProducts = []struct {
+	product string
+	profile string
+}{
+	{"claude-cli", "claude_code"},
+	{"claude-code", "claude_code"},
+	{"codex_cli_rs", "codex"},
+	{"codex_exec", "codex"},
+	{"codex_vscode", "codex"},
+	{"opencode", "opencode"},
+	{"geminicli", "gemini_cli"},
+	{"gemini-cli", "gemini_cli"},
+	{"kilo-code", "kilo_code"},
+	{"roo-code", "roo_code"},
+	{"cline", "cline"},
+	{"cursor", "cursor"},
+	{"cherrystudio", "cherry_studio"},
+	{"aider", "aider"},
+	{"curl", "curl"},
+	{"python-requests", "python_requests"},
+	{"python-httpx", "python_httpx"},
+	{"go-http-client", "go_http"},
+	{"axios", "axios"},
+	{"node-fetch", "node_fetch"},
+	{"undici", "node_fetch"},
+}
+
+// DetectClientProfile 从 User-Agent 识别客户端类型与版本；无法识别时返回 UA 中第一个产品名。
+func DetectClientProfile(userAgent string) (profile, version string) {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return ClientProfileUnknown, ""
+	}
+	if m := clientSDKUserAgentPattern.FindStringSubmatch(userAgent); m != nil {
+		return strings.ToLower(m[1]) + "_sdk_" + strings.ToLower(m[2]), m[3]
+	}
+	matches := clientProductUserAgentPattern.FindAllStringSubmatch(userAgent, -1)
+	for _, known := range clientProfileProducts {
+		for _, m := range matches {
+			if strings.ToLower(m[1]) == known.product {
+				return known.profile, m[2]
+			}
+		}
+	}
+	if len(matches) > 0 {
+		return strings.ToLower(matches[0][1]), matches[0][2]
+	}
+	lower := strings.ToLower(userAgent)
+	for _, known := range clientProfileProducts {
+		if strings.Contains(lower, known.product) {
+			return known.profile, ""
+		}
+	}
+	return ClientProfileUnknown, ""
+}
+
+// ClientOriginNetwork 将客户端 IP 归并为来源网段（IPv4 /24、IPv6 /48），用于识别同一办公网络/机房的流量。
+// 未配置 IP 库，不做 ASN 解析；无法解析的地址归为 unknown。
+func ClientOriginNetwork(ip string) string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return ClientProfileUnknown
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ClientProfileUnknown
+	}
+	return prefix.String()
+}
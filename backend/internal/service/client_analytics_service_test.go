@@ -0,0 +1,101 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type clientAnalyticsRepoStub struct {
+	groups   []ClientUsageGroup
+	start    time.Time
+	end      time.Time
+	apiKeyID int64
+	limit    int
+}
+
+func (r *clientAnalyticsRepoStub) AggregateClientUsage(_ context.Context, start, end time.Time, apiKeyID int64, limit int) ([]ClientUsageGroup, error) {
+	r.start, r.end, r.apiKeyID, r.limit = start, end, apiKeyID, limit
+	return r.groups, nil
+}
+
+func TestDetectClientProfile(t *testing.T) {
+	cases := []struct {
+		ua, profile, version string
+	}{
+		{"claude-cli/1.0.83 (external, cli)", "claude_code", "1.0.83"},
+		{"codex_cli_rs/0.46.0 (Mac OS 15.1.0; arm64) iTerm.app/3.5.10", "codex", "0.46.0"},
+		{"opencode/0.15.2 ai-sdk/provider-utils/3.0.10 runtime/bun/1.3.0", "opencode", "0.15.2"},
+		{"GeminiCLI/0.9.0 (darwin; arm64)", "gemini_cli", "0.9.0"},
+		{"Anthropic/Python 0.39.0", "anthropic_sdk_python", "0.39.0"},
+		{"OpenAI/JS 4.67.3", "openai_sdk_js", "4.67.3"},
+		{"curl/8.7.1", "curl", "8.7.1"},
+		{"Mozilla/5.0 (Macintosh) AppleWebKit/605.1.15", "mozilla", "5.0"},
+		{"", ClientProfileUnknown, ""},
+		{"some tool", ClientProfileUnknown, ""},
+	}
+	for _, tc := range cases {
+		profile, version := DetectClientProfile(tc.ua)
+		require.Equal(t, tc.profile, profile, tc.ua)
+		require.Equal(t, tc.version, version, tc.ua)
+	}
+}
+
+func TestClientOriginNetwork(t *testing.T) {
+	require.Equal(t, "203.0.113.0/24", ClientOriginNetwork("203.0.113.77"))
+	require.Equal(t, "203.0.113.0/24", ClientOriginNetwork("::ffff:203.0.113.9"))
+	require.Equal(t, "2001:db8:1::/48", ClientOriginNetwork("2001:db8:1:2::5"))
+	require.Equal(t, ClientProfileUnknown, ClientOriginNetwork(""))
+}
+
+func TestClientAnalyticsService_Report(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	repo := &clientAnalyticsRepoStub{groups: []ClientUsageGroup{
+		{APIKeyID: 1, APIKeyName: "team-a", UserAgent: "claude-cli/1.0.83 (external, cli)", IPAddress: "203.0.113.7", Requests: 60, TotalTokens: 6000, ActualCost: 1.5, LastUsedAt: now.Add(-time.Hour)},
+		{APIKeyID: 2, APIKeyName: "team-b", UserAgent: "claude-cli/1.0.90 (external, cli)", IPAddress: "198.51.100.3", Requests: 30, TotalTokens: 3000, ActualCost: 0.5, LastUsedAt: now.Add(-time.Minute)},
+		{APIKeyID: 1, APIKeyName: "team-a", UserAgent: "codex_cli_rs/0.46.0", IPAddress: "203.0.113.8", Requests: 10, TotalTokens: 900},
+	}}
+	svc := &ClientAnalyticsService{repo: repo, now: func() time.Time { return now }}
+
+	report, err := svc.Report(context.Background(), "", 0, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, ClientAnalyticsDimensionClient, report.Dimension)
+	require.Equal(t, clientAnalyticsDefaultDays, report.Days)
+	require.Equal(t, now.AddDate(0, 0, -clientAnalyticsDefaultDays), repo.start)
+	require.Equal(t, clientAnalyticsMaxGroups, repo.limit)
+	require.Equal(t, int64(100), report.Requests)
+	require.False(t, report.Truncated)
+	require.Len(t, report.Rows, 2)
+
+	claude := report.Rows[0]
+	require.Equal(t, "claude_code", claude.Key)
+	require.Equal(t, int64(90), claude.Requests)
+	require.InDelta(t, 0.9, claude.Share, 1e-9)
+	require.Equal(t, int64(9000), claude.TotalTokens)
+	require.InDelta(t, 2.0, claude.ActualCost, 1e-9)
+	require.Equal(t, 2, claude.APIKeys)
+	require.Equal(t, 2, claude.Origins)
+	require.Equal(t, "claude-cli/1.0.83 (external, cli)", claude.SampleUserAgent)
+	require.Equal(t, now.Add(-time.Minute), claude.LastUsedAt)
+	require.Equal(t, []ClientAnalyticsKeyShare{{APIKeyID: 1, Name: "team-a", Requests: 60}, {APIKeyID: 2, Name: "team-b", Requests: 30}}, claude.TopAPIKeys)
+
+	report, err = svc.Report(context.Background(), ClientAnalyticsDimensionClientVersion, 1, 3, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), repo.apiKeyID)
+	require.Len(t, report.Rows, 1)
+	require.Equal(t, "claude_code/1.0.83", report.Rows[0].Key)
+	require.Equal(t, "1.0.83", report.Rows[0].Version)
+
+	report, err = svc.Report(context.Background(), ClientAnalyticsDimensionOrigin, 0, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.0/24", report.Rows[0].Key)
+	require.Equal(t, int64(70), report.Rows[0].Requests)
+
+	_, err = svc.Report(context.Background(), "user_agent", 0, 0, 0)
+	require.Equal(t, "INVALID_CLIENT_ANALYTICS_DIMENSION", infraerrors.Reason(err))
+}
@@ -108,6 +108,49 @@ type ConcurrencyService struct {
 	accountLoadCacheMu  sync.RWMutex
 	accountLoadCache    map[string]cachedAccountLoadBatch
 	accountLoadGroup    singleflight.Group
+
+	userFairness atomic.Pointer[UserFairnessTracker]
+
+	// 账号跨分组并发份额配置（本地短 TTL 快照）
+	shareReader    AccountGroupShareReader
+	shareSnapshot  atomic.Pointer[accountGroupShareSnapshot]
+	shareLoadGroup singleflight.Group
+}
+
+// SetUserFairnessTracker 设置分组内用户公平调度跟踪器；nil 表示关闭。
+func (s *ConcurrencyService) SetUserFairnessTracker(tracker *UserFairnessTracker) {
+	if s == nil {
+		return
+	}
+	s.userFairness.Store(tracker)
+}
+
+// RecordUserConsumption 记录用户在分组内的消耗，供公平调度计算占比。
+func (s *ConcurrencyService) RecordUserConsumption(groupID, userID int64, tokens int) {
+	if s == nil || tokens <= 0 {
+		return
+	}
+	s.userFairness.Load().Record(groupID, userID, float64(tokens))
+}
+
+// RecordUsageLogConsumption 按使用记录累计用户在分组内的 token 消耗。
+func (s *ConcurrencyService) RecordUsageLogConsumption(usageLog *UsageLog) {
+	if s == nil || usageLog == nil {
+		return
+	}
+	var groupID int64
+	if usageLog.GroupID != nil {
+		groupID = *usageLog.GroupID
+	}
+	s.RecordUserConsumption(groupID, usageLog.UserID, usageLog.TotalTokens())
+}
+
+// UserFairnessBackoffMultiplier 返回用户等待账号槽位时的退避倍数（未开启时为 1）。
+func (s *ConcurrencyService) UserFairnessBackoffMultiplier(groupID, userID int64) float64 {
+	if s == nil {
+		return 1
+	}
+	return s.userFairness.Load().BackoffMultiplier(groupID, userID)
 }
 
 type cachedAccountLoadBatch struct {
@@ -183,8 +226,18 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 	// Generate unique request ID for this slot
 	requestID := generateRequestID()
 
+	// 账号被多个分组共享时，先按当前分组的并发份额占位（份额按账号完整并发计算）
+	releaseShare, shareAcquired := s.acquireAccountGroupShareSlot(ctx, accountID, maxConcurrency, requestID)
+	if !shareAcquired {
+		return &AcquireResult{Acquired: false}, nil
+	}
+
+	// 非关键 Key 不能占用分组为关键 Key 预留的槽位
+	maxConcurrency = effectiveAccountConcurrency(ctx, maxConcurrency)
+
 	acquired, err := s.cache.AcquireAccountSlot(ctx, accountID, maxConcurrency, requestID)
 	if err != nil {
+		releaseShare()
 		return nil, err
 	}
 
@@ -197,9 +250,11 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 				if err := s.cache.ReleaseAccountSlot(bgCtx, accountID, requestID); err != nil {
 					logger.LegacyPrintf("service.concurrency", "Warning: failed to release account slot for %d (req=%s): %v", accountID, requestID, err)
 				}
+				releaseShare()
 			},
 		}, nil
 	}
+	releaseShare()
 
 	return &AcquireResult{
 		Acquired:    false,
@@ -36,6 +36,7 @@ type stubConcurrencyCacheForTest struct {
 	apiKeyConcurrencyErr error
 
 	// 记录调用
+	acquiredAccountMax       []int
 	releasedAccountIDs       []int64
 	releasedRequestIDs       []string
 	loadBatchCalls           atomic.Int64
@@ -47,7 +48,8 @@ type stubConcurrencyCacheForTest struct {
 
 var _ ConcurrencyCache = (*stubConcurrencyCacheForTest)(nil)
 
-func (c *stubConcurrencyCacheForTest) AcquireAccountSlot(_ context.Context, _ int64, _ int, _ string) (bool, error) {
+func (c *stubConcurrencyCacheForTest) AcquireAccountSlot(_ context.Context, _ int64, maxConcurrency int, _ string) (bool, error) {
+	c.acquiredAccountMax = append(c.acquiredAccountMax, maxConcurrency)
 	return c.acquireResult, c.acquireErr
 }
 func (c *stubConcurrencyCacheForTest) ReleaseAccountSlot(_ context.Context, accountID int64, requestID string) error {
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// ConfigSyncSnapshotPath primary 暴露的快照拉取路径
+	ConfigSyncSnapshotPath = "/api/v1/config-sync/snapshot"
+	// ConfigSyncFormatVersion 快照格式版本，主从不一致时拒绝应用
+	ConfigSyncFormatVersion = 1
+
+	// 单次快照响应体上限，防止异常 primary 撑爆内存
+	configSyncMaxSnapshotBytes = 256 << 20
+)
+
+var (
+	ErrConfigSyncDisabled       = infraerrors.NotFound("CONFIG_SYNC_DISABLED", "config sync is not enabled on this instance")
+	ErrConfigSyncUnauthorized   = infraerrors.Unauthorized("CONFIG_SYNC_UNAUTHORIZED", "invalid config sync token")
+	ErrConfigSyncNotReplica     = infraerrors.Conflict("CONFIG_SYNC_NOT_REPLICA", "this instance is not a config sync replica")
+	ErrConfigSyncSchemaMismatch = infraerrors.Conflict("CONFIG_SYNC_SCHEMA_MISMATCH", "primary and replica database schema versions differ")
+	ErrConfigSyncFormatMismatch = infraerrors.Conflict("CONFIG_SYNC_FORMAT_MISMATCH", "unsupported config sync snapshot format")
+	ErrConfigReadOnly           = infraerrors.Forbidden("CONFIG_READ_ONLY", "configuration is managed by the primary instance and is read-only on this replica")
+)
+
+// ConfigSyncSnapshot 主从同步的配置快照。
+// Tables 中每项为对应表的 JSON 数组（row_to_json），仅包含未软删除的行。
+type ConfigSyncSnapshot struct {
+	Version       int                        `json:"version"`
+	SchemaVersion string                     `json:"schema_version"`
+	GeneratedAt   time.Time                  `json:"generated_at"`
+	Tables        map[string]json.RawMessage `json:"tables"`
+}
+
+// ConfigSyncApplyResult 快照应用结果，用于失效缓存与运维展示。
+type ConfigSyncApplyResult struct {
+	RowCounts         map[string]int
+	ChangedTables     []string
+	ChangedAPIKeys    []string
+	ChangedUserIDs    []int64
+	ChangedGroupIDs   []int64
+	ChangedProjectIDs []int64
+	SchedulerChanged  bool
+}
+
+// ConfigSyncRepository 配置快照的导出与应用。
+type ConfigSyncRepository interface {
+	ExportSnapshot(ctx context.Context) (*ConfigSyncSnapshot, error)
+	// ApplySnapshot 在单个事务中用快照覆盖本地配置；调度相关配置变化时负责写入调度 outbox。
+	ApplySnapshot(ctx context.Context, snapshot *ConfigSyncSnapshot) (*ConfigSyncApplyResult, error)
+}
+
+// ConfigSyncStatus 配置同步运行状态。
+type ConfigSyncStatus struct {
+	Role              string         `json:"role"`
+	PrimaryURL        string         `json:"primary_url,omitempty"`
+	IntervalSeconds   int            `json:"interval_seconds,omitempty"`
+	UpstreamOverride  bool           `json:"upstream_override,omitempty"`
+	LastAttemptAt     *time.Time     `json:"last_attempt_at,omitempty"`
+	LastSuccessAt     *time.Time     `json:"last_success_at,omitempty"`
+	LastError         string         `json:"last_error,omitempty"`
+	LastSchemaVersion string         `json:"last_schema_version,omitempty"`
+	LastGeneratedAt   *time.Time     `json:"last_generated_at,omitempty"`
+	LastRowCounts     map[string]int `json:"last_row_counts,omitempty"`
+	LastChangedTables []string       `json:"last_changed_tables,omitempty"`
+}
+
+// ConfigSyncService 跨地域部署的配置主从同步。
+//
+// primary：通过共享令牌鉴权的接口导出账号/分组/用户/Key/代理配置快照；
+// replica：定期从 primary 拉取快照并在本地事务内覆盖，随后失效认证缓存并触发调度快照重建。
+// 使用日志、运维数据等不参与同步，各实例写入本地数据库。
+type ConfigSyncService struct {
+	repo                 ConfigSyncRepository
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+	cfg                  config.ConfigSyncConfig
+	httpClient           *http.Client
+
+	runMu sync.Mutex
+
+	statusMu sync.RWMutex
+	status   ConfigSyncStatus
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+func NewConfigSyncService(repo ConfigSyncRepository, authCacheInvalidator APIKeyAuthCacheInvalidator, cfg *config.Config) *ConfigSyncService {
+	var syncCfg config.ConfigSyncConfig
+	if cfg != nil {
+		syncCfg = cfg.ConfigSync
+	}
+	timeout := 30 * time.Second
+	if syncCfg.RequestTimeoutSeconds > 0 {
+		timeout = time.Duration(syncCfg.RequestTimeoutSeconds) * time.Second
+	}
+	svc := &ConfigSyncService{
+		repo:                 repo,
+		authCacheInvalidator: authCacheInvalidator,
+		cfg:                  syncCfg,
+		httpClient:           &http.Client{Timeout: timeout},
+		stopCh:               make(chan struct{}),
+	}
+	svc.status = ConfigSyncStatus{Role: strings.ToLower(strings.TrimSpace(syncCfg.Role))}
+	if syncCfg.IsReplica() {
+		svc.status.PrimaryURL = strings.TrimRight(strings.TrimSpace(syncCfg.PrimaryURL), "/")
+		svc.status.IntervalSeconds = syncCfg.IntervalSeconds
+		svc.status.UpstreamOverride = syncCfg.IsMirror() && strings.TrimSpace(syncCfg.UpstreamOverrideURL) != ""
+	}
+	return svc
+}
+
+// IsPrimary 当前实例是否对外提供配置快照。
+func (s *ConfigSyncService) IsPrimary() bool {
+	return s != nil && s.cfg.IsPrimary()
+}
+
+// IsReplica 当前实例是否为只读副本（replica/mirror，本地配置写操作应被拒绝）。
+func (s *ConfigSyncService) IsReplica() bool {
+	return s != nil && s.cfg.IsReplica()
+}
+
+// VerifyToken 校验 replica 携带的共享令牌（常量时间比较）。
+func (s *ConfigSyncService) VerifyToken(token string) error {
+	if !s.IsPrimary() {
+		return ErrConfigSyncDisabled
+	}
+	expected := strings.TrimSpace(s.cfg.Token)
+	token = strings.TrimSpace(token)
+	if expected == "" || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return ErrConfigSyncUnauthorized
+	}
+	return nil
+}
+
+// ExportSnapshot 导出当前配置快照（仅 primary）。
+func (s *ConfigSyncService) ExportSnapshot(ctx context.Context) (*ConfigSyncSnapshot, error) {
+	if !s.IsPrimary() {
+		return nil, ErrConfigSyncDisabled
+	}
+	snapshot, err := s.repo.ExportSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Version = ConfigSyncFormatVersion
+	return snapshot, nil
+}
+
+// Status 返回配置同步运行状态。
+func (s *ConfigSyncService) Status() ConfigSyncStatus {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+	status := s.status
+	if status.LastRowCounts != nil {
+		counts := make(map[string]int, len(status.LastRowCounts))
+		for k, v := range status.LastRowCounts {
+			counts[k] = v
+		}
+		status.LastRowCounts = counts
+	}
+	status.LastChangedTables = append([]string(nil), status.LastChangedTables...)
+	return status
+}
+
+// SyncOnce 从 primary 拉取一次快照并应用到本地（仅 replica）；并发调用串行执行。
+func (s *ConfigSyncService) SyncOnce(ctx context.Context) (*ConfigSyncApplyResult, error) {
+	if !s.IsReplica() {
+		return nil, ErrConfigSyncNotReplica
+	}
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	startedAt := time.Now()
+	result, snapshot, err := s.pullAndApply(ctx)
+
+	s.statusMu.Lock()
+	s.status.LastAttemptAt = &startedAt
+	if err != nil {
+		s.status.LastError = err.Error()
+	} else {
+		s.status.LastError = ""
+		s.status.LastSuccessAt = &startedAt
+		s.status.LastSchemaVersion = snapshot.SchemaVersion
+		generatedAt := snapshot.GeneratedAt
+		s.status.LastGeneratedAt = &generatedAt
+		s.status.LastRowCounts = result.RowCounts
+		s.status.LastChangedTables = result.ChangedTables
+	}
+	s.statusMu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ConfigSyncService) pullAndApply(ctx context.Context) (*ConfigSyncApplyResult, *ConfigSyncSnapshot, error) {
+	snapshot, err := s.fetchSnapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if snapshot.Version != ConfigSyncFormatVersion {
+		return nil, nil, ErrConfigSyncFormatMismatch.WithMetadata(map[string]string{
+			"primary_version": fmt.Sprintf("%d", snapshot.Version),
+			"replica_version": fmt.Sprintf("%d", ConfigSyncFormatVersion),
+		})
+	}
+	result, err := s.repo.ApplySnapshot(ctx, snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.invalidateAuthCache(ctx, result)
+	return result, snapshot, nil
+}
+
+func (s *ConfigSyncService) fetchSnapshot(ctx context.Context) (*ConfigSyncSnapshot, error) {
+	endpoint := strings.TrimRight(strings.TrimSpace(s.cfg.PrimaryURL), "/") + ConfigSyncSnapshotPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build config sync request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(s.cfg.Token))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch config snapshot: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, configSyncMaxSnapshotBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read config snapshot: %w", err)
+	}
+	if len(body) > configSyncMaxSnapshotBytes {
+		return nil, fmt.Errorf("config snapshot exceeds %d bytes", configSyncMaxSnapshotBytes)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch config snapshot: primary returned status %d", resp.StatusCode)
+	}
+
+	// primary 使用统一响应包装 {code, message, data}
+	var envelope struct {
+		Code    int                 `json:"code"`
+		Message string              `json:"message"`
+		Data    *ConfigSyncSnapshot `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("decode config snapshot: %w", err)
+	}
+	if envelope.Code != 0 || envelope.Data == nil {
+		return nil, fmt.Errorf("fetch config snapshot: primary error code=%d message=%s", envelope.Code, envelope.Message)
+	}
+	return envelope.Data, nil
+}
+
+func (s *ConfigSyncService) invalidateAuthCache(ctx context.Context, result *ConfigSyncApplyResult) {
+	if s.authCacheInvalidator == nil || result == nil {
+		return
+	}
+	for _, key := range result.ChangedAPIKeys {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, key)
+	}
+	for _, userID := range result.ChangedUserIDs {
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, userID)
+	}
+	for _, groupID := range result.ChangedGroupIDs {
+		s.authCacheInvalidator.InvalidateAuthCacheByGroupID(ctx, groupID)
+	}
+	// 项目额度写在成员 Key 的鉴权快照里
+	if invalidator, ok := s.authCacheInvalidator.(apiKeyProjectAuthCacheInvalidator); ok {
+		for _, projectID := range result.ChangedProjectIDs {
+			invalidator.InvalidateAuthCacheByProjectID(ctx, projectID)
+		}
+	}
+}
+
+// Start 启动 replica 周期拉取；非 replica 实例为空操作。
+func (s *ConfigSyncService) Start() {
+	if !s.IsReplica() || s.repo == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		interval := time.Duration(s.cfg.IntervalSeconds) * time.Second
+		if interval <= 0 {
+			interval = 30 * time.Second
+		}
+		logger.LegacyPrintf("service.config_sync", "[ConfigSync] replica started primary=%s interval=%s", s.status.PrimaryURL, interval)
+		s.wg.Add(1)
+		go s.runLoop(interval)
+	})
+}
+
+func (s *ConfigSyncService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		s.wg.Wait()
+	})
+}
+
+func (s *ConfigSyncService) runLoop(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 启动后立即同步一轮，缩短 replica 冷启动时的配置滞后。
+	s.syncFromLoop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.syncFromLoop()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+func (s *ConfigSyncService) syncFromLoop() {
+	timeout := s.httpClient.Timeout + 30*time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := s.SyncOnce(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.config_sync", "[ConfigSync] sync failed err=%v", err)
+		return
+	}
+	if len(result.ChangedTables) > 0 {
+		logger.LegacyPrintf("service.config_sync", "[ConfigSync] applied snapshot changed_tables=%s", strings.Join(result.ChangedTables, ","))
+	}
+}
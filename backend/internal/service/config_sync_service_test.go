@@ -0,0 +1,177 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type configSyncRepoStub struct {
+	mu       sync.Mutex
+	snapshot *ConfigSyncSnapshot
+	applied  []*ConfigSyncSnapshot
+	result   *ConfigSyncApplyResult
+}
+
+func (s *configSyncRepoStub) ExportSnapshot(context.Context) (*ConfigSyncSnapshot, error) {
+	return s.snapshot, nil
+}
+
+func (s *configSyncRepoStub) ApplySnapshot(_ context.Context, snapshot *ConfigSyncSnapshot) (*ConfigSyncApplyResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applied = append(s.applied, snapshot)
+	return s.result, nil
+}
+
+type configSyncInvalidatorStub struct {
+	keys     []string
+	users    []int64
+	groups   []int64
+	projects []int64
+}
+
+func (s *configSyncInvalidatorStub) InvalidateAuthCacheByKey(_ context.Context, key string) {
+	s.keys = append(s.keys, key)
+}
+
+func (s *configSyncInvalidatorStub) InvalidateAuthCacheByUserID(_ context.Context, userID int64) {
+	s.users = append(s.users, userID)
+}
+
+func (s *configSyncInvalidatorStub) InvalidateAuthCacheByGroupID(_ context.Context, groupID int64) {
+	s.groups = append(s.groups, groupID)
+}
+
+func (s *configSyncInvalidatorStub) InvalidateAuthCacheByProjectID(_ context.Context, projectID int64) {
+	s.projects = append(s.projects, projectID)
+}
+
+const configSyncTestToken = "0123456789abcdef0123456789abcdef"
+
+func newConfigSyncTestConfig(role, primaryURL string) *config.Config {
+	return &config.Config{ConfigSync: config.ConfigSyncConfig{
+		Role:                  role,
+		Token:                 configSyncTestToken,
+		PrimaryURL:            primaryURL,
+		IntervalSeconds:       30,
+		RequestTimeoutSeconds: 5,
+	}}
+}
+
+// newConfigSyncPrimaryServer 模拟 primary：校验令牌并以统一响应包装返回快照。
+func newConfigSyncPrimaryServer(t *testing.T, primary *ConfigSyncService) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, ConfigSyncSnapshotPath, r.URL.Path)
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if err := primary.VerifyToken(token); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":401,"message":"unauthorized"}`))
+			return
+		}
+		snapshot, err := primary.ExportSnapshot(r.Context())
+		require.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "message": "success", "data": snapshot})
+	}))
+}
+
+func TestConfigSyncService_VerifyToken(t *testing.T) {
+	primary := NewConfigSyncService(&configSyncRepoStub{}, nil, newConfigSyncTestConfig(config.ConfigSyncRolePrimary, ""))
+	require.NoError(t, primary.VerifyToken(configSyncTestToken))
+	require.ErrorIs(t, primary.VerifyToken("wrong"), ErrConfigSyncUnauthorized)
+	require.ErrorIs(t, primary.VerifyToken(""), ErrConfigSyncUnauthorized)
+
+	disabled := NewConfigSyncService(&configSyncRepoStub{}, nil, &config.Config{})
+	require.ErrorIs(t, disabled.VerifyToken(configSyncTestToken), ErrConfigSyncDisabled)
+	_, err := disabled.SyncOnce(context.Background())
+	require.ErrorIs(t, err, ErrConfigSyncNotReplica)
+}
+
+func TestConfigSyncService_SyncOnceAppliesPrimarySnapshot(t *testing.T) {
+	primaryRepo := &configSyncRepoStub{snapshot: &ConfigSyncSnapshot{
+		SchemaVersion: "174_add_reserved_capacity.sql",
+		GeneratedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tables:        map[string]json.RawMessage{"groups": json.RawMessage(`[{"id":1}]`)},
+	}}
+	primary := NewConfigSyncService(primaryRepo, nil, newConfigSyncTestConfig(config.ConfigSyncRolePrimary, ""))
+	server := newConfigSyncPrimaryServer(t, primary)
+	defer server.Close()
+
+	replicaRepo := &configSyncRepoStub{result: &ConfigSyncApplyResult{
+		RowCounts:         map[string]int{"groups": 1},
+		ChangedTables:     []string{"groups", "api_keys"},
+		ChangedAPIKeys:    []string{"sk-a"},
+		ChangedUserIDs:    []int64{7},
+		ChangedGroupIDs:   []int64{1},
+		ChangedProjectIDs: []int64{5},
+	}}
+	invalidator := &configSyncInvalidatorStub{}
+	replica := NewConfigSyncService(replicaRepo, invalidator, newConfigSyncTestConfig(config.ConfigSyncRoleReplica, server.URL+"/"))
+
+	result, err := replica.SyncOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"groups", "api_keys"}, result.ChangedTables)
+
+	require.Len(t, replicaRepo.applied, 1)
+	applied := replicaRepo.applied[0]
+	require.Equal(t, ConfigSyncFormatVersion, applied.Version)
+	require.Equal(t, "174_add_reserved_capacity.sql", applied.SchemaVersion)
+	require.JSONEq(t, `[{"id":1}]`, string(applied.Tables["groups"]))
+
+	require.Equal(t, []string{"sk-a"}, invalidator.keys)
+	require.Equal(t, []int64{7}, invalidator.users)
+	require.Equal(t, []int64{1}, invalidator.groups)
+	require.Equal(t, []int64{5}, invalidator.projects)
+
+	status := replica.Status()
+	require.Equal(t, config.ConfigSyncRoleReplica, status.Role)
+	require.NotNil(t, status.LastSuccessAt)
+	require.Empty(t, status.LastError)
+	require.Equal(t, map[string]int{"groups": 1}, status.LastRowCounts)
+}
+
+func TestConfigSyncService_SyncOnceRejectsWrongToken(t *testing.T) {
+	primary := NewConfigSyncService(&configSyncRepoStub{snapshot: &ConfigSyncSnapshot{}}, nil, newConfigSyncTestConfig(config.ConfigSyncRolePrimary, ""))
+	server := newConfigSyncPrimaryServer(t, primary)
+	defer server.Close()
+
+	cfg := newConfigSyncTestConfig(config.ConfigSyncRoleReplica, server.URL)
+	cfg.ConfigSync.Token = strings.Repeat("x", 32)
+	replicaRepo := &configSyncRepoStub{}
+	replica := NewConfigSyncService(replicaRepo, nil, cfg)
+
+	_, err := replica.SyncOnce(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "status 401")
+	require.Empty(t, replicaRepo.applied)
+
+	status := replica.Status()
+	require.NotNil(t, status.LastAttemptAt)
+	require.Nil(t, status.LastSuccessAt)
+	require.NotEmpty(t, status.LastError)
+}
+
+func TestConfigSyncService_SyncOnceRejectsUnknownFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0,"message":"success","data":{"version":99,"tables":{}}}`))
+	}))
+	defer server.Close()
+
+	replicaRepo := &configSyncRepoStub{}
+	replica := NewConfigSyncService(replicaRepo, nil, newConfigSyncTestConfig(config.ConfigSyncRoleReplica, server.URL))
+
+	_, err := replica.SyncOnce(context.Background())
+	require.ErrorIs(t, err, ErrConfigSyncFormatMismatch)
+	require.Empty(t, replicaRepo.applied)
+}
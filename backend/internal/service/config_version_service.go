@@ -0,0 +1,596 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/model"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 纳入版本管理的配置实体类型。
+const (
+	ConfigEntityGroup                = "group"
+	ConfigEntityAccount              = "account"
+	ConfigEntityChannel              = "channel"
+	ConfigEntityErrorPassthroughRule = "error_passthrough_rule"
+)
+
+// 配置版本记录的动作类型。
+const (
+	ConfigVersionActionBaseline = "baseline"
+	ConfigVersionActionCreate   = "create"
+	ConfigVersionActionUpdate   = "update"
+	ConfigVersionActionDelete   = "delete"
+	ConfigVersionActionRollback = "rollback"
+)
+
+// 差异条目的变更类型。
+const (
+	ConfigDiffAdded   = "added"
+	ConfigDiffRemoved = "removed"
+	ConfigDiffChanged = "changed"
+)
+
+var (
+	ErrConfigVersionNotFound      = infraerrors.NotFound("CONFIG_VERSION_NOT_FOUND", "config version not found")
+	ErrConfigVersionEntityInvalid = infraerrors.BadRequest("CONFIG_VERSION_ENTITY_INVALID", "unsupported config entity type")
+	ErrConfigVersionEntityDeleted = infraerrors.Conflict("CONFIG_VERSION_ENTITY_DELETED", "config entity has been deleted and cannot be rolled back")
+)
+
+// accountRuntimeExtraKeys 账号 Extra 中由运行时维护的键，不属于管理员配置，快照时剔除以免产生无意义的版本。
+var accountRuntimeExtraKeys = []string{
+	"quota_used", "quota_daily_used", "quota_daily_start", "quota_weekly_used", "quota_weekly_start",
+	modelRateLimitsKey, "antigravity_credits_overages",
+}
+
+// ConfigVersion 配置实体的一个历史版本。
+type ConfigVersion struct {
+	ID           int64           `json:"id"`
+	EntityType   string          `json:"entity_type"`
+	EntityID     int64           `json:"entity_id"`
+	Version      int             `json:"version"`
+	Action       string          `json:"action"`
+	Snapshot     json.RawMessage `json:"snapshot"`
+	SnapshotHash string          `json:"snapshot_hash"`
+	OperatorID   *int64          `json:"operator_id,omitempty"`
+	RestoredFrom *int            `json:"restored_from,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// ConfigVersionDiffEntry 两个版本间单个字段路径的变化。
+type ConfigVersionDiffEntry struct {
+	Path string `json:"path"`
+	Op   string `json:"op"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// ConfigVersionDiff 两个版本的字段级差异。
+type ConfigVersionDiff struct {
+	EntityType  string                   `json:"entity_type"`
+	EntityID    int64                    `json:"entity_id"`
+	FromVersion int                      `json:"from_version"`
+	ToVersion   int                      `json:"to_version"`
+	Changes     []ConfigVersionDiffEntry `json:"changes"`
+}
+
+// ConfigVersionRepository 配置版本存储。
+type ConfigVersionRepository interface {
+	// Append 以该实体当前最大版本号 +1 追加记录，并回填 ID/Version/CreatedAt。
+	Append(ctx context.Context, v *ConfigVersion) error
+	// Latest 返回实体的最新版本；不存在时返回 (nil, nil)。
+	Latest(ctx context.Context, entityType string, entityID int64) (*ConfigVersion, error)
+	GetByVersion(ctx context.Context, entityType string, entityID int64, version int) (*ConfigVersion, error)
+	List(ctx context.Context, params pagination.PaginationParams, entityType string, entityID int64) ([]*ConfigVersion, *pagination.PaginationResult, error)
+}
+
+// accountConfigSnapshot 账号快照只保留管理员可编辑的字段，凭证中的敏感键（token/密钥）一律剔除。
+type accountConfigSnapshot struct {
+	Name               string         `json:"name"`
+	Notes              *string        `json:"notes"`
+	Platform           string         `json:"platform"`
+	Type               string         `json:"type"`
+	Credentials        map[string]any `json:"credentials"`
+	Extra              map[string]any `json:"extra"`
+	ProxyID            *int64         `json:"proxy_id"`
+	Concurrency        int            `json:"concurrency"`
+	Priority           int            `json:"priority"`
+	RateMultiplier     *float64       `json:"rate_multiplier"`
+	LoadFactor         *int           `json:"load_factor"`
+	Status             string         `json:"status"`
+	GroupIDs           []int64        `json:"group_ids"`
+	ExpiresAt          *time.Time     `json:"expires_at"`
+	AutoPauseOnExpired bool           `json:"auto_pause_on_expired"`
+}
+
+// ConfigVersionService 记录管理端配置变更历史，并支持版本差异对比与回滚。
+type ConfigVersionService struct {
+	repo                    ConfigVersionRepository
+	groupRepo               GroupRepository
+	accountRepo             AccountRepository
+	adminService            AdminService
+	channelService          *ChannelService
+	errorPassthroughService *ErrorPassthroughService
+	authCacheInvalidator    APIKeyAuthCacheInvalidator
+}
+
+// NewConfigVersionService creates a new ConfigVersionService
+func NewConfigVersionService(
+	repo ConfigVersionRepository,
+	groupRepo GroupRepository,
+	accountRepo AccountRepository,
+	adminService AdminService,
+	channelService *ChannelService,
+	errorPassthroughService *ErrorPassthroughService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+) *ConfigVersionService {
+	return &ConfigVersionService{
+		repo:                    repo,
+		groupRepo:               groupRepo,
+		accountRepo:             accountRepo,
+		adminService:            adminService,
+		channelService:          channelService,
+		errorPassthroughService: errorPassthroughService,
+		authCacheInvalidator:    authCacheInvalidator,
+	}
+}
+
+// IsValidConfigEntityType 判断实体类型是否纳入版本管理。
+func IsValidConfigEntityType(entityType string) bool {
+	switch entityType {
+	case ConfigEntityGroup, ConfigEntityAccount, ConfigEntityChannel, ConfigEntityErrorPassthroughRule:
+		return true
+	}
+	return false
+}
+
+// EnsureBaseline 实体尚无任何版本时补录当前状态作为基线，使首次变更也能回滚到变更前。
+// 实体不存在（如创建前）时静默跳过。
+func (s *ConfigVersionService) EnsureBaseline(ctx context.Context, entityType string, entityID int64, operatorID *int64) error {
+	if !IsValidConfigEntityType(entityType) {
+		return ErrConfigVersionEntityInvalid
+	}
+	latest, err := s.repo.Latest(ctx, entityType, entityID)
+	if err != nil || latest != nil {
+		return err
+	}
+	snapshot, err := s.snapshot(ctx, entityType, entityID)
+	if err != nil {
+		if infraerrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	_, err = s.appendVersion(ctx, entityType, entityID, ConfigVersionActionBaseline, snapshot, operatorID, nil)
+	return err
+}
+
+// Capture 在变更成功后记录实体的新版本。内容与最新版本一致时不重复记录，返回 (nil, nil)。
+// 删除动作沿用最后一个版本的快照，作为删除前状态的墓碑记录。
+func (s *ConfigVersionService) Capture(ctx context.Context, entityType string, entityID int64, action string, operatorID *int64) (*ConfigVersion, error) {
+	if !IsValidConfigEntityType(entityType) {
+		return nil, ErrConfigVersionEntityInvalid
+	}
+	latest, err := s.repo.Latest(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if action == ConfigVersionActionDelete {
+		if latest == nil || latest.Action == ConfigVersionActionDelete {
+			return nil, nil
+		}
+		return s.appendVersion(ctx, entityType, entityID, action, latest.Snapshot, operatorID, nil)
+	}
+
+	snapshot, err := s.snapshot(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.Action != ConfigVersionActionDelete && latest.SnapshotHash == hashConfigSnapshot(snapshot) {
+		return nil, nil
+	}
+	return s.appendVersion(ctx, entityType, entityID, action, snapshot, operatorID, nil)
+}
+
+// List 分页返回实体的版本历史（新版本在前）。
+func (s *ConfigVersionService) List(ctx context.Context, params pagination.PaginationParams, entityType string, entityID int64) ([]*ConfigVersion, *pagination.PaginationResult, error) {
+	if !IsValidConfigEntityType(entityType) {
+		return nil, nil, ErrConfigVersionEntityInvalid
+	}
+	return s.repo.List(ctx, params, entityType, entityID)
+}
+
+// Get 返回实体的指定版本。
+func (s *ConfigVersionService) Get(ctx context.Context, entityType string, entityID int64, version int) (*ConfigVersion, error) {
+	if !IsValidConfigEntityType(entityType) {
+		return nil, ErrConfigVersionEntityInvalid
+	}
+	return s.repo.GetByVersion(ctx, entityType, entityID, version)
+}
+
+// Diff 对比两个版本的快照，toVersion <= 0 时与最新版本对比。
+func (s *ConfigVersionService) Diff(ctx context.Context, entityType string, entityID int64, fromVersion, toVersion int) (*ConfigVersionDiff, error) {
+	from, err := s.Get(ctx, entityType, entityID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	var to *ConfigVersion
+	if toVersion > 0 {
+		to, err = s.repo.GetByVersion(ctx, entityType, entityID, toVersion)
+	} else {
+		to, err = s.repo.Latest(ctx, entityType, entityID)
+		if err == nil && to == nil {
+			err = ErrConfigVersionNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diffConfigSnapshots(from.Snapshot, to.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigVersionDiff{
+		EntityType:  entityType,
+		EntityID:    entityID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Changes:     changes,
+	}, nil
+}
+
+// Rollback 将实体恢复为指定版本的配置，并追加一条 rollback 版本。
+// 账号快照不含敏感凭证，回滚时保留当前的 token/密钥。
+func (s *ConfigVersionService) Rollback(ctx context.Context, entityType string, entityID int64, version int, operatorID *int64) (*ConfigVersion, error) {
+	target, err := s.Get(ctx, entityType, entityID, version)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.repo.Latest(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.Action == ConfigVersionActionDelete {
+		return nil, ErrConfigVersionEntityDeleted
+	}
+
+	if err := s.restore(ctx, entityType, entityID, target.Snapshot); err != nil {
+		if infraerrors.IsNotFound(err) {
+			return nil, ErrConfigVersionEntityDeleted
+		}
+		return nil, err
+	}
+
+	snapshot, err := s.snapshot(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	restoredFrom := target.Version
+	return s.appendVersion(ctx, entityType, entityID, ConfigVersionActionRollback, snapshot, operatorID, &restoredFrom)
+}
+
+func (s *ConfigVersionService) appendVersion(ctx context.Context, entityType string, entityID int64, action string, snapshot json.RawMessage, operatorID *int64, restoredFrom *int) (*ConfigVersion, error) {
+	v := &ConfigVersion{
+		EntityType:   entityType,
+		EntityID:     entityID,
+		Action:       action,
+		Snapshot:     snapshot,
+		SnapshotHash: hashConfigSnapshot(snapshot),
+		OperatorID:   operatorID,
+		RestoredFrom: restoredFrom,
+	}
+	if err := s.repo.Append(ctx, v); err != nil {
+		return nil, fmt.Errorf("append config version: %w", err)
+	}
+	return v, nil
+}
+
+// snapshot 读取实体当前状态并序列化为可回滚的 JSON 快照。
+func (s *ConfigVersionService) snapshot(ctx context.Context, entityType string, entityID int64) (json.RawMessage, error) {
+	var payload any
+	switch entityType {
+	case ConfigEntityGroup:
+		group, err := s.groupRepo.GetByID(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		payload = groupConfigSnapshot(group)
+	case ConfigEntityAccount:
+		account, err := s.accountRepo.GetByID(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		payload = newAccountConfigSnapshot(account)
+	case ConfigEntityChannel:
+		channel, err := s.channelService.GetByID(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		payload = channelConfigSnapshot(channel)
+	case ConfigEntityErrorPassthroughRule:
+		rule, err := s.errorPassthroughService.GetByID(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		if rule == nil {
+			return nil, ErrConfigVersionNotFound
+		}
+		clone := *rule
+		clone.CreatedAt, clone.UpdatedAt = time.Time{}, time.Time{}
+		payload = &clone
+	default:
+		return nil, ErrConfigVersionEntityInvalid
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s snapshot: %w", entityType, err)
+	}
+	return raw, nil
+}
+
+// restore 将快照写回实体，复用各实体现有的更新路径以保留校验与缓存失效逻辑。
+func (s *ConfigVersionService) restore(ctx context.Context, entityType string, entityID int64, snapshot json.RawMessage) error {
+	switch entityType {
+	case ConfigEntityGroup:
+		current, err := s.groupRepo.GetByID(ctx, entityID)
+		if err != nil {
+			return err
+		}
+		var restored Group
+		if err := json.Unmarshal(snapshot, &restored); err != nil {
+			return fmt.Errorf("decode group snapshot: %w", err)
+		}
+		restored.ID = current.ID
+		restored.Hydrated = current.Hydrated
+		restored.CreatedAt = current.CreatedAt
+		if err := s.groupRepo.Update(ctx, &restored); err != nil {
+			return err
+		}
+		if s.authCacheInvalidator != nil {
+			s.authCacheInvalidator.InvalidateAuthCacheByGroupID(ctx, entityID)
+		}
+		return nil
+	case ConfigEntityAccount:
+		var snap accountConfigSnapshot
+		if err := json.Unmarshal(snapshot, &snap); err != nil {
+			return fmt.Errorf("decode account snapshot: %w", err)
+		}
+		_, err := s.adminService.UpdateAccount(ctx, entityID, snap.toUpdateInput())
+		return err
+	case ConfigEntityChannel:
+		var restored Channel
+		if err := json.Unmarshal(snapshot, &restored); err != nil {
+			return fmt.Errorf("decode channel snapshot: %w", err)
+		}
+		_, err := s.channelService.Update(ctx, entityID, channelUpdateInputFromSnapshot(&restored))
+		return err
+	case ConfigEntityErrorPassthroughRule:
+		var restored model.ErrorPassthroughRule
+		if err := json.Unmarshal(snapshot, &restored); err != nil {
+			return fmt.Errorf("decode error passthrough rule snapshot: %w", err)
+		}
+		restored.ID = entityID
+		_, err := s.errorPassthroughService.Update(ctx, &restored)
+		return err
+	}
+	return ErrConfigVersionEntityInvalid
+}
+
+// groupConfigSnapshot 去掉分组上的统计/关联等派生字段，只保留可配置项。
+func groupConfigSnapshot(group *Group) *Group {
+	clone := *group
+	clone.Hydrated = false
+	clone.CreatedAt, clone.UpdatedAt = time.Time{}, time.Time{}
+	clone.AccountGroups = nil
+	clone.AccountCount, clone.ActiveAccountCount, clone.RateLimitedAccountCount = 0, 0, 0
+	return &clone
+}
+
+func newAccountConfigSnapshot(account *Account) *accountConfigSnapshot {
+	credentials := make(map[string]any, len(account.Credentials))
+	for k, v := range account.Credentials {
+		if !IsSensitiveCredentialKey(k) {
+			credentials[k] = v
+		}
+	}
+	extra := make(map[string]any, len(account.Extra))
+	for k, v := range account.Extra {
+		extra[k] = v
+	}
+	for _, key := range accountRuntimeExtraKeys {
+		delete(extra, key)
+	}
+	groupIDs := append([]int64{}, account.GroupIDs...)
+	sort.Slice(groupIDs, func(i, j int) bool { return groupIDs[i] < groupIDs[j] })
+	return &accountConfigSnapshot{
+		Name:               account.Name,
+		Notes:              account.Notes,
+		Platform:           account.Platform,
+		Type:               account.Type,
+		Credentials:        credentials,
+		Extra:              extra,
+		ProxyID:            account.ProxyID,
+		Concurrency:        account.Concurrency,
+		Priority:           account.Priority,
+		RateMultiplier:     account.RateMultiplier,
+		LoadFactor:         account.LoadFactor,
+		Status:             account.Status,
+		GroupIDs:           groupIDs,
+		ExpiresAt:          account.ExpiresAt,
+		AutoPauseOnExpired: account.AutoPauseOnExpired,
+	}
+}
+
+// toUpdateInput 转成全量更新输入：nil 的可选字段显式置零，保证回滚能清除快照之后新增的设置。
+func (snap *accountConfigSnapshot) toUpdateInput() *UpdateAccountInput {
+	var proxyID, expiresAt int64
+	if snap.ProxyID != nil {
+		proxyID = *snap.ProxyID
+	}
+	loadFactor := 0
+	if snap.LoadFactor != nil {
+		loadFactor = *snap.LoadFactor
+	}
+	if snap.ExpiresAt != nil {
+		expiresAt = snap.ExpiresAt.Unix()
+	}
+	notes := ""
+	if snap.Notes != nil {
+		notes = *snap.Notes
+	}
+	rateMultiplier := 1.0
+	if snap.RateMultiplier != nil {
+		rateMultiplier = *snap.RateMultiplier
+	}
+	concurrency, priority := snap.Concurrency, snap.Priority
+	autoPause := snap.AutoPauseOnExpired
+	groupIDs := append([]int64{}, snap.GroupIDs...)
+	extra := snap.Extra
+	if extra == nil {
+		extra = map[string]any{}
+	}
+	return &UpdateAccountInput{
+		Name:                  snap.Name,
+		Notes:                 &notes,
+		Type:                  snap.Type,
+		Credentials:           snap.Credentials,
+		Extra:                 extra,
+		ProxyID:               &proxyID,
+		Concurrency:           &concurrency,
+		Priority:              &priority,
+		RateMultiplier:        &rateMultiplier,
+		LoadFactor:            &loadFactor,
+		Status:                snap.Status,
+		GroupIDs:              &groupIDs,
+		ExpiresAt:             &expiresAt,
+		AutoPauseOnExpired:    &autoPause,
+		SkipMixedChannelCheck: true,
+	}
+}
+
+// channelConfigSnapshot 去掉定价/规则行上的 ID 与时间戳：这些行在每次更新时会重建，保留会让每个版本都显示为变化。
+func channelConfigSnapshot(channel *Channel) *Channel {
+	clone := *channel
+	clone.CreatedAt, clone.UpdatedAt = time.Time{}, time.Time{}
+	clone.ModelPricing = stripChannelPricingIdentity(channel.ModelPricing)
+	clone.AccountStatsPricingRules = make([]AccountStatsPricingRule, len(channel.AccountStatsPricingRules))
+	for i, rule := range channel.AccountStatsPricingRules {
+		rule.ID, rule.ChannelID = 0, 0
+		rule.CreatedAt, rule.UpdatedAt = time.Time{}, time.Time{}
+		rule.Pricing = stripChannelPricingIdentity(rule.Pricing)
+		clone.AccountStatsPricingRules[i] = rule
+	}
+	return &clone
+}
+
+func stripChannelPricingIdentity(pricing []ChannelModelPricing) []ChannelModelPricing {
+	out := make([]ChannelModelPricing, len(pricing))
+	for i, p := range pricing {
+		p.ID, p.ChannelID = 0, 0
+		p.CreatedAt, p.UpdatedAt = time.Time{}, time.Time{}
+		intervals := make([]PricingInterval, len(p.Intervals))
+		for j, iv := range p.Intervals {
+			iv.ID, iv.PricingID = 0, 0
+			iv.CreatedAt, iv.UpdatedAt = time.Time{}, time.Time{}
+			intervals[j] = iv
+		}
+		p.Intervals = intervals
+		out[i] = p
+	}
+	return out
+}
+
+func channelUpdateInputFromSnapshot(ch *Channel) *UpdateChannelInput {
+	description := ch.Description
+	restrictModels := ch.RestrictModels
+	features := ch.Features
+	applyPricing := ch.ApplyPricingToAccountStats
+	groupIDs := append([]int64{}, ch.GroupIDs...)
+	pricing := append([]ChannelModelPricing{}, ch.ModelPricing...)
+	rules := append([]AccountStatsPricingRule{}, ch.AccountStatsPricingRules...)
+	modelMapping := ch.ModelMapping
+	if modelMapping == nil {
+		modelMapping = map[string]map[string]string{}
+	}
+	featuresConfig := ch.FeaturesConfig
+	if featuresConfig == nil {
+		featuresConfig = map[string]any{}
+	}
+	return &UpdateChannelInput{
+		Name:                       ch.Name,
+		Description:                &description,
+		Status:                     ch.Status,
+		GroupIDs:                   &groupIDs,
+		ModelPricing:               &pricing,
+		ModelMapping:               modelMapping,
+		BillingModelSource:         ch.BillingModelSource,
+		RestrictModels:             &restrictModels,
+		Features:                   &features,
+		FeaturesConfig:             featuresConfig,
+		ApplyPricingToAccountStats: &applyPricing,
+		AccountStatsPricingRules:   &rules,
+	}
+}
+
+func hashConfigSnapshot(snapshot json.RawMessage) string {
+	sum := sha256.Sum256(snapshot)
+	return hex.EncodeToString(sum[:])
+}
+
+// diffConfigSnapshots 将两个快照展开为字段路径后逐项对比，结果按路径排序。
+// 对象按键展开，数组整体比较，避免元素位移时产生大量噪音。
+func diffConfigSnapshots(from, to json.RawMessage) ([]ConfigVersionDiffEntry, error) {
+	var fromValue, toValue any
+	if err := json.Unmarshal(from, &fromValue); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if err := json.Unmarshal(to, &toValue); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	fromFlat := map[string]any{}
+	toFlat := map[string]any{}
+	flattenConfigSnapshot("", fromValue, fromFlat)
+	flattenConfigSnapshot("", toValue, toFlat)
+
+	changes := make([]ConfigVersionDiffEntry, 0)
+	for path, before := range fromFlat {
+		after, ok := toFlat[path]
+		switch {
+		case !ok:
+			changes = append(changes, ConfigVersionDiffEntry{Path: path, Op: ConfigDiffRemoved, From: before})
+		case !reflect.DeepEqual(before, after):
+			changes = append(changes, ConfigVersionDiffEntry{Path: path, Op: ConfigDiffChanged, From: before, To: after})
+		}
+	}
+	for path, after := range toFlat {
+		if _, ok := fromFlat[path]; !ok {
+			changes = append(changes, ConfigVersionDiffEntry{Path: path, Op: ConfigDiffAdded, To: after})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func flattenConfigSnapshot(prefix string, value any, out map[string]any) {
+	obj, ok := value.(map[string]any)
+	if !ok || (len(obj) == 0 && prefix != "") {
+		out[prefix] = value
+		return
+	}
+	for k, v := range obj {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		flattenConfigSnapshot(path, v, out)
+	}
+}
@@ -0,0 +1,199 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigVersionService_GroupHistoryDiffAndRollback(t *testing.T) {
+	ctx := context.Background()
+	repo := &configVersionRepoStub{}
+	groups := &configVersionGroupRepoStub{group: &Group{ID: 3, Name: "prod", RateMultiplier: 1, AccountCount: 9}}
+	invalidator := &configVersionInvalidatorStub{}
+	svc := NewConfigVersionService(repo, groups, nil, nil, nil, nil, invalidator)
+	operator := int64(1)
+
+	require.NoError(t, svc.EnsureBaseline(ctx, ConfigEntityGroup, 3, &operator))
+	require.NoError(t, svc.EnsureBaseline(ctx, ConfigEntityGroup, 3, &operator), "baseline is only recorded once")
+	require.Len(t, repo.versions, 1)
+
+	groups.group.Name = "prod-v2"
+	groups.group.RateMultiplier = 1.5
+	groups.group.AccountCount = 12
+	v, err := svc.Capture(ctx, ConfigEntityGroup, 3, ConfigVersionActionUpdate, &operator)
+	require.NoError(t, err)
+	require.Equal(t, 2, v.Version)
+
+	groups.group.AccountCount = 20
+	v, err = svc.Capture(ctx, ConfigEntityGroup, 3, ConfigVersionActionUpdate, &operator)
+	require.NoError(t, err)
+	require.Nil(t, v, "derived fields alone do not produce a version")
+
+	diff, err := svc.Diff(ctx, ConfigEntityGroup, 3, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, diff.ToVersion)
+	paths := make([]string, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		require.Equal(t, ConfigDiffChanged, change.Op)
+		paths = append(paths, change.Path)
+	}
+	require.Equal(t, []string{"Name", "RateMultiplier"}, paths)
+
+	v, err = svc.Rollback(ctx, ConfigEntityGroup, 3, 1, &operator)
+	require.NoError(t, err)
+	require.Equal(t, ConfigVersionActionRollback, v.Action)
+	require.Equal(t, 1, *v.RestoredFrom)
+	require.Equal(t, "prod", groups.group.Name)
+	require.Equal(t, 1.0, groups.group.RateMultiplier)
+	require.Equal(t, []int64{3}, invalidator.groupIDs)
+}
+
+func TestConfigVersionService_AccountSnapshotOmitsSecrets(t *testing.T) {
+	ctx := context.Background()
+	repo := &configVersionRepoStub{}
+	accounts := &configVersionAccountRepoStub{account: &Account{
+		ID:          7,
+		Name:        "claude-a",
+		Platform:    PlatformAnthropic,
+		Type:        AccountTypeOAuth,
+		Credentials: map[string]any{"access_token": "secret", "refresh_token": "secret2", "base_url": "https://example.com"},
+		Extra:       map[string]any{"quota_used": 12.5, "custom_flag": true},
+		Concurrency: 3,
+		Status:      StatusActive,
+		GroupIDs:    []int64{5, 2},
+	}}
+	admin := &configVersionAdminStub{}
+	svc := NewConfigVersionService(repo, nil, accounts, admin, nil, nil, nil)
+
+	v, err := svc.Capture(ctx, ConfigEntityAccount, 7, ConfigVersionActionCreate, nil)
+	require.NoError(t, err)
+	require.NotContains(t, string(v.Snapshot), "secret")
+	require.NotContains(t, string(v.Snapshot), "quota_used")
+
+	var snap map[string]any
+	require.NoError(t, json.Unmarshal(v.Snapshot, &snap))
+	require.Equal(t, []any{float64(2), float64(5)}, snap["group_ids"])
+
+	_, err = svc.Rollback(ctx, ConfigEntityAccount, 7, 1, nil)
+	require.NoError(t, err)
+	require.NotNil(t, admin.input)
+	require.Equal(t, map[string]any{"base_url": "https://example.com"}, admin.input.Credentials)
+	require.Equal(t, int64(0), *admin.input.ProxyID, "missing proxy clears the current one")
+	require.Equal(t, 3, *admin.input.Concurrency)
+	require.True(t, admin.input.SkipMixedChannelCheck)
+}
+
+func TestConfigVersionService_RollbackAfterDeleteRejected(t *testing.T) {
+	ctx := context.Background()
+	repo := &configVersionRepoStub{}
+	groups := &configVersionGroupRepoStub{group: &Group{ID: 3, Name: "prod"}}
+	svc := NewConfigVersionService(repo, groups, nil, nil, nil, nil, nil)
+
+	require.NoError(t, svc.EnsureBaseline(ctx, ConfigEntityGroup, 3, nil))
+	v, err := svc.Capture(ctx, ConfigEntityGroup, 3, ConfigVersionActionDelete, nil)
+	require.NoError(t, err)
+	require.Equal(t, ConfigVersionActionDelete, v.Action)
+	require.JSONEq(t, string(repo.versions[0].Snapshot), string(v.Snapshot))
+
+	_, err = svc.Rollback(ctx, ConfigEntityGroup, 3, 1, nil)
+	require.ErrorIs(t, err, ErrConfigVersionEntityDeleted)
+
+	_, err = svc.Capture(ctx, "settings", 1, ConfigVersionActionUpdate, nil)
+	require.ErrorIs(t, err, ErrConfigVersionEntityInvalid)
+}
+
+type configVersionRepoStub struct {
+	versions []*ConfigVersion
+}
+
+func (s *configVersionRepoStub) Append(ctx context.Context, v *ConfigVersion) error {
+	v.ID = int64(len(s.versions) + 1)
+	v.Version = 1
+	if latest, _ := s.Latest(ctx, v.EntityType, v.EntityID); latest != nil {
+		v.Version = latest.Version + 1
+	}
+	s.versions = append(s.versions, v)
+	return nil
+}
+
+func (s *configVersionRepoStub) Latest(ctx context.Context, entityType string, entityID int64) (*ConfigVersion, error) {
+	var latest *ConfigVersion
+	for _, v := range s.versions {
+		if v.EntityType == entityType && v.EntityID == entityID {
+			latest = v
+		}
+	}
+	return latest, nil
+}
+
+func (s *configVersionRepoStub) GetByVersion(ctx context.Context, entityType string, entityID int64, version int) (*ConfigVersion, error) {
+	for _, v := range s.versions {
+		if v.EntityType == entityType && v.EntityID == entityID && v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, ErrConfigVersionNotFound
+}
+
+func (s *configVersionRepoStub) List(ctx context.Context, params pagination.PaginationParams, entityType string, entityID int64) ([]*ConfigVersion, *pagination.PaginationResult, error) {
+	return s.versions, &pagination.PaginationResult{Total: int64(len(s.versions))}, nil
+}
+
+type configVersionGroupRepoStub struct {
+	GroupRepository
+	group *Group
+}
+
+func (s *configVersionGroupRepoStub) GetByID(ctx context.Context, id int64) (*Group, error) {
+	if s.group == nil || s.group.ID != id {
+		return nil, ErrGroupNotFound
+	}
+	clone := *s.group
+	return &clone, nil
+}
+
+func (s *configVersionGroupRepoStub) Update(ctx context.Context, group *Group) error {
+	s.group = group
+	return nil
+}
+
+type configVersionAccountRepoStub struct {
+	AccountRepository
+	account *Account
+}
+
+func (s *configVersionAccountRepoStub) GetByID(ctx context.Context, id int64) (*Account, error) {
+	if s.account == nil || s.account.ID != id {
+		return nil, ErrAccountNotFound
+	}
+	return s.account, nil
+}
+
+type configVersionAdminStub struct {
+	AdminService
+	input *UpdateAccountInput
+}
+
+func (s *configVersionAdminStub) UpdateAccount(ctx context.Context, id int64, input *UpdateAccountInput) (*Account, error) {
+	s.input = input
+	return &Account{ID: id}, nil
+}
+
+type configVersionInvalidatorStub struct {
+	groupIDs []int64
+}
+
+func (s *configVersionInvalidatorStub) InvalidateAuthCacheByKey(ctx context.Context, key string) {}
+
+func (s *configVersionInvalidatorStub) InvalidateAuthCacheByUserID(ctx context.Context, userID int64) {
+}
+
+func (s *configVersionInvalidatorStub) InvalidateAuthCacheByGroupID(ctx context.Context, groupID int64) {
+	s.groupIDs = append(s.groupIDs, groupID)
+}
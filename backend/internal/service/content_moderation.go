@@ -513,6 +513,7 @@ type ContentModerationService struct {
 	lastCleanupDeletedNonHit atomic.Int64
 	keyHealthMu              sync.Mutex
 	keyHealth                map[string]*contentModerationKeyHealth
+	intrusionConfig          atomic.Pointer[intrusionConfigCache]
 }
 
 type contentModerationTask struct {
@@ -784,6 +785,9 @@ func (s *ContentModerationService) Check(ctx context.Context, input ContentModer
 			"protocol", input.Protocol)
 		return allow, nil
 	}
+	if decision := s.checkIntrusion(ctx, input); decision != nil {
+		return decision, nil
+	}
 	cfg, err := s.loadConfig(ctx)
 	if err != nil {
 		slog.Warn("content_moderation.skip_config_load_failed",
@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 入侵检测：在内容审计之前按正则/启发式规则识别提示注入与数据外泄特征，
+// 按分组执行 log（仅记录）/ flag（标记命中）/ block（拦截）。命中记录写入风控日志（action 前缀 intrusion_），
+// 不参与自动封号计数。仅受 risk_control_enabled 总开关与自身 enabled 约束，与内容审计的开关/模式/抽样无关。
+const (
+	IntrusionActionOff   = "off"
+	IntrusionActionLog   = "log"
+	IntrusionActionFlag  = "flag"
+	IntrusionActionBlock = "block"
+
+	IntrusionCategoryPromptInjection  = "prompt_injection"
+	IntrusionCategoryDataExfiltration = "data_exfiltration"
+
+	ContentModerationActionIntrusionLog   = "intrusion_log"
+	ContentModerationActionIntrusionFlag  = "intrusion_flag"
+	ContentModerationActionIntrusionBlock = "intrusion_block"
+
+	contentModerationIntrusionMode = "intrusion"
+
+	defaultIntrusionBlockMessage = "请求命中安全检测规则（疑似提示注入或数据外泄），已被拦截"
+	maxIntrusionRules            = 200
+	maxIntrusionRuleNameRunes    = 100
+	maxIntrusionRulePatternRunes = 1000
+	maxIntrusionGroupActions     = 1000
+	maxIntrusionScanRunes        = 64000
+)
+
+// IntrusionRule 自定义检测规则（Go RE2 正则，默认大小写不敏感）。
+type IntrusionRule struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Pattern  string `json:"pattern"`
+}
+
+// IntrusionDetectionConfig 入侵检测配置（存储于 settings，JSON）。
+type IntrusionDetectionConfig struct {
+	Enabled bool `json:"enabled"`
+	// DefaultAction 未在 GroupActions 中单独配置的分组（含无分组请求）使用的动作
+	DefaultAction string `json:"default_action"`
+	// GroupActions 分组级动作覆盖：group_id → off/log/flag/block
+	GroupActions map[int64]string `json:"group_actions"`
+	// BuiltinRulesEnabled 是否启用内置规则与启发式检测
+	BuiltinRulesEnabled bool            `json:"builtin_rules_enabled"`
+	Rules               []IntrusionRule `json:"rules"`
+	BlockStatus         int             `json:"block_status"`
+	BlockMessage        string          `json:"block_message"`
+}
+
+// IntrusionDetectionConfigView 管理端视图，附带只读的内置规则列表。
+type IntrusionDetectionConfigView struct {
+	IntrusionDetectionConfig
+	BuiltinRules []IntrusionRule `json:"builtin_rules"`
+}
+
+type UpdateIntrusionDetectionConfigInput struct {
+	Enabled             *bool             `json:"enabled"`
+	DefaultAction       *string           `json:"default_action"`
+	GroupActions        *map[int64]string `json:"group_actions"`
+	BuiltinRulesEnabled *bool             `json:"builtin_rules_enabled"`
+	Rules               *[]IntrusionRule  `json:"rules"`
+	BlockStatus         *int              `json:"block_status"`
+	BlockMessage        *string           `json:"block_message"`
+}
+
+// IntrusionMatch 一次规则命中。
+type IntrusionMatch struct {
+	Rule     string `json:"rule"`
+	Category string `json:"category"`
+	Builtin  bool   `json:"builtin"`
+}
+
+type compiledIntrusionRule struct {
+	IntrusionRule
+	re *regexp.Regexp
+}
+
+// intrusionConfigCache 按原始 JSON 缓存解析与编译结果，避免每个请求重复编译自定义正则。
+type intrusionConfigCache struct {
+	raw   string
+	cfg   *IntrusionDetectionConfig
+	rules []compiledIntrusionRule
+}
+
+// builtinIntrusionRules 内置规则：覆盖常见的越狱/指令覆盖、系统提示词窃取与凭证外带特征。
+var builtinIntrusionRules = []compiledIntrusionRule{
+	builtinIntrusionRule("ignore_previous_instructions", IntrusionCategoryPromptInjection,
+		`\b(ignore|disregard|forget|override)\b.{0,30}\b(all|any|the|your)?\s*(previous|prior|above|earlier|preceding|system)\s+(instructions?|prompts?|rules?|directives?)`),
+	builtinIntrusionRule("ignore_previous_instructions_zh", IntrusionCategoryPromptInjection,
+		`(忽略|无视|忘记|忘掉)(掉)?(之前|以上|上面|前面|先前|所有)(的)?(所有)?(指令|指示|提示|规则|设定)`),
+	builtinIntrusionRule("system_prompt_extraction", IntrusionCategoryPromptInjection,
+		`\b(reveal|print|show|repeat|output|dump|leak)\b.{0,30}\b(your|the)\s+(system\s+prompt|initial\s+instructions|hidden\s+instructions|developer\s+message)`),
+	builtinIntrusionRule("jailbreak_persona", IntrusionCategoryPromptInjection,
+		`\b(you\s+are\s+now\s+(DAN|in\s+developer\s+mode|jailbroken)|do\s+anything\s+now|developer\s+mode\s+enabled)\b`),
+	builtinIntrusionRule("fake_system_turn", IntrusionCategoryPromptInjection,
+		`(<\|im_start\|>\s*system|\[/?INST\]|<<SYS>>|</?system>)`),
+	builtinIntrusionRule("markdown_image_exfiltration", IntrusionCategoryDataExfiltration,
+		`!\[[^\]]*\]\(\s*https?://[^)\s]*\?[^)\s]*=[^)\s]*[{\[<$][^)\s]*\)`),
+	builtinIntrusionRule("send_secrets_to_url", IntrusionCategoryDataExfiltration,
+		`\b(send|post|upload|exfiltrate|forward|curl|wget)\b.{0,60}\b(api[_\s-]?keys?|secrets?|credentials?|passwords?|tokens?|env(ironment)?\s+variables?|\.env|ssh\s+keys?)\b.{0,60}https?://`),
+	builtinIntrusionRule("private_key_material", IntrusionCategoryDataExfiltration,
+		`-----BEGIN (RSA |EC |DSA |OPENSSH |PGP )?PRIVATE KEY( BLOCK)?-----`),
+	builtinIntrusionRule("cloud_access_key", IntrusionCategoryDataExfiltration,
+		`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+}
+
+func builtinIntrusionRule(name, category, pattern string) compiledIntrusionRule {
+	return compiledIntrusionRule{
+		IntrusionRule: IntrusionRule{Name: name, Category: category, Pattern: pattern},
+		re:            regexp.MustCompile(`(?is)` + pattern),
+	}
+}
+
+// BuiltinIntrusionRules 返回内置规则列表（供管理端展示）。
+func BuiltinIntrusionRules() []IntrusionRule {
+	out := make([]IntrusionRule, 0, len(builtinIntrusionRules)+1)
+	for _, rule := range builtinIntrusionRules {
+		out = append(out, rule.IntrusionRule)
+	}
+	return append(out, IntrusionRule{Name: "hidden_unicode_instructions", Category: IntrusionCategoryPromptInjection, Pattern: "heuristic: unicode tag / bidi override characters"})
+}
+
+// detectHiddenUnicodeInstructions 启发式：Unicode Tag 字符（U+E0000–U+E007F）常用于隐藏不可见指令，
+// 双向覆盖字符（U+202A–U+202E、U+2066–U+2069）常用于伪装文本顺序。
+func detectHiddenUnicodeInstructions(text string) bool {
+	tags, bidi := 0, 0
+	for _, r := range text {
+		switch {
+		case r >= 0xE0000 && r <= 0xE007F:
+			tags++
+		case (r >= 0x202A && r <= 0x202E) || (r >= 0x2066 && r <= 0x2069):
+			bidi++
+		}
+		if tags >= 4 || bidi >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// detectIntrusion 按内置规则（可选）与自定义规则扫描文本，返回全部命中。
+func detectIntrusion(text string, builtin bool, rules []compiledIntrusionRule) []IntrusionMatch {
+	text = trimRunes(text, maxIntrusionScanRunes)
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	var matches []IntrusionMatch
+	if builtin {
+		for _, rule := range builtinIntrusionRules {
+			if rule.re.MatchString(text) {
+				matches = append(matches, IntrusionMatch{Rule: rule.Name, Category: rule.Category, Builtin: true})
+			}
+		}
+		if detectHiddenUnicodeInstructions(text) {
+			matches = append(matches, IntrusionMatch{Rule: "hidden_unicode_instructions", Category: IntrusionCategoryPromptInjection, Builtin: true})
+		}
+	}
+	for _, rule := range rules {
+		if rule.re != nil && rule.re.MatchString(text) {
+			matches = append(matches, IntrusionMatch{Rule: rule.Name, Category: rule.Category})
+		}
+	}
+	return matches
+}
+
+// compileIntrusionRules 编译自定义规则；非法正则返回 BadRequest。
+func compileIntrusionRules(rules []IntrusionRule) ([]compiledIntrusionRule, error) {
+	out := make([]compiledIntrusionRule, 0, len(rules))
+	for i, rule := range rules {
+		re, err := regexp.Compile(`(?is)` + rule.Pattern)
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_INTRUSION_RULE", fmt.Sprintf("规则 #%d（%s）正则无效: %v", i+1, rule.Name, err))
+		}
+		out = append(out, compiledIntrusionRule{IntrusionRule: rule, re: re})
+	}
+	return out, nil
+}
+
+func defaultIntrusionDetectionConfig() *IntrusionDetectionConfig {
+	return &IntrusionDetectionConfig{
+		DefaultAction:       IntrusionActionLog,
+		GroupActions:        map[int64]string{},
+		BuiltinRulesEnabled: true,
+		Rules:               []IntrusionRule{},
+		BlockStatus:         http.StatusForbidden,
+		BlockMessage:        defaultIntrusionBlockMessage,
+	}
+}
+
+func normalizeIntrusionAction(action string) string {
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case IntrusionActionOff:
+		return IntrusionActionOff
+	case IntrusionActionFlag:
+		return IntrusionActionFlag
+	case IntrusionActionBlock:
+		return IntrusionActionBlock
+	default:
+		return IntrusionActionLog
+	}
+}
+
+func isValidIntrusionAction(action string) bool {
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case IntrusionActionOff, IntrusionActionLog, IntrusionActionFlag, IntrusionActionBlock:
+		return true
+	}
+	return false
+}
+
+func (cfg *IntrusionDetectionConfig) normalize() {
+	cfg.DefaultAction = normalizeIntrusionAction(cfg.DefaultAction)
+	groupActions := make(map[int64]string, len(cfg.GroupActions))
+	for groupID, action := range cfg.GroupActions {
+		if groupID > 0 {
+			groupActions[groupID] = normalizeIntrusionAction(action)
+		}
+	}
+	cfg.GroupActions = groupActions
+	rules := make([]IntrusionRule, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		rule.Name = trimRunes(strings.TrimSpace(rule.Name), maxIntrusionRuleNameRunes)
+		rule.Pattern = strings.TrimSpace(rule.Pattern)
+		if rule.Pattern == "" {
+			continue
+		}
+		if rule.Category != IntrusionCategoryDataExfiltration {
+			rule.Category = IntrusionCategoryPromptInjection
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("custom_%d", len(rules)+1)
+		}
+		rules = append(rules, rule)
+	}
+	cfg.Rules = rules
+	if cfg.BlockStatus < 400 || cfg.BlockStatus > 599 {
+		cfg.BlockStatus = http.StatusForbidden
+	}
+	cfg.BlockMessage = strings.TrimSpace(cfg.BlockMessage)
+	if cfg.BlockMessage == "" {
+		cfg.BlockMessage = defaultIntrusionBlockMessage
+	}
+}
+
+// actionFor 返回分组适用的动作（分组覆盖优先，否则默认动作）。
+func (cfg *IntrusionDetectionConfig) actionFor(groupID *int64) string {
+	if groupID != nil {
+		if action, ok := cfg.GroupActions[*groupID]; ok {
+			return action
+		}
+	}
+	return cfg.DefaultAction
+}
+
+func (s *ContentModerationService) GetIntrusionDetectionConfig(ctx context.Context) (*IntrusionDetectionConfigView, error) {
+	cfg, _, err := s.loadIntrusionDetectionConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return intrusionDetectionConfigView(cfg), nil
+}
+
+func (s *ContentModerationService) UpdateIntrusionDetectionConfig(ctx context.Context, input UpdateIntrusionDetectionConfigInput) (*IntrusionDetectionConfigView, error) {
+	cached, _, err := s.loadIntrusionDetectionConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := cloneIntrusionDetectionConfig(cached)
+	if input.Enabled != nil {
+		cfg.Enabled = *input.Enabled
+	}
+	if input.DefaultAction != nil {
+		if !isValidIntrusionAction(*input.DefaultAction) {
+			return nil, infraerrors.BadRequest("INVALID_INTRUSION_ACTION", "default_action 仅支持 off/log/flag/block")
+		}
+		cfg.DefaultAction = *input.DefaultAction
+	}
+	if input.GroupActions != nil {
+		if len(*input.GroupActions) > maxIntrusionGroupActions {
+			return nil, infraerrors.BadRequest("INVALID_INTRUSION_GROUP_ACTIONS", fmt.Sprintf("分组动作最多 %d 项", maxIntrusionGroupActions))
+		}
+		for groupID, action := range *input.GroupActions {
+			if groupID <= 0 || !isValidIntrusionAction(action) {
+				return nil, infraerrors.BadRequest("INVALID_INTRUSION_GROUP_ACTIONS", fmt.Sprintf("分组 %d 的动作无效，仅支持 off/log/flag/block", groupID))
+			}
+		}
+		cfg.GroupActions = *input.GroupActions
+	}
+	if input.BuiltinRulesEnabled != nil {
+		cfg.BuiltinRulesEnabled = *input.BuiltinRulesEnabled
+	}
+	if input.Rules != nil {
+		if len(*input.Rules) > maxIntrusionRules {
+			return nil, infraerrors.BadRequest("INVALID_INTRUSION_RULE", fmt.Sprintf("自定义规则最多 %d 条", maxIntrusionRules))
+		}
+		for i, rule := range *input.Rules {
+			if utf8.RuneCountInString(rule.Pattern) > maxIntrusionRulePatternRunes {
+				return nil, infraerrors.BadRequest("INVALID_INTRUSION_RULE", fmt.Sprintf("规则 #%d 正则过长（最多 %d 字符）", i+1, maxIntrusionRulePatternRunes))
+			}
+		}
+		if _, err := compileIntrusionRules(*input.Rules); err != nil {
+			return nil, err
+		}
+		cfg.Rules = *input.Rules
+	}
+	if input.BlockStatus != nil {
+		if *input.BlockStatus < 400 || *input.BlockStatus > 599 {
+			return nil, infraerrors.BadRequest("INVALID_INTRUSION_BLOCK_STATUS", "block_status 必须为 4xx/5xx")
+		}
+		cfg.BlockStatus = *input.BlockStatus
+	}
+	if input.BlockMessage != nil {
+		cfg.BlockMessage = *input.BlockMessage
+	}
+	cfg.normalize()
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyIntrusionDetectionConfig, string(raw)); err != nil {
+		return nil, fmt.Errorf("save intrusion detection config: %w", err)
+	}
+	return intrusionDetectionConfigView(cfg), nil
+}
+
+func intrusionDetectionConfigView(cfg *IntrusionDetectionConfig) *IntrusionDetectionConfigView {
+	return &IntrusionDetectionConfigView{IntrusionDetectionConfig: *cloneIntrusionDetectionConfig(cfg), BuiltinRules: BuiltinIntrusionRules()}
+}
+
+func cloneIntrusionDetectionConfig(cfg *IntrusionDetectionConfig) *IntrusionDetectionConfig {
+	out := *cfg
+	out.GroupActions = make(map[int64]string, len(cfg.GroupActions))
+	for groupID, action := range cfg.GroupActions {
+		out.GroupActions[groupID] = action
+	}
+	out.Rules = append([]IntrusionRule{}, cfg.Rules...)
+	return &out
+}
+
+// loadIntrusionDetectionConfig 读取配置并编译规则；返回的 cfg 为缓存共享对象，修改前需先 clone。
+func (s *ContentModerationService) loadIntrusionDetectionConfig(ctx context.Context) (*IntrusionDetectionConfig, []compiledIntrusionRule, error) {
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyIntrusionDetectionConfig)
+	if err != nil && !errors.Is(err, ErrSettingNotFound) {
+		return nil, nil, fmt.Errorf("get intrusion detection config: %w", err)
+	}
+	raw = strings.TrimSpace(raw)
+	if cached := s.intrusionConfig.Load(); cached != nil && cached.raw == raw {
+		return cached.cfg, cached.rules, nil
+	}
+	cfg := defaultIntrusionDetectionConfig()
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+			return nil, nil, infraerrors.BadRequest("INVALID_INTRUSION_DETECTION_CONFIG", "入侵检测配置不是有效 JSON")
+		}
+	}
+	cfg.normalize()
+	compiled, err := compileIntrusionRules(cfg.Rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.intrusionConfig.Store(&intrusionConfigCache{raw: raw, cfg: cfg, rules: compiled})
+	return cfg, compiled, nil
+}
+
+// checkIntrusion 执行入侵检测；仅在动作为 block 且命中时返回拦截决策，其余情况返回 nil 继续内容审计。
+func (s *ContentModerationService) checkIntrusion(ctx context.Context, input ContentModerationCheckInput) *ContentModerationDecision {
+	cfg, rules, err := s.loadIntrusionDetectionConfig(ctx)
+	if err != nil {
+		slog.Warn("content_moderation.intrusion_config_load_failed", "endpoint", input.Endpoint, "error", err)
+		return nil
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	action := cfg.actionFor(input.GroupID)
+	if action == IntrusionActionOff {
+		return nil
+	}
+	content := ExtractContentModerationInput(input.Protocol, input.Body)
+	matches := detectIntrusion(content.Text, cfg.BuiltinRulesEnabled, rules)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	logAction := ContentModerationActionIntrusionLog
+	switch action {
+	case IntrusionActionFlag:
+		logAction = ContentModerationActionIntrusionFlag
+	case IntrusionActionBlock:
+		logAction = ContentModerationActionIntrusionBlock
+	}
+	scores := make(map[string]float64, len(matches))
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		scores[match.Category] = 1.0
+		names = append(names, match.Rule)
+	}
+	slog.Info("content_moderation.intrusion_detected",
+		"request_id", input.RequestID,
+		"user_id", input.UserID,
+		"api_key_id", input.APIKeyID,
+		"group_id", contentModerationLogGroupID(input.GroupID),
+		"endpoint", input.Endpoint,
+		"protocol", input.Protocol,
+		"action", action,
+		"rules", names)
+
+	moderationCfg, err := s.loadConfig(ctx)
+	if err != nil {
+		moderationCfg = defaultContentModerationConfig()
+		moderationCfg.normalize()
+	}
+	log := s.buildLog(input, moderationCfg, logAction, action != IntrusionActionLog, matches[0].Category, 1.0, scores, content.ExcerptText(), nil, nil, "")
+	log.Mode = contentModerationIntrusionMode
+	log.MatchedKeyword = trimRunes(strings.Join(names, ","), maxContentModerationBlockedKeywordRunes)
+	log.ThresholdSnapshot = map[string]float64{}
+	s.enqueueRecord(input, moderationCfg, log, "", false, false)
+
+	if action != IntrusionActionBlock {
+		return nil
+	}
+	return &ContentModerationDecision{
+		Allowed:         false,
+		Blocked:         true,
+		Flagged:         true,
+		Message:         cfg.BlockMessage,
+		StatusCode:      cfg.BlockStatus,
+		HighestCategory: matches[0].Category,
+		HighestScore:    1.0,
+		CategoryScores:  scores,
+		Action:          ContentModerationActionIntrusionBlock,
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// ContextOverflowRoutedHeader 响应头：请求因估算输入超出上下文窗口被改路由时，值为客户端原始请求的模型。
+const ContextOverflowRoutedHeader = "X-Sub2API-Context-Routed-From"
+
+// anthropicImageTokenEstimate 单张图片的输入 token 估算值（Anthropic 单图上限约 1600 token）。
+const anthropicImageTokenEstimate = 1600
+
+// ContextOverflowDecision 长上下文路由判定结果。
+type ContextOverflowDecision struct {
+	EstimatedTokens int    // 估算的输入 token 数
+	ContextWindow   int    // 原模型（渠道映射后）的上下文窗口
+	RoutedModel     string // 改路由的目标模型（非空表示改路由）
+
+	Truncation *ContextTruncationResult // 非 nil 表示已按分组策略截断
+	Body       []byte                   // 截断后的请求体
+}
+
+// ResolveContextOverflowModel 返回请求模型超出上下文窗口时应改用的大上下文模型。
+func (g *Group) ResolveContextOverflowModel(requestedModel string) string {
+	if g == nil {
+		return ""
+	}
+	return resolveGroupModelOverride(g.ContextOverflowModels, requestedModel)
+}
+
+// HasContextOverflowPolicy 分组是否配置了长上下文路由、截断或拒绝策略。
+func (g *Group) HasContextOverflowPolicy() bool {
+	return g != nil && (len(g.ContextOverflowModels) > 0 || g.ContextTruncationStrategy != "" || g.ContextOverflowReject)
+}
+
+// normalizeContextOverflowModels 清理并校验长上下文路由映射；nil 原样返回（表示不修改）。
+func normalizeContextOverflowModels(m map[string]string) (map[string]string, error) {
+	return normalizeGroupModelOverrides(m, "INVALID_CONTEXT_OVERFLOW_MODELS", "context overflow")
+}
+
+// ResolveContextOverflow 估算 /v1/messages 请求的输入 token，与渠道映射后模型的上下文窗口（来自价格数据 max_input_tokens）比较。
+//
+// 未超出、分组未配置策略或窗口未知时返回 nil。超出时依次尝试：
+//  1. 改路由到分组配置的大上下文模型（其窗口足够或未知）；
+//  2. 按分组截断策略丢弃历史轮次，压缩到窗口的 90% 以内；
+//  3. 分组开启拒绝时返回 CONTEXT_LENGTH_EXCEEDED 错误，避免请求在上游以 context_length_exceeded 失败。
+func (s *GatewayService) ResolveContextOverflow(ctx context.Context, group *Group, requestedModel string, body []byte) (*ContextOverflowDecision, error) {
+	if !group.HasContextOverflowPolicy() || requestedModel == "" {
+		return nil, nil
+	}
+	window := s.contextWindowForGroupModel(ctx, group.ID, requestedModel)
+	if window <= 0 {
+		return nil, nil
+	}
+	estimated := estimateAnthropicInputTokens(body)
+	if estimated <= window {
+		return nil, nil
+	}
+
+	decision := &ContextOverflowDecision{EstimatedTokens: estimated, ContextWindow: window}
+	if target := group.ResolveContextOverflowModel(requestedModel); target != "" {
+		targetWindow := s.contextWindowForGroupModel(ctx, group.ID, target)
+		if targetWindow <= 0 || estimated <= targetWindow {
+			decision.RoutedModel = target
+			return decision, nil
+		}
+	}
+	if strategy := group.ContextTruncationStrategy; strategy != "" {
+		maxTokens := int(float64(window) * contextTruncationBudgetRatio)
+		if out, result, ok := truncateAnthropicMessages(body, strategy, maxTokens); ok {
+			decision.Truncation = result
+			decision.Body = out
+			return decision, nil
+		}
+	}
+	if !group.ContextOverflowReject {
+		return nil, nil
+	}
+	return nil, infraerrors.BadRequest("CONTEXT_LENGTH_EXCEEDED", fmt.Sprintf(
+		"estimated input of about %d tokens exceeds the %d-token context window of model %s; shorten the conversation (e.g. compact or drop earlier turns) or request a larger-context model",
+		estimated, window, requestedModel,
+	)).WithMetadata(map[string]string{
+		"model":            requestedModel,
+		"estimated_tokens": strconv.Itoa(estimated),
+		"context_window":   strconv.Itoa(window),
+	})
+}
+
+// contextWindowForGroupModel 返回分组内模型（经渠道映射后）的上下文窗口，未知时返回 0。
+func (s *GatewayService) contextWindowForGroupModel(ctx context.Context, groupID int64, model string) int {
+	mapping, _ := s.ResolveChannelMappingAndRestrict(ctx, &groupID, model)
+	if mapping.MappedModel != "" {
+		model = mapping.MappedModel
+	}
+	if s.billingService == nil || s.billingService.pricingService == nil {
+		return 0
+	}
+	pricing := s.billingService.pricingService.GetModelPricing(model)
+	if pricing == nil {
+		return 0
+	}
+	return pricing.MaxInputTokens
+}
+
+// estimateAnthropicInputTokens 粗略估算 Anthropic Messages 请求的输入 token（system、messages、tools）。
+func estimateAnthropicInputTokens(body []byte) int {
+	total := estimateAnthropicContentTokens(gjson.GetBytes(body, "system"))
+	gjson.GetBytes(body, "messages").ForEach(func(_, msg gjson.Result) bool {
+		total += estimateAnthropicContentTokens(msg.Get("content"))
+		return true
+	})
+	if tools := gjson.GetBytes(body, "tools"); tools.IsArray() {
+		total += estimateTokensForText(tools.Raw)
+	}
+	return total
+}
+
+func estimateAnthropicContentTokens(content gjson.Result) int {
+	if content.Type == gjson.String {
+		return estimateTokensForText(content.String())
+	}
+	if !content.IsArray() {
+		return 0
+	}
+	total := 0
+	content.ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "text":
+			total += estimateTokensForText(block.Get("text").String())
+		case "thinking":
+			total += estimateTokensForText(block.Get("thinking").String())
+		case "tool_use":
+			total += estimateTokensForText(block.Get("input").Raw)
+		case "tool_result":
+			total += estimateAnthropicContentTokens(block.Get("content"))
+		case "image":
+			total += anthropicImageTokenEstimate
+		}
+		return true
+	})
+	return total
+}
@@ -0,0 +1,88 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newContextOverflowGatewayServiceForTest() *GatewayService {
+	pricing := &PricingService{pricingData: map[string]*LiteLLMModelPricing{
+		"claude-sonnet-4-5":  {InputCostPerToken: 3e-6, MaxInputTokens: 200000},
+		"claude-sonnet-4-1m": {InputCostPerToken: 6e-6, MaxInputTokens: 1000000},
+		"claude-haiku-4-5":   {InputCostPerToken: 1e-6, MaxInputTokens: 200000},
+	}}
+	return &GatewayService{billingService: NewBillingService(&config.Config{}, pricing)}
+}
+
+// contextOverflowBody 构造约 tokens 个输入 token 的请求体（英文文本约 4 字符 / token）。
+func contextOverflowBody(model string, tokens int) []byte {
+	text := strings.Repeat("abcd", tokens)
+	return []byte(`{"model":"` + model + `","system":"be brief","messages":[{"role":"user","content":[{"type":"text","text":"` + text + `"}]}]}`)
+}
+
+func TestGatewayServiceResolveContextOverflow_RoutesToLargeContextModel(t *testing.T) {
+	svc := newContextOverflowGatewayServiceForTest()
+	group := &Group{ID: 1, ContextOverflowModels: map[string]string{"claude-sonnet-*": "claude-sonnet-4-1m"}}
+
+	decision, err := svc.ResolveContextOverflow(context.Background(), group, "claude-sonnet-4-5", contextOverflowBody("claude-sonnet-4-5", 250000))
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	require.Equal(t, "claude-sonnet-4-1m", decision.RoutedModel)
+	require.Equal(t, 200000, decision.ContextWindow)
+	require.Greater(t, decision.EstimatedTokens, 250000)
+
+	decision, err = svc.ResolveContextOverflow(context.Background(), group, "claude-sonnet-4-5", contextOverflowBody("claude-sonnet-4-5", 1000))
+	require.NoError(t, err)
+	require.Nil(t, decision, "fits within the context window")
+}
+
+func TestGatewayServiceResolveContextOverflow_RejectsWithGuidance(t *testing.T) {
+	svc := newContextOverflowGatewayServiceForTest()
+	group := &Group{ID: 1, ContextOverflowReject: true, ContextOverflowModels: map[string]string{"claude-haiku-4-5": "claude-sonnet-4-5"}}
+
+	// 目标模型窗口同样不足时不改路由
+	_, err := svc.ResolveContextOverflow(context.Background(), group, "claude-haiku-4-5", contextOverflowBody("claude-haiku-4-5", 250000))
+	require.Error(t, err)
+	require.Equal(t, "CONTEXT_LENGTH_EXCEEDED", infraerrors.Reason(err))
+	require.Contains(t, infraerrors.Message(err), "200000-token context window")
+	require.Equal(t, "200000", infraerrors.FromError(err).Metadata["context_window"])
+
+	// 未开启拒绝时交由上游处理
+	group.ContextOverflowReject = false
+	decision, err := svc.ResolveContextOverflow(context.Background(), group, "claude-haiku-4-5", contextOverflowBody("claude-haiku-4-5", 250000))
+	require.NoError(t, err)
+	require.Nil(t, decision)
+}
+
+func TestGatewayServiceResolveContextOverflow_SkipsWithoutPolicyOrWindow(t *testing.T) {
+	svc := newContextOverflowGatewayServiceForTest()
+	body := contextOverflowBody("claude-sonnet-4-5", 250000)
+
+	decision, err := svc.ResolveContextOverflow(context.Background(), &Group{ID: 1}, "claude-sonnet-4-5", body)
+	require.NoError(t, err)
+	require.Nil(t, decision)
+
+	decision, err = svc.ResolveContextOverflow(context.Background(), &Group{ID: 1, ContextOverflowReject: true}, "unknown-model", body)
+	require.NoError(t, err)
+	require.Nil(t, decision, "unknown context window is never rejected")
+}
+
+func TestEstimateAnthropicInputTokens(t *testing.T) {
+	body := []byte(`{
+		"system":[{"type":"text","text":"abcdabcd"}],
+		"messages":[
+			{"role":"user","content":"abcdabcdabcd"},
+			{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"x","input":{"q":"a"}}]},
+			{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"abcd"}]},{"type":"image","source":{"type":"base64","data":"AAAA"}}]}
+		]
+	}`)
+	// system 2 + 文本 3 + tool_use {"q":"a"} 3 + tool_result 1 + 图片 1600
+	require.Equal(t, 1609, estimateAnthropicInputTokens(body))
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// 长上下文截断策略（分组 context_truncation_strategy，空表示不截断）。
+const (
+	ContextTruncationDropOldest = "drop_oldest" // 从最早的对话轮次开始丢弃
+	ContextTruncationSummarize  = "summarize"   // 丢弃最早的轮次，并以摘要注记替代被丢弃内容
+	ContextTruncationMiddleOut  = "middle_out"  // 保留首轮（任务上下文）与最近轮次，从中间丢弃
+)
+
+// ContextTruncationHeader 响应头：请求被截断时记录所用策略与移除的消息数，如 "drop_oldest; removed=12"。
+const ContextTruncationHeader = "X-Sub2API-Context-Truncated"
+
+const (
+	// contextTruncationBudgetRatio 截断目标为上下文窗口的 90%，为估算误差留余量。
+	contextTruncationBudgetRatio = 0.9
+	// contextSummarySnippetRunes 摘要中每条被移除消息保留的字符数。
+	contextSummarySnippetRunes = 160
+	// contextSummaryMaxRunes 摘要注记的最大字符数。
+	contextSummaryMaxRunes = 4000
+)
+
+// ContextTruncationResult 截断结果，随响应头回传给客户端。
+type ContextTruncationResult struct {
+	Strategy        string
+	RemovedMessages int
+	EstimatedTokens int // 截断后估算的输入 token
+}
+
+// HeaderValue 返回 X-Sub2API-Context-Truncated 响应头的值。
+func (r *ContextTruncationResult) HeaderValue() string {
+	return r.Strategy + "; removed=" + strconv.Itoa(r.RemovedMessages)
+}
+
+// normalizeContextTruncationStrategy 校验长上下文截断策略。
+func normalizeContextTruncationStrategy(strategy string) (string, error) {
+	strategy = strings.TrimSpace(strategy)
+	switch strategy {
+	case "", ContextTruncationDropOldest, ContextTruncationSummarize, ContextTruncationMiddleOut:
+		return strategy, nil
+	}
+	return "", infraerrors.BadRequest("INVALID_CONTEXT_TRUNCATION_STRATEGY", "context truncation strategy must be one of drop_oldest, summarize, middle_out").
+		WithMetadata(map[string]string{"strategy": strategy})
+}
+
+// truncateAnthropicMessages 按策略丢弃 Anthropic Messages 请求中的整轮对话，使估算输入不超过 maxTokens。
+//
+// 以「非 tool_result 的 user 消息」为轮次起点，保证 tool_use/tool_result 不被拆开；最后一轮始终保留。
+// 丢弃全部可丢弃轮次后仍超出时返回 false。
+func truncateAnthropicMessages(body []byte, strategy string, maxTokens int) ([]byte, *ContextTruncationResult, bool) {
+	messages := gjson.GetBytes(body, "messages").Array()
+	if len(messages) < 2 {
+		return nil, nil, false
+	}
+	fixed := estimateAnthropicContentTokens(gjson.GetBytes(body, "system"))
+	if tools := gjson.GetBytes(body, "tools"); tools.IsArray() {
+		fixed += estimateTokensForText(tools.Raw)
+	}
+
+	type turn struct {
+		start, end int
+		tokens     int
+	}
+	var turns []turn
+	for i, msg := range messages {
+		tokens := estimateAnthropicContentTokens(msg.Get("content"))
+		if i == 0 || isAnthropicTurnStart(msg) {
+			turns = append(turns, turn{start: i, end: i + 1, tokens: tokens})
+			continue
+		}
+		last := &turns[len(turns)-1]
+		last.end = i + 1
+		last.tokens += tokens
+	}
+	if len(turns) < 2 {
+		return nil, nil, false
+	}
+
+	budget := maxTokens - fixed
+	if strategy == ContextTruncationSummarize {
+		budget -= contextSummaryMaxRunes / 4
+	}
+	total := 0
+	for _, t := range turns {
+		total += t.tokens
+	}
+
+	// 可丢弃轮次的顺序：drop_oldest/summarize 从第一轮开始；middle_out 保留第一轮，从第二轮开始
+	first := 0
+	if strategy == ContextTruncationMiddleOut {
+		first = 1
+	}
+	dropped := make([]bool, len(turns))
+	for i := first; i < len(turns)-1 && total > budget; i++ {
+		dropped[i] = true
+		total -= turns[i].tokens
+	}
+	if total > budget {
+		return nil, nil, false
+	}
+
+	kept := make([]string, 0, len(messages))
+	var removed []gjson.Result
+	for i, t := range turns {
+		for j := t.start; j < t.end; j++ {
+			if dropped[i] {
+				removed = append(removed, messages[j])
+			} else {
+				kept = append(kept, messages[j].Raw)
+			}
+		}
+	}
+	if strategy == ContextTruncationSummarize && len(kept) > 0 && gjson.Get(kept[0], "role").String() == "user" {
+		if withSummary, ok := prependAnthropicTextBlock(kept[0], buildContextTruncationSummary(removed)); ok {
+			kept[0] = withSummary
+		}
+	}
+
+	out, err := sjson.SetRawBytes(body, "messages", []byte("["+strings.Join(kept, ",")+"]"))
+	if err != nil {
+		return nil, nil, false
+	}
+	return out, &ContextTruncationResult{
+		Strategy:        strategy,
+		RemovedMessages: len(removed),
+		EstimatedTokens: estimateAnthropicInputTokens(out),
+	}, true
+}
+
+// isAnthropicTurnStart 判断消息是否为新一轮对话的起点（user 消息且不是纯 tool_result 回传）。
+func isAnthropicTurnStart(msg gjson.Result) bool {
+	if msg.Get("role").String() != "user" {
+		return false
+	}
+	content := msg.Get("content")
+	if !content.IsArray() {
+		return true
+	}
+	start := true
+	content.ForEach(func(_, block gjson.Result) bool {
+		if block.Get("type").String() == "tool_result" {
+			start = false
+			return false
+		}
+		return true
+	})
+	return start
+}
+
+// buildContextTruncationSummary 以被移除消息的开头片段生成摘要注记（抽取式，不额外调用上游）。
+func buildContextTruncationSummary(removed []gjson.Result) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Gateway note: %d earlier messages were removed to fit the context window. Summary of the removed messages:]", len(removed))
+	for _, msg := range removed {
+		snippet := anthropicMessageSnippet(msg.Get("content"))
+		if snippet == "" {
+			continue
+		}
+		line := "\n- " + msg.Get("role").String() + ": " + snippet
+		if len([]rune(sb.String()))+len([]rune(line)) > contextSummaryMaxRunes {
+			sb.WriteString("\n- …")
+			break
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+func anthropicMessageSnippet(content gjson.Result) string {
+	var text string
+	if content.Type == gjson.String {
+		text = content.String()
+	} else {
+		var parts []string
+		content.ForEach(func(_, block gjson.Result) bool {
+			switch block.Get("type").String() {
+			case "text":
+				parts = append(parts, block.Get("text").String())
+			case "tool_use":
+				parts = append(parts, "[tool_use "+block.Get("name").String()+"]")
+			case "tool_result":
+				parts = append(parts, "[tool_result]")
+			case "image":
+				parts = append(parts, "[image]")
+			}
+			return true
+		})
+		text = strings.Join(parts, " ")
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > contextSummarySnippetRunes {
+		text = string(runes[:contextSummarySnippetRunes]) + "…"
+	}
+	return text
+}
+
+// prependAnthropicTextBlock 在 user 消息内容最前面插入一个文本块（字符串内容先转换为块数组），其余块原样保留。
+func prependAnthropicTextBlock(rawMsg, text string) (string, bool) {
+	block, err := json.Marshal(map[string]string{"type": "text", "text": text})
+	if err != nil {
+		return "", false
+	}
+	content := gjson.Get(rawMsg, "content")
+	var rest string
+	switch {
+	case content.Type == gjson.String:
+		orig, err := json.Marshal(map[string]string{"type": "text", "text": content.String()})
+		if err != nil {
+			return "", false
+		}
+		rest = string(orig)
+	case content.IsArray():
+		rest = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(content.Raw), "["), "]"))
+	default:
+		return "", false
+	}
+	blocks := "[" + string(block)
+	if rest != "" {
+		blocks += "," + rest
+	}
+	out, err := sjson.SetRaw(rawMsg, "content", blocks+"]")
+	if err != nil {
+		return "", false
+	}
+	return out, true
+}
@@ -0,0 +1,117 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// truncationTestBody 构造 n 轮对话，每轮 user/assistant 各约 tokensPerMsg 个 token；第 2 轮包含一次工具调用。
+func truncationTestBody(n, tokensPerMsg int) []byte {
+	text := strings.Repeat("abcd", tokensPerMsg)
+	msgs := make([]string, 0, n*2+2)
+	for i := 0; i < n; i++ {
+		turn := string(rune('A' + i))
+		msgs = append(msgs, `{"role":"user","content":"turn `+turn+` `+text+`"}`)
+		if i == 1 {
+			msgs = append(msgs,
+				`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"read","input":{}}]}`,
+				`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}`,
+			)
+		}
+		msgs = append(msgs, `{"role":"assistant","content":[{"type":"text","text":"`+text+`"}]}`)
+	}
+	return []byte(`{"model":"claude-sonnet-4-5","system":"sys","messages":[` + strings.Join(msgs, ",") + `]}`)
+}
+
+func truncationTestUserTurns(body []byte) []string {
+	var out []string
+	gjson.GetBytes(body, "messages").ForEach(func(_, msg gjson.Result) bool {
+		if c := msg.Get("content"); c.Type == gjson.String {
+			out = append(out, strings.Fields(c.String())[1])
+		}
+		return true
+	})
+	return out
+}
+
+func TestTruncateAnthropicMessages_DropOldestKeepsToolPairs(t *testing.T) {
+	body := truncationTestBody(4, 100) // 每轮约 200 token
+
+	out, result, ok := truncateAnthropicMessages(body, ContextTruncationDropOldest, 500)
+	require.True(t, ok)
+	require.Equal(t, []string{"C", "D"}, truncationTestUserTurns(out))
+	require.Equal(t, 6, result.RemovedMessages, "turn B is dropped together with its tool_use/tool_result")
+	require.LessOrEqual(t, result.EstimatedTokens, 500)
+	require.Equal(t, "drop_oldest; removed=6", result.HeaderValue())
+	require.Equal(t, "user", gjson.GetBytes(out, "messages.0.role").String())
+}
+
+func TestTruncateAnthropicMessages_MiddleOutKeepsFirstTurn(t *testing.T) {
+	body := truncationTestBody(4, 100)
+
+	out, result, ok := truncateAnthropicMessages(body, ContextTruncationMiddleOut, 500)
+	require.True(t, ok)
+	require.Equal(t, []string{"A", "D"}, truncationTestUserTurns(out))
+	require.Equal(t, 6, result.RemovedMessages)
+}
+
+func TestTruncateAnthropicMessages_SummarizePrependsNote(t *testing.T) {
+	body := truncationTestBody(4, 100)
+
+	out, result, ok := truncateAnthropicMessages(body, ContextTruncationSummarize, 1500)
+	require.True(t, ok)
+	require.Equal(t, ContextTruncationSummarize, result.Strategy)
+	first := gjson.GetBytes(out, "messages.0")
+	require.Equal(t, "user", first.Get("role").String())
+	note := first.Get("content.0.text").String()
+	require.Contains(t, note, "earlier messages were removed")
+	require.Contains(t, note, "- user: turn A")
+	require.Contains(t, note, "[tool_use read]")
+	require.True(t, strings.HasPrefix(first.Get("content.1.text").String(), "turn "), "original content is kept after the note")
+}
+
+func TestTruncateAnthropicMessages_FailsWhenLastTurnAloneTooLarge(t *testing.T) {
+	body := truncationTestBody(3, 100)
+
+	_, _, ok := truncateAnthropicMessages(body, ContextTruncationDropOldest, 100)
+	require.False(t, ok)
+	_, _, ok = truncateAnthropicMessages([]byte(`{"messages":[{"role":"user","content":"hi"}]}`), ContextTruncationDropOldest, 1)
+	require.False(t, ok)
+}
+
+func TestGatewayServiceResolveContextOverflow_TruncatesWhenNoRoute(t *testing.T) {
+	svc := newContextOverflowGatewayServiceForTest()
+	group := &Group{ID: 1, ContextTruncationStrategy: ContextTruncationDropOldest, ContextOverflowReject: true}
+	body := truncationTestBody(6, 50000) // 每轮约 100k token，超出 200k 窗口
+
+	decision, err := svc.ResolveContextOverflow(context.Background(), group, "claude-sonnet-4-5", body)
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	require.Empty(t, decision.RoutedModel)
+	require.NotNil(t, decision.Truncation)
+	require.LessOrEqual(t, decision.Truncation.EstimatedTokens, 180000)
+	require.Equal(t, []string{"F"}, truncationTestUserTurns(decision.Body))
+
+	// 配置了可用的大上下文模型时优先改路由
+	group.ContextOverflowModels = map[string]string{"claude-sonnet-4-5": "claude-sonnet-4-1m"}
+	decision, err = svc.ResolveContextOverflow(context.Background(), group, "claude-sonnet-4-5", body)
+	require.NoError(t, err)
+	require.Equal(t, "claude-sonnet-4-1m", decision.RoutedModel)
+	require.Nil(t, decision.Truncation)
+}
+
+func TestNormalizeContextTruncationStrategy(t *testing.T) {
+	for _, s := range []string{"", "drop_oldest", " summarize ", "middle_out"} {
+		got, err := normalizeContextTruncationStrategy(s)
+		require.NoError(t, err)
+		require.Equal(t, strings.TrimSpace(s), got)
+	}
+	_, err := normalizeContextTruncationStrategy("random")
+	require.Error(t, err)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 原始数据导出格式。
+const (
+	DataExportFormatCSV   = "csv"
+	DataExportFormatJSONL = "jsonl"
+)
+
+const (
+	// dataExportChunkSize 每批从数据库读取的行数（按 id 游标分批，内存占用与总行数无关）。
+	dataExportChunkSize = 1000
+	dataExportMaxRange  = 366 * 24 * time.Hour
+)
+
+var (
+	ErrDataExportInvalidFormat = infraerrors.BadRequest("DATA_EXPORT_INVALID_FORMAT", "format must be one of: csv, jsonl")
+	ErrDataExportInvalidRange  = infraerrors.BadRequest("DATA_EXPORT_INVALID_RANGE", "time range must be non-empty and at most 366 days")
+)
+
+// DataExportFilter 原始数据导出过滤条件。零值字段不过滤。
+type DataExportFilter struct {
+	StartTime time.Time
+	EndTime   time.Time
+
+	UserID    int64
+	APIKeyID  int64
+	GroupID   int64
+	AccountID int64
+	Model     string
+
+	// 以下仅对错误日志导出生效
+	Platform   string
+	Phase      string
+	Owner      string
+	StatusCode int
+}
+
+// UsageLogExportRow 用量日志导出行
+type UsageLogExportRow struct {
+	ID                  int64     `json:"id"`
+	CreatedAt           time.Time `json:"created_at"`
+	RequestID           string    `json:"request_id"`
+	UserID              int64     `json:"user_id"`
+	APIKeyID            int64     `json:"api_key_id"`
+	AccountID           int64     `json:"account_id"`
+	GroupID             *int64    `json:"group_id"`
+	Model               string    `json:"model"`
+	RequestedModel      string    `json:"requested_model"`
+	UpstreamModel       string    `json:"upstream_model"`
+	RequestType         int16     `json:"request_type"`
+	Stream              bool      `json:"stream"`
+	InputTokens         int64     `json:"input_tokens"`
+	OutputTokens        int64     `json:"output_tokens"`
+	CacheCreationTokens int64     `json:"cache_creation_tokens"`
+	CacheReadTokens     int64     `json:"cache_read_tokens"`
+	InputCost           float64   `json:"input_cost"`
+	OutputCost          float64   `json:"output_cost"`
+	CacheCreationCost   float64   `json:"cache_creation_cost"`
+	CacheReadCost       float64   `json:"cache_read_cost"`
+	TotalCost           float64   `json:"total_cost"`
+	ActualCost          float64   `json:"actual_cost"`
+	RateMultiplier      float64   `json:"rate_multiplier"`
+	BillingType         int16     `json:"billing_type"`
+	BillingMode         string    `json:"billing_mode"`
+	DurationMs          *int64    `json:"duration_ms"`
+	FirstTokenMs        *int64    `json:"first_token_ms"`
+	IPAddress           string    `json:"ip_address"`
+	UserAgent           string    `json:"user_agent"`
+}
+
+var usageLogExportHeader = []string{
+	"id", "created_at", "request_id", "user_id", "api_key_id", "account_id", "group_id",
+	"model", "requested_model", "upstream_model", "request_type", "stream",
+	"input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens",
+	"input_cost", "output_cost", "cache_creation_cost", "cache_read_cost", "total_cost", "actual_cost",
+	"rate_multiplier", "billing_type", "billing_mode", "duration_ms", "first_token_ms", "ip_address", "user_agent",
+}
+
+func (r *UsageLogExportRow) csvRecord() []string {
+	return []string{
+		exportInt(r.ID), exportTime(r.CreatedAt), r.RequestID, exportInt(r.UserID), exportInt(r.APIKeyID), exportInt(r.AccountID), exportIntPtr(r.GroupID),
+		r.Model, r.RequestedModel, r.UpstreamModel, exportInt(int64(r.RequestType)), strconv.FormatBool(r.Stream),
+		exportInt(r.InputTokens), exportInt(r.OutputTokens), exportInt(r.CacheCreationTokens), exportInt(r.CacheReadTokens),
+		exportFloat(r.InputCost), exportFloat(r.OutputCost), exportFloat(r.CacheCreationCost), exportFloat(r.CacheReadCost), exportFloat(r.TotalCost), exportFloat(r.ActualCost),
+		exportFloat(r.RateMultiplier), exportInt(int64(r.BillingType)), r.BillingMode, exportIntPtr(r.DurationMs), exportIntPtr(r.FirstTokenMs), r.IPAddress, r.UserAgent,
+	}
+}
+
+// OpsErrorExportRow 错误日志导出行（不含请求体 / 请求头等重放上下文）
+type OpsErrorExportRow struct {
+	ID                 int64     `json:"id"`
+	CreatedAt          time.Time `json:"created_at"`
+	RequestID          string    `json:"request_id"`
+	ClientRequestID    string    `json:"client_request_id"`
+	UserID             *int64    `json:"user_id"`
+	APIKeyID           *int64    `json:"api_key_id"`
+	AccountID          *int64    `json:"account_id"`
+	GroupID            *int64    `json:"group_id"`
+	Platform           string    `json:"platform"`
+	Model              string    `json:"model"`
+	RequestPath        string    `json:"request_path"`
+	Stream             bool      `json:"stream"`
+	ErrorPhase         string    `json:"error_phase"`
+	ErrorType          string    `json:"error_type"`
+	ErrorCode          string    `json:"error_code"`
+	Severity           string    `json:"severity"`
+	StatusCode         *int64    `json:"status_code"`
+	UpstreamStatusCode *int64    `json:"upstream_status_code"`
+	ErrorOwner         string    `json:"error_owner"`
+	ErrorSource        string    `json:"error_source"`
+	IsBusinessLimited  bool      `json:"is_business_limited"`
+	IsCountTokens      bool      `json:"is_count_tokens"`
+	ErrorMessage       string    `json:"error_message"`
+	DurationMs         *int64    `json:"duration_ms"`
+	ClientIP           string    `json:"client_ip"`
+}
+
+var opsErrorExportHeader = []string{
+	"id", "created_at", "request_id", "client_request_id", "user_id", "api_key_id", "account_id", "group_id",
+	"platform", "model", "request_path", "stream", "error_phase", "error_type", "error_code", "severity",
+	"status_code", "upstream_status_code", "error_owner", "error_source", "is_business_limited", "is_count_tokens",
+	"error_message", "duration_ms", "client_ip",
+}
+
+func (r *OpsErrorExportRow) csvRecord() []string {
+	return []string{
+		exportInt(r.ID), exportTime(r.CreatedAt), r.RequestID, r.ClientRequestID, exportIntPtr(r.UserID), exportIntPtr(r.APIKeyID), exportIntPtr(r.AccountID), exportIntPtr(r.GroupID),
+		r.Platform, r.Model, r.RequestPath, strconv.FormatBool(r.Stream), r.ErrorPhase, r.ErrorType, r.ErrorCode, r.Severity,
+		exportIntPtr(r.StatusCode), exportIntPtr(r.UpstreamStatusCode), r.ErrorOwner, r.ErrorSource, strconv.FormatBool(r.IsBusinessLimited), strconv.FormatBool(r.IsCountTokens),
+		r.ErrorMessage, exportIntPtr(r.DurationMs), r.ClientIP,
+	}
+}
+
+// DataExportRepository 按 id 游标分批读取原始数据。
+type DataExportRepository interface {
+	// ListUsageLogs 返回 id > afterID 且满足过滤条件的用量日志，按 id 升序，至多 limit 行。
+	ListUsageLogs(ctx context.Context, filter *DataExportFilter, afterID int64, limit int) ([]*UsageLogExportRow, error)
+	// ListOpsErrors 返回 id > afterID 且满足过滤条件的错误日志，按 id 升序，至多 limit 行。
+	ListOpsErrors(ctx context.Context, filter *DataExportFilter, afterID int64, limit int) ([]*OpsErrorExportRow, error)
+}
+
+// DataExportService 用量日志与错误日志的流式导出
+type DataExportService struct {
+	repo DataExportRepository
+}
+
+// NewDataExportService 创建原始数据导出服务
+func NewDataExportService(repo DataExportRepository) *DataExportService {
+	return &DataExportService{repo: repo}
+}
+
+// NormalizeDataExportRequest 校验导出格式与时间范围，返回规范化后的格式。
+// 应在写出任何响应内容之前调用，以便校验失败时仍能返回 JSON 错误。
+func NormalizeDataExportRequest(format string, filter *DataExportFilter) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = DataExportFormatCSV
+	case DataExportFormatCSV, DataExportFormatJSONL:
+	default:
+		return "", ErrDataExportInvalidFormat
+	}
+	if !filter.EndTime.After(filter.StartTime) || filter.EndTime.Sub(filter.StartTime) > dataExportMaxRange {
+		return "", ErrDataExportInvalidRange
+	}
+	filter.Model = strings.TrimSpace(filter.Model)
+	filter.Platform = strings.TrimSpace(filter.Platform)
+	filter.Phase = strings.TrimSpace(filter.Phase)
+	filter.Owner = strings.TrimSpace(filter.Owner)
+	return format, nil
+}
+
+// ExportUsageLogs 将满足条件的用量日志以 CSV / JSONL 写入 w，返回写出的行数。
+func (s *DataExportService) ExportUsageLogs(ctx context.Context, w io.Writer, format string, filter DataExportFilter) (int64, error) {
+	format, err := NormalizeDataExportRequest(format, &filter)
+	if err != nil {
+		return 0, err
+	}
+	return streamDataExport(ctx, w, format, usageLogExportHeader,
+		func(afterID int64) ([]*UsageLogExportRow, error) {
+			return s.repo.ListUsageLogs(ctx, &filter, afterID, dataExportChunkSize)
+		},
+		func(r *UsageLogExportRow) int64 { return r.ID },
+		(*UsageLogExportRow).csvRecord)
+}
+
+// ExportOpsErrors 将满足条件的错误日志以 CSV / JSONL 写入 w，返回写出的行数。
+func (s *DataExportService) ExportOpsErrors(ctx context.Context, w io.Writer, format string, filter DataExportFilter) (int64, error) {
+	format, err := NormalizeDataExportRequest(format, &filter)
+	if err != nil {
+		return 0, err
+	}
+	return streamDataExport(ctx, w, format, opsErrorExportHeader,
+		func(afterID int64) ([]*OpsErrorExportRow, error) {
+			return s.repo.ListOpsErrors(ctx, &filter, afterID, dataExportChunkSize)
+		},
+		func(r *OpsErrorExportRow) int64 { return r.ID },
+		(*OpsErrorExportRow).csvRecord)
+}
+
+// streamDataExport 按 id 游标逐批读取并写出，每批结束后 Flush，直到某批不足 chunk 大小。
+// CSV 表头在首批数据读取成功后才写出，使首次查询失败时调用方仍可返回错误响应。
+func streamDataExport[T any](
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	header []string,
+	fetch func(afterID int64) ([]T, error),
+	idOf func(T) int64,
+	record func(T) []string,
+) (int64, error) {
+	var (
+		csvWriter *csv.Writer
+		encoder   *json.Encoder
+		written   int64
+		afterID   int64
+	)
+	flusher, _ := w.(interface{ Flush() })
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		rows, err := fetch(afterID)
+		if err != nil {
+			return written, err
+		}
+
+		if format == DataExportFormatCSV {
+			if csvWriter == nil {
+				csvWriter = csv.NewWriter(w)
+				if err := csvWriter.Write(header); err != nil {
+					return written, err
+				}
+			}
+			for _, row := range rows {
+				if err := csvWriter.Write(record(row)); err != nil {
+					return written, err
+				}
+			}
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return written, err
+			}
+		} else {
+			if encoder == nil {
+				encoder = json.NewEncoder(w)
+				encoder.SetEscapeHTML(false)
+			}
+			for _, row := range rows {
+				if err := encoder.Encode(row); err != nil {
+					return written, err
+				}
+			}
+		}
+		written += int64(len(rows))
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(rows) < dataExportChunkSize {
+			return written, nil
+		}
+		afterID = idOf(rows[len(rows)-1])
+	}
+}
+
+func exportInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func exportIntPtr(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func exportFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func exportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
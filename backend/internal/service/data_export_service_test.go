@@ -0,0 +1,131 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type dataExportRepoStub struct {
+	usage    []*UsageLogExportRow
+	opsErr   []*OpsErrorExportRow
+	afterIDs []int64
+	err      error
+}
+
+func (r *dataExportRepoStub) ListUsageLogs(_ context.Context, _ *DataExportFilter, afterID int64, limit int) ([]*UsageLogExportRow, error) {
+	r.afterIDs = append(r.afterIDs, afterID)
+	if r.err != nil {
+		return nil, r.err
+	}
+	out := make([]*UsageLogExportRow, 0, limit)
+	for _, row := range r.usage {
+		if row.ID > afterID && len(out) < limit {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (r *dataExportRepoStub) ListOpsErrors(_ context.Context, _ *DataExportFilter, afterID int64, limit int) ([]*OpsErrorExportRow, error) {
+	r.afterIDs = append(r.afterIDs, afterID)
+	out := make([]*OpsErrorExportRow, 0, limit)
+	for _, row := range r.opsErr {
+		if row.ID > afterID && len(out) < limit {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+type flushCountingBuffer struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (b *flushCountingBuffer) Flush() { b.flushes++ }
+
+func dataExportTestFilter() DataExportFilter {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	return DataExportFilter{StartTime: start, EndTime: start.AddDate(0, 0, 1)}
+}
+
+func TestDataExportService_ExportUsageLogsCSVPagesByCursor(t *testing.T) {
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	repo := &dataExportRepoStub{}
+	for i := int64(1); i <= dataExportChunkSize+2; i++ {
+		repo.usage = append(repo.usage, &UsageLogExportRow{ID: i, CreatedAt: created, Model: "claude-sonnet-4-6", TotalCost: 0.5, ActualCost: 0.75})
+	}
+	svc := NewDataExportService(repo)
+	buf := &flushCountingBuffer{}
+
+	written, err := svc.ExportUsageLogs(context.Background(), buf, "", dataExportTestFilter())
+
+	require.NoError(t, err)
+	require.Equal(t, int64(dataExportChunkSize+2), written)
+	require.Equal(t, []int64{0, dataExportChunkSize}, repo.afterIDs)
+	require.Equal(t, 2, buf.flushes)
+
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, dataExportChunkSize+3)
+	require.Equal(t, usageLogExportHeader, records[0])
+	require.Equal(t, "1", records[1][0])
+	require.Equal(t, "2026-10-01T08:00:00Z", records[1][1])
+	require.Equal(t, "", records[1][6], "nil group_id exports as empty cell")
+	require.Equal(t, "0.75", records[1][21])
+}
+
+func TestDataExportService_ExportOpsErrorsJSONL(t *testing.T) {
+	status := int64(502)
+	repo := &dataExportRepoStub{opsErr: []*OpsErrorExportRow{
+		{ID: 7, StatusCode: &status, ErrorMessage: "upstream <timeout>"},
+		{ID: 9, ErrorPhase: "routing"},
+	}}
+	svc := NewDataExportService(repo)
+	var buf bytes.Buffer
+
+	written, err := svc.ExportOpsErrors(context.Background(), &buf, "JSONL", dataExportTestFilter())
+
+	require.NoError(t, err)
+	require.Equal(t, int64(2), written)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.Equal(t, float64(502), first["status_code"])
+	require.Contains(t, lines[0], `"upstream <timeout>"`)
+}
+
+func TestDataExportService_RejectsInvalidRequest(t *testing.T) {
+	svc := NewDataExportService(&dataExportRepoStub{})
+	var buf bytes.Buffer
+
+	_, err := svc.ExportUsageLogs(context.Background(), &buf, "xlsx", dataExportTestFilter())
+	require.ErrorIs(t, err, ErrDataExportInvalidFormat)
+
+	filter := dataExportTestFilter()
+	filter.EndTime = filter.StartTime.AddDate(0, 0, 400)
+	_, err = svc.ExportUsageLogs(context.Background(), &buf, "csv", filter)
+	require.ErrorIs(t, err, ErrDataExportInvalidRange)
+	require.Zero(t, buf.Len())
+}
+
+func TestDataExportService_FirstChunkErrorWritesNothing(t *testing.T) {
+	svc := NewDataExportService(&dataExportRepoStub{err: errors.New("db down")})
+	var buf bytes.Buffer
+
+	_, err := svc.ExportUsageLogs(context.Background(), &buf, "csv", dataExportTestFilter())
+
+	require.Error(t, err)
+	require.Zero(t, buf.Len())
+}
@@ -14,6 +14,7 @@ const (
 	StatusUnused   = domain.StatusUnused
 	StatusUsed     = domain.StatusUsed
 	StatusExpired  = domain.StatusExpired
+	StatusArchived = domain.StatusArchived
 )
 
 // Role constants
@@ -140,6 +141,7 @@ const (
 	SettingKeyAffiliateRebatePerInviteeCap     = "affiliate_rebate_per_invitee_cap"    // 单人返利上限（0=无上限）
 	SettingKeyRiskControlEnabled               = "risk_control_enabled"                // 是否启用风控中心入口与审计链路
 	SettingKeyContentModerationConfig          = "content_moderation_config"           // 内容审计配置（JSON）
+	SettingKeyIntrusionDetectionConfig         = "intrusion_detection_config"          // 入侵检测（提示注入/数据外泄）配置（JSON）
 	SettingKeyCyberSessionBlockEnabled         = "cyber_session_block_enabled"         // cyber 命中后会话级自动屏蔽总开关(默认关)
 	SettingKeyCyberSessionBlockTTLSeconds      = "cyber_session_block_ttl_seconds"     // 会话屏蔽 TTL 秒数(默认 3600)
 	SettingKeyLoginAgreementEnabled            = "login_agreement_enabled"             // 登录前是否要求同意条款
@@ -338,6 +340,9 @@ const (
 	// SettingKeyOpsEmailNotificationConfig stores JSON config for ops email notifications.
 	SettingKeyOpsEmailNotificationConfig = "ops_email_notification_config"
 
+	// SettingKeyOpsReportSubscriptions stores JSON per-admin subscription preferences for scheduled ops reports.
+	SettingKeyOpsReportSubscriptions = "ops_report_subscriptions"
+
 	// SettingKeyOpsAlertRuntimeSettings stores JSON config for ops alert evaluator runtime settings.
 	SettingKeyOpsAlertRuntimeSettings = "ops_alert_runtime_settings"
 
@@ -350,6 +355,9 @@ const (
 	// SettingKeyOpsRuntimeLogConfig stores JSON config for runtime log settings.
 	SettingKeyOpsRuntimeLogConfig = "ops_runtime_log_config"
 
+	// SettingKeyAPIKeyTraceRedactionRules stores JSON admin-defined regex rules applied to API key trace records before persisting.
+	SettingKeyAPIKeyTraceRedactionRules = "api_key_trace_redaction_rules"
+
 	// =========================
 	// Channel Monitor (渠道监控)
 	// =========================
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"github.com/dgraph-io/ristretto"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"golang.org/x/sync/singleflight"
+)
+
+// fileURLFetchMaxRedirects 代取时允许的最大重定向次数（每跳都重新校验白名单/内网地址）。
+const fileURLFetchMaxRedirects = 3
+
+// FileURLFetcher 在网关侧代取请求中的 input_image/input_file URL，并内联为 base64 data URI，
+// 供不接受 URL 的上游（Anthropic 转换链路、Gemini）使用。
+//
+// 安全约束：URL 来自终端用户，下载前按 security.url_allowlist 校验主机（白名单启用时必须命中
+// file_fetch_hosts），默认拒绝内网/本地地址并校验 DNS 解析结果，重定向逐跳复检；下载经账号代理发出。
+type FileURLFetcher struct {
+	cfg               config.GatewayFileFetchConfig
+	allowedHosts      []string
+	requireAllowlist  bool
+	allowInsecureHTTP bool
+
+	cache  *ristretto.Cache
+	flight singleflight.Group
+}
+
+type fetchedFile struct {
+	data      []byte
+	mediaType string
+}
+
+// NewFileURLFetcher 创建 URL 代取器；cfg 为 nil 或未启用时返回的实例 Enabled() 为 false。
+func NewFileURLFetcher(cfg *config.Config) *FileURLFetcher {
+	f := &FileURLFetcher{}
+	if cfg == nil {
+		return f
+	}
+	f.cfg = cfg.Gateway.FileFetch
+	f.allowedHosts = cfg.Security.URLAllowlist.FileFetchHosts
+	f.requireAllowlist = cfg.Security.URLAllowlist.Enabled
+	f.allowInsecureHTTP = cfg.Security.URLAllowlist.AllowInsecureHTTP
+	if f.cfg.Enabled && f.cfg.CacheTTLSeconds > 0 && f.cfg.CacheMaxBytes > 0 {
+		cache, err := ristretto.NewCache(&ristretto.Config{
+			NumCounters: 10000,
+			MaxCost:     f.cfg.CacheMaxBytes,
+			BufferItems: 64,
+		})
+		if err == nil {
+			f.cache = cache
+		}
+	}
+	return f
+}
+
+// Enabled 是否开启 URL 代取。
+func (f *FileURLFetcher) Enabled() bool {
+	return f != nil && f.cfg.Enabled
+}
+
+// InlineResponsesInputURLs 将 Responses 请求 input[].content[] 中的 input_image.image_url 与
+// input_file.file_url 下载并替换为 data URI（input_file 写入 file_data/filename 并移除 file_url）。
+// 未启用或没有需要代取的 URL 时原样返回 body。
+func (f *FileURLFetcher) InlineResponsesInputURLs(ctx context.Context, body []byte, proxyURL string) ([]byte, error) {
+	if !f.Enabled() {
+		return body, nil
+	}
+	type target struct {
+		path     string
+		url      string
+		isFile   bool
+		filename string
+	}
+	var targets []target
+	gjson.GetBytes(body, "input").ForEach(func(i, item gjson.Result) bool {
+		content := item.Get("content")
+		if !content.IsArray() {
+			return true
+		}
+		content.ForEach(func(j, part gjson.Result) bool {
+			prefix := "input." + i.String() + ".content." + j.String()
+			switch part.Get("type").String() {
+			case "input_image":
+				imageURL := part.Get("image_url")
+				if imageURL.IsObject() {
+					// 兼容 {"image_url":{"url":"..."}} 写法
+					if u := imageURL.Get("url").String(); isFetchableFileURL(u) {
+						targets = append(targets, target{path: prefix + ".image_url", url: u})
+					}
+				} else if u := imageURL.String(); isFetchableFileURL(u) {
+					targets = append(targets, target{path: prefix + ".image_url", url: u})
+				}
+			case "input_file":
+				if u := part.Get("file_url").String(); isFetchableFileURL(u) && !part.Get("file_data").Exists() {
+					targets = append(targets, target{path: prefix, url: u, isFile: true, filename: part.Get("filename").String()})
+				}
+			}
+			return true
+		})
+		return true
+	})
+	if len(targets) == 0 {
+		return body, nil
+	}
+	if err := f.checkFileCount(len(targets)); err != nil {
+		return nil, err
+	}
+
+	out := body
+	for _, t := range targets {
+		file, err := f.Fetch(ctx, t.url, proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		if !t.isFile && !strings.HasPrefix(file.mediaType, "image/") {
+			return nil, fileURLFetchError(t.url, "content type "+file.mediaType+" is not an image")
+		}
+		dataURI := "data:" + file.mediaType + ";base64," + base64.StdEncoding.EncodeToString(file.data)
+		if !t.isFile {
+			if out, err = sjson.SetBytes(out, t.path, dataURI); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if out, err = sjson.SetBytes(out, t.path+".file_data", dataURI); err != nil {
+			return nil, err
+		}
+		if t.filename == "" {
+			if out, err = sjson.SetBytes(out, t.path+".filename", filenameFromURL(t.url)); err != nil {
+				return nil, err
+			}
+		}
+		if out, err = sjson.DeleteBytes(out, t.path+".file_url"); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// InlineChatCompletionsImageURLs 将 Chat Completions 请求 messages[].content[] 中 image_url.url
+// 的远程图片下载并替换为 data URI。未启用或没有需要代取的 URL 时原样返回 body。
+func (f *FileURLFetcher) InlineChatCompletionsImageURLs(ctx context.Context, body []byte, proxyURL string) ([]byte, error) {
+	if !f.Enabled() {
+		return body, nil
+	}
+	type target struct {
+		path string
+		url  string
+	}
+	var targets []target
+	gjson.GetBytes(body, "messages").ForEach(func(i, msg gjson.Result) bool {
+		content := msg.Get("content")
+		if !content.IsArray() {
+			return true
+		}
+		content.ForEach(func(j, part gjson.Result) bool {
+			if part.Get("type").String() != "image_url" {
+				return true
+			}
+			prefix := "messages." + i.String() + ".content." + j.String() + ".image_url"
+			if u := part.Get("image_url.url").String(); isFetchableFileURL(u) {
+				targets = append(targets, target{path: prefix + ".url", url: u})
+			} else if u := part.Get("image_url").String(); part.Get("image_url").Type == gjson.String && isFetchableFileURL(u) {
+				targets = append(targets, target{path: prefix, url: u})
+			}
+			return true
+		})
+		return true
+	})
+	if len(targets) == 0 {
+		return body, nil
+	}
+	if err := f.checkFileCount(len(targets)); err != nil {
+		return nil, err
+	}
+
+	out := body
+	for _, t := range targets {
+		file, err := f.Fetch(ctx, t.url, proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(file.mediaType, "image/") {
+			return nil, fileURLFetchError(t.url, "content type "+file.mediaType+" is not an image")
+		}
+		dataURI := "data:" + file.mediaType + ";base64," + base64.StdEncoding.EncodeToString(file.data)
+		if out, err = sjson.SetBytes(out, t.path, dataURI); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Fetch 下载单个 URL（带缓存与并发合并），返回内容与媒体类型。
+// 缓存与合并按 (代理, URL) 区分，不同代理的下载结果互不复用。合并后的下载脱离首个调用方的
+// context，只受 timeout_seconds 约束；每个调用方在自己的 ctx 取消时立即返回，不影响其他等待者。
+func (f *FileURLFetcher) Fetch(ctx context.Context, rawURL, proxyURL string) (*fetchedFile, error) {
+	if err := f.validateURL(rawURL); err != nil {
+		return nil, fileURLFetchError(rawURL, err.Error())
+	}
+	key := proxyURL + "\x00" + rawURL
+	if f.cache != nil {
+		if v, ok := f.cache.Get(key); ok {
+			if file, ok := v.(*fetchedFile); ok {
+				return file, nil
+			}
+		}
+	}
+	ch := f.flight.DoChan(key, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Duration(f.cfg.TimeoutSeconds)*time.Second)
+		defer cancel()
+		file, err := f.download(fetchCtx, rawURL, proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		if f.cache != nil {
+			f.cache.SetWithTTL(key, file, int64(len(file.data)), time.Duration(f.cfg.CacheTTLSeconds)*time.Second)
+			f.cache.Wait()
+		}
+		return file, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*fetchedFile), nil
+	}
+}
+
+func (f *FileURLFetcher) download(ctx context.Context, rawURL, proxyURL string) (*fetchedFile, error) {
+	shared, err := httpclient.GetClient(httpclient.Options{
+		ProxyURL:           proxyURL,
+		Timeout:            time.Duration(f.cfg.TimeoutSeconds) * time.Second,
+		ValidateResolvedIP: true,
+		AllowPrivateHosts:  f.cfg.AllowPrivateHosts,
+	})
+	if err != nil {
+		return nil, fileURLFetchError(rawURL, "build http client: "+err.Error())
+	}
+	// 复制共享客户端，仅替换重定向策略，不影响其他调用方
+	client := *shared
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= fileURLFetchMaxRedirects {
+			return errors.New("too many redirects")
+		}
+		return f.validateURL(req.URL.String())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fileURLFetchError(rawURL, err.Error())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fileURLFetchError(rawURL, err.Error())
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fileURLFetchError(rawURL, "upstream returned status "+strconv.Itoa(resp.StatusCode))
+	}
+	if resp.ContentLength > f.cfg.MaxBytes {
+		return nil, fileURLTooLargeError(rawURL, f.cfg.MaxBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, fileURLFetchError(rawURL, err.Error())
+	}
+	if int64(len(data)) > f.cfg.MaxBytes {
+		return nil, fileURLTooLargeError(rawURL, f.cfg.MaxBytes)
+	}
+	if len(data) == 0 {
+		return nil, fileURLFetchError(rawURL, "empty response body")
+	}
+	return &fetchedFile{data: data, mediaType: detectFetchedMediaType(resp.Header.Get("Content-Type"), data)}, nil
+}
+
+func (f *FileURLFetcher) validateURL(rawURL string) error {
+	_, err := urlvalidator.ValidateHTTPURL(rawURL, f.allowInsecureHTTP, urlvalidator.ValidationOptions{
+		AllowedHosts:     f.allowedHosts,
+		RequireAllowlist: f.requireAllowlist,
+		AllowPrivate:     f.cfg.AllowPrivateHosts,
+	})
+	return err
+}
+
+func (f *FileURLFetcher) checkFileCount(n int) error {
+	if n <= f.cfg.MaxFilesPerRequest {
+		return nil
+	}
+	return infraerrors.BadRequest("TOO_MANY_FILE_URLS", fmt.Sprintf(
+		"request references %d file/image URLs, at most %d can be fetched per request", n, f.cfg.MaxFilesPerRequest,
+	)).WithMetadata(map[string]string{"max_files": strconv.Itoa(f.cfg.MaxFilesPerRequest)})
+}
+
+// isFetchableFileURL 仅代取 http/https URL；data URI 与 file_id 等原样保留。
+func isFetchableFileURL(u string) bool {
+	lower := strings.ToLower(strings.TrimSpace(u))
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// detectFetchedMediaType 优先使用响应 Content-Type，缺失或为通用二进制类型时按内容嗅探。
+func detectFetchedMediaType(contentType string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "" && mediaType != "application/octet-stream" {
+		return strings.ToLower(mediaType)
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
+
+func filenameFromURL(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		if name := path.Base(parsed.Path); name != "" && name != "." && name != "/" {
+			return name
+		}
+	}
+	return "file"
+}
+
+func fileURLFetchError(rawURL, reason string) error {
+	return infraerrors.BadRequest("FILE_URL_FETCH_FAILED", "failed to fetch file url: "+reason).
+		WithMetadata(map[string]string{"url": rawURL})
+}
+
+func fileURLTooLargeError(rawURL string, maxBytes int64) error {
+	return infraerrors.BadRequest("FILE_URL_TOO_LARGE", fmt.Sprintf("file url exceeds the %d-byte size limit", maxBytes)).
+		WithMetadata(map[string]string{"url": rawURL, "max_bytes": strconv.FormatInt(maxBytes, 10)})
+}
+
+// InlineResponsesFileURLs 经账号代理代取 Responses 请求中的文件/图片 URL（见 FileURLFetcher）。
+func (s *GatewayService) InlineResponsesFileURLs(ctx context.Context, account *Account, body []byte) ([]byte, error) {
+	return s.fileURLFetcher.InlineResponsesInputURLs(ctx, body, fileFetchProxyURL(account))
+}
+
+// InlineChatCompletionsImageURLs 经账号代理代取 Chat Completions 请求中的图片 URL（见 FileURLFetcher）。
+func (s *GatewayService) InlineChatCompletionsImageURLs(ctx context.Context, account *Account, body []byte) ([]byte, error) {
+	return s.fileURLFetcher.InlineChatCompletionsImageURLs(ctx, body, fileFetchProxyURL(account))
+}
+
+func fileFetchProxyURL(account *Account) string {
+	if account == nil || account.Proxy == nil {
+		return ""
+	}
+	return account.Proxy.URL()
+}
@@ -0,0 +1,219 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestFileURLFetcher(mutate func(cfg *config.Config)) *FileURLFetcher {
+	cfg := &config.Config{}
+	cfg.Security.URLAllowlist.AllowInsecureHTTP = true
+	cfg.Gateway.FileFetch = config.GatewayFileFetchConfig{
+		Enabled:            true,
+		MaxBytes:           1024,
+		MaxFilesPerRequest: 4,
+		TimeoutSeconds:     5,
+		CacheTTLSeconds:    60,
+		CacheMaxBytes:      1 << 20,
+		AllowPrivateHosts:  true, // httptest 监听 127.0.0.1
+	}
+	if mutate != nil {
+		mutate(cfg)
+	}
+	return NewFileURLFetcher(cfg)
+}
+
+func newFileServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cat.png", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG\r\n\x1a\nfake"))
+	})
+	mux.HandleFunc("/doc.pdf", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4"))
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://blocked.example.com/cat.png", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFileURLFetcher_InlineResponsesInputURLs(t *testing.T) {
+	var hits atomic.Int32
+	srv := newFileServer(t, &hits)
+	f := newTestFileURLFetcher(nil)
+
+	body := []byte(`{"model":"m","input":[{"role":"user","content":[` +
+		`{"type":"input_text","text":"hi"},` +
+		`{"type":"input_image","image_url":"` + srv.URL + `/cat.png"},` +
+		`{"type":"input_image","image_url":"data:image/png;base64,AAAA"},` +
+		`{"type":"input_file","file_url":"` + srv.URL + `/doc.pdf"}]}]}`)
+
+	out, err := f.InlineResponsesInputURLs(context.Background(), body, "")
+	require.NoError(t, err)
+	require.Equal(t, "data:image/png;base64,iVBORw0KGgpmYWtl", gjson.GetBytes(out, "input.0.content.1.image_url").String())
+	require.Equal(t, "data:image/png;base64,AAAA", gjson.GetBytes(out, "input.0.content.2.image_url").String())
+	require.Equal(t, "data:application/pdf;base64,JVBERi0xLjQ=", gjson.GetBytes(out, "input.0.content.3.file_data").String())
+	require.Equal(t, "doc.pdf", gjson.GetBytes(out, "input.0.content.3.filename").String())
+	require.False(t, gjson.GetBytes(out, "input.0.content.3.file_url").Exists())
+	require.Equal(t, int32(2), hits.Load())
+
+	// 第二次命中缓存，不再请求源站
+	_, err = f.InlineResponsesInputURLs(context.Background(), body, "")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), hits.Load())
+}
+
+func TestFileURLFetcher_InlineChatCompletionsImageURLs(t *testing.T) {
+	var hits atomic.Int32
+	srv := newFileServer(t, &hits)
+	f := newTestFileURLFetcher(nil)
+
+	body := []byte(`{"model":"m","messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"` + srv.URL + `/cat.png","detail":"high"}}]}]}`)
+	out, err := f.InlineChatCompletionsImageURLs(context.Background(), body, "")
+	require.NoError(t, err)
+	require.Equal(t, "data:image/png;base64,iVBORw0KGgpmYWtl", gjson.GetBytes(out, "messages.0.content.0.image_url.url").String())
+	require.Equal(t, "high", gjson.GetBytes(out, "messages.0.content.0.image_url.detail").String())
+
+	// 非图片内容拒绝作为 image_url 内联
+	body = []byte(`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"` + srv.URL + `/doc.pdf"}}]}]}`)
+	_, err = f.InlineChatCompletionsImageURLs(context.Background(), body, "")
+	require.Equal(t, "FILE_URL_FETCH_FAILED", infraerrors.Reason(err))
+}
+
+func TestFileURLFetcher_Limits(t *testing.T) {
+	var hits atomic.Int32
+	srv := newFileServer(t, &hits)
+
+	t.Run("size_cap", func(t *testing.T) {
+		f := newTestFileURLFetcher(nil)
+		body := []byte(`{"input":[{"role":"user","content":[{"type":"input_file","file_url":"` + srv.URL + `/big"}]}]}`)
+		_, err := f.InlineResponsesInputURLs(context.Background(), body, "")
+		require.Equal(t, "FILE_URL_TOO_LARGE", infraerrors.Reason(err))
+	})
+
+	t.Run("too_many_files", func(t *testing.T) {
+		f := newTestFileURLFetcher(func(cfg *config.Config) { cfg.Gateway.FileFetch.MaxFilesPerRequest = 1 })
+		body := []byte(`{"input":[{"role":"user","content":[{"type":"input_image","image_url":"` + srv.URL + `/cat.png"},{"type":"input_image","image_url":"` + srv.URL + `/cat.png?v=2"}]}]}`)
+		_, err := f.InlineResponsesInputURLs(context.Background(), body, "")
+		require.Equal(t, "TOO_MANY_FILE_URLS", infraerrors.Reason(err))
+	})
+
+	t.Run("private_host_blocked_by_default", func(t *testing.T) {
+		f := newTestFileURLFetcher(func(cfg *config.Config) { cfg.Gateway.FileFetch.AllowPrivateHosts = false })
+		_, err := f.Fetch(context.Background(), srv.URL+"/cat.png", "")
+		require.Equal(t, "FILE_URL_FETCH_FAILED", infraerrors.Reason(err))
+	})
+
+	t.Run("allowlist_enforced", func(t *testing.T) {
+		f := newTestFileURLFetcher(func(cfg *config.Config) {
+			cfg.Security.URLAllowlist.Enabled = true
+			cfg.Security.URLAllowlist.FileFetchHosts = []string{"cdn.example.com"}
+		})
+		_, err := f.Fetch(context.Background(), srv.URL+"/cat.png", "")
+		require.Equal(t, "FILE_URL_FETCH_FAILED", infraerrors.Reason(err))
+	})
+
+	t.Run("redirect_revalidated", func(t *testing.T) {
+		f := newTestFileURLFetcher(func(cfg *config.Config) {
+			cfg.Security.URLAllowlist.Enabled = true
+			cfg.Security.URLAllowlist.FileFetchHosts = []string{"127.0.0.1"}
+		})
+		_, err := f.Fetch(context.Background(), srv.URL+"/redirect", "")
+		require.Equal(t, "FILE_URL_FETCH_FAILED", infraerrors.Reason(err))
+		require.Contains(t, infraerrors.Message(err), "blocked.example.com")
+	})
+
+	t.Run("disabled_passthrough", func(t *testing.T) {
+		f := newTestFileURLFetcher(func(cfg *config.Config) { cfg.Gateway.FileFetch.Enabled = false })
+		body := []byte(`{"input":[{"role":"user","content":[{"type":"input_image","image_url":"http://10.0.0.1/a.png"}]}]}`)
+		out, err := f.InlineResponsesInputURLs(context.Background(), body, "")
+		require.NoError(t, err)
+		require.Equal(t, body, out)
+	})
+}
+
+func TestFileURLFetcher_FetchSharedAcrossCallers(t *testing.T) {
+	t.Run("first_caller_cancel_does_not_fail_waiters", func(t *testing.T) {
+		var hits atomic.Int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hits.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG\r\n\x1a\nfake"))
+		}))
+		t.Cleanup(srv.Close)
+		f := newTestFileURLFetcher(nil)
+
+		firstCtx, cancelFirst := context.WithCancel(context.Background())
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := f.Fetch(firstCtx, srv.URL+"/cat.png", "")
+			firstErr <- err
+		}()
+		<-started
+
+		type fetchResult struct {
+			file *fetchedFile
+			err  error
+		}
+		secondResult := make(chan fetchResult, 1)
+		go func() {
+			file, err := f.Fetch(context.Background(), srv.URL+"/cat.png", "")
+			secondResult <- fetchResult{file: file, err: err}
+		}()
+
+		cancelFirst()
+		require.ErrorIs(t, <-firstErr, context.Canceled)
+		close(release)
+		res := <-secondResult
+		require.NoError(t, res.err)
+		require.Equal(t, "image/png", res.file.mediaType)
+		require.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("cache_keyed_by_proxy", func(t *testing.T) {
+		var hits, proxyHits atomic.Int32
+		srv := newFileServer(t, &hits)
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxyHits.Add(1)
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG\r\n\x1a\nproxied"))
+		}))
+		t.Cleanup(proxy.Close)
+		f := newTestFileURLFetcher(nil)
+
+		direct, err := f.Fetch(context.Background(), srv.URL+"/cat.png", "")
+		require.NoError(t, err)
+		proxied, err := f.Fetch(context.Background(), srv.URL+"/cat.png", proxy.URL)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), hits.Load())
+		require.Equal(t, int32(1), proxyHits.Load())
+		require.NotEqual(t, direct.data, proxied.data)
+	})
+}
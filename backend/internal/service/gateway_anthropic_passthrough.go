@@ -656,6 +656,10 @@ func (s *GatewayService) parseSSEUsagePassthrough(data string, usage *ClaudeUsag
 				usage.CacheCreation1hTokens = int(cc1h.Int())
 			}
 		}
+	case "content_block_delta":
+		if parsed.Get("delta.type").String() == "input_json_delta" {
+			usage.addToolCallChars(len(parsed.Get("delta.partial_json").String()))
+		}
 	}
 
 	if usage.CacheReadInputTokens == 0 {
@@ -711,6 +715,7 @@ func parseClaudeUsageFromResponseBody(body []byte) *ClaudeUsage {
 			usage.CacheReadInputTokens = int(cached)
 		}
 	}
+	usage.ToolCallOutputTokens = claudeToolCallTokensFromResponseBody(body)
 	return usage
 }
 
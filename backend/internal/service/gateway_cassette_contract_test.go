@@ -0,0 +1,52 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/cassette"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// 以录制的上游 cassette 作为假上游，回归测试流式转发与 usage 解析，无需真实凭证。
+func TestGatewayService_CassetteContract_AnthropicMessagesStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	player, err := cassette.LoadPlayer("testdata/cassettes/anthropic_messages_stream.json")
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	body := []byte(`{"model":"claude-sonnet-4-5","stream":true,"max_tokens":64,"messages":[{"role":"user","content":"hello"}]}`)
+
+	cfg := &config.Config{Gateway: config.GatewayConfig{MaxLineSize: defaultMaxLineSize}}
+	svc := &GatewayService{
+		cfg:                  cfg,
+		responseHeaderFilter: compileResponseHeaderFilter(cfg),
+		httpUpstream:         player,
+		rateLimitService:     &RateLimitService{},
+		deferredService:      &DeferredService{},
+	}
+
+	result, err := svc.Forward(context.Background(), c, newAnthropicAPIKeyAccountForTest(), &ParsedRequest{
+		Body:   NewRequestBodyRef(body),
+		Model:  "claude-sonnet-4-5",
+		Stream: true,
+	})
+	require.NoError(t, err)
+	require.Zero(t, player.Unused(), "the recorded interaction should be replayed")
+
+	require.True(t, result.Stream)
+	require.Equal(t, 12, result.Usage.InputTokens)
+	require.Equal(t, 4, result.Usage.OutputTokens)
+	require.Equal(t, 5, result.Usage.CacheReadInputTokens)
+	require.Contains(t, rec.Body.String(), `"text":"Hi there"`)
+	require.Contains(t, rec.Body.String(), "event: message_stop")
+	require.Empty(t, rec.Header().Get("Set-Cookie"))
+}
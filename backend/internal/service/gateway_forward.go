@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -87,7 +88,10 @@ func sleepWithContext(ctx context.Context, d time.Duration) error {
 }
 
 // Forward 转发请求到Claude API
-func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *Account, parsed *ParsedRequest) (*ForwardResult, error) {
+func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *Account, parsed *ParsedRequest) (forwardResult *ForwardResult, retErr error) {
+	ctx, span := startGatewayForwardSpan(ctx, "gateway.forward", account)
+	defer func() { endGatewayForwardSpan(span, forwardResult.upstreamRequestID(), retErr) }()
+
 	startTime := time.Now()
 	if parsed == nil {
 		return nil, fmt.Errorf("parse request: empty request")
@@ -353,6 +357,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	// 重试循环
 	var resp *http.Response
 	lastWireBody := body
+	// lastSourceBody 为被接受请求在 buildUpstreamRequest 之前的请求体，截断自动续写以此为基础重新构建请求。
+	lastSourceBody := body
 	retryStart := time.Now()
 	for attempt := 1; attempt <= maxRetryAttempts; attempt++ {
 		// 构建上游请求（每次重试需要重新构建，因为请求体需要重新读取）
@@ -364,6 +370,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		}
 		// 记录本次实际发送的 wire body；只有请求成功后才写回 ParsedRequest，避免 400 retry 基于已签名 CCH 再改写。
 		lastWireBody = wireBody
+		lastSourceBody = body
 
 		// 发送请求
 		resp, err = s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
@@ -449,6 +456,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 							if retryResp.StatusCode < 400 {
 								// 重试请求被上游接受后同步 ParsedRequest，保证 usage/日志看到真实请求体。
 								lastWireBody = retryWireBody
+								lastSourceBody = filteredBody
 								if err := replaceBody(retryWireBody); err != nil {
 									_ = retryResp.Body.Close()
 									return nil, err
@@ -490,6 +498,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 											if retryResp2.StatusCode < 400 {
 												// 二阶段工具块降级成功时也必须更新当前 body。
 												lastWireBody = retryWireBody2
+												lastSourceBody = filteredBody2
 												if err := replaceBody(retryWireBody2); err != nil {
 													_ = retryResp2.Body.Close()
 													return nil, err
@@ -569,6 +578,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 								if budgetRetryResp.StatusCode < 400 {
 									// budget 修正请求成功后，ParsedRequest 也要描述被接受的修正版。
 									lastWireBody = budgetWireBody
+									lastSourceBody = rectifiedBody
 									if err := replaceBody(budgetWireBody); err != nil {
 										_ = budgetRetryResp.Body.Close()
 										return nil, err
@@ -786,7 +796,9 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	var firstTokenMs *int
 	var clientDisconnect bool
 	if reqStream {
-		streamResult, err := s.handleStreamingResponse(ctx, resp, c, account, startTime, originalModel, reqModel, shouldMimicClaudeCode)
+		streamCtx, streamTiming := s.streamTiming.begin(ctx, account, originalModel, resp)
+		streamResult, err := s.handleStreamingResponse(streamCtx, resp, c, account, startTime, originalModel, reqModel, shouldMimicClaudeCode)
+		streamTiming.Finish(err, streamResult != nil && streamResult.clientDisconnect)
 		if err != nil {
 			var sseErr *sseStreamErrorEventError
 			if errors.As(err, &sseErr) {
@@ -836,7 +848,15 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		firstTokenMs = streamResult.firstTokenMs
 		clientDisconnect = streamResult.clientDisconnect
 	} else {
-		usage, err = s.handleNonStreamingResponse(ctx, resp, c, account, originalModel, reqModel)
+		group, _ := ctx.Value(ctxkey.Group).(*Group)
+		continuation := s.newAnthropicContinuation(group, lastSourceBody, func(contCtx context.Context, contBody []byte) (*http.Response, error) {
+			contReq, _, err := s.buildUpstreamRequest(contCtx, c, account, contBody, token, tokenType, reqModel, false, shouldMimicClaudeCode)
+			if err != nil {
+				return nil, err
+			}
+			return s.httpUpstream.DoWithTLS(contReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
+		})
+		usage, err = s.handleNonStreamingResponseWithContinuation(ctx, resp, c, account, originalModel, reqModel, continuation)
 		if err != nil {
 			return nil, err
 		}
@@ -32,7 +32,10 @@ func (s *GatewayService) ForwardAsChatCompletions(
 	account *Account,
 	body []byte,
 	parsed *ParsedRequest,
-) (*ForwardResult, error) {
+) (forwardResult *ForwardResult, retErr error) {
+	ctx, span := startGatewayForwardSpan(ctx, "gateway.forward_as_chat_completions", account)
+	defer func() { endGatewayForwardSpan(span, forwardResult.upstreamRequestID(), retErr) }()
+
 	startTime := time.Now()
 
 	// 1. Parse Chat Completions request
@@ -303,6 +303,22 @@ func (m *mockGroupRepoForGateway) BindAccountsToGroup(ctx context.Context, group
 	return nil
 }
 
+func (m *mockGroupRepoForGateway) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	return nil, nil
+}
+
+func (m *mockGroupRepoForGateway) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	return nil
+}
+
+func (m *mockGroupRepoForGateway) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	return nil, nil
+}
+
+func (m *mockGroupRepoForGateway) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	return nil
+}
+
 func (m *mockGroupRepoForGateway) GetAccountIDsByGroupIDs(ctx context.Context, groupIDs []int64) ([]int64, error) {
 	return nil, nil
 }
@@ -50,6 +50,8 @@ type SessionContext struct {
 	ClientIP  string
 	UserAgent string
 	APIKeyID  int64
+	// ConversationID 客户端显式携带的会话标识（session_id / conversation_id 请求头），用于会话亲和
+	ConversationID string
 }
 
 type jsonRange struct {
@@ -114,6 +116,7 @@ func clearGatewayRequestDerivedState(parsed *ParsedRequest) {
 	parsed.Model = ""
 	parsed.Stream = false
 	parsed.MetadataUserID = ""
+	parsed.PromptCacheKey = ""
 	parsed.HasSystem = false
 	parsed.ThinkingEnabled = false
 	parsed.OutputEffort = ""
@@ -194,6 +197,7 @@ func parseGatewayRequestCurrentBody(parsed *ParsedRequest, protocol string) erro
 	}
 
 	parsed.MetadataUserID = gjson.Get(jsonStr, "metadata.user_id").String()
+	parsed.PromptCacheKey = strings.TrimSpace(gjson.Get(jsonStr, "prompt_cache_key").String())
 
 	thinkingType := gjson.Get(jsonStr, "thinking.type").String()
 	parsed.ThinkingEnabled = thinkingType == "enabled" || thinkingType == "adaptive"
@@ -254,6 +258,7 @@ type ParsedRequest struct {
 	Model           string          // 请求的模型名称
 	Stream          bool            // 是否为流式请求
 	MetadataUserID  string          // metadata.user_id（用于会话亲和）
+	PromptCacheKey  string          // prompt_cache_key（OpenAI 兼容客户端的会话亲和标识）
 	HasSystem       bool            // 是否包含 system 字段（包含 null 也视为显式传入）
 	ThinkingEnabled bool            // 是否开启 thinking（部分平台会影响最终模型名）
 	OutputEffort    string          // output_config.effort（Claude API 的推理强度控制）
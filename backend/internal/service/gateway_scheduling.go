@@ -247,6 +247,17 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			}
 		}
 	}
+	// 语言路由：命中分组语言规则且指定了账号/代理时，以规则账号作为优先列表（优先级高于模型路由）
+	if group != nil {
+		if lang := PromptLanguageFromContext(ctx); lang != "" {
+			if ids := languageRoutingAccountIDs(group.LanguageRouteFor(lang), accounts); len(ids) > 0 {
+				if s.debugModelRoutingEnabled() {
+					logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] language routing: group_id=%d language=%s matched_ids=%v", group.ID, lang, ids)
+				}
+				routingAccountIDs = ids
+			}
+		}
+	}
 
 	// ============ Layer 1: 模型路由优先选择（优先级高于粘性会话） ============
 	if len(routingAccountIDs) > 0 && s.concurrencyService != nil {
@@ -454,7 +465,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 							continue
 						}
 						if sessionHash != "" && s.cache != nil {
-							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, stickySessionTTL)
+							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, s.stickySessionTTL())
 						}
 						if s.debugModelRoutingEnabled() {
 							logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed select: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), item.account.ID)
@@ -493,7 +504,8 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			account, ok := accountByID[accountID]
 			if ok {
 				// 检查账户是否需要清理粘性会话绑定
-				clearSticky := shouldClearStickySession(account, requestedModel)
+				// 粘性账号所在层级低于分组当前启用层级时（高层级已恢复）同样放弃粘性
+				clearSticky := shouldClearStickySession(account, requestedModel) || s.failoverTiers.stickyDemoted(groupID, account)
 				if clearSticky {
 					slog.Debug("sticky.layer1_5_no_routing_clear",
 						"account_id", accountID,
@@ -545,7 +557,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 								"result", "slot_acquired",
 							)
 							if s.cache != nil {
-								_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, stickySessionTTL)
+								_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, s.stickySessionTTL())
 							}
 							return s.newSelectionResult(ctx, account, true, result.ReleaseFunc, nil)
 						}
@@ -650,6 +662,8 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	if len(candidates) == 0 {
 		return nil, ErrNoAvailableAccounts
 	}
+	// 分组故障转移层级：仅当更高层级账号全部不可用时才启用更低层级
+	candidates = s.failoverTiers.filter(groupID, candidates)
 
 	accountLoads := make([]AccountWithConcurrency, 0, len(candidates))
 	for _, acc := range candidates {
@@ -681,18 +695,12 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			}
 		}
 
-		// 分层过滤选择：优先级 →（可选）最早重置 → 负载率 → LRU
+		// 分层过滤选择：延迟 SLO → 分组账号选择策略（默认 priority：优先级 →（可选）最早重置 → 负载率 → LRU）
+		strategy := s.groupAccountSelectionStrategy(ctx, group, groupID)
 		for len(available) > 0 {
-			// 1. 取优先级最小的集合
-			candidates := filterByMinPriority(available)
-			// 2. （可选）use-it-or-lose-it：优先选用会话窗口最早重置的账号
-			if cfg.PreferSoonestReset {
-				candidates = filterBySoonestReset(candidates)
-			}
-			// 3. 取负载率最低的集合
-			candidates = filterByMinLoadRate(candidates)
-			// 4. LRU 选择最久未用的账号
-			selected := selectByLRU(candidates, preferOAuth)
+			// 排除违反延迟 SLO 的账号×模型组合（全部违反时不排除）
+			candidates := s.latencySLO.preferLatencyHealthy(available, requestedModel)
+			selected := s.selectByStrategy(strategy, groupID, candidates, cfg.PreferSoonestReset, preferOAuth)
 			if selected == nil {
 				break
 			}
@@ -704,7 +712,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 					result.ReleaseFunc() // 释放槽位，继续尝试下一个账号
 				} else {
 					if sessionHash != "" && s.cache != nil {
-						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, stickySessionTTL)
+						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, s.stickySessionTTL())
 					}
 					return s.newSelectionResult(ctx, selected.account, true, result.ReleaseFunc, nil)
 				}
@@ -724,6 +732,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 
 	// ============ Layer 3: 兜底排队 ============
 	s.sortCandidatesForFallback(candidates, preferOAuth, cfg.FallbackSelectionMode)
+	s.latencySLO.demoteLatencyDegraded(candidates, requestedModel)
 	for _, acc := range candidates {
 		// 会话数量限制检查（等待计划也需要占用会话配额）
 		if !s.checkAndRegisterSession(ctx, acc, sessionHash) {
@@ -752,7 +761,7 @@ func (s *GatewayService) tryAcquireByLegacyOrder(ctx context.Context, candidates
 				continue
 			}
 			if sessionHash != "" && s.cache != nil {
-				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, stickySessionTTL)
+				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, s.stickySessionTTL())
 			}
 			selection, err := s.newSelectionResult(ctx, acc, true, result.ReleaseFunc, nil)
 			if err != nil {
@@ -796,6 +805,17 @@ func (s *GatewayService) groupFromContext(ctx context.Context, groupID int64) *G
 	return nil
 }
 
+// groupAccountSelectionStrategy 返回分组的账号选择策略；强制平台模式下未解析分组时从请求上下文读取。
+func (s *GatewayService) groupAccountSelectionStrategy(ctx context.Context, group *Group, groupID *int64) string {
+	if group == nil && groupID != nil {
+		group = s.groupFromContext(ctx, *groupID)
+	}
+	if group == nil {
+		return ""
+	}
+	return group.AccountSelectionStrategy
+}
+
 func (s *GatewayService) resolveGroupByID(ctx context.Context, groupID int64) (*Group, error) {
 	if group := s.groupFromContext(ctx, groupID); group != nil {
 		return group, nil
@@ -1192,6 +1212,13 @@ func (s *GatewayService) withWindowCostPrefetch(ctx context.Context, accounts []
 		}
 	}
 
+	// 叠加在途流式请求的窗口费用预占（仅对已取得费用的账号，未取得的走单查路径）
+	for accountID, reserved := range s.reservedWindowCosts(ctx, accountIDs) {
+		if _, ok := costs[accountID]; ok {
+			costs[accountID] += reserved
+		}
+	}
+
 	return context.WithValue(ctx, windowCostPrefetchContextKey, costs)
 }
 
@@ -1226,7 +1253,7 @@ func (s *GatewayService) isAccountSchedulableForWindowCost(ctx context.Context,
 	}
 	if s.sessionLimitCache != nil {
 		if cost, hit, err := s.sessionLimitCache.GetWindowCost(ctx, account.ID); err == nil && hit {
-			currentCost = cost
+			currentCost = cost + s.reservedWindowCosts(ctx, []int64{account.ID})[account.ID]
 			goto checkSchedulability
 		}
 	}
@@ -1249,6 +1276,7 @@ func (s *GatewayService) isAccountSchedulableForWindowCost(ctx context.Context,
 		if s.sessionLimitCache != nil {
 			_ = s.sessionLimitCache.SetWindowCost(ctx, account.ID, currentCost)
 		}
+		currentCost += s.reservedWindowCosts(ctx, []int64{account.ID})[account.ID]
 	}
 
 checkSchedulability:
@@ -1842,7 +1870,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -1862,7 +1890,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 				account, err := s.getSchedulableAccount(ctx, accountID)
 				// 检查账号分组归属和平台匹配（确保粘性会话不会跨分组或跨平台）
 				if err == nil {
-					clearSticky := shouldClearStickySession(account, requestedModel)
+					clearSticky := shouldClearStickySession(account, requestedModel) || s.failoverTiers.stickyDemoted(groupID, account)
 					if clearSticky {
 						_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 					}
@@ -1934,6 +1962,12 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 			selected = acc
 			continue
 		}
+		if c := compareGroupTier(acc, selected, groupID); c != 0 {
+			if c < 0 {
+				selected = acc
+			}
+			continue
+		}
 		if acc.Priority < selected.Priority {
 			selected = acc
 		} else if acc.Priority == selected.Priority {
@@ -1954,6 +1988,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 		}
 	}
 
+	s.failoverTiers.observeSelected(groupID, selected)
 	if selected == nil {
 		stats := s.logDetailedSelectionFailure(ctx, groupID, sessionHash, requestedModel, platform, accounts, excludedIDs, false)
 		if requestedModel != "" {
@@ -1964,7 +1999,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
@@ -2102,7 +2137,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -2122,7 +2157,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 				account, err := s.getSchedulableAccount(ctx, accountID)
 				// 检查账号分组归属和有效性：原生平台直接匹配，antigravity 需要启用混合调度
 				if err == nil {
-					clearSticky := shouldClearStickySession(account, requestedModel)
+					clearSticky := shouldClearStickySession(account, requestedModel) || s.failoverTiers.stickyDemoted(groupID, account)
 					if clearSticky {
 						_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 					}
@@ -2195,6 +2230,12 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 			selected = acc
 			continue
 		}
+		if c := compareGroupTier(acc, selected, groupID); c != 0 {
+			if c < 0 {
+				selected = acc
+			}
+			continue
+		}
 		if acc.Priority < selected.Priority {
 			selected = acc
 		} else if acc.Priority == selected.Priority {
@@ -2215,6 +2256,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 		}
 	}
 
+	s.failoverTiers.observeSelected(groupID, selected)
 	if selected == nil {
 		stats := s.logDetailedSelectionFailure(ctx, groupID, sessionHash, requestedModel, nativePlatform, accounts, excludedIDs, true)
 		if requestedModel != "" {
@@ -2225,7 +2267,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
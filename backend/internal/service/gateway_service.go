@@ -30,7 +30,7 @@ import (
 const (
 	claudeAPIURL            = "https://api.anthropic.com/v1/messages?beta=true"
 	claudeAPICountTokensURL = "https://api.anthropic.com/v1/messages/count_tokens?beta=true"
-	stickySessionTTL        = time.Hour // 粘性会话TTL
+	defaultStickySessionTTL = time.Hour // 粘性会话默认TTL（gateway.sticky_session_ttl_seconds 未配置时）
 	defaultMaxLineSize      = 500 * 1024 * 1024
 	// Canonical Claude Code banner. Keep it EXACT (no trailing whitespace/newlines)
 	// to match real Claude CLI traffic as closely as possible. When we need a visual
@@ -532,6 +532,11 @@ type ClaudeUsage struct {
 	CacheCreation5mTokens    int // 5分钟缓存创建token（来自嵌套 cache_creation 对象）
 	CacheCreation1hTokens    int // 1小时缓存创建token（来自嵌套 cache_creation 对象）
 	ImageOutputTokens        int `json:"image_output_tokens,omitempty"`
+	// ReasoningOutputTokens / ToolCallOutputTokens 是 OutputTokens 中推理与工具调用参数的部分：
+	// 推理 token 取自上游明细，工具调用 token 按响应中的工具参数估算；上游未提供时为 0。
+	ReasoningOutputTokens int `json:"-"`
+	ToolCallOutputTokens  int `json:"-"`
+	toolCallChars         int // 流式累计的工具参数字符数
 }
 
 // ForwardResult 转发结果
@@ -627,6 +632,7 @@ type GatewayService struct {
 	modelsListCacheTTL    time.Duration
 	settingService        *SettingService
 	responseHeaderFilter  *responseheaders.CompiledHeaderFilter
+	fileURLFetcher        *FileURLFetcher
 	debugModelRouting     atomic.Bool
 	debugClaudeMimic      atomic.Bool
 	channelService        *ChannelService
@@ -635,6 +641,11 @@ type GatewayService struct {
 	tlsFPProfileService   *TLSFingerprintProfileService
 	balanceNotifyService  *BalanceNotifyService
 	userPlatformQuotaRepo UserPlatformQuotaRepository
+	latencySLO            *LatencySLOTracker         // 可选：账号×模型延迟 SLO 降权
+	streamTiming          *OpsStreamTimingRecorder   // 可选：采样流逐事件耗时
+	failoverTiers         groupFailoverTierTracker   // 分组内故障转移层级状态
+	selectionRoundRobin   weightedRoundRobinSelector // 分组账号选择策略 weighted_round_robin 的轮询状态
+	accountLatency        accountLatencyTracker      // 分组账号选择策略 lowest_latency 的账号延迟估计
 }
 
 // NewGatewayService creates a new GatewayService
@@ -697,6 +708,7 @@ func NewGatewayService(
 		modelsListCache:       gocache.New(modelsListTTL, time.Minute),
 		modelsListCacheTTL:    modelsListTTL,
 		responseHeaderFilter:  compileResponseHeaderFilter(cfg),
+		fileURLFetcher:        NewFileURLFetcher(cfg),
 		tlsFPProfileService:   tlsFPProfileService,
 		channelService:        channelService,
 		resolver:              resolver,
@@ -718,6 +730,16 @@ func NewGatewayService(
 	return svc
 }
 
+// SetLatencySLOTracker 注入账号×模型延迟 SLO 跟踪器（nil 表示关闭）。
+func (s *GatewayService) SetLatencySLOTracker(tracker *LatencySLOTracker) {
+	s.latencySLO = tracker
+}
+
+// SetStreamTimingRecorder 注入采样流耗时记录器（nil 表示关闭）。
+func (s *GatewayService) SetStreamTimingRecorder(recorder *OpsStreamTimingRecorder) {
+	s.streamTiming = recorder
+}
+
 // GenerateSessionHash 从预解析请求计算粘性会话 hash
 func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 	if parsed == nil {
@@ -742,7 +764,17 @@ func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 		)
 	}
 
-	// 2. 提取带 cache_control: {type: "ephemeral"} 的内容
+	// 2. 客户端显式会话标识：session_id / conversation_id 请求头，其次 body.prompt_cache_key
+	if conversationID := explicitConversationID(parsed); conversationID != "" {
+		hash := s.hashContent(conversationSessionSeed(parsed.SessionContext, conversationID))
+		slog.Info("sticky.hash_source",
+			"source", "conversation_id",
+			"hash", hash,
+		)
+		return hash
+	}
+
+	// 3. 提取带 cache_control: {type: "ephemeral"} 的内容
 	cacheableContent := s.extractCacheableContent(parsed)
 	if cacheableContent != "" {
 		hash := s.hashContent(cacheableContent)
@@ -753,7 +785,7 @@ func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 		return hash
 	}
 
-	// 3. 最后 fallback: 使用 session上下文 + system + 所有消息的完整摘要串
+	// 4. 最后 fallback: 使用 session上下文 + system + 所有消息的完整摘要串
 	var combined strings.Builder
 	// 混入请求上下文区分因子，避免不同用户相同消息产生相同 hash
 	if parsed.SessionContext != nil {
@@ -785,12 +817,46 @@ func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 	return ""
 }
 
+// explicitConversationID 返回客户端显式携带的会话标识；请求头优先于 body.prompt_cache_key。
+func explicitConversationID(parsed *ParsedRequest) string {
+	if parsed.SessionContext != nil {
+		if id := strings.TrimSpace(parsed.SessionContext.ConversationID); id != "" {
+			return id
+		}
+	}
+	return parsed.PromptCacheKey
+}
+
+// conversationSessionSeed 将显式会话标识限定在 API Key 范围内，避免不同用户使用相同的通用标识时粘连到同一会话。
+func conversationSessionSeed(sessionCtx *SessionContext, conversationID string) string {
+	if sessionCtx == nil || sessionCtx.APIKeyID <= 0 {
+		return "conversation:" + conversationID
+	}
+	return "conversation:" + strconv.FormatInt(sessionCtx.APIKeyID, 10) + ":" + conversationID
+}
+
+// ConversationIDFromHeaders 提取客户端显式会话标识请求头（与 OpenAI 路径一致：session_id 优先，其次 conversation_id）。
+func ConversationIDFromHeaders(h http.Header) string {
+	if id := strings.TrimSpace(h.Get("session_id")); id != "" {
+		return id
+	}
+	return strings.TrimSpace(h.Get("conversation_id"))
+}
+
+// stickySessionTTL 返回会话粘连 TTL（gateway.sticky_session_ttl_seconds），未配置时为 1 小时。
+func (s *GatewayService) stickySessionTTL() time.Duration {
+	if s != nil && s.cfg != nil && s.cfg.Gateway.StickySessionTTLSeconds > 0 {
+		return time.Duration(s.cfg.Gateway.StickySessionTTLSeconds) * time.Second
+	}
+	return defaultStickySessionTTL
+}
+
 // BindStickySession sets session -> account binding with standard TTL.
 func (s *GatewayService) BindStickySession(ctx context.Context, groupID *int64, sessionHash string, accountID int64) error {
 	if sessionHash == "" || accountID <= 0 || s.cache == nil {
 		return nil
 	}
-	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, stickySessionTTL)
+	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, s.stickySessionTTL())
 }
 
 // GetCachedSessionAccountID retrieves the account ID bound to a sticky session.
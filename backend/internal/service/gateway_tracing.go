@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startGatewayForwardSpan 为一次账号转发开启 span（覆盖上游请求与流式响应的完整处理），
+// 同一客户端请求 failover 到多个账号时每次转发各有一个 span。
+func startGatewayForwardSpan(ctx context.Context, name string, account *Account) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	if id, _ := ctx.Value(ctxkey.ClientRequestID).(string); strings.TrimSpace(id) != "" {
+		span.SetAttributes(tracing.AttrClientRequestID.String(strings.TrimSpace(id)))
+	}
+	if account != nil {
+		span.SetAttributes(tracing.AttrAccountID.Int64(account.ID), tracing.AttrPlatform.String(account.Platform))
+	}
+	return ctx, span
+}
+
+// endGatewayForwardSpan 记录上游请求 ID 与转发结果后结束 span。
+func endGatewayForwardSpan(span trace.Span, upstreamRequestID string, err error) {
+	if upstreamRequestID = strings.TrimSpace(upstreamRequestID); upstreamRequestID != "" {
+		span.SetAttributes(tracing.AttrUpstreamRequestID.String(upstreamRequestID))
+	}
+	tracing.EndSpan(span, err)
+}
+
+func (r *ForwardResult) upstreamRequestID() string {
+	if r == nil {
+		return ""
+	}
+	return r.RequestID
+}
+
+func (r *OpenAIForwardResult) upstreamRequestID() string {
+	if r == nil {
+		return ""
+	}
+	return r.RequestID
+}
@@ -0,0 +1,48 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestGatewayForwardSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx := context.WithValue(context.Background(), ctxkey.ClientRequestID, "client-req-1")
+	account := &Account{ID: 9, Platform: PlatformAnthropic}
+
+	_, span := startGatewayForwardSpan(ctx, "gateway.forward", account)
+	var result *ForwardResult
+	endGatewayForwardSpan(span, result.upstreamRequestID(), &UpstreamFailoverError{StatusCode: 529})
+
+	_, span = startGatewayForwardSpan(ctx, "gateway.forward", account)
+	endGatewayForwardSpan(span, (&ForwardResult{RequestID: "req_up"}).upstreamRequestID(), nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, codes.Unset, spans[1].Status().Code)
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[1].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	require.Equal(t, "client-req-1", attrs[tracing.AttrClientRequestID].AsString())
+	require.Equal(t, int64(9), attrs[tracing.AttrAccountID].AsInt64())
+	require.Equal(t, PlatformAnthropic, attrs[tracing.AttrPlatform].AsString())
+	require.Equal(t, "req_up", attrs[tracing.AttrUpstreamRequestID].AsString())
+}
@@ -352,7 +352,8 @@ func (s *GatewayService) readUpstreamErrorBody(resp *http.Response) ([]byte, err
 	if s != nil && s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody && s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes > int(limit) {
 		limit = int64(s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
 	}
-	return io.ReadAll(io.LimitReader(resp.Body, limit))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	return replaceUpstreamHTMLErrorBody(resp, body), err
 }
 
 func (s *GatewayService) handleErrorResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, requestedModel ...string) (*ForwardResult, error) {
@@ -672,6 +673,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 
 	usage := &ClaudeUsage{}
 	var firstTokenMs *int
+	streamTiming := sseStreamTimingFromContext(ctx)
 	scanner := bufio.NewScanner(resp.Body)
 	// 设置更大的buffer以处理长行
 	maxLineSize := defaultMaxLineSize
@@ -682,8 +684,9 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	scanner.Buffer(scanBuf[:0], maxLineSize)
 
 	type scanEvent struct {
-		line string
-		err  error
+		line   string
+		err    error
+		readAt time.Time
 	}
 	// 独立 goroutine 读取上游，避免读取阻塞导致超时/keepalive无法处理
 	events := make(chan scanEvent, 16)
@@ -702,8 +705,9 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 		defer putSSEScannerBuf64K(scanBuf)
 		defer close(events)
 		for scanner.Scan() {
-			atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
-			if !sendEvent(scanEvent{line: scanner.Text()}) {
+			readAt := time.Now()
+			atomic.StoreInt64(&lastReadAt, readAt.UnixNano())
+			if !sendEvent(scanEvent{line: scanner.Text(), readAt: readAt}) {
 				return
 			}
 		}
@@ -1048,6 +1052,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 						}
 					}
 				}
+				streamTiming.ObserveEvent(ev.readAt, time.Now())
 				continue
 			}
 
@@ -1124,6 +1129,7 @@ type sseUsagePatch struct {
 	hasCacheCreation5m       bool
 	cacheCreation1hTokens    int
 	hasCacheCreation1h       bool
+	toolCallChars            int // content_block_delta(input_json_delta) 中的工具参数字符数
 }
 
 func (s *GatewayService) extractSSEUsagePatch(event map[string]any) *sseUsagePatch {
@@ -1199,6 +1205,17 @@ func (s *GatewayService) extractSSEUsagePatch(event map[string]any) *sseUsagePat
 			}
 		}
 		return patch
+
+	case "content_block_delta":
+		delta, _ := event["delta"].(map[string]any)
+		if deltaType, _ := delta["type"].(string); deltaType != "input_json_delta" {
+			return nil
+		}
+		partial, _ := delta["partial_json"].(string)
+		if partial == "" {
+			return nil
+		}
+		return &sseUsagePatch{toolCallChars: len(partial)}
 	}
 
 	return nil
@@ -1227,6 +1244,9 @@ func mergeSSEUsagePatch(usage *ClaudeUsage, patch *sseUsagePatch) {
 	if patch.hasCacheCreation1h {
 		usage.CacheCreation1hTokens = patch.cacheCreation1hTokens
 	}
+	if patch.toolCallChars > 0 {
+		usage.addToolCallChars(patch.toolCallChars)
+	}
 }
 
 func parseSSEUsageInt(value any) (int, bool) {
@@ -1329,6 +1349,11 @@ func (s *GatewayService) resolveCacheTTLUsageOverrideTarget(ctx context.Context,
 }
 
 func (s *GatewayService) handleNonStreamingResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, originalModel, mappedModel string) (*ClaudeUsage, error) {
+	return s.handleNonStreamingResponseWithContinuation(ctx, resp, c, account, originalModel, mappedModel, nil)
+}
+
+// handleNonStreamingResponseWithContinuation 处理非流式响应；continuation 非 nil 时对因 max_tokens 截断的响应自动续写并拼接。
+func (s *GatewayService) handleNonStreamingResponseWithContinuation(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, originalModel, mappedModel string, continuation *anthropicContinuation) (*ClaudeUsage, error) {
 	// 更新5h窗口状态
 	s.rateLimitService.UpdateSessionWindow(ctx, account, resp.Header)
 
@@ -1348,6 +1373,8 @@ func (s *GatewayService) handleNonStreamingResponse(ctx context.Context, resp *h
 		return nil, fmt.Errorf("parse response: %w", err)
 	}
 
+	response.Usage.ToolCallOutputTokens = claudeToolCallTokensFromResponseBody(body)
+
 	// 解析嵌套的 cache_creation 对象中的 5m/1h 明细
 	cc5m := gjson.GetBytes(body, "usage.cache_creation.ephemeral_5m_input_tokens")
 	cc1h := gjson.GetBytes(body, "usage.cache_creation.ephemeral_1h_input_tokens")
@@ -1356,6 +1383,12 @@ func (s *GatewayService) handleNonStreamingResponse(ctx context.Context, resp *h
 		response.Usage.CacheCreation1hTokens = int(cc1h.Int())
 	}
 
+	// 截断检测与自动续写：续写请求的用量累加到本次请求一并计费
+	if anthropicResponseTruncated(body) {
+		recordTruncatedResponse(PlatformAnthropic)
+		body = continuation.run(ctx, body, &response.Usage)
+	}
+
 	// 兼容 Kimi cached_tokens → cache_read_input_tokens
 	if response.Usage.CacheReadInputTokens == 0 {
 		cachedTokens := gjson.GetBytes(body, "usage.cached_tokens").Int()
@@ -9,6 +9,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
 )
 
@@ -45,6 +46,9 @@ type RecordUsageInput struct {
 	ForceCacheBilling  bool               // 强制缓存计费：将 input_tokens 转为 cache_read 计费（用于粘性会话切换）
 	APIKeyService      APIKeyQuotaUpdater // 可选：用于更新API Key配额
 	QuotaPlatform      string             // user×platform 配额计量平台：handler 在请求 ctx 内经 QuotaPlatform() 算定后传入（后扣运行在 worker 池 background ctx 上，取不到 ForcePlatform）
+	ThreadID           string             // 客户端会话/线程 ID（可选，用于按会话汇总成本）
+	// WindowCostReservation 流式请求的窗口费用预占（可选），计费完成后按实际标准费用结算
+	WindowCostReservation *WindowCostReservation
 
 	ChannelUsageFields // 渠道映射信息（由 handler 在 Forward 前解析）
 }
@@ -59,6 +63,15 @@ type apiKeyAuthCacheInvalidator interface {
 	InvalidateAuthCacheByKey(ctx context.Context, key string)
 }
 
+// apiKeyThroughputRecorder 由 APIKeyService 实现，按实际 token 用量扣除 Key 的 TPM 令牌桶。
+type apiKeyThroughputRecorder interface {
+	RecordThroughputTokens(ctx context.Context, apiKey *APIKey, tokens int64)
+}
+
+type apiKeyProjectAuthCacheInvalidator interface {
+	InvalidateAuthCacheByProjectID(ctx context.Context, projectID int64)
+}
+
 type usageLogBestEffortWriter interface {
 	CreateBestEffort(ctx context.Context, log *UsageLog) error
 }
@@ -269,6 +282,14 @@ func buildUsageBillingCommand(requestID string, usageLog *UsageLog, p *postUsage
 	if p.shouldUpdateAccountQuota() {
 		cmd.AccountQuotaCost = p.Cost.TotalCost * p.AccountRateMultiplier
 	}
+	if p.APIKey.TokenLimit > 0 && usageLog != nil {
+		cmd.APIKeyTokens = int64(usageLog.InputTokens + usageLog.OutputTokens + usageLog.CacheCreationTokens + usageLog.CacheReadTokens)
+	}
+	// 项目无论是否设置额度都累加 quota_used，用作项目合计消费
+	if p.APIKey.ProjectID != nil && *p.APIKey.ProjectID > 0 && p.Cost.ActualCost > 0 {
+		cmd.APIKeyProjectID = *p.APIKey.ProjectID
+		cmd.APIKeyProjectCost = p.Cost.ActualCost
+	}
 
 	cmd.Normalize()
 	return cmd
@@ -282,6 +303,8 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 	cmd := buildUsageBillingCommand(requestID, usageLog, p)
 	if cmd == nil || cmd.RequestID == "" || repo == nil {
 		postUsageBilling(ctx, p, deps)
+		recordAPIKeyThroughputTokens(ctx, p, usageLog)
+		recordSpendBudgetUsage(p, deps)
 		return true, nil
 	}
 
@@ -298,16 +321,42 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 		return false, nil
 	}
 
-	if result.APIKeyQuotaExhausted {
+	if result.APIKeyQuotaExhausted || result.APIKeyTokenLimitExhausted {
 		if invalidator, ok := p.APIKeyService.(apiKeyAuthCacheInvalidator); ok && p.APIKey != nil && p.APIKey.Key != "" {
 			invalidator.InvalidateAuthCacheByKey(billingCtx, p.APIKey.Key)
 		}
 	}
+	if result.APIKeyProjectQuotaExhausted {
+		// 项目额度由全部成员 Key 共享，需一并刷新其快照
+		if invalidator, ok := p.APIKeyService.(apiKeyProjectAuthCacheInvalidator); ok {
+			invalidator.InvalidateAuthCacheByProjectID(billingCtx, cmd.APIKeyProjectID)
+		}
+	}
 
 	finalizePostUsageBilling(billingCtx, p, deps, result)
+	recordAPIKeyThroughputTokens(billingCtx, p, usageLog)
+	recordSpendBudgetUsage(p, deps)
 	return true, nil
 }
 
+// recordSpendBudgetUsage 把本次实际消费累加到消费预算的本地缓存，使预算耗尽后的拦截立即生效。
+func recordSpendBudgetUsage(p *postUsageBillingParams, deps *billingDeps) {
+	if p == nil || p.Cost == nil || p.User == nil || p.APIKey == nil || deps == nil || deps.billingCacheService == nil {
+		return
+	}
+	deps.billingCacheService.spendBudgetService.RecordSpend(p.User.ID, p.APIKey.GroupID, p.APIKey.ID, p.Cost.ActualCost)
+}
+
+// recordAPIKeyThroughputTokens 请求计费落账后扣除 Key 的 TPM 令牌；重复请求（未 Applied）不会走到这里。
+func recordAPIKeyThroughputTokens(ctx context.Context, p *postUsageBillingParams, usageLog *UsageLog) {
+	if p.APIKey == nil || p.APIKey.TPMLimit <= 0 || usageLog == nil {
+		return
+	}
+	if recorder, ok := p.APIKeyService.(apiKeyThroughputRecorder); ok {
+		recorder.RecordThroughputTokens(ctx, p.APIKey, int64(usageLog.TotalTokens()))
+	}
+}
+
 func finalizePostUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *billingDeps, result *UsageBillingApplyResult) {
 	if p == nil || p.Cost == nil || deps == nil {
 		return
@@ -511,6 +560,10 @@ func writeUsageLogBestEffort(ctx context.Context, repo UsageLogRepository, usage
 	if repo == nil || usageLog == nil {
 		return
 	}
+	if metrics.Enabled() {
+		platform, _ := ctx.Value(ctxkey.Platform).(string)
+		metrics.AddTokens(platform, usageLog.Model, usageLog.InputTokens, usageLog.OutputTokens, usageLog.CacheCreationTokens, usageLog.CacheReadTokens)
+	}
 	usageCtx, cancel := detachedBillingContext(ctx)
 	defer cancel()
 
@@ -549,20 +602,22 @@ type recordUsageOpts struct {
 // RecordUsage 记录使用量并扣费（或更新订阅用量）
 func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInput) error {
 	return s.recordUsageCore(ctx, &recordUsageCoreInput{
-		Result:             input.Result,
-		APIKey:             input.APIKey,
-		User:               input.User,
-		Account:            input.Account,
-		Subscription:       input.Subscription,
-		InboundEndpoint:    input.InboundEndpoint,
-		UpstreamEndpoint:   input.UpstreamEndpoint,
-		UserAgent:          input.UserAgent,
-		IPAddress:          input.IPAddress,
-		RequestPayloadHash: input.RequestPayloadHash,
-		ForceCacheBilling:  input.ForceCacheBilling,
-		APIKeyService:      input.APIKeyService,
-		QuotaPlatform:      input.QuotaPlatform,
-		ChannelUsageFields: input.ChannelUsageFields,
+		Result:                input.Result,
+		APIKey:                input.APIKey,
+		User:                  input.User,
+		Account:               input.Account,
+		Subscription:          input.Subscription,
+		InboundEndpoint:       input.InboundEndpoint,
+		UpstreamEndpoint:      input.UpstreamEndpoint,
+		UserAgent:             input.UserAgent,
+		IPAddress:             input.IPAddress,
+		RequestPayloadHash:    input.RequestPayloadHash,
+		ForceCacheBilling:     input.ForceCacheBilling,
+		APIKeyService:         input.APIKeyService,
+		QuotaPlatform:         input.QuotaPlatform,
+		ThreadID:              input.ThreadID,
+		ChannelUsageFields:    input.ChannelUsageFields,
+		WindowCostReservation: input.WindowCostReservation,
 	}, &recordUsageOpts{})
 }
 
@@ -583,6 +638,7 @@ type RecordUsageLongContextInput struct {
 	ForceCacheBilling     bool               // 强制缓存计费：将 input_tokens 转为 cache_read 计费（用于粘性会话切换）
 	APIKeyService         APIKeyQuotaUpdater // API Key 配额服务（可选）
 	QuotaPlatform         string             // user×platform 配额计量平台：handler 在请求 ctx 内经 QuotaPlatform() 算定后传入（后扣运行在 worker 池 background ctx 上，取不到 ForcePlatform）
+	ThreadID              string             // 客户端会话/线程 ID（可选，用于按会话汇总成本）
 
 	ChannelUsageFields // 渠道映射信息（由 handler 在 Forward 前解析）
 }
@@ -603,6 +659,7 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 		ForceCacheBilling:  input.ForceCacheBilling,
 		APIKeyService:      input.APIKeyService,
 		QuotaPlatform:      input.QuotaPlatform,
+		ThreadID:           input.ThreadID,
 		ChannelUsageFields: input.ChannelUsageFields,
 	}, &recordUsageOpts{
 		LongContextThreshold:  input.LongContextThreshold,
@@ -625,7 +682,9 @@ type recordUsageCoreInput struct {
 	ForceCacheBilling  bool
 	APIKeyService      APIKeyQuotaUpdater
 	QuotaPlatform      string
+	ThreadID           string
 	ChannelUsageFields
+	WindowCostReservation *WindowCostReservation
 }
 
 // recordUsageCore 是 RecordUsage 和 RecordUsageWithLongContext 的统一实现。
@@ -637,6 +696,10 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	account := input.Account
 	subscription := input.Subscription
 	ApplyForwardImageBillingResolution(result)
+	if account != nil {
+		s.latencySLO.ObserveResult(account.ID, result.Model, result.Stream, result.Duration, result.FirstTokenMs)
+		s.accountLatency.observe(account.ID, result.Duration, result.FirstTokenMs)
+	}
 
 	// 强制缓存计费：将 input_tokens 转为 cache_read_input_tokens
 	// 用于粘性会话切换时的特殊计费处理
@@ -685,6 +748,8 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 
 	// 计算费用
 	cost := s.calculateRecordUsageCost(ctx, result, apiKey, billingModel, multiplier, imageMultiplier, opts)
+	applyGroupRequestSurcharge(cost, apiKey)
+	input.WindowCostReservation.Settle(cost.TotalCost)
 
 	// 判断计费方式：订阅模式 vs 余额模式
 	isSubscriptionBilling := subscription != nil && apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
@@ -716,6 +781,7 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	}
 
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
+		s.concurrencyService.RecordUsageLogConsumption(usageLog)
 		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
 		logger.LegacyPrintf("service.gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
 		s.deferredService.ScheduleLastUsedUpdate(account.ID)
@@ -746,6 +812,7 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	if billingErr != nil {
 		return billingErr
 	}
+	s.concurrencyService.RecordUsageLogConsumption(usageLog)
 	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
 
 	return nil
@@ -932,6 +999,8 @@ func (s *GatewayService) buildRecordUsageLog(
 		ModelMappingChain:     optionalTrimmedStringPtr(input.ModelMappingChain),
 		UserAgent:             optionalTrimmedStringPtr(input.UserAgent),
 		IPAddress:             optionalTrimmedStringPtr(input.IPAddress),
+		ThreadID:              optionalTrimmedStringPtr(NormalizeUsageThreadID(input.ThreadID)),
+		RequestClass:          optionalTrimmedStringPtr(RequestClassFromContext(ctx)),
 		GroupID:               apiKey.GroupID,
 		SubscriptionID:        optionalSubscriptionID(subscription),
 		CreatedAt:             time.Now(),
@@ -939,6 +1008,8 @@ func (s *GatewayService) buildRecordUsageLog(
 	if result.ImageCount > 0 && (cost == nil || cost.BillingMode != string(BillingModeToken)) {
 		usageLog.RateMultiplier = imageMultiplier
 	}
+	usageLog.ReasoningTokens, usageLog.ToolCallTokens = normalizeOutputTokenBreakdown(
+		result.Usage.OutputTokens, result.Usage.ReasoningOutputTokens, result.Usage.ToolCallOutputTokens)
 	if cost != nil {
 		usageLog.InputCost = cost.InputCost
 		usageLog.OutputCost = cost.OutputCost
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// windowCostReservationTimeout 结算/释放预占的 Redis 操作超时（脱离请求 ctx 执行）。
+const windowCostReservationTimeout = 2 * time.Second
+
+// WindowCostReservation 单个流式请求对账号 5h 窗口费用的软预占。
+// Settle / Release 只生效一次，对 nil 安全。
+type WindowCostReservation struct {
+	cache     SessionLimitCache
+	accountID int64
+	id        string
+	Amount    float64
+	once      sync.Once
+}
+
+// Settle 用量到达后以实际标准费用结算：预占换成实际费用，保留到窗口费用缓存刷新为止。
+func (r *WindowCostReservation) Settle(actualCost float64) {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), windowCostReservationTimeout)
+		defer cancel()
+		if err := r.cache.SettleWindowCost(ctx, r.accountID, r.id, actualCost); err != nil {
+			// 结算失败时预占保留到 TTL 到期，调度只会偏保守
+			slog.Warn("[WindowCostReservation] settle failed", "account_id", r.accountID, "reservation_id", r.id, "error", err)
+		}
+	})
+}
+
+// Release 请求失败（无用量）时释放预占。
+func (r *WindowCostReservation) Release() {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), windowCostReservationTimeout)
+		defer cancel()
+		if err := r.cache.ReleaseWindowCost(ctx, r.accountID, r.id); err != nil {
+			slog.Warn("[WindowCostReservation] release failed", "account_id", r.accountID, "reservation_id", r.id, "error", err)
+		}
+	})
+}
+
+func (s *GatewayService) windowCostReservationEnabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Gateway.Scheduling.WindowCostReservation.Enabled && s.sessionLimitCache != nil
+}
+
+// ReserveWindowCost 为流式请求预占账号窗口费用。
+// 仅对设置了窗口费用上限的 Anthropic OAuth/SetupToken 账号生效；未启用、估算为 0 或 Redis 故障时返回 nil（失败开放）。
+func (s *GatewayService) ReserveWindowCost(ctx context.Context, account *Account, model string, body []byte) *WindowCostReservation {
+	if !s.windowCostReservationEnabled() || account == nil || !account.IsAnthropicOAuthOrSetupToken() || account.GetWindowCostLimit() <= 0 {
+		return nil
+	}
+	amount := s.estimateWindowCost(model, body)
+	if amount <= 0 {
+		return nil
+	}
+	cfg := s.cfg.Gateway.Scheduling.WindowCostReservation
+	id := uuid.NewString()
+	ttl := time.Duration(cfg.ReservationTTLSeconds) * time.Second
+	if err := s.sessionLimitCache.ReserveWindowCost(ctx, account.ID, id, amount, ttl); err != nil {
+		slog.Warn("[WindowCostReservation] reserve failed, skip reservation", "account_id", account.ID, "error", err)
+		return nil
+	}
+	return &WindowCostReservation{cache: s.sessionLimitCache, accountID: account.ID, id: id, Amount: amount}
+}
+
+// estimateWindowCost 按请求体大小与声明的最大输出 token 估算标准费用（不含倍率，与窗口费用口径一致）。
+func (s *GatewayService) estimateWindowCost(model string, body []byte) float64 {
+	if s.billingService == nil || model == "" {
+		return 0
+	}
+	cfg := s.cfg.Gateway.Scheduling.WindowCostReservation
+	outputTokens := cfg.DefaultOutputTokens
+	for _, path := range prepaidMaxOutputTokenPaths {
+		if v := gjson.GetBytes(body, path); v.Exists() && v.Int() > 0 {
+			outputTokens = int(v.Int())
+			break
+		}
+	}
+	cost, err := s.billingService.CalculateCost(model, UsageTokens{
+		InputTokens:  len(body) / prepaidBytesPerInputToken,
+		OutputTokens: outputTokens,
+	}, 1.0)
+	if err != nil {
+		return 0
+	}
+	estimate := cost.TotalCost
+	if cfg.MaxReservation > 0 && estimate > cfg.MaxReservation {
+		estimate = cfg.MaxReservation
+	}
+	return estimate
+}
+
+// reservedWindowCosts 批量读取账号的在途预占总额；未启用或读取失败时返回 nil。
+func (s *GatewayService) reservedWindowCosts(ctx context.Context, accountIDs []int64) map[int64]float64 {
+	if !s.windowCostReservationEnabled() || len(accountIDs) == 0 {
+		return nil
+	}
+	reserved, err := s.sessionLimitCache.GetReservedWindowCostBatch(ctx, accountIDs)
+	if err != nil {
+		slog.Warn("[WindowCostReservation] load reserved failed", "error", err)
+		return nil
+	}
+	return reserved
+}
@@ -0,0 +1,144 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type windowCostReservationCacheStub struct {
+	SessionLimitCache
+
+	windowCost map[int64]float64
+	reserved   map[int64]float64
+	holds      map[string]float64
+	settled    map[string]float64
+	released   []string
+}
+
+func newWindowCostReservationCacheStub() *windowCostReservationCacheStub {
+	return &windowCostReservationCacheStub{
+		windowCost: map[int64]float64{},
+		reserved:   map[int64]float64{},
+		holds:      map[string]float64{},
+		settled:    map[string]float64{},
+	}
+}
+
+func (s *windowCostReservationCacheStub) GetWindowCost(_ context.Context, accountID int64) (float64, bool, error) {
+	cost, ok := s.windowCost[accountID]
+	return cost, ok, nil
+}
+
+func (s *windowCostReservationCacheStub) ReserveWindowCost(_ context.Context, accountID int64, reservationID string, amount float64, _ time.Duration) error {
+	s.holds[reservationID] = amount
+	s.reserved[accountID] += amount
+	return nil
+}
+
+func (s *windowCostReservationCacheStub) SettleWindowCost(_ context.Context, _ int64, reservationID string, actualCost float64) error {
+	s.settled[reservationID] = actualCost
+	return nil
+}
+
+func (s *windowCostReservationCacheStub) ReleaseWindowCost(_ context.Context, _ int64, reservationID string) error {
+	s.released = append(s.released, reservationID)
+	return nil
+}
+
+func (s *windowCostReservationCacheStub) GetReservedWindowCostBatch(_ context.Context, accountIDs []int64) (map[int64]float64, error) {
+	out := make(map[int64]float64, len(accountIDs))
+	for _, id := range accountIDs {
+		if v, ok := s.reserved[id]; ok {
+			out[id] = v
+		}
+	}
+	return out, nil
+}
+
+func newWindowCostReservationTestService(cache SessionLimitCache, maxReservation float64) *GatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.Scheduling.WindowCostReservation = config.GatewayWindowCostReservationConfig{
+		Enabled:               true,
+		DefaultOutputTokens:   8192,
+		MaxReservation:        maxReservation,
+		ReservationTTLSeconds: 900,
+	}
+	return &GatewayService{cfg: cfg, sessionLimitCache: cache, billingService: newTestBillingService()}
+}
+
+func windowCostReservationTestAccount(limit float64) *Account {
+	return &Account{
+		ID:       1,
+		Platform: PlatformAnthropic,
+		Type:     AccountTypeOAuth,
+		Extra:    map[string]any{"window_cost_limit": limit},
+	}
+}
+
+func TestGatewayService_ReserveWindowCost(t *testing.T) {
+	cache := newWindowCostReservationCacheStub()
+	svc := newWindowCostReservationTestService(cache, 0)
+	body := []byte(`{"model":"claude-sonnet-4","max_tokens":1000,"stream":true}`)
+
+	r := svc.ReserveWindowCost(context.Background(), windowCostReservationTestAccount(10), "claude-sonnet-4", body)
+	require.NotNil(t, r)
+	require.Greater(t, r.Amount, 0.0)
+	require.InDelta(t, r.Amount, cache.reserved[1], 1e-12)
+
+	// max_tokens 越大预占越多
+	larger := svc.ReserveWindowCost(context.Background(), windowCostReservationTestAccount(10), "claude-sonnet-4",
+		[]byte(`{"model":"claude-sonnet-4","max_tokens":32000,"stream":true}`))
+	require.NotNil(t, larger)
+	require.Greater(t, larger.Amount, r.Amount)
+
+	// 结算/释放只生效一次
+	r.Settle(0.02)
+	r.Release()
+	require.Equal(t, map[string]float64{r.id: 0.02}, cache.settled)
+	require.Empty(t, cache.released)
+}
+
+func TestGatewayService_ReserveWindowCostSkips(t *testing.T) {
+	cache := newWindowCostReservationCacheStub()
+	svc := newWindowCostReservationTestService(cache, 0)
+	body := []byte(`{"max_tokens":1000}`)
+
+	require.Nil(t, svc.ReserveWindowCost(context.Background(), windowCostReservationTestAccount(0), "claude-sonnet-4", body), "no window cost limit")
+	apiKeyAccount := windowCostReservationTestAccount(10)
+	apiKeyAccount.Type = AccountTypeAPIKey
+	require.Nil(t, svc.ReserveWindowCost(context.Background(), apiKeyAccount, "claude-sonnet-4", body))
+
+	svc.cfg.Gateway.Scheduling.WindowCostReservation.Enabled = false
+	require.Nil(t, svc.ReserveWindowCost(context.Background(), windowCostReservationTestAccount(10), "claude-sonnet-4", body))
+	require.Empty(t, cache.holds)
+
+	var r *WindowCostReservation
+	r.Settle(1)
+	r.Release()
+}
+
+func TestGatewayService_EstimateWindowCostCapped(t *testing.T) {
+	svc := newWindowCostReservationTestService(newWindowCostReservationCacheStub(), 0.05)
+	require.InDelta(t, 0.05, svc.estimateWindowCost("claude-sonnet-4", []byte(`{"max_tokens":64000}`)), 1e-12)
+}
+
+func TestIsAccountSchedulableForWindowCost_IncludesReservations(t *testing.T) {
+	cache := newWindowCostReservationCacheStub()
+	svc := newWindowCostReservationTestService(cache, 0)
+	account := windowCostReservationTestAccount(10)
+	cache.windowCost[1] = 8
+
+	require.True(t, svc.isAccountSchedulableForWindowCost(context.Background(), account, false))
+
+	cache.reserved[1] = 2.5
+	require.False(t, svc.isAccountSchedulableForWindowCost(context.Background(), account, false), "in-flight reservations count toward the window limit")
+
+	svc.cfg.Gateway.Scheduling.WindowCostReservation.Enabled = false
+	require.True(t, svc.isAccountSchedulableForWindowCost(context.Background(), account, false))
+}
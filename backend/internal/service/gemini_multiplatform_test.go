@@ -255,6 +255,22 @@ func (m *mockGroupRepoForGemini) BindAccountsToGroup(ctx context.Context, groupI
 	return nil
 }
 
+func (m *mockGroupRepoForGemini) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	return nil, nil
+}
+
+func (m *mockGroupRepoForGemini) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	return nil
+}
+
+func (m *mockGroupRepoForGemini) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	return nil, nil
+}
+
+func (m *mockGroupRepoForGemini) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	return nil
+}
+
 func (m *mockGroupRepoForGemini) GetAccountIDsByGroupIDs(ctx context.Context, groupIDs []int64) ([]int64, error) {
 	return nil, nil
 }
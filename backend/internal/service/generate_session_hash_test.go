@@ -4,8 +4,11 @@ package service
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/domain"
 	"github.com/stretchr/testify/require"
 )
@@ -646,3 +649,39 @@ func TestGenerateSessionHash_GeminiEndToEnd(t *testing.T) {
 	h3 := svc.GenerateSessionHash(parsed3)
 	require.NotEqual(t, h, h3, "different user with same Gemini request should get different hash")
 }
+
+func TestGenerateSessionHash_ExplicitConversationIDStableAcrossTurns(t *testing.T) {
+	svc := &GatewayService{}
+	ctx := &SessionContext{ClientIP: "10.0.0.1", UserAgent: "opencode/1.0", APIKeyID: 42}
+
+	turn1 := mustParseResponsesSessionHashRequest(t, `{"model":"claude-sonnet-4-5","prompt_cache_key":"conv-1","input":"hello"}`, ctx)
+	turn2 := mustParseResponsesSessionHashRequest(t, `{"model":"claude-sonnet-4-5","prompt_cache_key":"conv-1","input":"tell me more"}`, ctx)
+	h1 := svc.GenerateSessionHash(turn1)
+	require.NotEmpty(t, h1)
+	require.Equal(t, h1, svc.GenerateSessionHash(turn2), "prompt_cache_key pins every turn to the same session")
+
+	otherKey := mustParseResponsesSessionHashRequest(t, `{"model":"claude-sonnet-4-5","prompt_cache_key":"conv-1","input":"hello"}`, &SessionContext{APIKeyID: 7})
+	require.NotEqual(t, h1, svc.GenerateSessionHash(otherKey), "the same conversation id under another API key is a different session")
+
+	withHeader := mustParseResponsesSessionHashRequest(t, `{"model":"claude-sonnet-4-5","prompt_cache_key":"conv-1","input":"hello"}`,
+		&SessionContext{APIKeyID: 42, ConversationID: "conv-2"})
+	headerOnly := mustParseSessionHashRequest(t, anthropicSessionBody("sys", []any{map[string]any{"role": "user", "content": "hi"}}, ""),
+		&SessionContext{APIKeyID: 42, ConversationID: "conv-2"})
+	require.Equal(t, svc.GenerateSessionHash(headerOnly), svc.GenerateSessionHash(withHeader), "conversation header takes precedence over prompt_cache_key")
+}
+
+func TestConversationIDFromHeaders(t *testing.T) {
+	h := http.Header{}
+	require.Empty(t, ConversationIDFromHeaders(h))
+	h.Set("conversation_id", " conv ")
+	require.Equal(t, "conv", ConversationIDFromHeaders(h))
+	h.Set("session_id", "sess")
+	require.Equal(t, "sess", ConversationIDFromHeaders(h))
+}
+
+func TestGatewayStickySessionTTL(t *testing.T) {
+	require.Equal(t, defaultStickySessionTTL, (&GatewayService{}).stickySessionTTL())
+	cfg := &config.Config{}
+	cfg.Gateway.StickySessionTTLSeconds = 600
+	require.Equal(t, 10*time.Minute, (&GatewayService{cfg: cfg}).stickySessionTTL())
+}
@@ -12,6 +12,13 @@ import (
 
 type OpenAIMessagesDispatchModelConfig = domain.OpenAIMessagesDispatchModelConfig
 type GroupModelsListConfig = domain.GroupModelsListConfig
+type GroupOutputPostprocessConfig = domain.GroupOutputPostprocessConfig
+type OutputPostprocessRule = domain.OutputPostprocessRule
+type GroupLanguageRoutingConfig = domain.GroupLanguageRoutingConfig
+type LanguageRoutingRule = domain.LanguageRoutingRule
+type GroupStreamReplayConfig = domain.GroupStreamReplayConfig
+type GroupCapabilityCheckConfig = domain.GroupCapabilityCheckConfig
+type GroupAutoContinuationConfig = domain.GroupAutoContinuationConfig
 
 type Group struct {
 	ID             int64
@@ -29,6 +36,9 @@ type Group struct {
 	Status             string
 	Hydrated           bool // indicates the group was loaded from a trusted repository source
 
+	// RequestSurcharge 按次固定附加费（USD）：在倍率计算后的实际费用上叠加，原始成本不变。
+	RequestSurcharge float64
+
 	SubscriptionType    string
 	DailyLimitUSD       *float64
 	WeeklyLimitUSD      *float64
@@ -85,6 +95,43 @@ type Group struct {
 	// 一旦设置即接管该分组用户的限流（覆盖用户级 rpm_limit），可被 user-group rpm_override 进一步覆盖。
 	RPMLimit int
 
+	// ReservedConcurrencyRatio 账号并发中为关键 API Key 预留的比例（0 = 不预留）。
+	ReservedConcurrencyRatio float64
+
+	// OverloadFallbackModels 过载降级映射（请求模型模式 -> 备用模型，支持末尾 * 通配）。
+	// 首选账号全部饱和或冷却时，/v1/messages 请求改用备用模型重新调度，而不是直接返回 429/503。
+	OverloadFallbackModels map[string]string
+
+	// ContextOverflowModels 长上下文路由映射（请求模型模式 -> 大上下文模型，支持末尾 * 通配）。
+	// 估算输入 token 超出模型上下文窗口时，/v1/messages 请求改用大上下文模型，避免上游 context_length_exceeded。
+	ContextOverflowModels map[string]string
+	// ContextOverflowReject 超出上下文窗口且无可用大上下文模型时，在转发前直接返回 400 并给出提示。
+	ContextOverflowReject bool
+	// ContextTruncationStrategy 超出上下文窗口且无法改路由时的截断策略（drop_oldest/summarize/middle_out，空表示不截断）。
+	ContextTruncationStrategy string
+	// AccountSelectionStrategy 负载感知调度的账号选择策略（weighted_round_robin/least_recently_used/lowest_latency，空表示 priority）。
+	AccountSelectionStrategy string
+
+	// ImageMaxEdge / ImageMaxBytes 图片预处理目标（0 = 不限制）：转发前把超出目标的 base64 图片
+	// 等比缩放到最长边不超过 ImageMaxEdge，并重新压缩到不超过 ImageMaxBytes，降低 token 成本并避免上游尺寸限制报错。
+	ImageMaxEdge  int
+	ImageMaxBytes int
+
+	// OutputPostprocess 输出后处理：对最终输出文本做正则替换/违禁词过滤并追加署名（JSON 与流式增量均生效）。
+	OutputPostprocess GroupOutputPostprocessConfig
+
+	// LanguageRouting 语言路由：按提示词主要语言改用其他模型，或优先调度指定账号/代理下的账号。
+	LanguageRouting GroupLanguageRoutingConfig
+
+	// StreamReplay 流式断线续传：缓存进行中响应的最近 SSE 事件，客户端断线后可携带 Last-Event-ID 重连续读，无需重新生成。
+	StreamReplay GroupStreamReplayConfig
+
+	// CapabilityCheck 模型能力校验：请求内容需要映射后模型不具备的能力（图片、音频、工具、推理）时改用备用模型，否则提前拒绝并指出具体位置。
+	CapabilityCheck GroupCapabilityCheckConfig
+
+	// AutoContinuation 截断自动续写：非流式响应因输出 token 上限截断时，以已生成内容作为 assistant 预填充继续请求并拼接输出。
+	AutoContinuation GroupAutoContinuationConfig
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -322,3 +369,13 @@ func computePeakAwareMultipliers(apiKey *APIKey, base float64, now time.Time) (t
 	text = base * peak
 	return
 }
+
+// applyGroupRequestSurcharge 在倍率计算后的实际费用上叠加分组按次附加费。原始成本 TotalCost 不变，
+// 使 usage_logs 可同时统计上游原始成本（total_cost）与加价后的计费金额（actual_cost）。
+// 仅对产生费用的请求收取，定价缺失按 0 记账的请求不收附加费。
+func applyGroupRequestSurcharge(cost *CostBreakdown, apiKey *APIKey) {
+	if cost == nil || cost.TotalCost <= 0 || apiKey == nil || apiKey.Group == nil || apiKey.Group.RequestSurcharge <= 0 {
+		return
+	}
+	cost.ActualCost += apiKey.Group.RequestSurcharge
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// GroupTier 返回账号在指定分组内的故障转移层级；未找到绑定关系时视为 primary。
+func (a *Account) GroupTier(groupID int64) int {
+	if a == nil || groupID <= 0 {
+		return AccountGroupTierPrimary
+	}
+	for i := range a.AccountGroups {
+		if a.AccountGroups[i].GroupID == groupID {
+			return a.AccountGroups[i].Tier
+		}
+	}
+	return AccountGroupTierPrimary
+}
+
+// groupFailoverTierTracker 记录各分组当前启用的故障转移层级，
+// 用于在层级切换时输出日志，以及让绑定在低层级账号上的粘性会话在高层级恢复后回迁。
+// 零值可用；状态仅在本实例内存中维护，跨实例的告警由 ops 指标 group_failover_tier 负责。
+type groupFailoverTierTracker struct {
+	mu     sync.Mutex
+	active map[int64]int
+}
+
+// filter 仅保留候选中层级最高（数值最小）的账号，并记录该分组当前启用的层级。
+// 候选应已完成可调度性过滤：只要更高层级还有可用账号，低层级账号就不会进入负载均衡。
+func (t *groupFailoverTierTracker) filter(groupID *int64, candidates []*Account) []*Account {
+	if groupID == nil || *groupID <= 0 || len(candidates) == 0 {
+		return candidates
+	}
+	gid := *groupID
+	top := candidates[0].GroupTier(gid)
+	mixed := false
+	for _, acc := range candidates[1:] {
+		tier := acc.GroupTier(gid)
+		if tier != top {
+			mixed = true
+		}
+		if tier < top {
+			top = tier
+		}
+	}
+	t.observe(gid, top)
+	if !mixed {
+		return candidates
+	}
+	kept := make([]*Account, 0, len(candidates))
+	for _, acc := range candidates {
+		if acc.GroupTier(gid) == top {
+			kept = append(kept, acc)
+		}
+	}
+	return kept
+}
+
+// observe 更新分组当前层级；层级变化时输出日志，进入 emergency 层级时以 Error 级别记录。
+func (t *groupFailoverTierTracker) observe(groupID int64, tier int) {
+	t.mu.Lock()
+	if t.active == nil {
+		t.active = make(map[int64]int)
+	}
+	prev, seen := t.active[groupID]
+	t.active[groupID] = tier
+	t.mu.Unlock()
+
+	if !seen {
+		prev = AccountGroupTierPrimary
+	}
+	if prev == tier {
+		return
+	}
+	log := logger.L().With(zap.String("component", "service.failover_tier"))
+	fields := []zap.Field{
+		zap.Int64("group_id", groupID),
+		zap.String("from_tier", AccountGroupTierName(prev)),
+		zap.String("to_tier", AccountGroupTierName(tier)),
+	}
+	switch {
+	case tier >= AccountGroupTierEmergency:
+		log.Error("failover_tier.emergency_engaged", fields...)
+	case tier > prev:
+		log.Warn("failover_tier.degraded", fields...)
+	default:
+		log.Info("failover_tier.recovered", fields...)
+	}
+}
+
+// stickyDemoted 判断粘性账号是否处于比分组当前启用层级更低的层级（高层级已恢复，应放弃粘性重新调度）。
+func (t *groupFailoverTierTracker) stickyDemoted(groupID *int64, account *Account) bool {
+	if groupID == nil || *groupID <= 0 || account == nil {
+		return false
+	}
+	tier := account.GroupTier(*groupID)
+	if tier == AccountGroupTierPrimary {
+		return false
+	}
+	t.mu.Lock()
+	active, ok := t.active[*groupID]
+	t.mu.Unlock()
+	return ok && tier > active
+}
+
+// observeSelected 记录逐个比较选择路径（非负载感知）最终选中账号所在的层级。
+func (t *groupFailoverTierTracker) observeSelected(groupID *int64, selected *Account) {
+	if groupID == nil || *groupID <= 0 || selected == nil {
+		return
+	}
+	t.observe(*groupID, selected.GroupTier(*groupID))
+}
+
+// compareGroupTier 比较两个账号在分组内的层级：负数表示 a 层级更高（数值更小），0 表示同层级。
+func compareGroupTier(a, b *Account, groupID *int64) int {
+	if groupID == nil || *groupID <= 0 {
+		return 0
+	}
+	return a.GroupTier(*groupID) - b.GroupTier(*groupID)
+}
@@ -0,0 +1,104 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tieredAccount(id, groupID int64, tier int) Account {
+	return Account{
+		ID:            id,
+		Platform:      PlatformAnthropic,
+		Priority:      1,
+		Status:        StatusActive,
+		Schedulable:   true,
+		AccountGroups: []AccountGroup{{AccountID: id, GroupID: groupID, Tier: tier}},
+	}
+}
+
+func TestGroupFailoverTierTracker_FilterKeepsHighestAvailableTier(t *testing.T) {
+	groupID := int64(5)
+	secondaryA := tieredAccount(1, groupID, AccountGroupTierSecondary)
+	secondaryB := tieredAccount(2, groupID, AccountGroupTierSecondary)
+	emergency := tieredAccount(3, groupID, AccountGroupTierEmergency)
+	var tracker groupFailoverTierTracker
+
+	kept := tracker.filter(&groupID, []*Account{&emergency, &secondaryA, &secondaryB})
+	require.Equal(t, []*Account{&secondaryA, &secondaryB}, kept)
+	require.True(t, tracker.stickyDemoted(&groupID, &emergency))
+	require.False(t, tracker.stickyDemoted(&groupID, &secondaryA))
+
+	kept = tracker.filter(&groupID, []*Account{&emergency})
+	require.Equal(t, []*Account{&emergency}, kept, "emergency tier engages only when nothing above it is left")
+	require.False(t, tracker.stickyDemoted(&groupID, &secondaryA))
+
+	// 未配置层级的账号（无绑定或不同分组）视为 primary
+	unbound := Account{ID: 4}
+	kept = tracker.filter(&groupID, []*Account{&emergency, &unbound})
+	require.Equal(t, []*Account{&unbound}, kept)
+	require.True(t, tracker.stickyDemoted(&groupID, &emergency))
+
+	// 无分组请求不受层级影响
+	require.Len(t, tracker.filter(nil, []*Account{&emergency, &secondaryA}), 2)
+	require.False(t, tracker.stickyDemoted(nil, &emergency))
+}
+
+func TestGatewayService_SelectAccountForModelWithPlatform_FailoverTier(t *testing.T) {
+	ctx := context.Background()
+	groupID := int64(9)
+	now := time.Now()
+
+	primary := tieredAccount(1, groupID, AccountGroupTierPrimary)
+	primary.Priority = 10
+	primary.LastUsedAt = ptr(now)
+	secondary := tieredAccount(2, groupID, AccountGroupTierSecondary)
+	emergency := tieredAccount(3, groupID, AccountGroupTierEmergency)
+	repo := &mockAccountRepoForPlatform{
+		accounts:     []Account{emergency, secondary, primary},
+		accountsByID: map[int64]*Account{},
+	}
+	svc := &GatewayService{accountRepo: repo, cache: &mockGatewayCacheForPlatform{}, cfg: testConfig()}
+
+	acc, err := svc.selectAccountForModelWithPlatform(ctx, &groupID, "", "", nil, PlatformAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), acc.ID, "tier outranks priority and LRU")
+
+	acc, err = svc.selectAccountForModelWithPlatform(ctx, &groupID, "", "", map[int64]struct{}{1: {}}, PlatformAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), acc.ID)
+
+	acc, err = svc.selectAccountForModelWithPlatform(ctx, &groupID, "", "", map[int64]struct{}{1: {}, 2: {}}, PlatformAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), acc.ID)
+	require.False(t, svc.failoverTiers.stickyDemoted(&groupID, &secondary))
+}
+
+func TestComputeRuleMetric_GroupFailoverTier(t *testing.T) {
+	groupID := int64(7)
+	var group *GroupAvailability
+	svc := &OpsAlertEvaluatorService{opsService: &OpsService{
+		getAccountAvailability: func(context.Context, string, *int64) (*OpsAccountAvailability, error) {
+			return &OpsAccountAvailability{Group: group}, nil
+		},
+	}}
+	rule := &OpsAlertRule{MetricType: "group_failover_tier"}
+	now := time.Now()
+
+	_, ok := svc.computeRuleMetric(context.Background(), rule, nil, now, now, "", nil)
+	require.False(t, ok, "metric requires a group scope")
+
+	group = &GroupAvailability{GroupID: groupID}
+	_, ok = svc.computeRuleMetric(context.Background(), rule, nil, now, now, "", &groupID)
+	require.False(t, ok, "no available account is covered by group_available_accounts")
+
+	tier := AccountGroupTierEmergency
+	group.ActiveTier = &tier
+	val, ok := svc.computeRuleMetric(context.Background(), rule, nil, now, now, "", &groupID)
+	require.True(t, ok)
+	require.Equal(t, float64(AccountGroupTierEmergency), val)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// maxGroupModelOverrides 单个分组模型改写映射（过载降级、长上下文路由）的最大规则数。
+const maxGroupModelOverrides = 50
+
+// resolveGroupModelOverride 在分组模型改写映射中查找请求模型的目标模型。
+// 精确匹配优先，其次取最长的末尾 * 通配；无匹配或目标与原模型相同时返回空串。
+func resolveGroupModelOverride(m map[string]string, requestedModel string) string {
+	if len(m) == 0 || requestedModel == "" {
+		return ""
+	}
+	target, ok := m[requestedModel]
+	if !ok {
+		target, ok = matchWildcardMappingResult(m, requestedModel)
+	}
+	target = strings.TrimSpace(target)
+	if !ok || target == "" || target == requestedModel {
+		return ""
+	}
+	return target
+}
+
+// normalizeGroupModelOverrides 清理并校验分组模型改写映射；nil 原样返回（表示不修改）。
+// reason 为校验失败时的错误码，label 用于错误信息中指明映射用途。
+func normalizeGroupModelOverrides(m map[string]string, reason, label string) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	if len(m) > maxGroupModelOverrides {
+		return nil, infraerrors.BadRequest(reason, fmt.Sprintf("too many %s rules", label))
+	}
+	out := make(map[string]string, len(m))
+	for pattern, target := range m {
+		pattern = strings.TrimSpace(pattern)
+		target = strings.TrimSpace(target)
+		if pattern == "" || target == "" {
+			return nil, infraerrors.BadRequest(reason, fmt.Sprintf("%s pattern and target must not be empty", label))
+		}
+		if strings.Contains(target, "*") {
+			return nil, infraerrors.BadRequest(reason, fmt.Sprintf("%s target must be a concrete model", label)).
+				WithMetadata(map[string]string{"pattern": pattern})
+		}
+		if pattern == target {
+			return nil, infraerrors.BadRequest(reason, fmt.Sprintf("%s target must differ from the requested model", label)).
+				WithMetadata(map[string]string{"pattern": pattern})
+		}
+		out[pattern] = target
+	}
+	return out, nil
+}
@@ -0,0 +1,27 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyGroupRequestSurcharge(t *testing.T) {
+	apiKey := &APIKey{Group: &Group{RateMultiplier: 1.2, RequestSurcharge: 0.002}}
+
+	cost := &CostBreakdown{TotalCost: 0.01, ActualCost: 0.012}
+	applyGroupRequestSurcharge(cost, apiKey)
+	require.InDelta(t, 0.01, cost.TotalCost, 1e-12, "raw upstream cost must stay unchanged")
+	require.InDelta(t, 0.014, cost.ActualCost, 1e-12)
+
+	// 定价缺失按 0 记账的请求不收附加费
+	zero := &CostBreakdown{}
+	applyGroupRequestSurcharge(zero, apiKey)
+	require.Zero(t, zero.ActualCost)
+
+	noGroup := &CostBreakdown{TotalCost: 0.01, ActualCost: 0.01}
+	applyGroupRequestSurcharge(noGroup, &APIKey{})
+	require.InDelta(t, 0.01, noGroup.ActualCost, 1e-12)
+}
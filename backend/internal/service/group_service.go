@@ -33,6 +33,14 @@ type GroupRepository interface {
 	GetAccountIDsByGroupIDs(ctx context.Context, groupIDs []int64) ([]int64, error)
 	// BindAccountsToGroup 将多个账号绑定到指定分组
 	BindAccountsToGroup(ctx context.Context, groupID int64, accountIDs []int64) error
+	// ListAccountTiers 列出分组内全部账号的故障转移层级
+	ListAccountTiers(ctx context.Context, groupID int64) ([]GroupAccountTier, error)
+	// SetAccountTiers 批量更新分组内账号的故障转移层级
+	SetAccountTiers(ctx context.Context, groupID int64, tiers []GroupAccountTier) error
+	// ListAccountConcurrencyShares 列出分组内全部账号的并发份额
+	ListAccountConcurrencyShares(ctx context.Context, groupID int64) ([]GroupAccountConcurrencyShare, error)
+	// SetAccountConcurrencyShares 批量更新分组内账号的并发份额
+	SetAccountConcurrencyShares(ctx context.Context, groupID int64, shares []GroupAccountConcurrencyShare) error
 	// UpdateSortOrders 批量更新分组排序
 	UpdateSortOrders(ctx context.Context, updates []GroupSortOrderUpdate) error
 }
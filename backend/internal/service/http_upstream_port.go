@@ -2,6 +2,7 @@ package service
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
 )
@@ -22,3 +23,47 @@ type HTTPUpstream interface {
 	// 支持按账号绑定的数据库 profile 或内置默认 profile。
 	DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*http.Response, error)
 }
+
+// HTTPUpstreamConnStats 上游连接复用统计（进程内、自启动以来）。
+type HTTPUpstreamConnStats struct {
+	Since                time.Time `json:"since"`
+	WarmEnabled          bool      `json:"warm_enabled"`
+	Requests             int64     `json:"requests"`
+	ReusedConns          int64     `json:"reused_conns"`
+	NewConns             int64     `json:"new_conns"`
+	ReuseRate            float64   `json:"reuse_rate"`
+	AvgConnSetupMs       float64   `json:"avg_conn_setup_ms"` // 新建连接的平均建连耗时（含代理与 TLS 握手）
+	WarmRounds           int64     `json:"warm_rounds"`
+	WarmRequests         int64     `json:"warm_requests"`
+	WarmFailures         int64     `json:"warm_failures"`
+	WarmConnsEstablished int64     `json:"warm_conns_established"` // 预热时新建（而非复用）的连接数
+	CachedClients        int       `json:"cached_clients"`
+}
+
+// HTTPUpstreamConnStatsProvider 可选接口：HTTPUpstream 实现提供连接复用统计。
+type HTTPUpstreamConnStatsProvider interface {
+	ConnStats() HTTPUpstreamConnStats
+}
+
+// HTTPUpstreamObserver 观察经由 HTTPUpstream 发出的请求与收到的响应头（如上游请求 ID 关联）。
+// 回调在请求路径上同步执行，实现必须轻量且不得读取响应体。
+type HTTPUpstreamObserver interface {
+	BeforeUpstreamRequest(req *http.Request)
+	AfterUpstreamResponse(req *http.Request, resp *http.Response, accountID int64)
+}
+
+// HTTPUpstreamObserverSetter 可选接口：HTTPUpstream 实现支持注入观察者。
+type HTTPUpstreamObserverSetter interface {
+	SetObserver(observer HTTPUpstreamObserver)
+}
+
+// HTTPUpstreamResponseInspector 检查解压后的上游响应（如格式漂移采样）。
+// 实现可以包装 resp.Body 旁路读取，但不得改变读出的内容。
+type HTTPUpstreamResponseInspector interface {
+	InspectUpstreamResponse(req *http.Request, resp *http.Response)
+}
+
+// HTTPUpstreamResponseInspectorSetter 可选接口：HTTPUpstream 实现支持注入响应检查器。
+type HTTPUpstreamResponseInspectorSetter interface {
+	SetResponseInspector(inspector HTTPUpstreamResponseInspector)
+}
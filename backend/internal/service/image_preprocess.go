@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	stddraw "image/draw"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ImagePreprocessHeader 响应头：转发前被缩放/重新压缩的图片数量。
+const ImagePreprocessHeader = "X-Sub2API-Images-Downscaled"
+
+const (
+	imagePreprocessMinEdge  = 64
+	imagePreprocessMaxEdge  = 8192
+	imagePreprocessMinBytes = 32 * 1024
+	// imagePreprocessMaxPixels 解码前的像素上限，防止解压炸弹占满内存；超出时原样转发。
+	imagePreprocessMaxPixels = 64 * 1024 * 1024
+)
+
+var imagePreprocessQualitySteps = []int{85, 75, 65, 50}
+
+// ImagePreprocessResult 图片预处理统计。
+type ImagePreprocessResult struct {
+	Images      int // 被改写的图片数
+	BytesBefore int // 改写前解码字节数合计
+	BytesAfter  int // 改写后解码字节数合计
+}
+
+// ImagePreprocessEnabled 分组是否开启了图片预处理（任一尺寸目标大于 0）。
+func (g *Group) ImagePreprocessEnabled() bool {
+	return g != nil && (g.ImageMaxEdge > 0 || g.ImageMaxBytes > 0)
+}
+
+// validateImagePreprocessTargets 校验分组图片预处理目标；0 表示不限制。
+func validateImagePreprocessTargets(maxEdge, maxBytes int) error {
+	if maxEdge != 0 && (maxEdge < imagePreprocessMinEdge || maxEdge > imagePreprocessMaxEdge) {
+		return infraerrors.BadRequest("INVALID_IMAGE_PREPROCESS_TARGET", "image max edge must be 0 or between 64 and 8192 pixels")
+	}
+	if maxBytes != 0 && maxBytes < imagePreprocessMinBytes {
+		return infraerrors.BadRequest("INVALID_IMAGE_PREPROCESS_TARGET", "image max bytes must be 0 or at least 32768")
+	}
+	return nil
+}
+
+// PreprocessAnthropicImages 缩放并重新压缩 Anthropic Messages 请求中超出分组目标的 base64 图片
+// （messages[].content[] 及 tool_result 内嵌图片）。
+//
+// 最长边超过 maxEdge 时等比缩放；解码字节数仍超过 maxBytes 时逐级降低 JPEG 质量。
+// 含透明通道的 PNG 只缩放不转 JPEG；GIF（可能为动图）、解码失败或改写后未变小的图片原样保留。
+// 无图片被改写时返回 nil。
+func PreprocessAnthropicImages(body []byte, maxEdge, maxBytes int) ([]byte, *ImagePreprocessResult) {
+	if maxEdge <= 0 && maxBytes <= 0 {
+		return nil, nil
+	}
+	type target struct {
+		path   string
+		source gjson.Result
+	}
+	var targets []target
+	collect := func(prefix string, content gjson.Result) {
+		content.ForEach(func(key, block gjson.Result) bool {
+			if block.Get("type").String() == "image" && block.Get("source.type").String() == "base64" {
+				targets = append(targets, target{path: prefix + "." + key.String() + ".source", source: block.Get("source")})
+			}
+			return true
+		})
+	}
+	gjson.GetBytes(body, "messages").ForEach(func(i, msg gjson.Result) bool {
+		content := msg.Get("content")
+		if !content.IsArray() {
+			return true
+		}
+		prefix := "messages." + i.String() + ".content"
+		collect(prefix, content)
+		content.ForEach(func(j, block gjson.Result) bool {
+			if block.Get("type").String() == "tool_result" && block.Get("content").IsArray() {
+				collect(prefix+"."+j.String()+".content", block.Get("content"))
+			}
+			return true
+		})
+		return true
+	})
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	result := &ImagePreprocessResult{}
+	out := body
+	for _, t := range targets {
+		decoded, err := base64.StdEncoding.DecodeString(t.source.Get("data").String())
+		if err != nil {
+			continue
+		}
+		recoded, mediaType, ok := downscaleImage(decoded, t.source.Get("media_type").String(), maxEdge, maxBytes)
+		if !ok {
+			continue
+		}
+		next, err := sjson.SetBytes(out, t.path+".data", base64.StdEncoding.EncodeToString(recoded))
+		if err != nil {
+			continue
+		}
+		if next, err = sjson.SetBytes(next, t.path+".media_type", mediaType); err != nil {
+			continue
+		}
+		out = next
+		result.Images++
+		result.BytesBefore += len(decoded)
+		result.BytesAfter += len(recoded)
+	}
+	if result.Images == 0 {
+		return nil, nil
+	}
+	return out, result
+}
+
+// HeaderValue 返回 X-Sub2API-Images-Downscaled 响应头的值。
+func (r *ImagePreprocessResult) HeaderValue() string {
+	return strconv.Itoa(r.Images)
+}
+
+// downscaleImage 按目标尺寸缩放/压缩单张图片，返回新数据与媒体类型；无需或无法改写时 ok=false。
+func downscaleImage(decoded []byte, mediaType string, maxEdge, maxBytes int) ([]byte, string, bool) {
+	if strings.EqualFold(mediaType, "image/gif") {
+		return nil, "", false
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(decoded))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > imagePreprocessMaxPixels {
+		return nil, "", false
+	}
+	longest := max(cfg.Width, cfg.Height)
+	needResize := maxEdge > 0 && longest > maxEdge
+	needShrink := maxBytes > 0 && len(decoded) > maxBytes
+	if !needResize && !needShrink {
+		return nil, "", false
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(decoded))
+	if err != nil {
+		return nil, "", false
+	}
+	img := src
+	if needResize {
+		scale := float64(maxEdge) / float64(longest)
+		width := max(1, int(float64(cfg.Width)*scale))
+		height := max(1, int(float64(cfg.Height)*scale))
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), stddraw.Src, nil)
+		img = dst
+	}
+
+	if format == "png" && hasTransparency(img) {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil || buf.Len() >= len(decoded) {
+			return nil, "", false
+		}
+		return buf.Bytes(), "image/png", true
+	}
+
+	// JPEG 无透明通道：先铺白底，避免透明像素变黑
+	opaque := image.NewRGBA(img.Bounds())
+	stddraw.Draw(opaque, opaque.Bounds(), &image.Uniform{C: color.White}, image.Point{}, stddraw.Src)
+	stddraw.Draw(opaque, opaque.Bounds(), img, img.Bounds().Min, stddraw.Over)
+	var best []byte
+	for _, quality := range imagePreprocessQualitySteps {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, opaque, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, "", false
+		}
+		best = buf.Bytes()
+		if maxBytes <= 0 || buf.Len() <= maxBytes {
+			break
+		}
+	}
+	if len(best) >= len(decoded) {
+		return nil, "", false
+	}
+	return best, "image/jpeg", true
+}
+
+func hasTransparency(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// noisyTestImage 生成带噪点的图片，避免纯色图片压缩后过小。
+func noisyTestImage(w, h int, alpha bool) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	r := rand.New(rand.NewSource(1))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a := uint8(255)
+			if alpha && x < w/2 {
+				a = 0
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(r.Intn(256)), G: uint8(x), B: uint8(y), A: a})
+		}
+	}
+	return img
+}
+
+func encodeTestPNG(t *testing.T, img image.Image) string {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func imagePreprocessTestBody(data, mediaType string) []byte {
+	return []byte(`{"model":"claude-sonnet-4-5","messages":[
+		{"role":"user","content":[{"type":"text","text":"describe"},{"type":"image","source":{"type":"base64","media_type":"` + mediaType + `","data":"` + data + `"}}]},
+		{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"shot","input":{}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"image","source":{"type":"base64","media_type":"` + mediaType + `","data":"` + data + `"}}]}]}
+	]}`)
+}
+
+func decodeSourceConfig(t *testing.T, source gjson.Result) image.Config {
+	raw, err := base64.StdEncoding.DecodeString(source.Get("data").String())
+	require.NoError(t, err)
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestPreprocessAnthropicImages_DownscalesToMaxEdgeAsJPEG(t *testing.T) {
+	body := imagePreprocessTestBody(encodeTestPNG(t, noisyTestImage(1200, 600, false)), "image/png")
+
+	out, result := PreprocessAnthropicImages(body, 400, 0)
+	require.NotNil(t, result)
+	require.Equal(t, 2, result.Images, "nested tool_result images are processed too")
+	require.Less(t, result.BytesAfter, result.BytesBefore)
+	require.Equal(t, "2", result.HeaderValue())
+
+	for _, path := range []string{"messages.0.content.1.source", "messages.2.content.0.content.0.source"} {
+		source := gjson.GetBytes(out, path)
+		require.Equal(t, "image/jpeg", source.Get("media_type").String())
+		cfg := decodeSourceConfig(t, source)
+		require.Equal(t, 400, cfg.Width)
+		require.Equal(t, 200, cfg.Height)
+	}
+	require.Equal(t, "describe", gjson.GetBytes(out, "messages.0.content.0.text").String())
+}
+
+func TestPreprocessAnthropicImages_KeepsTransparentPNG(t *testing.T) {
+	body := imagePreprocessTestBody(encodeTestPNG(t, noisyTestImage(800, 800, true)), "image/png")
+
+	out, result := PreprocessAnthropicImages(body, 200, 0)
+	require.NotNil(t, result)
+	source := gjson.GetBytes(out, "messages.0.content.1.source")
+	require.Equal(t, "image/png", source.Get("media_type").String())
+	require.Equal(t, 200, decodeSourceConfig(t, source).Width)
+}
+
+func TestPreprocessAnthropicImages_RecompressesToMaxBytes(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, noisyTestImage(600, 600, false), &jpeg.Options{Quality: 100}))
+	body := imagePreprocessTestBody(base64.StdEncoding.EncodeToString(buf.Bytes()), "image/jpeg")
+
+	out, result := PreprocessAnthropicImages(body, 0, buf.Len()/2)
+	require.NotNil(t, result)
+	require.LessOrEqual(t, result.BytesAfter, buf.Len())
+	require.Equal(t, 600, decodeSourceConfig(t, gjson.GetBytes(out, "messages.0.content.1.source")).Width, "dimensions unchanged")
+}
+
+func TestPreprocessAnthropicImages_SkipsSmallGIFAndInvalid(t *testing.T) {
+	small := imagePreprocessTestBody(encodeTestPNG(t, noisyTestImage(100, 100, false)), "image/png")
+	out, result := PreprocessAnthropicImages(small, 400, 1<<20)
+	require.Nil(t, out)
+	require.Nil(t, result)
+
+	gif := imagePreprocessTestBody(encodeTestPNG(t, noisyTestImage(1000, 1000, false)), "image/gif")
+	_, result = PreprocessAnthropicImages(gif, 400, 0)
+	require.Nil(t, result)
+
+	_, result = PreprocessAnthropicImages(imagePreprocessTestBody("not-base64!", "image/png"), 400, 0)
+	require.Nil(t, result)
+
+	_, result = PreprocessAnthropicImages(small, 0, 0)
+	require.Nil(t, result)
+}
+
+func TestValidateImagePreprocessTargets(t *testing.T) {
+	require.NoError(t, validateImagePreprocessTargets(0, 0))
+	require.NoError(t, validateImagePreprocessTargets(1568, 5*1024*1024))
+	require.Error(t, validateImagePreprocessTargets(10, 0))
+	require.Error(t, validateImagePreprocessTargets(9000, 0))
+	require.Error(t, validateImagePreprocessTargets(0, 1024))
+}
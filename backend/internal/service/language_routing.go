@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// LanguageRoutedHeader 语言路由改写模型时返回给客户端的响应头，值为原始请求模型。
+const LanguageRoutedHeader = "X-Sub2API-Language-Routed-From"
+
+const (
+	maxLanguageRoutingRules = 20
+	// languageDetectMaxRunes 语言检测最多分析的字符数，避免长提示词拖慢热路径。
+	languageDetectMaxRunes = 4000
+)
+
+// languageRoutingLanguages 语言路由支持的语言代码：非拉丁文字按书写系统区分，拉丁文字按常用虚词区分。
+var languageRoutingLanguages = []string{"zh", "ja", "ko", "ru", "ar", "he", "th", "hi", "el", "en", "es", "fr", "de", "pt", "it"}
+
+// latinStopwords 拉丁文字语言的高频虚词，按顺序决定平票时的优先级（英文优先）。
+var latinStopwords = []struct {
+	lang  string
+	words []string
+}{
+	{"en", []string{"the", "and", "is", "are", "to", "of", "that", "it", "you", "what", "how", "with", "for", "this", "please"}},
+	{"es", []string{"el", "los", "las", "que", "es", "y", "por", "para", "una", "con", "cómo", "qué", "del", "está", "puedes"}},
+	{"fr", []string{"le", "les", "des", "est", "et", "une", "pour", "dans", "avec", "pas", "vous", "je", "du", "ce", "qui"}},
+	{"de", []string{"der", "die", "das", "und", "ist", "nicht", "ich", "ein", "eine", "mit", "zu", "wie", "was", "sie", "auf"}},
+	{"pt", []string{"os", "que", "é", "um", "uma", "não", "para", "com", "do", "da", "em", "como", "você", "isso", "são"}},
+	{"it", []string{"il", "lo", "gli", "che", "di", "è", "un", "una", "non", "per", "con", "come", "sono", "questo", "della"}},
+}
+
+var fencedCodeBlockRe = regexp.MustCompile("(?s)```.*?```")
+
+// normalizeGroupLanguageRoutingConfig 清理并校验语言路由配置：语言代码小写去重，
+// 每条规则至少需要改写模型或指定账号/代理之一。
+func normalizeGroupLanguageRoutingConfig(cfg GroupLanguageRoutingConfig) (GroupLanguageRoutingConfig, error) {
+	var out GroupLanguageRoutingConfig
+	for i, rule := range cfg.Rules {
+		var languages []string
+		for _, lang := range rule.Languages {
+			lang = strings.ToLower(strings.TrimSpace(lang))
+			if lang == "" || slices.Contains(languages, lang) {
+				continue
+			}
+			if !slices.Contains(languageRoutingLanguages, lang) {
+				return GroupLanguageRoutingConfig{}, languageRoutingRuleError(i, "unsupported language "+strconv.Quote(lang)+", supported: "+strings.Join(languageRoutingLanguages, ", "))
+			}
+			languages = append(languages, lang)
+		}
+		if len(languages) == 0 {
+			return GroupLanguageRoutingConfig{}, languageRoutingRuleError(i, "languages is required")
+		}
+		normalized := LanguageRoutingRule{
+			Languages:  languages,
+			Model:      strings.TrimSpace(rule.Model),
+			AccountIDs: normalizePositiveIDs(rule.AccountIDs),
+			ProxyIDs:   normalizePositiveIDs(rule.ProxyIDs),
+		}
+		if normalized.Model == "" && len(normalized.AccountIDs) == 0 && len(normalized.ProxyIDs) == 0 {
+			return GroupLanguageRoutingConfig{}, languageRoutingRuleError(i, "model, account_ids or proxy_ids is required")
+		}
+		out.Rules = append(out.Rules, normalized)
+	}
+	if len(out.Rules) > maxLanguageRoutingRules {
+		return GroupLanguageRoutingConfig{}, infraerrors.BadRequest("INVALID_LANGUAGE_ROUTING",
+			"language routing supports at most "+strconv.Itoa(maxLanguageRoutingRules)+" rules")
+	}
+	return out, nil
+}
+
+func languageRoutingRuleError(i int, reason string) error {
+	return infraerrors.BadRequest("INVALID_LANGUAGE_ROUTING", "language routing rule "+strconv.Itoa(i)+": "+reason).
+		WithMetadata(map[string]string{"rule": strconv.Itoa(i)})
+}
+
+func normalizePositiveIDs(ids []int64) []int64 {
+	var out []int64
+	for _, id := range ids {
+		if id > 0 && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// LanguageRouteFor 返回首条包含该语言的路由规则；未配置或未命中时返回 nil。
+func (g *Group) LanguageRouteFor(lang string) *LanguageRoutingRule {
+	if g == nil || lang == "" {
+		return nil
+	}
+	for i := range g.LanguageRouting.Rules {
+		if slices.Contains(g.LanguageRouting.Rules[i].Languages, lang) {
+			return &g.LanguageRouting.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ResolveLanguageRoute 检测请求体中最后一条用户消息的主要语言，并返回分组命中的路由规则。
+// 分组未配置语言路由时不做检测，直接返回空。
+func ResolveLanguageRoute(group *Group, body []byte) (string, *LanguageRoutingRule) {
+	if group == nil || len(group.LanguageRouting.Rules) == 0 {
+		return "", nil
+	}
+	lang := DetectPromptLanguage(body)
+	return lang, group.LanguageRouteFor(lang)
+}
+
+// WithPromptLanguage 在 context 中记录命中语言路由的提示词语言，供调度层读取。
+func WithPromptLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, ctxkey.PromptLanguage, lang)
+}
+
+// PromptLanguageFromContext 读取命中语言路由的提示词语言；未命中时返回空。
+func PromptLanguageFromContext(ctx context.Context) string {
+	lang, _ := ctx.Value(ctxkey.PromptLanguage).(string)
+	return lang
+}
+
+// languageRoutingAccountIDs 返回语言规则指定的优先账号：显式列出的账号在前，
+// 其后是使用规则代理的账号（保持候选列表顺序）。
+func languageRoutingAccountIDs(rule *LanguageRoutingRule, accounts []Account) []int64 {
+	if rule == nil {
+		return nil
+	}
+	ids := slices.Clone(rule.AccountIDs)
+	if len(rule.ProxyIDs) > 0 {
+		for i := range accounts {
+			if accounts[i].ProxyID != nil && slices.Contains(rule.ProxyIDs, *accounts[i].ProxyID) && !slices.Contains(ids, accounts[i].ID) {
+				ids = append(ids, accounts[i].ID)
+			}
+		}
+	}
+	return ids
+}
+
+// DetectPromptLanguage 检测请求体（Anthropic Messages / Chat Completions / Responses）中
+// 最后一条带文本的用户消息的主要语言；无法判断时返回空。
+func DetectPromptLanguage(body []byte) string {
+	return DetectLanguage(lastUserPromptText(body))
+}
+
+func lastUserPromptText(body []byte) string {
+	if input := gjson.GetBytes(body, "input"); input.Type == gjson.String {
+		return input.String()
+	} else if input.IsArray() {
+		if text := lastUserTextInMessages(input.Array()); text != "" {
+			return text
+		}
+	}
+	return lastUserTextInMessages(gjson.GetBytes(body, "messages").Array())
+}
+
+func lastUserTextInMessages(messages []gjson.Result) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Get("role").String() != "user" {
+			continue
+		}
+		content := messages[i].Get("content")
+		if content.Type == gjson.String {
+			if text := content.String(); strings.TrimSpace(text) != "" {
+				return text
+			}
+			continue
+		}
+		// 智能体循环中的用户消息常只包含 tool_result，跳过并继续向前找真正的用户输入
+		var sb strings.Builder
+		for _, part := range content.Array() {
+			switch part.Get("type").String() {
+			case "text", "input_text":
+				sb.WriteString(part.Get("text").String())
+				sb.WriteByte('\n')
+			}
+		}
+		if text := sb.String(); strings.TrimSpace(text) != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// DetectLanguage 按书写系统统计文本的主要语言：中日韩字符按 3 倍权重计（单字信息量接近一个拉丁词），
+// 日文以出现假名区分于中文，拉丁文字再按常用虚词区分英/西/法/德/葡/意。忽略 Markdown 代码块。
+func DetectLanguage(text string) string {
+	text = fencedCodeBlockRe.ReplaceAllString(text, " ")
+	counts := make(map[string]int)
+	var han, kana, n int
+	for _, r := range text {
+		if n >= languageDetectMaxRunes {
+			break
+		}
+		n++
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"] += 3
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["latin"]++
+		}
+	}
+	// 日文混用汉字与假名；中文里偶见的片假名不足以改判
+	if kana > 0 && kana*10 >= han {
+		counts["ja"] += (han + kana) * 3
+	} else {
+		counts["zh"] += han * 3
+	}
+
+	best, bestCount := "", 0
+	for _, lang := range append([]string{"latin"}, languageRoutingLanguages...) {
+		if counts[lang] > bestCount {
+			best, bestCount = lang, counts[lang]
+		}
+	}
+	if best == "latin" {
+		return detectLatinLanguage(text)
+	}
+	return best
+}
+
+func detectLatinLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) > languageDetectMaxRunes/4 {
+		words = words[:languageDetectMaxRunes/4]
+	}
+	best, bestCount := "en", 0
+	for _, sw := range latinStopwords {
+		count := 0
+		for _, w := range words {
+			if slices.Contains(sw.words, w) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = sw.lang, count
+		}
+	}
+	return best
+}
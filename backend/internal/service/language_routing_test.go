@@ -0,0 +1,84 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]string{
+		"请帮我把这段代码重构一下，并解释原因":                                      "zh",
+		"このコードをリファクタリングしてください":                                    "ja",
+		"이 코드를 리팩터링해 주세요":                                         "ko",
+		"Пожалуйста, объясни этот код":                            "ru",
+		"Can you explain what this function does?":                "en",
+		"¿Puedes explicar qué hace esta función para mí?":         "es",
+		"Peux-tu expliquer ce que fait cette fonction pour moi ?": "fr",
+		"Kannst du mir erklären, was die Funktion macht und wie?": "de",
+		"":   "",
+		"42": "",
+	}
+	for text, want := range cases {
+		require.Equal(t, want, DetectLanguage(text), text)
+	}
+
+	// 中文提示词夹带英文代码块与标识符时仍判为中文
+	require.Equal(t, "zh", DetectLanguage("解释一下 handleRequest 函数做了什么：\n```go\nfunc handleRequest(w http.ResponseWriter, r *http.Request) {\n\treturn\n}\n```"))
+}
+
+func TestDetectPromptLanguage_LastUserText(t *testing.T) {
+	anthropic := []byte(`{"model":"m","messages":[
+		{"role":"user","content":"你好，请帮我看看这个错误"},
+		{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"read","input":{}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"error: file not found at path"}]}
+	]}`)
+	require.Equal(t, "zh", DetectPromptLanguage(anthropic), "tool_result-only turns are skipped")
+
+	responses := []byte(`{"model":"m","input":[{"role":"user","content":[{"type":"input_text","text":"日本語で答えてください"}]}]}`)
+	require.Equal(t, "ja", DetectPromptLanguage(responses))
+
+	require.Equal(t, "en", DetectPromptLanguage([]byte(`{"input":"what is the weather like today"}`)))
+}
+
+func TestNormalizeGroupLanguageRoutingConfig(t *testing.T) {
+	out, err := normalizeGroupLanguageRoutingConfig(GroupLanguageRoutingConfig{Rules: []LanguageRoutingRule{
+		{Languages: []string{" ZH ", "zh", ""}, Model: " glm-4 ", AccountIDs: []int64{3, 3, 0}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []LanguageRoutingRule{{Languages: []string{"zh"}, Model: "glm-4", AccountIDs: []int64{3}}}, out.Rules)
+
+	_, err = normalizeGroupLanguageRoutingConfig(GroupLanguageRoutingConfig{Rules: []LanguageRoutingRule{{Languages: []string{"klingon"}, Model: "m"}}})
+	require.Equal(t, "INVALID_LANGUAGE_ROUTING", infraerrors.Reason(err))
+
+	_, err = normalizeGroupLanguageRoutingConfig(GroupLanguageRoutingConfig{Rules: []LanguageRoutingRule{{Languages: []string{"zh"}}}})
+	require.Equal(t, "INVALID_LANGUAGE_ROUTING", infraerrors.Reason(err))
+}
+
+func TestLanguageRouting_RuleAndAccounts(t *testing.T) {
+	proxyA, proxyB := int64(10), int64(20)
+	group := &Group{LanguageRouting: GroupLanguageRoutingConfig{Rules: []LanguageRoutingRule{
+		{Languages: []string{"en"}, Model: "claude-sonnet-4-5"},
+		{Languages: []string{"zh", "ja"}, AccountIDs: []int64{5}, ProxyIDs: []int64{proxyA}},
+	}}}
+	accounts := []Account{{ID: 1, ProxyID: &proxyA}, {ID: 2, ProxyID: &proxyB}, {ID: 3}, {ID: 5, ProxyID: &proxyA}}
+
+	lang, rule := ResolveLanguageRoute(group, []byte(`{"messages":[{"role":"user","content":"用中文回答"}]}`))
+	require.Equal(t, "zh", lang)
+	require.NotNil(t, rule)
+	require.Equal(t, []int64{5, 1}, languageRoutingAccountIDs(rule, accounts))
+
+	require.Nil(t, group.LanguageRouteFor("ko"))
+	require.Empty(t, languageRoutingAccountIDs(group.LanguageRouteFor("en"), accounts), "model-only rules do not restrict accounts")
+
+	_, rule = ResolveLanguageRoute(&Group{}, []byte(`{"messages":[{"role":"user","content":"用中文回答"}]}`))
+	require.Nil(t, rule)
+
+	ctx := WithPromptLanguage(context.Background(), "zh")
+	require.Equal(t, "zh", PromptLanguageFromContext(ctx))
+	require.Empty(t, PromptLanguageFromContext(context.Background()))
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// 单个账号×模型组合保留的最大样本数（超出后丢弃最旧样本）
+	latencySLOMaxSamplesPerPair = 1024
+	// 跟踪的账号×模型组合上限，超出后新组合不再统计
+	latencySLOMaxTrackedPairs = 8192
+
+	latencySLOAlertSeverity = "P1"
+	latencySLOAlertTimeout  = 5 * time.Second
+)
+
+// LatencySLOConfig 账号×模型延迟 SLO 参数。
+type LatencySLOConfig struct {
+	Percentile          float64
+	Threshold           time.Duration
+	RecoverRatio        float64
+	Window              time.Duration
+	MinSamples          int
+	StreamUseFirstToken bool
+}
+
+// LatencySLOAlertSink 接收 SLO 违反/恢复告警（由 OpsService 实现）。
+type LatencySLOAlertSink interface {
+	CreateAlertEvent(ctx context.Context, event *OpsAlertEvent) (*OpsAlertEvent, error)
+	UpdateAlertEventStatus(ctx context.Context, eventID int64, status string, resolvedAt *time.Time) error
+}
+
+type latencySLOKey struct {
+	accountID int64
+	model     string
+}
+
+type latencySLOSample struct {
+	at      time.Time
+	latency time.Duration
+}
+
+type latencySLOPair struct {
+	samples  []latencySLOSample // 按时间升序
+	degraded bool
+	since    time.Time
+	value    time.Duration // 最近一次计算的分位数
+	alertID  int64
+}
+
+// LatencySLOTracker 按账号×模型统计窗口内的响应耗时分位数，
+// 持续超过 SLO 阈值的组合在调度时降权并发出告警，回落后自动恢复。
+//
+// 降权组合拿到的流量很少，样本会随窗口过期；窗口内样本不足时视为恢复，
+// 让该组合重新接收流量以获得新的判定（每个窗口最多试探一轮）。
+// 状态仅保存在本实例内存中，多实例部署时各实例独立判定。
+type LatencySLOTracker struct {
+	cfg  LatencySLOConfig
+	now  func() time.Time
+	sink LatencySLOAlertSink
+
+	mu    sync.Mutex
+	pairs map[latencySLOKey]*latencySLOPair
+
+	// alertMu 串行化告警写入，保证同一组合的创建/恢复按状态收敛
+	alertMu sync.Mutex
+}
+
+// NewLatencySLOTracker 创建延迟 SLO 跟踪器，sink 为空时只降权不告警。
+func NewLatencySLOTracker(cfg LatencySLOConfig, sink LatencySLOAlertSink) *LatencySLOTracker {
+	return &LatencySLOTracker{
+		cfg:   cfg,
+		now:   time.Now,
+		sink:  sink,
+		pairs: make(map[latencySLOKey]*latencySLOPair),
+	}
+}
+
+// newLatencySLOTrackerFromConfig 未启用时返回 nil。
+func newLatencySLOTrackerFromConfig(cfg *config.Config, sink LatencySLOAlertSink) *LatencySLOTracker {
+	if cfg == nil || !cfg.Gateway.Scheduling.LatencySLO.Enabled {
+		return nil
+	}
+	slo := cfg.Gateway.Scheduling.LatencySLO
+	return NewLatencySLOTracker(LatencySLOConfig{
+		Percentile:          slo.Percentile,
+		Threshold:           time.Duration(slo.ThresholdMs) * time.Millisecond,
+		RecoverRatio:        slo.RecoverRatio,
+		Window:              time.Duration(slo.WindowMinutes) * time.Minute,
+		MinSamples:          slo.MinSamples,
+		StreamUseFirstToken: slo.StreamUseFirstToken,
+	}, sink)
+}
+
+// ObserveResult 按配置从一次转发结果中取耗时并记录。
+func (t *LatencySLOTracker) ObserveResult(accountID int64, model string, stream bool, duration time.Duration, firstTokenMs *int) {
+	if t == nil {
+		return
+	}
+	if stream && t.cfg.StreamUseFirstToken {
+		if firstTokenMs == nil {
+			return
+		}
+		duration = time.Duration(*firstTokenMs) * time.Millisecond
+	}
+	t.Observe(accountID, model, duration)
+}
+
+// Observe 记录账号×模型的一次响应耗时。
+func (t *LatencySLOTracker) Observe(accountID int64, model string, latency time.Duration) {
+	if t == nil || accountID <= 0 || model == "" || latency <= 0 {
+		return
+	}
+	key := latencySLOKey{accountID: accountID, model: model}
+	now := t.now()
+
+	t.mu.Lock()
+	pair := t.pairs[key]
+	if pair == nil {
+		if len(t.pairs) >= latencySLOMaxTrackedPairs {
+			t.pruneIdleLocked(now)
+			if len(t.pairs) >= latencySLOMaxTrackedPairs {
+				t.mu.Unlock()
+				return
+			}
+		}
+		pair = &latencySLOPair{}
+		t.pairs[key] = pair
+	}
+	pair.samples = append(pair.samples, latencySLOSample{at: now, latency: latency})
+	if len(pair.samples) > latencySLOMaxSamplesPerPair {
+		pair.samples = append(pair.samples[:0], pair.samples[len(pair.samples)-latencySLOMaxSamplesPerPair:]...)
+	}
+	t.expireLocked(pair, now)
+	changed := t.evaluateLocked(pair, now)
+	t.mu.Unlock()
+
+	if changed {
+		go t.reconcileAlert(key)
+	}
+}
+
+// IsDegraded 判断账号×模型当前是否因违反 SLO 被降权。
+func (t *LatencySLOTracker) IsDegraded(accountID int64, model string) bool {
+	if t == nil || model == "" {
+		return false
+	}
+	key := latencySLOKey{accountID: accountID, model: model}
+	now := t.now()
+
+	t.mu.Lock()
+	pair := t.pairs[key]
+	if pair == nil || !pair.degraded {
+		t.mu.Unlock()
+		return false
+	}
+	changed := false
+	if t.expireLocked(pair, now) {
+		changed = t.evaluateLocked(pair, now)
+	}
+	degraded := pair.degraded
+	t.mu.Unlock()
+
+	if changed {
+		go t.reconcileAlert(key)
+	}
+	return degraded
+}
+
+// expireLocked 丢弃窗口外的样本，返回是否有样本被丢弃。
+func (t *LatencySLOTracker) expireLocked(pair *latencySLOPair, now time.Time) bool {
+	cutoff := now.Add(-t.cfg.Window)
+	n := 0
+	for n < len(pair.samples) && pair.samples[n].at.Before(cutoff) {
+		n++
+	}
+	if n == 0 {
+		return false
+	}
+	pair.samples = append(pair.samples[:0], pair.samples[n:]...)
+	return true
+}
+
+// evaluateLocked 重新计算分位数并更新降权状态，返回状态是否变化。
+func (t *LatencySLOTracker) evaluateLocked(pair *latencySLOPair, now time.Time) bool {
+	if len(pair.samples) < t.cfg.MinSamples {
+		if pair.degraded {
+			pair.degraded = false
+			pair.since = now
+			return true
+		}
+		return false
+	}
+	values := make([]time.Duration, len(pair.samples))
+	for i, s := range pair.samples {
+		values[i] = s.latency
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	pair.value = values[int(float64(len(values)-1)*t.cfg.Percentile)]
+
+	switch {
+	case !pair.degraded && pair.value > t.cfg.Threshold:
+		pair.degraded = true
+		pair.since = now
+		return true
+	case pair.degraded && pair.value <= time.Duration(float64(t.cfg.Threshold)*t.cfg.RecoverRatio):
+		pair.degraded = false
+		pair.since = now
+		return true
+	}
+	return false
+}
+
+// pruneIdleLocked 清理没有样本、未降权且没有未恢复告警的组合。
+func (t *LatencySLOTracker) pruneIdleLocked(now time.Time) {
+	for key, pair := range t.pairs {
+		t.expireLocked(pair, now)
+		if len(pair.samples) == 0 && !pair.degraded && pair.alertID == 0 {
+			delete(t.pairs, key)
+		}
+	}
+}
+
+// reconcileAlert 让告警状态与组合当前状态收敛：降权且无告警时创建，恢复且有告警时关闭。
+// 按当前状态而非触发时的转换处理，因此并发触发或乱序执行都不会遗留告警。
+func (t *LatencySLOTracker) reconcileAlert(key latencySLOKey) {
+	if t.sink == nil {
+		return
+	}
+	t.alertMu.Lock()
+	defer t.alertMu.Unlock()
+
+	t.mu.Lock()
+	pair := t.pairs[key]
+	if pair == nil {
+		t.mu.Unlock()
+		return
+	}
+	degraded, alertID, value, since := pair.degraded, pair.alertID, pair.value, pair.since
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), latencySLOAlertTimeout)
+	defer cancel()
+
+	switch {
+	case degraded && alertID == 0:
+		metric := float64(value.Milliseconds())
+		threshold := float64(t.cfg.Threshold.Milliseconds())
+		created, err := t.sink.CreateAlertEvent(ctx, &OpsAlertEvent{
+			Severity: latencySLOAlertSeverity,
+			Status:   OpsAlertStatusFiring,
+			Title:    fmt.Sprintf("%s: latency SLO breached (account %d, %s)", latencySLOAlertSeverity, key.accountID, key.model),
+			Description: fmt.Sprintf("p%g latency %dms > %dms over the last %s; account deprioritized for this model until latency recovers",
+				t.cfg.Percentile*100, value.Milliseconds(), t.cfg.Threshold.Milliseconds(), t.cfg.Window),
+			MetricValue:    &metric,
+			ThresholdValue: &threshold,
+			Dimensions: map[string]any{
+				"kind":       "latency_slo",
+				"account_id": key.accountID,
+				"model":      key.model,
+			},
+			FiredAt:   since,
+			CreatedAt: since,
+		})
+		if err != nil {
+			logger.LegacyPrintf("service.latency_slo", "[LatencySLO] create alert failed (account=%d model=%s): %v", key.accountID, key.model, err)
+			return
+		}
+		if created != nil && created.ID > 0 {
+			t.mu.Lock()
+			if current := t.pairs[key]; current != nil {
+				current.alertID = created.ID
+			}
+			t.mu.Unlock()
+		}
+	case !degraded && alertID > 0:
+		resolvedAt := since
+		if err := t.sink.UpdateAlertEventStatus(ctx, alertID, OpsAlertStatusResolved, &resolvedAt); err != nil {
+			logger.LegacyPrintf("service.latency_slo", "[LatencySLO] resolve alert failed (event=%d): %v", alertID, err)
+			return
+		}
+		t.mu.Lock()
+		if current := t.pairs[key]; current != nil && current.alertID == alertID {
+			current.alertID = 0
+		}
+		t.mu.Unlock()
+	}
+}
+
+// preferLatencyHealthy 过滤掉违反 SLO 的账号；全部违反时原样返回（降权而非禁用）。
+func (t *LatencySLOTracker) preferLatencyHealthy(accounts []accountWithLoad, model string) []accountWithLoad {
+	if t == nil || model == "" || len(accounts) == 0 {
+		return accounts
+	}
+	healthy := make([]accountWithLoad, 0, len(accounts))
+	for _, acc := range accounts {
+		if !t.IsDegraded(acc.account.ID, model) {
+			healthy = append(healthy, acc)
+		}
+	}
+	if len(healthy) == 0 {
+		return accounts
+	}
+	return healthy
+}
+
+// demoteLatencyDegraded 将违反 SLO 的账号稳定地移到列表末尾。
+func (t *LatencySLOTracker) demoteLatencyDegraded(accounts []*Account, model string) {
+	if t == nil || model == "" || len(accounts) < 2 {
+		return
+	}
+	degraded := make(map[int64]bool, len(accounts))
+	for _, acc := range accounts {
+		if t.IsDegraded(acc.ID, model) {
+			degraded[acc.ID] = true
+		}
+	}
+	if len(degraded) == 0 {
+		return
+	}
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return !degraded[accounts[i].ID] && degraded[accounts[j].ID]
+	})
+}
@@ -0,0 +1,146 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type latencySLOSinkStub struct {
+	mu       sync.Mutex
+	created  []*OpsAlertEvent
+	resolved []int64
+}
+
+func (s *latencySLOSinkStub) CreateAlertEvent(_ context.Context, event *OpsAlertEvent) (*OpsAlertEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created = append(s.created, event)
+	out := *event
+	out.ID = int64(len(s.created))
+	return &out, nil
+}
+
+func (s *latencySLOSinkStub) UpdateAlertEventStatus(_ context.Context, eventID int64, status string, _ *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status == OpsAlertStatusResolved {
+		s.resolved = append(s.resolved, eventID)
+	}
+	return nil
+}
+
+func (s *latencySLOSinkStub) counts() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.created), len(s.resolved)
+}
+
+func newTestLatencySLOTracker(sink LatencySLOAlertSink, now *time.Time) *LatencySLOTracker {
+	tracker := NewLatencySLOTracker(LatencySLOConfig{
+		Percentile:   0.95,
+		Threshold:    60 * time.Second,
+		RecoverRatio: 0.8,
+		Window:       15 * time.Minute,
+		MinSamples:   5,
+	}, sink)
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func TestLatencySLOTracker_DegradesAlertsAndRecovers(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	sink := &latencySLOSinkStub{}
+	tracker := newTestLatencySLOTracker(sink, &now)
+
+	for i := 0; i < 4; i++ {
+		tracker.Observe(1, "claude-sonnet-4-5", 90*time.Second)
+	}
+	require.False(t, tracker.IsDegraded(1, "claude-sonnet-4-5"), "not judged below min_samples")
+
+	tracker.Observe(1, "claude-sonnet-4-5", 90*time.Second)
+	require.True(t, tracker.IsDegraded(1, "claude-sonnet-4-5"))
+	require.False(t, tracker.IsDegraded(1, "claude-haiku-4-5"), "other models on the same account are unaffected")
+	require.False(t, tracker.IsDegraded(2, "claude-sonnet-4-5"))
+	require.Eventually(t, func() bool { created, _ := sink.counts(); return created == 1 }, time.Second, 5*time.Millisecond)
+	sink.mu.Lock()
+	event := sink.created[0]
+	sink.mu.Unlock()
+	require.Equal(t, "latency_slo", event.Dimensions["kind"])
+	require.Equal(t, int64(1), event.Dimensions["account_id"])
+	require.Equal(t, float64(90000), *event.MetricValue)
+
+	// 低于阈值但高于恢复阈值（48s）时保持降权
+	for i := 0; i < 200; i++ {
+		tracker.Observe(1, "claude-sonnet-4-5", 55*time.Second)
+	}
+	require.True(t, tracker.IsDegraded(1, "claude-sonnet-4-5"), "hysteresis keeps the pair degraded")
+
+	for i := 0; i < 1000; i++ {
+		tracker.Observe(1, "claude-sonnet-4-5", 5*time.Second)
+	}
+	require.False(t, tracker.IsDegraded(1, "claude-sonnet-4-5"))
+	require.Eventually(t, func() bool { _, resolved := sink.counts(); return resolved == 1 }, time.Second, 5*time.Millisecond)
+	created, _ := sink.counts()
+	require.Equal(t, 1, created)
+}
+
+func TestLatencySLOTracker_RecoversWhenSamplesExpire(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestLatencySLOTracker(nil, &now)
+	for i := 0; i < 5; i++ {
+		tracker.Observe(3, "gpt-5", 2*time.Minute)
+	}
+	require.True(t, tracker.IsDegraded(3, "gpt-5"))
+
+	now = now.Add(15*time.Minute + time.Second)
+	require.False(t, tracker.IsDegraded(3, "gpt-5"), "a starved pair is retried once its window empties")
+}
+
+func TestLatencySLOTracker_ObserveResultUsesFirstTokenForStreams(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestLatencySLOTracker(nil, &now)
+	tracker.cfg.StreamUseFirstToken = true
+	firstToken := 800
+	for i := 0; i < 5; i++ {
+		tracker.ObserveResult(4, "claude-opus-4-5", true, 5*time.Minute, &firstToken)
+		tracker.ObserveResult(4, "claude-opus-4-5", true, 5*time.Minute, nil)
+	}
+	require.False(t, tracker.IsDegraded(4, "claude-opus-4-5"))
+
+	for i := 0; i < 100; i++ {
+		tracker.ObserveResult(4, "claude-opus-4-5", false, 2*time.Minute, nil)
+	}
+	require.True(t, tracker.IsDegraded(4, "claude-opus-4-5"))
+}
+
+func TestLatencySLOTracker_RoutingPreference(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestLatencySLOTracker(nil, &now)
+	for i := 0; i < 5; i++ {
+		tracker.Observe(1, "m", 2*time.Minute)
+	}
+
+	available := []accountWithLoad{
+		{account: &Account{ID: 1}, loadInfo: &AccountLoadInfo{AccountID: 1}},
+		{account: &Account{ID: 2}, loadInfo: &AccountLoadInfo{AccountID: 2}},
+	}
+	healthy := tracker.preferLatencyHealthy(available, "m")
+	require.Len(t, healthy, 1)
+	require.Equal(t, int64(2), healthy[0].account.ID)
+	require.Len(t, tracker.preferLatencyHealthy(available[:1], "m"), 1, "falls back to degraded accounts rather than failing")
+	require.Len(t, tracker.preferLatencyHealthy(available, "other"), 2)
+
+	accounts := []*Account{{ID: 1}, {ID: 2}, {ID: 3}}
+	tracker.demoteLatencyDegraded(accounts, "m")
+	require.Equal(t, []int64{2, 3, 1}, []int64{accounts[0].ID, accounts[1].ID, accounts[2].ID})
+
+	var disabled *LatencySLOTracker
+	require.False(t, disabled.IsDegraded(1, "m"))
+	require.Len(t, disabled.preferLatencyHealthy(available, "m"), 2)
+}
@@ -0,0 +1,506 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	mappingSimulationDefaultDays    = 7
+	mappingSimulationMaxDays        = 30
+	mappingSimulationMaxTraffic     = 5000 // 参与回放的 (账号, 分组, 模型) 组合上限
+	mappingSimulationMaxChanges     = 500  // 报告中列出的变化条目上限，受影响请求数仍按全部组合统计
+	mappingSimulationMaxCandidates  = 50   // 单次模拟的账号/分组草稿数上限
+	mappingSimulationConflictModels = 5    // 冲突条目中列出的示例模型数
+)
+
+// 模拟结果中的变化类型。
+const (
+	MappingSimulationKindAccountMapping = "account_mapping"
+	MappingSimulationKindGroupRouting   = "group_routing"
+)
+
+// 草稿配置中的冲突代码（前端按代码做本地化）。
+const (
+	MappingConflictShadowedRule     = "shadowed_rule"     // 有序规则被更靠前的规则完全遮蔽，永远不会命中
+	MappingConflictAmbiguousMapping = "ambiguous_mapping" // 多个同长度的 model_mapping 模式命中同一模型，仅靠字典序决胜
+	MappingConflictAmbiguousRouting = "ambiguous_routing" // 多个分组路由模式命中同一模型，实际选中哪个不确定
+)
+
+// MappingSimulationAccountChange 账号映射草稿；字段为 nil 时沿用已保存配置。
+type MappingSimulationAccountChange struct {
+	AccountID         int64          `json:"account_id"`
+	ModelMapping      map[string]any `json:"model_mapping"`
+	ModelMappingRules []any          `json:"model_mapping_rules"`
+}
+
+// MappingSimulationGroupChange 分组模型路由草稿；字段为 nil 时沿用已保存配置。
+type MappingSimulationGroupChange struct {
+	GroupID             int64              `json:"group_id"`
+	ModelRouting        map[string][]int64 `json:"model_routing"`
+	ModelRoutingEnabled *bool              `json:"model_routing_enabled"`
+}
+
+// MappingSimulationInput 一组待评估的映射/路由变更。
+type MappingSimulationInput struct {
+	Days     int                              `json:"days"`
+	Accounts []MappingSimulationAccountChange `json:"accounts"`
+	Groups   []MappingSimulationGroupChange   `json:"groups"`
+}
+
+// MappingSimulationTraffic 统计区间内某账号/分组上某请求模型的真实流量。
+type MappingSimulationTraffic struct {
+	AccountID      int64
+	GroupID        *int64
+	RequestedModel string
+	UpstreamModel  string
+	Requests       int64
+	LastSeenAt     time.Time
+}
+
+// MappingSimulationRepository 读取模拟回放所需的真实流量。
+type MappingSimulationRepository interface {
+	// AggregateRoutedTraffic 聚合 [start, end) 区间内落在给定账号或分组上的请求，按请求数降序返回前 limit 行。
+	AggregateRoutedTraffic(ctx context.Context, start, end time.Time, accountIDs, groupIDs []int64, limit int) ([]MappingSimulationTraffic, error)
+}
+
+// MappingSimulationOutcome 变更前/后的路由结果；账号映射与分组路由分别填充各自字段。
+type MappingSimulationOutcome struct {
+	MappedModel string `json:"mapped_model,omitempty"`
+	Supported   *bool  `json:"supported,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	RuleIndex   *int   `json:"rule_index,omitempty"`
+
+	RoutingAccountIDs []int64 `json:"routing_account_ids,omitempty"`
+	// AccountEligible 实际承接该流量的账号在此路由下是否仍可被选中（未命中路由时全部账号可选）
+	AccountEligible *bool `json:"account_eligible,omitempty"`
+}
+
+// MappingSimulationChange 一组真实流量在变更后的路由差异。
+type MappingSimulationChange struct {
+	Kind                  string                   `json:"kind"`
+	AccountID             int64                    `json:"account_id"`
+	GroupID               *int64                   `json:"group_id,omitempty"`
+	RequestedModel        string                   `json:"requested_model"`
+	ObservedUpstreamModel string                   `json:"observed_upstream_model"`
+	Requests              int64                    `json:"requests"`
+	LastSeenAt            time.Time                `json:"last_seen_at"`
+	Before                MappingSimulationOutcome `json:"before"`
+	After                 MappingSimulationOutcome `json:"after"`
+}
+
+// MappingConflict 草稿配置中的规则冲突。
+type MappingConflict struct {
+	Code      string   `json:"code"`
+	AccountID int64    `json:"account_id,omitempty"`
+	GroupID   int64    `json:"group_id,omitempty"`
+	Patterns  []string `json:"patterns"`
+	RuleIndex *int     `json:"rule_index,omitempty"`
+	Models    []string `json:"models,omitempty"`
+	Observed  bool     `json:"observed"` // 冲突是否已在真实流量中出现
+	Message   string   `json:"message"`
+}
+
+// MappingSimulationReport 映射/路由变更的模拟报告。
+type MappingSimulationReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Days        int       `json:"days"`
+
+	SampledRequests  int64 `json:"sampled_requests"`
+	AffectedRequests int64 `json:"affected_requests"`
+	// Truncated 为 true 表示流量组合超过回放上限，或变化条目超过展示上限
+	Truncated bool `json:"truncated"`
+
+	Changes   []MappingSimulationChange `json:"changes"`
+	Conflicts []MappingConflict         `json:"conflicts"`
+}
+
+// MappingSimulationService 在应用映射/路由变更前，用最近的真实流量回放草稿配置。
+type MappingSimulationService struct {
+	repo        MappingSimulationRepository
+	accountRepo AccountRepository
+	groupRepo   GroupRepository
+	now         func() time.Time
+}
+
+// NewMappingSimulationService creates a new MappingSimulationService.
+func NewMappingSimulationService(repo MappingSimulationRepository, accountRepo AccountRepository, groupRepo GroupRepository) *MappingSimulationService {
+	return &MappingSimulationService{repo: repo, accountRepo: accountRepo, groupRepo: groupRepo, now: time.Now}
+}
+
+// mappingSimulationAccount 同一账号变更前后的配置。
+type mappingSimulationAccount struct {
+	current, draft *Account
+}
+
+// mappingSimulationGroup 同一分组变更前后的配置。
+type mappingSimulationGroup struct {
+	current, draft *Group
+}
+
+// Simulate 用最近 days 天的真实流量回放草稿配置，报告路由会发生变化的请求及草稿中的规则冲突。
+func (s *MappingSimulationService) Simulate(ctx context.Context, input MappingSimulationInput) (*MappingSimulationReport, error) {
+	if len(input.Accounts) == 0 && len(input.Groups) == 0 {
+		return nil, infraerrors.BadRequest("MAPPING_SIMULATION_EMPTY", "at least one account or group change is required")
+	}
+	if len(input.Accounts) > mappingSimulationMaxCandidates || len(input.Groups) > mappingSimulationMaxCandidates {
+		return nil, infraerrors.BadRequest("MAPPING_SIMULATION_TOO_LARGE", fmt.Sprintf("at most %d accounts and %d groups per simulation", mappingSimulationMaxCandidates, mappingSimulationMaxCandidates))
+	}
+
+	accounts := make(map[int64]mappingSimulationAccount, len(input.Accounts))
+	accountIDs := make([]int64, 0, len(input.Accounts))
+	for _, change := range input.Accounts {
+		if _, dup := accounts[change.AccountID]; dup {
+			return nil, infraerrors.BadRequest("MAPPING_SIMULATION_DUPLICATE", fmt.Sprintf("account %d appears more than once", change.AccountID))
+		}
+		current, err := s.accountRepo.GetByID(ctx, change.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		draft, err := current.WithModelMappingDraft(change.ModelMapping, change.ModelMappingRules)
+		if err != nil {
+			return nil, err
+		}
+		accounts[change.AccountID] = mappingSimulationAccount{current: current, draft: draft}
+		accountIDs = append(accountIDs, change.AccountID)
+	}
+
+	groups := make(map[int64]mappingSimulationGroup, len(input.Groups))
+	groupIDs := make([]int64, 0, len(input.Groups))
+	for _, change := range input.Groups {
+		if _, dup := groups[change.GroupID]; dup {
+			return nil, infraerrors.BadRequest("MAPPING_SIMULATION_DUPLICATE", fmt.Sprintf("group %d appears more than once", change.GroupID))
+		}
+		current, err := s.groupRepo.GetByID(ctx, change.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		draft := *current
+		if change.ModelRouting != nil {
+			draft.ModelRouting = change.ModelRouting
+		}
+		if change.ModelRoutingEnabled != nil {
+			draft.ModelRoutingEnabled = *change.ModelRoutingEnabled
+		}
+		groups[change.GroupID] = mappingSimulationGroup{current: current, draft: &draft}
+		groupIDs = append(groupIDs, change.GroupID)
+	}
+
+	days := clampCapacityDays(input.Days, mappingSimulationDefaultDays, 1, mappingSimulationMaxDays)
+	end := s.now().UTC()
+	traffic, err := s.repo.AggregateRoutedTraffic(ctx, end.AddDate(0, 0, -days), end, accountIDs, groupIDs, mappingSimulationMaxTraffic)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MappingSimulationReport{
+		GeneratedAt: end,
+		Days:        days,
+		Truncated:   len(traffic) >= mappingSimulationMaxTraffic,
+		Changes:     []MappingSimulationChange{},
+		Conflicts:   []MappingConflict{},
+	}
+	for _, row := range traffic {
+		report.SampledRequests += row.Requests
+		affected := false
+		if acc, ok := accounts[row.AccountID]; ok {
+			if change, changed := simulateAccountMapping(acc, row); changed {
+				affected = true
+				report.addChange(change)
+			}
+		}
+		if row.GroupID != nil {
+			if grp, ok := groups[*row.GroupID]; ok {
+				if change, changed := simulateGroupRouting(grp, row); changed {
+					affected = true
+					report.addChange(change)
+				}
+			}
+		}
+		if affected {
+			report.AffectedRequests += row.Requests
+		}
+	}
+
+	for _, id := range accountIDs {
+		report.Conflicts = append(report.Conflicts, detectAccountMappingConflicts(accounts[id].draft, traffic)...)
+	}
+	for _, id := range groupIDs {
+		report.Conflicts = append(report.Conflicts, detectGroupRoutingConflicts(groups[id].draft, traffic)...)
+	}
+	return report, nil
+}
+
+func (r *MappingSimulationReport) addChange(change MappingSimulationChange) {
+	if len(r.Changes) >= mappingSimulationMaxChanges {
+		r.Truncated = true
+		return
+	}
+	r.Changes = append(r.Changes, change)
+}
+
+// simulateAccountMapping 比较同一请求模型在账号当前与草稿映射下的映射结果及是否仍被支持。
+func simulateAccountMapping(acc mappingSimulationAccount, row MappingSimulationTraffic) (MappingSimulationChange, bool) {
+	before := acc.current.ExplainModelMapping(row.RequestedModel)
+	after := acc.draft.ExplainModelMapping(row.RequestedModel)
+	if before.MappedModel == after.MappedModel && before.Supported == after.Supported {
+		return MappingSimulationChange{}, false
+	}
+	return MappingSimulationChange{
+		Kind:                  MappingSimulationKindAccountMapping,
+		AccountID:             row.AccountID,
+		GroupID:               row.GroupID,
+		RequestedModel:        row.RequestedModel,
+		ObservedUpstreamModel: row.UpstreamModel,
+		Requests:              row.Requests,
+		LastSeenAt:            row.LastSeenAt,
+		Before:                mappingOutcomeFromResolution(before),
+		After:                 mappingOutcomeFromResolution(after),
+	}, true
+}
+
+func mappingOutcomeFromResolution(res ModelMappingResolution) MappingSimulationOutcome {
+	supported := res.Supported
+	return MappingSimulationOutcome{
+		MappedModel: res.MappedModel,
+		Supported:   &supported,
+		Source:      res.Source,
+		Pattern:     res.Pattern,
+		RuleIndex:   res.RuleIndex,
+	}
+}
+
+// simulateGroupRouting 比较同一请求模型在分组当前与草稿路由下的候选账号集合。
+func simulateGroupRouting(grp mappingSimulationGroup, row MappingSimulationTraffic) (MappingSimulationChange, bool) {
+	before := grp.current.GetRoutingAccountIDs(row.RequestedModel)
+	after := grp.draft.GetRoutingAccountIDs(row.RequestedModel)
+	if sameAccountIDSet(before, after) {
+		return MappingSimulationChange{}, false
+	}
+	return MappingSimulationChange{
+		Kind:                  MappingSimulationKindGroupRouting,
+		AccountID:             row.AccountID,
+		GroupID:               row.GroupID,
+		RequestedModel:        row.RequestedModel,
+		ObservedUpstreamModel: row.UpstreamModel,
+		Requests:              row.Requests,
+		LastSeenAt:            row.LastSeenAt,
+		Before:                routingOutcome(before, row.AccountID),
+		After:                 routingOutcome(after, row.AccountID),
+	}, true
+}
+
+func routingOutcome(accountIDs []int64, observedAccountID int64) MappingSimulationOutcome {
+	eligible := len(accountIDs) == 0 || slices.Contains(accountIDs, observedAccountID)
+	return MappingSimulationOutcome{RoutingAccountIDs: accountIDs, AccountEligible: &eligible}
+}
+
+func sameAccountIDSet(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := slices.Clone(a)
+	y := slices.Clone(b)
+	slices.Sort(x)
+	slices.Sort(y)
+	return slices.Equal(x, y)
+}
+
+// observedModels 返回流量中落在 match 上的去重请求模型，按请求数降序。
+func observedModels(traffic []MappingSimulationTraffic, match func(MappingSimulationTraffic) bool) []string {
+	seen := make(map[string]struct{})
+	var models []string
+	for _, row := range traffic {
+		if !match(row) {
+			continue
+		}
+		if _, ok := seen[row.RequestedModel]; ok {
+			continue
+		}
+		seen[row.RequestedModel] = struct{}{}
+		models = append(models, row.RequestedModel)
+	}
+	return models
+}
+
+// modelMappingPatternSample 返回一定命中该模式的字面样例（精确模式为自身，通配符的 * 取空串）；正则无法构造时返回空。
+func modelMappingPatternSample(pattern string) string {
+	switch compileModelMappingPattern(pattern).kind {
+	case ModelMappingKindExact:
+		return pattern
+	case ModelMappingKindWildcard:
+		return strings.ReplaceAll(pattern, "*", "")
+	}
+	return ""
+}
+
+// detectAccountMappingConflicts 检查账号草稿映射：
+//   - 有序规则的字面样例被更靠前的规则命中，或其在真实流量中命中的模型全部被更靠前的规则截走；
+//   - 真实流量中的模型同时命中多个同长度的 model_mapping 模式。
+func detectAccountMappingConflicts(account *Account, traffic []MappingSimulationTraffic) []MappingConflict {
+	var conflicts []MappingConflict
+	models := observedModels(traffic, func(row MappingSimulationTraffic) bool { return row.AccountID == account.ID })
+
+	rules := account.GetModelMappingRules()
+	for j := 1; j < len(rules); j++ {
+		rule := rules[j]
+		// 真实流量中命中本规则的模型都被更靠前的规则截走，或字面样例被截走，即视为遮蔽
+		shadowedBy := -1
+		var hitModels []string
+		for _, model := range models {
+			if _, ok := matchModelMappingPattern(rule.Pattern, rule.Target, model); !ok {
+				continue
+			}
+			hit, ok := matchModelMappingRules(rules[:j], model)
+			if !ok {
+				hitModels = nil
+				shadowedBy = -1
+				break
+			}
+			hitModels = append(hitModels, model)
+			shadowedBy = hit.index
+		}
+		if sample := modelMappingPatternSample(rule.Pattern); sample != "" && shadowedBy < 0 && len(hitModels) == 0 {
+			if hit, ok := matchModelMappingRules(rules[:j], sample); ok {
+				shadowedBy = hit.index
+			}
+		}
+		if shadowedBy < 0 {
+			continue
+		}
+		index := j
+		conflicts = append(conflicts, MappingConflict{
+			Code:      MappingConflictShadowedRule,
+			AccountID: account.ID,
+			Patterns:  []string{rule.Pattern, rules[shadowedBy].Pattern},
+			RuleIndex: &index,
+			Models:    limitConflictModels(hitModels),
+			Observed:  len(hitModels) > 0,
+			Message:   fmt.Sprintf("rule #%d %q never matches: rule #%d %q comes first and covers it", j, rule.Pattern, shadowedBy, rules[shadowedBy].Pattern),
+		})
+	}
+
+	mapping := account.GetModelMapping()
+	ambiguous := make(map[string][]string) // 冲突模式组（按 \x00 连接）-> 命中模型
+	for _, model := range models {
+		if _, exact := mapping[model]; exact {
+			continue
+		}
+		var matched []string
+		for pattern, target := range mapping {
+			if compileModelMappingPattern(pattern).kind == ModelMappingKindExact {
+				continue
+			}
+			if _, ok := matchModelMappingPattern(pattern, target, model); ok {
+				matched = append(matched, pattern)
+			}
+		}
+		if tied := longestTiedPatterns(matched); len(tied) > 1 {
+			key := strings.Join(tied, "\x00")
+			ambiguous[key] = append(ambiguous[key], model)
+		}
+	}
+	for _, key := range sortedConflictKeys(ambiguous) {
+		patterns := strings.Split(key, "\x00")
+		conflicts = append(conflicts, MappingConflict{
+			Code:      MappingConflictAmbiguousMapping,
+			AccountID: account.ID,
+			Patterns:  patterns,
+			Models:    limitConflictModels(ambiguous[key]),
+			Observed:  true,
+			Message:   fmt.Sprintf("patterns %s match the same model with equal length; %q wins only by lexical order", strings.Join(quoteAll(patterns), ", "), patterns[0]),
+		})
+	}
+	return conflicts
+}
+
+// detectGroupRoutingConflicts 检查分组草稿路由：真实流量中的模型没有精确路由、却命中多个通配模式时，
+// GetRoutingAccountIDs 的结果取决于 map 遍历顺序。
+func detectGroupRoutingConflicts(group *Group, traffic []MappingSimulationTraffic) []MappingConflict {
+	if !group.ModelRoutingEnabled || len(group.ModelRouting) == 0 {
+		return nil
+	}
+	models := observedModels(traffic, func(row MappingSimulationTraffic) bool {
+		return row.GroupID != nil && *row.GroupID == group.ID
+	})
+	ambiguous := make(map[string][]string)
+	for _, model := range models {
+		if ids, exact := group.ModelRouting[model]; exact && len(ids) > 0 {
+			continue
+		}
+		var matched []string
+		for pattern, ids := range group.ModelRouting {
+			if len(ids) > 0 && matchModelPattern(pattern, model) {
+				matched = append(matched, pattern)
+			}
+		}
+		if len(matched) > 1 {
+			sort.Strings(matched)
+			key := strings.Join(matched, "\x00")
+			ambiguous[key] = append(ambiguous[key], model)
+		}
+	}
+	var conflicts []MappingConflict
+	for _, key := range sortedConflictKeys(ambiguous) {
+		patterns := strings.Split(key, "\x00")
+		conflicts = append(conflicts, MappingConflict{
+			Code:     MappingConflictAmbiguousRouting,
+			GroupID:  group.ID,
+			Patterns: patterns,
+			Models:   limitConflictModels(ambiguous[key]),
+			Observed: true,
+			Message:  fmt.Sprintf("routing patterns %s all match the same model; which account set is used is not deterministic", strings.Join(quoteAll(patterns), ", ")),
+		})
+	}
+	return conflicts
+}
+
+// longestTiedPatterns 返回最长的若干个模式（按字典序）；matchModelMappingMap 在它们之间仅按字典序决胜。
+func longestTiedPatterns(patterns []string) []string {
+	if len(patterns) < 2 {
+		return nil
+	}
+	longest := 0
+	for _, p := range patterns {
+		longest = max(longest, len(p))
+	}
+	var tied []string
+	for _, p := range patterns {
+		if len(p) == longest {
+			tied = append(tied, p)
+		}
+	}
+	sort.Strings(tied)
+	return tied
+}
+
+func limitConflictModels(models []string) []string {
+	if len(models) > mappingSimulationConflictModels {
+		return models[:mappingSimulationConflictModels]
+	}
+	return models
+}
+
+func sortedConflictKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func quoteAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = fmt.Sprintf("%q", v)
+	}
+	return out
+}
@@ -0,0 +1,192 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mappingSimulationRepoStub struct {
+	rows       []MappingSimulationTraffic
+	accountIDs []int64
+	groupIDs   []int64
+	start, end time.Time
+}
+
+func (s *mappingSimulationRepoStub) AggregateRoutedTraffic(_ context.Context, start, end time.Time, accountIDs, groupIDs []int64, _ int) ([]MappingSimulationTraffic, error) {
+	s.start, s.end, s.accountIDs, s.groupIDs = start, end, accountIDs, groupIDs
+	return s.rows, nil
+}
+
+type mappingSimulationAccountRepoStub struct {
+	AccountRepository
+	accounts map[int64]*Account
+}
+
+func (s *mappingSimulationAccountRepoStub) GetByID(_ context.Context, id int64) (*Account, error) {
+	if acc, ok := s.accounts[id]; ok {
+		return acc, nil
+	}
+	return nil, ErrAccountNotFound
+}
+
+type mappingSimulationGroupRepoStub struct {
+	GroupRepository
+	groups map[int64]*Group
+}
+
+func (s *mappingSimulationGroupRepoStub) GetByID(_ context.Context, id int64) (*Group, error) {
+	if g, ok := s.groups[id]; ok {
+		return g, nil
+	}
+	return nil, ErrGroupNotFound
+}
+
+func newMappingSimulationServiceForTest(rows []MappingSimulationTraffic) (*MappingSimulationService, *mappingSimulationRepoStub) {
+	repo := &mappingSimulationRepoStub{rows: rows}
+	accounts := &mappingSimulationAccountRepoStub{accounts: map[int64]*Account{
+		1: {ID: 1, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{
+			"model_mapping": map[string]any{"gpt-5*": "gpt-5"},
+		}},
+	}}
+	groups := &mappingSimulationGroupRepoStub{groups: map[int64]*Group{
+		10: {ID: 10, ModelRoutingEnabled: true, ModelRouting: map[string][]int64{"claude-opus-*": {1, 2}}},
+	}}
+	svc := NewMappingSimulationService(repo, accounts, groups)
+	svc.now = func() time.Time { return time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC) }
+	return svc, repo
+}
+
+func TestMappingSimulation_AccountMappingChanges(t *testing.T) {
+	groupID := int64(10)
+	svc, repo := newMappingSimulationServiceForTest([]MappingSimulationTraffic{
+		{AccountID: 1, GroupID: &groupID, RequestedModel: "gpt-5-mini", UpstreamModel: "gpt-5", Requests: 40},
+		{AccountID: 1, GroupID: &groupID, RequestedModel: "gpt-5.1", UpstreamModel: "gpt-5", Requests: 10},
+	})
+
+	report, err := svc.Simulate(context.Background(), MappingSimulationInput{
+		Days: 3,
+		Accounts: []MappingSimulationAccountChange{{
+			AccountID:         1,
+			ModelMappingRules: []any{map[string]any{"pattern": "gpt-5*-mini", "target": "gpt-5-mini"}},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, repo.accountIDs)
+	require.Equal(t, 3*24*time.Hour, repo.end.Sub(repo.start))
+
+	require.Equal(t, int64(50), report.SampledRequests)
+	require.Equal(t, int64(40), report.AffectedRequests)
+	require.Len(t, report.Changes, 1)
+	change := report.Changes[0]
+	require.Equal(t, MappingSimulationKindAccountMapping, change.Kind)
+	require.Equal(t, "gpt-5-mini", change.RequestedModel)
+	require.Equal(t, "gpt-5", change.Before.MappedModel)
+	require.Equal(t, "gpt-5-mini", change.After.MappedModel)
+	require.Equal(t, ModelMappingSourceRules, change.After.Source)
+}
+
+func TestMappingSimulation_GroupRoutingChanges(t *testing.T) {
+	groupID := int64(10)
+	svc, _ := newMappingSimulationServiceForTest([]MappingSimulationTraffic{
+		{AccountID: 1, GroupID: &groupID, RequestedModel: "claude-opus-4-5", UpstreamModel: "claude-opus-4-5", Requests: 7},
+		{AccountID: 2, GroupID: &groupID, RequestedModel: "claude-sonnet-4-5", UpstreamModel: "claude-sonnet-4-5", Requests: 3},
+	})
+
+	report, err := svc.Simulate(context.Background(), MappingSimulationInput{
+		Groups: []MappingSimulationGroupChange{{
+			GroupID:      10,
+			ModelRouting: map[string][]int64{"claude-opus-*": {2}},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), report.AffectedRequests)
+	require.Len(t, report.Changes, 1)
+	change := report.Changes[0]
+	require.Equal(t, MappingSimulationKindGroupRouting, change.Kind)
+	require.Equal(t, []int64{1, 2}, change.Before.RoutingAccountIDs)
+	require.Equal(t, []int64{2}, change.After.RoutingAccountIDs)
+	require.True(t, *change.Before.AccountEligible)
+	require.False(t, *change.After.AccountEligible, "account 1 currently serving this traffic would be excluded")
+}
+
+func TestMappingSimulation_Conflicts(t *testing.T) {
+	groupID := int64(10)
+	svc, _ := newMappingSimulationServiceForTest([]MappingSimulationTraffic{
+		{AccountID: 1, GroupID: &groupID, RequestedModel: "gpt-5-codex", Requests: 5},
+		{AccountID: 1, GroupID: &groupID, RequestedModel: "claude-opus-4-5", Requests: 5},
+	})
+
+	report, err := svc.Simulate(context.Background(), MappingSimulationInput{
+		Accounts: []MappingSimulationAccountChange{{
+			AccountID: 1,
+			ModelMapping: map[string]any{
+				"gpt-5-*": "gpt-5",
+				"*-codex": "gpt-5-codex",
+			},
+			ModelMappingRules: []any{
+				map[string]any{"pattern": "claude-*", "target": "claude-sonnet-4-5"},
+				map[string]any{"pattern": "claude-opus-*", "target": "claude-opus-4-5"},
+			},
+		}},
+		Groups: []MappingSimulationGroupChange{{
+			GroupID:      10,
+			ModelRouting: map[string][]int64{"claude-*": {1}, "claude-opus-*": {2}},
+		}},
+	})
+	require.NoError(t, err)
+
+	codes := map[string]MappingConflict{}
+	for _, c := range report.Conflicts {
+		codes[c.Code] = c
+	}
+	require.Len(t, codes, 3, "%+v", report.Conflicts)
+
+	shadowed := codes[MappingConflictShadowedRule]
+	require.Equal(t, []string{"claude-opus-*", "claude-*"}, shadowed.Patterns)
+	require.Equal(t, 1, *shadowed.RuleIndex)
+	require.True(t, shadowed.Observed)
+	require.Equal(t, []string{"claude-opus-4-5"}, shadowed.Models)
+
+	ambiguous := codes[MappingConflictAmbiguousMapping]
+	require.Equal(t, []string{"*-codex", "gpt-5-*"}, ambiguous.Patterns)
+	require.Equal(t, []string{"gpt-5-codex"}, ambiguous.Models)
+
+	routing := codes[MappingConflictAmbiguousRouting]
+	require.Equal(t, int64(10), routing.GroupID)
+	require.Equal(t, []string{"claude-*", "claude-opus-*"}, routing.Patterns)
+}
+
+func TestMappingSimulation_ShadowedRuleWithoutTraffic(t *testing.T) {
+	account := &Account{ID: 1, Platform: PlatformOpenAI, Credentials: map[string]any{
+		"model_mapping_rules": []any{
+			map[string]any{"pattern": "gpt-*", "target": "gpt-5"},
+			map[string]any{"pattern": "gpt-4o", "target": "gpt-4.1"},
+			map[string]any{"pattern": "re:o\\d-mini", "target": "o4-mini"},
+		},
+	}}
+	conflicts := detectAccountMappingConflicts(account, nil)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, []string{"gpt-4o", "gpt-*"}, conflicts[0].Patterns)
+	require.False(t, conflicts[0].Observed)
+}
+
+func TestMappingSimulation_Validation(t *testing.T) {
+	svc, _ := newMappingSimulationServiceForTest(nil)
+
+	_, err := svc.Simulate(context.Background(), MappingSimulationInput{})
+	require.Error(t, err)
+
+	_, err = svc.Simulate(context.Background(), MappingSimulationInput{Accounts: []MappingSimulationAccountChange{{AccountID: 99}}})
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	_, err = svc.Simulate(context.Background(), MappingSimulationInput{Accounts: []MappingSimulationAccountChange{{
+		AccountID:         1,
+		ModelMappingRules: []any{map[string]any{"pattern": "re:(", "target": "x"}},
+	}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// CapabilityRoutedHeader 响应头：请求因模型缺少所需能力被改路由时，值为客户端原始请求的模型。
+const CapabilityRoutedHeader = "X-Sub2API-Capability-Routed-From"
+
+// ModelCapability 请求内容可能依赖的模型能力。
+type ModelCapability string
+
+const (
+	ModelCapabilityVision    ModelCapability = "vision"
+	ModelCapabilityAudio     ModelCapability = "audio"
+	ModelCapabilityTools     ModelCapability = "tools"
+	ModelCapabilityReasoning ModelCapability = "reasoning"
+)
+
+// modelCapabilityOrder 能力的固定检查顺序，保证同一请求总是报告同一个缺失项。
+var modelCapabilityOrder = []ModelCapability{ModelCapabilityVision, ModelCapabilityAudio, ModelCapabilityTools, ModelCapabilityReasoning}
+
+var modelCapabilityDescriptions = map[ModelCapability]string{
+	ModelCapabilityVision:    "image input",
+	ModelCapabilityAudio:     "audio input",
+	ModelCapabilityTools:     "tool use",
+	ModelCapabilityReasoning: "reasoning",
+}
+
+// ModelCapabilities 能力矩阵中一个模型的能力：存在的键表示已知是否支持，缺失表示未知（不拦截）。
+type ModelCapabilities map[ModelCapability]bool
+
+// CapabilityRequirement 请求内容依赖的一项能力及首个触发位置（如 messages[2].content[1]）。
+type CapabilityRequirement struct {
+	Capability ModelCapability
+	Location   string
+}
+
+// CapabilityCheckDecision 能力校验结果：RoutedModel 非空表示改用备用模型。
+type CapabilityCheckDecision struct {
+	Missing     CapabilityRequirement
+	RoutedModel string
+}
+
+// capabilitiesFromLiteLLMEntry 提取价格数据声明的能力；源数据声明了任一能力时，其余未声明的能力视为不支持。
+func capabilitiesFromLiteLLMEntry(entry *LiteLLMRawEntry) ModelCapabilities {
+	flags := map[ModelCapability]*bool{
+		ModelCapabilityVision:    entry.SupportsVision,
+		ModelCapabilityAudio:     entry.SupportsAudioInput,
+		ModelCapabilityTools:     entry.SupportsFunctionCalling,
+		ModelCapabilityReasoning: entry.SupportsReasoning,
+	}
+	declared := false
+	for _, v := range flags {
+		declared = declared || v != nil
+	}
+	if !declared {
+		return nil
+	}
+	caps := make(ModelCapabilities, len(flags))
+	for capability, v := range flags {
+		caps[capability] = v != nil && *v
+	}
+	return caps
+}
+
+// lookupModelCapabilities 合并价格数据与 gateway.model_capabilities 覆盖项，得到模型的能力矩阵行。
+func lookupModelCapabilities(cfg *config.Config, pricingService *PricingService, model string) ModelCapabilities {
+	caps := ModelCapabilities{}
+	if pricingService != nil {
+		if pricing := pricingService.GetModelPricing(model); pricing != nil {
+			for capability, supported := range pricing.Capabilities {
+				caps[capability] = supported
+			}
+		}
+	}
+	if cfg != nil {
+		if override := matchModelCapabilityOverride(cfg.Gateway.ModelCapabilities, model); override != nil {
+			for capability, v := range map[ModelCapability]*bool{
+				ModelCapabilityVision:    override.Vision,
+				ModelCapabilityAudio:     override.Audio,
+				ModelCapabilityTools:     override.Tools,
+				ModelCapabilityReasoning: override.Reasoning,
+			} {
+				if v != nil {
+					caps[capability] = *v
+				}
+			}
+		}
+	}
+	return caps
+}
+
+// matchModelCapabilityOverride 精确匹配优先，其次取最长的末尾 * 通配。
+func matchModelCapabilityOverride(overrides []config.GatewayModelCapabilityOverride, model string) *config.GatewayModelCapabilityOverride {
+	model = strings.ToLower(strings.TrimSpace(model))
+	var best *config.GatewayModelCapabilityOverride
+	bestLen := -1
+	for i := range overrides {
+		pattern := strings.ToLower(strings.TrimSpace(overrides[i].Model))
+		if pattern == model {
+			return &overrides[i]
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best, bestLen = &overrides[i], len(prefix)
+		}
+	}
+	return best
+}
+
+// firstMissingCapability 返回第一项模型明确不支持的需求；能力未知时放行。
+func firstMissingCapability(caps ModelCapabilities, reqs []CapabilityRequirement) (CapabilityRequirement, bool) {
+	for _, req := range reqs {
+		if supported, known := caps[req.Capability]; known && !supported {
+			return req, true
+		}
+	}
+	return CapabilityRequirement{}, false
+}
+
+// ResolveCapabilityFallbackModel 返回请求模型缺少能力时应改用的备用模型。
+func (g *Group) ResolveCapabilityFallbackModel(requestedModel string) string {
+	if g == nil {
+		return ""
+	}
+	return resolveGroupModelOverride(g.CapabilityCheck.FallbackModels, requestedModel)
+}
+
+// normalizeGroupCapabilityCheckConfig 清理并校验能力校验配置中的备用模型映射。
+func normalizeGroupCapabilityCheckConfig(cfg GroupCapabilityCheckConfig) (GroupCapabilityCheckConfig, error) {
+	fallback, err := normalizeGroupModelOverrides(cfg.FallbackModels, "INVALID_CAPABILITY_CHECK", "capability fallback")
+	if err != nil {
+		return GroupCapabilityCheckConfig{}, err
+	}
+	cfg.FallbackModels = fallback
+	return cfg, nil
+}
+
+// resolveCapabilityCheck 校验请求需求与渠道映射后模型的能力：缺少能力时改用分组备用模型（其能力满足或未知），
+// 否则返回 MODEL_CAPABILITY_UNSUPPORTED 错误并指出触发的请求位置，避免请求在上游失败。
+func resolveCapabilityCheck(group *Group, requestedModel string, reqs []CapabilityRequirement, lookup func(model string) ModelCapabilities) (*CapabilityCheckDecision, error) {
+	if group == nil || !group.CapabilityCheck.Enabled || requestedModel == "" || len(reqs) == 0 {
+		return nil, nil
+	}
+	missing, ok := firstMissingCapability(lookup(requestedModel), reqs)
+	if !ok {
+		return nil, nil
+	}
+	if target := group.ResolveCapabilityFallbackModel(requestedModel); target != "" {
+		if _, stillMissing := firstMissingCapability(lookup(target), reqs); !stillMissing {
+			return &CapabilityCheckDecision{Missing: missing, RoutedModel: target}, nil
+		}
+	}
+	return nil, infraerrors.BadRequest("MODEL_CAPABILITY_UNSUPPORTED", fmt.Sprintf(
+		"model %s does not support %s, required by %s; remove that item or request a model with %s capability",
+		requestedModel, modelCapabilityDescriptions[missing.Capability], missing.Location, missing.Capability,
+	)).WithMetadata(map[string]string{
+		"model":      requestedModel,
+		"capability": string(missing.Capability),
+		"location":   missing.Location,
+	})
+}
+
+// ResolveCapabilityCheck 按分组能力校验配置检查请求需求，模型先经渠道映射再查能力矩阵。
+func (s *GatewayService) ResolveCapabilityCheck(ctx context.Context, group *Group, requestedModel string, reqs []CapabilityRequirement) (*CapabilityCheckDecision, error) {
+	return resolveCapabilityCheck(group, requestedModel, reqs, func(model string) ModelCapabilities {
+		mapping, _ := s.ResolveChannelMappingAndRestrict(ctx, &group.ID, model)
+		if mapping.MappedModel != "" {
+			model = mapping.MappedModel
+		}
+		var pricingService *PricingService
+		if s.billingService != nil {
+			pricingService = s.billingService.pricingService
+		}
+		return lookupModelCapabilities(s.cfg, pricingService, model)
+	})
+}
+
+// ResolveCapabilityCheck 按分组能力校验配置检查请求需求，模型先经渠道映射再查能力矩阵。
+func (s *OpenAIGatewayService) ResolveCapabilityCheck(ctx context.Context, group *Group, requestedModel string, reqs []CapabilityRequirement) (*CapabilityCheckDecision, error) {
+	return resolveCapabilityCheck(group, requestedModel, reqs, func(model string) ModelCapabilities {
+		mapping, _ := s.ResolveChannelMappingAndRestrict(ctx, &group.ID, model)
+		if mapping.MappedModel != "" {
+			model = mapping.MappedModel
+		}
+		var pricingService *PricingService
+		if s.billingService != nil {
+			pricingService = s.billingService.pricingService
+		}
+		return lookupModelCapabilities(s.cfg, pricingService, model)
+	})
+}
+
+// capabilityRequirementSet 记录每项能力首次出现的位置。
+type capabilityRequirementSet map[ModelCapability]string
+
+func (set capabilityRequirementSet) add(capability ModelCapability, location string) {
+	if _, ok := set[capability]; !ok {
+		set[capability] = location
+	}
+}
+
+func (set capabilityRequirementSet) list() []CapabilityRequirement {
+	reqs := make([]CapabilityRequirement, 0, len(set))
+	for _, capability := range modelCapabilityOrder {
+		if location, ok := set[capability]; ok {
+			reqs = append(reqs, CapabilityRequirement{Capability: capability, Location: location})
+		}
+	}
+	return reqs
+}
+
+// DetectAnthropicCapabilityRequirements 识别 Messages 请求依赖的能力：图片块（含 tool_result 内）、工具定义、extended thinking。
+func DetectAnthropicCapabilityRequirements(body []byte) []CapabilityRequirement {
+	set := capabilityRequirementSet{}
+	gjson.GetBytes(body, "messages").ForEach(func(i, msg gjson.Result) bool {
+		msg.Get("content").ForEach(func(j, block gjson.Result) bool {
+			location := fmt.Sprintf("messages[%d].content[%d]", i.Int(), j.Int())
+			switch block.Get("type").String() {
+			case "image":
+				set.add(ModelCapabilityVision, location)
+			case "tool_result":
+				block.Get("content").ForEach(func(k, inner gjson.Result) bool {
+					if inner.Get("type").String() == "image" {
+						set.add(ModelCapabilityVision, fmt.Sprintf("%s.content[%d]", location, k.Int()))
+					}
+					return true
+				})
+			}
+			return true
+		})
+		return true
+	})
+	if tools := gjson.GetBytes(body, "tools"); tools.IsArray() && len(tools.Array()) > 0 {
+		set.add(ModelCapabilityTools, "tools")
+	}
+	if thinking := gjson.GetBytes(body, "thinking.type").String(); thinking == "enabled" || thinking == "adaptive" {
+		set.add(ModelCapabilityReasoning, "thinking")
+	}
+	return set.list()
+}
+
+// DetectChatCompletionsCapabilityRequirements 识别 Chat Completions 请求依赖的能力：image_url / input_audio 内容、
+// tools / functions 定义、reasoning_effort。
+func DetectChatCompletionsCapabilityRequirements(body []byte) []CapabilityRequirement {
+	set := capabilityRequirementSet{}
+	gjson.GetBytes(body, "messages").ForEach(func(i, msg gjson.Result) bool {
+		msg.Get("content").ForEach(func(j, part gjson.Result) bool {
+			location := fmt.Sprintf("messages[%d].content[%d]", i.Int(), j.Int())
+			switch part.Get("type").String() {
+			case "image_url", "image":
+				set.add(ModelCapabilityVision, location)
+			case "input_audio":
+				set.add(ModelCapabilityAudio, location)
+			}
+			return true
+		})
+		return true
+	})
+	for _, field := range []string{"tools", "functions"} {
+		if tools := gjson.GetBytes(body, field); tools.IsArray() && len(tools.Array()) > 0 {
+			set.add(ModelCapabilityTools, field)
+		}
+	}
+	if effort := strings.TrimSpace(gjson.GetBytes(body, "reasoning_effort").String()); effort != "" && effort != "none" {
+		set.add(ModelCapabilityReasoning, "reasoning_effort")
+	}
+	return set.list()
+}
@@ -0,0 +1,123 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newModelCapabilityGatewayServiceForTest(overrides ...config.GatewayModelCapabilityOverride) *GatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.ModelCapabilities = overrides
+	pricing := &PricingService{pricingData: map[string]*LiteLLMModelPricing{
+		"claude-sonnet-4-5": {InputCostPerToken: 3e-6, Capabilities: ModelCapabilities{ModelCapabilityVision: true, ModelCapabilityTools: true, ModelCapabilityReasoning: true}},
+		"claude-instant-1":  {InputCostPerToken: 1e-6, Capabilities: ModelCapabilities{ModelCapabilityVision: false, ModelCapabilityTools: false}},
+		"legacy-text":       {InputCostPerToken: 1e-6},
+	}}
+	return &GatewayService{cfg: cfg, billingService: NewBillingService(cfg, pricing)}
+}
+
+const capabilityImageBody = `{"model":"claude-instant-1","messages":[{"role":"user","content":"hi"},{"role":"user","content":[{"type":"text","text":"look"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}}]}]}`
+
+func TestCapabilitiesFromLiteLLMEntry(t *testing.T) {
+	yes, no := true, false
+	require.Nil(t, capabilitiesFromLiteLLMEntry(&LiteLLMRawEntry{}), "no capability declared means unknown")
+
+	caps := capabilitiesFromLiteLLMEntry(&LiteLLMRawEntry{SupportsVision: &yes, SupportsReasoning: &no})
+	require.Equal(t, ModelCapabilities{
+		ModelCapabilityVision:    true,
+		ModelCapabilityAudio:     false,
+		ModelCapabilityTools:     false,
+		ModelCapabilityReasoning: false,
+	}, caps)
+}
+
+func TestDetectAnthropicCapabilityRequirements(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"tool_result","tool_use_id":"t","content":[{"type":"text","text":"x"},{"type":"image","source":{}}]}]}],"tools":[{"name":"a"}],"thinking":{"type":"enabled","budget_tokens":1024}}`)
+	require.Equal(t, []CapabilityRequirement{
+		{Capability: ModelCapabilityVision, Location: "messages[0].content[0].content[1]"},
+		{Capability: ModelCapabilityTools, Location: "tools"},
+		{Capability: ModelCapabilityReasoning, Location: "thinking"},
+	}, DetectAnthropicCapabilityRequirements(body))
+
+	require.Empty(t, DetectAnthropicCapabilityRequirements([]byte(`{"messages":[{"role":"user","content":"hi"}],"tools":[],"thinking":{"type":"disabled"}}`)))
+}
+
+func TestDetectChatCompletionsCapabilityRequirements(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"input_audio","input_audio":{"data":"x","format":"wav"}}]},{"role":"user","content":[{"type":"text","text":"a"},{"type":"image_url","image_url":{"url":"https://x"}}]}],"functions":[{"name":"f"}],"reasoning_effort":"high"}`)
+	require.Equal(t, []CapabilityRequirement{
+		{Capability: ModelCapabilityVision, Location: "messages[1].content[1]"},
+		{Capability: ModelCapabilityAudio, Location: "messages[0].content[0]"},
+		{Capability: ModelCapabilityTools, Location: "functions"},
+		{Capability: ModelCapabilityReasoning, Location: "reasoning_effort"},
+	}, DetectChatCompletionsCapabilityRequirements(body))
+}
+
+func TestGatewayServiceResolveCapabilityCheck_RejectsWithLocation(t *testing.T) {
+	svc := newModelCapabilityGatewayServiceForTest()
+	group := &Group{ID: 1, CapabilityCheck: GroupCapabilityCheckConfig{Enabled: true}}
+	reqs := DetectAnthropicCapabilityRequirements([]byte(capabilityImageBody))
+
+	_, err := svc.ResolveCapabilityCheck(context.Background(), group, "claude-instant-1", reqs)
+	require.Error(t, err)
+	require.Equal(t, "MODEL_CAPABILITY_UNSUPPORTED", infraerrors.Reason(err))
+	require.Contains(t, infraerrors.Message(err), "messages[1].content[1]")
+	require.Equal(t, "vision", infraerrors.FromError(err).Metadata["capability"])
+
+	// 未开启时不校验
+	group.CapabilityCheck.Enabled = false
+	decision, err := svc.ResolveCapabilityCheck(context.Background(), group, "claude-instant-1", reqs)
+	require.NoError(t, err)
+	require.Nil(t, decision)
+}
+
+func TestGatewayServiceResolveCapabilityCheck_FallbackAndUnknown(t *testing.T) {
+	svc := newModelCapabilityGatewayServiceForTest()
+	group := &Group{ID: 1, CapabilityCheck: GroupCapabilityCheckConfig{
+		Enabled:        true,
+		FallbackModels: map[string]string{"claude-instant-*": "claude-sonnet-4-5"},
+	}}
+	reqs := DetectAnthropicCapabilityRequirements([]byte(capabilityImageBody))
+
+	decision, err := svc.ResolveCapabilityCheck(context.Background(), group, "claude-instant-1", reqs)
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	require.Equal(t, "claude-sonnet-4-5", decision.RoutedModel)
+	require.Equal(t, ModelCapabilityVision, decision.Missing.Capability)
+
+	// 能力未知的模型放行
+	decision, err = svc.ResolveCapabilityCheck(context.Background(), group, "legacy-text", reqs)
+	require.NoError(t, err)
+	require.Nil(t, decision)
+}
+
+func TestGatewayServiceResolveCapabilityCheck_ConfigOverrides(t *testing.T) {
+	yes, no := true, false
+	svc := newModelCapabilityGatewayServiceForTest(
+		config.GatewayModelCapabilityOverride{Model: "legacy-*", Vision: &no},
+		config.GatewayModelCapabilityOverride{Model: "claude-instant-1", Vision: &yes},
+	)
+	group := &Group{ID: 1, CapabilityCheck: GroupCapabilityCheckConfig{Enabled: true}}
+	reqs := DetectAnthropicCapabilityRequirements([]byte(capabilityImageBody))
+
+	_, err := svc.ResolveCapabilityCheck(context.Background(), group, "legacy-text", reqs)
+	require.Equal(t, "MODEL_CAPABILITY_UNSUPPORTED", infraerrors.Reason(err))
+
+	decision, err := svc.ResolveCapabilityCheck(context.Background(), group, "claude-instant-1", reqs)
+	require.NoError(t, err)
+	require.Nil(t, decision, "override marks vision as supported")
+}
+
+func TestNormalizeGroupCapabilityCheckConfig(t *testing.T) {
+	cfg, err := normalizeGroupCapabilityCheckConfig(GroupCapabilityCheckConfig{Enabled: true, FallbackModels: map[string]string{" claude-instant-1 ": " claude-sonnet-4-5 "}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"claude-instant-1": "claude-sonnet-4-5"}, cfg.FallbackModels)
+
+	_, err = normalizeGroupCapabilityCheckConfig(GroupCapabilityCheckConfig{FallbackModels: map[string]string{"claude-instant-1": ""}})
+	require.Equal(t, "INVALID_CAPABILITY_CHECK", infraerrors.Reason(err))
+}
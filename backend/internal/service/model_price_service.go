@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// modelPriceIndexTTL 价格表本地缓存时长；管理端修改会立即失效本地缓存。
+	modelPriceIndexTTL      = 30 * time.Second
+	modelPriceLoadTimeout   = 3 * time.Second
+	modelPriceBackfillBatch = 500
+	// modelPriceBackfillMaxRange 单次回填允许的最大时间跨度
+	modelPriceBackfillMaxRange = 366 * 24 * time.Hour
+)
+
+var (
+	ErrModelPriceNotFound        = infraerrors.NotFound("MODEL_PRICE_NOT_FOUND", "model price not found")
+	ErrModelPriceExists          = infraerrors.Conflict("MODEL_PRICE_EXISTS", "a price for this model with the same effective_from already exists")
+	ErrModelPriceInvalid         = infraerrors.BadRequest("MODEL_PRICE_INVALID", "model is required and prices must not be negative")
+	ErrModelPriceInvalidRange    = infraerrors.BadRequest("MODEL_PRICE_INVALID_RANGE", "effective_to must be later than effective_from")
+	ErrModelPriceBackfillRunning = infraerrors.Conflict("MODEL_PRICE_BACKFILL_RUNNING", "a usage cost backfill is already running")
+	ErrModelPriceBackfillRange   = infraerrors.BadRequest("MODEL_PRICE_BACKFILL_INVALID_RANGE", "backfill range must be non-empty and at most 366 days")
+)
+
+// ModelPrice 管理员维护的模型单价（USD / token），在 [EffectiveFrom, EffectiveTo) 内生效
+type ModelPrice struct {
+	ID              int64      `json:"id"`
+	Model           string     `json:"model"`
+	InputPrice      float64    `json:"input_price"`
+	OutputPrice     float64    `json:"output_price"`
+	CacheWritePrice float64    `json:"cache_write_price"`
+	CacheReadPrice  float64    `json:"cache_read_price"`
+	EffectiveFrom   time.Time  `json:"effective_from"`
+	EffectiveTo     *time.Time `json:"effective_to,omitempty"`
+	Note            string     `json:"note"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// activeAt 价格在 at 时刻是否生效
+func (p *ModelPrice) activeAt(at time.Time) bool {
+	if at.Before(p.EffectiveFrom) {
+		return false
+	}
+	return p.EffectiveTo == nil || at.Before(*p.EffectiveTo)
+}
+
+// toModelPricing 转换为计费使用的价格配置。价格表不区分 priority 档位与缓存 TTL。
+func (p *ModelPrice) toModelPricing() *ModelPricing {
+	return &ModelPricing{
+		InputPricePerToken:         p.InputPrice,
+		OutputPricePerToken:        p.OutputPrice,
+		CacheCreationPricePerToken: p.CacheWritePrice,
+		CacheCreationPriceExplicit: true,
+		CacheReadPricePerToken:     p.CacheReadPrice,
+		CacheCreation5mPrice:       p.CacheWritePrice,
+		CacheCreation1hPrice:       p.CacheWritePrice,
+	}
+}
+
+// UsageCostRow 回填所需的使用日志字段
+type UsageCostRow struct {
+	ID                  int64
+	Model               string
+	CreatedAt           time.Time
+	InputTokens         int
+	OutputTokens        int
+	CacheCreationTokens int
+	CacheReadTokens     int
+	RateMultiplier      float64
+}
+
+// UsageCostUpdate 回填写回的费用
+type UsageCostUpdate struct {
+	ID                int64
+	InputCost         float64
+	OutputCost        float64
+	CacheCreationCost float64
+	CacheReadCost     float64
+	TotalCost         float64
+	ActualCost        float64
+}
+
+// ModelPriceRepository 模型价格数据访问接口
+type ModelPriceRepository interface {
+	Create(ctx context.Context, price *ModelPrice) (*ModelPrice, error)
+	GetByID(ctx context.Context, id int64) (*ModelPrice, error)
+	Update(ctx context.Context, price *ModelPrice) (*ModelPrice, error)
+	Delete(ctx context.Context, id int64) error
+	// List 按模型过滤价格，model 为空时返回全部。
+	List(ctx context.Context, model string) ([]*ModelPrice, error)
+	// ListUsageForBackfill 按 ID 升序分页读取 [start, end) 内按 token 计费的使用日志，model 为空时不过滤模型。
+	ListUsageForBackfill(ctx context.Context, start, end time.Time, model string, afterID int64, limit int) ([]UsageCostRow, error)
+	// UpdateUsageCosts 批量写回使用日志费用
+	UpdateUsageCosts(ctx context.Context, updates []UsageCostUpdate) error
+}
+
+// ModelPriceBackfillRequest 历史使用日志费用回填参数
+type ModelPriceBackfillRequest struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Model     string    `json:"model,omitempty"`
+}
+
+// ModelPriceBackfillStatus 回填任务状态（仅保留本实例最近一次任务）
+type ModelPriceBackfillStatus struct {
+	Running    bool                      `json:"running"`
+	Request    ModelPriceBackfillRequest `json:"request"`
+	Scanned    int64                     `json:"scanned"`
+	Updated    int64                     `json:"updated"`
+	Skipped    int64                     `json:"skipped"`
+	StartedAt  time.Time                 `json:"started_at"`
+	FinishedAt *time.Time                `json:"finished_at,omitempty"`
+	Error      string                    `json:"error,omitempty"`
+}
+
+// ModelPriceService 模型价格服务：维护按生效时间区分的模型单价，作为计费的最高优先级基础价格，
+// 并支持按历史价格回填使用日志费用。
+type ModelPriceService struct {
+	repo ModelPriceRepository
+
+	indexMu       sync.RWMutex
+	index         map[string][]*ModelPrice // model -> 按 EffectiveFrom 降序
+	indexLoadedAt time.Time
+	indexSF       singleflight.Group
+
+	backfillMu     sync.Mutex
+	backfillStatus *ModelPriceBackfillStatus
+}
+
+// NewModelPriceService 创建模型价格服务
+func NewModelPriceService(repo ModelPriceRepository) *ModelPriceService {
+	return &ModelPriceService{repo: repo}
+}
+
+func normalizeModelPrice(price *ModelPrice) error {
+	price.Model = strings.ToLower(strings.TrimSpace(price.Model))
+	price.Note = strings.TrimSpace(price.Note)
+	if price.Model == "" || price.InputPrice < 0 || price.OutputPrice < 0 ||
+		price.CacheWritePrice < 0 || price.CacheReadPrice < 0 {
+		return ErrModelPriceInvalid
+	}
+	if price.EffectiveFrom.IsZero() {
+		price.EffectiveFrom = time.Now()
+	}
+	if price.EffectiveTo != nil && !price.EffectiveTo.After(price.EffectiveFrom) {
+		return ErrModelPriceInvalidRange
+	}
+	return nil
+}
+
+// List 列出价格，model 为空时返回全部
+func (s *ModelPriceService) List(ctx context.Context, model string) ([]*ModelPrice, error) {
+	return s.repo.List(ctx, strings.ToLower(strings.TrimSpace(model)))
+}
+
+// Create 创建价格
+func (s *ModelPriceService) Create(ctx context.Context, price *ModelPrice) (*ModelPrice, error) {
+	if err := normalizeModelPrice(price); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, price)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateIndex()
+	return created, nil
+}
+
+// Update 整体更新价格
+func (s *ModelPriceService) Update(ctx context.Context, id int64, price *ModelPrice) (*ModelPrice, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	price.ID = id
+	if err := normalizeModelPrice(price); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(ctx, price)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateIndex()
+	return updated, nil
+}
+
+// Delete 删除价格
+func (s *ModelPriceService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateIndex()
+	return nil
+}
+
+func (s *ModelPriceService) invalidateIndex() {
+	s.indexMu.Lock()
+	s.indexLoadedAt = time.Time{}
+	s.indexMu.Unlock()
+}
+
+// loadIndex 返回按模型分组的价格表；缓存过期时重新加载，加载失败时沿用旧数据。
+func (s *ModelPriceService) loadIndex(ctx context.Context) (map[string][]*ModelPrice, error) {
+	s.indexMu.RLock()
+	index, loadedAt := s.index, s.indexLoadedAt
+	s.indexMu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < modelPriceIndexTTL {
+		return index, nil
+	}
+
+	v, err, _ := s.indexSF.Do("index", func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), modelPriceLoadTimeout)
+		defer cancel()
+		prices, err := s.repo.List(loadCtx, "")
+		if err != nil {
+			return nil, err
+		}
+		next := buildModelPriceIndex(prices)
+		s.indexMu.Lock()
+		s.index = next
+		s.indexLoadedAt = time.Now()
+		s.indexMu.Unlock()
+		return next, nil
+	})
+	if err != nil {
+		return index, err
+	}
+	return v.(map[string][]*ModelPrice), nil
+}
+
+func buildModelPriceIndex(prices []*ModelPrice) map[string][]*ModelPrice {
+	index := make(map[string][]*ModelPrice)
+	for _, price := range prices {
+		index[price.Model] = append(index[price.Model], price)
+	}
+	for _, list := range index {
+		sort.Slice(list, func(i, j int) bool { return list[i].EffectiveFrom.After(list[j].EffectiveFrom) })
+	}
+	return index
+}
+
+// priceFromIndex 在 at 时刻生效的价格；多条重叠时取生效起始时间最晚的一条。
+func priceFromIndex(index map[string][]*ModelPrice, model string, at time.Time) *ModelPrice {
+	for _, price := range index[strings.ToLower(strings.TrimSpace(model))] {
+		if price.activeAt(at) {
+			return price
+		}
+	}
+	return nil
+}
+
+// PricingAt 返回模型在 at 时刻的价格表定价，未配置时返回 nil。
+// 价格表加载失败时沿用旧数据或返回 nil（回退到 LiteLLM / 内置价格），不阻塞计费。
+func (s *ModelPriceService) PricingAt(ctx context.Context, model string, at time.Time) *ModelPricing {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	index, err := s.loadIndex(ctx)
+	if err != nil {
+		slog.Warn("model price table load failed", "error", err)
+	}
+	if price := priceFromIndex(index, model, at); price != nil {
+		return price.toModelPricing()
+	}
+	return nil
+}
+
+// StartBackfill 在后台按历史价格重新计算 [start, end) 内使用日志的费用。
+// 仅更新使用日志中的费用字段用于成本报表，不会对用户余额或订阅用量做任何补扣或退款。
+func (s *ModelPriceService) StartBackfill(req ModelPriceBackfillRequest) (*ModelPriceBackfillStatus, error) {
+	req.Model = strings.ToLower(strings.TrimSpace(req.Model))
+	if !req.EndTime.After(req.StartTime) || req.EndTime.Sub(req.StartTime) > modelPriceBackfillMaxRange {
+		return nil, ErrModelPriceBackfillRange
+	}
+
+	s.backfillMu.Lock()
+	if s.backfillStatus != nil && s.backfillStatus.Running {
+		s.backfillMu.Unlock()
+		return nil, ErrModelPriceBackfillRunning
+	}
+	s.backfillStatus = &ModelPriceBackfillStatus{Running: true, Request: req, StartedAt: time.Now()}
+	snapshot := *s.backfillStatus
+	s.backfillMu.Unlock()
+
+	go s.runBackfill(context.Background(), req)
+	return &snapshot, nil
+}
+
+// GetBackfillStatus 返回最近一次回填任务状态，从未执行时返回 nil
+func (s *ModelPriceService) GetBackfillStatus() *ModelPriceBackfillStatus {
+	s.backfillMu.Lock()
+	defer s.backfillMu.Unlock()
+	if s.backfillStatus == nil {
+		return nil
+	}
+	snapshot := *s.backfillStatus
+	return &snapshot
+}
+
+func (s *ModelPriceService) runBackfill(ctx context.Context, req ModelPriceBackfillRequest) {
+	err := s.backfill(ctx, req)
+
+	s.backfillMu.Lock()
+	defer s.backfillMu.Unlock()
+	now := time.Now()
+	s.backfillStatus.Running = false
+	s.backfillStatus.FinishedAt = &now
+	if err != nil {
+		s.backfillStatus.Error = err.Error()
+		slog.Error("model price usage cost backfill failed", "error", err)
+		return
+	}
+	slog.Info("model price usage cost backfill finished",
+		"scanned", s.backfillStatus.Scanned, "updated", s.backfillStatus.Updated, "skipped", s.backfillStatus.Skipped)
+}
+
+func (s *ModelPriceService) backfill(ctx context.Context, req ModelPriceBackfillRequest) error {
+	prices, err := s.repo.List(ctx, req.Model)
+	if err != nil {
+		return err
+	}
+	index := buildModelPriceIndex(prices)
+
+	var afterID int64
+	for {
+		rows, err := s.repo.ListUsageForBackfill(ctx, req.StartTime, req.EndTime, req.Model, afterID, modelPriceBackfillBatch)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		updates := make([]UsageCostUpdate, 0, len(rows))
+		for _, row := range rows {
+			if price := priceFromIndex(index, row.Model, row.CreatedAt); price != nil {
+				updates = append(updates, computeUsageCost(row, price))
+			}
+		}
+		if len(updates) > 0 {
+			if err := s.repo.UpdateUsageCosts(ctx, updates); err != nil {
+				return err
+			}
+		}
+		afterID = rows[len(rows)-1].ID
+
+		s.backfillMu.Lock()
+		s.backfillStatus.Scanned += int64(len(rows))
+		s.backfillStatus.Updated += int64(len(updates))
+		s.backfillStatus.Skipped += int64(len(rows) - len(updates))
+		s.backfillMu.Unlock()
+
+		if len(rows) < modelPriceBackfillBatch {
+			return nil
+		}
+	}
+}
+
+// computeUsageCost 按价格表单价计算单条日志费用，实际费用沿用日志记录的倍率
+func computeUsageCost(row UsageCostRow, price *ModelPrice) UsageCostUpdate {
+	update := UsageCostUpdate{
+		ID:                row.ID,
+		InputCost:         float64(row.InputTokens) * price.InputPrice,
+		OutputCost:        float64(row.OutputTokens) * price.OutputPrice,
+		CacheCreationCost: float64(row.CacheCreationTokens) * price.CacheWritePrice,
+		CacheReadCost:     float64(row.CacheReadTokens) * price.CacheReadPrice,
+	}
+	update.TotalCost = update.InputCost + update.OutputCost + update.CacheCreationCost + update.CacheReadCost
+	update.ActualCost = update.TotalCost * row.RateMultiplier
+	return update
+}
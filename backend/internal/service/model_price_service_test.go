@@ -0,0 +1,152 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type modelPriceRepoStub struct {
+	prices    []*ModelPrice
+	usage     []UsageCostRow
+	updates   []UsageCostUpdate
+	listCalls int
+}
+
+func (r *modelPriceRepoStub) Create(_ context.Context, price *ModelPrice) (*ModelPrice, error) {
+	price.ID = int64(len(r.prices) + 1)
+	r.prices = append(r.prices, price)
+	return price, nil
+}
+
+func (r *modelPriceRepoStub) GetByID(_ context.Context, id int64) (*ModelPrice, error) {
+	for _, p := range r.prices {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, ErrModelPriceNotFound
+}
+
+func (r *modelPriceRepoStub) Update(_ context.Context, price *ModelPrice) (*ModelPrice, error) {
+	return price, nil
+}
+
+func (r *modelPriceRepoStub) Delete(context.Context, int64) error { return nil }
+
+func (r *modelPriceRepoStub) List(_ context.Context, model string) ([]*ModelPrice, error) {
+	r.listCalls++
+	out := []*ModelPrice{}
+	for _, p := range r.prices {
+		if model == "" || p.Model == model {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (r *modelPriceRepoStub) ListUsageForBackfill(_ context.Context, _, _ time.Time, _ string, afterID int64, limit int) ([]UsageCostRow, error) {
+	out := []UsageCostRow{}
+	for _, row := range r.usage {
+		if row.ID > afterID && len(out) < limit {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (r *modelPriceRepoStub) UpdateUsageCosts(_ context.Context, updates []UsageCostUpdate) error {
+	r.updates = append(r.updates, updates...)
+	return nil
+}
+
+func TestModelPriceService_PricingAtHonorsEffectiveDates(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := &modelPriceRepoStub{prices: []*ModelPrice{
+		{ID: 1, Model: "claude-sonnet-4-6", InputPrice: 2e-6, OutputPrice: 10e-6, EffectiveFrom: jan, EffectiveTo: &jun},
+		{ID: 2, Model: "claude-sonnet-4-6", InputPrice: 3e-6, OutputPrice: 15e-6, EffectiveFrom: jun},
+	}}
+	svc := NewModelPriceService(repo)
+	ctx := context.Background()
+
+	require.Nil(t, svc.PricingAt(ctx, "claude-sonnet-4-6", jan.Add(-time.Hour)))
+	require.InDelta(t, 2e-6, svc.PricingAt(ctx, "claude-sonnet-4-6", jan).InputPricePerToken, 1e-12)
+	require.InDelta(t, 3e-6, svc.PricingAt(ctx, "CLAUDE-SONNET-4-6", jun).InputPricePerToken, 1e-12)
+	require.Nil(t, svc.PricingAt(ctx, "gpt-5", jun))
+	require.Equal(t, 1, repo.listCalls, "price table should be cached between lookups")
+
+	_, err := svc.Create(ctx, &ModelPrice{Model: " GPT-5 ", InputPrice: 1e-6, EffectiveFrom: jan})
+	require.NoError(t, err)
+	require.NotNil(t, svc.PricingAt(ctx, "gpt-5", jun))
+	require.Equal(t, 2, repo.listCalls)
+}
+
+func TestModelPriceService_CreateValidates(t *testing.T) {
+	svc := NewModelPriceService(&modelPriceRepoStub{})
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := svc.Create(context.Background(), &ModelPrice{Model: "m", InputPrice: -1})
+	require.ErrorIs(t, err, ErrModelPriceInvalid)
+	_, err = svc.Create(context.Background(), &ModelPrice{Model: "m", EffectiveFrom: from, EffectiveTo: &from})
+	require.ErrorIs(t, err, ErrModelPriceInvalidRange)
+}
+
+func TestBillingService_ModelPriceTableTakesPrecedence(t *testing.T) {
+	repo := &modelPriceRepoStub{prices: []*ModelPrice{
+		{ID: 1, Model: "claude-sonnet-4", InputPrice: 1e-6, OutputPrice: 2e-6, CacheWritePrice: 1.5e-6, CacheReadPrice: 0.1e-6, EffectiveFrom: time.Now().Add(-time.Hour)},
+	}}
+	billing := NewBillingService(nil, nil)
+	billing.SetModelPriceService(NewModelPriceService(repo))
+
+	pricing, err := billing.GetModelPricing("claude-sonnet-4")
+	require.NoError(t, err)
+	require.InDelta(t, 1e-6, pricing.InputPricePerToken, 1e-12)
+	require.InDelta(t, 1.5e-6, pricing.CacheCreationPricePerToken, 1e-12)
+
+	resolved := NewModelPricingResolver(nil, billing).Resolve(context.Background(), PricingInput{Model: "claude-sonnet-4"})
+	require.Equal(t, PricingSourceModelPrice, resolved.Source)
+	require.InDelta(t, 2e-6, resolved.BasePricing.OutputPricePerToken, 1e-12)
+}
+
+func TestModelPriceService_BackfillRecomputesPricedRows(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &modelPriceRepoStub{
+		prices: []*ModelPrice{{ID: 1, Model: "m", InputPrice: 1e-6, OutputPrice: 2e-6, CacheReadPrice: 0.5e-6, EffectiveFrom: jan}},
+		usage: []UsageCostRow{
+			{ID: 1, Model: "m", CreatedAt: jan.Add(time.Hour), InputTokens: 1000, OutputTokens: 500, CacheReadTokens: 2000, RateMultiplier: 1.5},
+			{ID: 2, Model: "m", CreatedAt: jan.Add(-time.Hour), InputTokens: 1000},
+			{ID: 3, Model: "other", CreatedAt: jan.Add(time.Hour), InputTokens: 1000},
+		},
+	}
+	svc := NewModelPriceService(repo)
+	svc.backfillStatus = &ModelPriceBackfillStatus{Running: true}
+
+	svc.runBackfill(context.Background(), ModelPriceBackfillRequest{StartTime: jan.Add(-24 * time.Hour), EndTime: jan.Add(24 * time.Hour)})
+
+	status := svc.GetBackfillStatus()
+	require.False(t, status.Running)
+	require.Empty(t, status.Error)
+	require.Equal(t, int64(3), status.Scanned)
+	require.Equal(t, int64(1), status.Updated)
+	require.Equal(t, int64(2), status.Skipped)
+	require.Len(t, repo.updates, 1)
+	require.InDelta(t, 0.003, repo.updates[0].TotalCost, 1e-12)
+	require.InDelta(t, 0.0045, repo.updates[0].ActualCost, 1e-12)
+}
+
+func TestModelPriceService_StartBackfillRejectsInvalidRangeAndConcurrentRuns(t *testing.T) {
+	svc := NewModelPriceService(&modelPriceRepoStub{})
+	now := time.Now()
+
+	_, err := svc.StartBackfill(ModelPriceBackfillRequest{StartTime: now, EndTime: now})
+	require.ErrorIs(t, err, ErrModelPriceBackfillRange)
+
+	svc.backfillStatus = &ModelPriceBackfillStatus{Running: true}
+	_, err = svc.StartBackfill(ModelPriceBackfillRequest{StartTime: now.Add(-time.Hour), EndTime: now})
+	require.ErrorIs(t, err, ErrModelPriceBackfillRunning)
+}
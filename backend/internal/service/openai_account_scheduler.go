@@ -425,6 +425,20 @@ func (s *defaultOpenAIAccountScheduler) selectBySessionHash(
 		_ = s.service.deleteStickySessionAccountID(ctx, req.GroupID, sessionHash)
 		return nil, false, nil
 	}
+	if s.service.usageWindows.IsNearLimit(ctx, account) {
+		slog.Info("sticky_escape_triggered",
+			"account_id", accountID,
+			"reason", "usage_window_near_limit",
+		)
+		return nil, true, nil
+	}
+	if s.service.failoverTiers.stickyDemoted(req.GroupID, account) {
+		slog.Info("sticky_escape_triggered",
+			"account_id", accountID,
+			"reason", "failover_tier_recovered",
+		)
+		return nil, true, nil
+	}
 	escapeCfg := s.service.openAIStickyEscapeConfig()
 	if reason, errorRate, ttft, shouldEscape := s.shouldEscapeStickyAccount(accountID, escapeCfg); shouldEscape {
 		slog.Info("sticky_escape_triggered",
@@ -520,6 +534,8 @@ type openAIAccountCandidateScore struct {
 	errorRate float64
 	ttft      float64
 	hasTTFT   bool
+	// latencyDegraded 账号×请求模型违反延迟 SLO，排序时置于健康账号之后
+	latencyDegraded bool
 }
 
 type openAIAccountCandidateHeap []openAIAccountCandidateScore
@@ -731,11 +747,12 @@ func (s *defaultOpenAIAccountScheduler) buildOpenAIAccountLoadPlan(
 			errorRate, ttft, hasTTFT = s.stats.snapshot(account.ID)
 		}
 		allCandidates = append(allCandidates, openAIAccountCandidateScore{
-			account:   account,
-			loadInfo:  loadInfo,
-			errorRate: errorRate,
-			ttft:      ttft,
-			hasTTFT:   hasTTFT,
+			account:         account,
+			loadInfo:        loadInfo,
+			errorRate:       errorRate,
+			ttft:            ttft,
+			hasTTFT:         hasTTFT,
+			latencyDegraded: s.service.latencySLO.IsDegraded(account.ID, req.RequestedModel),
 		})
 	}
 
@@ -893,7 +910,7 @@ func (s *defaultOpenAIAccountScheduler) buildOpenAISelectionOrder(
 	req OpenAIAccountScheduleRequest,
 	plan openAIAccountLoadPlan,
 ) []openAIAccountCandidateScore {
-	buildSelectionOrder := func(pool []openAIAccountCandidateScore) []openAIAccountCandidateScore {
+	buildRankedOrder := func(pool []openAIAccountCandidateScore) []openAIAccountCandidateScore {
 		if len(pool) == 0 || plan.topK <= 0 {
 			return nil
 		}
@@ -918,6 +935,22 @@ func (s *defaultOpenAIAccountScheduler) buildOpenAISelectionOrder(
 		}
 		return buildOpenAIWeightedSelectionOrder(ranked, req)
 	}
+	// 违反延迟 SLO 的账号单独排序并追加在健康账号之后：降权而非剔除
+	buildSelectionOrder := func(pool []openAIAccountCandidateScore) []openAIAccountCandidateScore {
+		healthy := make([]openAIAccountCandidateScore, 0, len(pool))
+		var degraded []openAIAccountCandidateScore
+		for _, candidate := range pool {
+			if candidate.latencyDegraded {
+				degraded = append(degraded, candidate)
+			} else {
+				healthy = append(healthy, candidate)
+			}
+		}
+		if len(degraded) == 0 || len(healthy) == 0 {
+			return buildRankedOrder(pool)
+		}
+		return append(buildRankedOrder(healthy), buildRankedOrder(degraded)...)
+	}
 
 	if req.RequireCompact {
 		supported := make([]openAIAccountCandidateScore, 0, len(plan.candidates))
@@ -1097,7 +1130,6 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 	}
 
 	filtered := make([]*Account, 0, len(accounts))
-	loadReq := make([]AccountWithConcurrency, 0, len(accounts))
 	for i := range accounts {
 		account := &accounts[i]
 		if req.ExcludedIDs != nil {
@@ -1125,14 +1157,14 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 			continue
 		}
 		filtered = append(filtered, account)
-		loadReq = append(loadReq, AccountWithConcurrency{
-			ID:             account.ID,
-			MaxConcurrency: account.EffectiveLoadFactor(),
-		})
 	}
 	if len(filtered) == 0 {
 		return nil, 0, 0, 0, noAvailableOpenAISelectionError(req.RequestedModel, false)
 	}
+	filtered = excludeOpenAIUsageWindowNearLimit(filtered, s.service.usageWindows.NearLimitAccounts(ctx, filtered))
+	// 分组故障转移层级：仅当更高层级账号全部不可用时才启用更低层级
+	filtered = s.service.failoverTiers.filter(req.GroupID, filtered)
+	loadReq := buildOpenAIAccountLoadRequest(filtered)
 
 	loadMap := map[int64]*AccountLoadInfo{}
 	if s.service.concurrencyService != nil {
@@ -1191,6 +1223,21 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 	return s.finishLoadBalanceSelectionFallback(ctx, req, attempt)
 }
 
+// excludeOpenAIUsageWindowNearLimit 剔除接近用量窗口上限的账号；全部接近上限时保留原列表（计数为估算值，交由上游裁决）。
+func excludeOpenAIUsageWindowNearLimit(accounts []*Account, nearLimit map[int64]struct{}) []*Account {
+	if len(nearLimit) == 0 || len(nearLimit) >= len(accounts) {
+		return accounts
+	}
+	kept := make([]*Account, 0, len(accounts)-len(nearLimit))
+	for _, account := range accounts {
+		if _, near := nearLimit[account.ID]; near {
+			continue
+		}
+		kept = append(kept, account)
+	}
+	return kept
+}
+
 func partitionOpenAIChatGPTSubscriptionAccounts(accounts []*Account) ([]*Account, []*Account) {
 	subscriptionAccounts := make([]*Account, 0, len(accounts))
 	regularAccounts := make([]*Account, 0, len(accounts))
@@ -1369,6 +1416,9 @@ func (s *defaultOpenAIAccountScheduler) isAccountRequestCompatible(ctx context.C
 	if s != nil && s.service != nil && s.service.isOpenAIAccountRuntimeBlocked(account) {
 		return false
 	}
+	if !account.ServesRequestClass(RequestClassFromContext(ctx)) {
+		return false
+	}
 	// Quota auto-pause must be evaluated during the initial filter too. Without it the
 	// TopK candidate pool can be filled with paused accounts and the later fresh/DB
 	// rechecks won't reach healthy accounts that fell outside TopK — manifesting as
@@ -0,0 +1,561 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const (
+	openAIAudioTranscriptionsEndpoint = "/v1/audio/transcriptions"
+	openAIAudioSpeechEndpoint         = "/v1/audio/speech"
+
+	openAIAudioStreamChunkSize = 32 << 10
+)
+
+// OpenAIAudioTranscriptionRequest 是 /v1/audio/transcriptions multipart 表单中与路由/计费相关的字段。
+// 音频文件本身不解析，原样转发。
+type OpenAIAudioTranscriptionRequest struct {
+	Model          string
+	ResponseFormat string
+	Stream         bool
+	HasFile        bool
+}
+
+// ParseOpenAIAudioTranscriptionRequest 解析 multipart 表单中的非文件字段。
+func ParseOpenAIAudioTranscriptionRequest(body []byte, contentType string) (*OpenAIAudioTranscriptionRequest, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.EqualFold(mediaType, "multipart/form-data") {
+		return nil, fmt.Errorf("content-type must be multipart/form-data")
+	}
+	boundary := strings.TrimSpace(params["boundary"])
+	if boundary == "" {
+		return nil, fmt.Errorf("multipart boundary is required")
+	}
+
+	req := &OpenAIAudioTranscriptionRequest{}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read multipart body: %w", err)
+		}
+		name := strings.TrimSpace(part.FormName())
+		if part.FileName() != "" {
+			if name == "file" {
+				req.HasFile = true
+			}
+			_ = part.Close()
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, 4096))
+		_ = part.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read multipart field %s: %w", name, err)
+		}
+		switch name {
+		case "model":
+			req.Model = strings.TrimSpace(string(value))
+		case "response_format":
+			req.ResponseFormat = strings.ToLower(strings.TrimSpace(string(value)))
+		case "stream":
+			req.Stream, _ = strconv.ParseBool(strings.TrimSpace(string(value)))
+		}
+	}
+	return req, nil
+}
+
+// ForwardAudioTranscription 转发 /v1/audio/transcriptions。
+// 计费优先使用上游回传的 token 用量（gpt-4o-transcribe 系列），否则按音频秒数（whisper-1）。
+// response_format=text 时上游改为 json 以取得时长，再还原为纯文本返回给客户端。
+func (s *OpenAIGatewayService) ForwardAudioTranscription(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	contentType string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	parsed, err := ParseOpenAIAudioTranscriptionRequest(body, contentType)
+	if err != nil {
+		writeOpenAIAudioError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return nil, err
+	}
+	if parsed.Model == "" {
+		writeOpenAIAudioError(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return nil, fmt.Errorf("missing model in request")
+	}
+
+	billingModel := resolveOpenAIForwardModel(account, parsed.Model, defaultMappedModel)
+	upstreamModel := normalizeOpenAIModelForUpstream(account, billingModel)
+	overrides := map[string]string{}
+	if upstreamModel != parsed.Model {
+		overrides["model"] = upstreamModel
+	}
+	textToJSON := parsed.ResponseFormat == "text" && !parsed.Stream
+	if textToJSON {
+		overrides["response_format"] = "json"
+	}
+	upstreamBody, upstreamContentType := body, contentType
+	if len(overrides) > 0 {
+		upstreamBody, upstreamContentType, err = rewriteMultipartFormFields(body, contentType, overrides)
+		if err != nil {
+			writeOpenAIAudioError(c, http.StatusBadRequest, "invalid_request_error", "Failed to rewrite multipart body")
+			return nil, err
+		}
+	}
+
+	logger.L().Debug("openai audio transcription: forwarding",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", parsed.Model),
+		zap.String("billing_model", billingModel),
+		zap.String("upstream_model", upstreamModel),
+		zap.String("response_format", parsed.ResponseFormat),
+		zap.Bool("stream", parsed.Stream),
+	)
+
+	resp, upstreamRetries, err := s.doOpenAIAudioRequest(ctx, c, account, openAIAudioTranscriptionsEndpoint, upstreamBody, upstreamContentType, "application/json", upstreamModel)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	result := &OpenAIForwardResult{
+		RequestID:       firstNonEmptyString(resp.Header.Get("x-request-id"), resp.Header.Get("request-id")),
+		Model:           parsed.Model,
+		BillingModel:    billingModel,
+		UpstreamModel:   upstreamModel,
+		Stream:          parsed.Stream,
+		UpstreamRetries: upstreamRetries,
+	}
+
+	if isEventStreamResponse(resp.Header) {
+		usage, seconds, firstTokenMs, clientDisconnected, err := s.streamOpenAIAudioResponse(c, resp, startTime)
+		if err != nil {
+			return nil, fmt.Errorf("stream upstream body: %w", err)
+		}
+		result.Usage = usage
+		result.AudioDurationSeconds = seconds
+		result.FirstTokenMs = firstTokenMs
+		result.ClientDisconnect = clientDisconnected
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	respBody, err := ReadUpstreamResponseBody(resp.Body, s.cfg, c, openAITooLargeError)
+	if err != nil {
+		if !errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
+			writeOpenAIAudioError(c, http.StatusBadGateway, "api_error", "Failed to read upstream response")
+		}
+		return nil, fmt.Errorf("read upstream body: %w", err)
+	}
+	result.Usage, result.AudioDurationSeconds = extractOpenAIAudioUsage(respBody)
+	if result.AudioDurationSeconds <= 0 && !hasOpenAIAudioTokenUsage(result.Usage) {
+		result.AudioDurationSeconds = subtitleDurationSeconds(respBody)
+	}
+
+	if textToJSON && gjson.ValidBytes(respBody) {
+		if text := gjson.GetBytes(respBody, "text"); text.Exists() {
+			resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+			resp.Header.Del("Content-Length")
+			respBody = []byte(text.String() + "\n")
+		}
+	}
+	writeOpenAIAudioUpstreamResponse(c, resp, respBody, s.responseHeaderFilter)
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// ForwardAudioSpeech 转发 /v1/audio/speech，音频流边读边写给客户端。
+// 按输入文本字符数计费；stream_format=sse 且上游回传 token 用量时按 token 计费。
+func (s *OpenAIGatewayService) ForwardAudioSpeech(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	originalModel := strings.TrimSpace(gjson.GetBytes(body, "model").String())
+	if originalModel == "" {
+		writeOpenAIAudioError(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return nil, fmt.Errorf("missing model in request")
+	}
+	characters := utf8.RuneCountInString(gjson.GetBytes(body, "input").String())
+
+	billingModel := resolveOpenAIForwardModel(account, originalModel, defaultMappedModel)
+	upstreamModel := normalizeOpenAIModelForUpstream(account, billingModel)
+	upstreamBody := body
+	if upstreamModel != originalModel {
+		upstreamBody = ReplaceModelInBody(body, upstreamModel)
+	}
+
+	logger.L().Debug("openai audio speech: forwarding",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("billing_model", billingModel),
+		zap.String("upstream_model", upstreamModel),
+		zap.Int("characters", characters),
+	)
+
+	resp, upstreamRetries, err := s.doOpenAIAudioRequest(ctx, c, account, openAIAudioSpeechEndpoint, upstreamBody, "application/json", "*/*", upstreamModel)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	usage, _, firstTokenMs, clientDisconnected, err := s.streamOpenAIAudioResponse(c, resp, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("stream upstream body: %w", err)
+	}
+	result := &OpenAIForwardResult{
+		RequestID:        firstNonEmptyString(resp.Header.Get("x-request-id"), resp.Header.Get("request-id")),
+		Usage:            usage,
+		Model:            originalModel,
+		BillingModel:     billingModel,
+		UpstreamModel:    upstreamModel,
+		Stream:           true,
+		Duration:         time.Since(startTime),
+		FirstTokenMs:     firstTokenMs,
+		ClientDisconnect: clientDisconnected,
+		UpstreamRetries:  upstreamRetries,
+	}
+	if !hasOpenAIAudioTokenUsage(usage) {
+		result.AudioCharacters = characters
+	}
+	return result, nil
+}
+
+// doOpenAIAudioRequest 发送上游请求并处理失败/failover；返回的响应状态码一定 < 400。
+func (s *OpenAIGatewayService) doOpenAIAudioRequest(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	endpoint string,
+	body []byte,
+	contentType string,
+	accept string,
+	upstreamModel string,
+) (*http.Response, int, error) {
+	apiKey := account.GetOpenAIApiKey()
+	if apiKey == "" {
+		return nil, 0, fmt.Errorf("account %d missing api_key", account.ID)
+	}
+	baseURL := account.GetOpenAIBaseURL()
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	validatedURL, err := s.validateUpstreamBaseURL(baseURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid base_url: %w", err)
+	}
+	targetURL := buildOpenAIEndpointURL(validatedURL, endpoint)
+
+	upstreamCtx, releaseUpstreamCtx := detachUpstreamContext(ctx)
+	upstreamReq, err := http.NewRequestWithContext(upstreamCtx, http.MethodPost, targetURL, bytes.NewReader(body))
+	releaseUpstreamCtx()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build upstream request: %w", err)
+	}
+	upstreamReq = upstreamReq.WithContext(WithHTTPUpstreamProfile(upstreamReq.Context(), HTTPUpstreamProfileOpenAI))
+	upstreamReq.Header.Set("Content-Type", contentType)
+	upstreamReq.Header.Set("Authorization", "Bearer "+apiKey)
+	upstreamReq.Header.Set("Accept", accept)
+	for key, values := range c.Request.Header {
+		if openaiCCRawAllowedHeaders[strings.ToLower(key)] {
+			for _, v := range values {
+				upstreamReq.Header.Add(key, v)
+			}
+		}
+	}
+	if customUA := account.GetOpenAIUserAgent(); customUA != "" {
+		upstreamReq.Header.Set("user-agent", customUA)
+	}
+	account.ApplyHeaderOverrides(upstreamReq.Header)
+
+	proxyURL := ""
+	if account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, retries, err := s.doOpenAIUpstreamWithRetry(ctx, c, account, upstreamReq, proxyURL, 0, nil)
+	if err != nil {
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		writeOpenAIAudioError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
+		return nil, 0, fmt.Errorf("upstream request failed: %s", safeErr)
+	}
+	if resp.StatusCode < 400 {
+		return resp, retries, nil
+	}
+
+	respBody := s.readUpstreamErrorBody(resp)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+	if s.shouldFailoverOpenAIUpstreamResponse(resp.StatusCode, upstreamMsg, respBody) {
+		upstreamDetail := ""
+		if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
+			maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+			if maxBytes <= 0 {
+				maxBytes = 2048
+			}
+			upstreamDetail = truncateString(string(respBody), maxBytes)
+		}
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: resp.StatusCode,
+			UpstreamRequestID:  resp.Header.Get("x-request-id"),
+			Kind:               "failover",
+			Message:            upstreamMsg,
+			Detail:             upstreamDetail,
+		})
+		s.handleOpenAIAccountUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody, upstreamModel)
+		return nil, 0, &UpstreamFailoverError{
+			StatusCode:             resp.StatusCode,
+			ResponseBody:           respBody,
+			RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
+		}
+	}
+	writeOpenAIAudioUpstreamResponse(c, resp, respBody, s.responseHeaderFilter)
+	return nil, 0, fmt.Errorf("upstream returned status %d", resp.StatusCode)
+}
+
+// streamOpenAIAudioResponse 将上游响应边读边写给客户端。SSE 按行转发并从事件中提取用量，
+// 其余（音频二进制）按块转发。客户端断开后 SSE 继续读完上游以取得用量，二进制直接停止。
+func (s *OpenAIGatewayService) streamOpenAIAudioResponse(
+	c *gin.Context,
+	resp *http.Response,
+	startTime time.Time,
+) (usage OpenAIUsage, seconds float64, firstTokenMs *int, clientDisconnected bool, err error) {
+	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		c.Writer.Header().Set("Content-Type", ct)
+	}
+	c.Writer.Header().Del("Content-Length")
+	c.Writer.WriteHeader(resp.StatusCode)
+	flusher, _ := c.Writer.(http.Flusher)
+
+	markFirstByte := func() {
+		if firstTokenMs == nil {
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+	}
+	write := func(data []byte) {
+		if clientDisconnected {
+			return
+		}
+		if _, writeErr := c.Writer.Write(data); writeErr != nil {
+			clientDisconnected = true
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if !isEventStreamResponse(resp.Header) {
+		buf := make([]byte, openAIAudioStreamChunkSize)
+		for !clientDisconnected {
+			n, readErr := resp.Body.Read(buf)
+			if n > 0 {
+				markFirstByte()
+				write(buf[:n])
+			}
+			if readErr == io.EOF {
+				return usage, seconds, firstTokenMs, clientDisconnected, nil
+			}
+			if readErr != nil {
+				return usage, seconds, firstTokenMs, clientDisconnected, readErr
+			}
+		}
+		return usage, seconds, firstTokenMs, clientDisconnected, nil
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			markFirstByte()
+			write(line)
+			if data, ok := extractOpenAISSEDataLine(strings.TrimRight(string(line), "\r\n")); ok && gjson.Valid(data) {
+				parsedUsage, parsedSeconds := extractOpenAIAudioUsage([]byte(data))
+				if hasOpenAIAudioTokenUsage(parsedUsage) {
+					usage = parsedUsage
+				}
+				if parsedSeconds > 0 {
+					seconds = parsedSeconds
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return usage, seconds, firstTokenMs, clientDisconnected, nil
+		}
+		if readErr != nil {
+			return usage, seconds, firstTokenMs, clientDisconnected, readErr
+		}
+	}
+}
+
+// extractOpenAIAudioUsage 解析音频接口的用量：usage.type=tokens 返回 token 用量，
+// usage.type=duration（或 verbose_json 顶层 duration）返回音频秒数。
+func extractOpenAIAudioUsage(body []byte) (OpenAIUsage, float64) {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return OpenAIUsage{}, 0
+	}
+	usage := gjson.GetBytes(body, "usage")
+	if usage.IsObject() {
+		if seconds := usage.Get("seconds").Float(); usage.Get("type").String() == "duration" && seconds > 0 {
+			return OpenAIUsage{}, seconds
+		}
+		parsed := OpenAIUsage{
+			InputTokens:  firstPositiveGJSONInt(usage.Get("input_tokens"), usage.Get("prompt_tokens")),
+			OutputTokens: firstPositiveGJSONInt(usage.Get("output_tokens"), usage.Get("completion_tokens")),
+		}
+		if hasOpenAIAudioTokenUsage(parsed) {
+			return parsed, 0
+		}
+	}
+	if seconds := gjson.GetBytes(body, "duration").Float(); seconds > 0 {
+		return OpenAIUsage{}, seconds
+	}
+	return OpenAIUsage{}, 0
+}
+
+func hasOpenAIAudioTokenUsage(usage OpenAIUsage) bool {
+	return usage.InputTokens > 0 || usage.OutputTokens > 0
+}
+
+var subtitleTimestampPattern = regexp.MustCompile(`(\d{1,2}):(\d{2}):(\d{2})[.,](\d{3})`)
+
+// subtitleDurationSeconds 从 srt/vtt 字幕中取最后一个时间戳作为音频时长。
+func subtitleDurationSeconds(body []byte) float64 {
+	matches := subtitleTimestampPattern.FindAllSubmatch(body, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	last := matches[len(matches)-1]
+	hours, _ := strconv.Atoi(string(last[1]))
+	minutes, _ := strconv.Atoi(string(last[2]))
+	secs, _ := strconv.Atoi(string(last[3]))
+	millis, _ := strconv.Atoi(string(last[4]))
+	return float64(hours*3600+minutes*60+secs) + float64(millis)/1000
+}
+
+// ReplaceModelInMultipartBody 是 multipart 请求体版本的 ReplaceModelInBody，返回新的请求体与 Content-Type。
+func (s *OpenAIGatewayService) ReplaceModelInMultipartBody(body []byte, contentType string, model string) ([]byte, string, error) {
+	return rewriteMultipartFormFields(body, contentType, map[string]string{"model": model})
+}
+
+// rewriteMultipartFormFields 覆写 multipart 表单中的文本字段（不存在则追加），文件字段原样保留。
+func rewriteMultipartFormFields(body []byte, contentType string, fields map[string]string) ([]byte, string, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, "", fmt.Errorf("parse multipart content-type: %w", err)
+	}
+	boundary := strings.TrimSpace(params["boundary"])
+	if boundary == "" {
+		return nil, "", fmt.Errorf("multipart boundary is required")
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	written := make(map[string]bool, len(fields))
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("read multipart body: %w", err)
+		}
+		target, err := writer.CreatePart(cloneMultipartHeader(part.Header))
+		if err != nil {
+			_ = part.Close()
+			return nil, "", fmt.Errorf("create multipart part: %w", err)
+		}
+		name := strings.TrimSpace(part.FormName())
+		if value, ok := fields[name]; ok && part.FileName() == "" {
+			_, err = target.Write([]byte(value))
+			written[name] = true
+		} else {
+			_, err = io.Copy(target, part)
+		}
+		_ = part.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("copy multipart part %s: %w", name, err)
+		}
+	}
+	for name, value := range fields {
+		if written[name] {
+			continue
+		}
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", fmt.Errorf("append multipart field %s: %w", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("finalize multipart body: %w", err)
+	}
+	return buffer.Bytes(), writer.FormDataContentType(), nil
+}
+
+func writeOpenAIAudioUpstreamResponse(c *gin.Context, resp *http.Response, body []byte, filter *responseheaders.CompiledHeaderFilter) {
+	if c == nil || resp == nil || c.Writer.Written() {
+		return
+	}
+	if resp.Header != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, filter)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		c.Writer.Header().Set("Content-Type", ct)
+	} else {
+		c.Writer.Header().Set("Content-Type", "application/json")
+	}
+	c.Writer.WriteHeader(resp.StatusCode)
+	_, _ = c.Writer.Write(body)
+}
+
+func writeOpenAIAudioError(c *gin.Context, statusCode int, errType, message string) {
+	c.JSON(statusCode, gin.H{
+		"error": gin.H{
+			"type":    errType,
+			"message": message,
+		},
+	})
+}
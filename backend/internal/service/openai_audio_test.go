@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func buildAudioTranscriptionBody(t *testing.T, fields map[string]string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", "speech.mp3")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("ID3-fake-audio"))
+	require.NoError(t, err)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes(), writer.FormDataContentType()
+}
+
+func newAudioTestAccount() *Account {
+	return &Account{
+		ID:       7,
+		Platform: PlatformOpenAI,
+		Type:     AccountTypeAPIKey,
+		Credentials: map[string]any{
+			"api_key":  "sk-test",
+			"base_url": "https://api.openai.com",
+			"model_mapping": map[string]any{
+				"stt": "whisper-1",
+			},
+		},
+	}
+}
+
+func TestParseOpenAIAudioTranscriptionRequest(t *testing.T) {
+	body, contentType := buildAudioTranscriptionBody(t, map[string]string{"model": "whisper-1", "response_format": "SRT", "stream": "true"})
+
+	parsed, err := ParseOpenAIAudioTranscriptionRequest(body, contentType)
+	require.NoError(t, err)
+	require.Equal(t, &OpenAIAudioTranscriptionRequest{Model: "whisper-1", ResponseFormat: "srt", Stream: true, HasFile: true}, parsed)
+
+	_, err = ParseOpenAIAudioTranscriptionRequest([]byte(`{}`), "application/json")
+	require.Error(t, err)
+}
+
+func TestExtractOpenAIAudioUsage(t *testing.T) {
+	usage, seconds := extractOpenAIAudioUsage([]byte(`{"text":"hi","usage":{"type":"duration","seconds":12}}`))
+	require.Equal(t, OpenAIUsage{}, usage)
+	require.Equal(t, 12.0, seconds)
+
+	usage, seconds = extractOpenAIAudioUsage([]byte(`{"text":"hi","usage":{"type":"tokens","input_tokens":30,"output_tokens":4,"total_tokens":34}}`))
+	require.Equal(t, OpenAIUsage{InputTokens: 30, OutputTokens: 4}, usage)
+	require.Zero(t, seconds)
+
+	_, seconds = extractOpenAIAudioUsage([]byte(`{"task":"transcribe","duration":8.52,"text":"hi"}`))
+	require.Equal(t, 8.52, seconds)
+}
+
+func TestSubtitleDurationSeconds(t *testing.T) {
+	srt := "1\n00:00:00,000 --> 00:00:02,500\nhello\n\n2\n00:00:02,500 --> 00:01:05,250\nworld\n"
+	require.Equal(t, 65.25, subtitleDurationSeconds([]byte(srt)))
+	vtt := "WEBVTT\n\n00:00.000 --> 00:00:03.100\nhello\n"
+	require.Equal(t, 3.1, subtitleDurationSeconds([]byte(vtt)))
+	require.Zero(t, subtitleDurationSeconds([]byte("plain text")))
+}
+
+func TestForwardAudioTranscription_TextFormatBillsDurationAndMapsModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body, contentType := buildAudioTranscriptionBody(t, map[string]string{"model": "stt", "response_format": "text"})
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "X-Request-Id": []string{"stt-rid"}},
+		Body:       io.NopCloser(strings.NewReader(`{"text":"hello world","usage":{"type":"duration","seconds":42}}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardAudioTranscription(context.Background(), c, newAudioTestAccount(), body, contentType, "")
+	require.NoError(t, err)
+	require.Equal(t, "stt-rid", result.RequestID)
+	require.Equal(t, "stt", result.Model)
+	require.Equal(t, "whisper-1", result.UpstreamModel)
+	require.Equal(t, 42.0, result.AudioDurationSeconds)
+	require.Zero(t, result.AudioCharacters)
+
+	require.Equal(t, "https://api.openai.com/v1/audio/transcriptions", upstream.lastReq.URL.String())
+	forwarded, err := ParseOpenAIAudioTranscriptionRequest(upstream.lastBody, upstream.lastReq.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "whisper-1", forwarded.Model)
+	require.Equal(t, "json", forwarded.ResponseFormat)
+	require.True(t, forwarded.HasFile)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "hello world\n", rec.Body.String())
+	require.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+}
+
+func TestForwardAudioSpeech_StreamsAudioAndBillsCharacters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reqBody := []byte(`{"model":"tts-1","input":"你好, world","voice":"alloy"}`)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", bytes.NewReader(reqBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	audio := bytes.Repeat([]byte{0xff, 0xfb}, openAIAudioStreamChunkSize)
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"audio/mpeg"}},
+		Body:       io.NopCloser(bytes.NewReader(audio)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardAudioSpeech(context.Background(), c, newAudioTestAccount(), reqBody, "")
+	require.NoError(t, err)
+	require.Equal(t, 9, result.AudioCharacters)
+	require.Zero(t, result.AudioDurationSeconds)
+	require.NotNil(t, result.FirstTokenMs)
+	require.Equal(t, "https://api.openai.com/v1/audio/speech", upstream.lastReq.URL.String())
+	require.Equal(t, "tts-1", gjson.GetBytes(upstream.lastBody, "model").String())
+
+	require.Equal(t, "audio/mpeg", rec.Header().Get("Content-Type"))
+	require.Equal(t, audio, rec.Body.Bytes())
+}
+
+func TestForwardAudioSpeech_SSETokenUsageSkipsCharacterBilling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reqBody := []byte(`{"model":"gpt-4o-mini-tts","input":"hello","voice":"alloy","stream_format":"sse"}`)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", bytes.NewReader(reqBody))
+
+	stream := "data: {\"type\":\"speech.audio.delta\",\"audio\":\"AAAA\"}\n\n" +
+		"data: {\"type\":\"speech.audio.done\",\"usage\":{\"input_tokens\":5,\"output_tokens\":80,\"total_tokens\":85}}\n\n"
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(stream)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardAudioSpeech(context.Background(), c, newAudioTestAccount(), reqBody, "")
+	require.NoError(t, err)
+	require.Equal(t, OpenAIUsage{InputTokens: 5, OutputTokens: 80}, result.Usage)
+	require.Zero(t, result.AudioCharacters)
+	require.Equal(t, stream, rec.Body.String())
+}
+
+func TestCalculateAudioCost_UsesPricingWithDefaults(t *testing.T) {
+	pricing := &PricingService{pricingData: map[string]*LiteLLMModelPricing{
+		"whisper-1": {InputCostPerSecond: 0.0002},
+	}}
+	svc := &BillingService{pricingService: pricing}
+
+	cost := svc.CalculateAudioCost("whisper-1", 30, 0, 2)
+	require.InDelta(t, 0.006, cost.TotalCost, 1e-12)
+	require.InDelta(t, 0.012, cost.ActualCost, 1e-12)
+	require.Equal(t, string(BillingModeAudio), cost.BillingMode)
+
+	cost = svc.CalculateAudioCost("tts-1", 0, 1000, 1)
+	require.InDelta(t, 1000*defaultAudioSpeechPricePerCharacter, cost.TotalCost, 1e-12)
+}
@@ -0,0 +1,567 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const (
+	OpenAIBatchObjectFile  = "file"
+	OpenAIBatchObjectBatch = "batch"
+
+	// OpenAIBatchServiceTier 写入批次结算用量的 service_tier，计费时按 Batch API 半价。
+	OpenAIBatchServiceTier = "batch"
+
+	openAIFilesEndpoint   = "/v1/files"
+	openAIBatchesEndpoint = "/v1/batches"
+
+	openAIBatchFileStreamChunkSize = 32 << 10
+)
+
+var ErrOpenAIBatchObjectNotFound = infraerrors.NotFound("OPENAI_BATCH_OBJECT_NOT_FOUND", "batch object not found")
+
+// openAIBatchObjectIDPattern 限制上游对象 ID 的字符集，避免拼接到上游路径时越界到其他接口。
+var openAIBatchObjectIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// OpenAIBatchObject 记录上游文件/批次与创建它的账号、API Key 的绑定。
+// 上游对象只存在于创建它的账号下，后续请求都路由回 AccountID。
+type OpenAIBatchObject struct {
+	ID         int64
+	ObjectType string
+	ObjectID   string
+	PublicID   string // 返回给客户端的文件 ID（file-<hex>），批次对象为空
+	UserID     int64
+	APIKeyID   int64
+	AccountID  int64
+	GroupID    *int64
+	Model      string
+	Status     string
+	Purpose    string
+	Filename   string
+	Bytes      int64
+	SettledAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// OpenAIBatchRepository 存储 Batch API 对象绑定。
+type OpenAIBatchRepository interface {
+	// Create 登记对象绑定；同一对象重复登记时保留首次绑定，并把已存储的 ID/PublicID 回填到 obj。
+	Create(ctx context.Context, obj *OpenAIBatchObject) error
+	// Get 按类型与上游 ID 查询，不存在时返回 ErrOpenAIBatchObjectNotFound。
+	Get(ctx context.Context, objectType, objectID string) (*OpenAIBatchObject, error)
+	// GetByPublicID 按类型与客户端可见 ID 查询，不存在时返回 ErrOpenAIBatchObjectNotFound。
+	GetByPublicID(ctx context.Context, objectType, publicID string) (*OpenAIBatchObject, error)
+	// ListFiles 按 ID 倒序/正序列出 apiKeyID 名下的文件。
+	ListFiles(ctx context.Context, apiKeyID int64, filter OpenAIFileListFilter) ([]*OpenAIBatchObject, error)
+	UpdateStatus(ctx context.Context, id int64, status string) error
+	Delete(ctx context.Context, id int64) error
+	// ClaimSettlement 抢占批次结算权，仅第一次调用返回 true。
+	ClaimSettlement(ctx context.Context, id int64) (bool, error)
+	// ReleaseSettlement 撤销结算抢占，供用量记录失败后下次轮询重试。
+	ReleaseSettlement(ctx context.Context, id int64) error
+}
+
+// SetOpenAIBatchRepository 注入 Batch API 对象绑定存储（nil 表示关闭 Batch API）。
+func (s *OpenAIGatewayService) SetOpenAIBatchRepository(repo OpenAIBatchRepository) {
+	s.batchRepo = repo
+}
+
+// OpenAIBatchEnabled 返回 Batch API 是否可用。
+func (s *OpenAIGatewayService) OpenAIBatchEnabled() bool {
+	return s != nil && s.batchRepo != nil
+}
+
+// ValidOpenAIBatchObjectID 校验客户端传入的上游对象 ID。
+func ValidOpenAIBatchObjectID(id string) bool {
+	return openAIBatchObjectIDPattern.MatchString(id)
+}
+
+// OpenAIBatchFileUpload 是 /v1/files 上传表单中与路由/计费相关的信息。
+// 仅 purpose=batch 时扫描文件内容：Model 取自 JSONL 每行 body.model，要求整份文件使用同一个模型。
+type OpenAIBatchFileUpload struct {
+	Purpose  string
+	Filename string
+	Model    string
+	HasFile  bool
+	Lines    int
+}
+
+// ParseOpenAIBatchFileUpload 解析 /v1/files 的 multipart 表单，purpose=batch 时扫描批次输入文件。
+func ParseOpenAIBatchFileUpload(body []byte, contentType string) (*OpenAIBatchFileUpload, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.EqualFold(mediaType, "multipart/form-data") {
+		return nil, fmt.Errorf("content-type must be multipart/form-data")
+	}
+	boundary := strings.TrimSpace(params["boundary"])
+	if boundary == "" {
+		return nil, fmt.Errorf("multipart boundary is required")
+	}
+
+	// purpose 字段可能排在 file 之后，先读一遍表单确定 purpose，再决定是否扫描文件内容。
+	upload := &OpenAIBatchFileUpload{}
+	err = eachOpenAIMultipartPart(body, boundary, func(part *multipart.Part) error {
+		switch name := strings.TrimSpace(part.FormName()); {
+		case name == "file" && part.FileName() != "":
+			upload.HasFile = true
+			upload.Filename = part.FileName()
+		case name == "purpose":
+			value, err := io.ReadAll(io.LimitReader(part, 256))
+			if err != nil {
+				return err
+			}
+			upload.Purpose = strings.TrimSpace(string(value))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if upload.Purpose != "batch" || !upload.HasFile {
+		return upload, nil
+	}
+	err = eachOpenAIMultipartPart(body, boundary, func(part *multipart.Part) error {
+		if strings.TrimSpace(part.FormName()) == "file" && part.FileName() != "" {
+			return scanOpenAIBatchInputFile(part, upload)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+func eachOpenAIMultipartPart(body []byte, boundary string, fn func(part *multipart.Part) error) error {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read multipart body: %w", err)
+		}
+		err = fn(part)
+		_ = part.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func scanOpenAIBatchInputFile(r io.Reader, upload *OpenAIBatchFileUpload) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !gjson.ValidBytes(line) {
+			return fmt.Errorf("line %d is not valid JSON", lineNo)
+		}
+		model := strings.TrimSpace(gjson.GetBytes(line, "body.model").String())
+		if model == "" {
+			return fmt.Errorf("line %d: body.model is required", lineNo)
+		}
+		if upload.Model == "" {
+			upload.Model = model
+		} else if model != upload.Model {
+			return fmt.Errorf("line %d: all requests in a batch must use the same model", lineNo)
+		}
+		upload.Lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read batch input file: %w", err)
+	}
+	if upload.Lines == 0 {
+		return fmt.Errorf("batch input file is empty")
+	}
+	return nil
+}
+
+// OpenAIBatchState 是上游批次对象中与绑定/结算相关的字段。
+type OpenAIBatchState struct {
+	ID           string
+	Status       string
+	OutputFileID string
+	ErrorFileID  string
+	Usage        OpenAIUsage
+}
+
+// ParseOpenAIBatchState 解析上游批次对象。
+func ParseOpenAIBatchState(body []byte) OpenAIBatchState {
+	if !gjson.ValidBytes(body) {
+		return OpenAIBatchState{}
+	}
+	usage := gjson.GetBytes(body, "usage")
+	return OpenAIBatchState{
+		ID:           strings.TrimSpace(gjson.GetBytes(body, "id").String()),
+		Status:       strings.TrimSpace(gjson.GetBytes(body, "status").String()),
+		OutputFileID: strings.TrimSpace(gjson.GetBytes(body, "output_file_id").String()),
+		ErrorFileID:  strings.TrimSpace(gjson.GetBytes(body, "error_file_id").String()),
+		Usage: OpenAIUsage{
+			InputTokens:          int(usage.Get("input_tokens").Int()),
+			OutputTokens:         int(usage.Get("output_tokens").Int()),
+			CacheReadInputTokens: int(usage.Get("input_tokens_details.cached_tokens").Int()),
+			ReasoningTokens:      int(usage.Get("output_tokens_details.reasoning_tokens").Int()),
+		},
+	}
+}
+
+// IsOpenAIBatchTerminalStatus 判断批次是否已结束（之后用量不再变化）。
+func IsOpenAIBatchTerminalStatus(status string) bool {
+	switch status {
+	case "completed", "failed", "expired", "cancelled":
+		return true
+	default:
+		return false
+	}
+}
+
+// RegisterOpenAIBatchObject 登记新建的上游对象；文件对象分配客户端可见的 PublicID。
+// 对象已登记过时 obj.PublicID 回填为首次登记的值。
+func (s *OpenAIGatewayService) RegisterOpenAIBatchObject(ctx context.Context, obj *OpenAIBatchObject) error {
+	if s.batchRepo == nil {
+		return errors.New("openai batch repository not configured")
+	}
+	if obj.ObjectType == OpenAIBatchObjectFile && obj.PublicID == "" {
+		publicID, err := NewOpenAIFilePublicID()
+		if err != nil {
+			return err
+		}
+		obj.PublicID = publicID
+	}
+	return s.batchRepo.Create(ctx, obj)
+}
+
+// GetOpenAIBatchObject 查询属于 apiKeyID 的对象及其绑定账号。
+// 文件按客户端可见的 PublicID 查询，批次按上游 ID 查询。
+// 其他 API Key 创建的对象一律视为不存在，避免共享账号下跨用户访问。
+func (s *OpenAIGatewayService) GetOpenAIBatchObject(ctx context.Context, apiKeyID int64, objectType, objectID string) (*OpenAIBatchObject, *Account, error) {
+	if s.batchRepo == nil || !ValidOpenAIBatchObjectID(objectID) {
+		return nil, nil, ErrOpenAIBatchObjectNotFound
+	}
+	var (
+		obj *OpenAIBatchObject
+		err error
+	)
+	if objectType == OpenAIBatchObjectFile {
+		obj, err = s.batchRepo.GetByPublicID(ctx, objectType, objectID)
+	} else {
+		obj, err = s.batchRepo.Get(ctx, objectType, objectID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if obj.APIKeyID != apiKeyID {
+		return nil, nil, ErrOpenAIBatchObjectNotFound
+	}
+	account, err := s.accountRepo.GetByID(ctx, obj.AccountID)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) {
+			return nil, nil, ErrOpenAIBatchObjectNotFound
+		}
+		return nil, nil, err
+	}
+	return obj, account, nil
+}
+
+// SyncOpenAIBatchState 根据上游批次对象更新本地状态，并登记结果/错误文件的绑定。
+// 批次进入终态且有用量时抢占结算权，返回 true 表示调用方需要记录本次结算用量。
+func (s *OpenAIGatewayService) SyncOpenAIBatchState(ctx context.Context, batch *OpenAIBatchObject, state OpenAIBatchState) (bool, error) {
+	if s.batchRepo == nil || batch == nil {
+		return false, nil
+	}
+	if state.Status != "" && state.Status != batch.Status {
+		if err := s.batchRepo.UpdateStatus(ctx, batch.ID, state.Status); err != nil {
+			return false, err
+		}
+		batch.Status = state.Status
+	}
+	for _, fileID := range []string{state.OutputFileID, state.ErrorFileID} {
+		if !ValidOpenAIBatchObjectID(fileID) {
+			continue
+		}
+		if err := s.RegisterOpenAIBatchObject(ctx, &OpenAIBatchObject{
+			ObjectType: OpenAIBatchObjectFile,
+			ObjectID:   fileID,
+			UserID:     batch.UserID,
+			APIKeyID:   batch.APIKeyID,
+			AccountID:  batch.AccountID,
+			GroupID:    batch.GroupID,
+			Model:      batch.Model,
+			Status:     "processed",
+			Purpose:    "batch_output",
+		}); err != nil {
+			return false, err
+		}
+	}
+	if batch.SettledAt != nil || !IsOpenAIBatchTerminalStatus(batch.Status) {
+		return false, nil
+	}
+	if state.Usage.InputTokens <= 0 && state.Usage.OutputTokens <= 0 {
+		return false, nil
+	}
+	return s.batchRepo.ClaimSettlement(ctx, batch.ID)
+}
+
+// ReleaseOpenAIBatchSettlement 撤销结算抢占。
+func (s *OpenAIGatewayService) ReleaseOpenAIBatchSettlement(ctx context.Context, batchID int64) error {
+	if s.batchRepo == nil {
+		return nil
+	}
+	return s.batchRepo.ReleaseSettlement(ctx, batchID)
+}
+
+// BuildOpenAIBatchUsageResult 构造批次结算的用量结果，整个批次记为一条用量。
+func BuildOpenAIBatchUsageResult(batch *OpenAIBatchObject, state OpenAIBatchState) *OpenAIForwardResult {
+	serviceTier := OpenAIBatchServiceTier
+	return &OpenAIForwardResult{
+		RequestID:   "batch:" + batch.ObjectID,
+		ResponseID:  batch.ObjectID,
+		Model:       batch.Model,
+		Usage:       state.Usage,
+		ServiceTier: &serviceTier,
+	}
+}
+
+// OpenAIBatchResponseRewriter 在成功响应写回客户端前改写响应体（例如把上游文件 ID 换成 PublicID）。
+type OpenAIBatchResponseRewriter func(body []byte) ([]byte, error)
+
+// ForwardOpenAIBatchRequest 转发文件与批次请求，成功响应经 rewrite（可为 nil）改写后写回客户端。
+// 成功时返回上游原始响应体供调用方解析；可 failover 的错误返回 UpstreamFailoverError 且不写响应。
+func (s *OpenAIGatewayService) ForwardOpenAIBatchRequest(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	method string,
+	endpoint string,
+	body []byte,
+	contentType string,
+	rewrite OpenAIBatchResponseRewriter,
+) ([]byte, error) {
+	resp, err := s.doOpenAIBatchRequest(ctx, c, account, method, endpoint, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := ReadUpstreamResponseBody(resp.Body, s.cfg, c, openAITooLargeError)
+	if err != nil {
+		if !errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
+			writeOpenAIBatchError(c, http.StatusBadGateway, "api_error", "Failed to read upstream response")
+		}
+		return nil, fmt.Errorf("read upstream body: %w", err)
+	}
+	clientBody := respBody
+	if rewrite != nil {
+		clientBody, err = rewrite(respBody)
+		if err != nil {
+			writeOpenAIBatchError(c, http.StatusInternalServerError, "api_error", "Failed to process upstream response")
+			return nil, fmt.Errorf("rewrite upstream body: %w", err)
+		}
+	}
+	writeOpenAIBatchUpstreamResponse(c, resp, clientBody, s.responseHeaderFilter)
+	return respBody, nil
+}
+
+// ForwardOpenAIBatchFileContent 下载文件内容，边读边写给客户端。
+func (s *OpenAIGatewayService) ForwardOpenAIBatchFileContent(ctx context.Context, c *gin.Context, account *Account, fileID string) error {
+	resp, err := s.doOpenAIBatchRequest(ctx, c, account, http.MethodGet, OpenAIBatchFileContentEndpoint(fileID), nil, "")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		c.Writer.Header().Set("Content-Type", ct)
+	}
+	c.Writer.Header().Del("Content-Length")
+	c.Writer.WriteHeader(resp.StatusCode)
+	buf := make([]byte, openAIBatchFileStreamChunkSize)
+	if _, err := io.CopyBuffer(c.Writer, resp.Body, buf); err != nil {
+		return fmt.Errorf("stream upstream body: %w", err)
+	}
+	return nil
+}
+
+// OpenAIBatchEndpoint 返回批次对象的上游路径，action 为空时为批次本身。
+func OpenAIBatchEndpoint(batchID, action string) string {
+	endpoint := openAIBatchesEndpoint
+	if batchID != "" {
+		endpoint += "/" + url.PathEscape(batchID)
+	}
+	if action != "" {
+		endpoint += "/" + action
+	}
+	return endpoint
+}
+
+// OpenAIBatchFilesEndpoint 返回文件上传的上游路径。
+func OpenAIBatchFilesEndpoint() string {
+	return openAIFilesEndpoint
+}
+
+// OpenAIFileEndpoint 返回单个文件的上游路径（查询/删除）。
+func OpenAIFileEndpoint(fileID string) string {
+	return openAIFilesEndpoint + "/" + url.PathEscape(fileID)
+}
+
+// OpenAIBatchFileContentEndpoint 返回文件内容下载的上游路径。
+func OpenAIBatchFileContentEndpoint(fileID string) string {
+	return openAIFilesEndpoint + "/" + url.PathEscape(fileID) + "/content"
+}
+
+// doOpenAIBatchRequest 发送上游请求并处理失败/failover；返回的响应状态码一定 < 400。
+func (s *OpenAIGatewayService) doOpenAIBatchRequest(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	method string,
+	endpoint string,
+	body []byte,
+	contentType string,
+) (*http.Response, error) {
+	apiKey := account.GetOpenAIApiKey()
+	if apiKey == "" {
+		return nil, fmt.Errorf("account %d missing api_key", account.ID)
+	}
+	baseURL := account.GetOpenAIBaseURL()
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	validatedURL, err := s.validateUpstreamBaseURL(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base_url: %w", err)
+	}
+	targetURL := buildOpenAIEndpointURL(validatedURL, endpoint)
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	upstreamCtx, releaseUpstreamCtx := detachUpstreamContext(ctx)
+	upstreamReq, err := http.NewRequestWithContext(upstreamCtx, method, targetURL, bodyReader)
+	releaseUpstreamCtx()
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	upstreamReq = upstreamReq.WithContext(WithHTTPUpstreamProfile(upstreamReq.Context(), HTTPUpstreamProfileOpenAI))
+	if contentType != "" {
+		upstreamReq.Header.Set("Content-Type", contentType)
+	}
+	upstreamReq.Header.Set("Authorization", "Bearer "+apiKey)
+	for key, values := range c.Request.Header {
+		if openaiCCRawAllowedHeaders[strings.ToLower(key)] {
+			for _, v := range values {
+				upstreamReq.Header.Add(key, v)
+			}
+		}
+	}
+	if customUA := account.GetOpenAIUserAgent(); customUA != "" {
+		upstreamReq.Header.Set("user-agent", customUA)
+	}
+	account.ApplyHeaderOverrides(upstreamReq.Header)
+
+	logger.L().Debug("openai batch: forwarding",
+		zap.Int64("account_id", account.ID),
+		zap.String("method", method),
+		zap.String("endpoint", endpoint),
+	)
+
+	proxyURL := ""
+	if account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		writeOpenAIBatchError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
+		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
+	}
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+
+	respBody := s.readUpstreamErrorBody(resp)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+	if s.shouldFailoverOpenAIUpstreamResponse(resp.StatusCode, upstreamMsg, respBody) {
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: resp.StatusCode,
+			UpstreamRequestID:  resp.Header.Get("x-request-id"),
+			Kind:               "failover",
+			Message:            upstreamMsg,
+		})
+		s.handleOpenAIAccountUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody, "")
+		return nil, &UpstreamFailoverError{
+			StatusCode:             resp.StatusCode,
+			ResponseBody:           respBody,
+			RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
+		}
+	}
+	writeOpenAIBatchUpstreamResponse(c, resp, respBody, s.responseHeaderFilter)
+	return nil, fmt.Errorf("upstream returned status %d", resp.StatusCode)
+}
+
+func writeOpenAIBatchUpstreamResponse(c *gin.Context, resp *http.Response, body []byte, filter *responseheaders.CompiledHeaderFilter) {
+	if c == nil || resp == nil || c.Writer.Written() {
+		return
+	}
+	if resp.Header != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, filter)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		c.Writer.Header().Set("Content-Type", ct)
+	} else {
+		c.Writer.Header().Set("Content-Type", "application/json")
+	}
+	c.Writer.WriteHeader(resp.StatusCode)
+	_, _ = c.Writer.Write(body)
+}
+
+func writeOpenAIBatchError(c *gin.Context, statusCode int, errType, message string) {
+	c.JSON(statusCode, gin.H{
+		"error": gin.H{
+			"type":    errType,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type openAIBatchRepoStub struct {
+	objects  map[string]*OpenAIBatchObject
+	statuses map[int64]string
+	claimed  map[int64]bool
+}
+
+func newOpenAIBatchRepoStub() *openAIBatchRepoStub {
+	return &openAIBatchRepoStub{
+		objects:  map[string]*OpenAIBatchObject{},
+		statuses: map[int64]string{},
+		claimed:  map[int64]bool{},
+	}
+}
+
+func (r *openAIBatchRepoStub) Create(_ context.Context, obj *OpenAIBatchObject) error {
+	key := obj.ObjectType + "/" + obj.ObjectID
+	if _, ok := r.objects[key]; !ok {
+		clone := *obj
+		clone.ID = int64(len(r.objects) + 1)
+		r.objects[key] = &clone
+	}
+	obj.ID = r.objects[key].ID
+	obj.PublicID = r.objects[key].PublicID
+	return nil
+}
+
+func (r *openAIBatchRepoStub) Get(_ context.Context, objectType, objectID string) (*OpenAIBatchObject, error) {
+	if obj, ok := r.objects[objectType+"/"+objectID]; ok {
+		clone := *obj
+		return &clone, nil
+	}
+	return nil, ErrOpenAIBatchObjectNotFound
+}
+
+func (r *openAIBatchRepoStub) GetByPublicID(_ context.Context, objectType, publicID string) (*OpenAIBatchObject, error) {
+	for _, obj := range r.objects {
+		if obj.ObjectType == objectType && obj.PublicID == publicID {
+			clone := *obj
+			return &clone, nil
+		}
+	}
+	return nil, ErrOpenAIBatchObjectNotFound
+}
+
+func (r *openAIBatchRepoStub) ListFiles(_ context.Context, apiKeyID int64, filter OpenAIFileListFilter) ([]*OpenAIBatchObject, error) {
+	var files []*OpenAIBatchObject
+	for _, obj := range r.objects {
+		if obj.ObjectType == OpenAIBatchObjectFile && obj.APIKeyID == apiKeyID && (filter.Purpose == "" || obj.Purpose == filter.Purpose) {
+			files = append(files, obj)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ID < files[j].ID })
+	if len(files) > filter.Limit {
+		files = files[:filter.Limit]
+	}
+	return files, nil
+}
+
+func (r *openAIBatchRepoStub) Delete(_ context.Context, id int64) error {
+	for key, obj := range r.objects {
+		if obj.ID == id {
+			delete(r.objects, key)
+		}
+	}
+	return nil
+}
+
+func (r *openAIBatchRepoStub) UpdateStatus(_ context.Context, id int64, status string) error {
+	r.statuses[id] = status
+	return nil
+}
+
+func (r *openAIBatchRepoStub) ClaimSettlement(_ context.Context, id int64) (bool, error) {
+	if r.claimed[id] {
+		return false, nil
+	}
+	r.claimed[id] = true
+	return true, nil
+}
+
+func (r *openAIBatchRepoStub) ReleaseSettlement(_ context.Context, id int64) error {
+	delete(r.claimed, id)
+	return nil
+}
+
+func buildBatchFileUploadBody(t *testing.T, purpose, content string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	require.NoError(t, writer.WriteField("purpose", purpose))
+	part, err := writer.CreateFormFile("file", "input.jsonl")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes(), writer.FormDataContentType()
+}
+
+func TestParseOpenAIBatchFileUpload(t *testing.T) {
+	content := `{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o-mini","messages":[]}}` + "\n\n" +
+		`{"custom_id":"b","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o-mini","messages":[]}}` + "\n"
+	body, contentType := buildBatchFileUploadBody(t, "batch", content)
+
+	upload, err := ParseOpenAIBatchFileUpload(body, contentType)
+	require.NoError(t, err)
+	require.Equal(t, &OpenAIBatchFileUpload{Purpose: "batch", Filename: "input.jsonl", Model: "gpt-4o-mini", HasFile: true, Lines: 2}, upload)
+
+	mixed := `{"body":{"model":"gpt-4o-mini"}}` + "\n" + `{"body":{"model":"gpt-4o"}}`
+	body, contentType = buildBatchFileUploadBody(t, "batch", mixed)
+	_, err = ParseOpenAIBatchFileUpload(body, contentType)
+	require.ErrorContains(t, err, "same model")
+
+	body, contentType = buildBatchFileUploadBody(t, "batch", `{"body":{}}`)
+	_, err = ParseOpenAIBatchFileUpload(body, contentType)
+	require.ErrorContains(t, err, "body.model is required")
+
+	body, contentType = buildBatchFileUploadBody(t, "batch", "")
+	_, err = ParseOpenAIBatchFileUpload(body, contentType)
+	require.ErrorContains(t, err, "empty")
+
+	body, contentType = buildBatchFileUploadBody(t, "fine-tune", `{"messages":[]}`)
+	upload, err = ParseOpenAIBatchFileUpload(body, contentType)
+	require.NoError(t, err)
+	require.Equal(t, &OpenAIBatchFileUpload{Purpose: "fine-tune", Filename: "input.jsonl", HasFile: true}, upload)
+}
+
+func TestParseOpenAIBatchState(t *testing.T) {
+	state := ParseOpenAIBatchState([]byte(`{"id":"batch_1","status":"completed","output_file_id":"file-out","error_file_id":null,
+		"usage":{"input_tokens":1000,"input_tokens_details":{"cached_tokens":200},"output_tokens":300,"output_tokens_details":{"reasoning_tokens":50}}}`))
+	require.Equal(t, OpenAIBatchState{
+		ID:           "batch_1",
+		Status:       "completed",
+		OutputFileID: "file-out",
+		Usage:        OpenAIUsage{InputTokens: 1000, OutputTokens: 300, CacheReadInputTokens: 200, ReasoningTokens: 50},
+	}, state)
+	require.True(t, IsOpenAIBatchTerminalStatus("cancelled"))
+	require.False(t, IsOpenAIBatchTerminalStatus("finalizing"))
+}
+
+func TestOpenAIBatchObjectIDValidationAndEndpoints(t *testing.T) {
+	require.True(t, ValidOpenAIBatchObjectID("batch_abc-123"))
+	require.False(t, ValidOpenAIBatchObjectID(""))
+	require.False(t, ValidOpenAIBatchObjectID("../models"))
+	require.False(t, ValidOpenAIBatchObjectID("file-1/content"))
+	require.Equal(t, "/v1/batches/batch_1/cancel", OpenAIBatchEndpoint("batch_1", "cancel"))
+	require.Equal(t, "/v1/batches", OpenAIBatchEndpoint("", ""))
+	require.Equal(t, "/v1/files/file-1/content", OpenAIBatchFileContentEndpoint("file-1"))
+}
+
+func TestGetOpenAIBatchObject_HidesOtherAPIKeys(t *testing.T) {
+	repo := newOpenAIBatchRepoStub()
+	require.NoError(t, repo.Create(context.Background(), &OpenAIBatchObject{ObjectType: OpenAIBatchObjectBatch, ObjectID: "batch_1", APIKeyID: 5, AccountID: 7}))
+	svc := &OpenAIGatewayService{accountRepo: stubOpenAIAccountRepo{accounts: []Account{{ID: 7, Platform: PlatformOpenAI}}}}
+	svc.SetOpenAIBatchRepository(repo)
+
+	obj, account, err := svc.GetOpenAIBatchObject(context.Background(), 5, OpenAIBatchObjectBatch, "batch_1")
+	require.NoError(t, err)
+	require.Equal(t, "batch_1", obj.ObjectID)
+	require.Equal(t, int64(7), account.ID)
+
+	_, _, err = svc.GetOpenAIBatchObject(context.Background(), 6, OpenAIBatchObjectBatch, "batch_1")
+	require.ErrorIs(t, err, ErrOpenAIBatchObjectNotFound)
+	_, _, err = svc.GetOpenAIBatchObject(context.Background(), 5, OpenAIBatchObjectBatch, "../batch_1")
+	require.ErrorIs(t, err, ErrOpenAIBatchObjectNotFound)
+}
+
+func TestSyncOpenAIBatchState_SettlesOnceOnTerminalStatus(t *testing.T) {
+	repo := newOpenAIBatchRepoStub()
+	svc := &OpenAIGatewayService{}
+	svc.SetOpenAIBatchRepository(repo)
+	ctx := context.Background()
+	groupID := int64(3)
+	batch := &OpenAIBatchObject{ID: 9, ObjectType: OpenAIBatchObjectBatch, ObjectID: "batch_1", UserID: 1, APIKeyID: 5, AccountID: 7, GroupID: &groupID, Model: "gpt-4o-mini", Status: "validating"}
+	usage := OpenAIUsage{InputTokens: 100, OutputTokens: 20}
+
+	settle, err := svc.SyncOpenAIBatchState(ctx, batch, OpenAIBatchState{Status: "in_progress", Usage: usage})
+	require.NoError(t, err)
+	require.False(t, settle)
+	require.Equal(t, "in_progress", repo.statuses[9])
+
+	state := OpenAIBatchState{Status: "completed", OutputFileID: "file-out", ErrorFileID: "file-err", Usage: usage}
+	settle, err = svc.SyncOpenAIBatchState(ctx, batch, state)
+	require.NoError(t, err)
+	require.True(t, settle)
+	out, err := repo.Get(ctx, OpenAIBatchObjectFile, "file-out")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), out.AccountID)
+	require.Equal(t, int64(5), out.APIKeyID)
+	require.Equal(t, "batch_output", out.Purpose)
+	require.NotEmpty(t, out.PublicID)
+	_, err = repo.Get(ctx, OpenAIBatchObjectFile, "file-err")
+	require.NoError(t, err)
+
+	settle, err = svc.SyncOpenAIBatchState(ctx, batch, state)
+	require.NoError(t, err)
+	require.False(t, settle, "usage must be attributed once")
+
+	require.NoError(t, svc.ReleaseOpenAIBatchSettlement(ctx, 9))
+	settle, err = svc.SyncOpenAIBatchState(ctx, batch, state)
+	require.NoError(t, err)
+	require.True(t, settle, "released settlement is retried on next poll")
+
+	settledAt := time.Now()
+	batch.SettledAt = &settledAt
+	settle, err = svc.SyncOpenAIBatchState(ctx, batch, state)
+	require.NoError(t, err)
+	require.False(t, settle)
+
+	result := BuildOpenAIBatchUsageResult(batch, state)
+	require.Equal(t, "gpt-4o-mini", result.Model)
+	require.Equal(t, OpenAIBatchServiceTier, *result.ServiceTier)
+	require.Equal(t, usage, result.Usage)
+}
+
+func TestForwardOpenAIBatchRequest_PinnedRetrieve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/batches/batch_1", nil)
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"batch_1","status":"in_progress"}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+	account := &Account{ID: 7, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "sk-test", "base_url": "https://api.openai.com/v1"}}
+
+	body, err := svc.ForwardOpenAIBatchRequest(context.Background(), c, account, http.MethodGet, OpenAIBatchEndpoint("batch_1", ""), nil, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "in_progress", ParseOpenAIBatchState(body).Status)
+	require.Equal(t, http.MethodGet, upstream.lastReq.Method)
+	require.Equal(t, "https://api.openai.com/v1/batches/batch_1", upstream.lastReq.URL.String())
+	require.Equal(t, "Bearer sk-test", upstream.lastReq.Header.Get("Authorization"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"id":"batch_1","status":"in_progress"}`, rec.Body.String())
+}
@@ -33,10 +33,45 @@ type CodexModelsManifest struct {
 // with Codex client releases, and interpreting it here would force the gateway
 // to chase upstream changes. Passing it through keeps the gateway
 // schema-agnostic and always reflects the account's real entitlements.
+//
+// When the upstream metadata cache is enabled, the manifest is cached per
+// account and client_version, and If-None-Match is answered locally against
+// the cached ETag instead of being forwarded upstream.
 func (s *OpenAIGatewayService) FetchCodexModelsManifest(ctx context.Context, account *Account, clientVersion, ifNoneMatch string) (*CodexModelsManifest, error) {
 	if account == nil {
 		return nil, infraerrors.New(http.StatusInternalServerError, "OPENAI_CODEX_MODELS_ACCOUNT_REQUIRED", "account is required")
 	}
+	clientVersion = strings.TrimSpace(clientVersion)
+	if clientVersion == "" {
+		clientVersion = openAICodexProbeVersion
+	}
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if s.metadataCache == nil {
+		return s.requestCodexModelsManifest(ctx, account, clientVersion, ifNoneMatch)
+	}
+
+	res, err := s.metadataCache.Load(account.ID, "codex-models:"+clientVersion, func() (*UpstreamHTTPResult, error) {
+		manifest, err := s.requestCodexModelsManifest(ctx, account, clientVersion, "")
+		if err != nil {
+			return nil, err
+		}
+		headers := http.Header{}
+		if manifest.ETag != "" {
+			headers.Set("ETag", manifest.ETag)
+		}
+		return &UpstreamHTTPResult{StatusCode: http.StatusOK, Headers: headers, Body: manifest.Body}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	etag := res.Headers.Get("ETag")
+	if etag != "" && ifNoneMatch == etag {
+		return &CodexModelsManifest{ETag: etag, NotModified: true}, nil
+	}
+	return &CodexModelsManifest{Body: res.Body, ETag: etag}, nil
+}
+
+func (s *OpenAIGatewayService) requestCodexModelsManifest(ctx context.Context, account *Account, clientVersion, ifNoneMatch string) (*CodexModelsManifest, error) {
 	credAccount, err := resolveCredentialAccount(ctx, s.accountRepo, account)
 	if err != nil {
 		return nil, infraerrors.Newf(http.StatusInternalServerError, "OPENAI_CODEX_MODELS_CREDENTIALS_FAILED", "resolve credential account: %v", err)
@@ -46,10 +81,6 @@ func (s *OpenAIGatewayService) FetchCodexModelsManifest(ctx context.Context, acc
 		return nil, infraerrors.New(http.StatusBadGateway, "OPENAI_CODEX_MODELS_TOKEN_MISSING", "account has no Codex backend access token")
 	}
 
-	clientVersion = strings.TrimSpace(clientVersion)
-	if clientVersion == "" {
-		clientVersion = openAICodexProbeVersion
-	}
 	requestURL := chatgptCodexModelsURL + "?client_version=" + url.QueryEscape(clientVersion)
 
 	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
@@ -63,7 +94,7 @@ func (s *OpenAIGatewayService) FetchCodexModelsManifest(ctx context.Context, acc
 	req.Header.Set("Originator", "codex_cli_rs")
 	req.Header.Set("Version", clientVersion)
 	req.Header.Set("User-Agent", codexCLIUserAgent)
-	if ifNoneMatch = strings.TrimSpace(ifNoneMatch); ifNoneMatch != "" {
+	if ifNoneMatch != "" {
 		req.Header.Set("If-None-Match", ifNoneMatch)
 	}
 	setOpenAIChatGPTAccountHeaders(req.Header, credAccount)
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func newCodexModelsTestAccount() *Account {
@@ -136,3 +137,48 @@ func TestFetchCodexModelsManifestMissingToken(t *testing.T) {
 		t.Fatal("expected error for missing access token, got nil")
 	}
 }
+
+func TestFetchCodexModelsManifestCachedPerAccount(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("If-None-Match must not be forwarded when caching")
+		}
+		w.Header().Set("ETag", `W/"v1"`)
+		_, _ = w.Write([]byte(`{"models":[]}`))
+	}))
+	defer server.Close()
+
+	original := chatgptCodexModelsURL
+	chatgptCodexModelsURL = server.URL
+	defer func() { chatgptCodexModelsURL = original }()
+
+	s := &OpenAIGatewayService{metadataCache: newUpstreamMetadataCache(time.Minute)}
+	account := newCodexModelsTestAccount()
+	manifest, err := s.FetchCodexModelsManifest(context.Background(), account, "0.137.0", "")
+	if err != nil {
+		t.Fatalf("FetchCodexModelsManifest returned error: %v", err)
+	}
+	if string(manifest.Body) != `{"models":[]}` || manifest.ETag != `W/"v1"` {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	manifest, err = s.FetchCodexModelsManifest(context.Background(), account, "0.137.0", `W/"v1"`)
+	if err != nil {
+		t.Fatalf("FetchCodexModelsManifest returned error: %v", err)
+	}
+	if !manifest.NotModified {
+		t.Errorf("expected cached ETag match to yield NotModified")
+	}
+	if calls != 1 {
+		t.Errorf("expected one upstream call, got %d", calls)
+	}
+
+	if _, err := s.FetchCodexModelsManifest(context.Background(), account, "0.138.0", ""); err != nil {
+		t.Fatalf("FetchCodexModelsManifest returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("client_version must be part of the cache key, got %d calls", calls)
+	}
+}
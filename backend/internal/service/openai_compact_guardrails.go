@@ -0,0 +1,317 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// openAICompactGuardrailKey 在 gin.Context 中保存本次 compact 转发的质量兜底状态，
+// 供响应写回前（handleNonStreamingResponse / handleSSEToJSON）校验摘要。
+const openAICompactGuardrailKey = "openai_compact_guardrail"
+
+// openAICompactMissingContentMessage 是重试耗尽后仍无压缩内容时返回给客户端的 502 错误信息。
+const openAICompactMissingContentMessage = "Upstream did not return compaction content"
+
+const (
+	// openAICompactLanguageSampleRunes 语言识别最多采样的用户消息字符数（从最近的消息往前取）。
+	openAICompactLanguageSampleRunes = 4000
+	// openAICompactDegeneratePeriod 摘要由不超过该长度的片段反复拼接而成时视为退化摘要。
+	openAICompactDegeneratePeriod = 32
+)
+
+type openAICompactSummaryVerdict int
+
+const (
+	openAICompactSummaryOK openAICompactSummaryVerdict = iota
+	// openAICompactSummaryMissing: 没有 compaction item，或 item 既无密文也无明文摘要。
+	openAICompactSummaryMissing
+	// openAICompactSummaryTooShort: 明文摘要短于 min_summary_chars 或为重复字符等退化内容。
+	openAICompactSummaryTooShort
+	// openAICompactSummaryTooLong: 明文摘要超过 max_summary_chars。
+	openAICompactSummaryTooLong
+)
+
+func (v openAICompactSummaryVerdict) String() string {
+	switch v {
+	case openAICompactSummaryMissing:
+		return "missing"
+	case openAICompactSummaryTooShort:
+		return "too_short"
+	case openAICompactSummaryTooLong:
+		return "too_long"
+	default:
+		return "ok"
+	}
+}
+
+// openAICompactGuardrail 是单次 Forward 内的压缩摘要质量兜底状态。
+type openAICompactGuardrail struct {
+	minChars   int
+	maxChars   int
+	maxRetries int
+	attempt    int
+}
+
+// openAICompactRetryError 表示摘要不合格且仍可重试；响应尚未写回客户端。
+type openAICompactRetryError struct {
+	verdict openAICompactSummaryVerdict
+}
+
+func (e *openAICompactRetryError) Error() string {
+	return "openai compact summary rejected: " + e.verdict.String()
+}
+
+// beginOpenAICompactGuardrail 为非流式 compact 请求启用质量兜底并挂到 gin.Context；
+// 未开启或非 compact 请求返回 nil。开启语言匹配时同时返回追加了语言要求的请求体。
+func (s *OpenAIGatewayService) beginOpenAICompactGuardrail(c *gin.Context, body []byte, isCompact bool) (*openAICompactGuardrail, []byte) {
+	if c == nil || !isCompact || s.cfg == nil || !s.cfg.Gateway.CompactGuardrails.Enabled {
+		return nil, body
+	}
+	cfg := s.cfg.Gateway.CompactGuardrails
+	g := &openAICompactGuardrail{minChars: cfg.MinSummaryChars, maxChars: cfg.MaxSummaryChars, maxRetries: cfg.MaxRetries}
+	c.Set(openAICompactGuardrailKey, g)
+	if cfg.LanguageMatching {
+		body = appendOpenAICompactInstruction(body, openAICompactLanguageInstruction(detectOpenAIConversationLanguage(body)))
+	}
+	return g, body
+}
+
+func openAICompactGuardrailFromContext(c *gin.Context) *openAICompactGuardrail {
+	if c == nil {
+		return nil
+	}
+	value, ok := c.Get(openAICompactGuardrailKey)
+	if !ok {
+		return nil
+	}
+	g, _ := value.(*openAICompactGuardrail)
+	return g
+}
+
+// retryBody 记录一次重试并按上次的判定结果调整 instructions。
+func (g *openAICompactGuardrail) retryBody(body []byte, verdict openAICompactSummaryVerdict) []byte {
+	g.attempt++
+	var hint string
+	switch verdict {
+	case openAICompactSummaryTooLong:
+		hint = fmt.Sprintf("The previous compaction summary was too long. Rewrite it concisely in no more than %d characters, keeping only the facts, decisions, open tasks and file/code references needed to continue the work.", g.maxChars)
+	case openAICompactSummaryTooShort:
+		hint = "The previous compaction summary was empty or degenerate. Write a complete, substantive summary of the conversation"
+		if g.minChars > 0 {
+			hint += fmt.Sprintf(" of at least %d characters", g.minChars)
+		}
+		hint += ", covering the user's goals, decisions made, current state, open tasks and relevant file/code references."
+	default:
+		hint = "The previous compaction attempt returned no summary. You must return exactly one compaction item containing a complete summary of the conversation so far."
+	}
+	return appendOpenAICompactInstruction(body, hint)
+}
+
+// checkOpenAICompactSummary 在 compact 响应写回前校验摘要质量：
+//   - 合格：返回 nil，按原路径写回；
+//   - 不合格且可重试：返回 *openAICompactRetryError，不写回任何内容；
+//   - 重试耗尽且仍无压缩内容：写回 502 并返回错误；
+//   - 重试耗尽但摘要存在（长度不达标）：记录日志后返回 nil，原样写回。
+func (s *OpenAIGatewayService) checkOpenAICompactSummary(resp *http.Response, c *gin.Context, statusCode int, body []byte) error {
+	g := openAICompactGuardrailFromContext(c)
+	if g == nil || statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		return nil
+	}
+	verdict := assessOpenAICompactSummary(body, g.minChars, g.maxChars)
+	if verdict == openAICompactSummaryOK {
+		return nil
+	}
+	if g.attempt < g.maxRetries {
+		return &openAICompactRetryError{verdict: verdict}
+	}
+	if verdict == openAICompactSummaryMissing {
+		return s.writeOpenAINonStreamingProtocolError(resp, c, openAICompactMissingContentMessage)
+	}
+	logger.LegacyPrintf("service.openai_gateway", "[OpenAI] Compact summary still %s after %d retries, passing through", verdict, g.attempt)
+	return nil
+}
+
+// assessOpenAICompactSummary 校验 compact 响应中的 compaction item。
+// 仅有密文（encrypted_content）时无法度量长度，只要求非空；携带明文 summary 时校验长度与退化内容。
+func assessOpenAICompactSummary(body []byte, minChars, maxChars int) openAICompactSummaryVerdict {
+	if !gjson.ValidBytes(body) {
+		return openAICompactSummaryMissing
+	}
+	var item gjson.Result
+	for _, candidate := range gjson.GetBytes(body, "output").Array() {
+		if isResponsesCompactionItemType(candidate.Get("type").String()) {
+			item = candidate
+			break
+		}
+	}
+	if !item.Exists() {
+		return openAICompactSummaryMissing
+	}
+	summary := strings.TrimSpace(extractOpenAICompactSummaryText(item))
+	encrypted := strings.TrimSpace(item.Get("encrypted_content").String())
+	if summary == "" {
+		if encrypted == "" {
+			return openAICompactSummaryMissing
+		}
+		return openAICompactSummaryOK
+	}
+	length := len([]rune(summary))
+	if length < minChars || isDegenerateOpenAICompactSummary(summary) {
+		return openAICompactSummaryTooShort
+	}
+	if maxChars > 0 && length > maxChars {
+		return openAICompactSummaryTooLong
+	}
+	return openAICompactSummaryOK
+}
+
+// extractOpenAICompactSummaryText 提取 compaction item 的明文摘要：
+// summary 可能是字符串或 [{"type":"summary_text","text":...}] 数组。
+func extractOpenAICompactSummaryText(item gjson.Result) string {
+	summary := item.Get("summary")
+	if summary.Type == gjson.String {
+		return summary.String()
+	}
+	var sb strings.Builder
+	for _, part := range summary.Array() {
+		if text := part.Get("text").String(); text != "" {
+			if sb.Len() > 0 {
+				sb.WriteByte('\n')
+			}
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+// isDegenerateOpenAICompactSummary 识别退化摘要：不同字符极少，或整段由短片段反复拼接而成。
+func isDegenerateOpenAICompactSummary(summary string) bool {
+	runes := make([]rune, 0, len(summary))
+	distinct := make(map[rune]struct{})
+	for _, r := range summary {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		runes = append(runes, r)
+		distinct[r] = struct{}{}
+	}
+	if len(distinct) <= 3 {
+		return true
+	}
+	for period := 1; period <= openAICompactDegeneratePeriod && period*2 <= len(runes); period++ {
+		repeated := true
+		for i := period; i < len(runes); i++ {
+			if runes[i] != runes[i-period] {
+				repeated = false
+				break
+			}
+		}
+		if repeated {
+			return true
+		}
+	}
+	return false
+}
+
+// detectOpenAIConversationLanguage 按文字体系粗略识别用户消息所用语言；
+// 拉丁字母等无法区分具体语言的情况返回空字符串。
+func detectOpenAIConversationLanguage(body []byte) string {
+	var texts []string
+	for _, item := range gjson.GetBytes(body, "input").Array() {
+		if role := item.Get("role").String(); role != "" && role != "user" {
+			continue
+		}
+		content := item.Get("content")
+		if content.Type == gjson.String {
+			texts = append(texts, content.String())
+			continue
+		}
+		for _, part := range content.Array() {
+			if partType := part.Get("type").String(); partType == "input_text" || partType == "text" {
+				texts = append(texts, part.Get("text").String())
+			}
+		}
+	}
+
+	counts := make(map[string]int)
+	sampled := 0
+	for i := len(texts) - 1; i >= 0 && sampled < openAICompactLanguageSampleRunes; i-- {
+		for _, r := range texts[i] {
+			if sampled >= openAICompactLanguageSampleRunes {
+				break
+			}
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			sampled++
+			switch {
+			case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+				counts["Japanese"]++
+			case unicode.Is(unicode.Han, r):
+				counts["Chinese"]++
+			case unicode.Is(unicode.Hangul, r):
+				counts["Korean"]++
+			case unicode.Is(unicode.Cyrillic, r):
+				counts["Russian"]++
+			case unicode.Is(unicode.Arabic, r):
+				counts["Arabic"]++
+			case unicode.Is(unicode.Thai, r):
+				counts["Thai"]++
+			case unicode.Is(unicode.Devanagari, r):
+				counts["Hindi"]++
+			case unicode.Is(unicode.Greek, r):
+				counts["Greek"]++
+			case unicode.Is(unicode.Hebrew, r):
+				counts["Hebrew"]++
+			default:
+				counts[""]++
+			}
+		}
+	}
+	// 日文混用汉字与假名：假名占比达到一成即判定为日文。
+	if counts["Japanese"] > 0 && counts["Japanese"]*10 >= counts["Japanese"]+counts["Chinese"] {
+		return "Japanese"
+	}
+	counts["Chinese"] += counts["Japanese"]
+	delete(counts, "Japanese")
+
+	language, best := "", 0
+	for name, count := range counts {
+		if count > best || (count == best && name < language) {
+			language, best = name, count
+		}
+	}
+	return language
+}
+
+func openAICompactLanguageInstruction(language string) string {
+	if language == "" {
+		return "Write the compaction summary in the same language the user uses in the conversation."
+	}
+	return "Write the compaction summary in " + language + ", the language the user uses in the conversation."
+}
+
+// appendOpenAICompactInstruction 把额外要求追加到 instructions 末尾。
+func appendOpenAICompactInstruction(body []byte, hint string) []byte {
+	instructions := strings.TrimSpace(gjson.GetBytes(body, "instructions").String())
+	if instructions != "" {
+		instructions += "\n\n"
+	}
+	patched, err := sjson.SetBytes(body, "instructions", instructions+hint)
+	if err != nil {
+		return body
+	}
+	return patched
+}
+
+func openAICompactGuardrailAttemptLabel(g *openAICompactGuardrail) string {
+	return strconv.Itoa(g.attempt) + "/" + strconv.Itoa(g.maxRetries)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestAssessOpenAICompactSummary(t *testing.T) {
+	summary := func(text string) []byte {
+		return []byte(`{"output":[{"type":"compaction","summary":[{"type":"summary_text","text":"` + text + `"}]}]}`)
+	}
+	good := "User is refactoring the billing service; tests pass, migration pending."
+
+	require.Equal(t, openAICompactSummaryMissing, assessOpenAICompactSummary([]byte(`{"output":[]}`), 10, 0))
+	require.Equal(t, openAICompactSummaryMissing, assessOpenAICompactSummary([]byte(`{"output":[{"type":"message"}]}`), 10, 0))
+	require.Equal(t, openAICompactSummaryMissing, assessOpenAICompactSummary([]byte(`{"output":[{"type":"compaction","encrypted_content":" "}]}`), 10, 0))
+	require.Equal(t, openAICompactSummaryMissing, assessOpenAICompactSummary([]byte(`data: {}`), 10, 0))
+	require.Equal(t, openAICompactSummaryOK, assessOpenAICompactSummary([]byte(`{"output":[{"type":"compaction","encrypted_content":"x"}]}`), 10, 0), "encrypted-only summaries cannot be measured")
+	require.Equal(t, openAICompactSummaryOK, assessOpenAICompactSummary(summary(good), 10, 0))
+	require.Equal(t, openAICompactSummaryTooShort, assessOpenAICompactSummary(summary("ok"), 10, 0))
+	require.Equal(t, openAICompactSummaryTooShort, assessOpenAICompactSummary(summary(strings.Repeat("summary ", 20)), 10, 0))
+	require.Equal(t, openAICompactSummaryTooShort, assessOpenAICompactSummary(summary(strings.Repeat(".", 40)), 10, 0))
+	require.Equal(t, openAICompactSummaryTooLong, assessOpenAICompactSummary(summary(good), 10, 20))
+	require.Equal(t, openAICompactSummaryOK, assessOpenAICompactSummary([]byte(`{"output":[{"type":"compaction_summary","summary":"`+good+`"}]}`), 10, 0))
+}
+
+func TestDetectOpenAIConversationLanguage(t *testing.T) {
+	input := func(items string) []byte { return []byte(`{"input":[` + items + `]}`) }
+
+	require.Equal(t, "Chinese", detectOpenAIConversationLanguage(input(`{"role":"user","content":"帮我重构计费模块"}`)))
+	require.Equal(t, "Japanese", detectOpenAIConversationLanguage(input(`{"role":"user","content":[{"type":"input_text","text":"請求モジュールをリファクタリングしてください"}]}`)))
+	require.Equal(t, "Korean", detectOpenAIConversationLanguage(input(`{"role":"user","content":"결제 모듈을 리팩터링해 주세요"}`)))
+	require.Equal(t, "Russian", detectOpenAIConversationLanguage(input(`{"role":"user","content":"Перепиши модуль оплаты"}`)))
+	require.Equal(t, "", detectOpenAIConversationLanguage(input(`{"role":"user","content":"Refactor the billing module"}`)))
+	// 助手输出与工具结果不参与识别
+	require.Equal(t, "", detectOpenAIConversationLanguage(input(`{"role":"user","content":"Refactor the billing module"},{"role":"assistant","content":"好的，我来重构计费模块"}`)))
+}
+
+func newCompactGuardrailTestService(upstream HTTPUpstream, maxRetries int) *OpenAIGatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.CompactGuardrails = config.GatewayCompactGuardrailsConfig{Enabled: true, MinSummaryChars: 20, MaxRetries: maxRetries, LanguageMatching: true}
+	return &OpenAIGatewayService{cfg: cfg, httpUpstream: upstream}
+}
+
+func newCompactGuardrailTestContext(body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses/compact", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, rec
+}
+
+func compactGuardrailResponse(output string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"resp_1","status":"completed","model":"gpt-5.4","output":` + output + `,"usage":{"input_tokens":1,"output_tokens":1}}`)),
+	}
+}
+
+var compactGuardrailTestAccount = &Account{
+	ID:          1,
+	Name:        "openai-apikey",
+	Platform:    PlatformOpenAI,
+	Type:        AccountTypeAPIKey,
+	Concurrency: 1,
+	Credentials: map[string]any{"api_key": "sk-test"},
+	Status:      StatusActive,
+	Schedulable: true,
+}
+
+func TestOpenAIGatewayService_Forward_CompactGuardrailRetriesDegenerateSummary(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","instructions":"compact-test","input":[{"role":"user","content":"帮我重构计费模块"}]}`)
+	c, rec := newCompactGuardrailTestContext(body)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		compactGuardrailResponse(`[]`),
+		compactGuardrailResponse(`[{"type":"compaction","summary":[{"type":"summary_text","text":"用户正在重构计费模块，测试已通过，迁移脚本待补充。"}]}]`),
+	}}
+	svc := newCompactGuardrailTestService(upstream, 1)
+
+	result, err := svc.Forward(context.Background(), c, compactGuardrailTestAccount, body)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "迁移脚本待补充")
+	require.Len(t, upstream.bodies, 2)
+
+	first := gjson.GetBytes(upstream.bodies[0], "instructions").String()
+	require.True(t, strings.HasPrefix(first, "compact-test"))
+	require.Contains(t, first, "Chinese")
+	retried := gjson.GetBytes(upstream.bodies[1], "instructions").String()
+	require.True(t, strings.HasPrefix(retried, first))
+	require.Contains(t, retried, "returned no summary")
+}
+
+func TestOpenAIGatewayService_Forward_CompactGuardrailExhaustedRetries(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","instructions":"compact-test","input":"hello"}`)
+
+	c, rec := newCompactGuardrailTestContext(body)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{compactGuardrailResponse(`[]`), compactGuardrailResponse(`[{"type":"message"}]`)}}
+	_, err := newCompactGuardrailTestService(upstream, 1).Forward(context.Background(), c, compactGuardrailTestAccount, body)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Equal(t, openAICompactMissingContentMessage, gjson.Get(rec.Body.String(), "error.message").String())
+	require.Len(t, upstream.bodies, 2)
+
+	// 摘要存在但仍过短时不再 502，原样返回
+	c, rec = newCompactGuardrailTestContext(body)
+	short := `[{"type":"compaction","summary":[{"type":"summary_text","text":"short"}]}]`
+	upstream = &httpUpstreamRecorder{responses: []*http.Response{compactGuardrailResponse(short), compactGuardrailResponse(short)}}
+	_, err = newCompactGuardrailTestService(upstream, 1).Forward(context.Background(), c, compactGuardrailTestAccount, body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"short"`)
+	require.Len(t, upstream.bodies, 2)
+	require.Contains(t, gjson.GetBytes(upstream.bodies[1], "instructions").String(), "at least 20 characters")
+}
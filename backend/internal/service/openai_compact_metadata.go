@@ -0,0 +1,78 @@
+package service
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// openAICompactInputEstimateKey 保存 compact 请求的输入 token 估算值，
+// 上游 usage 缺失 input_tokens 时作为 compaction_metadata.original_tokens 的兜底。
+const openAICompactInputEstimateKey = "openai_compact_input_estimate"
+
+// OpenAICompactionMetadata 是附带在 compact 响应中的非敏感元数据，客户端据此判断压缩是否划算。
+// 摘要本身（encrypted_content）不参与计算也不会被解密。
+type OpenAICompactionMetadata struct {
+	OriginalTokens          int     `json:"original_tokens"`
+	OriginalTokensEstimated bool    `json:"original_tokens_estimated,omitempty"`
+	SummaryTokens           int     `json:"summary_tokens"`
+	CompressionRatio        float64 `json:"compression_ratio,omitempty"`
+	Model                   string  `json:"model,omitempty"`
+}
+
+// markOpenAICompactInputEstimate 为开启元数据的 compact 请求记录输入 token 估算值。
+func (s *OpenAIGatewayService) markOpenAICompactInputEstimate(c *gin.Context, body []byte, isCompact bool) {
+	if c == nil || !isCompact || s.cfg == nil || !s.cfg.Gateway.CompactResponseMetadata {
+		return
+	}
+	estimate := estimateTokensForText(gjson.GetBytes(body, "instructions").String()) +
+		estimateTokensForText(gjson.GetBytes(body, "input").Raw)
+	c.Set(openAICompactInputEstimateKey, estimate)
+}
+
+// attachOpenAICompactMetadata 在 compact 响应写回前附加 compaction_metadata。
+// 非 compact 请求、未开启、非 2xx 或响应中没有 compaction item 时原样返回。
+func (s *OpenAIGatewayService) attachOpenAICompactMetadata(c *gin.Context, statusCode int, body []byte, usage *OpenAIUsage) []byte {
+	if c == nil || s.cfg == nil || !s.cfg.Gateway.CompactResponseMetadata || !isOpenAIResponsesCompactPath(c) {
+		return body
+	}
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices || !gjson.ValidBytes(body) {
+		return body
+	}
+	var item gjson.Result
+	for _, candidate := range gjson.GetBytes(body, "output").Array() {
+		if isResponsesCompactionItemType(candidate.Get("type").String()) {
+			item = candidate
+			break
+		}
+	}
+	if !item.Exists() {
+		return body
+	}
+
+	meta := OpenAICompactionMetadata{Model: gjson.GetBytes(body, "model").String()}
+	if usage != nil {
+		meta.OriginalTokens = usage.InputTokens
+		meta.SummaryTokens = max(usage.OutputTokens-usage.ReasoningTokens, 0)
+	}
+	if meta.OriginalTokens <= 0 {
+		if value, ok := c.Get(openAICompactInputEstimateKey); ok {
+			meta.OriginalTokens, _ = value.(int)
+			meta.OriginalTokensEstimated = meta.OriginalTokens > 0
+		}
+	}
+	if meta.SummaryTokens <= 0 {
+		meta.SummaryTokens = estimateTokensForText(extractOpenAICompactSummaryText(item))
+	}
+	if meta.OriginalTokens > 0 {
+		meta.CompressionRatio = math.Round(float64(meta.SummaryTokens)/float64(meta.OriginalTokens)*10000) / 10000
+	}
+	patched, err := sjson.SetBytes(body, "compaction_metadata", meta)
+	if err != nil {
+		return body
+	}
+	return patched
+}
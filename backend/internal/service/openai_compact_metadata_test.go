@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIGatewayService_Forward_CompactResponseCarriesMetadata(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","instructions":"compact-test","input":"hello"}`)
+	c, rec := newCompactGuardrailTestContext(body)
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body: io.NopCloser(strings.NewReader(`{"id":"resp_1","status":"completed","model":"gpt-5.4","output":[{"type":"compaction","encrypted_content":"secret"}],` +
+			`"usage":{"input_tokens":1000,"output_tokens":120,"output_tokens_details":{"reasoning_tokens":20}}}`)),
+	}}
+	cfg := &config.Config{}
+	cfg.Gateway.CompactResponseMetadata = true
+	svc := &OpenAIGatewayService{cfg: cfg, httpUpstream: upstream}
+
+	_, err := svc.Forward(context.Background(), c, compactGuardrailTestAccount, body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"original_tokens":1000,"summary_tokens":100,"compression_ratio":0.1,"model":"gpt-5.4"}`, gjson.Get(rec.Body.String(), "compaction_metadata").Raw)
+	require.Equal(t, "secret", gjson.Get(rec.Body.String(), "output.0.encrypted_content").String())
+}
+
+func TestAttachOpenAICompactMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newContext := func(path string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, path, nil)
+		return c
+	}
+	cfg := &config.Config{}
+	cfg.Gateway.CompactResponseMetadata = true
+	svc := &OpenAIGatewayService{cfg: cfg}
+	response := []byte(`{"model":"gpt-5.4","output":[{"type":"compaction","summary":[{"type":"summary_text","text":"` + strings.Repeat("abcd", 50) + `"}]}]}`)
+
+	// 上游未返回 usage 时按请求体与摘要明文估算
+	c := newContext("/v1/responses/compact")
+	svc.markOpenAICompactInputEstimate(c, []byte(`{"input":"`+strings.Repeat("word ", 400)+`"}`), true)
+	patched := svc.attachOpenAICompactMetadata(c, http.StatusOK, response, nil)
+	meta := gjson.GetBytes(patched, "compaction_metadata")
+	require.True(t, meta.Get("original_tokens_estimated").Bool())
+	require.Positive(t, meta.Get("original_tokens").Int())
+	require.Equal(t, int64(50), meta.Get("summary_tokens").Int())
+	require.Positive(t, meta.Get("compression_ratio").Float())
+
+	// 非 compact 请求、非 2xx、无 compaction item、未开启时原样返回
+	require.Equal(t, response, svc.attachOpenAICompactMetadata(newContext("/v1/responses"), http.StatusOK, response, nil))
+	require.Equal(t, response, svc.attachOpenAICompactMetadata(c, http.StatusBadRequest, response, nil))
+	noItem := []byte(`{"output":[{"type":"message"}]}`)
+	require.Equal(t, noItem, svc.attachOpenAICompactMetadata(c, http.StatusOK, noItem, nil))
+	disabled := &OpenAIGatewayService{cfg: &config.Config{}}
+	require.Equal(t, response, disabled.attachOpenAICompactMetadata(c, http.StatusOK, response, nil))
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// compactionTokenPrefix 标记由网关包装过的压缩 token；不带前缀的视为开启绑定前签发的旧 token。
+const compactionTokenPrefix = "s2a1."
+
+var (
+	// ErrCompactionTokenMismatch 表示压缩 token 不是为当前分组/API Key 签发的（或已被篡改）。
+	ErrCompactionTokenMismatch = errors.New("compaction encrypted_content was not issued for this API key")
+	// ErrCompactionTokenLegacy 表示收到未包装的旧 token 且配置不再接受。
+	ErrCompactionTokenLegacy = errors.New("unbound compaction encrypted_content is no longer accepted, please compact the conversation again")
+)
+
+// compactionTokenAAD 返回当前请求的绑定上下文（GCM 附加认证数据）；请求未鉴权时返回 false。
+func (s *OpenAIGatewayService) compactionTokenAAD(c *gin.Context) ([]byte, bool) {
+	apiKey := getAPIKeyFromContext(c)
+	if apiKey == nil {
+		return nil, false
+	}
+	if s.cfg.Gateway.CompactionTokenBinding.Scope == "group" && apiKey.GroupID != nil {
+		return []byte("sub2api-compaction:group:" + strconv.FormatInt(*apiKey.GroupID, 10)), true
+	}
+	return []byte("sub2api-compaction:api_key:" + strconv.FormatInt(apiKey.ID, 10)), true
+}
+
+func (s *OpenAIGatewayService) compactionTokenBindingEnabled() bool {
+	return s.cfg != nil && s.cfg.Gateway.CompactionTokenBinding.Enabled
+}
+
+func (s *OpenAIGatewayService) compactionTokenCipher() (cipher.AEAD, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(s.cfg.Gateway.CompactionTokenBinding.Key))
+	if err != nil {
+		return nil, fmt.Errorf("decode compaction token key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// bindOpenAICompactionTokens 在 compact 响应写回前包装 output 中 compaction item 的 encrypted_content。
+// 包装失败时原样返回，客户端拿到的仍是可用的旧格式 token。
+func (s *OpenAIGatewayService) bindOpenAICompactionTokens(c *gin.Context, statusCode int, body []byte) []byte {
+	if !s.compactionTokenBindingEnabled() || c == nil || !isOpenAIResponsesCompactPath(c) {
+		return body
+	}
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices || !gjson.ValidBytes(body) {
+		return body
+	}
+	aad, ok := s.compactionTokenAAD(c)
+	if !ok {
+		return body
+	}
+	aead, err := s.compactionTokenCipher()
+	if err != nil {
+		return body
+	}
+	for i, item := range gjson.GetBytes(body, "output").Array() {
+		token := item.Get("encrypted_content").String()
+		if !isResponsesCompactionItemType(item.Get("type").String()) || token == "" || strings.HasPrefix(token, compactionTokenPrefix) {
+			continue
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return body
+		}
+		sealed := aead.Seal(nonce, nonce, []byte(token), aad)
+		patched, err := sjson.SetBytes(body, "output."+strconv.Itoa(i)+".encrypted_content", compactionTokenPrefix+base64.RawURLEncoding.EncodeToString(sealed))
+		if err != nil {
+			return body
+		}
+		body = patched
+	}
+	return body
+}
+
+// unbindOpenAICompactionTokens 把请求 input 中由网关包装的 compaction token 还原为上游 token。
+// 绑定上下文不符（其他分组/API Key 签发或被篡改）时返回 ErrCompactionTokenMismatch；
+// 未包装的旧 token 按 accept_legacy_tokens 放行或返回 ErrCompactionTokenLegacy。
+func (s *OpenAIGatewayService) unbindOpenAICompactionTokens(c *gin.Context, body []byte) ([]byte, error) {
+	if !s.compactionTokenBindingEnabled() || len(body) == 0 {
+		return body, nil
+	}
+	input := gjson.GetBytes(body, "input")
+	if !input.IsArray() {
+		return body, nil
+	}
+	var aead cipher.AEAD
+	var aad []byte
+	for i, item := range input.Array() {
+		token := item.Get("encrypted_content").String()
+		if !isResponsesCompactionItemType(item.Get("type").String()) || token == "" {
+			continue
+		}
+		if !strings.HasPrefix(token, compactionTokenPrefix) {
+			if s.cfg.Gateway.CompactionTokenBinding.AcceptLegacyTokens {
+				continue
+			}
+			return nil, ErrCompactionTokenLegacy
+		}
+		if aead == nil {
+			var ok bool
+			if aad, ok = s.compactionTokenAAD(c); !ok {
+				return nil, ErrCompactionTokenMismatch
+			}
+			var err error
+			if aead, err = s.compactionTokenCipher(); err != nil {
+				return nil, err
+			}
+		}
+		sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, compactionTokenPrefix))
+		if err != nil || len(sealed) < aead.NonceSize() {
+			return nil, ErrCompactionTokenMismatch
+		}
+		plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], aad)
+		if err != nil {
+			return nil, ErrCompactionTokenMismatch
+		}
+		if body, err = sjson.SetBytes(body, "input."+strconv.Itoa(i)+".encrypted_content", string(plain)); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
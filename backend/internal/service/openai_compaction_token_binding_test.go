@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newCompactionTokenBindingService(scope string, acceptLegacy bool) *OpenAIGatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.CompactionTokenBinding = config.GatewayCompactionTokenBindingConfig{
+		Enabled:            true,
+		Scope:              scope,
+		Key:                strings.Repeat("ab", 32),
+		AcceptLegacyTokens: acceptLegacy,
+	}
+	return &OpenAIGatewayService{cfg: cfg}
+}
+
+func newCompactionTokenContext(path string, apiKeyID int64, groupID *int64) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, path, nil)
+	c.Set("api_key", &APIKey{ID: apiKeyID, GroupID: groupID})
+	return c
+}
+
+func TestCompactionTokenBinding_RoundTripWithinScope(t *testing.T) {
+	groupID := int64(3)
+	otherGroupID := int64(4)
+	svc := newCompactionTokenBindingService("group", true)
+	response := []byte(`{"output":[{"type":"message"},{"type":"compaction","encrypted_content":"upstream-token"}]}`)
+
+	bound := svc.bindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses/compact", 1, &groupID), http.StatusOK, response)
+	token := gjson.GetBytes(bound, "output.1.encrypted_content").String()
+	require.True(t, strings.HasPrefix(token, compactionTokenPrefix))
+	require.NotContains(t, token, "upstream-token")
+	require.Equal(t, bound, svc.bindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses/compact", 1, &groupID), http.StatusOK, bound), "already bound tokens are left untouched")
+
+	request := []byte(`{"input":[{"role":"user","content":"hi"},{"type":"compaction","encrypted_content":"` + token + `"}]}`)
+	// 同分组的其他 API Key 可以复用
+	unbound, err := svc.unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 2, &groupID), request)
+	require.NoError(t, err)
+	require.Equal(t, "upstream-token", gjson.GetBytes(unbound, "input.1.encrypted_content").String())
+
+	_, err = svc.unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 1, &otherGroupID), request)
+	require.ErrorIs(t, err, ErrCompactionTokenMismatch)
+	_, err = svc.unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 1, nil), request)
+	require.ErrorIs(t, err, ErrCompactionTokenMismatch)
+
+	tampered := []byte(strings.Replace(string(request), token, token[:len(token)-2]+"AA", 1))
+	_, err = svc.unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 1, &groupID), tampered)
+	require.ErrorIs(t, err, ErrCompactionTokenMismatch)
+}
+
+func TestCompactionTokenBinding_APIKeyScopeAndLegacyTokens(t *testing.T) {
+	groupID := int64(3)
+	svc := newCompactionTokenBindingService("api_key", false)
+	bound := svc.bindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses/compact", 1, &groupID), http.StatusOK,
+		[]byte(`{"output":[{"type":"compaction_summary","encrypted_content":"upstream-token"}]}`))
+	request := []byte(`{"input":[{"type":"compaction_summary","encrypted_content":"` + gjson.GetBytes(bound, "output.0.encrypted_content").String() + `"}]}`)
+
+	_, err := svc.unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 2, &groupID), request)
+	require.ErrorIs(t, err, ErrCompactionTokenMismatch, "api_key scope does not share tokens within a group")
+	unbound, err := svc.unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 1, &groupID), request)
+	require.NoError(t, err)
+	require.Equal(t, "upstream-token", gjson.GetBytes(unbound, "input.0.encrypted_content").String())
+
+	legacy := []byte(`{"input":[{"type":"compaction","encrypted_content":"upstream-token"}]}`)
+	_, err = svc.unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 1, &groupID), legacy)
+	require.ErrorIs(t, err, ErrCompactionTokenLegacy)
+	accepted, err := newCompactionTokenBindingService("api_key", true).unbindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 1, &groupID), legacy)
+	require.NoError(t, err)
+	require.Equal(t, legacy, accepted)
+
+	// 非 compact 请求的响应、失败响应不包装
+	response := []byte(`{"output":[{"type":"compaction","encrypted_content":"upstream-token"}]}`)
+	require.Equal(t, response, svc.bindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses", 1, &groupID), http.StatusOK, response))
+	require.Equal(t, response, svc.bindOpenAICompactionTokens(newCompactionTokenContext("/v1/responses/compact", 1, &groupID), http.StatusBadRequest, response))
+}
+
+func TestOpenAIGatewayService_Forward_CompactionTokenBinding(t *testing.T) {
+	groupID := int64(3)
+	svc := newCompactionTokenBindingService("group", false)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"resp_1","status":"completed","model":"gpt-5.4","output":[{"type":"compaction","encrypted_content":"upstream-token"}],"usage":{"input_tokens":1,"output_tokens":1}}`)),
+	}, {
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"resp_2","status":"completed","model":"gpt-5.4","output":[],"usage":{"input_tokens":1,"output_tokens":1}}`)),
+	}}}
+	svc.httpUpstream = upstream
+
+	compactBody := []byte(`{"model":"gpt-5.4","instructions":"compact-test","input":"hello"}`)
+	c, rec := newCompactGuardrailTestContext(compactBody)
+	c.Set("api_key", &APIKey{ID: 1, GroupID: &groupID})
+	_, err := svc.Forward(context.Background(), c, compactGuardrailTestAccount, compactBody)
+	require.NoError(t, err)
+	token := gjson.Get(rec.Body.String(), "output.0.encrypted_content").String()
+	require.True(t, strings.HasPrefix(token, compactionTokenPrefix))
+
+	nextBody := []byte(`{"model":"gpt-5.4","instructions":"test","input":[{"type":"compaction","encrypted_content":"` + token + `"}]}`)
+	c, _ = newCompactGuardrailTestContext(nextBody)
+	c.Request.URL.Path = "/v1/responses"
+	c.Set("api_key", &APIKey{ID: 1, GroupID: &groupID})
+	_, err = svc.Forward(context.Background(), c, compactGuardrailTestAccount, nextBody)
+	require.NoError(t, err)
+	require.Equal(t, "upstream-token", gjson.GetBytes(upstream.lastBody, "input.0.encrypted_content").String())
+
+	// 其他分组重放被拒绝，不请求上游
+	otherGroupID := int64(4)
+	c, rec = newCompactGuardrailTestContext(nextBody)
+	c.Request.URL.Path = "/v1/responses"
+	c.Set("api_key", &APIKey{ID: 9, GroupID: &otherGroupID})
+	_, err = svc.Forward(context.Background(), c, compactGuardrailTestAccount, nextBody)
+	require.ErrorIs(t, err, ErrCompactionTokenMismatch)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Len(t, upstream.requests, 2)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// OpenAIFileListDefaultLimit / OpenAIFileListMaxLimit 与上游 GET /v1/files 的 limit 取值一致。
+	OpenAIFileListDefaultLimit = 10000
+	OpenAIFileListMaxLimit     = 10000
+)
+
+// openAIBatchFileIDFields 是批次对象中引用文件的字段，返回客户端前统一换成 PublicID。
+var openAIBatchFileIDFields = []string{"input_file_id", "output_file_id", "error_file_id"}
+
+// NewOpenAIFilePublicID 生成返回给客户端的文件 ID。上游文件 ID 只在网关内部使用，
+// 客户端拿到的 ID 与账号无关，换账号/换上游不会暴露给客户端。
+func NewOpenAIFilePublicID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "file-" + hex.EncodeToString(b[:]), nil
+}
+
+// OpenAIFileListFilter 是 GET /v1/files 的查询参数。
+// After 为客户端可见的文件 ID；Limit 由调用方保证在 1..OpenAIFileListMaxLimit 内。
+type OpenAIFileListFilter struct {
+	Purpose   string
+	After     string
+	Limit     int
+	Ascending bool
+}
+
+// OpenAIFileInfo 是上游文件对象中需要在本地留存的元数据。
+type OpenAIFileInfo struct {
+	ID       string
+	Status   string
+	Purpose  string
+	Filename string
+	Bytes    int64
+}
+
+// ParseOpenAIFileInfo 解析上游文件对象。
+func ParseOpenAIFileInfo(body []byte) OpenAIFileInfo {
+	if !gjson.ValidBytes(body) {
+		return OpenAIFileInfo{}
+	}
+	return OpenAIFileInfo{
+		ID:       strings.TrimSpace(gjson.GetBytes(body, "id").String()),
+		Status:   strings.TrimSpace(gjson.GetBytes(body, "status").String()),
+		Purpose:  strings.TrimSpace(gjson.GetBytes(body, "purpose").String()),
+		Filename: gjson.GetBytes(body, "filename").String(),
+		Bytes:    gjson.GetBytes(body, "bytes").Int(),
+	}
+}
+
+// RewriteOpenAIFileID 把上游文件对象（或删除结果）中的 id 换成 PublicID。
+func RewriteOpenAIFileID(body []byte, publicID string) ([]byte, error) {
+	if !gjson.ValidBytes(body) || !gjson.GetBytes(body, "id").Exists() {
+		return body, nil
+	}
+	return sjson.SetBytes(body, "id", publicID)
+}
+
+// RewriteOpenAIBatchFileIDs 把批次对象中引用的上游文件 ID 换成 apiKeyID 名下对应的 PublicID。
+// 未登记或不属于该 API Key 的文件 ID 置为 null，避免把上游 ID 透给客户端。
+func (s *OpenAIGatewayService) RewriteOpenAIBatchFileIDs(ctx context.Context, apiKeyID int64, body []byte) ([]byte, error) {
+	if s.batchRepo == nil || !gjson.ValidBytes(body) {
+		return body, nil
+	}
+	for _, field := range openAIBatchFileIDFields {
+		objectID := strings.TrimSpace(gjson.GetBytes(body, field).String())
+		if objectID == "" {
+			continue
+		}
+		var publicID any
+		file, err := s.batchRepo.Get(ctx, OpenAIBatchObjectFile, objectID)
+		switch {
+		case err == nil && file.APIKeyID == apiKeyID && file.PublicID != "":
+			publicID = file.PublicID
+		case err != nil && !errors.Is(err, ErrOpenAIBatchObjectNotFound):
+			return nil, err
+		}
+		if body, err = sjson.SetBytes(body, field, publicID); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// ListOpenAIFiles 列出 apiKeyID 名下的文件，返回是否还有下一页。
+// 文件列表取自本地绑定记录而非上游：共享账号下上游列表会包含其他用户的文件。
+func (s *OpenAIGatewayService) ListOpenAIFiles(ctx context.Context, apiKeyID int64, filter OpenAIFileListFilter) ([]*OpenAIBatchObject, bool, error) {
+	if s.batchRepo == nil {
+		return nil, false, nil
+	}
+	if filter.Limit <= 0 || filter.Limit > OpenAIFileListMaxLimit {
+		filter.Limit = OpenAIFileListDefaultLimit
+	}
+	limit := filter.Limit
+	filter.Limit++
+	files, err := s.batchRepo.ListFiles(ctx, apiKeyID, filter)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(files) > limit {
+		return files[:limit], true, nil
+	}
+	return files, false, nil
+}
+
+// DeleteOpenAIFile 删除文件的本地绑定，调用方需先确认上游已删除。
+func (s *OpenAIGatewayService) DeleteOpenAIFile(ctx context.Context, file *OpenAIBatchObject) error {
+	if s.batchRepo == nil || file == nil {
+		return nil
+	}
+	return s.batchRepo.Delete(ctx, file.ID)
+}
+
+type openAIFileObject struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status,omitempty"`
+}
+
+type openAIFileListResponse struct {
+	Object  string             `json:"object"`
+	Data    []openAIFileObject `json:"data"`
+	FirstID *string            `json:"first_id"`
+	LastID  *string            `json:"last_id"`
+	HasMore bool               `json:"has_more"`
+}
+
+// BuildOpenAIFileListResponse 按上游 GET /v1/files 的格式构造文件列表。
+func BuildOpenAIFileListResponse(files []*OpenAIBatchObject, hasMore bool) ([]byte, error) {
+	resp := openAIFileListResponse{Object: "list", Data: make([]openAIFileObject, 0, len(files)), HasMore: hasMore}
+	for _, file := range files {
+		resp.Data = append(resp.Data, openAIFileObject{
+			ID:        file.PublicID,
+			Object:    "file",
+			Bytes:     file.Bytes,
+			CreatedAt: file.CreatedAt.Unix(),
+			Filename:  file.Filename,
+			Purpose:   file.Purpose,
+			Status:    file.Status,
+		})
+	}
+	if n := len(resp.Data); n > 0 {
+		resp.FirstID = &resp.Data[0].ID
+		resp.LastID = &resp.Data[n-1].ID
+	}
+	return json.Marshal(resp)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestRegisterOpenAIBatchObject_AssignsStablePublicFileID(t *testing.T) {
+	repo := newOpenAIBatchRepoStub()
+	svc := &OpenAIGatewayService{accountRepo: stubOpenAIAccountRepo{accounts: []Account{{ID: 7, Platform: PlatformOpenAI}}}}
+	svc.SetOpenAIBatchRepository(repo)
+	ctx := context.Background()
+
+	file := &OpenAIBatchObject{ObjectType: OpenAIBatchObjectFile, ObjectID: "file-upstream", APIKeyID: 5, AccountID: 7, Purpose: "fine-tune"}
+	require.NoError(t, svc.RegisterOpenAIBatchObject(ctx, file))
+	require.Regexp(t, `^file-[0-9a-f]{32}$`, file.PublicID)
+	require.NotEqual(t, "file-upstream", file.PublicID)
+
+	again := &OpenAIBatchObject{ObjectType: OpenAIBatchObjectFile, ObjectID: "file-upstream", APIKeyID: 5, AccountID: 7}
+	require.NoError(t, svc.RegisterOpenAIBatchObject(ctx, again))
+	require.Equal(t, file.PublicID, again.PublicID, "re-registering keeps the first public id")
+
+	obj, account, err := svc.GetOpenAIBatchObject(ctx, 5, OpenAIBatchObjectFile, file.PublicID)
+	require.NoError(t, err)
+	require.Equal(t, "file-upstream", obj.ObjectID)
+	require.Equal(t, int64(7), account.ID)
+
+	_, _, err = svc.GetOpenAIBatchObject(ctx, 5, OpenAIBatchObjectFile, "file-upstream")
+	require.ErrorIs(t, err, ErrOpenAIBatchObjectNotFound, "raw upstream ids are not resolvable")
+	_, _, err = svc.GetOpenAIBatchObject(ctx, 6, OpenAIBatchObjectFile, file.PublicID)
+	require.ErrorIs(t, err, ErrOpenAIBatchObjectNotFound)
+
+	require.NoError(t, svc.DeleteOpenAIFile(ctx, obj))
+	_, _, err = svc.GetOpenAIBatchObject(ctx, 5, OpenAIBatchObjectFile, file.PublicID)
+	require.ErrorIs(t, err, ErrOpenAIBatchObjectNotFound)
+}
+
+func TestRewriteOpenAIBatchFileIDs(t *testing.T) {
+	repo := newOpenAIBatchRepoStub()
+	svc := &OpenAIGatewayService{}
+	svc.SetOpenAIBatchRepository(repo)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &OpenAIBatchObject{ObjectType: OpenAIBatchObjectFile, ObjectID: "file-in", PublicID: "file-pub-in", APIKeyID: 5}))
+	require.NoError(t, repo.Create(ctx, &OpenAIBatchObject{ObjectType: OpenAIBatchObjectFile, ObjectID: "file-out", PublicID: "file-pub-out", APIKeyID: 6}))
+
+	body, err := svc.RewriteOpenAIBatchFileIDs(ctx, 5, []byte(`{"id":"batch_1","input_file_id":"file-in","output_file_id":"file-out","error_file_id":null}`))
+	require.NoError(t, err)
+	require.Equal(t, "batch_1", gjson.GetBytes(body, "id").String())
+	require.Equal(t, "file-pub-in", gjson.GetBytes(body, "input_file_id").String())
+	require.Equal(t, gjson.Null, gjson.GetBytes(body, "output_file_id").Type, "files of other api keys must not leak")
+	require.Equal(t, gjson.Null, gjson.GetBytes(body, "error_file_id").Type)
+}
+
+func TestListOpenAIFiles_BuildsUpstreamShapedList(t *testing.T) {
+	repo := newOpenAIBatchRepoStub()
+	svc := &OpenAIGatewayService{}
+	svc.SetOpenAIBatchRepository(repo)
+	ctx := context.Background()
+	createdAt := time.Unix(1760000000, 0)
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, repo.Create(ctx, &OpenAIBatchObject{ObjectType: OpenAIBatchObjectFile, ObjectID: "file-" + id, PublicID: "file-pub-" + id, APIKeyID: 5, Purpose: "batch", Filename: id + ".jsonl", Bytes: 10, CreatedAt: createdAt}))
+	}
+
+	files, hasMore, err := svc.ListOpenAIFiles(ctx, 5, OpenAIFileListFilter{Limit: 2})
+	require.NoError(t, err)
+	require.True(t, hasMore)
+	require.Len(t, files, 2)
+
+	body, err := BuildOpenAIFileListResponse(files, hasMore)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"object":"list","has_more":true,"first_id":"file-pub-a","last_id":"file-pub-b","data":[
+		{"id":"file-pub-a","object":"file","bytes":10,"created_at":1760000000,"filename":"a.jsonl","purpose":"batch"},
+		{"id":"file-pub-b","object":"file","bytes":10,"created_at":1760000000,"filename":"b.jsonl","purpose":"batch"}]}`, string(body))
+
+	body, err = BuildOpenAIFileListResponse(nil, false)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"object":"list","data":[],"first_id":null,"last_id":null,"has_more":false}`, string(body))
+}
+
+func TestForwardOpenAIBatchRequest_RewritesClientResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/files/file-pub", nil)
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"file-upstream","object":"file","purpose":"batch"}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+	account := &Account{ID: 7, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "sk-test", "base_url": "https://api.openai.com/v1"}}
+
+	raw, err := svc.ForwardOpenAIBatchRequest(context.Background(), c, account, http.MethodGet, OpenAIFileEndpoint("file-upstream"), nil, "", func(body []byte) ([]byte, error) {
+		return RewriteOpenAIFileID(body, "file-pub")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "file-upstream", ParseOpenAIFileInfo(raw).ID)
+	require.Equal(t, "https://api.openai.com/v1/files/file-upstream", upstream.lastReq.URL.String())
+	require.JSONEq(t, `{"id":"file-pub","object":"file","purpose":"batch"}`, rec.Body.String())
+}
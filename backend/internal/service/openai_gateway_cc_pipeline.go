@@ -147,7 +147,8 @@ func (s *OpenAIGatewayService) resolveCCFallbackTarget(account *Account) (apiKey
 // sendCCUpstreamRequest 构建并发送 CC 上游请求：分离的上游 context、OpenAI HTTP
 // profile、标准头（含流式 Accept 切换）、客户端 header 白名单透传、自定义 UA 与
 // 账号级 header 覆写，最后经代理发出。传输层失败（DNS/TCP/TLS，无 HTTP 响应）
-// 统一由 handleOpenAIUpstreamTransportError 归一为 failover。
+// 统一由 handleOpenAIUpstreamTransportError 归一为 failover。发送经
+// doOpenAIUpstreamWithRetry 对瞬时错误原地重试，返回值中的 int 为重试次数。
 //
 // userAgent 为空时保留默认 UA；Grok 的默认 UA 兜底由调用方解析后传入。
 func (s *OpenAIGatewayService) sendCCUpstreamRequest(
@@ -159,12 +160,12 @@ func (s *OpenAIGatewayService) sendCCUpstreamRequest(
 	stream bool,
 	bearerToken string,
 	userAgent string,
-) (*http.Response, error) {
+) (*http.Response, int, error) {
 	upstreamCtx, releaseUpstreamCtx := detachUpstreamContext(ctx)
 	upstreamReq, err := http.NewRequestWithContext(upstreamCtx, http.MethodPost, targetURL, bytes.NewReader(body))
 	releaseUpstreamCtx()
 	if err != nil {
-		return nil, fmt.Errorf("build upstream request: %w", err)
+		return nil, 0, fmt.Errorf("build upstream request: %w", err)
 	}
 	upstreamReq = upstreamReq.WithContext(WithHTTPUpstreamProfile(upstreamReq.Context(), HTTPUpstreamProfileOpenAI))
 	upstreamReq.Header.Set("Content-Type", "application/json")
@@ -195,11 +196,11 @@ func (s *OpenAIGatewayService) sendCCUpstreamRequest(
 	if account.Proxy != nil {
 		proxyURL = account.Proxy.URL()
 	}
-	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	resp, retries, err := s.doOpenAIUpstreamWithRetry(ctx, c, account, upstreamReq, proxyURL, 0, nil)
 	if err != nil {
-		return nil, s.handleOpenAIUpstreamTransportError(ctx, c, account, err, false)
+		return nil, retries, s.handleOpenAIUpstreamTransportError(ctx, c, account, err, false)
 	}
-	return resp, nil
+	return resp, retries, nil
 }
 
 // ccStreamScanState 是 scanCCStream 返回的读取状态快照。
@@ -152,7 +152,7 @@ func (s *OpenAIGatewayService) forwardAsRawChatCompletions(
 	if customUA == "" && account.Platform == PlatformGrok {
 		customUA = "sub2api-grok/1.0"
 	}
-	resp, err := s.sendCCUpstreamRequest(ctx, c, account, targetURL, upstreamBody, clientStream, token, customUA)
+	resp, upstreamRetries, err := s.sendCCUpstreamRequest(ctx, c, account, targetURL, upstreamBody, clientStream, token, customUA)
 	if err != nil {
 		return nil, err
 	}
@@ -201,6 +201,7 @@ func (s *OpenAIGatewayService) forwardAsRawChatCompletions(
 		result, forwardErr = s.bufferRawChatCompletions(c, resp, originalModel, billingModel, upstreamModel, reasoningEffort, serviceTier, startTime)
 	}
 	if result != nil {
+		result.UpstreamRetries = upstreamRetries
 		addOpenAIUsage(&result.Usage, bridgeUsage)
 	}
 	return result, forwardErr
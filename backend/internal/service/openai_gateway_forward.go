@@ -18,7 +18,10 @@ import (
 )
 
 // Forward forwards request to OpenAI API
-func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, account *Account, body []byte) (*OpenAIForwardResult, error) {
+func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, account *Account, body []byte) (forwardResult *OpenAIForwardResult, retErr error) {
+	ctx, span := startGatewayForwardSpan(ctx, "openai.forward", account)
+	defer func() { endGatewayForwardSpan(span, forwardResult.upstreamRequestID(), retErr) }()
+
 	startTime := time.Now()
 
 	restrictionResult := s.detectCodexClientRestriction(c, account, body)
@@ -35,6 +38,14 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		return nil, errors.New("codex_cli_only restriction: only codex official clients are allowed")
 	}
 
+	unboundBody, err := s.unbindOpenAICompactionTokens(c, body)
+	if err != nil {
+		setOpsUpstreamError(c, http.StatusBadRequest, err.Error(), "")
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"type": "invalid_request_error", "message": err.Error()}})
+		return nil, err
+	}
+	body = unboundBody
+
 	normalizedBody, normalized, err := normalizeOpenAICodexCompactReasoningEffortForAccount(c, account, body)
 	if err != nil {
 		return nil, err
@@ -695,7 +706,15 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		return nil, wsErr
 	}
 
+	s.markOpenAICompactInputEstimate(c, body, isCompactRequest)
+	compactGuardrail, guardedBody := s.beginOpenAICompactGuardrail(c, body, isCompactRequest && !reqStream)
+	if compactGuardrail != nil {
+		body = guardedBody
+		requestView = newOpenAIRequestView(body)
+		reqBody = nil
+	}
 	httpInvalidEncryptedContentRetryTried := false
+	upstreamRetries := 0
 	for {
 		// Build upstream request
 		upstreamCtx, releaseUpstreamCtx := detachUpstreamContext(ctx)
@@ -712,9 +731,8 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		}
 
 		// Send request
-		upstreamStart := time.Now()
-		resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
-		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
+		var resp *http.Response
+		resp, upstreamRetries, err = s.doOpenAIUpstreamWithRetry(ctx, c, account, upstreamReq, proxyURL, upstreamRetries, nil)
 		if err != nil {
 			// Transport-level failure (proxy/DNS/TCP/TLS — no HTTP response). Convert to
 			// a failover so the handler switches to a healthy account, and temporarily
@@ -765,6 +783,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 					Kind:               "failover",
 					Message:            upstreamMsg,
 					Detail:             upstreamDetail,
+					RetryAttempt:       upstreamRetries,
 				})
 
 				s.handleFailoverSideEffects(ctx, resp, account, respBody, upstreamModel)
@@ -793,7 +812,9 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		imageCount := 0
 		var imageOutputSizes []string
 		if reqStream {
-			streamResult, err := s.handleStreamingResponse(ctx, resp, c, account, startTime, originalModel, upstreamModel)
+			streamCtx, streamTiming := s.streamTiming.begin(ctx, account, originalModel, resp)
+			streamResult, err := s.handleStreamingResponse(streamCtx, resp, c, account, startTime, originalModel, upstreamModel)
+			streamTiming.Finish(err, false)
 			if err != nil {
 				return nil, err
 			}
@@ -804,6 +825,13 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 			imageOutputSizes = streamResult.imageOutputSizes
 		} else {
 			nonStreamResult, err := s.handleNonStreamingResponse(ctx, resp, c, account, originalModel, upstreamModel)
+			var compactRetry *openAICompactRetryError
+			if errors.As(err, &compactRetry) {
+				body = compactGuardrail.retryBody(body, compactRetry.verdict)
+				requestView = newOpenAIRequestView(body)
+				logger.LegacyPrintf("service.openai_gateway", "[OpenAI] Retrying compact request after %s summary (attempt %s, account: %s)", compactRetry.verdict, openAICompactGuardrailAttemptLabel(compactGuardrail), account.Name)
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -827,6 +855,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		}
 
 		forwardResult := &OpenAIForwardResult{
+			SessionStrategy: openAISessionStrategyFromContext(c),
 			RequestID:       resp.Header.Get("x-request-id"),
 			ResponseID:      responseID,
 			Usage:           *usage,
@@ -839,6 +868,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 			OpenAIWSMode:    false,
 			Duration:        time.Since(startTime),
 			FirstTokenMs:    firstTokenMs,
+			UpstreamRetries: upstreamRetries,
 		}
 		if imageCount > 0 {
 			forwardResult.ImageCount = imageCount
@@ -927,8 +957,8 @@ func (s *OpenAIGatewayService) buildUpstreamRequest(ctx context.Context, c *gin.
 		} else {
 			req.Header.Set("accept", "text/event-stream")
 		}
-		if promptCacheKey != "" {
-			isolated := isolateOpenAISessionID(apiKeyID, promptCacheKey)
+		if sessionKey := s.openAIUpstreamSessionKey(c, account, promptCacheKey); sessionKey != "" {
+			isolated := isolateOpenAISessionID(apiKeyID, sessionKey)
 			req.Header.Set("session_id", isolated)
 			if !compatMessagesBridge || clientConversationID != "" {
 				req.Header.Set("conversation_id", isolated)
@@ -103,7 +103,9 @@ func (s *OpenAIGatewayService) forwardGrokResponses(
 	var firstTokenMs *int
 	responseID := ""
 	if reqStream {
-		streamResult, err := s.handleStreamingResponse(ctx, resp, c, account, startTime, originalModel, upstreamModel)
+		streamCtx, streamTiming := s.streamTiming.begin(ctx, account, originalModel, resp)
+		streamResult, err := s.handleStreamingResponse(streamCtx, resp, c, account, startTime, originalModel, upstreamModel)
+		streamTiming.Finish(err, false)
 		if err != nil {
 			return nil, err
 		}
@@ -621,6 +623,8 @@ func addOpenAIUsage(dst *OpenAIUsage, usage OpenAIUsage) {
 	dst.CacheCreationInputTokens += usage.CacheCreationInputTokens
 	dst.CacheReadInputTokens += usage.CacheReadInputTokens
 	dst.ImageOutputTokens += usage.ImageOutputTokens
+	dst.ReasoningTokens += usage.ReasoningTokens
+	dst.ToolCallTokens += usage.ToolCallTokens
 }
 
 func buildGrokResponsesRequest(ctx context.Context, c *gin.Context, account *Account, body []byte, token string) (*http.Request, error) {
@@ -72,6 +72,7 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	imageCounter := newOpenAIImageOutputCounter()
 	var firstTokenMs *int
 	responseID := ""
+	streamTiming := sseStreamTimingFromContext(ctx)
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -378,7 +379,12 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	if streamInterval <= 0 && keepaliveInterval <= 0 {
 		defer putSSEScannerBuf64K(scanBuf)
 		for scanner.Scan() {
-			processSSELine(scanner.Text(), true)
+			readAt := time.Now()
+			line := scanner.Text()
+			processSSELine(line, true)
+			if streamTiming != nil && strings.HasPrefix(line, "data:") {
+				streamTiming.ObserveEvent(readAt, time.Now())
+			}
 			if streamEarlyErr != nil {
 				return resultWithUsage(), streamEarlyErr
 			}
@@ -390,8 +396,9 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	}
 
 	type scanEvent struct {
-		line string
-		err  error
+		line   string
+		err    error
+		readAt time.Time
 	}
 	// 独立 goroutine 读取上游，避免读取阻塞影响 keepalive/超时处理
 	events := make(chan scanEvent, 16)
@@ -410,8 +417,9 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 		defer putSSEScannerBuf64K(scanBuf)
 		defer close(events)
 		for scanner.Scan() {
-			atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
-			if !sendEvent(scanEvent{line: scanner.Text()}) {
+			readAt := time.Now()
+			atomic.StoreInt64(&lastReadAt, readAt.UnixNano())
+			if !sendEvent(scanEvent{line: scanner.Text(), readAt: readAt}) {
 				return
 			}
 		}
@@ -431,6 +439,9 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 				return result, err
 			}
 			processSSELine(ev.line, len(events) == 0)
+			if streamTiming != nil && strings.HasPrefix(ev.line, "data:") {
+				streamTiming.ObserveEvent(ev.readAt, time.Now())
+			}
 			if streamEarlyErr != nil {
 				return resultWithUsage(), streamEarlyErr
 			}
@@ -711,10 +722,14 @@ func extractOpenAIUsageFromJSONBytes(body []byte) (OpenAIUsage, bool) {
 	if len(body) == 0 || !gjson.ValidBytes(body) {
 		return OpenAIUsage{}, false
 	}
-	if usage, ok := openAIUsageFromGJSON(gjson.GetBytes(body, "usage")); ok {
-		return usage, true
+	usage, ok := openAIUsageFromGJSON(gjson.GetBytes(body, "usage"))
+	if !ok {
+		usage, ok = openAIUsageFromGJSON(gjson.GetBytes(body, "response.usage"))
 	}
-	return openAIUsageFromGJSON(gjson.GetBytes(body, "response.usage"))
+	if ok {
+		usage.ToolCallTokens = openAIToolCallTokensFromJSONBytes(body)
+	}
+	return usage, ok
 }
 
 func extractOpenAIResponseIDFromJSONBytes(body []byte) string {
@@ -762,12 +777,17 @@ func openAIUsageFromGJSON(value gjson.Result) (OpenAIUsage, bool) {
 	if imageOutputTokens == 0 {
 		imageOutputTokens = value.Get("completion_tokens_details.image_tokens").Int()
 	}
+	reasoningTokens := value.Get("output_tokens_details.reasoning_tokens").Int()
+	if reasoningTokens == 0 {
+		reasoningTokens = value.Get("completion_tokens_details.reasoning_tokens").Int()
+	}
 	return OpenAIUsage{
 		InputTokens:              int(inputTokens),
 		OutputTokens:             int(outputTokens),
 		CacheCreationInputTokens: cacheCreationTokens,
 		CacheReadInputTokens:     cacheReadTokens,
 		ImageOutputTokens:        int(imageOutputTokens),
+		ReasoningTokens:          int(reasoningTokens),
 	}, true
 }
 
@@ -845,12 +865,22 @@ func (s *OpenAIGatewayService) handleNonStreamingResponse(ctx context.Context, r
 		return nil, fmt.Errorf("parse response: invalid json response")
 	}
 	usage := &usageValue
+	// 截断检测：Responses/Chat Completions 无法从预填充处续写，仅统计
+	if openAIResponseTruncated(body) {
+		recordTruncatedResponse(PlatformOpenAI)
+	}
 
 	// Replace model in response if needed
 	if originalModel != mappedModel {
 		body = s.replaceModelInResponseBody(body, mappedModel, originalModel)
 	}
 
+	if err := s.checkOpenAICompactSummary(resp, c, resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+	body = s.attachOpenAICompactMetadata(c, resp.StatusCode, body, usage)
+	body = s.bindOpenAICompactionTokens(c, resp.StatusCode, body)
+
 	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
 
 	contentType := "application/json"
@@ -936,6 +966,12 @@ func (s *OpenAIGatewayService) handleSSEToJSON(resp *http.Response, c *gin.Conte
 		body = []byte(bodyText)
 	}
 
+	if err := s.checkOpenAICompactSummary(resp, c, resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+	body = s.attachOpenAICompactMetadata(c, resp.StatusCode, body, usage)
+	body = s.bindOpenAICompactionTokens(c, resp.StatusCode, body)
+
 	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
 
 	contentType := "application/json; charset=utf-8"
@@ -727,12 +727,22 @@ func (s *OpenAIGatewayService) selectBestAccount(ctx context.Context, groupID *i
 			continue
 		}
 
+		// 分组故障转移层级优先于优先级
+		if c := compareGroupTier(fresh, selected, groupID); c != 0 {
+			if c < 0 {
+				selected = fresh
+				selectedCompactTier = compactTier
+			}
+			continue
+		}
+
 		if s.isBetterAccount(fresh, selected) {
 			selected = fresh
 			selectedCompactTier = compactTier
 		}
 	}
 
+	s.failoverTiers.observeSelected(groupID, selected)
 	return selected, compactBlocked
 }
 
@@ -841,7 +851,7 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 		if accountID > 0 && !isExcluded(accountID) {
 			account, err := s.getSchedulableAccount(ctx, accountID)
 			if err == nil {
-				clearSticky := shouldClearStickySession(account, requestedModel)
+				clearSticky := shouldClearStickySession(account, requestedModel) || s.failoverTiers.stickyDemoted(groupID, account)
 				if clearSticky {
 					_ = s.deleteStickySessionAccountID(ctx, groupID, sessionHash)
 				}
@@ -927,6 +937,8 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 	if len(candidates) == 0 {
 		return nil, ErrNoAvailableAccounts
 	}
+	// 分组故障转移层级：仅当更高层级账号全部不可用时才启用更低层级
+	candidates = s.failoverTiers.filter(groupID, candidates)
 
 	accountLoads := make([]AccountWithConcurrency, 0, len(candidates))
 	for _, acc := range candidates {
@@ -207,14 +207,20 @@ type OpenAIUsage struct {
 	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
 	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
 	ImageOutputTokens        int `json:"image_output_tokens,omitempty"`
+	// ReasoningTokens 取自 output_tokens_details.reasoning_tokens；
+	// ToolCallTokens 按响应中的工具调用参数估算。二者都包含在 OutputTokens 内。
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
+	ToolCallTokens  int `json:"tool_call_tokens,omitempty"`
 }
 
 // OpenAIForwardResult represents the result of forwarding
 type OpenAIForwardResult struct {
 	RequestID  string
 	ResponseID string
-	Usage      OpenAIUsage
-	Model      string // 原始模型（用于响应和日志显示）
+	// SessionStrategy OAuth 上游会话 ID 策略（prompt_cache_key/api_key/request），未设置会话头时为空。
+	SessionStrategy string
+	Usage           OpenAIUsage
+	Model           string // 原始模型（用于响应和日志显示）
 	// BillingModel is the model used for cost calculation.
 	// When non-empty, CalculateCost uses this instead of Model.
 	// This is set by the Anthropic Messages conversion path where
@@ -246,6 +252,12 @@ type OpenAIForwardResult struct {
 	VideoResolution    string
 	// VideoDurationSeconds 是提交时请求的生成时长（xAI 按输出秒数计费），已归一化到 1-15 秒。
 	VideoDurationSeconds int
+	// AudioDurationSeconds 是转写音频时长（秒，上游回传），AudioCharacters 是语音合成的输入字符数；
+	// 上游回传 token 用量时两者均为 0，按 token 计费。
+	AudioDurationSeconds float64
+	AudioCharacters      int
+	// UpstreamRetries 是成功前在同一账号上对瞬时错误的原地重试次数（gateway.openai_upstream_retry）。
+	UpstreamRetries int
 
 	wsReplayInput       []json.RawMessage
 	wsReplayInputExists bool
@@ -371,6 +383,27 @@ type OpenAIGatewayService struct {
 	codexSnapshotThrottle               *accountWriteThrottle
 	openaiCompatSessionResponses        sync.Map
 	openaiCompatAnthropicDigestSessions sync.Map
+	latencySLO                          *LatencySLOTracker         // 可选：账号×模型延迟 SLO 降权
+	usageWindows                        *AccountUsageWindowTracker // 可选：账号 5h/每周用量窗口
+	streamTiming                        *OpsStreamTimingRecorder   // 可选：采样流逐事件耗时
+	batchRepo                           OpenAIBatchRepository      // 可选：Batch API 对象与账号的绑定
+	metadataCache                       *upstreamMetadataCache     // 上游元数据按账号缓存（nil 表示关闭）
+	failoverTiers                       groupFailoverTierTracker   // 分组内故障转移层级状态
+}
+
+// SetLatencySLOTracker 注入账号×模型延迟 SLO 跟踪器（nil 表示关闭）。
+func (s *OpenAIGatewayService) SetLatencySLOTracker(tracker *LatencySLOTracker) {
+	s.latencySLO = tracker
+}
+
+// SetAccountUsageWindowTracker 注入账号用量窗口跟踪器（nil 表示关闭）。
+func (s *OpenAIGatewayService) SetAccountUsageWindowTracker(tracker *AccountUsageWindowTracker) {
+	s.usageWindows = tracker
+}
+
+// SetStreamTimingRecorder 注入采样流耗时记录器（nil 表示关闭）。
+func (s *OpenAIGatewayService) SetStreamTimingRecorder(recorder *OpsStreamTimingRecorder) {
+	s.streamTiming = recorder
 }
 
 // NewOpenAIGatewayService creates a new OpenAIGatewayService
@@ -432,6 +465,7 @@ func NewOpenAIGatewayService(
 		userPlatformQuotaRepo: userPlatformQuotaRepo,
 		responseHeaderFilter:  compileResponseHeaderFilter(cfg),
 		codexSnapshotThrottle: newAccountWriteThrottle(openAICodexSnapshotPersistMinInterval),
+		metadataCache:         newUpstreamMetadataCache(resolveUpstreamMetadataCacheTTL(cfg)),
 	}
 	if rateLimitService != nil {
 		rateLimitService.SetAccountRuntimeBlocker(svc)
@@ -32,6 +32,7 @@ type OpenAIRecordUsageInput struct {
 	RequestPayloadHash string
 	APIKeyService      APIKeyQuotaUpdater
 	QuotaPlatform      string // user×platform quota platform resolved by the handler before async billing.
+	ThreadID           string // 客户端会话/线程 ID（可选，用于按会话汇总成本）
 	// CyberBlocked 为 true 时把该用量行标记为 cyber（request_type=cyber），计费逻辑不变。
 	CyberBlocked bool
 	ChannelUsageFields
@@ -110,6 +111,11 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	if s.rateLimitService != nil && input.Account != nil && input.Account.Platform == PlatformOpenAI {
 		s.rateLimitService.ResetOpenAI403Counter(ctx, input.Account.ID)
 	}
+	recordOpenAISessionStrategyUsage(result.SessionStrategy, result.Usage)
+	if input.Account != nil {
+		s.latencySLO.ObserveResult(input.Account.ID, result.Model, result.Stream, result.Duration, result.FirstTokenMs)
+		s.usageWindows.Record(ctx, input.Account)
+	}
 
 	apiKey := input.APIKey
 	user := input.User
@@ -194,6 +200,7 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		).Warn("openai_usage.pricing_missing_record_zero_cost", zap.Error(err))
 		cost = &CostBreakdown{BillingMode: string(BillingModeToken)}
 	}
+	applyGroupRequestSurcharge(cost, apiKey)
 
 	// Determine billing type
 	isSubscriptionBilling := subscription != nil && apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
@@ -242,6 +249,8 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		ImageSizeSource:     optionalTrimmedStringPtr(result.ImageSizeSource),
 		ImageSizeBreakdown:  result.ImageSizeBreakdown,
 	}
+	usageLog.ReasoningTokens, usageLog.ToolCallTokens = normalizeOutputTokenBreakdown(
+		result.Usage.OutputTokens, result.Usage.ReasoningTokens, result.Usage.ToolCallTokens)
 	isVideoUsage := isGrokVideoUsageResult(result, billingModels)
 	if isVideoUsage {
 		usageLog.VideoCount = result.VideoCount
@@ -249,6 +258,14 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		videoDurationSeconds := NormalizeVideoBillingDurationSecondsOrDefault(result.VideoDurationSeconds)
 		usageLog.VideoDurationSeconds = &videoDurationSeconds
 	}
+	if result.AudioDurationSeconds > 0 {
+		audioDurationSeconds := result.AudioDurationSeconds
+		usageLog.AudioDurationSeconds = &audioDurationSeconds
+	}
+	if result.AudioCharacters > 0 {
+		audioCharacters := result.AudioCharacters
+		usageLog.AudioCharacters = &audioCharacters
+	}
 	if cost != nil {
 		usageLog.InputCost = cost.InputCost
 		usageLog.OutputCost = cost.OutputCost
@@ -301,6 +318,8 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	if input.IPAddress != "" {
 		usageLog.IPAddress = &input.IPAddress
 	}
+	usageLog.ThreadID = optionalTrimmedStringPtr(NormalizeUsageThreadID(input.ThreadID))
+	usageLog.RequestClass = optionalTrimmedStringPtr(RequestClassFromContext(ctx))
 
 	if apiKey.GroupID != nil {
 		usageLog.GroupID = apiKey.GroupID
@@ -318,6 +337,7 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	}
 
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
+		s.concurrencyService.RecordUsageLogConsumption(usageLog)
 		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.openai_gateway")
 		logger.LegacyPrintf("service.openai_gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
 		s.deferredService.ScheduleLastUsedUpdate(account.ID)
@@ -350,6 +370,7 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	if billingErr != nil {
 		return billingErr
 	}
+	s.concurrencyService.RecordUsageLogConsumption(usageLog)
 	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.openai_gateway")
 
 	return nil
@@ -372,6 +393,9 @@ func (s *OpenAIGatewayService) calculateOpenAIRecordUsageCost(
 			return s.calculateOpenAIVideoCost(ctx, billingModel, apiKey, result, videoMultiplier), nil
 		}
 	}
+	if isOpenAIAudioUnitUsageResult(result) {
+		return s.calculateOpenAIAudioCost(ctx, billingModel, apiKey, result, multiplier), nil
+	}
 	if result != nil && result.ImageCount > 0 {
 		// 渠道定价为 token 计费时走 token 路径，否则走图片计费
 		if resolved := s.resolveOpenAIChannelPricing(ctx, billingModel, apiKey); resolved == nil || resolved.Mode != BillingModeToken {
@@ -417,6 +441,11 @@ func isGrokVideoUsageResult(result *OpenAIForwardResult, billingModels []string)
 	return false
 }
 
+// isOpenAIAudioUnitUsageResult 判断是否为按秒/按字符计费的音频用量（上游未回传 token 用量）。
+func isOpenAIAudioUnitUsageResult(result *OpenAIForwardResult) bool {
+	return result != nil && (result.AudioDurationSeconds > 0 || result.AudioCharacters > 0)
+}
+
 func isUsagePricingUnavailableError(err error) bool {
 	if err == nil {
 		return false
@@ -541,6 +570,33 @@ func (s *OpenAIGatewayService) calculateOpenAIVideoCost(
 	return s.billingService.CalculateVideoCost(billingModel, resolution, videoCount, durationSeconds, groupConfig, multiplier)
 }
 
+func (s *OpenAIGatewayService) calculateOpenAIAudioCost(
+	ctx context.Context,
+	billingModel string,
+	apiKey *APIKey,
+	result *OpenAIForwardResult,
+	multiplier float64,
+) *CostBreakdown {
+	if resolved := s.resolveOpenAIChannelPricing(ctx, billingModel, apiKey); resolved != nil && resolved.Mode == BillingModePerRequest {
+		// 渠道按次定价：一次音频请求计一次，不乘时长/字符数。
+		gid := apiKey.Group.ID
+		cost, err := s.billingService.CalculateCostUnified(CostInput{
+			Ctx:            ctx,
+			Model:          billingModel,
+			GroupID:        &gid,
+			RequestCount:   1,
+			RateMultiplier: multiplier,
+			Resolver:       s.resolver,
+			Resolved:       resolved,
+		})
+		if err == nil {
+			return cost
+		}
+		logger.LegacyPrintf("service.openai_gateway", "Calculate audio channel cost failed: %v", err)
+	}
+	return s.billingService.CalculateAudioCost(billingModel, result.AudioDurationSeconds, result.AudioCharacters, multiplier)
+}
+
 func (s *OpenAIGatewayService) apiKeyWithFreshGroupMediaPricing(ctx context.Context, apiKey *APIKey) *APIKey {
 	if apiKey == nil || apiKey.GroupID == nil || *apiKey.GroupID <= 0 {
 		return apiKey
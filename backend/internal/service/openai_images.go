@@ -19,6 +19,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/gin-gonic/gin"
@@ -612,9 +613,7 @@ func (s *OpenAIGatewayService) forwardOpenAIImagesAPIKey(
 	if account.ProxyID != nil && account.Proxy != nil {
 		proxyURL = account.Proxy.URL()
 	}
-	upstreamStart := time.Now()
-	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
-	SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
+	resp, upstreamRetries, err := s.doOpenAIUpstreamWithRetry(ctx, c, account, upstreamReq, proxyURL, 0, nil)
 	if err != nil {
 		safeErr := sanitizeUpstreamErrorMessage(err.Error())
 		setOpsUpstreamError(c, 0, safeErr, "")
@@ -677,6 +676,7 @@ func (s *OpenAIGatewayService) forwardOpenAIImagesAPIKey(
 					ImageSize:        parsed.SizeTier,
 					ImageInputSize:   parsed.Size,
 					ImageOutputSizes: streamSizes,
+					UpstreamRetries:  upstreamRetries,
 				}, err
 			}
 			return nil, err
@@ -698,6 +698,7 @@ func (s *OpenAIGatewayService) forwardOpenAIImagesAPIKey(
 			ImageSize:        parsed.SizeTier,
 			ImageInputSize:   parsed.Size,
 			ImageOutputSizes: imageOutputSizes,
+			UpstreamRetries:  upstreamRetries,
 		}, nil
 	} else {
 		nonStreamUsage, nonStreamCount, nonStreamSizes, err := s.handleOpenAIImagesNonStreamingResponse(resp, c)
@@ -721,6 +722,7 @@ func (s *OpenAIGatewayService) forwardOpenAIImagesAPIKey(
 			ImageSize:        parsed.SizeTier,
 			ImageInputSize:   parsed.Size,
 			ImageOutputSizes: nonStreamSizes,
+			UpstreamRetries:  upstreamRetries,
 		}, nil
 	}
 }
@@ -875,12 +877,71 @@ func (s *OpenAIGatewayService) handleOpenAIImagesNonStreamingResponse(resp *http
 			contentType = upstreamType
 		}
 	}
-	c.Data(resp.StatusCode, contentType, body)
+	c.Data(resp.StatusCode, contentType, s.rewriteOpenAIImageResultURLs(body))
 
 	usage, _ := extractOpenAIUsageFromJSONBytes(body)
 	return usage, extractOpenAIImageCountFromJSONBytes(body), collectOpenAIResponseImageOutputSizesFromJSONBytes(body), nil
 }
 
+// rewriteOpenAIImageResultURLs 按 gateway.image_result_url_rewrites 改写图片结果中的 url（data[].url 与流式事件顶层 url），
+// 未配置或无匹配时原样返回。
+func (s *OpenAIGatewayService) rewriteOpenAIImageResultURLs(body []byte) []byte {
+	if s.cfg == nil || len(s.cfg.Gateway.ImageResultURLRewrites) == 0 || !bytes.Contains(body, []byte(`"url"`)) {
+		return body
+	}
+	paths := make([]string, 0, 2)
+	if gjson.GetBytes(body, "url").Type == gjson.String {
+		paths = append(paths, "url")
+	}
+	gjson.GetBytes(body, "data").ForEach(func(key, value gjson.Result) bool {
+		if value.Get("url").Type == gjson.String {
+			paths = append(paths, "data."+key.String()+".url")
+		}
+		return true
+	})
+	for _, path := range paths {
+		rewritten, ok := rewriteImageResultURL(s.cfg.Gateway.ImageResultURLRewrites, gjson.GetBytes(body, path).String())
+		if !ok {
+			continue
+		}
+		if next, err := sjson.SetBytes(body, path, rewritten); err == nil {
+			body = next
+		}
+	}
+	return body
+}
+
+// rewriteOpenAIImageSSELineURLs 对单行 SSE data 应用图片 URL 改写，保留原有行尾。
+func (s *OpenAIGatewayService) rewriteOpenAIImageSSELineURLs(line []byte) []byte {
+	if s.cfg == nil || len(s.cfg.Gateway.ImageResultURLRewrites) == 0 || !bytes.HasPrefix(line, []byte("data:")) {
+		return line
+	}
+	content := bytes.TrimRight(line, "\r\n")
+	data, _ := extractOpenAISSEDataLine(string(content))
+	if !gjson.Valid(data) {
+		return line
+	}
+	rewritten := s.rewriteOpenAIImageResultURLs([]byte(data))
+	if bytes.Equal(rewritten, []byte(data)) {
+		return line
+	}
+	out := make([]byte, 0, len(line)+len(rewritten)-len(data))
+	out = append(out, "data: "...)
+	out = append(out, rewritten...)
+	return append(out, line[len(content):]...)
+}
+
+// rewriteImageResultURL 取第一条前缀匹配的规则替换 URL 前缀。
+func rewriteImageResultURL(rules []config.GatewayImageURLRewriteRule, raw string) (string, bool) {
+	for _, rule := range rules {
+		from := strings.TrimSpace(rule.From)
+		if from != "" && strings.HasPrefix(raw, from) {
+			return strings.TrimSpace(rule.To) + raw[len(from):], true
+		}
+	}
+	return raw, false
+}
+
 func (s *OpenAIGatewayService) handleOpenAIImagesStreamingResponse(
 	resp *http.Response,
 	c *gin.Context,
@@ -932,7 +993,7 @@ func (s *OpenAIGatewayService) handleOpenAIImagesStreamingResponse(
 			firstTokenMs = &ms
 		}
 		if !clientDisconnected {
-			if _, writeErr := c.Writer.Write(line); writeErr != nil {
+			if _, writeErr := c.Writer.Write(s.rewriteOpenAIImageSSELineURLs(line)); writeErr != nil {
 				clientDisconnected = true
 				logger.LegacyPrintf("service.openai_gateway", "[OpenAI] Images stream client disconnected, continue draining upstream for billing")
 			} else {
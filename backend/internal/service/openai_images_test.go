@@ -1765,3 +1765,66 @@ func TestOpenAIGatewayServiceForwardImages_OAuthStreamingDrainsAfterClientDiscon
 	require.Equal(t, 9, result.Usage.OutputTokens)
 	require.Equal(t, 4, result.Usage.ImageOutputTokens)
 }
+
+func TestOpenAIGatewayServiceForwardImages_APIKeyRewritesResultURLs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := []byte(`{"model":"gpt-image-2","prompt":"draw a cat","response_format":"url"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/images/generations", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = req
+
+	cfg := &config.Config{}
+	cfg.Gateway.ImageResultURLRewrites = []config.GatewayImageURLRewriteRule{
+		{From: "https://files.upstream.example/", To: "https://cdn.example.com/images/"},
+	}
+	svc := &OpenAIGatewayService{
+		cfg: cfg,
+		httpUpstream: &httpUpstreamRecorder{
+			resp: &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body: io.NopCloser(strings.NewReader(`{"created":1710000008,"data":[` +
+					`{"url":"https://files.upstream.example/a.png?sig=1"},{"url":"https://other.example/b.png"}]}`)),
+			},
+		},
+	}
+	parsed, err := svc.ParseOpenAIImagesRequest(c, body)
+	require.NoError(t, err)
+
+	account := &Account{
+		ID:          7,
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Credentials: map[string]any{"api_key": "test-api-key", "base_url": "https://image-upstream.example/v1"},
+	}
+	result, err := svc.ForwardImages(context.Background(), c, account, body, parsed, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ImageCount)
+	require.Equal(t, "https://cdn.example.com/images/a.png?sig=1", gjson.Get(rec.Body.String(), "data.0.url").String())
+	require.Equal(t, "https://other.example/b.png", gjson.Get(rec.Body.String(), "data.1.url").String())
+}
+
+func TestRewriteOpenAIImageSSELineURLs(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.ImageResultURLRewrites = []config.GatewayImageURLRewriteRule{
+		{From: "https://files.upstream.example/", To: "https://cdn.example.com/"},
+	}
+	svc := &OpenAIGatewayService{cfg: cfg}
+
+	line := []byte("data: {\"type\":\"image_generation.completed\",\"url\":\"https://files.upstream.example/x.png\"}\r\n")
+	require.Equal(t, "data: {\"type\":\"image_generation.completed\",\"url\":\"https://cdn.example.com/x.png\"}\r\n",
+		string(svc.rewriteOpenAIImageSSELineURLs(line)))
+
+	for _, unchanged := range []string{
+		"event: image_generation.completed\n",
+		"data: [DONE]\n",
+		"data: {\"type\":\"image_generation.partial_image\",\"b64_json\":\"aGVsbG8=\"}\n",
+	} {
+		require.Equal(t, unchanged, string(svc.rewriteOpenAIImageSSELineURLs([]byte(unchanged))))
+	}
+
+	require.Equal(t, string(line), string((&OpenAIGatewayService{cfg: &config.Config{}}).rewriteOpenAIImageSSELineURLs(line)))
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OpenAI OAuth（ChatGPT 内部 API）上游 session_id / conversation_id 生成策略。
+// 不同策略影响上游 prompt cache 命中率与账号风控画像：
+//   - prompt_cache_key（默认）：按请求的 prompt_cache_key 派生，同一对话复用同一会话；
+//   - api_key：同一 API Key 的全部请求共用一个稳定会话；
+//   - request：每个请求使用新的随机会话，不与任何历史请求关联。
+//
+// 所有策略都会混入 apiKeyID 隔离，避免不同 API Key 的会话碰撞。
+const (
+	OpenAISessionStrategyPromptCacheKey = "prompt_cache_key"
+	OpenAISessionStrategyAPIKey         = "api_key"
+	OpenAISessionStrategyRequest        = "request"
+)
+
+// openAISessionStrategies 固定顺序，用于统计快照输出。
+var openAISessionStrategies = [...]string{OpenAISessionStrategyPromptCacheKey, OpenAISessionStrategyAPIKey, OpenAISessionStrategyRequest}
+
+const (
+	openAISessionStrategyContextKey  = "openai_session_strategy"
+	openAISessionRequestIDContextKey = "openai_session_request_id"
+	// openAISessionAPIKeySeed api_key 策略的固定会话种子（与 apiKeyID 一起哈希）。
+	openAISessionAPIKeySeed = "sub2api:api_key_session"
+)
+
+// NormalizeOpenAISessionStrategy 规范化策略名；未知值返回空。
+func NormalizeOpenAISessionStrategy(raw string) string {
+	strategy := strings.ToLower(strings.TrimSpace(raw))
+	for _, s := range openAISessionStrategies {
+		if strategy == s {
+			return s
+		}
+	}
+	return ""
+}
+
+// GetOpenAISessionStrategy 返回账号级会话 ID 策略覆盖（extra.openai_session_id_strategy），未设置返回空。
+func (a *Account) GetOpenAISessionStrategy() string {
+	if a == nil || !a.IsOpenAIOAuth() {
+		return ""
+	}
+	return NormalizeOpenAISessionStrategy(a.GetExtraString("openai_session_id_strategy"))
+}
+
+// openAISessionStrategy 返回账号生效的会话 ID 策略：账号覆盖优先，其次全局配置，默认 prompt_cache_key。
+func (s *OpenAIGatewayService) openAISessionStrategy(account *Account) string {
+	if strategy := account.GetOpenAISessionStrategy(); strategy != "" {
+		return strategy
+	}
+	if s == nil {
+		return defaultOpenAISessionStrategy(nil)
+	}
+	return defaultOpenAISessionStrategy(s.cfg)
+}
+
+func defaultOpenAISessionStrategy(cfg *config.Config) string {
+	if cfg != nil {
+		if strategy := NormalizeOpenAISessionStrategy(cfg.Gateway.OpenAISessionIDStrategy); strategy != "" {
+			return strategy
+		}
+	}
+	return OpenAISessionStrategyPromptCacheKey
+}
+
+// openAIUpstreamSessionKey 按账号生效的策略返回用于派生上游 session_id/conversation_id 的原始标识（尚未混入 apiKeyID）；
+// 返回空表示不设置会话头。策略仅作用于 OAuth 账号，API Key 账号保持按 prompt_cache_key 派生；
+// 生效策略写入 gin context，供用量统计按策略归因。
+func (s *OpenAIGatewayService) openAIUpstreamSessionKey(c *gin.Context, account *Account, promptCacheKey string) string {
+	if account == nil || account.Type != AccountTypeOAuth {
+		return strings.TrimSpace(promptCacheKey)
+	}
+	strategy := s.openAISessionStrategy(account)
+	if c != nil {
+		c.Set(openAISessionStrategyContextKey, strategy)
+	}
+	switch strategy {
+	case OpenAISessionStrategyAPIKey:
+		return openAISessionAPIKeySeed
+	case OpenAISessionStrategyRequest:
+		// 同一请求内的重试与多次构建上游请求复用同一随机会话
+		if c != nil {
+			if id, ok := c.Get(openAISessionRequestIDContextKey); ok {
+				if existing, _ := id.(string); existing != "" {
+					return existing
+				}
+			}
+		}
+		id := uuid.NewString()
+		if c != nil {
+			c.Set(openAISessionRequestIDContextKey, id)
+		}
+		return id
+	}
+	return strings.TrimSpace(promptCacheKey)
+}
+
+// openAISessionStrategyFromContext 返回当前请求实际生效的会话 ID 策略；未设置会话头的请求返回空。
+func openAISessionStrategyFromContext(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	v, _ := c.Get(openAISessionStrategyContextKey)
+	strategy, _ := v.(string)
+	return strategy
+}
+
+type openAISessionStrategyCounters struct {
+	requests        atomic.Int64
+	cacheHitRequest atomic.Int64
+	inputTokens     atomic.Int64
+	cacheReadTokens atomic.Int64
+}
+
+var (
+	openAISessionStrategyStats   [len(openAISessionStrategies)]openAISessionStrategyCounters
+	openAISessionStrategyStatsAt = time.Now()
+)
+
+// recordOpenAISessionStrategyUsage 按会话 ID 策略累计请求数与缓存命中（进程内统计，重启后清零）。
+func recordOpenAISessionStrategyUsage(strategy string, usage OpenAIUsage) {
+	for i, s := range openAISessionStrategies {
+		if s != strategy {
+			continue
+		}
+		counters := &openAISessionStrategyStats[i]
+		counters.requests.Add(1)
+		counters.inputTokens.Add(int64(usage.InputTokens))
+		counters.cacheReadTokens.Add(int64(usage.CacheReadInputTokens))
+		if usage.CacheReadInputTokens > 0 {
+			counters.cacheHitRequest.Add(1)
+		}
+		return
+	}
+}
+
+// OpenAISessionStrategyStats 单个会话 ID 策略的缓存命中统计。
+type OpenAISessionStrategyStats struct {
+	Strategy         string  `json:"strategy"`
+	Requests         int64   `json:"requests"`
+	CacheHitRequests int64   `json:"cache_hit_requests"`
+	InputTokens      int64   `json:"input_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	RequestHitRate   float64 `json:"request_hit_rate"` // 有缓存读取的请求占比
+	TokenHitRate     float64 `json:"token_hit_rate"`   // 缓存读取 token 占输入 token 比例
+}
+
+// OpenAISessionStrategyStatsSnapshot 会话 ID 策略统计快照。
+type OpenAISessionStrategyStatsSnapshot struct {
+	DefaultStrategy string                       `json:"default_strategy"`
+	Since           time.Time                    `json:"since"`
+	Strategies      []OpenAISessionStrategyStats `json:"strategies"`
+}
+
+// GetOpenAISessionStrategyStats 返回各会话 ID 策略的缓存命中统计（进程内、自启动以来）。
+func (s *OpsService) GetOpenAISessionStrategyStats() OpenAISessionStrategyStatsSnapshot {
+	var cfg *config.Config
+	if s != nil {
+		cfg = s.cfg
+	}
+	snapshot := OpenAISessionStrategyStatsSnapshot{
+		DefaultStrategy: defaultOpenAISessionStrategy(cfg),
+		Since:           openAISessionStrategyStatsAt,
+		Strategies:      make([]OpenAISessionStrategyStats, 0, len(openAISessionStrategies)),
+	}
+	for i, strategy := range openAISessionStrategies {
+		counters := &openAISessionStrategyStats[i]
+		stats := OpenAISessionStrategyStats{
+			Strategy:         strategy,
+			Requests:         counters.requests.Load(),
+			CacheHitRequests: counters.cacheHitRequest.Load(),
+			InputTokens:      counters.inputTokens.Load(),
+			CacheReadTokens:  counters.cacheReadTokens.Load(),
+		}
+		if stats.Requests > 0 {
+			stats.RequestHitRate = float64(stats.CacheHitRequests) / float64(stats.Requests)
+		}
+		if stats.InputTokens > 0 {
+			stats.TokenHitRate = float64(stats.CacheReadTokens) / float64(stats.InputTokens)
+		}
+		snapshot.Strategies = append(snapshot.Strategies, stats)
+	}
+	return snapshot
+}
@@ -0,0 +1,94 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpenAISessionStrategyTestContext(apiKeyID int64) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", bytes.NewReader([]byte(`{}`)))
+	c.Set("api_key", &APIKey{ID: apiKeyID})
+	return c
+}
+
+func buildOpenAISessionStrategyRequest(t *testing.T, svc *OpenAIGatewayService, c *gin.Context, account *Account, promptCacheKey string) *http.Request {
+	t.Helper()
+	req, err := svc.buildUpstreamRequest(c.Request.Context(), c, account, []byte(`{"model":"gpt-5"}`), "token", true, promptCacheKey, false)
+	require.NoError(t, err)
+	return req
+}
+
+func TestOpenAIUpstreamSessionKey_Strategies(t *testing.T) {
+	oauth := func(extra map[string]any) *Account {
+		return &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"chatgpt_account_id": "acc"}, Extra: extra}
+	}
+
+	t.Run("prompt_cache_key_default", func(t *testing.T) {
+		svc := &OpenAIGatewayService{}
+		c := newOpenAISessionStrategyTestContext(1)
+		req := buildOpenAISessionStrategyRequest(t, svc, c, oauth(nil), "pck-1")
+		require.Equal(t, isolateOpenAISessionID(1, "pck-1"), req.Header.Get("session_id"))
+		require.Equal(t, OpenAISessionStrategyPromptCacheKey, openAISessionStrategyFromContext(c))
+
+		// 无 prompt_cache_key 时不设置会话头
+		c = newOpenAISessionStrategyTestContext(1)
+		require.Empty(t, buildOpenAISessionStrategyRequest(t, svc, c, oauth(nil), "").Header.Get("session_id"))
+	})
+
+	t.Run("api_key_stable_across_prompts", func(t *testing.T) {
+		svc := &OpenAIGatewayService{cfg: &config.Config{Gateway: config.GatewayConfig{OpenAISessionIDStrategy: "api_key"}}}
+		first := buildOpenAISessionStrategyRequest(t, svc, newOpenAISessionStrategyTestContext(7), oauth(nil), "a").Header.Get("session_id")
+		second := buildOpenAISessionStrategyRequest(t, svc, newOpenAISessionStrategyTestContext(7), oauth(nil), "").Header.Get("session_id")
+		other := buildOpenAISessionStrategyRequest(t, svc, newOpenAISessionStrategyTestContext(8), oauth(nil), "a").Header.Get("session_id")
+		require.NotEmpty(t, first)
+		require.Equal(t, first, second)
+		require.NotEqual(t, first, other, "sessions stay isolated per API key")
+	})
+
+	t.Run("request_account_override", func(t *testing.T) {
+		svc := &OpenAIGatewayService{}
+		account := oauth(map[string]any{"openai_session_id_strategy": "request"})
+		c := newOpenAISessionStrategyTestContext(1)
+		first := buildOpenAISessionStrategyRequest(t, svc, c, account, "pck").Header.Get("session_id")
+		retry := buildOpenAISessionStrategyRequest(t, svc, c, account, "pck").Header.Get("session_id")
+		next := buildOpenAISessionStrategyRequest(t, svc, newOpenAISessionStrategyTestContext(1), account, "pck").Header.Get("session_id")
+		require.Equal(t, first, retry, "retries within a request reuse the session")
+		require.NotEqual(t, first, next)
+		require.NotEqual(t, isolateOpenAISessionID(1, "pck"), first)
+	})
+
+	t.Run("api_key_accounts_unaffected", func(t *testing.T) {
+		svc := &OpenAIGatewayService{cfg: &config.Config{Gateway: config.GatewayConfig{OpenAISessionIDStrategy: "request"}}}
+		c := newOpenAISessionStrategyTestContext(1)
+		require.Equal(t, "pck", svc.openAIUpstreamSessionKey(c, &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey}, "pck"))
+		require.Empty(t, openAISessionStrategyFromContext(c))
+	})
+}
+
+func TestOpsService_GetOpenAISessionStrategyStats(t *testing.T) {
+	before := (&OpsService{}).GetOpenAISessionStrategyStats()
+	recordOpenAISessionStrategyUsage(OpenAISessionStrategyAPIKey, OpenAIUsage{InputTokens: 100, CacheReadInputTokens: 80})
+	recordOpenAISessionStrategyUsage(OpenAISessionStrategyAPIKey, OpenAIUsage{InputTokens: 100})
+	recordOpenAISessionStrategyUsage("", OpenAIUsage{InputTokens: 100})
+
+	after := (&OpsService{cfg: &config.Config{Gateway: config.GatewayConfig{OpenAISessionIDStrategy: "api_key"}}}).GetOpenAISessionStrategyStats()
+	require.Equal(t, OpenAISessionStrategyAPIKey, after.DefaultStrategy)
+	require.Len(t, after.Strategies, 3)
+	stats := after.Strategies[1]
+	require.Equal(t, OpenAISessionStrategyAPIKey, stats.Strategy)
+	require.Equal(t, before.Strategies[1].Requests+2, stats.Requests)
+	require.Equal(t, before.Strategies[1].CacheHitRequests+1, stats.CacheHitRequests)
+	require.Equal(t, before.Strategies[1].CacheReadTokens+80, stats.CacheReadTokens)
+	require.Greater(t, stats.TokenHitRate, 0.0)
+	require.Equal(t, before.Strategies[0].Requests, after.Strategies[0].Requests)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// isOpenAIUpstreamRetryableResponse 判断上游错误响应是否属于可原地重试的瞬时错误。
+// 429 只有带 Retry-After 时才重试（是否过长由 openAIUpstreamRetryDelay 判断），否则直接切换账号。
+func isOpenAIUpstreamRetryableResponse(statusCode int, header http.Header, upstreamMsg string, body []byte) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	case http.StatusTooManyRequests:
+		return header.Get("Retry-After") != ""
+	}
+	return isOpenAITransientProcessingError(statusCode, upstreamMsg, body)
+}
+
+// isOpenAIUpstreamRetryableTransportError 判断传输层错误能否原地重试：客户端断开与持久性故障（代理凭证失效、DNS 等）不重试。
+func isOpenAIUpstreamRetryableTransportError(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled) && !classifyOpenAITransportError(err).Persistent
+}
+
+// openAIUpstreamRetryDelay 返回第 attempt 次重试（从 1 开始）前的等待时长。
+// 上游给出 Retry-After 时按其等待，超过 max_retry_after_seconds 时返回 false 表示放弃原地重试；
+// 否则按指数退避并叠加 ±jitter_ratio 的抖动。
+func (s *OpenAIGatewayService) openAIUpstreamRetryDelay(attempt int, header http.Header) (time.Duration, bool) {
+	retryCfg := s.cfg.Gateway.OpenAIUpstreamRetry
+	now := time.Now()
+	if resetAt := parseRetryAfterResetTime(header, now); resetAt != nil {
+		delay := resetAt.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		if delay > time.Duration(retryCfg.MaxRetryAfterSeconds)*time.Second {
+			return 0, false
+		}
+		return delay, true
+	}
+
+	initial := time.Duration(retryCfg.BackoffInitialMS) * time.Millisecond
+	backoff := initial
+	if attempt > 1 {
+		backoff = initial * time.Duration(1<<min(attempt-1, 16))
+	}
+	if maxBackoff := time.Duration(retryCfg.BackoffMaxMS) * time.Millisecond; maxBackoff > 0 && backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	jitterRatio := retryCfg.JitterRatio
+	if jitterRatio > 1 {
+		jitterRatio = 1
+	}
+	jitter := time.Duration(float64(backoff) * jitterRatio)
+	if jitter <= 0 {
+		return backoff, true
+	}
+	delay := backoff + time.Duration(rand.Int63n(int64(jitter)*2+1)) - jitter
+	if delay < 0 {
+		return 0, true
+	}
+	return delay, true
+}
+
+// waitOpenAIUpstreamRetry 在切换账号前尝试原地重试：retries 为已重试次数，statusCode 为 0 表示传输层错误。
+// 允许重试时记录 kind=retry 的 ops 事件并按退避等待，返回 true 表示应重新请求；
+// 未开启、次数耗尽、Retry-After 过长或等待期间请求被取消时返回 false，由调用方继续原有的 failover 处理。
+func (s *OpenAIGatewayService) waitOpenAIUpstreamRetry(ctx context.Context, c *gin.Context, account *Account, retries int, statusCode int, header http.Header, message string) bool {
+	if s.cfg == nil || !s.cfg.Gateway.OpenAIUpstreamRetry.Enabled || retries >= s.cfg.Gateway.OpenAIUpstreamRetry.MaxAttempts {
+		return false
+	}
+	delay, ok := s.openAIUpstreamRetryDelay(retries+1, header)
+	if !ok {
+		return false
+	}
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+		Platform:           account.Platform,
+		AccountID:          account.ID,
+		AccountName:        account.Name,
+		UpstreamStatusCode: statusCode,
+		UpstreamRequestID:  header.Get("x-request-id"),
+		Kind:               "retry",
+		Message:            message,
+		RetryAttempt:       retries + 1,
+	})
+	logger.LegacyPrintf("service.openai_gateway", "[OpenAI] Upstream transient error %d, retry %d/%d after %v (account: %s)",
+		statusCode, retries+1, s.cfg.Gateway.OpenAIUpstreamRetry.MaxAttempts, delay, account.Name)
+	return sleepWithContext(ctx, delay) == nil
+}
+
+// nextOpenAIUpstreamRetryRequest 为原地重试复制上游请求并重置请求体；重试未开启、次数耗尽
+// 或请求体无法重放（缺少 GetBody）时返回 false。
+func (s *OpenAIGatewayService) nextOpenAIUpstreamRetryRequest(req *http.Request, retries int) (*http.Request, bool) {
+	if s.cfg == nil || !s.cfg.Gateway.OpenAIUpstreamRetry.Enabled || retries >= s.cfg.Gateway.OpenAIUpstreamRetry.MaxAttempts {
+		return nil, false
+	}
+	next := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		next.Body = body
+	}
+	return next, true
+}
+
+// doOpenAIUpstreamWithRetry 发送 OpenAI HTTP 上游请求，并在切换账号前对瞬时错误原地重试。
+// 传输层错误，以及 wouldFailover 判定为需要切换账号且属于瞬时错误的响应，会按退避在同一账号上重发；
+// wouldFailover 为 nil 时使用 shouldFailoverOpenAIUpstreamResponse。retries 为此前已重试的次数，
+// 返回值中的重试次数已包含本次调用内的重试。
+//
+// 返回最后一次的响应或传输层错误，由调用方继续原有的错误处理；为判定而读取过的错误响应体
+// 会被还原，调用方仍可照常读取。每次尝试都会刷新 ops 上游耗时（不含退避等待）。
+func (s *OpenAIGatewayService) doOpenAIUpstreamWithRetry(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	req *http.Request,
+	proxyURL string,
+	retries int,
+	wouldFailover func(statusCode int, upstreamMsg string, body []byte) bool,
+) (*http.Response, int, error) {
+	if wouldFailover == nil {
+		wouldFailover = s.shouldFailoverOpenAIUpstreamResponse
+	}
+	for {
+		upstreamStart := time.Now()
+		resp, err := s.httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
+		if err != nil {
+			if !isOpenAIUpstreamRetryableTransportError(err) {
+				return nil, retries, err
+			}
+			next, ok := s.nextOpenAIUpstreamRetryRequest(req, retries)
+			if !ok || !s.waitOpenAIUpstreamRetry(ctx, c, account, retries, 0, nil, sanitizeUpstreamErrorMessage(err.Error())) {
+				return nil, retries, err
+			}
+			req = next
+			retries++
+			continue
+		}
+		if resp.StatusCode < 400 {
+			return resp, retries, nil
+		}
+		// 400 只有 server_is_overloaded 等瞬时错误码才可能重试，其余状态码无需读取响应体即可排除
+		if resp.StatusCode != http.StatusBadRequest && !isOpenAIUpstreamRetryableResponse(resp.StatusCode, resp.Header, "", nil) {
+			return resp, retries, nil
+		}
+		next, ok := s.nextOpenAIUpstreamRetryRequest(req, retries)
+		if !ok {
+			return resp, retries, nil
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, openAIUpstreamErrorBodyReadLimitForConfig(s.cfg)))
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+		if !wouldFailover(resp.StatusCode, upstreamMsg, respBody) ||
+			!isOpenAIUpstreamRetryableResponse(resp.StatusCode, resp.Header, upstreamMsg, respBody) ||
+			!s.waitOpenAIUpstreamRetry(ctx, c, account, retries, resp.StatusCode, resp.Header, upstreamMsg) {
+			return resp, retries, nil
+		}
+		req = next
+		retries++
+	}
+}
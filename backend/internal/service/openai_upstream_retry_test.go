@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpenAIUpstreamRetryService(upstream HTTPUpstream) *OpenAIGatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAIUpstreamRetry = config.GatewayOpenAIUpstreamRetryConfig{
+		Enabled:              true,
+		MaxAttempts:          2,
+		BackoffInitialMS:     1,
+		BackoffMaxMS:         2,
+		MaxRetryAfterSeconds: 10,
+	}
+	return &OpenAIGatewayService{cfg: cfg, httpUpstream: upstream}
+}
+
+func newOpenAIUpstreamRetryContext(body []byte) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c
+}
+
+func openAIUpstreamRetryErrorResponse(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"upstream unavailable","type":"server_error"}}`)),
+	}
+}
+
+func openAIUpstreamRetryOpsEvents(c *gin.Context) []*OpsUpstreamErrorEvent {
+	v, _ := c.Get(OpsUpstreamErrorsKey)
+	events, _ := v.([]*OpsUpstreamErrorEvent)
+	return events
+}
+
+func TestOpenAIGatewayService_Forward_RetriesTransientErrorInPlace(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","instructions":"test","input":"hello"}`)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusServiceUnavailable, nil),
+		{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"resp_1","status":"completed","model":"gpt-5.4","output":[],"usage":{"input_tokens":1,"output_tokens":1}}`)),
+		},
+	}}
+	c := newOpenAIUpstreamRetryContext(body)
+
+	result, err := newOpenAIUpstreamRetryService(upstream).Forward(context.Background(), c, compactGuardrailTestAccount, body)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.UpstreamRetries)
+	require.Len(t, upstream.requests, 2)
+	events := openAIUpstreamRetryOpsEvents(c)
+	require.Len(t, events, 1)
+	require.Equal(t, "retry", events[0].Kind)
+	require.Equal(t, http.StatusServiceUnavailable, events[0].UpstreamStatusCode)
+	require.Equal(t, 1, events[0].RetryAttempt)
+}
+
+func TestOpenAIGatewayService_Forward_FailsOverAfterRetriesExhausted(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","instructions":"test","input":"hello"}`)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusBadGateway, nil),
+		openAIUpstreamRetryErrorResponse(http.StatusBadGateway, nil),
+		openAIUpstreamRetryErrorResponse(http.StatusBadGateway, nil),
+	}}
+	c := newOpenAIUpstreamRetryContext(body)
+
+	_, err := newOpenAIUpstreamRetryService(upstream).Forward(context.Background(), c, compactGuardrailTestAccount, body)
+	var failoverErr *UpstreamFailoverError
+	require.True(t, errors.As(err, &failoverErr))
+	require.Equal(t, http.StatusBadGateway, failoverErr.StatusCode)
+	require.Len(t, upstream.requests, 3)
+	events := openAIUpstreamRetryOpsEvents(c)
+	require.Len(t, events, 3)
+	require.Equal(t, "failover", events[2].Kind)
+	require.Equal(t, 2, events[2].RetryAttempt)
+}
+
+func TestOpenAIGatewayService_Forward_LongRetryAfterFailsOverImmediately(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","instructions":"test","input":"hello"}`)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"60"}}),
+	}}
+	c := newOpenAIUpstreamRetryContext(body)
+
+	_, err := newOpenAIUpstreamRetryService(upstream).Forward(context.Background(), c, compactGuardrailTestAccount, body)
+	var failoverErr *UpstreamFailoverError
+	require.True(t, errors.As(err, &failoverErr))
+	require.Equal(t, http.StatusTooManyRequests, failoverErr.StatusCode)
+	require.Len(t, upstream.requests, 1)
+}
+
+func TestOpenAIGatewayService_Passthrough_RetriesCapacityErrorsOnly(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","instructions":"test","input":"hello"}`)
+	account := &Account{
+		ID:          3,
+		Name:        "openai-apikey-pass",
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Concurrency: 1,
+		Credentials: map[string]any{"api_key": "sk-test"},
+		Extra:       map[string]any{"openai_passthrough": true},
+		Status:      StatusActive,
+		Schedulable: true,
+	}
+
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(529, nil),
+		{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"resp_1","status":"completed","model":"gpt-5.4","output":[],"usage":{"input_tokens":1,"output_tokens":1}}`)),
+		},
+	}}
+	c := newOpenAIUpstreamRetryContext(body)
+	result, err := newOpenAIUpstreamRetryService(upstream).Forward(context.Background(), c, account, body)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.UpstreamRetries)
+	require.Len(t, upstream.requests, 2)
+	require.Equal(t, "retry", openAIUpstreamRetryOpsEvents(c)[0].Kind)
+
+	// 429 的 Retry-After 过长：不等待，原错误体交给 failover 处理
+	upstream = &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"60"}}),
+	}}
+	c = newOpenAIUpstreamRetryContext(body)
+	_, err = newOpenAIUpstreamRetryService(upstream).Forward(context.Background(), c, account, body)
+	var failoverErr *UpstreamFailoverError
+	require.True(t, errors.As(err, &failoverErr))
+	require.Equal(t, http.StatusTooManyRequests, failoverErr.StatusCode)
+	require.Contains(t, string(failoverErr.ResponseBody), "upstream unavailable")
+	require.Len(t, upstream.requests, 1)
+
+	// 透传模式的 5xx 不重试，原样返回客户端
+	upstream = &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusBadGateway, nil),
+	}}
+	c = newOpenAIUpstreamRetryContext(body)
+	_, _ = newOpenAIUpstreamRetryService(upstream).Forward(context.Background(), c, account, body)
+	require.Len(t, upstream.requests, 1)
+}
+
+func TestOpenAIGatewayService_DoOpenAIUpstreamWithRetry_ReplaysBodyAndKeepsLastError(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","input":"hello"}`)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusServiceUnavailable, nil),
+		openAIUpstreamRetryErrorResponse(http.StatusServiceUnavailable, nil),
+		openAIUpstreamRetryErrorResponse(http.StatusServiceUnavailable, nil),
+	}}
+	c := newOpenAIUpstreamRetryContext(body)
+	req, err := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/responses", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	svc := newOpenAIUpstreamRetryService(upstream)
+	resp, retries, err := svc.doOpenAIUpstreamWithRetry(context.Background(), c, compactGuardrailTestAccount, req, "", 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, retries)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Len(t, upstream.bodies, 3)
+	for _, sent := range upstream.bodies {
+		require.Equal(t, body, sent)
+	}
+	// 判定时读取过的错误体被还原，调用方仍可照常读取
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(respBody), "upstream unavailable")
+}
+
+func TestOpenAIGatewayService_DoOpenAIUpstreamWithRetry_SkipsNonTransientErrors(t *testing.T) {
+	body := []byte(`{"model":"gpt-5.4","input":"hello"}`)
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusBadRequest, nil),
+	}}
+	c := newOpenAIUpstreamRetryContext(body)
+	req, err := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/responses", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	resp, retries, err := newOpenAIUpstreamRetryService(upstream).doOpenAIUpstreamWithRetry(context.Background(), c, compactGuardrailTestAccount, req, "", 0, nil)
+	require.NoError(t, err)
+	require.Zero(t, retries)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Len(t, upstream.requests, 1)
+	require.Empty(t, openAIUpstreamRetryOpsEvents(c))
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_RetriesTransientErrorInPlace(t *testing.T) {
+	body := []byte(`{"model":"text-embedding-3-small","input":"hello"}`)
+	account := &Account{
+		ID:          4,
+		Name:        "openai-apikey-embeddings",
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Concurrency: 1,
+		Credentials: map[string]any{"api_key": "sk-test"},
+		Status:      StatusActive,
+		Schedulable: true,
+	}
+	upstream := &httpUpstreamRecorder{responses: []*http.Response{
+		openAIUpstreamRetryErrorResponse(http.StatusBadGateway, nil),
+		{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"object":"list","data":[],"model":"text-embedding-3-small","usage":{"prompt_tokens":2,"total_tokens":2}}`)),
+		},
+	}}
+	c := newOpenAIUpstreamRetryContext(body)
+
+	result, err := newOpenAIUpstreamRetryService(upstream).ForwardEmbeddings(context.Background(), c, account, body, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, result.UpstreamRetries)
+	require.Len(t, upstream.requests, 2)
+	require.Equal(t, body, upstream.bodies[1])
+	require.Equal(t, "retry", openAIUpstreamRetryOpsEvents(c)[0].Kind)
+}
+
+func TestOpenAIUpstreamRetryDelay(t *testing.T) {
+	svc := newOpenAIUpstreamRetryService(nil)
+	svc.cfg.Gateway.OpenAIUpstreamRetry.BackoffInitialMS = 100
+	svc.cfg.Gateway.OpenAIUpstreamRetry.BackoffMaxMS = 250
+	svc.cfg.Gateway.OpenAIUpstreamRetry.JitterRatio = 0.2
+
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 250 * time.Millisecond} {
+		delay, ok := svc.openAIUpstreamRetryDelay(attempt, nil)
+		require.True(t, ok)
+		require.InDelta(t, float64(want), float64(delay), float64(want)*0.2, "attempt %d", attempt)
+	}
+
+	delay, ok := svc.openAIUpstreamRetryDelay(1, http.Header{"Retry-After": []string{"3"}})
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, delay)
+	_, ok = svc.openAIUpstreamRetryDelay(1, http.Header{"Retry-After": []string{"30"}})
+	require.False(t, ok)
+
+	require.True(t, isOpenAIUpstreamRetryableResponse(http.StatusServiceUnavailable, nil, "", nil))
+	require.True(t, isOpenAIUpstreamRetryableResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"1"}}, "", nil))
+	require.False(t, isOpenAIUpstreamRetryableResponse(http.StatusTooManyRequests, http.Header{}, "", nil))
+	require.False(t, isOpenAIUpstreamRetryableResponse(http.StatusUnauthorized, nil, "", nil))
+	require.False(t, isOpenAIUpstreamRetryableTransportError(context.Canceled))
+	require.True(t, isOpenAIUpstreamRetryableTransportError(errors.New("read: connection reset by peer")))
+}
@@ -101,6 +101,9 @@ func (s *OpsService) GetAccountAvailabilityStats(ctx context.Context, platformFi
 			g.TotalAccounts++
 			if isAvailable {
 				g.AvailableCount++
+				if tier := acc.GroupTier(grp.ID); g.ActiveTier == nil || tier < *g.ActiveTier {
+					g.ActiveTier = &tier
+				}
 			}
 			if isRateLimited {
 				g.RateLimitCount++
@@ -199,6 +199,7 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 	eventsCreated := 0
 	eventsResolved := 0
 	emailsSent := 0
+	webhooksSent := 0
 
 	now := time.Now().UTC()
 	safeEnd := now.Truncate(time.Minute)
@@ -283,6 +284,12 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				FiredAt:        now,
 				CreatedAt:      now,
 			}
+			if accountID := parseOpsAlertRuleAccountID(rule.Filters); accountID != nil {
+				if firedEvent.Dimensions == nil {
+					firedEvent.Dimensions = map[string]any{}
+				}
+				firedEvent.Dimensions["account_id"] = *accountID
+			}
 
 			created, err := s.opsRepo.CreateAlertEvent(ctx, firedEvent)
 			if err != nil {
@@ -295,6 +302,7 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				if s.maybeSendAlertEmail(ctx, runtimeCfg, rule, created) {
 					emailsSent++
 				}
+				webhooksSent += s.maybeSendAlertWebhooks(ctx, runtimeCfg, rule, created)
 			}
 			continue
 		}
@@ -306,11 +314,14 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				logger.LegacyPrintf("service.ops_alert_evaluator", "[OpsAlertEvaluator] resolve event failed (event=%d): %v", activeEvent.ID, err)
 			} else {
 				eventsResolved++
+				activeEvent.Status = OpsAlertStatusResolved
+				activeEvent.ResolvedAt = &resolvedAt
+				webhooksSent += s.maybeSendAlertWebhooks(ctx, runtimeCfg, rule, activeEvent)
 			}
 		}
 	}
 
-	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent), 2048)
+	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d webhooks_sent=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent, webhooksSent), 2048)
 	s.recordHeartbeatSuccess(runAt, time.Since(startedAt), result)
 }
 
@@ -432,6 +443,25 @@ func parseOpsAlertRuleScope(filters map[string]any) (platform string, groupID *i
 	return platform, groupID, region
 }
 
+// parseOpsAlertRuleAccountID 读取规则 filters.account_id（仅上游 429 / 流内失败类指标使用）。
+func parseOpsAlertRuleAccountID(filters map[string]any) *int64 {
+	var id int64
+	switch t := filters["account_id"].(type) {
+	case float64:
+		id = int64(t)
+	case int64:
+		id = t
+	case int:
+		id = int64(t)
+	case string:
+		id, _ = strconv.ParseInt(strings.TrimSpace(t), 10, 64)
+	}
+	if id <= 0 {
+		return nil
+	}
+	return &id
+}
+
 func (s *OpsAlertEvaluatorService) computeRuleMetric(
 	ctx context.Context,
 	rule *OpsAlertRule,
@@ -521,6 +551,19 @@ func (s *OpsAlertEvaluatorService) computeRuleMetric(
 		return float64(countAccountsByCondition(availability.Accounts, func(acc *AccountAvailability) bool {
 			return acc.TempUnschedulableUntil != nil && now.Before(*acc.TempUnschedulableUntil)
 		})), true
+	case "group_failover_tier":
+		if groupID == nil || *groupID <= 0 {
+			return 0, false
+		}
+		if s == nil || s.opsService == nil {
+			return 0, false
+		}
+		availability, err := s.opsService.GetAccountAvailability(ctx, platform, groupID)
+		if err != nil || availability == nil || availability.Group == nil || availability.Group.ActiveTier == nil {
+			// 分组无可用账号时由 group_available_accounts 告警覆盖
+			return 0, false
+		}
+		return float64(*availability.Group.ActiveTier), true
 	case "group_rate_limit_ratio":
 		if groupID == nil || *groupID <= 0 {
 			return 0, false
@@ -581,6 +624,24 @@ func (s *OpsAlertEvaluatorService) computeRuleMetric(
 			return 0, false
 		}
 		return float64(n), true
+	case "upstream_429_count", "stream_fault_rate":
+		counts, err := s.opsRepo.GetAlertWindowCounts(ctx, &OpsAlertWindowFilter{
+			StartTime: start,
+			EndTime:   end,
+			Platform:  platform,
+			GroupID:   groupID,
+			AccountID: parseOpsAlertRuleAccountID(rule.Filters),
+		})
+		if err != nil || counts == nil {
+			return 0, false
+		}
+		if rule.MetricType == "upstream_429_count" {
+			return float64(counts.Upstream429Count), true
+		}
+		if counts.StreamRequestCount <= 0 {
+			return 0, false
+		}
+		return float64(counts.StreamFaultCount) / float64(counts.StreamRequestCount) * 100, true
 	}
 
 	overview, err := s.opsRepo.GetDashboardOverview(ctx, &OpsDashboardFilter{
@@ -662,6 +723,9 @@ func buildOpsAlertDescription(rule *OpsAlertRule, value float64, windowMinutes i
 	if groupID != nil && *groupID > 0 {
 		scope = fmt.Sprintf("%s group_id=%d", scope, *groupID)
 	}
+	if accountID := parseOpsAlertRuleAccountID(rule.Filters); accountID != nil {
+		scope = fmt.Sprintf("%s account_id=%d", scope, *accountID)
+	}
 	if windowMinutes <= 0 {
 		windowMinutes = 1
 	}
@@ -747,6 +811,19 @@ func (s *OpsAlertEvaluatorService) maybeSendAlertEmail(ctx context.Context, runt
 	return anySent
 }
 
+// maybeSendAlertWebhooks 按与邮件相同的静默策略推送 webhook，返回成功投递的渠道数。
+func (s *OpsAlertEvaluatorService) maybeSendAlertWebhooks(ctx context.Context, runtimeCfg *OpsAlertRuntimeSettings, rule *OpsAlertRule, event *OpsAlertEvent) int {
+	if s == nil || s.opsService == nil || event == nil || rule == nil {
+		return 0
+	}
+	if runtimeCfg != nil && runtimeCfg.Silencing.Enabled {
+		if isOpsAlertSilenced(time.Now().UTC(), rule, event, runtimeCfg.Silencing) {
+			return 0
+		}
+	}
+	return s.opsService.DeliverAlertWebhooks(ctx, rule, event)
+}
+
 func opsAlertEmailVariables(rule *OpsAlertRule, event *OpsAlertEvent) map[string]string {
 	variables := map[string]string{
 		"rule_name":         "-",
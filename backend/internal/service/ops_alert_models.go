@@ -93,3 +93,86 @@ type OpsAlertEventFilter struct {
 	Platform string
 	GroupID  *int64
 }
+
+const (
+	OpsAlertWebhookTypeGeneric  = "generic"
+	OpsAlertWebhookTypeSlack    = "slack"
+	OpsAlertWebhookTypeTelegram = "telegram"
+	OpsAlertWebhookTypeLark     = "lark"
+
+	// OpsAlertWebhookDeliveryTest marks deliveries triggered by the admin "send test" action.
+	OpsAlertWebhookDeliveryTest = "test"
+)
+
+// OpsAlertWebhook is an outbound alert channel. Secret holds the generic HMAC key,
+// the Lark signing secret or the Telegram bot token, and is never echoed back.
+type OpsAlertWebhook struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+
+	Secret           string `json:"-"`
+	SecretConfigured bool   `json:"secret_configured"`
+	ChatID           string `json:"chat_id,omitempty"`
+
+	Enabled bool `json:"enabled"`
+	// RuleIDs limits delivery to these rules; empty means every rule.
+	RuleIDs        []int64 `json:"rule_ids"`
+	MinSeverity    string  `json:"min_severity"`
+	NotifyResolved bool    `json:"notify_resolved"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OpsAlertWebhookInput is the admin create/update payload. A nil Secret keeps the
+// stored secret on update; a nil Enabled means enabled on create and unchanged on update.
+type OpsAlertWebhookInput struct {
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	URL            string  `json:"url"`
+	Secret         *string `json:"secret"`
+	ChatID         string  `json:"chat_id"`
+	Enabled        *bool   `json:"enabled"`
+	RuleIDs        []int64 `json:"rule_ids"`
+	MinSeverity    string  `json:"min_severity"`
+	NotifyResolved bool    `json:"notify_resolved"`
+}
+
+type OpsAlertWebhookDelivery struct {
+	ID          int64  `json:"id"`
+	WebhookID   int64  `json:"webhook_id"`
+	RuleID      *int64 `json:"rule_id,omitempty"`
+	EventID     *int64 `json:"event_id,omitempty"`
+	EventStatus string `json:"event_status"`
+
+	Success    bool   `json:"success"`
+	StatusCode *int   `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OpsAlertWebhookDeliveryFilter struct {
+	WebhookID *int64
+	EventID   *int64
+	Limit     int
+}
+
+// OpsAlertWindowFilter scopes the error-log based alert metrics (upstream 429s, stream faults).
+type OpsAlertWindowFilter struct {
+	StartTime time.Time
+	EndTime   time.Time
+
+	Platform  string
+	GroupID   *int64
+	AccountID *int64
+}
+
+type OpsAlertWindowCounts struct {
+	Upstream429Count   int64
+	StreamFaultCount   int64
+	StreamRequestCount int64
+}
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+)
+
+const (
+	opsAlertWebhookTimeout       = 10 * time.Second
+	opsAlertWebhookMaxNameLen    = 128
+	opsAlertWebhookMaxErrorLen   = 500
+	opsAlertWebhookTelegramAPI   = "https://api.telegram.org"
+	opsAlertWebhookSignatureName = "X-Sub2API-Signature"
+)
+
+var opsAlertWebhookTypes = []string{
+	OpsAlertWebhookTypeGeneric,
+	OpsAlertWebhookTypeSlack,
+	OpsAlertWebhookTypeTelegram,
+	OpsAlertWebhookTypeLark,
+}
+
+// OpsAlertWebhookPayload 通用 webhook 推送的 JSON 结构；Slack/Telegram/飞书只推送 Text。
+type OpsAlertWebhookPayload struct {
+	Event  string                      `json:"event"`
+	SentAt time.Time                   `json:"sent_at"`
+	Text   string                      `json:"text"`
+	Rule   *OpsAlertWebhookPayloadRule `json:"rule,omitempty"`
+	Alert  *OpsAlertEvent              `json:"alert,omitempty"`
+}
+
+type OpsAlertWebhookPayloadRule struct {
+	ID            int64   `json:"id"`
+	Name          string  `json:"name"`
+	Severity      string  `json:"severity"`
+	MetricType    string  `json:"metric_type"`
+	Operator      string  `json:"operator"`
+	Threshold     float64 `json:"threshold"`
+	WindowMinutes int     `json:"window_minutes"`
+}
+
+func (s *OpsService) ListAlertWebhooks(ctx context.Context) ([]*OpsAlertWebhook, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return []*OpsAlertWebhook{}, nil
+	}
+	return s.opsRepo.ListAlertWebhooks(ctx)
+}
+
+func (s *OpsService) CreateAlertWebhook(ctx context.Context, input *OpsAlertWebhookInput) (*OpsAlertWebhook, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	hook, err := normalizeOpsAlertWebhookInput(input, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.opsRepo.CreateAlertWebhook(ctx, hook)
+}
+
+func (s *OpsService) UpdateAlertWebhook(ctx context.Context, id int64, input *OpsAlertWebhookInput) (*OpsAlertWebhook, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	existing, err := s.getAlertWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	hook, err := normalizeOpsAlertWebhookInput(input, existing)
+	if err != nil {
+		return nil, err
+	}
+	hook.ID = id
+	updated, err := s.opsRepo.UpdateAlertWebhook(ctx, hook)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, infraerrors.NotFound("OPS_ALERT_WEBHOOK_NOT_FOUND", "alert webhook not found")
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *OpsService) DeleteAlertWebhook(ctx context.Context, id int64) error {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return err
+	}
+	if s.opsRepo == nil {
+		return infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if id <= 0 {
+		return infraerrors.BadRequest("INVALID_WEBHOOK_ID", "invalid webhook id")
+	}
+	if err := s.opsRepo.DeleteAlertWebhook(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return infraerrors.NotFound("OPS_ALERT_WEBHOOK_NOT_FOUND", "alert webhook not found")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *OpsService) ListAlertWebhookDeliveries(ctx context.Context, filter *OpsAlertWebhookDeliveryFilter) ([]*OpsAlertWebhookDelivery, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return []*OpsAlertWebhookDelivery{}, nil
+	}
+	return s.opsRepo.ListAlertWebhookDeliveries(ctx, filter)
+}
+
+// TestAlertWebhook 向指定渠道发送一条测试消息（忽略启用状态与规则/级别过滤），投递结果同样落库。
+func (s *OpsService) TestAlertWebhook(ctx context.Context, id int64) (*OpsAlertWebhookDelivery, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	hook, err := s.getAlertWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	rule := &OpsAlertRule{Name: "Webhook test", Severity: "P3", MetricType: "test"}
+	event := &OpsAlertEvent{
+		Severity:    "P3",
+		Status:      OpsAlertWebhookDeliveryTest,
+		Title:       "P3: Webhook test",
+		Description: "This is a test message from sub2api ops alerting.",
+		FiredAt:     now,
+		CreatedAt:   now,
+	}
+	return s.sendAlertWebhook(ctx, hook, rule, event), nil
+}
+
+// DeliverAlertWebhooks 把告警事件推送到所有匹配的 webhook 渠道，逐个记录投递结果，返回成功数。
+// 单个渠道失败不影响其它渠道，也不影响告警事件本身。
+func (s *OpsService) DeliverAlertWebhooks(ctx context.Context, rule *OpsAlertRule, event *OpsAlertEvent) int {
+	if s == nil || s.opsRepo == nil || rule == nil || event == nil {
+		return 0
+	}
+	hooks, err := s.opsRepo.ListAlertWebhooks(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.ops_alert_webhook", "[OpsAlertWebhook] list webhooks failed: %v", err)
+		return 0
+	}
+	sent := 0
+	for _, hook := range hooks {
+		if !opsAlertWebhookMatches(hook, rule, event) {
+			continue
+		}
+		if delivery := s.sendAlertWebhook(ctx, hook, rule, event); delivery.Success {
+			sent++
+		}
+	}
+	return sent
+}
+
+func (s *OpsService) getAlertWebhook(ctx context.Context, id int64) (*OpsAlertWebhook, error) {
+	if id <= 0 {
+		return nil, infraerrors.BadRequest("INVALID_WEBHOOK_ID", "invalid webhook id")
+	}
+	hook, err := s.opsRepo.GetAlertWebhookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, infraerrors.NotFound("OPS_ALERT_WEBHOOK_NOT_FOUND", "alert webhook not found")
+		}
+		return nil, err
+	}
+	if hook == nil {
+		return nil, infraerrors.NotFound("OPS_ALERT_WEBHOOK_NOT_FOUND", "alert webhook not found")
+	}
+	return hook, nil
+}
+
+func (s *OpsService) sendAlertWebhook(ctx context.Context, hook *OpsAlertWebhook, rule *OpsAlertRule, event *OpsAlertEvent) *OpsAlertWebhookDelivery {
+	delivery := &OpsAlertWebhookDelivery{
+		WebhookID:   hook.ID,
+		EventStatus: event.Status,
+	}
+	if rule.ID > 0 {
+		delivery.RuleID = &rule.ID
+	}
+	if event.ID > 0 {
+		delivery.EventID = &event.ID
+	}
+
+	startedAt := time.Now()
+	statusCode, err := s.postAlertWebhook(ctx, hook, rule, event, startedAt.UTC())
+	delivery.DurationMs = time.Since(startedAt).Milliseconds()
+	if statusCode > 0 {
+		delivery.StatusCode = &statusCode
+	}
+	if err != nil {
+		msg := err.Error()
+		if hook.Secret != "" {
+			// Telegram 的 bot token 在 URL 路径里，net/http 错误会带出完整 URL。
+			msg = strings.ReplaceAll(msg, hook.Secret, "***")
+		}
+		delivery.Error = truncateString(msg, opsAlertWebhookMaxErrorLen)
+		logger.LegacyPrintf("service.ops_alert_webhook", "[OpsAlertWebhook] delivery failed (webhook=%d event=%d): %s", hook.ID, event.ID, delivery.Error)
+	} else {
+		delivery.Success = true
+	}
+
+	if _, err := s.opsRepo.InsertAlertWebhookDelivery(ctx, delivery); err != nil {
+		logger.LegacyPrintf("service.ops_alert_webhook", "[OpsAlertWebhook] record delivery failed (webhook=%d): %v", hook.ID, err)
+	}
+	return delivery
+}
+
+func (s *OpsService) postAlertWebhook(ctx context.Context, hook *OpsAlertWebhook, rule *OpsAlertRule, event *OpsAlertEvent, now time.Time) (int, error) {
+	target, body, headers, err := buildOpsAlertWebhookRequest(hook, rule, event, now)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return 0, err
+	}
+	validateHost := s.alertWebhookValidateHost
+	if validateHost == nil {
+		validateHost = urlvalidator.ValidateResolvedIP
+	}
+	if err := validateHost(parsed.Hostname()); err != nil {
+		return 0, err
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, opsAlertWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sub2api-ops-alert")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client := s.alertWebhookClient
+	if client == nil {
+		client = newOpsAlertWebhookHTTPClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	// 飞书签名/参数错误时仍返回 200，错误码在响应体里。
+	if hook.Type == OpsAlertWebhookTypeLark {
+		var larkResp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if json.Unmarshal(respBody, &larkResp) == nil && larkResp.Code != 0 {
+			return resp.StatusCode, fmt.Errorf("lark error %d: %s", larkResp.Code, larkResp.Msg)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func newOpsAlertWebhookHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: opsAlertWebhookTimeout,
+		// 不跟随重定向，避免被 302 引导到内网地址
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// buildOpsAlertWebhookRequest 按渠道类型构造推送地址、请求体与额外请求头。
+func buildOpsAlertWebhookRequest(hook *OpsAlertWebhook, rule *OpsAlertRule, event *OpsAlertEvent, now time.Time) (string, []byte, map[string]string, error) {
+	text := buildOpsAlertWebhookText(rule, event)
+	var (
+		target  = hook.URL
+		payload any
+		headers map[string]string
+	)
+	switch hook.Type {
+	case OpsAlertWebhookTypeSlack:
+		payload = map[string]any{"text": text}
+	case OpsAlertWebhookTypeTelegram:
+		target = strings.TrimRight(hook.URL, "/") + "/bot" + hook.Secret + "/sendMessage"
+		payload = map[string]any{"chat_id": hook.ChatID, "text": text, "disable_web_page_preview": true}
+	case OpsAlertWebhookTypeLark:
+		msg := map[string]any{"msg_type": "text", "content": map[string]any{"text": text}}
+		if hook.Secret != "" {
+			ts := strconv.FormatInt(now.Unix(), 10)
+			msg["timestamp"] = ts
+			msg["sign"] = larkWebhookSign(ts, hook.Secret)
+		}
+		payload = msg
+	case OpsAlertWebhookTypeGeneric:
+		payload = &OpsAlertWebhookPayload{
+			Event:  "ops_alert." + event.Status,
+			SentAt: now,
+			Text:   text,
+			Rule: &OpsAlertWebhookPayloadRule{
+				ID:            rule.ID,
+				Name:          strings.TrimSpace(rule.Name),
+				Severity:      strings.TrimSpace(rule.Severity),
+				MetricType:    strings.TrimSpace(rule.MetricType),
+				Operator:      strings.TrimSpace(rule.Operator),
+				Threshold:     rule.Threshold,
+				WindowMinutes: rule.WindowMinutes,
+			},
+			Alert: event,
+		}
+	default:
+		return "", nil, nil, fmt.Errorf("unsupported webhook type %q", hook.Type)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if hook.Type == OpsAlertWebhookTypeGeneric && hook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(hook.Secret))
+		mac.Write(body)
+		headers = map[string]string{opsAlertWebhookSignatureName: "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+	}
+	return target, body, headers, nil
+}
+
+// larkWebhookSign 飞书自定义机器人签名：以 "timestamp\nsecret" 为密钥对空串做 HMAC-SHA256 后 base64。
+func larkWebhookSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func buildOpsAlertWebhookText(rule *OpsAlertRule, event *OpsAlertEvent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Ops Alert][%s] %s - %s", strings.TrimSpace(event.Severity), strings.TrimSpace(rule.Name), strings.ToUpper(event.Status))
+	if desc := strings.TrimSpace(event.Description); desc != "" {
+		b.WriteString("\n")
+		b.WriteString(desc)
+	}
+	fmt.Fprintf(&b, "\nFired at: %s", event.FiredAt.UTC().Format(time.RFC3339))
+	if event.ResolvedAt != nil {
+		fmt.Fprintf(&b, "\nResolved at: %s", event.ResolvedAt.UTC().Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+// opsAlertWebhookMatches 判断渠道是否应接收该事件：启用、规则范围、最低级别，恢复事件需显式开启。
+func opsAlertWebhookMatches(hook *OpsAlertWebhook, rule *OpsAlertRule, event *OpsAlertEvent) bool {
+	if hook == nil || !hook.Enabled {
+		return false
+	}
+	if len(hook.RuleIDs) > 0 && !slices.Contains(hook.RuleIDs, rule.ID) {
+		return false
+	}
+	switch event.Status {
+	case OpsAlertStatusFiring:
+	case OpsAlertStatusResolved:
+		if !hook.NotifyResolved {
+			return false
+		}
+	default:
+		return false
+	}
+	return shouldSendOpsAlertEmailByMinSeverity(hook.MinSeverity, rule.Severity)
+}
+
+// normalizeOpsAlertWebhookInput 校验并规范化管理端输入；existing 非空时为更新，未提供的 secret/enabled 沿用原值。
+func normalizeOpsAlertWebhookInput(input *OpsAlertWebhookInput, existing *OpsAlertWebhook) (*OpsAlertWebhook, error) {
+	if input == nil {
+		return nil, infraerrors.BadRequest("INVALID_WEBHOOK", "invalid webhook")
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > opsAlertWebhookMaxNameLen {
+		return nil, infraerrors.BadRequest("INVALID_WEBHOOK_NAME", fmt.Sprintf("name is required and must be at most %d characters", opsAlertWebhookMaxNameLen))
+	}
+	hookType := strings.ToLower(strings.TrimSpace(input.Type))
+	if !slices.Contains(opsAlertWebhookTypes, hookType) {
+		return nil, infraerrors.BadRequest("INVALID_WEBHOOK_TYPE", "type must be one of: "+strings.Join(opsAlertWebhookTypes, ", "))
+	}
+
+	rawURL := strings.TrimSpace(input.URL)
+	if rawURL == "" && hookType == OpsAlertWebhookTypeTelegram {
+		rawURL = opsAlertWebhookTelegramAPI
+	}
+	normalizedURL, err := urlvalidator.ValidateHTTPSURL(rawURL, urlvalidator.ValidationOptions{})
+	if err != nil {
+		return nil, infraerrors.BadRequest("INVALID_WEBHOOK_URL", "url must be a public https URL").WithCause(err)
+	}
+
+	hook := &OpsAlertWebhook{
+		Name:           name,
+		Type:           hookType,
+		URL:            normalizedURL,
+		ChatID:         strings.TrimSpace(input.ChatID),
+		Enabled:        true,
+		MinSeverity:    strings.ToLower(strings.TrimSpace(input.MinSeverity)),
+		NotifyResolved: input.NotifyResolved,
+		RuleIDs:        []int64{},
+	}
+	if existing != nil {
+		hook.Secret = existing.Secret
+		hook.Enabled = existing.Enabled
+	}
+	if input.Secret != nil {
+		hook.Secret = strings.TrimSpace(*input.Secret)
+	}
+	if input.Enabled != nil {
+		hook.Enabled = *input.Enabled
+	}
+	hook.SecretConfigured = hook.Secret != ""
+
+	switch hook.MinSeverity {
+	case "", "critical", "warning", "info":
+	default:
+		return nil, infraerrors.BadRequest("INVALID_MIN_SEVERITY", "min_severity must be one of: critical, warning, info")
+	}
+	for _, id := range input.RuleIDs {
+		if id <= 0 {
+			return nil, infraerrors.BadRequest("INVALID_RULE_ID", "rule_ids must be positive")
+		}
+		if !slices.Contains(hook.RuleIDs, id) {
+			hook.RuleIDs = append(hook.RuleIDs, id)
+		}
+	}
+	if hookType == OpsAlertWebhookTypeTelegram && (hook.Secret == "" || hook.ChatID == "") {
+		return nil, infraerrors.BadRequest("INVALID_TELEGRAM_WEBHOOK", "telegram webhooks require secret (bot token) and chat_id")
+	}
+	return hook, nil
+}
@@ -0,0 +1,219 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type opsAlertWebhookRepoStub struct {
+	OpsRepository
+	hooks  []*OpsAlertWebhook
+	counts *OpsAlertWindowCounts
+	filter *OpsAlertWindowFilter
+
+	mu         sync.Mutex
+	deliveries []*OpsAlertWebhookDelivery
+}
+
+func (r *opsAlertWebhookRepoStub) ListAlertWebhooks(context.Context) ([]*OpsAlertWebhook, error) {
+	return r.hooks, nil
+}
+
+func (r *opsAlertWebhookRepoStub) InsertAlertWebhookDelivery(_ context.Context, d *OpsAlertWebhookDelivery) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, d)
+	return int64(len(r.deliveries)), nil
+}
+
+func (r *opsAlertWebhookRepoStub) GetAlertWindowCounts(_ context.Context, filter *OpsAlertWindowFilter) (*OpsAlertWindowCounts, error) {
+	r.filter = filter
+	return r.counts, nil
+}
+
+type capturedWebhookRequest struct {
+	path    string
+	headers http.Header
+	body    []byte
+}
+
+func newOpsAlertWebhookTestServer(t *testing.T, status int) (*httptest.Server, *[]capturedWebhookRequest) {
+	t.Helper()
+	var captured []capturedWebhookRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured = append(captured, capturedWebhookRequest{path: r.URL.Path, headers: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &captured
+}
+
+func newOpsAlertWebhookTestService(repo *opsAlertWebhookRepoStub) *OpsService {
+	return &OpsService{
+		opsRepo:                  repo,
+		alertWebhookClient:       http.DefaultClient,
+		alertWebhookValidateHost: func(string) error { return nil },
+	}
+}
+
+func opsAlertWebhookTestEvent(status string) (*OpsAlertRule, *OpsAlertEvent) {
+	rule := &OpsAlertRule{ID: 7, Name: "429 storm", Severity: "P1", MetricType: "upstream_429_count", Operator: ">", Threshold: 20, WindowMinutes: 5}
+	event := &OpsAlertEvent{ID: 42, RuleID: 7, Severity: "P1", Status: status, Description: "upstream_429_count > 20.00 (current 31.00)", FiredAt: time.Unix(1700000000, 0).UTC()}
+	return rule, event
+}
+
+func TestDeliverAlertWebhooks_FiltersAndRecords(t *testing.T) {
+	srv, captured := newOpsAlertWebhookTestServer(t, http.StatusOK)
+	repo := &opsAlertWebhookRepoStub{hooks: []*OpsAlertWebhook{
+		{ID: 1, Type: OpsAlertWebhookTypeGeneric, URL: srv.URL + "/generic", Secret: "s3cret", Enabled: true},
+		{ID: 2, Type: OpsAlertWebhookTypeSlack, URL: srv.URL + "/slack", Enabled: false},
+		{ID: 3, Type: OpsAlertWebhookTypeSlack, URL: srv.URL + "/other-rule", Enabled: true, RuleIDs: []int64{99}},
+		{ID: 4, Type: OpsAlertWebhookTypeSlack, URL: srv.URL + "/critical-only", Enabled: true, MinSeverity: "critical"},
+		{ID: 5, Type: OpsAlertWebhookTypeSlack, URL: srv.URL + "/resolved", Enabled: true, RuleIDs: []int64{7}, NotifyResolved: true},
+	}}
+	svc := newOpsAlertWebhookTestService(repo)
+
+	rule, firing := opsAlertWebhookTestEvent(OpsAlertStatusFiring)
+	require.Equal(t, 2, svc.DeliverAlertWebhooks(context.Background(), rule, firing))
+	require.Len(t, *captured, 2)
+	require.Equal(t, "/generic", (*captured)[0].path)
+	require.Equal(t, "/resolved", (*captured)[1].path)
+
+	generic := (*captured)[0]
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(generic.body)
+	require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), generic.headers.Get("X-Sub2API-Signature"))
+	var payload OpsAlertWebhookPayload
+	require.NoError(t, json.Unmarshal(generic.body, &payload))
+	require.Equal(t, "ops_alert.firing", payload.Event)
+	require.Equal(t, int64(7), payload.Rule.ID)
+	require.Equal(t, int64(42), payload.Alert.ID)
+
+	resolvedAt := firing.FiredAt.Add(10 * time.Minute)
+	_, resolved := opsAlertWebhookTestEvent(OpsAlertStatusResolved)
+	resolved.ResolvedAt = &resolvedAt
+	require.Equal(t, 1, svc.DeliverAlertWebhooks(context.Background(), rule, resolved), "only channels opting into resolved events")
+	require.Contains(t, string((*captured)[2].body), "RESOLVED")
+
+	require.Len(t, repo.deliveries, 3)
+	for _, d := range repo.deliveries {
+		require.True(t, d.Success)
+		require.Equal(t, int64(7), *d.RuleID)
+		require.Equal(t, int64(42), *d.EventID)
+	}
+	require.Equal(t, OpsAlertStatusResolved, repo.deliveries[2].EventStatus)
+}
+
+func TestSendAlertWebhook_TelegramRedactsTokenOnFailure(t *testing.T) {
+	srv, captured := newOpsAlertWebhookTestServer(t, http.StatusBadRequest)
+	repo := &opsAlertWebhookRepoStub{}
+	svc := newOpsAlertWebhookTestService(repo)
+	hook := &OpsAlertWebhook{ID: 9, Type: OpsAlertWebhookTypeTelegram, URL: srv.URL + "/", Secret: "123:abc", ChatID: "-100"}
+
+	rule, event := opsAlertWebhookTestEvent(OpsAlertStatusFiring)
+	delivery := svc.sendAlertWebhook(context.Background(), hook, rule, event)
+	require.False(t, delivery.Success)
+	require.Equal(t, http.StatusBadRequest, *delivery.StatusCode)
+	require.Equal(t, "/bot123:abc/sendMessage", (*captured)[0].path)
+	require.JSONEq(t, `"-100"`, mustJSONField(t, (*captured)[0].body, "chat_id"))
+
+	svc.alertWebhookValidateHost = func(string) error { return io.ErrUnexpectedEOF }
+	hook.URL = "https://api.telegram.org"
+	delivery = svc.sendAlertWebhook(context.Background(), hook, rule, event)
+	require.False(t, delivery.Success)
+	require.NotContains(t, delivery.Error, "123:abc")
+	require.Len(t, repo.deliveries, 2)
+}
+
+func TestBuildOpsAlertWebhookRequest_LarkSign(t *testing.T) {
+	rule, event := opsAlertWebhookTestEvent(OpsAlertStatusFiring)
+	hook := &OpsAlertWebhook{Type: OpsAlertWebhookTypeLark, URL: "https://open.feishu.cn/open-apis/bot/v2/hook/x", Secret: "lark"}
+	now := time.Unix(1700000100, 0)
+
+	target, body, headers, err := buildOpsAlertWebhookRequest(hook, rule, event, now)
+	require.NoError(t, err)
+	require.Equal(t, hook.URL, target)
+	require.Empty(t, headers)
+	require.JSONEq(t, `"1700000100"`, mustJSONField(t, body, "timestamp"))
+	require.JSONEq(t, `"`+larkWebhookSign("1700000100", "lark")+`"`, mustJSONField(t, body, "sign"))
+	require.Contains(t, mustJSONField(t, body, "content"), "429 storm - FIRING")
+}
+
+func TestNormalizeOpsAlertWebhookInput(t *testing.T) {
+	secret := "token"
+	hook, err := normalizeOpsAlertWebhookInput(&OpsAlertWebhookInput{
+		Name: " tg ", Type: "Telegram", Secret: &secret, ChatID: "1", RuleIDs: []int64{3, 3, 5}, MinSeverity: "Warning",
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "tg", hook.Name)
+	require.Equal(t, OpsAlertWebhookTypeTelegram, hook.Type)
+	require.Equal(t, "https://api.telegram.org", hook.URL)
+	require.True(t, hook.Enabled)
+	require.Equal(t, []int64{3, 5}, hook.RuleIDs)
+	require.Equal(t, "warning", hook.MinSeverity)
+
+	disabled := false
+	updated, err := normalizeOpsAlertWebhookInput(&OpsAlertWebhookInput{Name: "tg", Type: "telegram", ChatID: "2", Enabled: &disabled}, hook)
+	require.NoError(t, err)
+	require.Equal(t, "token", updated.Secret, "omitted secret keeps the stored one")
+	require.False(t, updated.Enabled)
+
+	for _, in := range []*OpsAlertWebhookInput{
+		{Name: "x", Type: "discord", URL: "https://example.com/hook"},
+		{Name: "x", Type: "slack", URL: "http://example.com/hook"},
+		{Name: "x", Type: "slack", URL: "https://127.0.0.1/hook"},
+		{Name: "x", Type: "telegram"},
+		{Name: "x", Type: "slack", URL: "https://example.com/hook", MinSeverity: "P0"},
+		{Name: "", Type: "slack", URL: "https://example.com/hook"},
+	} {
+		_, err := normalizeOpsAlertWebhookInput(in, nil)
+		require.Error(t, err, "%+v", in)
+	}
+}
+
+func TestComputeRuleMetric_WindowCounts(t *testing.T) {
+	repo := &opsAlertWebhookRepoStub{counts: &OpsAlertWindowCounts{Upstream429Count: 31, StreamFaultCount: 3, StreamRequestCount: 60}}
+	svc := &OpsAlertEvaluatorService{opsRepo: repo}
+	end := time.Now().UTC()
+	start := end.Add(-5 * time.Minute)
+
+	rule := &OpsAlertRule{MetricType: "upstream_429_count", Filters: map[string]any{"account_id": float64(12)}}
+	val, ok := svc.computeRuleMetric(context.Background(), rule, nil, start, end, "anthropic", nil)
+	require.True(t, ok)
+	require.Equal(t, 31.0, val)
+	require.Equal(t, int64(12), *repo.filter.AccountID)
+	require.Equal(t, "anthropic", repo.filter.Platform)
+	require.True(t, strings.Contains(buildOpsAlertDescription(rule, val, 5, "anthropic", nil), "account_id=12"))
+
+	val, ok = svc.computeRuleMetric(context.Background(), &OpsAlertRule{MetricType: "stream_fault_rate"}, nil, start, end, "", nil)
+	require.True(t, ok)
+	require.InDelta(t, 5.0, val, 0.0001)
+	require.Nil(t, repo.filter.AccountID)
+
+	repo.counts = &OpsAlertWindowCounts{}
+	_, ok = svc.computeRuleMetric(context.Background(), &OpsAlertRule{MetricType: "stream_fault_rate"}, nil, start, end, "", nil)
+	require.False(t, ok, "no stream traffic means no signal")
+}
+
+func mustJSONField(t *testing.T, body []byte, field string) string {
+	t.Helper()
+	var m map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &m))
+	return string(m[field])
+}
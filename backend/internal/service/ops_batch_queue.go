@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// opsBatchQueue 进程内有界队列 + 后台批量写入，供请求路径上的 ops 旁路记录使用：
+// 入队失败（队列满/已停止）直接丢弃并计数，绝不阻塞网关请求。
+type opsBatchQueue[T any] struct {
+	name  string
+	write func(ctx context.Context, batch []T) error
+
+	queue         chan T
+	batchSize     int
+	flushInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	droppedCount uint64
+}
+
+func newOpsBatchQueue[T any](name string, capacity, batchSize int, flushInterval time.Duration, write func(ctx context.Context, batch []T) error) *opsBatchQueue[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &opsBatchQueue[T]{
+		name:          name,
+		write:         write,
+		queue:         make(chan T, capacity),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+func (q *opsBatchQueue[T]) start() {
+	q.wg.Add(1)
+	go q.run()
+}
+
+// stop 停止接收并写完队列中剩余的记录。
+func (q *opsBatchQueue[T]) stop() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *opsBatchQueue[T]) offer(item T) {
+	select {
+	case <-q.ctx.Done():
+		return
+	default:
+	}
+	select {
+	case q.queue <- item:
+	default:
+		atomic.AddUint64(&q.droppedCount, 1)
+	}
+}
+
+func (q *opsBatchQueue[T]) dropped() uint64 {
+	return atomic.LoadUint64(&q.droppedCount)
+}
+
+func (q *opsBatchQueue[T]) run() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, q.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := q.write(ctx, batch); err != nil {
+			logger.L().With(zap.String("component", "service.ops_batch_queue")).
+				Warn("ops.batch_queue_flush_failed", zap.String("queue", q.name), zap.Int("batch", len(batch)), zap.Error(err))
+		}
+		batch = batch[:0]
+	}
+	add := func(item T) {
+		batch = append(batch, item)
+		if len(batch) >= q.batchSize {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			for {
+				select {
+				case item := <-q.queue:
+					add(item)
+				default:
+					flush()
+					return
+				}
+			}
+		case item := <-q.queue:
+			add(item)
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
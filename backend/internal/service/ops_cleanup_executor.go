@@ -25,22 +25,30 @@ type opsCleanupTarget struct {
 }
 
 type opsCleanupDeletedCounts struct {
-	errorLogs     int64
-	alertEvents   int64
-	systemLogs    int64
-	logAudits     int64
-	systemMetrics int64
-	hourlyPreagg  int64
-	dailyPreagg   int64
+	errorLogs         int64
+	alertEvents       int64
+	systemLogs        int64
+	logAudits         int64
+	upstreamIDs       int64
+	streamTiming      int64
+	replays           int64
+	webhookDeliveries int64
+	systemMetrics     int64
+	hourlyPreagg      int64
+	dailyPreagg       int64
 }
 
 func (c opsCleanupDeletedCounts) String() string {
 	return fmt.Sprintf(
-		"error_logs=%d alert_events=%d system_logs=%d log_audits=%d system_metrics=%d hourly_preagg=%d daily_preagg=%d",
+		"error_logs=%d alert_events=%d system_logs=%d log_audits=%d upstream_request_ids=%d stream_timings=%d request_replays=%d webhook_deliveries=%d system_metrics=%d hourly_preagg=%d daily_preagg=%d",
 		c.errorLogs,
 		c.alertEvents,
 		c.systemLogs,
 		c.logAudits,
+		c.upstreamIDs,
+		c.streamTiming,
+		c.replays,
+		c.webhookDeliveries,
 		c.systemMetrics,
 		c.hourlyPreagg,
 		c.dailyPreagg,
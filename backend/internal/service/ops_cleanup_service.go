@@ -302,6 +302,10 @@ func (s *OpsCleanupService) runCleanupOnce(ctx context.Context) (opsCleanupDelet
 		{effective.ErrorLogRetentionDays, "ops_alert_events", "created_at", false, &out.alertEvents},
 		{effective.ErrorLogRetentionDays, "ops_system_logs", "created_at", false, &out.systemLogs},
 		{effective.ErrorLogRetentionDays, "ops_system_log_cleanup_audits", "created_at", false, &out.logAudits},
+		{effective.ErrorLogRetentionDays, "ops_upstream_request_ids", "created_at", false, &out.upstreamIDs},
+		{effective.ErrorLogRetentionDays, "ops_stream_timings", "created_at", false, &out.streamTiming},
+		{effective.ErrorLogRetentionDays, "ops_request_replays", "created_at", false, &out.replays},
+		{effective.ErrorLogRetentionDays, "ops_alert_webhook_deliveries", "created_at", false, &out.webhookDeliveries},
 		{effective.MinuteMetricsRetentionDays, "ops_system_metrics", "created_at", false, &out.systemMetrics},
 		{effective.HourlyMetricsRetentionDays, "ops_metrics_hourly", "bucket_start", false, &out.hourlyPreagg},
 		{effective.HourlyMetricsRetentionDays, "ops_metrics_daily", "bucket_date", true, &out.dailyPreagg},
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	opsDebugBundleMaxKeyLen      = 256
+	opsDebugBundleMaxRequestRows = 100
+	// opsDebugBundleMaxErrors 单个请求关联的错误日志上限（含各次上游重试）。
+	opsDebugBundleMaxErrors = 50
+)
+
+var (
+	ErrOpsDebugBundleInvalidKey = infraerrors.BadRequest("OPS_DEBUG_BUNDLE_INVALID_KEY", "invalid request key")
+	ErrOpsDebugBundleNotFound   = infraerrors.NotFound("OPS_DEBUG_BUNDLE_NOT_FOUND", "no request or error log found for key")
+)
+
+// OpsDebugBundle 单个请求的排查材料：请求明细、关联错误日志、耗时与（若该 Key 处于抓包中）脱敏后的请求/响应记录。
+type OpsDebugBundle struct {
+	Key         string    `json:"key"`
+	GeneratedAt time.Time `json:"generated_at"`
+	GeneratedBy int64     `json:"generated_by,omitempty"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+
+	Requests    []*OpsRequestDetail   `json:"requests"`
+	Errors      []*OpsErrorLogDetail  `json:"errors"`
+	Timing      *OpsDebugBundleTiming `json:"timing"`
+	RequestDump *APIKeyTraceRecord    `json:"request_dump,omitempty"`
+	// UpstreamRequestIDs 上游请求 ID 索引中与该请求关联的记录（含各次重试/切换账号）。
+	UpstreamRequestIDs []*OpsUpstreamRequestID `json:"upstream_request_ids,omitempty"`
+	// StreamTimings 该请求被采样时的流式逐事件耗时摘要。
+	StreamTimings []*OpsStreamTiming `json:"stream_timings,omitempty"`
+
+	// Notes 说明缺失的材料（如请求体未抓取），便于接收方判断信息是否完整。
+	Notes []string `json:"notes,omitempty"`
+}
+
+// OpsDebugBundleTiming 请求总耗时与各错误记录的分段耗时。
+type OpsDebugBundleTiming struct {
+	RequestDurationMs *int                         `json:"request_duration_ms,omitempty"`
+	Errors            []*OpsDebugBundleErrorTiming `json:"errors,omitempty"`
+}
+
+type OpsDebugBundleErrorTiming struct {
+	ErrorID            int64     `json:"error_id"`
+	CreatedAt          time.Time `json:"created_at"`
+	Phase              string    `json:"phase"`
+	StatusCode         int       `json:"status_code"`
+	AuthLatencyMs      *int64    `json:"auth_latency_ms,omitempty"`
+	RoutingLatencyMs   *int64    `json:"routing_latency_ms,omitempty"`
+	UpstreamLatencyMs  *int64    `json:"upstream_latency_ms,omitempty"`
+	ResponseLatencyMs  *int64    `json:"response_latency_ms,omitempty"`
+	TimeToFirstTokenMs *int64    `json:"time_to_first_token_ms,omitempty"`
+}
+
+// SetAPIKeyTraceSource 由 wire 注入 API Key 抓包服务，调试包据此附带请求/响应记录。
+func (s *OpsService) SetAPIKeyTraceSource(traceService *APIKeyTraceService) {
+	if s == nil {
+		return
+	}
+	s.apiKeyTraceService = traceService
+}
+
+// BuildDebugBundle 按 request_id / client_request_id / 上游请求 ID 汇总 [start, end) 内的请求排查材料。
+func (s *OpsService) BuildDebugBundle(ctx context.Context, key string, start, end time.Time, generatedBy int64) (*OpsDebugBundle, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > opsDebugBundleMaxKeyLen {
+		return nil, ErrOpsDebugBundleInvalidKey
+	}
+
+	bundle := &OpsDebugBundle{
+		Key:         key,
+		GeneratedAt: time.Now().UTC(),
+		GeneratedBy: generatedBy,
+		StartTime:   start.UTC(),
+		EndTime:     end.UTC(),
+		Timing:      &OpsDebugBundleTiming{},
+	}
+
+	mappings, err := s.opsRepo.ListUpstreamRequestIDs(ctx, key, opsUpstreamRequestIDListLimit)
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_DEBUG_BUNDLE_LOAD_FAILED", "Failed to load upstream request ids").WithCause(err)
+	}
+	bundle.UpstreamRequestIDs = mappings
+
+	if err := s.loadDebugBundleRecords(ctx, bundle, key, start, end); err != nil {
+		return nil, err
+	}
+	lookupKeys := []string{key}
+	// key 为上游请求 ID 且未出现在使用记录/错误日志中时（如使用记录写入失败），经索引换成 client_request_id 再查
+	if len(bundle.Requests) == 0 && len(bundle.Errors) == 0 {
+		for _, m := range mappings {
+			if m == nil || m.UpstreamRequestID != key || m.ClientRequestID == key {
+				continue
+			}
+			if err := s.loadDebugBundleRecords(ctx, bundle, m.ClientRequestID, start, end); err != nil {
+				return nil, err
+			}
+			lookupKeys = append(lookupKeys, m.ClientRequestID)
+			bundle.Notes = append(bundle.Notes, "upstream request id resolved to client_request_id "+m.ClientRequestID+" via the upstream request id index")
+			break
+		}
+	}
+	if len(bundle.Requests) == 0 && len(bundle.Errors) == 0 && len(bundle.UpstreamRequestIDs) == 0 {
+		return nil, ErrOpsDebugBundleNotFound
+	}
+
+	for _, r := range bundle.Requests {
+		if r != nil && r.DurationMs != nil {
+			bundle.Timing.RequestDurationMs = r.DurationMs
+			break
+		}
+	}
+	for _, e := range bundle.Errors {
+		bundle.Timing.Errors = append(bundle.Timing.Errors, &OpsDebugBundleErrorTiming{
+			ErrorID:            e.ID,
+			CreatedAt:          e.CreatedAt,
+			Phase:              e.Phase,
+			StatusCode:         e.StatusCode,
+			AuthLatencyMs:      e.AuthLatencyMs,
+			RoutingLatencyMs:   e.RoutingLatencyMs,
+			UpstreamLatencyMs:  e.UpstreamLatencyMs,
+			ResponseLatencyMs:  e.ResponseLatencyMs,
+			TimeToFirstTokenMs: e.TimeToFirstTokenMs,
+		})
+	}
+
+	if timings, err := s.opsRepo.ListStreamTimings(ctx, lookupKeys, opsStreamTimingListLimit); err != nil {
+		bundle.Notes = append(bundle.Notes, "stream timings could not be loaded: "+err.Error())
+	} else {
+		bundle.StreamTimings = timings
+	}
+
+	bundle.RequestDump = s.findDebugBundleRequestDump(ctx, lookupKeys, bundle)
+	if bundle.RequestDump == nil {
+		bundle.Notes = append(bundle.Notes, "request/response bodies are not included: the API key was not traced when this request was served (or the trace record has expired)")
+	}
+	if len(bundle.Errors) >= opsDebugBundleMaxErrors {
+		bundle.Notes = append(bundle.Notes, "error logs truncated to the most recent entries")
+	}
+	return bundle, nil
+}
+
+// loadDebugBundleRecords 加载 key 对应的请求明细与错误日志。
+func (s *OpsService) loadDebugBundleRecords(ctx context.Context, bundle *OpsDebugBundle, key string, start, end time.Time) error {
+	requests, _, err := s.opsRepo.ListRequestDetails(ctx, &OpsRequestDetailFilter{
+		StartTime: &start,
+		EndTime:   &end,
+		Kind:      "all",
+		RequestID: key,
+		Page:      1,
+		PageSize:  opsDebugBundleMaxRequestRows,
+	})
+	if err != nil {
+		return infraerrors.InternalServer("OPS_DEBUG_BUNDLE_LOAD_FAILED", "Failed to load request details").WithCause(err)
+	}
+	if requests == nil {
+		requests = []*OpsRequestDetail{}
+	}
+	errs, err := s.collectDebugBundleErrors(ctx, key, start, end)
+	if err != nil {
+		return err
+	}
+	bundle.Requests = requests
+	bundle.Errors = errs
+	return nil
+}
+
+// collectDebugBundleErrors 收集与 key 关联的请求错误与上游错误（含 recovered 上游错误），按 ID 去重后加载详情。
+func (s *OpsService) collectDebugBundleErrors(ctx context.Context, key string, start, end time.Time) ([]*OpsErrorLogDetail, error) {
+	filters := make([]*OpsErrorLogFilter, 0, 4)
+	for _, byClientID := range []bool{false, true} {
+		for _, upstream := range []bool{false, true} {
+			f := &OpsErrorLogFilter{
+				StartTime: &start,
+				EndTime:   &end,
+				View:      "all",
+				Page:      1,
+				PageSize:  opsDebugBundleMaxErrors,
+			}
+			if byClientID {
+				f.ClientRequestID = key
+			} else {
+				f.RequestID = key
+			}
+			if upstream {
+				f.Phase = "upstream"
+				f.IncludeRecoveredUpstream = true
+			}
+			filters = append(filters, f)
+		}
+	}
+
+	seen := make(map[int64]struct{})
+	out := make([]*OpsErrorLogDetail, 0)
+	for _, f := range filters {
+		list, err := s.opsRepo.ListErrorLogs(ctx, f)
+		if err != nil {
+			return nil, infraerrors.InternalServer("OPS_DEBUG_BUNDLE_LOAD_FAILED", "Failed to load related error logs").WithCause(err)
+		}
+		if list == nil {
+			continue
+		}
+		for _, item := range list.Errors {
+			if item == nil {
+				continue
+			}
+			if _, ok := seen[item.ID]; ok {
+				continue
+			}
+			seen[item.ID] = struct{}{}
+			if len(out) >= opsDebugBundleMaxErrors {
+				continue
+			}
+			detail, err := s.opsRepo.GetErrorLogByID(ctx, item.ID)
+			if err != nil || detail == nil {
+				// 列表与详情之间被清理时跳过该条
+				continue
+			}
+			out = append(out, detail)
+		}
+	}
+	return out, nil
+}
+
+// findDebugBundleRequestDump 在关联 API Key 的抓包记录中按任一 key 查找该请求，返回脱敏副本。
+func (s *OpsService) findDebugBundleRequestDump(ctx context.Context, keys []string, bundle *OpsDebugBundle) *APIKeyTraceRecord {
+	record := s.findAPIKeyTraceRecord(ctx, keys, bundle)
+	if record == nil {
+		return nil
+	}
+	return redactAPIKeyTraceRecord(record)
+}
+
+// findAPIKeyTraceRecord 返回抓包服务中保存的原始记录（未做调试包脱敏），未找到返回 nil。
+func (s *OpsService) findAPIKeyTraceRecord(ctx context.Context, keys []string, bundle *OpsDebugBundle) *APIKeyTraceRecord {
+	if s.apiKeyTraceService == nil {
+		return nil
+	}
+	apiKeyIDs := make([]int64, 0, 2)
+	seen := make(map[int64]struct{})
+	addKey := func(id *int64) {
+		if id == nil || *id <= 0 {
+			return
+		}
+		if _, ok := seen[*id]; ok {
+			return
+		}
+		seen[*id] = struct{}{}
+		apiKeyIDs = append(apiKeyIDs, *id)
+	}
+	for _, r := range bundle.Requests {
+		if r != nil {
+			addKey(r.APIKeyID)
+		}
+	}
+	for _, e := range bundle.Errors {
+		addKey(e.APIKeyID)
+	}
+
+	for _, apiKeyID := range apiKeyIDs {
+		_, records, err := s.apiKeyTraceService.GetTrace(ctx, apiKeyID, 0)
+		if err != nil {
+			continue
+		}
+		for _, record := range records {
+			if record == nil {
+				continue
+			}
+			for _, key := range keys {
+				if record.RequestID == key || record.ClientRequestID == key {
+					return record
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// redactAPIKeyTraceRecord 返回记录副本：JSON 请求/响应体（含上游请求/响应体）按 ops 错误日志相同规则脱敏（头部在抓取时已脱敏）。
+func redactAPIKeyTraceRecord(record *APIKeyTraceRecord) *APIKeyTraceRecord {
+	out := *record
+	out.RequestBody = redactDebugBundleBody(record.RequestBody)
+	out.ResponseBody = redactDebugBundleBody(record.ResponseBody)
+	if len(record.UpstreamResponses) > 0 {
+		out.UpstreamResponses = make([]*APIKeyTraceUpstreamResponse, 0, len(record.UpstreamResponses))
+		for _, upstream := range record.UpstreamResponses {
+			if upstream == nil {
+				continue
+			}
+			redacted := *upstream
+			redacted.RequestBody = redactDebugBundleBody(upstream.RequestBody)
+			redacted.Body = redactDebugBundleBody(upstream.Body)
+			out.UpstreamResponses = append(out.UpstreamResponses, &redacted)
+		}
+	}
+	return &out
+}
+
+func redactDebugBundleBody(body string) string {
+	if body == "" {
+		return body
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+		// 非 JSON（如 SSE 流）原样保留
+		return body
+	}
+	encoded, err := json.Marshal(redactSensitiveJSON(decoded))
+	if err != nil {
+		return body
+	}
+	return string(encoded)
+}
+
+// WriteDebugBundleZip 将调试包写为 zip：manifest、请求明细、错误日志、耗时与可选的请求记录各占一个 JSON 文件。
+func WriteDebugBundleZip(w io.Writer, bundle *OpsDebugBundle) error {
+	type bundleFile struct {
+		name string
+		data any
+	}
+	files := []bundleFile{
+		{"requests.json", bundle.Requests},
+		{"errors.json", bundle.Errors},
+		{"timing.json", bundle.Timing},
+	}
+	if bundle.RequestDump != nil {
+		files = append(files, bundleFile{"request_dump.json", bundle.RequestDump})
+	}
+	if len(bundle.UpstreamRequestIDs) > 0 {
+		files = append(files, bundleFile{"upstream_request_ids.json", bundle.UpstreamRequestIDs})
+	}
+	if len(bundle.StreamTimings) > 0 {
+		files = append(files, bundleFile{"stream_timings.json", bundle.StreamTimings})
+	}
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	manifest := map[string]any{
+		"key":          bundle.Key,
+		"generated_at": bundle.GeneratedAt,
+		"generated_by": bundle.GeneratedBy,
+		"start_time":   bundle.StartTime,
+		"end_time":     bundle.EndTime,
+		"files":        names,
+		"counts": map[string]int{
+			"requests": len(bundle.Requests),
+			"errors":   len(bundle.Errors),
+		},
+		"notes": bundle.Notes,
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeDebugBundleZipJSON(zw, "manifest.json", bundle.GeneratedAt, manifest); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := writeDebugBundleZipJSON(zw, f.name, bundle.GeneratedAt, f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeDebugBundleZipJSON(zw *zip.Writer, name string, modified time.Time, data any) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
@@ -0,0 +1,129 @@
+//go:build unit
+
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpsDebugBundleTestService(t *testing.T) (*OpsService, *apiKeyTraceCacheFake) {
+	t.Helper()
+	apiKeyID := int64(11)
+	duration := 1234
+	ttft := int64(300)
+	repo := &opsRepoMock{
+		ListRequestDetailsFn: func(ctx context.Context, filter *OpsRequestDetailFilter) ([]*OpsRequestDetail, int64, error) {
+			require.Equal(t, "all", filter.Kind)
+			if filter.RequestID != "req-1" {
+				return nil, 0, nil
+			}
+			return []*OpsRequestDetail{{Kind: OpsRequestKindError, RequestID: "req-1", DurationMs: &duration, APIKeyID: &apiKeyID}}, 1, nil
+		},
+		ListErrorLogsFn: func(ctx context.Context, filter *OpsErrorLogFilter) (*OpsErrorLogList, error) {
+			list := &OpsErrorLogList{Errors: []*OpsErrorLog{}}
+			if filter.RequestID != "req-1" {
+				return list, nil
+			}
+			if filter.Phase == "upstream" {
+				require.True(t, filter.IncludeRecoveredUpstream)
+				list.Errors = append(list.Errors, &OpsErrorLog{ID: 2}, &OpsErrorLog{ID: 1})
+			} else {
+				list.Errors = append(list.Errors, &OpsErrorLog{ID: 1})
+			}
+			return list, nil
+		},
+		GetErrorLogByIDFn: func(ctx context.Context, id int64) (*OpsErrorLogDetail, error) {
+			return &OpsErrorLogDetail{
+				OpsErrorLog:        OpsErrorLog{ID: id, RequestID: "req-1", APIKeyID: &apiKeyID, Phase: "upstream"},
+				TimeToFirstTokenMs: &ttft,
+			}, nil
+		},
+	}
+	cache := newAPIKeyTraceCacheFake()
+	svc := &OpsService{opsRepo: repo}
+	svc.SetAPIKeyTraceSource(NewAPIKeyTraceService(cache, nil, nil, nil))
+	return svc, cache
+}
+
+func TestOpsService_BuildDebugBundle(t *testing.T) {
+	svc, cache := newOpsDebugBundleTestService(t)
+	cache.records[11] = []*APIKeyTraceRecord{
+		{RequestID: "req-other", APIKeyID: 11},
+		{
+			RequestID:    "req-1",
+			APIKeyID:     11,
+			RequestBody:  `{"model":"claude","api_key":"sk-secret","messages":[]}`,
+			ResponseBody: "event: message_start\ndata: {}\n\n",
+			UpstreamResponses: []*APIKeyTraceUpstreamResponse{
+				{StatusCode: 400, Body: `{"error":{"message":"bad"},"access_token":"at-secret"}`},
+			},
+		},
+	}
+	end := time.Now()
+
+	bundle, err := svc.BuildDebugBundle(context.Background(), " req-1 ", end.Add(-time.Hour), end, 5)
+	require.NoError(t, err)
+	require.Equal(t, "req-1", bundle.Key)
+	require.Len(t, bundle.Requests, 1)
+	require.Len(t, bundle.Errors, 2, "errors deduplicated across request/upstream lookups")
+	require.Equal(t, 1234, *bundle.Timing.RequestDurationMs)
+	require.Len(t, bundle.Timing.Errors, 2)
+	require.Equal(t, int64(300), *bundle.Timing.Errors[0].TimeToFirstTokenMs)
+
+	require.NotNil(t, bundle.RequestDump)
+	require.NotContains(t, bundle.RequestDump.RequestBody, "sk-secret")
+	require.Equal(t, "event: message_start\ndata: {}\n\n", bundle.RequestDump.ResponseBody, "non-JSON bodies kept as-is")
+	require.Len(t, bundle.RequestDump.UpstreamResponses, 1)
+	require.NotContains(t, bundle.RequestDump.UpstreamResponses[0].Body, "at-secret")
+	require.Contains(t, cache.records[11][1].RequestBody, "sk-secret", "stored trace record must not be mutated")
+	require.Contains(t, cache.records[11][1].UpstreamResponses[0].Body, "at-secret")
+	require.Empty(t, bundle.Notes)
+}
+
+func TestOpsService_BuildDebugBundle_NotFoundAndInvalid(t *testing.T) {
+	svc, _ := newOpsDebugBundleTestService(t)
+	end := time.Now()
+
+	_, err := svc.BuildDebugBundle(context.Background(), "missing", end.Add(-time.Hour), end, 5)
+	require.Equal(t, "OPS_DEBUG_BUNDLE_NOT_FOUND", infraerrors.Reason(err))
+	_, err = svc.BuildDebugBundle(context.Background(), "  ", end.Add(-time.Hour), end, 5)
+	require.Equal(t, "OPS_DEBUG_BUNDLE_INVALID_KEY", infraerrors.Reason(err))
+}
+
+func TestWriteDebugBundleZip(t *testing.T) {
+	svc, _ := newOpsDebugBundleTestService(t)
+	end := time.Now()
+	bundle, err := svc.BuildDebugBundle(context.Background(), "req-1", end.Add(-time.Hour), end, 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, bundle.Notes, "missing trace record should be noted")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDebugBundleZip(&buf, bundle))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"manifest.json", "requests.json", "errors.json", "timing.json"}, names)
+
+	rc, err := zr.File[0].Open()
+	require.NoError(t, err)
+	raw, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	_ = rc.Close()
+	var manifest map[string]any
+	require.NoError(t, json.Unmarshal(raw, &manifest))
+	require.Equal(t, "req-1", manifest["key"])
+	require.Equal(t, map[string]any{"requests": float64(1), "errors": float64(2)}, manifest["counts"])
+}
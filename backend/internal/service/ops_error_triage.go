@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// opsErrorCommentMaxRunes 单条处理评论的最大字符数。
+const opsErrorCommentMaxRunes = 4000
+
+// loadErrorLogForTriage 校验前置条件并加载错误日志（处理流程各操作共用）。
+func (s *OpsService) loadErrorLogForTriage(ctx context.Context, errorID int64) (*OpsErrorLogDetail, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if errorID <= 0 {
+		return nil, infraerrors.BadRequest("OPS_ERROR_INVALID_ID", "invalid error id")
+	}
+	detail, err := s.opsRepo.GetErrorLogByID(ctx, errorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, infraerrors.NotFound("OPS_ERROR_NOT_FOUND", "ops error log not found")
+		}
+		return nil, infraerrors.InternalServer("OPS_ERROR_LOAD_FAILED", "Failed to load ops error log").WithCause(err)
+	}
+	return detail, nil
+}
+
+// UpdateErrorTriage 更新错误的处理状态与负责人；未提供的字段保持原值。
+// 负责人必须是管理员；关闭状态（resolved/ignored）同步写入 resolved 字段。
+func (s *OpsService) UpdateErrorTriage(ctx context.Context, errorID int64, update *OpsErrorTriageUpdate, actorUserID *int64) (*OpsErrorLogDetail, error) {
+	if update == nil || (update.Status == nil && update.AssigneeUserID == nil) {
+		return nil, infraerrors.BadRequest("OPS_ERROR_TRIAGE_EMPTY", "status or assignee_user_id is required")
+	}
+	detail, err := s.loadErrorLogForTriage(ctx, errorID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := detail.Status
+	if update.Status != nil {
+		status = strings.ToLower(strings.TrimSpace(*update.Status))
+		if !IsValidOpsErrorTriageStatus(status) {
+			return nil, infraerrors.BadRequest("OPS_ERROR_INVALID_STATUS", "invalid status")
+		}
+	}
+
+	assignee := detail.AssigneeUserID
+	if update.AssigneeUserID != nil {
+		assignee = nil
+		if id := *update.AssigneeUserID; id > 0 {
+			if err := s.validateErrorAssignee(ctx, id); err != nil {
+				return nil, err
+			}
+			assignee = &id
+		}
+	}
+
+	if err := s.opsRepo.UpdateErrorTriage(ctx, errorID, status, assignee, actorUserID); err != nil {
+		return nil, infraerrors.InternalServer("OPS_ERROR_TRIAGE_FAILED", "Failed to update ops error triage").WithCause(err)
+	}
+	return s.GetErrorLogByID(ctx, errorID)
+}
+
+func (s *OpsService) validateErrorAssignee(ctx context.Context, userID int64) error {
+	if s.userRepo == nil {
+		return nil
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return infraerrors.BadRequest("OPS_ERROR_INVALID_ASSIGNEE", "assignee not found")
+		}
+		return err
+	}
+	if user == nil || !user.IsAdmin() {
+		return infraerrors.BadRequest("OPS_ERROR_INVALID_ASSIGNEE", "assignee must be an admin")
+	}
+	return nil
+}
+
+// AddErrorComment 为错误日志追加一条处理评论。
+func (s *OpsService) AddErrorComment(ctx context.Context, errorID int64, userID int64, body string) (*OpsErrorComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, infraerrors.BadRequest("OPS_ERROR_COMMENT_EMPTY", "comment body is required")
+	}
+	if utf8.RuneCountInString(body) > opsErrorCommentMaxRunes {
+		return nil, infraerrors.BadRequest("OPS_ERROR_COMMENT_TOO_LONG", "comment body is too long")
+	}
+	if _, err := s.loadErrorLogForTriage(ctx, errorID); err != nil {
+		return nil, err
+	}
+	comment := &OpsErrorComment{ErrorID: errorID, Body: body}
+	if userID > 0 {
+		comment.UserID = &userID
+	}
+	created, err := s.opsRepo.InsertErrorComment(ctx, comment)
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_ERROR_COMMENT_FAILED", "Failed to add ops error comment").WithCause(err)
+	}
+	return created, nil
+}
+
+// ListErrorComments 按时间正序返回错误日志的处理评论。
+func (s *OpsService) ListErrorComments(ctx context.Context, errorID int64) ([]*OpsErrorComment, error) {
+	if _, err := s.loadErrorLogForTriage(ctx, errorID); err != nil {
+		return nil, err
+	}
+	comments, err := s.opsRepo.ListErrorComments(ctx, errorID)
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_ERROR_COMMENT_LOAD_FAILED", "Failed to load ops error comments").WithCause(err)
+	}
+	return comments, nil
+}
@@ -0,0 +1,101 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type opsErrorTriageCall struct {
+	status   string
+	assignee *int64
+	actor    *int64
+}
+
+func newOpsErrorTriageTestService(detail *OpsErrorLogDetail, user *User) (*OpsService, *[]opsErrorTriageCall) {
+	calls := &[]opsErrorTriageCall{}
+	repo := &opsRepoMock{
+		GetErrorLogByIDFn: func(ctx context.Context, id int64) (*OpsErrorLogDetail, error) {
+			return detail, nil
+		},
+		UpdateErrorTriageFn: func(ctx context.Context, errorID int64, status string, assigneeUserID *int64, actorUserID *int64) error {
+			*calls = append(*calls, opsErrorTriageCall{status: status, assignee: assigneeUserID, actor: actorUserID})
+			return nil
+		},
+	}
+	return &OpsService{opsRepo: repo, userRepo: &userRepoStub{user: user}}, calls
+}
+
+func TestOpsService_UpdateErrorTriage_MergesUnchangedFields(t *testing.T) {
+	assignee := int64(7)
+	detail := &OpsErrorLogDetail{OpsErrorLog: OpsErrorLog{ID: 1, Status: OpsErrorTriageStatusOpen, AssigneeUserID: &assignee}}
+	svc, calls := newOpsErrorTriageTestService(detail, &User{ID: 9, Role: RoleAdmin})
+	actor := int64(3)
+
+	status := " Investigating "
+	_, err := svc.UpdateErrorTriage(context.Background(), 1, &OpsErrorTriageUpdate{Status: &status}, &actor)
+	require.NoError(t, err)
+	require.Equal(t, OpsErrorTriageStatusInvestigating, (*calls)[0].status)
+	require.Equal(t, &assignee, (*calls)[0].assignee, "assignee kept when not provided")
+
+	newAssignee := int64(9)
+	_, err = svc.UpdateErrorTriage(context.Background(), 1, &OpsErrorTriageUpdate{AssigneeUserID: &newAssignee}, &actor)
+	require.NoError(t, err)
+	require.Equal(t, OpsErrorTriageStatusOpen, (*calls)[1].status, "status kept when not provided")
+	require.Equal(t, &newAssignee, (*calls)[1].assignee)
+
+	unassign := int64(0)
+	_, err = svc.UpdateErrorTriage(context.Background(), 1, &OpsErrorTriageUpdate{AssigneeUserID: &unassign}, &actor)
+	require.NoError(t, err)
+	require.Nil(t, (*calls)[2].assignee)
+}
+
+func TestOpsService_UpdateErrorTriage_Validation(t *testing.T) {
+	detail := &OpsErrorLogDetail{OpsErrorLog: OpsErrorLog{ID: 1, Status: OpsErrorTriageStatusOpen}}
+	ctx := context.Background()
+
+	svc, calls := newOpsErrorTriageTestService(detail, &User{ID: 9, Role: RoleUser})
+	_, err := svc.UpdateErrorTriage(ctx, 1, &OpsErrorTriageUpdate{}, nil)
+	require.Equal(t, "OPS_ERROR_TRIAGE_EMPTY", infraerrors.Reason(err))
+
+	bad := "closed"
+	_, err = svc.UpdateErrorTriage(ctx, 1, &OpsErrorTriageUpdate{Status: &bad}, nil)
+	require.Equal(t, "OPS_ERROR_INVALID_STATUS", infraerrors.Reason(err))
+
+	nonAdmin := int64(9)
+	_, err = svc.UpdateErrorTriage(ctx, 1, &OpsErrorTriageUpdate{AssigneeUserID: &nonAdmin}, nil)
+	require.Equal(t, "OPS_ERROR_INVALID_ASSIGNEE", infraerrors.Reason(err))
+
+	svc, calls = newOpsErrorTriageTestService(detail, nil)
+	_, err = svc.UpdateErrorTriage(ctx, 1, &OpsErrorTriageUpdate{AssigneeUserID: &nonAdmin}, nil)
+	require.Equal(t, "OPS_ERROR_INVALID_ASSIGNEE", infraerrors.Reason(err))
+	require.Empty(t, *calls)
+}
+
+func TestOpsService_AddErrorComment(t *testing.T) {
+	svc, _ := newOpsErrorTriageTestService(&OpsErrorLogDetail{OpsErrorLog: OpsErrorLog{ID: 1}}, nil)
+	ctx := context.Background()
+
+	comment, err := svc.AddErrorComment(ctx, 1, 3, "  looking into upstream 529s  ")
+	require.NoError(t, err)
+	require.Equal(t, "looking into upstream 529s", comment.Body)
+	require.Equal(t, int64(1), comment.ErrorID)
+	require.Equal(t, int64(3), *comment.UserID)
+
+	_, err = svc.AddErrorComment(ctx, 1, 3, "   ")
+	require.Equal(t, "OPS_ERROR_COMMENT_EMPTY", infraerrors.Reason(err))
+	_, err = svc.AddErrorComment(ctx, 1, 3, strings.Repeat("错", opsErrorCommentMaxRunes+1))
+	require.Equal(t, "OPS_ERROR_COMMENT_TOO_LONG", infraerrors.Reason(err))
+}
+
+func TestIsOpsErrorTriageStatusClosed(t *testing.T) {
+	require.True(t, IsOpsErrorTriageStatusClosed(OpsErrorTriageStatusResolved))
+	require.True(t, IsOpsErrorTriageStatusClosed(OpsErrorTriageStatusIgnored))
+	require.False(t, IsOpsErrorTriageStatusClosed(OpsErrorTriageStatusInvestigating))
+	require.False(t, IsValidOpsErrorTriageStatus("closed"))
+}
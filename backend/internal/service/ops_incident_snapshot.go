@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	opsIncidentDefaultFingerprintLimit = 20
+	opsIncidentMaxFingerprintLimit     = 100
+	opsIncidentMaxConfigChanges        = 200
+
+	// 指纹判定为尖峰：窗口内至少 opsIncidentSpikeMinCount 次，且不低于基线窗口（等长前一时段）的 opsIncidentSpikeRatio 倍。
+	opsIncidentSpikeMinCount = 5
+	opsIncidentSpikeRatio    = 2.0
+
+	// opsIncidentAuditComponentPrefix 审计类系统日志的 component 前缀（配置变更、部署等）。
+	opsIncidentAuditComponentPrefix = "audit."
+)
+
+// 事故时间线事件类型。
+const (
+	OpsIncidentEventErrorSpike        = "error_spike"
+	OpsIncidentEventAccountCooldown   = "account_cooldown"
+	OpsIncidentEventProxyProbeFailure = "proxy_probe_failure"
+	OpsIncidentEventConfigChange      = "config_change"
+)
+
+type OpsIncidentSnapshotFilter struct {
+	StartTime time.Time
+	EndTime   time.Time
+
+	Platform string
+	GroupID  *int64
+
+	FingerprintLimit int
+}
+
+// OpsErrorFingerprintFilter 错误指纹聚合条件：[BaselineStart, Start) 为基线窗口，[Start, End) 为事故窗口。
+type OpsErrorFingerprintFilter struct {
+	BaselineStartTime time.Time
+	StartTime         time.Time
+	EndTime           time.Time
+
+	Platform string
+	GroupID  *int64
+
+	Limit int
+}
+
+// OpsErrorFingerprint 按 (phase, type, error_code, status_code, platform) 聚合的一类错误。
+type OpsErrorFingerprint struct {
+	Fingerprint string `json:"fingerprint"`
+
+	Phase      string `json:"phase"`
+	Type       string `json:"type"`
+	ErrorCode  string `json:"error_code"`
+	StatusCode int    `json:"status_code"`
+	Platform   string `json:"platform"`
+
+	Count         int64   `json:"count"`
+	BaselineCount int64   `json:"baseline_count"`
+	SpikeRatio    float64 `json:"spike_ratio"`
+	Spike         bool    `json:"spike"`
+
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+	SampleMessage string    `json:"sample_message"`
+}
+
+// OpsIncidentAccountCooldown 事故窗口内进入或处于冷却的账号。
+// StartedAt 仅限流有记录；过载与临时不可调度只保留截止时间。
+type OpsIncidentAccountCooldown struct {
+	AccountID   int64      `json:"account_id"`
+	AccountName string     `json:"account_name"`
+	Platform    string     `json:"platform"`
+	Kind        string     `json:"kind"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	Until       *time.Time `json:"until,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+}
+
+// OpsIncidentProxyProbeFailure 事故窗口内最近一次探测失败的代理（探测结果只保留最新一次）。
+type OpsIncidentProxyProbeFailure struct {
+	ProxyID   int64     `json:"proxy_id"`
+	ProxyName string    `json:"proxy_name"`
+	Message   string    `json:"message"`
+	ProbedAt  time.Time `json:"probed_at"`
+}
+
+// OpsIncidentConfigChange 审计日志中的配置变更 / 部署记录。
+type OpsIncidentConfigChange struct {
+	At        time.Time      `json:"at"`
+	Component string         `json:"component"`
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Extra     map[string]any `json:"extra,omitempty"`
+}
+
+// OpsIncidentTimelineEvent 时间线上的一个节点，Ref 指向对应明细（指纹 / 账号 ID / 代理 ID / 组件）。
+type OpsIncidentTimelineEvent struct {
+	At    time.Time `json:"at"`
+	Kind  string    `json:"kind"`
+	Title string    `json:"title"`
+	Ref   string    `json:"ref,omitempty"`
+}
+
+type OpsIncidentSnapshot struct {
+	StartTime         time.Time `json:"start_time"`
+	EndTime           time.Time `json:"end_time"`
+	BaselineStartTime time.Time `json:"baseline_start_time"`
+
+	ErrorFingerprints  []*OpsErrorFingerprint          `json:"error_fingerprints"`
+	AccountCooldowns   []*OpsIncidentAccountCooldown   `json:"account_cooldowns"`
+	ProxyProbeFailures []*OpsIncidentProxyProbeFailure `json:"proxy_probe_failures"`
+	ConfigChanges      []*OpsIncidentConfigChange      `json:"config_changes"`
+	Timeline           []*OpsIncidentTimelineEvent     `json:"timeline"`
+
+	// Warnings 列出采集失败的数据源；其余部分照常返回，避免单一数据源故障导致整个快照不可用。
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SetProxyProbeSource 由 wire 注入代理列表与探测结果缓存，供事故快照读取代理探测失败。
+func (s *OpsService) SetProxyProbeSource(proxyRepo ProxyRepository, proxyLatencyCache ProxyLatencyCache) {
+	if s == nil {
+		return
+	}
+	s.proxyRepo = proxyRepo
+	s.proxyLatencyCache = proxyLatencyCache
+}
+
+// GetIncidentSnapshot 汇总事故窗口内的错误尖峰、账号冷却、代理探测失败与配置变更，
+// 并合并为按时间排序的时间线，供控制台渲染事故复盘视图。
+func (s *OpsService) GetIncidentSnapshot(ctx context.Context, filter *OpsIncidentSnapshotFilter) (*OpsIncidentSnapshot, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if filter == nil {
+		return nil, infraerrors.BadRequest("OPS_FILTER_REQUIRED", "filter is required")
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, infraerrors.BadRequest("OPS_TIME_RANGE_REQUIRED", "start_time/end_time are required")
+	}
+	if !filter.StartTime.Before(filter.EndTime) {
+		return nil, infraerrors.BadRequest("OPS_TIME_RANGE_INVALID", "start_time must be before end_time")
+	}
+	limit := filter.FingerprintLimit
+	if limit <= 0 {
+		limit = opsIncidentDefaultFingerprintLimit
+	}
+	if limit > opsIncidentMaxFingerprintLimit {
+		limit = opsIncidentMaxFingerprintLimit
+	}
+
+	start, end := filter.StartTime.UTC(), filter.EndTime.UTC()
+	snapshot := &OpsIncidentSnapshot{
+		StartTime:          start,
+		EndTime:            end,
+		BaselineStartTime:  start.Add(-end.Sub(start)),
+		ErrorFingerprints:  []*OpsErrorFingerprint{},
+		AccountCooldowns:   []*OpsIncidentAccountCooldown{},
+		ProxyProbeFailures: []*OpsIncidentProxyProbeFailure{},
+		ConfigChanges:      []*OpsIncidentConfigChange{},
+	}
+	warn := func(source string, err error) {
+		logger.L().Warn("ops.incident_snapshot_source_failed", zap.String("source", source), zap.Error(err))
+		snapshot.Warnings = append(snapshot.Warnings, source+": "+err.Error())
+	}
+
+	fingerprints, err := s.opsRepo.GetErrorFingerprints(ctx, &OpsErrorFingerprintFilter{
+		BaselineStartTime: snapshot.BaselineStartTime,
+		StartTime:         start,
+		EndTime:           end,
+		Platform:          filter.Platform,
+		GroupID:           filter.GroupID,
+		Limit:             limit,
+	})
+	if err != nil {
+		warn("error_fingerprints", err)
+	} else {
+		for _, fp := range fingerprints {
+			annotateErrorFingerprint(fp)
+		}
+		snapshot.ErrorFingerprints = fingerprints
+	}
+
+	if accounts, err := s.listAllAccountsForOps(ctx, filter.Platform, filter.GroupID); err != nil {
+		warn("account_cooldowns", err)
+	} else {
+		snapshot.AccountCooldowns = collectIncidentAccountCooldowns(accounts, start, end)
+	}
+
+	if failures, err := s.collectIncidentProxyProbeFailures(ctx, start, end); err != nil {
+		warn("proxy_probe_failures", err)
+	} else {
+		snapshot.ProxyProbeFailures = failures
+	}
+
+	if changes, err := s.collectIncidentConfigChanges(ctx, start, end); err != nil {
+		warn("config_changes", err)
+	} else {
+		snapshot.ConfigChanges = changes
+	}
+
+	snapshot.Timeline = buildIncidentTimeline(snapshot)
+	return snapshot, nil
+}
+
+// annotateErrorFingerprint 计算指纹 ID 与尖峰判定。
+func annotateErrorFingerprint(fp *OpsErrorFingerprint) {
+	if fp == nil {
+		return
+	}
+	sum := sha1.Sum([]byte(strings.Join([]string{fp.Phase, fp.Type, fp.ErrorCode, strconv.Itoa(fp.StatusCode), fp.Platform}, "|")))
+	fp.Fingerprint = hex.EncodeToString(sum[:])[:12]
+	baseline := fp.BaselineCount
+	if baseline < 1 {
+		baseline = 1
+	}
+	fp.SpikeRatio = float64(fp.Count) / float64(baseline)
+	fp.Spike = fp.Count >= opsIncidentSpikeMinCount && fp.SpikeRatio >= opsIncidentSpikeRatio
+}
+
+// collectIncidentAccountCooldowns 挑出在 [start, end) 内进入或处于冷却的账号。
+func collectIncidentAccountCooldowns(accounts []Account, start, end time.Time) []*OpsIncidentAccountCooldown {
+	out := make([]*OpsIncidentAccountCooldown, 0)
+	add := func(a *Account, kind string, startedAt, until *time.Time, reason string) {
+		out = append(out, &OpsIncidentAccountCooldown{
+			AccountID:   a.ID,
+			AccountName: a.Name,
+			Platform:    a.Platform,
+			Kind:        kind,
+			StartedAt:   startedAt,
+			Until:       until,
+			Reason:      reason,
+		})
+	}
+	overlaps := func(startedAt, until *time.Time) bool {
+		if startedAt != nil {
+			return !startedAt.Before(start) && startedAt.Before(end)
+		}
+		return until != nil && until.After(start)
+	}
+	for i := range accounts {
+		a := &accounts[i]
+		if a.RateLimitedAt != nil || a.RateLimitResetAt != nil {
+			if overlaps(a.RateLimitedAt, a.RateLimitResetAt) {
+				add(a, "rate_limited", a.RateLimitedAt, a.RateLimitResetAt, "")
+			}
+		}
+		if overlaps(nil, a.OverloadUntil) {
+			add(a, "overloaded", nil, a.OverloadUntil, "")
+		}
+		if overlaps(nil, a.TempUnschedulableUntil) {
+			add(a, "temp_unschedulable", nil, a.TempUnschedulableUntil, a.TempUnschedulableReason)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return incidentCooldownTime(out[i]).Before(incidentCooldownTime(out[j])) })
+	return out
+}
+
+func incidentCooldownTime(c *OpsIncidentAccountCooldown) time.Time {
+	if c.StartedAt != nil {
+		return *c.StartedAt
+	}
+	if c.Until != nil {
+		return *c.Until
+	}
+	return time.Time{}
+}
+
+func (s *OpsService) collectIncidentProxyProbeFailures(ctx context.Context, start, end time.Time) ([]*OpsIncidentProxyProbeFailure, error) {
+	out := make([]*OpsIncidentProxyProbeFailure, 0)
+	if s.proxyRepo == nil || s.proxyLatencyCache == nil {
+		return out, nil
+	}
+	proxies, err := s.proxyRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(proxies) == 0 {
+		return out, nil
+	}
+	ids := make([]int64, 0, len(proxies))
+	for i := range proxies {
+		ids = append(ids, proxies[i].ID)
+	}
+	latencies, err := s.proxyLatencyCache.GetProxyLatencies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range proxies {
+		info := latencies[proxies[i].ID]
+		if info == nil || info.Success || info.UpdatedAt.Before(start) || !info.UpdatedAt.Before(end) {
+			continue
+		}
+		out = append(out, &OpsIncidentProxyProbeFailure{
+			ProxyID:   proxies[i].ID,
+			ProxyName: proxies[i].Name,
+			Message:   info.Message,
+			ProbedAt:  info.UpdatedAt.UTC(),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ProbedAt.Before(out[j].ProbedAt) })
+	return out, nil
+}
+
+func (s *OpsService) collectIncidentConfigChanges(ctx context.Context, start, end time.Time) ([]*OpsIncidentConfigChange, error) {
+	list, err := s.opsRepo.ListSystemLogs(ctx, &OpsSystemLogFilter{
+		StartTime:       &start,
+		EndTime:         &end,
+		ComponentPrefix: opsIncidentAuditComponentPrefix,
+		Page:            1,
+		PageSize:        opsIncidentMaxConfigChanges,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*OpsIncidentConfigChange, 0)
+	if list == nil {
+		return out, nil
+	}
+	for _, entry := range list.Logs {
+		if entry == nil {
+			continue
+		}
+		out = append(out, &OpsIncidentConfigChange{
+			At:        entry.CreatedAt.UTC(),
+			Component: entry.Component,
+			Level:     entry.Level,
+			Message:   entry.Message,
+			Extra:     entry.Extra,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// buildIncidentTimeline 合并各数据源为时间线；错误指纹只收录尖峰，以首次出现时间定位。
+func buildIncidentTimeline(snapshot *OpsIncidentSnapshot) []*OpsIncidentTimelineEvent {
+	timeline := make([]*OpsIncidentTimelineEvent, 0)
+	for _, fp := range snapshot.ErrorFingerprints {
+		if fp == nil || !fp.Spike {
+			continue
+		}
+		title := fmt.Sprintf("%d× %s/%s", fp.Count, fp.Phase, fp.Type)
+		if fp.StatusCode > 0 {
+			title += fmt.Sprintf(" (HTTP %d)", fp.StatusCode)
+		}
+		if fp.ErrorCode != "" {
+			title += " " + fp.ErrorCode
+		}
+		timeline = append(timeline, &OpsIncidentTimelineEvent{At: fp.FirstSeen, Kind: OpsIncidentEventErrorSpike, Title: title, Ref: fp.Fingerprint})
+	}
+	for _, cd := range snapshot.AccountCooldowns {
+		at := cd.StartedAt
+		if at == nil || at.Before(snapshot.StartTime) {
+			at = &snapshot.StartTime
+		}
+		title := fmt.Sprintf("account %s %s", cd.AccountName, cd.Kind)
+		if cd.Until != nil {
+			title += " until " + cd.Until.UTC().Format(time.RFC3339)
+		}
+		timeline = append(timeline, &OpsIncidentTimelineEvent{At: *at, Kind: OpsIncidentEventAccountCooldown, Title: title, Ref: strconv.FormatInt(cd.AccountID, 10)})
+	}
+	for _, pf := range snapshot.ProxyProbeFailures {
+		timeline = append(timeline, &OpsIncidentTimelineEvent{At: pf.ProbedAt, Kind: OpsIncidentEventProxyProbeFailure, Title: fmt.Sprintf("proxy %s probe failed: %s", pf.ProxyName, pf.Message), Ref: strconv.FormatInt(pf.ProxyID, 10)})
+	}
+	for _, cc := range snapshot.ConfigChanges {
+		timeline = append(timeline, &OpsIncidentTimelineEvent{At: cc.At, Kind: OpsIncidentEventConfigChange, Title: cc.Message, Ref: cc.Component})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].At.Before(timeline[j].At) })
+	return timeline
+}
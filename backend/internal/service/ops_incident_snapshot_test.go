@@ -0,0 +1,89 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsServiceGetIncidentSnapshot_AssemblesTimeline(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	var gotFingerprintFilter *OpsErrorFingerprintFilter
+	var gotLogFilter *OpsSystemLogFilter
+	repo := &opsRepoMock{
+		GetErrorFingerprintsFn: func(ctx context.Context, filter *OpsErrorFingerprintFilter) ([]*OpsErrorFingerprint, error) {
+			gotFingerprintFilter = filter
+			return []*OpsErrorFingerprint{
+				{Phase: "upstream", Type: "upstream_error", ErrorCode: "rate_limited", StatusCode: 429, Platform: "anthropic", Count: 40, BaselineCount: 2, FirstSeen: start.Add(20 * time.Minute)},
+				{Phase: "request", Type: "invalid_request_error", StatusCode: 400, Platform: "anthropic", Count: 6, BaselineCount: 5, FirstSeen: start},
+			}, nil
+		},
+		ListSystemLogsFn: func(ctx context.Context, filter *OpsSystemLogFilter) (*OpsSystemLogList, error) {
+			gotLogFilter = filter
+			return &OpsSystemLogList{Logs: []*OpsSystemLog{
+				{CreatedAt: start.Add(15 * time.Minute), Component: "audit.settings_change", Level: "info", Message: "settings updated"},
+			}}, nil
+		},
+	}
+	rateLimitedAt := start.Add(25 * time.Minute)
+	resetAt := start.Add(2 * time.Hour)
+	staleReset := start.Add(-time.Minute)
+	accounts := &opsIncidentAccountRepoStub{accounts: []Account{
+		{ID: 7, Name: "claude-a", Platform: "anthropic", RateLimitedAt: &rateLimitedAt, RateLimitResetAt: &resetAt},
+		{ID: 8, Name: "claude-b", Platform: "anthropic", OverloadUntil: &staleReset},
+	}}
+	svc := &OpsService{opsRepo: repo, accountRepo: accounts}
+
+	snapshot, err := svc.GetIncidentSnapshot(context.Background(), &OpsIncidentSnapshotFilter{StartTime: start, EndTime: end})
+	require.NoError(t, err)
+	require.Empty(t, snapshot.Warnings)
+	require.Equal(t, start.Add(-time.Hour), gotFingerprintFilter.BaselineStartTime)
+	require.Equal(t, "audit.", gotLogFilter.ComponentPrefix)
+
+	require.Len(t, snapshot.ErrorFingerprints, 2)
+	require.True(t, snapshot.ErrorFingerprints[0].Spike)
+	require.Len(t, snapshot.ErrorFingerprints[0].Fingerprint, 12)
+	require.False(t, snapshot.ErrorFingerprints[1].Spike, "close to baseline is not a spike")
+
+	require.Len(t, snapshot.AccountCooldowns, 1, "cooldown ending before the window is excluded")
+	require.Equal(t, "rate_limited", snapshot.AccountCooldowns[0].Kind)
+
+	kinds := make([]string, 0, len(snapshot.Timeline))
+	for _, event := range snapshot.Timeline {
+		kinds = append(kinds, event.Kind)
+	}
+	require.Equal(t, []string{OpsIncidentEventConfigChange, OpsIncidentEventErrorSpike, OpsIncidentEventAccountCooldown}, kinds)
+}
+
+func TestOpsServiceGetIncidentSnapshot_PartialOnSourceFailure(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := &opsRepoMock{
+		GetErrorFingerprintsFn: func(ctx context.Context, filter *OpsErrorFingerprintFilter) ([]*OpsErrorFingerprint, error) {
+			return nil, errors.New("db timeout")
+		},
+	}
+	svc := &OpsService{opsRepo: repo}
+
+	snapshot, err := svc.GetIncidentSnapshot(context.Background(), &OpsIncidentSnapshotFilter{StartTime: start, EndTime: start.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, []string{"error_fingerprints: db timeout"}, snapshot.Warnings)
+	require.NotNil(t, snapshot.ErrorFingerprints)
+
+	_, err = svc.GetIncidentSnapshot(context.Background(), &OpsIncidentSnapshotFilter{StartTime: start, EndTime: start})
+	require.Error(t, err)
+}
+
+type opsIncidentAccountRepoStub struct {
+	AccountRepository
+	accounts []Account
+}
+
+func (s *opsIncidentAccountRepoStub) ListOpsAccountsForStats(ctx context.Context, platform string, groupID *int64) ([]Account, error) {
+	return s.accounts, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	opsLatencyBreakdownDefaultLimit = 50
+	opsLatencyBreakdownMaxLimit     = 200
+)
+
+// GetLatencyBreakdown 返回按模型/账号聚合的 TTFT 与生成阶段吞吐统计。
+func (s *OpsService) GetLatencyBreakdown(ctx context.Context, filter *OpsLatencyBreakdownFilter) (*OpsLatencyBreakdownResponse, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if filter == nil {
+		return nil, infraerrors.BadRequest("OPS_FILTER_REQUIRED", "filter is required")
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, infraerrors.BadRequest("OPS_TIME_RANGE_REQUIRED", "start_time/end_time are required")
+	}
+	if filter.StartTime.After(filter.EndTime) {
+		return nil, infraerrors.BadRequest("OPS_TIME_RANGE_INVALID", "start_time must be <= end_time")
+	}
+	if filter.GroupID != nil && *filter.GroupID <= 0 {
+		return nil, infraerrors.BadRequest("OPS_GROUP_ID_INVALID", "group_id must be > 0")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(filter.GroupBy)) {
+	case "", OpsLatencyBreakdownGroupByModel:
+		filter.GroupBy = OpsLatencyBreakdownGroupByModel
+	case OpsLatencyBreakdownGroupByAccount:
+		filter.GroupBy = OpsLatencyBreakdownGroupByAccount
+	default:
+		return nil, infraerrors.BadRequest("OPS_GROUP_BY_INVALID", "group_by must be model or account")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = opsLatencyBreakdownDefaultLimit
+	}
+	if filter.Limit > opsLatencyBreakdownMaxLimit {
+		return nil, infraerrors.BadRequest("OPS_LIMIT_INVALID", "limit must be between 1 and 200")
+	}
+
+	return s.opsRepo.GetLatencyBreakdown(ctx, filter)
+}
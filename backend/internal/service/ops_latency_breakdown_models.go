@@ -0,0 +1,63 @@
+package service
+
+import "time"
+
+const (
+	OpsLatencyBreakdownGroupByModel   = "model"
+	OpsLatencyBreakdownGroupByAccount = "account"
+)
+
+// OpsLatencyBreakdownFilter 延迟拆解统计过滤条件。
+type OpsLatencyBreakdownFilter struct {
+	TimeRange string
+	StartTime time.Time
+	EndTime   time.Time
+
+	Platform string
+	GroupID  *int64
+
+	// GroupBy: model / account
+	GroupBy string
+	// Limit 返回的最大行数（按请求数降序）
+	Limit int
+}
+
+// OpsLatencyBreakdownItem 单个模型/账号的延迟拆解。
+//
+// 首字时间（TTFT）反映排队 + 上游处理前缀的耗时，生成阶段吞吐
+// （output_tokens / (duration - ttft)）反映真正的出字速度，两者结合才能
+// 判断慢在排队还是慢在生成。
+type OpsLatencyBreakdownItem struct {
+	Model       string `json:"model,omitempty"`
+	AccountID   *int64 `json:"account_id,omitempty"`
+	AccountName string `json:"account_name,omitempty"`
+
+	RequestCount       int64 `json:"request_count"`
+	StreamRequestCount int64 `json:"stream_request_count"`
+	TotalOutputTokens  int64 `json:"total_output_tokens"`
+
+	AvgDurationMs *float64 `json:"avg_duration_ms"`
+	AvgTTFTMs     *float64 `json:"avg_ttft_ms"`
+	P50TTFTMs     *float64 `json:"p50_ttft_ms"`
+	P95TTFTMs     *float64 `json:"p95_ttft_ms"`
+	// AvgGenerationMs 首字之后到响应结束的平均耗时。
+	AvgGenerationMs *float64 `json:"avg_generation_ms"`
+	// AvgOutputTokensPerSec 生成阶段平均出字速度（不含 TTFT）。
+	AvgOutputTokensPerSec *float64 `json:"avg_output_tokens_per_sec"`
+	// AvgEndToEndTokensPerSec 端到端平均出字速度（含 TTFT），与旧口径一致。
+	AvgEndToEndTokensPerSec *float64 `json:"avg_end_to_end_tokens_per_sec"`
+	// TTFTShare TTFT 在总耗时中的平均占比（0-1），越高说明越多时间花在排队/首字前。
+	TTFTShare *float64 `json:"ttft_share"`
+}
+
+type OpsLatencyBreakdownResponse struct {
+	TimeRange string    `json:"time_range"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+
+	Platform string `json:"platform,omitempty"`
+	GroupID  *int64 `json:"group_id,omitempty"`
+	GroupBy  string `json:"group_by"`
+
+	Items []*OpsLatencyBreakdownItem `json:"items"`
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type latencyBreakdownRepoStub struct {
+	OpsRepository
+	captured *OpsLatencyBreakdownFilter
+}
+
+func (s *latencyBreakdownRepoStub) GetLatencyBreakdown(ctx context.Context, filter *OpsLatencyBreakdownFilter) (*OpsLatencyBreakdownResponse, error) {
+	s.captured = filter
+	return &OpsLatencyBreakdownResponse{GroupBy: filter.GroupBy}, nil
+}
+
+func TestOpsServiceGetLatencyBreakdown_Validation(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name       string
+		filter     *OpsLatencyBreakdownFilter
+		wantReason string
+	}{
+		{name: "filter 不能为空", filter: nil, wantReason: "OPS_FILTER_REQUIRED"},
+		{
+			name:       "start_time/end_time 必填",
+			filter:     &OpsLatencyBreakdownFilter{EndTime: now},
+			wantReason: "OPS_TIME_RANGE_REQUIRED",
+		},
+		{
+			name:       "group_by 非法",
+			filter:     &OpsLatencyBreakdownFilter{StartTime: now.Add(-time.Hour), EndTime: now, GroupBy: "user"},
+			wantReason: "OPS_GROUP_BY_INVALID",
+		},
+		{
+			name:       "limit 越界",
+			filter:     &OpsLatencyBreakdownFilter{StartTime: now.Add(-time.Hour), EndTime: now, Limit: 201},
+			wantReason: "OPS_LIMIT_INVALID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &OpsService{opsRepo: &latencyBreakdownRepoStub{}}
+			_, err := svc.GetLatencyBreakdown(context.Background(), tt.filter)
+			require.Error(t, err)
+			require.Equal(t, 400, infraerrors.Code(err))
+			require.Equal(t, tt.wantReason, infraerrors.Reason(err))
+		})
+	}
+}
+
+func TestOpsServiceGetLatencyBreakdown_Defaults(t *testing.T) {
+	now := time.Now().UTC()
+	repo := &latencyBreakdownRepoStub{}
+	svc := &OpsService{opsRepo: repo}
+
+	resp, err := svc.GetLatencyBreakdown(context.Background(), &OpsLatencyBreakdownFilter{
+		StartTime: now.Add(-time.Hour),
+		EndTime:   now,
+		GroupBy:   " Account ",
+	})
+	require.NoError(t, err)
+	require.Equal(t, OpsLatencyBreakdownGroupByAccount, resp.GroupBy)
+	require.Equal(t, opsLatencyBreakdownDefaultLimit, repo.captured.Limit)
+}
+
+func TestUsageLogOutputTokensPerSecond(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	t.Run("流式请求扣除 TTFT", func(t *testing.T) {
+		log := &UsageLog{OutputTokens: 500, DurationMs: intPtr(6000), FirstTokenMs: intPtr(1000)}
+		require.Equal(t, 5000, *log.GenerationMs())
+		require.InDelta(t, 100.0, *log.OutputTokensPerSecond(), 1e-9)
+	})
+
+	t.Run("非流式请求按总耗时计算", func(t *testing.T) {
+		log := &UsageLog{OutputTokens: 300, DurationMs: intPtr(3000)}
+		require.Nil(t, log.GenerationMs())
+		require.InDelta(t, 100.0, *log.OutputTokensPerSecond(), 1e-9)
+	})
+
+	t.Run("无输出或耗时为 0 返回 nil", func(t *testing.T) {
+		require.Nil(t, (&UsageLog{DurationMs: intPtr(1000)}).OutputTokensPerSecond())
+		require.Nil(t, (&UsageLog{OutputTokens: 10, DurationMs: intPtr(0)}).OutputTokensPerSecond())
+	})
+
+	t.Run("TTFT 大于总耗时视为异常", func(t *testing.T) {
+		log := &UsageLog{OutputTokens: 10, DurationMs: intPtr(100), FirstTokenMs: intPtr(200)}
+		require.Nil(t, log.GenerationMs())
+		require.InDelta(t, 100.0, *log.OutputTokensPerSecond(), 1e-9)
+	})
+}
@@ -35,6 +35,9 @@ type OpsErrorLog struct {
 	Owner  string `json:"error_owner"`
 	Source string `json:"error_source"`
 
+	// ErrorCode 归一化上游错误代码（见 upstream_error_taxonomy.go），非上游错误为空。
+	ErrorCode string `json:"error_code"`
+
 	Severity string `json:"severity"`
 
 	StatusCode int    `json:"status_code"`
@@ -47,6 +50,12 @@ type OpsErrorLog struct {
 	ResolvedByUserName string     `json:"resolved_by_user_name"`
 	ResolvedStatusRaw  string     `json:"-"`
 
+	// 处理流程：状态（见 OpsErrorTriageStatus*）、负责人与评论数
+	Status         string `json:"status"`
+	AssigneeUserID *int64 `json:"assignee_user_id"`
+	AssigneeEmail  string `json:"assignee_email"`
+	CommentCount   int    `json:"comment_count"`
+
 	ClientRequestID string `json:"client_request_id"`
 	RequestID       string `json:"request_id"`
 	Message         string `json:"message"`
@@ -123,10 +132,16 @@ type OpsErrorLogFilter struct {
 	Phase            string // Special: Phase=="upstream" bypasses status>=400 clause; do not set together with ErrorPhasesAny.
 	Owner            string
 	Source           string
+	ErrorCode        string // 归一化上游错误代码精确匹配
 	Resolved         *bool
 	Query            string
 	UserQuery        string // Search by user email
 
+	// 处理流程过滤：Statuses 匹配任一状态；AssigneeUserID 与 Unassigned 互斥（同时设置时以 AssigneeUserID 为准）
+	Statuses       []string
+	AssigneeUserID *int64
+	Unassigned     bool
+
 	// Optional correlation keys for exact matching.
 	RequestID       string
 	ClientRequestID string
@@ -186,9 +201,90 @@ func (f *OpsErrorLogFilter) SetSort(sortBy, sortOrder string) {
 	f.SortOrder = strings.TrimSpace(sortOrder)
 }
 
+// 错误处理状态。resolved 与 ignored 视为已关闭（resolved 字段为 true）。
+const (
+	OpsErrorTriageStatusOpen          = "open"
+	OpsErrorTriageStatusInvestigating = "investigating"
+	OpsErrorTriageStatusResolved      = "resolved"
+	OpsErrorTriageStatusIgnored       = "ignored"
+)
+
+// IsValidOpsErrorTriageStatus 判断是否为合法的处理状态。
+func IsValidOpsErrorTriageStatus(status string) bool {
+	switch status {
+	case OpsErrorTriageStatusOpen, OpsErrorTriageStatusInvestigating, OpsErrorTriageStatusResolved, OpsErrorTriageStatusIgnored:
+		return true
+	}
+	return false
+}
+
+// IsOpsErrorTriageStatusClosed 关闭状态同步写入 resolved，保持既有 resolved 过滤与统计语义。
+func IsOpsErrorTriageStatusClosed(status string) bool {
+	return status == OpsErrorTriageStatusResolved || status == OpsErrorTriageStatusIgnored
+}
+
+// OpsErrorTriageUpdate 处理状态/负责人更新，nil 字段保持不变。
+// AssigneeUserID 指向 0 表示取消指派。
+type OpsErrorTriageUpdate struct {
+	Status         *string `json:"status"`
+	AssigneeUserID *int64  `json:"assignee_user_id"`
+}
+
+// OpsErrorComment 错误日志下的处理评论。
+type OpsErrorComment struct {
+	ID        int64     `json:"id"`
+	ErrorID   int64     `json:"error_id"`
+	UserID    *int64    `json:"user_id"`
+	UserEmail string    `json:"user_email"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type OpsErrorLogList struct {
 	Errors   []*OpsErrorLog `json:"errors"`
 	Total    int            `json:"total"`
 	Page     int            `json:"page"`
 	PageSize int            `json:"page_size"`
 }
+
+// OpsUpstreamRequestID 上游请求 ID 与网关 client_request_id 的对应记录。
+type OpsUpstreamRequestID struct {
+	ID                int64     `json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	ClientRequestID   string    `json:"client_request_id"`
+	UpstreamRequestID string    `json:"upstream_request_id"`
+	AccountID         *int64    `json:"account_id,omitempty"`
+	UpstreamHost      string    `json:"upstream_host,omitempty"`
+	StatusCode        int       `json:"status_code,omitempty"`
+}
+
+const (
+	OpsStreamStallSourceNone     = "none"
+	OpsStreamStallSourceUpstream = "upstream"
+	OpsStreamStallSourceRelay    = "relay"
+)
+
+// OpsStreamTiming 采样流式请求的逐事件耗时摘要。
+// 上游间隔 = 相邻事件从上游读到的时间差；中转延迟 = 事件读到至写出给客户端的耗时。
+type OpsStreamTiming struct {
+	ID              int64     `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	RequestID       string    `json:"request_id"`
+	ClientRequestID string    `json:"client_request_id"`
+	AccountID       *int64    `json:"account_id,omitempty"`
+	Platform        string    `json:"platform"`
+	Model           string    `json:"model"`
+	// Outcome: completed / client_disconnected / timeout / error
+	Outcome string `json:"outcome"`
+
+	EventCount       int    `json:"event_count"`
+	DurationMs       int64  `json:"duration_ms"`
+	FirstEventMs     *int64 `json:"first_event_ms,omitempty"`
+	MaxUpstreamGapMs int64  `json:"max_upstream_gap_ms"`
+	MaxRelayLagMs    int64  `json:"max_relay_lag_ms"`
+	AvgRelayLagMs    int64  `json:"avg_relay_lag_ms"`
+	// UpstreamGapHistogram 上游事件间隔分布（lt_100ms/lt_500ms/lt_1s/lt_5s/lt_15s/ge_15s）。
+	UpstreamGapHistogram map[string]int `json:"upstream_gap_histogram"`
+	// StallSource 最长卡顿的来源：upstream / relay / none。
+	StallSource string `json:"stall_source"`
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// OpsPanicKey 保存请求处理链中恢复的 panic（OpsPanic），由 ops_error_logger 落库。
+	OpsPanicKey = "ops_panic"
+
+	opsPanicMessageMaxLen = 1024
+	opsPanicStackMaxLen   = 16 * 1024
+)
+
+// OpsPanic 网关请求内恢复的 panic，消息与调用栈均已脱敏。
+type OpsPanic struct {
+	Message string
+	Stack   string
+}
+
+// MarkOpsPanic 记录本请求恢复的 panic，首个标记生效（外层兜底不覆盖内层已记录的根因）。
+func MarkOpsPanic(c *gin.Context, recovered any, stack []byte) {
+	if c == nil || recovered == nil {
+		return
+	}
+	if _, exists := c.Get(OpsPanicKey); exists {
+		return
+	}
+	c.Set(OpsPanicKey, OpsPanic{
+		Message: truncateString(redactContentModerationSecrets(fmt.Sprint(recovered)), opsPanicMessageMaxLen),
+		Stack:   truncateString(redactOpsPanicStack(string(stack)), opsPanicStackMaxLen),
+	})
+}
+
+// GetOpsPanic 返回本请求记录的 panic（若有）。
+func GetOpsPanic(c *gin.Context) (OpsPanic, bool) {
+	if c == nil {
+		return OpsPanic{}, false
+	}
+	v, ok := c.Get(OpsPanicKey)
+	if !ok {
+		return OpsPanic{}, false
+	}
+	p, ok := v.(OpsPanic)
+	return p, ok
+}
+
+// redactOpsPanicStack 去掉栈帧中的参数值与 PC 偏移：参数可能是请求体 / 密钥所在内存地址的原始字，
+// 排查只需要函数名与文件行号。
+func redactOpsPanicStack(stack string) string {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "\t") {
+			// 文件行："\t/path/file.go:123 +0x1d"
+			if idx := strings.LastIndex(line, " +0x"); idx > 0 {
+				lines[i] = line[:idx]
+			}
+			continue
+		}
+		// 函数行："pkg.(*T).Method(0xc000123, {0x1, 0x2})"
+		if strings.HasSuffix(line, ")") {
+			if idx := strings.LastIndex(line, "("); idx > 0 && idx < len(line)-2 {
+				lines[i] = line[:idx] + "(...)"
+			}
+		}
+	}
+	return redactContentModerationSecrets(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,33 @@
+//go:build unit
+
+package service
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkOpsPanic_RedactsAndKeepsFirst(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	stack := []byte("goroutine 7 [running]:\n" +
+		"github.com/Wei-Shaw/sub2api/internal/service.(*GatewayService).Forward(0xc000123456, {0x1a2b3c, 0xc0004})\n" +
+		"\t/src/internal/service/gateway_forward.go:123 +0x1d\n" +
+		"main.run()\n" +
+		"\t/src/main.go:10 +0x25\n")
+
+	MarkOpsPanic(c, "token=sk-ant-REDACTED leaked", stack)
+	MarkOpsPanic(c, "second", nil)
+
+	p, ok := GetOpsPanic(c)
+	require.True(t, ok)
+	require.NotContains(t, p.Message, "sk-ant-REDACTED")
+	require.Contains(t, p.Stack, "service.(*GatewayService).Forward(...)")
+	require.Contains(t, p.Stack, "\t/src/internal/service/gateway_forward.go:123\n")
+	require.Contains(t, p.Stack, "main.run()")
+	require.False(t, strings.Contains(p.Stack, "0xc000123456"))
+	require.False(t, strings.Contains(p.Stack, "+0x"))
+}
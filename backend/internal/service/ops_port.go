@@ -17,8 +17,21 @@ type OpsRepository interface {
 	ListSystemLogs(ctx context.Context, filter *OpsSystemLogFilter) (*OpsSystemLogList, error)
 	DeleteSystemLogs(ctx context.Context, filter *OpsSystemLogCleanupFilter) (int64, error)
 	InsertSystemLogCleanupAudit(ctx context.Context, input *OpsSystemLogCleanupAudit) error
+	BatchInsertUpstreamRequestIDs(ctx context.Context, inputs []*OpsUpstreamRequestID) (int64, error)
+	// ListUpstreamRequestIDs 按上游请求 ID 或 client_request_id 查询对应关系（新记录在前）。
+	ListUpstreamRequestIDs(ctx context.Context, key string, limit int) ([]*OpsUpstreamRequestID, error)
+	BatchInsertStreamTimings(ctx context.Context, inputs []*OpsStreamTiming) (int64, error)
+	// ListStreamTimings 按 request_id 或 client_request_id（任一 key 命中）查询采样流耗时摘要。
+	ListStreamTimings(ctx context.Context, keys []string, limit int) ([]*OpsStreamTiming, error)
+	InsertRequestReplay(ctx context.Context, replay *OpsRequestReplay) (int64, error)
+	// ListRequestReplays 按原请求的 request_id 查询重放结果（新记录在前）。
+	ListRequestReplays(ctx context.Context, requestID string, limit int) ([]*OpsRequestReplay, error)
 
 	UpdateErrorResolution(ctx context.Context, errorID int64, resolved bool, resolvedByUserID *int64, resolvedAt *time.Time) error
+	// UpdateErrorTriage 写入处理状态与负责人（全量），关闭状态同步 resolved 字段。
+	UpdateErrorTriage(ctx context.Context, errorID int64, status string, assigneeUserID *int64, actorUserID *int64) error
+	InsertErrorComment(ctx context.Context, comment *OpsErrorComment) (*OpsErrorComment, error)
+	ListErrorComments(ctx context.Context, errorID int64) ([]*OpsErrorComment, error)
 
 	// Lightweight window stats (for realtime WS / quick sampling).
 	GetWindowStats(ctx context.Context, filter *OpsDashboardFilter) (*OpsWindowStats, error)
@@ -30,7 +43,10 @@ type OpsRepository interface {
 	GetLatencyHistogram(ctx context.Context, filter *OpsDashboardFilter) (*OpsLatencyHistogramResponse, error)
 	GetErrorTrend(ctx context.Context, filter *OpsDashboardFilter, bucketSeconds int) (*OpsErrorTrendResponse, error)
 	GetErrorDistribution(ctx context.Context, filter *OpsDashboardFilter) (*OpsErrorDistributionResponse, error)
+	// GetErrorFingerprints 按错误指纹聚合事故窗口与基线窗口的错误数（事故快照）。
+	GetErrorFingerprints(ctx context.Context, filter *OpsErrorFingerprintFilter) ([]*OpsErrorFingerprint, error)
 	GetOpenAITokenStats(ctx context.Context, filter *OpsOpenAITokenStatsFilter) (*OpsOpenAITokenStatsResponse, error)
+	GetLatencyBreakdown(ctx context.Context, filter *OpsLatencyBreakdownFilter) (*OpsLatencyBreakdownResponse, error)
 
 	InsertSystemMetrics(ctx context.Context, input *OpsInsertSystemMetricsInput) error
 	GetLatestSystemMetrics(ctx context.Context, windowMinutes int) (*OpsSystemMetricsSnapshot, error)
@@ -56,6 +72,17 @@ type OpsRepository interface {
 	CreateAlertSilence(ctx context.Context, input *OpsAlertSilence) (*OpsAlertSilence, error)
 	IsAlertSilenced(ctx context.Context, ruleID int64, platform string, groupID *int64, region *string, now time.Time) (bool, error)
 
+	// Alert webhooks (channels + delivery history)
+	ListAlertWebhooks(ctx context.Context) ([]*OpsAlertWebhook, error)
+	GetAlertWebhookByID(ctx context.Context, id int64) (*OpsAlertWebhook, error)
+	CreateAlertWebhook(ctx context.Context, input *OpsAlertWebhook) (*OpsAlertWebhook, error)
+	UpdateAlertWebhook(ctx context.Context, input *OpsAlertWebhook) (*OpsAlertWebhook, error)
+	DeleteAlertWebhook(ctx context.Context, id int64) error
+	InsertAlertWebhookDelivery(ctx context.Context, delivery *OpsAlertWebhookDelivery) (int64, error)
+	ListAlertWebhookDeliveries(ctx context.Context, filter *OpsAlertWebhookDeliveryFilter) ([]*OpsAlertWebhookDelivery, error)
+	// GetAlertWindowCounts 统计告警窗口内的上游 429 数与流内失败数/流式请求数（按平台/分组/账号收窄）。
+	GetAlertWindowCounts(ctx context.Context, filter *OpsAlertWindowFilter) (*OpsAlertWindowCounts, error)
+
 	// Pre-aggregation (hourly/daily) used for long-window dashboard performance.
 	UpsertHourlyMetrics(ctx context.Context, startTime, endTime time.Time) error
 	UpsertDailyMetrics(ctx context.Context, startTime, endTime time.Time) error
@@ -108,6 +135,9 @@ type OpsInsertErrorLogInput struct {
 
 	ErrorSource string
 	ErrorOwner  string
+	// ErrorCode 是归一化后的上游错误代码（rate_limited / quota_exhausted / html_error_page ...）。
+	// 为空时由 OpsService 根据上游状态码与错误体推导；非上游错误保持为空。
+	ErrorCode string
 
 	UpstreamStatusCode   *int
 	UpstreamErrorMessage *string
@@ -213,6 +243,8 @@ type OpsSystemLogFilter struct {
 
 	Level     string
 	Component string
+	// ComponentPrefix 按 component 前缀过滤（如 "audit." 取全部审计日志）。
+	ComponentPrefix string
 
 	RequestID       string
 	ClientRequestID string
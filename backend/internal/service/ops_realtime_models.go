@@ -66,6 +66,8 @@ type GroupAvailability struct {
 	AvailableCount int64  `json:"available_count"`
 	RateLimitCount int64  `json:"rate_limit_count"`
 	ErrorCount     int64  `json:"error_count"`
+	// ActiveTier 当前仍有可用账号的最高故障转移层级（0=primary，1=secondary，2=emergency），无可用账号时为空
+	ActiveTier *int `json:"active_tier,omitempty"`
 }
 
 // AccountAvailability represents current availability for a single account.
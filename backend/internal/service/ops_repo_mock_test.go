@@ -7,13 +7,25 @@ import (
 
 // opsRepoMock is a test-only OpsRepository implementation with optional function hooks.
 type opsRepoMock struct {
-	InsertErrorLogFn              func(ctx context.Context, input *OpsInsertErrorLogInput) (int64, error)
-	BatchInsertErrorLogsFn        func(ctx context.Context, inputs []*OpsInsertErrorLogInput) (int64, error)
-	BatchInsertSystemLogsFn       func(ctx context.Context, inputs []*OpsInsertSystemLogInput) (int64, error)
-	ListSystemLogsFn              func(ctx context.Context, filter *OpsSystemLogFilter) (*OpsSystemLogList, error)
-	DeleteSystemLogsFn            func(ctx context.Context, filter *OpsSystemLogCleanupFilter) (int64, error)
-	InsertSystemLogCleanupAuditFn func(ctx context.Context, input *OpsSystemLogCleanupAudit) error
-	LookupDeletedKeyAuditFn       func(ctx context.Context, key string) (*DeletedKeyAuditResult, error)
+	InsertErrorLogFn                func(ctx context.Context, input *OpsInsertErrorLogInput) (int64, error)
+	BatchInsertErrorLogsFn          func(ctx context.Context, inputs []*OpsInsertErrorLogInput) (int64, error)
+	BatchInsertSystemLogsFn         func(ctx context.Context, inputs []*OpsInsertSystemLogInput) (int64, error)
+	ListSystemLogsFn                func(ctx context.Context, filter *OpsSystemLogFilter) (*OpsSystemLogList, error)
+	DeleteSystemLogsFn              func(ctx context.Context, filter *OpsSystemLogCleanupFilter) (int64, error)
+	InsertSystemLogCleanupAuditFn   func(ctx context.Context, input *OpsSystemLogCleanupAudit) error
+	LookupDeletedKeyAuditFn         func(ctx context.Context, key string) (*DeletedKeyAuditResult, error)
+	GetErrorFingerprintsFn          func(ctx context.Context, filter *OpsErrorFingerprintFilter) ([]*OpsErrorFingerprint, error)
+	GetErrorLogByIDFn               func(ctx context.Context, id int64) (*OpsErrorLogDetail, error)
+	ListErrorLogsFn                 func(ctx context.Context, filter *OpsErrorLogFilter) (*OpsErrorLogList, error)
+	ListRequestDetailsFn            func(ctx context.Context, filter *OpsRequestDetailFilter) ([]*OpsRequestDetail, int64, error)
+	BatchInsertUpstreamRequestIDsFn func(ctx context.Context, inputs []*OpsUpstreamRequestID) (int64, error)
+	ListUpstreamRequestIDsFn        func(ctx context.Context, key string, limit int) ([]*OpsUpstreamRequestID, error)
+	BatchInsertStreamTimingsFn      func(ctx context.Context, inputs []*OpsStreamTiming) (int64, error)
+	ListStreamTimingsFn             func(ctx context.Context, keys []string, limit int) ([]*OpsStreamTiming, error)
+	InsertRequestReplayFn           func(ctx context.Context, replay *OpsRequestReplay) (int64, error)
+	ListRequestReplaysFn            func(ctx context.Context, requestID string, limit int) ([]*OpsRequestReplay, error)
+	UpdateErrorTriageFn             func(ctx context.Context, errorID int64, status string, assigneeUserID *int64, actorUserID *int64) error
+	InsertErrorCommentFn            func(ctx context.Context, comment *OpsErrorComment) (*OpsErrorComment, error)
 }
 
 func (m *opsRepoMock) InsertErrorLog(ctx context.Context, input *OpsInsertErrorLogInput) (int64, error) {
@@ -31,14 +43,23 @@ func (m *opsRepoMock) BatchInsertErrorLogs(ctx context.Context, inputs []*OpsIns
 }
 
 func (m *opsRepoMock) ListErrorLogs(ctx context.Context, filter *OpsErrorLogFilter) (*OpsErrorLogList, error) {
+	if m.ListErrorLogsFn != nil {
+		return m.ListErrorLogsFn(ctx, filter)
+	}
 	return &OpsErrorLogList{Errors: []*OpsErrorLog{}, Page: 1, PageSize: 20}, nil
 }
 
 func (m *opsRepoMock) GetErrorLogByID(ctx context.Context, id int64) (*OpsErrorLogDetail, error) {
+	if m.GetErrorLogByIDFn != nil {
+		return m.GetErrorLogByIDFn(ctx, id)
+	}
 	return &OpsErrorLogDetail{}, nil
 }
 
 func (m *opsRepoMock) ListRequestDetails(ctx context.Context, filter *OpsRequestDetailFilter) ([]*OpsRequestDetail, int64, error) {
+	if m.ListRequestDetailsFn != nil {
+		return m.ListRequestDetailsFn(ctx, filter)
+	}
 	return []*OpsRequestDetail{}, 0, nil
 }
 
@@ -70,10 +91,70 @@ func (m *opsRepoMock) InsertSystemLogCleanupAudit(ctx context.Context, input *Op
 	return nil
 }
 
+func (m *opsRepoMock) BatchInsertUpstreamRequestIDs(ctx context.Context, inputs []*OpsUpstreamRequestID) (int64, error) {
+	if m.BatchInsertUpstreamRequestIDsFn != nil {
+		return m.BatchInsertUpstreamRequestIDsFn(ctx, inputs)
+	}
+	return int64(len(inputs)), nil
+}
+
+func (m *opsRepoMock) ListUpstreamRequestIDs(ctx context.Context, key string, limit int) ([]*OpsUpstreamRequestID, error) {
+	if m.ListUpstreamRequestIDsFn != nil {
+		return m.ListUpstreamRequestIDsFn(ctx, key, limit)
+	}
+	return []*OpsUpstreamRequestID{}, nil
+}
+
+func (m *opsRepoMock) BatchInsertStreamTimings(ctx context.Context, inputs []*OpsStreamTiming) (int64, error) {
+	if m.BatchInsertStreamTimingsFn != nil {
+		return m.BatchInsertStreamTimingsFn(ctx, inputs)
+	}
+	return int64(len(inputs)), nil
+}
+
+func (m *opsRepoMock) ListStreamTimings(ctx context.Context, keys []string, limit int) ([]*OpsStreamTiming, error) {
+	if m.ListStreamTimingsFn != nil {
+		return m.ListStreamTimingsFn(ctx, keys, limit)
+	}
+	return []*OpsStreamTiming{}, nil
+}
+
+func (m *opsRepoMock) InsertRequestReplay(ctx context.Context, replay *OpsRequestReplay) (int64, error) {
+	if m.InsertRequestReplayFn != nil {
+		return m.InsertRequestReplayFn(ctx, replay)
+	}
+	return 0, nil
+}
+
+func (m *opsRepoMock) ListRequestReplays(ctx context.Context, requestID string, limit int) ([]*OpsRequestReplay, error) {
+	if m.ListRequestReplaysFn != nil {
+		return m.ListRequestReplaysFn(ctx, requestID, limit)
+	}
+	return []*OpsRequestReplay{}, nil
+}
+
 func (m *opsRepoMock) UpdateErrorResolution(ctx context.Context, errorID int64, resolved bool, resolvedByUserID *int64, resolvedAt *time.Time) error {
 	return nil
 }
 
+func (m *opsRepoMock) UpdateErrorTriage(ctx context.Context, errorID int64, status string, assigneeUserID *int64, actorUserID *int64) error {
+	if m.UpdateErrorTriageFn != nil {
+		return m.UpdateErrorTriageFn(ctx, errorID, status, assigneeUserID, actorUserID)
+	}
+	return nil
+}
+
+func (m *opsRepoMock) InsertErrorComment(ctx context.Context, comment *OpsErrorComment) (*OpsErrorComment, error) {
+	if m.InsertErrorCommentFn != nil {
+		return m.InsertErrorCommentFn(ctx, comment)
+	}
+	return comment, nil
+}
+
+func (m *opsRepoMock) ListErrorComments(ctx context.Context, errorID int64) ([]*OpsErrorComment, error) {
+	return []*OpsErrorComment{}, nil
+}
+
 func (m *opsRepoMock) GetWindowStats(ctx context.Context, filter *OpsDashboardFilter) (*OpsWindowStats, error) {
 	return &OpsWindowStats{}, nil
 }
@@ -102,10 +183,21 @@ func (m *opsRepoMock) GetErrorDistribution(ctx context.Context, filter *OpsDashb
 	return &OpsErrorDistributionResponse{}, nil
 }
 
+func (m *opsRepoMock) GetErrorFingerprints(ctx context.Context, filter *OpsErrorFingerprintFilter) ([]*OpsErrorFingerprint, error) {
+	if m.GetErrorFingerprintsFn != nil {
+		return m.GetErrorFingerprintsFn(ctx, filter)
+	}
+	return []*OpsErrorFingerprint{}, nil
+}
+
 func (m *opsRepoMock) GetOpenAITokenStats(ctx context.Context, filter *OpsOpenAITokenStatsFilter) (*OpsOpenAITokenStatsResponse, error) {
 	return &OpsOpenAITokenStatsResponse{}, nil
 }
 
+func (m *opsRepoMock) GetLatencyBreakdown(ctx context.Context, filter *OpsLatencyBreakdownFilter) (*OpsLatencyBreakdownResponse, error) {
+	return &OpsLatencyBreakdownResponse{}, nil
+}
+
 func (m *opsRepoMock) InsertSystemMetrics(ctx context.Context, input *OpsInsertSystemMetricsInput) error {
 	return nil
 }
@@ -174,6 +266,38 @@ func (m *opsRepoMock) IsAlertSilenced(ctx context.Context, ruleID int64, platfor
 	return false, nil
 }
 
+func (m *opsRepoMock) ListAlertWebhooks(ctx context.Context) ([]*OpsAlertWebhook, error) {
+	return []*OpsAlertWebhook{}, nil
+}
+
+func (m *opsRepoMock) GetAlertWebhookByID(ctx context.Context, id int64) (*OpsAlertWebhook, error) {
+	return &OpsAlertWebhook{}, nil
+}
+
+func (m *opsRepoMock) CreateAlertWebhook(ctx context.Context, input *OpsAlertWebhook) (*OpsAlertWebhook, error) {
+	return input, nil
+}
+
+func (m *opsRepoMock) UpdateAlertWebhook(ctx context.Context, input *OpsAlertWebhook) (*OpsAlertWebhook, error) {
+	return input, nil
+}
+
+func (m *opsRepoMock) DeleteAlertWebhook(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *opsRepoMock) InsertAlertWebhookDelivery(ctx context.Context, delivery *OpsAlertWebhookDelivery) (int64, error) {
+	return 0, nil
+}
+
+func (m *opsRepoMock) ListAlertWebhookDeliveries(ctx context.Context, filter *OpsAlertWebhookDeliveryFilter) ([]*OpsAlertWebhookDelivery, error) {
+	return []*OpsAlertWebhookDelivery{}, nil
+}
+
+func (m *opsRepoMock) GetAlertWindowCounts(ctx context.Context, filter *OpsAlertWindowFilter) (*OpsAlertWindowCounts, error) {
+	return &OpsAlertWindowCounts{}, nil
+}
+
 func (m *opsRepoMock) UpsertHourlyMetrics(ctx context.Context, startTime, endTime time.Time) error {
 	return nil
 }
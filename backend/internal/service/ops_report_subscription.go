@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+)
+
+// 定时报表类型，与 OpsEmailReportConfig 中的开关一一对应。
+const (
+	OpsReportTypeDailySummary  = "daily_summary"
+	OpsReportTypeWeeklySummary = "weekly_summary"
+	OpsReportTypeErrorDigest   = "error_digest"
+	OpsReportTypeAccountHealth = "account_health"
+	OpsReportTypeWeeklyDigest  = "weekly_digest"
+)
+
+var (
+	ErrOpsReportSubscriptionInvalidType    = infraerrors.BadRequest("OPS_REPORT_SUBSCRIPTION_INVALID_TYPE", "unknown report type")
+	ErrOpsReportSubscriptionInvalidWebhook = infraerrors.BadRequest("OPS_REPORT_SUBSCRIPTION_INVALID_WEBHOOK", "webhook_url must be a public https url")
+)
+
+// OpsReportSubscription 单个管理员的报表订阅偏好。
+// 没有订阅记录的管理员沿用全局收件人配置；有记录时以记录为准（未勾选的报表类型视为退订）。
+type OpsReportSubscription struct {
+	UserID      int64     `json:"user_id"`
+	ReportTypes []string  `json:"report_types"`
+	Email       bool      `json:"email"`
+	WebhookURL  string    `json:"webhook_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// OpsReportSubscriptionInput 更新订阅偏好的请求体。
+type OpsReportSubscriptionInput struct {
+	ReportTypes []string `json:"report_types"`
+	Email       bool     `json:"email"`
+	WebhookURL  string   `json:"webhook_url"`
+}
+
+// Subscribes 判断是否订阅了指定报表类型。
+func (s *OpsReportSubscription) Subscribes(reportType string) bool {
+	if s == nil {
+		return false
+	}
+	for _, t := range s.ReportTypes {
+		if t == reportType {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidOpsReportType(reportType string) bool {
+	switch reportType {
+	case OpsReportTypeDailySummary, OpsReportTypeWeeklySummary, OpsReportTypeErrorDigest, OpsReportTypeAccountHealth, OpsReportTypeWeeklyDigest:
+		return true
+	}
+	return false
+}
+
+// ListReportSubscriptions 返回全部管理员的订阅记录（按用户 ID 排序）。
+func (s *OpsService) ListReportSubscriptions(ctx context.Context) ([]*OpsReportSubscription, error) {
+	if s == nil || s.settingRepo == nil {
+		return []*OpsReportSubscription{}, nil
+	}
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyOpsReportSubscriptions)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return []*OpsReportSubscription{}, nil
+		}
+		return nil, err
+	}
+	subs := []*OpsReportSubscription{}
+	if err := json.Unmarshal([]byte(raw), &subs); err != nil {
+		// Corrupted JSON should not block report delivery; treat as no subscriptions.
+		return []*OpsReportSubscription{}, nil
+	}
+	return subs, nil
+}
+
+// GetReportSubscription 返回管理员的订阅记录；未设置时返回 nil。
+func (s *OpsService) GetReportSubscription(ctx context.Context, userID int64) (*OpsReportSubscription, error) {
+	subs, err := s.ListReportSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		if sub != nil && sub.UserID == userID {
+			return sub, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateReportSubscription 创建或覆盖管理员的订阅偏好。
+func (s *OpsService) UpdateReportSubscription(ctx context.Context, userID int64, in *OpsReportSubscriptionInput) (*OpsReportSubscription, error) {
+	if s == nil || s.settingRepo == nil {
+		return nil, errors.New("setting repository not initialized")
+	}
+	if in == nil {
+		return nil, errors.New("invalid request")
+	}
+
+	seen := make(map[string]struct{}, len(in.ReportTypes))
+	types := make([]string, 0, len(in.ReportTypes))
+	for _, raw := range in.ReportTypes {
+		t := strings.TrimSpace(raw)
+		if !isValidOpsReportType(t) {
+			return nil, ErrOpsReportSubscriptionInvalidType
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	webhookURL := strings.TrimSpace(in.WebhookURL)
+	if webhookURL != "" {
+		normalized, err := urlvalidator.ValidateHTTPSURL(webhookURL, urlvalidator.ValidationOptions{})
+		if err != nil {
+			return nil, ErrOpsReportSubscriptionInvalidWebhook.WithCause(err)
+		}
+		webhookURL = normalized
+	}
+
+	sub := &OpsReportSubscription{
+		UserID:      userID,
+		ReportTypes: types,
+		Email:       in.Email,
+		WebhookURL:  webhookURL,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if err := s.saveReportSubscription(ctx, userID, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// DeleteReportSubscription 删除管理员的订阅记录，恢复为沿用全局收件人配置。
+func (s *OpsService) DeleteReportSubscription(ctx context.Context, userID int64) error {
+	if s == nil || s.settingRepo == nil {
+		return errors.New("setting repository not initialized")
+	}
+	return s.saveReportSubscription(ctx, userID, nil)
+}
+
+// saveReportSubscription 以 sub 替换 userID 的记录；sub 为 nil 时删除。
+func (s *OpsService) saveReportSubscription(ctx context.Context, userID int64, sub *OpsReportSubscription) error {
+	subs, err := s.ListReportSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	out := make([]*OpsReportSubscription, 0, len(subs)+1)
+	for _, existing := range subs {
+		if existing != nil && existing.UserID != userID {
+			out = append(out, existing)
+		}
+	}
+	if sub != nil {
+		out = append(out, sub)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UserID < out[j].UserID })
+
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return s.settingRepo.Set(ctx, SettingKeyOpsReportSubscriptions, string(raw))
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	opsRequestReplayTimeout      = 2 * time.Minute
+	opsRequestReplayMaxBodyBytes = 64 * 1024
+	opsRequestReplayListLimit    = 50
+)
+
+var (
+	ErrOpsRequestReplayDumpNotFound       = infraerrors.NotFound("OPS_REQUEST_REPLAY_DUMP_NOT_FOUND", "no request dump found for key; the API key must be traced while the request is served")
+	ErrOpsRequestReplayInvalidUpstream    = infraerrors.BadRequest("OPS_REQUEST_REPLAY_INVALID_UPSTREAM", "upstream index out of range")
+	ErrOpsRequestReplayIncomplete         = infraerrors.BadRequest("OPS_REQUEST_REPLAY_INCOMPLETE", "the captured upstream request is incomplete (not captured or body truncated) and cannot be replayed")
+	ErrOpsRequestReplayAccountMismatch    = infraerrors.BadRequest("OPS_REQUEST_REPLAY_ACCOUNT_MISMATCH", "replay account must have the same platform, type and base url as the original account")
+	ErrOpsRequestReplayUnsupportedAccount = infraerrors.BadRequest("OPS_REQUEST_REPLAY_UNSUPPORTED_ACCOUNT", "replay is only supported for anthropic, openai and grok accounts with token credentials")
+)
+
+// opsRequestReplaySkippedHeaders 重放时不沿用的抓包请求头：逐跳头由 HTTP 栈管理，认证头按账号凭据重新注入。
+var opsRequestReplaySkippedHeaders = map[string]struct{}{
+	"host":                {},
+	"content-length":      {},
+	"transfer-encoding":   {},
+	"connection":          {},
+	"keep-alive":          {},
+	"proxy-authorization": {},
+	"proxy-connection":    {},
+	"te":                  {},
+	"trailer":             {},
+	"upgrade":             {},
+	"cookie":              {},
+	"accept-encoding":     {},
+	"authorization":       {},
+	"x-api-key":           {},
+	"x-goog-api-key":      {},
+	"chatgpt-account-id":  {},
+	"x-openai-fedramp":    {},
+}
+
+// opsRequestReplayMaskedHeaders 保存重放响应时脱敏的响应头（小写）。
+var opsRequestReplayMaskedHeaders = map[string]struct{}{
+	"set-cookie":    {},
+	"authorization": {},
+}
+
+// OpsRequestReplay 一次重放的结果，按 RequestID 关联调试包中的原请求。
+type OpsRequestReplay struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy int64     `json:"created_by,omitempty"`
+
+	RequestID       string `json:"request_id"`
+	APIKeyID        int64  `json:"api_key_id"`
+	UpstreamIndex   int    `json:"upstream_index"`
+	SourceAccountID *int64 `json:"source_account_id,omitempty"`
+	AccountID       int64  `json:"account_id"`
+	Method          string `json:"method"`
+	URL             string `json:"url"`
+
+	StatusCode            int               `json:"status_code"`
+	DurationMs            int64             `json:"duration_ms"`
+	ResponseHeaders       map[string]string `json:"response_headers,omitempty"`
+	ResponseBody          string            `json:"response_body,omitempty"`
+	ResponseBodyTruncated bool              `json:"response_body_truncated,omitempty"`
+	// ErrorMessage 未拿到上游响应时的错误（网络错误、超时等）。
+	ErrorMessage string `json:"error_message,omitempty"`
+
+	// SourceStatusCode 原上游调用的状态码，仅在重放接口的返回中提供，便于直接对比。
+	SourceStatusCode int `json:"source_status_code,omitempty"`
+}
+
+// OpsRequestReplayInput 重放参数：Key 同调试包（request_id / client_request_id），
+// UpstreamIndex 为 nil 时重放最后一次上游调用，AccountID 为 nil 时沿用原账号。
+type OpsRequestReplayInput struct {
+	Key           string
+	StartTime     time.Time
+	EndTime       time.Time
+	UpstreamIndex *int
+	AccountID     *int64
+}
+
+// ReplayRequestDump 按抓包记录重新发起一次上游请求并保存结果。
+// 请求体与非认证请求头取自抓包记录，认证头按（可替换的）账号凭据重新注入；重放不计费、不写使用记录。
+func (s *OpsService) ReplayRequestDump(ctx context.Context, input OpsRequestReplayInput, createdBy int64) (*OpsRequestReplay, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if s.accountRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("ACCOUNT_REPO_UNAVAILABLE", "Account repository not available")
+	}
+	key := strings.TrimSpace(input.Key)
+	if key == "" || len(key) > opsDebugBundleMaxKeyLen {
+		return nil, ErrOpsDebugBundleInvalidKey
+	}
+
+	bundle := &OpsDebugBundle{}
+	if err := s.loadDebugBundleRecords(ctx, bundle, key, input.StartTime, input.EndTime); err != nil {
+		return nil, err
+	}
+	record := s.findAPIKeyTraceRecord(ctx, []string{key}, bundle)
+	if record == nil {
+		return nil, ErrOpsRequestReplayDumpNotFound
+	}
+
+	index := len(record.UpstreamResponses) - 1
+	if input.UpstreamIndex != nil {
+		index = *input.UpstreamIndex
+	}
+	if index < 0 || index >= len(record.UpstreamResponses) || record.UpstreamResponses[index] == nil {
+		return nil, ErrOpsRequestReplayInvalidUpstream
+	}
+	upstream := record.UpstreamResponses[index]
+	// 早于上游请求抓取的记录没有请求头；截断的请求体无法还原
+	if upstream.URL == "" || len(upstream.RequestHeaders) == 0 || upstream.RequestBodyTruncated {
+		return nil, ErrOpsRequestReplayIncomplete
+	}
+
+	account, err := s.resolveReplayAccount(ctx, upstream.AccountID, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	token, httpUpstream, err := s.replayCredentials(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &OpsRequestReplay{
+		CreatedBy:        createdBy,
+		RequestID:        record.RequestID,
+		APIKeyID:         record.APIKeyID,
+		UpstreamIndex:    index,
+		AccountID:        account.ID,
+		Method:           upstream.Method,
+		URL:              upstream.URL,
+		SourceStatusCode: upstream.StatusCode,
+	}
+	if upstream.AccountID > 0 {
+		sourceAccountID := upstream.AccountID
+		replay.SourceAccountID = &sourceAccountID
+	}
+	if err := s.sendReplayRequest(ctx, httpUpstream, account, token, upstream, replay); err != nil {
+		return nil, err
+	}
+
+	id, err := s.opsRepo.InsertRequestReplay(ctx, replay)
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_REQUEST_REPLAY_SAVE_FAILED", "Failed to save replay result").WithCause(err)
+	}
+	replay.ID = id
+	return replay, nil
+}
+
+// ListRequestReplays 按原请求的 request_id 列出重放记录（新记录在前）。
+func (s *OpsService) ListRequestReplays(ctx context.Context, key string) ([]*OpsRequestReplay, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > opsDebugBundleMaxKeyLen {
+		return nil, ErrOpsDebugBundleInvalidKey
+	}
+	replays, err := s.opsRepo.ListRequestReplays(ctx, key, opsRequestReplayListLimit)
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_REQUEST_REPLAY_LOAD_FAILED", "Failed to load replay results").WithCause(err)
+	}
+	return replays, nil
+}
+
+// resolveReplayAccount 加载重放账号；替换账号时要求与原账号平台、类型一致，API Key 账号还需 base_url 一致，
+// 避免把凭据发往其他账号的上游地址。
+func (s *OpsService) resolveReplayAccount(ctx context.Context, sourceAccountID int64, overrideID *int64) (*Account, error) {
+	accountID := sourceAccountID
+	if overrideID != nil {
+		accountID = *overrideID
+	}
+	if accountID <= 0 {
+		return nil, ErrAccountNotFound
+	}
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == sourceAccountID {
+		return account, nil
+	}
+	source, err := s.accountRepo.GetByID(ctx, sourceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Platform != account.Platform || source.Type != account.Type {
+		return nil, ErrOpsRequestReplayAccountMismatch
+	}
+	if account.Type == AccountTypeAPIKey &&
+		strings.TrimRight(source.GetCredential("base_url"), "/") != strings.TrimRight(account.GetCredential("base_url"), "/") {
+		return nil, ErrOpsRequestReplayAccountMismatch
+	}
+	return account, nil
+}
+
+// replayCredentials 按平台取账号令牌及对应网关使用的 HTTPUpstream。
+func (s *OpsService) replayCredentials(ctx context.Context, account *Account) (string, HTTPUpstream, error) {
+	var (
+		token        string
+		httpUpstream HTTPUpstream
+		err          error
+	)
+	switch account.Platform {
+	case PlatformAnthropic:
+		if s.gatewayService == nil {
+			return "", nil, ErrOpsRequestReplayUnsupportedAccount
+		}
+		token, _, err = s.gatewayService.GetAccessToken(ctx, account)
+		httpUpstream = s.gatewayService.httpUpstream
+	case PlatformOpenAI, PlatformGrok:
+		if s.openAIGatewayService == nil {
+			return "", nil, ErrOpsRequestReplayUnsupportedAccount
+		}
+		token, _, err = s.openAIGatewayService.GetAccessToken(ctx, account)
+		httpUpstream = s.openAIGatewayService.httpUpstream
+	default:
+		return "", nil, ErrOpsRequestReplayUnsupportedAccount
+	}
+	if err != nil {
+		return "", nil, infraerrors.ServiceUnavailable("OPS_REQUEST_REPLAY_CREDENTIALS_FAILED", "Failed to get account credentials").WithCause(err)
+	}
+	// Bedrock 等签名类账号不返回令牌
+	if token == "" || httpUpstream == nil {
+		return "", nil, ErrOpsRequestReplayUnsupportedAccount
+	}
+	return token, httpUpstream, nil
+}
+
+// sendReplayRequest 发出重放请求并把响应写入 replay；网络错误记入 ErrorMessage 而非返回错误。
+func (s *OpsService) sendReplayRequest(ctx context.Context, httpUpstream HTTPUpstream, account *Account, token string, upstream *APIKeyTraceUpstreamResponse, replay *OpsRequestReplay) error {
+	ctx, cancel := context.WithTimeout(ctx, opsRequestReplayTimeout)
+	defer cancel()
+
+	target := upstream.URL
+	if upstream.Query != "" {
+		target += "?" + upstream.Query
+	}
+	var body io.Reader
+	if upstream.RequestBody != "" {
+		body = strings.NewReader(upstream.RequestBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, upstream.Method, target, body)
+	if err != nil {
+		return infraerrors.BadRequest("OPS_REQUEST_REPLAY_INVALID_REQUEST", "captured upstream request is invalid").WithCause(err)
+	}
+	for name, value := range upstream.RequestHeaders {
+		lower := strings.ToLower(name)
+		if _, skip := opsRequestReplaySkippedHeaders[lower]; skip {
+			// 认证头仅在原请求携带时按同样方式注入新凭据
+			switch lower {
+			case "authorization":
+				req.Header.Set("Authorization", "Bearer "+token)
+			case "x-api-key", "x-goog-api-key":
+				req.Header.Set(name, token)
+			}
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if account.IsOpenAIOAuth() {
+		if err := resolveAndSetOpenAIChatGPTAccountHeaders(ctx, s.accountRepo, req.Header, account); err != nil {
+			return err
+		}
+	}
+
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	startedAt := time.Now()
+	resp, err := httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		replay.DurationMs = time.Since(startedAt).Milliseconds()
+		replay.ErrorMessage = sanitizeUpstreamErrorMessage(err.Error())
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, readErr := io.ReadAll(io.LimitReader(resp.Body, opsRequestReplayMaxBodyBytes+1))
+	replay.DurationMs = time.Since(startedAt).Milliseconds()
+	replay.StatusCode = resp.StatusCode
+	replay.ResponseHeaders = opsRequestReplayResponseHeaders(resp.Header)
+	if len(data) > opsRequestReplayMaxBodyBytes {
+		data = data[:opsRequestReplayMaxBodyBytes]
+		replay.ResponseBodyTruncated = true
+	}
+	replay.ResponseBody = redactDebugBundleBody(string(data))
+	if readErr != nil {
+		replay.ErrorMessage = sanitizeUpstreamErrorMessage(readErr.Error())
+	}
+	return nil
+}
+
+func opsRequestReplayResponseHeaders(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if _, masked := opsRequestReplayMaskedHeaders[strings.ToLower(name)]; masked {
+			out[name] = "***"
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type opsReplayAccountRepoStub struct {
+	AccountRepository
+	accounts map[int64]*Account
+}
+
+func (r *opsReplayAccountRepoStub) GetByID(_ context.Context, id int64) (*Account, error) {
+	if account, ok := r.accounts[id]; ok {
+		return account, nil
+	}
+	return nil, ErrAccountNotFound
+}
+
+func newOpsRequestReplayTestService(t *testing.T) (*OpsService, *anthropicHTTPUpstreamRecorder, *[]*OpsRequestReplay) {
+	t.Helper()
+	svc, cache := newOpsDebugBundleTestService(t)
+	saved := &[]*OpsRequestReplay{}
+	repo := svc.opsRepo.(*opsRepoMock)
+	repo.InsertRequestReplayFn = func(_ context.Context, replay *OpsRequestReplay) (int64, error) {
+		*saved = append(*saved, replay)
+		return int64(len(*saved)), nil
+	}
+
+	cache.records[11] = []*APIKeyTraceRecord{{
+		RequestID: "req-1",
+		APIKeyID:  11,
+		UpstreamResponses: []*APIKeyTraceUpstreamResponse{
+			{AccountID: 201, Method: http.MethodPost, URL: "https://api.anthropic.com/v1/messages", StatusCode: 529},
+			{
+				AccountID:      201,
+				Method:         http.MethodPost,
+				URL:            "https://api.anthropic.com/v1/messages",
+				Query:          "beta=true",
+				RequestHeaders: map[string]string{"X-Api-Key": "***", "Anthropic-Version": "2023-06-01", "Cookie": "***", "Content-Length": "9"},
+				RequestBody:    `{"a":"b"}`,
+				StatusCode:     500,
+			},
+		},
+	}}
+
+	source := newAnthropicAPIKeyAccountForTest()
+	other := newAnthropicAPIKeyAccountForTest()
+	other.ID = 202
+	other.Credentials = map[string]any{"api_key": "other-key", "base_url": "https://api.anthropic.com/"}
+	foreign := newAnthropicAPIKeyAccountForTest()
+	foreign.ID = 203
+	foreign.Credentials = map[string]any{"api_key": "foreign-key", "base_url": "https://relay.example"}
+	svc.accountRepo = &opsReplayAccountRepoStub{accounts: map[int64]*Account{201: source, 202: other, 203: foreign}}
+
+	upstream := &anthropicHTTPUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Set-Cookie": []string{"sid=1"}, "Request-Id": []string{"req_up"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"msg_1"}`)),
+	}}
+	svc.gatewayService = &GatewayService{httpUpstream: upstream}
+	return svc, upstream, saved
+}
+
+func TestOpsService_ReplayRequestDump(t *testing.T) {
+	svc, upstream, saved := newOpsRequestReplayTestService(t)
+	end := time.Now()
+
+	replay, err := svc.ReplayRequestDump(context.Background(), OpsRequestReplayInput{Key: "req-1", StartTime: end.Add(-time.Hour), EndTime: end}, 5)
+	require.NoError(t, err)
+	require.Len(t, *saved, 1)
+	require.Equal(t, int64(1), replay.ID)
+	require.Equal(t, "req-1", replay.RequestID)
+	require.Equal(t, 1, replay.UpstreamIndex, "defaults to the last upstream call")
+	require.Equal(t, int64(201), replay.AccountID)
+	require.Equal(t, 500, replay.SourceStatusCode)
+	require.Equal(t, http.StatusOK, replay.StatusCode)
+	require.Equal(t, `{"id":"msg_1"}`, replay.ResponseBody)
+	require.Equal(t, "***", replay.ResponseHeaders["Set-Cookie"])
+
+	req := upstream.lastReq
+	require.Equal(t, "https://api.anthropic.com/v1/messages?beta=true", req.URL.String())
+	require.Equal(t, "upstream-anthropic-key", req.Header.Get("X-Api-Key"), "masked auth header is re-injected from the account")
+	require.Equal(t, "2023-06-01", req.Header.Get("Anthropic-Version"))
+	require.Empty(t, req.Header.Get("Cookie"))
+	require.Equal(t, `{"a":"b"}`, string(upstream.lastBody))
+}
+
+func TestOpsService_ReplayRequestDump_AccountOverride(t *testing.T) {
+	svc, upstream, _ := newOpsRequestReplayTestService(t)
+	end := time.Now()
+	input := OpsRequestReplayInput{Key: "req-1", StartTime: end.Add(-time.Hour), EndTime: end}
+
+	input.AccountID = ptrInt64ForOpsReplayTest(203)
+	_, err := svc.ReplayRequestDump(context.Background(), input, 5)
+	require.ErrorIs(t, err, ErrOpsRequestReplayAccountMismatch, "credentials must not be sent to another base url")
+	require.Nil(t, upstream.lastReq)
+
+	input.AccountID = ptrInt64ForOpsReplayTest(202)
+	replay, err := svc.ReplayRequestDump(context.Background(), input, 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(202), replay.AccountID)
+	require.Equal(t, int64(201), *replay.SourceAccountID)
+	require.Equal(t, "other-key", upstream.lastReq.Header.Get("X-Api-Key"))
+}
+
+func TestOpsService_ReplayRequestDump_RejectsIncompleteDump(t *testing.T) {
+	svc, upstream, _ := newOpsRequestReplayTestService(t)
+	end := time.Now()
+	input := OpsRequestReplayInput{Key: "req-1", StartTime: end.Add(-time.Hour), EndTime: end}
+
+	first := 0
+	input.UpstreamIndex = &first
+	_, err := svc.ReplayRequestDump(context.Background(), input, 5)
+	require.ErrorIs(t, err, ErrOpsRequestReplayIncomplete, "entries captured without the upstream request cannot be replayed")
+
+	outOfRange := 2
+	input.UpstreamIndex = &outOfRange
+	_, err = svc.ReplayRequestDump(context.Background(), input, 5)
+	require.ErrorIs(t, err, ErrOpsRequestReplayInvalidUpstream)
+
+	input.UpstreamIndex = nil
+	input.Key = "req-missing"
+	_, err = svc.ReplayRequestDump(context.Background(), input, 5)
+	require.ErrorIs(t, err, ErrOpsRequestReplayDumpNotFound)
+	require.Nil(t, upstream.lastReq)
+}
+
+func ptrInt64ForOpsReplayTest(v int64) *int64 { return &v }
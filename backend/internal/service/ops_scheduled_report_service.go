@@ -1,15 +1,22 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/robfig/cron/v3"
@@ -24,6 +31,10 @@ const (
 	opsScheduledReportLastRunKeyPrefix = "ops:scheduled_reports:last_run:"
 
 	opsScheduledReportTickInterval = 1 * time.Minute
+
+	opsScheduledReportWebhookTimeout = 10 * time.Second
+	// OpsReportWebhookEvent 报表 webhook 回调中的事件类型
+	OpsReportWebhookEvent = "ops_report.generated"
 )
 
 var opsScheduledReportCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
@@ -42,6 +53,9 @@ type OpsScheduledReportService struct {
 	redisClient  *redis.Client
 	cfg          *config.Config
 
+	httpClient   *http.Client
+	validateHost func(host string) error
+
 	instanceID string
 	loc        *time.Location
 
@@ -77,6 +91,13 @@ func NewOpsScheduledReportService(
 		redisClient:  redisClient,
 		cfg:          cfg,
 
+		httpClient: &http.Client{
+			Timeout: opsScheduledReportWebhookTimeout,
+			// 不跟随重定向，避免被 302 引导到内网地址
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		validateHost: urlvalidator.ValidateResolvedIP,
+
 		instanceID:        uuid.NewString(),
 		loc:               loc,
 		distributedLockOn: lockOn,
@@ -214,6 +235,7 @@ type opsScheduledReport struct {
 
 	ErrorDigestMinCount             int
 	AccountHealthErrorRateThreshold float64
+	DigestOptions                   OpsWeeklyDigestOptions
 
 	LastRunAt *time.Time
 	NextRunAt time.Time
@@ -250,6 +272,7 @@ func (s *OpsScheduledReportService) listScheduledReports(ctx context.Context, no
 		{enabled: emailCfg.Report.WeeklySummaryEnabled, name: "周报", kind: "weekly_summary", timeRange: 7 * 24 * time.Hour, schedule: emailCfg.Report.WeeklySummarySchedule},
 		{enabled: emailCfg.Report.ErrorDigestEnabled, name: "错误摘要", kind: "error_digest", timeRange: 24 * time.Hour, schedule: emailCfg.Report.ErrorDigestSchedule},
 		{enabled: emailCfg.Report.AccountHealthEnabled, name: "账号健康", kind: "account_health", timeRange: 24 * time.Hour, schedule: emailCfg.Report.AccountHealthSchedule},
+		{enabled: emailCfg.Report.WeeklyDigestEnabled, name: "周度用量与健康摘要", kind: OpsReportTypeWeeklyDigest, timeRange: opsWeeklyDigestWindow, schedule: emailCfg.Report.WeeklyDigestSchedule},
 	}
 
 	out := make([]*opsScheduledReport, 0, len(defs))
@@ -296,6 +319,10 @@ func (s *OpsScheduledReportService) listScheduledReports(ctx context.Context, no
 
 			ErrorDigestMinCount:             emailCfg.Report.ErrorDigestMinCount,
 			AccountHealthErrorRateThreshold: emailCfg.Report.AccountHealthErrorRateThreshold,
+			DigestOptions: OpsWeeklyDigestOptions{
+				QuotaThresholdPercent: emailCfg.Report.DigestQuotaThresholdPercent,
+				ExpiringWithinDays:    emailCfg.Report.DigestExpiringWithinDays,
+			},
 
 			LastRunAt: lastRunPtr,
 			NextRunAt: next,
@@ -316,7 +343,7 @@ func (s *OpsScheduledReportService) runReport(ctx context.Context, report *opsSc
 	// Mark as "run" up-front so a broken SMTP config doesn't spam retries every minute.
 	s.setLastRunAt(ctx, report.ReportType, now)
 
-	content, err := s.generateReportHTML(ctx, report, now)
+	content, data, err := s.generateReport(ctx, report, now)
 	if err != nil {
 		return 0, err
 	}
@@ -325,21 +352,38 @@ func (s *OpsScheduledReportService) runReport(ctx context.Context, report *opsSc
 		return 0, nil
 	}
 
-	recipients := report.Recipients
-	if len(recipients) == 0 && s.userService != nil {
+	subs, err := s.opsService.ListReportSubscriptions(ctx)
+	if err != nil {
+		log.Printf("[OpsScheduledReport] load subscriptions failed; using global recipients: %v", err)
+		subs = nil
+	}
+	recipients, webhooks := resolveOpsReportDelivery(report.ReportType, report.Recipients, subs, s.subscribedAdminEmails(ctx, subs))
+
+	attempts := 0
+	if len(webhooks) > 0 {
+		payload := opsReportWebhookPayload(report, now, data)
+		for _, hook := range webhooks {
+			attempts++
+			if err := s.postWebhook(ctx, hook, payload); err != nil {
+				// Ignore per-webhook failures; continue best-effort.
+				log.Printf("[OpsScheduledReport] webhook delivery failed report=%s: %v", report.ReportType, err)
+			}
+		}
+	}
+
+	if len(recipients) == 0 && len(subs) == 0 && s.userService != nil {
 		admin, err := s.userService.GetFirstAdmin(ctx)
 		if err == nil && admin != nil && strings.TrimSpace(admin.Email) != "" {
 			recipients = []string{strings.TrimSpace(admin.Email)}
 		}
 	}
 	if len(recipients) == 0 {
-		return 0, nil
+		return attempts, nil
 	}
 
 	subject := fmt.Sprintf("[Ops Report] %s", strings.TrimSpace(report.Name))
 	templateVariables := opsScheduledReportEmailVariables(report, now)
 
-	attempts := 0
 	for _, to := range recipients {
 		addr := strings.TrimSpace(to)
 		if addr == "" {
@@ -412,12 +456,13 @@ func opsScheduledReportEmailVariables(report *opsScheduledReport, now time.Time)
 	}
 }
 
-func (s *OpsScheduledReportService) generateReportHTML(ctx context.Context, report *opsScheduledReport, now time.Time) (string, error) {
+// generateReport 生成报表邮件 HTML，同时返回结构化数据供 webhook 投递。
+func (s *OpsScheduledReportService) generateReport(ctx context.Context, report *opsScheduledReport, now time.Time) (string, any, error) {
 	if s == nil || s.opsService == nil || report == nil {
-		return "", fmt.Errorf("service not initialized")
+		return "", nil, fmt.Errorf("service not initialized")
 	}
 	if report.TimeRange <= 0 {
-		return "", fmt.Errorf("invalid time range")
+		return "", nil, fmt.Errorf("invalid time range")
 	}
 
 	end := now.UTC()
@@ -444,10 +489,10 @@ func (s *OpsScheduledReportService) generateReportHTML(ctx context.Context, repo
 				})
 			}
 			if err != nil {
-				return "", err
+				return "", nil, err
 			}
 		}
-		return buildOpsSummaryEmailHTML(report.Name, start, end, overview), nil
+		return buildOpsSummaryEmailHTML(report.Name, start, end, overview), overview, nil
 	case "error_digest":
 		// Lightweight digest: list recent errors (status>=400) and breakdown by type.
 		startTime := start
@@ -460,23 +505,154 @@ func (s *OpsScheduledReportService) generateReportHTML(ctx context.Context, repo
 		}
 		out, err := s.opsService.GetErrorLogs(ctx, filter)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		if report.ErrorDigestMinCount > 0 && out != nil && out.Total < report.ErrorDigestMinCount {
-			return "", nil
+			return "", nil, nil
 		}
-		return buildOpsErrorDigestEmailHTML(report.Name, start, end, out), nil
+		return buildOpsErrorDigestEmailHTML(report.Name, start, end, out), out, nil
 	case "account_health":
 		// Best-effort: use account availability (not error rate yet).
 		avail, err := s.opsService.GetAccountAvailability(ctx, "", nil)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		_ = report.AccountHealthErrorRateThreshold // reserved for future per-account error rate report
-		return buildOpsAccountHealthEmailHTML(report.Name, start, end, avail), nil
+		return buildOpsAccountHealthEmailHTML(report.Name, start, end, avail), avail, nil
+	case OpsReportTypeWeeklyDigest:
+		digest, err := s.opsService.BuildWeeklyDigest(ctx, end, report.DigestOptions)
+		if err != nil {
+			return "", nil, err
+		}
+		return buildOpsWeeklyDigestEmailHTML(report.Name, digest), digest, nil
 	default:
-		return "", fmt.Errorf("unknown report type: %s", report.ReportType)
+		return "", nil, fmt.Errorf("unknown report type: %s", report.ReportType)
+	}
+}
+
+// OpsReportWebhookPayload 报表生成后 POST 到订阅 webhook 的 JSON 结构。
+type OpsReportWebhookPayload struct {
+	Event      string    `json:"event"`
+	ReportType string    `json:"report_type"`
+	ReportName string    `json:"report_name"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Data       any       `json:"data,omitempty"`
+}
+
+func opsReportWebhookPayload(report *opsScheduledReport, now time.Time, data any) *OpsReportWebhookPayload {
+	end := now.UTC()
+	return &OpsReportWebhookPayload{
+		Event:      OpsReportWebhookEvent,
+		ReportType: report.ReportType,
+		ReportName: report.Name,
+		StartTime:  end.Add(-report.TimeRange),
+		EndTime:    end,
+		Data:       data,
+	}
+}
+
+// resolveOpsReportDelivery 合并全局收件人与管理员订阅偏好。
+// 订阅了该报表且开启邮件的管理员加入收件人；有订阅记录但未订阅该报表（或关闭邮件）的管理员
+// 即使在全局收件人中也会被移除。adminEmails 只包含仍为启用状态的管理员。
+func resolveOpsReportDelivery(reportType string, globalRecipients []string, subs []*OpsReportSubscription, adminEmails map[int64]string) ([]string, []string) {
+	optedOut := make(map[string]struct{})
+	var subscribed []string
+	var webhooks []string
+	for _, sub := range subs {
+		if sub == nil {
+			continue
+		}
+		email, ok := adminEmails[sub.UserID]
+		if !ok {
+			continue
+		}
+		wants := sub.Subscribes(reportType)
+		if email != "" {
+			if wants && sub.Email {
+				subscribed = append(subscribed, email)
+			} else {
+				optedOut[strings.ToLower(email)] = struct{}{}
+			}
+		}
+		if wants && sub.WebhookURL != "" {
+			webhooks = append(webhooks, sub.WebhookURL)
+		}
+	}
+
+	recipients := make([]string, 0, len(globalRecipients)+len(subscribed))
+	for _, addr := range normalizeEmails(append(append([]string{}, globalRecipients...), subscribed...)) {
+		if _, out := optedOut[addr]; out && !containsFold(subscribed, addr) {
+			continue
+		}
+		recipients = append(recipients, addr)
+	}
+	return recipients, webhooks
+}
+
+func containsFold(list []string, target string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), target) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribedAdminEmails 查询订阅记录对应的启用管理员邮箱；非管理员或已禁用的用户不出现在结果中。
+func (s *OpsScheduledReportService) subscribedAdminEmails(ctx context.Context, subs []*OpsReportSubscription) map[int64]string {
+	out := make(map[int64]string, len(subs))
+	if s == nil || s.userService == nil {
+		return out
+	}
+	for _, sub := range subs {
+		if sub == nil {
+			continue
+		}
+		user, err := s.userService.GetByID(ctx, sub.UserID)
+		if err != nil || user == nil || !user.IsAdmin() || !user.IsActive() {
+			continue
+		}
+		out[sub.UserID] = strings.TrimSpace(user.Email)
+	}
+	return out
+}
+
+func (s *OpsScheduledReportService) postWebhook(ctx context.Context, rawURL string, payload *OpsReportWebhookPayload) error {
+	if s.httpClient == nil {
+		return errors.New("http client not initialized")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if s.validateHost != nil {
+		if err := s.validateHost(parsed.Hostname()); err != nil {
+			return err
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, opsScheduledReportWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sub2api-ops-report")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
 	}
+	return nil
 }
 
 func buildOpsSummaryEmailHTML(title string, start, end time.Time, overview *OpsDashboardOverview) string {
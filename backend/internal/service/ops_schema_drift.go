@@ -0,0 +1,622 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// opsSchemaDriftMaxBodyBytes 非流式响应体超过该大小时放弃本次样本，避免缓存大响应。
+	opsSchemaDriftMaxBodyBytes = 1 << 20
+	// opsSchemaDriftMaxLineBytes SSE 单行超过该大小时跳过该行（只需要事件类型与顶层字段名）。
+	opsSchemaDriftMaxLineBytes = 256 << 10
+	// opsSchemaDriftMaxFindings 未知字段名来自上游，限制保存的发现数量防止无界增长。
+	opsSchemaDriftMaxFindings = 500
+	opsSchemaDriftMaxNameLen  = 128
+
+	OpsSchemaDriftKindNewField     = "new_field"
+	OpsSchemaDriftKindNewEvent     = "new_event"
+	OpsSchemaDriftKindMissingField = "missing_field"
+	OpsSchemaDriftKindMissingEvent = "missing_event"
+)
+
+// opsSchemaSpec 一种上游响应格式的预期结构。
+// 非流式响应检查响应体顶层字段；流式响应若定义了 Events 则检查事件类型，否则检查每个 data 块的顶层字段。
+type opsSchemaSpec struct {
+	Name   string
+	Stream bool
+	// Fields 每个样本都应出现的顶层字段；OptionalFields 为已知但可能缺省的字段
+	Fields         []string
+	OptionalFields []string
+	// Events 已知事件类型；RequiredEvents 每个完整的流都应出现的事件
+	Events         []string
+	RequiredEvents []string
+}
+
+func (s *opsSchemaSpec) checksEvents() bool {
+	return len(s.Events) > 0
+}
+
+var opsSchemaSpecs = []*opsSchemaSpec{
+	{
+		Name:           "anthropic_messages",
+		Fields:         []string{"id", "type", "role", "model", "content", "stop_reason", "usage"},
+		OptionalFields: []string{"stop_sequence", "container", "context_management"},
+	},
+	{
+		Name:   "anthropic_messages_stream",
+		Stream: true,
+		Events: []string{
+			"message_start", "content_block_start", "content_block_delta", "content_block_stop",
+			"message_delta", "message_stop", "ping", "error",
+		},
+		RequiredEvents: []string{"message_start", "message_stop"},
+	},
+	{
+		Name:   "openai_responses",
+		Fields: []string{"id", "object", "created_at", "status", "model", "output"},
+		OptionalFields: []string{
+			"error", "incomplete_details", "instructions", "max_output_tokens", "max_tool_calls", "metadata",
+			"parallel_tool_calls", "previous_response_id", "prompt", "prompt_cache_key", "reasoning",
+			"safety_identifier", "service_tier", "store", "temperature", "text", "tool_choice", "tools",
+			"top_logprobs", "top_p", "truncation", "usage", "user", "background", "conversation",
+			"output_text", "prompt_cache_retention", "billing",
+		},
+	},
+	{
+		Name:   "openai_responses_stream",
+		Stream: true,
+		Events: []string{
+			"response.created", "response.in_progress", "response.queued", "response.completed",
+			"response.failed", "response.incomplete", "response.cancelled",
+			"response.output_item.added", "response.output_item.done",
+			"response.content_part.added", "response.content_part.done",
+			"response.output_text.delta", "response.output_text.done", "response.output_text.annotation.added",
+			"response.refusal.delta", "response.refusal.done",
+			"response.function_call_arguments.delta", "response.function_call_arguments.done",
+			"response.custom_tool_call_input.delta", "response.custom_tool_call_input.done",
+			"response.reasoning_summary_part.added", "response.reasoning_summary_part.done",
+			"response.reasoning_summary_text.delta", "response.reasoning_summary_text.done",
+			"response.reasoning_text.delta", "response.reasoning_text.done",
+			"response.web_search_call.in_progress", "response.web_search_call.searching",
+			"response.web_search_call.completed",
+			"response.image_generation_call.in_progress", "response.image_generation_call.generating",
+			"response.image_generation_call.partial_image", "response.image_generation_call.completed",
+			"error",
+		},
+		RequiredEvents: []string{"response.created"},
+	},
+	{
+		Name:           "openai_chat_completions",
+		Fields:         []string{"id", "object", "created", "model", "choices"},
+		OptionalFields: []string{"usage", "system_fingerprint", "service_tier"},
+	},
+	{
+		Name:           "openai_chat_completions_stream",
+		Stream:         true,
+		Fields:         []string{"id", "object", "created", "model", "choices"},
+		OptionalFields: []string{"usage", "system_fingerprint", "service_tier", "obfuscation"},
+	},
+	{
+		Name:           "gemini_generate_content",
+		Fields:         []string{"candidates"},
+		OptionalFields: []string{"usageMetadata", "modelVersion", "responseId", "promptFeedback", "createTime"},
+	},
+	{
+		Name:           "gemini_generate_content_stream",
+		Stream:         true,
+		Fields:         []string{"candidates"},
+		OptionalFields: []string{"usageMetadata", "modelVersion", "responseId", "promptFeedback", "createTime"},
+	},
+}
+
+// opsSchemaSpecFor 按上游请求路径与响应是否为 SSE 选择预期结构；未覆盖的接口返回 nil。
+func opsSchemaSpecFor(path string, stream bool) *opsSchemaSpec {
+	var name string
+	switch {
+	case strings.HasSuffix(path, "/v1/messages"):
+		name = "anthropic_messages"
+	case strings.HasSuffix(path, "/responses"):
+		name = "openai_responses"
+	case strings.HasSuffix(path, "/chat/completions"):
+		name = "openai_chat_completions"
+	case strings.Contains(path, "/models/") && strings.HasSuffix(path, ":generateContent"):
+		if stream {
+			return nil
+		}
+		name = "gemini_generate_content"
+	case strings.Contains(path, "/models/") && strings.HasSuffix(path, ":streamGenerateContent"):
+		// 非 alt=sse 的流式接口返回 JSON 数组，不检查
+		if !stream {
+			return nil
+		}
+		name = "gemini_generate_content"
+	default:
+		return nil
+	}
+	if stream {
+		name += "_stream"
+	}
+	for _, spec := range opsSchemaSpecs {
+		if spec.Name == name {
+			return spec
+		}
+	}
+	return nil
+}
+
+// OpsSchemaDriftFinding 一项格式漂移发现。
+type OpsSchemaDriftFinding struct {
+	Schema string `json:"schema"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	// Occurrences 新字段/事件为累计出现的样本数，消失类为累计判定为缺失的检查窗口样本数
+	Occurrences  int64      `json:"occurrences"`
+	FirstSeenAt  time.Time  `json:"first_seen_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`
+	LastWarnedAt *time.Time `json:"last_warned_at,omitempty"`
+}
+
+// OpsSchemaDriftSchemaStatus 单个预期结构的样本统计。
+type OpsSchemaDriftSchemaStatus struct {
+	Name           string `json:"name"`
+	PendingSamples int    `json:"pending_samples"`
+	TotalSamples   int64  `json:"total_samples"`
+}
+
+// OpsSchemaDriftStatus 本实例格式漂移检测的配置、样本统计与发现列表。
+type OpsSchemaDriftStatus struct {
+	Hostname             string                        `json:"hostname"`
+	SampleRate           float64                       `json:"sample_rate"`
+	CheckIntervalSeconds int                           `json:"check_interval_seconds"`
+	MinSamples           int                           `json:"min_samples"`
+	LastCheckAt          *time.Time                    `json:"last_check_at,omitempty"`
+	Schemas              []*OpsSchemaDriftSchemaStatus `json:"schemas"`
+	Findings             []*OpsSchemaDriftFinding      `json:"findings"`
+}
+
+// SetSchemaDriftDetector 由 wire 注入格式漂移检测器，供管理接口查看发现。
+func (s *OpsService) SetSchemaDriftDetector(d *OpsSchemaDriftDetector) {
+	if s == nil {
+		return
+	}
+	s.schemaDrift = d
+}
+
+// GetSchemaDriftStatus 返回本实例的格式漂移检测状态（样本与发现只保存在各实例内存中）。
+func (s *OpsService) GetSchemaDriftStatus(ctx context.Context) (*OpsSchemaDriftStatus, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.schemaDrift == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_SCHEMA_DRIFT_DISABLED", "Ops schema drift detector is not enabled")
+	}
+	return s.schemaDrift.Status(), nil
+}
+
+type opsSchemaDriftWindow struct {
+	samples int
+	fields  map[string]int
+	events  map[string]int
+}
+
+// OpsSchemaDriftDetector 按采样率旁路检查成功的上游响应（挂在 HTTPUpstream 上，解压后的响应体），
+// 周期性地与预期结构比对：出现未知字段 / 事件类型，或预期字段 / 事件在整个窗口内消失时输出 warn 日志
+// （写入 ops 系统日志），在客户端出错前提示上游格式变化。每个实例独立统计。
+type OpsSchemaDriftDetector struct {
+	cfg      config.OpsSchemaDriftConfig
+	hostname string
+
+	mu          sync.Mutex
+	windows     map[string]*opsSchemaDriftWindow
+	totals      map[string]int64
+	findings    map[string]*OpsSchemaDriftFinding
+	lastCheckAt *time.Time
+
+	// sample / now 为单测钩子
+	sample func() bool
+	now    func() time.Time
+
+	stopCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewOpsSchemaDriftDetector(cfg config.OpsSchemaDriftConfig) *OpsSchemaDriftDetector {
+	hostname, _ := os.Hostname()
+	d := &OpsSchemaDriftDetector{
+		cfg:      cfg,
+		hostname: hostname,
+		windows:  make(map[string]*opsSchemaDriftWindow),
+		totals:   make(map[string]int64),
+		findings: make(map[string]*OpsSchemaDriftFinding),
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+	d.sample = func() bool {
+		return cfg.SampleRate >= 1 || (cfg.SampleRate > 0 && rand.Float64() < cfg.SampleRate)
+	}
+	return d
+}
+
+func (d *OpsSchemaDriftDetector) Start() {
+	if d == nil {
+		return
+	}
+	d.startOnce.Do(func() {
+		d.wg.Add(1)
+		go d.run()
+	})
+}
+
+func (d *OpsSchemaDriftDetector) Stop() {
+	if d == nil {
+		return
+	}
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+	d.wg.Wait()
+}
+
+func (d *OpsSchemaDriftDetector) run() {
+	defer d.wg.Done()
+	interval := time.Duration(d.cfg.CheckIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.checkOnce()
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// InspectUpstreamResponse 对采样命中的 2xx 响应包装 resp.Body，读到 EOF 时提交样本；
+// 未读完即关闭的响应（客户端断开等）不计入，避免把截断的流误判为事件缺失。
+func (d *OpsSchemaDriftDetector) InspectUpstreamResponse(req *http.Request, resp *http.Response) {
+	if d == nil || req == nil || req.URL == nil || resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return
+	}
+	stream := strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/event-stream")
+	spec := opsSchemaSpecFor(req.URL.Path, stream)
+	if spec == nil || !d.sample() {
+		return
+	}
+	resp.Body = &opsSchemaDriftBody{ReadCloser: resp.Body, detector: d, spec: spec, sample: newOpsSchemaDriftSample()}
+}
+
+type opsSchemaDriftSample struct {
+	fields map[string]struct{}
+	events map[string]struct{}
+}
+
+func newOpsSchemaDriftSample() *opsSchemaDriftSample {
+	return &opsSchemaDriftSample{fields: make(map[string]struct{}), events: make(map[string]struct{})}
+}
+
+// observe 合并一个样本到当前检查窗口。
+func (d *OpsSchemaDriftDetector) observe(spec *opsSchemaSpec, sample *opsSchemaDriftSample) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w := d.windows[spec.Name]
+	if w == nil {
+		w = &opsSchemaDriftWindow{fields: make(map[string]int), events: make(map[string]int)}
+		d.windows[spec.Name] = w
+	}
+	w.samples++
+	for name := range sample.fields {
+		w.fields[name]++
+	}
+	for name := range sample.events {
+		w.events[name]++
+	}
+	d.totals[spec.Name]++
+}
+
+type opsSchemaDriftHit struct {
+	kind  string
+	name  string
+	count int
+}
+
+// opsSchemaDriftCompare 比较一个窗口与预期结构；消失类只在样本数达到 minSamples 时判定。
+func opsSchemaDriftCompare(spec *opsSchemaSpec, w *opsSchemaDriftWindow, minSamples int) []opsSchemaDriftHit {
+	var hits []opsSchemaDriftHit
+	if spec.checksEvents() {
+		known := opsSchemaNameSet(spec.Events)
+		for name, count := range w.events {
+			if _, ok := known[name]; !ok {
+				hits = append(hits, opsSchemaDriftHit{kind: OpsSchemaDriftKindNewEvent, name: name, count: count})
+			}
+		}
+		if w.samples >= minSamples {
+			for _, name := range spec.RequiredEvents {
+				if w.events[name] == 0 {
+					hits = append(hits, opsSchemaDriftHit{kind: OpsSchemaDriftKindMissingEvent, name: name, count: w.samples})
+				}
+			}
+		}
+	} else {
+		known := opsSchemaNameSet(spec.Fields, spec.OptionalFields)
+		for name, count := range w.fields {
+			if _, ok := known[name]; !ok {
+				hits = append(hits, opsSchemaDriftHit{kind: OpsSchemaDriftKindNewField, name: name, count: count})
+			}
+		}
+		if w.samples >= minSamples {
+			for _, name := range spec.Fields {
+				if w.fields[name] == 0 {
+					hits = append(hits, opsSchemaDriftHit{kind: OpsSchemaDriftKindMissingField, name: name, count: w.samples})
+				}
+			}
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].kind != hits[j].kind {
+			return hits[i].kind < hits[j].kind
+		}
+		return hits[i].name < hits[j].name
+	})
+	return hits
+}
+
+func opsSchemaNameSet(lists ...[]string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, list := range lists {
+		for _, name := range list {
+			set[name] = struct{}{}
+		}
+	}
+	return set
+}
+
+// checkOnce 比对并清空当前窗口，更新发现列表；新发现或距上次告警超过冷却期的发现输出 warn 日志。
+func (d *OpsSchemaDriftDetector) checkOnce() {
+	now := d.now()
+	cooldown := time.Duration(d.cfg.WarnCooldownMinutes) * time.Minute
+
+	var toWarn []OpsSchemaDriftFinding
+	d.mu.Lock()
+	for _, spec := range opsSchemaSpecs {
+		w := d.windows[spec.Name]
+		if w == nil || w.samples == 0 {
+			continue
+		}
+		for _, hit := range opsSchemaDriftCompare(spec, w, d.cfg.MinSamples) {
+			key := spec.Name + "|" + hit.kind + "|" + hit.name
+			finding := d.findings[key]
+			if finding == nil {
+				if len(d.findings) >= opsSchemaDriftMaxFindings {
+					continue
+				}
+				finding = &OpsSchemaDriftFinding{Schema: spec.Name, Kind: hit.kind, Name: hit.name, FirstSeenAt: now}
+				d.findings[key] = finding
+			}
+			finding.Occurrences += int64(hit.count)
+			finding.LastSeenAt = now
+			if finding.LastWarnedAt == nil || now.Sub(*finding.LastWarnedAt) >= cooldown {
+				warnedAt := now
+				finding.LastWarnedAt = &warnedAt
+				toWarn = append(toWarn, *finding)
+			}
+		}
+	}
+	d.windows = make(map[string]*opsSchemaDriftWindow)
+	d.lastCheckAt = &now
+	d.mu.Unlock()
+
+	if len(toWarn) == 0 {
+		return
+	}
+	log := logger.L().With(zap.String("component", "ops.schema_drift"))
+	for _, f := range toWarn {
+		log.Warn("ops.upstream_schema_drift",
+			zap.String("schema", f.Schema),
+			zap.String("kind", f.Kind),
+			zap.String("name", f.Name),
+			zap.Int64("occurrences", f.Occurrences),
+			zap.Time("first_seen_at", f.FirstSeenAt),
+		)
+	}
+}
+
+// Status 返回样本统计与发现列表（最近出现的在前）。
+func (d *OpsSchemaDriftDetector) Status() *OpsSchemaDriftStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := &OpsSchemaDriftStatus{
+		Hostname:             d.hostname,
+		SampleRate:           d.cfg.SampleRate,
+		CheckIntervalSeconds: d.cfg.CheckIntervalSeconds,
+		MinSamples:           d.cfg.MinSamples,
+		LastCheckAt:          d.lastCheckAt,
+		Schemas:              make([]*OpsSchemaDriftSchemaStatus, 0, len(opsSchemaSpecs)),
+		Findings:             make([]*OpsSchemaDriftFinding, 0, len(d.findings)),
+	}
+	for _, spec := range opsSchemaSpecs {
+		item := &OpsSchemaDriftSchemaStatus{Name: spec.Name, TotalSamples: d.totals[spec.Name]}
+		if w := d.windows[spec.Name]; w != nil {
+			item.PendingSamples = w.samples
+		}
+		status.Schemas = append(status.Schemas, item)
+	}
+	for _, f := range d.findings {
+		cp := *f
+		status.Findings = append(status.Findings, &cp)
+	}
+	sort.Slice(status.Findings, func(i, j int) bool {
+		a, b := status.Findings[i], status.Findings[j]
+		if !a.LastSeenAt.Equal(b.LastSeenAt) {
+			return a.LastSeenAt.After(b.LastSeenAt)
+		}
+		return a.Schema+a.Kind+a.Name < b.Schema+b.Kind+b.Name
+	})
+	return status
+}
+
+// opsSchemaDriftBody 旁路解析响应体：非流式缓存整个响应体，流式逐行解析 SSE，不改变读出的内容。
+// 仅由读取方单 goroutine 调用。
+type opsSchemaDriftBody struct {
+	io.ReadCloser
+	detector *OpsSchemaDriftDetector
+	spec     *opsSchemaSpec
+	sample   *opsSchemaDriftSample
+
+	buf          bytes.Buffer
+	overflow     bool // 非流式：响应体过大；流式：当前行过长
+	currentEvent string
+	done         bool
+}
+
+func (b *opsSchemaDriftBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.done {
+		return n, err
+	}
+	if n > 0 {
+		if b.spec.Stream {
+			b.feedStream(p[:n])
+		} else if !b.overflow {
+			if b.buf.Len()+n > opsSchemaDriftMaxBodyBytes {
+				b.overflow = true
+				b.buf = bytes.Buffer{}
+			} else {
+				_, _ = b.buf.Write(p[:n])
+			}
+		}
+	}
+	if err == io.EOF {
+		b.finish()
+	} else if err != nil {
+		b.done = true
+	}
+	return n, err
+}
+
+func (b *opsSchemaDriftBody) finish() {
+	b.done = true
+	if b.spec.Stream {
+		if b.buf.Len() > 0 && !b.overflow {
+			b.handleLine(b.buf.Bytes())
+		}
+	} else {
+		if b.overflow || !opsSchemaCollectFields(b.buf.Bytes(), b.sample.fields) {
+			return
+		}
+	}
+	b.buf = bytes.Buffer{}
+	if len(b.sample.fields) == 0 && len(b.sample.events) == 0 {
+		return
+	}
+	b.detector.observe(b.spec, b.sample)
+}
+
+func (b *opsSchemaDriftBody) feedStream(chunk []byte) {
+	for len(chunk) > 0 {
+		idx := bytes.IndexByte(chunk, '\n')
+		if idx < 0 {
+			b.appendLine(chunk)
+			return
+		}
+		b.appendLine(chunk[:idx])
+		if !b.overflow {
+			b.handleLine(b.buf.Bytes())
+		}
+		b.buf.Reset()
+		b.overflow = false
+		chunk = chunk[idx+1:]
+	}
+}
+
+func (b *opsSchemaDriftBody) appendLine(part []byte) {
+	if b.overflow {
+		return
+	}
+	if b.buf.Len()+len(part) > opsSchemaDriftMaxLineBytes {
+		b.overflow = true
+		return
+	}
+	_, _ = b.buf.Write(part)
+}
+
+func (b *opsSchemaDriftBody) handleLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		b.currentEvent = ""
+		return
+	}
+	if v, ok := bytes.CutPrefix(line, []byte("event:")); ok {
+		b.currentEvent = strings.TrimSpace(string(v))
+		return
+	}
+	data, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return
+	}
+	if !b.spec.checksEvents() {
+		opsSchemaCollectFields(data, b.sample.fields)
+		return
+	}
+	event := b.currentEvent
+	if event == "" {
+		var typed struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(data, &typed) == nil {
+			event = typed.Type
+		}
+	}
+	if event = opsSchemaDriftName(event); event != "" {
+		b.sample.events[event] = struct{}{}
+	}
+}
+
+// opsSchemaCollectFields 把 JSON 对象的顶层字段名加入 dst；不是 JSON 对象时返回 false。
+func opsSchemaCollectFields(data []byte, dst map[string]struct{}) bool {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+		return false
+	}
+	for name := range obj {
+		if name = opsSchemaDriftName(name); name != "" {
+			dst[name] = struct{}{}
+		}
+	}
+	return true
+}
+
+func opsSchemaDriftName(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) > opsSchemaDriftMaxNameLen {
+		name = name[:opsSchemaDriftMaxNameLen]
+	}
+	return name
+}
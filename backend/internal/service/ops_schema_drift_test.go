@@ -0,0 +1,168 @@
+//go:build unit
+
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOpsSchemaDrift(t *testing.T, minSamples int) (*OpsSchemaDriftDetector, *time.Time) {
+	t.Helper()
+	d := NewOpsSchemaDriftDetector(config.OpsSchemaDriftConfig{SampleRate: 1, MinSamples: minSamples, WarnCooldownMinutes: 60})
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+// inspectAndDrain 模拟 HTTPUpstream 把响应交给检查器后由业务层读完响应体，返回业务层读到的内容。
+func inspectAndDrain(t *testing.T, d *OpsSchemaDriftDetector, path, contentType, body string) string {
+	t.Helper()
+	req := &http.Request{Method: http.MethodPost, URL: &url.URL{Scheme: "https", Host: "upstream.example", Path: path}}
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		// OneByteReader 让 SSE 行跨多次 Read，覆盖分片拼接
+		Body: io.NopCloser(iotest.OneByteReader(strings.NewReader(body))),
+	}
+	d.InspectUpstreamResponse(req, resp)
+	out, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return string(out)
+}
+
+func findingKeys(status *OpsSchemaDriftStatus) []string {
+	keys := make([]string, 0, len(status.Findings))
+	for _, f := range status.Findings {
+		keys = append(keys, f.Schema+"|"+f.Kind+"|"+f.Name)
+	}
+	return keys
+}
+
+func TestOpsSchemaSpecFor(t *testing.T) {
+	cases := []struct {
+		path   string
+		stream bool
+		want   string
+	}{
+		{"/v1/messages", false, "anthropic_messages"},
+		{"/v1/messages", true, "anthropic_messages_stream"},
+		{"/v1/messages/count_tokens", false, ""},
+		{"/backend-api/codex/responses", true, "openai_responses_stream"},
+		{"/v1/chat/completions", false, "openai_chat_completions"},
+		{"/v1beta/models/gemini-2.5-pro:generateContent", false, "gemini_generate_content"},
+		{"/v1beta/models/gemini-2.5-pro:streamGenerateContent", true, "gemini_generate_content_stream"},
+		{"/v1beta/models/gemini-2.5-pro:streamGenerateContent", false, ""},
+		{"/v1internal:generateContent", false, ""},
+	}
+	for _, tc := range cases {
+		spec := opsSchemaSpecFor(tc.path, tc.stream)
+		if tc.want == "" {
+			require.Nil(t, spec, tc.path)
+			continue
+		}
+		require.NotNil(t, spec, tc.path)
+		require.Equal(t, tc.want, spec.Name, tc.path)
+	}
+}
+
+func TestOpsSchemaDrift_StreamEventsAndPassthrough(t *testing.T) {
+	d, _ := newTestOpsSchemaDrift(t, 1)
+	body := "event: message_start\ndata: {\"type\":\"message_start\"}\n\n" +
+		"event: content_block_citation\r\ndata: {\"type\":\"content_block_citation\"}\r\n\r\n" +
+		"data: {\"type\":\"message_delta\"}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}"
+
+	require.Equal(t, body, inspectAndDrain(t, d, "/v1/messages", "text/event-stream; charset=utf-8", body), "body must pass through unchanged")
+	d.checkOnce()
+
+	status := d.Status()
+	require.Equal(t, []string{"anthropic_messages_stream|new_event|content_block_citation"}, findingKeys(status))
+	require.Equal(t, int64(1), status.Findings[0].Occurrences)
+	require.NotNil(t, status.Findings[0].LastWarnedAt)
+}
+
+func TestOpsSchemaDrift_JSONFieldsAndMissing(t *testing.T) {
+	d, now := newTestOpsSchemaDrift(t, 2)
+	body := `{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[],"usage":{},"stop_details":{}}`
+
+	inspectAndDrain(t, d, "/v1/messages", "application/json", body)
+	d.checkOnce()
+	require.Equal(t, []string{"anthropic_messages|new_field|stop_details"}, findingKeys(d.Status()),
+		"missing fields are not judged below min_samples")
+
+	*now = now.Add(10 * time.Minute)
+	inspectAndDrain(t, d, "/v1/messages", "application/json", body)
+	inspectAndDrain(t, d, "/v1/messages", "application/json", body)
+	d.checkOnce()
+
+	status := d.Status()
+	require.ElementsMatch(t, []string{
+		"anthropic_messages|new_field|stop_details",
+		"anthropic_messages|missing_field|stop_reason",
+	}, findingKeys(status))
+	for _, f := range status.Findings {
+		if f.Name == "stop_details" {
+			require.Equal(t, int64(3), f.Occurrences)
+			require.Equal(t, time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC), *f.LastWarnedAt, "cooldown suppresses a repeated warning")
+		}
+	}
+	require.Equal(t, int64(3), status.Schemas[0].TotalSamples)
+	require.Zero(t, status.Schemas[0].PendingSamples, "check resets the window")
+}
+
+func TestOpsSchemaDrift_SkipsIncompleteAndUnsampledResponses(t *testing.T) {
+	d, _ := newTestOpsSchemaDrift(t, 1)
+
+	// 未读完即关闭：不计入样本
+	req := &http.Request{URL: &url.URL{Path: "/v1/messages"}}
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader("event: message_start\ndata: {}\n\nevent: message_stop\ndata: {}\n\n")),
+	}
+	d.InspectUpstreamResponse(req, resp)
+	buf := make([]byte, 8)
+	_, _ = resp.Body.Read(buf)
+	require.NoError(t, resp.Body.Close())
+
+	// 非 2xx 不检查
+	errResp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"x":1}`))}
+	original := errResp.Body
+	d.InspectUpstreamResponse(req, errResp)
+	require.Equal(t, original, errResp.Body)
+
+	// 未命中采样不包装
+	d.sample = func() bool { return false }
+	okResp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"x":1}`))}
+	original = okResp.Body
+	d.InspectUpstreamResponse(req, okResp)
+	require.Equal(t, original, okResp.Body)
+
+	d.checkOnce()
+	status := d.Status()
+	require.Empty(t, status.Findings)
+	require.Zero(t, status.Schemas[0].TotalSamples)
+	require.Zero(t, status.Schemas[1].TotalSamples)
+}
+
+func TestOpsSchemaDrift_ChunkStreamChecksDataFields(t *testing.T) {
+	d, _ := newTestOpsSchemaDrift(t, 1)
+	body := "data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt\",\"choices\":[]}\n\n" +
+		"data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt\",\"choices\":[],\"citations\":[]}\n\n" +
+		"data: [DONE]\n\n"
+
+	inspectAndDrain(t, d, "/v1/chat/completions", "text/event-stream", body)
+	d.checkOnce()
+
+	require.Equal(t, []string{"openai_chat_completions_stream|new_field|citations"}, findingKeys(d.Status()))
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
@@ -46,6 +48,27 @@ type OpsService struct {
 	// UpdateOpsAdvancedSettings 写入新配置后调用，把最新的 quota auto-pause 全局默认阈值
 	// 立即同步到调度热路径读取的内存缓存，避免下次请求才能感知新值。
 	quotaAutoPauseSink func(OpsOpenAIAccountQuotaAutoPauseSettings)
+
+	// proxyRepo / proxyLatencyCache 由 wire 通过 SetProxyProbeSource 注入，供事故快照读取代理探测结果。
+	proxyRepo         ProxyRepository
+	proxyLatencyCache ProxyLatencyCache
+
+	// usageLogRepo 由 wire 通过 SetUsageStatsSource 注入，供周度摘要统计模型用量与花费。
+	usageLogRepo UsageLogRepository
+
+	// apiKeyTraceService 由 wire 通过 SetAPIKeyTraceSource 注入，供调试包附带抓包记录。
+	apiKeyTraceService *APIKeyTraceService
+
+	// inFlightRequests 由网关中间件通过 TrackInFlightRequest 维护，供资源看门狗读取。
+	inFlightRequests atomic.Int64
+	// watchdog 由 wire 通过 SetWatchdog 注入；未启用时为 nil。
+	watchdog *OpsWatchdogService
+	// schemaDrift 由 wire 通过 SetSchemaDriftDetector 注入；未启用时为 nil。
+	schemaDrift *OpsSchemaDriftDetector
+
+	// alertWebhookClient / alertWebhookValidateHost 用于告警 webhook 推送；为 nil 时使用默认实现，测试中可替换。
+	alertWebhookClient       *http.Client
+	alertWebhookValidateHost func(host string) error
 }
 
 // CleanupReloader 由 OpsCleanupService 实现。
@@ -232,6 +255,8 @@ func (s *OpsService) prepareErrorLogInput(ctx context.Context, entry *OpsInsertE
 	if entry.UpstreamStatusCode != nil && *entry.UpstreamStatusCode <= 0 {
 		entry.UpstreamStatusCode = nil
 	}
+	// 归一化错误代码需基于原始上游错误体推导，必须在脱敏/截断之前完成。
+	entry.ErrorCode = deriveOpsErrorCode(entry)
 	if entry.UpstreamErrorMessage != nil {
 		msg := strings.TrimSpace(*entry.UpstreamErrorMessage)
 		msg = sanitizeUpstreamErrorMessage(msg)
@@ -263,6 +288,63 @@ func (s *OpsService) prepareErrorLogInput(ctx context.Context, entry *OpsInsertE
 	return entry, true, nil
 }
 
+// deriveOpsErrorCode 推导错误日志的归一化上游错误代码。
+// 优先使用网关记录的上游状态码/消息/错误体；没有时回退到最后一次上游尝试事件；
+// 仅仍处于 upstream/network 阶段的错误才基于对客错误体推导，其余（鉴权、路由、内部错误）保持为空。
+func deriveOpsErrorCode(entry *OpsInsertErrorLogInput) string {
+	if code := strings.TrimSpace(entry.ErrorCode); code != "" {
+		return truncateString(code, 64)
+	}
+
+	upstreamStatus := 0
+	if entry.UpstreamStatusCode != nil {
+		upstreamStatus = *entry.UpstreamStatusCode
+	}
+	upstreamMsg := ""
+	if entry.UpstreamErrorMessage != nil {
+		upstreamMsg = *entry.UpstreamErrorMessage
+	}
+	upstreamDetail := ""
+	if entry.UpstreamErrorDetail != nil {
+		upstreamDetail = *entry.UpstreamErrorDetail
+	}
+	if upstreamStatus > 0 || strings.TrimSpace(upstreamMsg) != "" || strings.TrimSpace(upstreamDetail) != "" {
+		return NormalizeUpstreamErrorCode(upstreamStatus, upstreamMsg, []byte(upstreamDetail))
+	}
+
+	for i := len(entry.UpstreamErrors) - 1; i >= 0; i-- {
+		if ev := entry.UpstreamErrors[i]; ev != nil {
+			return normalizeOpsUpstreamEventCode(ev, ev)
+		}
+	}
+
+	switch entry.ErrorPhase {
+	case "upstream":
+		if entry.StatusCode <= 0 && strings.TrimSpace(entry.ErrorMessage) == "" && strings.TrimSpace(entry.ErrorBody) == "" {
+			return UpstreamErrorCodeUnknown
+		}
+		return NormalizeUpstreamErrorCode(entry.StatusCode, entry.ErrorMessage, []byte(entry.ErrorBody))
+	case "network":
+		return UpstreamErrorCodeNetworkError
+	}
+	return ""
+}
+
+// normalizeOpsUpstreamEventCode 基于原始事件（未脱敏）推导归一化代码；已显式给出的代码直接沿用。
+func normalizeOpsUpstreamEventCode(raw *OpsUpstreamErrorEvent, out *OpsUpstreamErrorEvent) string {
+	if code := strings.TrimSpace(out.ErrorCode); code != "" {
+		return truncateString(code, 64)
+	}
+	if raw.Kind == "request_error" && raw.UpstreamStatusCode <= 0 {
+		return UpstreamErrorCodeNetworkError
+	}
+	body := raw.Detail
+	if strings.TrimSpace(body) == "" {
+		body = raw.UpstreamResponseBody
+	}
+	return NormalizeUpstreamErrorCode(raw.UpstreamStatusCode, raw.Message, []byte(body))
+}
+
 func sanitizeOpsUpstreamErrors(entry *OpsInsertErrorLogInput) error {
 	if entry == nil || len(entry.UpstreamErrors) == 0 {
 		return nil
@@ -312,6 +394,7 @@ func sanitizeOpsUpstreamErrors(entry *OpsInsertErrorLogInput) error {
 		if out.UpstreamStatusCode == 0 && out.Message == "" && out.Detail == "" {
 			continue
 		}
+		out.ErrorCode = normalizeOpsUpstreamEventCode(ev, &out)
 
 		evCopy := out
 		sanitized = append(sanitized, &evCopy)
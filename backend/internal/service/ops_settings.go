@@ -90,6 +90,10 @@ func (s *OpsService) UpdateEmailNotificationConfig(ctx context.Context, req *Ops
 		cfg.Report.AccountHealthEnabled = req.Report.AccountHealthEnabled
 		cfg.Report.AccountHealthSchedule = strings.TrimSpace(req.Report.AccountHealthSchedule)
 		cfg.Report.AccountHealthErrorRateThreshold = req.Report.AccountHealthErrorRateThreshold
+		cfg.Report.WeeklyDigestEnabled = req.Report.WeeklyDigestEnabled
+		cfg.Report.WeeklyDigestSchedule = strings.TrimSpace(req.Report.WeeklyDigestSchedule)
+		cfg.Report.DigestQuotaThresholdPercent = req.Report.DigestQuotaThresholdPercent
+		cfg.Report.DigestExpiringWithinDays = req.Report.DigestExpiringWithinDays
 	}
 
 	if err := validateOpsEmailNotificationConfig(cfg); err != nil {
@@ -130,6 +134,10 @@ func defaultOpsEmailNotificationConfig() *OpsEmailNotificationConfig {
 			AccountHealthEnabled:            false,
 			AccountHealthSchedule:           "0 9 * * *",
 			AccountHealthErrorRateThreshold: 10.0,
+			WeeklyDigestEnabled:             false,
+			WeeklyDigestSchedule:            "0 9 * * 1",
+			DigestQuotaThresholdPercent:     80,
+			DigestExpiringWithinDays:        7,
 		},
 	}
 }
@@ -150,6 +158,7 @@ func normalizeOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) {
 	cfg.Report.WeeklySummarySchedule = strings.TrimSpace(cfg.Report.WeeklySummarySchedule)
 	cfg.Report.ErrorDigestSchedule = strings.TrimSpace(cfg.Report.ErrorDigestSchedule)
 	cfg.Report.AccountHealthSchedule = strings.TrimSpace(cfg.Report.AccountHealthSchedule)
+	cfg.Report.WeeklyDigestSchedule = strings.TrimSpace(cfg.Report.WeeklyDigestSchedule)
 
 	// Fill missing schedules with defaults to avoid breaking cron logic if clients send empty strings.
 	if cfg.Report.DailySummarySchedule == "" {
@@ -164,6 +173,16 @@ func normalizeOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) {
 	if cfg.Report.AccountHealthSchedule == "" {
 		cfg.Report.AccountHealthSchedule = "0 9 * * *"
 	}
+	if cfg.Report.WeeklyDigestSchedule == "" {
+		cfg.Report.WeeklyDigestSchedule = "0 9 * * 1"
+	}
+	// 旧配置没有摘要阈值字段，读出为 0 时回填默认值。
+	if cfg.Report.DigestQuotaThresholdPercent <= 0 {
+		cfg.Report.DigestQuotaThresholdPercent = 80
+	}
+	if cfg.Report.DigestExpiringWithinDays <= 0 {
+		cfg.Report.DigestExpiringWithinDays = 7
+	}
 }
 
 func validateOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) error {
@@ -189,6 +208,12 @@ func validateOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) error {
 	if cfg.Report.AccountHealthErrorRateThreshold < 0 || cfg.Report.AccountHealthErrorRateThreshold > 100 {
 		return errors.New("report.account_health_error_rate_threshold must be between 0 and 100")
 	}
+	if cfg.Report.DigestQuotaThresholdPercent < 0 || cfg.Report.DigestQuotaThresholdPercent > 100 {
+		return errors.New("report.digest_quota_threshold_percent must be between 0 and 100")
+	}
+	if cfg.Report.DigestExpiringWithinDays < 0 || cfg.Report.DigestExpiringWithinDays > 90 {
+		return errors.New("report.digest_expiring_within_days must be between 0 and 90")
+	}
 	return nil
 }
 
@@ -29,6 +29,11 @@ type OpsEmailReportConfig struct {
 	AccountHealthEnabled            bool     `json:"account_health_enabled"`
 	AccountHealthSchedule           string   `json:"account_health_schedule"`
 	AccountHealthErrorRateThreshold float64  `json:"account_health_error_rate_threshold"`
+	// 周度用量与健康摘要：热门模型、花费、错误趋势、临近配额与即将过期的账号。
+	WeeklyDigestEnabled         bool    `json:"weekly_digest_enabled"`
+	WeeklyDigestSchedule        string  `json:"weekly_digest_schedule"`
+	DigestQuotaThresholdPercent float64 `json:"digest_quota_threshold_percent"`
+	DigestExpiringWithinDays    int     `json:"digest_expiring_within_days"`
 }
 
 // OpsEmailNotificationConfigUpdateRequest allows partial updates, while the
@@ -0,0 +1,32 @@
+package service
+
+import "strings"
+
+// OpsSlowRequestThresholds 慢请求阈值（毫秒，0 表示不检查该项）。
+type OpsSlowRequestThresholds struct {
+	// RoutePrefix 命中的路由前缀；为空表示使用默认阈值
+	RoutePrefix string
+	TotalMs     int64
+	TTFTMs      int64
+}
+
+// SlowRequestThresholds 返回 path 适用的慢请求阈值：按最长前缀匹配路由覆盖，未命中时使用默认阈值。
+// 慢请求日志未启用或两项阈值均为 0 时 ok=false。
+func (s *OpsService) SlowRequestThresholds(path string) (OpsSlowRequestThresholds, bool) {
+	if s == nil || s.cfg == nil || !s.cfg.Ops.Enabled || !s.cfg.Ops.SlowRequestLog.Enabled {
+		return OpsSlowRequestThresholds{}, false
+	}
+	cfg := s.cfg.Ops.SlowRequestLog
+	thresholds := OpsSlowRequestThresholds{TotalMs: cfg.TotalThresholdMs, TTFTMs: cfg.TTFTThresholdMs}
+	for _, route := range cfg.Routes {
+		prefix := strings.TrimSpace(route.PathPrefix)
+		if prefix == "" || !strings.HasPrefix(path, prefix) || len(prefix) <= len(thresholds.RoutePrefix) {
+			continue
+		}
+		thresholds = OpsSlowRequestThresholds{RoutePrefix: prefix, TotalMs: route.TotalThresholdMs, TTFTMs: route.TTFTThresholdMs}
+	}
+	if thresholds.TotalMs <= 0 && thresholds.TTFTMs <= 0 {
+		return OpsSlowRequestThresholds{}, false
+	}
+	return thresholds, true
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsService_SlowRequestThresholds(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ops.Enabled = true
+	cfg.Ops.SlowRequestLog = config.OpsSlowRequestLogConfig{
+		Enabled:          true,
+		TotalThresholdMs: 60000,
+		TTFTThresholdMs:  10000,
+		Routes: []config.OpsSlowRequestRouteConfig{
+			{PathPrefix: "/v1", TotalThresholdMs: 30000},
+			{PathPrefix: "/v1/messages", TotalThresholdMs: 300000, TTFTThresholdMs: 20000},
+			{PathPrefix: "/v1/images", TotalThresholdMs: 0, TTFTThresholdMs: 0},
+		},
+	}
+	svc := &OpsService{cfg: cfg}
+
+	got, ok := svc.SlowRequestThresholds("/v1/messages/count_tokens")
+	require.True(t, ok)
+	require.Equal(t, OpsSlowRequestThresholds{RoutePrefix: "/v1/messages", TotalMs: 300000, TTFTMs: 20000}, got)
+
+	got, ok = svc.SlowRequestThresholds("/v1/chat/completions")
+	require.True(t, ok)
+	require.Equal(t, OpsSlowRequestThresholds{RoutePrefix: "/v1", TotalMs: 30000}, got)
+
+	got, ok = svc.SlowRequestThresholds("/antigravity/v1/messages")
+	require.True(t, ok)
+	require.Equal(t, OpsSlowRequestThresholds{TotalMs: 60000, TTFTMs: 10000}, got)
+
+	_, ok = svc.SlowRequestThresholds("/v1/images/generations")
+	require.False(t, ok, "route with all thresholds 0 disables the log")
+
+	cfg.Ops.SlowRequestLog.Enabled = false
+	_, ok = svc.SlowRequestThresholds("/v1/messages")
+	require.False(t, ok)
+
+	var nilSvc *OpsService
+	_, ok = nilSvc.SlowRequestThresholds("/v1/messages")
+	require.False(t, ok)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	opsStreamTimingQueueSize     = 5000
+	opsStreamTimingBatchSize     = 200
+	opsStreamTimingFlushInterval = 2 * time.Second
+	opsStreamTimingListLimit     = 20
+	// opsStreamTimingStallThreshold 最长间隔达到该值才判定卡顿来源，避免把正常抖动归因。
+	opsStreamTimingStallThreshold = time.Second
+)
+
+var opsStreamTimingGapBuckets = []struct {
+	label string
+	upper time.Duration // 0 表示无上界
+}{
+	{"lt_100ms", 100 * time.Millisecond},
+	{"lt_500ms", 500 * time.Millisecond},
+	{"lt_1s", time.Second},
+	{"lt_5s", 5 * time.Second},
+	{"lt_15s", 15 * time.Second},
+	{"ge_15s", 0},
+}
+
+// OpsStreamTimingRecorder 按采样率为流式请求记录逐事件耗时，流结束后异步批量写入 ops_stream_timings。
+type OpsStreamTimingRecorder struct {
+	sampleRate float64
+	queue      *opsBatchQueue[*OpsStreamTiming]
+}
+
+func NewOpsStreamTimingRecorder(opsRepo OpsRepository, sampleRate float64) *OpsStreamTimingRecorder {
+	return &OpsStreamTimingRecorder{
+		sampleRate: sampleRate,
+		queue: newOpsBatchQueue("stream_timings", opsStreamTimingQueueSize, opsStreamTimingBatchSize, opsStreamTimingFlushInterval,
+			func(ctx context.Context, batch []*OpsStreamTiming) error {
+				if opsRepo == nil {
+					return nil
+				}
+				_, err := opsRepo.BatchInsertStreamTimings(ctx, batch)
+				return err
+			}),
+	}
+}
+
+func (r *OpsStreamTimingRecorder) Start() {
+	if r == nil {
+		return
+	}
+	r.queue.start()
+}
+
+func (r *OpsStreamTimingRecorder) Stop() {
+	if r == nil {
+		return
+	}
+	r.queue.stop()
+}
+
+// begin 按采样率为即将处理的流创建计时器并放入 ctx；未采样时原样返回 ctx 与 nil。
+func (r *OpsStreamTimingRecorder) begin(ctx context.Context, account *Account, model string, resp *http.Response) (context.Context, *sseStreamTiming) {
+	if r == nil || r.sampleRate <= 0 {
+		return ctx, nil
+	}
+	if r.sampleRate < 1 && rand.Float64() >= r.sampleRate {
+		return ctx, nil
+	}
+	t := &sseStreamTiming{
+		recorder: r,
+		start:    time.Now(),
+		summary: OpsStreamTiming{
+			ClientRequestID: upstreamClientRequestIDFromContext(ctx),
+			Model:           model,
+		},
+	}
+	if resp != nil {
+		t.summary.RequestID = sanitizeUpstreamRequestID(firstNonEmptyString(resp.Header.Get("x-request-id"), resp.Header.Get("request-id")))
+	}
+	if account != nil {
+		t.summary.Platform = account.Platform
+		if account.ID > 0 {
+			id := account.ID
+			t.summary.AccountID = &id
+		}
+	}
+	t.lastUpstreamAt = t.start
+	return context.WithValue(ctx, sseStreamTimingContextKey{}, t), t
+}
+
+type sseStreamTimingContextKey struct{}
+
+func sseStreamTimingFromContext(ctx context.Context) *sseStreamTiming {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(sseStreamTimingContextKey{}).(*sseStreamTiming)
+	return t
+}
+
+// sseStreamTiming 单个流的逐事件计时，仅由流处理主循环调用（非并发安全）；nil 接收者为空操作。
+type sseStreamTiming struct {
+	recorder *OpsStreamTimingRecorder
+	summary  OpsStreamTiming
+
+	start          time.Time
+	lastUpstreamAt time.Time
+	maxUpstreamGap time.Duration
+	maxRelayLag    time.Duration
+	relayLagTotal  time.Duration
+	gapCounts      [6]int
+	finished       bool
+}
+
+// ObserveEvent 记录一个事件：readAt 为从上游读到事件的时间，relayedAt 为写出给客户端后的时间。
+func (t *sseStreamTiming) ObserveEvent(readAt, relayedAt time.Time) {
+	if t == nil || t.finished {
+		return
+	}
+	if t.summary.EventCount == 0 {
+		ms := readAt.Sub(t.start).Milliseconds()
+		t.summary.FirstEventMs = &ms
+	} else {
+		gap := readAt.Sub(t.lastUpstreamAt)
+		if gap > t.maxUpstreamGap {
+			t.maxUpstreamGap = gap
+		}
+		for i, b := range opsStreamTimingGapBuckets {
+			if b.upper == 0 || gap < b.upper {
+				t.gapCounts[i]++
+				break
+			}
+		}
+	}
+	t.lastUpstreamAt = readAt
+	t.summary.EventCount++
+
+	lag := relayedAt.Sub(readAt)
+	if lag < 0 {
+		lag = 0
+	}
+	t.relayLagTotal += lag
+	if lag > t.maxRelayLag {
+		t.maxRelayLag = lag
+	}
+}
+
+// Finish 汇总并提交记录；同一计时器只提交一次。
+func (t *sseStreamTiming) Finish(err error, clientDisconnect bool) {
+	if t == nil || t.finished {
+		return
+	}
+	t.finished = true
+	t.recorder.queue.offer(t.buildSummary(err, clientDisconnect))
+}
+
+func (t *sseStreamTiming) buildSummary(err error, clientDisconnect bool) *OpsStreamTiming {
+	out := t.summary
+	out.CreatedAt = t.start.UTC()
+	out.DurationMs = time.Since(t.start).Milliseconds()
+	out.Outcome = sseStreamTimingOutcome(err, clientDisconnect)
+	// 超时/读错误时最后一个事件之后的静默同样是上游间隔
+	if out.Outcome == "timeout" || out.Outcome == "error" {
+		if trailing := time.Since(t.lastUpstreamAt); trailing > t.maxUpstreamGap && out.EventCount > 0 {
+			t.maxUpstreamGap = trailing
+		}
+	}
+	out.MaxUpstreamGapMs = t.maxUpstreamGap.Milliseconds()
+	out.MaxRelayLagMs = t.maxRelayLag.Milliseconds()
+	if out.EventCount > 0 {
+		out.AvgRelayLagMs = (t.relayLagTotal / time.Duration(out.EventCount)).Milliseconds()
+	}
+	out.UpstreamGapHistogram = make(map[string]int, len(opsStreamTimingGapBuckets))
+	for i, b := range opsStreamTimingGapBuckets {
+		out.UpstreamGapHistogram[b.label] = t.gapCounts[i]
+	}
+
+	// 首个事件前的等待也计入上游卡顿（上游已返回响应头但迟迟不出数据）
+	upstreamStall := t.maxUpstreamGap
+	if out.FirstEventMs != nil {
+		if first := time.Duration(*out.FirstEventMs) * time.Millisecond; first > upstreamStall {
+			upstreamStall = first
+		}
+	}
+	switch {
+	case upstreamStall < opsStreamTimingStallThreshold && t.maxRelayLag < opsStreamTimingStallThreshold:
+		out.StallSource = OpsStreamStallSourceNone
+	case upstreamStall >= t.maxRelayLag:
+		out.StallSource = OpsStreamStallSourceUpstream
+	default:
+		out.StallSource = OpsStreamStallSourceRelay
+	}
+	return &out
+}
+
+func sseStreamTimingOutcome(err error, clientDisconnect bool) string {
+	switch {
+	case clientDisconnect:
+		return "client_disconnected"
+	case err == nil:
+		return "completed"
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "interval timeout"):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
+// ListStreamTimings 按 request_id（上游请求 ID）或 client_request_id 查询采样流的耗时摘要。
+func (s *OpsService) ListStreamTimings(ctx context.Context, key string) ([]*OpsStreamTiming, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > opsUpstreamRequestIDMaxLen {
+		return nil, infraerrors.BadRequest("OPS_STREAM_TIMING_INVALID_KEY", "invalid request id")
+	}
+	items, err := s.opsRepo.ListStreamTimings(ctx, []string{key}, opsStreamTimingListLimit)
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_STREAM_TIMING_LOAD_FAILED", "Failed to load stream timings").WithCause(err)
+	}
+	return items, nil
+}
@@ -0,0 +1,124 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSSEStreamTiming(start time.Time) *sseStreamTiming {
+	return &sseStreamTiming{
+		recorder:       NewOpsStreamTimingRecorder(nil, 1),
+		start:          start,
+		lastUpstreamAt: start,
+	}
+}
+
+func TestSSEStreamTiming_UpstreamStall(t *testing.T) {
+	start := time.Now().Add(-10 * time.Second)
+	st := newTestSSEStreamTiming(start)
+
+	st.ObserveEvent(start.Add(200*time.Millisecond), start.Add(201*time.Millisecond))
+	st.ObserveEvent(start.Add(250*time.Millisecond), start.Add(252*time.Millisecond))
+	st.ObserveEvent(start.Add(6250*time.Millisecond), start.Add(6251*time.Millisecond))
+
+	out := st.buildSummary(nil, false)
+	require.Equal(t, "completed", out.Outcome)
+	require.Equal(t, 3, out.EventCount)
+	require.Equal(t, int64(200), *out.FirstEventMs)
+	require.Equal(t, int64(6000), out.MaxUpstreamGapMs)
+	require.Equal(t, 1, out.UpstreamGapHistogram["lt_100ms"])
+	require.Equal(t, 1, out.UpstreamGapHistogram["lt_15s"])
+	require.Equal(t, int64(2), out.MaxRelayLagMs)
+	require.Equal(t, OpsStreamStallSourceUpstream, out.StallSource)
+}
+
+func TestSSEStreamTiming_RelayStall(t *testing.T) {
+	start := time.Now()
+	st := newTestSSEStreamTiming(start)
+
+	st.ObserveEvent(start.Add(100*time.Millisecond), start.Add(100*time.Millisecond))
+	st.ObserveEvent(start.Add(150*time.Millisecond), start.Add(3150*time.Millisecond))
+
+	out := st.buildSummary(nil, false)
+	require.Equal(t, int64(3000), out.MaxRelayLagMs)
+	require.Equal(t, int64(1500), out.AvgRelayLagMs)
+	require.Equal(t, OpsStreamStallSourceRelay, out.StallSource)
+}
+
+func TestSSEStreamTiming_NoStallAndOutcomes(t *testing.T) {
+	start := time.Now()
+	st := newTestSSEStreamTiming(start)
+	st.ObserveEvent(start.Add(10*time.Millisecond), start.Add(11*time.Millisecond))
+	require.Equal(t, OpsStreamStallSourceNone, st.buildSummary(nil, false).StallSource)
+
+	require.Equal(t, "timeout", sseStreamTimingOutcome(errors.New("stream data interval timeout"), false))
+	require.Equal(t, "client_disconnected", sseStreamTimingOutcome(nil, true))
+	require.Equal(t, "error", sseStreamTimingOutcome(errors.New("stream read error"), false))
+}
+
+func TestOpsStreamTimingRecorder_BeginSamplingAndFinishOnce(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxkey.ClientRequestID, "creq-1")
+	resp := &http.Response{Header: http.Header{"X-Request-Id": []string{"req_up_1"}}}
+
+	var nilRecorder *OpsStreamTimingRecorder
+	gotCtx, timing := nilRecorder.begin(ctx, &Account{ID: 3}, "m", resp)
+	require.Nil(t, timing)
+	require.Equal(t, ctx, gotCtx)
+	timing.Finish(nil, false) // nil 计时器为空操作
+
+	_, timing = NewOpsStreamTimingRecorder(nil, 0).begin(ctx, &Account{ID: 3}, "m", resp)
+	require.Nil(t, timing)
+
+	r := NewOpsStreamTimingRecorder(nil, 1)
+	streamCtx, timing := r.begin(ctx, &Account{ID: 3, Platform: PlatformAnthropic}, "claude", resp)
+	require.NotNil(t, timing)
+	require.Same(t, timing, sseStreamTimingFromContext(streamCtx))
+	require.Equal(t, "creq-1", timing.summary.ClientRequestID)
+	require.Equal(t, "req_up_1", timing.summary.RequestID)
+	require.Equal(t, int64(3), *timing.summary.AccountID)
+
+	timing.Finish(nil, false)
+	timing.Finish(nil, false)
+	require.Len(t, r.queue.queue, 1)
+	item := <-r.queue.queue
+	require.Equal(t, PlatformAnthropic, item.Platform)
+	require.Equal(t, "claude", item.Model)
+}
+
+func TestHandleStreamingResponse_RecordsStreamTiming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newMinimalGatewayService()
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	pr, pw := io.Pipe()
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: pr}
+	go func() {
+		defer func() { _ = pw.Close() }()
+		_, _ = pw.Write([]byte("data: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":1}}}\n\n"))
+		_, _ = pw.Write([]byte("data: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":2}}\n\n"))
+		_, _ = pw.Write([]byte("data: [DONE]\n\n"))
+	}()
+
+	r := NewOpsStreamTimingRecorder(nil, 1)
+	ctx, timing := r.begin(context.Background(), &Account{ID: 1}, "model", resp)
+	result, err := svc.handleStreamingResponse(ctx, resp, c, &Account{ID: 1}, time.Now(), "model", "model", false)
+	_ = pr.Close()
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 3, timing.summary.EventCount)
+	require.NotNil(t, timing.summary.FirstEventMs)
+}
@@ -60,7 +60,17 @@ type OpsErrorDistributionItem struct {
 	BusinessLimited int64 `json:"business_limited"`
 }
 
+// OpsErrorCodeDistributionItem 按归一化上游错误代码聚合的错误分布。
+type OpsErrorCodeDistributionItem struct {
+	ErrorCode       string `json:"error_code"`
+	Total           int64  `json:"total"`
+	SLA             int64  `json:"sla"`
+	BusinessLimited int64  `json:"business_limited"`
+}
+
 type OpsErrorDistributionResponse struct {
 	Total int64                       `json:"total"`
 	Items []*OpsErrorDistributionItem `json:"items"`
+	// ErrorCodes 仅统计带归一化代码的错误（上游错误），按数量降序。
+	ErrorCodes []*OpsErrorCodeDistributionItem `json:"error_codes"`
 }
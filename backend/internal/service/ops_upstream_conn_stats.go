@@ -0,0 +1,13 @@
+package service
+
+// GetUpstreamConnStats 返回上游连接复用与热备连接统计；HTTPUpstream 实现不支持统计时 ok=false。
+func (s *OpsService) GetUpstreamConnStats() (HTTPUpstreamConnStats, bool) {
+	if s == nil || s.gatewayService == nil || s.gatewayService.httpUpstream == nil {
+		return HTTPUpstreamConnStats{}, false
+	}
+	provider, ok := s.gatewayService.httpUpstream.(HTTPUpstreamConnStatsProvider)
+	if !ok {
+		return HTTPUpstreamConnStats{}, false
+	}
+	return provider.ConnStats(), true
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,6 +16,8 @@ const (
 	OpsUpstreamErrorMessageKey = "ops_upstream_error_message"
 	OpsUpstreamErrorDetailKey  = "ops_upstream_error_detail"
 	OpsUpstreamErrorsKey       = "ops_upstream_errors"
+	// OpsErrorCodeKey 显式指定归一化错误代码（如响应内容守卫判定的 invalid_response），优先于按错误体推导。
+	OpsErrorCodeKey = "ops_error_code"
 
 	// Optional stage latencies (milliseconds) for troubleshooting and alerting.
 	OpsAuthLatencyMsKey      = "ops_auth_latency_ms"
@@ -51,6 +54,8 @@ const (
 	OpsClientBusinessLimitedReasonAPIKeyGroupUnassigned  = "api_key_group_unassigned"
 	OpsClientBusinessLimitedReasonLocalFeatureGate       = "local_feature_gate"
 	OpsClientBusinessLimitedReasonLocalPolicyDenied      = "local_policy_denied"
+	OpsClientBusinessLimitedReasonAPIKeyModelNotAllowed  = "api_key_model_not_allowed"
+	OpsClientBusinessLimitedReasonAPIKeyRateLimited      = "api_key_rate_limited"
 )
 
 func MarkResponseCommitted(c *gin.Context) { c.Set(ResponseCommittedKey, true) }
@@ -146,6 +151,16 @@ func SetOpsUpstreamError(c *gin.Context, upstreamStatusCode int, upstreamMessage
 	setOpsUpstreamError(c, upstreamStatusCode, upstreamMessage, upstreamDetail)
 }
 
+// SetOpsErrorCode 显式记录本请求的归一化错误代码，供 ops_error_logger 写入 ops_error_logs.error_code。
+func SetOpsErrorCode(c *gin.Context, code string) {
+	if c == nil {
+		return
+	}
+	if code = strings.TrimSpace(code); code != "" {
+		c.Set(OpsErrorCodeKey, code)
+	}
+}
+
 func setOpsUpstreamError(c *gin.Context, upstreamStatusCode int, upstreamMessage, upstreamDetail string) {
 	if c == nil {
 		return
@@ -191,6 +206,12 @@ type OpsUpstreamErrorEvent struct {
 
 	Message string `json:"message,omitempty"`
 	Detail  string `json:"detail,omitempty"`
+
+	// RetryAttempt 是该事件发生时在同一账号上的原地重试序号（kind=retry）或已重试次数（kind=failover）。
+	RetryAttempt int `json:"retry_attempt,omitempty"`
+
+	// ErrorCode 是归一化后的上游错误代码（见 upstream_error_taxonomy.go），由 OpsService 在入库前填充。
+	ErrorCode string `json:"error_code,omitempty"`
 }
 
 func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
@@ -221,6 +242,7 @@ func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
 	evCopy := ev
 	existing = append(existing, &evCopy)
 	c.Set(OpsUpstreamErrorsKey, existing)
+	metrics.IncUpstreamError(evCopy.Platform, evCopy.Kind, evCopy.UpstreamStatusCode)
 
 	checkSkipMonitoringForUpstreamEvent(c, &evCopy)
 }
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// UpstreamClientRequestIDHeader 转发给上游的客户端请求 ID 头（OpenAI / Anthropic 官方 API 均接受并记录）。
+const UpstreamClientRequestIDHeader = "X-Client-Request-Id"
+
+const (
+	opsUpstreamRequestIDQueueSize     = 10000
+	opsUpstreamRequestIDBatchSize     = 500
+	opsUpstreamRequestIDFlushInterval = time.Second
+	opsUpstreamRequestIDMaxLen        = 128
+	opsUpstreamRequestIDListLimit     = 50
+)
+
+// OpsUpstreamRequestIDRecorder 在收到上游响应头时记录上游请求 ID 与 client_request_id 的对应关系，
+// 异步批量写入 ops_upstream_request_ids；队列满时丢弃，绝不阻塞网关请求。
+type OpsUpstreamRequestIDRecorder struct {
+	queue *opsBatchQueue[*OpsUpstreamRequestID]
+}
+
+func NewOpsUpstreamRequestIDRecorder(opsRepo OpsRepository) *OpsUpstreamRequestIDRecorder {
+	return &OpsUpstreamRequestIDRecorder{
+		queue: newOpsBatchQueue("upstream_request_ids", opsUpstreamRequestIDQueueSize, opsUpstreamRequestIDBatchSize, opsUpstreamRequestIDFlushInterval,
+			func(ctx context.Context, batch []*OpsUpstreamRequestID) error {
+				if opsRepo == nil {
+					return nil
+				}
+				_, err := opsRepo.BatchInsertUpstreamRequestIDs(ctx, batch)
+				return err
+			}),
+	}
+}
+
+func (r *OpsUpstreamRequestIDRecorder) Start() {
+	if r == nil {
+		return
+	}
+	r.queue.start()
+}
+
+func (r *OpsUpstreamRequestIDRecorder) Stop() {
+	if r == nil {
+		return
+	}
+	r.queue.stop()
+}
+
+// BeforeUpstreamRequest 向支持的上游注入 client_request_id，便于在上游侧按同一 ID 检索。
+func (r *OpsUpstreamRequestIDRecorder) BeforeUpstreamRequest(req *http.Request) {
+	if req == nil || req.URL == nil {
+		return
+	}
+	clientRequestID := upstreamClientRequestIDFromContext(req.Context())
+	if clientRequestID == "" || !upstreamAcceptsClientRequestID(req) {
+		return
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set(UpstreamClientRequestIDHeader, clientRequestID)
+}
+
+// AfterUpstreamResponse 记录上游返回的请求 ID；无 client_request_id（非网关请求）或上游未返回 ID 时跳过。
+func (r *OpsUpstreamRequestIDRecorder) AfterUpstreamResponse(req *http.Request, resp *http.Response, accountID int64) {
+	if r == nil || req == nil || resp == nil {
+		return
+	}
+	clientRequestID := upstreamClientRequestIDFromContext(req.Context())
+	if clientRequestID == "" {
+		return
+	}
+	upstreamRequestID := sanitizeUpstreamRequestID(firstNonEmptyString(resp.Header.Get("x-request-id"), resp.Header.Get("request-id")))
+	if upstreamRequestID == "" {
+		return
+	}
+	entry := &OpsUpstreamRequestID{
+		CreatedAt:         time.Now().UTC(),
+		ClientRequestID:   clientRequestID,
+		UpstreamRequestID: upstreamRequestID,
+		StatusCode:        resp.StatusCode,
+	}
+	if accountID > 0 {
+		entry.AccountID = &accountID
+	}
+	if req.URL != nil {
+		entry.UpstreamHost = req.URL.Host
+	}
+
+	r.queue.offer(entry)
+}
+
+// DroppedCount 返回因队列满而丢弃的记录数。
+func (r *OpsUpstreamRequestIDRecorder) DroppedCount() uint64 {
+	if r == nil {
+		return 0
+	}
+	return r.queue.dropped()
+}
+
+// upstreamAcceptsClientRequestID 仅对官方 API 的 API Key 请求注入：
+// OAuth 请求需保持官方客户端的请求头指纹，自定义 base_url 的中转站对未知头的处理不可预期，均跳过。
+func upstreamAcceptsClientRequestID(req *http.Request) bool {
+	switch strings.ToLower(req.URL.Hostname()) {
+	case "api.openai.com":
+		return true
+	case "api.anthropic.com":
+		return strings.TrimSpace(req.Header.Get("x-api-key")) != ""
+	}
+	return false
+}
+
+func upstreamClientRequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	v, _ := ctx.Value(ctxkey.ClientRequestID).(string)
+	return sanitizeUpstreamRequestID(v)
+}
+
+// sanitizeUpstreamRequestID 只接受可打印 ASCII 且不超长的 ID，避免写入异常请求头或索引。
+func sanitizeUpstreamRequestID(id string) string {
+	id = strings.TrimSpace(id)
+	if id == "" || len(id) > opsUpstreamRequestIDMaxLen {
+		return ""
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return ""
+		}
+	}
+	return id
+}
+
+// ListUpstreamRequestIDs 按上游请求 ID 或 client_request_id 查询对应关系。
+func (s *OpsService) ListUpstreamRequestIDs(ctx context.Context, key string) ([]*OpsUpstreamRequestID, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > opsUpstreamRequestIDMaxLen {
+		return nil, infraerrors.BadRequest("OPS_UPSTREAM_REQUEST_ID_INVALID_KEY", "invalid request id")
+	}
+	items, err := s.opsRepo.ListUpstreamRequestIDs(ctx, key, opsUpstreamRequestIDListLimit)
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_UPSTREAM_REQUEST_ID_LOAD_FAILED", "Failed to load upstream request ids").WithCause(err)
+	}
+	return items, nil
+}
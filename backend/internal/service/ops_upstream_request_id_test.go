@@ -0,0 +1,125 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func newUpstreamRequestIDTestRequest(t *testing.T, rawURL, clientRequestID string) *http.Request {
+	t.Helper()
+	ctx := context.Background()
+	if clientRequestID != "" {
+		ctx = context.WithValue(ctx, ctxkey.ClientRequestID, clientRequestID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestOpsUpstreamRequestIDRecorder_BeforeUpstreamRequest(t *testing.T) {
+	r := NewOpsUpstreamRequestIDRecorder(nil)
+
+	openai := newUpstreamRequestIDTestRequest(t, "https://api.openai.com/v1/responses", "creq-1")
+	r.BeforeUpstreamRequest(openai)
+	require.Equal(t, "creq-1", openai.Header.Get(UpstreamClientRequestIDHeader))
+
+	anthropicKey := newUpstreamRequestIDTestRequest(t, "https://api.anthropic.com/v1/messages", "creq-2")
+	anthropicKey.Header.Set("x-api-key", "sk-ant-xxx")
+	r.BeforeUpstreamRequest(anthropicKey)
+	require.Equal(t, "creq-2", anthropicKey.Header.Get(UpstreamClientRequestIDHeader))
+
+	anthropicOAuth := newUpstreamRequestIDTestRequest(t, "https://api.anthropic.com/v1/messages", "creq-3")
+	anthropicOAuth.Header.Set("Authorization", "Bearer oauth-token")
+	r.BeforeUpstreamRequest(anthropicOAuth)
+	require.Empty(t, anthropicOAuth.Header.Get(UpstreamClientRequestIDHeader), "OAuth requests keep the official client header set")
+
+	relay := newUpstreamRequestIDTestRequest(t, "https://relay.example.com/v1/messages", "creq-4")
+	relay.Header.Set("x-api-key", "sk-xxx")
+	r.BeforeUpstreamRequest(relay)
+	require.Empty(t, relay.Header.Get(UpstreamClientRequestIDHeader), "unknown hosts are skipped")
+
+	noID := newUpstreamRequestIDTestRequest(t, "https://api.openai.com/v1/responses", "")
+	r.BeforeUpstreamRequest(noID)
+	require.Empty(t, noID.Header.Get(UpstreamClientRequestIDHeader))
+
+	badID := newUpstreamRequestIDTestRequest(t, "https://api.openai.com/v1/responses", "bad\nid")
+	r.BeforeUpstreamRequest(badID)
+	require.Empty(t, badID.Header.Get(UpstreamClientRequestIDHeader))
+}
+
+func TestOpsUpstreamRequestIDRecorder_RecordsMappingsAndFlushesOnStop(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		captured []*OpsUpstreamRequestID
+	)
+	repo := &opsRepoMock{
+		BatchInsertUpstreamRequestIDsFn: func(ctx context.Context, inputs []*OpsUpstreamRequestID) (int64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			captured = append(captured, inputs...)
+			return int64(len(inputs)), nil
+		},
+	}
+	r := NewOpsUpstreamRequestIDRecorder(repo)
+	r.queue.flushInterval = time.Hour
+	r.Start()
+
+	req := newUpstreamRequestIDTestRequest(t, "https://api.anthropic.com/v1/messages", "creq-1")
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("request-id", "req_011")
+	r.AfterUpstreamResponse(req, resp, 42)
+
+	// 非网关请求（无 client_request_id）与上游未返回 ID 的响应均不记录
+	r.AfterUpstreamResponse(newUpstreamRequestIDTestRequest(t, "https://api.anthropic.com/v1/messages", ""), resp, 42)
+	r.AfterUpstreamResponse(req, &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, 42)
+
+	r.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, captured, 1)
+	require.Equal(t, "creq-1", captured[0].ClientRequestID)
+	require.Equal(t, "req_011", captured[0].UpstreamRequestID)
+	require.Equal(t, "api.anthropic.com", captured[0].UpstreamHost)
+	require.Equal(t, http.StatusTooManyRequests, captured[0].StatusCode)
+	require.Equal(t, int64(42), *captured[0].AccountID)
+}
+
+func TestOpsUpstreamRequestIDRecorder_DropsWhenQueueFull(t *testing.T) {
+	r := NewOpsUpstreamRequestIDRecorder(&opsRepoMock{})
+	r.queue.queue = make(chan *OpsUpstreamRequestID, 1)
+
+	req := newUpstreamRequestIDTestRequest(t, "https://api.openai.com/v1/responses", "creq-1")
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Request-Id": []string{"req_1"}}}
+	r.AfterUpstreamResponse(req, resp, 1)
+	r.AfterUpstreamResponse(req, resp, 1)
+
+	require.Equal(t, uint64(1), r.DroppedCount())
+}
+
+func TestOpsService_BuildDebugBundle_ResolvesUpstreamRequestID(t *testing.T) {
+	svc, _ := newOpsDebugBundleTestService(t)
+	repo := svc.opsRepo.(*opsRepoMock)
+	repo.ListUpstreamRequestIDsFn = func(ctx context.Context, key string, limit int) ([]*OpsUpstreamRequestID, error) {
+		if key != "req_up_9" {
+			return []*OpsUpstreamRequestID{}, nil
+		}
+		return []*OpsUpstreamRequestID{{ClientRequestID: "req-1", UpstreamRequestID: "req_up_9"}}, nil
+	}
+	end := time.Now()
+
+	bundle, err := svc.BuildDebugBundle(context.Background(), "req_up_9", end.Add(-time.Hour), end, 5)
+	require.NoError(t, err)
+	require.Equal(t, "req_up_9", bundle.Key)
+	require.Len(t, bundle.Requests, 1)
+	require.Len(t, bundle.UpstreamRequestIDs, 1)
+	require.Contains(t, bundle.Notes[0], "client_request_id req-1")
+}
@@ -0,0 +1,471 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	opsWatchdogDumpIDLayout = "20060102T150405.000Z"
+	opsWatchdogDumpExt      = ".zip"
+	opsWatchdogMetaFile     = "meta.json"
+	opsWatchdogAlertTimeout = 30 * time.Second
+
+	// 转储触发来源
+	OpsWatchdogTriggerThreshold = "threshold"
+	OpsWatchdogTriggerManual    = "manual"
+)
+
+// opsWatchdogDumpIDPattern 转储 ID 即文件名（不含扩展名），下载前校验以防路径穿越。
+var opsWatchdogDumpIDPattern = regexp.MustCompile(`^\d{8}T\d{6}\.\d{3}Z$`)
+
+// OpsWatchdogStats 看门狗一次采样的进程资源读数。
+type OpsWatchdogStats struct {
+	InFlightRequests int64 `json:"in_flight_requests"`
+	HeapAllocMB      int64 `json:"heap_alloc_mb"`
+	HeapSysMB        int64 `json:"heap_sys_mb"`
+	Goroutines       int   `json:"goroutines"`
+}
+
+// OpsWatchdogDump 一次转储的元数据，随 profile 一起写入 zip 的 meta.json。
+type OpsWatchdogDump struct {
+	ID        string           `json:"id"`
+	CreatedAt time.Time        `json:"created_at"`
+	Hostname  string           `json:"hostname"`
+	Trigger   string           `json:"trigger"`
+	Reasons   []string         `json:"reasons,omitempty"`
+	Stats     OpsWatchdogStats `json:"stats"`
+	SizeBytes int64            `json:"size_bytes"`
+}
+
+// OpsWatchdogStatus 看门狗配置、当前读数与本实例保存的转储列表。
+type OpsWatchdogStatus struct {
+	Hostname             string             `json:"hostname"`
+	CheckIntervalSeconds int                `json:"check_interval_seconds"`
+	MaxInFlightRequests  int64              `json:"max_in_flight_requests"`
+	MaxHeapMB            int                `json:"max_heap_mb"`
+	MaxGoroutines        int                `json:"max_goroutines"`
+	CooldownMinutes      int                `json:"cooldown_minutes"`
+	MaxDumps             int                `json:"max_dumps"`
+	Current              OpsWatchdogStats   `json:"current"`
+	LastDumpAt           *time.Time         `json:"last_dump_at,omitempty"`
+	Dumps                []*OpsWatchdogDump `json:"dumps"`
+}
+
+// TrackInFlightRequest 记录一个进行中的网关请求，返回的函数在请求结束时调用。
+// nil 接收者返回空操作，便于中间件无条件 defer。
+func (s *OpsService) TrackInFlightRequest() func() {
+	if s == nil {
+		return func() {}
+	}
+	s.inFlightRequests.Add(1)
+	return func() { s.inFlightRequests.Add(-1) }
+}
+
+// InFlightRequests 返回当前进行中的网关请求数。
+func (s *OpsService) InFlightRequests() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.inFlightRequests.Load()
+}
+
+// SetWatchdog 由 wire 注入资源看门狗，供管理接口列出 / 下载 / 手动触发转储。
+func (s *OpsService) SetWatchdog(w *OpsWatchdogService) {
+	if s == nil {
+		return
+	}
+	s.watchdog = w
+}
+
+func (s *OpsService) requireWatchdog(ctx context.Context) (*OpsWatchdogService, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.watchdog == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_WATCHDOG_DISABLED", "Ops watchdog is not enabled")
+	}
+	return s.watchdog, nil
+}
+
+// GetWatchdogStatus 返回本实例看门狗状态与转储列表（转储只保存在各实例本地）。
+func (s *OpsService) GetWatchdogStatus(ctx context.Context) (*OpsWatchdogStatus, error) {
+	w, err := s.requireWatchdog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return w.Status()
+}
+
+// CaptureWatchdogDump 立即生成一次转储（不受冷却时间限制，不发送告警）。
+func (s *OpsService) CaptureWatchdogDump(ctx context.Context) (*OpsWatchdogDump, error) {
+	w, err := s.requireWatchdog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dump, err := w.capture(OpsWatchdogTriggerManual, nil, w.readStats())
+	if err != nil {
+		return nil, infraerrors.InternalServer("OPS_WATCHDOG_DUMP_FAILED", "Failed to capture watchdog dump").WithCause(err)
+	}
+	return dump, nil
+}
+
+// WatchdogDumpPath 校验转储 ID 并返回 zip 文件路径。
+func (s *OpsService) WatchdogDumpPath(ctx context.Context, id string) (string, error) {
+	w, err := s.requireWatchdog(ctx)
+	if err != nil {
+		return "", err
+	}
+	return w.dumpPath(id)
+}
+
+// OpsWatchdogService 周期检查在途请求数、堆内存与 goroutine 数，超过阈值时把
+// goroutine / heap profile 写入本地有界目录并告警，免去进入容器手工抓取。
+// 每个实例独立运行，不做 leader 选举：泄漏是进程级的。
+type OpsWatchdogService struct {
+	opsService   *OpsService
+	emailService *EmailService
+	cfg          config.OpsWatchdogConfig
+	hostname     string
+
+	mu         sync.Mutex // 串行化转储与目录清理
+	lastDumpAt atomic.Pointer[time.Time]
+
+	// readStats / now 为单测钩子
+	readStats func() OpsWatchdogStats
+	now       func() time.Time
+
+	stopCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewOpsWatchdogService(opsService *OpsService, emailService *EmailService, cfg config.OpsWatchdogConfig) *OpsWatchdogService {
+	hostname, _ := os.Hostname()
+	w := &OpsWatchdogService{
+		opsService:   opsService,
+		emailService: emailService,
+		cfg:          cfg,
+		hostname:     hostname,
+		now:          time.Now,
+		stopCh:       make(chan struct{}),
+	}
+	w.readStats = w.readRuntimeStats
+	return w
+}
+
+func (w *OpsWatchdogService) Start() {
+	if w == nil {
+		return
+	}
+	w.startOnce.Do(func() {
+		w.wg.Add(1)
+		go w.run()
+	})
+}
+
+func (w *OpsWatchdogService) Stop() {
+	if w == nil {
+		return
+	}
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+	w.wg.Wait()
+}
+
+func (w *OpsWatchdogService) run() {
+	defer w.wg.Done()
+	interval := time.Duration(w.cfg.CheckIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.checkOnce()
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// checkOnce 采样一次，超过任一阈值且不在冷却期内时转储并告警。
+func (w *OpsWatchdogService) checkOnce() {
+	stats := w.readStats()
+	reasons := opsWatchdogBreaches(w.cfg, stats)
+	if len(reasons) == 0 {
+		return
+	}
+	if last := w.lastDumpAt.Load(); last != nil && w.now().Sub(*last) < time.Duration(w.cfg.CooldownMinutes)*time.Minute {
+		return
+	}
+
+	dump, err := w.capture(OpsWatchdogTriggerThreshold, reasons, stats)
+	log := logger.L().With(zap.String("component", "ops.watchdog"))
+	if err != nil {
+		log.Error("ops.watchdog_dump_failed", zap.Strings("reasons", reasons), zap.Error(err))
+		return
+	}
+	log.Warn("ops.watchdog_threshold_exceeded",
+		zap.String("dump_id", dump.ID),
+		zap.Strings("reasons", reasons),
+		zap.Int64("in_flight_requests", stats.InFlightRequests),
+		zap.Int64("heap_alloc_mb", stats.HeapAllocMB),
+		zap.Int("goroutines", stats.Goroutines),
+	)
+	w.sendAlert(dump)
+}
+
+// opsWatchdogBreaches 返回超过的阈值描述；阈值为 0 表示不检查。
+func opsWatchdogBreaches(cfg config.OpsWatchdogConfig, stats OpsWatchdogStats) []string {
+	var reasons []string
+	if cfg.MaxInFlightRequests > 0 && stats.InFlightRequests > cfg.MaxInFlightRequests {
+		reasons = append(reasons, fmt.Sprintf("in_flight_requests %d > %d", stats.InFlightRequests, cfg.MaxInFlightRequests))
+	}
+	if cfg.MaxHeapMB > 0 && stats.HeapAllocMB > int64(cfg.MaxHeapMB) {
+		reasons = append(reasons, fmt.Sprintf("heap_alloc_mb %d > %d", stats.HeapAllocMB, cfg.MaxHeapMB))
+	}
+	if cfg.MaxGoroutines > 0 && stats.Goroutines > cfg.MaxGoroutines {
+		reasons = append(reasons, fmt.Sprintf("goroutines %d > %d", stats.Goroutines, cfg.MaxGoroutines))
+	}
+	return reasons
+}
+
+func (w *OpsWatchdogService) readRuntimeStats() OpsWatchdogStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return OpsWatchdogStats{
+		InFlightRequests: w.opsService.InFlightRequests(),
+		HeapAllocMB:      int64(ms.HeapAlloc / bytesPerMB),
+		HeapSysMB:        int64(ms.HeapSys / bytesPerMB),
+		Goroutines:       runtime.NumGoroutine(),
+	}
+}
+
+// capture 写入 zip（meta.json + goroutine.txt + heap.pb.gz），先写临时文件再 rename，
+// 随后删除超出 MaxDumps 的最旧转储。
+func (w *OpsWatchdogService) capture(trigger string, reasons []string, stats OpsWatchdogStats) (*OpsWatchdogDump, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.MkdirAll(w.cfg.DumpDir, 0o750); err != nil {
+		return nil, fmt.Errorf("create dump dir: %w", err)
+	}
+	now := w.now().UTC()
+	dump := &OpsWatchdogDump{
+		ID:        now.Format(opsWatchdogDumpIDLayout),
+		CreatedAt: now,
+		Hostname:  w.hostname,
+		Trigger:   trigger,
+		Reasons:   reasons,
+		Stats:     stats,
+	}
+
+	finalPath := filepath.Join(w.cfg.DumpDir, dump.ID+opsWatchdogDumpExt)
+	tmp, err := os.CreateTemp(w.cfg.DumpDir, ".watchdog-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("create dump file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := writeOpsWatchdogZip(tmp, dump); err != nil {
+		_ = tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("close dump file: %w", err)
+	}
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return nil, fmt.Errorf("rename dump file: %w", err)
+	}
+	if info, err := os.Stat(finalPath); err == nil {
+		dump.SizeBytes = info.Size()
+	}
+	w.lastDumpAt.Store(&now)
+	w.pruneLocked()
+	return dump, nil
+}
+
+func writeOpsWatchdogZip(f *os.File, dump *OpsWatchdogDump) error {
+	zw := zip.NewWriter(f)
+	meta, err := zw.Create(opsWatchdogMetaFile)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(meta)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(dump); err != nil {
+		return fmt.Errorf("write meta: %w", err)
+	}
+	// debug=2 输出带完整调用栈的 goroutine 文本，可直接阅读；heap 为 pprof 二进制格式
+	profiles := []struct {
+		name  string
+		file  string
+		debug int
+	}{
+		{name: "goroutine", file: "goroutine.txt", debug: 2},
+		{name: "heap", file: "heap.pb.gz", debug: 0},
+	}
+	for _, p := range profiles {
+		prof := pprof.Lookup(p.name)
+		if prof == nil {
+			continue
+		}
+		fw, err := zw.Create(p.file)
+		if err != nil {
+			return err
+		}
+		if err := prof.WriteTo(fw, p.debug); err != nil {
+			return fmt.Errorf("write %s profile: %w", p.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// pruneLocked 按文件名（即时间）保留最新的 MaxDumps 个转储。
+func (w *OpsWatchdogService) pruneLocked() {
+	ids := w.listDumpIDs()
+	if w.cfg.MaxDumps <= 0 || len(ids) <= w.cfg.MaxDumps {
+		return
+	}
+	for _, id := range ids[w.cfg.MaxDumps:] {
+		_ = os.Remove(filepath.Join(w.cfg.DumpDir, id+opsWatchdogDumpExt))
+	}
+}
+
+// listDumpIDs 返回目录中的转储 ID，新的在前。
+func (w *OpsWatchdogService) listDumpIDs() []string {
+	entries, err := os.ReadDir(w.cfg.DumpDir)
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), opsWatchdogDumpExt)
+		if ok && !e.IsDir() && opsWatchdogDumpIDPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids
+}
+
+// Status 返回当前读数与转储列表；读取单个 meta.json 失败时只保留 ID 与大小。
+func (w *OpsWatchdogService) Status() (*OpsWatchdogStatus, error) {
+	status := &OpsWatchdogStatus{
+		Hostname:             w.hostname,
+		CheckIntervalSeconds: w.cfg.CheckIntervalSeconds,
+		MaxInFlightRequests:  w.cfg.MaxInFlightRequests,
+		MaxHeapMB:            w.cfg.MaxHeapMB,
+		MaxGoroutines:        w.cfg.MaxGoroutines,
+		CooldownMinutes:      w.cfg.CooldownMinutes,
+		MaxDumps:             w.cfg.MaxDumps,
+		Current:              w.readStats(),
+		LastDumpAt:           w.lastDumpAt.Load(),
+		Dumps:                []*OpsWatchdogDump{},
+	}
+	for _, id := range w.listDumpIDs() {
+		status.Dumps = append(status.Dumps, w.readDumpMeta(id))
+	}
+	return status, nil
+}
+
+func (w *OpsWatchdogService) readDumpMeta(id string) *OpsWatchdogDump {
+	path := filepath.Join(w.cfg.DumpDir, id+opsWatchdogDumpExt)
+	fallback := &OpsWatchdogDump{ID: id}
+	if t, err := time.Parse(opsWatchdogDumpIDLayout, id); err == nil {
+		fallback.CreatedAt = t
+	}
+	if info, err := os.Stat(path); err == nil {
+		fallback.SizeBytes = info.Size()
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fallback
+	}
+	defer func() { _ = zr.Close() }()
+	f, err := zr.Open(opsWatchdogMetaFile)
+	if err != nil {
+		return fallback
+	}
+	defer func() { _ = f.Close() }()
+	var dump OpsWatchdogDump
+	if err := json.NewDecoder(f).Decode(&dump); err != nil {
+		return fallback
+	}
+	dump.ID = id
+	dump.SizeBytes = fallback.SizeBytes
+	return &dump
+}
+
+func (w *OpsWatchdogService) dumpPath(id string) (string, error) {
+	if !opsWatchdogDumpIDPattern.MatchString(id) {
+		return "", infraerrors.BadRequest("OPS_WATCHDOG_INVALID_DUMP_ID", "invalid dump id")
+	}
+	path := filepath.Join(w.cfg.DumpDir, id+opsWatchdogDumpExt)
+	if _, err := os.Stat(path); err != nil {
+		return "", infraerrors.NotFound("OPS_WATCHDOG_DUMP_NOT_FOUND", "watchdog dump not found")
+	}
+	return path, nil
+}
+
+// sendAlert 按运维告警邮件配置通知收件人；冷却期同时限制了告警频率。
+func (w *OpsWatchdogService) sendAlert(dump *OpsWatchdogDump) {
+	if w.emailService == nil || w.opsService == nil || dump == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opsWatchdogAlertTimeout)
+	defer cancel()
+
+	emailCfg, err := w.opsService.GetEmailNotificationConfig(ctx)
+	if err != nil || emailCfg == nil || !emailCfg.Alert.Enabled {
+		return
+	}
+	subject := fmt.Sprintf("[Ops Alert][P1] Resource watchdog triggered on %s", w.hostname)
+	body := buildOpsWatchdogAlertBody(dump)
+	for _, to := range emailCfg.Alert.Recipients {
+		addr := strings.TrimSpace(to)
+		if addr == "" {
+			continue
+		}
+		// 单个收件人失败不影响其它收件人
+		_ = w.emailService.SendEmail(ctx, addr, subject, body)
+	}
+}
+
+func buildOpsWatchdogAlertBody(dump *OpsWatchdogDump) string {
+	var b strings.Builder
+	b.WriteString("<h2>Resource watchdog triggered</h2>")
+	fmt.Fprintf(&b, "<p><b>Host</b>: %s</p>", htmlEscape(dump.Hostname))
+	fmt.Fprintf(&b, "<p><b>Time</b>: %s</p>", dump.CreatedAt.Format(time.RFC3339))
+	b.WriteString("<p><b>Reasons</b>:</p><ul>")
+	for _, r := range dump.Reasons {
+		fmt.Fprintf(&b, "<li>%s</li>", htmlEscape(r))
+	}
+	b.WriteString("</ul>")
+	fmt.Fprintf(&b, "<p>Dump <code>%s</code> (goroutine + heap profiles) can be downloaded from the ops console on this instance.</p>", htmlEscape(dump.ID))
+	return b.String()
+}
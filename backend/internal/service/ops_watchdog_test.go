@@ -0,0 +1,110 @@
+//go:build unit
+
+package service
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOpsWatchdog(t *testing.T, cfg config.OpsWatchdogConfig, stats OpsWatchdogStats) (*OpsWatchdogService, *time.Time) {
+	t.Helper()
+	cfg.DumpDir = t.TempDir()
+	w := NewOpsWatchdogService(nil, nil, cfg)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	w.readStats = func() OpsWatchdogStats { return stats }
+	return w, &now
+}
+
+func TestOpsService_TrackInFlightRequest(t *testing.T) {
+	svc := &OpsService{}
+	done1 := svc.TrackInFlightRequest()
+	done2 := svc.TrackInFlightRequest()
+	require.Equal(t, int64(2), svc.InFlightRequests())
+	done1()
+	done2()
+	require.Equal(t, int64(0), svc.InFlightRequests())
+
+	var nilSvc *OpsService
+	nilSvc.TrackInFlightRequest()()
+	require.Zero(t, nilSvc.InFlightRequests())
+}
+
+func TestOpsWatchdogBreaches(t *testing.T) {
+	cfg := config.OpsWatchdogConfig{MaxInFlightRequests: 100, MaxHeapMB: 512, MaxGoroutines: 0}
+	require.Empty(t, opsWatchdogBreaches(cfg, OpsWatchdogStats{InFlightRequests: 100, HeapAllocMB: 512, Goroutines: 1 << 20}))
+
+	reasons := opsWatchdogBreaches(cfg, OpsWatchdogStats{InFlightRequests: 101, HeapAllocMB: 600})
+	require.Equal(t, []string{"in_flight_requests 101 > 100", "heap_alloc_mb 600 > 512"}, reasons)
+}
+
+func TestOpsWatchdog_CheckOnceDumpsAndRespectsCooldown(t *testing.T) {
+	w, now := newTestOpsWatchdog(t, config.OpsWatchdogConfig{MaxGoroutines: 10, CooldownMinutes: 10, MaxDumps: 5}, OpsWatchdogStats{Goroutines: 11})
+
+	w.checkOnce()
+	require.Len(t, w.listDumpIDs(), 1)
+
+	*now = now.Add(5 * time.Minute)
+	w.checkOnce()
+	require.Len(t, w.listDumpIDs(), 1, "cooldown should suppress a second dump")
+
+	*now = now.Add(6 * time.Minute)
+	w.checkOnce()
+	ids := w.listDumpIDs()
+	require.Equal(t, []string{"20261015T081100.000Z", "20261015T080000.000Z"}, ids)
+
+	zr, err := zip.OpenReader(filepath.Join(w.cfg.DumpDir, ids[0]+opsWatchdogDumpExt))
+	require.NoError(t, err)
+	defer func() { _ = zr.Close() }()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"meta.json", "goroutine.txt", "heap.pb.gz"}, names)
+}
+
+func TestOpsWatchdog_CapturePrunesOldestAndStatusReadsMeta(t *testing.T) {
+	w, now := newTestOpsWatchdog(t, config.OpsWatchdogConfig{MaxDumps: 2}, OpsWatchdogStats{InFlightRequests: 7})
+
+	for i := 0; i < 3; i++ {
+		_, err := w.capture(OpsWatchdogTriggerManual, nil, w.readStats())
+		require.NoError(t, err)
+		*now = now.Add(time.Second)
+	}
+	// 非转储文件不受影响
+	require.NoError(t, os.WriteFile(filepath.Join(w.cfg.DumpDir, "notes.txt"), []byte("x"), 0o600))
+
+	status, err := w.Status()
+	require.NoError(t, err)
+	require.Len(t, status.Dumps, 2)
+	require.Equal(t, "20261015T080002.000Z", status.Dumps[0].ID)
+	require.Equal(t, "20261015T080001.000Z", status.Dumps[1].ID)
+	require.Equal(t, OpsWatchdogTriggerManual, status.Dumps[0].Trigger)
+	require.Equal(t, int64(7), status.Dumps[0].Stats.InFlightRequests)
+	require.Positive(t, status.Dumps[0].SizeBytes)
+	require.NotNil(t, status.LastDumpAt)
+}
+
+func TestOpsWatchdog_DumpPathValidatesID(t *testing.T) {
+	w, _ := newTestOpsWatchdog(t, config.OpsWatchdogConfig{MaxDumps: 1}, OpsWatchdogStats{})
+	dump, err := w.capture(OpsWatchdogTriggerManual, nil, OpsWatchdogStats{})
+	require.NoError(t, err)
+
+	path, err := w.dumpPath(dump.ID)
+	require.NoError(t, err)
+	require.FileExists(t, path)
+
+	_, err = w.dumpPath("../../etc/passwd")
+	require.Equal(t, "OPS_WATCHDOG_INVALID_DUMP_ID", infraerrors.Reason(err))
+
+	_, err = w.dumpPath("20200101T000000.000Z")
+	require.Equal(t, "OPS_WATCHDOG_DUMP_NOT_FOUND", infraerrors.Reason(err))
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	opsWeeklyDigestWindow       = 7 * 24 * time.Hour
+	opsWeeklyDigestTopModels    = 10
+	opsWeeklyDigestAccountLimit = 20
+)
+
+// OpsWeeklyDigestOptions 摘要中“临近配额”“即将过期”两节的判定阈值。
+type OpsWeeklyDigestOptions struct {
+	QuotaThresholdPercent float64
+	ExpiringWithinDays    int
+}
+
+// OpsDigestModel 周内单个模型的用量与花费。
+type OpsDigestModel struct {
+	Model       string  `json:"model"`
+	Requests    int64   `json:"requests"`
+	TotalTokens int64   `json:"total_tokens"`
+	ActualCost  float64 `json:"actual_cost"`
+	SharePct    float64 `json:"share_pct"`
+}
+
+// OpsDigestErrorDay 按天聚合的 SLA 错误数。
+type OpsDigestErrorDay struct {
+	Date   string `json:"date"`
+	Errors int64  `json:"errors"`
+}
+
+// OpsDigestQuotaAccount 已用额度达到阈值的账号额度维度。
+type OpsDigestQuotaAccount struct {
+	AccountID   int64   `json:"account_id"`
+	AccountName string  `json:"account_name"`
+	Platform    string  `json:"platform"`
+	Dimension   string  `json:"dimension"` // total / daily / weekly
+	Used        float64 `json:"used"`
+	Limit       float64 `json:"limit"`
+	UsedPct     float64 `json:"used_pct"`
+}
+
+// OpsDigestExpiringAccount 即将到期的账号。
+type OpsDigestExpiringAccount struct {
+	AccountID   int64     `json:"account_id"`
+	AccountName string    `json:"account_name"`
+	Platform    string    `json:"platform"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// OpsWeeklyDigest 周度用量与健康摘要。Prev* 为上一个 7 天窗口，用于环比。
+type OpsWeeklyDigest struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+
+	TotalRequests  int64   `json:"total_requests"`
+	TotalTokens    int64   `json:"total_tokens"`
+	ActualCost     float64 `json:"actual_cost"`
+	PrevActualCost float64 `json:"prev_actual_cost"`
+
+	TopModels []*OpsDigestModel `json:"top_models"`
+
+	ErrorsTotal     int64                `json:"errors_total"`
+	PrevErrorsTotal int64                `json:"prev_errors_total"`
+	ErrorTrend      []*OpsDigestErrorDay `json:"error_trend"`
+
+	AccountsNearQuota []*OpsDigestQuotaAccount    `json:"accounts_near_quota"`
+	ExpiringAccounts  []*OpsDigestExpiringAccount `json:"expiring_accounts"`
+
+	// Warnings 记录取数失败的分节；单个数据源失败时其余分节照常生成。
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SetUsageStatsSource 由 wire 注入用量日志仓储，供周度摘要统计模型用量与花费。
+func (s *OpsService) SetUsageStatsSource(usageLogRepo UsageLogRepository) {
+	if s == nil {
+		return
+	}
+	s.usageLogRepo = usageLogRepo
+}
+
+// BuildWeeklyDigest 生成截至 end 的 7 天摘要。
+func (s *OpsService) BuildWeeklyDigest(ctx context.Context, end time.Time, opts OpsWeeklyDigestOptions) (*OpsWeeklyDigest, error) {
+	if s == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_SERVICE_UNAVAILABLE", "Ops service not available")
+	}
+	if end.IsZero() {
+		end = time.Now()
+	}
+	end = end.UTC()
+	start := end.Add(-opsWeeklyDigestWindow)
+	prevStart := start.Add(-opsWeeklyDigestWindow)
+
+	digest := &OpsWeeklyDigest{
+		StartTime:         start,
+		EndTime:           end,
+		TopModels:         []*OpsDigestModel{},
+		ErrorTrend:        []*OpsDigestErrorDay{},
+		AccountsNearQuota: []*OpsDigestQuotaAccount{},
+		ExpiringAccounts:  []*OpsDigestExpiringAccount{},
+	}
+	warn := func(section string, err error) {
+		digest.Warnings = append(digest.Warnings, fmt.Sprintf("%s: %v", section, err))
+	}
+
+	if s.usageLogRepo != nil {
+		if err := s.fillDigestUsage(ctx, digest, prevStart); err != nil {
+			warn("usage", err)
+		}
+	} else {
+		warn("usage", fmt.Errorf("usage stats source not configured"))
+	}
+
+	if err := s.fillDigestErrors(ctx, digest, prevStart); err != nil {
+		warn("errors", err)
+	}
+
+	accounts, err := s.listAllAccountsForOps(ctx, "", nil)
+	if err != nil {
+		warn("accounts", err)
+	} else {
+		digest.AccountsNearQuota = opsDigestAccountsNearQuota(accounts, opts.QuotaThresholdPercent)
+		digest.ExpiringAccounts = opsDigestExpiringAccounts(accounts, end, opts.ExpiringWithinDays)
+	}
+	return digest, nil
+}
+
+func (s *OpsService) fillDigestUsage(ctx context.Context, digest *OpsWeeklyDigest, prevStart time.Time) error {
+	stats, err := s.usageLogRepo.GetModelStatsWithFilters(ctx, digest.StartTime, digest.EndTime, 0, 0, 0, 0, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	models := make([]*OpsDigestModel, 0, len(stats))
+	for _, st := range stats {
+		digest.TotalRequests += st.Requests
+		digest.TotalTokens += st.TotalTokens
+		digest.ActualCost += st.ActualCost
+		models = append(models, &OpsDigestModel{
+			Model:       st.Model,
+			Requests:    st.Requests,
+			TotalTokens: st.TotalTokens,
+			ActualCost:  st.ActualCost,
+		})
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		if models[i].ActualCost != models[j].ActualCost {
+			return models[i].ActualCost > models[j].ActualCost
+		}
+		return models[i].Requests > models[j].Requests
+	})
+	if len(models) > opsWeeklyDigestTopModels {
+		models = models[:opsWeeklyDigestTopModels]
+	}
+	for _, m := range models {
+		if digest.ActualCost > 0 {
+			m.SharePct = roundTo1DP(m.ActualCost / digest.ActualCost * 100)
+		}
+	}
+	digest.TopModels = models
+
+	prev, err := s.usageLogRepo.GetModelStatsWithFilters(ctx, prevStart, digest.StartTime, 0, 0, 0, 0, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("previous window: %w", err)
+	}
+	for _, st := range prev {
+		digest.PrevActualCost += st.ActualCost
+	}
+	return nil
+}
+
+// fillDigestErrors 一次查询 14 天的日粒度错误趋势，前 7 天只计入环比总数。
+func (s *OpsService) fillDigestErrors(ctx context.Context, digest *OpsWeeklyDigest, prevStart time.Time) error {
+	trend, err := s.GetErrorTrend(ctx, &OpsDashboardFilter{
+		StartTime: prevStart,
+		EndTime:   digest.EndTime,
+		QueryMode: OpsQueryModeAuto,
+	}, 24*3600)
+	if err != nil {
+		return err
+	}
+	if trend == nil {
+		return nil
+	}
+	for _, p := range trend.Points {
+		if p == nil {
+			continue
+		}
+		if p.BucketStart.Before(digest.StartTime.Truncate(24 * time.Hour)) {
+			digest.PrevErrorsTotal += p.ErrorCountSLA
+			continue
+		}
+		digest.ErrorsTotal += p.ErrorCountSLA
+		digest.ErrorTrend = append(digest.ErrorTrend, &OpsDigestErrorDay{
+			Date:   p.BucketStart.UTC().Format("2006-01-02"),
+			Errors: p.ErrorCountSLA,
+		})
+	}
+	return nil
+}
+
+func opsDigestAccountsNearQuota(accounts []Account, thresholdPct float64) []*OpsDigestQuotaAccount {
+	out := []*OpsDigestQuotaAccount{}
+	if thresholdPct <= 0 {
+		return out
+	}
+	for i := range accounts {
+		acc := &accounts[i]
+		dims := []struct {
+			name        string
+			used, limit float64
+		}{
+			{"total", acc.GetQuotaUsed(), acc.GetQuotaLimit()},
+			{"daily", acc.GetQuotaDailyUsed(), acc.GetQuotaDailyLimit()},
+			{"weekly", acc.GetQuotaWeeklyUsed(), acc.GetQuotaWeeklyLimit()},
+		}
+		for _, d := range dims {
+			if d.limit <= 0 {
+				continue
+			}
+			pct := d.used / d.limit * 100
+			if pct < thresholdPct {
+				continue
+			}
+			out = append(out, &OpsDigestQuotaAccount{
+				AccountID:   acc.ID,
+				AccountName: acc.Name,
+				Platform:    acc.Platform,
+				Dimension:   d.name,
+				Used:        d.used,
+				Limit:       d.limit,
+				UsedPct:     roundTo1DP(pct),
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UsedPct > out[j].UsedPct })
+	if len(out) > opsWeeklyDigestAccountLimit {
+		out = out[:opsWeeklyDigestAccountLimit]
+	}
+	return out
+}
+
+func opsDigestExpiringAccounts(accounts []Account, now time.Time, withinDays int) []*OpsDigestExpiringAccount {
+	out := []*OpsDigestExpiringAccount{}
+	if withinDays <= 0 {
+		return out
+	}
+	deadline := now.Add(time.Duration(withinDays) * 24 * time.Hour)
+	for i := range accounts {
+		acc := &accounts[i]
+		if acc.ExpiresAt == nil || acc.ExpiresAt.Before(now) || acc.ExpiresAt.After(deadline) {
+			continue
+		}
+		out = append(out, &OpsDigestExpiringAccount{
+			AccountID:   acc.ID,
+			AccountName: acc.Name,
+			Platform:    acc.Platform,
+			ExpiresAt:   acc.ExpiresAt.UTC(),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ExpiresAt.Before(out[j].ExpiresAt) })
+	if len(out) > opsWeeklyDigestAccountLimit {
+		out = out[:opsWeeklyDigestAccountLimit]
+	}
+	return out
+}
+
+// opsDigestChangePct 环比变化百分比；上期为 0 时无法计算，返回 "-"。
+func opsDigestChangePct(current, previous float64) string {
+	if previous <= 0 {
+		return "-"
+	}
+	change := (current - previous) / previous * 100
+	if math.IsNaN(change) || math.IsInf(change, 0) {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", change)
+}
+
+func buildOpsWeeklyDigestEmailHTML(title string, digest *OpsWeeklyDigest) string {
+	if digest == nil {
+		return fmt.Sprintf("<h2>%s</h2><p>No data.</p>", htmlEscape(title))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h2>%s</h2>\n<p><b>Period</b>: %s ~ %s (UTC)</p>\n",
+		htmlEscape(strings.TrimSpace(title)),
+		htmlEscape(digest.StartTime.Format(time.RFC3339)),
+		htmlEscape(digest.EndTime.Format(time.RFC3339)),
+	)
+	fmt.Fprintf(&b, `<ul>
+  <li><b>Requests</b>: %d</li>
+  <li><b>Tokens</b>: %d</li>
+  <li><b>Spend</b>: $%.4f (prev $%.4f, %s)</li>
+  <li><b>Errors (SLA)</b>: %d (prev %d, %s)</li>
+</ul>
+`,
+		digest.TotalRequests,
+		digest.TotalTokens,
+		digest.ActualCost, digest.PrevActualCost, htmlEscape(opsDigestChangePct(digest.ActualCost, digest.PrevActualCost)),
+		digest.ErrorsTotal, digest.PrevErrorsTotal, htmlEscape(opsDigestChangePct(float64(digest.ErrorsTotal), float64(digest.PrevErrorsTotal))),
+	)
+
+	rows := ""
+	for _, m := range digest.TopModels {
+		rows += fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>$%.4f</td><td>%.1f%%</td></tr>",
+			htmlEscape(m.Model), m.Requests, m.TotalTokens, m.ActualCost, m.SharePct)
+	}
+	writeOpsDigestTable(&b, "Top Models", []string{"Model", "Requests", "Tokens", "Spend", "Share"}, rows)
+
+	rows = ""
+	for _, d := range digest.ErrorTrend {
+		rows += fmt.Sprintf("<tr><td>%s</td><td>%d</td></tr>", htmlEscape(d.Date), d.Errors)
+	}
+	writeOpsDigestTable(&b, "Error Trend", []string{"Date", "Errors"}, rows)
+
+	rows = ""
+	for _, a := range digest.AccountsNearQuota {
+		rows += fmt.Sprintf("<tr><td>#%d %s</td><td>%s</td><td>%s</td><td>$%.2f / $%.2f</td><td>%.1f%%</td></tr>",
+			a.AccountID, htmlEscape(a.AccountName), htmlEscape(a.Platform), htmlEscape(a.Dimension), a.Used, a.Limit, a.UsedPct)
+	}
+	writeOpsDigestTable(&b, "Accounts Nearing Quota", []string{"Account", "Platform", "Window", "Used", "Used %"}, rows)
+
+	rows = ""
+	for _, a := range digest.ExpiringAccounts {
+		rows += fmt.Sprintf("<tr><td>#%d %s</td><td>%s</td><td>%s</td></tr>",
+			a.AccountID, htmlEscape(a.AccountName), htmlEscape(a.Platform), htmlEscape(a.ExpiresAt.Format(time.RFC3339)))
+	}
+	writeOpsDigestTable(&b, "Expiring Accounts", []string{"Account", "Platform", "Expires At"}, rows)
+
+	if len(digest.Warnings) > 0 {
+		fmt.Fprintf(&b, "<p><i>Partial data: %s</i></p>\n", htmlEscape(strings.Join(digest.Warnings, "; ")))
+	}
+	return b.String()
+}
+
+func writeOpsDigestTable(b *strings.Builder, heading string, columns []string, rows string) {
+	head := ""
+	for _, col := range columns {
+		head += "<th>" + htmlEscape(col) + "</th>"
+	}
+	if rows == "" {
+		rows = fmt.Sprintf("<tr><td colspan=\"%d\">None.</td></tr>", len(columns))
+	}
+	fmt.Fprintf(b, `<h3>%s</h3>
+<table border="1" cellpadding="6" cellspacing="0" style="border-collapse:collapse;">
+  <thead><tr>%s</tr></thead>
+  <tbody>%s</tbody>
+</table>
+`, htmlEscape(heading), head, rows)
+}
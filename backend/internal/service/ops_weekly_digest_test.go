@@ -0,0 +1,108 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsDigestAccountsNearQuota(t *testing.T) {
+	accounts := []Account{
+		{ID: 1, Name: "a", Platform: PlatformAnthropic, Extra: map[string]any{"quota_used": 85.0, "quota_limit": 100.0}},
+		{ID: 2, Name: "b", Platform: PlatformOpenAI, Extra: map[string]any{"quota_daily_used": 9.5, "quota_daily_limit": 10.0, "quota_used": 1.0, "quota_limit": 100.0}},
+		{ID: 3, Name: "c", Platform: PlatformGemini, Extra: map[string]any{"quota_used": 50.0}},
+	}
+
+	out := opsDigestAccountsNearQuota(accounts, 80)
+	require.Len(t, out, 2)
+	require.Equal(t, int64(2), out[0].AccountID)
+	require.Equal(t, "daily", out[0].Dimension)
+	require.Equal(t, 95.0, out[0].UsedPct)
+	require.Equal(t, int64(1), out[1].AccountID)
+	require.Equal(t, "total", out[1].Dimension)
+
+	require.Empty(t, opsDigestAccountsNearQuota(accounts, 0), "threshold 0 disables the section")
+}
+
+func TestOpsDigestExpiringAccounts(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	soon := now.Add(48 * time.Hour)
+	later := now.Add(30 * 24 * time.Hour)
+	past := now.Add(-time.Hour)
+	accounts := []Account{
+		{ID: 1, Name: "soon", ExpiresAt: &soon},
+		{ID: 2, Name: "later", ExpiresAt: &later},
+		{ID: 3, Name: "expired", ExpiresAt: &past},
+		{ID: 4, Name: "never"},
+	}
+
+	out := opsDigestExpiringAccounts(accounts, now, 7)
+	require.Len(t, out, 1)
+	require.Equal(t, int64(1), out[0].AccountID)
+}
+
+func TestResolveOpsReportDelivery(t *testing.T) {
+	subs := []*OpsReportSubscription{
+		{UserID: 1, ReportTypes: []string{OpsReportTypeWeeklyDigest}, Email: true, WebhookURL: "https://hooks.example.com/a"},
+		{UserID: 2, ReportTypes: []string{OpsReportTypeDailySummary}, Email: true},
+		{UserID: 3, ReportTypes: []string{OpsReportTypeWeeklyDigest}, Email: false, WebhookURL: "https://hooks.example.com/c"},
+		{UserID: 4, ReportTypes: []string{OpsReportTypeWeeklyDigest}, Email: true, WebhookURL: "https://hooks.example.com/disabled"},
+	}
+	adminEmails := map[int64]string{
+		1: "alice@example.com",
+		2: "Bob@example.com",
+		3: "carol@example.com",
+	}
+
+	emails, webhooks := resolveOpsReportDelivery(OpsReportTypeWeeklyDigest,
+		[]string{"bob@example.com", "ops@example.com", "carol@example.com"}, subs, adminEmails)
+	require.ElementsMatch(t, []string{"ops@example.com", "alice@example.com"}, emails)
+	require.Equal(t, []string{"https://hooks.example.com/a", "https://hooks.example.com/c"}, webhooks)
+
+	emails, webhooks = resolveOpsReportDelivery(OpsReportTypeErrorDigest, []string{"ops@example.com"}, nil, nil)
+	require.Equal(t, []string{"ops@example.com"}, emails)
+	require.Empty(t, webhooks)
+}
+
+func TestOpsService_ReportSubscriptionCRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := newRuntimeSettingRepoStub()
+	svc := &OpsService{settingRepo: repo}
+
+	sub, err := svc.GetReportSubscription(ctx, 7)
+	require.NoError(t, err)
+	require.Nil(t, sub)
+
+	_, err = svc.UpdateReportSubscription(ctx, 7, &OpsReportSubscriptionInput{ReportTypes: []string{"monthly"}})
+	require.ErrorIs(t, err, ErrOpsReportSubscriptionInvalidType)
+
+	_, err = svc.UpdateReportSubscription(ctx, 7, &OpsReportSubscriptionInput{
+		ReportTypes: []string{OpsReportTypeWeeklyDigest},
+		WebhookURL:  "http://hooks.example.com/x",
+	})
+	require.ErrorIs(t, err, ErrOpsReportSubscriptionInvalidWebhook)
+
+	sub, err = svc.UpdateReportSubscription(ctx, 7, &OpsReportSubscriptionInput{
+		ReportTypes: []string{OpsReportTypeWeeklyDigest, OpsReportTypeDailySummary, OpsReportTypeWeeklyDigest},
+		Email:       true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{OpsReportTypeDailySummary, OpsReportTypeWeeklyDigest}, sub.ReportTypes)
+
+	_, err = svc.UpdateReportSubscription(ctx, 3, &OpsReportSubscriptionInput{ReportTypes: []string{OpsReportTypeErrorDigest}})
+	require.NoError(t, err)
+
+	subs, err := svc.ListReportSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	require.Equal(t, int64(3), subs[0].UserID)
+
+	require.NoError(t, svc.DeleteReportSubscription(ctx, 7))
+	sub, err = svc.GetReportSubscription(ctx, 7)
+	require.NoError(t, err)
+	require.Nil(t, sub)
+}
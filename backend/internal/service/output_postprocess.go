@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 输出后处理规则类型。
+const (
+	OutputPostprocessRuleRegex        = "regex"         // RE2 正则替换，replacement 支持 $1 引用
+	OutputPostprocessRuleBannedPhrase = "banned_phrase" // 大小写不敏感的字面量，按 replacement 原样替换（默认删除）
+)
+
+const (
+	maxOutputPostprocessRules          = 20
+	maxOutputPostprocessPatternLen     = 512
+	maxOutputPostprocessAttributionLen = 2000
+	// outputPostprocessRegexHoldbackRunes 流式输出中为正则规则保留的尾部字符数：
+	// 跨增量边界、长度不超过该值的匹配仍能被完整替换。
+	outputPostprocessRegexHoldbackRunes = 64
+)
+
+// OutputPostprocessor 编译后的分组输出后处理规则。
+type OutputPostprocessor struct {
+	rules       []compiledOutputRule
+	attribution string
+	holdback    int // 流式输出保留的尾部字符数
+}
+
+type compiledOutputRule struct {
+	re          *regexp.Regexp
+	replacement string
+	literal     bool
+}
+
+// normalizeGroupOutputPostprocessConfig 清理并校验输出后处理配置（编译正则以提前暴露语法错误）。
+func normalizeGroupOutputPostprocessConfig(cfg GroupOutputPostprocessConfig) (GroupOutputPostprocessConfig, error) {
+	out := GroupOutputPostprocessConfig{Attribution: strings.TrimSpace(cfg.Attribution)}
+	if utf8.RuneCountInString(out.Attribution) > maxOutputPostprocessAttributionLen {
+		return GroupOutputPostprocessConfig{}, infraerrors.BadRequest("INVALID_OUTPUT_POSTPROCESS",
+			"output postprocess attribution must be at most "+strconv.Itoa(maxOutputPostprocessAttributionLen)+" characters")
+	}
+	for i, rule := range cfg.Rules {
+		rule.Type = strings.TrimSpace(rule.Type)
+		if rule.Pattern == "" {
+			continue
+		}
+		if len(rule.Pattern) > maxOutputPostprocessPatternLen {
+			return GroupOutputPostprocessConfig{}, outputPostprocessRuleError(i, "pattern is too long")
+		}
+		if _, err := compileOutputRule(rule); err != nil {
+			return GroupOutputPostprocessConfig{}, outputPostprocessRuleError(i, err.Error())
+		}
+		out.Rules = append(out.Rules, rule)
+	}
+	if len(out.Rules) > maxOutputPostprocessRules {
+		return GroupOutputPostprocessConfig{}, infraerrors.BadRequest("INVALID_OUTPUT_POSTPROCESS",
+			"output postprocess supports at most "+strconv.Itoa(maxOutputPostprocessRules)+" rules")
+	}
+	return out, nil
+}
+
+func compileOutputRule(rule OutputPostprocessRule) (compiledOutputRule, error) {
+	switch rule.Type {
+	case OutputPostprocessRuleRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return compiledOutputRule{}, fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		return compiledOutputRule{re: re, replacement: rule.Replacement}, nil
+	case OutputPostprocessRuleBannedPhrase:
+		return compiledOutputRule{
+			re:          regexp.MustCompile("(?i)" + regexp.QuoteMeta(rule.Pattern)),
+			replacement: rule.Replacement,
+			literal:     true,
+		}, nil
+	}
+	return compiledOutputRule{}, errors.New("rule type must be regex or banned_phrase")
+}
+
+func outputPostprocessRuleError(i int, reason string) error {
+	return infraerrors.BadRequest("INVALID_OUTPUT_POSTPROCESS", "output postprocess rule "+strconv.Itoa(i)+": "+reason).
+		WithMetadata(map[string]string{"rule": strconv.Itoa(i)})
+}
+
+// NewOutputPostprocessor 编译分组输出后处理配置；未配置规则与署名时返回 nil。
+// 配置在保存时已校验，这里跳过无法编译的规则。
+func NewOutputPostprocessor(cfg GroupOutputPostprocessConfig) *OutputPostprocessor {
+	p := &OutputPostprocessor{attribution: cfg.Attribution}
+	for _, rule := range cfg.Rules {
+		compiled, err := compileOutputRule(rule)
+		if err != nil {
+			continue
+		}
+		p.rules = append(p.rules, compiled)
+		keep := outputPostprocessRegexHoldbackRunes
+		if compiled.literal {
+			keep = utf8.RuneCountInString(rule.Pattern) - 1
+		}
+		p.holdback = max(p.holdback, keep)
+	}
+	if len(p.rules) == 0 && p.attribution == "" {
+		return nil
+	}
+	return p
+}
+
+// outputPostprocessorCacheEntry 分组已编译的后处理器及编译时的配置，配置变化后重新编译。
+type outputPostprocessorCacheEntry struct {
+	cfg GroupOutputPostprocessConfig
+	p   *OutputPostprocessor
+}
+
+var outputPostprocessorCache sync.Map // groupID -> *outputPostprocessorCacheEntry
+
+// OutputPostprocessorForGroup 返回分组的输出后处理器；未配置时返回 nil。
+// 按分组 ID 缓存编译结果，请求路径上只比较配置而不重新编译正则。
+func OutputPostprocessorForGroup(g *Group) *OutputPostprocessor {
+	if g == nil || (len(g.OutputPostprocess.Rules) == 0 && g.OutputPostprocess.Attribution == "") {
+		return nil
+	}
+	if g.ID <= 0 {
+		return NewOutputPostprocessor(g.OutputPostprocess)
+	}
+	if cached, ok := outputPostprocessorCache.Load(g.ID); ok {
+		entry := cached.(*outputPostprocessorCacheEntry)
+		if outputPostprocessConfigEqual(entry.cfg, g.OutputPostprocess) {
+			return entry.p
+		}
+	}
+	entry := &outputPostprocessorCacheEntry{
+		cfg: GroupOutputPostprocessConfig{Rules: slices.Clone(g.OutputPostprocess.Rules), Attribution: g.OutputPostprocess.Attribution},
+		p:   NewOutputPostprocessor(g.OutputPostprocess),
+	}
+	outputPostprocessorCache.Store(g.ID, entry)
+	return entry.p
+}
+
+func outputPostprocessConfigEqual(a, b GroupOutputPostprocessConfig) bool {
+	return a.Attribution == b.Attribution && slices.Equal(a.Rules, b.Rules)
+}
+
+// Apply 按顺序对完整文本应用全部替换规则。
+func (p *OutputPostprocessor) Apply(text string) string {
+	if p == nil || text == "" {
+		return text
+	}
+	for _, rule := range p.rules {
+		if rule.literal {
+			text = rule.re.ReplaceAllLiteralString(text, rule.replacement)
+		} else {
+			text = rule.re.ReplaceAllString(text, rule.replacement)
+		}
+	}
+	return text
+}
+
+// Attribution 返回需要追加到完整响应末尾的署名文本（可能为空）。
+func (p *OutputPostprocessor) Attribution() string {
+	if p == nil {
+		return ""
+	}
+	return p.attribution
+}
+
+// NewStream 为一段流式文本（如一个 content block）创建增量处理状态。
+func (p *OutputPostprocessor) NewStream() *OutputPostprocessStream {
+	return &OutputPostprocessStream{p: p}
+}
+
+// OutputPostprocessStream 流式文本的增量后处理：每次只输出已确定不会再参与匹配的前缀，
+// 尾部 holdback 个字符及跨越切分点的匹配留待后续增量，保证替换结果与整段处理一致且不打断逐字渲染。
+type OutputPostprocessStream struct {
+	p       *OutputPostprocessor
+	pending string
+}
+
+// Push 追加一段增量文本，返回当前可安全输出的已处理文本（可能为空）。
+func (s *OutputPostprocessStream) Push(delta string) string {
+	if s == nil || s.p == nil {
+		return delta
+	}
+	s.pending += delta
+	if len(s.p.rules) == 0 {
+		out := s.pending
+		s.pending = ""
+		return out
+	}
+
+	cut := len(s.pending)
+	for kept := 0; kept < s.p.holdback && cut > 0; kept++ {
+		_, size := utf8.DecodeLastRuneInString(s.pending[:cut])
+		cut -= size
+	}
+	// 切分点落在某个匹配内部时，把匹配整体留到下一轮
+	for changed := true; changed && cut > 0; {
+		changed = false
+		for _, rule := range s.p.rules {
+			for _, m := range rule.re.FindAllStringIndex(s.pending, -1) {
+				if m[0] < cut && m[1] > cut {
+					cut = m[0]
+					changed = true
+				}
+			}
+		}
+	}
+	if cut <= 0 {
+		return ""
+	}
+	out := s.p.Apply(s.pending[:cut])
+	s.pending = s.pending[cut:]
+	return out
+}
+
+// Flush 在文本结束时输出剩余的已处理文本。
+func (s *OutputPostprocessStream) Flush() string {
+	if s == nil || s.p == nil {
+		return ""
+	}
+	out := s.p.Apply(s.pending)
+	s.pending = ""
+	return out
+}
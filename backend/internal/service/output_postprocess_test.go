@@ -0,0 +1,95 @@
+//go:build unit
+
+package service
+
+import (
+	"strings"
+	"testing"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeGroupOutputPostprocessConfig(t *testing.T) {
+	out, err := normalizeGroupOutputPostprocessConfig(GroupOutputPostprocessConfig{
+		Rules: []OutputPostprocessRule{
+			{Type: " regex ", Pattern: `\[(\d+)\]`, Replacement: "($1)"},
+			{Type: "banned_phrase", Pattern: ""},
+		},
+		Attribution: "  — via sub2api  ",
+	})
+	require.NoError(t, err)
+	require.Len(t, out.Rules, 1)
+	require.Equal(t, "regex", out.Rules[0].Type)
+	require.Equal(t, "— via sub2api", out.Attribution)
+
+	_, err = normalizeGroupOutputPostprocessConfig(GroupOutputPostprocessConfig{Rules: []OutputPostprocessRule{{Type: "regex", Pattern: "("}}})
+	require.Equal(t, "INVALID_OUTPUT_POSTPROCESS", infraerrors.Reason(err))
+
+	_, err = normalizeGroupOutputPostprocessConfig(GroupOutputPostprocessConfig{Rules: []OutputPostprocessRule{{Type: "lua", Pattern: "x"}}})
+	require.Equal(t, "INVALID_OUTPUT_POSTPROCESS", infraerrors.Reason(err))
+
+	require.Nil(t, NewOutputPostprocessor(GroupOutputPostprocessConfig{}))
+}
+
+func TestOutputPostprocessor_Apply(t *testing.T) {
+	p := NewOutputPostprocessor(GroupOutputPostprocessConfig{Rules: []OutputPostprocessRule{
+		{Type: OutputPostprocessRuleRegex, Pattern: `【(\d+)†source】`, Replacement: "[$1]"},
+		{Type: OutputPostprocessRuleBannedPhrase, Pattern: "As an AI", Replacement: "$x"},
+	}})
+	require.Equal(t, "see [3]. $x model", p.Apply("see 【3†source】. as an ai model"))
+}
+
+func TestOutputPostprocessStream_MatchesAcrossDeltas(t *testing.T) {
+	p := NewOutputPostprocessor(GroupOutputPostprocessConfig{Rules: []OutputPostprocessRule{
+		{Type: OutputPostprocessRuleRegex, Pattern: `【\d+†source】`},
+		{Type: OutputPostprocessRuleBannedPhrase, Pattern: "forbidden word", Replacement: "***"},
+	}})
+	full := strings.Repeat("lorem ipsum 【12†source】 dolor forbidden word sit amet, ", 20)
+
+	for _, size := range []int{1, 3, 7, 50} {
+		s := p.NewStream()
+		var out strings.Builder
+		runes := []rune(full)
+		emitted := 0
+		for i := 0; i < len(runes); i += size {
+			chunk := s.Push(string(runes[i:min(i+size, len(runes))]))
+			if chunk != "" {
+				emitted++
+			}
+			out.WriteString(chunk)
+		}
+		out.WriteString(s.Flush())
+		require.Equal(t, p.Apply(full), out.String(), "chunk size %d", size)
+		require.Greater(t, emitted, 1, "output must stay incremental (chunk size %d)", size)
+	}
+}
+
+func TestOutputPostprocessStream_AttributionOnlyPassesThrough(t *testing.T) {
+	p := NewOutputPostprocessor(GroupOutputPostprocessConfig{Attribution: "via gateway"})
+	s := p.NewStream()
+	require.Equal(t, "hel", s.Push("hel"))
+	require.Equal(t, "lo", s.Push("lo"))
+	require.Empty(t, s.Flush())
+	require.Equal(t, "via gateway", p.Attribution())
+}
+
+func TestOutputPostprocessorForGroup_CachesCompiledRulesPerConfig(t *testing.T) {
+	g := &Group{ID: 9101, OutputPostprocess: GroupOutputPostprocessConfig{Rules: []OutputPostprocessRule{
+		{Type: OutputPostprocessRuleBannedPhrase, Pattern: "secret", Replacement: "***"},
+	}}}
+	p := OutputPostprocessorForGroup(g)
+	require.NotNil(t, p)
+	// 快照每次请求都会重新构造 Group，配置相同则复用编译结果
+	clone := *g
+	clone.OutputPostprocess.Rules = append([]OutputPostprocessRule(nil), g.OutputPostprocess.Rules...)
+	require.Same(t, p, OutputPostprocessorForGroup(&clone))
+
+	clone.OutputPostprocess.Rules[0].Replacement = "[redacted]"
+	updated := OutputPostprocessorForGroup(&clone)
+	require.NotSame(t, p, updated)
+	require.Equal(t, "a [redacted]", updated.Apply("a Secret"))
+
+	require.Nil(t, OutputPostprocessorForGroup(&Group{ID: 9101}))
+	require.Nil(t, OutputPostprocessorForGroup(nil))
+}
@@ -0,0 +1,18 @@
+package service
+
+// OverloadDowngradeHeader 响应头：请求因首选账号过载被降级时，值为客户端原始请求的模型。
+const OverloadDowngradeHeader = "X-Sub2API-Downgraded-From"
+
+// ResolveOverloadFallbackModel 返回请求模型在过载时应降级到的备用模型。
+// 精确匹配优先，其次取最长的末尾 * 通配；无配置、无匹配或备用模型与原模型相同时返回空串。
+func (g *Group) ResolveOverloadFallbackModel(requestedModel string) string {
+	if g == nil {
+		return ""
+	}
+	return resolveGroupModelOverride(g.OverloadFallbackModels, requestedModel)
+}
+
+// normalizeOverloadFallbackModels 清理并校验过载降级映射；nil 原样返回（表示不修改）。
+func normalizeOverloadFallbackModels(m map[string]string) (map[string]string, error) {
+	return normalizeGroupModelOverrides(m, "INVALID_OVERLOAD_FALLBACK_MODELS", "overload fallback")
+}
@@ -0,0 +1,60 @@
+//go:build unit
+
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupResolveOverloadFallbackModel(t *testing.T) {
+	g := &Group{OverloadFallbackModels: map[string]string{
+		"claude-opus-4-1": "claude-sonnet-4-5",
+		"claude-opus-*":   "claude-sonnet-4",
+		"claude-*":        "claude-haiku-4-5",
+		"gpt-5":           "gpt-5",
+	}}
+
+	require.Equal(t, "claude-sonnet-4-5", g.ResolveOverloadFallbackModel("claude-opus-4-1"))
+	require.Equal(t, "claude-sonnet-4", g.ResolveOverloadFallbackModel("claude-opus-4"), "longest wildcard wins")
+	require.Equal(t, "claude-haiku-4-5", g.ResolveOverloadFallbackModel("claude-sonnet-4-5"))
+	require.Empty(t, g.ResolveOverloadFallbackModel("gpt-5"), "self mapping is ignored")
+	require.Empty(t, g.ResolveOverloadFallbackModel("gemini-2.5-pro"))
+	require.Empty(t, (*Group)(nil).ResolveOverloadFallbackModel("claude-opus-4-1"))
+}
+
+func TestNormalizeOverloadFallbackModels(t *testing.T) {
+	out, err := normalizeOverloadFallbackModels(nil)
+	require.NoError(t, err)
+	require.Nil(t, out, "nil means unchanged")
+
+	out, err = normalizeOverloadFallbackModels(map[string]string{})
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Empty(t, out)
+
+	out, err = normalizeOverloadFallbackModels(map[string]string{" claude-opus-* ": " claude-sonnet-4-5 "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"claude-opus-*": "claude-sonnet-4-5"}, out)
+
+	for _, bad := range []map[string]string{
+		{"": "claude-sonnet-4-5"},
+		{"claude-opus-*": " "},
+		{"claude-opus-*": "claude-sonnet-*"},
+		{"claude-opus-4-1": "claude-opus-4-1"},
+	} {
+		_, err := normalizeOverloadFallbackModels(bad)
+		require.Error(t, err)
+		require.Equal(t, "INVALID_OVERLOAD_FALLBACK_MODELS", infraerrors.Reason(err))
+	}
+
+	tooMany := make(map[string]string, maxGroupModelOverrides+1)
+	for i := 0; i <= maxGroupModelOverrides; i++ {
+		tooMany[fmt.Sprintf("model-%d", i)] = "fallback"
+	}
+	_, err = normalizeOverloadFallbackModels(tooMany)
+	require.Error(t, err)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// 请求体 JSON 的字节数粗略折算为输入 token（包含结构开销，偏保守）
+	prepaidBytesPerInputToken = 4
+
+	prepaidReleaseTimeout     = 2 * time.Second
+	prepaidHistoryMaxDays     = 90
+	prepaidHistoryDefaultDays = 30
+)
+
+// prepaidMaxOutputTokenPaths 各协议中声明最大输出 token 的字段。
+var prepaidMaxOutputTokenPaths = []string{
+	"max_tokens",
+	"max_output_tokens",
+	"max_completion_tokens",
+	"generationConfig.maxOutputTokens",
+}
+
+// BalanceReservationCache 余额冻结存储（Redis）。
+// Reserve 必须原子地完成“清理过期冻结 → 计算可用余额 → 判断 → 写入冻结”。
+type BalanceReservationCache interface {
+	// Reserve 以 fallbackBalance 作为余额缓存缺失时的余额；可用余额扣除本次冻结后低于 floor 时拒绝。
+	// 返回是否冻结成功，以及（成功时为冻结后、失败时为冻结前的）可用余额。
+	Reserve(ctx context.Context, userID int64, reservationID string, amount, fallbackBalance, floor float64, ttl time.Duration) (bool, float64, error)
+	Release(ctx context.Context, userID int64, reservationID string) error
+	// Reserved 返回用户当前未过期的冻结总额。
+	Reserved(ctx context.Context, userID int64) (float64, error)
+}
+
+// BalanceDailyFlow 某一天的余额入账与扣费汇总。
+type BalanceDailyFlow struct {
+	Date    string  // YYYY-MM-DD（全局时区）
+	Credits float64 // 充值/兑换/管理员调整（可为负）
+	Debits  float64 // 余额计费请求的实际扣费
+}
+
+// BalanceLedgerRepository 余额流水查询（来源于 redeem_codes 与 usage_logs，不额外落库）。
+type BalanceLedgerRepository interface {
+	ListDailyFlows(ctx context.Context, userID int64, since time.Time, tz string) ([]BalanceDailyFlow, error)
+}
+
+// BalanceSummary 用户余额概览。
+type BalanceSummary struct {
+	Balance   float64 `json:"balance"`
+	Reserved  float64 `json:"reserved"`  // 在途请求冻结的预估费用
+	Available float64 `json:"available"` // balance - reserved
+}
+
+// BalanceHistoryDay 余额日流水，Balance 为当日结束时的余额（由当前余额倒推）。
+type BalanceHistoryDay struct {
+	Date    string  `json:"date"`
+	Credits float64 `json:"credits"`
+	Debits  float64 `json:"debits"`
+	Balance float64 `json:"balance"`
+}
+
+// PrepaidBalanceService 余额预扣：转发前按预估费用冻结余额，用量记录链路按实际 token 扣费后释放冻结，
+// 两者相加即“预扣 + 结算”；请求未产生用量时在 handler 返回时释放。
+type PrepaidBalanceService struct {
+	cfg            *config.Config
+	cache          BalanceReservationCache
+	billingCache   *BillingCacheService
+	billingService *BillingService
+	userRepo       UserRepository
+	ledgerRepo     BalanceLedgerRepository
+	now            func() time.Time
+}
+
+// NewPrepaidBalanceService 创建余额预扣服务。
+func NewPrepaidBalanceService(
+	cfg *config.Config,
+	cache BalanceReservationCache,
+	billingCache *BillingCacheService,
+	billingService *BillingService,
+	userRepo UserRepository,
+	ledgerRepo BalanceLedgerRepository,
+) *PrepaidBalanceService {
+	return &PrepaidBalanceService{
+		cfg:            cfg,
+		cache:          cache,
+		billingCache:   billingCache,
+		billingService: billingService,
+		userRepo:       userRepo,
+		ledgerRepo:     ledgerRepo,
+		now:            timezone.Now,
+	}
+}
+
+func (s *PrepaidBalanceService) enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Billing.Prepaid.Enabled && s.cfg.RunMode != config.RunModeSimple && s.cache != nil
+}
+
+// BalanceReservation 单个请求的余额冻结，Release 幂等且对 nil 安全。
+// 请求产生用量时冻结随用量记录任务移交（见 HandOffBalanceReservation），在实际扣费后才释放，
+// 避免 handler 返回到异步扣费完成之间的空窗期被并发请求重复占用同一笔余额。
+type BalanceReservation struct {
+	cache     BalanceReservationCache
+	userID    int64
+	id        string
+	Amount    float64
+	once      sync.Once
+	handedOff atomic.Bool
+}
+
+// Release 在请求结束且未产生用量时释放冻结；冻结已移交给用量记录任务时为空操作。
+func (r *BalanceReservation) Release() {
+	if r == nil || r.handedOff.Load() {
+		return
+	}
+	r.release()
+}
+
+func (r *BalanceReservation) release() {
+	r.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), prepaidReleaseTimeout)
+		defer cancel()
+		if err := r.cache.Release(ctx, r.userID, r.id); err != nil {
+			// 释放失败的冻结会在 TTL 到期后自动失效
+			slog.Warn("[PrepaidBalance] release reservation failed", "user_id", r.userID, "reservation_id", r.id, "error", err)
+		}
+	})
+}
+
+type balanceReservationContextKey struct{}
+
+// WithBalanceReservation 把冻结挂到请求 context 上，供提交用量记录任务时移交。
+func WithBalanceReservation(ctx context.Context, r *BalanceReservation) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, balanceReservationContextKey{}, r)
+}
+
+// HandOffBalanceReservation 取出请求 context 上的冻结并把释放责任移交给调用方：此后 Release 为空操作，
+// 调用方须在扣费完成（或确定不会扣费）后调用返回的函数。没有冻结或已被移交时返回 nil。
+func HandOffBalanceReservation(ctx context.Context) func() {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(balanceReservationContextKey{}).(*BalanceReservation)
+	if r == nil || !r.handedOff.CompareAndSwap(false, true) {
+		return nil
+	}
+	return r.release
+}
+
+// Reserve 为余额模式的请求冻结预估费用。订阅模式、未开启预扣或预估为 0 时返回 (nil, nil)。
+// 可用余额不足时返回 ErrInsufficientBalance；Redis 故障时放行（余额资格检查已在此前完成）。
+func (s *PrepaidBalanceService) Reserve(ctx context.Context, apiKey *APIKey, subscription *UserSubscription, model string, body []byte) (*BalanceReservation, error) {
+	if !s.enabled() || apiKey == nil || apiKey.User == nil {
+		return nil, nil
+	}
+	if apiKey.Group != nil && apiKey.Group.IsSubscriptionType() && subscription != nil {
+		return nil, nil
+	}
+	amount := s.EstimateCost(apiKey, model, body)
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	userID := apiKey.User.ID
+	balance, err := s.currentBalance(ctx, userID)
+	if err != nil {
+		slog.Warn("[PrepaidBalance] load balance failed, skip reservation", "user_id", userID, "error", err)
+		return nil, nil
+	}
+
+	id := uuid.NewString()
+	ttl := time.Duration(s.cfg.Billing.Prepaid.ReservationTTLSeconds) * time.Second
+	ok, available, err := s.cache.Reserve(ctx, userID, id, amount, balance, s.cfg.Billing.MinimumBalanceReserve, ttl)
+	if err != nil {
+		slog.Warn("[PrepaidBalance] reserve failed, skip reservation", "user_id", userID, "error", err)
+		return nil, nil
+	}
+	if !ok {
+		return nil, ErrInsufficientBalance.WithMetadata(map[string]string{
+			"available": formatPrepaidAmount(available),
+			"required":  formatPrepaidAmount(amount),
+		})
+	}
+	return &BalanceReservation{cache: s.cache, userID: userID, id: id, Amount: amount}, nil
+}
+
+func (s *PrepaidBalanceService) currentBalance(ctx context.Context, userID int64) (float64, error) {
+	if s.billingCache != nil {
+		return s.billingCache.GetUserBalance(ctx, userID)
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return user.Balance, nil
+}
+
+// EstimateCost 按请求体大小与声明的最大输出 token 估算本次请求费用（已含分组倍率），并按配置上下限截断。
+// 模型无定价时退回最小冻结额。
+func (s *PrepaidBalanceService) EstimateCost(apiKey *APIKey, model string, body []byte) float64 {
+	prepaid := s.cfg.Billing.Prepaid
+	outputTokens := prepaid.DefaultOutputTokens
+	for _, path := range prepaidMaxOutputTokenPaths {
+		if v := gjson.GetBytes(body, path); v.Exists() && v.Int() > 0 {
+			outputTokens = int(v.Int())
+			break
+		}
+	}
+	tokens := UsageTokens{
+		InputTokens:  len(body) / prepaidBytesPerInputToken,
+		OutputTokens: outputTokens,
+	}
+
+	multiplier := s.cfg.Default.RateMultiplier
+	if apiKey != nil && apiKey.Group != nil {
+		multiplier = apiKey.Group.RateMultiplier
+	}
+
+	estimate := prepaid.MinReservation
+	if s.billingService != nil && model != "" {
+		if cost, err := s.billingService.CalculateCost(model, tokens, multiplier); err == nil && cost.ActualCost > estimate {
+			estimate = cost.ActualCost
+		}
+	}
+	if prepaid.MaxReservation > 0 && estimate > prepaid.MaxReservation {
+		estimate = prepaid.MaxReservation
+	}
+	return estimate
+}
+
+// GetSummary 返回用户余额、在途冻结与可用余额。
+func (s *PrepaidBalanceService) GetSummary(ctx context.Context, userID int64) (*BalanceSummary, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &BalanceSummary{Balance: user.Balance, Available: user.Balance}
+	if s.cache != nil {
+		if reserved, err := s.cache.Reserved(ctx, userID); err == nil {
+			summary.Reserved = reserved
+			summary.Available = user.Balance - reserved
+		}
+	}
+	return summary, nil
+}
+
+// GetHistory 返回最近 days 天（含今天）的余额日流水，按日期倒序；无流水的日期也会返回以便绘制余额曲线。
+func (s *PrepaidBalanceService) GetHistory(ctx context.Context, userID int64, days int) ([]BalanceHistoryDay, error) {
+	if days <= 0 {
+		days = prepaidHistoryDefaultDays
+	}
+	if days > prepaidHistoryMaxDays {
+		days = prepaidHistoryMaxDays
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := timezone.StartOfDay(s.now())
+	since := today.AddDate(0, 0, -(days - 1))
+	flows, err := s.ledgerRepo.ListDailyFlows(ctx, userID, since, timezone.Name())
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]BalanceDailyFlow, len(flows))
+	for _, f := range flows {
+		byDate[f.Date] = f
+	}
+
+	out := make([]BalanceHistoryDay, 0, days)
+	balance := user.Balance
+	for day := today; !day.Before(since); day = day.AddDate(0, 0, -1) {
+		date := day.Format("2006-01-02")
+		f := byDate[date]
+		out = append(out, BalanceHistoryDay{Date: date, Credits: f.Credits, Debits: f.Debits, Balance: balance})
+		// 倒推前一天结束时的余额
+		balance = balance - f.Credits + f.Debits
+	}
+	return out, nil
+}
+
+func formatPrepaidAmount(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
@@ -0,0 +1,200 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/stretchr/testify/require"
+)
+
+type balanceReservationCacheFake struct {
+	balanceKey map[int64]float64 // 模拟 Redis 余额缓存；缺失时使用 fallback
+	holds      map[string]float64
+	reserveErr error
+	released   []string
+}
+
+func newBalanceReservationCacheFake() *balanceReservationCacheFake {
+	return &balanceReservationCacheFake{balanceKey: map[int64]float64{}, holds: map[string]float64{}}
+}
+
+func (f *balanceReservationCacheFake) Reserve(_ context.Context, userID int64, id string, amount, fallback, floor float64, _ time.Duration) (bool, float64, error) {
+	if f.reserveErr != nil {
+		return false, 0, f.reserveErr
+	}
+	balance, ok := f.balanceKey[userID]
+	if !ok {
+		balance = fallback
+	}
+	reserved, _ := f.Reserved(context.Background(), userID)
+	available := balance - reserved
+	if available-amount < floor {
+		return false, available, nil
+	}
+	f.holds[id] = amount
+	return true, available - amount, nil
+}
+
+func (f *balanceReservationCacheFake) Release(_ context.Context, _ int64, id string) error {
+	f.released = append(f.released, id)
+	delete(f.holds, id)
+	return nil
+}
+
+func (f *balanceReservationCacheFake) Reserved(context.Context, int64) (float64, error) {
+	var sum float64
+	for _, v := range f.holds {
+		sum += v
+	}
+	return sum, nil
+}
+
+type balanceLedgerRepoFake struct {
+	flows []BalanceDailyFlow
+	since time.Time
+}
+
+func (f *balanceLedgerRepoFake) ListDailyFlows(_ context.Context, _ int64, since time.Time, _ string) ([]BalanceDailyFlow, error) {
+	f.since = since
+	return f.flows, nil
+}
+
+func newPrepaidBalanceServiceForTest(cache BalanceReservationCache, ledger BalanceLedgerRepository, balance float64) *PrepaidBalanceService {
+	cfg := &config.Config{}
+	cfg.Default.RateMultiplier = 1
+	cfg.Billing.Prepaid = config.BillingPrepaidConfig{
+		Enabled:               true,
+		DefaultOutputTokens:   1000,
+		MinReservation:        0.001,
+		MaxReservation:        1,
+		ReservationTTLSeconds: 60,
+	}
+	return NewPrepaidBalanceService(cfg, cache, nil, NewBillingService(cfg, nil), &userRepoStub{user: &User{ID: 7, Balance: balance}}, ledger)
+}
+
+func prepaidTestAPIKey(group *Group) *APIKey {
+	return &APIKey{ID: 1, User: &User{ID: 7}, Group: group}
+}
+
+func TestPrepaidBalanceService_EstimateCostUsesDeclaredOutputAndClamps(t *testing.T) {
+	svc := newPrepaidBalanceServiceForTest(newBalanceReservationCacheFake(), nil, 10)
+	body := []byte(`{"model":"claude-sonnet-4","max_tokens":2000}`)
+
+	expected, err := svc.billingService.CalculateCost("claude-sonnet-4", UsageTokens{InputTokens: len(body) / 4, OutputTokens: 2000}, 2)
+	require.NoError(t, err)
+	got := svc.EstimateCost(prepaidTestAPIKey(&Group{RateMultiplier: 2}), "claude-sonnet-4", body)
+	require.InDelta(t, expected.ActualCost, got, 1e-12)
+
+	// 超出上限截断，未知模型退回最小冻结额
+	require.Equal(t, 1.0, svc.EstimateCost(prepaidTestAPIKey(nil), "claude-sonnet-4", []byte(`{"max_tokens":1000000}`)))
+	require.Equal(t, 0.001, svc.EstimateCost(prepaidTestAPIKey(nil), "", body))
+}
+
+func TestPrepaidBalanceService_ReserveRejectsWhenInFlightHoldsExhaustBalance(t *testing.T) {
+	cache := newBalanceReservationCacheFake()
+	svc := newPrepaidBalanceServiceForTest(cache, nil, 0.05)
+	ctx := context.Background()
+	body := []byte(`{"max_tokens":2000}`) // ≈ 0.03 USD
+
+	first, err := svc.Reserve(ctx, prepaidTestAPIKey(nil), nil, "claude-sonnet-4", body)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+
+	_, err = svc.Reserve(ctx, prepaidTestAPIKey(nil), nil, "claude-sonnet-4", body)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.NotEmpty(t, infraerrors.FromError(err).Metadata["required"])
+
+	first.Release()
+	first.Release()
+	require.Len(t, cache.released, 1, "release is idempotent")
+
+	second, err := svc.Reserve(ctx, prepaidTestAPIKey(nil), nil, "claude-sonnet-4", body)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+}
+
+func TestBalanceReservation_HandOffKeepsHoldUntilBilled(t *testing.T) {
+	cache := newBalanceReservationCacheFake()
+	svc := newPrepaidBalanceServiceForTest(cache, nil, 1)
+	r, err := svc.Reserve(context.Background(), prepaidTestAPIKey(nil), nil, "claude-sonnet-4", []byte(`{"max_tokens":2000}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	ctx := WithBalanceReservation(context.Background(), r)
+
+	release := HandOffBalanceReservation(ctx)
+	require.NotNil(t, release)
+	require.Nil(t, HandOffBalanceReservation(ctx), "hand-off happens once")
+
+	// handler 返回时的 Release 不再释放，冻结保留到用量记录任务扣费之后
+	r.Release()
+	require.Empty(t, cache.released)
+	require.Len(t, cache.holds, 1)
+
+	release()
+	release()
+	require.Len(t, cache.released, 1)
+	require.Empty(t, cache.holds)
+
+	require.Nil(t, HandOffBalanceReservation(context.Background()))
+	require.Equal(t, context.Background(), WithBalanceReservation(context.Background(), nil))
+}
+
+func TestPrepaidBalanceService_ReserveSkipsSubscriptionAndFailsOpen(t *testing.T) {
+	cache := newBalanceReservationCacheFake()
+	svc := newPrepaidBalanceServiceForTest(cache, nil, 0)
+	ctx := context.Background()
+
+	group := &Group{SubscriptionType: SubscriptionTypeSubscription, RateMultiplier: 1}
+	r, err := svc.Reserve(ctx, prepaidTestAPIKey(group), &UserSubscription{ID: 1}, "claude-sonnet-4", nil)
+	require.NoError(t, err)
+	require.Nil(t, r)
+
+	cache.reserveErr = errors.New("redis down")
+	r, err = svc.Reserve(ctx, prepaidTestAPIKey(nil), nil, "claude-sonnet-4", nil)
+	require.NoError(t, err)
+	require.Nil(t, r)
+	r.Release() // nil 安全
+
+	svc.cfg.Billing.Prepaid.Enabled = false
+	cache.reserveErr = nil
+	r, err = svc.Reserve(ctx, prepaidTestAPIKey(nil), nil, "claude-sonnet-4", nil)
+	require.NoError(t, err)
+	require.Nil(t, r)
+}
+
+func TestPrepaidBalanceService_GetHistoryDerivesRunningBalance(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, timezone.Location())
+	ledger := &balanceLedgerRepoFake{flows: []BalanceDailyFlow{
+		{Date: "2026-10-14", Credits: 0, Debits: 2},
+		{Date: "2026-10-12", Credits: 10, Debits: 1},
+	}}
+	svc := newPrepaidBalanceServiceForTest(newBalanceReservationCacheFake(), ledger, 20)
+	svc.now = func() time.Time { return now }
+
+	days, err := svc.GetHistory(context.Background(), 7, 4)
+	require.NoError(t, err)
+	require.Equal(t, timezone.StartOfDay(now).AddDate(0, 0, -3), ledger.since)
+	require.Equal(t, []BalanceHistoryDay{
+		{Date: "2026-10-14", Debits: 2, Balance: 20},
+		{Date: "2026-10-13", Balance: 22},
+		{Date: "2026-10-12", Credits: 10, Debits: 1, Balance: 22},
+		{Date: "2026-10-11", Balance: 13},
+	}, days)
+}
+
+func TestPrepaidBalanceService_GetSummarySubtractsReserved(t *testing.T) {
+	cache := newBalanceReservationCacheFake()
+	cache.holds["a"] = 0.25
+	svc := newPrepaidBalanceServiceForTest(cache, nil, 1)
+
+	summary, err := svc.GetSummary(context.Background(), 7)
+	require.NoError(t, err)
+	require.Equal(t, &BalanceSummary{Balance: 1, Reserved: 0.25, Available: 0.75}, summary)
+}
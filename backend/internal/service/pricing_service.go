@@ -125,9 +125,15 @@ type LiteLLMModelPricing struct {
 	SupportsPromptCaching               bool    `json:"supports_prompt_caching"`
 	OutputCostPerImage                  float64 `json:"output_cost_per_image"`       // 图片生成模型每张图片价格
 	OutputCostPerImageToken             float64 `json:"output_cost_per_image_token"` // 图片输出 token 价格
+	InputCostPerSecond                  float64 `json:"input_cost_per_second"`       // 音频转写按输入时长计价（USD/s）
+	InputCostPerCharacter               float64 `json:"input_cost_per_character"`    // 语音合成按输入字符计价（USD/字符）
+	MaxInputTokens                      int     `json:"max_input_tokens,omitempty"`  // 上下文窗口（输入 token 上限），0 表示未知
 
-	// TokenPricingAbsent 表示源数据中 input/output token 价格均缺失（仅有图片价）。
-	// 此类条目只可用于图片计费，token 计费必须回退到 fallback 或 fail-closed，
+	// Capabilities 源数据声明的模型能力（图片/音频/工具/推理），nil 表示源数据未提供能力信息。
+	Capabilities ModelCapabilities `json:"-"`
+
+	// TokenPricingAbsent 表示源数据中 input/output token 价格均缺失（仅有图片价或音频价）。
+	// 此类条目只可用于图片/音频计费，token 计费必须回退到 fallback 或 fail-closed，
 	// 否则 token 流量会被按 $0 计费。零值（false）表示条目具备 token 价格。
 	TokenPricingAbsent bool `json:"-"`
 }
@@ -158,6 +164,13 @@ type LiteLLMRawEntry struct {
 	SupportsPromptCaching               bool     `json:"supports_prompt_caching"`
 	OutputCostPerImage                  *float64 `json:"output_cost_per_image"`
 	OutputCostPerImageToken             *float64 `json:"output_cost_per_image_token"`
+	InputCostPerSecond                  *float64 `json:"input_cost_per_second"`
+	InputCostPerCharacter               *float64 `json:"input_cost_per_character"`
+	MaxInputTokens                      *float64 `json:"max_input_tokens"` // 部分源数据为浮点，按 float 解析避免整条目被跳过
+	SupportsVision                      *bool    `json:"supports_vision"`
+	SupportsAudioInput                  *bool    `json:"supports_audio_input"`
+	SupportsFunctionCalling             *bool    `json:"supports_function_calling"`
+	SupportsReasoning                   *bool    `json:"supports_reasoning"`
 }
 
 // PricingService 动态价格服务
@@ -169,6 +182,10 @@ type PricingService struct {
 	lastUpdated  time.Time
 	localHash    string
 
+	// 手动审批模式：待审批的更新与最近一次被驳回的版本哈希
+	pending      *pricingPendingUpdate
+	rejectedHash string
+
 	// 停止信号
 	stopCh chan struct{}
 	wg     sync.WaitGroup
@@ -249,6 +266,17 @@ func (s *PricingService) checkAndUpdatePricing() error {
 
 	// 检查本地文件是否存在
 	if _, err := os.Stat(pricingFile); os.IsNotExist(err) {
+		if s.manualApproval() {
+			// 手动审批模式下远程数据不能直接生效，先以回退文件启动，远程数据暂存待审批
+			logger.LegacyPrintf("service.pricing", "%s", "[Pricing] Local pricing file not found, starting from fallback (manual approval mode)")
+			if err := s.useFallbackPricing(); err != nil {
+				return err
+			}
+			if err := s.downloadPricingData(); err != nil {
+				logger.LegacyPrintf("service.pricing", "[Pricing] Download failed: %v", err)
+			}
+			return nil
+		}
 		logger.LegacyPrintf("service.pricing", "%s", "[Pricing] Local pricing file not found, downloading...")
 		return s.downloadPricingData()
 	}
@@ -271,7 +299,7 @@ func (s *PricingService) checkAndUpdatePricing() error {
 		localHash := s.localHash
 		s.mu.RUnlock()
 
-		if localHash == "" || remoteHash != localHash {
+		if (localHash == "" || remoteHash != localHash) && !s.remoteHashHandled(remoteHash) {
 			logger.LegacyPrintf("service.pricing", "[Pricing] Remote hash differs on startup (local=%s remote=%s), downloading...",
 				localHash[:min(8, len(localHash))], remoteHash[:min(8, len(remoteHash))])
 			if err := s.downloadPricingData(); err != nil {
@@ -314,7 +342,7 @@ func (s *PricingService) syncWithRemote() error {
 		localHash := s.localHash
 		s.mu.RUnlock()
 
-		if localHash == "" || remoteHash != localHash {
+		if (localHash == "" || remoteHash != localHash) && !s.remoteHashHandled(remoteHash) {
 			logger.LegacyPrintf("service.pricing", "[Pricing] Remote hash differs (local=%s remote=%s), downloading new version...",
 				localHash[:min(8, len(localHash))], remoteHash[:min(8, len(remoteHash))])
 			return s.downloadPricingData()
@@ -375,6 +403,11 @@ func (s *PricingService) downloadPricingData() error {
 			remoteHash[:min(8, len(remoteHash))], dataHashStr[:8])
 	}
 
+	// 配置了签名公钥时，未通过签名校验的数据一律不采用
+	if err := s.verifyPricingSignature(ctx, body); err != nil {
+		return err
+	}
+
 	// 解析JSON数据（使用灵活的解析方式）
 	data, err := s.parsePricingData(body)
 	if err != nil {
@@ -382,30 +415,19 @@ func (s *PricingService) downloadPricingData() error {
 	}
 	data = s.mergeFallbackPricingData(data)
 
-	// 保存到本地文件
-	pricingFile := s.getPricingFilePath()
-	if err := os.WriteFile(pricingFile, body, 0644); err != nil {
-		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to save file: %v", err)
-	}
-
 	// 使用远程哈希作为同步锚点，防止重复下载
 	// 当远程哈希不可用时，回退到数据本身的哈希
 	syncHash := dataHashStr
 	if remoteHash != "" {
 		syncHash = remoteHash
 	}
-	hashFile := s.getHashFilePath()
-	if err := os.WriteFile(hashFile, []byte(syncHash+"\n"), 0644); err != nil {
-		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to save hash: %v", err)
-	}
 
-	// 更新内存数据
-	s.mu.Lock()
-	s.pricingData = data
-	s.lastUpdated = time.Now()
-	s.localHash = syncHash
-	s.mu.Unlock()
+	if s.manualApproval() {
+		s.stagePendingPricing(body, data, syncHash)
+		return nil
+	}
 
+	s.applyPricingData(body, data, syncHash)
 	logger.LegacyPrintf("service.pricing", "[Pricing] Downloaded %d models successfully", len(data))
 	return nil
 }
@@ -435,7 +457,8 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 		}
 
 		// 只保留有有效价格的条目
-		if entry.InputCostPerToken == nil && entry.OutputCostPerToken == nil && entry.OutputCostPerImage == nil && entry.OutputCostPerImageToken == nil {
+		if entry.InputCostPerToken == nil && entry.OutputCostPerToken == nil && entry.OutputCostPerImage == nil && entry.OutputCostPerImageToken == nil &&
+			entry.InputCostPerSecond == nil && entry.InputCostPerCharacter == nil {
 			continue
 		}
 
@@ -489,6 +512,16 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 		if entry.OutputCostPerImageToken != nil {
 			pricing.OutputCostPerImageToken = *entry.OutputCostPerImageToken
 		}
+		if entry.InputCostPerSecond != nil {
+			pricing.InputCostPerSecond = *entry.InputCostPerSecond
+		}
+		if entry.InputCostPerCharacter != nil {
+			pricing.InputCostPerCharacter = *entry.InputCostPerCharacter
+		}
+		if entry.MaxInputTokens != nil && *entry.MaxInputTokens > 0 {
+			pricing.MaxInputTokens = int(*entry.MaxInputTokens)
+		}
+		pricing.Capabilities = capabilitiesFromLiteLLMEntry(&entry)
 
 		result[modelName] = pricing
 	}
@@ -1018,7 +1051,7 @@ func (s *PricingService) GetStatus() map[string]any {
 	}
 }
 
-// ForceUpdate 强制更新
+// ForceUpdate 强制更新（手动审批模式下仅暂存为待审批更新）
 func (s *PricingService) ForceUpdate() error {
 	return s.downloadPricingData()
 }
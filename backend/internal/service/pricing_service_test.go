@@ -25,6 +25,7 @@ func TestParsePricingData_ParsesPriorityAndServiceTierFields(t *testing.T) {
 			"long_context_input_token_threshold": 272000,
 			"long_context_input_cost_multiplier": 2,
 			"long_context_output_cost_multiplier": 1.5,
+			"max_input_tokens": 1050000,
 			"supports_service_tier": true,
 			"supports_prompt_caching": true,
 			"litellm_provider": "openai",
@@ -43,6 +44,7 @@ func TestParsePricingData_ParsesPriorityAndServiceTierFields(t *testing.T) {
 	require.Equal(t, 272000, pricing.LongContextInputTokenThreshold)
 	require.InDelta(t, 2.0, pricing.LongContextInputCostMultiplier, 1e-12)
 	require.InDelta(t, 1.5, pricing.LongContextOutputCostMultiplier, 1e-12)
+	require.Equal(t, 1050000, pricing.MaxInputTokens)
 	require.True(t, pricing.SupportsServiceTier)
 }
 
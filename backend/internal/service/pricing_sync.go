@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+var (
+	ErrPricingNoPendingUpdate     = infraerrors.NotFound("PRICING_NO_PENDING_UPDATE", "no pending pricing update")
+	ErrPricingPendingHashMismatch = infraerrors.Conflict("PRICING_PENDING_HASH_MISMATCH", "pending pricing update has changed, please reload")
+	ErrPricingSignatureInvalid    = infraerrors.BadRequest("PRICING_SIGNATURE_INVALID", "pricing data signature verification failed")
+)
+
+// pricingPendingUpdate 手动审批模式下已下载、已校验但尚未生效的价格数据
+type pricingPendingUpdate struct {
+	body    []byte
+	data    map[string]*LiteLLMModelPricing
+	summary PricingPendingUpdate
+}
+
+// PricingPendingUpdate 待审批价格更新的摘要（相对当前生效数据的差异）
+type PricingPendingUpdate struct {
+	Hash       string    `json:"hash"`
+	FetchedAt  time.Time `json:"fetched_at"`
+	ModelCount int       `json:"model_count"`
+	Added      []string  `json:"added"`
+	Removed    []string  `json:"removed"`
+	Changed    []string  `json:"changed"`
+}
+
+// PricingSyncStatus 价格同步状态
+type PricingSyncStatus struct {
+	ApprovalMode      string                `json:"approval_mode"`
+	SignatureRequired bool                  `json:"signature_required"`
+	ModelCount        int                   `json:"model_count"`
+	LastUpdated       time.Time             `json:"last_updated"`
+	LocalHash         string                `json:"local_hash"`
+	RejectedHash      string                `json:"rejected_hash,omitempty"`
+	Pending           *PricingPendingUpdate `json:"pending,omitempty"`
+}
+
+func (s *PricingService) manualApproval() bool {
+	return s.cfg != nil && s.cfg.Pricing.ApprovalMode == config.PricingApprovalModeManual
+}
+
+// verifyPricingSignature 使用配置的 Ed25519 公钥校验价格数据原文的分离签名。
+// 未配置公钥时不校验；配置后签名缺失或不匹配均拒绝采用该数据。
+func (s *PricingService) verifyPricingSignature(ctx context.Context, body []byte) error {
+	publicKey := strings.TrimSpace(s.cfg.Pricing.PublicKey)
+	if publicKey == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid pricing public key")
+	}
+	signatureURL, err := s.validatePricingURL(s.cfg.Pricing.SignatureURL)
+	if err != nil {
+		return err
+	}
+	signatureText, err := s.remoteClient.FetchHashText(ctx, signatureURL)
+	if err != nil {
+		return fmt.Errorf("fetch pricing signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signatureText))
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(key), body, signature) {
+		return ErrPricingSignatureInvalid
+	}
+	return nil
+}
+
+// remoteHashHandled 远程哈希是否已暂存待审批或已被驳回，避免重复下载同一版本
+func (s *PricingService) remoteHashHandled(remoteHash string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.pending != nil && strings.EqualFold(s.pending.summary.Hash, remoteHash) {
+		return true
+	}
+	return s.rejectedHash != "" && strings.EqualFold(s.rejectedHash, remoteHash)
+}
+
+// applyPricingData 持久化并启用价格数据
+func (s *PricingService) applyPricingData(body []byte, data map[string]*LiteLLMModelPricing, syncHash string) {
+	pricingFile := s.getPricingFilePath()
+	if err := os.WriteFile(pricingFile, body, 0644); err != nil {
+		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to save file: %v", err)
+	}
+	hashFile := s.getHashFilePath()
+	if err := os.WriteFile(hashFile, []byte(syncHash+"\n"), 0644); err != nil {
+		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to save hash: %v", err)
+	}
+
+	s.mu.Lock()
+	s.pricingData = data
+	s.lastUpdated = time.Now()
+	s.localHash = syncHash
+	s.pending = nil
+	s.mu.Unlock()
+}
+
+// stagePendingPricing 暂存待审批的价格数据；同一版本重复下载时不刷新摘要
+func (s *PricingService) stagePendingPricing(body []byte, data map[string]*LiteLLMModelPricing, syncHash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if strings.EqualFold(syncHash, s.localHash) || strings.EqualFold(syncHash, s.rejectedHash) {
+		return
+	}
+	if s.pending != nil && strings.EqualFold(s.pending.summary.Hash, syncHash) {
+		return
+	}
+	summary := diffPricingData(s.pricingData, data)
+	summary.Hash = syncHash
+	summary.FetchedAt = time.Now()
+	summary.ModelCount = len(data)
+	s.pending = &pricingPendingUpdate{body: body, data: data, summary: summary}
+	logger.LegacyPrintf("service.pricing", "[Pricing] Staged pending update %s for approval (added=%d removed=%d changed=%d)",
+		syncHash[:min(8, len(syncHash))], len(summary.Added), len(summary.Removed), len(summary.Changed))
+}
+
+// diffPricingData 计算新旧价格数据的模型差异
+func diffPricingData(current, next map[string]*LiteLLMModelPricing) PricingPendingUpdate {
+	summary := PricingPendingUpdate{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for name, pricing := range next {
+		old, ok := current[name]
+		if !ok {
+			summary.Added = append(summary.Added, name)
+			continue
+		}
+		if !reflect.DeepEqual(old, pricing) {
+			summary.Changed = append(summary.Changed, name)
+		}
+	}
+	for name := range current {
+		if _, ok := next[name]; !ok {
+			summary.Removed = append(summary.Removed, name)
+		}
+	}
+	sort.Strings(summary.Added)
+	sort.Strings(summary.Removed)
+	sort.Strings(summary.Changed)
+	return summary
+}
+
+// GetSyncStatus 获取价格同步状态（含待审批更新摘要）
+func (s *PricingService) GetSyncStatus() PricingSyncStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := PricingSyncStatus{
+		ApprovalMode:      config.PricingApprovalModeAuto,
+		SignatureRequired: strings.TrimSpace(s.cfg.Pricing.PublicKey) != "",
+		ModelCount:        len(s.pricingData),
+		LastUpdated:       s.lastUpdated,
+		LocalHash:         s.localHash,
+		RejectedHash:      s.rejectedHash,
+	}
+	if s.manualApproval() {
+		status.ApprovalMode = config.PricingApprovalModeManual
+	}
+	if s.pending != nil {
+		summary := s.pending.summary
+		status.Pending = &summary
+	}
+	return status
+}
+
+// ApprovePendingPricing 批准待审批的价格更新。hash 非空时必须与当前待审批版本一致，
+// 防止管理员批准的并非其审阅过的版本。
+func (s *PricingService) ApprovePendingPricing(hash string) (*PricingPendingUpdate, error) {
+	s.mu.RLock()
+	pending := s.pending
+	s.mu.RUnlock()
+	if pending == nil {
+		return nil, ErrPricingNoPendingUpdate
+	}
+	if hash = strings.TrimSpace(hash); hash != "" && !strings.EqualFold(hash, pending.summary.Hash) {
+		return nil, ErrPricingPendingHashMismatch
+	}
+
+	s.applyPricingData(pending.body, pending.data, pending.summary.Hash)
+	logger.LegacyPrintf("service.pricing", "[Pricing] Approved pending update %s (%d models)",
+		pending.summary.Hash[:min(8, len(pending.summary.Hash))], len(pending.data))
+	summary := pending.summary
+	return &summary, nil
+}
+
+// RejectPendingPricing 驳回待审批的价格更新；同一版本不会再次暂存，直到远程发布新版本
+func (s *PricingService) RejectPendingPricing(hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == nil {
+		return ErrPricingNoPendingUpdate
+	}
+	if hash = strings.TrimSpace(hash); hash != "" && !strings.EqualFold(hash, s.pending.summary.Hash) {
+		return ErrPricingPendingHashMismatch
+	}
+	s.rejectedHash = s.pending.summary.Hash
+	s.pending = nil
+	logger.LegacyPrintf("service.pricing", "[Pricing] Rejected pending update %s", s.rejectedHash[:min(8, len(s.rejectedHash))])
+	return nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type pricingSyncRemoteStub struct {
+	body      []byte
+	signature string
+}
+
+func (r *pricingSyncRemoteStub) FetchPricingJSON(context.Context, string) ([]byte, error) {
+	return r.body, nil
+}
+
+func (r *pricingSyncRemoteStub) FetchHashText(_ context.Context, url string) (string, error) {
+	if url == "https://pricing.example.com/prices.sig" {
+		return r.signature, nil
+	}
+	sum := sha256.Sum256(r.body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func newPricingSyncTestService(t *testing.T, remote *pricingSyncRemoteStub, mutate func(*config.PricingConfig)) *PricingService {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Security.URLAllowlist.Enabled = false
+	cfg.Pricing = config.PricingConfig{
+		RemoteURL:    "https://pricing.example.com/prices.json",
+		HashURL:      "https://pricing.example.com/prices.sha256",
+		SignatureURL: "https://pricing.example.com/prices.sig",
+		DataDir:      t.TempDir(),
+		ApprovalMode: config.PricingApprovalModeAuto,
+	}
+	if mutate != nil {
+		mutate(&cfg.Pricing)
+	}
+	return NewPricingService(cfg, remote)
+}
+
+func TestPricingSync_SignatureRequiredWhenPublicKeyConfigured(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	body := []byte(`{"claude-sonnet-4-6":{"input_cost_per_token":0.000003,"output_cost_per_token":0.000015}}`)
+	remote := &pricingSyncRemoteStub{body: body, signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(`{}`)))}
+	svc := newPricingSyncTestService(t, remote, func(p *config.PricingConfig) {
+		p.PublicKey = base64.StdEncoding.EncodeToString(pub)
+	})
+
+	require.ErrorIs(t, svc.ForceUpdate(), ErrPricingSignatureInvalid)
+	require.Nil(t, svc.GetModelPricing("claude-sonnet-4-6"))
+	_, statErr := os.Stat(filepath.Join(svc.cfg.Pricing.DataDir, "model_pricing.json"))
+	require.True(t, os.IsNotExist(statErr))
+
+	remote.signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, body))
+	require.NoError(t, svc.ForceUpdate())
+	require.NotNil(t, svc.GetModelPricing("claude-sonnet-4-6"))
+	require.True(t, svc.GetSyncStatus().SignatureRequired)
+}
+
+func TestPricingSync_ManualApprovalStagesUntilApproved(t *testing.T) {
+	remote := &pricingSyncRemoteStub{body: []byte(`{"model-a":{"input_cost_per_token":1},"model-b":{"input_cost_per_token":2}}`)}
+	svc := newPricingSyncTestService(t, remote, func(p *config.PricingConfig) {
+		p.ApprovalMode = config.PricingApprovalModeManual
+	})
+	svc.pricingData = map[string]*LiteLLMModelPricing{
+		"model-a": {InputCostPerToken: 1},
+		"model-c": {InputCostPerToken: 3},
+	}
+	svc.localHash = "current"
+
+	require.NoError(t, svc.syncWithRemote())
+	status := svc.GetSyncStatus()
+	require.Equal(t, config.PricingApprovalModeManual, status.ApprovalMode)
+	require.NotNil(t, status.Pending)
+	require.Equal(t, []string{"model-b"}, status.Pending.Added)
+	require.Equal(t, []string{"model-c"}, status.Pending.Removed)
+	require.Empty(t, status.Pending.Changed)
+	// 暂存期间价格不变
+	require.Nil(t, svc.GetModelPricing("model-b"))
+
+	_, err := svc.ApprovePendingPricing("stale")
+	require.ErrorIs(t, err, ErrPricingPendingHashMismatch)
+
+	approved, err := svc.ApprovePendingPricing(status.Pending.Hash)
+	require.NoError(t, err)
+	require.Equal(t, 2, approved.ModelCount)
+	require.NotNil(t, svc.GetModelPricing("model-b"))
+	require.Nil(t, svc.GetSyncStatus().Pending)
+	require.Equal(t, status.Pending.Hash, svc.GetSyncStatus().LocalHash)
+
+	_, err = svc.ApprovePendingPricing("")
+	require.ErrorIs(t, err, ErrPricingNoPendingUpdate)
+}
+
+func TestPricingSync_RejectedVersionIsNotStagedAgain(t *testing.T) {
+	remote := &pricingSyncRemoteStub{body: []byte(`{"model-a":{"input_cost_per_token":5}}`)}
+	svc := newPricingSyncTestService(t, remote, func(p *config.PricingConfig) {
+		p.ApprovalMode = config.PricingApprovalModeManual
+	})
+	svc.pricingData = map[string]*LiteLLMModelPricing{"model-a": {InputCostPerToken: 1}}
+	svc.localHash = "current"
+
+	require.NoError(t, svc.syncWithRemote())
+	pending := svc.GetSyncStatus().Pending
+	require.NotNil(t, pending)
+	require.Equal(t, []string{"model-a"}, pending.Changed)
+
+	require.NoError(t, svc.RejectPendingPricing(pending.Hash))
+	require.NoError(t, svc.syncWithRemote())
+	require.Nil(t, svc.GetSyncStatus().Pending)
+	require.Equal(t, pending.Hash, svc.GetSyncStatus().RejectedHash)
+	require.InDelta(t, 1, svc.GetModelPricing("model-a").InputCostPerToken, 1e-12)
+
+	// 远程发布新版本后重新进入待审批
+	remote.body = []byte(`{"model-a":{"input_cost_per_token":6}}`)
+	require.NoError(t, svc.syncWithRemote())
+	require.NotNil(t, svc.GetSyncStatus().Pending)
+}
@@ -30,6 +30,7 @@ type RateLimitService struct {
 	settingService        *SettingService
 	tokenCacheInvalidator TokenCacheInvalidator
 	runtimeBlocker        AccountRuntimeBlocker
+	proxyLatencyCache     ProxyLatencyCache
 	usageCacheMu          sync.RWMutex
 	usageCache            map[int64]*geminiUsageCacheEntry
 }
@@ -116,6 +117,11 @@ func (s *RateLimitService) SetAccountRuntimeBlocker(blocker AccountRuntimeBlocke
 	s.runtimeBlocker = blocker
 }
 
+// SetProxyLatencyCache 设置代理质量缓存（可选依赖），用于记录线上请求命中的代理拦截。
+func (s *RateLimitService) SetProxyLatencyCache(cache ProxyLatencyCache) {
+	s.proxyLatencyCache = cache
+}
+
 func (s *RateLimitService) IsOpenAIAdvancedSchedulerStickyWeightedEnabled(ctx context.Context) bool {
 	if s == nil || s.settingService == nil {
 		return false
@@ -178,6 +184,13 @@ func (s *RateLimitService) HandleUpstreamError(ctx context.Context, account *Acc
 		return false
 	}
 
+	// 上游返回的是 Cloudflare challenge / 代理错误页（已被替换为 JSON）：问题在出口 IP / 代理，
+	// 不按状态码（403 会永久禁用账号）处理，只做短暂冷却并记入代理健康。
+	if code := upstreamHTMLErrorCode(responseBody); code != "" {
+		s.handleUpstreamHTMLResponse(ctx, account, statusCode, code, responseBody)
+		return true
+	}
+
 	// apikey 类型账号：检查自定义错误码配置
 	// 如果启用且错误码不在列表中，则不处理（不停止调度、不标记限流/过载）
 	if !account.ShouldHandleErrorCode(statusCode) {
@@ -1980,6 +1993,43 @@ func parseRetryAfterResetTime(headers http.Header, now time.Time) *time.Time {
 	return nil
 }
 
+// maxClientRetryAfter 回传给客户端的 Retry-After 上限；更远的重置时间（如周窗口）交给客户端自行退避。
+const maxClientRetryAfter = time.Hour
+
+// ClientRetryAfterSeconds 从上游限流/过载响应头推算客户端应等待的秒数（向上取整，封顶 maxClientRetryAfter），
+// 依次识别 Retry-After / retry-after-ms、Codex 用量窗口头与 anthropic-ratelimit-unified-reset；无法确定时返回 0。
+func ClientRetryAfterSeconds(headers http.Header, now time.Time) int {
+	if headers == nil {
+		return 0
+	}
+	var resetAt *time.Time
+	if raw := strings.TrimSpace(headers.Get("retry-after-ms")); raw != "" {
+		if ms, err := strconv.ParseFloat(raw, 64); err == nil && ms > 0 {
+			t := now.Add(time.Duration(ms * float64(time.Millisecond)))
+			resetAt = &t
+		}
+	}
+	if resetAt == nil {
+		resetAt = parseRetryAfterResetTime(headers, now)
+	}
+	if resetAt == nil {
+		resetAt = calculateOpenAI429ResetTime(headers)
+	}
+	if resetAt == nil {
+		if t, ok := parseAnthropicAggregateReset(headers, now); ok {
+			resetAt = &t
+		}
+	}
+	if resetAt == nil || !resetAt.After(now) {
+		return 0
+	}
+	wait := resetAt.Sub(now)
+	if wait > maxClientRetryAfter {
+		wait = maxClientRetryAfter
+	}
+	return int((wait + time.Second - 1) / time.Second)
+}
+
 func parseOpenAIImageTryAgainCooldown(body []byte) time.Duration {
 	if len(body) == 0 {
 		return 0
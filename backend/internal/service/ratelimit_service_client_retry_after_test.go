@@ -0,0 +1,44 @@
+//go:build unit
+
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRetryAfterSeconds(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+
+	require.Zero(t, ClientRetryAfterSeconds(nil, now))
+	require.Zero(t, ClientRetryAfterSeconds(http.Header{}, now))
+
+	h := http.Header{}
+	h.Set("Retry-After", "7")
+	require.Equal(t, 7, ClientRetryAfterSeconds(h, now))
+
+	h = http.Header{}
+	h.Set("retry-after-ms", "1200")
+	h.Set("Retry-After", "30")
+	require.Equal(t, 2, ClientRetryAfterSeconds(h, now), "millisecond hint wins and rounds up")
+
+	h = http.Header{}
+	h.Set("Retry-After", now.Add(90*time.Second).UTC().Format(http.TimeFormat))
+	require.Equal(t, 90, ClientRetryAfterSeconds(h, now))
+
+	h = http.Header{}
+	h.Set("anthropic-ratelimit-unified-reset", strconv.FormatInt(now.Add(45*time.Second).Unix(), 10))
+	require.Equal(t, 45, ClientRetryAfterSeconds(h, now))
+
+	h = http.Header{}
+	h.Set("Retry-After", "86400")
+	require.Equal(t, int(maxClientRetryAfter/time.Second), ClientRetryAfterSeconds(h, now))
+
+	h = http.Header{}
+	h.Set("Retry-After", "0")
+	require.Zero(t, ClientRetryAfterSeconds(h, now))
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// 请求分类：交互式请求对首 token 延迟敏感，批量请求对吞吐与完成率敏感，二者使用不同的超时、重试与账号池。
+const (
+	RequestClassInteractive = "interactive"
+	RequestClassBatch       = "batch"
+)
+
+// RequestClassHeader 客户端显式声明请求分类的请求头。
+const RequestClassHeader = "X-Sub2API-Request-Class"
+
+// accountRequestClassExtraKey 账号 extra 中的专属分类字段；设置后账号只服务该分类的请求。
+const accountRequestClassExtraKey = "request_class"
+
+// NormalizeRequestClass 规范化分类值，无法识别时返回空。
+func NormalizeRequestClass(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case RequestClassInteractive:
+		return RequestClassInteractive
+	case RequestClassBatch:
+		return RequestClassBatch
+	default:
+		return ""
+	}
+}
+
+// ResolveRequestClass 确定请求分类：优先采用客户端声明，否则流式请求视为交互、非流式视为批量。
+func ResolveRequestClass(declared string, stream bool) string {
+	if class := NormalizeRequestClass(declared); class != "" {
+		return class
+	}
+	if stream {
+		return RequestClassInteractive
+	}
+	return RequestClassBatch
+}
+
+// WithRequestClass 在 context 中记录请求分类，供调度层与用量记录读取。
+func WithRequestClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, ctxkey.RequestClass, class)
+}
+
+// RequestClassFromContext 读取请求分类；未分类时返回空。
+func RequestClassFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	class, _ := ctx.Value(ctxkey.RequestClass).(string)
+	return class
+}
+
+// RequestClass 返回账号专属的请求分类；未设置时返回空，表示服务所有分类。
+func (a *Account) RequestClass() string {
+	if a == nil || a.Extra == nil {
+		return ""
+	}
+	raw, _ := a.Extra[accountRequestClassExtraKey].(string)
+	return NormalizeRequestClass(raw)
+}
+
+// ServesRequestClass 判断账号是否可服务指定分类的请求。请求未分类或账号未设置专属分类时均放行。
+func (a *Account) ServesRequestClass(class string) bool {
+	if class == "" {
+		return true
+	}
+	accountClass := a.RequestClass()
+	return accountClass == "" || accountClass == class
+}
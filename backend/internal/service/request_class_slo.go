@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const requestClassSLOMaxRange = 31 * 24 * time.Hour
+
+var ErrRequestClassSLOInvalidRange = infraerrors.BadRequest("REQUEST_CLASS_SLO_INVALID_RANGE", "time range must be non-empty and at most 31 days")
+
+// RequestClassSLOFilter 分类 SLO 报表过滤条件
+type RequestClassSLOFilter struct {
+	StartTime time.Time
+	EndTime   time.Time
+	GroupID   int64
+	Model     string
+}
+
+// RequestClassSLOTarget 分类延迟 SLO 目标（毫秒），0 表示不评估
+type RequestClassSLOTarget struct {
+	FirstTokenMs int `json:"first_token_ms"`
+	DurationMs   int `json:"duration_ms"`
+}
+
+// RequestClassLatency 延迟分位数（毫秒），无样本时为空
+type RequestClassLatency struct {
+	Samples int64    `json:"samples"`
+	P50     *float64 `json:"p50"`
+	P95     *float64 `json:"p95"`
+	P99     *float64 `json:"p99"`
+	// WithinTarget 不超过 SLO 目标的样本数（未设置目标时为 0）
+	WithinTarget int64 `json:"within_target"`
+	// Compliance 达标率（0-1），未设置目标或无样本时为空
+	Compliance *float64 `json:"compliance"`
+}
+
+// RequestClassSLOStats 单个分类的延迟统计
+type RequestClassSLOStats struct {
+	Class      string                `json:"class"`
+	Requests   int64                 `json:"requests"`
+	FirstToken RequestClassLatency   `json:"first_token_ms"`
+	Duration   RequestClassLatency   `json:"duration_ms"`
+	Target     RequestClassSLOTarget `json:"target"`
+}
+
+// RequestClassSLOReport 按请求分类拆分的延迟 SLO 报表
+type RequestClassSLOReport struct {
+	StartTime time.Time               `json:"start_time"`
+	EndTime   time.Time               `json:"end_time"`
+	Classes   []*RequestClassSLOStats `json:"classes"`
+}
+
+// RequestClassSLORepository 按请求分类聚合成功请求的延迟。
+type RequestClassSLORepository interface {
+	// AggregateLatency 返回各分类的请求数、延迟分位数与达标样本数。
+	// 未记录分类的历史数据按流式标记归类（与未声明分类时的推断规则一致）。
+	AggregateLatency(ctx context.Context, filter *RequestClassSLOFilter, targets map[string]RequestClassSLOTarget) ([]*RequestClassSLOStats, error)
+}
+
+// RequestClassSLOService 交互 / 批量请求分别统计延迟 SLO
+type RequestClassSLOService struct {
+	repo RequestClassSLORepository
+	cfg  *config.Config
+}
+
+// NewRequestClassSLOService 创建分类 SLO 报表服务
+func NewRequestClassSLOService(repo RequestClassSLORepository, cfg *config.Config) *RequestClassSLOService {
+	return &RequestClassSLOService{repo: repo, cfg: cfg}
+}
+
+// Targets 返回各分类的 SLO 目标
+func (s *RequestClassSLOService) Targets() map[string]RequestClassSLOTarget {
+	targets := map[string]RequestClassSLOTarget{
+		RequestClassInteractive: {},
+		RequestClassBatch:       {},
+	}
+	if s.cfg == nil {
+		return targets
+	}
+	classes := s.cfg.Gateway.RequestClasses
+	targets[RequestClassInteractive] = RequestClassSLOTarget{FirstTokenMs: classes.Interactive.SLOFirstTokenMs, DurationMs: classes.Interactive.SLODurationMs}
+	targets[RequestClassBatch] = RequestClassSLOTarget{FirstTokenMs: classes.Batch.SLOFirstTokenMs, DurationMs: classes.Batch.SLODurationMs}
+	return targets
+}
+
+// Report 生成分类 SLO 报表。两个分类总会出现在结果中（交互在前），无请求时计数为 0。
+func (s *RequestClassSLOService) Report(ctx context.Context, filter RequestClassSLOFilter) (*RequestClassSLOReport, error) {
+	if !filter.EndTime.After(filter.StartTime) || filter.EndTime.Sub(filter.StartTime) > requestClassSLOMaxRange {
+		return nil, ErrRequestClassSLOInvalidRange
+	}
+	targets := s.Targets()
+	rows, err := s.repo.AggregateLatency(ctx, &filter, targets)
+	if err != nil {
+		return nil, err
+	}
+	byClass := make(map[string]*RequestClassSLOStats, len(rows))
+	for _, row := range rows {
+		byClass[row.Class] = row
+	}
+
+	report := &RequestClassSLOReport{StartTime: filter.StartTime, EndTime: filter.EndTime}
+	for _, class := range []string{RequestClassInteractive, RequestClassBatch} {
+		stats, ok := byClass[class]
+		if !ok {
+			stats = &RequestClassSLOStats{Class: class}
+		}
+		stats.Target = targets[class]
+		stats.FirstToken.finalize(stats.Target.FirstTokenMs)
+		stats.Duration.finalize(stats.Target.DurationMs)
+		report.Classes = append(report.Classes, stats)
+	}
+	return report, nil
+}
+
+func (l *RequestClassLatency) finalize(targetMs int) {
+	l.Compliance = nil
+	if targetMs <= 0 {
+		l.WithinTarget = 0
+		return
+	}
+	if l.Samples > 0 {
+		rate := float64(l.WithinTarget) / float64(l.Samples)
+		l.Compliance = &rate
+	}
+}
@@ -0,0 +1,95 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRequestClass(t *testing.T) {
+	require.Equal(t, RequestClassInteractive, ResolveRequestClass("", true))
+	require.Equal(t, RequestClassBatch, ResolveRequestClass("", false))
+	require.Equal(t, RequestClassBatch, ResolveRequestClass(" Batch ", true))
+	require.Equal(t, RequestClassInteractive, ResolveRequestClass("interactive", false))
+	require.Equal(t, RequestClassBatch, ResolveRequestClass("realtime", false), "unknown declarations fall back to inference")
+}
+
+func TestAccountServesRequestClass(t *testing.T) {
+	shared := &Account{}
+	batchOnly := &Account{Extra: map[string]any{"request_class": "batch"}}
+
+	require.True(t, shared.ServesRequestClass(RequestClassInteractive))
+	require.True(t, shared.ServesRequestClass(RequestClassBatch))
+	require.True(t, batchOnly.ServesRequestClass(RequestClassBatch))
+	require.False(t, batchOnly.ServesRequestClass(RequestClassInteractive))
+	require.True(t, batchOnly.ServesRequestClass(""), "unclassified requests may use any account")
+}
+
+func TestIsSchedulableForModelWithContext_RespectsRequestClassPool(t *testing.T) {
+	account := &Account{
+		Platform:    PlatformAnthropic,
+		Status:      StatusActive,
+		Schedulable: true,
+		Extra:       map[string]any{"request_class": "interactive"},
+	}
+
+	require.True(t, account.IsSchedulableForModelWithContext(WithRequestClass(context.Background(), RequestClassInteractive), "claude-sonnet-4-6"))
+	require.False(t, account.IsSchedulableForModelWithContext(WithRequestClass(context.Background(), RequestClassBatch), "claude-sonnet-4-6"))
+}
+
+type requestClassSLORepoStub struct {
+	targets map[string]RequestClassSLOTarget
+	rows    []*RequestClassSLOStats
+}
+
+func (r *requestClassSLORepoStub) AggregateLatency(_ context.Context, _ *RequestClassSLOFilter, targets map[string]RequestClassSLOTarget) ([]*RequestClassSLOStats, error) {
+	r.targets = targets
+	return r.rows, nil
+}
+
+func TestRequestClassSLOService_ReportComputesCompliancePerClass(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.RequestClasses.Interactive.SLOFirstTokenMs = 5000
+	cfg.Gateway.RequestClasses.Batch.SLODurationMs = 300000
+	repo := &requestClassSLORepoStub{rows: []*RequestClassSLOStats{{
+		Class:      RequestClassInteractive,
+		Requests:   10,
+		FirstToken: RequestClassLatency{Samples: 8, WithinTarget: 6},
+		Duration:   RequestClassLatency{Samples: 10, WithinTarget: 10},
+	}}}
+	svc := NewRequestClassSLOService(repo, cfg)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	report, err := svc.Report(context.Background(), RequestClassSLOFilter{StartTime: start, EndTime: start.Add(24 * time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, 5000, repo.targets[RequestClassInteractive].FirstTokenMs)
+	require.Len(t, report.Classes, 2)
+
+	interactive := report.Classes[0]
+	require.Equal(t, RequestClassInteractive, interactive.Class)
+	require.NotNil(t, interactive.FirstToken.Compliance)
+	require.InDelta(t, 0.75, *interactive.FirstToken.Compliance, 1e-9)
+	require.Nil(t, interactive.Duration.Compliance, "no duration target configured for interactive")
+	require.Zero(t, interactive.Duration.WithinTarget)
+
+	batch := report.Classes[1]
+	require.Equal(t, RequestClassBatch, batch.Class)
+	require.Zero(t, batch.Requests)
+	require.Equal(t, 300000, batch.Target.DurationMs)
+	require.Nil(t, batch.Duration.Compliance, "no samples yet")
+}
+
+func TestRequestClassSLOService_ReportRejectsInvalidRange(t *testing.T) {
+	svc := NewRequestClassSLOService(&requestClassSLORepoStub{}, nil)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := svc.Report(context.Background(), RequestClassSLOFilter{StartTime: start, EndTime: start})
+	require.ErrorIs(t, err, ErrRequestClassSLOInvalidRange)
+	_, err = svc.Report(context.Background(), RequestClassSLOFilter{StartTime: start, EndTime: start.Add(32 * 24 * time.Hour)})
+	require.ErrorIs(t, err, ErrRequestClassSLOInvalidRange)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// CapacityReservation 描述当前请求在分组账号并发中的预留容量策略。
+// 由 API Key 认证中间件写入 context，账号槽位获取时据此收紧非关键 Key 的并发上限。
+type CapacityReservation struct {
+	// Ratio 分组为关键 Key 预留的账号并发比例 [0,1)
+	Ratio float64
+	// Critical 当前 Key 是否为关键 Key（可占用预留槽位）
+	Critical bool
+}
+
+// WithCapacityReservation 根据 API Key 及其分组配置写入预留容量策略；未配置预留时原样返回。
+func WithCapacityReservation(ctx context.Context, apiKey *APIKey) context.Context {
+	if ctx == nil || apiKey == nil || apiKey.Group == nil || apiKey.Group.ReservedConcurrencyRatio <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxkey.CapacityReservation, CapacityReservation{
+		Ratio:    apiKey.Group.ReservedConcurrencyRatio,
+		Critical: apiKey.Critical,
+	})
+}
+
+// CapacityReservationFromContext 读取当前请求的预留容量策略。
+func CapacityReservationFromContext(ctx context.Context) (CapacityReservation, bool) {
+	if ctx == nil {
+		return CapacityReservation{}, false
+	}
+	reservation, ok := ctx.Value(ctxkey.CapacityReservation).(CapacityReservation)
+	return reservation, ok
+}
+
+// ReservedAccountSlots 返回账号并发中为关键 Key 预留的槽位数。
+// 按 floor 取整：并发较小的账号（如 1）不会被预留完全占满，非关键 Key 始终至少保留 1 个槽位。
+func ReservedAccountSlots(maxConcurrency int, ratio float64) int {
+	if maxConcurrency <= 0 || ratio <= 0 || ratio >= 1 {
+		return 0
+	}
+	return int(math.Floor(float64(maxConcurrency) * ratio))
+}
+
+// effectiveAccountConcurrency 返回当前请求可使用的账号并发上限：关键 Key 可用满，其余 Key 扣除预留槽位。
+func effectiveAccountConcurrency(ctx context.Context, maxConcurrency int) int {
+	reservation, ok := CapacityReservationFromContext(ctx)
+	if !ok || reservation.Critical {
+		return maxConcurrency
+	}
+	return maxConcurrency - ReservedAccountSlots(maxConcurrency, reservation.Ratio)
+}
+
+func validateReservedConcurrencyRatio(ratio float64) error {
+	if math.IsNaN(ratio) || ratio < 0 || ratio >= 1 {
+		return infraerrors.BadRequest("INVALID_RESERVED_CONCURRENCY_RATIO", "reserved_concurrency_ratio must be in [0, 1)")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservedAccountSlots(t *testing.T) {
+	require.Equal(t, 0, ReservedAccountSlots(10, 0))
+	require.Equal(t, 2, ReservedAccountSlots(10, 0.2))
+	require.Equal(t, 2, ReservedAccountSlots(10, 0.25))
+	// 小并发账号向下取整，非关键 Key 仍保留槽位
+	require.Equal(t, 0, ReservedAccountSlots(1, 0.5))
+	require.Equal(t, 1, ReservedAccountSlots(3, 0.5))
+	// 非法比例不预留
+	require.Equal(t, 0, ReservedAccountSlots(10, 1))
+	require.Equal(t, 0, ReservedAccountSlots(10, -0.1))
+	require.Equal(t, 0, ReservedAccountSlots(0, 0.5))
+}
+
+func TestWithCapacityReservation(t *testing.T) {
+	ctx := context.Background()
+
+	// 未配置预留：context 不写入
+	got := WithCapacityReservation(ctx, &APIKey{Group: &Group{}})
+	_, ok := CapacityReservationFromContext(got)
+	require.False(t, ok)
+	_, ok = CapacityReservationFromContext(WithCapacityReservation(ctx, &APIKey{}))
+	require.False(t, ok)
+
+	got = WithCapacityReservation(ctx, &APIKey{Critical: true, Group: &Group{ReservedConcurrencyRatio: 0.3}})
+	reservation, ok := CapacityReservationFromContext(got)
+	require.True(t, ok)
+	require.Equal(t, CapacityReservation{Ratio: 0.3, Critical: true}, reservation)
+}
+
+func TestEffectiveAccountConcurrency(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, 10, effectiveAccountConcurrency(ctx, 10))
+
+	bulk := WithCapacityReservation(ctx, &APIKey{Group: &Group{ReservedConcurrencyRatio: 0.2}})
+	require.Equal(t, 8, effectiveAccountConcurrency(bulk, 10))
+	require.Equal(t, 1, effectiveAccountConcurrency(bulk, 1))
+
+	critical := WithCapacityReservation(ctx, &APIKey{Critical: true, Group: &Group{ReservedConcurrencyRatio: 0.2}})
+	require.Equal(t, 10, effectiveAccountConcurrency(critical, 10))
+}
+
+func TestAcquireAccountSlot_HonorsCapacityReservation(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{acquireResult: true}
+	svc := NewConcurrencyService(cache)
+	group := &Group{ReservedConcurrencyRatio: 0.5}
+
+	bulkCtx := WithCapacityReservation(context.Background(), &APIKey{Group: group})
+	_, err := svc.AcquireAccountSlot(bulkCtx, 1, 4)
+	require.NoError(t, err)
+
+	criticalCtx := WithCapacityReservation(context.Background(), &APIKey{Critical: true, Group: group})
+	_, err = svc.AcquireAccountSlot(criticalCtx, 1, 4)
+	require.NoError(t, err)
+
+	require.Equal(t, []int{2, 4}, cache.acquiredAccountMax)
+}
+
+func TestValidateReservedConcurrencyRatio(t *testing.T) {
+	require.NoError(t, validateReservedConcurrencyRatio(0))
+	require.NoError(t, validateReservedConcurrencyRatio(0.5))
+	require.Error(t, validateReservedConcurrencyRatio(1))
+	require.Error(t, validateReservedConcurrencyRatio(-0.1))
+}
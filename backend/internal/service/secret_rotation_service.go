@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 主密钥（totp.encryption_key）加密的存量密文类别。
+const (
+	SecretKindUserTOTP             = "user_totp"
+	SecretKindChannelMonitorAPIKey = "channel_monitor_api_key"
+	SecretKindArchivedCredentials  = "archived_account_credentials"
+	SecretKindBackupS3SecretKey    = "backup_s3_secret_access_key"
+)
+
+const (
+	secretRotationBatchSize = 200
+	// secretRotationMaxReportedIDs 报告中每个类别最多列出的无法解密记录 id
+	secretRotationMaxReportedIDs = 20
+)
+
+// SecretRotationKinds 轮换时依次处理的密文类别
+var SecretRotationKinds = []string{
+	SecretKindUserTOTP,
+	SecretKindChannelMonitorAPIKey,
+	SecretKindArchivedCredentials,
+	SecretKindBackupS3SecretKey,
+}
+
+// ErrSecretRotationUnreadable 存在任何已配置密钥都无法解密的密文，轮换已中止且未写入任何数据。
+var ErrSecretRotationUnreadable = errors.New("some stored secrets cannot be decrypted by any configured key; nothing was written")
+
+// SecretReencryptor 将旧密钥加密的密文重新加密到当前主密钥。
+// 已使用当前密钥的密文原样返回且 rotated=false；无法解密时返回错误。
+type SecretReencryptor interface {
+	Reencrypt(ciphertext string) (sealed string, rotated bool, err error)
+}
+
+// EncryptedSecret 一条存量密文
+type EncryptedSecret struct {
+	ID         int64
+	Ciphertext string
+}
+
+// SecretRotationRepository 按类别读取与替换存量密文。
+type SecretRotationRepository interface {
+	// ListEncryptedSecrets 按 id 游标分批读取指定类别的非空密文
+	ListEncryptedSecrets(ctx context.Context, kind string, afterID int64, limit int) ([]EncryptedSecret, error)
+	// ReplaceEncryptedSecret 仅当当前值仍为 oldCiphertext 时替换为 newCiphertext（比较并交换），
+	// 返回 false 表示该密文已被并发修改。
+	ReplaceEncryptedSecret(ctx context.Context, kind string, id int64, oldCiphertext, newCiphertext string) (bool, error)
+}
+
+// SecretRotationKindReport 单个类别的轮换进度
+type SecretRotationKindReport struct {
+	Kind    string `json:"kind"`
+	Scanned int64  `json:"scanned"`
+	// Rotated 已重新加密（试运行时为待重新加密）的数量
+	Rotated int64 `json:"rotated"`
+	// Current 已使用当前密钥、无需处理的数量
+	Current int64 `json:"current"`
+	// Conflicts 处理期间被并发修改而跳过的数量（新写入的值已使用当前密钥）
+	Conflicts int64 `json:"conflicts"`
+	// Unreadable 任何已配置密钥都无法解密的数量
+	Unreadable    int64   `json:"unreadable"`
+	UnreadableIDs []int64 `json:"unreadable_ids,omitempty"`
+}
+
+// SecretRotationReport 轮换结果
+type SecretRotationReport struct {
+	DryRun bool                        `json:"dry_run"`
+	Kinds  []*SecretRotationKindReport `json:"kinds"`
+}
+
+// Unreadable 返回无法解密的密文总数
+func (r *SecretRotationReport) Unreadable() int64 {
+	var n int64
+	for _, k := range r.Kinds {
+		n += k.Unreadable
+	}
+	return n
+}
+
+// Pending 返回仍使用旧密钥的密文总数（试运行时即待轮换数）
+func (r *SecretRotationReport) Pending() int64 {
+	var n int64
+	for _, k := range r.Kinds {
+		n += k.Rotated
+	}
+	return n
+}
+
+// SecretRotationOptions 轮换选项
+type SecretRotationOptions struct {
+	// DryRun 只校验与统计，不写入
+	DryRun bool
+	// AllowUnreadable 存在无法解密的密文时仍继续轮换其余密文（无法解密的保持原样）
+	AllowUnreadable bool
+	// Progress 每处理完一批后回调当前类别的进度
+	Progress func(*SecretRotationKindReport)
+}
+
+// SecretRotationService 主密钥轮换：在线将存量密文从旧密钥重新加密到新密钥。
+//
+// 轮换期间运行中的实例同时持有新旧密钥（旧密钥仅解密），因此无需停机；
+// 每条密文以比较并交换方式替换，不会覆盖并发写入；写入前先完整校验一遍，
+// 存在无法解密的密文时默认不写入任何数据。中途失败可直接重跑，已轮换的密文会被跳过。
+type SecretRotationService struct {
+	repo        SecretRotationRepository
+	reencryptor SecretReencryptor
+}
+
+// NewSecretRotationService 创建主密钥轮换服务
+func NewSecretRotationService(repo SecretRotationRepository, reencryptor SecretReencryptor) *SecretRotationService {
+	return &SecretRotationService{repo: repo, reencryptor: reencryptor}
+}
+
+// Rotate 执行轮换。非试运行时先完整试运行一遍校验可解密性，再逐类别写入。
+func (s *SecretRotationService) Rotate(ctx context.Context, opts SecretRotationOptions) (*SecretRotationReport, error) {
+	if !opts.DryRun {
+		preflight, err := s.run(ctx, true, nil)
+		if err != nil {
+			return nil, err
+		}
+		if preflight.Unreadable() > 0 && !opts.AllowUnreadable {
+			return preflight, ErrSecretRotationUnreadable
+		}
+	}
+	return s.run(ctx, opts.DryRun, opts.Progress)
+}
+
+func (s *SecretRotationService) run(ctx context.Context, dryRun bool, progress func(*SecretRotationKindReport)) (*SecretRotationReport, error) {
+	report := &SecretRotationReport{DryRun: dryRun}
+	for _, kind := range SecretRotationKinds {
+		kr := &SecretRotationKindReport{Kind: kind}
+		report.Kinds = append(report.Kinds, kr)
+		if err := s.rotateKind(ctx, kr, dryRun, progress); err != nil {
+			return report, fmt.Errorf("rotate %s: %w", kind, err)
+		}
+	}
+	return report, nil
+}
+
+func (s *SecretRotationService) rotateKind(ctx context.Context, kr *SecretRotationKindReport, dryRun bool, progress func(*SecretRotationKindReport)) error {
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch, err := s.repo.ListEncryptedSecrets(ctx, kr.Kind, afterID, secretRotationBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, secret := range batch {
+			afterID = secret.ID
+			kr.Scanned++
+			sealed, rotated, err := s.reencryptor.Reencrypt(secret.Ciphertext)
+			if err != nil {
+				kr.Unreadable++
+				if len(kr.UnreadableIDs) < secretRotationMaxReportedIDs {
+					kr.UnreadableIDs = append(kr.UnreadableIDs, secret.ID)
+				}
+				continue
+			}
+			if !rotated {
+				kr.Current++
+				continue
+			}
+			if dryRun {
+				kr.Rotated++
+				continue
+			}
+			replaced, err := s.repo.ReplaceEncryptedSecret(ctx, kr.Kind, secret.ID, secret.Ciphertext, sealed)
+			if err != nil {
+				return err
+			}
+			if replaced {
+				kr.Rotated++
+			} else {
+				kr.Conflicts++
+			}
+		}
+		if progress != nil && len(batch) > 0 {
+			progress(kr)
+		}
+		if len(batch) < secretRotationBatchSize {
+			return nil
+		}
+	}
+}
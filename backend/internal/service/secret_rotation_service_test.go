@@ -0,0 +1,118 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// prefixReencryptor 以前缀模拟密钥："new:" 为当前密钥，"old:" 为旧密钥，其余无法解密。
+type prefixReencryptor struct{}
+
+func (prefixReencryptor) Reencrypt(ciphertext string) (string, bool, error) {
+	switch {
+	case strings.HasPrefix(ciphertext, "new:"):
+		return ciphertext, false, nil
+	case strings.HasPrefix(ciphertext, "old:"):
+		return "new:" + strings.TrimPrefix(ciphertext, "old:"), true, nil
+	default:
+		return "", false, errors.New("unknown key")
+	}
+}
+
+type secretRotationRepoStub struct {
+	secrets  map[string][]EncryptedSecret
+	replaced map[string]string
+	// conflictIDs 模拟处理期间被并发修改的记录
+	conflictIDs map[int64]bool
+}
+
+func (r *secretRotationRepoStub) ListEncryptedSecrets(_ context.Context, kind string, afterID int64, limit int) ([]EncryptedSecret, error) {
+	out := make([]EncryptedSecret, 0, limit)
+	for _, s := range r.secrets[kind] {
+		if s.ID > afterID && len(out) < limit {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (r *secretRotationRepoStub) ReplaceEncryptedSecret(_ context.Context, kind string, id int64, oldCiphertext, newCiphertext string) (bool, error) {
+	if r.conflictIDs[id] {
+		return false, nil
+	}
+	if r.replaced == nil {
+		r.replaced = map[string]string{}
+	}
+	r.replaced[oldCiphertext] = newCiphertext
+	return true, nil
+}
+
+func TestSecretRotationService_RotatesOnlyPreviousKeySecrets(t *testing.T) {
+	repo := &secretRotationRepoStub{
+		secrets: map[string][]EncryptedSecret{
+			SecretKindUserTOTP:             {{ID: 1, Ciphertext: "old:a"}, {ID: 2, Ciphertext: "new:b"}},
+			SecretKindChannelMonitorAPIKey: {{ID: 7, Ciphertext: "old:c"}, {ID: 8, Ciphertext: "old:d"}},
+		},
+		conflictIDs: map[int64]bool{8: true},
+	}
+	var progress []string
+	svc := NewSecretRotationService(repo, prefixReencryptor{})
+
+	report, err := svc.Rotate(context.Background(), SecretRotationOptions{
+		Progress: func(k *SecretRotationKindReport) { progress = append(progress, k.Kind) },
+	})
+	require.NoError(t, err)
+	require.False(t, report.DryRun)
+	require.Equal(t, map[string]string{"old:a": "new:a", "old:c": "new:c"}, repo.replaced)
+
+	byKind := map[string]*SecretRotationKindReport{}
+	for _, k := range report.Kinds {
+		byKind[k.Kind] = k
+	}
+	require.Equal(t, int64(1), byKind[SecretKindUserTOTP].Rotated)
+	require.Equal(t, int64(1), byKind[SecretKindUserTOTP].Current)
+	require.Equal(t, int64(1), byKind[SecretKindChannelMonitorAPIKey].Rotated)
+	require.Equal(t, int64(1), byKind[SecretKindChannelMonitorAPIKey].Conflicts)
+	require.Equal(t, []string{SecretKindUserTOTP, SecretKindChannelMonitorAPIKey}, progress)
+}
+
+func TestSecretRotationService_DryRunDoesNotWrite(t *testing.T) {
+	repo := &secretRotationRepoStub{secrets: map[string][]EncryptedSecret{
+		SecretKindArchivedCredentials: {{ID: 3, Ciphertext: "old:x"}},
+	}}
+	svc := NewSecretRotationService(repo, prefixReencryptor{})
+
+	report, err := svc.Rotate(context.Background(), SecretRotationOptions{DryRun: true})
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	require.Equal(t, int64(1), report.Pending())
+	require.Empty(t, repo.replaced)
+}
+
+func TestSecretRotationService_UnreadableSecretsBlockWrites(t *testing.T) {
+	repo := &secretRotationRepoStub{secrets: map[string][]EncryptedSecret{
+		SecretKindUserTOTP:          {{ID: 1, Ciphertext: "old:a"}},
+		SecretKindBackupS3SecretKey: {{ID: 9, Ciphertext: "plaintext-legacy"}},
+	}}
+	svc := NewSecretRotationService(repo, prefixReencryptor{})
+
+	report, err := svc.Rotate(context.Background(), SecretRotationOptions{})
+	require.ErrorIs(t, err, ErrSecretRotationUnreadable)
+	require.Equal(t, int64(1), report.Unreadable())
+	require.Empty(t, repo.replaced, "preflight failure must not write anything")
+
+	report, err = svc.Rotate(context.Background(), SecretRotationOptions{AllowUnreadable: true})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"old:a": "new:a"}, repo.replaced)
+	for _, k := range report.Kinds {
+		if k.Kind == SecretKindBackupS3SecretKey {
+			require.Equal(t, []int64{9}, k.UnreadableIDs)
+		}
+	}
+}
@@ -61,4 +61,22 @@ type SessionLimitCache interface {
 	// GetWindowCostBatch 批量获取窗口费用缓存
 	// 返回 map[accountID]cost，缓存未命中的账号不在 map 中
 	GetWindowCostBatch(ctx context.Context, accountIDs []int64) (map[int64]float64, error)
+
+	// ========== 5h窗口费用软预占 ==========
+	// Key 格式: window_cost_hold:account:{accountID}（HASH: reservationID -> 金额）
+	//          window_cost_hold_exp:account:{accountID}（ZSET: reservationID -> 过期时间）
+	// 在途流式请求按预估费用占用窗口额度，用量到达后换成实际费用并保留到窗口费用缓存刷新为止
+
+	// ReserveWindowCost 写入一条预占（不做额度判断）
+	ReserveWindowCost(ctx context.Context, accountID int64, reservationID string, amount float64, ttl time.Duration) error
+
+	// SettleWindowCost 用实际费用替换预占金额，并将有效期缩短到窗口费用缓存的 TTL
+	SettleWindowCost(ctx context.Context, accountID int64, reservationID string, actualCost float64) error
+
+	// ReleaseWindowCost 删除一条预占
+	ReleaseWindowCost(ctx context.Context, accountID int64, reservationID string) error
+
+	// GetReservedWindowCostBatch 批量获取未过期的预占总额
+	// 返回 map[accountID]amount，无预占的账号不在 map 中
+	GetReservedWindowCostBatch(ctx context.Context, accountIDs []int64) (map[int64]float64, error)
 }
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"golang.org/x/sync/singleflight"
+)
+
+// 预算对象类型
+const (
+	SpendBudgetScopeUser   = "user"
+	SpendBudgetScopeGroup  = "group"
+	SpendBudgetScopeAPIKey = "api_key"
+)
+
+// 预算周期（按系统时区的自然日 / 周一起始的自然周 / 自然月）
+const (
+	SpendBudgetPeriodDaily   = "daily"
+	SpendBudgetPeriodWeekly  = "weekly"
+	SpendBudgetPeriodMonthly = "monthly"
+)
+
+const (
+	// spendBudgetIndexTTL 已启用预算列表的本地缓存时长；管理端修改会立即失效本地缓存。
+	spendBudgetIndexTTL = 30 * time.Second
+	// spendBudgetSpendTTL 周期内已消费金额的本地缓存时长。期间本实例的扣费会实时累加，
+	// 其他实例的消费最多延迟该时长被感知。
+	spendBudgetSpendTTL    = 30 * time.Second
+	spendBudgetLoadTimeout = 3 * time.Second
+)
+
+var (
+	ErrSpendBudgetNotFound      = infraerrors.NotFound("SPEND_BUDGET_NOT_FOUND", "spend budget not found")
+	ErrSpendBudgetExists        = infraerrors.Conflict("SPEND_BUDGET_EXISTS", "a spend budget for this scope and period already exists")
+	ErrSpendBudgetInvalidScope  = infraerrors.BadRequest("SPEND_BUDGET_INVALID_SCOPE", "scope_type must be one of: user, group, api_key")
+	ErrSpendBudgetInvalidPeriod = infraerrors.BadRequest("SPEND_BUDGET_INVALID_PERIOD", "period must be one of: daily, weekly, monthly")
+	ErrSpendBudgetInvalidLimit  = infraerrors.BadRequest("SPEND_BUDGET_INVALID_LIMIT", "limit_usd must be greater than 0")
+	// ErrSpendBudgetExhausted 预算耗尽，与 user × platform quota 一致映射为 429 + Retry-After（周期重置时间）。
+	ErrSpendBudgetExhausted = infraerrors.TooManyRequests("SPEND_BUDGET_EXHAUSTED", "Spend budget exhausted.")
+)
+
+// SpendBudget 消费预算
+type SpendBudget struct {
+	ID        int64     `json:"id"`
+	ScopeType string    `json:"scope_type"`
+	ScopeID   int64     `json:"scope_id"`
+	Period    string    `json:"period"`
+	LimitUSD  float64   `json:"limit_usd"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SpendBudgetStatus 预算在当前周期的消费情况
+type SpendBudgetStatus struct {
+	*SpendBudget
+	SpentUSD     float64   `json:"spent_usd"`
+	RemainingUSD float64   `json:"remaining_usd"`
+	PeriodStart  time.Time `json:"period_start"`
+	ResetsAt     time.Time `json:"resets_at"`
+	Exhausted    bool      `json:"exhausted"`
+}
+
+// SpendBudgetRepository 消费预算数据访问接口
+type SpendBudgetRepository interface {
+	Create(ctx context.Context, budget *SpendBudget) (*SpendBudget, error)
+	GetByID(ctx context.Context, id int64) (*SpendBudget, error)
+	Update(ctx context.Context, budget *SpendBudget) (*SpendBudget, error)
+	Delete(ctx context.Context, id int64) error
+	// List 按对象过滤预算，scopeType 为空时返回全部，scopeID <= 0 时不按 ID 过滤。
+	List(ctx context.Context, scopeType string, scopeID int64) ([]*SpendBudget, error)
+	ListEnabled(ctx context.Context) ([]*SpendBudget, error)
+	// SumActualCost 汇总对象自 since 起在 usage_logs 中的实际消费（actual_cost）。
+	SumActualCost(ctx context.Context, scopeType string, scopeID int64, since time.Time) (float64, error)
+}
+
+type spendBudgetScopeKey struct {
+	scopeType string
+	scopeID   int64
+}
+
+type spendBudgetSpendKey struct {
+	scope       spendBudgetScopeKey
+	period      string
+	periodStart int64
+}
+
+type spendBudgetSpend struct {
+	amount   float64
+	loadedAt time.Time
+}
+
+// SpendBudgetService 消费预算服务：管理预算配置，并在请求前按周期内已消费金额执行硬性拦截。
+type SpendBudgetService struct {
+	repo SpendBudgetRepository
+
+	indexMu       sync.RWMutex
+	index         map[spendBudgetScopeKey][]*SpendBudget
+	indexLoadedAt time.Time
+	indexSF       singleflight.Group
+
+	spendMu sync.Mutex
+	spend   map[spendBudgetSpendKey]*spendBudgetSpend
+	spendSF singleflight.Group
+}
+
+// NewSpendBudgetService 创建消费预算服务
+func NewSpendBudgetService(repo SpendBudgetRepository) *SpendBudgetService {
+	return &SpendBudgetService{
+		repo:  repo,
+		spend: make(map[spendBudgetSpendKey]*spendBudgetSpend),
+	}
+}
+
+// spendBudgetPeriodBounds 返回 now 所在周期的起止时间
+func spendBudgetPeriodBounds(period string, now time.Time) (time.Time, time.Time) {
+	switch period {
+	case SpendBudgetPeriodWeekly:
+		start := timezone.StartOfWeek(now)
+		return start, start.AddDate(0, 0, 7)
+	case SpendBudgetPeriodMonthly:
+		start := timezone.StartOfMonth(now)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := timezone.StartOfDay(now)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+func validateSpendBudget(budget *SpendBudget) error {
+	switch budget.ScopeType {
+	case SpendBudgetScopeUser, SpendBudgetScopeGroup, SpendBudgetScopeAPIKey:
+	default:
+		return ErrSpendBudgetInvalidScope
+	}
+	if budget.ScopeID <= 0 {
+		return ErrSpendBudgetInvalidScope
+	}
+	switch budget.Period {
+	case SpendBudgetPeriodDaily, SpendBudgetPeriodWeekly, SpendBudgetPeriodMonthly:
+	default:
+		return ErrSpendBudgetInvalidPeriod
+	}
+	if budget.LimitUSD <= 0 {
+		return ErrSpendBudgetInvalidLimit
+	}
+	return nil
+}
+
+// Create 创建预算
+func (s *SpendBudgetService) Create(ctx context.Context, budget *SpendBudget) (*SpendBudget, error) {
+	if err := validateSpendBudget(budget); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateIndex()
+	return created, nil
+}
+
+// Update 更新预算的额度与启用状态；对象与周期创建后不可修改。
+func (s *SpendBudgetService) Update(ctx context.Context, id int64, limitUSD *float64, enabled *bool) (*SpendBudget, error) {
+	budget, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if limitUSD != nil {
+		budget.LimitUSD = *limitUSD
+	}
+	if enabled != nil {
+		budget.Enabled = *enabled
+	}
+	if err := validateSpendBudget(budget); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateIndex()
+	return updated, nil
+}
+
+// Delete 删除预算
+func (s *SpendBudgetService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateIndex()
+	return nil
+}
+
+// ListStatus 列出预算及其当前周期的消费与剩余额度。已消费金额直接查询 usage_logs，不使用缓存。
+func (s *SpendBudgetService) ListStatus(ctx context.Context, scopeType string, scopeID int64) ([]*SpendBudgetStatus, error) {
+	budgets, err := s.repo.List(ctx, scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := make([]*SpendBudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		start, end := spendBudgetPeriodBounds(budget.Period, now)
+		spent, err := s.repo.SumActualCost(ctx, budget.ScopeType, budget.ScopeID, start)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, newSpendBudgetStatus(budget, spent, start, end))
+	}
+	return out, nil
+}
+
+func newSpendBudgetStatus(budget *SpendBudget, spent float64, start, end time.Time) *SpendBudgetStatus {
+	remaining := budget.LimitUSD - spent
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &SpendBudgetStatus{
+		SpendBudget:  budget,
+		SpentUSD:     spent,
+		RemainingUSD: remaining,
+		PeriodStart:  start,
+		ResetsAt:     end,
+		Exhausted:    spent >= budget.LimitUSD,
+	}
+}
+
+// CheckEligibility 校验请求涉及的用户、分组、API Key 预算，任一已启用预算耗尽即拒绝。
+// 预算或消费数据加载失败时 fail-open（记录告警，不阻塞业务），与其他计费限额保持一致。
+func (s *SpendBudgetService) CheckEligibility(ctx context.Context, userID int64, groupID *int64, apiKeyID int64) error {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	index, err := s.loadIndex(ctx)
+	if err != nil {
+		slog.Warn("spend budget index load failed, skipping budget check", "error", err)
+		return nil
+	}
+	if len(index) == 0 {
+		return nil
+	}
+
+	scopes := []spendBudgetScopeKey{{SpendBudgetScopeAPIKey, apiKeyID}, {SpendBudgetScopeUser, userID}}
+	if groupID != nil {
+		scopes = append(scopes, spendBudgetScopeKey{SpendBudgetScopeGroup, *groupID})
+	}
+	now := time.Now()
+	for _, scope := range scopes {
+		for _, budget := range index[scope] {
+			start, end := spendBudgetPeriodBounds(budget.Period, now)
+			spent, err := s.currentSpend(ctx, scope, budget.Period, start, now)
+			if err != nil {
+				slog.Warn("spend budget usage load failed, skipping budget", "budget_id", budget.ID, "error", err)
+				continue
+			}
+			if spent >= budget.LimitUSD {
+				return spendBudgetExhaustedError(budget, spent, end)
+			}
+		}
+	}
+	return nil
+}
+
+func spendBudgetExhaustedError(budget *SpendBudget, spent float64, resetsAt time.Time) error {
+	scopeLabel := map[string]string{
+		SpendBudgetScopeUser:   "user",
+		SpendBudgetScopeGroup:  "group",
+		SpendBudgetScopeAPIKey: "API key",
+	}[budget.ScopeType]
+	err := infraerrors.Newf(http.StatusTooManyRequests, ErrSpendBudgetExhausted.Reason,
+		"%s spend budget of $%.2f for this %s is exhausted (spent $%.2f); it resets at %s.",
+		spendBudgetPeriodLabel(budget.Period), budget.LimitUSD, scopeLabel, spent, resetsAt.Format(time.RFC3339))
+	return err.WithMetadata(map[string]string{
+		"window_resets_at": resetsAt.Format(time.RFC3339),
+		"scope_type":       budget.ScopeType,
+		"scope_id":         strconv.FormatInt(budget.ScopeID, 10),
+		"period":           budget.Period,
+		"limit_usd":        strconv.FormatFloat(budget.LimitUSD, 'f', -1, 64),
+	})
+}
+
+func spendBudgetPeriodLabel(period string) string {
+	switch period {
+	case SpendBudgetPeriodWeekly:
+		return "Weekly"
+	case SpendBudgetPeriodMonthly:
+		return "Monthly"
+	default:
+		return "Daily"
+	}
+}
+
+// RecordSpend 请求扣费后把实际消费累加到本地已缓存的周期消费上，使硬性拦截在本实例内立即生效。
+func (s *SpendBudgetService) RecordSpend(userID int64, groupID *int64, apiKeyID int64, cost float64) {
+	if s == nil || cost <= 0 {
+		return
+	}
+	scopes := []spendBudgetScopeKey{{SpendBudgetScopeAPIKey, apiKeyID}, {SpendBudgetScopeUser, userID}}
+	if groupID != nil {
+		scopes = append(scopes, spendBudgetScopeKey{SpendBudgetScopeGroup, *groupID})
+	}
+	now := time.Now()
+	s.spendMu.Lock()
+	defer s.spendMu.Unlock()
+	for _, period := range []string{SpendBudgetPeriodDaily, SpendBudgetPeriodWeekly, SpendBudgetPeriodMonthly} {
+		start, _ := spendBudgetPeriodBounds(period, now)
+		for _, scope := range scopes {
+			if entry, ok := s.spend[spendBudgetSpendKey{scope: scope, period: period, periodStart: start.Unix()}]; ok {
+				entry.amount += cost
+			}
+		}
+	}
+}
+
+func (s *SpendBudgetService) invalidateIndex() {
+	s.indexMu.Lock()
+	s.index = nil
+	s.indexLoadedAt = time.Time{}
+	s.indexMu.Unlock()
+}
+
+func (s *SpendBudgetService) loadIndex(ctx context.Context) (map[spendBudgetScopeKey][]*SpendBudget, error) {
+	s.indexMu.RLock()
+	index, loadedAt := s.index, s.indexLoadedAt
+	s.indexMu.RUnlock()
+	if index != nil && time.Since(loadedAt) < spendBudgetIndexTTL {
+		return index, nil
+	}
+
+	v, err, _ := s.indexSF.Do("index", func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), spendBudgetLoadTimeout)
+		defer cancel()
+		budgets, err := s.repo.ListEnabled(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		index := make(map[spendBudgetScopeKey][]*SpendBudget, len(budgets))
+		for _, budget := range budgets {
+			key := spendBudgetScopeKey{budget.ScopeType, budget.ScopeID}
+			index[key] = append(index[key], budget)
+		}
+		s.indexMu.Lock()
+		s.index = index
+		s.indexLoadedAt = time.Now()
+		s.indexMu.Unlock()
+		return index, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[spendBudgetScopeKey][]*SpendBudget), nil
+}
+
+func (s *SpendBudgetService) currentSpend(ctx context.Context, scope spendBudgetScopeKey, period string, periodStart, now time.Time) (float64, error) {
+	key := spendBudgetSpendKey{scope: scope, period: period, periodStart: periodStart.Unix()}
+	s.spendMu.Lock()
+	if entry, ok := s.spend[key]; ok && now.Sub(entry.loadedAt) < spendBudgetSpendTTL {
+		amount := entry.amount
+		s.spendMu.Unlock()
+		return amount, nil
+	}
+	s.spendMu.Unlock()
+
+	sfKey := fmt.Sprintf("%s:%d:%s:%d", scope.scopeType, scope.scopeID, period, key.periodStart)
+	v, err, _ := s.spendSF.Do(sfKey, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), spendBudgetLoadTimeout)
+		defer cancel()
+		amount, err := s.repo.SumActualCost(loadCtx, scope.scopeType, scope.scopeID, periodStart)
+		if err != nil {
+			return nil, err
+		}
+		s.spendMu.Lock()
+		// 清理已过期周期的缓存，避免长时间运行后无界增长
+		for k := range s.spend {
+			if k.scope == scope && k.period == period && k.periodStart != key.periodStart {
+				delete(s.spend, k)
+			}
+		}
+		s.spend[key] = &spendBudgetSpend{amount: amount, loadedAt: time.Now()}
+		s.spendMu.Unlock()
+		return amount, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(float64), nil
+}
@@ -0,0 +1,157 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/stretchr/testify/require"
+)
+
+type spendBudgetRepoStub struct {
+	budgets   []*SpendBudget
+	spent     map[spendBudgetScopeKey]float64
+	sumCalls  int
+	listCalls int
+	sumErr    error
+}
+
+func (r *spendBudgetRepoStub) Create(_ context.Context, budget *SpendBudget) (*SpendBudget, error) {
+	budget.ID = int64(len(r.budgets) + 1)
+	r.budgets = append(r.budgets, budget)
+	return budget, nil
+}
+
+func (r *spendBudgetRepoStub) GetByID(_ context.Context, id int64) (*SpendBudget, error) {
+	for _, b := range r.budgets {
+		if b.ID == id {
+			copied := *b
+			return &copied, nil
+		}
+	}
+	return nil, ErrSpendBudgetNotFound
+}
+
+func (r *spendBudgetRepoStub) Update(_ context.Context, budget *SpendBudget) (*SpendBudget, error) {
+	for i, b := range r.budgets {
+		if b.ID == budget.ID {
+			r.budgets[i] = budget
+		}
+	}
+	return budget, nil
+}
+
+func (r *spendBudgetRepoStub) Delete(context.Context, int64) error { return nil }
+
+func (r *spendBudgetRepoStub) List(context.Context, string, int64) ([]*SpendBudget, error) {
+	return r.budgets, nil
+}
+
+func (r *spendBudgetRepoStub) ListEnabled(context.Context) ([]*SpendBudget, error) {
+	r.listCalls++
+	var out []*SpendBudget
+	for _, b := range r.budgets {
+		if b.Enabled {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (r *spendBudgetRepoStub) SumActualCost(_ context.Context, scopeType string, scopeID int64, _ time.Time) (float64, error) {
+	r.sumCalls++
+	if r.sumErr != nil {
+		return 0, r.sumErr
+	}
+	return r.spent[spendBudgetScopeKey{scopeType, scopeID}], nil
+}
+
+func TestSpendBudgetService_CheckEligibilityRejectsExhaustedScope(t *testing.T) {
+	groupID := int64(3)
+	repo := &spendBudgetRepoStub{
+		budgets: []*SpendBudget{
+			{ID: 1, ScopeType: SpendBudgetScopeGroup, ScopeID: groupID, Period: SpendBudgetPeriodMonthly, LimitUSD: 100, Enabled: true},
+			{ID: 2, ScopeType: SpendBudgetScopeUser, ScopeID: 7, Period: SpendBudgetPeriodDaily, LimitUSD: 5, Enabled: true},
+		},
+		spent: map[spendBudgetScopeKey]float64{
+			{SpendBudgetScopeGroup, groupID}: 40,
+			{SpendBudgetScopeUser, 7}:        4.5,
+		},
+	}
+	svc := NewSpendBudgetService(repo)
+
+	require.NoError(t, svc.CheckEligibility(context.Background(), 7, &groupID, 11))
+
+	// 本实例扣费实时累加到缓存，无需等待缓存过期即可拦截
+	svc.RecordSpend(7, &groupID, 11, 0.6)
+	err := svc.CheckEligibility(context.Background(), 7, &groupID, 11)
+	require.ErrorIs(t, err, ErrSpendBudgetExhausted)
+	appErr := infraerrors.FromError(err)
+	require.Equal(t, SpendBudgetScopeUser, appErr.Metadata["scope_type"])
+	require.Equal(t, SpendBudgetPeriodDaily, appErr.Metadata["period"])
+	require.Equal(t, timezone.StartOfDay(time.Now()).AddDate(0, 0, 1).Format(time.RFC3339), appErr.Metadata["window_resets_at"])
+	require.Contains(t, appErr.Message, "Daily spend budget of $5.00 for this user is exhausted")
+	require.Equal(t, 2, repo.sumCalls)
+
+	// 其他用户不受该用户预算影响
+	require.NoError(t, svc.CheckEligibility(context.Background(), 8, &groupID, 12))
+}
+
+func TestSpendBudgetService_MutationsInvalidateIndex(t *testing.T) {
+	repo := &spendBudgetRepoStub{spent: map[spendBudgetScopeKey]float64{{SpendBudgetScopeAPIKey, 11}: 2}}
+	svc := NewSpendBudgetService(repo)
+
+	require.NoError(t, svc.CheckEligibility(context.Background(), 7, nil, 11))
+	require.NoError(t, svc.CheckEligibility(context.Background(), 7, nil, 11))
+	require.Equal(t, 1, repo.listCalls)
+
+	budget, err := svc.Create(context.Background(), &SpendBudget{ScopeType: SpendBudgetScopeAPIKey, ScopeID: 11, Period: SpendBudgetPeriodWeekly, LimitUSD: 2, Enabled: true})
+	require.NoError(t, err)
+	require.ErrorIs(t, svc.CheckEligibility(context.Background(), 7, nil, 11), ErrSpendBudgetExhausted)
+
+	disabled := false
+	_, err = svc.Update(context.Background(), budget.ID, nil, &disabled)
+	require.NoError(t, err)
+	require.NoError(t, svc.CheckEligibility(context.Background(), 7, nil, 11))
+
+	_, err = svc.Create(context.Background(), &SpendBudget{ScopeType: "account", ScopeID: 1, Period: SpendBudgetPeriodDaily, LimitUSD: 1})
+	require.ErrorIs(t, err, ErrSpendBudgetInvalidScope)
+}
+
+func TestSpendBudgetService_UsageLoadFailureFailsOpen(t *testing.T) {
+	repo := &spendBudgetRepoStub{
+		budgets: []*SpendBudget{{ID: 1, ScopeType: SpendBudgetScopeUser, ScopeID: 7, Period: SpendBudgetPeriodDaily, LimitUSD: 1, Enabled: true}},
+		sumErr:  errors.New("db down"),
+	}
+	svc := NewSpendBudgetService(repo)
+
+	require.NoError(t, svc.CheckEligibility(context.Background(), 7, nil, 11))
+}
+
+func TestSpendBudgetService_ListStatusReportsRemaining(t *testing.T) {
+	repo := &spendBudgetRepoStub{
+		budgets: []*SpendBudget{
+			{ID: 1, ScopeType: SpendBudgetScopeUser, ScopeID: 7, Period: SpendBudgetPeriodMonthly, LimitUSD: 10, Enabled: true},
+			{ID: 2, ScopeType: SpendBudgetScopeAPIKey, ScopeID: 11, Period: SpendBudgetPeriodWeekly, LimitUSD: 1, Enabled: true},
+		},
+		spent: map[spendBudgetScopeKey]float64{
+			{SpendBudgetScopeUser, 7}:    2.5,
+			{SpendBudgetScopeAPIKey, 11}: 1.2,
+		},
+	}
+	statuses, err := NewSpendBudgetService(repo).ListStatus(context.Background(), "", 0)
+
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.InDelta(t, 7.5, statuses[0].RemainingUSD, 1e-9)
+	require.False(t, statuses[0].Exhausted)
+	require.Equal(t, timezone.StartOfMonth(time.Now()), statuses[0].PeriodStart)
+	require.Zero(t, statuses[1].RemainingUSD)
+	require.True(t, statuses[1].Exhausted)
+	require.Equal(t, statuses[1].PeriodStart.AddDate(0, 0, 7), statuses[1].ResetsAt)
+}
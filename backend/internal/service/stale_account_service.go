@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// 闲置账号检测与归档写入 accounts.extra / credentials 的键。
+const (
+	// AccountExtraStaleFlaggedAt 首次被检测为闲置的时间（RFC3339），恢复使用后由检测任务清除。
+	AccountExtraStaleFlaggedAt = "stale_flagged_at"
+	// AccountExtraArchivedAt 归档时间（RFC3339）。
+	AccountExtraArchivedAt = "archived_at"
+	// AccountExtraUnarchivedAt 最近一次恢复归档的时间（RFC3339），闲置检测将其视为一次活跃。
+	AccountExtraUnarchivedAt = "unarchived_at"
+
+	accountExtraArchivedPrevStatus      = "archived_prev_status"
+	accountExtraArchivedPrevSchedulable = "archived_prev_schedulable"
+
+	// credKeyArchivedCredentials 归档后 credentials 中仅保留该键：原凭证 JSON 经 SecretEncryptor 加密后的密文。
+	credKeyArchivedCredentials = "archived_credentials"
+)
+
+const (
+	staleAccountDefaultDays = 30
+	staleAccountMaxDays     = 3650
+
+	staleAccountLeaderLockKey = "account:stale:leader"
+	staleAccountLeaderLockTTL = 10 * time.Minute
+)
+
+var (
+	ErrAccountArchived    = infraerrors.Conflict("ACCOUNT_ARCHIVED", "account is archived; unarchive it before editing")
+	ErrAccountNotArchived = infraerrors.BadRequest("ACCOUNT_NOT_ARCHIVED", "account is not archived")
+)
+
+// StaleAccount 闲置账号。
+type StaleAccount struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	Platform     string     `json:"platform"`
+	Type         string     `json:"type"`
+	Status       string     `json:"status"`
+	Concurrency  int        `json:"concurrency"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastActiveAt time.Time  `json:"last_active_at"`
+	FlaggedAt    *time.Time `json:"flagged_at,omitempty"`
+	IdleDays     int        `json:"idle_days"`
+}
+
+// StaleAccountCapacity 一组账号占用的调度容量（账号数与并发槽位数）。
+type StaleAccountCapacity struct {
+	Accounts    int64 `json:"accounts"`
+	Concurrency int64 `json:"concurrency"`
+}
+
+// StaleAccountPlatformCapacity 按平台拆分的可回收容量。
+type StaleAccountPlatformCapacity struct {
+	Platform string `json:"platform"`
+	StaleAccountCapacity
+}
+
+// StaleAccountReport 闲置账号报告。
+type StaleAccountReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	StaleDays   int       `json:"stale_days"`
+
+	Accounts []StaleAccount `json:"accounts"`
+	// Reclaimable 归档全部闲置账号可回收的容量
+	Reclaimable           StaleAccountCapacity           `json:"reclaimable"`
+	ReclaimableByPlatform []StaleAccountPlatformCapacity `json:"reclaimable_by_platform"`
+	// Archived 已归档账号释放的容量
+	Archived StaleAccountCapacity `json:"archived"`
+
+	AutoArchive      bool `json:"auto_archive"`
+	ArchiveAfterDays int  `json:"archive_after_days,omitempty"`
+}
+
+// StaleAccountRepository 闲置账号查询与标记。
+type StaleAccountRepository interface {
+	// ListStaleAccounts 返回最后活跃时间早于 cutoff 的未归档账号，按最后活跃时间升序。
+	ListStaleAccounts(ctx context.Context, cutoff time.Time) ([]StaleAccount, error)
+	// SummarizeArchived 汇总已归档账号的容量。
+	SummarizeArchived(ctx context.Context) (StaleAccountCapacity, error)
+	// SyncStaleFlags 为新近闲置的账号写入 stale_flagged_at，并清除已恢复活跃账号的标记。
+	SyncStaleFlags(ctx context.Context, cutoff, flaggedAt time.Time) (flagged, cleared int64, err error)
+}
+
+// StaleAccountService 检测长期未使用的账号，按配置标记并自动归档；归档账号不参与调度，凭证加密保留，可随时恢复。
+type StaleAccountService struct {
+	repo        StaleAccountRepository
+	accountRepo AccountRepository
+	encryptor   SecretEncryptor
+	cfg         config.AccountStaleConfig
+	now         func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+}
+
+// NewStaleAccountService creates a new StaleAccountService.
+func NewStaleAccountService(repo StaleAccountRepository, accountRepo AccountRepository, encryptor SecretEncryptor, cfg *config.Config) *StaleAccountService {
+	svc := &StaleAccountService{
+		repo:        repo,
+		accountRepo: accountRepo,
+		encryptor:   encryptor,
+		now:         time.Now,
+		stopCh:      make(chan struct{}),
+		instanceID:  uuid.NewString(),
+	}
+	if cfg != nil {
+		svc.cfg = cfg.AccountStale
+	}
+	return svc
+}
+
+// SetLeaderLock 注入多实例选主所需的锁；均为 nil 时不做互斥（单实例 / 测试）。
+func (s *StaleAccountService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Start 在 account_stale.enabled 时启动周期检测。
+func (s *StaleAccountService) Start() {
+	if s == nil || s.repo == nil || !s.cfg.Enabled || s.cfg.CheckIntervalMinutes <= 0 {
+		return
+	}
+	interval := time.Duration(s.cfg.CheckIntervalMinutes) * time.Minute
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *StaleAccountService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *StaleAccountService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, staleAccountLeaderLockKey, s.instanceID, staleAccountLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	flagged, cleared, archived, err := s.RunCheck(ctx)
+	if err != nil {
+		log.Printf("[StaleAccount] Check failed: %v", err)
+		return
+	}
+	if flagged > 0 || cleared > 0 || archived > 0 {
+		log.Printf("[StaleAccount] Flagged %d, cleared %d, archived %d accounts", flagged, cleared, archived)
+	}
+}
+
+// RunCheck 执行一次检测：同步闲置标记，开启 auto_archive 时归档闲置超过 archive_after_days 的账号。
+func (s *StaleAccountService) RunCheck(ctx context.Context) (flagged, cleared int64, archived int, err error) {
+	now := s.now().UTC()
+	flagged, cleared, err = s.repo.SyncStaleFlags(ctx, now.AddDate(0, 0, -s.staleDays()), now)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if !s.cfg.AutoArchive || s.cfg.ArchiveAfterDays <= 0 {
+		return flagged, cleared, 0, nil
+	}
+	candidates, err := s.repo.ListStaleAccounts(ctx, now.AddDate(0, 0, -s.cfg.ArchiveAfterDays))
+	if err != nil {
+		return flagged, cleared, 0, err
+	}
+	for _, candidate := range candidates {
+		if _, err := s.Archive(ctx, candidate.ID); err != nil {
+			// 单个账号失败（如带有影子账号）不影响其余账号
+			log.Printf("[StaleAccount] Auto archive account %d failed: %v", candidate.ID, err)
+			continue
+		}
+		archived++
+	}
+	return flagged, cleared, archived, nil
+}
+
+func (s *StaleAccountService) staleDays() int {
+	if s.cfg.StaleDays > 0 {
+		return s.cfg.StaleDays
+	}
+	return staleAccountDefaultDays
+}
+
+// Report 列出闲置超过 days 天的账号及归档可回收的容量；days 为 0 时使用 account_stale.stale_days。
+func (s *StaleAccountService) Report(ctx context.Context, days int) (*StaleAccountReport, error) {
+	if days <= 0 {
+		days = s.staleDays()
+	}
+	days = min(days, staleAccountMaxDays)
+	now := s.now().UTC()
+
+	accounts, err := s.repo.ListStaleAccounts(ctx, now.AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+	archived, err := s.repo.SummarizeArchived(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StaleAccountReport{
+		GeneratedAt:           now,
+		StaleDays:             days,
+		Accounts:              make([]StaleAccount, 0, len(accounts)),
+		ReclaimableByPlatform: []StaleAccountPlatformCapacity{},
+		Archived:              archived,
+		AutoArchive:           s.cfg.AutoArchive,
+	}
+	if s.cfg.AutoArchive {
+		report.ArchiveAfterDays = s.cfg.ArchiveAfterDays
+	}
+	byPlatform := make(map[string]*StaleAccountPlatformCapacity)
+	for _, acc := range accounts {
+		acc.IdleDays = int(now.Sub(acc.LastActiveAt).Hours() / 24)
+		report.Accounts = append(report.Accounts, acc)
+		report.Reclaimable.Accounts++
+		report.Reclaimable.Concurrency += int64(acc.Concurrency)
+		p, ok := byPlatform[acc.Platform]
+		if !ok {
+			p = &StaleAccountPlatformCapacity{Platform: acc.Platform}
+			byPlatform[acc.Platform] = p
+		}
+		p.Accounts++
+		p.Concurrency += int64(acc.Concurrency)
+	}
+	for _, p := range byPlatform {
+		report.ReclaimableByPlatform = append(report.ReclaimableByPlatform, *p)
+	}
+	sort.Slice(report.ReclaimableByPlatform, func(i, j int) bool {
+		return report.ReclaimableByPlatform[i].Platform < report.ReclaimableByPlatform[j].Platform
+	})
+	return report, nil
+}
+
+// Archive 归档账号：状态置为 archived 并停止调度，凭证整体加密封存，原状态记录在 extra 中供恢复。
+func (s *StaleAccountService) Archive(ctx context.Context, id int64) (*Account, error) {
+	if s.encryptor == nil {
+		return nil, infraerrors.ServiceUnavailable("ARCHIVE_UNAVAILABLE", "credential encryptor is not configured")
+	}
+	account, err := s.accountRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status == StatusArchived {
+		return account, nil
+	}
+	// 影子账号读透母账号凭证，母账号凭证被封存后影子将无法工作
+	shadows, err := s.accountRepo.ListShadowsByParent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(shadows) > 0 {
+		return nil, infraerrors.New(http.StatusBadRequest, "ACCOUNT_HAS_SHADOWS",
+			"account has spark shadow accounts that read its credentials; delete or archive the shadows first")
+	}
+
+	raw, err := json.Marshal(account.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("marshal credentials: %w", err)
+	}
+	sealed, err := s.encryptor.Encrypt(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt credentials: %w", err)
+	}
+
+	extra := make(map[string]any, len(account.Extra)+3)
+	for k, v := range account.Extra {
+		extra[k] = v
+	}
+	extra[AccountExtraArchivedAt] = s.now().UTC().Format(time.RFC3339)
+	extra[accountExtraArchivedPrevStatus] = account.Status
+	extra[accountExtraArchivedPrevSchedulable] = account.Schedulable
+
+	account.Credentials = map[string]any{credKeyArchivedCredentials: sealed}
+	account.Extra = extra
+	account.Status = StatusArchived
+	account.Schedulable = false
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Unarchive 恢复归档账号：解密还原凭证，恢复归档前的状态与调度开关。
+func (s *StaleAccountService) Unarchive(ctx context.Context, id int64) (*Account, error) {
+	if s.encryptor == nil {
+		return nil, infraerrors.ServiceUnavailable("ARCHIVE_UNAVAILABLE", "credential encryptor is not configured")
+	}
+	account, err := s.accountRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != StatusArchived {
+		return nil, ErrAccountNotArchived
+	}
+	sealed, _ := account.Credentials[credKeyArchivedCredentials].(string)
+	credentials := map[string]any{}
+	if sealed != "" {
+		plaintext, err := s.encryptor.Decrypt(sealed)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt archived credentials: %w", err)
+		}
+		if err := json.Unmarshal([]byte(plaintext), &credentials); err != nil {
+			return nil, fmt.Errorf("unmarshal archived credentials: %w", err)
+		}
+	}
+
+	status, _ := account.Extra[accountExtraArchivedPrevStatus].(string)
+	if status == "" || status == StatusArchived {
+		status = StatusActive
+	}
+	schedulable, ok := account.Extra[accountExtraArchivedPrevSchedulable].(bool)
+	if !ok {
+		schedulable = true
+	}
+	extra := make(map[string]any, len(account.Extra)+1)
+	for k, v := range account.Extra {
+		extra[k] = v
+	}
+	for _, key := range []string{AccountExtraArchivedAt, AccountExtraStaleFlaggedAt, accountExtraArchivedPrevStatus, accountExtraArchivedPrevSchedulable} {
+		delete(extra, key)
+	}
+	extra[AccountExtraUnarchivedAt] = s.now().UTC().Format(time.RFC3339)
+
+	account.Credentials = credentials
+	account.Extra = extra
+	account.Status = status
+	account.Schedulable = schedulable
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
@@ -0,0 +1,183 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type staleAccountRepoStub struct {
+	stale    map[time.Time][]StaleAccount
+	archived StaleAccountCapacity
+	cutoffs  []time.Time
+}
+
+func (s *staleAccountRepoStub) ListStaleAccounts(_ context.Context, cutoff time.Time) ([]StaleAccount, error) {
+	s.cutoffs = append(s.cutoffs, cutoff)
+	return s.stale[cutoff], nil
+}
+
+func (s *staleAccountRepoStub) SummarizeArchived(context.Context) (StaleAccountCapacity, error) {
+	return s.archived, nil
+}
+
+func (s *staleAccountRepoStub) SyncStaleFlags(_ context.Context, cutoff, _ time.Time) (int64, int64, error) {
+	s.cutoffs = append(s.cutoffs, cutoff)
+	return int64(len(s.stale[cutoff])), 0, nil
+}
+
+type staleAccountAccountRepoStub struct {
+	AccountRepository
+	accounts map[int64]*Account
+	shadows  map[int64][]*Account
+	updated  []Account
+}
+
+func (s *staleAccountAccountRepoStub) GetByID(_ context.Context, id int64) (*Account, error) {
+	acc, ok := s.accounts[id]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	cp := *acc
+	return &cp, nil
+}
+
+func (s *staleAccountAccountRepoStub) ListShadowsByParent(_ context.Context, id int64) ([]*Account, error) {
+	return s.shadows[id], nil
+}
+
+func (s *staleAccountAccountRepoStub) Update(_ context.Context, account *Account) error {
+	s.updated = append(s.updated, *account)
+	cp := *account
+	s.accounts[account.ID] = &cp
+	return nil
+}
+
+// reverseEncryptor 以可逆变换模拟加密，便于断言密文不含明文。
+type reverseEncryptor struct{}
+
+func (reverseEncryptor) Encrypt(plaintext string) (string, error) {
+	r := []rune(plaintext)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return "enc:" + string(r), nil
+}
+
+func (e reverseEncryptor) Decrypt(ciphertext string) (string, error) {
+	if !strings.HasPrefix(ciphertext, "enc:") {
+		return "", errors.New("bad ciphertext")
+	}
+	plain, _ := e.Encrypt(strings.TrimPrefix(ciphertext, "enc:"))
+	return strings.TrimPrefix(plain, "enc:"), nil
+}
+
+func newStaleAccountServiceForTest(repo *staleAccountRepoStub, accounts *staleAccountAccountRepoStub, cfg config.AccountStaleConfig) *StaleAccountService {
+	svc := NewStaleAccountService(repo, accounts, reverseEncryptor{}, &config.Config{AccountStale: cfg})
+	svc.now = func() time.Time { return time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestStaleAccountService_ArchiveAndUnarchiveRoundTrip(t *testing.T) {
+	accounts := &staleAccountAccountRepoStub{accounts: map[int64]*Account{
+		1: {ID: 1, Status: StatusActive, Schedulable: false, Credentials: map[string]any{"api_key": "sk-secret"}, Extra: map[string]any{"note": "x", AccountExtraStaleFlaggedAt: "2026-09-01T00:00:00Z"}},
+	}}
+	svc := newStaleAccountServiceForTest(&staleAccountRepoStub{}, accounts, config.AccountStaleConfig{})
+
+	archived, err := svc.Archive(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, StatusArchived, archived.Status)
+	require.False(t, archived.IsSchedulable())
+	require.Len(t, archived.Credentials, 1)
+	sealed, _ := archived.Credentials[credKeyArchivedCredentials].(string)
+	require.NotEmpty(t, sealed)
+	require.NotContains(t, sealed, "sk-secret")
+	require.Equal(t, "2026-10-14T12:00:00Z", archived.Extra[AccountExtraArchivedAt])
+
+	again, err := svc.Archive(context.Background(), 1)
+	require.NoError(t, err, "archiving twice is a no-op")
+	require.Equal(t, sealed, again.Credentials[credKeyArchivedCredentials], "credentials must not be sealed twice")
+
+	restored, err := svc.Unarchive(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, StatusActive, restored.Status)
+	require.False(t, restored.Schedulable, "previous schedulable switch is restored")
+	require.Equal(t, map[string]any{"api_key": "sk-secret"}, restored.Credentials)
+	require.Equal(t, "x", restored.Extra["note"])
+	require.NotContains(t, restored.Extra, AccountExtraStaleFlaggedAt)
+	require.NotContains(t, restored.Extra, AccountExtraArchivedAt)
+	require.Equal(t, "2026-10-14T12:00:00Z", restored.Extra[AccountExtraUnarchivedAt])
+
+	_, err = svc.Unarchive(context.Background(), 1)
+	require.ErrorIs(t, err, ErrAccountNotArchived)
+}
+
+func TestStaleAccountService_ArchiveRejectsShadowParents(t *testing.T) {
+	accounts := &staleAccountAccountRepoStub{
+		accounts: map[int64]*Account{1: {ID: 1, Status: StatusActive, Credentials: map[string]any{}}},
+		shadows:  map[int64][]*Account{1: {{ID: 2}}},
+	}
+	svc := newStaleAccountServiceForTest(&staleAccountRepoStub{}, accounts, config.AccountStaleConfig{})
+
+	_, err := svc.Archive(context.Background(), 1)
+	require.Error(t, err)
+	require.Empty(t, accounts.updated)
+}
+
+func TestStaleAccountService_ReportReclaimableCapacity(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	cutoff := now.AddDate(0, 0, -30)
+	repo := &staleAccountRepoStub{
+		stale: map[time.Time][]StaleAccount{cutoff: {
+			{ID: 1, Platform: PlatformOpenAI, Concurrency: 3, LastActiveAt: now.AddDate(0, 0, -90)},
+			{ID: 2, Platform: PlatformAnthropic, Concurrency: 5, LastActiveAt: now.AddDate(0, 0, -31)},
+			{ID: 3, Platform: PlatformOpenAI, Concurrency: 2, LastActiveAt: now.AddDate(0, 0, -45)},
+		}},
+		archived: StaleAccountCapacity{Accounts: 4, Concurrency: 12},
+	}
+	svc := newStaleAccountServiceForTest(repo, &staleAccountAccountRepoStub{}, config.AccountStaleConfig{StaleDays: 30})
+
+	report, err := svc.Report(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, 30, report.StaleDays)
+	require.Equal(t, StaleAccountCapacity{Accounts: 3, Concurrency: 10}, report.Reclaimable)
+	require.Equal(t, []StaleAccountPlatformCapacity{
+		{Platform: PlatformAnthropic, StaleAccountCapacity: StaleAccountCapacity{Accounts: 1, Concurrency: 5}},
+		{Platform: PlatformOpenAI, StaleAccountCapacity: StaleAccountCapacity{Accounts: 2, Concurrency: 5}},
+	}, report.ReclaimableByPlatform)
+	require.Equal(t, 90, report.Accounts[0].IdleDays)
+	require.Equal(t, StaleAccountCapacity{Accounts: 4, Concurrency: 12}, report.Archived)
+}
+
+func TestStaleAccountService_RunCheckAutoArchive(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	archiveCutoff := now.AddDate(0, 0, -60)
+	repo := &staleAccountRepoStub{stale: map[time.Time][]StaleAccount{
+		now.AddDate(0, 0, -30): {{ID: 1}, {ID: 2}},
+		archiveCutoff:          {{ID: 1}},
+	}}
+	accounts := &staleAccountAccountRepoStub{accounts: map[int64]*Account{
+		1: {ID: 1, Status: StatusActive, Credentials: map[string]any{"api_key": "k"}},
+		2: {ID: 2, Status: StatusActive, Credentials: map[string]any{"api_key": "k"}},
+	}}
+
+	svc := newStaleAccountServiceForTest(repo, accounts, config.AccountStaleConfig{StaleDays: 30})
+	flagged, _, archived, err := svc.RunCheck(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), flagged)
+	require.Zero(t, archived, "auto archive is opt-in")
+
+	svc = newStaleAccountServiceForTest(repo, accounts, config.AccountStaleConfig{StaleDays: 30, AutoArchive: true, ArchiveAfterDays: 60})
+	_, _, archived, err = svc.RunCheck(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+	require.Equal(t, StatusArchived, accounts.accounts[1].Status)
+	require.Equal(t, StatusActive, accounts.accounts[2].Status)
+}
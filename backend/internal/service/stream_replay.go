@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	// 流式续传缓冲默认值：单个响应最多缓存的事件数/字节数，以及结束后可续传的时长。
+	defaultStreamReplayMaxEvents        = 2000
+	defaultStreamReplayMaxBytes         = 4 << 20
+	defaultStreamReplayRetentionSeconds = 60
+
+	maxStreamReplayMaxEvents        = 10000
+	maxStreamReplayMaxBytes         = 16 << 20
+	maxStreamReplayRetentionSeconds = 600
+)
+
+// normalizeGroupStreamReplayConfig 校验流式续传配置：各项不能为负且不超过上限，0 表示使用默认值。
+func normalizeGroupStreamReplayConfig(cfg GroupStreamReplayConfig) (GroupStreamReplayConfig, error) {
+	limits := []struct {
+		field string
+		value int
+		max   int
+	}{
+		{"max_events", cfg.MaxEvents, maxStreamReplayMaxEvents},
+		{"max_bytes", cfg.MaxBytes, maxStreamReplayMaxBytes},
+		{"retention_seconds", cfg.RetentionSeconds, maxStreamReplayRetentionSeconds},
+	}
+	for _, l := range limits {
+		if l.value < 0 || l.value > l.max {
+			return GroupStreamReplayConfig{}, infraerrors.BadRequest("INVALID_STREAM_REPLAY",
+				"stream replay "+l.field+" must be between 0 and "+strconv.Itoa(l.max)).
+				WithMetadata(map[string]string{"field": l.field})
+		}
+	}
+	return cfg, nil
+}
+
+// StreamReplayLimits 返回生效的续传缓冲上限，未配置的项取默认值。
+func StreamReplayLimits(cfg GroupStreamReplayConfig) (maxEvents, maxBytes int, retention time.Duration) {
+	maxEvents, maxBytes, retentionSeconds := cfg.MaxEvents, cfg.MaxBytes, cfg.RetentionSeconds
+	if maxEvents <= 0 {
+		maxEvents = defaultStreamReplayMaxEvents
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultStreamReplayMaxBytes
+	}
+	if retentionSeconds <= 0 {
+		retentionSeconds = defaultStreamReplayRetentionSeconds
+	}
+	return maxEvents, maxBytes, time.Duration(retentionSeconds) * time.Second
+}
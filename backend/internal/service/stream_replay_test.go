@@ -0,0 +1,39 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeGroupStreamReplayConfig(t *testing.T) {
+	cfg, err := normalizeGroupStreamReplayConfig(GroupStreamReplayConfig{Enabled: true, MaxEvents: 100, RetentionSeconds: 30})
+	require.NoError(t, err)
+	require.Equal(t, GroupStreamReplayConfig{Enabled: true, MaxEvents: 100, RetentionSeconds: 30}, cfg)
+
+	for _, bad := range []GroupStreamReplayConfig{
+		{MaxEvents: -1},
+		{MaxBytes: maxStreamReplayMaxBytes + 1},
+		{RetentionSeconds: maxStreamReplayRetentionSeconds + 1},
+	} {
+		_, err := normalizeGroupStreamReplayConfig(bad)
+		require.Error(t, err)
+		require.Equal(t, "INVALID_STREAM_REPLAY", infraerrors.Reason(err))
+	}
+}
+
+func TestStreamReplayLimits(t *testing.T) {
+	events, bytes, retention := StreamReplayLimits(GroupStreamReplayConfig{Enabled: true})
+	require.Equal(t, defaultStreamReplayMaxEvents, events)
+	require.Equal(t, defaultStreamReplayMaxBytes, bytes)
+	require.Equal(t, defaultStreamReplayRetentionSeconds*time.Second, retention)
+
+	events, bytes, retention = StreamReplayLimits(GroupStreamReplayConfig{MaxEvents: 5, MaxBytes: 64, RetentionSeconds: 2})
+	require.Equal(t, 5, events)
+	require.Equal(t, 64, bytes)
+	require.Equal(t, 2*time.Second, retention)
+}
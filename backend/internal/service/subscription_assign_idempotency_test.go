@@ -90,6 +90,18 @@ func (groupRepoNoop) GetAccountIDsByGroupIDs(context.Context, []int64) ([]int64,
 func (groupRepoNoop) BindAccountsToGroup(context.Context, int64, []int64) error {
 	panic("unexpected BindAccountsToGroup call")
 }
+func (groupRepoNoop) ListAccountTiers(context.Context, int64) ([]GroupAccountTier, error) {
+	panic("unexpected ListAccountTiers call")
+}
+func (groupRepoNoop) SetAccountTiers(context.Context, int64, []GroupAccountTier) error {
+	panic("unexpected SetAccountTiers call")
+}
+func (groupRepoNoop) ListAccountConcurrencyShares(context.Context, int64) ([]GroupAccountConcurrencyShare, error) {
+	panic("unexpected ListAccountConcurrencyShares call")
+}
+func (groupRepoNoop) SetAccountConcurrencyShares(context.Context, int64, []GroupAccountConcurrencyShare) error {
+	panic("unexpected SetAccountConcurrencyShares call")
+}
 func (groupRepoNoop) UpdateSortOrders(context.Context, []GroupSortOrderUpdate) error {
 	panic("unexpected UpdateSortOrders call")
 }
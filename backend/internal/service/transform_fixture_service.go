@@ -0,0 +1,468 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/cassette"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	TransformFixtureEndpointMessages        = "/v1/messages"
+	TransformFixtureEndpointChatCompletions = "/v1/chat/completions"
+	TransformFixtureEndpointResponses       = "/v1/responses"
+
+	transformFixtureMaxNameLen        = 100
+	transformFixtureMaxDescriptionLen = 500
+	transformFixtureMaxBodyBytes      = 1 << 20
+	transformFixtureMaxPerRun         = 100
+	transformFixtureRunTimeout        = 30 * time.Second
+)
+
+var (
+	ErrTransformFixtureNotFound           = infraerrors.NotFound("TRANSFORM_FIXTURE_NOT_FOUND", "transform fixture not found")
+	ErrTransformFixtureExists             = infraerrors.Conflict("TRANSFORM_FIXTURE_EXISTS", "transform fixture with this name already exists")
+	ErrTransformFixtureInvalidName        = infraerrors.BadRequest("TRANSFORM_FIXTURE_INVALID_NAME", fmt.Sprintf("name must be 1-%d characters", transformFixtureMaxNameLen))
+	ErrTransformFixtureInvalidDescription = infraerrors.BadRequest("TRANSFORM_FIXTURE_INVALID_DESCRIPTION", fmt.Sprintf("description must be at most %d characters", transformFixtureMaxDescriptionLen))
+	ErrTransformFixtureInvalidEndpoint    = infraerrors.BadRequest("TRANSFORM_FIXTURE_INVALID_ENDPOINT", "endpoint must be one of: /v1/messages, /v1/chat/completions, /v1/responses")
+	ErrTransformFixtureInvalidAccount     = infraerrors.BadRequest("TRANSFORM_FIXTURE_INVALID_ACCOUNT", "account_id is required")
+	ErrTransformFixtureInvalidRequest     = infraerrors.BadRequest("TRANSFORM_FIXTURE_INVALID_REQUEST", fmt.Sprintf("request.body must be a JSON object of at most %d bytes", transformFixtureMaxBodyBytes))
+	ErrTransformFixtureInvalidExpect      = infraerrors.BadRequest("TRANSFORM_FIXTURE_INVALID_EXPECT", "expect must set at least one of method/host/path/headers/absent_headers/body, and expect.body must be a JSON object")
+	ErrTransformFixtureRunEmpty           = infraerrors.BadRequest("TRANSFORM_FIXTURE_RUN_EMPTY", "no fixtures to run")
+	ErrTransformFixtureRunTooLarge        = infraerrors.BadRequest("TRANSFORM_FIXTURE_RUN_TOO_LARGE", fmt.Sprintf("at most %d fixtures per run", transformFixtureMaxPerRun))
+)
+
+// TransformFixtureRequest 夹具中的客户端请求：入站头与请求体。
+type TransformFixtureRequest struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// TransformFixtureExpectation 对转换后上游请求的断言；未设置的字段不检查。
+// Headers 按名称忽略大小写比较，值为 "*" 表示只要求存在；Body 为 JSON 子集匹配（期望中的字段必须存在且相等，数组逐元素比较）。
+type TransformFixtureExpectation struct {
+	Method        string            `json:"method,omitempty"`
+	Host          string            `json:"host,omitempty"`
+	Path          string            `json:"path,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	AbsentHeaders []string          `json:"absent_headers,omitempty"`
+	Body          json.RawMessage   `json:"body,omitempty"`
+}
+
+// TransformFixture "给定客户端请求，期望得到的上游请求" 测试夹具，用指定账号（及分组的渠道映射）在真实转换管线上干跑。
+type TransformFixture struct {
+	ID          int64                       `json:"id"`
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Endpoint    string                      `json:"endpoint"`
+	AccountID   int64                       `json:"account_id"`
+	GroupID     *int64                      `json:"group_id,omitempty"`
+	Request     TransformFixtureRequest     `json:"request"`
+	Expect      TransformFixtureExpectation `json:"expect"`
+	LastRunAt   *time.Time                  `json:"last_run_at,omitempty"`
+	LastPassed  *bool                       `json:"last_passed,omitempty"`
+	CreatedBy   *int64                      `json:"created_by,omitempty"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	UpdatedAt   time.Time                   `json:"updated_at"`
+}
+
+// TransformFixtureInput 创建/更新夹具或临时运行夹具的入参。
+type TransformFixtureInput struct {
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Endpoint    string                      `json:"endpoint"`
+	AccountID   int64                       `json:"account_id"`
+	GroupID     *int64                      `json:"group_id"`
+	Request     TransformFixtureRequest     `json:"request"`
+	Expect      TransformFixtureExpectation `json:"expect"`
+}
+
+// TransformFixtureRunInput 一次运行：已保存夹具按 IDs 选取（IDs 与 Fixtures 都为空时运行全部已保存夹具），
+// Fixtures 为不保存的临时夹具；Accounts 为账号映射草稿，运行时覆盖对应账号的已保存配置，用于上线前验证。
+type TransformFixtureRunInput struct {
+	IDs      []int64                          `json:"ids"`
+	Fixtures []TransformFixtureInput          `json:"fixtures"`
+	Accounts []MappingSimulationAccountChange `json:"accounts"`
+}
+
+// TransformFixtureMismatch 一项断言失败。
+type TransformFixtureMismatch struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// TransformFixtureResult 单个夹具的运行结果；Actual 为脱敏后的实际上游请求。
+type TransformFixtureResult struct {
+	FixtureID  int64                      `json:"fixture_id,omitempty"`
+	Name       string                     `json:"name"`
+	Passed     bool                       `json:"passed"`
+	Error      string                     `json:"error,omitempty"`
+	Mismatches []TransformFixtureMismatch `json:"mismatches"`
+	Actual     *cassette.Request          `json:"actual,omitempty"`
+	DurationMs int64                      `json:"duration_ms"`
+}
+
+// TransformFixtureRunReport 一次运行的汇总；Failed > 0 时不应上线对应配置。
+type TransformFixtureRunReport struct {
+	RanAt   time.Time                 `json:"ran_at"`
+	Total   int                       `json:"total"`
+	Passed  int                       `json:"passed"`
+	Failed  int                       `json:"failed"`
+	Results []*TransformFixtureResult `json:"results"`
+}
+
+// TransformFixtureRepository 夹具存储。
+type TransformFixtureRepository interface {
+	Create(ctx context.Context, fixture *TransformFixture) (*TransformFixture, error)
+	GetByID(ctx context.Context, id int64) (*TransformFixture, error)
+	List(ctx context.Context) ([]*TransformFixture, error)
+	Update(ctx context.Context, fixture *TransformFixture) (*TransformFixture, error)
+	Delete(ctx context.Context, id int64) error
+	RecordRun(ctx context.Context, id int64, passed bool, ranAt time.Time) error
+}
+
+// TransformFixtureService 管理转换测试夹具，并在真实转发管线上干跑：上游请求在 HTTPUpstream 处被截获、不会发出，
+// 不计费、不写使用记录，用于在映射/策略变更上线前校验转换结果。
+type TransformFixtureService struct {
+	repo                 TransformFixtureRepository
+	accountRepo          AccountRepository
+	gatewayService       *GatewayService
+	openAIGatewayService *OpenAIGatewayService
+	now                  func() time.Time
+}
+
+// NewTransformFixtureService creates a new TransformFixtureService.
+func NewTransformFixtureService(repo TransformFixtureRepository, accountRepo AccountRepository, gatewayService *GatewayService, openAIGatewayService *OpenAIGatewayService) *TransformFixtureService {
+	return &TransformFixtureService{
+		repo:                 repo,
+		accountRepo:          accountRepo,
+		gatewayService:       gatewayService,
+		openAIGatewayService: openAIGatewayService,
+		now:                  time.Now,
+	}
+}
+
+func (s *TransformFixtureService) List(ctx context.Context) ([]*TransformFixture, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *TransformFixtureService) Get(ctx context.Context, id int64) (*TransformFixture, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *TransformFixtureService) Create(ctx context.Context, input TransformFixtureInput, createdBy int64) (*TransformFixture, error) {
+	fixture, err := input.toFixture()
+	if err != nil {
+		return nil, err
+	}
+	if createdBy > 0 {
+		fixture.CreatedBy = &createdBy
+	}
+	return s.repo.Create(ctx, fixture)
+}
+
+func (s *TransformFixtureService) Update(ctx context.Context, id int64, input TransformFixtureInput) (*TransformFixture, error) {
+	fixture, err := input.toFixture()
+	if err != nil {
+		return nil, err
+	}
+	fixture.ID = id
+	return s.repo.Update(ctx, fixture)
+}
+
+func (s *TransformFixtureService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Run 依次干跑选中的夹具；已保存夹具的最近结果在未使用账号草稿时回写，草稿运行不影响夹具状态。
+func (s *TransformFixtureService) Run(ctx context.Context, input TransformFixtureRunInput) (*TransformFixtureRunReport, error) {
+	var fixtures []*TransformFixture
+	switch {
+	case len(input.IDs) > 0:
+		for _, id := range input.IDs {
+			fixture, err := s.repo.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			fixtures = append(fixtures, fixture)
+		}
+	case len(input.Fixtures) == 0:
+		all, err := s.repo.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = all
+	}
+	for _, in := range input.Fixtures {
+		fixture, err := in.toFixture()
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	if len(fixtures) == 0 {
+		return nil, ErrTransformFixtureRunEmpty
+	}
+	if len(fixtures) > transformFixtureMaxPerRun {
+		return nil, ErrTransformFixtureRunTooLarge
+	}
+
+	drafts := make(map[int64]MappingSimulationAccountChange, len(input.Accounts))
+	for _, change := range input.Accounts {
+		drafts[change.AccountID] = change
+	}
+
+	report := &TransformFixtureRunReport{RanAt: s.now().UTC(), Results: make([]*TransformFixtureResult, 0, len(fixtures))}
+	for _, fixture := range fixtures {
+		result := s.runFixture(ctx, fixture, drafts)
+		report.Results = append(report.Results, result)
+		report.Total++
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		if fixture.ID > 0 && len(drafts) == 0 {
+			if err := s.repo.RecordRun(ctx, fixture.ID, result.Passed, report.RanAt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+func (s *TransformFixtureService) runFixture(ctx context.Context, fixture *TransformFixture, drafts map[int64]MappingSimulationAccountChange) *TransformFixtureResult {
+	startedAt := s.now()
+	result := &TransformFixtureResult{FixtureID: fixture.ID, Name: fixture.Name, Mismatches: []TransformFixtureMismatch{}}
+	defer func() { result.DurationMs = s.now().Sub(startedAt).Milliseconds() }()
+
+	account, err := s.accountRepo.GetByID(ctx, fixture.AccountID)
+	if err != nil {
+		result.Error = "load account: " + err.Error()
+		return result
+	}
+	if change, ok := drafts[account.ID]; ok {
+		if account, err = account.WithModelMappingDraft(change.ModelMapping, change.ModelMappingRules); err != nil {
+			result.Error = "apply account draft: " + err.Error()
+			return result
+		}
+	}
+
+	body := []byte(fixture.Request.Body)
+	if fixture.GroupID != nil && s.gatewayService != nil {
+		model := gjson.GetBytes(body, "model").String()
+		mapping, restricted := s.gatewayService.ResolveChannelMappingAndRestrict(ctx, fixture.GroupID, model)
+		if restricted {
+			result.Error = fmt.Sprintf("model %q is restricted by the group's channel", model)
+			return result
+		}
+		if mapping.Mapped {
+			body = s.gatewayService.ReplaceModelInBody(body, mapping.MappedModel)
+		}
+	}
+
+	capture := &upstreamDryRunCapture{}
+	runCtx, cancel := context.WithTimeout(withUpstreamDryRun(ctx, capture), transformFixtureRunTimeout)
+	defer cancel()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req, err := http.NewRequestWithContext(runCtx, http.MethodPost, fixture.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for name, value := range fixture.Request.Headers {
+		req.Header.Set(name, value)
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.Request = req
+
+	forwardErr := s.forward(runCtx, c, account, fixture, body)
+	upstreamReq, upstreamBody := capture.captured()
+	if upstreamReq == nil {
+		switch {
+		case forwardErr != nil:
+			result.Error = "no upstream request was produced: " + forwardErr.Error()
+		default:
+			result.Error = "no upstream request was produced"
+		}
+		return result
+	}
+
+	actual := cassette.NewRequest(upstreamReq, upstreamBody)
+	result.Actual = &actual
+	result.Mismatches = compareTransformFixture(fixture.Expect, upstreamReq, upstreamBody)
+	result.Passed = len(result.Mismatches) == 0
+	return result
+}
+
+// forward 按入站端点与账号平台调用与网关 handler 相同的转发入口。
+func (s *TransformFixtureService) forward(ctx context.Context, c *gin.Context, account *Account, fixture *TransformFixture, body []byte) error {
+	openAI := account.Platform == PlatformOpenAI && s.openAIGatewayService != nil
+	anthropic := account.Platform == PlatformAnthropic && s.gatewayService != nil
+	var err error
+	switch {
+	case fixture.Endpoint == TransformFixtureEndpointMessages && openAI:
+		_, err = s.openAIGatewayService.ForwardAsAnthropic(ctx, c, account, body, "", "")
+	case fixture.Endpoint == TransformFixtureEndpointMessages && anthropic:
+		var parsed *ParsedRequest
+		if parsed, err = ParseGatewayRequest(NewRequestBodyRef(body), PlatformAnthropic); err == nil {
+			parsed.GroupID = fixture.GroupID
+			_, err = s.gatewayService.Forward(ctx, c, account, parsed)
+		}
+	case fixture.Endpoint == TransformFixtureEndpointChatCompletions && openAI:
+		_, err = s.openAIGatewayService.ForwardAsChatCompletions(ctx, c, account, body, "", "")
+	case fixture.Endpoint == TransformFixtureEndpointChatCompletions && anthropic:
+		parsed, _ := ParseGatewayRequest(NewRequestBodyRef(body), "chat_completions")
+		_, err = s.gatewayService.ForwardAsChatCompletions(ctx, c, account, body, parsed)
+	case fixture.Endpoint == TransformFixtureEndpointResponses && openAI:
+		_, err = s.openAIGatewayService.Forward(ctx, c, account, body)
+	case fixture.Endpoint == TransformFixtureEndpointResponses && anthropic:
+		parsed, _ := ParseGatewayRequest(NewRequestBodyRef(body), "responses")
+		_, err = s.gatewayService.ForwardAsResponses(ctx, c, account, body, parsed)
+	default:
+		return fmt.Errorf("endpoint %s is not supported for %s accounts", fixture.Endpoint, account.Platform)
+	}
+	return err
+}
+
+func (in TransformFixtureInput) toFixture() (*TransformFixture, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len([]rune(name)) > transformFixtureMaxNameLen {
+		return nil, ErrTransformFixtureInvalidName
+	}
+	if len([]rune(in.Description)) > transformFixtureMaxDescriptionLen {
+		return nil, ErrTransformFixtureInvalidDescription
+	}
+	switch in.Endpoint {
+	case TransformFixtureEndpointMessages, TransformFixtureEndpointChatCompletions, TransformFixtureEndpointResponses:
+	default:
+		return nil, ErrTransformFixtureInvalidEndpoint
+	}
+	if in.AccountID <= 0 {
+		return nil, ErrTransformFixtureInvalidAccount
+	}
+	if len(in.Request.Body) > transformFixtureMaxBodyBytes || !isJSONObject(in.Request.Body) {
+		return nil, ErrTransformFixtureInvalidRequest
+	}
+	expect := in.Expect
+	if len(expect.Body) > 0 && !isJSONObject(expect.Body) {
+		return nil, ErrTransformFixtureInvalidExpect
+	}
+	if expect.Method == "" && expect.Host == "" && expect.Path == "" && len(expect.Headers) == 0 && len(expect.AbsentHeaders) == 0 && len(expect.Body) == 0 {
+		return nil, ErrTransformFixtureInvalidExpect
+	}
+	return &TransformFixture{
+		Name:        name,
+		Description: strings.TrimSpace(in.Description),
+		Endpoint:    in.Endpoint,
+		AccountID:   in.AccountID,
+		GroupID:     in.GroupID,
+		Request:     in.Request,
+		Expect:      expect,
+	}, nil
+}
+
+// compareTransformFixture 返回实际上游请求与期望的全部差异，按字段排序。
+func compareTransformFixture(expect TransformFixtureExpectation, req *http.Request, body []byte) []TransformFixtureMismatch {
+	mismatches := []TransformFixtureMismatch{}
+	add := func(field, expected, actual string) {
+		mismatches = append(mismatches, TransformFixtureMismatch{Field: field, Expected: expected, Actual: actual})
+	}
+	if expect.Method != "" && !strings.EqualFold(expect.Method, req.Method) {
+		add("method", expect.Method, req.Method)
+	}
+	if expect.Host != "" && !strings.EqualFold(expect.Host, req.URL.Host) {
+		add("host", expect.Host, req.URL.Host)
+	}
+	if expect.Path != "" && expect.Path != req.URL.Path {
+		add("path", expect.Path, req.URL.Path)
+	}
+	for name, want := range expect.Headers {
+		values, ok := req.Header[http.CanonicalHeaderKey(name)]
+		got := strings.Join(values, ", ")
+		switch {
+		case !ok:
+			add("headers."+strings.ToLower(name), want, "<absent>")
+		case want != "*" && want != got:
+			add("headers."+strings.ToLower(name), want, got)
+		}
+	}
+	for _, name := range expect.AbsentHeaders {
+		if values, ok := req.Header[http.CanonicalHeaderKey(name)]; ok {
+			add("headers."+strings.ToLower(name), "<absent>", strings.Join(values, ", "))
+		}
+	}
+	if len(expect.Body) > 0 {
+		var want, got any
+		_ = json.Unmarshal(expect.Body, &want)
+		if err := json.Unmarshal(body, &got); err != nil {
+			add("body", string(expect.Body), "<non-JSON body>")
+		} else {
+			matchJSONSubset("body", want, got, add)
+		}
+	}
+	sort.SliceStable(mismatches, func(i, j int) bool { return mismatches[i].Field < mismatches[j].Field })
+	return mismatches
+}
+
+// matchJSONSubset 对象按期望中的键递归比较，数组要求长度一致并逐元素比较，其余值要求相等。
+func matchJSONSubset(path string, want, got any, add func(field, expected, actual string)) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			add(path, compactJSON(want), compactJSON(got))
+			return
+		}
+		for key, wv := range w {
+			gv, exists := g[key]
+			if !exists {
+				add(path+"."+key, compactJSON(wv), "<absent>")
+				continue
+			}
+			matchJSONSubset(path+"."+key, wv, gv, add)
+		}
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			add(path, compactJSON(want), compactJSON(got))
+			return
+		}
+		for i := range w {
+			matchJSONSubset(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], add)
+		}
+	default:
+		if !reflect.DeepEqual(want, got) {
+			add(path, compactJSON(want), compactJSON(got))
+		}
+	}
+}
+
+func compactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	var obj map[string]any
+	return len(raw) > 0 && json.Unmarshal(raw, &obj) == nil && obj != nil
+}
@@ -0,0 +1,215 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/stretchr/testify/require"
+)
+
+// transformFixtureUpstreamStub 与真实 HTTPUpstream 一样先交给干跑截获；走到真实发送即视为泄漏。
+type transformFixtureUpstreamStub struct {
+	sent int
+}
+
+func (u *transformFixtureUpstreamStub) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	if intercepted, err := InterceptUpstreamDryRun(req); intercepted {
+		return nil, err
+	}
+	u.sent++
+	return nil, errors.New("unexpected upstream request")
+}
+
+func (u *transformFixtureUpstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+type transformFixtureRepoStub struct {
+	fixtures map[int64]*TransformFixture
+	runs     map[int64]bool
+}
+
+func (r *transformFixtureRepoStub) Create(_ context.Context, f *TransformFixture) (*TransformFixture, error) {
+	f.ID = int64(len(r.fixtures) + 1)
+	r.fixtures[f.ID] = f
+	return f, nil
+}
+
+func (r *transformFixtureRepoStub) GetByID(_ context.Context, id int64) (*TransformFixture, error) {
+	if f, ok := r.fixtures[id]; ok {
+		return f, nil
+	}
+	return nil, ErrTransformFixtureNotFound
+}
+
+func (r *transformFixtureRepoStub) List(_ context.Context) ([]*TransformFixture, error) {
+	out := make([]*TransformFixture, 0, len(r.fixtures))
+	for id := int64(1); id <= int64(len(r.fixtures)); id++ {
+		out = append(out, r.fixtures[id])
+	}
+	return out, nil
+}
+
+func (r *transformFixtureRepoStub) Update(_ context.Context, f *TransformFixture) (*TransformFixture, error) {
+	r.fixtures[f.ID] = f
+	return f, nil
+}
+
+func (r *transformFixtureRepoStub) Delete(_ context.Context, id int64) error {
+	delete(r.fixtures, id)
+	return nil
+}
+
+func (r *transformFixtureRepoStub) RecordRun(_ context.Context, id int64, passed bool, _ time.Time) error {
+	r.runs[id] = passed
+	return nil
+}
+
+func newTransformFixtureTestService(t *testing.T) (*TransformFixtureService, *transformFixtureRepoStub, *transformFixtureUpstreamStub) {
+	t.Helper()
+	account := newAnthropicAPIKeyAccountForTest()
+	account.Extra = nil
+	account.Credentials["model_mapping"] = map[string]any{"claude-alias": "claude-sonnet-4-5"}
+
+	upstream := &transformFixtureUpstreamStub{}
+	cfg := &config.Config{Gateway: config.GatewayConfig{MaxLineSize: defaultMaxLineSize}}
+	gateway := &GatewayService{
+		cfg:                  cfg,
+		responseHeaderFilter: compileResponseHeaderFilter(cfg),
+		httpUpstream:         upstream,
+		rateLimitService:     &RateLimitService{},
+		deferredService:      &DeferredService{},
+	}
+	repo := &transformFixtureRepoStub{fixtures: map[int64]*TransformFixture{}, runs: map[int64]bool{}}
+	accounts := &opsReplayAccountRepoStub{accounts: map[int64]*Account{account.ID: account}}
+	return NewTransformFixtureService(repo, accounts, gateway, nil), repo, upstream
+}
+
+func transformFixtureMessagesInput(expectModel string) TransformFixtureInput {
+	return TransformFixtureInput{
+		Name:      "alias maps to sonnet",
+		Endpoint:  TransformFixtureEndpointMessages,
+		AccountID: 201,
+		Request: TransformFixtureRequest{
+			Headers: map[string]string{"anthropic-version": "2023-06-01"},
+			Body:    json.RawMessage(`{"model":"claude-alias","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`),
+		},
+		Expect: TransformFixtureExpectation{
+			Method:        http.MethodPost,
+			Host:          "api.anthropic.com",
+			Path:          "/v1/messages",
+			Headers:       map[string]string{"x-api-key": "*"},
+			AbsentHeaders: []string{"authorization"},
+			Body:          json.RawMessage(`{"model":"` + expectModel + `","max_tokens":16}`),
+		},
+	}
+}
+
+func TestTransformFixtureService_RunSavedFixture(t *testing.T) {
+	svc, repo, upstream := newTransformFixtureTestService(t)
+	ctx := context.Background()
+
+	passing, err := svc.Create(ctx, transformFixtureMessagesInput("claude-sonnet-4-5"), 1)
+	require.NoError(t, err)
+	failing := transformFixtureMessagesInput("claude-opus-4-1")
+	failing.Name = "stale expectation"
+	_, err = svc.Create(ctx, failing, 1)
+	require.NoError(t, err)
+
+	report, err := svc.Run(ctx, TransformFixtureRunInput{})
+	require.NoError(t, err)
+	require.Zero(t, upstream.sent, "dry run must not reach the upstream")
+	require.Equal(t, 2, report.Total)
+	require.Equal(t, 1, report.Passed)
+	require.Equal(t, 1, report.Failed)
+
+	ok := report.Results[0]
+	require.Equal(t, passing.ID, ok.FixtureID)
+	require.True(t, ok.Passed, "%+v", ok.Mismatches)
+	require.Empty(t, ok.Error)
+	require.Equal(t, "***", ok.Actual.Headers["X-Api-Key"], "captured credentials are redacted")
+
+	bad := report.Results[1]
+	require.False(t, bad.Passed)
+	require.Equal(t, []TransformFixtureMismatch{{Field: "body.model", Expected: `"claude-opus-4-1"`, Actual: `"claude-sonnet-4-5"`}}, bad.Mismatches)
+	require.Equal(t, map[int64]bool{1: true, 2: false}, repo.runs)
+}
+
+func TestTransformFixtureService_RunWithAccountDraft(t *testing.T) {
+	svc, repo, _ := newTransformFixtureTestService(t)
+	ctx := context.Background()
+	_, err := svc.Create(ctx, transformFixtureMessagesInput("claude-sonnet-4-5"), 1)
+	require.NoError(t, err)
+
+	report, err := svc.Run(ctx, TransformFixtureRunInput{
+		Accounts: []MappingSimulationAccountChange{{AccountID: 201, ModelMapping: map[string]any{"claude-alias": "claude-haiku-4-5"}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Failed, "the draft mapping breaks the saved expectation")
+	require.Equal(t, "body.model", report.Results[0].Mismatches[0].Field)
+	require.Empty(t, repo.runs, "draft runs do not overwrite the saved status")
+
+	inline := transformFixtureMessagesInput("claude-haiku-4-5")
+	report, err = svc.Run(ctx, TransformFixtureRunInput{
+		Fixtures: []TransformFixtureInput{inline},
+		Accounts: []MappingSimulationAccountChange{{AccountID: 201, ModelMapping: map[string]any{"claude-alias": "claude-haiku-4-5"}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Total, "inline fixtures alone do not pull in saved ones")
+	require.True(t, report.Results[0].Passed)
+}
+
+func TestTransformFixtureService_Validation(t *testing.T) {
+	svc, _, _ := newTransformFixtureTestService(t)
+	ctx := context.Background()
+
+	in := transformFixtureMessagesInput("x")
+	in.Endpoint = "/v1/embeddings"
+	_, err := svc.Create(ctx, in, 1)
+	require.ErrorIs(t, err, ErrTransformFixtureInvalidEndpoint)
+
+	in = transformFixtureMessagesInput("x")
+	in.Request.Body = json.RawMessage(`[1,2]`)
+	_, err = svc.Create(ctx, in, 1)
+	require.ErrorIs(t, err, ErrTransformFixtureInvalidRequest)
+
+	in = transformFixtureMessagesInput("x")
+	in.Expect = TransformFixtureExpectation{}
+	_, err = svc.Create(ctx, in, 1)
+	require.ErrorIs(t, err, ErrTransformFixtureInvalidExpect)
+
+	_, err = svc.Run(ctx, TransformFixtureRunInput{})
+	require.ErrorIs(t, err, ErrTransformFixtureRunEmpty)
+}
+
+func TestCompareTransformFixture(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/responses", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer sk")
+	req.Header.Set("OpenAI-Beta", "responses=v1")
+	body := []byte(`{"model":"gpt-5","input":[{"role":"user"},{"role":"assistant"}],"store":false}`)
+
+	mismatches := compareTransformFixture(TransformFixtureExpectation{
+		Path:          "/v1/chat/completions",
+		Headers:       map[string]string{"openai-beta": "responses=v2", "session_id": "*"},
+		AbsentHeaders: []string{"Authorization"},
+		Body:          json.RawMessage(`{"input":[{"role":"user"}],"store":false,"stream":true}`),
+	}, req, body)
+
+	require.Equal(t, []TransformFixtureMismatch{
+		{Field: "body.input", Expected: `[{"role":"user"}]`, Actual: `[{"role":"user"},{"role":"assistant"}]`},
+		{Field: "body.stream", Expected: "true", Actual: "<absent>"},
+		{Field: "headers.authorization", Expected: "<absent>", Actual: "Bearer sk"},
+		{Field: "headers.openai-beta", Expected: "responses=v2", Actual: "responses=v1"},
+		{Field: "headers.session_id", Expected: "*", Actual: "<absent>"},
+		{Field: "path", Expected: "/v1/chat/completions", Actual: "/v1/responses"},
+	}, mismatches)
+}
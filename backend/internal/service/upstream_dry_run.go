@@ -0,0 +1,57 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrUpstreamDryRun 干跑模式下上游请求已被截获、未实际发出；转发流程按网络错误处理并就此结束。
+var ErrUpstreamDryRun = errors.New("upstream dry-run: request captured, not sent")
+
+type upstreamDryRunCtxKey struct{}
+
+// upstreamDryRunCapture 记录干跑期间第一次上游请求（转换管线的最终产物）。
+type upstreamDryRunCapture struct {
+	mu   sync.Mutex
+	req  *http.Request
+	body []byte
+}
+
+func withUpstreamDryRun(ctx context.Context, capture *upstreamDryRunCapture) context.Context {
+	return context.WithValue(ctx, upstreamDryRunCtxKey{}, capture)
+}
+
+func (c *upstreamDryRunCapture) captured() (*http.Request, []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.req, c.body
+}
+
+// InterceptUpstreamDryRun 供 HTTPUpstream 实现在发出请求前调用：请求 ctx 处于干跑模式时记录请求并返回
+// (true, ErrUpstreamDryRun)，调用方应直接返回该错误而不发出请求；非干跑请求返回 (false, nil)。
+func InterceptUpstreamDryRun(req *http.Request) (bool, error) {
+	if req == nil {
+		return false, nil
+	}
+	capture, _ := req.Context().Value(upstreamDryRunCtxKey{}).(*upstreamDryRunCapture)
+	if capture == nil {
+		return false, nil
+	}
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	capture.mu.Lock()
+	if capture.req == nil {
+		capture.req = req
+		capture.body = body
+	}
+	capture.mu.Unlock()
+	return true, ErrUpstreamDryRun
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/util/httputil"
+	"github.com/tidwall/gjson"
+)
+
+// 上游错误归一化代码（存入 ops_error_logs.error_code）。
+// 各家上游（OpenAI API、ChatGPT 内部接口、Anthropic、Gemini、代理 HTML 错误页）的错误体格式各异，
+// 看板与冷却策略应以这些稳定代码为准，而不是对 message 做正则匹配。
+const (
+	UpstreamErrorCodeRateLimited           = "rate_limited"
+	UpstreamErrorCodeQuotaExhausted        = "quota_exhausted"
+	UpstreamErrorCodeOverloaded            = "overloaded"
+	UpstreamErrorCodeAuthInvalid           = "auth_invalid"
+	UpstreamErrorCodePermissionDenied      = "permission_denied"
+	UpstreamErrorCodeAccountDisabled       = "account_disabled"
+	UpstreamErrorCodeContextLengthExceeded = "context_length_exceeded"
+	UpstreamErrorCodeInvalidRequest        = "invalid_request"
+	UpstreamErrorCodeModelNotFound         = "model_not_found"
+	UpstreamErrorCodeNotFound              = "not_found"
+	UpstreamErrorCodeContentPolicy         = "content_policy"
+	UpstreamErrorCodeTimeout               = "upstream_timeout"
+	UpstreamErrorCodeUnavailable           = "upstream_unavailable"
+	UpstreamErrorCodeServerError           = "server_error"
+	UpstreamErrorCodeHTMLErrorPage         = "html_error_page"
+	UpstreamErrorCodeCloudflareChallenge   = "cloudflare_challenge"
+	UpstreamErrorCodeInvalidResponse       = "invalid_response"
+	UpstreamErrorCodeNetworkError          = "network_error"
+	UpstreamErrorCodeUnknown               = "unknown"
+)
+
+// upstreamErrorTokenCodes 上游结构化错误标识（error.type / error.code / error.status 等）到归一化代码的映射。
+var upstreamErrorTokenCodes = map[string]string{
+	// 限流
+	"rate_limit_error":    UpstreamErrorCodeRateLimited,
+	"rate_limit_exceeded": UpstreamErrorCodeRateLimited,
+	"rate_limited":        UpstreamErrorCodeRateLimited,
+	"too_many_requests":   UpstreamErrorCodeRateLimited,
+	"resource_exhausted":  UpstreamErrorCodeRateLimited,
+	// 额度 / 计费
+	"insufficient_quota":          UpstreamErrorCodeQuotaExhausted,
+	"quota_exceeded":              UpstreamErrorCodeQuotaExhausted,
+	"usage_limit_reached":         UpstreamErrorCodeQuotaExhausted,
+	"billing_hard_limit_reached":  UpstreamErrorCodeQuotaExhausted,
+	"billing_not_active":          UpstreamErrorCodeQuotaExhausted,
+	"credit_balance_too_low":      UpstreamErrorCodeQuotaExhausted,
+	"insufficient_balance":        UpstreamErrorCodeQuotaExhausted,
+	"payment_required":            UpstreamErrorCodeQuotaExhausted,
+	"usage_not_included":          UpstreamErrorCodeQuotaExhausted,
+	"model_cap_exceeded":          UpstreamErrorCodeQuotaExhausted,
+	"subscription_limit_exceeded": UpstreamErrorCodeQuotaExhausted,
+	// 过载
+	"overloaded_error":     UpstreamErrorCodeOverloaded,
+	"overloaded":           UpstreamErrorCodeOverloaded,
+	"server_is_overloaded": UpstreamErrorCodeOverloaded,
+	"engine_overloaded":    UpstreamErrorCodeOverloaded,
+	// 鉴权
+	"authentication_error":   UpstreamErrorCodeAuthInvalid,
+	"invalid_api_key":        UpstreamErrorCodeAuthInvalid,
+	"invalid_authentication": UpstreamErrorCodeAuthInvalid,
+	"unauthenticated":        UpstreamErrorCodeAuthInvalid,
+	"token_expired":          UpstreamErrorCodeAuthInvalid,
+	"token_invalidated":      UpstreamErrorCodeAuthInvalid,
+	"token_revoked":          UpstreamErrorCodeAuthInvalid,
+	"invalid_grant":          UpstreamErrorCodeAuthInvalid,
+	"oauth_error":            UpstreamErrorCodeAuthInvalid,
+	// 权限
+	"permission_error":                     UpstreamErrorCodePermissionDenied,
+	"permission_denied":                    UpstreamErrorCodePermissionDenied,
+	"forbidden":                            UpstreamErrorCodePermissionDenied,
+	"unsupported_country_region_territory": UpstreamErrorCodePermissionDenied,
+	"failed_precondition":                  UpstreamErrorCodePermissionDenied,
+	// 账号被封禁 / 停用
+	"account_deactivated":      UpstreamErrorCodeAccountDisabled,
+	"account_disabled":         UpstreamErrorCodeAccountDisabled,
+	"account_suspended":        UpstreamErrorCodeAccountDisabled,
+	"organization_deactivated": UpstreamErrorCodeAccountDisabled,
+	"organization_disabled":    UpstreamErrorCodeAccountDisabled,
+	"user_deactivated":         UpstreamErrorCodeAccountDisabled,
+	"deactivated_workspace":    UpstreamErrorCodeAccountDisabled,
+	// 上下文
+	"context_length_exceeded": UpstreamErrorCodeContextLengthExceeded,
+	"context_too_large":       UpstreamErrorCodeContextLengthExceeded,
+	"string_above_max_length": UpstreamErrorCodeContextLengthExceeded,
+	// 请求本身错误
+	"invalid_request_error": UpstreamErrorCodeInvalidRequest,
+	"invalid_request":       UpstreamErrorCodeInvalidRequest,
+	"invalid_argument":      UpstreamErrorCodeInvalidRequest,
+	"invalid_value":         UpstreamErrorCodeInvalidRequest,
+	"bad_request":           UpstreamErrorCodeInvalidRequest,
+	"request_too_large":     UpstreamErrorCodeInvalidRequest,
+	"unsupported_parameter": UpstreamErrorCodeInvalidRequest,
+	// 模型 / 资源不存在
+	"model_not_found":     UpstreamErrorCodeModelNotFound,
+	"model_not_supported": UpstreamErrorCodeModelNotFound,
+	"unsupported_model":   UpstreamErrorCodeModelNotFound,
+	"not_found_error":     UpstreamErrorCodeNotFound,
+	"not_found":           UpstreamErrorCodeNotFound,
+	// 内容安全
+	"content_policy_violation": UpstreamErrorCodeContentPolicy,
+	"content_filter":           UpstreamErrorCodeContentPolicy,
+	"cyber_policy":             UpstreamErrorCodeContentPolicy,
+	"safety":                   UpstreamErrorCodeContentPolicy,
+	// 超时 / 不可用 / 服务端错误
+	"timeout_error":         UpstreamErrorCodeTimeout,
+	"timeout":               UpstreamErrorCodeTimeout,
+	"deadline_exceeded":     UpstreamErrorCodeTimeout,
+	"unavailable":           UpstreamErrorCodeUnavailable,
+	"service_unavailable":   UpstreamErrorCodeUnavailable,
+	"api_error":             UpstreamErrorCodeServerError,
+	"server_error":          UpstreamErrorCodeServerError,
+	"internal":              UpstreamErrorCodeServerError,
+	"internal_error":        UpstreamErrorCodeServerError,
+	"internal_server_error": UpstreamErrorCodeServerError,
+	// 网关把 HTML 错误页替换为 JSON 后写入的代码（见 upstream_html_guard.go）
+	UpstreamErrorCodeHTMLErrorPage:       UpstreamErrorCodeHTMLErrorPage,
+	UpstreamErrorCodeCloudflareChallenge: UpstreamErrorCodeCloudflareChallenge,
+}
+
+// upstreamErrorTokenPaths 按优先级尝试的结构化错误标识字段。
+// error.code 通常比 error.type 更具体（如 OpenAI 的 invalid_request_error + context_length_exceeded）。
+var upstreamErrorTokenPaths = []string{
+	"error.code",
+	"error.type",
+	"error.status",
+	"response.error.code",
+	"response.error.type",
+	"detail.code",
+	"code",
+	"type",
+}
+
+// NormalizeUpstreamErrorCode 将上游状态码/错误消息/错误体归一化为稳定错误代码。
+// 判定顺序：HTML 错误页 → 结构化错误标识 → 消息关键词 → HTTP 状态码兜底。
+// statusCode 为 0 且没有任何可识别内容时返回 network_error（请求未拿到上游响应）。
+func NormalizeUpstreamErrorCode(statusCode int, message string, body []byte) string {
+	if httputil.LooksLikeHTML(body) || httputil.LooksLikeHTML([]byte(message)) {
+		if httputil.HasCloudflareChallengeMarkers(nil, body) || httputil.HasCloudflareChallengeMarkers(nil, []byte(message)) {
+			return UpstreamErrorCodeCloudflareChallenge
+		}
+		return UpstreamErrorCodeHTMLErrorPage
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" && len(body) > 0 {
+		message = strings.TrimSpace(extractUpstreamErrorMessage(body))
+	}
+	lowerMsg := strings.ToLower(message)
+
+	if len(body) > 0 && gjson.ValidBytes(body) {
+		for _, path := range upstreamErrorTokenPaths {
+			token := normalizeUpstreamErrorToken(gjson.GetBytes(body, path).String())
+			if token == "" {
+				continue
+			}
+			code, ok := upstreamErrorTokenCodes[token]
+			if !ok {
+				continue
+			}
+			return refineUpstreamErrorCode(code, statusCode, lowerMsg, body)
+		}
+		if inner := extractUpstreamErrorCode(body); inner != "" {
+			if code, ok := upstreamErrorTokenCodes[normalizeUpstreamErrorToken(inner)]; ok {
+				return refineUpstreamErrorCode(code, statusCode, lowerMsg, body)
+			}
+		}
+	}
+
+	if code := upstreamErrorCodeFromMessage(lowerMsg, body); code != "" {
+		return code
+	}
+	if code := upstreamErrorCodeFromStatus(statusCode); code != "" {
+		return code
+	}
+	if statusCode <= 0 {
+		return UpstreamErrorCodeNetworkError
+	}
+	return UpstreamErrorCodeUnknown
+}
+
+// refineUpstreamErrorCode 对过于宽泛的结构化标识按消息内容细化。
+func refineUpstreamErrorCode(code string, statusCode int, lowerMsg string, body []byte) string {
+	switch code {
+	case UpstreamErrorCodeRateLimited:
+		// Gemini RESOURCE_EXHAUSTED 同时用于限流与额度耗尽。
+		if strings.Contains(lowerMsg, "quota") || strings.Contains(lowerMsg, "billing") {
+			return UpstreamErrorCodeQuotaExhausted
+		}
+	case UpstreamErrorCodeInvalidRequest, UpstreamErrorCodeNotFound:
+		// Anthropic 超长上下文：invalid_request_error + "prompt is too long"。
+		if isOpenAIContextWindowError(lowerMsg, body) || strings.Contains(lowerMsg, "prompt is too long") {
+			return UpstreamErrorCodeContextLengthExceeded
+		}
+		if isUpstreamModelNotFoundMessage(lowerMsg) {
+			return UpstreamErrorCodeModelNotFound
+		}
+	case UpstreamErrorCodeServerError:
+		if statusCode == http.StatusTooManyRequests {
+			return UpstreamErrorCodeRateLimited
+		}
+		if statusCode == 529 || strings.Contains(lowerMsg, "overloaded") {
+			return UpstreamErrorCodeOverloaded
+		}
+	case UpstreamErrorCodePermissionDenied:
+		if isUpstreamAccountDisabledMessage(lowerMsg) {
+			return UpstreamErrorCodeAccountDisabled
+		}
+	}
+	return code
+}
+
+func upstreamErrorCodeFromMessage(lowerMsg string, body []byte) string {
+	if lowerMsg == "" {
+		return ""
+	}
+	switch {
+	case isOpenAIContextWindowError(lowerMsg, body):
+		return UpstreamErrorCodeContextLengthExceeded
+	case isUpstreamAccountDisabledMessage(lowerMsg):
+		return UpstreamErrorCodeAccountDisabled
+	case strings.Contains(lowerMsg, "insufficient_quota"), strings.Contains(lowerMsg, "exceeded your current quota"),
+		strings.Contains(lowerMsg, "usage limit"), strings.Contains(lowerMsg, "credit balance"):
+		return UpstreamErrorCodeQuotaExhausted
+	case strings.Contains(lowerMsg, "rate limit"), strings.Contains(lowerMsg, "too many requests"):
+		return UpstreamErrorCodeRateLimited
+	case strings.Contains(lowerMsg, "overloaded"):
+		return UpstreamErrorCodeOverloaded
+	case isUpstreamModelNotFoundMessage(lowerMsg):
+		return UpstreamErrorCodeModelNotFound
+	case strings.Contains(lowerMsg, "invalid api key"), strings.Contains(lowerMsg, "invalid x-api-key"),
+		strings.Contains(lowerMsg, "token has expired"), strings.Contains(lowerMsg, "token is expired"):
+		return UpstreamErrorCodeAuthInvalid
+	case strings.Contains(lowerMsg, "timeout"), strings.Contains(lowerMsg, "timed out"):
+		return UpstreamErrorCodeTimeout
+	}
+	return ""
+}
+
+func upstreamErrorCodeFromStatus(statusCode int) string {
+	switch {
+	case statusCode == http.StatusBadRequest, statusCode == http.StatusUnprocessableEntity,
+		statusCode == http.StatusRequestEntityTooLarge:
+		return UpstreamErrorCodeInvalidRequest
+	case statusCode == http.StatusUnauthorized:
+		return UpstreamErrorCodeAuthInvalid
+	case statusCode == http.StatusPaymentRequired:
+		return UpstreamErrorCodeQuotaExhausted
+	case statusCode == http.StatusForbidden:
+		return UpstreamErrorCodePermissionDenied
+	case statusCode == http.StatusNotFound:
+		return UpstreamErrorCodeNotFound
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusGatewayTimeout,
+		statusCode == 524:
+		return UpstreamErrorCodeTimeout
+	case statusCode == http.StatusTooManyRequests:
+		return UpstreamErrorCodeRateLimited
+	case statusCode == 529:
+		return UpstreamErrorCodeOverloaded
+	case statusCode == http.StatusBadGateway, statusCode == http.StatusServiceUnavailable,
+		statusCode == 520, statusCode == 521, statusCode == 522, statusCode == 523:
+		return UpstreamErrorCodeUnavailable
+	case statusCode >= 500:
+		return UpstreamErrorCodeServerError
+	}
+	return ""
+}
+
+func normalizeUpstreamErrorToken(raw string) string {
+	token := strings.ToLower(strings.TrimSpace(raw))
+	token = strings.ReplaceAll(token, "-", "_")
+	token = strings.ReplaceAll(token, " ", "_")
+	return token
+}
+
+func isUpstreamModelNotFoundMessage(lowerMsg string) bool {
+	if !strings.Contains(lowerMsg, "model") {
+		return false
+	}
+	return strings.Contains(lowerMsg, "not found") ||
+		strings.Contains(lowerMsg, "does not exist") ||
+		strings.Contains(lowerMsg, "not supported") ||
+		strings.Contains(lowerMsg, "no such model")
+}
+
+func isUpstreamAccountDisabledMessage(lowerMsg string) bool {
+	return strings.Contains(lowerMsg, "account has been deactivated") ||
+		strings.Contains(lowerMsg, "account has been disabled") ||
+		strings.Contains(lowerMsg, "account is suspended") ||
+		strings.Contains(lowerMsg, "account has been suspended") ||
+		strings.Contains(lowerMsg, "organization has been disabled") ||
+		strings.Contains(lowerMsg, "organization has been deactivated")
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeUpstreamErrorCode(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		status  int
+		message string
+		body    string
+		want    string
+	}{
+		{
+			name:   "openai rate limit",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"message":"Rate limit reached for gpt-4o","type":"requests","code":"rate_limit_exceeded"}}`,
+			want:   UpstreamErrorCodeRateLimited,
+		},
+		{
+			name:   "openai insufficient quota",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			want:   UpstreamErrorCodeQuotaExhausted,
+		},
+		{
+			name:   "openai context length via code",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"message":"This model's maximum context length is 128000 tokens","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			want:   UpstreamErrorCodeContextLengthExceeded,
+		},
+		{
+			name:   "openai model not found",
+			status: http.StatusNotFound,
+			body:   `{"error":{"message":"The model gpt-9 does not exist","type":"invalid_request_error","code":"model_not_found"}}`,
+			want:   UpstreamErrorCodeModelNotFound,
+		},
+		{
+			name:   "openai invalid api key",
+			status: http.StatusUnauthorized,
+			body:   `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			want:   UpstreamErrorCodeAuthInvalid,
+		},
+		{
+			name:   "chatgpt usage limit",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"type":"usage_limit_reached","message":"The usage limit has been reached","resets_in_seconds":3600}}`,
+			want:   UpstreamErrorCodeQuotaExhausted,
+		},
+		{
+			name:   "chatgpt detail string falls back to status",
+			status: http.StatusUnauthorized,
+			body:   `{"detail":"Unauthorized"}`,
+			want:   UpstreamErrorCodeAuthInvalid,
+		},
+		{
+			name:   "chatgpt account deactivated",
+			status: http.StatusUnauthorized,
+			body:   `{"error":{"message":"Your OpenAI account has been deactivated","code":"account_deactivated"}}`,
+			want:   UpstreamErrorCodeAccountDisabled,
+		},
+		{
+			name:   "anthropic overloaded",
+			status: 529,
+			body:   `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			want:   UpstreamErrorCodeOverloaded,
+		},
+		{
+			name:   "anthropic prompt too long",
+			status: http.StatusBadRequest,
+			body:   `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`,
+			want:   UpstreamErrorCodeContextLengthExceeded,
+		},
+		{
+			name:   "anthropic permission disabled org",
+			status: http.StatusForbidden,
+			body:   `{"type":"error","error":{"type":"permission_error","message":"This organization has been disabled."}}`,
+			want:   UpstreamErrorCodeAccountDisabled,
+		},
+		{
+			name:   "anthropic api_error",
+			status: http.StatusInternalServerError,
+			body:   `{"type":"error","error":{"type":"api_error","message":"Internal server error"}}`,
+			want:   UpstreamErrorCodeServerError,
+		},
+		{
+			name:   "gemini resource exhausted quota",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"code":429,"message":"You exceeded your current quota, please check your plan and billing details.","status":"RESOURCE_EXHAUSTED"}}`,
+			want:   UpstreamErrorCodeQuotaExhausted,
+		},
+		{
+			name:   "gemini resource exhausted rate",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"code":429,"message":"Resource has been exhausted (e.g. check rate).","status":"RESOURCE_EXHAUSTED"}}`,
+			want:   UpstreamErrorCodeRateLimited,
+		},
+		{
+			name:   "html proxy page",
+			status: http.StatusBadGateway,
+			body:   "<!DOCTYPE html>\n<html><head><title>502 Bad Gateway</title></head><body>nginx</body></html>",
+			want:   UpstreamErrorCodeHTMLErrorPage,
+		},
+		{
+			name:    "message keyword only",
+			status:  http.StatusBadRequest,
+			message: "Input exceeds the context window of this model",
+			want:    UpstreamErrorCodeContextLengthExceeded,
+		},
+		{
+			name:   "status fallback 503",
+			status: http.StatusServiceUnavailable,
+			body:   `upstream connect error`,
+			want:   UpstreamErrorCodeUnavailable,
+		},
+		{
+			name:   "status fallback 504",
+			status: http.StatusGatewayTimeout,
+			want:   UpstreamErrorCodeTimeout,
+		},
+		{
+			name:    "no response",
+			message: "dial tcp: connection refused",
+			want:    UpstreamErrorCodeNetworkError,
+		},
+		{
+			name:   "unrecognized 3xx",
+			status: http.StatusTemporaryRedirect,
+			want:   UpstreamErrorCodeUnknown,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, NormalizeUpstreamErrorCode(tc.status, tc.message, []byte(tc.body)))
+		})
+	}
+}
+
+func TestOpsServiceRecordErrorBatch_DerivesErrorCode(t *testing.T) {
+	t.Parallel()
+
+	var captured []*OpsInsertErrorLogInput
+	repo := &opsRepoMock{
+		BatchInsertErrorLogsFn: func(ctx context.Context, inputs []*OpsInsertErrorLogInput) (int64, error) {
+			captured = append(captured, inputs...)
+			return int64(len(inputs)), nil
+		},
+	}
+	svc := NewOpsService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	entries := []*OpsInsertErrorLogInput{
+		{
+			ErrorPhase:          "upstream",
+			StatusCode:          http.StatusBadGateway,
+			UpstreamStatusCode:  intPtr(http.StatusTooManyRequests),
+			UpstreamErrorDetail: strPtr(`{"error":{"type":"insufficient_quota","message":"quota"}}`),
+			UpstreamErrors: []*OpsUpstreamErrorEvent{
+				{Kind: "request_error", Message: "dial tcp: i/o timeout"},
+				{Kind: "http_error", UpstreamStatusCode: 529, Detail: `{"type":"error","error":{"type":"overloaded_error"}}`},
+			},
+		},
+		{ErrorPhase: "auth", StatusCode: http.StatusUnauthorized, ErrorMessage: "invalid api key"},
+		{ErrorPhase: "upstream", StatusCode: http.StatusBadGateway, ErrorCode: UpstreamErrorCodeHTMLErrorPage},
+	}
+
+	require.NoError(t, svc.RecordErrorBatch(context.Background(), entries))
+	require.Len(t, captured, 3)
+
+	require.Equal(t, UpstreamErrorCodeQuotaExhausted, captured[0].ErrorCode)
+	require.NotNil(t, captured[0].UpstreamErrorsJSON)
+	var events []*OpsUpstreamErrorEvent
+	require.NoError(t, json.Unmarshal([]byte(*captured[0].UpstreamErrorsJSON), &events))
+	require.Len(t, events, 2)
+	require.Equal(t, UpstreamErrorCodeNetworkError, events[0].ErrorCode)
+	require.Equal(t, UpstreamErrorCodeOverloaded, events[1].ErrorCode)
+
+	require.Empty(t, captured[1].ErrorCode, "non-upstream errors keep error_code empty")
+	require.Equal(t, UpstreamErrorCodeHTMLErrorPage, captured[2].ErrorCode)
+}
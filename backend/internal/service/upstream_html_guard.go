@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/util/httputil"
+	"github.com/tidwall/gjson"
+)
+
+// upstreamHTMLErrorType 网关把上游 HTML 错误页替换为 JSON 错误体时使用的 error.type。
+// 下游处理（限流/冷却、ops 归类）据此识别「上游实际返回的是 HTML」，而不看被替换后的状态码。
+const upstreamHTMLErrorType = "upstream_html_error"
+
+// defaultProxyChallengeCooldownMinutes 命中 Cloudflare challenge / 代理错误页后账号的默认冷却时间。
+const defaultProxyChallengeCooldownMinutes = 10
+
+// upstreamHTMLErrorBody 与 Anthropic / OpenAI 两种错误格式都兼容的 JSON 错误体。
+type upstreamHTMLErrorBody struct {
+	Type  string                    `json:"type"`
+	Error upstreamHTMLErrorBodyItem `json:"error"`
+}
+
+type upstreamHTMLErrorBodyItem struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// classifyUpstreamHTMLResponse 判断上游响应是否为 HTML 页面，并区分 Cloudflare challenge 与普通代理/CDN 错误页。
+// 非 HTML 返回 ""。
+func classifyUpstreamHTMLResponse(headers http.Header, body []byte) string {
+	if !httputil.IsHTMLResponse(headers, body) {
+		return ""
+	}
+	if httputil.HasCloudflareChallengeMarkers(headers, body) {
+		return UpstreamErrorCodeCloudflareChallenge
+	}
+	return UpstreamErrorCodeHTMLErrorPage
+}
+
+// replaceUpstreamHTMLErrorBody 在上游错误响应为 HTML 页面时，将其替换为 JSON 错误体并改写 resp.Header 的 Content-Type，
+// 保证后续任何透传路径都不会把 HTML 转发给 JSON 客户端。非 HTML 响应原样返回。
+func replaceUpstreamHTMLErrorBody(resp *http.Response, body []byte) []byte {
+	if resp == nil {
+		return body
+	}
+	code := classifyUpstreamHTMLResponse(resp.Header, body)
+	if code == "" {
+		return body
+	}
+
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	payload, err := json.Marshal(upstreamHTMLErrorBody{
+		Type: "error",
+		Error: upstreamHTMLErrorBodyItem{
+			Type:    upstreamHTMLErrorType,
+			Code:    code,
+			Message: upstreamHTMLErrorMessage(code, resp.StatusCode, resp.Header, body),
+		},
+	})
+	if err != nil {
+		return body
+	}
+	resp.Header.Set("Content-Type", "application/json")
+	resp.Header.Del("Content-Length")
+	resp.Header.Del("Content-Encoding")
+	return payload
+}
+
+func upstreamHTMLErrorMessage(code string, statusCode int, headers http.Header, body []byte) string {
+	if code == UpstreamErrorCodeCloudflareChallenge {
+		return httputil.FormatCloudflareChallengeMessage(
+			fmt.Sprintf("Upstream returned a Cloudflare challenge page (HTTP %d); the egress IP or proxy is being challenged", statusCode),
+			headers,
+			body,
+		)
+	}
+	msg := fmt.Sprintf("Upstream returned an HTML error page (HTTP %d)", statusCode)
+	if title := truncateString(httputil.ExtractHTMLTitle(body), 120); title != "" {
+		msg += ": " + title
+	}
+	return msg
+}
+
+// upstreamHTMLErrorCode 返回 replaceUpstreamHTMLErrorBody 写入的归一化代码；不是由此生成的错误体返回 ""。
+func upstreamHTMLErrorCode(body []byte) string {
+	if len(body) == 0 || gjson.GetBytes(body, "error.type").String() != upstreamHTMLErrorType {
+		return ""
+	}
+	return gjson.GetBytes(body, "error.code").String()
+}
+
+// handleUpstreamHTMLResponse 处理上游返回 HTML（Cloudflare challenge / 代理错误页）的情况。
+// 这类错误出在出口 IP / 代理而非账号本身，因此不走 403/5xx 的账号禁用逻辑：
+//   - 账号短暂冷却（同一代理下立即重试大概率再次被拦截），冷却到期自动恢复；
+//   - 账号绑定了代理时，把本次拦截记入代理质量快照，管理后台代理列表可直接看到。
+func (s *RateLimitService) handleUpstreamHTMLResponse(ctx context.Context, account *Account, statusCode int, code string, responseBody []byte) {
+	message := strings.TrimSpace(gjson.GetBytes(responseBody, "error.message").String())
+
+	cooldownMinutes := defaultProxyChallengeCooldownMinutes
+	if s.cfg != nil && s.cfg.RateLimit.ProxyChallengeCooldownMinutes > 0 {
+		cooldownMinutes = s.cfg.RateLimit.ProxyChallengeCooldownMinutes
+	}
+	until := time.Now().Add(time.Duration(cooldownMinutes) * time.Minute)
+	reason := fmt.Sprintf("Upstream %s (%d): %s", code, statusCode, message)
+	if account.ProxyID != nil {
+		reason = fmt.Sprintf("Proxy #%d %s (%d): %s", *account.ProxyID, code, statusCode, message)
+	}
+	s.notifyAccountSchedulingBlocked(account, until, code)
+	if err := s.accountRepo.SetTempUnschedulable(ctx, account.ID, until, reason); err != nil {
+		slog.Warn("upstream_html_set_temp_unschedulable_failed", "account_id", account.ID, "error", err)
+	}
+
+	slog.Warn("upstream_html_response",
+		"account_id", account.ID,
+		"proxy_id", derefInt64(account.ProxyID),
+		"status_code", statusCode,
+		"code", code,
+		"until", until,
+	)
+
+	if account.ProxyID != nil {
+		s.penalizeProxyHealth(ctx, *account.ProxyID, code, message)
+	}
+}
+
+// penalizeProxyHealth 将线上请求命中的代理拦截写入代理质量快照（与后台「质量检测」共用同一缓存）。
+// 只覆盖质量字段，保留已有的延迟与出口信息；下一次成功的质量检测会覆盖此结果。
+func (s *RateLimitService) penalizeProxyHealth(ctx context.Context, proxyID int64, code, message string) {
+	if s.proxyLatencyCache == nil {
+		return
+	}
+	info := &ProxyLatencyInfo{}
+	if latencies, err := s.proxyLatencyCache.GetProxyLatencies(ctx, []int64{proxyID}); err == nil {
+		if existing := latencies[proxyID]; existing != nil {
+			copied := *existing
+			info = &copied
+		}
+	}
+
+	status, score := "fail", 40
+	if code == UpstreamErrorCodeCloudflareChallenge {
+		status, score = "challenge", 30
+	}
+	if info.QualityScore != nil && *info.QualityScore < score {
+		score = *info.QualityScore
+	}
+	checkedAt := time.Now().Unix()
+	info.QualityStatus = status
+	info.QualityScore = &score
+	info.QualityGrade = proxyQualityGrade(score)
+	info.QualitySummary = truncateString("线上请求被拦截: "+message, 256)
+	info.QualityCheckedAt = &checkedAt
+	if code == UpstreamErrorCodeCloudflareChallenge {
+		info.QualityCFRay = httputil.ExtractCloudflareRayID(nil, []byte(message))
+	}
+	info.UpdatedAt = time.Now()
+
+	if err := s.proxyLatencyCache.SetProxyLatency(ctx, proxyID, info); err != nil {
+		slog.Warn("proxy_health_penalty_store_failed", "proxy_id", proxyID, "error", err)
+	}
+}
+
+func derefInt64(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,131 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type proxyLatencyCacheRecorder struct {
+	stored map[int64]*ProxyLatencyInfo
+}
+
+func (c *proxyLatencyCacheRecorder) GetProxyLatencies(_ context.Context, proxyIDs []int64) (map[int64]*ProxyLatencyInfo, error) {
+	out := make(map[int64]*ProxyLatencyInfo)
+	for _, id := range proxyIDs {
+		if info, ok := c.stored[id]; ok {
+			out[id] = info
+		}
+	}
+	return out, nil
+}
+
+func (c *proxyLatencyCacheRecorder) SetProxyLatency(_ context.Context, proxyID int64, info *ProxyLatencyInfo) error {
+	if c.stored == nil {
+		c.stored = make(map[int64]*ProxyLatencyInfo)
+	}
+	c.stored[proxyID] = info
+	return nil
+}
+
+const cloudflareChallengeHTML = `<!DOCTYPE html><html lang="en-US"><head><title>Just a moment...</title></head>
+<body><script>window._cf_chl_opt={cRay: '8f1e2d3c4b5a6978'};</script></body></html>`
+
+func TestReplaceUpstreamHTMLErrorBody_CloudflareChallenge(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header: http.Header{
+			"Content-Type":   []string{"text/html; charset=UTF-8"},
+			"Content-Length": []string{"1234"},
+			"Cf-Ray":         []string{"8f1e2d3c4b5a6978-LAX"},
+		},
+	}
+
+	body := replaceUpstreamHTMLErrorBody(resp, []byte(cloudflareChallengeHTML))
+
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.Empty(t, resp.Header.Get("Content-Length"))
+	require.True(t, gjson.ValidBytes(body))
+	require.Equal(t, "error", gjson.GetBytes(body, "type").String())
+	require.Equal(t, upstreamHTMLErrorType, gjson.GetBytes(body, "error.type").String())
+	require.Equal(t, UpstreamErrorCodeCloudflareChallenge, gjson.GetBytes(body, "error.code").String())
+	require.Contains(t, gjson.GetBytes(body, "error.message").String(), "cf-ray: 8f1e2d3c4b5a6978-LAX")
+	require.NotContains(t, string(body), "<html")
+
+	require.Equal(t, UpstreamErrorCodeCloudflareChallenge, upstreamHTMLErrorCode(body))
+	require.Equal(t, UpstreamErrorCodeCloudflareChallenge, NormalizeUpstreamErrorCode(http.StatusForbidden, "", body))
+}
+
+func TestReplaceUpstreamHTMLErrorBody_ProxyErrorPage(t *testing.T) {
+	// 无 Content-Type 时也要靠 HTML 文档特征识别。
+	resp := &http.Response{StatusCode: http.StatusBadGateway}
+	page := "<html>\n<head><title>502 Bad Gateway</title></head>\n<body><center><h1>502 Bad Gateway</h1></center><hr><center>nginx</center></body>\n</html>"
+
+	body := replaceUpstreamHTMLErrorBody(resp, []byte(page))
+
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.Equal(t, UpstreamErrorCodeHTMLErrorPage, upstreamHTMLErrorCode(body))
+	require.Equal(t, "Upstream returned an HTML error page (HTTP 502): 502 Bad Gateway", gjson.GetBytes(body, "error.message").String())
+}
+
+func TestReplaceUpstreamHTMLErrorBody_KeepsJSON(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}
+	raw := []byte(`{"type":"error","error":{"type":"permission_error","message":"denied"}}`)
+
+	require.Equal(t, raw, replaceUpstreamHTMLErrorBody(resp, raw))
+	require.Empty(t, upstreamHTMLErrorCode(raw))
+}
+
+func TestRateLimitService_HandleUpstreamError_HTMLChallengeCoolsDownInsteadOfDisabling(t *testing.T) {
+	repo := &rateLimitAccountRepoStub{}
+	cache := &proxyLatencyCacheRecorder{}
+	svc := NewRateLimitService(repo, nil, &config.Config{}, nil, nil)
+	svc.SetProxyLatencyCache(cache)
+
+	proxyID := int64(7)
+	account := &Account{ID: 42, Platform: PlatformAnthropic, Type: AccountTypeOAuth, ProxyID: &proxyID}
+	resp := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{"Content-Type": []string{"text/html"}, "Cf-Ray": []string{"abc123-SJC"}},
+	}
+	body := replaceUpstreamHTMLErrorBody(resp, []byte(cloudflareChallengeHTML))
+
+	shouldDisable := svc.HandleUpstreamError(context.Background(), account, resp.StatusCode, resp.Header, body)
+
+	require.True(t, shouldDisable, "challenge should fail over to another account")
+	require.Zero(t, repo.setErrorCalls, "403 challenge must not permanently disable the account")
+	require.Equal(t, 1, repo.tempCalls)
+	require.Contains(t, repo.lastTempReason, "Proxy #7 cloudflare_challenge")
+
+	info := cache.stored[proxyID]
+	require.NotNil(t, info)
+	require.Equal(t, "challenge", info.QualityStatus)
+	require.NotNil(t, info.QualityScore)
+	require.Equal(t, 30, *info.QualityScore)
+	require.Equal(t, "abc123-SJC", info.QualityCFRay)
+}
+
+func TestRateLimitService_HandleUpstreamError_HTMLWithoutProxySkipsProxyPenalty(t *testing.T) {
+	repo := &rateLimitAccountRepoStub{}
+	cache := &proxyLatencyCacheRecorder{}
+	svc := NewRateLimitService(repo, nil, &config.Config{RateLimit: config.RateLimitConfig{ProxyChallengeCooldownMinutes: 3}}, nil, nil)
+	svc.SetProxyLatencyCache(cache)
+
+	account := &Account{ID: 43, Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+	resp := &http.Response{StatusCode: http.StatusBadGateway}
+	body := replaceUpstreamHTMLErrorBody(resp, []byte("<html><head><title>Bad gateway</title></head></html>"))
+
+	require.True(t, svc.HandleUpstreamError(context.Background(), account, resp.StatusCode, resp.Header, body))
+	require.Equal(t, 1, repo.tempCalls)
+	require.Contains(t, repo.lastTempReason, "html_error_page")
+	require.Empty(t, cache.stored)
+}
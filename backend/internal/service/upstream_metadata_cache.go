@@ -0,0 +1,100 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	gocache "github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultUpstreamMetadataCacheTTL = 5 * time.Minute
+
+var (
+	upstreamMetadataCacheHitTotal   atomic.Int64
+	upstreamMetadataCacheMissTotal  atomic.Int64
+	upstreamMetadataCacheStoreTotal atomic.Int64
+)
+
+// GatewayUpstreamMetadataCacheStats 返回上游元数据缓存（模型列表/模型详情/Codex manifest）的命中、未命中与写入次数。
+func GatewayUpstreamMetadataCacheStats() (cacheHit, cacheMiss, store int64) {
+	return upstreamMetadataCacheHitTotal.Load(), upstreamMetadataCacheMissTotal.Load(), upstreamMetadataCacheStoreTotal.Load()
+}
+
+// upstreamMetadataCache 按账号缓存上游元数据 GET 响应。
+// 客户端（IDE 插件、Codex CLI 等）会高频轮询模型列表，这些响应对同一账号在短时间内不会变化，
+// 本地命中即可避免每次都打到上游。只缓存 2xx 响应，错误与限流状态始终透传。
+// nil 表示关闭缓存，调用方直接走上游。
+type upstreamMetadataCache struct {
+	cache *gocache.Cache
+	sf    singleflight.Group
+	ttl   time.Duration
+}
+
+func resolveUpstreamMetadataCacheTTL(cfg *config.Config) time.Duration {
+	if cfg == nil {
+		return defaultUpstreamMetadataCacheTTL
+	}
+	if cfg.Gateway.UpstreamMetadataCacheTTLSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.Gateway.UpstreamMetadataCacheTTLSeconds) * time.Second
+}
+
+func newUpstreamMetadataCache(ttl time.Duration) *upstreamMetadataCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &upstreamMetadataCache{cache: gocache.New(ttl, time.Minute), ttl: ttl}
+}
+
+func upstreamMetadataCacheKey(accountID int64, resource string) string {
+	return strconv.FormatInt(accountID, 10) + ":" + resource
+}
+
+// Load 返回缓存的响应；未命中时以 singleflight 合并并发请求调用 fetch，2xx 结果写入缓存。
+// 返回的 UpstreamHTTPResult 为副本，调用方可自由修改 Headers。
+func (c *upstreamMetadataCache) Load(accountID int64, resource string, fetch func() (*UpstreamHTTPResult, error)) (*UpstreamHTTPResult, error) {
+	if c == nil {
+		return fetch()
+	}
+	key := upstreamMetadataCacheKey(accountID, resource)
+	if cached, found := c.cache.Get(key); found {
+		if res, ok := cached.(*UpstreamHTTPResult); ok {
+			upstreamMetadataCacheHitTotal.Add(1)
+			return cloneUpstreamHTTPResult(res), nil
+		}
+	}
+	upstreamMetadataCacheMissTotal.Add(1)
+
+	value, err, _ := c.sf.Do(key, func() (any, error) {
+		res, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		if res != nil && res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+			c.cache.Set(key, cloneUpstreamHTTPResult(res), c.ttl)
+			upstreamMetadataCacheStoreTotal.Add(1)
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, _ := value.(*UpstreamHTTPResult)
+	return cloneUpstreamHTTPResult(res), nil
+}
+
+func cloneUpstreamHTTPResult(res *UpstreamHTTPResult) *UpstreamHTTPResult {
+	if res == nil {
+		return nil
+	}
+	return &UpstreamHTTPResult{
+		StatusCode: res.StatusCode,
+		Headers:    res.Headers.Clone(),
+		Body:       append([]byte(nil), res.Body...),
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpstreamMetadataCache_CachesOnlySuccessPerAccount(t *testing.T) {
+	cache := newUpstreamMetadataCache(time.Minute)
+	calls := 0
+	status := http.StatusServiceUnavailable
+	fetch := func() (*UpstreamHTTPResult, error) {
+		calls++
+		return &UpstreamHTTPResult{StatusCode: status, Headers: http.Header{"Content-Type": []string{"application/json"}}, Body: []byte(`{"models":[]}`)}, nil
+	}
+
+	res, err := cache.Load(1, "gemini/v1beta/models", fetch)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	_, err = cache.Load(1, "gemini/v1beta/models", fetch)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls, "non-2xx responses must not be cached")
+
+	status = http.StatusOK
+	_, err = cache.Load(1, "gemini/v1beta/models", fetch)
+	require.NoError(t, err)
+	res, err = cache.Load(1, "gemini/v1beta/models", fetch)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, `{"models":[]}`, string(res.Body))
+
+	res.Headers.Set("Content-Type", "text/plain")
+	res, err = cache.Load(1, "gemini/v1beta/models", fetch)
+	require.NoError(t, err)
+	require.Equal(t, "application/json", res.Headers.Get("Content-Type"), "callers get a copy")
+
+	_, err = cache.Load(2, "gemini/v1beta/models", fetch)
+	require.NoError(t, err)
+	require.Equal(t, 4, calls, "cache is keyed per account")
+}
+
+func TestUpstreamMetadataCache_DisabledAndErrors(t *testing.T) {
+	var disabled *upstreamMetadataCache
+	calls := 0
+	fetch := func() (*UpstreamHTTPResult, error) {
+		calls++
+		return &UpstreamHTTPResult{StatusCode: http.StatusOK}, nil
+	}
+	_, _ = disabled.Load(1, "x", fetch)
+	_, _ = disabled.Load(1, "x", fetch)
+	require.Equal(t, 2, calls)
+
+	cache := newUpstreamMetadataCache(time.Minute)
+	_, err := cache.Load(1, "x", func() (*UpstreamHTTPResult, error) { return nil, errors.New("boom") })
+	require.EqualError(t, err, "boom")
+
+	require.Nil(t, newUpstreamMetadataCache(resolveUpstreamMetadataCacheTTL(&config.Config{})))
+	require.Equal(t, defaultUpstreamMetadataCacheTTL, resolveUpstreamMetadataCacheTTL(nil))
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamMetadataCacheTTLSeconds = 60
+	require.Equal(t, time.Minute, resolveUpstreamMetadataCacheTTL(cfg))
+}
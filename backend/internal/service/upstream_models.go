@@ -33,6 +33,8 @@ type UpstreamModelSyncError struct {
 	Kind    UpstreamModelSyncErrorKind
 	Message string
 	Err     error
+	// StatusCode 上游返回的非 2xx 状态码；请求未到达上游时为 0。
+	StatusCode int
 }
 
 func (e *UpstreamModelSyncError) Error() string {
@@ -110,10 +112,12 @@ func (s *AccountTestService) FetchUpstreamSupportedModels(ctx context.Context, a
 	}
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, newUpstreamModelSyncUpstreamError(
-			fmt.Sprintf("Upstream model list request failed with HTTP %d", resp.StatusCode),
-			fmt.Errorf("upstream model list returned HTTP %d", resp.StatusCode),
-		)
+		return nil, &UpstreamModelSyncError{
+			Kind:       UpstreamModelSyncErrorUpstream,
+			Message:    fmt.Sprintf("Upstream model list request failed with HTTP %d", resp.StatusCode),
+			Err:        fmt.Errorf("upstream model list returned HTTP %d", resp.StatusCode),
+			StatusCode: resp.StatusCode,
+		}
 	}
 
 	models, err := extractUpstreamModelIDs(body)
@@ -39,6 +39,11 @@ type UsageBillingCommand struct {
 	APIKeyQuotaCost     float64
 	APIKeyRateLimitCost float64
 	AccountQuotaCost    float64
+	// APIKeyTokens 计入 API Key Token 上限的用量（仅 Key 设置了 token_limit 时非 0）
+	APIKeyTokens int64
+	// APIKeyProjectID / APIKeyProjectCost Key 所属项目及计入项目合计额度的费用（Key 未归入项目时为 0）
+	APIKeyProjectID   int64
+	APIKeyProjectCost float64
 }
 
 func (c *UsageBillingCommand) Normalize() {
@@ -117,6 +122,11 @@ type UsageBillingApplyResult struct {
 	NewBalance           *float64           // post-deduction balance (nil = no balance deduction)
 	BalanceOverdrafted   bool               // true when the sufficient-balance guard missed and debt was still recorded
 	QuotaState           *AccountQuotaState // post-increment quota state (nil = no quota increment)
+
+	// APIKeyTokenLimitExhausted 本次扣费使 Key 的 tokens_used 首次达到 token_limit
+	APIKeyTokenLimitExhausted bool
+	// APIKeyProjectQuotaExhausted 本次扣费使项目的 quota_used 首次达到项目额度
+	APIKeyProjectQuotaExhausted bool
 }
 
 // BatchImageBalanceHoldCommand describes an idempotent balance hold operation.
@@ -19,11 +19,12 @@ const (
 	RequestTypeStream       RequestType = 2
 	RequestTypeWSV2         RequestType = 3
 	RequestTypeCyberBlocked RequestType = 4 // cyber_policy 命中（透传但被上游安全策略拒绝）
+	RequestTypeReused       RequestType = 5 // 复用已有响应（请求合并跟随者、压缩重放、响应缓存命中），未请求上游
 )
 
 func (t RequestType) IsValid() bool {
 	switch t {
-	case RequestTypeUnknown, RequestTypeSync, RequestTypeStream, RequestTypeWSV2, RequestTypeCyberBlocked:
+	case RequestTypeUnknown, RequestTypeSync, RequestTypeStream, RequestTypeWSV2, RequestTypeCyberBlocked, RequestTypeReused:
 		return true
 	default:
 		return false
@@ -47,6 +48,8 @@ func (t RequestType) String() string {
 		return "ws_v2"
 	case RequestTypeCyberBlocked:
 		return "cyber"
+	case RequestTypeReused:
+		return "reused"
 	default:
 		return "unknown"
 	}
@@ -68,8 +71,10 @@ func ParseUsageRequestType(value string) (RequestType, error) {
 		return RequestTypeWSV2, nil
 	case "cyber":
 		return RequestTypeCyberBlocked, nil
+	case "reused":
+		return RequestTypeReused, nil
 	default:
-		return RequestTypeUnknown, fmt.Errorf("invalid request_type, allowed values: unknown, sync, stream, ws_v2, cyber")
+		return RequestTypeUnknown, fmt.Errorf("invalid request_type, allowed values: unknown, sync, stream, ws_v2, cyber, reused")
 	}
 }
 
@@ -127,6 +132,14 @@ type UsageLog struct {
 	InboundEndpoint *string
 	// UpstreamEndpoint is the normalized upstream endpoint path, e.g. /v1/responses.
 	UpstreamEndpoint *string
+	// ThreadID is the client-supplied conversation/thread identifier (X-Sub2API-Thread-ID).
+	ThreadID *string
+	// RequestClass is the request classification used for scheduling and SLO reporting: "interactive" / "batch".
+	RequestClass *string
+	// AudioDurationSeconds is the transcribed audio length for /v1/audio/transcriptions (per-second billing).
+	AudioDurationSeconds *float64
+	// AudioCharacters is the input text length for /v1/audio/speech (per-character billing).
+	AudioCharacters *int
 
 	GroupID        *int64
 	SubscriptionID *int64
@@ -142,6 +155,10 @@ type UsageLog struct {
 	ImageOutputTokens int
 	ImageOutputCost   float64
 
+	// 输出 token 构成：推理、工具调用参数，其余为可见输出（均包含在 OutputTokens 内）
+	ReasoningTokens int
+	ToolCallTokens  int
+
 	InputCost         float64
 	OutputCost        float64
 	CacheCreationCost float64
@@ -193,6 +210,38 @@ func (u *UsageLog) TotalTokens() int {
 	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
 }
 
+// GenerationMs 返回首字之后到响应结束的耗时（毫秒）。
+// 未记录 first_token_ms（非流式）或数据异常时返回 nil。
+func (u *UsageLog) GenerationMs() *int {
+	if u == nil || u.DurationMs == nil || u.FirstTokenMs == nil {
+		return nil
+	}
+	gen := *u.DurationMs - *u.FirstTokenMs
+	if gen < 0 {
+		return nil
+	}
+	return &gen
+}
+
+// OutputTokensPerSecond 返回生成阶段的平均出字速度（tokens/s，不含 TTFT）。
+// 非流式请求没有首字时间，退化为按总耗时计算。
+func (u *UsageLog) OutputTokensPerSecond() *float64 {
+	if u == nil || u.OutputTokens <= 0 {
+		return nil
+	}
+	var ms int
+	if gen := u.GenerationMs(); gen != nil {
+		ms = *gen
+	} else if u.DurationMs != nil {
+		ms = *u.DurationMs
+	}
+	if ms <= 0 {
+		return nil
+	}
+	tps := float64(u.OutputTokens) * 1000 / float64(ms)
+	return &tps
+}
+
 func (u *UsageLog) EffectiveRequestType() RequestType {
 	if u == nil {
 		return RequestTypeUnknown
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ReusedResponseUsageInput 描述一次直接复用已有响应、未请求上游的请求
+// （请求合并跟随者、会话压缩合并/重放、响应缓存命中）。
+type ReusedResponseUsageInput struct {
+	APIKey          *APIKey
+	AccountID       int64 // 产生被复用响应的账号
+	Model           string
+	Stream          bool
+	InboundEndpoint string
+	UserAgent       string
+	IPAddress       string
+	DurationMs      int
+}
+
+// RecordReusedResponseUsage 为复用响应的请求写入一条零费用用量记录（request_type=reused）。
+// 复用不产生上游调用，因此有意不扣费、不计入订阅与配额；记录仅用于让每个被服务的请求在用量明细中可见、可对账。
+// 无法确定来源账号时（usage_logs.account_id 非空）跳过。
+func (s *GatewayService) RecordReusedResponseUsage(ctx context.Context, input *ReusedResponseUsageInput) {
+	if s == nil || input == nil || input.APIKey == nil || input.AccountID <= 0 {
+		return
+	}
+	apiKey := input.APIKey
+	model := strings.TrimSpace(input.Model)
+	billingType := BillingTypeBalance
+	if apiKey.Group != nil && apiKey.Group.IsSubscriptionType() {
+		billingType = BillingTypeSubscription
+	}
+	durationMs := input.DurationMs
+	usageLog := &UsageLog{
+		UserID:          apiKey.UserID,
+		APIKeyID:        apiKey.ID,
+		AccountID:       input.AccountID,
+		RequestID:       resolveUsageBillingRequestID(ctx, ""),
+		Model:           model,
+		RequestedModel:  model,
+		InboundEndpoint: optionalTrimmedStringPtr(input.InboundEndpoint),
+		GroupID:         apiKey.GroupID,
+		RateMultiplier:  1,
+		BillingType:     billingType,
+		RequestType:     RequestTypeReused,
+		Stream:          input.Stream,
+		UserAgent:       optionalTrimmedStringPtr(input.UserAgent),
+		IPAddress:       optionalTrimmedStringPtr(input.IPAddress),
+		DurationMs:      &durationMs,
+		RequestClass:    optionalTrimmedStringPtr(RequestClassFromContext(ctx)),
+		CreatedAt:       time.Now(),
+	}
+	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
+}
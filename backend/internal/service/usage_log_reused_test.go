@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestTypeReused(t *testing.T) {
+	require.True(t, RequestTypeReused.IsValid())
+	require.Equal(t, "reused", RequestTypeReused.String())
+	rt, err := ParseUsageRequestType("reused")
+	require.NoError(t, err)
+	require.Equal(t, RequestTypeReused, rt)
+
+	u := &UsageLog{RequestType: RequestTypeReused, Stream: true}
+	u.SyncRequestTypeAndLegacyFields()
+	require.Equal(t, RequestTypeReused, u.RequestType)
+	require.True(t, u.Stream)
+}
+
+func TestGatewayService_RecordReusedResponseUsage(t *testing.T) {
+	repo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc := &GatewayService{usageLogRepo: repo}
+	groupID := int64(3)
+	apiKey := &APIKey{ID: 11, UserID: 1, GroupID: &groupID}
+	ctx := context.WithValue(context.Background(), ctxkey.ClientRequestID, "req-follower")
+
+	svc.RecordReusedResponseUsage(ctx, &ReusedResponseUsageInput{
+		APIKey: apiKey, AccountID: 7, Model: "gpt-5", Stream: true, InboundEndpoint: "/v1/responses/compact",
+	})
+	require.Equal(t, 1, repo.calls)
+	log := repo.lastLog
+	require.Equal(t, "client:req-follower", log.RequestID)
+	require.Equal(t, int64(11), log.APIKeyID)
+	require.Equal(t, int64(7), log.AccountID)
+	require.Equal(t, &groupID, log.GroupID)
+	require.Equal(t, RequestTypeReused, log.RequestType)
+	require.Zero(t, log.ActualCost)
+	require.Zero(t, log.TotalTokens())
+
+	// 来源账号未知时不写入（account_id 非空外键）
+	svc.RecordReusedResponseUsage(ctx, &ReusedResponseUsageInput{APIKey: apiKey, Model: "gpt-5"})
+	require.Equal(t, 1, repo.calls)
+}
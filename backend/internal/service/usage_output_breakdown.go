@@ -0,0 +1,86 @@
+package service
+
+import (
+	"github.com/tidwall/gjson"
+)
+
+// 工具调用参数基本是 ASCII JSON，按约 4 字符/token 估算（与 estimateTokensForText 的英文口径一致）。
+func estimateToolCallTokensFromChars(chars int) int {
+	if chars <= 0 {
+		return 0
+	}
+	return (chars + 3) / 4
+}
+
+// addToolCallChars 累计流式 input_json_delta 的字符数，并按累计值重新估算工具调用 token，
+// 避免逐块估算时的取整误差叠加。
+func (u *ClaudeUsage) addToolCallChars(chars int) {
+	if u == nil || chars <= 0 {
+		return
+	}
+	u.toolCallChars += chars
+	u.ToolCallOutputTokens = estimateToolCallTokensFromChars(u.toolCallChars)
+}
+
+// claudeToolCallTokensFromResponseBody 估算 Anthropic 非流式响应中 tool_use 块参数的 token 数。
+func claudeToolCallTokensFromResponseBody(body []byte) int {
+	if len(body) == 0 {
+		return 0
+	}
+	chars := 0
+	gjson.GetBytes(body, "content").ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "tool_use", "server_tool_use":
+			chars += len(block.Get("input").Raw)
+		}
+		return true
+	})
+	return estimateToolCallTokensFromChars(chars)
+}
+
+// openAIToolCallTokensFromJSONBytes 估算 OpenAI 响应中工具调用参数的 token 数，兼容：
+//   - Responses：output[]（或事件中的 response.output[]）的 function_call.arguments / custom_tool_call.input
+//   - Chat Completions：choices[].message.tool_calls[].function.arguments
+func openAIToolCallTokensFromJSONBytes(body []byte) int {
+	chars := 0
+	output := gjson.GetBytes(body, "output")
+	if !output.IsArray() {
+		output = gjson.GetBytes(body, "response.output")
+	}
+	output.ForEach(func(_, item gjson.Result) bool {
+		switch item.Get("type").String() {
+		case "function_call":
+			chars += len(item.Get("arguments").String())
+		case "custom_tool_call":
+			chars += len(item.Get("input").String())
+		}
+		return true
+	})
+	gjson.GetBytes(body, "choices").ForEach(func(_, choice gjson.Result) bool {
+		choice.Get("message.tool_calls").ForEach(func(_, call gjson.Result) bool {
+			chars += len(call.Get("function.arguments").String())
+			return true
+		})
+		return true
+	})
+	return estimateToolCallTokensFromChars(chars)
+}
+
+// normalizeOutputTokenBreakdown 将推理/工具调用 token 限制在输出 token 之内：
+// 推理 token 来自上游优先保留，工具调用 token 为估算值，只能占用剩余部分。
+func normalizeOutputTokenBreakdown(outputTokens, reasoningTokens, toolCallTokens int) (int, int) {
+	if outputTokens <= 0 {
+		return 0, 0
+	}
+	reasoningTokens = min(max(reasoningTokens, 0), outputTokens)
+	toolCallTokens = min(max(toolCallTokens, 0), outputTokens-reasoningTokens)
+	return reasoningTokens, toolCallTokens
+}
+
+// VisibleOutputTokens 返回扣除推理与工具调用后的可见输出 token 数。
+func (l *UsageLog) VisibleOutputTokens() int {
+	if l == nil {
+		return 0
+	}
+	return max(l.OutputTokens-l.ReasoningTokens-l.ToolCallTokens, 0)
+}
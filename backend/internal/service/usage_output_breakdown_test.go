@@ -0,0 +1,78 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractOpenAIUsageFromJSONBytes_OutputBreakdown(t *testing.T) {
+	body := []byte(`{
+		"output": [
+			{"type": "reasoning", "summary": []},
+			{"type": "function_call", "name": "get_weather", "arguments": "{\"city\":\"Paris\",\"unit\":\"c\"}"},
+			{"type": "message", "content": [{"type": "output_text", "text": "ok"}]}
+		],
+		"usage": {
+			"input_tokens": 10,
+			"output_tokens": 120,
+			"output_tokens_details": {"reasoning_tokens": 80}
+		}
+	}`)
+	usage, ok := extractOpenAIUsageFromJSONBytes(body)
+	require.True(t, ok)
+	require.Equal(t, 80, usage.ReasoningTokens)
+	require.Equal(t, estimateToolCallTokensFromChars(len(`{"city":"Paris","unit":"c"}`)), usage.ToolCallTokens)
+
+	chat := []byte(`{
+		"choices": [{"message": {"tool_calls": [{"function": {"name": "f", "arguments": "{\"a\":1}"}}]}}],
+		"usage": {"prompt_tokens": 5, "completion_tokens": 30, "completion_tokens_details": {"reasoning_tokens": 12}}
+	}`)
+	usage, ok = extractOpenAIUsageFromJSONBytes(chat)
+	require.True(t, ok)
+	require.Equal(t, 12, usage.ReasoningTokens)
+	require.Equal(t, 2, usage.ToolCallTokens)
+}
+
+func TestClaudeToolCallTokens_NonStreamingAndSSE(t *testing.T) {
+	body := []byte(`{"content":[{"type":"text","text":"hi"},{"type":"tool_use","id":"t1","name":"bash","input":{"command":"ls -la"}}]}`)
+	require.Equal(t, estimateToolCallTokensFromChars(len(`{"command":"ls -la"}`)), claudeToolCallTokensFromResponseBody(body))
+
+	svc := &GatewayService{}
+	usage := &ClaudeUsage{}
+	for _, partial := range []string{`{"comm`, `and":"l`, `s -la"}`} {
+		patch := svc.extractSSEUsagePatch(map[string]any{
+			"type":  "content_block_delta",
+			"delta": map[string]any{"type": "input_json_delta", "partial_json": partial},
+		})
+		require.NotNil(t, patch)
+		mergeSSEUsagePatch(usage, patch)
+	}
+	require.Nil(t, svc.extractSSEUsagePatch(map[string]any{
+		"type":  "content_block_delta",
+		"delta": map[string]any{"type": "text_delta", "text": "hello"},
+	}))
+	// 按累计字符数估算：20 字符 = 5 token，而逐块取整会得到 6
+	require.Equal(t, 5, usage.ToolCallOutputTokens)
+}
+
+func TestNormalizeOutputTokenBreakdown(t *testing.T) {
+	reasoning, toolCall := normalizeOutputTokenBreakdown(100, 60, 30)
+	require.Equal(t, 60, reasoning)
+	require.Equal(t, 30, toolCall)
+
+	reasoning, toolCall = normalizeOutputTokenBreakdown(100, 90, 30)
+	require.Equal(t, 90, reasoning)
+	require.Equal(t, 10, toolCall, "estimated tool-call tokens are capped by the remaining output")
+
+	reasoning, toolCall = normalizeOutputTokenBreakdown(0, 10, 10)
+	require.Zero(t, reasoning)
+	require.Zero(t, toolCall)
+
+	log := &UsageLog{OutputTokens: 100, ReasoningTokens: 90, ToolCallTokens: 10}
+	require.Zero(t, log.VisibleOutputTokens())
+	log.ReasoningTokens = 40
+	require.Equal(t, 50, log.VisibleOutputTokens())
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 用量报表聚合维度。
+const (
+	UsageReportDimensionUser    = "user"
+	UsageReportDimensionAPIKey  = "api_key"
+	UsageReportDimensionGroup   = "group"
+	UsageReportDimensionAccount = "account"
+	UsageReportDimensionModel   = "model"
+)
+
+// 用量报表时间桶（按报表时区截断）。
+const (
+	UsageReportBucketHour  = "hour"
+	UsageReportBucketDay   = "day"
+	UsageReportBucketMonth = "month"
+)
+
+const (
+	usageReportDefaultLimit = 100
+	usageReportMaxLimit     = 1000
+	// usageReportMaxGroups 单次从数据库读取的分组上限，超出时报表标记为截断。
+	usageReportMaxGroups = 50000
+	// usageReportMaxHourRange 按小时分桶时允许的最大时间跨度，避免一次生成过多时间桶。
+	usageReportMaxHourRange = 31 * 24 * time.Hour
+	usageReportMaxRange     = 366 * 24 * time.Hour
+)
+
+var (
+	ErrUsageReportInvalidDimension = infraerrors.BadRequest("USAGE_REPORT_INVALID_DIMENSION", "group_by must be a comma-separated list of: user, api_key, group, account, model")
+	ErrUsageReportInvalidBucket    = infraerrors.BadRequest("USAGE_REPORT_INVALID_BUCKET", "bucket must be one of: hour, day, month")
+	ErrUsageReportInvalidRange     = infraerrors.BadRequest("USAGE_REPORT_INVALID_RANGE", "time range must be non-empty and at most 366 days (31 days for hourly buckets)")
+)
+
+// UsageReportQuery 用量报表查询参数。过滤条件为零值时不过滤。
+type UsageReportQuery struct {
+	StartTime  time.Time
+	EndTime    time.Time
+	Dimensions []string
+	Bucket     string
+	// Timezone 时间桶使用的 IANA 时区，为空时使用系统时区
+	Timezone string
+
+	UserID    int64
+	APIKeyID  int64
+	GroupID   int64
+	AccountID int64
+	Model     string
+
+	Limit int
+}
+
+// HasDimension 查询是否按指定维度分组
+func (q *UsageReportQuery) HasDimension(dimension string) bool {
+	for _, d := range q.Dimensions {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// UsageReportMetrics 用量报表指标
+type UsageReportMetrics struct {
+	Requests            int64   `json:"requests"` // 成功计费的请求数
+	Errors              int64   `json:"errors"`   // 计入 SLA 的错误请求数（不含业务限流）
+	ErrorRate           float64 `json:"error_rate"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalTokens         int64   `json:"total_tokens"`
+	TotalCost           float64 `json:"total_cost"`  // 标准计费（倍率前）
+	ActualCost          float64 `json:"actual_cost"` // 实际扣除
+}
+
+func (m *UsageReportMetrics) add(other UsageReportMetrics) {
+	m.Requests += other.Requests
+	m.Errors += other.Errors
+	m.InputTokens += other.InputTokens
+	m.OutputTokens += other.OutputTokens
+	m.CacheCreationTokens += other.CacheCreationTokens
+	m.CacheReadTokens += other.CacheReadTokens
+	m.TotalTokens += other.TotalTokens
+	m.TotalCost += other.TotalCost
+	m.ActualCost += other.ActualCost
+}
+
+func (m *UsageReportMetrics) finalize() {
+	if total := m.Requests + m.Errors; total > 0 {
+		m.ErrorRate = float64(m.Errors) / float64(total)
+	}
+}
+
+// UsageReportRow 报表单行：未参与分组的维度字段为空。
+type UsageReportRow struct {
+	Bucket      string  `json:"bucket,omitempty"`
+	UserID      *int64  `json:"user_id,omitempty"`
+	UserEmail   string  `json:"user_email,omitempty"`
+	APIKeyID    *int64  `json:"api_key_id,omitempty"`
+	APIKeyName  string  `json:"api_key_name,omitempty"`
+	GroupID     *int64  `json:"group_id,omitempty"`
+	GroupName   string  `json:"group_name,omitempty"`
+	AccountID   *int64  `json:"account_id,omitempty"`
+	AccountName string  `json:"account_name,omitempty"`
+	Model       *string `json:"model,omitempty"`
+
+	UsageReportMetrics
+}
+
+func (r *UsageReportRow) key() string {
+	var b strings.Builder
+	b.WriteString(r.Bucket)
+	for _, id := range []*int64{r.UserID, r.APIKeyID, r.GroupID, r.AccountID} {
+		b.WriteByte('|')
+		if id != nil {
+			fmt.Fprint(&b, *id)
+		}
+	}
+	b.WriteByte('|')
+	if r.Model != nil {
+		b.WriteString(*r.Model)
+	}
+	return b.String()
+}
+
+// UsageReport 用量报表
+type UsageReport struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	StartTime   time.Time          `json:"start_time"`
+	EndTime     time.Time          `json:"end_time"`
+	GroupBy     []string           `json:"group_by"`
+	Bucket      string             `json:"bucket,omitempty"`
+	Totals      UsageReportMetrics `json:"totals"`
+	Truncated   bool               `json:"truncated"`
+	Rows        []*UsageReportRow  `json:"rows"`
+}
+
+// UsageReportRepository 读取用量报表所需的聚合数据。
+type UsageReportRepository interface {
+	// AggregateUsage 按查询维度聚合 usage_logs，最多返回 limit 组。
+	AggregateUsage(ctx context.Context, query *UsageReportQuery, limit int) ([]*UsageReportRow, error)
+	// AggregateErrors 按查询维度聚合 ops_error_logs 中计入 SLA 的错误数（仅填充 Errors），最多返回 limit 组。
+	AggregateErrors(ctx context.Context, query *UsageReportQuery, limit int) ([]*UsageReportRow, error)
+	// LookupNames 查询维度对象的展示名（用户邮箱、Key / 分组 / 账号名称）。
+	LookupNames(ctx context.Context, dimension string, ids []int64) (map[int64]string, error)
+}
+
+// UsageReportService 多维度用量与成本报表
+type UsageReportService struct {
+	repo UsageReportRepository
+}
+
+// NewUsageReportService 创建用量报表服务
+func NewUsageReportService(repo UsageReportRepository) *UsageReportService {
+	return &UsageReportService{repo: repo}
+}
+
+func normalizeUsageReportQuery(query *UsageReportQuery) error {
+	seen := make(map[string]struct{}, len(query.Dimensions))
+	dimensions := make([]string, 0, len(query.Dimensions))
+	for _, raw := range query.Dimensions {
+		d := strings.ToLower(strings.TrimSpace(raw))
+		if d == "" {
+			continue
+		}
+		switch d {
+		case UsageReportDimensionUser, UsageReportDimensionAPIKey, UsageReportDimensionGroup,
+			UsageReportDimensionAccount, UsageReportDimensionModel:
+		default:
+			return ErrUsageReportInvalidDimension
+		}
+		if _, dup := seen[d]; dup {
+			continue
+		}
+		seen[d] = struct{}{}
+		dimensions = append(dimensions, d)
+	}
+	query.Dimensions = dimensions
+
+	query.Bucket = strings.ToLower(strings.TrimSpace(query.Bucket))
+	switch query.Bucket {
+	case "", UsageReportBucketHour, UsageReportBucketDay, UsageReportBucketMonth:
+	default:
+		return ErrUsageReportInvalidBucket
+	}
+
+	maxRange := usageReportMaxRange
+	if query.Bucket == UsageReportBucketHour {
+		maxRange = usageReportMaxHourRange
+	}
+	if !query.EndTime.After(query.StartTime) || query.EndTime.Sub(query.StartTime) > maxRange {
+		return ErrUsageReportInvalidRange
+	}
+
+	if tz := strings.TrimSpace(query.Timezone); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			query.Timezone = ""
+		}
+	}
+	query.Model = strings.TrimSpace(query.Model)
+	if query.Limit <= 0 {
+		query.Limit = usageReportDefaultLimit
+	}
+	if query.Limit > usageReportMaxLimit {
+		query.Limit = usageReportMaxLimit
+	}
+	return nil
+}
+
+// Report 生成用量报表：按维度合并成功用量与错误数，计算错误率与总计。
+// 有时间桶时按时间升序、同桶内按实际费用降序排列，否则按实际费用降序排列。
+func (s *UsageReportService) Report(ctx context.Context, query UsageReportQuery) (*UsageReport, error) {
+	if err := normalizeUsageReportQuery(&query); err != nil {
+		return nil, err
+	}
+
+	usageRows, err := s.repo.AggregateUsage(ctx, &query, usageReportMaxGroups+1)
+	if err != nil {
+		return nil, err
+	}
+	errorRows, err := s.repo.AggregateErrors(ctx, &query, usageReportMaxGroups+1)
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(usageRows) > usageReportMaxGroups || len(errorRows) > usageReportMaxGroups
+
+	merged := make(map[string]*UsageReportRow, len(usageRows))
+	rows := make([]*UsageReportRow, 0, len(usageRows))
+	for _, list := range [][]*UsageReportRow{usageRows, errorRows} {
+		for _, row := range list {
+			key := row.key()
+			if existing, ok := merged[key]; ok {
+				existing.add(row.UsageReportMetrics)
+				continue
+			}
+			merged[key] = row
+			rows = append(rows, row)
+		}
+	}
+
+	report := &UsageReport{
+		GeneratedAt: time.Now(),
+		StartTime:   query.StartTime,
+		EndTime:     query.EndTime,
+		GroupBy:     query.Dimensions,
+		Bucket:      query.Bucket,
+		Truncated:   truncated,
+	}
+	for _, row := range rows {
+		row.finalize()
+		report.Totals.add(row.UsageReportMetrics)
+	}
+	report.Totals.finalize()
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Bucket != rows[j].Bucket {
+			return rows[i].Bucket < rows[j].Bucket
+		}
+		if rows[i].ActualCost != rows[j].ActualCost {
+			return rows[i].ActualCost > rows[j].ActualCost
+		}
+		return rows[i].Requests+rows[i].Errors > rows[j].Requests+rows[j].Errors
+	})
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		report.Truncated = true
+	}
+	if err := s.fillNames(ctx, &query, rows); err != nil {
+		return nil, err
+	}
+	report.Rows = rows
+	return report, nil
+}
+
+// fillNames 为返回的行补充维度对象展示名
+func (s *UsageReportService) fillNames(ctx context.Context, query *UsageReportQuery, rows []*UsageReportRow) error {
+	type nameField struct {
+		dimension string
+		id        func(*UsageReportRow) *int64
+		set       func(*UsageReportRow, string)
+	}
+	fields := []nameField{
+		{UsageReportDimensionUser, func(r *UsageReportRow) *int64 { return r.UserID }, func(r *UsageReportRow, v string) { r.UserEmail = v }},
+		{UsageReportDimensionAPIKey, func(r *UsageReportRow) *int64 { return r.APIKeyID }, func(r *UsageReportRow, v string) { r.APIKeyName = v }},
+		{UsageReportDimensionGroup, func(r *UsageReportRow) *int64 { return r.GroupID }, func(r *UsageReportRow, v string) { r.GroupName = v }},
+		{UsageReportDimensionAccount, func(r *UsageReportRow) *int64 { return r.AccountID }, func(r *UsageReportRow, v string) { r.AccountName = v }},
+	}
+	for _, f := range fields {
+		if !query.HasDimension(f.dimension) {
+			continue
+		}
+		idSet := make(map[int64]struct{})
+		for _, row := range rows {
+			if id := f.id(row); id != nil && *id > 0 {
+				idSet[*id] = struct{}{}
+			}
+		}
+		if len(idSet) == 0 {
+			continue
+		}
+		ids := make([]int64, 0, len(idSet))
+		for id := range idSet {
+			ids = append(ids, id)
+		}
+		names, err := s.repo.LookupNames(ctx, f.dimension, ids)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if id := f.id(row); id != nil {
+				f.set(row, names[*id])
+			}
+		}
+	}
+	return nil
+}
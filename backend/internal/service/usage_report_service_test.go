@@ -0,0 +1,114 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type usageReportRepoStub struct {
+	usage      []*UsageReportRow
+	errors     []*UsageReportRow
+	names      map[string]map[int64]string
+	lastQuery  *UsageReportQuery
+	nameLookup map[string][]int64
+}
+
+func (r *usageReportRepoStub) AggregateUsage(_ context.Context, query *UsageReportQuery, _ int) ([]*UsageReportRow, error) {
+	r.lastQuery = query
+	return r.usage, nil
+}
+
+func (r *usageReportRepoStub) AggregateErrors(_ context.Context, _ *UsageReportQuery, _ int) ([]*UsageReportRow, error) {
+	return r.errors, nil
+}
+
+func (r *usageReportRepoStub) LookupNames(_ context.Context, dimension string, ids []int64) (map[int64]string, error) {
+	if r.nameLookup == nil {
+		r.nameLookup = map[string][]int64{}
+	}
+	r.nameLookup[dimension] = append(r.nameLookup[dimension], ids...)
+	return r.names[dimension], nil
+}
+
+func TestUsageReportService_MergesErrorsAndComputesRates(t *testing.T) {
+	sonnet, opus := "claude-sonnet-4-6", "claude-opus-4-6"
+	repo := &usageReportRepoStub{
+		usage: []*UsageReportRow{
+			{Bucket: "2026-10-01", UserID: int64Ptr(1), Model: &sonnet, UsageReportMetrics: UsageReportMetrics{Requests: 9, InputTokens: 100, TotalTokens: 150, TotalCost: 1, ActualCost: 1.5}},
+			{Bucket: "2026-10-01", UserID: int64Ptr(2), Model: &opus, UsageReportMetrics: UsageReportMetrics{Requests: 2, TotalCost: 4, ActualCost: 4}},
+			{Bucket: "2026-09-30", UserID: int64Ptr(1), Model: &sonnet, UsageReportMetrics: UsageReportMetrics{Requests: 1, ActualCost: 0.1}},
+		},
+		errors: []*UsageReportRow{
+			{Bucket: "2026-10-01", UserID: int64Ptr(1), Model: &sonnet, UsageReportMetrics: UsageReportMetrics{Errors: 1}},
+			{Bucket: "2026-10-02", UserID: int64Ptr(3), Model: &sonnet, UsageReportMetrics: UsageReportMetrics{Errors: 4}},
+		},
+		names: map[string]map[int64]string{UsageReportDimensionUser: {1: "a@example.com", 2: "b@example.com"}},
+	}
+	svc := NewUsageReportService(repo)
+	start := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	report, err := svc.Report(context.Background(), UsageReportQuery{
+		StartTime:  start,
+		EndTime:    start.AddDate(0, 0, 3),
+		Dimensions: []string{" User", "model", "user"},
+		Bucket:     "DAY",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{UsageReportDimensionUser, UsageReportDimensionModel}, report.GroupBy)
+	require.Len(t, report.Rows, 4)
+	// 按时间桶升序，同桶内按实际费用降序
+	require.Equal(t, "2026-09-30", report.Rows[0].Bucket)
+	require.Equal(t, int64(2), *report.Rows[1].UserID)
+	require.Equal(t, "b@example.com", report.Rows[1].UserEmail)
+	merged := report.Rows[2]
+	require.Equal(t, int64(9), merged.Requests)
+	require.Equal(t, int64(1), merged.Errors)
+	require.InDelta(t, 0.1, merged.ErrorRate, 1e-12)
+	require.Equal(t, "a@example.com", merged.UserEmail)
+	errorOnly := report.Rows[3]
+	require.Equal(t, "2026-10-02", errorOnly.Bucket)
+	require.InDelta(t, 1.0, errorOnly.ErrorRate, 1e-12)
+
+	require.Equal(t, int64(12), report.Totals.Requests)
+	require.Equal(t, int64(5), report.Totals.Errors)
+	require.InDelta(t, 5.6, report.Totals.ActualCost, 1e-9)
+	require.InDelta(t, 5.0/17.0, report.Totals.ErrorRate, 1e-12)
+	require.False(t, report.Truncated)
+	require.ElementsMatch(t, []int64{1, 2, 3}, repo.nameLookup[UsageReportDimensionUser])
+}
+
+func TestUsageReportService_LimitMarksTruncated(t *testing.T) {
+	repo := &usageReportRepoStub{usage: []*UsageReportRow{
+		{GroupID: int64Ptr(1), UsageReportMetrics: UsageReportMetrics{Requests: 1, ActualCost: 1}},
+		{GroupID: int64Ptr(2), UsageReportMetrics: UsageReportMetrics{Requests: 1, ActualCost: 3}},
+	}}
+	now := time.Now()
+
+	report, err := NewUsageReportService(repo).Report(context.Background(), UsageReportQuery{
+		StartTime: now.Add(-time.Hour), EndTime: now, Dimensions: []string{UsageReportDimensionGroup}, Limit: 1,
+	})
+
+	require.NoError(t, err)
+	require.True(t, report.Truncated)
+	require.Len(t, report.Rows, 1)
+	require.Equal(t, int64(2), *report.Rows[0].GroupID)
+	require.InDelta(t, 4, report.Totals.ActualCost, 1e-12, "totals cover rows beyond the limit")
+}
+
+func TestUsageReportService_ValidatesQuery(t *testing.T) {
+	svc := NewUsageReportService(&usageReportRepoStub{})
+	now := time.Now()
+
+	_, err := svc.Report(context.Background(), UsageReportQuery{StartTime: now.Add(-time.Hour), EndTime: now, Dimensions: []string{"platform"}})
+	require.ErrorIs(t, err, ErrUsageReportInvalidDimension)
+	_, err = svc.Report(context.Background(), UsageReportQuery{StartTime: now.Add(-time.Hour), EndTime: now, Bucket: "week"})
+	require.ErrorIs(t, err, ErrUsageReportInvalidBucket)
+	_, err = svc.Report(context.Background(), UsageReportQuery{StartTime: now.AddDate(0, 0, -40), EndTime: now, Bucket: UsageReportBucketHour})
+	require.ErrorIs(t, err, ErrUsageReportInvalidRange)
+}
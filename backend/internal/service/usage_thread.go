@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+)
+
+// UsageThreadIDHeader 客户端用于标记会话/线程的请求头，仅用于用量归因，不会转发到上游。
+const UsageThreadIDHeader = "X-Sub2API-Thread-ID"
+
+// maxUsageThreadIDLength 与 usage_logs.thread_id 列宽一致。
+const maxUsageThreadIDLength = 128
+
+var (
+	ErrUsageThreadIDInvalid        = infraerrors.BadRequest("USAGE_THREAD_ID_INVALID", "thread_id must be 1-128 printable characters")
+	ErrUsageThreadCostNotSupported = infraerrors.ServiceUnavailable("USAGE_THREAD_COST_UNAVAILABLE", "thread cost is not supported by the usage store")
+)
+
+// usageThreadCostReader 由 usage_logs 仓储实现；按会话汇总为可选能力，未实现时接口返回不可用。
+type usageThreadCostReader interface {
+	GetThreadCost(ctx context.Context, userID int64, threadID string) (*usagestats.ThreadCost, error)
+}
+
+// NormalizeUsageThreadID 规范化客户端传入的 thread ID：去除首尾空白，
+// 超长或包含控制字符时视为未提供（返回空串），避免脏数据写入 usage_logs。
+func NormalizeUsageThreadID(raw string) string {
+	threadID := strings.TrimSpace(raw)
+	if threadID == "" || len(threadID) > maxUsageThreadIDLength {
+		return ""
+	}
+	for _, r := range threadID {
+		if !unicode.IsPrint(r) {
+			return ""
+		}
+	}
+	return threadID
+}
+
+// GetThreadCost 汇总某个会话的所有轮次。userID 为 0 时不限用户（仅管理员接口使用）。
+func (s *UsageService) GetThreadCost(ctx context.Context, userID int64, threadID string) (*usagestats.ThreadCost, error) {
+	normalized := NormalizeUsageThreadID(threadID)
+	if normalized == "" {
+		return nil, ErrUsageThreadIDInvalid
+	}
+	reader, ok := s.usageRepo.(usageThreadCostReader)
+	if !ok {
+		return nil, ErrUsageThreadCostNotSupported
+	}
+	cost, err := reader.GetThreadCost(ctx, userID, normalized)
+	if err != nil {
+		return nil, fmt.Errorf("get thread cost: %w", err)
+	}
+	return cost, nil
+}
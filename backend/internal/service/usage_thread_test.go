@@ -0,0 +1,29 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeUsageThreadID(t *testing.T) {
+	require.Equal(t, "conv-1", NormalizeUsageThreadID("  conv-1 "))
+	require.Equal(t, "会话-1", NormalizeUsageThreadID("会话-1"))
+	require.Empty(t, NormalizeUsageThreadID(""))
+	require.Empty(t, NormalizeUsageThreadID("conv\n1"), "control characters are rejected")
+	require.Empty(t, NormalizeUsageThreadID(strings.Repeat("a", maxUsageThreadIDLength+1)))
+	require.Len(t, NormalizeUsageThreadID(strings.Repeat("a", maxUsageThreadIDLength)), maxUsageThreadIDLength)
+}
+
+func TestUsageServiceGetThreadCostValidatesThreadID(t *testing.T) {
+	svc := &UsageService{}
+	_, err := svc.GetThreadCost(context.Background(), 1, " ")
+	require.ErrorIs(t, err, ErrUsageThreadIDInvalid)
+
+	_, err = svc.GetThreadCost(context.Background(), 1, "conv-1")
+	require.ErrorIs(t, err, ErrUsageThreadCostNotSupported)
+}
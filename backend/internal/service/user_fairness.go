@@ -0,0 +1,139 @@
+package service
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultUserFairnessHalfLife             = 5 * time.Minute
+	defaultUserFairnessMaxBackoffMultiplier = 4.0
+	defaultUserFairnessMinActiveUsers       = 2
+
+	// 衰减后低于该值的用户视为不活跃，并在下次访问时清理
+	userFairnessActiveThreshold = 1.0
+)
+
+// UserFairnessConfig 分组内用户公平调度参数。
+type UserFairnessConfig struct {
+	HalfLife             time.Duration
+	MaxBackoffMultiplier float64
+	MinActiveUsers       int
+}
+
+type userFairnessEntry struct {
+	score     float64
+	updatedAt time.Time
+}
+
+// UserFairnessTracker 以指数衰减方式记录分组内每个用户的近期消耗，
+// 并在账号槽位争抢时为超过公平份额的用户给出退避倍数。
+//
+// 状态仅保存在本实例内存中：多实例部署时各实例独立统计，
+// 由于请求在实例间大致均匀分布，局部占比足以反映重度用户。
+type UserFairnessTracker struct {
+	cfg UserFairnessConfig
+	now func() time.Time
+
+	mu     sync.Mutex
+	groups map[int64]map[int64]*userFairnessEntry
+}
+
+// NewUserFairnessTracker 创建用户公平调度跟踪器，非法参数回退为默认值。
+func NewUserFairnessTracker(cfg UserFairnessConfig) *UserFairnessTracker {
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = defaultUserFairnessHalfLife
+	}
+	if cfg.MaxBackoffMultiplier < 1 {
+		cfg.MaxBackoffMultiplier = defaultUserFairnessMaxBackoffMultiplier
+	}
+	if cfg.MinActiveUsers < 0 {
+		cfg.MinActiveUsers = defaultUserFairnessMinActiveUsers
+	}
+	return &UserFairnessTracker{
+		cfg:    cfg,
+		now:    time.Now,
+		groups: make(map[int64]map[int64]*userFairnessEntry),
+	}
+}
+
+// Record 记录用户在分组内的一次消耗（通常为本次请求的总 token 数）。
+func (t *UserFairnessTracker) Record(groupID, userID int64, amount float64) {
+	if t == nil || userID <= 0 || amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return
+	}
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	users := t.groups[groupID]
+	if users == nil {
+		users = make(map[int64]*userFairnessEntry)
+		t.groups[groupID] = users
+	}
+	entry := users[userID]
+	if entry == nil {
+		users[userID] = &userFairnessEntry{score: amount, updatedAt: now}
+		return
+	}
+	entry.score = t.decayed(entry, now) + amount
+	entry.updatedAt = now
+}
+
+// BackoffMultiplier 返回用户在分组内等待账号槽位时的退避倍数。
+// 占比不超过公平份额（1/活跃用户数）时返回 1；超出时按「占比/公平份额」放大，
+// 上限为 MaxBackoffMultiplier。
+func (t *UserFairnessTracker) BackoffMultiplier(groupID, userID int64) float64 {
+	if t == nil || userID <= 0 {
+		return 1
+	}
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	users := t.groups[groupID]
+	if len(users) == 0 {
+		return 1
+	}
+
+	var total, own float64
+	active := 0
+	for id, entry := range users {
+		score := t.decayed(entry, now)
+		if score < userFairnessActiveThreshold {
+			delete(users, id)
+			continue
+		}
+		entry.score = score
+		entry.updatedAt = now
+		total += score
+		active++
+		if id == userID {
+			own = score
+		}
+	}
+	if len(users) == 0 {
+		delete(t.groups, groupID)
+	}
+
+	if active < t.cfg.MinActiveUsers || active < 2 || total <= 0 || own <= 0 {
+		return 1
+	}
+	fairShare := 1 / float64(active)
+	share := own / total
+	if share <= fairShare {
+		return 1
+	}
+	return math.Min(share/fairShare, t.cfg.MaxBackoffMultiplier)
+}
+
+func (t *UserFairnessTracker) decayed(entry *userFairnessEntry, now time.Time) float64 {
+	elapsed := now.Sub(entry.updatedAt)
+	if elapsed <= 0 {
+		return entry.score
+	}
+	return entry.score * math.Exp2(-float64(elapsed)/float64(t.cfg.HalfLife))
+}
@@ -0,0 +1,123 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserFairnessTracker(cfg UserFairnessConfig) (*UserFairnessTracker, *time.Time) {
+	tracker := NewUserFairnessTracker(cfg)
+	now := time.Unix(1_700_000_000, 0)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestUserFairnessTracker_HeavyUserGetsBackoff(t *testing.T) {
+	tracker, _ := newTestUserFairnessTracker(UserFairnessConfig{
+		HalfLife:             time.Minute,
+		MaxBackoffMultiplier: 4,
+		MinActiveUsers:       2,
+	})
+
+	tracker.Record(1, 10, 9000)
+	tracker.Record(1, 20, 1000)
+
+	// 两个活跃用户公平份额为 0.5，重度用户占比 0.9 → 1.8 倍
+	require.InDelta(t, 1.8, tracker.BackoffMultiplier(1, 10), 1e-9)
+	require.Equal(t, 1.0, tracker.BackoffMultiplier(1, 20))
+	// 无消耗记录的新用户不降权
+	require.Equal(t, 1.0, tracker.BackoffMultiplier(1, 30))
+	// 不同分组互不影响
+	require.Equal(t, 1.0, tracker.BackoffMultiplier(2, 10))
+}
+
+func TestUserFairnessTracker_MultiplierCapped(t *testing.T) {
+	tracker, _ := newTestUserFairnessTracker(UserFairnessConfig{
+		HalfLife:             time.Minute,
+		MaxBackoffMultiplier: 1.5,
+		MinActiveUsers:       2,
+	})
+
+	tracker.Record(1, 10, 99_000)
+	tracker.Record(1, 20, 1_000)
+
+	require.Equal(t, 1.5, tracker.BackoffMultiplier(1, 10))
+}
+
+func TestUserFairnessTracker_SingleUserNoPenalty(t *testing.T) {
+	tracker, _ := newTestUserFairnessTracker(UserFairnessConfig{HalfLife: time.Minute, MaxBackoffMultiplier: 4})
+
+	tracker.Record(1, 10, 50_000)
+
+	require.Equal(t, 1.0, tracker.BackoffMultiplier(1, 10))
+}
+
+func TestUserFairnessTracker_MinActiveUsers(t *testing.T) {
+	tracker, _ := newTestUserFairnessTracker(UserFairnessConfig{
+		HalfLife:             time.Minute,
+		MaxBackoffMultiplier: 4,
+		MinActiveUsers:       3,
+	})
+
+	tracker.Record(1, 10, 9000)
+	tracker.Record(1, 20, 1000)
+	require.Equal(t, 1.0, tracker.BackoffMultiplier(1, 10))
+
+	tracker.Record(1, 30, 1000)
+	require.Greater(t, tracker.BackoffMultiplier(1, 10), 1.0)
+}
+
+func TestUserFairnessTracker_DecayAndPrune(t *testing.T) {
+	tracker, now := newTestUserFairnessTracker(UserFairnessConfig{
+		HalfLife:             time.Minute,
+		MaxBackoffMultiplier: 4,
+		MinActiveUsers:       2,
+	})
+
+	tracker.Record(1, 10, 8000)
+	*now = now.Add(3 * time.Minute) // 8000 → 1000
+	tracker.Record(1, 20, 1000)
+
+	// 衰减后两人消耗相当，不再降权
+	require.InDelta(t, 1.0, tracker.BackoffMultiplier(1, 10), 1e-9)
+
+	*now = now.Add(time.Hour)
+	require.Equal(t, 1.0, tracker.BackoffMultiplier(1, 10))
+	tracker.mu.Lock()
+	_, exists := tracker.groups[1]
+	tracker.mu.Unlock()
+	require.False(t, exists, "长期无消耗的分组应被清理")
+}
+
+func TestUserFairnessTracker_IgnoresInvalidInput(t *testing.T) {
+	var nilTracker *UserFairnessTracker
+	nilTracker.Record(1, 10, 100)
+	require.Equal(t, 1.0, nilTracker.BackoffMultiplier(1, 10))
+
+	tracker, _ := newTestUserFairnessTracker(UserFairnessConfig{})
+	tracker.Record(1, 0, 100)
+	tracker.Record(1, 10, -5)
+	require.Empty(t, tracker.groups)
+}
+
+func TestConcurrencyService_RecordUsageLogConsumption(t *testing.T) {
+	svc := NewConcurrencyService(nil)
+	groupID := int64(7)
+
+	// 未开启时为空操作
+	svc.RecordUsageLogConsumption(&UsageLog{UserID: 1, GroupID: &groupID, InputTokens: 100})
+	require.Equal(t, 1.0, svc.UserFairnessBackoffMultiplier(groupID, 1))
+
+	tracker, _ := newTestUserFairnessTracker(UserFairnessConfig{HalfLife: time.Minute, MaxBackoffMultiplier: 4, MinActiveUsers: 2})
+	svc.SetUserFairnessTracker(tracker)
+	svc.RecordUsageLogConsumption(&UsageLog{UserID: 1, GroupID: &groupID, InputTokens: 2000, OutputTokens: 1000})
+	svc.RecordUsageLogConsumption(&UsageLog{UserID: 2, GroupID: &groupID, InputTokens: 1000})
+	svc.RecordUsageLogConsumption(nil)
+
+	require.InDelta(t, 1.5, svc.UserFairnessBackoffMultiplier(groupID, 1), 1e-9)
+	require.Equal(t, 1.0, svc.UserFairnessBackoffMultiplier(groupID, 2))
+}
@@ -0,0 +1,605 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"github.com/google/uuid"
+)
+
+// 用量告警指标
+const (
+	UserUsageAlertMetricCost     = "cost"     // 窗口内实际扣费（USD）
+	UserUsageAlertMetricRequests = "requests" // 窗口内请求数
+	UserUsageAlertMetricTokens   = "tokens"   // 窗口内总 token（输入+输出+缓存）
+)
+
+// UserUsageAlertWebhookEvent webhook 回调中的事件类型
+const UserUsageAlertWebhookEvent = "usage_alert.triggered"
+
+const (
+	userUsageAlertMaxRulesPerUser  = 10
+	userUsageAlertMinWindowMinutes = 5
+	userUsageAlertMaxWindowMinutes = 7 * 24 * 60
+	userUsageAlertEvalInterval     = time.Minute
+	userUsageAlertEvalTimeout      = 50 * time.Second
+	userUsageAlertWebhookTimeout   = 10 * time.Second
+
+	// userUsageAlertLeaderLockKey 多实例部署时只由一个实例评估规则，避免重复通知。
+	userUsageAlertLeaderLockKey = "user_usage_alert:evaluator:leader"
+	userUsageAlertLeaderLockTTL = 2 * time.Minute
+)
+
+var (
+	ErrUserUsageAlertNotFound       = infraerrors.NotFound("USAGE_ALERT_NOT_FOUND", "usage alert not found")
+	ErrUserUsageAlertInvalidMetric  = infraerrors.BadRequest("USAGE_ALERT_INVALID_METRIC", "metric must be one of cost, requests, tokens")
+	ErrUserUsageAlertInvalidWindow  = infraerrors.BadRequest("USAGE_ALERT_INVALID_WINDOW", fmt.Sprintf("window_minutes must be between %d and %d", userUsageAlertMinWindowMinutes, userUsageAlertMaxWindowMinutes))
+	ErrUserUsageAlertInvalidLimit   = infraerrors.BadRequest("USAGE_ALERT_INVALID_THRESHOLD", "threshold must be greater than 0")
+	ErrUserUsageAlertNoChannel      = infraerrors.BadRequest("USAGE_ALERT_NO_CHANNEL", "either webhook_url or notify_email must be set")
+	ErrUserUsageAlertInvalidWebhook = infraerrors.BadRequest("USAGE_ALERT_INVALID_WEBHOOK", "webhook_url must be a public https url")
+	ErrUserUsageAlertTooMany        = infraerrors.Conflict("USAGE_ALERT_LIMIT_REACHED", fmt.Sprintf("at most %d usage alerts per user", userUsageAlertMaxRulesPerUser))
+	ErrUserUsageAlertDeliveryFailed = infraerrors.BadRequest("USAGE_ALERT_DELIVERY_FAILED", "usage alert delivery failed")
+)
+
+// UserUsageAlert 用户自助配置的用量告警规则。APIKeyID 为空表示统计该用户全部 Key。
+type UserUsageAlert struct {
+	ID              int64      `json:"id"`
+	UserID          int64      `json:"user_id"`
+	APIKeyID        *int64     `json:"api_key_id"`
+	Metric          string     `json:"metric"`
+	WindowMinutes   int        `json:"window_minutes"`
+	Threshold       float64    `json:"threshold"`
+	WebhookURL      string     `json:"webhook_url"`
+	NotifyEmail     bool       `json:"notify_email"`
+	Enabled         bool       `json:"enabled"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// UserUsageAlertUsage 窗口内的用量汇总。
+type UserUsageAlertUsage struct {
+	Cost     float64
+	Requests int64
+	Tokens   int64
+}
+
+// value 返回规则指标对应的用量值。
+func (u *UserUsageAlertUsage) value(metric string) float64 {
+	if u == nil {
+		return 0
+	}
+	switch metric {
+	case UserUsageAlertMetricCost:
+		return u.Cost
+	case UserUsageAlertMetricRequests:
+		return float64(u.Requests)
+	case UserUsageAlertMetricTokens:
+		return float64(u.Tokens)
+	}
+	return 0
+}
+
+// UserUsageAlertInput 创建/更新规则的入参。
+type UserUsageAlertInput struct {
+	APIKeyID      *int64
+	Metric        string
+	WindowMinutes int
+	Threshold     float64
+	WebhookURL    string
+	NotifyEmail   bool
+	Enabled       bool
+}
+
+// UserUsageAlertWebhookPayload 触发时 POST 到用户 webhook 的 JSON 结构。
+type UserUsageAlertWebhookPayload struct {
+	Event         string    `json:"event"`
+	AlertID       int64     `json:"alert_id"`
+	UserID        int64     `json:"user_id"`
+	APIKeyID      *int64    `json:"api_key_id"`
+	APIKeyName    string    `json:"api_key_name,omitempty"`
+	Metric        string    `json:"metric"`
+	WindowMinutes int       `json:"window_minutes"`
+	Threshold     float64   `json:"threshold"`
+	Value         float64   `json:"value"`
+	WindowStart   time.Time `json:"window_start"`
+	WindowEnd     time.Time `json:"window_end"`
+	Test          bool      `json:"test,omitempty"`
+}
+
+// UserUsageAlertRepository 用量告警规则存储及窗口用量聚合。
+type UserUsageAlertRepository interface {
+	Create(ctx context.Context, alert *UserUsageAlert) (*UserUsageAlert, error)
+	GetByID(ctx context.Context, id int64) (*UserUsageAlert, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*UserUsageAlert, error)
+	CountByUserID(ctx context.Context, userID int64) (int, error)
+	ListEnabled(ctx context.Context) ([]*UserUsageAlert, error)
+	Update(ctx context.Context, alert *UserUsageAlert) (*UserUsageAlert, error)
+	Delete(ctx context.Context, id int64) error
+	MarkTriggered(ctx context.Context, id int64, at time.Time) error
+	// SumUsage 汇总 [since, now) 内用户（或指定 Key）的用量。
+	SumUsage(ctx context.Context, userID int64, apiKeyID *int64, since time.Time) (*UserUsageAlertUsage, error)
+}
+
+// UserUsageAlertService 管理用户自助用量告警规则，并周期性评估、投递通知。
+type UserUsageAlertService struct {
+	repo         UserUsageAlertRepository
+	userRepo     UserRepository
+	apiKeyRepo   APIKeyRepository
+	settingRepo  SettingRepository
+	emailService *EmailService
+
+	httpClient *http.Client
+	// validateHost 在实际发起 webhook 请求前校验解析后的 IP（防 DNS Rebinding），测试中可替换。
+	validateHost func(host string) error
+	now          func() time.Time
+
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+}
+
+// NewUserUsageAlertService 创建用量告警服务。
+func NewUserUsageAlertService(
+	repo UserUsageAlertRepository,
+	userRepo UserRepository,
+	apiKeyRepo APIKeyRepository,
+	settingRepo SettingRepository,
+	emailService *EmailService,
+) *UserUsageAlertService {
+	return &UserUsageAlertService{
+		repo:         repo,
+		userRepo:     userRepo,
+		apiKeyRepo:   apiKeyRepo,
+		settingRepo:  settingRepo,
+		emailService: emailService,
+		httpClient: &http.Client{
+			Timeout: userUsageAlertWebhookTimeout,
+			// 不跟随重定向，避免被 302 引导到内网地址
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		validateHost: urlvalidator.ValidateResolvedIP,
+		now:          time.Now,
+		interval:     userUsageAlertEvalInterval,
+		stopCh:       make(chan struct{}),
+		instanceID:   uuid.NewString(),
+	}
+}
+
+// SetLeaderLock 注入多实例选主所用的锁缓存与 DB；均为 nil 时不做门控（单实例/测试）。
+func (s *UserUsageAlertService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// List 返回用户的全部规则。
+func (s *UserUsageAlertService) List(ctx context.Context, userID int64) ([]*UserUsageAlert, error) {
+	alerts, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if alerts == nil {
+		alerts = []*UserUsageAlert{}
+	}
+	return alerts, nil
+}
+
+// Create 为用户新建规则。
+func (s *UserUsageAlertService) Create(ctx context.Context, userID int64, in UserUsageAlertInput) (*UserUsageAlert, error) {
+	alert := &UserUsageAlert{UserID: userID}
+	if err := s.applyInput(ctx, userID, alert, in); err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= userUsageAlertMaxRulesPerUser {
+		return nil, ErrUserUsageAlertTooMany
+	}
+	return s.repo.Create(ctx, alert)
+}
+
+// Update 更新用户自己的规则。修改阈值/窗口会重置冷却，便于立即按新规则评估。
+func (s *UserUsageAlertService) Update(ctx context.Context, userID, id int64, in UserUsageAlertInput) (*UserUsageAlert, error) {
+	alert, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Metric != in.Metric || alert.WindowMinutes != in.WindowMinutes || alert.Threshold != in.Threshold {
+		alert.LastTriggeredAt = nil
+	}
+	if err := s.applyInput(ctx, userID, alert, in); err != nil {
+		return nil, err
+	}
+	return s.repo.Update(ctx, alert)
+}
+
+// Delete 删除用户自己的规则。
+func (s *UserUsageAlertService) Delete(ctx context.Context, userID, id int64) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// SendTest 按规则当前配置立即投递一次测试通知（不更新冷却时间），返回投递错误便于用户排查 webhook。
+func (s *UserUsageAlertService) SendTest(ctx context.Context, userID, id int64) error {
+	alert, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	since := now.Add(-time.Duration(alert.WindowMinutes) * time.Minute)
+	usage, err := s.repo.SumUsage(ctx, alert.UserID, alert.APIKeyID, since)
+	if err != nil {
+		return err
+	}
+	payload := s.buildPayload(ctx, alert, usage.value(alert.Metric), since, now)
+	payload.Test = true
+	if err := s.deliver(ctx, alert, payload); err != nil {
+		return ErrUserUsageAlertDeliveryFailed.WithCause(err).WithMetadata(map[string]string{"detail": err.Error()})
+	}
+	return nil
+}
+
+func (s *UserUsageAlertService) getOwned(ctx context.Context, userID, id int64) (*UserUsageAlert, error) {
+	alert, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// 不区分“不存在”与“不属于自己”，避免探测他人规则 ID
+	if alert == nil || alert.UserID != userID {
+		return nil, ErrUserUsageAlertNotFound
+	}
+	return alert, nil
+}
+
+// applyInput 校验入参并写入 alert。
+func (s *UserUsageAlertService) applyInput(ctx context.Context, userID int64, alert *UserUsageAlert, in UserUsageAlertInput) error {
+	metric := strings.ToLower(strings.TrimSpace(in.Metric))
+	switch metric {
+	case UserUsageAlertMetricCost, UserUsageAlertMetricRequests, UserUsageAlertMetricTokens:
+	default:
+		return ErrUserUsageAlertInvalidMetric
+	}
+	if in.WindowMinutes < userUsageAlertMinWindowMinutes || in.WindowMinutes > userUsageAlertMaxWindowMinutes {
+		return ErrUserUsageAlertInvalidWindow
+	}
+	if in.Threshold <= 0 {
+		return ErrUserUsageAlertInvalidLimit
+	}
+
+	webhookURL := strings.TrimSpace(in.WebhookURL)
+	if webhookURL != "" {
+		normalized, err := urlvalidator.ValidateHTTPSURL(webhookURL, urlvalidator.ValidationOptions{})
+		if err != nil {
+			return ErrUserUsageAlertInvalidWebhook.WithCause(err)
+		}
+		webhookURL = normalized
+	}
+	if webhookURL == "" && !in.NotifyEmail {
+		return ErrUserUsageAlertNoChannel
+	}
+
+	var apiKeyID *int64
+	if in.APIKeyID != nil && *in.APIKeyID > 0 {
+		key, err := s.apiKeyRepo.GetByID(ctx, *in.APIKeyID)
+		if err != nil {
+			return err
+		}
+		if key == nil || key.UserID != userID {
+			return ErrAPIKeyNotFound
+		}
+		id := key.ID
+		apiKeyID = &id
+	}
+
+	alert.APIKeyID = apiKeyID
+	alert.Metric = metric
+	alert.WindowMinutes = in.WindowMinutes
+	alert.Threshold = in.Threshold
+	alert.WebhookURL = webhookURL
+	alert.NotifyEmail = in.NotifyEmail
+	alert.Enabled = in.Enabled
+	return nil
+}
+
+// Start 启动后台评估循环（每分钟一次）。
+func (s *UserUsageAlertService) Start() {
+	if s == nil || s.repo == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台评估循环并等待当前轮次结束。
+func (s *UserUsageAlertService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *UserUsageAlertService) runOnce() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, userUsageAlertLeaderLockKey, s.instanceID, userUsageAlertLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), userUsageAlertEvalTimeout)
+	defer cancel()
+	if triggered, err := s.evaluate(ctx); err != nil {
+		slog.Warn("[UserUsageAlert] evaluation failed", "error", err)
+	} else if triggered > 0 {
+		slog.Info("[UserUsageAlert] alerts triggered", "count", triggered)
+	}
+}
+
+// evaluate 评估所有启用的规则，返回本轮触发的规则数。
+// 同一规则在一个窗口时长内最多触发一次，避免持续超阈值时反复轰炸用户。
+func (s *UserUsageAlertService) evaluate(ctx context.Context) (int, error) {
+	alerts, err := s.repo.ListEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	triggered := 0
+	for _, alert := range alerts {
+		if ctx.Err() != nil {
+			break
+		}
+		if alert == nil || !alert.Enabled {
+			continue
+		}
+		window := time.Duration(alert.WindowMinutes) * time.Minute
+		if alert.LastTriggeredAt != nil && now.Sub(*alert.LastTriggeredAt) < window {
+			continue
+		}
+		since := now.Add(-window)
+		usage, err := s.repo.SumUsage(ctx, alert.UserID, alert.APIKeyID, since)
+		if err != nil {
+			slog.Warn("[UserUsageAlert] sum usage failed", "alert_id", alert.ID, "error", err)
+			continue
+		}
+		value := usage.value(alert.Metric)
+		if value < alert.Threshold {
+			continue
+		}
+		// 先落冷却再投递：投递失败不重试，防止 webhook 故障时每分钟重复发送邮件
+		if err := s.repo.MarkTriggered(ctx, alert.ID, now); err != nil {
+			slog.Warn("[UserUsageAlert] mark triggered failed", "alert_id", alert.ID, "error", err)
+			continue
+		}
+		triggered++
+		if err := s.deliver(ctx, alert, s.buildPayload(ctx, alert, value, since, now)); err != nil {
+			slog.Warn("[UserUsageAlert] delivery failed", "alert_id", alert.ID, "user_id", alert.UserID, "error", err)
+		}
+	}
+	return triggered, nil
+}
+
+func (s *UserUsageAlertService) buildPayload(ctx context.Context, alert *UserUsageAlert, value float64, since, now time.Time) *UserUsageAlertWebhookPayload {
+	payload := &UserUsageAlertWebhookPayload{
+		Event:         UserUsageAlertWebhookEvent,
+		AlertID:       alert.ID,
+		UserID:        alert.UserID,
+		APIKeyID:      alert.APIKeyID,
+		Metric:        alert.Metric,
+		WindowMinutes: alert.WindowMinutes,
+		Threshold:     alert.Threshold,
+		Value:         value,
+		WindowStart:   since.UTC(),
+		WindowEnd:     now.UTC(),
+	}
+	if alert.APIKeyID != nil && s.apiKeyRepo != nil {
+		if key, err := s.apiKeyRepo.GetByID(ctx, *alert.APIKeyID); err == nil && key != nil {
+			payload.APIKeyName = key.Name
+		}
+	}
+	return payload
+}
+
+// deliver 依次投递 webhook 与邮件；任一渠道失败都会返回错误，但不影响另一渠道。
+func (s *UserUsageAlertService) deliver(ctx context.Context, alert *UserUsageAlert, payload *UserUsageAlertWebhookPayload) error {
+	var errs []error
+	if alert.WebhookURL != "" {
+		if err := s.postWebhook(ctx, alert.WebhookURL, payload); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if alert.NotifyEmail {
+		if err := s.sendEmail(ctx, alert, payload); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *UserUsageAlertService) postWebhook(ctx context.Context, rawURL string, payload *UserUsageAlertWebhookPayload) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if s.validateHost != nil {
+		if err := s.validateHost(parsed.Hostname()); err != nil {
+			return err
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, userUsageAlertWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sub2api-usage-alert")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *UserUsageAlertService) sendEmail(ctx context.Context, alert *UserUsageAlert, payload *UserUsageAlertWebhookPayload) error {
+	if s.emailService == nil || s.userRepo == nil {
+		return errors.New("email service unavailable")
+	}
+	user, err := s.userRepo.GetByID(ctx, alert.UserID)
+	if err != nil {
+		return err
+	}
+	recipients := filterVerifiedEmails(user.BalanceNotifyExtraEmails)
+	if len(recipients) == 0 && strings.TrimSpace(user.Email) != "" {
+		recipients = []string{strings.TrimSpace(user.Email)}
+	}
+	if len(recipients) == 0 {
+		return errors.New("no recipient email")
+	}
+
+	siteName := defaultSiteName
+	if s.settingRepo != nil {
+		if name, err := s.settingRepo.GetValue(ctx, SettingKeySiteName); err == nil && name != "" {
+			siteName = name
+		}
+	}
+	subject := fmt.Sprintf("[%s] 用量告警 / Usage Alert", sanitizeEmailHeader(siteName))
+	body := buildUserUsageAlertEmailBody(siteName, payload)
+
+	var errs []error
+	for _, to := range recipients {
+		sendCtx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+		if err := s.emailService.SendEmail(sendCtx, to, subject, body); err != nil {
+			errs = append(errs, err)
+		}
+		cancel()
+	}
+	// 至少一个收件人成功即视为投递成功
+	if len(errs) == len(recipients) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+var userUsageAlertMetricLabels = map[string]string{
+	UserUsageAlertMetricCost:     "费用 / Cost",
+	UserUsageAlertMetricRequests: "请求数 / Requests",
+	UserUsageAlertMetricTokens:   "Token 数 / Tokens",
+}
+
+func formatUserUsageAlertValue(metric string, v float64) string {
+	if metric == UserUsageAlertMetricCost {
+		return fmt.Sprintf("$%.4f", v)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+func buildUserUsageAlertEmailBody(siteName string, p *UserUsageAlertWebhookPayload) string {
+	scope := "全部 Key / All keys"
+	if p.APIKeyID != nil {
+		scope = fmt.Sprintf("#%d", *p.APIKeyID)
+		if p.APIKeyName != "" {
+			scope = fmt.Sprintf("%s (#%d)", p.APIKeyName, *p.APIKeyID)
+		}
+	}
+	title := "用量告警 / Usage Alert"
+	if p.Test {
+		title = "用量告警测试 / Usage Alert Test"
+	}
+	return fmt.Sprintf(userUsageAlertEmailTemplate,
+		html.EscapeString(siteName),
+		title,
+		html.EscapeString(scope),
+		userUsageAlertMetricLabels[p.Metric],
+		p.WindowMinutes,
+		formatUserUsageAlertValue(p.Metric, p.Value),
+		formatUserUsageAlertValue(p.Metric, p.Threshold),
+	)
+}
+
+const userUsageAlertEmailTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background-color: #f5f5f5; margin: 0; padding: 20px; }
+        .container { max-width: 600px; margin: 0 auto; background-color: #fff; border-radius: 8px; overflow: hidden; box-shadow: 0 2px 8px rgba(0,0,0,0.1); }
+        .header { background: linear-gradient(135deg, #f59e0b 0%%, #d97706 100%%); color: white; padding: 30px; text-align: center; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { padding: 40px 30px; }
+        .metric { display: flex; justify-content: space-between; padding: 12px 0; border-bottom: 1px solid #eee; }
+        .metric-label { color: #666; }
+        .metric-value { font-weight: bold; color: #333; }
+        .info { color: #666; font-size: 14px; line-height: 1.6; margin-top: 20px; text-align: center; }
+        .footer { background-color: #f8f9fa; padding: 20px; text-align: center; color: #999; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header"><h1>%s</h1></div>
+        <div class="content">
+            <p style="font-size: 18px; color: #333; text-align: center;">%s</p>
+            <div class="metric"><span class="metric-label">范围 / Scope</span><span class="metric-value">%s</span></div>
+            <div class="metric"><span class="metric-label">指标 / Metric</span><span class="metric-value">%s</span></div>
+            <div class="metric"><span class="metric-label">统计窗口 / Window</span><span class="metric-value">%d min</span></div>
+            <div class="metric"><span class="metric-label">当前用量 / Current Usage</span><span class="metric-value">%s</span></div>
+            <div class="metric"><span class="metric-label">告警阈值 / Threshold</span><span class="metric-value">%s</span></div>
+            <div class="info">
+                <p>您的用量已达到您设置的告警阈值，请确认是否为预期使用。</p>
+                <p>Your usage has reached the alert threshold you configured. Please verify it is expected.</p>
+            </div>
+        </div>
+        <div class="footer"><p>此邮件由系统自动发送，请勿回复。</p></div>
+    </div>
+</body>
+</html>`
@@ -0,0 +1,285 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type userUsageAlertRepoFake struct {
+	mu        sync.Mutex
+	alerts    map[int64]*UserUsageAlert
+	nextID    int64
+	usage     *UserUsageAlertUsage
+	sumCalls  int
+	lastSince time.Time
+}
+
+func newUserUsageAlertRepoFake() *userUsageAlertRepoFake {
+	return &userUsageAlertRepoFake{alerts: make(map[int64]*UserUsageAlert), usage: &UserUsageAlertUsage{}}
+}
+
+func (f *userUsageAlertRepoFake) Create(_ context.Context, alert *UserUsageAlert) (*UserUsageAlert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	clone := *alert
+	clone.ID = f.nextID
+	f.alerts[clone.ID] = &clone
+	return &clone, nil
+}
+
+func (f *userUsageAlertRepoFake) GetByID(_ context.Context, id int64) (*UserUsageAlert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	alert, ok := f.alerts[id]
+	if !ok {
+		return nil, ErrUserUsageAlertNotFound
+	}
+	clone := *alert
+	return &clone, nil
+}
+
+func (f *userUsageAlertRepoFake) ListByUserID(_ context.Context, userID int64) ([]*UserUsageAlert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*UserUsageAlert
+	for _, a := range f.alerts {
+		if a.UserID == userID {
+			clone := *a
+			out = append(out, &clone)
+		}
+	}
+	return out, nil
+}
+
+func (f *userUsageAlertRepoFake) CountByUserID(ctx context.Context, userID int64) (int, error) {
+	list, _ := f.ListByUserID(ctx, userID)
+	return len(list), nil
+}
+
+func (f *userUsageAlertRepoFake) ListEnabled(context.Context) ([]*UserUsageAlert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*UserUsageAlert
+	for _, a := range f.alerts {
+		if a.Enabled {
+			clone := *a
+			out = append(out, &clone)
+		}
+	}
+	return out, nil
+}
+
+func (f *userUsageAlertRepoFake) Update(_ context.Context, alert *UserUsageAlert) (*UserUsageAlert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clone := *alert
+	f.alerts[alert.ID] = &clone
+	return &clone, nil
+}
+
+func (f *userUsageAlertRepoFake) Delete(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.alerts, id)
+	return nil
+}
+
+func (f *userUsageAlertRepoFake) MarkTriggered(_ context.Context, id int64, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if a, ok := f.alerts[id]; ok {
+		a.LastTriggeredAt = &at
+	}
+	return nil
+}
+
+func (f *userUsageAlertRepoFake) SumUsage(_ context.Context, _ int64, _ *int64, since time.Time) (*UserUsageAlertUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sumCalls++
+	f.lastSince = since
+	clone := *f.usage
+	return &clone, nil
+}
+
+func newUserUsageAlertServiceForTest(repo *userUsageAlertRepoFake, apiKeyRepo APIKeyRepository) *UserUsageAlertService {
+	svc := NewUserUsageAlertService(repo, nil, apiKeyRepo, nil, nil)
+	svc.validateHost = func(string) error { return nil }
+	return svc
+}
+
+func validUserUsageAlertInput() UserUsageAlertInput {
+	return UserUsageAlertInput{
+		Metric:        UserUsageAlertMetricCost,
+		WindowMinutes: 60,
+		Threshold:     5,
+		WebhookURL:    "https://hooks.example.com/usage/",
+		Enabled:       true,
+	}
+}
+
+func TestUserUsageAlertService_CreateValidatesInput(t *testing.T) {
+	svc := newUserUsageAlertServiceForTest(newUserUsageAlertRepoFake(), nil)
+	ctx := context.Background()
+
+	alert, err := svc.Create(ctx, 1, validUserUsageAlertInput())
+	require.NoError(t, err)
+	require.Equal(t, "https://hooks.example.com/usage", alert.WebhookURL)
+	require.Nil(t, alert.APIKeyID)
+
+	cases := []struct {
+		name   string
+		mutate func(in *UserUsageAlertInput)
+		want   error
+	}{
+		{"metric", func(in *UserUsageAlertInput) { in.Metric = "latency" }, ErrUserUsageAlertInvalidMetric},
+		{"window", func(in *UserUsageAlertInput) { in.WindowMinutes = 1 }, ErrUserUsageAlertInvalidWindow},
+		{"threshold", func(in *UserUsageAlertInput) { in.Threshold = 0 }, ErrUserUsageAlertInvalidLimit},
+		{"plain http", func(in *UserUsageAlertInput) { in.WebhookURL = "http://hooks.example.com" }, ErrUserUsageAlertInvalidWebhook},
+		{"private host", func(in *UserUsageAlertInput) { in.WebhookURL = "https://127.0.0.1/hook" }, ErrUserUsageAlertInvalidWebhook},
+		{"no channel", func(in *UserUsageAlertInput) { in.WebhookURL = "" }, ErrUserUsageAlertNoChannel},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := validUserUsageAlertInput()
+			tc.mutate(&in)
+			_, err := svc.Create(ctx, 1, in)
+			require.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestUserUsageAlertService_CreateRejectsForeignAPIKeyAndLimit(t *testing.T) {
+	repo := newUserUsageAlertRepoFake()
+	apiKeyRepo := &apiKeyRepoStubForGroupUpdate{key: &APIKey{ID: 9, UserID: 2}}
+	svc := newUserUsageAlertServiceForTest(repo, apiKeyRepo)
+	ctx := context.Background()
+
+	in := validUserUsageAlertInput()
+	keyID := int64(9)
+	in.APIKeyID = &keyID
+	_, err := svc.Create(ctx, 1, in)
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	for i := 0; i < userUsageAlertMaxRulesPerUser; i++ {
+		_, err := svc.Create(ctx, 1, validUserUsageAlertInput())
+		require.NoError(t, err)
+	}
+	_, err = svc.Create(ctx, 1, validUserUsageAlertInput())
+	require.ErrorIs(t, err, ErrUserUsageAlertTooMany)
+}
+
+func TestUserUsageAlertService_UpdateAndDeleteRequireOwnership(t *testing.T) {
+	repo := newUserUsageAlertRepoFake()
+	svc := newUserUsageAlertServiceForTest(repo, nil)
+	ctx := context.Background()
+
+	alert, err := svc.Create(ctx, 1, validUserUsageAlertInput())
+	require.NoError(t, err)
+
+	_, err = svc.Update(ctx, 2, alert.ID, validUserUsageAlertInput())
+	require.ErrorIs(t, err, ErrUserUsageAlertNotFound)
+	require.ErrorIs(t, svc.Delete(ctx, 2, alert.ID), ErrUserUsageAlertNotFound)
+
+	// 调整阈值会清空冷却
+	triggeredAt := time.Now()
+	repo.alerts[alert.ID].LastTriggeredAt = &triggeredAt
+	in := validUserUsageAlertInput()
+	in.Threshold = 10
+	updated, err := svc.Update(ctx, 1, alert.ID, in)
+	require.NoError(t, err)
+	require.Nil(t, updated.LastTriggeredAt)
+
+	require.NoError(t, svc.Delete(ctx, 1, alert.ID))
+	require.Empty(t, repo.alerts)
+}
+
+func TestUserUsageAlertService_EvaluateTriggersWebhookOncePerWindow(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []UserUsageAlertWebhookPayload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload UserUsageAlertWebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := newUserUsageAlertRepoFake()
+	repo.alerts[1] = &UserUsageAlert{
+		ID: 1, UserID: 7, Metric: UserUsageAlertMetricCost, WindowMinutes: 60,
+		Threshold: 5, WebhookURL: server.URL, Enabled: true,
+	}
+	repo.alerts[2] = &UserUsageAlert{
+		ID: 2, UserID: 7, Metric: UserUsageAlertMetricCost, WindowMinutes: 60,
+		Threshold: 5, WebhookURL: server.URL, Enabled: false,
+	}
+	svc := newUserUsageAlertServiceForTest(repo, nil)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	repo.usage = &UserUsageAlertUsage{Cost: 4.99}
+	triggered, err := svc.evaluate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, triggered)
+	require.Equal(t, now.Add(-time.Hour), repo.lastSince)
+
+	repo.usage = &UserUsageAlertUsage{Cost: 6}
+	triggered, err = svc.evaluate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, triggered)
+	require.Len(t, received, 1)
+	require.Equal(t, UserUsageAlertWebhookEvent, received[0].Event)
+	require.Equal(t, int64(1), received[0].AlertID)
+	require.Equal(t, 6.0, received[0].Value)
+
+	// 冷却期内不重复触发，也不再查询用量
+	sumCalls := repo.sumCalls
+	now = now.Add(30 * time.Minute)
+	triggered, err = svc.evaluate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, triggered)
+	require.Equal(t, sumCalls, repo.sumCalls)
+
+	now = now.Add(31 * time.Minute)
+	triggered, err = svc.evaluate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, triggered)
+	require.Len(t, received, 2)
+}
+
+func TestUserUsageAlertService_SendTestReportsWebhookFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repo := newUserUsageAlertRepoFake()
+	repo.alerts[1] = &UserUsageAlert{
+		ID: 1, UserID: 7, Metric: UserUsageAlertMetricRequests, WindowMinutes: 60,
+		Threshold: 100, WebhookURL: server.URL, Enabled: true,
+	}
+	svc := newUserUsageAlertServiceForTest(repo, nil)
+
+	err := svc.SendTest(context.Background(), 7, 1)
+	require.ErrorIs(t, err, ErrUserUsageAlertDeliveryFailed)
+	require.Nil(t, repo.alerts[1].LastTriggeredAt, "test delivery must not start the cooldown")
+
+	require.ErrorIs(t, svc.SendTest(context.Background(), 8, 1), ErrUserUsageAlertNotFound)
+}
@@ -8,6 +8,7 @@ import (
 	dbent "github.com/Wei-Shaw/sub2api/ent"
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/payment/provider"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/antigravity"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/google/wire"
@@ -91,7 +92,10 @@ func ProvideTokenRefreshService(
 	// 调用侧显式注入后台刷新策略，避免策略漂移
 	svc.SetRefreshPolicy(DefaultBackgroundRefreshPolicy())
 	svc.SetAccountRuntimeBlocker(runtimeBlocker)
-	svc.Start()
+	// replica 的 OAuth 凭证由 primary 刷新并同步，本地刷新会轮换 refresh token 导致 primary 凭证失效
+	if cfg == nil || !cfg.ConfigSync.IsReplica() {
+		svc.Start()
+	}
 	return svc
 }
 
@@ -212,6 +216,21 @@ func ProvideAccountExpiryService(accountRepo AccountRepository) *AccountExpirySe
 	return svc
 }
 
+// ProvideAccountLeaseService creates and starts AccountLeaseService.
+func ProvideAccountLeaseService(repo AccountLeaseRepository) *AccountLeaseService {
+	svc := NewAccountLeaseService(repo, accountLeaseSweepInterval)
+	svc.Start()
+	return svc
+}
+
+// ProvideStaleAccountService creates and starts StaleAccountService.
+func ProvideStaleAccountService(repo StaleAccountRepository, accountRepo AccountRepository, encryptor SecretEncryptor, lockCache LeaderLockCache, db *sql.DB, cfg *config.Config) *StaleAccountService {
+	svc := NewStaleAccountService(repo, accountRepo, encryptor, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
 // ProvideProxyExpiryService creates and starts ProxyExpiryService.
 func ProvideProxyExpiryService(proxyRepo ProxyRepository) *ProxyExpiryService {
 	svc := NewProxyExpiryService(proxyRepo, time.Minute)
@@ -219,6 +238,13 @@ func ProvideProxyExpiryService(proxyRepo ProxyRepository) *ProxyExpiryService {
 	return svc
 }
 
+// ProvideGuestAPIKeyCleanupService creates and starts GuestAPIKeyCleanupService.
+func ProvideGuestAPIKeyCleanupService(apiKeyService *APIKeyService) *GuestAPIKeyCleanupService {
+	svc := NewGuestAPIKeyCleanupService(apiKeyService, time.Minute)
+	svc.Start()
+	return svc
+}
+
 // ProvideSubscriptionExpiryService creates and starts SubscriptionExpiryService.
 func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository, settingRepo SettingRepository, notificationEmailService *NotificationEmailService, lockCache LeaderLockCache, db *sql.DB) *SubscriptionExpiryService {
 	svc := NewSubscriptionExpiryService(userSubRepo, time.Minute)
@@ -249,12 +275,22 @@ func ProvideDeferredService(accountRepo AccountRepository, timingWheel *TimingWh
 // ProvideConcurrencyService creates ConcurrencyService and starts slot cleanup worker.
 func ProvideConcurrencyService(cache ConcurrencyCache, accountRepo AccountRepository, cfg *config.Config) *ConcurrencyService {
 	svc := NewConcurrencyService(cache)
+	if reader, ok := accountRepo.(AccountGroupShareReader); ok {
+		svc.SetAccountGroupShareReader(reader)
+	}
 	if err := svc.CleanupStaleProcessSlots(context.Background()); err != nil {
 		logger.LegacyPrintf("service.concurrency", "Warning: startup cleanup stale process slots failed: %v", err)
 	}
 	if cfg != nil {
 		svc.SetAccountLoadBatchCacheTTL(time.Duration(cfg.Gateway.Scheduling.LoadBatchCacheTTLMS) * time.Millisecond)
 		svc.StartSlotCleanupWorker(accountRepo, cfg.Gateway.Scheduling.SlotCleanupInterval)
+		if fairness := cfg.Gateway.Scheduling.UserFairness; fairness.Enabled {
+			svc.SetUserFairnessTracker(NewUserFairnessTracker(UserFairnessConfig{
+				HalfLife:             time.Duration(fairness.HalfLifeSeconds) * time.Second,
+				MaxBackoffMultiplier: fairness.MaxBackoffMultiplier,
+				MinActiveUsers:       fairness.MinActiveUsers,
+			}))
+		}
 	}
 	return svc
 }
@@ -292,12 +328,14 @@ func ProvideRateLimitService(
 	openAI403CounterCache OpenAI403CounterCache,
 	settingService *SettingService,
 	tokenCacheInvalidator TokenCacheInvalidator,
+	proxyLatencyCache ProxyLatencyCache,
 ) *RateLimitService {
 	svc := NewRateLimitService(accountRepo, usageRepo, cfg, geminiQuotaService, tempUnschedCache)
 	svc.SetTimeoutCounterCache(timeoutCounterCache)
 	svc.SetOpenAI403CounterCache(openAI403CounterCache)
 	svc.SetSettingService(settingService)
 	svc.SetTokenCacheInvalidator(tokenCacheInvalidator)
+	svc.SetProxyLatencyCache(proxyLatencyCache)
 	return svc
 }
 
@@ -411,6 +449,13 @@ func ProvideIdempotencyCleanupService(repo IdempotencyRepository, cfg *config.Co
 	return svc
 }
 
+// ProvideConfigSyncService creates ConfigSyncService and starts replica pulling when configured.
+func ProvideConfigSyncService(repo ConfigSyncRepository, authCacheInvalidator APIKeyAuthCacheInvalidator, cfg *config.Config) *ConfigSyncService {
+	svc := NewConfigSyncService(repo, authCacheInvalidator, cfg)
+	svc.Start()
+	return svc
+}
+
 // ProvideScheduledTestService creates ScheduledTestService.
 func ProvideScheduledTestService(
 	planRepo ScheduledTestPlanRepository,
@@ -469,6 +514,66 @@ func ProvideBackupService(
 // auto-pause cache sink. Mirrors the SetCleanupReloader pattern: OpsService doesn't
 // hold a *SettingService reference, but wire injects a tiny callback so writes to
 // ops_advanced_settings immediately propagate into the scheduler hot-path cache.
+// ProvideOpsUpstreamRequestIDRecorder 创建上游请求 ID 关联记录器并挂到 HTTPUpstream；ops 硬开关关闭时不启用。
+// ProvideOpsWatchdogService creates and starts the resource watchdog when ops.watchdog is enabled.
+func ProvideOpsWatchdogService(opsService *OpsService, emailService *EmailService, cfg *config.Config) *OpsWatchdogService {
+	if cfg == nil || !cfg.Ops.Enabled || !cfg.Ops.Watchdog.Enabled {
+		return nil
+	}
+	w := NewOpsWatchdogService(opsService, emailService, cfg.Ops.Watchdog)
+	w.Start()
+	opsService.SetWatchdog(w)
+	return w
+}
+
+// ProvideOpsSchemaDriftDetector creates and starts the upstream schema drift detector when ops.schema_drift
+// is enabled, and hooks it into HTTPUpstream to sample decompressed responses.
+func ProvideOpsSchemaDriftDetector(opsService *OpsService, httpUpstream HTTPUpstream, cfg *config.Config) *OpsSchemaDriftDetector {
+	if cfg == nil || !cfg.Ops.Enabled || !cfg.Ops.SchemaDrift.Enabled {
+		return nil
+	}
+	d := NewOpsSchemaDriftDetector(cfg.Ops.SchemaDrift)
+	d.Start()
+	if setter, ok := httpUpstream.(HTTPUpstreamResponseInspectorSetter); ok {
+		setter.SetResponseInspector(d)
+	}
+	opsService.SetSchemaDriftDetector(d)
+	return d
+}
+
+func ProvideOpsUpstreamRequestIDRecorder(opsRepo OpsRepository, httpUpstream HTTPUpstream, cfg *config.Config) *OpsUpstreamRequestIDRecorder {
+	if cfg != nil && !cfg.Ops.Enabled {
+		return nil
+	}
+	recorder := NewOpsUpstreamRequestIDRecorder(opsRepo)
+	recorder.Start()
+	if setter, ok := httpUpstream.(HTTPUpstreamObserverSetter); ok {
+		setter.SetObserver(recorder)
+	}
+	return recorder
+}
+
+// ProvideOpsStreamTimingRecorder 创建采样流耗时记录器并注入网关服务；未配置采样率或 ops 硬开关关闭时不启用。
+func ProvideOpsStreamTimingRecorder(
+	opsRepo OpsRepository,
+	cfg *config.Config,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+) *OpsStreamTimingRecorder {
+	if cfg == nil || !cfg.Ops.Enabled || cfg.Ops.StreamTimingSampleRate <= 0 {
+		return nil
+	}
+	recorder := NewOpsStreamTimingRecorder(opsRepo, cfg.Ops.StreamTimingSampleRate)
+	recorder.Start()
+	if gatewayService != nil {
+		gatewayService.SetStreamTimingRecorder(recorder)
+	}
+	if openAIGatewayService != nil {
+		openAIGatewayService.SetStreamTimingRecorder(recorder)
+	}
+	return recorder
+}
+
 func ProvideOpsService(
 	opsRepo OpsRepository,
 	settingRepo SettingRepository,
@@ -482,6 +587,9 @@ func ProvideOpsService(
 	antigravityGatewayService *AntigravityGatewayService,
 	systemLogSink *OpsSystemLogSink,
 	settingService *SettingService,
+	proxyRepo ProxyRepository,
+	proxyLatencyCache ProxyLatencyCache,
+	usageLogRepo UsageLogRepository,
 ) *OpsService {
 	svc := NewOpsService(
 		opsRepo,
@@ -496,6 +604,16 @@ func ProvideOpsService(
 		antigravityGatewayService,
 		systemLogSink,
 	)
+	svc.SetProxyProbeSource(proxyRepo, proxyLatencyCache)
+	svc.SetUsageStatsSource(usageLogRepo)
+	if tracker := newLatencySLOTrackerFromConfig(cfg, svc); tracker != nil {
+		if gatewayService != nil {
+			gatewayService.SetLatencySLOTracker(tracker)
+		}
+		if openAIGatewayService != nil {
+			openAIGatewayService.SetLatencySLOTracker(tracker)
+		}
+	}
 	if settingService != nil {
 		svc.SetOpenAIQuotaAutoPauseSettingsSink(settingService.SetOpenAIQuotaAutoPauseSettings)
 		// Optional warm-up so the first scheduled request after process start observes
@@ -505,6 +623,70 @@ func ProvideOpsService(
 	return svc
 }
 
+// ProvideAPIKeyTraceService constructs APIKeyTraceService and hands it to OpsService
+// so exported debug bundles can include the traced request/response of a request.
+func ProvideAPIKeyTraceService(cache APIKeyTraceCache, apiKeyRepo APIKeyRepository, settingRepo SettingRepository, cfg *config.Config, opsService *OpsService) *APIKeyTraceService {
+	svc := NewAPIKeyTraceService(cache, apiKeyRepo, settingRepo, cfg)
+	opsService.SetAPIKeyTraceSource(svc)
+	return svc
+}
+
+// ProvideOpenAIGatewayService creates OpenAIGatewayService and attaches the optional account usage window tracker and batch repository.
+func ProvideOpenAIGatewayService(
+	accountRepo AccountRepository,
+	usageLogRepo UsageLogRepository,
+	usageBillingRepo UsageBillingRepository,
+	userRepo UserRepository,
+	userSubRepo UserSubscriptionRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	cache GatewayCache,
+	cfg *config.Config,
+	schedulerSnapshot *SchedulerSnapshotService,
+	concurrencyService *ConcurrencyService,
+	billingService *BillingService,
+	rateLimitService *RateLimitService,
+	billingCacheService *BillingCacheService,
+	httpUpstream HTTPUpstream,
+	deferredService *DeferredService,
+	openAITokenProvider *OpenAITokenProvider,
+	grokTokenProvider *GrokTokenProvider,
+	resolver *ModelPricingResolver,
+	channelService *ChannelService,
+	balanceNotifyService *BalanceNotifyService,
+	settingService *SettingService,
+	userPlatformQuotaRepo UserPlatformQuotaRepository,
+	usageWindowCache AccountUsageWindowCache,
+	batchRepo OpenAIBatchRepository,
+) *OpenAIGatewayService {
+	svc := NewOpenAIGatewayService(
+		accountRepo,
+		usageLogRepo,
+		usageBillingRepo,
+		userRepo,
+		userSubRepo,
+		userGroupRateRepo,
+		cache,
+		cfg,
+		schedulerSnapshot,
+		concurrencyService,
+		billingService,
+		rateLimitService,
+		billingCacheService,
+		httpUpstream,
+		deferredService,
+		openAITokenProvider,
+		grokTokenProvider,
+		resolver,
+		channelService,
+		balanceNotifyService,
+		settingService,
+		userPlatformQuotaRepo,
+	)
+	svc.SetAccountUsageWindowTracker(newAccountUsageWindowTrackerFromConfig(cfg, usageWindowCache))
+	svc.SetOpenAIBatchRepository(batchRepo)
+	return svc
+}
+
 // ProvideSettingService wires SettingService with group reader and proxy repo.
 func ProvideSettingService(settingRepo SettingRepository, groupRepo GroupRepository, proxyRepo ProxyRepository, cfg *config.Config) *SettingService {
 	svc := NewSettingService(settingRepo, cfg)
@@ -548,10 +730,26 @@ func ProvideAPIKeyService(
 	cfg *config.Config,
 	billingCacheService *BillingCacheService,
 	concurrencyService *ConcurrencyService,
+	projectRepo APIKeyProjectRepository,
 ) *APIKeyService {
 	svc := NewAPIKeyService(apiKeyRepo, userRepo, groupRepo, userSubRepo, userGroupRateRepo, cache, cfg)
 	svc.SetRateLimitCacheInvalidator(billingCacheService)
 	svc.SetConcurrencyService(concurrencyService)
+	svc.SetProjectRepository(projectRepo)
+	return svc
+}
+
+// ProvideSpendBudgetService wires SpendBudgetService and enables budget checks in billing eligibility.
+func ProvideSpendBudgetService(repo SpendBudgetRepository, billingCacheService *BillingCacheService) *SpendBudgetService {
+	svc := NewSpendBudgetService(repo)
+	billingCacheService.SetSpendBudgetService(svc)
+	return svc
+}
+
+// ProvideModelPriceService wires ModelPriceService and makes the price table the preferred base pricing source.
+func ProvideModelPriceService(repo ModelPriceRepository, billingService *BillingService) *ModelPriceService {
+	svc := NewModelPriceService(repo)
+	billingService.SetModelPriceService(svc)
 	return svc
 }
 
@@ -562,6 +760,8 @@ var ProviderSet = wire.NewSet(
 	NewUserService,
 	ProvideAPIKeyService,
 	ProvideAPIKeyAuthCacheInvalidator,
+	NewAPIKeyProjectService,
+	NewAdminServiceTokenService,
 	NewGroupService,
 	NewAccountService,
 	NewProxyService,
@@ -569,13 +769,24 @@ var ProviderSet = wire.NewSet(
 	NewPromoService,
 	NewUsageService,
 	NewDashboardService,
+	NewCapacityForecastService,
+	NewCacheEfficiencyService,
+	NewClientAnalyticsService,
+	NewMappingSimulationService,
+	NewTransformFixtureService,
+	ProvideSpendBudgetService,
+	ProvideModelPriceService,
+	NewUsageReportService,
+	NewDataExportService,
+	NewRequestClassSLOService,
 	ProvidePricingService,
 	NewBillingService,
 	ProvideBillingCacheService,
+	NewPrepaidBalanceService,
 	NewAnnouncementService,
 	NewAdminService,
 	NewGatewayService,
-	NewOpenAIGatewayService,
+	ProvideOpenAIGatewayService,
 	ProvideBatchImageModelPricingResolver,
 	NewBatchImagePublicService,
 	NewBatchImageDownloadService,
@@ -607,12 +818,16 @@ var ProviderSet = wire.NewSet(
 	NewDataManagementService,
 	ProvideBackupService,
 	ProvideOpsSystemLogSink,
+	ProvideOpsUpstreamRequestIDRecorder,
+	ProvideOpsStreamTimingRecorder,
 	ProvideOpsService,
 	ProvideOpsMetricsCollector,
 	ProvideOpsAggregationService,
 	ProvideOpsAlertEvaluatorService,
 	ProvideOpsCleanupService,
 	ProvideOpsScheduledReportService,
+	ProvideOpsWatchdogService,
+	ProvideOpsSchemaDriftDetector,
 	NewEmailService,
 	NewNotificationEmailService,
 	ProvideEmailQueueService,
@@ -628,7 +843,10 @@ var ProviderSet = wire.NewSet(
 	ProvideUpdateService,
 	ProvideTokenRefreshService,
 	ProvideAccountExpiryService,
+	ProvideStaleAccountService,
+	ProvideAccountLeaseService,
 	ProvideProxyExpiryService,
+	ProvideGuestAPIKeyCleanupService,
 	ProvideSubscriptionExpiryService,
 	ProvideTimingWheelService,
 	ProvideDashboardAggregationService,
@@ -645,6 +863,8 @@ var ProviderSet = wire.NewSet(
 	ProvideIdempotencyCoordinator,
 	ProvideSystemOperationLockService,
 	ProvideIdempotencyCleanupService,
+	ProvideConfigSyncService,
+	ProvideAPIKeyTraceService,
 	ProvideScheduledTestService,
 	ProvideScheduledTestRunnerService,
 	NewGroupCapacityService,
@@ -656,6 +876,9 @@ var ProviderSet = wire.NewSet(
 	ProvidePaymentService,
 	ProvidePaymentOrderExpiryService,
 	ProvideBalanceNotifyService,
+	ProvideUserUsageAlertService,
+	ProvideBillingStatementService,
+	NewConfigVersionService,
 	ProvideChannelMonitorService,
 	ProvideChannelMonitorRunner,
 	NewChannelMonitorRequestTemplateService,
@@ -697,6 +920,45 @@ func ProvidePaymentOrderExpiryService(paymentSvc *PaymentService, lockCache Lead
 	return svc
 }
 
+// ProvideUserUsageAlertService creates and starts UserUsageAlertService.
+func ProvideUserUsageAlertService(
+	repo UserUsageAlertRepository,
+	userRepo UserRepository,
+	apiKeyRepo APIKeyRepository,
+	settingRepo SettingRepository,
+	emailService *EmailService,
+	lockCache LeaderLockCache,
+	db *sql.DB,
+) *UserUsageAlertService {
+	svc := NewUserUsageAlertService(repo, userRepo, apiKeyRepo, settingRepo, emailService)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideBillingStatementService creates and starts BillingStatementService.
+// Stripe 客户端仅在 billing.statements.stripe.enabled 时创建。
+func ProvideBillingStatementService(
+	cfg *config.Config,
+	repo BillingStatementRepository,
+	userRepo UserRepository,
+	lockCache LeaderLockCache,
+	db *sql.DB,
+) (*BillingStatementService, error) {
+	var stripeClient BillingStripeClient
+	if stripeCfg := cfg.Billing.Statements.Stripe; stripeCfg.Enabled {
+		client, err := provider.NewStripeBilling(stripeCfg.SecretKey, stripeCfg.WebhookSecret, stripeCfg.Currency)
+		if err != nil {
+			return nil, err
+		}
+		stripeClient = client
+	}
+	svc := NewBillingStatementService(repo, userRepo, stripeClient, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc, nil
+}
+
 // ProvideChannelMonitorService 创建渠道监控服务（CRUD + RunCheck + 用户视图聚合）。
 // 加密器复用 wire 中已注入的 SecretEncryptor（AES-256-GCM）。
 func ProvideChannelMonitorService(
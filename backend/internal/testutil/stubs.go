@@ -136,3 +136,15 @@ func (c StubSessionLimitCache) SetWindowCost(_ context.Context, _ int64, _ float
 func (c StubSessionLimitCache) GetWindowCostBatch(_ context.Context, _ []int64) (map[int64]float64, error) {
 	return nil, nil
 }
+func (c StubSessionLimitCache) ReserveWindowCost(_ context.Context, _ int64, _ string, _ float64, _ time.Duration) error {
+	return nil
+}
+func (c StubSessionLimitCache) SettleWindowCost(_ context.Context, _ int64, _ string, _ float64) error {
+	return nil
+}
+func (c StubSessionLimitCache) ReleaseWindowCost(_ context.Context, _ int64, _ string) error {
+	return nil
+}
+func (c StubSessionLimitCache) GetReservedWindowCostBatch(_ context.Context, _ []int64) (map[int64]float64, error) {
+	return nil, nil
+}
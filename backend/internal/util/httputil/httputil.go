@@ -9,9 +9,11 @@ import (
 )
 
 var (
-	cfRayPattern  = regexp.MustCompile(`(?i)cf-ray[:\s=]+([a-z0-9-]+)`)
-	cRayPattern   = regexp.MustCompile(`(?i)cRay:\s*'([a-z0-9-]+)'`)
-	htmlChallenge = []string{
+	cfRayPattern = regexp.MustCompile(`(?i)cf-ray[:\s=]+([a-z0-9-]+)`)
+	cRayPattern  = regexp.MustCompile(`(?i)cRay:\s*'([a-z0-9-]+)'`)
+	// htmlTitlePattern extracts the <title> of HTML error pages.
+	htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlChallenge    = []string{
 		"window._cf_chl_opt",
 		"just a moment",
 		"enable javascript and cookies to continue",
@@ -25,7 +27,12 @@ func IsCloudflareChallengeResponse(statusCode int, headers http.Header, body []b
 	if statusCode != http.StatusForbidden && statusCode != http.StatusTooManyRequests {
 		return false
 	}
+	return HasCloudflareChallengeMarkers(headers, body)
+}
 
+// HasCloudflareChallengeMarkers reports whether headers/body carry Cloudflare challenge markers,
+// regardless of status code (challenges are also served with 503).
+func HasCloudflareChallengeMarkers(headers http.Header, body []byte) bool {
 	if headers != nil && strings.EqualFold(strings.TrimSpace(headers.Get("cf-mitigated")), "challenge") {
 		return true
 	}
@@ -50,6 +57,44 @@ func IsCloudflareChallengeResponse(statusCode int, headers http.Header, body []b
 	return false
 }
 
+// IsHTMLResponse reports whether the response is an HTML page (proxy/CDN error page, challenge, captive portal)
+// rather than an API payload. Either a text/html content type or a leading HTML document marker is enough.
+func IsHTMLResponse(headers http.Header, body []byte) bool {
+	if headers != nil {
+		contentType := strings.ToLower(strings.TrimSpace(headers.Get("content-type")))
+		if strings.HasPrefix(contentType, "text/html") || strings.HasPrefix(contentType, "application/xhtml") {
+			return true
+		}
+	}
+	return LooksLikeHTML(body)
+}
+
+// LooksLikeHTML reports whether body starts like an HTML document.
+func LooksLikeHTML(body []byte) bool {
+	trimmed := strings.TrimSpace(string(body))
+	if trimmed == "" || trimmed[0] != '<' {
+		return false
+	}
+	if len(trimmed) > 512 {
+		trimmed = trimmed[:512]
+	}
+	lower := strings.ToLower(trimmed)
+	return strings.HasPrefix(lower, "<!doctype html") ||
+		strings.HasPrefix(lower, "<html") ||
+		strings.Contains(lower, "<head") ||
+		strings.Contains(lower, "<body") ||
+		strings.Contains(lower, "<title")
+}
+
+// ExtractHTMLTitle returns the trimmed <title> text of an HTML page, or "".
+func ExtractHTMLTitle(body []byte) string {
+	preview := TruncateBody(body, 8192)
+	if matches := htmlTitlePattern.FindStringSubmatch(preview); len(matches) >= 2 {
+		return strings.Join(strings.Fields(matches[1]), " ")
+	}
+	return ""
+}
+
 // ExtractCloudflareRayID extracts cf-ray from headers or response body.
 func ExtractCloudflareRayID(headers http.Header, body []byte) string {
 	if headers != nil {
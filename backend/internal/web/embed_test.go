@@ -458,6 +458,7 @@ func TestFrontendServer_Middleware(t *testing.T) {
 			"/antigravity/test",
 			"/setup/init",
 			"/health",
+			"/metrics",
 			"/responses",
 			"/responses/compact",
 		}
@@ -662,6 +663,7 @@ func TestServeEmbeddedFrontend(t *testing.T) {
 			"/antigravity/test",
 			"/setup/init",
 			"/health",
+			"/metrics",
 			"/responses",
 			"/responses/compact",
 		}
@@ -0,0 +1,11 @@
+-- 关键 API Key 预留容量：
+--   - groups.reserved_concurrency_ratio：账号并发中为关键 Key 预留的比例（0 = 不预留）
+--   - api_keys.critical：标记为关键的 Key 可以占用预留槽位，其余 Key 只能使用剩余并发
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS reserved_concurrency_ratio decimal(5,4) NOT NULL DEFAULT 0;
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS critical boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN groups.reserved_concurrency_ratio IS '账号并发预留比例 [0,1)，非关键 Key 无法占用预留槽位。';
+COMMENT ON COLUMN api_keys.critical IS '关键 Key，可占用分组预留的账号并发槽位。';
@@ -0,0 +1,10 @@
+-- 上游错误归一化代码：将各家上游五花八门的错误体（OpenAI / ChatGPT 内部接口 / Anthropic / Gemini / 代理 HTML 错误页）
+-- 映射为稳定代码（rate_limited、quota_exhausted、html_error_page ...），供看板与冷却策略使用。
+ALTER TABLE ops_error_logs
+    ADD COLUMN IF NOT EXISTS error_code VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_ops_error_logs_error_code_created_at
+    ON ops_error_logs (error_code, created_at)
+    WHERE error_code IS NOT NULL;
+
+COMMENT ON COLUMN ops_error_logs.error_code IS '归一化上游错误代码，非上游错误为空。';
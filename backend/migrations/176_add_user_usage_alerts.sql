@@ -0,0 +1,25 @@
+-- 用户自助用量告警规则。用户为自己的全部 Key（api_key_id IS NULL）或单个 Key 设置滑动窗口内的用量阈值，
+-- 超过阈值时回调用户自己配置的 webhook 和/或通知邮箱，与管理员侧的 ops 告警相互独立。
+-- last_triggered_at 用于冷却：同一规则在一个窗口时长内最多触发一次。
+
+CREATE TABLE IF NOT EXISTS user_usage_alerts (
+    id                BIGSERIAL PRIMARY KEY,
+    user_id           BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id        BIGINT REFERENCES api_keys(id) ON DELETE CASCADE,
+    metric            VARCHAR(16) NOT NULL CHECK (metric IN ('cost', 'requests', 'tokens')),
+    window_minutes    INT NOT NULL CHECK (window_minutes > 0),
+    threshold         DECIMAL(20,10) NOT NULL CHECK (threshold > 0),
+    webhook_url       TEXT NOT NULL DEFAULT '',
+    notify_email      BOOLEAN NOT NULL DEFAULT FALSE,
+    enabled           BOOLEAN NOT NULL DEFAULT TRUE,
+    last_triggered_at TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS userusagealert_user_id
+    ON user_usage_alerts (user_id);
+
+CREATE INDEX IF NOT EXISTS userusagealert_enabled
+    ON user_usage_alerts (enabled)
+    WHERE enabled = TRUE;
@@ -0,0 +1,39 @@
+-- 月度账单。每个 (user_id, period_start) 一张，由 usage_logs 汇总生成（ON CONFLICT DO NOTHING 保证重复生成幂等）。
+-- line_items 为按模型拆分的明细；stripe_* 字段记录可选的 Stripe 收费进度。
+
+CREATE TABLE IF NOT EXISTS billing_statements (
+    id                 BIGSERIAL PRIMARY KEY,
+    user_id            BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start       TIMESTAMPTZ NOT NULL,
+    period_end         TIMESTAMPTZ NOT NULL,
+    total_requests     BIGINT NOT NULL DEFAULT 0,
+    total_tokens       BIGINT NOT NULL DEFAULT 0,
+    -- 标准价总额 / 倍率后实际扣费
+    total_cost         DECIMAL(20,10) NOT NULL DEFAULT 0,
+    actual_cost        DECIMAL(20,10) NOT NULL DEFAULT 0,
+    line_items         JSONB NOT NULL DEFAULT '[]'::jsonb,
+    status             VARCHAR(16) NOT NULL DEFAULT 'issued'
+                       CHECK (status IN ('issued', 'invoiced', 'reported', 'paid', 'void')),
+    stripe_invoice_id  VARCHAR(128) NOT NULL DEFAULT '',
+    stripe_invoice_url TEXT NOT NULL DEFAULT '',
+    stripe_error       TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS billingstatement_user_id_period_start_uq
+    ON billing_statements (user_id, period_start);
+
+CREATE INDEX IF NOT EXISTS billingstatement_period_start
+    ON billing_statements (period_start);
+
+CREATE INDEX IF NOT EXISTS billingstatement_stripe_invoice_id
+    ON billing_statements (stripe_invoice_id)
+    WHERE stripe_invoice_id <> '';
+
+-- 用户与 Stripe Customer 的映射，首次推送账单时创建。
+CREATE TABLE IF NOT EXISTS billing_stripe_customers (
+    user_id     BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    customer_id VARCHAR(128) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- 过载降级：首选账号全部饱和/冷却时，按分组映射把请求降级到备用模型，而不是直接返回 429/503。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS overload_fallback_models jsonb;
+
+COMMENT ON COLUMN groups.overload_fallback_models IS '过载降级模型映射：模型模式 -> 备用模型，为空表示不降级。';
@@ -0,0 +1,7 @@
+-- 长上下文路由：估算输入超出模型上下文窗口时，按分组映射改用大上下文模型，或在转发前直接拒绝。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS context_overflow_models jsonb,
+    ADD COLUMN IF NOT EXISTS context_overflow_reject boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN groups.context_overflow_models IS '长上下文路由映射：模型模式 -> 大上下文模型，为空表示不改路由。';
+COMMENT ON COLUMN groups.context_overflow_reject IS '估算输入超出上下文窗口且无可用大上下文模型时直接拒绝（400）。';
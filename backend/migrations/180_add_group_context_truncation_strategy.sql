@@ -0,0 +1,5 @@
+-- 长上下文截断：估算输入超出上下文窗口且无法改路由时，按分组策略丢弃历史轮次（drop_oldest/summarize/middle_out）。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS context_truncation_strategy varchar(20) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.context_truncation_strategy IS '超出上下文窗口时的截断策略：drop_oldest/summarize/middle_out，空表示不截断。';
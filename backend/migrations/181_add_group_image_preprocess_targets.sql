@@ -0,0 +1,7 @@
+-- 图片预处理：转发前把超出分组目标的 base64 图片等比缩放并重新压缩，降低 token 成本并避免上游尺寸限制报错。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS image_max_edge integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS image_max_bytes integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN groups.image_max_edge IS '图片最长边上限（像素），超出时等比缩放，0 表示不限制。';
+COMMENT ON COLUMN groups.image_max_bytes IS '单张图片字节数目标，超出时重新压缩，0 表示不限制。';
@@ -0,0 +1,5 @@
+-- 输出后处理：对最终输出文本（JSON 与流式增量）按分组规则做正则替换、违禁词过滤与追加署名。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS output_postprocess JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.output_postprocess IS '输出后处理配置：rules（regex/banned_phrase 替换规则）与 attribution（追加署名），为空表示不处理。';
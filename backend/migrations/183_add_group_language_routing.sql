@@ -0,0 +1,5 @@
+-- 语言路由：按提示词主要语言改用其他模型，或优先调度指定账号/代理下的账号。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS language_routing JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.language_routing IS '语言路由规则：rules[].languages 命中时改写 model 或优先 account_ids/proxy_ids 对应的账号，为空表示不启用。';
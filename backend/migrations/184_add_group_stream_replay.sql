@@ -0,0 +1,5 @@
+-- 流式断线续传：缓存进行中响应的最近 SSE 事件，客户端可携带 Last-Event-ID 重连续读。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS stream_replay JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.stream_replay IS '流式断线续传配置：enabled 开启后按 max_events/max_bytes 缓存 SSE 事件并在 retention_seconds 内支持 Last-Event-ID 重连，为空表示不启用。';
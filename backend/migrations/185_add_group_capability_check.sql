@@ -0,0 +1,5 @@
+-- 模型能力校验：请求包含映射后模型不支持的图片/音频/工具/推理时改用备用模型或提前拒绝。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS capability_check JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.capability_check IS '模型能力校验配置：enabled 开启后按模型能力矩阵校验请求内容，fallback_models 为缺少能力时改用的备用模型映射，为空表示不启用。';
@@ -0,0 +1,25 @@
+-- 管理端配置变更历史。每次分组/账号/渠道/错误透传规则变更后追加一条快照，用于查看差异与回滚。
+-- snapshot 为实体可回滚字段的 JSON（账号不含凭证密钥），snapshot_hash 用于跳过内容未变化的重复记录。
+
+CREATE TABLE IF NOT EXISTS config_versions (
+    id            BIGSERIAL PRIMARY KEY,
+    entity_type   VARCHAR(32) NOT NULL
+                  CHECK (entity_type IN ('group', 'account', 'channel', 'error_passthrough_rule')),
+    entity_id     BIGINT NOT NULL,
+    version       INT NOT NULL,
+    -- baseline 为首次纳入版本管理时补录的变更前状态
+    action        VARCHAR(16) NOT NULL
+                  CHECK (action IN ('baseline', 'create', 'update', 'delete', 'rollback')),
+    snapshot      JSONB NOT NULL DEFAULT '{}'::jsonb,
+    snapshot_hash VARCHAR(64) NOT NULL DEFAULT '',
+    operator_id   BIGINT,
+    -- 回滚记录指向被恢复的版本号
+    restored_from INT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS configversion_entity_version_uq
+    ON config_versions (entity_type, entity_id, version);
+
+CREATE INDEX IF NOT EXISTS configversion_created_at
+    ON config_versions (created_at);
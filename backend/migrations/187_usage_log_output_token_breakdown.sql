@@ -0,0 +1,3 @@
+-- 输出 token 构成：推理 token（上游明细）与工具调用参数 token（按响应内容估算），均包含在 output_tokens 内。
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS reasoning_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS tool_call_tokens INTEGER NOT NULL DEFAULT 0;
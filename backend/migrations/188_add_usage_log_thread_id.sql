@@ -0,0 +1,2 @@
+-- 客户端会话/线程 ID（X-Sub2API-Thread-ID），用于按会话汇总用量与成本。
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS thread_id VARCHAR(128);
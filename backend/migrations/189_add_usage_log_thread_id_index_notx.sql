@@ -0,0 +1,6 @@
+-- 189_add_usage_log_thread_id_index_notx.sql
+-- Non-transactional migration: CREATE INDEX CONCURRENTLY cannot run in a transaction.
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_usage_logs_thread_id_user_id
+  ON usage_logs (thread_id, user_id)
+  WHERE thread_id IS NOT NULL;
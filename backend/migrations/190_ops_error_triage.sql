@@ -0,0 +1,25 @@
+-- 错误日志处理流程：处理状态（open/investigating/resolved/ignored）、负责人与评论，
+-- 使错误列表可作为小团队的轻量事件队列。
+-- triage_status 为空的历史行按 resolved 推导（true → resolved，否则 open），无需回填大表。
+
+ALTER TABLE ops_error_logs
+  ADD COLUMN IF NOT EXISTS triage_status VARCHAR(16);
+
+ALTER TABLE ops_error_logs
+  ADD COLUMN IF NOT EXISTS assignee_user_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_ops_error_logs_assignee_time
+  ON ops_error_logs (assignee_user_id, created_at DESC)
+  WHERE assignee_user_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ops_error_comments (
+    id         BIGSERIAL PRIMARY KEY,
+    -- 错误日志按保留期清理时评论随之删除
+    error_id   BIGINT NOT NULL REFERENCES ops_error_logs(id) ON DELETE CASCADE,
+    user_id    BIGINT,
+    body       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_error_comments_error_time
+  ON ops_error_comments (error_id, created_at);
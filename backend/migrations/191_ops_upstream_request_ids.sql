@@ -0,0 +1,22 @@
+-- 上游请求 ID 索引：记录上游返回的 x-request-id / request-id 与网关 client_request_id 的对应关系。
+-- 在收到上游响应头时即写入，不依赖使用记录或错误日志落库，用于与上游客服/日志对账。
+-- 按 ops 错误日志保留期清理。
+
+CREATE TABLE IF NOT EXISTS ops_upstream_request_ids (
+    id                  BIGSERIAL PRIMARY KEY,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    client_request_id   VARCHAR(64) NOT NULL,
+    upstream_request_id VARCHAR(128) NOT NULL,
+    account_id          BIGINT,
+    upstream_host       VARCHAR(255),
+    status_code         INT
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_upstream_request_ids_upstream
+  ON ops_upstream_request_ids (upstream_request_id);
+
+CREATE INDEX IF NOT EXISTS idx_ops_upstream_request_ids_client
+  ON ops_upstream_request_ids (client_request_id);
+
+CREATE INDEX IF NOT EXISTS idx_ops_upstream_request_ids_created_at
+  ON ops_upstream_request_ids (created_at);
@@ -0,0 +1,32 @@
+-- 采样流式请求的逐事件耗时摘要：上游事件间隔分布与中转写出延迟，用于判断慢流卡在上游还是本服务中转。
+-- 采样比例由 ops.stream_timing_sample_rate 控制（默认关闭）；按 ops 错误日志保留期清理。
+
+CREATE TABLE IF NOT EXISTS ops_stream_timings (
+    id                     BIGSERIAL PRIMARY KEY,
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    request_id             VARCHAR(128),
+    client_request_id      VARCHAR(64),
+    account_id             BIGINT,
+    platform               VARCHAR(32),
+    model                  VARCHAR(100),
+    outcome                VARCHAR(32) NOT NULL,
+    event_count            INT NOT NULL DEFAULT 0,
+    duration_ms            BIGINT NOT NULL DEFAULT 0,
+    first_event_ms         BIGINT,
+    max_upstream_gap_ms    BIGINT NOT NULL DEFAULT 0,
+    max_relay_lag_ms       BIGINT NOT NULL DEFAULT 0,
+    avg_relay_lag_ms       BIGINT NOT NULL DEFAULT 0,
+    upstream_gap_histogram JSONB NOT NULL DEFAULT '{}'::jsonb,
+    stall_source           VARCHAR(16) NOT NULL DEFAULT 'none'
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_stream_timings_request_id
+  ON ops_stream_timings (request_id)
+  WHERE request_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ops_stream_timings_client_request_id
+  ON ops_stream_timings (client_request_id)
+  WHERE client_request_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ops_stream_timings_created_at
+  ON ops_stream_timings (created_at);
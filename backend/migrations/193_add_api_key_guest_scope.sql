@@ -0,0 +1,16 @@
+-- 临时访客 API Key：
+--   - api_keys.guest：访客 Key，过期后由清理任务软删除
+--   - api_keys.allowed_models：允许请求的模型列表（支持尾部 * 通配，空 = 不限制）
+--   - api_keys.token_limit / tokens_used：Token 总量上限与已用量（0 = 不限制）
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS guest boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS allowed_models jsonb,
+    ADD COLUMN IF NOT EXISTS token_limit bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tokens_used bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS apikey_guest_expires_at ON api_keys (guest, expires_at);
+
+COMMENT ON COLUMN api_keys.guest IS '临时访客 Key，过期后由清理任务删除。';
+COMMENT ON COLUMN api_keys.allowed_models IS '允许请求的模型列表，支持尾部 * 通配；为空表示不限制。';
+COMMENT ON COLUMN api_keys.token_limit IS 'Token 总量上限（输入 + 输出 + 缓存），0 表示不限制。';
+COMMENT ON COLUMN api_keys.tokens_used IS '已消耗的 Token 总量。';
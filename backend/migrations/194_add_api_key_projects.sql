@@ -0,0 +1,27 @@
+-- API Key 项目：把同一用户的多个 Key 归入一个命名项目，共享项目额度并汇总用量。
+--   - api_key_projects.quota / quota_used：项目合计额度（USD，0 = 不限制）与已用量，
+--     成员 Key 的每次扣费同时累加到项目，超额后项目内所有 Key 一并拒绝
+--   - api_keys.project_id：所属项目，删除项目时置空
+
+CREATE TABLE IF NOT EXISTS api_key_projects (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    quota       DECIMAL(20,8) NOT NULL DEFAULT 0,
+    quota_used  DECIMAL(20,8) NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS apikeyproject_user_id_name
+    ON api_key_projects (user_id, name);
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS project_id BIGINT REFERENCES api_key_projects(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS apikey_project_id ON api_keys (project_id);
+
+COMMENT ON COLUMN api_key_projects.quota IS '项目合计额度（USD），0 表示不限制。';
+COMMENT ON COLUMN api_key_projects.quota_used IS '项目内所有 Key 已累计消耗的额度（USD）。';
+COMMENT ON COLUMN api_keys.project_id IS '所属 API Key 项目，为空表示未归入项目。';
@@ -0,0 +1,32 @@
+-- 管理员从调试包重放的上游请求结果：按抓包记录中的上游请求（可换账号）重新发起，结果关联原请求便于对比。
+-- 重放不计费、不写使用记录；按 ops 错误日志保留期清理。
+
+CREATE TABLE IF NOT EXISTS ops_request_replays (
+    id                      BIGSERIAL PRIMARY KEY,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by              BIGINT,
+    request_id              VARCHAR(128) NOT NULL,
+    api_key_id              BIGINT NOT NULL,
+    upstream_index          INT NOT NULL DEFAULT 0,
+    source_account_id       BIGINT,
+    account_id              BIGINT NOT NULL,
+    method                  VARCHAR(16) NOT NULL,
+    url                     TEXT NOT NULL,
+    status_code             INT NOT NULL DEFAULT 0,
+    duration_ms             BIGINT NOT NULL DEFAULT 0,
+    response_headers        JSONB NOT NULL DEFAULT '{}'::jsonb,
+    response_body           TEXT NOT NULL DEFAULT '',
+    response_body_truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    error_message           TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_request_replays_request_id
+  ON ops_request_replays (request_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_ops_request_replays_created_at
+  ON ops_request_replays (created_at);
+
+COMMENT ON COLUMN ops_request_replays.request_id IS '原请求的 request_id（抓包记录 / 调试包 key）';
+COMMENT ON COLUMN ops_request_replays.upstream_index IS '重放的是抓包记录中第几次上游调用（从 0 开始）';
+COMMENT ON COLUMN ops_request_replays.source_account_id IS '原上游调用使用的账号';
+COMMENT ON COLUMN ops_request_replays.error_message IS '请求未得到上游响应时的错误（网络错误、超时等）';
@@ -0,0 +1,28 @@
+-- 转换测试夹具：给定客户端请求与期望的上游请求，由管理员在真实转换管线上干跑（上游请求被截获、不发出），
+-- 用于在映射/策略变更上线前校验转换结果。
+
+CREATE TABLE IF NOT EXISTS transform_fixtures (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    endpoint    VARCHAR(64) NOT NULL,
+    account_id  BIGINT NOT NULL,
+    group_id    BIGINT,
+    request     JSONB NOT NULL DEFAULT '{}'::jsonb,
+    expect      JSONB NOT NULL DEFAULT '{}'::jsonb,
+    last_run_at TIMESTAMPTZ,
+    last_passed BOOLEAN,
+    created_by  BIGINT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transform_fixtures_name
+  ON transform_fixtures (name);
+
+COMMENT ON COLUMN transform_fixtures.endpoint IS '入站端点：/v1/messages、/v1/chat/completions、/v1/responses';
+COMMENT ON COLUMN transform_fixtures.account_id IS '干跑使用的账号（其模型映射与策略参与转换）';
+COMMENT ON COLUMN transform_fixtures.group_id IS '可选：应用该分组的渠道模型映射';
+COMMENT ON COLUMN transform_fixtures.request IS '客户端请求：{"headers":{...},"body":{...}}';
+COMMENT ON COLUMN transform_fixtures.expect IS '期望的上游请求：method/host/path/headers/absent_headers/body（JSON 子集匹配）';
+COMMENT ON COLUMN transform_fixtures.last_passed IS '最近一次按已保存配置运行的结果（草稿运行不回写）';
@@ -0,0 +1,48 @@
+-- Ops 告警 webhook 渠道：告警触发（可选恢复）时推送到通用 JSON / Slack / Telegram / 飞书。
+-- 投递记录按 ops 错误日志保留期清理。
+
+CREATE TABLE IF NOT EXISTS ops_alert_webhooks (
+    id              BIGSERIAL PRIMARY KEY,
+    name            VARCHAR(128) NOT NULL,
+    type            VARCHAR(16) NOT NULL,
+    url             TEXT NOT NULL,
+    secret          TEXT NOT NULL DEFAULT '',
+    chat_id         VARCHAR(128) NOT NULL DEFAULT '',
+    enabled         BOOLEAN NOT NULL DEFAULT TRUE,
+    rule_ids        JSONB NOT NULL DEFAULT '[]'::jsonb,
+    min_severity    VARCHAR(16) NOT NULL DEFAULT '',
+    notify_resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN ops_alert_webhooks.type IS 'generic / slack / telegram / lark';
+COMMENT ON COLUMN ops_alert_webhooks.url IS '推送地址；telegram 为 Bot API 根地址（默认 https://api.telegram.org）';
+COMMENT ON COLUMN ops_alert_webhooks.secret IS 'generic 的 HMAC 签名密钥 / 飞书签名密钥 / telegram bot token';
+COMMENT ON COLUMN ops_alert_webhooks.rule_ids IS '只推送这些规则的告警，空数组表示全部规则';
+COMMENT ON COLUMN ops_alert_webhooks.min_severity IS '最低推送级别（critical/warning/info），空表示不限';
+
+CREATE TABLE IF NOT EXISTS ops_alert_webhook_deliveries (
+    id           BIGSERIAL PRIMARY KEY,
+    webhook_id   BIGINT NOT NULL,
+    rule_id      BIGINT,
+    event_id     BIGINT,
+    event_status VARCHAR(16) NOT NULL,
+    success      BOOLEAN NOT NULL DEFAULT FALSE,
+    status_code  INT,
+    error        TEXT NOT NULL DEFAULT '',
+    duration_ms  BIGINT NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_alert_webhook_deliveries_webhook
+  ON ops_alert_webhook_deliveries (webhook_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_ops_alert_webhook_deliveries_event
+  ON ops_alert_webhook_deliveries (event_id);
+
+CREATE INDEX IF NOT EXISTS idx_ops_alert_webhook_deliveries_created_at
+  ON ops_alert_webhook_deliveries (created_at);
+
+COMMENT ON COLUMN ops_alert_webhook_deliveries.event_status IS 'firing / resolved / test';
+COMMENT ON COLUMN ops_alert_webhook_deliveries.error IS '投递失败原因（网络错误、非 2xx 状态码等）';
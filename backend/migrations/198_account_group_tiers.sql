@@ -0,0 +1,7 @@
+-- 分组内故障转移层级：账号在分组内按层级（0=primary / 1=secondary / 2=emergency）分档，
+-- 调度只在更高层级的账号全部不可用时才启用更低层级；层级在同层内仍按 priority 排序。
+
+ALTER TABLE account_groups
+    ADD COLUMN IF NOT EXISTS tier SMALLINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN account_groups.tier IS '分组内故障转移层级：0=primary，1=secondary，2=emergency。';
@@ -0,0 +1,7 @@
+-- 账号跨分组并发份额：同一账号绑定到多个分组时，可为每个分组设置其可占用的账号并发百分比
+-- （如分组 A 70%、分组 B 30%），由账号槽位获取时按分组单独计数限流，无需复制账号。
+
+ALTER TABLE account_groups
+    ADD COLUMN IF NOT EXISTS concurrency_share SMALLINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN account_groups.concurrency_share IS '分组可占用的账号并发百分比（1-100），0 表示不限制。';
@@ -0,0 +1,5 @@
+-- 账号选择策略：分组内负载感知调度挑选账号的方式（priority/weighted_round_robin/least_recently_used/lowest_latency）。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS account_selection_strategy varchar(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.account_selection_strategy IS '账号选择策略：weighted_round_robin/least_recently_used/lowest_latency，空表示按优先级逐级溢出（priority）。';
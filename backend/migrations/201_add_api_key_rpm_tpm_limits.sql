@@ -0,0 +1,9 @@
+-- API Key 吞吐限流（令牌桶）：
+--   - api_keys.rpm_limit：每分钟请求数上限（0 = 不限制）
+--   - api_keys.tpm_limit：每分钟 Token 数上限（输入 + 输出 + 缓存，0 = 不限制）
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS rpm_limit integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tpm_limit bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN api_keys.rpm_limit IS '每分钟请求数上限（令牌桶），0 表示不限制。';
+COMMENT ON COLUMN api_keys.tpm_limit IS '每分钟 Token 数上限（令牌桶，输入 + 输出 + 缓存），0 表示不限制。';
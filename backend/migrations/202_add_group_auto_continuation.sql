@@ -0,0 +1,5 @@
+-- 截断自动续写：非流式响应因输出 token 上限截断时自动发起续写请求并拼接输出。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS auto_continuation JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.auto_continuation IS '截断自动续写配置：enabled 开启后对 stop_reason=max_tokens 的非流式 Messages 响应以 assistant 预填充续写，最多 max_continuations 次，为空表示不启用。';
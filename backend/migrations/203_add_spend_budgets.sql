@@ -0,0 +1,21 @@
+-- 消费预算：按用户 / 分组 / API Key 设置日、周、月消费上限（USD）。
+--   - 已消费金额实时由 usage_logs.actual_cost 在当前自然周期内汇总得出，不单独维护累计值
+--   - 同一对象同一周期只允许一条预算；超出后该对象的请求被拒绝，直到周期重置
+
+CREATE TABLE IF NOT EXISTS spend_budgets (
+    id          BIGSERIAL PRIMARY KEY,
+    scope_type  VARCHAR(20) NOT NULL,
+    scope_id    BIGINT NOT NULL,
+    period      VARCHAR(20) NOT NULL,
+    limit_usd   DECIMAL(20,8) NOT NULL,
+    enabled     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS spendbudget_scope_period
+    ON spend_budgets (scope_type, scope_id, period);
+
+COMMENT ON COLUMN spend_budgets.scope_type IS '预算对象类型：user / group / api_key。';
+COMMENT ON COLUMN spend_budgets.period IS '预算周期：daily / weekly / monthly（按系统时区自然日、周一起始的自然周、自然月）。';
+COMMENT ON COLUMN spend_budgets.limit_usd IS '周期内允许的最大实际消费（USD，对应 usage_logs.actual_cost）。';
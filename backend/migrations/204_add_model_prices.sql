@@ -0,0 +1,26 @@
+-- 模型价格表：管理员维护的按生效时间区分的模型 token 单价（USD / token）。
+--   - 同一模型可有多条记录，请求时间落在 [effective_from, effective_to) 内的最新一条生效
+--   - 命中时优先于 LiteLLM 动态价格与内置回退价格，写入 usage_logs 时据此计算费用
+--   - 历史日志可通过回填任务按日志创建时间对应的价格重新计算费用
+
+CREATE TABLE IF NOT EXISTS model_prices (
+    id                  BIGSERIAL PRIMARY KEY,
+    model               VARCHAR(100) NOT NULL,
+    input_price         DECIMAL(20,12) NOT NULL DEFAULT 0,
+    output_price        DECIMAL(20,12) NOT NULL DEFAULT 0,
+    cache_write_price   DECIMAL(20,12) NOT NULL DEFAULT 0,
+    cache_read_price    DECIMAL(20,12) NOT NULL DEFAULT 0,
+    effective_from      TIMESTAMPTZ NOT NULL,
+    effective_to        TIMESTAMPTZ,
+    note                VARCHAR(255) NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS modelprice_model_effective_from
+    ON model_prices (model, effective_from);
+
+COMMENT ON COLUMN model_prices.model IS '模型名（小写，与 usage_logs.model 匹配）。';
+COMMENT ON COLUMN model_prices.cache_write_price IS '缓存创建每 token 价格（USD），不区分 5m / 1h。';
+COMMENT ON COLUMN model_prices.effective_from IS '生效起始时间（含）。';
+COMMENT ON COLUMN model_prices.effective_to IS '生效截止时间（不含），为空表示长期有效。';
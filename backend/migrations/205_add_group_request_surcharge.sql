@@ -0,0 +1,6 @@
+-- 分组按次附加费：在倍率计算后的实际费用（actual_cost）上叠加固定金额，原始成本（total_cost）不变，
+-- 供转售场景同时统计上游原始成本与加价后的计费金额。
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS request_surcharge DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN groups.request_surcharge IS '按次固定附加费（USD），仅对产生费用的请求收取，0 表示不收取。';
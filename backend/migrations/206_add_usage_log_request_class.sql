@@ -0,0 +1,5 @@
+-- 请求分类（interactive / batch），用于按分类统计延迟 SLO；历史数据为空。
+ALTER TABLE usage_logs
+    ADD COLUMN IF NOT EXISTS request_class VARCHAR(16);
+
+COMMENT ON COLUMN usage_logs.request_class IS '请求分类：interactive（交互）/ batch（批量），为空表示未分类的历史数据。';
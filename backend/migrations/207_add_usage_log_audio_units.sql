@@ -0,0 +1,7 @@
+-- 音频接口计费单位：转写按输入音频秒数、语音合成按输入字符数；非音频请求为空。
+ALTER TABLE usage_logs
+    ADD COLUMN IF NOT EXISTS audio_duration_seconds NUMERIC(12, 3),
+    ADD COLUMN IF NOT EXISTS audio_characters INTEGER;
+
+COMMENT ON COLUMN usage_logs.audio_duration_seconds IS '音频转写的输入音频时长（秒），按秒计费的乘数；非转写请求为空。';
+COMMENT ON COLUMN usage_logs.audio_characters IS '语音合成的输入文本字符数，按字符计费的乘数；非语音合成请求为空。';
@@ -0,0 +1,28 @@
+-- 管理端服务令牌：供看板、脚本等非交互场景调用管理 API，避免复用管理员的登录 JWT。
+--   - 明文令牌只在创建时返回一次，库中仅保存 SHA-256 摘要与展示用前缀
+--   - scopes 为 "<资源>:<read|write>" 列表（如 accounts:read、usage:read），write 包含 read
+--   - 以创建者身份鉴权，创建者被删除时令牌随之删除；撤销后立即失效
+
+CREATE TABLE IF NOT EXISTS admin_service_tokens (
+    id            BIGSERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL,
+    token_hash    VARCHAR(64) NOT NULL,
+    token_prefix  VARCHAR(16) NOT NULL,
+    scopes        JSONB NOT NULL DEFAULT '[]'::jsonb,
+    expires_at    TIMESTAMPTZ,
+    last_used_at  TIMESTAMPTZ,
+    last_used_ip  VARCHAR(64) NOT NULL DEFAULT '',
+    created_by    BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revoked_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS adminservicetoken_token_hash
+    ON admin_service_tokens (token_hash);
+
+COMMENT ON COLUMN admin_service_tokens.token_hash IS '令牌明文的 SHA-256 十六进制摘要。';
+COMMENT ON COLUMN admin_service_tokens.token_prefix IS '令牌明文前缀，仅用于列表中辨认令牌。';
+COMMENT ON COLUMN admin_service_tokens.scopes IS '授权范围列表，形如 accounts:read、usage:read、*:read。';
+COMMENT ON COLUMN admin_service_tokens.expires_at IS '过期时间，为空表示不过期。';
+COMMENT ON COLUMN admin_service_tokens.created_by IS '创建令牌的管理员，请求以该用户身份执行。';
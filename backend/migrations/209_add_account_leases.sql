@@ -0,0 +1,23 @@
+-- 账号租约：外部调度系统独占借出账号一段时间，租约期间内部调度器不再选中该账号。
+--   - 每个账号同时最多一个未过期租约；借出时账号 schedulable 置为 FALSE，
+--     归还或过期后恢复为借出前的值（prev_schedulable）
+--   - 过期租约由后台任务定期释放，外部系统也可随时续期或提前归还
+
+CREATE TABLE IF NOT EXISTS account_leases (
+    account_id        BIGINT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    lease_id          VARCHAR(64) NOT NULL,
+    holder            VARCHAR(100) NOT NULL,
+    note              VARCHAR(255) NOT NULL DEFAULT '',
+    prev_schedulable  BOOLEAN NOT NULL DEFAULT TRUE,
+    expires_at        TIMESTAMPTZ NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    renewed_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS accountlease_lease_id ON account_leases (lease_id);
+CREATE INDEX IF NOT EXISTS accountlease_expires_at ON account_leases (expires_at);
+
+COMMENT ON COLUMN account_leases.lease_id IS '租约 ID，续期与归还时使用。';
+COMMENT ON COLUMN account_leases.holder IS '借出方标识（外部系统名称）。';
+COMMENT ON COLUMN account_leases.prev_schedulable IS '借出前账号的调度开关，释放租约时恢复。';
+COMMENT ON COLUMN account_leases.expires_at IS '租约到期时间，到期后自动释放。';
@@ -0,0 +1,28 @@
+-- OpenAI Batch API 透传：记录上游文件/批次与创建它们的账号、API Key 的绑定。
+--   - 上游文件和批次只存在于创建它们的账号下，后续轮询、取消、下载结果都路由回该账号
+--   - 只有创建者 API Key 可以访问对应对象（共享账号下不同用户互相不可见）
+--   - 批次进入终态后按上游回传的 usage 结算一次，settled_at 非空表示已结算
+
+CREATE TABLE IF NOT EXISTS openai_batch_objects (
+    id           BIGSERIAL PRIMARY KEY,
+    object_type  VARCHAR(16) NOT NULL,
+    object_id    VARCHAR(128) NOT NULL,
+    user_id      BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id   BIGINT NOT NULL,
+    account_id   BIGINT NOT NULL,
+    group_id     BIGINT,
+    model        VARCHAR(128) NOT NULL DEFAULT '',
+    status       VARCHAR(32) NOT NULL DEFAULT '',
+    settled_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS openaibatchobject_type_object_id ON openai_batch_objects (object_type, object_id);
+CREATE INDEX IF NOT EXISTS openaibatchobject_api_key_id ON openai_batch_objects (api_key_id);
+
+COMMENT ON COLUMN openai_batch_objects.object_type IS '对象类型：file / batch。';
+COMMENT ON COLUMN openai_batch_objects.object_id IS '上游对象 ID（file-xxx / batch_xxx）。';
+COMMENT ON COLUMN openai_batch_objects.account_id IS '创建该对象的上游账号，后续请求固定路由到该账号。';
+COMMENT ON COLUMN openai_batch_objects.model IS '批次输入文件中的模型，用于结算计费。';
+COMMENT ON COLUMN openai_batch_objects.settled_at IS '批次用量结算时间，为空表示尚未结算。';
@@ -0,0 +1,18 @@
+-- OpenAI Files API 透传：对客户端隐藏上游文件 ID。
+--   - 客户端只看到网关生成的 public_id（file-<hex>），请求时再解析回 account_id + 上游 object_id
+--   - 上传时记录 purpose / filename / bytes，GET /v1/files 直接从本表列出当前 API Key 的文件，
+--     不去上游列举（共享账号下上游列表会包含其他用户的文件）
+--   - 已有文件行沿用上游 ID 作为 public_id，保证迁移前发出的 ID 继续可用
+
+ALTER TABLE openai_batch_objects ADD COLUMN IF NOT EXISTS public_id VARCHAR(128);
+ALTER TABLE openai_batch_objects ADD COLUMN IF NOT EXISTS purpose VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE openai_batch_objects ADD COLUMN IF NOT EXISTS filename VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE openai_batch_objects ADD COLUMN IF NOT EXISTS bytes BIGINT NOT NULL DEFAULT 0;
+
+UPDATE openai_batch_objects SET public_id = object_id WHERE object_type = 'file' AND public_id IS NULL;
+UPDATE openai_batch_objects SET purpose = 'batch' WHERE object_type = 'file' AND purpose = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS openaibatchobject_type_public_id ON openai_batch_objects (object_type, public_id) WHERE public_id IS NOT NULL;
+
+COMMENT ON COLUMN openai_batch_objects.public_id IS '返回给客户端的文件 ID，批次对象为空。';
+COMMENT ON COLUMN openai_batch_objects.purpose IS '文件用途（batch / fine-tune / batch_output 等）。';
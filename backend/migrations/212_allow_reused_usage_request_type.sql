@@ -0,0 +1,8 @@
+-- Requests served from a coalesced or cached response are recorded as
+-- request_type=5 (zero cost) so every served request stays visible in usage audits.
+ALTER TABLE usage_logs
+    DROP CONSTRAINT IF EXISTS usage_logs_request_type_check;
+
+ALTER TABLE usage_logs
+    ADD CONSTRAINT usage_logs_request_type_check
+    CHECK (request_type IN (0, 1, 2, 3, 4, 5)) NOT VALID;
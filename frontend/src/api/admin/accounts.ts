@@ -254,6 +254,147 @@ export async function clearError(id: number): Promise<Account> {
   return data
 }
 
+export interface StaleAccount {
+  id: number
+  name: string
+  platform: string
+  type: string
+  status: string
+  concurrency: number
+  last_used_at?: string
+  created_at: string
+  last_active_at: string
+  flagged_at?: string
+  idle_days: number
+}
+
+export interface StaleAccountCapacity {
+  accounts: number
+  concurrency: number
+}
+
+export interface StaleAccountReport {
+  generated_at: string
+  stale_days: number
+  accounts: StaleAccount[]
+  reclaimable: StaleAccountCapacity
+  reclaimable_by_platform: Array<StaleAccountCapacity & { platform: string }>
+  archived: StaleAccountCapacity
+  auto_archive: boolean
+  archive_after_days?: number
+}
+
+/**
+ * List accounts unused for N days and the capacity archiving them would reclaim
+ * @param days - Idle threshold in days (defaults to account_stale.stale_days)
+ * @returns Stale account report
+ */
+export async function getStaleAccounts(days?: number): Promise<StaleAccountReport> {
+  const { data } = await apiClient.get<StaleAccountReport>('/admin/accounts/stale', {
+    params: days ? { days } : undefined
+  })
+  return data
+}
+
+/**
+ * Archive an account: excluded from routing, credentials kept encrypted
+ * @param id - Account ID
+ * @returns Updated account
+ */
+export async function archiveAccount(id: number): Promise<Account> {
+  const { data } = await apiClient.post<Account>(`/admin/accounts/${id}/archive`)
+  return data
+}
+
+/**
+ * Restore an archived account's credentials and previous status
+ * @param id - Account ID
+ * @returns Updated account
+ */
+export async function unarchiveAccount(id: number): Promise<Account> {
+  const { data } = await apiClient.post<Account>(`/admin/accounts/${id}/unarchive`)
+  return data
+}
+
+/**
+ * Archive several accounts, continuing past individual failures
+ * @param accountIds - Account IDs to archive
+ * @returns Archived IDs and per-account failure reasons
+ */
+export async function archiveStaleAccounts(
+  accountIds: number[]
+): Promise<{ archived: number[]; failed: Record<string, string> }> {
+  const { data } = await apiClient.post<{ archived: number[]; failed: Record<string, string> }>(
+    '/admin/accounts/stale/archive',
+    { account_ids: accountIds }
+  )
+  return data
+}
+
+export interface AccountLease {
+  account_id: number
+  lease_id: string
+  holder: string
+  note: string
+  prev_schedulable: boolean
+  expires_at: string
+  created_at: string
+  renewed_at: string
+}
+
+/**
+ * List current account leases held by external schedulers
+ * @returns Leases ordered by expiry
+ */
+export async function listLeases(): Promise<AccountLease[]> {
+  const { data } = await apiClient.get<AccountLease[]>('/admin/accounts/leases')
+  return data
+}
+
+/**
+ * Lease an account exclusively; the internal scheduler skips it until checkin or expiry
+ * @param id - Account ID
+ * @param holder - Name of the external system holding the lease
+ * @param ttlSeconds - Lease duration (defaults to 15 minutes)
+ * @returns Created lease
+ */
+export async function checkoutAccount(
+  id: number,
+  holder: string,
+  ttlSeconds?: number,
+  note?: string
+): Promise<AccountLease> {
+  const { data } = await apiClient.post<AccountLease>(`/admin/accounts/${id}/lease`, {
+    holder,
+    ttl_seconds: ttlSeconds,
+    note
+  })
+  return data
+}
+
+/**
+ * Extend an active lease, counting the new duration from now
+ * @param leaseId - Lease ID returned by checkout
+ * @param ttlSeconds - New lease duration (defaults to 15 minutes)
+ * @returns Renewed lease
+ */
+export async function renewLease(leaseId: string, ttlSeconds?: number): Promise<AccountLease> {
+  const { data } = await apiClient.post<AccountLease>(`/admin/accounts/leases/${leaseId}/renew`, {
+    ttl_seconds: ttlSeconds
+  })
+  return data
+}
+
+/**
+ * Return a leased account and restore its previous schedulable flag
+ * @param leaseId - Lease ID returned by checkout
+ * @returns Released lease
+ */
+export async function checkinAccount(leaseId: string): Promise<AccountLease> {
+  const { data } = await apiClient.delete<AccountLease>(`/admin/accounts/leases/${leaseId}`)
+  return data
+}
+
 /**
  * Get account usage information (5h/7d window)
  * @param id - Account ID
@@ -476,6 +617,118 @@ export async function getAvailableModels(id: number): Promise<ClaudeModel[]> {
   return data
 }
 
+export interface ModelMappingRule {
+  pattern: string
+  target: string
+}
+
+export interface ModelMappingTestRequest {
+  model: string
+  model_mapping?: Record<string, string>
+  model_mapping_rules?: ModelMappingRule[]
+}
+
+export interface ModelMappingTestResult {
+  requested_model: string
+  lookup_model: string
+  mapped_model: string
+  matched: boolean
+  supported: boolean
+  source?: 'rules' | 'mapping'
+  rule_index?: number
+  pattern?: string
+  kind?: 'exact' | 'wildcard' | 'regex'
+}
+
+/**
+ * Show which mapping rule a model hits on an account
+ * @param id - Account ID
+ * @param request - Model to test, optionally with draft mapping/rules replacing the saved ones
+ * @returns Matched rule and mapped model
+ */
+export async function testModelMapping(
+  id: number,
+  request: ModelMappingTestRequest
+): Promise<ModelMappingTestResult> {
+  const { data } = await apiClient.post<ModelMappingTestResult>(
+    `/admin/accounts/${id}/model-mapping/test`,
+    request
+  )
+  return data
+}
+
+export interface MappingSimulationRequest {
+  days?: number
+  accounts?: Array<{
+    account_id: number
+    model_mapping?: Record<string, string>
+    model_mapping_rules?: ModelMappingRule[]
+  }>
+  groups?: Array<{
+    group_id: number
+    model_routing?: Record<string, number[]>
+    model_routing_enabled?: boolean
+  }>
+}
+
+export interface MappingSimulationOutcome {
+  mapped_model?: string
+  supported?: boolean
+  source?: 'rules' | 'mapping'
+  pattern?: string
+  rule_index?: number
+  routing_account_ids?: number[]
+  account_eligible?: boolean
+}
+
+export interface MappingSimulationChange {
+  kind: 'account_mapping' | 'group_routing'
+  account_id: number
+  group_id?: number
+  requested_model: string
+  observed_upstream_model: string
+  requests: number
+  last_seen_at: string
+  before: MappingSimulationOutcome
+  after: MappingSimulationOutcome
+}
+
+export interface MappingConflict {
+  code: 'shadowed_rule' | 'ambiguous_mapping' | 'ambiguous_routing'
+  account_id?: number
+  group_id?: number
+  patterns: string[]
+  rule_index?: number
+  models?: string[]
+  observed: boolean
+  message: string
+}
+
+export interface MappingSimulationReport {
+  generated_at: string
+  days: number
+  sampled_requests: number
+  affected_requests: number
+  truncated: boolean
+  changes: MappingSimulationChange[]
+  conflicts: MappingConflict[]
+}
+
+/**
+ * Replay recent usage against candidate mapping/routing changes before applying them
+ * @param request - Draft account mappings and group routings; omitted fields keep the saved values
+ * @returns Requests that would route differently and conflicts found in the drafts
+ */
+export async function simulateModelMapping(
+  request: MappingSimulationRequest
+): Promise<MappingSimulationReport> {
+  const { data } = await apiClient.post<MappingSimulationReport>(
+    '/admin/accounts/model-mapping/simulate',
+    request
+  )
+  return data
+}
+
 export interface SyncUpstreamModelsResult {
   models: string[]
 }
@@ -490,6 +743,32 @@ export async function syncUpstreamModels(id: number): Promise<SyncUpstreamModels
   return data
 }
 
+export type AccountCredentialCheckStatus = 'valid' | 'invalid' | 'expired' | 'unverified'
+
+export interface AccountCredentialCheck {
+  checked_at: string
+  status: AccountCredentialCheckStatus
+  message?: string
+  upstream_status?: number
+  token_expires_at?: string
+  refreshable: boolean
+  tier?: string
+  tier_source?: 'id_token' | 'credentials'
+  scopes?: string[]
+  models?: string[]
+  models_error?: string
+}
+
+/**
+ * Verify account credentials (token validity, tier, scopes, accessible models).
+ * The result is also stored in account.extra.credential_check.
+ * @param id - Account ID
+ */
+export async function verifyAccount(id: number): Promise<AccountCredentialCheck> {
+  const { data } = await apiClient.post<AccountCredentialCheck>(`/admin/accounts/${id}/verify`)
+  return data
+}
+
 export interface SyncUpstreamPreviewParams {
   platform: string
   type: string
@@ -818,6 +1097,14 @@ export const accountsAPI = {
   applyOAuthCredentials,
   getStats,
   clearError,
+  getStaleAccounts,
+  archiveAccount,
+  unarchiveAccount,
+  archiveStaleAccounts,
+  listLeases,
+  checkoutAccount,
+  renewLease,
+  checkinAccount,
   getUsage,
   getTodayStats,
   getBatchTodayStats,
@@ -828,8 +1115,11 @@ export const accountsAPI = {
   resetTempUnschedulable,
   setSchedulable,
   getAvailableModels,
+  testModelMapping,
+  simulateModelMapping,
   syncUpstreamModels,
   syncUpstreamModelsPreview,
+  verifyAccount,
   generateAuthUrl,
   exchangeCode,
   refreshOpenAIToken,
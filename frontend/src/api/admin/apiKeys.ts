@@ -26,8 +26,130 @@ export async function updateApiKeyGroup(id: number, groupId: number | null): Pro
   return data
 }
 
+export interface ApiKeyTraceSession {
+  api_key_id: number
+  reason?: string
+  started_by?: number
+  started_at: string
+  expires_at: string
+}
+
+export interface ApiKeyTraceRecord {
+  request_id?: string
+  client_request_id?: string
+  api_key_id: number
+  account_id?: number
+  model?: string
+  method: string
+  path: string
+  stream: boolean
+  status_code: number
+  duration_ms: number
+  request_headers?: Record<string, string>
+  request_body?: string
+  request_body_truncated?: boolean
+  response_headers?: Record<string, string>
+  response_body?: string
+  response_body_truncated?: boolean
+  upstream_responses?: ApiKeyTraceUpstreamResponse[]
+  upstream_responses_dropped?: number
+  created_at: string
+}
+
+export interface ApiKeyTraceUpstreamResponse {
+  account_id?: number
+  method: string
+  url: string
+  status_code: number
+  headers?: Record<string, string>
+  body?: string
+  body_truncated?: boolean
+}
+
+export interface ApiKeyTraceResult {
+  session: ApiKeyTraceSession | null
+  records: ApiKeyTraceRecord[]
+}
+
+/**
+ * Start capturing full request/response dumps for a single API key
+ * @param id - API Key ID
+ * @param durationMinutes - Trace duration (0 uses the server default)
+ * @param reason - Optional note shown in the audit log
+ */
+export async function startApiKeyTrace(id: number, durationMinutes = 0, reason = ''): Promise<ApiKeyTraceSession> {
+  const { data } = await apiClient.post<ApiKeyTraceSession>(`/admin/api-keys/${id}/trace`, {
+    duration_minutes: durationMinutes,
+    reason
+  })
+  return data
+}
+
+/**
+ * Stop an active API key trace; captured records are kept until retention expiry
+ */
+export async function stopApiKeyTrace(id: number): Promise<void> {
+  await apiClient.delete(`/admin/api-keys/${id}/trace`)
+}
+
+/**
+ * Get trace status and recent captured records for an API key
+ */
+export async function getApiKeyTrace(id: number, limit = 50): Promise<ApiKeyTraceResult> {
+  const { data } = await apiClient.get<ApiKeyTraceResult>(`/admin/api-keys/${id}/trace`, {
+    params: { limit }
+  })
+  return data
+}
+
+/**
+ * List all active API key traces
+ */
+export async function listActiveApiKeyTraces(): Promise<ApiKeyTraceSession[]> {
+  const { data } = await apiClient.get<ApiKeyTraceSession[]>('/admin/api-keys/traces')
+  return data
+}
+
+export interface ApiKeyTraceRedactionRule {
+  name: string
+  pattern: string
+  replacement?: string
+  enabled: boolean
+}
+
+export interface ApiKeyTraceRedactionSettings {
+  builtin_rules: string[]
+  rules: ApiKeyTraceRedactionRule[]
+}
+
+/**
+ * Get redaction rules applied to trace records before they are stored
+ */
+export async function getApiKeyTraceRedactionRules(): Promise<ApiKeyTraceRedactionSettings> {
+  const { data } = await apiClient.get<ApiKeyTraceRedactionSettings>('/admin/api-keys/trace-redaction-rules')
+  return data
+}
+
+/**
+ * Replace the custom (regex) redaction rules; built-in rules always apply
+ */
+export async function updateApiKeyTraceRedactionRules(
+  rules: ApiKeyTraceRedactionRule[]
+): Promise<ApiKeyTraceRedactionSettings> {
+  const { data } = await apiClient.put<ApiKeyTraceRedactionSettings>('/admin/api-keys/trace-redaction-rules', {
+    rules
+  })
+  return data
+}
+
 export const apiKeysAPI = {
-  updateApiKeyGroup
+  updateApiKeyGroup,
+  startApiKeyTrace,
+  stopApiKeyTrace,
+  getApiKeyTrace,
+  listActiveApiKeyTraces,
+  getApiKeyTraceRedactionRules,
+  updateApiKeyTraceRedactionRules
 }
 
 export default apiKeysAPI
@@ -0,0 +1,76 @@
+/**
+ * Admin Billing Statements API endpoints
+ * Monthly statement generation and optional Stripe invoicing
+ */
+
+import { apiClient } from '../client'
+import type { PaginatedResponse } from '@/types'
+import type { BillingStatement, BillingStatementStatus } from '../billingStatements'
+
+export interface BillingStatementListFilters {
+  user_id?: number
+  /** YYYY-MM */
+  period?: string
+  status?: BillingStatementStatus
+}
+
+/**
+ * List statements across all users
+ */
+export async function list(
+  page = 1,
+  pageSize = 20,
+  filters?: BillingStatementListFilters
+): Promise<PaginatedResponse<BillingStatement>> {
+  const { data } = await apiClient.get<PaginatedResponse<BillingStatement>>(
+    '/admin/billing/statements',
+    { params: { page, page_size: pageSize, ...filters } }
+  )
+  return data
+}
+
+/**
+ * Get a single statement
+ */
+export async function getById(id: number): Promise<BillingStatement> {
+  const { data } = await apiClient.get<BillingStatement>(`/admin/billing/statements/${id}`)
+  return data
+}
+
+/**
+ * Generate statements for a closed month (idempotent)
+ * @param period - Month in YYYY-MM format
+ */
+export async function generate(period: string): Promise<{ created: number }> {
+  const { data } = await apiClient.post<{ created: number }>(
+    '/admin/billing/statements/generate',
+    { period }
+  )
+  return data
+}
+
+/**
+ * Push (or retry) an issued statement to Stripe
+ */
+export async function push(id: number): Promise<BillingStatement> {
+  const { data } = await apiClient.post<BillingStatement>(`/admin/billing/statements/${id}/push`)
+  return data
+}
+
+/**
+ * Void an issued statement that has not been pushed to Stripe
+ */
+export async function voidStatement(id: number): Promise<BillingStatement> {
+  const { data } = await apiClient.post<BillingStatement>(`/admin/billing/statements/${id}/void`)
+  return data
+}
+
+export const billingStatementsAPI = {
+  list,
+  getById,
+  generate,
+  push,
+  void: voidStatement
+}
+
+export default billingStatementsAPI
@@ -178,5 +178,62 @@ export async function syncPricingModels(platform: string): Promise<SyncPricingMo
   return data
 }
 
-const channelsAPI = { list, getById, create, update, remove, getModelDefaultPricing, syncPricingModels }
+export interface PricingPendingUpdate {
+  hash: string
+  fetched_at: string
+  model_count: number
+  added: string[]
+  removed: string[]
+  changed: string[]
+}
+
+export interface PricingSyncStatus {
+  approval_mode: 'auto' | 'manual'
+  signature_required: boolean
+  model_count: number
+  last_updated: string
+  local_hash: string
+  rejected_hash?: string
+  pending?: PricingPendingUpdate
+}
+
+/**
+ * Get the remote pricing feed sync status, including any update awaiting approval
+ */
+export async function getPricingSyncStatus(): Promise<PricingSyncStatus> {
+  const { data } = await apiClient.get<PricingSyncStatus>('/admin/channels/pricing/sync-status')
+  return data
+}
+
+/**
+ * Fetch the remote pricing feed now (staged for approval in manual mode)
+ */
+export async function triggerPricingSync(): Promise<PricingSyncStatus> {
+  const { data } = await apiClient.post<PricingSyncStatus>('/admin/channels/pricing/sync')
+  return data
+}
+
+export async function approvePendingPricing(hash: string): Promise<PricingSyncStatus> {
+  const { data } = await apiClient.post<PricingSyncStatus>('/admin/channels/pricing/pending/approve', { hash })
+  return data
+}
+
+export async function rejectPendingPricing(hash: string): Promise<PricingSyncStatus> {
+  const { data } = await apiClient.post<PricingSyncStatus>('/admin/channels/pricing/pending/reject', { hash })
+  return data
+}
+
+const channelsAPI = {
+  list,
+  getById,
+  create,
+  update,
+  remove,
+  getModelDefaultPricing,
+  syncPricingModels,
+  getPricingSyncStatus,
+  triggerPricingSync,
+  approvePendingPricing,
+  rejectPendingPricing
+}
 export default channelsAPI
@@ -0,0 +1,109 @@
+/**
+ * Admin Config Versions API endpoints
+ * Change history, diffs and rollback for groups, accounts, channels and error passthrough rules
+ */
+
+import { apiClient } from '../client'
+import type { PaginatedResponse } from '@/types'
+
+export type ConfigEntityType = 'group' | 'account' | 'channel' | 'error_passthrough_rule'
+
+export type ConfigVersionAction = 'baseline' | 'create' | 'update' | 'delete' | 'rollback'
+
+export interface ConfigVersion {
+  id: number
+  entity_type: ConfigEntityType
+  entity_id: number
+  version: number
+  action: ConfigVersionAction
+  /** Rollback-able fields; account snapshots never contain credential secrets */
+  snapshot: Record<string, unknown>
+  snapshot_hash: string
+  operator_id?: number
+  restored_from?: number
+  created_at: string
+}
+
+export interface ConfigVersionDiffEntry {
+  path: string
+  op: 'added' | 'removed' | 'changed'
+  from?: unknown
+  to?: unknown
+}
+
+export interface ConfigVersionDiff {
+  entity_type: ConfigEntityType
+  entity_id: number
+  from_version: number
+  to_version: number
+  changes: ConfigVersionDiffEntry[]
+}
+
+/**
+ * List versions of an entity (newest first)
+ */
+export async function list(
+  entityType: ConfigEntityType,
+  entityId: number,
+  page = 1,
+  pageSize = 20
+): Promise<PaginatedResponse<ConfigVersion>> {
+  const { data } = await apiClient.get<PaginatedResponse<ConfigVersion>>(
+    `/admin/config-versions/${entityType}/${entityId}`,
+    { params: { page, page_size: pageSize } }
+  )
+  return data
+}
+
+/**
+ * Get a single version snapshot
+ */
+export async function getVersion(
+  entityType: ConfigEntityType,
+  entityId: number,
+  version: number
+): Promise<ConfigVersion> {
+  const { data } = await apiClient.get<ConfigVersion>(
+    `/admin/config-versions/${entityType}/${entityId}/versions/${version}`
+  )
+  return data
+}
+
+/**
+ * Diff two versions; omit `to` to compare against the latest version
+ */
+export async function diff(
+  entityType: ConfigEntityType,
+  entityId: number,
+  from: number,
+  to?: number
+): Promise<ConfigVersionDiff> {
+  const { data } = await apiClient.get<ConfigVersionDiff>(
+    `/admin/config-versions/${entityType}/${entityId}/diff`,
+    { params: { from, to } }
+  )
+  return data
+}
+
+/**
+ * Restore an entity to a prior version (recorded as a new rollback version)
+ */
+export async function rollback(
+  entityType: ConfigEntityType,
+  entityId: number,
+  version: number
+): Promise<ConfigVersion> {
+  const { data } = await apiClient.post<ConfigVersion>(
+    `/admin/config-versions/${entityType}/${entityId}/versions/${version}/rollback`
+  )
+  return data
+}
+
+export const configVersionsAPI = {
+  list,
+  getVersion,
+  diff,
+  rollback
+}
+
+export default configVersionsAPI
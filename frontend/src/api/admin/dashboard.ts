@@ -324,6 +324,238 @@ export async function getBatchApiKeysUsage(
   return data
 }
 
+export interface CapacityForecastDay {
+  date: string
+  tokens: number
+  account_cost: number
+}
+
+export interface CapacityPlatformCaps {
+  platform: string
+  accounts: number
+  capped_accounts: number
+  daily_cost_cap: number
+  remaining_quota: number
+  unlimited_accounts: number
+}
+
+export interface CapacityForecastResponse {
+  generated_at: string
+  lookback_days: number
+  horizon_days: number
+  history: CapacityForecastDay[]
+  projection: CapacityForecastDay[]
+  daily_token_growth: number
+  daily_cost_growth: number
+  active_accounts: number
+  /** Active accounts without any quota limit, not counted in capacity */
+  uncapped_accounts: number
+  daily_cost_cap: number
+  daily_token_cap: number
+  remaining_quota: number
+  platforms: CapacityPlatformCaps[]
+  daily_cap_exceeded_on?: string
+  quota_exhausted_on?: string
+  days_until_exhausted?: number
+}
+
+export interface CapacityForecastParams {
+  lookback_days?: number
+  horizon_days?: number
+}
+
+/**
+ * Get account capacity forecast (daily usage trajectory vs. configured account quotas)
+ * @param params - Lookback window and forecast horizon in days
+ * @returns Forecast with projected exhaustion dates
+ */
+export async function getCapacityForecast(
+  params?: CapacityForecastParams
+): Promise<CapacityForecastResponse> {
+  const { data } = await apiClient.get<CapacityForecastResponse>(
+    '/admin/dashboard/capacity-forecast',
+    { params }
+  )
+  return data
+}
+
+export type CacheEfficiencyDimension = 'model' | 'api_key' | 'account'
+
+export interface CacheEfficiencyRow {
+  /** API key / account ID; omitted for the model dimension */
+  id?: number
+  name: string
+  requests: number
+  input_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  prompt_tokens: number
+  hit_rate: number
+  cache_write_rate: number
+}
+
+export interface CacheSuggestion {
+  code: 'cache_writes_not_reused' | 'no_cache_usage' | 'below_average'
+  id?: number
+  name: string
+  message: string
+}
+
+export interface CacheEfficiencyReport {
+  generated_at: string
+  dimension: CacheEfficiencyDimension
+  days: number
+  requests: number
+  prompt_tokens: number
+  cache_read_tokens: number
+  hit_rate: number
+  rows: CacheEfficiencyRow[]
+  suggestions: CacheSuggestion[]
+}
+
+export interface CacheEfficiencyParams {
+  dimension?: CacheEfficiencyDimension
+  days?: number
+  limit?: number
+}
+
+/**
+ * Get prompt cache hit-rate report with routing suggestions for poor hit rates
+ * @param params - Aggregation dimension, window in days and row limit
+ * @returns Cache efficiency rows ordered by prompt tokens
+ */
+export async function getCacheEfficiency(
+  params?: CacheEfficiencyParams
+): Promise<CacheEfficiencyReport> {
+  const { data } = await apiClient.get<CacheEfficiencyReport>('/admin/dashboard/cache-efficiency', {
+    params
+  })
+  return data
+}
+
+export type ClientAnalyticsDimension = 'client' | 'client_version' | 'origin'
+
+export interface ClientAnalyticsKeyShare {
+  api_key_id: number
+  name: string
+  requests: number
+}
+
+export interface ClientAnalyticsRow {
+  key: string
+  client?: string
+  version?: string
+  /** Origin network (IPv4 /24 or IPv6 /48) */
+  origin?: string
+  requests: number
+  share: number
+  total_tokens: number
+  actual_cost: number
+  api_keys: number
+  origins: number
+  top_api_keys: ClientAnalyticsKeyShare[]
+  sample_user_agent?: string
+  last_used_at: string
+}
+
+export interface ClientAnalyticsReport {
+  generated_at: string
+  dimension: ClientAnalyticsDimension
+  days: number
+  api_key_id?: number
+  requests: number
+  truncated: boolean
+  rows: ClientAnalyticsRow[]
+}
+
+export interface ClientAnalyticsParams {
+  dimension?: ClientAnalyticsDimension
+  api_key_id?: number
+  days?: number
+  limit?: number
+}
+
+/**
+ * Get traffic breakdown by detected client profile, client version or origin network
+ * @param params - Aggregation dimension, optional API key filter, window in days and row limit
+ * @returns Rows ordered by request count with the top API keys per row
+ */
+export async function getClientAnalytics(
+  params?: ClientAnalyticsParams
+): Promise<ClientAnalyticsReport> {
+  const { data } = await apiClient.get<ClientAnalyticsReport>('/admin/dashboard/client-analytics', {
+    params
+  })
+  return data
+}
+
+export type UsageReportDimension = 'user' | 'api_key' | 'group' | 'account' | 'model'
+export type UsageReportBucket = 'hour' | 'day' | 'month'
+
+export interface UsageReportMetrics {
+  /** Successfully billed requests */
+  requests: number
+  /** SLA-counted error requests (business-limited rejections excluded) */
+  errors: number
+  error_rate: number
+  input_tokens: number
+  output_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  total_tokens: number
+  total_cost: number
+  actual_cost: number
+}
+
+export interface UsageReportRow extends UsageReportMetrics {
+  bucket?: string
+  user_id?: number
+  user_email?: string
+  api_key_id?: number
+  api_key_name?: string
+  group_id?: number
+  group_name?: string
+  account_id?: number
+  account_name?: string
+  model?: string
+}
+
+export interface UsageReport {
+  generated_at: string
+  start_time: string
+  end_time: string
+  group_by: UsageReportDimension[]
+  bucket?: UsageReportBucket
+  totals: UsageReportMetrics
+  truncated: boolean
+  rows: UsageReportRow[]
+}
+
+export interface UsageReportParams {
+  /** Comma-separated dimensions, e.g. "user,model" */
+  group_by?: string
+  bucket?: UsageReportBucket
+  start_date?: string
+  end_date?: string
+  timezone?: string
+  user_id?: number
+  api_key_id?: number
+  group_id?: number
+  account_id?: number
+  model?: string
+  limit?: number
+}
+
+/**
+ * Get usage, error rate and cost aggregated by dimensions and time bucket
+ * @param params - Dimensions, bucket, date range, filters and row limit
+ * @returns Rows ordered by bucket then actual cost, with totals over all rows
+ */
+export async function getUsageReport(params?: UsageReportParams): Promise<UsageReport> {
+  const { data } = await apiClient.get<UsageReport>('/admin/dashboard/usage-report', { params })
+  return data
+}
+
 export const dashboardAPI = {
   getStats,
   getRealtimeMetrics,
@@ -335,7 +567,11 @@ export const dashboardAPI = {
   getUserUsageTrend,
   getUserSpendingRanking,
   getBatchUsersUsage,
-  getBatchApiKeysUsage
+  getBatchApiKeysUsage,
+  getCapacityForecast,
+  getCacheEfficiency,
+  getClientAnalytics,
+  getUsageReport
 }
 
 export default dashboardAPI
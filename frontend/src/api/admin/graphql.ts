@@ -0,0 +1,51 @@
+/**
+ * Admin GraphQL reporting API
+ * Read-only queries over usage, accounts, ops errors and backup metadata.
+ * Responses use the standard GraphQL shape ({ data, errors }) rather than the { code, data } envelope.
+ */
+
+import { apiClient } from '../client'
+
+export interface GraphQLError {
+  message: string
+  locations?: Array<{ line: number; column: number }>
+  path?: Array<string | number>
+  extensions?: { code?: string; status?: number } & Record<string, unknown>
+}
+
+export interface GraphQLResponse<T = Record<string, unknown>> {
+  data?: T
+  errors?: GraphQLError[]
+}
+
+/**
+ * Execute a read-only GraphQL query. Field-level failures are returned in `errors`
+ * alongside partial `data`; invalid documents are rejected with HTTP 400.
+ */
+export async function query<T = Record<string, unknown>>(
+  document: string,
+  variables?: Record<string, unknown>,
+  operationName?: string
+): Promise<GraphQLResponse<T>> {
+  const { data } = await apiClient.post<GraphQLResponse<T>>('/admin/graphql', {
+    query: document,
+    variables,
+    operationName
+  })
+  return data
+}
+
+/**
+ * Fetch the schema in SDL form
+ */
+export async function getSchema(): Promise<string> {
+  const { data } = await apiClient.get<string>('/admin/graphql/schema', { responseType: 'text' })
+  return data
+}
+
+export const graphqlAPI = {
+  query,
+  getSchema
+}
+
+export default graphqlAPI
@@ -301,6 +301,69 @@ export async function clearGroupRPMOverrides(id: number): Promise<{ message: str
   return data
 }
 
+/**
+ * Failover tier of an account within a group: 0 = primary, 1 = secondary, 2 = emergency.
+ * Lower tiers are only scheduled when every higher-tier account is unavailable.
+ */
+export interface GroupAccountTierEntry {
+  account_id: number
+  tier: number
+}
+
+/**
+ * Get failover tiers of all accounts bound to a group
+ */
+export async function getGroupAccountTiers(id: number): Promise<GroupAccountTierEntry[]> {
+  const { data } = await apiClient.get<GroupAccountTierEntry[]>(`/admin/groups/${id}/account-tiers`)
+  return data
+}
+
+/**
+ * Batch set failover tiers for accounts already bound to a group
+ */
+export async function batchSetGroupAccountTiers(
+  id: number,
+  entries: GroupAccountTierEntry[]
+): Promise<{ message: string }> {
+  const { data } = await apiClient.put<{ message: string }>(
+    `/admin/groups/${id}/account-tiers`,
+    { entries }
+  )
+  return data
+}
+
+export interface GroupAccountConcurrencyShareEntry {
+  account_id: number
+  /** Percent (1-100) of the account's concurrency this group may use; 0 = unlimited */
+  concurrency_share: number
+}
+
+/**
+ * Get concurrency shares of all accounts bound to a group
+ */
+export async function getGroupAccountConcurrencyShares(
+  id: number
+): Promise<GroupAccountConcurrencyShareEntry[]> {
+  const { data } = await apiClient.get<GroupAccountConcurrencyShareEntry[]>(
+    `/admin/groups/${id}/account-shares`
+  )
+  return data
+}
+
+/**
+ * Batch set concurrency shares for accounts already bound to a group
+ */
+export async function batchSetGroupAccountConcurrencyShares(
+  id: number,
+  entries: GroupAccountConcurrencyShareEntry[]
+): Promise<{ message: string }> {
+  const { data } = await apiClient.put<{ message: string }>(
+    `/admin/groups/${id}/account-shares`,
+    { entries }
+  )
+  return data
+}
+
 /**
  * Get usage summary (today + cumulative cost) for all groups
  * @param timezone - IANA timezone string (e.g. "Asia/Shanghai")
@@ -348,6 +411,10 @@ export const groupsAPI = {
   getGroupRPMOverrides,
   clearGroupRPMOverrides,
   batchSetGroupRPMOverrides,
+  getGroupAccountTiers,
+  batchSetGroupAccountTiers,
+  getGroupAccountConcurrencyShares,
+  batchSetGroupAccountConcurrencyShares,
   updateSortOrder,
   getUsageSummary,
   getCapacitySummary
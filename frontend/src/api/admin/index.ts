@@ -33,6 +33,12 @@ import adminPaymentAPI from './payment'
 import affiliatesAPI from './affiliates'
 import riskControlAPI from './riskControl'
 import adminComplianceAPI from './compliance'
+import adminBillingStatementsAPI from './billingStatements'
+import spendBudgetsAPI from './spendBudgets'
+import modelPricesAPI from './modelPrices'
+import serviceTokensAPI from './serviceTokens'
+import configVersionsAPI from './configVersions'
+import graphqlAPI from './graphql'
 
 /**
  * Unified admin API object for convenient access
@@ -67,7 +73,13 @@ export const adminAPI = {
   payment: adminPaymentAPI,
   affiliates: affiliatesAPI,
   riskControl: riskControlAPI,
-  compliance: adminComplianceAPI
+  compliance: adminComplianceAPI,
+  billingStatements: adminBillingStatementsAPI,
+  spendBudgets: spendBudgetsAPI,
+  modelPrices: modelPricesAPI,
+  serviceTokens: serviceTokensAPI,
+  configVersions: configVersionsAPI,
+  graphql: graphqlAPI
 }
 
 export {
@@ -100,7 +112,13 @@ export {
   adminPaymentAPI,
   affiliatesAPI,
   riskControlAPI,
-  adminComplianceAPI
+  adminComplianceAPI,
+  adminBillingStatementsAPI,
+  spendBudgetsAPI,
+  modelPricesAPI,
+  serviceTokensAPI,
+  configVersionsAPI,
+  graphqlAPI
 }
 
 export default adminAPI
@@ -111,3 +129,5 @@ export type { ErrorPassthroughRule, CreateRuleRequest, UpdateRuleRequest } from
 export type { BackupAgentHealth, DataManagementConfig } from './dataManagement'
 export type { TLSFingerprintProfile, CreateProfileRequest, UpdateProfileRequest } from './tlsFingerprintProfile'
 export type { ContentModerationConfig, ContentModerationLog, ModerationMode } from './riskControl'
+export type { ConfigVersion, ConfigVersionDiff, ConfigEntityType } from './configVersions'
+export type { GraphQLResponse, GraphQLError } from './graphql'
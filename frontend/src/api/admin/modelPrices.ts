@@ -0,0 +1,88 @@
+/**
+ * Admin Model Prices API endpoints
+ * Effective-dated per-token model prices and historical usage cost backfill
+ */
+
+import { apiClient } from '../client'
+
+export interface ModelPrice {
+  id: number
+  model: string
+  input_price: number
+  output_price: number
+  cache_write_price: number
+  cache_read_price: number
+  effective_from: string
+  effective_to?: string
+  note: string
+  created_at: string
+  updated_at: string
+}
+
+export interface ModelPriceRequest {
+  model: string
+  input_price: number
+  output_price: number
+  cache_write_price: number
+  cache_read_price: number
+  effective_from?: string
+  effective_to?: string | null
+  note?: string
+}
+
+export interface ModelPriceBackfillRequest {
+  start_time: string
+  end_time: string
+  model?: string
+}
+
+export interface ModelPriceBackfillStatus {
+  running: boolean
+  request: ModelPriceBackfillRequest
+  scanned: number
+  updated: number
+  skipped: number
+  started_at: string
+  finished_at?: string
+  error?: string
+}
+
+export async function list(model?: string): Promise<ModelPrice[]> {
+  const { data } = await apiClient.get<ModelPrice[]>('/admin/model-prices', {
+    params: model ? { model } : undefined
+  })
+  return data
+}
+
+export async function create(request: ModelPriceRequest): Promise<ModelPrice> {
+  const { data } = await apiClient.post<ModelPrice>('/admin/model-prices', request)
+  return data
+}
+
+export async function update(id: number, request: ModelPriceRequest): Promise<ModelPrice> {
+  const { data } = await apiClient.put<ModelPrice>(`/admin/model-prices/${id}`, request)
+  return data
+}
+
+export async function remove(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(`/admin/model-prices/${id}`)
+  return data
+}
+
+/**
+ * Recompute usage log costs in a time range from the prices effective at each log's creation time.
+ * Only reporting fields are rewritten; balances are not adjusted.
+ */
+export async function startBackfill(request: ModelPriceBackfillRequest): Promise<ModelPriceBackfillStatus> {
+  const { data } = await apiClient.post<ModelPriceBackfillStatus>('/admin/model-prices/backfill', request)
+  return data
+}
+
+export async function getBackfillStatus(): Promise<ModelPriceBackfillStatus | null> {
+  const { data } = await apiClient.get<ModelPriceBackfillStatus | null>('/admin/model-prices/backfill')
+  return data
+}
+
+export const modelPricesAPI = { list, create, update, remove, startBackfill, getBackfillStatus }
+
+export default modelPricesAPI
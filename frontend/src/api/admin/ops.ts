@@ -198,9 +198,18 @@ export interface OpsErrorDistributionItem {
   business_limited: number
 }
 
+export interface OpsErrorCodeDistributionItem {
+  error_code: string
+  total: number
+  sla: number
+  business_limited: number
+}
+
 export interface OpsErrorDistributionResponse {
   total: number
   items: OpsErrorDistributionItem[]
+  // 按归一化上游错误代码聚合（仅上游错误）
+  error_codes?: OpsErrorCodeDistributionItem[]
 }
 
 export interface OpsDashboardSnapshotV2Response {
@@ -222,6 +231,110 @@ export interface OpsOpenAITokenStatsItem {
   requests_with_first_token: number
 }
 
+export type OpsOpenAISessionStrategy = 'prompt_cache_key' | 'api_key' | 'request'
+
+export interface OpsOpenAISessionStrategyStats {
+  strategy: OpsOpenAISessionStrategy
+  requests: number
+  cache_hit_requests: number
+  input_tokens: number
+  cache_read_tokens: number
+  request_hit_rate: number
+  token_hit_rate: number
+}
+
+export interface OpsOpenAISessionStrategyStatsResponse {
+  default_strategy: OpsOpenAISessionStrategy
+  since: string
+  strategies: OpsOpenAISessionStrategyStats[]
+}
+
+export interface OpsAutoContinuationStats {
+  since: string
+  truncated_responses: Record<string, number>
+  continued_responses: number
+  continuation_requests: number
+  completed: number
+  exhausted: number
+  failed: number
+  skipped: number
+}
+
+export interface OpsUpstreamConnectionStats {
+  since: string
+  warm_enabled: boolean
+  requests: number
+  reused_conns: number
+  new_conns: number
+  reuse_rate: number
+  avg_conn_setup_ms: number
+  warm_rounds: number
+  warm_requests: number
+  warm_failures: number
+  warm_conns_established: number
+  cached_clients: number
+}
+
+export interface OpsErrorFingerprint {
+  fingerprint: string
+  phase: string
+  type: string
+  error_code: string
+  status_code: number
+  platform: string
+  count: number
+  baseline_count: number
+  spike_ratio: number
+  spike: boolean
+  first_seen: string
+  last_seen: string
+  sample_message: string
+}
+
+export interface OpsIncidentAccountCooldown {
+  account_id: number
+  account_name: string
+  platform: string
+  kind: 'rate_limited' | 'overloaded' | 'temp_unschedulable'
+  started_at?: string
+  until?: string
+  reason?: string
+}
+
+export interface OpsIncidentProxyProbeFailure {
+  proxy_id: number
+  proxy_name: string
+  message: string
+  probed_at: string
+}
+
+export interface OpsIncidentConfigChange {
+  at: string
+  component: string
+  level: string
+  message: string
+  extra?: Record<string, any>
+}
+
+export interface OpsIncidentTimelineEvent {
+  at: string
+  kind: 'error_spike' | 'account_cooldown' | 'proxy_probe_failure' | 'config_change'
+  title: string
+  ref?: string
+}
+
+export interface OpsIncidentSnapshot {
+  start_time: string
+  end_time: string
+  baseline_start_time: string
+  error_fingerprints: OpsErrorFingerprint[]
+  account_cooldowns: OpsIncidentAccountCooldown[]
+  proxy_probe_failures: OpsIncidentProxyProbeFailure[]
+  config_changes: OpsIncidentConfigChange[]
+  timeline: OpsIncidentTimelineEvent[]
+  warnings?: string[]
+}
+
 export interface OpsOpenAITokenStatsResponse {
   time_range: OpsOpenAITokenStatsTimeRange
   start_time: string
@@ -372,6 +485,7 @@ export interface GroupAvailability {
   available_count: number
   rate_limit_count: number
   error_count: number
+  active_tier?: number
 }
 
 export interface AccountAvailability {
@@ -683,11 +797,14 @@ export type MetricType =
   | 'group_available_accounts'
   | 'group_available_ratio'
   | 'group_rate_limit_ratio'
+  | 'group_failover_tier'
   | 'account_rate_limited_count'
   | 'account_error_count'
   | 'account_error_ratio'
   | 'account_temp_unscheduled_count'
   | 'overload_account_count'
+  | 'upstream_429_count'
+  | 'stream_fault_rate'
 export type Operator = '>' | '>=' | '<' | '<=' | '==' | '!='
 
 export interface AlertRule {
@@ -725,6 +842,49 @@ export interface AlertEvent {
   created_at: string
 }
 
+export type AlertWebhookType = 'generic' | 'slack' | 'telegram' | 'lark'
+
+export interface AlertWebhook {
+  id: number
+  name: string
+  type: AlertWebhookType
+  url: string
+  secret_configured: boolean
+  chat_id?: string
+  enabled: boolean
+  rule_ids: number[]
+  min_severity: '' | AlertSeverity
+  notify_resolved: boolean
+  created_at: string
+  updated_at: string
+}
+
+export interface AlertWebhookInput {
+  name: string
+  type: AlertWebhookType
+  url?: string
+  // Omit on update to keep the stored secret.
+  secret?: string
+  chat_id?: string
+  enabled?: boolean
+  rule_ids?: number[]
+  min_severity?: '' | AlertSeverity
+  notify_resolved?: boolean
+}
+
+export interface AlertWebhookDelivery {
+  id: number
+  webhook_id: number
+  rule_id?: number
+  event_id?: number
+  event_status: 'firing' | 'resolved' | 'test' | string
+  success: boolean
+  status_code?: number
+  error?: string
+  duration_ms: number
+  created_at: string
+}
+
 export interface EmailNotificationConfig {
   alert: {
     enabled: boolean
@@ -747,9 +907,64 @@ export interface EmailNotificationConfig {
     account_health_enabled: boolean
     account_health_schedule: string
     account_health_error_rate_threshold: number
+    weekly_digest_enabled: boolean
+    weekly_digest_schedule: string
+    digest_quota_threshold_percent: number
+    digest_expiring_within_days: number
   }
 }
 
+export type OpsReportType = 'daily_summary' | 'weekly_summary' | 'error_digest' | 'account_health' | 'weekly_digest'
+
+export interface OpsReportSubscription {
+  user_id: number
+  report_types: OpsReportType[]
+  email: boolean
+  webhook_url: string
+  updated_at: string
+}
+
+export interface OpsReportSubscriptionInput {
+  report_types: OpsReportType[]
+  email: boolean
+  webhook_url: string
+}
+
+export interface OpsWeeklyDigest {
+  start_time: string
+  end_time: string
+  total_requests: number
+  total_tokens: number
+  actual_cost: number
+  prev_actual_cost: number
+  top_models: Array<{
+    model: string
+    requests: number
+    total_tokens: number
+    actual_cost: number
+    share_pct: number
+  }>
+  errors_total: number
+  prev_errors_total: number
+  error_trend: Array<{ date: string; errors: number }>
+  accounts_near_quota: Array<{
+    account_id: number
+    account_name: string
+    platform: string
+    dimension: 'total' | 'daily' | 'weekly'
+    used: number
+    limit: number
+    used_pct: number
+  }>
+  expiring_accounts: Array<{
+    account_id: number
+    account_name: string
+    platform: string
+    expires_at: string
+  }>
+  warnings?: string[]
+}
+
 export interface OpsMetricThresholds {
   sla_percent_min?: number | null                 // SLA低于此值变红
   ttft_p99_ms_max?: number | null                 // TTFT P99高于此值变红
@@ -895,6 +1110,8 @@ export interface OpsErrorLog {
   type: string
   error_owner: 'client' | 'provider' | 'platform' | string
   error_source: 'client_request' | 'upstream_http' | 'gateway' | string
+  // 归一化上游错误代码（rate_limited / quota_exhausted / html_error_page ...），非上游错误为空
+  error_code?: string
 
   severity: OpsSeverity
   status_code: number
@@ -905,6 +1122,12 @@ export interface OpsErrorLog {
   resolved_at?: string | null
   resolved_by_user_id?: number | null
 
+  // 处理流程：状态 / 负责人 / 评论数
+  status: OpsErrorTriageStatus
+  assignee_user_id?: number | null
+  assignee_email?: string
+  comment_count?: number
+
   client_request_id: string
   request_id: string
   message: string
@@ -938,6 +1161,24 @@ export interface OpsErrorLog {
   deleted_key_owner_email?: string | null
 }
 
+// resolved / ignored 视为已关闭（resolved=true）
+export type OpsErrorTriageStatus = 'open' | 'investigating' | 'resolved' | 'ignored'
+
+export interface OpsErrorTriageUpdate {
+  status?: OpsErrorTriageStatus
+  // 0 表示取消指派
+  assignee_user_id?: number
+}
+
+export interface OpsErrorComment {
+  id: number
+  error_id: number
+  user_id?: number | null
+  user_email: string
+  body: string
+  created_at: string
+}
+
 export interface OpsErrorDetail extends OpsErrorLog {
   error_body: string
 
@@ -1085,6 +1326,53 @@ export async function getOpenAITokenStats(
   return data
 }
 
+export async function getOpenAISessionStrategyStats(
+  options: OpsRequestOptions = {}
+): Promise<OpsOpenAISessionStrategyStatsResponse> {
+  const { data } = await apiClient.get<OpsOpenAISessionStrategyStatsResponse>(
+    '/admin/ops/dashboard/openai-session-strategy-stats',
+    { signal: options.signal }
+  )
+  return data
+}
+
+export async function getAutoContinuationStats(
+  options: OpsRequestOptions = {}
+): Promise<OpsAutoContinuationStats> {
+  const { data } = await apiClient.get<OpsAutoContinuationStats>(
+    '/admin/ops/dashboard/auto-continuation-stats',
+    { signal: options.signal }
+  )
+  return data
+}
+
+export async function getUpstreamConnectionStats(
+  options: OpsRequestOptions = {}
+): Promise<OpsUpstreamConnectionStats> {
+  const { data } = await apiClient.get<OpsUpstreamConnectionStats>(
+    '/admin/ops/dashboard/upstream-connection-stats',
+    { signal: options.signal }
+  )
+  return data
+}
+
+export async function getIncidentSnapshot(
+  params: {
+    start_time: string
+    end_time: string
+    platform?: string
+    group_id?: number | null
+    limit?: number
+  },
+  options: OpsRequestOptions = {}
+): Promise<OpsIncidentSnapshot> {
+  const { data } = await apiClient.get<OpsIncidentSnapshot>('/admin/ops/incidents/snapshot', {
+    params,
+    signal: options.signal
+  })
+  return data
+}
+
 export type OpsErrorListView = 'errors' | 'excluded' | 'all'
 
 export type OpsErrorListQueryParams = {
@@ -1106,7 +1394,11 @@ export type OpsErrorListQueryParams = {
   category?: string
   error_owner?: string
   error_source?: string
+  error_code?: string
   resolved?: string
+  // 处理状态（逗号分隔多选），assignee 取用户 ID / me / none
+  status?: string
+  assignee?: string
   view?: OpsErrorListView
 
   q?: string
@@ -1133,6 +1425,21 @@ export async function updateErrorResolved(errorId: number, resolved: boolean): P
   await apiClient.put(`/admin/ops/errors/${errorId}/resolve`, { resolved })
 }
 
+export async function updateErrorTriage(errorId: number, update: OpsErrorTriageUpdate): Promise<OpsErrorDetail> {
+  const { data } = await apiClient.put<OpsErrorDetail>(`/admin/ops/errors/${errorId}/triage`, update)
+  return data
+}
+
+export async function listErrorComments(errorId: number): Promise<OpsErrorComment[]> {
+  const { data } = await apiClient.get<OpsErrorComment[]>(`/admin/ops/errors/${errorId}/comments`)
+  return data
+}
+
+export async function addErrorComment(errorId: number, body: string): Promise<OpsErrorComment> {
+  const { data } = await apiClient.post<OpsErrorComment>(`/admin/ops/errors/${errorId}/comments`, { body })
+  return data
+}
+
 // New split endpoints
 export async function listRequestErrors(params: OpsErrorListQueryParams): Promise<OpsErrorLogsResponse> {
   const { data } = await apiClient.get<OpsErrorLogsResponse>('/admin/ops/request-errors', { params })
@@ -1178,6 +1485,244 @@ export async function listRequestDetails(params: OpsRequestDetailsParams): Promi
   return data
 }
 
+// 调试包：请求明细、关联错误日志、耗时与脱敏后的抓包记录（zip）。key 为 request_id 或 client_request_id，默认查找最近 7 天
+export async function downloadRequestBundle(
+  key: string,
+  params: { time_range?: string; start_time?: string; end_time?: string } = {}
+): Promise<Blob> {
+  const response = await apiClient.get(`/admin/ops/requests/${encodeURIComponent(key)}/bundle.zip`, {
+    params,
+    responseType: 'blob'
+  })
+  return response.data
+}
+
+// 原始错误日志导出（CSV / JSONL，服务端分批流式写出）。不含请求体与请求头，时间窗口最长 30 天
+export async function exportErrorLogs(params: {
+  format?: 'csv' | 'jsonl'
+  time_range?: string
+  start_time?: string
+  end_time?: string
+  platform?: string
+  phase?: string
+  error_owner?: string
+  status_code?: number
+  user_id?: number
+  api_key_id?: number
+  group_id?: number
+  account_id?: number
+  model?: string
+} = {}): Promise<Blob> {
+  const response = await apiClient.get('/admin/ops/errors/export', {
+    params,
+    responseType: 'blob'
+  })
+  return response.data
+}
+
+export type RequestClass = 'interactive' | 'batch'
+
+export interface RequestClassLatency {
+  samples: number
+  p50: number | null
+  p95: number | null
+  p99: number | null
+  within_target: number
+  compliance: number | null
+}
+
+export interface RequestClassSLOStats {
+  class: RequestClass
+  requests: number
+  first_token_ms: RequestClassLatency
+  duration_ms: RequestClassLatency
+  target: {
+    first_token_ms: number
+    duration_ms: number
+  }
+}
+
+export interface RequestClassSLOReport {
+  start_time: string
+  end_time: string
+  classes: RequestClassSLOStats[]
+}
+
+export async function getRequestClassSLO(params: {
+  time_range?: string
+  start_time?: string
+  end_time?: string
+  group_id?: number
+  model?: string
+} = {}): Promise<RequestClassSLOReport> {
+  const { data } = await apiClient.get<RequestClassSLOReport>('/admin/ops/request-class-slo', { params })
+  return data
+}
+
+export interface OpsUpstreamRequestID {
+  id: number
+  created_at: string
+  client_request_id: string
+  upstream_request_id: string
+  account_id?: number
+  upstream_host?: string
+  status_code?: number
+}
+
+export async function listUpstreamRequestIDs(key: string): Promise<OpsUpstreamRequestID[]> {
+  const { data } = await apiClient.get<OpsUpstreamRequestID[]>('/admin/ops/upstream-request-ids', {
+    params: { key }
+  })
+  return data
+}
+
+export type OpsStreamStallSource = 'none' | 'upstream' | 'relay'
+
+export interface OpsStreamTiming {
+  id: number
+  created_at: string
+  request_id: string
+  client_request_id: string
+  account_id?: number
+  platform: string
+  model: string
+  outcome: 'completed' | 'client_disconnected' | 'timeout' | 'error' | string
+  event_count: number
+  duration_ms: number
+  first_event_ms?: number
+  max_upstream_gap_ms: number
+  max_relay_lag_ms: number
+  avg_relay_lag_ms: number
+  upstream_gap_histogram: Record<string, number>
+  stall_source: OpsStreamStallSource
+}
+
+export async function listRequestStreamTimings(key: string): Promise<OpsStreamTiming[]> {
+  const { data } = await apiClient.get<OpsStreamTiming[]>(
+    `/admin/ops/requests/${encodeURIComponent(key)}/stream-timings`
+  )
+  return data
+}
+
+// 请求重放：按抓包记录重新发起上游请求（可换同平台同类型账号），结果关联原请求保存；不计费
+export interface OpsRequestReplay {
+  id: number
+  created_at: string
+  created_by?: number
+  request_id: string
+  api_key_id: number
+  upstream_index: number
+  source_account_id?: number
+  account_id: number
+  method: string
+  url: string
+  status_code: number
+  duration_ms: number
+  response_headers?: Record<string, string>
+  response_body?: string
+  response_body_truncated?: boolean
+  error_message?: string
+  source_status_code?: number
+}
+
+export async function replayRequest(
+  key: string,
+  payload: { upstream_index?: number; account_id?: number } = {},
+  params: { time_range?: string; start_time?: string; end_time?: string } = {}
+): Promise<OpsRequestReplay> {
+  const { data } = await apiClient.post<OpsRequestReplay>(
+    `/admin/ops/requests/${encodeURIComponent(key)}/replays`,
+    payload,
+    { params }
+  )
+  return data
+}
+
+export async function listRequestReplays(key: string): Promise<OpsRequestReplay[]> {
+  const { data } = await apiClient.get<OpsRequestReplay[]>(`/admin/ops/requests/${encodeURIComponent(key)}/replays`)
+  return data
+}
+
+export interface OpsWatchdogStats {
+  in_flight_requests: number
+  heap_alloc_mb: number
+  heap_sys_mb: number
+  goroutines: number
+}
+
+export interface OpsWatchdogDump {
+  id: string
+  created_at: string
+  hostname: string
+  trigger: 'threshold' | 'manual' | string
+  reasons?: string[]
+  stats: OpsWatchdogStats
+  size_bytes: number
+}
+
+export interface OpsWatchdogStatus {
+  hostname: string
+  check_interval_seconds: number
+  max_in_flight_requests: number
+  max_heap_mb: number
+  max_goroutines: number
+  cooldown_minutes: number
+  max_dumps: number
+  current: OpsWatchdogStats
+  last_dump_at?: string
+  dumps: OpsWatchdogDump[]
+}
+
+// Resource watchdog (dumps are stored on the instance serving the request)
+export async function getWatchdogStatus(): Promise<OpsWatchdogStatus> {
+  const { data } = await apiClient.get<OpsWatchdogStatus>('/admin/ops/watchdog')
+  return data
+}
+
+export async function captureWatchdogDump(): Promise<OpsWatchdogDump> {
+  const { data } = await apiClient.post<OpsWatchdogDump>('/admin/ops/watchdog/dumps')
+  return data
+}
+
+export async function downloadWatchdogDump(id: string): Promise<Blob> {
+  const response = await apiClient.get(`/admin/ops/watchdog/dumps/${encodeURIComponent(id)}/download`, {
+    responseType: 'blob'
+  })
+  return response.data
+}
+
+export interface OpsSchemaDriftFinding {
+  schema: string
+  kind: 'new_field' | 'new_event' | 'missing_field' | 'missing_event' | string
+  name: string
+  occurrences: number
+  first_seen_at: string
+  last_seen_at: string
+  last_warned_at?: string
+}
+
+export interface OpsSchemaDriftSchemaStatus {
+  name: string
+  pending_samples: number
+  total_samples: number
+}
+
+export interface OpsSchemaDriftStatus {
+  hostname: string
+  sample_rate: number
+  check_interval_seconds: number
+  min_samples: number
+  last_check_at?: string
+  schemas: OpsSchemaDriftSchemaStatus[]
+  findings: OpsSchemaDriftFinding[]
+}
+
+// Upstream schema drift (samples and findings are kept on the instance serving the request)
+export async function getSchemaDriftStatus(): Promise<OpsSchemaDriftStatus> {
+  const { data } = await apiClient.get<OpsSchemaDriftStatus>('/admin/ops/schema-drift')
+  return data
+}
+
 // Alert rules
 export async function listAlertRules(): Promise<AlertRule[]> {
   const { data } = await apiClient.get<AlertRule[]>('/admin/ops/alert-rules')
@@ -1237,6 +1782,38 @@ export async function createAlertSilence(payload: {
   await apiClient.post('/admin/ops/alert-silences', payload)
 }
 
+// Alert webhooks
+export async function listAlertWebhooks(): Promise<AlertWebhook[]> {
+  const { data } = await apiClient.get<AlertWebhook[]>('/admin/ops/alert-webhooks')
+  return data
+}
+
+export async function createAlertWebhook(payload: AlertWebhookInput): Promise<AlertWebhook> {
+  const { data } = await apiClient.post<AlertWebhook>('/admin/ops/alert-webhooks', payload)
+  return data
+}
+
+export async function updateAlertWebhook(id: number, payload: AlertWebhookInput): Promise<AlertWebhook> {
+  const { data } = await apiClient.put<AlertWebhook>(`/admin/ops/alert-webhooks/${id}`, payload)
+  return data
+}
+
+export async function deleteAlertWebhook(id: number): Promise<void> {
+  await apiClient.delete(`/admin/ops/alert-webhooks/${id}`)
+}
+
+export async function testAlertWebhook(id: number): Promise<AlertWebhookDelivery> {
+  const { data } = await apiClient.post<AlertWebhookDelivery>(`/admin/ops/alert-webhooks/${id}/test`)
+  return data
+}
+
+export async function listAlertWebhookDeliveries(
+  params: { webhook_id?: number; event_id?: number; limit?: number } = {}
+): Promise<AlertWebhookDelivery[]> {
+  const { data } = await apiClient.get<AlertWebhookDelivery[]>('/admin/ops/alert-webhook-deliveries', { params })
+  return data
+}
+
 // Email notification config
 export async function getEmailNotificationConfig(): Promise<EmailNotificationConfig> {
   const { data } = await apiClient.get<EmailNotificationConfig>('/admin/ops/email-notification/config')
@@ -1248,6 +1825,25 @@ export async function updateEmailNotificationConfig(config: EmailNotificationCon
   return data
 }
 
+export async function getWeeklyDigestPreview(): Promise<OpsWeeklyDigest> {
+  const { data } = await apiClient.get<OpsWeeklyDigest>('/admin/ops/reports/weekly-digest')
+  return data
+}
+
+export async function getReportSubscription(): Promise<OpsReportSubscription | null> {
+  const { data } = await apiClient.get<OpsReportSubscription | null>('/admin/ops/report-subscription')
+  return data
+}
+
+export async function updateReportSubscription(input: OpsReportSubscriptionInput): Promise<OpsReportSubscription> {
+  const { data } = await apiClient.put<OpsReportSubscription>('/admin/ops/report-subscription', input)
+  return data
+}
+
+export async function deleteReportSubscription(): Promise<void> {
+  await apiClient.delete('/admin/ops/report-subscription')
+}
+
 // Runtime settings (DB-backed)
 export async function getAlertRuntimeSettings(): Promise<OpsAlertRuntimeSettings> {
   const { data } = await apiClient.get<OpsAlertRuntimeSettings>('/admin/ops/runtime/alert')
@@ -1319,6 +1915,10 @@ export const opsAPI = {
   getErrorTrend,
   getErrorDistribution,
   getOpenAITokenStats,
+  getOpenAISessionStrategyStats,
+  getAutoContinuationStats,
+  getUpstreamConnectionStats,
+  getIncidentSnapshot,
   getConcurrencyStats,
   getUserConcurrencyStats,
   getAccountAvailabilityStats,
@@ -1329,6 +1929,9 @@ export const opsAPI = {
   listErrorLogs,
   getErrorLogDetail,
   updateErrorResolved,
+  updateErrorTriage,
+  listErrorComments,
+  addErrorComment,
 
   // New split endpoints
   listRequestErrors,
@@ -1340,6 +1943,17 @@ export const opsAPI = {
   listRequestErrorUpstreamErrors,
 
   listRequestDetails,
+  downloadRequestBundle,
+  exportErrorLogs,
+  getRequestClassSLO,
+  listUpstreamRequestIDs,
+  listRequestStreamTimings,
+  replayRequest,
+  listRequestReplays,
+  getWatchdogStatus,
+  captureWatchdogDump,
+  downloadWatchdogDump,
+  getSchemaDriftStatus,
   listAlertRules,
   createAlertRule,
   updateAlertRule,
@@ -1348,8 +1962,18 @@ export const opsAPI = {
   getAlertEvent,
   updateAlertEventStatus,
   createAlertSilence,
+  listAlertWebhooks,
+  createAlertWebhook,
+  updateAlertWebhook,
+  deleteAlertWebhook,
+  testAlertWebhook,
+  listAlertWebhookDeliveries,
   getEmailNotificationConfig,
   updateEmailNotificationConfig,
+  getWeeklyDigestPreview,
+  getReportSubscription,
+  updateReportSubscription,
+  deleteReportSubscription,
   getAlertRuntimeSettings,
   updateAlertRuntimeSettings,
   getRuntimeLogConfig,
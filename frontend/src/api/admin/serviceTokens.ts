@@ -0,0 +1,56 @@
+/**
+ * Admin Service Tokens API endpoints
+ * Non-interactive admin API tokens with scopes, expiry and last-used tracking
+ */
+
+import { apiClient } from '../client'
+
+export interface ServiceToken {
+  id: number
+  name: string
+  token_prefix: string
+  scopes: string[]
+  expires_at?: string
+  last_used_at?: string
+  last_used_ip: string
+  created_by: number
+  revoked_at?: string
+  created_at: string
+  updated_at: string
+}
+
+/** Plaintext token is only returned once, at creation time. */
+export interface CreatedServiceToken extends ServiceToken {
+  token: string
+}
+
+export interface CreateServiceTokenRequest {
+  name: string
+  /** e.g. ["accounts:read", "usage:read"]; "<resource>:write" implies read, "*:read" grants read on all resources */
+  scopes: string[]
+  expires_at?: string | null
+}
+
+export async function list(): Promise<ServiceToken[]> {
+  const { data } = await apiClient.get<ServiceToken[]>('/admin/service-tokens')
+  return data
+}
+
+export async function create(request: CreateServiceTokenRequest): Promise<CreatedServiceToken> {
+  const { data } = await apiClient.post<CreatedServiceToken>('/admin/service-tokens', request)
+  return data
+}
+
+export async function revoke(id: number): Promise<ServiceToken> {
+  const { data } = await apiClient.post<ServiceToken>(`/admin/service-tokens/${id}/revoke`)
+  return data
+}
+
+export async function remove(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(`/admin/service-tokens/${id}`)
+  return data
+}
+
+export const serviceTokensAPI = { list, create, revoke, remove }
+
+export default serviceTokensAPI
@@ -0,0 +1,73 @@
+/**
+ * Admin Spend Budgets API endpoints
+ * Daily/weekly/monthly spend caps per user, group or API key
+ */
+
+import { apiClient } from '../client'
+
+export type SpendBudgetScopeType = 'user' | 'group' | 'api_key'
+export type SpendBudgetPeriod = 'daily' | 'weekly' | 'monthly'
+
+export interface SpendBudget {
+  id: number
+  scope_type: SpendBudgetScopeType
+  scope_id: number
+  period: SpendBudgetPeriod
+  limit_usd: number
+  enabled: boolean
+  created_at: string
+  updated_at: string
+}
+
+export interface SpendBudgetStatus extends SpendBudget {
+  spent_usd: number
+  remaining_usd: number
+  period_start: string
+  resets_at: string
+  exhausted: boolean
+}
+
+export interface CreateSpendBudgetRequest {
+  scope_type: SpendBudgetScopeType
+  scope_id: number
+  period: SpendBudgetPeriod
+  limit_usd: number
+  enabled?: boolean
+}
+
+export interface UpdateSpendBudgetRequest {
+  limit_usd?: number
+  enabled?: boolean
+}
+
+/**
+ * List budgets with spent and remaining amounts for the current period
+ */
+export async function list(filters?: {
+  scope_type?: SpendBudgetScopeType
+  scope_id?: number
+}): Promise<SpendBudgetStatus[]> {
+  const { data } = await apiClient.get<SpendBudgetStatus[]>('/admin/spend-budgets', {
+    params: filters
+  })
+  return data
+}
+
+export async function create(request: CreateSpendBudgetRequest): Promise<SpendBudget> {
+  const { data } = await apiClient.post<SpendBudget>('/admin/spend-budgets', request)
+  return data
+}
+
+export async function update(id: number, request: UpdateSpendBudgetRequest): Promise<SpendBudget> {
+  const { data } = await apiClient.put<SpendBudget>(`/admin/spend-budgets/${id}`, request)
+  return data
+}
+
+export async function remove(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(`/admin/spend-budgets/${id}`)
+  return data
+}
+
+export const spendBudgetsAPI = { list, create, update, remove }
+
+export default spendBudgetsAPI
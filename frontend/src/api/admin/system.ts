@@ -90,13 +90,46 @@ export async function restartService(): Promise<{ message: string }> {
   return data
 }
 
+export interface RuntimeTuning {
+  go_version: string
+  num_cpu: number
+  goroutines: number
+  gc_percent: number
+  max_procs: number
+  memory_limit_bytes: number // 0 = unlimited
+}
+
+export interface UpdateRuntimeTuningRequest {
+  gc_percent?: number
+  max_procs?: number
+  memory_limit_bytes?: number
+}
+
+/**
+ * Get runtime tuning of the serving instance (requires server.debug_endpoints.enabled)
+ */
+export async function getRuntimeTuning(): Promise<RuntimeTuning> {
+  const { data } = await apiClient.get<RuntimeTuning>('/admin/debug/runtime')
+  return data
+}
+
+/**
+ * Adjust GC percent / GOMAXPROCS / memory limit of the serving instance (not persisted)
+ */
+export async function updateRuntimeTuning(req: UpdateRuntimeTuningRequest): Promise<RuntimeTuning> {
+  const { data } = await apiClient.put<RuntimeTuning>('/admin/debug/runtime', req)
+  return data
+}
+
 export const systemAPI = {
   getVersion,
   checkUpdates,
   performUpdate,
   getRollbackVersions,
   rollback,
-  restartService
+  restartService,
+  getRuntimeTuning,
+  updateRuntimeTuning
 }
 
 export default systemAPI
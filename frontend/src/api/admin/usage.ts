@@ -5,7 +5,7 @@
 
 import { apiClient } from '../client'
 import type { AdminUsageLog, UsageQueryParams, PaginatedResponse, UsageRequestType } from '@/types'
-import type { EndpointStat } from '@/types'
+import type { EndpointStat, ThreadCost } from '@/types'
 
 // ==================== Types ====================
 
@@ -21,6 +21,8 @@ export interface AdminUsageStatsResponse {
   total_actual_cost: number
   total_account_cost: number
   average_duration_ms: number
+  total_reasoning_tokens?: number
+  total_tool_call_tokens?: number
   endpoints?: EndpointStat[]
   upstream_endpoints?: EndpointStat[]
   endpoint_paths?: EndpointStat[]
@@ -135,6 +137,20 @@ export async function getStats(params: {
   return data
 }
 
+/**
+ * Get accumulated usage of one conversation thread (admin only)
+ * @param threadId - Client-supplied thread ID
+ * @param userId - Optional user scope; thread IDs are not unique across users
+ * @returns Thread totals across all turns
+ */
+export async function getThreadCost(threadId: string, userId?: number): Promise<ThreadCost> {
+  const { data } = await apiClient.get<ThreadCost>(
+    `/admin/usage/threads/${encodeURIComponent(threadId)}`,
+    { params: userId !== undefined ? { user_id: userId } : undefined }
+  )
+  return data
+}
+
 /**
  * Search users by email keyword (admin only)
  * @param keyword - Email keyword to search
@@ -204,14 +220,42 @@ export async function cancelCleanupTask(taskId: number): Promise<{ id: number; s
   return data
 }
 
+export interface UsageExportParams {
+  format?: 'csv' | 'jsonl'
+  start_date: string
+  end_date: string
+  timezone?: string
+  user_id?: number
+  api_key_id?: number
+  group_id?: number
+  account_id?: number
+  model?: string
+}
+
+/**
+ * Export raw usage logs as CSV or JSONL (admin only).
+ * The server streams the file in chunks; the end date is inclusive and the range is limited to 366 days.
+ * @param params - Format and filter parameters
+ * @returns File blob
+ */
+export async function exportUsageLogs(params: UsageExportParams): Promise<Blob> {
+  const response = await apiClient.get('/admin/usage/export', {
+    params,
+    responseType: 'blob'
+  })
+  return response.data
+}
+
 export const adminUsageAPI = {
   list,
   getStats,
+  getThreadCost,
   searchUsers,
   searchApiKeys,
   listCleanupTasks,
   createCleanupTask,
-  cancelCleanupTask
+  cancelCleanupTask,
+  exportUsageLogs
 }
 
 export default adminUsageAPI
@@ -0,0 +1,48 @@
+/**
+ * Balance API endpoints
+ * Prepaid balance summary and daily balance history of the current user
+ */
+
+import { apiClient } from './client'
+
+export interface BalanceSummary {
+  balance: number
+  /** Estimated cost held by in-flight requests */
+  reserved: number
+  /** balance - reserved */
+  available: number
+}
+
+export interface BalanceHistoryDay {
+  date: string
+  credits: number
+  debits: number
+  /** Balance at the end of the day */
+  balance: number
+}
+
+/**
+ * Get current balance with in-flight reservations
+ */
+export async function getSummary(): Promise<BalanceSummary> {
+  const { data } = await apiClient.get<BalanceSummary>('/user/balance')
+  return data
+}
+
+/**
+ * Get daily balance history, newest day first
+ * @param days - Number of days including today (max 90)
+ */
+export async function getHistory(days = 30): Promise<BalanceHistoryDay[]> {
+  const { data } = await apiClient.get<BalanceHistoryDay[]>('/user/balance/history', {
+    params: { days }
+  })
+  return data
+}
+
+export const balanceAPI = {
+  getSummary,
+  getHistory
+}
+
+export default balanceAPI
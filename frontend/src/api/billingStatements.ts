@@ -0,0 +1,65 @@
+/**
+ * Billing Statements API endpoints
+ * Monthly usage statements of the current user
+ */
+
+import { apiClient } from './client'
+import type { PaginatedResponse } from '@/types'
+
+export type BillingStatementStatus = 'issued' | 'invoiced' | 'reported' | 'paid' | 'void'
+
+export interface BillingStatementLineItem {
+  model: string
+  requests: number
+  input_tokens: number
+  output_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  total_cost: number
+  actual_cost: number
+}
+
+export interface BillingStatement {
+  id: number
+  user_id: number
+  period_start: string
+  period_end: string
+  total_requests: number
+  total_tokens: number
+  total_cost: number
+  actual_cost: number
+  line_items: BillingStatementLineItem[]
+  status: BillingStatementStatus
+  stripe_invoice_id?: string
+  /** Stripe hosted invoice page, present once invoiced */
+  stripe_invoice_url?: string
+  /** Last Stripe push error (admin only) */
+  stripe_error?: string
+  created_at: string
+  updated_at: string
+}
+
+/**
+ * List statements of the current user, newest period first
+ */
+export async function list(page = 1, pageSize = 20): Promise<PaginatedResponse<BillingStatement>> {
+  const { data } = await apiClient.get<PaginatedResponse<BillingStatement>>('/user/statements', {
+    params: { page, page_size: pageSize }
+  })
+  return data
+}
+
+/**
+ * Get a statement with its per-model line items
+ */
+export async function getById(id: number): Promise<BillingStatement> {
+  const { data } = await apiClient.get<BillingStatement>(`/user/statements/${id}`)
+  return data
+}
+
+export const billingStatementsAPI = {
+  list,
+  getById
+}
+
+export default billingStatementsAPI
@@ -19,6 +19,10 @@ export { userGroupsAPI } from './groups'
 export { userChannelsAPI } from './channels'
 export * as batchImageAPI from './batchImage'
 export { totpAPI } from './totp'
+export { usageAlertsAPI } from './usageAlerts'
+export { billingStatementsAPI } from './billingStatements'
+export { balanceAPI } from './balance'
+export { projectsAPI } from './projects'
 export { default as announcementsAPI } from './announcements'
 export { channelMonitorUserAPI } from './channelMonitor'
 
@@ -4,7 +4,13 @@
  */
 
 import { apiClient } from './client'
-import type { ApiKey, CreateApiKeyRequest, UpdateApiKeyRequest, PaginatedResponse } from '@/types'
+import type {
+  ApiKey,
+  CreateApiKeyRequest,
+  CreateGuestApiKeyRequest,
+  UpdateApiKeyRequest,
+  PaginatedResponse
+} from '@/types'
 
 /**
  * List all API keys for current user
@@ -65,7 +71,13 @@ export async function create(
   ipBlacklist?: string[],
   quota?: number,
   expiresInDays?: number,
-  rateLimitData?: { rate_limit_5h?: number; rate_limit_1d?: number; rate_limit_7d?: number }
+  rateLimitData?: {
+    rate_limit_5h?: number
+    rate_limit_1d?: number
+    rate_limit_7d?: number
+    rpm_limit?: number
+    tpm_limit?: number
+  }
 ): Promise<ApiKey> {
   const payload: CreateApiKeyRequest = { name }
   if (groupId !== undefined) {
@@ -95,11 +107,27 @@ export async function create(
   if (rateLimitData?.rate_limit_7d && rateLimitData.rate_limit_7d > 0) {
     payload.rate_limit_7d = rateLimitData.rate_limit_7d
   }
+  if (rateLimitData?.rpm_limit && rateLimitData.rpm_limit > 0) {
+    payload.rpm_limit = rateLimitData.rpm_limit
+  }
+  if (rateLimitData?.tpm_limit && rateLimitData.tpm_limit > 0) {
+    payload.tpm_limit = rateLimitData.tpm_limit
+  }
 
   const { data } = await apiClient.post<ApiKey>('/keys', payload)
   return data
 }
 
+/**
+ * Create a short-lived guest API key (deleted automatically after expiry)
+ * @param payload - TTL, token cap and allowed models
+ * @returns Created guest API key
+ */
+export async function createGuest(payload: CreateGuestApiKeyRequest): Promise<ApiKey> {
+  const { data } = await apiClient.post<ApiKey>('/keys/guest', payload)
+  return data
+}
+
 /**
  * Update API key
  * @param id - API key ID
@@ -135,6 +163,7 @@ export const keysAPI = {
   list,
   getById,
   create,
+  createGuest,
   update,
   delete: deleteKey,
   toggleStatus
@@ -0,0 +1,147 @@
+/**
+ * API Key Projects API endpoints
+ * Groups several API keys under one project with a shared quota and combined usage reporting
+ */
+
+import { apiClient } from './client'
+
+export interface ApiKeyProject {
+  id: number
+  user_id: number
+  name: string
+  description: string
+  /** Shared quota in USD across all member keys (0 = unlimited) */
+  quota: number
+  quota_used: number
+  key_count: number
+  created_at: string
+  updated_at: string
+}
+
+export interface ApiKeyProjectRequest {
+  name: string
+  description?: string
+  quota?: number
+}
+
+export interface ApiKeyProjectKeyUsage {
+  api_key_id: number
+  name: string
+  requests: number
+  tokens: number
+  total_cost: number
+  actual_cost: number
+}
+
+export interface ApiKeyProjectUsage {
+  project_id: number
+  start_time: string
+  end_time: string
+  requests: number
+  tokens: number
+  total_cost: number
+  actual_cost: number
+  quota: number
+  quota_used: number
+  keys: ApiKeyProjectKeyUsage[]
+}
+
+export interface ApiKeyProjectUsageParams {
+  /** YYYY-MM-DD, defaults to 29 days before end_date */
+  start_date?: string
+  /** YYYY-MM-DD (inclusive), defaults to today */
+  end_date?: string
+  timezone?: string
+}
+
+/**
+ * List projects of the current user
+ */
+export async function list(): Promise<ApiKeyProject[]> {
+  const { data } = await apiClient.get<ApiKeyProject[]>('/projects')
+  return data
+}
+
+/**
+ * Get a project by ID
+ */
+export async function getById(id: number): Promise<ApiKeyProject> {
+  const { data } = await apiClient.get<ApiKeyProject>(`/projects/${id}`)
+  return data
+}
+
+/**
+ * Create a project
+ */
+export async function create(request: ApiKeyProjectRequest): Promise<ApiKeyProject> {
+  const { data } = await apiClient.post<ApiKeyProject>('/projects', request)
+  return data
+}
+
+/**
+ * Update a project's name, description and shared quota
+ */
+export async function update(id: number, request: ApiKeyProjectRequest): Promise<ApiKeyProject> {
+  const { data } = await apiClient.put<ApiKeyProject>(`/projects/${id}`, request)
+  return data
+}
+
+/**
+ * Delete a project; member keys are kept and detached
+ */
+export async function remove(id: number): Promise<void> {
+  await apiClient.delete(`/projects/${id}`)
+}
+
+/**
+ * Move keys into a project (keys in another project are moved over)
+ */
+export async function addKeys(id: number, apiKeyIds: number[]): Promise<ApiKeyProject> {
+  const { data } = await apiClient.post<ApiKeyProject>(`/projects/${id}/keys`, {
+    api_key_ids: apiKeyIds
+  })
+  return data
+}
+
+/**
+ * Detach keys from a project
+ */
+export async function removeKeys(id: number, apiKeyIds: number[]): Promise<ApiKeyProject> {
+  const { data } = await apiClient.delete<ApiKeyProject>(`/projects/${id}/keys`, {
+    data: { api_key_ids: apiKeyIds }
+  })
+  return data
+}
+
+/**
+ * Reset the project's used quota so its keys can be used again
+ */
+export async function resetQuota(id: number): Promise<ApiKeyProject> {
+  const { data } = await apiClient.post<ApiKeyProject>(`/projects/${id}/reset-quota`)
+  return data
+}
+
+/**
+ * Combined usage of the project with a per-key breakdown
+ */
+export async function getUsage(
+  id: number,
+  params?: ApiKeyProjectUsageParams
+): Promise<ApiKeyProjectUsage> {
+  const { data } = await apiClient.get<ApiKeyProjectUsage>(`/projects/${id}/usage`, { params })
+  return data
+}
+
+export const projectsAPI = {
+  list,
+  getById,
+  create,
+  update,
+  remove,
+  addKeys,
+  removeKeys,
+  resetQuota,
+  getUsage
+}
+
+export default projectsAPI
@@ -6,6 +6,7 @@
 import { apiClient } from './client'
 import type {
   UsageLog,
+  ThreadCost,
   UsageQueryParams,
   UsageStatsResponse,
   PaginatedResponse,
@@ -250,6 +251,16 @@ export async function getById(id: number): Promise<UsageLog> {
   return data
 }
 
+/**
+ * Get accumulated usage of one conversation thread (tagged via X-Sub2API-Thread-ID)
+ * @param threadId - Client-supplied thread ID
+ * @returns Thread totals across all turns
+ */
+export async function getThreadCost(threadId: string): Promise<ThreadCost> {
+  const { data } = await apiClient.get<ThreadCost>(`/usage/threads/${encodeURIComponent(threadId)}`)
+  return data
+}
+
 // ==================== Dashboard API ====================
 
 /**
@@ -375,6 +386,7 @@ export const usageAPI = {
   getStatsByDateRange,
   getByDateRange,
   getById,
+  getThreadCost,
   // Dashboard
   getDashboardStats,
   getDashboardTrend,
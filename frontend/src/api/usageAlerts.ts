@@ -0,0 +1,83 @@
+/**
+ * Usage Alerts API endpoints
+ * Handles user-configured usage threshold notifications (webhook / email)
+ */
+
+import { apiClient } from './client'
+
+export type UsageAlertMetric = 'cost' | 'requests' | 'tokens'
+
+export interface UsageAlert {
+  id: number
+  user_id: number
+  api_key_id: number | null
+  metric: UsageAlertMetric
+  window_minutes: number
+  threshold: number
+  webhook_url: string
+  notify_email: boolean
+  enabled: boolean
+  last_triggered_at: string | null
+  created_at: string
+  updated_at: string
+}
+
+export interface UsageAlertRequest {
+  /** null/0 watches all keys of the current user */
+  api_key_id?: number | null
+  metric: UsageAlertMetric
+  window_minutes: number
+  threshold: number
+  webhook_url?: string
+  notify_email?: boolean
+  enabled?: boolean
+}
+
+/**
+ * List usage alerts of the current user
+ */
+export async function list(): Promise<UsageAlert[]> {
+  const { data } = await apiClient.get<UsageAlert[]>('/user/usage-alerts')
+  return data
+}
+
+/**
+ * Create a usage alert
+ */
+export async function create(request: UsageAlertRequest): Promise<UsageAlert> {
+  const { data } = await apiClient.post<UsageAlert>('/user/usage-alerts', request)
+  return data
+}
+
+/**
+ * Update a usage alert; changing metric/window/threshold resets its cooldown
+ */
+export async function update(id: number, request: UsageAlertRequest): Promise<UsageAlert> {
+  const { data } = await apiClient.put<UsageAlert>(`/user/usage-alerts/${id}`, request)
+  return data
+}
+
+/**
+ * Delete a usage alert
+ */
+export async function remove(id: number): Promise<void> {
+  await apiClient.delete(`/user/usage-alerts/${id}`)
+}
+
+/**
+ * Send a test notification through the alert's configured channels
+ */
+export async function sendTest(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.post<{ message: string }>(`/user/usage-alerts/${id}/test`)
+  return data
+}
+
+export const usageAlertsAPI = {
+  list,
+  create,
+  update,
+  remove,
+  sendTest
+}
+
+export default usageAlertsAPI
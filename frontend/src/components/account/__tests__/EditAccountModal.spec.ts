@@ -558,11 +558,16 @@ describe('EditAccountModal', () => {
     const embeddingsCheckbox = wrapper.get<HTMLInputElement>(
       '[data-testid="openai-endpoint-capability-embeddings"]'
     )
+    const audioCheckbox = wrapper.get<HTMLInputElement>(
+      '[data-testid="openai-endpoint-capability-audio"]'
+    )
 
     expect(chatCheckbox.element.checked).toBe(true)
     expect(embeddingsCheckbox.element.checked).toBe(true)
+    expect(audioCheckbox.element.checked).toBe(true)
 
     await embeddingsCheckbox.setValue(false)
+    await audioCheckbox.setValue(false)
 
     expect(chatCheckbox.element.checked).toBe(true)
     expect(embeddingsCheckbox.element.checked).toBe(false)
@@ -491,11 +491,14 @@ export default {
           groupAvailableAccounts: 'Group Available Accounts',
           groupAvailableRatio: 'Group Available Ratio (%)',
           groupRateLimitRatio: 'Group Rate Limit Ratio (%)',
+          groupFailoverTier: 'Group Active Failover Tier',
           accountRateLimitedCount: 'Rate-limited Accounts',
           accountErrorCount: 'Error Accounts (excluding temporarily unschedulable)',
           accountErrorRatio: 'Error Account Ratio (%)',
           accountTempUnscheduledCount: 'Temporarily Unschedulable Accounts',
-          overloadAccountCount: 'Overloaded Accounts'
+          overloadAccountCount: 'Overloaded Accounts',
+          upstream429Count: 'Upstream 429s',
+          streamFaultRate: 'Stream Fault Rate (%)'
         },
         metricDescriptions: {
           successRate: 'Percentage of successful requests in the window (0-100).',
@@ -509,11 +512,14 @@ export default {
           groupAvailableAccounts: 'Number of available accounts in the selected group (requires group_id).',
           groupAvailableRatio: 'Available account ratio in the selected group (0-100, requires group_id).',
           groupRateLimitRatio: 'Rate-limited account ratio in the selected group (0-100, requires group_id).',
+          groupFailoverTier: 'Highest failover tier that still has available accounts (0=primary, 1=secondary, 2=emergency, requires group_id). Use >= 2 to alert when the emergency tier engages.',
           accountRateLimitedCount: 'Number of rate-limited accounts within the window.',
           accountErrorCount: 'Number of error accounts within the window (excluding temporarily unschedulable).',
           accountErrorRatio: 'Error account ratio within the window (0-100).',
           accountTempUnscheduledCount: 'Number of accounts currently temporarily unschedulable (e.g. proxy/credential failure auto-eviction).',
-          overloadAccountCount: 'Number of overloaded accounts within the window.'
+          overloadAccountCount: 'Number of overloaded accounts within the window.',
+          upstream429Count: 'Upstream 429 responses within the window; set filters.account_id to watch a single account.',
+          streamFaultRate: 'Share of streaming requests that failed mid-stream after HTTP 200 (0-100).'
         },
         hints: {
           recommended: 'Recommended: operator {operator}, threshold {threshold}{unit}',
@@ -640,6 +646,9 @@ export default {
         errorDigestMinCount: 'Min errors for digest',
         accountHealth: 'Account health',
         accountHealthThreshold: 'Error rate threshold (%)',
+        weeklyDigest: 'Weekly usage & health digest',
+        digestQuotaThreshold: 'Quota warning threshold (%)',
+        digestExpiringDays: 'Expiring within (days)',
         cronPlaceholder: 'Cron expression',
         reportHint: 'Schedules use cron syntax; leave empty to use defaults.',
         validation: {
@@ -653,7 +662,9 @@ export default {
           cronRequired: 'A cron expression is required when schedule is enabled',
           cronFormat: 'Cron expression format looks invalid (expected at least 5 parts)',
           digestMinCountRange: 'Min errors for digest must be a number ≥ 0',
-          accountHealthThresholdRange: 'Account health threshold must be between 0 and 100'
+          accountHealthThresholdRange: 'Account health threshold must be between 0 and 100',
+          digestQuotaThresholdRange: 'Quota warning threshold must be between 0 and 100',
+          digestExpiringDaysRange: 'Expiring window must be between 0 and 90 days'
         }
       },
       settings: {
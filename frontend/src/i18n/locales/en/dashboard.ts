@@ -297,6 +297,7 @@ export default {
     stream: 'Stream',
     sync: 'Sync',
     cyber: 'Cyber',
+    reused: 'Reused',
     unknown: 'Unknown',
     in: 'In',
     out: 'Out',
@@ -314,6 +315,9 @@ export default {
     imageInputSize: 'Input size',
     imageOutputSize: 'Output size',
     imageOutputTokens: 'Image Output Tokens',
+    reasoningTokens: 'Reasoning Tokens',
+    toolCallTokens: 'Tool Call Tokens (est.)',
+    visibleOutputTokens: 'Visible Output Tokens',
     imageOutputTokenPrice: 'Image Output Price',
     imageOutputCost: 'Image Output Cost',
     imageSizeSource: 'Size source',
@@ -491,11 +491,14 @@ export default {
           groupAvailableAccounts: '分组可用账号数',
           groupAvailableRatio: '分组可用比例 (%)',
           groupRateLimitRatio: '分组限流比例 (%)',
+          groupFailoverTier: '分组当前故障转移层级',
           accountRateLimitedCount: '限流账号数',
           accountErrorCount: '错误账号数（不含临时不可调度）',
           accountErrorRatio: '错误账号比例 (%)',
           accountTempUnscheduledCount: '临时不可调度账号数',
-          overloadAccountCount: '过载账号数'
+          overloadAccountCount: '过载账号数',
+          upstream429Count: '上游 429 次数',
+          streamFaultRate: '流内失败率 (%)'
         },
         metricDescriptions: {
           successRate: '统计窗口内成功请求占比（0~100）。',
@@ -509,11 +512,14 @@ export default {
           groupAvailableAccounts: '指定分组中当前可用账号数量（需要 group_id 过滤）。',
           groupAvailableRatio: '指定分组中可用账号占比（0~100，需要 group_id 过滤）。',
           groupRateLimitRatio: '指定分组中账号被限流的比例（0~100，需要 group_id 过滤）。',
+          groupFailoverTier: '指定分组中仍有可用账号的最高故障转移层级（0=主用，1=备用，2=应急，需要 group_id 过滤）；设置 >= 2 可在应急层级启用时告警。',
           accountRateLimitedCount: '统计窗口内被限流的账号数量。',
           accountErrorCount: '统计窗口内产生错误的账号数量（不含临时不可调度）。',
           accountErrorRatio: '统计窗口内错误账号占比（0~100）。',
           accountTempUnscheduledCount: '当前处于临时不可调度状态的账号数量（如代理/凭据故障被自动摘除）。',
-          overloadAccountCount: '统计窗口内过载账号数量。'
+          overloadAccountCount: '统计窗口内过载账号数量。',
+          upstream429Count: '统计窗口内上游返回 429 的次数；可通过 filters.account_id 限定单个账号。',
+          streamFaultRate: '流式请求在已返回 HTTP 200 后流内失败的比例（0~100）。'
         },
         hints: {
           recommended: '推荐：运算符 {operator}，阈值 {threshold}{unit}',
@@ -640,6 +646,9 @@ export default {
         errorDigestMinCount: '错误摘要最小数量',
         accountHealth: '账号健康报告',
         accountHealthThreshold: '错误率阈值（%）',
+        weeklyDigest: '周度用量与健康摘要',
+        digestQuotaThreshold: '配额预警阈值（%）',
+        digestExpiringDays: '即将到期（天内）',
         cronPlaceholder: 'Cron 表达式',
         reportHint: '发送时间使用 Cron 语法；留空将使用默认值。',
         validation: {
@@ -653,7 +662,9 @@ export default {
           cronRequired: '启用定时任务时必须填写 Cron 表达式',
           cronFormat: 'Cron 表达式格式可能不正确（至少应包含 5 段）',
           digestMinCountRange: '错误摘要最小数量必须为 ≥ 0 的数字',
-          accountHealthThresholdRange: '账号健康错误率阈值必须在 0 到 100 之间'
+          accountHealthThresholdRange: '账号健康错误率阈值必须在 0 到 100 之间',
+          digestQuotaThresholdRange: '配额预警阈值必须在 0 到 100 之间',
+          digestExpiringDaysRange: '即将到期天数必须在 0 到 90 之间'
         }
       },
       settings: {
@@ -302,6 +302,7 @@ export default {
     stream: '流式',
     sync: '同步',
     cyber: '安全策略',
+    reused: '复用',
     unknown: '未知',
     in: '输入',
     out: '输出',
@@ -319,6 +320,9 @@ export default {
     imageInputSize: '输入尺寸',
     imageOutputSize: '输出尺寸',
     imageOutputTokens: '图片输出 Token',
+    reasoningTokens: '推理 Token',
+    toolCallTokens: '工具调用 Token（估算）',
+    visibleOutputTokens: '可见输出 Token',
     imageOutputTokenPrice: '图片输出单价',
     imageOutputCost: '图片输出费用',
     imageSizeSource: '尺寸来源',
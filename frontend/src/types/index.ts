@@ -536,6 +536,8 @@ export interface Group {
   peak_start: string
   peak_end: string
   peak_rate_multiplier: number
+  // 按次固定附加费（USD），在倍率计算后的实际费用上叠加
+  request_surcharge: number
   // Claude Code 客户端限制
   claude_code_only: boolean
   fallback_group_id: number | null
@@ -550,11 +552,80 @@ export interface Group {
   updated_at: string
 }
 
+export interface OutputPostprocessRule {
+  type: 'regex' | 'banned_phrase'
+  pattern: string
+  replacement?: string
+}
+
+export interface GroupOutputPostprocessConfig {
+  rules?: OutputPostprocessRule[]
+  attribution?: string
+}
+
+export interface LanguageRoutingRule {
+  languages: string[]
+  model?: string
+  account_ids?: number[]
+  proxy_ids?: number[]
+}
+
+export interface GroupLanguageRoutingConfig {
+  rules?: LanguageRoutingRule[]
+}
+
+export interface GroupStreamReplayConfig {
+  enabled?: boolean
+  max_events?: number
+  max_bytes?: number
+  retention_seconds?: number
+}
+
+export interface GroupCapabilityCheckConfig {
+  enabled?: boolean
+  fallback_models?: Record<string, string>
+}
+
+export interface GroupAutoContinuationConfig {
+  enabled?: boolean
+  max_continuations?: number
+}
+
 export interface AdminGroup extends Group {
   // 模型路由配置（仅管理员可见，内部信息）
   model_routing: Record<string, number[]> | null
   model_routing_enabled: boolean
 
+  // 过载降级：首选账号全部饱和/冷却时将请求模型降级为备用模型（支持末尾 * 通配）
+  overload_fallback_models: Record<string, string> | null
+
+  // 长上下文路由：估算输入超出模型上下文窗口时改用的大上下文模型；无可用模型时是否拒绝
+  context_overflow_models: Record<string, string> | null
+  context_overflow_reject: boolean
+  // 超出上下文窗口且无法改路由时的截断策略（空表示不截断）
+  context_truncation_strategy: '' | 'drop_oldest' | 'summarize' | 'middle_out'
+  // 负载感知调度的账号选择策略（空表示 priority：按优先级逐级溢出）
+  account_selection_strategy: '' | 'weighted_round_robin' | 'least_recently_used' | 'lowest_latency'
+
+  // 图片预处理目标：最长边像素 / 单张字节数（0 = 不限制）
+  image_max_edge: number
+  image_max_bytes: number
+
+  // 输出后处理：替换规则与追加署名
+  output_postprocess: GroupOutputPostprocessConfig
+
+  // 语言路由：按提示词主要语言改写模型或优先调度指定账号/代理
+  language_routing: GroupLanguageRoutingConfig
+
+  // 流式断线续传：缓存 SSE 事件，客户端可携带 Last-Event-ID 重连续读
+  stream_replay: GroupStreamReplayConfig
+
+  // 模型能力校验：请求需要模型不具备的能力时改用备用模型或拒绝
+  capability_check: GroupCapabilityCheckConfig
+
+  // 截断自动续写：非流式响应因输出 token 上限截断时自动续写并拼接
+  auto_continuation: GroupAutoContinuationConfig
+
   // MCP XML 协议注入（仅 antigravity 平台使用）
   mcp_xml_inject: boolean
 
@@ -610,6 +681,15 @@ export interface ApiKey {
   reset_5h_at: string | null
   reset_1d_at: string | null
   reset_7d_at: string | null
+  rpm_limit: number // Requests per minute (0 = unlimited)
+  tpm_limit: number // Tokens per minute (0 = unlimited)
+  // Guest key scope (omitted for regular keys)
+  guest?: boolean
+  allowed_models?: string[]
+  token_limit?: number // Total token cap (0 = unlimited)
+  tokens_used?: number
+  // API key project (shared quota), omitted when not in a project
+  project_id?: number
 }
 
 export interface CreateApiKeyRequest {
@@ -623,6 +703,18 @@ export interface CreateApiKeyRequest {
   rate_limit_5h?: number
   rate_limit_1d?: number
   rate_limit_7d?: number
+  rpm_limit?: number // Requests per minute (0 = unlimited)
+  tpm_limit?: number // Tokens per minute (0 = unlimited)
+}
+
+export interface CreateGuestApiKeyRequest {
+  name?: string
+  group_id?: number | null
+  ttl_minutes?: number // Added to ttl_hours; total must be within 72 hours
+  ttl_hours?: number
+  token_limit?: number // Total token cap (0 = unlimited)
+  quota?: number // Quota limit in USD (0 = unlimited)
+  allowed_models?: string[] // Supports trailing * wildcard
 }
 
 export interface UpdateApiKeyRequest {
@@ -638,6 +730,8 @@ export interface UpdateApiKeyRequest {
   rate_limit_1d?: number
   rate_limit_7d?: number
   reset_rate_limit_usage?: boolean
+  rpm_limit?: number // Requests per minute (null = no change, 0 = unlimited)
+  tpm_limit?: number // Tokens per minute (null = no change, 0 = unlimited)
 }
 
 export interface CreateGroupRequest {
@@ -668,6 +762,7 @@ export interface CreateGroupRequest {
   peak_start?: string
   peak_end?: string
   peak_rate_multiplier?: number
+  request_surcharge?: number
   claude_code_only?: boolean
   fallback_group_id?: number | null
   fallback_group_id_on_invalid_request?: number | null
@@ -679,6 +774,18 @@ export interface CreateGroupRequest {
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   model_routing?: Record<string, number[]> | null
   model_routing_enabled?: boolean
+  overload_fallback_models?: Record<string, string>
+  context_overflow_models?: Record<string, string>
+  context_overflow_reject?: boolean
+  context_truncation_strategy?: '' | 'drop_oldest' | 'summarize' | 'middle_out'
+  account_selection_strategy?: '' | 'priority' | 'weighted_round_robin' | 'least_recently_used' | 'lowest_latency'
+  image_max_edge?: number
+  image_max_bytes?: number
+  output_postprocess?: GroupOutputPostprocessConfig
+  language_routing?: GroupLanguageRoutingConfig
+  stream_replay?: GroupStreamReplayConfig
+  capability_check?: GroupCapabilityCheckConfig
+  auto_continuation?: GroupAutoContinuationConfig
   rpm_limit?: number
   require_oauth_only?: boolean
   require_privacy_set?: boolean
@@ -715,6 +822,7 @@ export interface UpdateGroupRequest {
   peak_start?: string
   peak_end?: string
   peak_rate_multiplier?: number
+  request_surcharge?: number
   claude_code_only?: boolean
   fallback_group_id?: number | null
   fallback_group_id_on_invalid_request?: number | null
@@ -726,6 +834,18 @@ export interface UpdateGroupRequest {
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   model_routing?: Record<string, number[]> | null
   model_routing_enabled?: boolean
+  overload_fallback_models?: Record<string, string>
+  context_overflow_models?: Record<string, string>
+  context_overflow_reject?: boolean
+  context_truncation_strategy?: '' | 'drop_oldest' | 'summarize' | 'middle_out'
+  account_selection_strategy?: '' | 'priority' | 'weighted_round_robin' | 'least_recently_used' | 'lowest_latency'
+  image_max_edge?: number
+  image_max_bytes?: number
+  output_postprocess?: GroupOutputPostprocessConfig
+  language_routing?: GroupLanguageRoutingConfig
+  stream_replay?: GroupStreamReplayConfig
+  capability_check?: GroupCapabilityCheckConfig
+  auto_continuation?: GroupAutoContinuationConfig
   rpm_limit?: number
   require_oauth_only?: boolean
   require_privacy_set?: boolean
@@ -893,7 +1013,7 @@ export interface Account {
   scheduler_scores?: AccountSchedulerGroupScore[] | null
   priority: number
   rate_multiplier?: number // Account billing multiplier (>=0, 0 means free)
-  status: 'active' | 'inactive' | 'error'
+  status: 'active' | 'inactive' | 'error' | 'archived'
   error_message: string | null
   last_used_at: string | null
   expires_at: number | null
@@ -1094,7 +1214,7 @@ export interface CodexUsageSnapshot {
 
 export type OpenAICompactMode = 'auto' | 'force_on' | 'force_off'
 export type OpenAIResponsesMode = 'auto' | 'force_responses' | 'force_chat_completions'
-export type OpenAIEndpointCapability = 'chat_completions' | 'embeddings'
+export type OpenAIEndpointCapability = 'chat_completions' | 'embeddings' | 'audio' | 'batch'
 
 export interface OpenAICompactState {
   openai_compact_mode?: OpenAICompactMode
@@ -1311,7 +1431,7 @@ export interface CodexSessionImportResult {
 // ==================== Usage & Redeem Types ====================
 
 export type RedeemCodeType = 'balance' | 'concurrency' | 'subscription' | 'invitation'
-export type UsageRequestType = 'unknown' | 'sync' | 'stream' | 'ws_v2' | 'cyber'
+export type UsageRequestType = 'unknown' | 'sync' | 'stream' | 'ws_v2' | 'cyber' | 'reused'
 export type ImageSizeSource = 'output' | 'input' | 'default' | 'legacy'
 export type ImageSizeBreakdown = Record<string, number>
 
@@ -1336,6 +1456,11 @@ export interface UsageLog {
   cache_read_tokens: number
   cache_creation_5m_tokens: number
   cache_creation_1h_tokens: number
+  thread_id?: string | null
+  // 输出 token 拆分（均包含在 output_tokens 中，工具调用为估算值）
+  reasoning_tokens?: number
+  tool_call_tokens?: number
+  visible_output_tokens?: number
 
   input_cost: number
   output_cost: number
@@ -1570,6 +1695,23 @@ export interface ModelStat {
   cost: number // 标准计费
   actual_cost: number // 实际扣除
   account_cost?: number // 账号成本（仅管理员接口返回）
+  reasoning_tokens?: number
+  tool_call_tokens?: number
+}
+
+/** 单个会话（X-Sub2API-Thread-ID）所有轮次的累计用量 */
+export interface ThreadCost {
+  thread_id: string
+  requests: number
+  input_tokens: number
+  output_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  total_tokens: number
+  cost: number // 标准计费
+  actual_cost: number // 实际扣除
+  first_request_at: string | null
+  last_request_at: string | null
 }
 
 export interface EndpointStat {
@@ -116,6 +116,18 @@ const createGroup = (overrides: Partial<AdminGroup> = {}): AdminGroup => ({
   updated_at: '2026-07-01T00:00:00Z',
   model_routing: null,
   model_routing_enabled: false,
+  overload_fallback_models: null,
+  context_overflow_models: null,
+  context_overflow_reject: false,
+  context_truncation_strategy: '',
+  account_selection_strategy: '',
+  image_max_edge: 0,
+  image_max_bytes: 0,
+  output_postprocess: {},
+  language_routing: {},
+  stream_replay: {},
+  capability_check: {},
+  auto_continuation: {},
   mcp_xml_inject: true,
   supported_model_scopes: [],
   account_count: 3,
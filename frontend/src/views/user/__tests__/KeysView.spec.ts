@@ -133,6 +133,8 @@ const createApiKey = (): ApiKey => ({
   reset_5h_at: null,
   reset_1d_at: null,
   reset_7d_at: null,
+  rpm_limit: 0,
+  tpm_limit: 0,
 })
 
 const AppLayoutStub = {